@@ -37,6 +37,7 @@ type csArgs struct {
 	ClientToken       string
 	ClientTokenHeader string
 	ListAllPackages   bool
+	Deterministic     bool
 	Target            string
 }
 
@@ -263,20 +264,20 @@ func TestClientServerWithTemplate(t *testing.T) {
 		golden string
 	}{
 		{
-			name: "alpine 3.10 with gitlab template",
+			name: "alpine 3.10 with gitlab-dependency-scanning format",
 			args: csArgs{
-				Format:       "template",
-				TemplatePath: "@../contrib/gitlab.tpl",
-				Input:        "testdata/fixtures/images/alpine-310.tar.gz",
+				Format:        "gitlab-dependency-scanning",
+				Deterministic: true,
+				Input:         "testdata/fixtures/images/alpine-310.tar.gz",
 			},
-			golden: "testdata/alpine-310.gitlab.golden",
+			golden: "testdata/alpine-310.gitlab-dependency-scanning.golden",
 		},
 		{
-			name: "alpine 3.10 with gitlab-codequality template",
+			name: "alpine 3.10 with gitlab-codequality format",
 			args: csArgs{
-				Format:       "template",
-				TemplatePath: "@../contrib/gitlab-codequality.tpl",
-				Input:        "testdata/fixtures/images/alpine-310.tar.gz",
+				Format:        "gitlab-codequality",
+				Deterministic: true,
+				Input:         "testdata/fixtures/images/alpine-310.tar.gz",
 			},
 			golden: "testdata/alpine-310.gitlab-codequality.golden",
 		},
@@ -565,6 +566,9 @@ func setupClient(t *testing.T, c csArgs, addr string, cacheDir string, golden st
 	if c.IgnoreUnfixed {
 		osArgs = append(osArgs, "--ignore-unfixed")
 	}
+	if c.Deterministic {
+		osArgs = append(osArgs, "--deterministic")
+	}
 	if len(c.Severity) != 0 {
 		osArgs = append(osArgs,
 			"--severity", strings.Join(c.Severity, ","),
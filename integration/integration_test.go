@@ -104,6 +104,13 @@ func readReport(t *testing.T, filePath string) types.Report {
 
 	res.Metadata.RepoDigests = nil
 
+	// We don't compare image references because the registry address differs in each test
+	res.Metadata.ImageReference = ""
+	res.Metadata.ImageDigest = ""
+
+	// We don't compare the scan options because the versions of Trivy and the DB differ
+	res.Metadata.Scan = nil
+
 	return res
 }
 
@@ -0,0 +1,246 @@
+package apk
+
+import (
+	"encoding/binary"
+	"path/filepath"
+
+	apkVersion "github.com/knqyf263/go-apk-version"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// The adb format of apk v3 is a header of the magic and the schema, followed by the blocks aligned to 8 bytes.
+// The first block is the database, whose values are 32 bits with the type in the upper 4 bits and the value or
+// the offset from the start of the block in the rest. See src/adb.h and src/apk_adb.h of apk-tools.
+var adbMagic = []byte("ADB.")
+
+const (
+	adbSchemaInstalledDB = 0x00626469 // "idb"
+
+	adbBlockADB      = 0
+	adbBlockSizeMask = 0x3fffffff
+
+	adbTypeMask   = 0xf0000000
+	adbValueMask  = 0x0fffffff
+	adbTypeBlob8  = 0x80000000
+	adbTypeBlob16 = 0x90000000
+	adbTypeBlob32 = 0xa0000000
+	adbTypeArray  = 0xd0000000
+	adbTypeObject = 0xe0000000
+
+	adbHeaderSize = 8 // the compat version, the version, reserved and the root
+	adbRootOffset = 4
+
+	// the fields of the installed database
+	adbIDBPackages = 0x01
+
+	// the fields of the package
+	adbPkgInfo  = 0x01
+	adbPkgPaths = 0x02
+
+	// the fields of the package info
+	adbPIName    = 0x01
+	adbPIVersion = 0x02
+	adbPILicense = 0x06
+	adbPIOrigin  = 0x07
+
+	// the fields of the directory and the file
+	adbDIName  = 0x01
+	adbDIFiles = 0x03
+	adbFIName  = 0x01
+)
+
+// parseInstalledADB parses the installed database in the adb format of apk v3
+func parseInstalledADB(b []byte) ([]types.Package, []string, error) {
+	if len(b) < 8 || binary.LittleEndian.Uint32(b[4:8]) != adbSchemaInstalledDB {
+		return nil, nil, xerrors.New("not the installed database")
+	}
+	if len(b) < 12 {
+		return nil, nil, xerrors.New("no block")
+	}
+	typeSize := binary.LittleEndian.Uint32(b[8:12])
+	size := int(typeSize & adbBlockSizeMask)
+	if typeSize>>30 != adbBlockADB || size < 4+adbHeaderSize || 8+size > len(b) {
+		return nil, nil, xerrors.New("invalid database block")
+	}
+	db := adb(b[12 : 8+size])
+
+	root, err := db.object(binary.LittleEndian.Uint32(db[adbRootOffset:]))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("invalid root: %w", err)
+	}
+	packages, err := db.object(root.field(adbIDBPackages))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("invalid packages: %w", err)
+	}
+
+	var pkgs []types.Package
+	var installedFiles []string
+	for i := 1; i < len(packages); i++ {
+		p, err := db.object(packages[i])
+		if err != nil {
+			return nil, nil, xerrors.Errorf("invalid package: %w", err)
+		}
+		info, err := db.object(p.field(adbPkgInfo))
+		if err != nil {
+			return nil, nil, xerrors.Errorf("invalid package info: %w", err)
+		}
+
+		var pkg types.Package
+		if pkg.Name, err = db.string(info.field(adbPIName)); err != nil {
+			return nil, nil, xerrors.Errorf("invalid package name: %w", err)
+		}
+		version, err := db.string(info.field(adbPIVersion))
+		if err != nil {
+			return nil, nil, xerrors.Errorf("invalid package version: %w", err)
+		}
+		if apkVersion.Valid(version) {
+			pkg.Version = version
+		} else {
+			log.Logger.Debugf("Invalid version found: OS alpine, package %s, version %s", pkg.Name, version)
+		}
+		if pkg.License, err = db.string(info.field(adbPILicense)); err != nil {
+			return nil, nil, xerrors.Errorf("invalid package license: %w", err)
+		}
+		if pkg.SrcName, err = db.string(info.field(adbPIOrigin)); err != nil {
+			return nil, nil, xerrors.Errorf("invalid package origin: %w", err)
+		}
+		if pkg.SrcName != "" {
+			pkg.SrcVersion = version
+		}
+		if !pkg.Empty() {
+			pkgs = append(pkgs, pkg)
+		}
+
+		files, err := db.files(p.field(adbPkgPaths))
+		if err != nil {
+			return nil, nil, xerrors.Errorf("invalid paths of %s: %w", pkg.Name, err)
+		}
+		installedFiles = append(installedFiles, files...)
+	}
+	return pkgs, installedFiles, nil
+}
+
+// adb is the database block of the adb format
+type adb []byte
+
+// adbObject is an object or an array, whose first value is the number of the values including itself.
+// The missing fields are zero, i.e. null.
+type adbObject []uint32
+
+func (o adbObject) field(i int) uint32 {
+	if i >= len(o) {
+		return 0
+	}
+	return o[i]
+}
+
+func (db adb) deref(offset, size int) ([]byte, error) {
+	if offset < 0 || size < 0 || offset+size > len(db) {
+		return nil, xerrors.Errorf("out of the block: offset %d, size %d", offset, size)
+	}
+	return db[offset : offset+size], nil
+}
+
+// object returns the object or the array of the value, and nil for null
+func (db adb) object(v uint32) (adbObject, error) {
+	if v == 0 {
+		return nil, nil
+	}
+	if t := v & adbTypeMask; t != adbTypeObject && t != adbTypeArray {
+		return nil, xerrors.Errorf("not an object: %08x", v)
+	}
+	offset := int(v & adbValueMask)
+	b, err := db.deref(offset, 4)
+	if err != nil {
+		return nil, err
+	}
+	num := int(binary.LittleEndian.Uint32(b))
+	if num < 1 {
+		return nil, xerrors.Errorf("invalid number of the values: %d", num)
+	}
+	if b, err = db.deref(offset, 4*num); err != nil {
+		return nil, err
+	}
+	o := make(adbObject, num)
+	for i := range o {
+		o[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return o, nil
+}
+
+// string returns the blob of the value, and empty for null
+func (db adb) string(v uint32) (string, error) {
+	offset := int(v & adbValueMask)
+	var lenSize int
+	switch v & adbTypeMask {
+	case 0:
+		if v == 0 {
+			return "", nil
+		}
+		return "", xerrors.Errorf("not a blob: %08x", v)
+	case adbTypeBlob8:
+		lenSize = 1
+	case adbTypeBlob16:
+		lenSize = 2
+	case adbTypeBlob32:
+		lenSize = 4
+	default:
+		return "", xerrors.Errorf("not a blob: %08x", v)
+	}
+
+	b, err := db.deref(offset, lenSize)
+	if err != nil {
+		return "", err
+	}
+	var n int
+	switch lenSize {
+	case 1:
+		n = int(b[0])
+	case 2:
+		n = int(binary.LittleEndian.Uint16(b))
+	default:
+		n = int(binary.LittleEndian.Uint32(b))
+	}
+	if b, err = db.deref(offset+lenSize, n); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// files returns the installed files in the paths of the package, which are the directories with the files
+func (db adb) files(v uint32) ([]string, error) {
+	dirs, err := db.object(v)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for i := 1; i < len(dirs); i++ {
+		dir, err := db.object(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		dirName, err := db.string(dir.field(adbDIName))
+		if err != nil {
+			return nil, err
+		}
+		fileList, err := db.object(dir.field(adbDIFiles))
+		if err != nil {
+			return nil, err
+		}
+		for j := 1; j < len(fileList); j++ {
+			file, err := db.object(fileList[j])
+			if err != nil {
+				return nil, err
+			}
+			fileName, err := db.string(file.field(adbFIName))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, filepath.Join(dirName, fileName))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,140 @@
+// Package apk parses the installed database of apk, in both the text format and the adb format of apk v3.
+package apk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	apkVersion "github.com/knqyf263/go-apk-version"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	// The analyzer of fanal is registered first, so that the one of the same type here replaces it
+	_ "github.com/aquasecurity/fanal/analyzer/pkg/apk"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&alpinePkgAnalyzer{})
+}
+
+// The version is greater than that of fanal, so that the layers analyzed by fanal are analyzed again
+const version = 2
+
+var requiredFiles = []string{"lib/apk/db/installed"}
+
+// alpinePkgAnalyzer replaces the analyzer of fanal, which reads only the text format of the installed database
+type alpinePkgAnalyzer struct{}
+
+func (a alpinePkgAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	r := bufio.NewReader(input.Content)
+	magic, err := r.Peek(len(adbMagic))
+	if err != nil && err != io.EOF {
+		return nil, xerrors.Errorf("unable to read the installed database: %w", err)
+	}
+
+	var pkgs []types.Package
+	var installedFiles []string
+	if bytes.Equal(magic, adbMagic) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, xerrors.Errorf("unable to read the installed database: %w", err)
+		}
+		if pkgs, installedFiles, err = parseInstalledADB(b); err != nil {
+			return nil, xerrors.Errorf("unable to parse the installed database of apk v3: %w", err)
+		}
+	} else {
+		pkgs, installedFiles = parseInstalled(bufio.NewScanner(r))
+	}
+
+	return &analyzer.AnalysisResult{
+		PackageInfos: []types.PackageInfo{
+			{
+				FilePath: input.FilePath,
+				Packages: uniquePkgs(pkgs),
+			},
+		},
+		SystemInstalledFiles: installedFiles,
+	}, nil
+}
+
+func (a alpinePkgAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return slices.Contains(requiredFiles, filePath)
+}
+
+func (a alpinePkgAnalyzer) Type() analyzer.Type {
+	return analyzer.TypeApk
+}
+
+func (a alpinePkgAnalyzer) Version() int {
+	return version
+}
+
+// parseInstalled parses the text format of the installed database in the same way as fanal
+func parseInstalled(scanner *bufio.Scanner) ([]types.Package, []string) {
+	var (
+		pkgs           []types.Package
+		pkg            types.Package
+		version        string
+		dir            string
+		installedFiles []string
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// check package if paragraph end
+		if len(line) < 2 {
+			if !pkg.Empty() {
+				pkgs = append(pkgs, pkg)
+			}
+			pkg = types.Package{}
+			continue
+		}
+
+		switch line[:2] {
+		case "P:":
+			pkg.Name = line[2:]
+		case "V:":
+			version = line[2:]
+			if !apkVersion.Valid(version) {
+				log.Logger.Debugf("Invalid version found: OS alpine, package %s, version %s", pkg.Name, version)
+				continue
+			}
+			pkg.Version = version
+		case "o:":
+			pkg.SrcName = line[2:]
+			pkg.SrcVersion = version
+		case "L:":
+			pkg.License = line[2:]
+		case "F:":
+			dir = line[2:]
+		case "R:":
+			installedFiles = append(installedFiles, filepath.Join(dir, line[2:]))
+		}
+	}
+	// in case of last paragraph
+	if !pkg.Empty() {
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, installedFiles
+}
+
+func uniquePkgs(pkgs []types.Package) []types.Package {
+	var uniqPkgs []types.Package
+	uniq := map[string]struct{}{}
+	for _, pkg := range pkgs {
+		if _, ok := uniq[pkg.Name]; ok {
+			continue
+		}
+		uniqPkgs = append(uniqPkgs, pkg)
+		uniq[pkg.Name] = struct{}{}
+	}
+	return uniqPkgs
+}
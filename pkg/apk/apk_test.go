@@ -0,0 +1,84 @@
+package apk
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+)
+
+func Test_alpinePkgAnalyzer_Analyze(t *testing.T) {
+	tests := []struct {
+		name      string
+		inputFile string
+		wantPkgs  []types.Package
+		wantFiles []string
+		wantErr   string
+	}{
+		{
+			name:      "apk v3",
+			inputFile: "testdata/installed.adb",
+			wantPkgs: []types.Package{
+				{Name: "musl", Version: "1.2.3-r0", SrcName: "musl", SrcVersion: "1.2.3-r0", License: "MIT"},
+				{Name: "busybox", Version: "1.35.0-r13", SrcName: "busybox", SrcVersion: "1.35.0-r13", License: "GPL-2.0-only"},
+				{Name: "libcrypto3", Version: "3.0.3-r0", SrcName: "openssl", SrcVersion: "3.0.3-r0", License: "Apache-2.0"},
+			},
+			wantFiles: []string{
+				"lib/ld-musl-x86_64.so.1",
+				"lib/libc.musl-x86_64.so.1",
+				"bin/busybox",
+				"etc/securetty",
+				"lib/libcrypto.so.3",
+			},
+		},
+		{
+			name:      "text",
+			inputFile: "testdata/installed",
+			wantPkgs: []types.Package{
+				{Name: "musl", Version: "1.2.3-r0", SrcName: "musl", SrcVersion: "1.2.3-r0", License: "MIT"},
+				{Name: "libcrypto3", Version: "3.0.3-r0", SrcName: "openssl", SrcVersion: "3.0.3-r0", License: "Apache-2.0"},
+			},
+			wantFiles: []string{
+				"lib/ld-musl-x86_64.so.1",
+				"lib/libc.musl-x86_64.so.1",
+				"lib/libcrypto.so.3",
+			},
+		},
+		{
+			name:      "truncated apk v3",
+			inputFile: "testdata/truncated.adb",
+			wantErr:   "invalid database block",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.inputFile)
+			require.NoError(t, err)
+			defer f.Close()
+
+			got, err := alpinePkgAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: "lib/apk/db/installed",
+				Content:  f,
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &analyzer.AnalysisResult{
+				PackageInfos: []types.PackageInfo{
+					{
+						FilePath: "lib/apk/db/installed",
+						Packages: tt.wantPkgs,
+					},
+				},
+				SystemInstalledFiles: tt.wantFiles,
+			}, got)
+		})
+	}
+}
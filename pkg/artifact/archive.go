@@ -0,0 +1,111 @@
+package artifact
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/walker"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// archiveExtensions are the extensions of the zip archives unpacked with '--max-archive-depth'.
+// JAR, WAR, EAR and egg files are not included, since their analyzers unpack them by themselves.
+var archiveExtensions = []string{".zip", ".whl"}
+
+// archiveWalker passes the files in the archives to the analyzers as if they were "<archive path>/<file name>",
+// unpacking the archives in the archives up to the depth.
+// The unpacked archives are hashed into the result, e.g. the wheels whose packages are found in them.
+type archiveWalker struct {
+	maxDepth int
+	mem      *memoryLimit
+	tempDir  string
+	hasher   fileHasher
+	result   *analyzer.AnalysisResult
+}
+
+func newArchiveWalker(maxDepth int, mem *memoryLimit, hasher fileHasher, result *analyzer.AnalysisResult) archiveWalker {
+	return archiveWalker{
+		maxDepth: maxDepth,
+		mem:      mem,
+		hasher:   hasher,
+		result:   result,
+	}
+}
+
+// withTempDir returns the walker writing the temp files to the directory
+func (w archiveWalker) withTempDir(dir string) archiveWalker {
+	w.tempDir = dir
+	return w
+}
+
+// walk calls analyzeFn for the file, and for the files in it if it is an archive
+func (w archiveWalker) walk(filePath string, info os.FileInfo, opener analyzer.Opener, analyzeFn walker.WalkFunc) error {
+	return w.walkDepth(filePath, info, opener, 0, analyzeFn)
+}
+
+func (w archiveWalker) walkDepth(filePath string, info os.FileInfo, opener analyzer.Opener, depth int,
+	analyzeFn walker.WalkFunc) error {
+	if err := analyzeFn(filePath, info, opener); err != nil {
+		return err
+	}
+	if depth >= w.maxDepth || info.IsDir() || !isArchive(filePath) {
+		return nil
+	}
+
+	rc, err := opener()
+	if err != nil {
+		return xerrors.Errorf("unable to open %s: %w", filePath, err)
+	}
+	defer rc.Close()
+
+	zr, err := zip.NewReader(rc, info.Size())
+	if err != nil {
+		// The file might not be a zip archive in spite of the extension
+		log.Logger.Debugf("Unable to unpack %s: %s", filePath, err)
+		return nil
+	}
+
+	for _, f := range zr.File {
+		fi := f.FileInfo()
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		// The entries can't point outside the archive, e.g. "../../etc/passwd"
+		entryPath := filepath.Join(filePath, filepath.Clean("/"+filepath.FromSlash(f.Name)))
+		if err = w.walkEntry(entryPath, f, depth+1, analyzeFn); err != nil {
+			return xerrors.Errorf("failed to analyze %s: %w", entryPath, err)
+		}
+	}
+
+	if _, err = rc.Seek(0, io.SeekStart); err != nil {
+		return xerrors.Errorf("unable to seek %s: %w", filePath, err)
+	}
+	w.result.Merge(w.hasher.hash(filePath, rc))
+	return nil
+}
+
+func (w archiveWalker) walkEntry(entryPath string, f *zip.File, depth int, analyzeFn walker.WalkFunc) error {
+	r, err := f.Open()
+	if err != nil {
+		log.Logger.Debugf("Unable to unpack %s: %s", entryPath, err)
+		return nil
+	}
+	defer r.Close()
+
+	// The entry is read at most once and shared by the analyzers as the files in layers are
+	tf := newTarFile(int64(f.UncompressedSize64), r, w.mem, w.tempDir)
+	defer tf.clean()
+
+	return w.walkDepth(entryPath, f.FileInfo(), tf.Open, depth, analyzeFn)
+}
+
+func isArchive(filePath string) bool {
+	return slices.Contains(archiveExtensions, strings.ToLower(filepath.Ext(filePath)))
+}
@@ -0,0 +1,134 @@
+package artifact
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveWalker_walk(t *testing.T) {
+	wheel := testZip(t, map[string][]byte{
+		"requests-2.0.0.dist-info/METADATA": []byte("Name: requests"),
+	})
+	dist := testZip(t, map[string][]byte{
+		"libs/requests-2.0.0-py3-none-any.whl": wheel,
+		"../../etc/passwd":                     []byte("root"),
+		"README.md":                            []byte("readme"),
+	})
+	filePath := filepath.Join(t.TempDir(), "dist.zip")
+	require.NoError(t, os.WriteFile(filePath, dist, 0600))
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     map[string]string
+	}{
+		{
+			name: "disabled",
+			want: map[string]string{
+				"dist.zip": string(dist),
+			},
+		},
+		{
+			name:     "depth 1",
+			maxDepth: 1,
+			want: map[string]string{
+				"dist.zip":            string(dist),
+				"dist.zip/etc/passwd": "root",
+				"dist.zip/README.md":  "readme",
+				"dist.zip/libs/requests-2.0.0-py3-none-any.whl": string(wheel),
+			},
+		},
+		{
+			name:     "depth 2",
+			maxDepth: 2,
+			want: map[string]string{
+				"dist.zip":            string(dist),
+				"dist.zip/etc/passwd": "root",
+				"dist.zip/README.md":  "readme",
+				"dist.zip/libs/requests-2.0.0-py3-none-any.whl":                                   string(wheel),
+				"dist.zip/libs/requests-2.0.0-py3-none-any.whl/requests-2.0.0.dist-info/METADATA": "Name: requests",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := archiveWalker{maxDepth: tt.maxDepth}
+			opener := func() (dio.ReadSeekCloserAt, error) {
+				return os.Open(filePath)
+			}
+
+			got := map[string]string{}
+			err := w.walk(filePath, info, opener, func(path string, _ os.FileInfo, opener analyzer.Opener) error {
+				rc, err := opener()
+				require.NoError(t, err)
+				defer rc.Close()
+				b, err := io.ReadAll(rc)
+				require.NoError(t, err)
+
+				rel, err := filepath.Rel(filepath.Dir(filePath), path)
+				require.NoError(t, err)
+				got[rel] = string(b)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestArchiveWalker_walk_hashes(t *testing.T) {
+	wheel := testZip(t, map[string][]byte{
+		"requests-2.0.0.dist-info/METADATA": []byte("Name: requests"),
+	})
+	filePath := filepath.Join(t.TempDir(), "requests-2.0.0-py3-none-any.whl")
+	require.NoError(t, os.WriteFile(filePath, wheel, 0600))
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+
+	result := analyzer.NewAnalysisResult()
+	w := newArchiveWalker(1, nil, fileHasher{algorithms: []string{"sha256"}}, result)
+	opener := func() (dio.ReadSeekCloserAt, error) {
+		return os.Open(filePath)
+	}
+	err = w.walk(filePath, info, opener, func(string, os.FileInfo, analyzer.Opener) error { return nil })
+	require.NoError(t, err)
+
+	// The wheel is hashed as the package file of the packages found in it
+	sum := sha256.Sum256(wheel)
+	assert.Equal(t, []types.CustomResource{
+		{
+			Type:     pkgtypes.PackageHashType,
+			FilePath: filePath,
+			Data:     []string{"sha256:" + hex.EncodeToString(sum[:])},
+		},
+	}, result.CustomResources)
+}
@@ -0,0 +1,219 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	dtypes "github.com/docker/docker/api/types"
+	dimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/image/daemon"
+)
+
+// DockerDaemon is the Docker daemon to read the images and the containers from, given by '--docker-host' and
+// '--docker-cert-path'. The environment variables of the Docker CLI, e.g. DOCKER_HOST, are used when they are empty.
+type DockerDaemon struct {
+	// Host is the address of the daemon, e.g. tcp://build-host:2376
+	Host string
+
+	// CertPath is the directory of ca.pem, cert.pem and key.pem to connect to the daemon with TLS.
+	// The certificate of the daemon is always verified with ca.pem.
+	CertPath string
+}
+
+// Client returns the client of the daemon
+func (d DockerDaemon) Client() (*client.Client, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if d.CertPath != "" {
+		opts = append(opts, client.WithTLSClientConfig(filepath.Join(d.CertPath, "ca.pem"),
+			filepath.Join(d.CertPath, "cert.pem"), filepath.Join(d.CertPath, "key.pem")))
+	}
+	// The host is applied after the TLS config, so that the transport with the certificates connects to it
+	if d.Host != "" {
+		opts = append(opts, client.WithHost(d.Host))
+	}
+	c, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to initialize a docker client: %w", err)
+	}
+	return c, nil
+}
+
+// Image returns the image in the daemon, which is exported to a temporary file in tempDir when the layers are read.
+// The caller must call cleanup() to remove the temporary file.
+func (d DockerDaemon) Image(ctx context.Context, ref name.Reference, tempDir string) (daemon.Image, func(), error) {
+	c, err := d.Client()
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	// <image_name>:<tag>, <image_name>@<digest> or <image_id>
+	imageID := ref.Name()
+	inspect, _, err := c.ImageInspectWithRaw(ctx, imageID)
+	if err != nil {
+		imageID = ref.String()
+		if inspect, _, err = c.ImageInspectWithRaw(ctx, imageID); err != nil {
+			_ = c.Close()
+			return nil, func() {}, xerrors.Errorf("unable to inspect the image (%s): %w", imageID, err)
+		}
+	}
+
+	history, err := c.ImageHistory(ctx, imageID)
+	if err != nil {
+		_ = c.Close()
+		return nil, func() {}, xerrors.Errorf("unable to get history (%s): %w", imageID, err)
+	}
+
+	f, err := os.CreateTemp(tempDir, "trivy-image-*")
+	if err != nil {
+		_ = c.Close()
+		return nil, func() {}, xerrors.Errorf("failed to create a temporary file: %w", err)
+	}
+
+	img := &clientImage{
+		client:  c,
+		imageID: imageID,
+		file:    f,
+		inspect: inspect,
+		history: history,
+	}
+	return img, func() {
+		_ = c.Close()
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}, nil
+}
+
+// clientImage is the image read by the client of the daemon in the same way as the daemon image of fanal.
+// The config is made from the inspection, and the image is exported to the temporary file only when
+// the layers are read, so that the cached images are not exported.
+type clientImage struct {
+	v1.Image
+	client  *client.Client
+	imageID string
+	file    *os.File
+	inspect dtypes.ImageInspect
+	history []dimage.HistoryResponseItem
+
+	once sync.Once
+	err  error
+}
+
+// open exports the image to the temporary file once, even if the layers are read concurrently
+func (img *clientImage) open() error {
+	img.once.Do(func() {
+		rc, err := img.client.ImageSave(context.Background(), []string{img.imageID})
+		if err != nil {
+			img.err = xerrors.Errorf("unable to export the image: %w", err)
+			return
+		}
+		defer rc.Close()
+
+		if _, err = io.Copy(img.file, rc); err != nil {
+			img.err = xerrors.Errorf("failed to copy the image: %w", err)
+			return
+		}
+		if img.Image, err = tarball.ImageFromPath(img.file.Name(), nil); err != nil {
+			img.err = xerrors.Errorf("failed to initialize the image from the temporary file: %w", err)
+		}
+	})
+	return img.err
+}
+
+func (img *clientImage) ConfigName() (v1.Hash, error) {
+	return v1.NewHash(img.inspect.ID)
+}
+
+func (img *clientImage) ConfigFile() (*v1.ConfigFile, error) {
+	if len(img.inspect.RootFS.Layers) == 0 {
+		if err := img.open(); err != nil {
+			return nil, err
+		}
+		return img.Image.ConfigFile()
+	}
+
+	var diffIDs []v1.Hash
+	for _, l := range img.inspect.RootFS.Layers {
+		h, err := v1.NewHash(l)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid hash %s: %w", l, err)
+		}
+		diffIDs = append(diffIDs, h)
+	}
+
+	created, err := time.Parse(time.RFC3339Nano, img.inspect.Created)
+	if err != nil {
+		return nil, xerrors.Errorf("failed parsing created %s: %w", img.inspect.Created, err)
+	}
+
+	// The container config of the daemon has the same JSON fields as the image config
+	var config v1.Config
+	if img.inspect.Config != nil {
+		b, err := json.Marshal(img.inspect.Config)
+		if err != nil {
+			return nil, xerrors.Errorf("json marshal error: %w", err)
+		}
+		if err = json.Unmarshal(b, &config); err != nil {
+			return nil, xerrors.Errorf("json unmarshal error: %w", err)
+		}
+	}
+
+	// The history of the daemon is the newest first
+	var history []v1.History
+	for i := len(img.history) - 1; i >= 0; i-- {
+		h := img.history[i]
+		history = append(history, v1.History{
+			Created:    v1.Time{Time: time.Unix(h.Created, 0).UTC()},
+			CreatedBy:  h.CreatedBy,
+			Comment:    h.Comment,
+			EmptyLayer: h.Size == 0,
+		})
+	}
+
+	return &v1.ConfigFile{
+		Architecture:  img.inspect.Architecture,
+		Author:        img.inspect.Author,
+		Container:     img.inspect.Container,
+		Created:       v1.Time{Time: created},
+		DockerVersion: img.inspect.DockerVersion,
+		Config:        config,
+		History:       history,
+		OS:            img.inspect.Os,
+		RootFS: v1.RootFS{
+			Type:    img.inspect.RootFS.Type,
+			DiffIDs: diffIDs,
+		},
+	}, nil
+}
+
+func (img *clientImage) LayerByDiffID(h v1.Hash) (v1.Layer, error) {
+	if err := img.open(); err != nil {
+		return nil, err
+	}
+	return img.Image.LayerByDiffID(h)
+}
+
+func (img *clientImage) RawConfigFile() ([]byte, error) {
+	if err := img.open(); err != nil {
+		return nil, err
+	}
+	return img.Image.RawConfigFile()
+}
+
+func (img *clientImage) RepoTags() []string {
+	return img.inspect.RepoTags
+}
+
+func (img *clientImage) RepoDigests() []string {
+	return img.inspect.RepoDigests
+}
@@ -0,0 +1,87 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dtypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	dimage "github.com/docker/docker/api/types/image"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerDaemon_Image(t *testing.T) {
+	// The daemon listens on the unix socket given by the host instead of DOCKER_HOST
+	t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:1")
+	sock := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.41")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			_, _ = w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/images/alpine:3.15/json"):
+			_ = json.NewEncoder(w).Encode(dtypes.ImageInspect{
+				ID:           "sha256:c059bfaa849c4d8e4aecaeb3a10c2d9b3d85f5165c66ad3a4d937758128c4d18",
+				RepoTags:     []string{"alpine:3.15"},
+				Created:      "2022-04-05T00:19:59.790636867Z",
+				Architecture: "amd64",
+				Os:           "linux",
+				Config:       &container.Config{Env: []string{"PATH=/bin"}, Cmd: []string{"/bin/sh"}},
+				RootFS: dtypes.RootFS{
+					Type:   "layers",
+					Layers: []string{"sha256:4fc242d58285699eca05db3cc7c7122a2b8e014d9481f323bd9277baacfa0628"},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, "/images/alpine:3.15/history"):
+			_ = json.NewEncoder(w).Encode([]dimage.HistoryResponseItem{
+				{Created: 1649117999, CreatedBy: `/bin/sh -c #(nop)  CMD ["/bin/sh"]`},
+				{Created: 1649117999, CreatedBy: "/bin/sh -c #(nop) ADD file:5d673d25da3a14ce1 in / ", Size: 5585386},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ts.Listener = l
+	ts.Start()
+	defer ts.Close()
+
+	ref, err := name.ParseReference("alpine:3.15")
+	require.NoError(t, err)
+
+	img, cleanup, err := DockerDaemon{Host: "unix://" + sock}.Image(context.Background(), ref, t.TempDir())
+	require.NoError(t, err)
+	defer cleanup()
+
+	assert.Equal(t, []string{"alpine:3.15"}, img.RepoTags())
+	configName, err := img.ConfigName()
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:c059bfaa849c4d8e4aecaeb3a10c2d9b3d85f5165c66ad3a4d937758128c4d18", configName.String())
+
+	// The config is made from the inspection without exporting the image
+	config, err := img.ConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, "amd64", config.Architecture)
+	assert.Equal(t, []string{"PATH=/bin"}, config.Config.Env)
+	assert.Equal(t, []string{"/bin/sh"}, config.Config.Cmd)
+	require.Len(t, config.RootFS.DiffIDs, 1)
+	assert.Equal(t, "sha256:4fc242d58285699eca05db3cc7c7122a2b8e014d9481f323bd9277baacfa0628", config.RootFS.DiffIDs[0].String())
+	require.Len(t, config.History, 2)
+	assert.Contains(t, config.History[0].CreatedBy, "ADD file")
+	assert.True(t, config.History[1].EmptyLayer)
+}
+
+func TestDockerDaemon_Client(t *testing.T) {
+	_, err := DockerDaemon{Host: "tcp://build-host:2376", CertPath: t.TempDir()}.Client()
+	assert.ErrorContains(t, err, "failed to initialize a docker client")
+}
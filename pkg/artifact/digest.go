@@ -0,0 +1,135 @@
+package artifact
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // nolint: gosec
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&apkDigestAnalyzer{})
+	analyzer.RegisterAnalyzer(&jarDigestAnalyzer{})
+}
+
+const (
+	// TypeApkDigest records the checksums of the Alpine packages in the package database
+	TypeApkDigest = analyzer.Type("apk-digest")
+
+	// TypeJarDigest records the SHA-1 digests of the JAR files
+	TypeJarDigest = analyzer.Type("jar-digest")
+
+	digestVersion = 1
+)
+
+// TypeDigests is the analyzers recording the digests of the packages listed with '--list-all-pkgs'
+var TypeDigests = []analyzer.Type{TypeApkDigest, TypeJarDigest}
+
+// apkDigestAnalyzer records the checksums of the packages in "lib/apk/db/installed", which fanal doesn't keep
+type apkDigestAnalyzer struct{}
+
+func (a apkDigestAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	digests := map[string]interface{}{}
+
+	var name, checksum string
+	scanner := bufio.NewScanner(input.Content)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 2 {
+			if digest := apkDigest(checksum); name != "" && digest != "" {
+				digests[name] = digest
+			}
+			name, checksum = "", ""
+			continue
+		}
+		switch line[:2] {
+		case "P:":
+			name = line[2:]
+		case "C:":
+			checksum = line[2:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("apk database scan error: %w", err)
+	}
+	if digest := apkDigest(checksum); name != "" && digest != "" {
+		digests[name] = digest
+	}
+
+	return digestResult(input.FilePath, digests), nil
+}
+
+// apkDigest converts the checksum in the package database to the digest.
+// "Q1" is followed by the base64-encoded SHA-1, otherwise the checksum is MD5 in hex.
+func apkDigest(checksum string) string {
+	if s := strings.TrimPrefix(checksum, "Q1"); s != checksum {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return ""
+		}
+		return "sha1:" + hex.EncodeToString(b)
+	} else if checksum != "" {
+		return "md5:" + checksum
+	}
+	return ""
+}
+
+func (a apkDigestAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filePath == "lib/apk/db/installed"
+}
+
+func (a apkDigestAnalyzer) Type() analyzer.Type {
+	return TypeApkDigest
+}
+
+func (a apkDigestAnalyzer) Version() int {
+	return digestVersion
+}
+
+// jarDigestAnalyzer records the SHA-1 digests of the JAR files, which Maven Central looks up the artifacts by
+type jarDigestAnalyzer struct{}
+
+func (a jarDigestAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	h := sha1.New() // nolint: gosec
+	if _, err := io.Copy(h, input.Content); err != nil {
+		return nil, xerrors.Errorf("jar read error: %w", err)
+	}
+	return digestResult(input.FilePath, "sha1:"+hex.EncodeToString(h.Sum(nil))), nil
+}
+
+func (a jarDigestAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return slices.Contains([]string{".jar", ".war", ".ear", ".par"}, ext)
+}
+
+func (a jarDigestAnalyzer) Type() analyzer.Type {
+	return TypeJarDigest
+}
+
+func (a jarDigestAnalyzer) Version() int {
+	return digestVersion
+}
+
+func digestResult(filePath string, data interface{}) *analyzer.AnalysisResult {
+	return &analyzer.AnalysisResult{
+		CustomResources: []types.CustomResource{
+			{
+				Type:     pkgtypes.PackageDigestType,
+				FilePath: filePath,
+				Data:     data,
+			},
+		},
+	}
+}
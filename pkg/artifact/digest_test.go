@@ -0,0 +1,69 @@
+package artifact
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestDigestAnalyzers(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer interface {
+			Analyze(context.Context, analyzer.AnalysisInput) (*analyzer.AnalysisResult, error)
+		}
+		filePath string
+		content  string
+		want     interface{}
+	}{
+		{
+			name:     "apk database",
+			analyzer: apkDigestAnalyzer{},
+			filePath: "lib/apk/db/installed",
+			content: `C:Q1LxBvJ/ofKKik4CrrENc7ePnJsko=
+P:busybox
+V:1.34.1-r5
+
+C:483bd5d0d380e8ba6a8dd8f2e4fd8c4a
+P:musl
+V:1.2.2-r7
+
+P:no-checksum
+V:1.0`,
+			want: map[string]interface{}{
+				"busybox": "sha1:2f106f27fa1f28a8a4e02aeb10d73b78f9c9b24a",
+				"musl":    "md5:483bd5d0d380e8ba6a8dd8f2e4fd8c4a",
+			},
+		},
+		{
+			name:     "jar",
+			analyzer: jarDigestAnalyzer{},
+			filePath: "app/log4j-api-2.17.1.jar",
+			content:  "PK\x03\x04",
+			want:     "sha1:fb467bb25be45fcf0c84c03ce5801abd5a28c1fd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.analyzer.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: tt.filePath,
+				Content:  strings.NewReader(tt.content),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []types.CustomResource{
+				{
+					Type:     pkgtypes.PackageDigestType,
+					FilePath: tt.filePath,
+					Data:     tt.want,
+				},
+			}, got.CustomResources)
+		})
+	}
+}
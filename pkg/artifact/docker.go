@@ -0,0 +1,170 @@
+package artifact
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/image"
+	"github.com/aquasecurity/fanal/image/daemon"
+	"github.com/aquasecurity/fanal/image/token"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+// NewRegistryTransport returns the transport pulling images from registries, verified with the CA certificates,
+// or not verified if insecure is true. It honors HTTP(S)_PROXY and NO_PROXY as the default transport of
+// go-containerregistry does.
+func NewRegistryTransport(rootCAs *x509.CertPool, insecure bool) *http.Transport {
+	t := remote.DefaultTransport.Clone()
+	t.TLSClientConfig = utils.TLSClientConfig(t.TLSClientConfig, rootCAs, insecure)
+	return t
+}
+
+// NewDockerImage opens the image in the Docker Engine, Podman or the registry as fanal does,
+// but reads the image from the Docker daemon of the option into the temp directory of the option,
+// and pulls the image with the registry transport of the option, e.g. verified with the given CA certificates.
+func NewDockerImage(ctx context.Context, imageName string, dockerOpt types.DockerOption, opt Option) (types.Image, func(), error) {
+	var nameOpts []name.Option
+	if dockerOpt.NonSSL {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	ref, err := name.ParseReference(imageName, nameOpts...)
+	if err != nil {
+		return nil, func() {}, xerrors.Errorf("failed to parse the image name: %w", err)
+	}
+
+	var errs error
+	img, cleanup, err := opt.Docker.Image(ctx, ref, opt.TempDir)
+	if err == nil {
+		return daemonImage{Image: img, name: imageName}, cleanup, nil
+	}
+	errs = multierror.Append(errs, err)
+
+	img, cleanup, err = daemon.PodmanImage(imageName)
+	if err == nil {
+		return daemonImage{Image: img, name: imageName}, cleanup, nil
+	}
+	errs = multierror.Append(errs, err)
+
+	transport := opt.RegistryTransport
+	if transport == nil {
+		transport = remote.DefaultTransport
+	}
+	rimg, err := newRegistryImage(ctx, imageName, ref, dockerOpt, transport)
+	if err == nil {
+		return rimg, func() {}, nil
+	}
+	errs = multierror.Append(errs, err)
+	return nil, func() {}, errs
+}
+
+// daemonImage is the image in the Docker Engine or Podman
+type daemonImage struct {
+	daemon.Image
+	name string
+}
+
+func (img daemonImage) Name() string {
+	return img.name
+}
+
+func (img daemonImage) ID() (string, error) {
+	return image.ID(img)
+}
+
+func (img daemonImage) LayerIDs() ([]string, error) {
+	return image.LayerIDs(img)
+}
+
+// registryImage is the image pulled from the registry with the given transport
+type registryImage struct {
+	v1.Image
+	name   string
+	ref    name.Reference
+	digest v1.Hash
+}
+
+func newRegistryImage(ctx context.Context, imageName string, ref name.Reference, dockerOpt types.DockerOption,
+	transport http.RoundTripper) (registryImage, error) {
+	if t, ok := transport.(*http.Transport); ok && dockerOpt.InsecureSkipTLSVerify {
+		t = t.Clone()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true
+		transport = t
+	}
+	remoteOpts := []remote.Option{remote.WithContext(ctx), remote.WithTransport(transport)}
+
+	// The same credentials as fanal
+	auth := token.GetToken(ctx, ref.Context().RegistryStr(), dockerOpt)
+	switch {
+	case auth.Username != "" && auth.Password != "":
+		remoteOpts = append(remoteOpts, remote.WithAuth(&auth))
+	case dockerOpt.RegistryToken != "":
+		remoteOpts = append(remoteOpts, remote.WithAuth(&authn.Bearer{Token: dockerOpt.RegistryToken}))
+	default:
+		remoteOpts = append(remoteOpts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	}
+
+	desc, err := remote.Get(ref, remoteOpts...)
+	if err != nil {
+		return registryImage{}, err
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return registryImage{}, err
+	}
+	return registryImage{
+		Image:  img,
+		name:   imageName,
+		ref:    ref,
+		digest: desc.Digest,
+	}, nil
+}
+
+func (img registryImage) Name() string {
+	return img.name
+}
+
+func (img registryImage) ID() (string, error) {
+	return image.ID(img)
+}
+
+func (img registryImage) LayerIDs() ([]string, error) {
+	return image.LayerIDs(img)
+}
+
+// RepoTags returns the tag in the same format as fanal, e.g. "alpine:3.15" for Docker Hub
+func (img registryImage) RepoTags() []string {
+	tag, ok := img.ref.(name.Tag)
+	if !ok {
+		return []string{}
+	}
+	return []string{fmt.Sprintf("%s:%s", repositoryName(img.ref), tag.TagStr())}
+}
+
+func (img registryImage) RepoDigests() []string {
+	return []string{fmt.Sprintf("%s@%s", repositoryName(img.ref), img.digest.String())}
+}
+
+// repositoryName trims the default registry and namespace of Docker Hub as fanal does
+func repositoryName(ref name.Reference) string {
+	reg := ref.Context().RegistryStr()
+	repo := ref.Context().RepositoryStr()
+	if reg != name.DefaultRegistry {
+		return fmt.Sprintf("%s/%s", reg, repo)
+	}
+	return strings.TrimPrefix(repo, "library/")
+}
@@ -0,0 +1,190 @@
+package artifact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/config"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
+)
+
+const filePatternSeparator = ":"
+
+// patternFileNames has the file names expected by the analyzers which '--file-patterns' can be used with.
+// A file matching the pattern is passed to the analyzer as if it had the first name.
+var patternFileNames = map[analyzer.Type][]string{
+	analyzer.TypeBundler:    {types.GemfileLock},
+	analyzer.TypeCargo:      {types.CargoLock},
+	analyzer.TypeComposer:   {types.ComposerLock},
+	analyzer.TypeNpmPkgLock: {types.NpmPkgLock},
+	analyzer.TypeYarn:       {types.YarnLock},
+	nodejs.TypePnpm:         {nodejs.PnpmLock},
+	analyzer.TypeNuget:      {types.NuGetPkgsLock, types.NuGetPkgsConfig},
+	analyzer.TypePip:        {types.PipRequirements},
+	analyzer.TypePipenv:     {types.PipfileLock},
+	analyzer.TypePoetry:     {types.PoetryLock},
+	analyzer.TypeGoMod:      {types.GoMod, types.GoSum},
+}
+
+// configFileTypes are the file types whose patterns are handled by the config analyzers of fanal
+var configFileTypes = []string{types.Dockerfile, types.JSON, types.YAML}
+
+type filePattern struct {
+	analyzerType analyzer.Type
+	regexp       *regexp.Regexp
+}
+
+// filePatterns passes the files matching '--file-patterns' to the analyzers
+// even if the analyzers don't expect their names, e.g. "pip:requirements-.*\.txt".
+type filePatterns struct {
+	patterns []filePattern
+
+	// analyzerTypes has all the analyzers of the group so that only one of them can be run
+	analyzerTypes []analyzer.Type
+}
+
+// registerFilePatterns registers the config analyzers with the patterns of the config files
+// and returns the patterns of the other analyzers
+func registerFilePatterns(patterns []string) (filePatterns, error) {
+	var configPatterns []string
+	var fp filePatterns
+	for _, p := range patterns {
+		// e.g. "pip:requirements-.*\.txt"
+		s := strings.SplitN(p, filePatternSeparator, 2)
+		if len(s) != 2 {
+			return filePatterns{}, xerrors.Errorf("invalid file pattern (%s)", p)
+		}
+		fileType, pattern := s[0], s[1]
+
+		if slices.Contains(configFileTypes, fileType) {
+			configPatterns = append(configPatterns, p)
+			continue
+		}
+		if _, ok := patternFileNames[analyzer.Type(fileType)]; !ok {
+			return filePatterns{}, xerrors.Errorf("unknown file type: %s, pattern: %s (supported: %s)",
+				fileType, pattern, strings.Join(supportedFileTypes(), ", "))
+		}
+
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return filePatterns{}, xerrors.Errorf("invalid file regexp (%s): %w", p, err)
+		}
+		fp.patterns = append(fp.patterns, filePattern{
+			analyzerType: analyzer.Type(fileType),
+			regexp:       r,
+		})
+	}
+
+	if err := config.RegisterConfigAnalyzers(configPatterns); err != nil {
+		return filePatterns{}, xerrors.Errorf("config analyzer error: %w", err)
+	}
+	return fp, nil
+}
+
+func supportedFileTypes() []string {
+	fileTypes := slices.Clone(configFileTypes)
+	for t := range patternFileNames {
+		fileTypes = append(fileTypes, string(t))
+	}
+	sort.Strings(fileTypes)
+	return fileTypes
+}
+
+// withAnalyzers returns the patterns of the analyzers in the group
+func (p filePatterns) withAnalyzers(ag analyzer.AnalyzerGroup) filePatterns {
+	for t := range ag.AnalyzerVersions() {
+		p.analyzerTypes = append(p.analyzerTypes, analyzer.Type(t))
+	}
+	return p
+}
+
+// analyzerVersions adds the file patterns, the archive depth, the file timeout and the hash algorithms
+// to the analyzer versions so that the cache keys change with them
+func analyzerVersions(ag analyzer.AnalyzerGroup, opt Option) map[string]int {
+	versions := ag.AnalyzerVersions()
+	patterns := opt.MisconfScannerOption.FilePatterns
+	depth := opt.MaxArchiveDepth
+	timeout := opt.FileTimeout
+	hashAlgorithms := opt.HashAlgorithms
+	if len(patterns) == 0 && depth == 0 && timeout <= 0 && len(hashAlgorithms) == 0 && len(opt.AnalyzerTimeouts) == 0 {
+		return versions
+	}
+	versions = maps.Clone(versions)
+	for _, p := range patterns {
+		versions["file-pattern:"+p] = 0
+	}
+	if depth > 0 {
+		versions["max-archive-depth"] = depth
+	}
+	if timeout > 0 {
+		versions["file-timeout"] = int(timeout.Milliseconds())
+	}
+	for t, analyzerTimeout := range opt.AnalyzerTimeouts {
+		versions["analyzer-timeout:"+string(t)] = int(analyzerTimeout.Milliseconds())
+	}
+	for _, alg := range hashAlgorithms {
+		versions["file-hash:"+alg] = 0
+	}
+	return versions
+}
+
+// analyze passes the file to the analyzers whose patterns match the file path.
+// The file is analyzed as "<filePath>/<the expected name>" and then the file path in the result is restored,
+// so the analysis is finished before returning.
+func (p filePatterns) analyze(ctx context.Context, ag analyzer.AnalyzerGroup, limit *semaphore.Weighted,
+	result *analyzer.AnalysisResult, dir, filePath string, info os.FileInfo, opener analyzer.Opener,
+	disabled []analyzer.Type, opts analyzer.AnalysisOptions) error {
+	if info.IsDir() {
+		return nil
+	}
+
+	for _, fp := range p.patterns {
+		if !slices.Contains(p.analyzerTypes, fp.analyzerType) || slices.Contains(disabled, fp.analyzerType) {
+			continue
+		}
+		// The analyzer already analyzes the file
+		fileNames := patternFileNames[fp.analyzerType]
+		if slices.Contains(fileNames, filepath.Base(filePath)) || !fp.regexp.MatchString(filePath) {
+			continue
+		}
+		log.Logger.Debugf("%s matches the file pattern of %s", filePath, fp.analyzerType)
+
+		// Only the analyzer of the pattern is run
+		var others []analyzer.Type
+		for _, t := range p.analyzerTypes {
+			if t != fp.analyzerType {
+				others = append(others, t)
+			}
+		}
+
+		var wg sync.WaitGroup
+		r := analyzer.NewAnalysisResult()
+		aliasPath := filePath + "/" + fileNames[0]
+		err := ag.AnalyzeFile(ctx, &wg, limit, r, dir, aliasPath, info, opener, others, opts)
+		wg.Wait()
+		if err != nil {
+			return xerrors.Errorf("failed to analyze %s as %s: %w", filePath, fp.analyzerType, err)
+		}
+
+		for i := range r.Applications {
+			if r.Applications[i].FilePath == aliasPath {
+				r.Applications[i].FilePath = filePath
+			}
+		}
+		result.Merge(r)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package artifact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/config"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+
+	_ "github.com/aquasecurity/fanal/analyzer/language/python/pip"
+	_ "github.com/aquasecurity/fanal/analyzer/language/python/pipenv"
+)
+
+func TestFilesystemArtifact_Inspect_filePatterns(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"requirements.txt":      "flask==0.12\n",
+		"requirements-prod.txt": "Jinja2==2.10\n",
+		"Pipfile.custom.lock":   `{"_meta": {}, "default": {"django": {"version": "==2.0.0"}}, "develop": {}}`,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	tests := []struct {
+		name              string
+		filePatterns      []string
+		disabledAnalyzers []analyzer.Type
+		want              []types.Application
+		wantErr           string
+	}{
+		{
+			name: "happy path",
+			filePatterns: []string{
+				`pip:requirements-.*\.txt`,
+				`pipenv:Pipfile\..*\.lock`,
+				`dockerfile:Containerfile`,
+			},
+			want: []types.Application{
+				{
+					Type:      types.Pipenv,
+					FilePath:  "Pipfile.custom.lock",
+					Libraries: []types.Package{{Name: "django", Version: "2.0.0"}},
+				},
+				{
+					Type:      types.Pip,
+					FilePath:  "requirements-prod.txt",
+					Libraries: []types.Package{{Name: "Jinja2", Version: "2.10"}},
+				},
+				{
+					Type:      types.Pip,
+					FilePath:  "requirements.txt",
+					Libraries: []types.Package{{Name: "flask", Version: "0.12"}},
+				},
+			},
+		},
+		{
+			name:         "no patterns",
+			filePatterns: nil,
+			want: []types.Application{
+				{
+					Type:      types.Pip,
+					FilePath:  "requirements.txt",
+					Libraries: []types.Package{{Name: "flask", Version: "0.12"}},
+				},
+			},
+		},
+		{
+			name:              "disabled analyzer",
+			filePatterns:      []string{`pip:requirements-.*\.txt`},
+			disabledAnalyzers: []analyzer.Type{analyzer.TypePip},
+		},
+		{
+			name:         "sad path: unknown analyzer",
+			filePatterns: []string{`unknown:requirements-.*\.txt`},
+			wantErr:      "unknown file type: unknown",
+		},
+		{
+			name:         "sad path: invalid regexp",
+			filePatterns: []string{`pip:requirements-(.txt`},
+			wantErr:      "invalid file regexp",
+		},
+		{
+			name:         "sad path: no separator",
+			filePatterns: []string{`requirements-.*\.txt`},
+			wantErr:      "invalid file pattern",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			defer c.Close()
+
+			a, err := NewFilesystemArtifact(dir, c, Option{
+				Option: artifact.Option{
+					DisabledAnalyzers: tt.disabledAnalyzers,
+					MisconfScannerOption: config.ScannerOption{
+						FilePatterns: tt.filePatterns,
+					},
+				},
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ref, err := a.Inspect(context.Background())
+			require.NoError(t, err)
+			require.Len(t, ref.BlobIDs, 1)
+
+			blob, err := c.GetBlob(ref.BlobIDs[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, blob.Applications)
+		})
+	}
+}
+
+func Test_analyzerVersions(t *testing.T) {
+	ag := analyzer.NewAnalyzerGroup(analyzer.GroupBuiltin, nil)
+	assert.Equal(t, ag.AnalyzerVersions(), analyzerVersions(ag, Option{}))
+
+	// The cache keys must change with the patterns
+	opt := Option{}
+	opt.MisconfScannerOption.FilePatterns = []string{`pip:requirements-.*\.txt`}
+	got := analyzerVersions(ag, opt)
+	assert.Contains(t, got, `file-pattern:pip:requirements-.*\.txt`)
+	assert.NotContains(t, ag.AnalyzerVersions(), `file-pattern:pip:requirements-.*\.txt`)
+
+	// and with the hash algorithms
+	assert.Contains(t, analyzerVersions(ag, Option{HashAlgorithms: []string{"sha256"}}), "file-hash:sha256")
+
+	// and with the analyzer timeouts
+	got = analyzerVersions(ag, Option{AnalyzerTimeouts: map[analyzer.Type]time.Duration{analyzer.TypeSecret: 10 * time.Second}})
+	assert.Equal(t, 10000, got["analyzer-timeout:secret"])
+}
@@ -0,0 +1,244 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	digest "github.com/opencontainers/go-digest"
+	swalker "github.com/saracen/walker"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/secret"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/handler"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/fanal/walker"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+	"github.com/aquasecurity/trivy/pkg/progress"
+)
+
+// FilesystemArtifact inspects the filesystem in the same way as the local artifact of fanal,
+// but the number of files analyzed at the same time is configurable, and the skipped paths can be globs.
+type FilesystemArtifact struct {
+	rootPath       string
+	cache          cache.ArtifactCache
+	matcher        pathMatcher
+	analyzer       analyzer.AnalyzerGroup
+	filePatterns   filePatterns
+	handlerManager handler.Manager
+	artifactOption Option
+
+	parallel int
+
+	// walk and readFile access the filesystem, which is local or remote over SFTP
+	walk     func(root string, matcher pathMatcher, walkFn walker.WalkFunc) error
+	readFile func(name string) ([]byte, error)
+}
+
+// NewFilesystemArtifact returns the artifact analyzing the files under the path in parallel
+func NewFilesystemArtifact(rootPath string, c cache.ArtifactCache, opt Option) (artifact.Artifact, error) {
+	return newFilesystemArtifact(rootPath, c, opt)
+}
+
+func newFilesystemArtifact(rootPath string, c cache.ArtifactCache, opt Option) (FilesystemArtifact, error) {
+	// Register config analyzers and the patterns of the other analyzers
+	patterns, err := registerFilePatterns(opt.MisconfScannerOption.FilePatterns)
+	if err != nil {
+		return FilesystemArtifact{}, xerrors.Errorf("file pattern error: %w", err)
+	}
+
+	handlerManager, err := handler.NewManager(opt.Option)
+	if err != nil {
+		return FilesystemArtifact{}, xerrors.Errorf("handler initialize error: %w", err)
+	}
+
+	// Register secret analyzer
+	if err = secret.RegisterSecretAnalyzer(opt.SecretScannerOption); err != nil {
+		return FilesystemArtifact{}, xerrors.Errorf("secret scanner error: %w", err)
+	}
+
+	// The group has the analyzers registered so far, so it must be created after the secret analyzer is registered
+	ag := analyzer.NewAnalyzerGroup(opt.AnalyzerGroup, opt.DisabledAnalyzers)
+
+	return FilesystemArtifact{
+		rootPath:       filepath.Clean(rootPath),
+		cache:          c,
+		matcher:        newFSPathMatcher(rootPath, opt.SkipFiles, opt.SkipDirs),
+		analyzer:       ag,
+		filePatterns:   patterns.withAnalyzers(ag),
+		handlerManager: handlerManager,
+		artifactOption: opt,
+
+		parallel: opt.parallel(),
+		walk:     walkFS,
+		readFile: os.ReadFile,
+	}, nil
+}
+
+// walkFS walks the file tree in the same way as the filesystem walker of fanal, but the skipped paths can be globs.
+// The directories are walked by multiple goroutines, so walkFn must be safe for concurrent use.
+func walkFS(root string, matcher pathMatcher, walkFn walker.WalkFunc) error {
+	fn := func(pathname string, fi os.FileInfo) error {
+		pathname = filepath.Clean(pathname)
+
+		if fi.IsDir() {
+			if matcher.skipDir(pathname) {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !fi.Mode().IsRegular() {
+			return nil
+		} else if matcher.skipFile(pathname) {
+			return nil
+		}
+
+		opener := func() (dio.ReadSeekCloserAt, error) {
+			return os.Open(pathname)
+		}
+		if err := walkFn(pathname, fi, opener); err != nil {
+			return xerrors.Errorf("failed to analyze file: %w", err)
+		}
+		return nil
+	}
+
+	errorCallback := swalker.WithErrorCallback(func(pathname string, err error) error {
+		// ignore permission errors
+		if os.IsPermission(err) {
+			return nil
+		}
+		// halt traversal on any other error
+		return xerrors.Errorf("unknown error with %s: %w", pathname, err)
+	})
+
+	if err := swalker.Walk(root, fn, errorCallback); err != nil {
+		return xerrors.Errorf("walk error: %w", err)
+	}
+	return nil
+}
+
+func (a FilesystemArtifact) Inspect(ctx context.Context) (types.ArtifactReference, error) {
+	var wg sync.WaitGroup
+	result := analyzer.NewAnalysisResult()
+	limit := semaphore.NewWeighted(int64(a.parallel))
+	budget := newFileBudget(a.artifactOption.FileTimeout)
+	analyzerBudgets := newAnalyzerBudgets(a.analyzer, a.artifactOption.AnalyzerTimeouts)
+	hasher := newFileHasher(a.artifactOption.HashAlgorithms)
+	archives := newArchiveWalker(a.artifactOption.MaxArchiveDepth, nil, hasher, result).withTempDir(a.artifactOption.TempDir)
+	files := newFileCounter(a.artifactOption.MaxFiles)
+
+	// The number of the files is unknown until the walk finishes
+	tracker := progress.Start(progress.PhaseAnalysis, a.rootPath, 0)
+	err := a.walk(a.rootPath, a.matcher, func(filePath string, info os.FileInfo, opener analyzer.Opener) error {
+		directory := a.rootPath
+
+		// When the directory is the same as the filePath, a file was given
+		// instead of a directory, rewrite the directory in this case.
+		if a.rootPath == filePath {
+			directory = filepath.Dir(a.rootPath)
+		}
+
+		// For exported rootfs (e.g. images/alpine/etc/alpine-release)
+		filePath, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return xerrors.Errorf("filepath rel (%s): %w", filePath, err)
+		}
+		if err = files.add(filePath); err != nil {
+			return err
+		}
+
+		opts := analyzer.AnalysisOptions{Offline: a.artifactOption.Offline}
+		return archives.walk(filePath, info, opener, func(filePath string, info os.FileInfo, opener analyzer.Opener) error {
+			return budget.analyze(&wg, result, filePath, opener, hasher.wrap(filePath, func(wg *sync.WaitGroup,
+				result *analyzer.AnalysisResult, opener analyzer.Opener) error {
+				err := analyzerBudgets.analyze(wg, result, filePath, opener, nil, func(wg *sync.WaitGroup,
+					result *analyzer.AnalysisResult, opener analyzer.Opener, disabled []analyzer.Type) error {
+					return a.analyzer.AnalyzeFile(ctx, wg, limit, result, directory, filePath, info, opener, disabled, opts)
+				})
+				if err != nil {
+					return xerrors.Errorf("analyze file (%s): %w", filePath, err)
+				}
+				if err := a.filePatterns.analyze(ctx, a.analyzer, limit, result, directory, filePath, info, opener, nil, opts); err != nil {
+					return xerrors.Errorf("analyze file (%s): %w", filePath, err)
+				}
+				return nil
+			}))
+		})
+	})
+
+	// Wait for all the goroutine to finish even on errors, as they write to the result.
+	wg.Wait()
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("walk filesystem: %w", err)
+	}
+	tracker.Finish()
+
+	// Sort the analysis result for consistent results
+	sortResult(result)
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion:   types.BlobJSONSchemaVersion,
+		OS:              result.OS,
+		Repository:      result.Repository,
+		PackageInfos:    result.PackageInfos,
+		Applications:    result.Applications,
+		Secrets:         result.Secrets,
+		CustomResources: result.CustomResources,
+	}
+
+	if err = a.handlerManager.PostHandle(ctx, result, &blobInfo); err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("failed to call hooks: %w", err)
+	}
+
+	cacheKey, err := a.calcCacheKey(blobInfo)
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("failed to calculate a cache key: %w", err)
+	}
+
+	if err = a.cache.PutBlob(cacheKey, blobInfo); err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("failed to store blob (%s) in cache: %w", cacheKey, err)
+	}
+
+	// get hostname
+	var hostName string
+	b, err := a.readFile(filepath.Join(a.rootPath, "etc", "hostname"))
+	if err == nil && string(b) != "" {
+		hostName = strings.TrimSpace(string(b))
+	} else {
+		hostName = a.rootPath
+	}
+
+	return types.ArtifactReference{
+		Name:    hostName,
+		Type:    types.ArtifactFilesystem,
+		ID:      cacheKey, // use a cache key as pseudo artifact ID
+		BlobIDs: []string{cacheKey},
+	}, nil
+}
+
+func (a FilesystemArtifact) Clean(reference types.ArtifactReference) error {
+	return a.cache.DeleteBlobs(reference.BlobIDs)
+}
+
+func (a FilesystemArtifact) calcCacheKey(blobInfo types.BlobInfo) (string, error) {
+	// calculate hash of JSON and use it as pseudo artifactID and blobID
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(blobInfo); err != nil {
+		return "", xerrors.Errorf("json error: %w", err)
+	}
+
+	d := digest.NewDigest(digest.SHA256, h)
+	versions := analyzerVersions(a.analyzer, a.artifactOption)
+	cacheKey, err := cache.CalcKey(d.String(), versions, a.handlerManager.Versions(), a.artifactOption.Option)
+	if err != nil {
+		return "", xerrors.Errorf("cache key: %w", err)
+	}
+	return cacheKey, nil
+}
@@ -0,0 +1,158 @@
+package artifact
+
+import (
+	"crypto/md5"  // nolint: gosec
+	"crypto/sha1" // nolint: gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+	"github.com/aquasecurity/trivy/pkg/log"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+// metadataFileTypes are the types of the applications found in the metadata files of the installed packages,
+// e.g. "package.json" in "node_modules", whose hashes aren't the ones of the packages.
+// The wheels they are unpacked from are hashed instead, and the eggs are the package files themselves.
+var metadataFileTypes = []string{types.NodePkg, types.PythonPkg, types.GemSpec}
+
+// fileHasher hashes the files in which the analyzers find packages, e.g. JAR files, Go binaries and lock files,
+// in all the algorithms in a single read. The hashes are recorded as PackageHashType custom resources.
+type fileHasher struct {
+	algorithms []string
+}
+
+func newFileHasher(algorithms []string) fileHasher {
+	return fileHasher{algorithms: algorithms}
+}
+
+// wrap returns analyzeFn hashing the file once its analyzers have found packages in it.
+// The file is held open until then, since the content is released when the analyzers close it.
+func (h fileHasher) wrap(filePath string, analyzeFn fileAnalyzeFunc) fileAnalyzeFunc {
+	if len(h.algorithms) == 0 {
+		return analyzeFn
+	}
+	return func(wg *sync.WaitGroup, result *analyzer.AnalysisResult, opener analyzer.Opener) error {
+		var fileWg sync.WaitGroup
+		fileResult := analyzer.NewAnalysisResult()
+		held := &heldFile{opener: opener}
+		err := analyzeFn(&fileWg, fileResult, held.open)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fileWg.Wait()
+			defer held.close()
+
+			if hasPackageFile(filePath, fileResult.Applications) && held.rc != nil {
+				if _, err := held.rc.Seek(0, io.SeekStart); err != nil {
+					log.Logger.Debugf("Unable to hash %s: %s", filePath, err)
+				} else {
+					fileResult.Merge(h.hash(filePath, held.rc))
+				}
+			}
+			result.Merge(fileResult)
+		}()
+		return err
+	}
+}
+
+func hasPackageFile(filePath string, apps []types.Application) bool {
+	if strings.EqualFold(filepath.Ext(filePath), ".egg") {
+		return len(apps) > 0
+	}
+	for _, app := range apps {
+		if !slices.Contains(metadataFileTypes, app.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// hash returns the result with the hashes of the file, or nil if it can't be read
+func (h fileHasher) hash(filePath string, r io.Reader) *analyzer.AnalysisResult {
+	if len(h.algorithms) == 0 {
+		return nil
+	}
+
+	hashes := make([]hash.Hash, 0, len(h.algorithms))
+	writers := make([]io.Writer, 0, len(h.algorithms))
+	for _, alg := range h.algorithms {
+		hh, err := newHash(alg)
+		if err != nil {
+			log.Logger.Debugf("Unable to hash %s: %s", filePath, err)
+			return nil
+		}
+		hashes = append(hashes, hh)
+		writers = append(writers, hh)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		log.Logger.Debugf("Unable to hash %s: %s", filePath, err)
+		return nil
+	}
+
+	var sums []string
+	for i, hh := range hashes {
+		sums = append(sums, h.algorithms[i]+":"+hex.EncodeToString(hh.Sum(nil)))
+	}
+	return &analyzer.AnalysisResult{
+		CustomResources: []types.CustomResource{
+			{
+				Type:     pkgtypes.PackageHashType,
+				FilePath: filePath,
+				Data:     sums,
+			},
+		},
+	}
+}
+
+func newHash(alg string) (hash.Hash, error) {
+	switch alg {
+	case "md5":
+		return md5.New(), nil // nolint: gosec
+	case "sha1":
+		return sha1.New(), nil // nolint: gosec
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	}
+	return nil, xerrors.Errorf("unsupported hash algorithm: %s", alg)
+}
+
+// heldFile opens an extra reader of the file when the analyzers open it first, and keeps it until close
+type heldFile struct {
+	opener analyzer.Opener
+	once   sync.Once
+	rc     dio.ReadSeekCloserAt
+}
+
+func (f *heldFile) open() (dio.ReadSeekCloserAt, error) {
+	rc, err := f.opener()
+	if err != nil {
+		return nil, err
+	}
+	f.once.Do(func() {
+		if held, err := f.opener(); err == nil {
+			f.rc = held
+		}
+	})
+	return rc, nil
+}
+
+func (f *heldFile) close() {
+	if f.rc != nil {
+		_ = f.rc.Close()
+	}
+}
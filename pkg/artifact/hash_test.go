@@ -0,0 +1,115 @@
+package artifact
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestFileHasher_wrap(t *testing.T) {
+	app := types.Application{Type: types.GoBinary, FilePath: "usr/local/bin/app"}
+
+	tests := []struct {
+		name       string
+		filePath   string
+		algorithms []string
+		apps       []types.Application
+		want       *analyzer.AnalysisResult
+	}{
+		{
+			name:       "packages found",
+			filePath:   "usr/local/bin/app",
+			algorithms: []string{"sha256", "sha1"},
+			apps:       []types.Application{app},
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{app},
+				CustomResources: []types.CustomResource{
+					{
+						Type:     pkgtypes.PackageHashType,
+						FilePath: "usr/local/bin/app",
+						Data: []string{
+							"sha256:8dcc7e601606217f3b754766511182a916b17e9a26a94c9d887104eba92e9bb2",
+							"sha1:fb467bb25be45fcf0c84c03ce5801abd5a28c1fd",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "metadata of an installed package",
+			filePath:   "node_modules/lodash/package.json",
+			algorithms: []string{"sha256"},
+			apps:       []types.Application{{Type: types.NodePkg, FilePath: "node_modules/lodash/package.json"}},
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{{Type: types.NodePkg, FilePath: "node_modules/lodash/package.json"}},
+			},
+		},
+		{
+			name:       "egg",
+			filePath:   "site-packages/six-1.16.0-py3.9.egg",
+			algorithms: []string{"sha1"},
+			apps:       []types.Application{{Type: types.PythonPkg, FilePath: "site-packages/six-1.16.0-py3.9.egg"}},
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{{Type: types.PythonPkg, FilePath: "site-packages/six-1.16.0-py3.9.egg"}},
+				CustomResources: []types.CustomResource{
+					{
+						Type:     pkgtypes.PackageHashType,
+						FilePath: "site-packages/six-1.16.0-py3.9.egg",
+						Data:     []string{"sha1:fb467bb25be45fcf0c84c03ce5801abd5a28c1fd"},
+					},
+				},
+			},
+		},
+		{
+			name:       "no package",
+			filePath:   "usr/local/bin/app",
+			algorithms: []string{"sha256"},
+			want:       &analyzer.AnalysisResult{},
+		},
+		{
+			name:     "no algorithms",
+			filePath: "usr/local/bin/app",
+			apps:     []types.Application{app},
+			want:     &analyzer.AnalysisResult{Applications: []types.Application{app}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The content is released once the walker moves on and the analyzer closes it
+			tf := newTarFile(4, strings.NewReader("PK\x03\x04"), nil, "")
+
+			var wg sync.WaitGroup
+			result := analyzer.NewAnalysisResult()
+			h := fileHasher{algorithms: tt.algorithms}
+			err := h.wrap(tt.filePath, func(wg *sync.WaitGroup, result *analyzer.AnalysisResult,
+				opener analyzer.Opener) error {
+				r, err := opener()
+				if err != nil {
+					return err
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer r.Close()
+					_, _ = io.ReadAll(r)
+					result.Merge(&analyzer.AnalysisResult{Applications: tt.apps})
+				}()
+				return nil
+			})(&wg, result, tf.Open)
+			require.NoError(t, err)
+			tf.clean()
+			wg.Wait()
+
+			assert.Equal(t, tt.want.Applications, result.Applications)
+			assert.Equal(t, tt.want.CustomResources, result.CustomResources)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package artifact
+
+import (
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/aquasecurity/fanal/secret"
+	"github.com/aquasecurity/fanal/types"
+)
+
+const (
+	// HistoryFilePath is the file path of the secrets detected in the history of the image config,
+	// which can't be a path in the layers
+	HistoryFilePath = "image config history"
+
+	// historySecretVersion is bumped when the content scanned for the secrets changes
+	historySecretVersion = 1
+)
+
+// historyScanner detects the secrets in the history of the image config, e.g. the tokens passed as build args to RUN,
+// which are still visible in "docker history" even if the files are deleted in the later layers.
+type historyScanner struct {
+	scanner secret.Scanner
+}
+
+func newHistoryScanner(configPath string) (*historyScanner, error) {
+	s, err := secret.NewScanner(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return &historyScanner{scanner: s}, nil
+}
+
+// scan returns the blob with the secrets in the history not of the base image.
+// The history is scanned as a line per instruction, so that the line numbers of the findings are the instructions.
+func (s historyScanner) scan(configFile *v1.ConfigFile) types.BlobInfo {
+	blobInfo := types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}
+	if configFile == nil {
+		return blobInfo
+	}
+
+	baseIndex := baseHistoryIndex(configFile)
+	lines := make([]string, len(configFile.History))
+	for i, h := range configFile.History {
+		// Secrets are not detected in base layers
+		if i <= baseIndex {
+			continue
+		}
+		lines[i] = strings.Join(strings.Fields(h.CreatedBy), " ")
+	}
+
+	result := s.scanner.Scan(secret.ScanArgs{
+		FilePath: HistoryFilePath,
+		Content:  []byte(strings.Join(lines, "\n")),
+	})
+	if len(result.Findings) > 0 {
+		blobInfo.Secrets = []types.Secret{result}
+	}
+	return blobInfo
+}
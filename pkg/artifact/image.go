@@ -0,0 +1,451 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/secret"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/handler"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/progress"
+)
+
+// ImageArtifact inspects the image in the same way as the image artifact of fanal, so that the blobs in the cache
+// are compatible, but the missing layers are analyzed by a bounded pool of workers.
+// The files are analyzed with the same number of workers shared by all the layers,
+// and the files kept in memory are limited by the memory limit shared by all the layers.
+type ImageArtifact struct {
+	image          types.Image
+	cache          cache.ArtifactCache
+	walker         LayerTar
+	analyzer       analyzer.AnalyzerGroup
+	filePatterns   filePatterns
+	handlerManager handler.Manager
+	artifactOption Option
+
+	// history is nil when the secret scanning is disabled
+	history *historyScanner
+
+	parallel  int
+	maxMemory int64
+
+	// maxFiles is the number of the files in the missing layers, 0 means no limit
+	maxFiles int
+}
+
+// NewImageArtifact returns the artifact analyzing the layers of the image in parallel
+func NewImageArtifact(img types.Image, c cache.ArtifactCache, opt Option) (artifact.Artifact, error) {
+	// Register config analyzers and the patterns of the other analyzers
+	patterns, err := registerFilePatterns(opt.MisconfScannerOption.FilePatterns)
+	if err != nil {
+		return nil, xerrors.Errorf("file pattern error: %w", err)
+	}
+
+	handlerManager, err := handler.NewManager(opt.Option)
+	if err != nil {
+		return nil, xerrors.Errorf("handler init error: %w", err)
+	}
+
+	// Register secret analyzer
+	if err = secret.RegisterSecretAnalyzer(opt.SecretScannerOption); err != nil {
+		return nil, xerrors.Errorf("secret scanner error: %w", err)
+	}
+
+	// The group has the analyzers registered so far, so it must be created after the secret analyzer is registered
+	ag := analyzer.NewAnalyzerGroup(opt.AnalyzerGroup, opt.DisabledAnalyzers)
+
+	var history *historyScanner
+	if !slices.Contains(opt.DisabledAnalyzers, analyzer.TypeSecret) {
+		if history, err = newHistoryScanner(opt.SecretScannerOption.ConfigPath); err != nil {
+			return nil, xerrors.Errorf("secret scanner error: %w", err)
+		}
+	}
+
+	return ImageArtifact{
+		image:          img,
+		cache:          c,
+		walker:         NewLayerTar(opt.SkipFiles, opt.SkipDirs).withTempDir(opt.TempDir),
+		analyzer:       ag,
+		filePatterns:   patterns.withAnalyzers(ag),
+		handlerManager: handlerManager,
+		artifactOption: opt,
+		history:        history,
+
+		parallel:  opt.parallel(),
+		maxMemory: opt.MaxMemory,
+		maxFiles:  opt.MaxFiles,
+	}, nil
+}
+
+func (a ImageArtifact) Inspect(ctx context.Context) (types.ArtifactReference, error) {
+	imageID, err := a.image.ID()
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("unable to get the image ID: %w", err)
+	}
+
+	diffIDs, err := a.image.LayerIDs()
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("unable to get layer IDs: %w", err)
+	}
+
+	configFile, err := a.image.ConfigFile()
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("unable to get the image's config file: %w", err)
+	}
+
+	log.Logger.Debugf("Image ID: %s", imageID)
+	log.Logger.Debugf("Diff IDs: %v", diffIDs)
+
+	// Secrets are not detected in base layers
+	baseDiffIDs := GuessBaseLayers(diffIDs, configFile)
+	log.Logger.Debugf("Base Layers: %v", baseDiffIDs)
+
+	// Pass an empty config scanner option so that the cache key can be the same, even when policies are updated.
+	imageKey, err := cache.CalcKey(imageID, a.analyzer.ImageConfigAnalyzerVersions(), nil, artifact.Option{})
+	if err != nil {
+		return types.ArtifactReference{}, err
+	}
+
+	layerKeyMap := map[string]string{}
+	var layerKeys []string
+	versions := analyzerVersions(a.analyzer, a.artifactOption)
+	for _, diffID := range diffIDs {
+		key, err := cache.CalcKey(diffID, versions, a.handlerManager.Versions(), a.artifactOption.Option)
+		if err != nil {
+			return types.ArtifactReference{}, err
+		}
+		layerKeys = append(layerKeys, key)
+		layerKeyMap[key] = diffID
+	}
+
+	// The secrets in the history are stored as a blob after the layers, as they are not in any layer
+	var historyKey string
+	if a.history != nil {
+		historyVersions := map[string]int{
+			"history-secret": historySecretVersion,
+			"secret":         versions[string(analyzer.TypeSecret)],
+		}
+		if historyKey, err = cache.CalcKey(imageID, historyVersions, nil, artifact.Option{}); err != nil {
+			return types.ArtifactReference{}, err
+		}
+		layerKeys = append(layerKeys, historyKey)
+	}
+
+	missingImage, missingLayers, err := a.cache.MissingBlobs(imageKey, layerKeys)
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("unable to get missing layers: %w", err)
+	}
+
+	if i := slices.Index(missingLayers, historyKey); historyKey != "" && i >= 0 {
+		missingLayers = slices.Delete(missingLayers, i, i+1)
+		if err = a.cache.PutBlob(historyKey, a.history.scan(configFile)); err != nil {
+			return types.ArtifactReference{}, xerrors.Errorf("failed to store the history in cache: %w", err)
+		}
+	}
+
+	index := newLayerIndex(a.cache, a.analyzer, a.artifactOption, a.handlerManager.Versions())
+	osFound, err := a.inspectLayers(ctx, missingLayers, baseDiffIDs, layerKeyMap, index)
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("analyze error: %w", err)
+	}
+
+	if missingImage {
+		log.Logger.Debugf("Missing image ID in cache: %s", imageID)
+		if err = a.inspectConfig(imageKey, osFound); err != nil {
+			return types.ArtifactReference{}, xerrors.Errorf("analyze error: unable to analyze config: %w", err)
+		}
+	}
+
+	return types.ArtifactReference{
+		Name:    a.image.Name(),
+		Type:    types.ArtifactContainerImage,
+		ID:      imageKey,
+		BlobIDs: layerKeys,
+		ImageMetadata: types.ImageMetadata{
+			ID:          imageID,
+			DiffIDs:     diffIDs,
+			RepoTags:    a.image.RepoTags(),
+			RepoDigests: a.image.RepoDigests(),
+			ConfigFile:  *configFile,
+		},
+	}, nil
+}
+
+func (ImageArtifact) Clean(_ types.ArtifactReference) error {
+	return nil
+}
+
+// inspectLayers analyzes the missing layers in parallel, and returns the OS detected in the last layer of them.
+// The OS is picked in the order of the layers, not in the order the analyses finish, so that the result is stable.
+// The layers analyzed before the analyzers are upgraded are analyzed again only by the upgraded analyzers.
+func (a ImageArtifact) inspectLayers(ctx context.Context, layerKeys, baseDiffIDs []string,
+	layerKeyMap map[string]string, index layerIndex) (types.OS, error) {
+	fileLimit := semaphore.NewWeighted(int64(a.parallel))
+	mem := newMemoryLimit(a.maxMemory)
+	files := newFileCounter(a.maxFiles)
+	found := make([]*types.OS, len(layerKeys))
+	tracker := progress.Start(progress.PhaseLayerAnalysis, a.image.Name(), int64(len(layerKeys)))
+
+	pool := newWorkerPool(ctx, a.parallel)
+	for i, key := range layerKeys {
+		i, key := i, key
+		pool.Go(func(ctx context.Context) error {
+			diffID := layerKeyMap[key]
+
+			// If it is a base layer, secret scanning should not be performed.
+			var disabled []analyzer.Type
+			if slices.Contains(baseDiffIDs, diffID) {
+				disabled = append(disabled, analyzer.TypeSecret)
+			}
+
+			prev, ok := index.previous(diffID)
+			if ok {
+				disabled = append(disabled, prev.disabled...)
+			}
+
+			layerInfo, err := a.inspectLayer(ctx, diffID, fileLimit, mem, files, disabled)
+			if err != nil {
+				return xerrors.Errorf("failed to analyze layer: %s : %w", diffID, err)
+			}
+			if ok {
+				layerInfo = prev.merge(layerInfo)
+			}
+			if err = a.cache.PutBlob(key, layerInfo); err != nil {
+				return xerrors.Errorf("failed to store layer: %s in cache: %w", key, err)
+			}
+			if err = index.put(diffID, key); err != nil {
+				return xerrors.Errorf("failed to store the index of layer: %s in cache: %w", diffID, err)
+			}
+			found[i] = layerInfo.OS
+			tracker.Add(1, diffID)
+			return nil
+		})
+	}
+	err := pool.Wait()
+	if ctx.Err() != nil {
+		return types.OS{}, xerrors.Errorf("timeout: %w", ctx.Err())
+	} else if err != nil {
+		return types.OS{}, err
+	}
+	tracker.Finish()
+
+	var osFound types.OS
+	for _, o := range found {
+		if o != nil {
+			osFound = *o
+		}
+	}
+	return osFound, nil
+}
+
+func (a ImageArtifact) inspectLayer(ctx context.Context, diffID string, fileLimit *semaphore.Weighted,
+	mem *memoryLimit, files *fileCounter, disabled []analyzer.Type) (types.BlobInfo, error) {
+	log.Logger.Debugf("Missing diff ID in cache: %s", diffID)
+
+	layerDigest, rc, err := a.uncompressedLayer(diffID)
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("unable to get uncompressed layer %s: %w", diffID, err)
+	}
+	defer rc.Close()
+
+	var wg sync.WaitGroup
+	opts := analyzer.AnalysisOptions{Offline: a.artifactOption.Offline}
+	result := analyzer.NewAnalysisResult()
+
+	// Walk a tar layer
+	budget := newFileBudget(a.artifactOption.FileTimeout)
+	analyzerBudgets := newAnalyzerBudgets(a.analyzer, a.artifactOption.AnalyzerTimeouts)
+	hasher := newFileHasher(a.artifactOption.HashAlgorithms)
+	analyzeFn := func(filePath string, info os.FileInfo, opener analyzer.Opener) error {
+		return budget.analyze(&wg, result, filePath, opener, hasher.wrap(filePath, func(wg *sync.WaitGroup,
+			result *analyzer.AnalysisResult, opener analyzer.Opener) error {
+			err := analyzerBudgets.analyze(wg, result, filePath, opener, disabled, func(wg *sync.WaitGroup,
+				result *analyzer.AnalysisResult, opener analyzer.Opener, disabled []analyzer.Type) error {
+				return a.analyzer.AnalyzeFile(ctx, wg, fileLimit, result, "", filePath, info, opener, disabled, opts)
+			})
+			if err != nil {
+				return xerrors.Errorf("failed to analyze %s: %w", filePath, err)
+			}
+			if err := a.filePatterns.analyze(ctx, a.analyzer, fileLimit, result, "", filePath, info, opener, disabled, opts); err != nil {
+				return xerrors.Errorf("failed to analyze %s: %w", filePath, err)
+			}
+			return nil
+		}))
+	}
+	archives := newArchiveWalker(a.artifactOption.MaxArchiveDepth, mem, hasher, result).withTempDir(a.artifactOption.TempDir)
+	opqDirs, whFiles, err := a.walker.withMemoryLimit(mem).Walk(rc, func(filePath string, info os.FileInfo, opener analyzer.Opener) error {
+		if err := files.add(filePath); err != nil {
+			return err
+		}
+		return archives.walk(filePath, info, opener, analyzeFn)
+	})
+
+	// Wait for all the goroutine to finish even on errors, as they write to the result.
+	wg.Wait()
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("walk error: %w", err)
+	}
+
+	// Sort the analysis result for consistent results
+	sortResult(result)
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion:   types.BlobJSONSchemaVersion,
+		Digest:          layerDigest,
+		DiffID:          diffID,
+		OS:              result.OS,
+		Repository:      result.Repository,
+		PackageInfos:    result.PackageInfos,
+		Applications:    result.Applications,
+		Secrets:         result.Secrets,
+		OpaqueDirs:      opqDirs,
+		WhiteoutFiles:   whFiles,
+		CustomResources: result.CustomResources,
+
+		// For Red Hat
+		BuildInfo: result.BuildInfo,
+	}
+
+	// Call post handlers to modify blob info
+	if err = a.handlerManager.PostHandle(ctx, result, &blobInfo); err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("post handler error: %w", err)
+	}
+
+	return blobInfo, nil
+}
+
+func (a ImageArtifact) uncompressedLayer(diffID string) (string, io.ReadCloser, error) {
+	// diffID is a hash of the uncompressed layer
+	h, err := v1.NewHash(diffID)
+	if err != nil {
+		return "", nil, xerrors.Errorf("invalid layer ID (%s): %w", diffID, err)
+	}
+
+	layer, err := a.image.LayerByDiffID(h)
+	if err != nil {
+		return "", nil, xerrors.Errorf("failed to get the layer (%s): %w", diffID, err)
+	}
+
+	// digest is a hash of the compressed layer
+	var digest string
+	if IsCompressed(layer) {
+		d, err := layer.Digest()
+		if err != nil {
+			return "", nil, xerrors.Errorf("failed to get the digest (%s): %w", diffID, err)
+		}
+		digest = d.String()
+	}
+
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return "", nil, xerrors.Errorf("failed to get the layer content (%s): %w", diffID, err)
+	}
+	return digest, rc, nil
+}
+
+func (a ImageArtifact) inspectConfig(imageID string, osFound types.OS) error {
+	configBlob, err := a.image.RawConfigFile()
+	if err != nil {
+		return xerrors.Errorf("unable to get config blob: %w", err)
+	}
+
+	pkgs := a.analyzer.AnalyzeImageConfig(osFound, configBlob)
+
+	var s1 v1.ConfigFile
+	if err = json.Unmarshal(configBlob, &s1); err != nil {
+		return xerrors.Errorf("json marshal error: %w", err)
+	}
+
+	info := types.ArtifactInfo{
+		SchemaVersion:   types.ArtifactJSONSchemaVersion,
+		Architecture:    s1.Architecture,
+		Created:         s1.Created.Time,
+		DockerVersion:   s1.DockerVersion,
+		OS:              s1.OS,
+		HistoryPackages: pkgs,
+	}
+
+	if err = a.cache.PutArtifact(imageID, info); err != nil {
+		return xerrors.Errorf("failed to put image info into the cache: %w", err)
+	}
+	return nil
+}
+
+// IsCompressed returns whether the layer is compressed, as the digest is available only for compressed layers
+// ref. https://github.com/google/go-containerregistry/issues/701
+func IsCompressed(l v1.Layer) bool {
+	_, uncompressed := reflect.TypeOf(l).Elem().FieldByName("UncompressedLayer")
+	return !uncompressed
+}
+
+// GuessBaseLayers returns the layers of the base image in the same way as the image artifact of fanal,
+// since secrets are not detected in base layers. It assumes that the last CMD in the history is of the base image.
+func GuessBaseLayers(diffIDs []string, configFile *v1.ConfigFile) []string {
+	if configFile == nil {
+		return nil
+	}
+	baseImageIndex := baseHistoryIndex(configFile)
+
+	// Diff IDs don't include empty layers, so the index is different from histories
+	var diffIDIndex int
+	var baseDiffIDs []string
+	for i, h := range configFile.History {
+		// It is no longer base layer.
+		if i > baseImageIndex {
+			break
+		}
+		// Empty layers are not included in diff IDs.
+		if h.EmptyLayer {
+			continue
+		}
+
+		if diffIDIndex >= len(diffIDs) {
+			// something wrong...
+			return nil
+		}
+		baseDiffIDs = append(baseDiffIDs, diffIDs[diffIDIndex])
+		diffIDIndex++
+	}
+	return baseDiffIDs
+}
+
+// baseHistoryIndex returns the index of the last history of the base image, which is the last CMD before the last layer
+func baseHistoryIndex(configFile *v1.ConfigFile) int {
+	var foundNonEmpty bool
+	for i := len(configFile.History) - 1; i >= 0; i-- {
+		h := configFile.History[i]
+
+		// Skip the last CMD, ENTRYPOINT, etc.
+		if !foundNonEmpty {
+			if h.EmptyLayer {
+				continue
+			}
+			foundNonEmpty = true
+		}
+
+		if !h.EmptyLayer {
+			continue
+		}
+
+		// Detect CMD instruction in base image
+		if strings.HasPrefix(h.CreatedBy, "/bin/sh -c #(nop)  CMD") ||
+			strings.HasPrefix(h.CreatedBy, "CMD") { // BuildKit
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+
+	"github.com/aquasecurity/fanal/artifact"
+	aimage "github.com/aquasecurity/fanal/artifact/image"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/image"
+	"github.com/aquasecurity/fanal/types"
+
+	_ "github.com/aquasecurity/fanal/analyzer/language/nodejs/npm"
+	_ "github.com/aquasecurity/fanal/analyzer/os/alpine"
+	_ "github.com/aquasecurity/fanal/analyzer/pkg/apk"
+	_ "github.com/aquasecurity/fanal/analyzer/repo/apk"
+)
+
+// testImage writes the image with the layers of the files to an archive, and opens it
+func testImage(t *testing.T, layers ...map[string]string) types.Image {
+	img := empty.Image
+	var history []v1.History
+	for _, files := range layers {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		// The files are written in order so that the diff ID is the same
+		paths := maps.Keys(files)
+		sort.Strings(paths)
+		for _, path := range paths {
+			content := files[path]
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: path, Mode: 0644, Size: int64(len(content))}))
+			_, err := tw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+
+		b := buf.Bytes()
+		layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		})
+		require.NoError(t, err)
+		img, err = mutate.AppendLayers(img, layer)
+		require.NoError(t, err)
+		history = append(history, v1.History{CreatedBy: "COPY . /"})
+	}
+
+	cfg, err := img.ConfigFile()
+	require.NoError(t, err)
+	cfg = cfg.DeepCopy()
+	cfg.History = history
+	img, err = mutate.ConfigFile(img, cfg)
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference("myapp:1.0")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "image.tar")
+	require.NoError(t, tarball.WriteToFile(path, ref, img))
+
+	archive, err := image.NewArchiveImage(path)
+	require.NoError(t, err)
+	return archive
+}
+
+func TestImageArtifact_Inspect(t *testing.T) {
+	layers := []map[string]string{
+		{
+			"etc/alpine-release":        "3.15.4\n",
+			"etc/apk/repositories":      "https://dl-cdn.alpinelinux.org/alpine/v3.15/main\n",
+			"lib/apk/db/installed":      "P:musl\nV:1.2.2-r7\nA:x86_64\no:musl\n\nP:busybox\nV:1.34.1-r5\nA:x86_64\no:busybox\n\n",
+			"app/node_modules/.keep":    "",
+			"app/package-lock.json":     `{"lockfileVersion": 1, "dependencies": {"lodash": {"version": "4.17.20"}}}`,
+			"app/web/package-lock.json": `{"lockfileVersion": 1, "dependencies": {"jquery": {"version": "3.4.0"}}}`,
+		},
+		{
+			"etc/alpine-release": "3.16.0\n",
+		},
+	}
+	// Many layers are analyzed at the same time
+	for i := 0; i < 10; i++ {
+		layers = append(layers, map[string]string{
+			fmt.Sprintf("srv/%d/package-lock.json", i):   `{"lockfileVersion": 1, "dependencies": {"ms": {"version": "2.1.3"}}}`,
+			fmt.Sprintf("srv/%d/c/package-lock.json", i): `{"lockfileVersion": 1, "dependencies": {"debug": {"version": "4.3.4"}}}`,
+		})
+	}
+	img := testImage(t, layers...)
+
+	// The blobs must be the same as the image artifact of fanal, so that the cache is shared.
+	// The layers are inspected one by one since the image artifact of fanal writes the OS in the goroutines.
+	var wantBlobs []types.BlobInfo
+	for _, files := range layers {
+		got := inspectImage(t, testImage(t, files), Option{}, fanalImageArtifact)
+		wantBlobs = append(wantBlobs, got.Blobs...)
+	}
+	want := inspectImage(t, img, Option{Parallel: 1}, NewImageArtifact)
+	require.Len(t, want.Blobs, len(layers)+1)
+	assert.Equal(t, wantBlobs, want.Blobs[:len(layers)])
+	// The history has no secret
+	assert.Equal(t, types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}, want.Blobs[len(layers)])
+	require.Len(t, want.Blobs[0].PackageInfos, 1)
+	assert.Len(t, want.Blobs[0].PackageInfos[0].Packages, 2)
+	assert.Len(t, want.Blobs[0].Applications, 2)
+	assert.Equal(t, "3.16.0", want.Blobs[1].OS.Name)
+
+	tests := []struct {
+		name      string
+		parallel  int
+		maxMemory int64
+	}{
+		{
+			name:     "default",
+			parallel: DefaultParallel,
+		},
+		{
+			name:     "more workers than layers",
+			parallel: 50,
+		},
+		{
+			name:      "files spilled to temp files",
+			parallel:  DefaultParallel,
+			maxMemory: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inspectImage(t, img, Option{Parallel: tt.parallel, MaxMemory: tt.maxMemory}, NewImageArtifact)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+type inspected struct {
+	Reference types.ArtifactReference
+	Artifact  types.ArtifactInfo
+	Blobs     []types.BlobInfo
+}
+
+// fanalImageArtifact is the image artifact of fanal, which has no limit of the analysis
+func fanalImageArtifact(img types.Image, c cache.ArtifactCache, opt Option) (artifact.Artifact, error) {
+	return aimage.NewArtifact(img, c, opt.Option)
+}
+
+func inspectImage(t *testing.T, img types.Image, opt Option,
+	newArtifact func(types.Image, cache.ArtifactCache, Option) (artifact.Artifact, error)) inspected {
+	c, err := cache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	defer c.Close()
+
+	a, err := newArtifact(img, c, opt)
+	require.NoError(t, err)
+	ref, err := a.Inspect(context.Background())
+	require.NoError(t, err)
+
+	got := inspected{Reference: ref}
+	got.Artifact, err = c.GetArtifact(ref.ID)
+	require.NoError(t, err)
+	for _, id := range ref.BlobIDs {
+		blob, err := c.GetBlob(id)
+		require.NoError(t, err)
+		got.Blobs = append(got.Blobs, sortCustomResources(blob))
+	}
+	return got
+}
+
+// sortCustomResources sorts the custom resources as the artifacts here do,
+// since those of fanal are in the order the analyzers running at the same time finish
+func sortCustomResources(blob types.BlobInfo) types.BlobInfo {
+	sort.Slice(blob.CustomResources, func(i, j int) bool {
+		a, b := blob.CustomResources[i], blob.CustomResources[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Type < b.Type
+	})
+	return blob
+}
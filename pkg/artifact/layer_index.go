@@ -0,0 +1,270 @@
+package artifact
+
+import (
+	"encoding/json"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/unpinned"
+)
+
+const (
+	// layerIndexVersion is bumped when the way the previous analyses are reused changes
+	layerIndexVersion = 1
+
+	// layerIndexType is the type of the custom resource in the index, which records the key of the last blob
+	// of the layer in FilePath and the analyzer versions of the blob in Data
+	layerIndexType = "trivy:layer-index"
+)
+
+// layerPart is the part of the layer analysis which the analyzers of the part are run again together for
+type layerPart string
+
+const (
+	// osPart has the OS, the OS packages and the repository, and is always analyzed again with the language packages,
+	// as the files installed by the OS packages filter the language packages
+	osPart     layerPart = "os"
+	configPart layerPart = "config"
+	secretPart layerPart = "secret"
+	digestPart layerPart = "digest"
+)
+
+var osAnalyzers = append([]analyzer.Type{analyzer.TypeOSRelease, analyzer.TypeCBLMariner, analyzer.TypeApkRepo,
+	analyzer.TypeRedHatContentManifestType, analyzer.TypeRedHatDockerfileType}, analyzer.TypeOSes...)
+
+// appAnalyzers are the analyzers whose applications are of the same type as the analyzer
+var appAnalyzers = append(append([]analyzer.Type{fingerprint.TypeStaticLibrary, nodejs.TypePnpm},
+	unpinned.Types...), analyzer.TypeLanguages...)
+
+// partOf returns the part of the analyzer, or false if the results of the analyzer can't be told from the others,
+// e.g. the modules
+func partOf(t analyzer.Type) (layerPart, bool) {
+	switch {
+	case slices.Contains(osAnalyzers, t):
+		return osPart, true
+	case slices.Contains(analyzer.TypeConfigFiles, t):
+		return configPart, true
+	case t == analyzer.TypeSecret:
+		return secretPart, true
+	case slices.Contains(TypeDigests, t):
+		return digestPart, true
+	case slices.Contains(appAnalyzers, t):
+		return layerPart(t), true
+	}
+	return "", false
+}
+
+// isAppPart reports whether the part is the applications of an analyzer
+func (p layerPart) isAppPart() bool {
+	return slices.Contains(appAnalyzers, analyzer.Type(p))
+}
+
+// layerIndex keys the layers by the diff ID and the options except the analyzer versions, so that the last analysis
+// of the layer is found after the analyzers are upgraded. Only the analyzers whose versions changed analyze the layer
+// again, and the results of the other analyzers are reused, instead of analyzing all the layers again after upgrades.
+// The index needs to read the blobs, so it is disabled with the remote cache by Option.NoLayerIndex.
+type layerIndex struct {
+	cache            cache.ArtifactCache
+	localCache       cache.LocalArtifactCache
+	analyzerVersions map[string]int
+	otherVersions    map[string]int
+	handlerVersions  map[string]int
+	option           artifact.Option
+}
+
+// previousLayer is the last analysis of the layer, and the analyzers running again on the layer
+type previousLayer struct {
+	blob      types.BlobInfo
+	parts     []layerPart
+	analyzers []analyzer.Type
+
+	// disabled are the analyzers whose results are reused
+	disabled []analyzer.Type
+}
+
+func newLayerIndex(c cache.ArtifactCache, ag analyzer.AnalyzerGroup, opt Option,
+	handlerVersions map[string]int) layerIndex {
+	localCache, ok := c.(cache.LocalArtifactCache)
+	if !ok || opt.NoLayerIndex {
+		return layerIndex{}
+	}
+
+	// The options such as the file patterns are also in the analyzer versions
+	versions := ag.AnalyzerVersions()
+	otherVersions := map[string]int{"layer-index": layerIndexVersion}
+	for k, v := range analyzerVersions(ag, opt) {
+		if _, ok := versions[k]; !ok {
+			otherVersions[k] = v
+		}
+	}
+
+	return layerIndex{
+		cache:            c,
+		localCache:       localCache,
+		analyzerVersions: versions,
+		otherVersions:    otherVersions,
+		handlerVersions:  handlerVersions,
+		option:           opt.Option,
+	}
+}
+
+// previous returns the last analysis of the layer with the same options, or false if the layer needs analyzing again
+func (i layerIndex) previous(diffID string) (previousLayer, bool) {
+	if i.localCache == nil {
+		return previousLayer{}, false
+	}
+	indexKey, err := i.key(diffID)
+	if err != nil {
+		return previousLayer{}, false
+	}
+	index, err := i.localCache.GetBlob(indexKey)
+	if err != nil || len(index.CustomResources) != 1 || index.CustomResources[0].Type != layerIndexType {
+		return previousLayer{}, false
+	}
+
+	var versions map[string]int
+	b, err := json.Marshal(index.CustomResources[0].Data)
+	if err != nil {
+		return previousLayer{}, false
+	}
+	if err = json.Unmarshal(b, &versions); err != nil {
+		return previousLayer{}, false
+	}
+
+	// The parts of the analyzers added, removed or upgraded
+	var parts []layerPart
+	for _, k := range append(maps.Keys(versions), maps.Keys(i.analyzerVersions)...) {
+		prev, ok := versions[k]
+		if cur, found := i.analyzerVersions[k]; ok && found && prev == cur {
+			continue
+		}
+		part, ok := partOf(analyzer.Type(k))
+		if !ok {
+			return previousLayer{}, false
+		}
+		if !slices.Contains(parts, part) {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return previousLayer{}, false
+	}
+	if !slices.Contains(parts, osPart) && slices.IndexFunc(parts, layerPart.isAppPart) >= 0 {
+		parts = append(parts, osPart)
+	}
+
+	blob, err := i.localCache.GetBlob(index.CustomResources[0].FilePath)
+	if err != nil {
+		return previousLayer{}, false
+	}
+
+	prev := previousLayer{blob: blob, parts: parts}
+	for k := range i.analyzerVersions {
+		t := analyzer.Type(k)
+		if part, _ := partOf(t); slices.Contains(parts, part) {
+			prev.analyzers = append(prev.analyzers, t)
+		} else {
+			prev.disabled = append(prev.disabled, t)
+		}
+	}
+	slices.Sort(prev.analyzers)
+	slices.Sort(prev.disabled)
+	return prev, true
+}
+
+// put records the blob as the last analysis of the layer
+func (i layerIndex) put(diffID, blobID string) error {
+	if i.localCache == nil {
+		return nil
+	}
+	indexKey, err := i.key(diffID)
+	if err != nil {
+		return err
+	}
+	return i.cache.PutBlob(indexKey, types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		DiffID:        diffID,
+		CustomResources: []types.CustomResource{
+			{
+				Type:     layerIndexType,
+				FilePath: blobID,
+				Data:     i.analyzerVersions,
+			},
+		},
+	})
+}
+
+func (i layerIndex) key(diffID string) (string, error) {
+	key, err := cache.CalcKey(diffID, i.otherVersions, i.handlerVersions, i.option)
+	if err != nil {
+		return "", xerrors.Errorf("unable to calculate the layer index key: %w", err)
+	}
+	return key, nil
+}
+
+// merge replaces the results of the parts analyzed again in the previous blob with the new ones
+func (p previousLayer) merge(blob types.BlobInfo) types.BlobInfo {
+	log.Logger.Debugf("Analyzed the layer %s again with the analyzers: %v", blob.DiffID, p.analyzers)
+	prev := p.blob
+	merged := blob
+
+	if !slices.Contains(p.parts, osPart) {
+		merged.OS = prev.OS
+		merged.Repository = prev.Repository
+		merged.PackageInfos = prev.PackageInfos
+		merged.BuildInfo = prev.BuildInfo
+	}
+	if !slices.Contains(p.parts, configPart) {
+		merged.Misconfigurations = prev.Misconfigurations
+	}
+	if !slices.Contains(p.parts, secretPart) {
+		merged.Secrets = prev.Secrets
+	}
+
+	merged.Applications = nil
+	for _, app := range prev.Applications {
+		if !slices.Contains(p.parts, layerPart(app.Type)) {
+			merged.Applications = append(merged.Applications, app)
+		}
+	}
+	merged.Applications = append(merged.Applications, blob.Applications...)
+
+	// The new resources such as the hashes and the skipped files replace the previous ones of the same files
+	type resourceKey struct{ Type, FilePath string }
+	replaced := map[resourceKey]bool{}
+	for _, r := range blob.CustomResources {
+		replaced[resourceKey{r.Type, r.FilePath}] = true
+	}
+	merged.CustomResources = nil
+	for _, r := range prev.CustomResources {
+		if replaced[resourceKey{r.Type, r.FilePath}] ||
+			(r.Type == pkgtypes.PackageDigestType && slices.Contains(p.parts, digestPart)) {
+			continue
+		}
+		merged.CustomResources = append(merged.CustomResources, r)
+	}
+	merged.CustomResources = append(merged.CustomResources, blob.CustomResources...)
+
+	result := &analyzer.AnalysisResult{
+		PackageInfos:    merged.PackageInfos,
+		Applications:    merged.Applications,
+		Secrets:         merged.Secrets,
+		CustomResources: merged.CustomResources,
+	}
+	sortResult(result)
+	merged.PackageInfos = result.PackageInfos
+	merged.Applications = result.Applications
+	merged.Secrets = result.Secrets
+	merged.CustomResources = result.CustomResources
+	return merged
+}
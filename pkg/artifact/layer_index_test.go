@@ -0,0 +1,127 @@
+package artifact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+)
+
+func TestImageArtifact_Inspect_upgradedAnalyzers(t *testing.T) {
+	img := testImage(t, map[string]string{
+		"etc/alpine-release":    "3.15.4\n",
+		"lib/apk/db/installed":  "P:musl\nV:1.2.2-r7\nA:x86_64\no:musl\n\n",
+		"app/package-lock.json": `{"lockfileVersion": 1, "dependencies": {"lodash": {"version": "4.17.20"}}}`,
+	})
+	staleSecret := types.Secret{FilePath: "app/stale.env"}
+
+	tests := []struct {
+		name     string
+		upgraded map[string]int
+		want     func(fresh types.BlobInfo) types.BlobInfo
+	}{
+		{
+			name:     "only the upgraded analyzer runs again",
+			upgraded: map[string]int{string(analyzer.TypeNpmPkgLock): -1},
+			want: func(fresh types.BlobInfo) types.BlobInfo {
+				// The secrets are reused, and the applications and the OS packages are analyzed again
+				fresh.Secrets = []types.Secret{staleSecret}
+				return fresh
+			},
+		},
+		{
+			name:     "the packages are reused",
+			upgraded: map[string]int{string(analyzer.TypeSecret): -1},
+			want: func(fresh types.BlobInfo) types.BlobInfo {
+				fresh.Applications[0].Libraries = []types.Package{{Name: "lodash", Version: "4.17.19"}}
+				fresh.PackageInfos = nil
+				return fresh
+			},
+		},
+		{
+			name:     "the analyzer whose results can't be told from the others",
+			upgraded: map[string]int{"module:my-module": 1},
+			want: func(fresh types.BlobInfo) types.BlobInfo {
+				return fresh
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			defer c.Close()
+
+			a, err := NewImageArtifact(img, c, Option{})
+			require.NoError(t, err)
+			ref, err := a.Inspect(context.Background())
+			require.NoError(t, err)
+			fresh, err := c.GetBlob(ref.BlobIDs[0])
+			require.NoError(t, err)
+			require.Len(t, fresh.Applications, 1)
+
+			// The layer was analyzed by the analyzers before the upgrade
+			stale := fresh
+			stale.Applications = []types.Application{
+				{
+					Type:     types.Npm,
+					FilePath: "app/package-lock.json",
+					Libraries: []types.Package{
+						{Name: "lodash", Version: "4.17.19"},
+					},
+				},
+			}
+			stale.PackageInfos = nil
+			stale.Secrets = []types.Secret{staleSecret}
+			require.NoError(t, c.PutBlob("sha256:stale", stale))
+
+			ia := a.(ImageArtifact)
+			index := newLayerIndex(c, ia.analyzer, ia.artifactOption, ia.handlerManager.Versions())
+			indexKey, err := index.key(fresh.DiffID)
+			require.NoError(t, err)
+			versions := ia.analyzer.AnalyzerVersions()
+			for k, v := range tt.upgraded {
+				versions[k] += v
+			}
+			require.NoError(t, c.PutBlob(indexKey, types.BlobInfo{
+				SchemaVersion: types.BlobJSONSchemaVersion,
+				DiffID:        fresh.DiffID,
+				CustomResources: []types.CustomResource{
+					{
+						Type:     layerIndexType,
+						FilePath: "sha256:stale",
+						Data:     versions,
+					},
+				},
+			}))
+			require.NoError(t, c.DeleteBlobs([]string{ref.BlobIDs[0]}))
+
+			_, err = a.Inspect(context.Background())
+			require.NoError(t, err)
+			got, err := c.GetBlob(ref.BlobIDs[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.want(fresh), got)
+
+			// The index points to the new blob
+			prev, ok := index.previous(fresh.DiffID)
+			assert.False(t, ok, prev)
+		})
+	}
+}
+
+func Test_newLayerIndex_disabled(t *testing.T) {
+	c, err := cache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	defer c.Close()
+
+	// The index is disabled with the remote cache even if the cache reads the blobs
+	index := newLayerIndex(c, analyzer.NewAnalyzerGroup(analyzer.GroupBuiltin, nil), Option{NoLayerIndex: true}, nil)
+	require.NoError(t, index.put("sha256:24d44f3a", "sha256:stale"))
+	_, ok := index.previous("sha256:24d44f3a")
+	assert.False(t, ok)
+}
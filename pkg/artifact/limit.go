@@ -0,0 +1,105 @@
+package artifact
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// fileCountDepth is the depth of the directories the files are counted in, e.g. usr/lib/python3.10/site-packages,
+// so that the directories with the most files are reported when there are too many files
+const fileCountDepth = 4
+
+// topDirectories is the number of the directories reported when there are too many files
+const topDirectories = 5
+
+// TooManyFilesError is returned when the artifact has more files than the limit.
+// Dirs are the directories with the most files, which are the candidates of '--skip-dirs'.
+type TooManyFilesError struct {
+	Max  int
+	Dirs []DirectoryCount
+}
+
+type DirectoryCount struct {
+	Dir   string
+	Files int
+}
+
+func (e *TooManyFilesError) Error() string {
+	var dirs []string
+	for _, d := range e.Dirs {
+		dirs = append(dirs, fmt.Sprintf("%s (%d)", d.Dir, d.Files))
+	}
+	return fmt.Sprintf("the artifact has more than %d files, the directories with the most files: %s. "+
+		"Skip the directories with '--skip-dirs' or raise '--max-files'", e.Max, strings.Join(dirs, ", "))
+}
+
+// fileCounter counts the files walked in the artifact, and fails the walk once the files exceed the limit.
+// It is shared by the layers of the image, and safe for concurrent use.
+type fileCounter struct {
+	max int
+
+	mu    sync.Mutex
+	files int
+	dirs  map[string]int
+}
+
+// newFileCounter returns the counter of the files, which is nil if there is no limit
+func newFileCounter(max int) *fileCounter {
+	if max <= 0 {
+		return nil
+	}
+	return &fileCounter{
+		max:  max,
+		dirs: map[string]int{},
+	}
+}
+
+// add counts the file, and returns TooManyFilesError if the files exceed the limit
+func (c *fileCounter) add(filePath string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.files++
+	c.dirs[countedDir(filePath)]++
+	if c.files <= c.max {
+		return nil
+	}
+	return xerrors.Errorf("file limit: %w", &TooManyFilesError{
+		Max:  c.max,
+		Dirs: c.topDirs(),
+	})
+}
+
+func (c *fileCounter) topDirs() []DirectoryCount {
+	var dirs []DirectoryCount
+	for dir, n := range c.dirs {
+		dirs = append(dirs, DirectoryCount{Dir: dir, Files: n})
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].Files != dirs[j].Files {
+			return dirs[i].Files > dirs[j].Files
+		}
+		return dirs[i].Dir < dirs[j].Dir
+	})
+	if len(dirs) > topDirectories {
+		dirs = dirs[:topDirectories]
+	}
+	return dirs
+}
+
+// countedDir returns the directory of the file up to fileCountDepth, or "." for the files at the root
+func countedDir(filePath string) string {
+	dir := filepath.ToSlash(filepath.Dir(filePath))
+	if parts := strings.Split(dir, "/"); len(parts) > fileCountDepth {
+		dir = strings.Join(parts[:fileCountDepth], "/")
+	}
+	return dir
+}
@@ -0,0 +1,67 @@
+package artifact
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCounter_add(t *testing.T) {
+	var files []string
+	for i := 0; i < 3; i++ {
+		files = append(files, fmt.Sprintf("usr/lib/python3.10/site-packages/torch/lib/%d.so", i))
+	}
+	for i := 0; i < 2; i++ {
+		files = append(files, fmt.Sprintf("usr/share/doc/%d.txt", i))
+	}
+	files = append(files, "app.py")
+
+	tests := []struct {
+		name     string
+		max      int
+		wantDirs []DirectoryCount
+	}{
+		{
+			name: "within the limit",
+			max:  len(files),
+		},
+		{
+			name: "no limit",
+			max:  0,
+		},
+		{
+			name: "too many files",
+			max:  len(files) - 1,
+			wantDirs: []DirectoryCount{
+				{Dir: "usr/lib/python3.10/site-packages", Files: 3},
+				{Dir: "usr/share/doc", Files: 2},
+				{Dir: ".", Files: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFileCounter(tt.max)
+			var err error
+			for _, f := range files {
+				if err = c.add(f); err != nil {
+					break
+				}
+			}
+			if tt.wantDirs == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var tooMany *TooManyFilesError
+			require.True(t, errors.As(err, &tooMany))
+			assert.Equal(t, tt.max, tooMany.Max)
+			assert.Equal(t, tt.wantDirs, tooMany.Dirs)
+			assert.ErrorContains(t, err, "the artifact has more than 5 files, the directories with the most files: "+
+				"usr/lib/python3.10/site-packages (3), usr/share/doc (2), . (1)")
+		})
+	}
+}
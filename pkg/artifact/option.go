@@ -0,0 +1,65 @@
+package artifact
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/artifact"
+)
+
+// Option extends the option of fanal with the limits of the analysis and the options of the remote artifacts.
+// The options are given to each artifact, so that the concurrent scans, e.g. in the server, don't share them.
+// The zero value of each field is the default of the CLI.
+type Option struct {
+	artifact.Option
+
+	// Parallel is the number of workers analyzing layers and files, DefaultParallel if 0
+	Parallel int
+
+	// MaxMemory is the total size of the files kept in memory during the analysis of an image.
+	// 0 means no limit, but files larger than 200MB are always written to temp files.
+	MaxMemory int64
+
+	// MaxArchiveDepth is how deep the nested archives are unpacked. 0 means the archives are not unpacked.
+	MaxArchiveDepth int
+
+	// FileTimeout is the time budget to analyze each file. 0 means no limit.
+	FileTimeout time.Duration
+
+	// AnalyzerTimeouts are the time budgets of the analyzers to analyze each file, e.g. 10s for the secret analyzer.
+	// The analyzers without them are limited only by FileTimeout.
+	AnalyzerTimeouts map[analyzer.Type]time.Duration
+
+	// MaxFiles is the maximum number of the files analyzed in an artifact. 0 means no limit.
+	MaxFiles int
+
+	// HashAlgorithms are the algorithms of the hashes of the package files, e.g. "sha256".
+	// No algorithm means the files are not hashed.
+	HashAlgorithms []string
+
+	// SSH is how to authenticate to the remote hosts
+	SSH SSHOption
+
+	// RegistryTransport pulls the images from the registries, the default transport of go-containerregistry if nil
+	RegistryTransport http.RoundTripper
+
+	// Docker is the Docker daemon to read the images from
+	Docker DockerDaemon
+
+	// NoLayerIndex disables reusing the last analyses of the layers after the analyzers are upgraded.
+	// The index reads the blobs from the cache, so it must be disabled with the remote cache.
+	NoLayerIndex bool
+
+	// TempDir is the directory of the temporary files, e.g. the images exported from the daemon.
+	// The system temporary directory is used if empty.
+	TempDir string
+}
+
+// parallel returns the number of workers analyzing layers and files
+func (o Option) parallel() int {
+	if o.Parallel <= 0 {
+		return DefaultParallel
+	}
+	return o.Parallel
+}
@@ -0,0 +1,87 @@
+package artifact
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/aquasecurity/fanal/analyzer"
+)
+
+// DefaultParallel is the number of layers and files analyzed at the same time by default
+const DefaultParallel = 5
+
+// workerPool runs the jobs in n goroutines at most, and returns the first error.
+// The context passed to the jobs is canceled when one of them fails.
+type workerPool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	limit  *semaphore.Weighted
+	wg     sync.WaitGroup
+
+	once sync.Once
+	err  error
+}
+
+func newWorkerPool(ctx context.Context, n int) *workerPool {
+	ctx, cancel := context.WithCancel(ctx)
+	return &workerPool{
+		ctx:    ctx,
+		cancel: cancel,
+		limit:  semaphore.NewWeighted(int64(n)),
+	}
+}
+
+// Go blocks until a worker is available, and runs the job in it
+func (p *workerPool) Go(job func(ctx context.Context) error) {
+	if err := p.limit.Acquire(p.ctx, 1); err != nil {
+		p.fail(err)
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.limit.Release(1)
+		defer p.wg.Done()
+		if err := job(p.ctx); err != nil {
+			p.fail(err)
+		}
+	}()
+}
+
+// Wait waits for all the jobs to finish
+func (p *workerPool) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+func (p *workerPool) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// sortResult sorts the analysis result so that it doesn't depend on the order in which files are analyzed
+func sortResult(result *analyzer.AnalysisResult) {
+	result.Sort()
+
+	// Different analyzers may detect applications in the same file
+	sort.Slice(result.Applications, func(i, j int) bool {
+		a, b := result.Applications[i], result.Applications[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Type < b.Type
+	})
+	sort.Strings(result.SystemInstalledFiles)
+	sort.Slice(result.CustomResources, func(i, j int) bool {
+		a, b := result.CustomResources[i], result.CustomResources[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Type < b.Type
+	})
+}
@@ -0,0 +1,87 @@
+package artifact
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestOption_parallel(t *testing.T) {
+	assert.Equal(t, 3, Option{Parallel: 3}.parallel())
+	assert.Equal(t, DefaultParallel, Option{}.parallel())
+}
+
+func Test_workerPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		jobs    int
+		failAt  int
+		wantErr string
+	}{
+		{
+			name:    "happy path",
+			workers: 3,
+			jobs:    20,
+			failAt:  -1,
+		},
+		{
+			name:    "single worker",
+			workers: 1,
+			jobs:    5,
+			failAt:  -1,
+		},
+		{
+			name:    "sad path",
+			workers: 2,
+			jobs:    10,
+			failAt:  3,
+			wantErr: "job 3 failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning, done int32
+			pool := newWorkerPool(context.Background(), tt.workers)
+			for i := 0; i < tt.jobs; i++ {
+				i := i
+				pool.Go(func(ctx context.Context) error {
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						m := atomic.LoadInt32(&maxRunning)
+						if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+							break
+						}
+					}
+					if i == tt.failAt {
+						return xerrors.Errorf("job %d failed", i)
+					}
+
+					select {
+					case <-time.After(10 * time.Millisecond):
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+					atomic.AddInt32(&done, 1)
+					return nil
+				})
+			}
+			err := pool.Wait()
+			assert.LessOrEqual(t, int(maxRunning), tt.workers)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				assert.Less(t, int(done), tt.jobs-1, "the rest of the jobs should be canceled")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.jobs, int(done))
+		})
+	}
+}
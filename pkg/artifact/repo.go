@@ -0,0 +1,105 @@
+package artifact
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+)
+
+// RepositoryArtifact clones the repository in the same way as the remote artifact of fanal,
+// and inspects it with FilesystemArtifact so that the skipped paths are the same as filesystems.
+type RepositoryArtifact struct {
+	url   string
+	local artifact.Artifact
+}
+
+// NewRepositoryArtifact clones the repository into a temp directory, which is removed by the returned function
+func NewRepositoryArtifact(rawurl string, c cache.ArtifactCache, artifactOpt Option) (
+	artifact.Artifact, func(), error) {
+	cleanup := func() {}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, cleanup, xerrors.Errorf("url parse error: %w", err)
+	}
+	// "https://" can be omitted, e.g. github.com/aquasecurity/trivy
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+
+	tmpDir, err := os.MkdirTemp(artifactOpt.TempDir, "trivy-repo-*")
+	if err != nil {
+		return nil, cleanup, xerrors.Errorf("failed to create a temp directory: %w", err)
+	}
+	cleanup = func() {
+		_ = os.RemoveAll(tmpDir)
+	}
+
+	cloneOptions := git.CloneOptions{
+		URL:             u.String(),
+		Auth:            gitAuth(),
+		Progress:        os.Stdout,
+		Depth:           1,
+		InsecureSkipTLS: artifactOpt.InsecureSkipTLS,
+	}
+
+	// suppress clone output if noProgress
+	if artifactOpt.NoProgress {
+		cloneOptions.Progress = nil
+	}
+
+	if _, err = git.PlainClone(tmpDir, false, &cloneOptions); err != nil {
+		return nil, cleanup, xerrors.Errorf("git error: %w", err)
+	}
+
+	art, err := NewFilesystemArtifact(tmpDir, c, artifactOpt)
+	if err != nil {
+		return nil, cleanup, xerrors.Errorf("fs artifact: %w", err)
+	}
+
+	return RepositoryArtifact{
+		url:   rawurl,
+		local: art,
+	}, cleanup, nil
+}
+
+func (a RepositoryArtifact) Inspect(ctx context.Context) (types.ArtifactReference, error) {
+	ref, err := a.local.Inspect(ctx)
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("remote repository error: %w", err)
+	}
+
+	ref.Name = a.url
+	ref.Type = types.ArtifactRemoteRepository
+
+	return ref, nil
+}
+
+func (RepositoryArtifact) Clean(_ types.ArtifactReference) error {
+	return nil
+}
+
+// gitAuth returns the GitHub or GitLab token in the environment variables to access private repositories.
+// nil makes the requests unauthenticated.
+func gitAuth() *http.BasicAuth {
+	// The username can be anything for HTTPS Git operations
+	const gitUsername = "fanal-aquasecurity-scan"
+
+	for _, env := range []string{"GITHUB_TOKEN", "GITLAB_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return &http.BasicAuth{
+				Username: gitUsername,
+				Password: token,
+			}
+		}
+	}
+	return nil
+}
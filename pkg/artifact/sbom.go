@@ -0,0 +1,62 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+)
+
+// SBOM holds the packages of the image listed by its SBOM, e.g. attached to the image by "cosign attest"
+type SBOM struct {
+	Blob     types.BlobInfo
+	Metadata types.ImageMetadata
+}
+
+// SBOMArtifact returns the packages listed by the SBOM as the blob of the image, so that the image
+// can be scanned without pulling and analyzing the layers.
+type SBOMArtifact struct {
+	name  string
+	sbom  SBOM
+	cache cache.ArtifactCache
+}
+
+// NewSBOMArtifact returns the artifact of the image with the packages listed by the SBOM
+func NewSBOMArtifact(name string, sbom SBOM, c cache.ArtifactCache) artifact.Artifact {
+	return SBOMArtifact{
+		name:  name,
+		sbom:  sbom,
+		cache: c,
+	}
+}
+
+func (a SBOMArtifact) Inspect(_ context.Context) (types.ArtifactReference, error) {
+	// calculate hash of JSON and use it as pseudo artifactID and blobID
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(a.sbom.Blob); err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("json error: %w", err)
+	}
+	cacheKey := digest.NewDigest(digest.SHA256, h).String()
+
+	if err := a.cache.PutBlob(cacheKey, a.sbom.Blob); err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("failed to store blob (%s) in cache: %w", cacheKey, err)
+	}
+
+	return types.ArtifactReference{
+		Name:          a.name,
+		Type:          types.ArtifactContainerImage,
+		ID:            cacheKey, // use a cache key as pseudo artifact ID
+		BlobIDs:       []string{cacheKey},
+		ImageMetadata: a.sbom.Metadata,
+	}, nil
+}
+
+func (a SBOMArtifact) Clean(reference types.ArtifactReference) error {
+	return a.cache.DeleteBlobs(reference.BlobIDs)
+}
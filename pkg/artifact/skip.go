@@ -0,0 +1,106 @@
+package artifact
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	"golang.org/x/exp/slices"
+
+	"github.com/aquasecurity/fanal/walker"
+)
+
+// pathMatcher decides the files and directories skipped with '--skip-files' and '--skip-dirs'.
+// The paths are exact paths or doublestar globs, e.g. "**/node_modules/**" and "**/testdata".
+type pathMatcher struct {
+	files []string
+	dirs  []string
+}
+
+// newPathMatcher returns the matcher of the paths in layers, which are relative to the root without "/"
+func newPathMatcher(skipFiles, skipDirs []string) pathMatcher {
+	clean := func(p string) string {
+		return strings.TrimLeft(path.Clean(filepath.ToSlash(p)), "/")
+	}
+
+	var m pathMatcher
+	for _, skipFile := range skipFiles {
+		m.files = append(m.files, clean(skipFile))
+	}
+	for _, skipDir := range append(skipDirs, walker.SystemDirs...) {
+		m.dirs = append(m.dirs, clean(skipDir))
+	}
+	return m
+}
+
+// newFSPathMatcher returns the matcher of the paths under the root, where the relative paths are of the root
+func newFSPathMatcher(root string, skipFiles, skipDirs []string) pathMatcher {
+	// System directories are relative, in the same way as the filesystem walker of fanal
+	abs := func(paths []string) []string {
+		var absPaths []string
+		for _, p := range paths {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(root, p)
+			}
+			absPaths = append(absPaths, filepath.ToSlash(filepath.Clean(p)))
+		}
+		return absPaths
+	}
+	return pathMatcher{
+		files: abs(skipFiles),
+		dirs:  append(abs(skipDirs), walker.SystemDirs...),
+	}
+}
+
+func (m pathMatcher) skipFile(filePath string) bool {
+	filePath = filepath.ToSlash(filePath)
+	for _, pattern := range m.files {
+		if matchPath(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m pathMatcher) skipDir(dir string) bool {
+	dir = filepath.ToSlash(dir)
+
+	// Skip application dirs (relative path)
+	if slices.Contains(walker.AppDirs, path.Base(dir)) {
+		return true
+	}
+
+	// "**/node_modules/**" skips the directory itself as well
+	for _, pattern := range m.dirs {
+		if matchPath(pattern, dir) || matchPath(strings.TrimSuffix(pattern, "/**"), dir) {
+			return true
+		}
+	}
+	// All the files in the directory are skipped with '--skip-files', e.g. "**/testdata/**"
+	for _, pattern := range m.files {
+		if strings.HasSuffix(pattern, "/**") && matchPath(strings.TrimSuffix(pattern, "/**"), dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// underSkippedDir returns true if any parent directory of the path is skipped,
+// as the directories are not always included in layers.
+func (m pathMatcher) underSkippedDir(filePath string) bool {
+	for dir := path.Dir(filepath.ToSlash(filePath)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if m.skipDir(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(pattern, p string) bool {
+	if pattern == p {
+		return true
+	}
+	matched, _ := doublestar.Match(pattern, p)
+	return matched
+}
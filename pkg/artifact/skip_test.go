@@ -0,0 +1,92 @@
+package artifact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_pathMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		matcher   pathMatcher
+		files     map[string]bool
+		dirs      map[string]bool
+		underDirs map[string]bool
+	}{
+		{
+			name:    "exact paths",
+			matcher: newPathMatcher([]string{"/app/index.html"}, []string{"app/web/"}),
+			files: map[string]bool{
+				"app/index.html":     true,
+				"app/index.htm":      false,
+				"src/app/index.html": false,
+			},
+			dirs: map[string]bool{
+				"app/web": true,
+				"app":     false,
+				"proc":    true,
+				"a/.git":  true,
+			},
+			underDirs: map[string]bool{
+				"app/web/a/b.js": true,
+				"app/a.js":       false,
+				"proc/1/status":  true,
+			},
+		},
+		{
+			name:    "globs",
+			matcher: newPathMatcher([]string{"**/*.md", "**/testdata/**"}, []string{"**/node_modules/**", "/usr/lib/python*"}),
+			files: map[string]bool{
+				"README.md":             true,
+				"docs/a/b.md":           true,
+				"pkg/testdata/a.json":   true,
+				"testdata/x/y/z":        true,
+				"pkg/testdata.go":       false,
+				"node_modules/lodash/a": false,
+			},
+			dirs: map[string]bool{
+				"node_modules":         true,
+				"app/node_modules":     true,
+				"app/node_modules/a/b": true,
+				"usr/lib/python3.9":    true,
+				"usr/lib/perl5":        false,
+				"pkg/testdata":         true,
+			},
+			underDirs: map[string]bool{
+				"app/node_modules/lodash/package.json": true,
+				"usr/lib/python3.9/site-packages/a.py": true,
+				"app/package.json":                     false,
+			},
+		},
+		{
+			name:    "filesystem",
+			matcher: newFSPathMatcher("/src", []string{"**/*.min.js", "/etc/passwd"}, []string{"**/testdata", "vendor2"}),
+			files: map[string]bool{
+				"/src/web/a.min.js": true,
+				"/src/web/a.js":     false,
+				"/etc/passwd":       true,
+				"/other/a.min.js":   false,
+			},
+			dirs: map[string]bool{
+				"/src/pkg/testdata": true,
+				"/src/vendor2":      true,
+				"/src/pkg/vendor2":  false,
+				"/src/vendor":       true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for p, want := range tt.files {
+				assert.Equal(t, want, tt.matcher.skipFile(p), "file: %s", p)
+			}
+			for p, want := range tt.dirs {
+				assert.Equal(t, want, tt.matcher.skipDir(p), "dir: %s", p)
+			}
+			for p, want := range tt.underDirs {
+				assert.Equal(t, want, tt.matcher.underSkippedDir(p), "under dir: %s", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package artifact
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/fanal/walker"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// SSHScheme is the scheme of the remote hosts scanned with '--remote', e.g. ssh://user@host:22/path
+const SSHScheme = "ssh"
+
+// defaultKeyFiles are tried in order when no key is given, in the same way as OpenSSH
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// SSHOption is how to authenticate to the remote hosts
+type SSHOption struct {
+	// KeyFile is the private key, and the default keys in ~/.ssh are used if empty
+	KeyFile string
+
+	// KnownHostsFile has the host keys to verify the remote hosts, ~/.ssh/known_hosts if empty
+	KnownHostsFile string
+}
+
+// SSHArtifact inspects the filesystem of the remote host over SFTP with FilesystemArtifact,
+// so that the analyzers run locally without installing Trivy on the host.
+type SSHArtifact struct {
+	url   string
+	local artifact.Artifact
+}
+
+// NewSSHArtifact connects to the remote host, and the connection is closed by the returned function
+func NewSSHArtifact(rawurl string, c cache.ArtifactCache, artifactOpt Option) (artifact.Artifact, func(), error) {
+	cleanup := func() {}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, cleanup, xerrors.Errorf("url parse error: %w", err)
+	} else if u.Scheme != SSHScheme || u.Hostname() == "" {
+		return nil, cleanup, xerrors.Errorf("invalid remote %q, must be ssh://[user@]host[:port]/path", rawurl)
+	}
+
+	conn, err := dialSSH(u, artifactOpt.SSH)
+	if err != nil {
+		return nil, cleanup, xerrors.Errorf("ssh error (%s): %w", u.Host, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, cleanup, xerrors.Errorf("sftp error (%s): %w", u.Host, err)
+	}
+	cleanup = func() {
+		_ = client.Close()
+		_ = conn.Close()
+	}
+
+	rootPath := u.Path
+	if rootPath == "" {
+		rootPath = "/"
+	}
+	art, err := newFilesystemArtifact(rootPath, c, artifactOpt)
+	if err != nil {
+		cleanup()
+		return nil, func() {}, xerrors.Errorf("fs artifact: %w", err)
+	}
+	art.walk = func(root string, matcher pathMatcher, walkFn walker.WalkFunc) error {
+		return walkSFTP(client, root, matcher, walkFn)
+	}
+	art.readFile = func(name string) ([]byte, error) {
+		f, err := client.Open(filepath.ToSlash(name))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+
+	return SSHArtifact{
+		url:   u.Redacted(),
+		local: art,
+	}, cleanup, nil
+}
+
+func (a SSHArtifact) Inspect(ctx context.Context) (types.ArtifactReference, error) {
+	ref, err := a.local.Inspect(ctx)
+	if err != nil {
+		return types.ArtifactReference{}, xerrors.Errorf("remote filesystem error: %w", err)
+	}
+
+	ref.Name = a.url
+	return ref, nil
+}
+
+func (a SSHArtifact) Clean(reference types.ArtifactReference) error {
+	return a.local.Clean(reference)
+}
+
+// walkSFTP walks the file tree on the remote host in the same way as walkFS.
+// Only the files required by the analyzers are read over the connection.
+func walkSFTP(client *sftp.Client, root string, matcher pathMatcher, walkFn walker.WalkFunc) error {
+	w := client.Walk(filepath.ToSlash(root))
+	for w.Step() {
+		pathname := path.Clean(w.Path())
+		if err := w.Err(); err != nil {
+			// ignore permission errors
+			if os.IsPermission(err) {
+				continue
+			}
+			// halt traversal on any other error
+			return xerrors.Errorf("unknown error with %s: %w", pathname, err)
+		}
+
+		fi := w.Stat()
+		if fi.IsDir() {
+			if matcher.skipDir(pathname) {
+				w.SkipDir()
+			}
+			continue
+		} else if !fi.Mode().IsRegular() {
+			continue
+		} else if matcher.skipFile(pathname) {
+			continue
+		}
+
+		opener := func() (dio.ReadSeekCloserAt, error) {
+			return client.Open(pathname)
+		}
+		if err := walkFn(pathname, fi, opener); err != nil {
+			return xerrors.Errorf("failed to analyze file: %w", err)
+		}
+	}
+	return nil
+}
+
+// dialSSH connects to the host with ssh-agent, the private key or the password in the URL,
+// verifying the host key with known_hosts
+func dialSSH(u *url.URL, opt SSHOption) (*ssh.Client, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to get the home dir: %w", err)
+	}
+
+	knownHostsFile := opt.KnownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, xerrors.Errorf("known hosts error: %w", err)
+	}
+
+	var auths []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if agentConn, err := net.Dial("unix", sock); err == nil {
+			auths = append(auths, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		}
+	}
+
+	keyFiles := []string{opt.KeyFile}
+	if opt.KeyFile == "" {
+		keyFiles = nil
+		for _, name := range defaultKeyFiles {
+			keyFiles = append(keyFiles, filepath.Join(home, ".ssh", name))
+		}
+	}
+	for _, keyFile := range keyFiles {
+		b, err := os.ReadFile(keyFile)
+		if os.IsNotExist(err) && opt.KeyFile == "" {
+			continue
+		} else if err != nil {
+			return nil, xerrors.Errorf("private key read error: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(b)
+		if err != nil && opt.KeyFile == "" {
+			// The default keys protected by passphrases are expected to be in ssh-agent
+			log.Logger.Debugf("Skipping the private key (%s): %s", keyFile, err)
+			continue
+		} else if err != nil {
+			return nil, xerrors.Errorf("private key parse error (%s), use ssh-agent for encrypted keys: %w", keyFile, err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+
+	if password, ok := u.User.Password(); ok {
+		auths = append(auths, ssh.Password(password))
+	}
+
+	username := u.User.Username()
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, xerrors.Errorf("unable to get the current user: %w", err)
+		}
+		username = current.Username
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+
+	config := &ssh.ClientConfig{
+		User:            username,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+	}
+	return ssh.Dial("tcp", net.JoinHostPort(u.Hostname(), port), config)
+}
@@ -0,0 +1,168 @@
+package artifact
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/aquasecurity/fanal/cache"
+)
+
+func TestSSHArtifact_Inspect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app", "package-lock.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	lock := `{"lockfileVersion": 1, "dependencies": {"lodash": {"version": "4.17.20"}}}`
+	require.NoError(t, os.WriteFile(path, []byte(lock), 0644))
+
+	// The blob must be the same as the local filesystem
+	want := inspectFilesystem(t, dir, Option{}, NewFilesystemArtifact)
+	require.Len(t, want.Applications, 1)
+
+	addr, hostKey := startSFTPServer(t, "trivy", "secret")
+	_, otherKey := startSFTPServer(t, "trivy", "secret")
+
+	// No keys in ~/.ssh and ssh-agent
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	tests := []struct {
+		name     string
+		url      string
+		hostKey  ssh.PublicKey
+		wantName string
+		wantErr  string
+	}{
+		{
+			name:     "happy path",
+			url:      fmt.Sprintf("ssh://trivy:secret@%s%s", addr, dir),
+			hostKey:  hostKey,
+			wantName: fmt.Sprintf("ssh://trivy:xxxxx@%s%s", addr, dir),
+		},
+		{
+			name:    "sad path: wrong password",
+			url:     fmt.Sprintf("ssh://trivy:wrong@%s%s", addr, dir),
+			hostKey: hostKey,
+			wantErr: "unable to authenticate",
+		},
+		{
+			name:    "sad path: host key mismatch",
+			url:     fmt.Sprintf("ssh://trivy:secret@%s%s", addr, dir),
+			hostKey: otherKey,
+			wantErr: "key mismatch",
+		},
+		{
+			name:    "sad path: not ssh",
+			url:     "sftp://trivy@example.com/",
+			hostKey: hostKey,
+			wantErr: `invalid remote "sftp://trivy@example.com/"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+			line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, tt.hostKey)
+			require.NoError(t, os.WriteFile(knownHosts, []byte(line+"\n"), 0600))
+
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			defer c.Close()
+
+			a, cleanup, err := NewSSHArtifact(tt.url, c, Option{SSH: SSHOption{KnownHostsFile: knownHosts}})
+			defer cleanup()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ref, err := a.Inspect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, ref.Name)
+
+			require.Len(t, ref.BlobIDs, 1)
+			got, err := c.GetBlob(ref.BlobIDs[0])
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+// startSFTPServer starts the SSH server serving the local filesystem over SFTP,
+// and returns the address and the host key
+func startSFTPServer(t *testing.T, user, password string) (string, ssh.PublicKey) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, p []byte) (*ssh.Permissions, error) {
+			if conn.User() == user && string(p) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %s", conn.User())
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config)
+		}
+	}()
+	return l.Addr().String(), signer.PublicKey()
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func(in <-chan *ssh.Request) {
+			for req := range in {
+				// "subsystem" request with "sftp" as the name
+				_ = req.Reply(req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}(requests)
+
+		server, err := sftp.NewServer(channel)
+		if err != nil {
+			return
+		}
+		_ = server.Serve()
+		_ = server.Close()
+	}
+}
@@ -0,0 +1,254 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/walker"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+)
+
+const (
+	opq string = ".wh..wh..opq"
+	wh  string = ".wh."
+)
+
+// LayerTar walks the layer in the same way as the layer tar walker of fanal, but the skipped paths can be globs,
+// and the files are kept in memory only while the memory limit allows it. The other files are written to temp files,
+// which are removed once all the analyzers have closed them.
+type LayerTar struct {
+	matcher pathMatcher
+	mem     *memoryLimit
+	tempDir string
+}
+
+// NewLayerTar returns the walker of layers skipping the given files and directories
+func NewLayerTar(skipFiles, skipDirs []string) LayerTar {
+	return LayerTar{matcher: newPathMatcher(skipFiles, skipDirs)}
+}
+
+// withMemoryLimit returns the walker sharing the memory limit with other walkers
+func (w LayerTar) withMemoryLimit(mem *memoryLimit) LayerTar {
+	w.mem = mem
+	return w
+}
+
+// withTempDir returns the walker writing the temp files to the directory
+func (w LayerTar) withTempDir(dir string) LayerTar {
+	w.tempDir = dir
+	return w
+}
+
+// Walk reads the entries of the layer one by one, and returns the opaque directories and the whiteout files.
+func (w LayerTar) Walk(layer io.Reader, analyzeFn walker.WalkFunc) ([]string, []string, error) {
+	var opqDirs, whFiles []string
+	tr := tar.NewReader(layer)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, xerrors.Errorf("failed to extract the archive: %w", err)
+		}
+
+		filePath := strings.TrimLeft(filepath.Clean(hdr.Name), "/")
+		fileDir, fileName := filepath.Split(filePath)
+
+		// e.g. etc/.wh..wh..opq
+		if opq == fileName {
+			opqDirs = append(opqDirs, fileDir)
+			continue
+		}
+		// etc/.wh.hostname
+		if strings.HasPrefix(fileName, wh) {
+			whFiles = append(whFiles, filepath.Join(fileDir, strings.TrimPrefix(fileName, wh)))
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if w.matcher.skipDir(filePath) {
+				continue
+			}
+		case tar.TypeSymlink, tar.TypeLink, tar.TypeReg:
+			if w.matcher.skipFile(filePath) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if w.matcher.underSkippedDir(filePath) {
+			continue
+		}
+
+		// A symbolic/hard link or regular file will reach here.
+		if err = w.processFile(filePath, tr, hdr.FileInfo(), analyzeFn); err != nil {
+			return nil, nil, xerrors.Errorf("failed to process the file: %w", err)
+		}
+	}
+	return opqDirs, whFiles, nil
+}
+
+func (w LayerTar) processFile(filePath string, tr *tar.Reader, fi fs.FileInfo, analyzeFn walker.WalkFunc) error {
+	tf := newTarFile(fi.Size(), tr, w.mem, w.tempDir)
+	defer tf.clean()
+
+	if err := analyzeFn(filePath, fi, tf.Open); err != nil {
+		return xerrors.Errorf("failed to analyze file: %w", err)
+	}
+	return nil
+}
+
+// memoryLimit limits the total size of the files kept in memory during the analysis. nil means no limit.
+type memoryLimit struct {
+	sem *semaphore.Weighted
+}
+
+func newMemoryLimit(max int64) *memoryLimit {
+	if max <= 0 {
+		return nil
+	}
+	return &memoryLimit{sem: semaphore.NewWeighted(max)}
+}
+
+// tryAcquire doesn't block, so that the file is written to a temp file instead of waiting for other files
+func (m *memoryLimit) tryAcquire(n int64) bool {
+	if m == nil {
+		return true
+	}
+	return m.sem.TryAcquire(n)
+}
+
+func (m *memoryLimit) release(n int64) {
+	if m == nil {
+		return
+	}
+	m.sem.Release(n)
+}
+
+// tarFile represents a file in the layer, which is read once and shared by the analyzers.
+// The content is released when the walker has moved on to the next entry and all the analyzers have closed it,
+// as the analyzers run in goroutines.
+type tarFile struct {
+	once    sync.Once
+	err     error
+	size    int64
+	reader  io.Reader
+	mem     *memoryLimit
+	tempDir string
+
+	mu      sync.Mutex
+	refs    int
+	cleaned bool
+
+	content  []byte // It will be populated if the file is kept in memory
+	filePath string // It will be populated if the file is written to a temp file
+}
+
+func newTarFile(size int64, r io.Reader, mem *memoryLimit, tempDir string) *tarFile {
+	return &tarFile{
+		size:    size,
+		reader:  r,
+		mem:     mem,
+		tempDir: tempDir,
+	}
+}
+
+// Open reads the file at the first call, and opens the content shared by the analyzers.
+func (o *tarFile) Open() (dio.ReadSeekCloserAt, error) {
+	o.once.Do(func() {
+		if o.size < walker.ThresholdSize && o.mem.tryAcquire(o.size) {
+			b, err := io.ReadAll(o.reader)
+			if err != nil {
+				o.mem.release(o.size)
+				o.err = xerrors.Errorf("unable to read the file: %w", err)
+				return
+			}
+			o.content = b
+			return
+		}
+
+		// The file is too large or the memory limit is reached
+		f, err := os.CreateTemp(o.tempDir, "trivy-layer-*")
+		if err != nil {
+			o.err = xerrors.Errorf("failed to create the temp file: %w", err)
+			return
+		}
+		o.filePath = f.Name()
+		defer f.Close()
+
+		if _, err = io.Copy(f, o.reader); err != nil {
+			o.err = xerrors.Errorf("failed to copy: %w", err)
+		}
+	})
+	if o.err != nil {
+		return nil, xerrors.Errorf("failed to open: %w", o.err)
+	}
+
+	var rc dio.ReadSeekCloserAt
+	if o.filePath != "" {
+		f, err := os.Open(o.filePath)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to open the temp file: %w", err)
+		}
+		rc = f
+	} else {
+		rc = dio.NopCloser(bytes.NewReader(o.content))
+	}
+
+	o.mu.Lock()
+	o.refs++
+	o.mu.Unlock()
+	return &tarFileReader{ReadSeekCloserAt: rc, file: o}, nil
+}
+
+// clean is called when the walker has moved on to the next entry
+func (o *tarFile) clean() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cleaned = true
+	o.releaseIfUnused()
+}
+
+func (o *tarFile) closeReader() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.refs--
+	o.releaseIfUnused()
+}
+
+func (o *tarFile) releaseIfUnused() {
+	if !o.cleaned || o.refs > 0 {
+		return
+	}
+	if o.content != nil {
+		o.content = nil
+		o.mem.release(o.size)
+	}
+	if o.filePath != "" {
+		_ = os.Remove(o.filePath)
+		o.filePath = ""
+	}
+}
+
+type tarFileReader struct {
+	dio.ReadSeekCloserAt
+	file *tarFile
+	once sync.Once
+}
+
+func (r *tarFileReader) Close() error {
+	err := r.ReadSeekCloserAt.Close()
+	r.once.Do(r.file.closeReader)
+	return err
+}
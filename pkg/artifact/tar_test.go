@@ -0,0 +1,177 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+}
+
+func testLayer(t *testing.T, entries []tarEntry) io.Reader {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		typeflag := e.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: typeflag,
+			Mode:     0644,
+			Size:     int64(len(e.content)),
+		}))
+		_, err := tw.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return &buf
+}
+
+func TestLayerTar_Walk(t *testing.T) {
+	entries := []tarEntry{
+		{name: "etc/", typeflag: tar.TypeDir},
+		{name: "etc/.wh..wh..opq"},
+		{name: "etc/alpine-release", content: "3.15.4"},
+		{name: "foo/.wh.foo"},
+		{name: "app/", typeflag: tar.TypeDir},
+		{name: "app/index.html", content: "<html>"},
+		{name: "app/vendor/", typeflag: tar.TypeDir},
+		{name: "app/vendor/lib.js", content: "lib"},
+		{name: "proc/", typeflag: tar.TypeDir},
+		{name: "proc/1/status", content: "status"},
+		{name: "secret.txt", content: "secret"},
+	}
+	tests := []struct {
+		name        string
+		skipFiles   []string
+		skipDirs    []string
+		want        []string
+		wantOpqDirs []string
+		wantWhFiles []string
+	}{
+		{
+			name:        "happy path",
+			want:        []string{"etc/alpine-release", "app/index.html", "secret.txt"},
+			wantOpqDirs: []string{"etc/"},
+			wantWhFiles: []string{"foo/foo"},
+		},
+		{
+			name:        "skip files and dirs",
+			skipFiles:   []string{"/secret.txt"},
+			skipDirs:    []string{"app"},
+			want:        []string{"etc/alpine-release"},
+			wantOpqDirs: []string{"etc/"},
+			wantWhFiles: []string{"foo/foo"},
+		},
+		{
+			name:        "skip globs",
+			skipFiles:   []string{"**/*.txt"},
+			skipDirs:    []string{"**/app/**"},
+			want:        []string{"etc/alpine-release"},
+			wantOpqDirs: []string{"etc/"},
+			wantWhFiles: []string{"foo/foo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			w := NewLayerTar(tt.skipFiles, tt.skipDirs)
+			opqDirs, whFiles, err := w.Walk(testLayer(t, entries),
+				func(filePath string, info os.FileInfo, _ analyzer.Opener) error {
+					// Directories are skipped by analyzers
+					if !info.IsDir() {
+						got = append(got, filePath)
+					}
+					return nil
+				})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOpqDirs, opqDirs)
+			assert.Equal(t, tt.wantWhFiles, whFiles)
+		})
+	}
+}
+
+func TestLayerTar_Walk_memoryLimit(t *testing.T) {
+	entries := []tarEntry{
+		{name: "a.txt", content: "aaaaaaaa"},
+		{name: "b.txt", content: "bbbbbbbb"},
+		{name: "c.txt", content: "cccccccc"},
+	}
+
+	// The reader of a.txt is closed after b.txt is read, like analyzers running in goroutines
+	var held dio.ReadSeekCloserAt
+	var tempFile string
+	spilled := map[string]bool{}
+	tempDir := t.TempDir()
+	w := NewLayerTar(nil, nil).withTempDir(tempDir).withMemoryLimit(newMemoryLimit(10))
+	_, _, err := w.Walk(testLayer(t, entries),
+		func(filePath string, _ os.FileInfo, opener analyzer.Opener) error {
+			rc, err := opener()
+			require.NoError(t, err)
+
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Len(t, b, 8)
+
+			if f, ok := rc.(*tarFileReader).ReadSeekCloserAt.(*os.File); ok {
+				spilled[filePath] = true
+				tempFile = f.Name()
+			}
+
+			switch filePath {
+			case "a.txt":
+				held = rc
+				return nil
+			case "b.txt":
+				require.NoError(t, held.Close())
+			}
+			return rc.Close()
+		})
+	require.NoError(t, err)
+
+	// b.txt exceeds the limit while a.txt is in memory, and c.txt is in memory again after a.txt is released
+	assert.Equal(t, map[string]bool{"b.txt": true}, spilled)
+	assert.Equal(t, tempDir, filepath.Dir(tempFile))
+	assert.NoFileExists(t, tempFile)
+}
+
+func TestTarFile_Open(t *testing.T) {
+	mem := newMemoryLimit(10)
+	tf := newTarFile(5, bytes.NewReader([]byte("hello")), mem, "")
+
+	// The content is shared by the analyzers
+	r1, err := tf.Open()
+	require.NoError(t, err)
+	r2, err := tf.Open()
+	require.NoError(t, err)
+	for _, r := range []io.Reader{r1, r2} {
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(b))
+	}
+
+	// The memory is released after the walker moves on and all the readers are closed
+	tf.clean()
+	require.NoError(t, r1.Close())
+	require.NoError(t, r1.Close())
+	assert.False(t, mem.tryAcquire(10))
+
+	require.NoError(t, r2.Close())
+	assert.True(t, mem.tryAcquire(10))
+}
@@ -0,0 +1,231 @@
+package artifact
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+	"github.com/aquasecurity/trivy/pkg/log"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+// errFileTimeout is returned from the reads of the file whose analysis timed out
+var errFileTimeout = xerrors.New("file analysis timed out")
+
+// fileAnalyzeFunc analyzes the file with the opener, writing to the result in the goroutines added to the wait group
+type fileAnalyzeFunc func(wg *sync.WaitGroup, result *analyzer.AnalysisResult, opener analyzer.Opener) error
+
+// fileBudget gives up the analysis of a file after the timeout, so that a pathological file
+// (e.g. a giant minified JS or a corrupt archive) doesn't eat the whole '--timeout'.
+// The file is recorded as skipped instead, and the rest of the files are analyzed as usual.
+type fileBudget struct {
+	timeout time.Duration
+
+	// analyzer is the analyzer given its own budget, or empty for all the analyzers of the file
+	analyzer analyzer.Type
+}
+
+func newFileBudget(timeout time.Duration) fileBudget {
+	return fileBudget{timeout: timeout}
+}
+
+// reason returns why the file is skipped
+func (b fileBudget) reason() string {
+	if b.analyzer == "" {
+		return fmt.Sprintf("analysis timed out after %s", b.timeout)
+	}
+	return fmt.Sprintf("analysis by the %s analyzer timed out after %s", b.analyzer, b.timeout)
+}
+
+// analyze calls analyzeFn with the wait group and the result of the file, and merges the result
+// only when all the analyzers of the file finish within the timeout.
+// The timer starts when the analyzers start reading the file, so that waiting for the other files isn't counted.
+// Once the timer expires, the reads of the file fail and the analyzers are not waited for any more.
+// The analyzers busy without reading can't be interrupted, and keep their workers until they finish.
+func (b fileBudget) analyze(wg *sync.WaitGroup, result *analyzer.AnalysisResult, filePath string,
+	opener analyzer.Opener, analyzeFn fileAnalyzeFunc) error {
+	if b.timeout <= 0 {
+		return analyzeFn(wg, result, opener)
+	}
+
+	var fileWg sync.WaitGroup
+	fileResult := analyzer.NewAnalysisResult()
+	timer := newFileTimer(b.timeout)
+	err := analyzeFn(&fileWg, fileResult, timer.opener(opener))
+
+	done := make(chan struct{})
+	go func() {
+		fileWg.Wait()
+		close(done)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-done:
+		case <-timer.expired:
+		}
+
+		// The analyzers may finish with partial results because the reads failed
+		if timer.stop() {
+			result.Merge(fileResult)
+			return
+		}
+		log.Logger.Warnf("The %s of %s, the file is skipped", b.reason(), filePath)
+		result.Merge(&analyzer.AnalysisResult{
+			CustomResources: []types.CustomResource{
+				{
+					Type:     pkgtypes.SkippedFileType,
+					FilePath: filePath,
+					Data:     b.reason(),
+				},
+			},
+		})
+	}()
+	return err
+}
+
+// analyzerBudgets gives the analyzers their own time budgets per file with '--analyzer-timeout',
+// e.g. the secret analyzer on a giant minified JS, so that only the results of the analyzer are given up
+// and the file is still analyzed by the other analyzers.
+type analyzerBudgets []analyzerBudget
+
+// analyzerBudget is the budget of the analyzer, which analyzes the file with all the other analyzers disabled
+type analyzerBudget struct {
+	fileBudget
+	others []analyzer.Type
+}
+
+func newAnalyzerBudgets(ag analyzer.AnalyzerGroup, timeouts map[analyzer.Type]time.Duration) analyzerBudgets {
+	versions := ag.AnalyzerVersions()
+	var budgets analyzerBudgets
+	for t, timeout := range timeouts {
+		if _, ok := versions[string(t)]; !ok || timeout <= 0 {
+			continue
+		}
+		budget := analyzerBudget{fileBudget: fileBudget{timeout: timeout, analyzer: t}}
+		for other := range versions {
+			if other != string(t) {
+				budget.others = append(budget.others, analyzer.Type(other))
+			}
+		}
+		budgets = append(budgets, budget)
+	}
+	// The analyzers are started in the same order for each file
+	sort.Slice(budgets, func(i, j int) bool {
+		return budgets[i].analyzer < budgets[j].analyzer
+	})
+	return budgets
+}
+
+// analyzerAnalyzeFunc analyzes the file with the analyzers except the disabled ones
+type analyzerAnalyzeFunc func(wg *sync.WaitGroup, result *analyzer.AnalysisResult, opener analyzer.Opener,
+	disabled []analyzer.Type) error
+
+// analyze calls analyzeFn for each analyzer with its own budget, disabling the other analyzers,
+// and then once for the rest of the analyzers disabling the budgeted ones.
+func (b analyzerBudgets) analyze(wg *sync.WaitGroup, result *analyzer.AnalysisResult, filePath string,
+	opener analyzer.Opener, disabled []analyzer.Type, analyzeFn analyzerAnalyzeFunc) error {
+	if len(b) == 0 {
+		return analyzeFn(wg, result, opener, disabled)
+	}
+
+	rest := slices.Clone(disabled)
+	for _, budget := range b {
+		if slices.Contains(disabled, budget.analyzer) {
+			continue
+		}
+		rest = append(rest, budget.analyzer)
+		others := budget.others
+		err := budget.fileBudget.analyze(wg, result, filePath, opener, func(wg *sync.WaitGroup,
+			result *analyzer.AnalysisResult, opener analyzer.Opener) error {
+			return analyzeFn(wg, result, opener, others)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return analyzeFn(wg, result, opener, rest)
+}
+
+// fileTimer expires after the timeout since the first read of the file
+type fileTimer struct {
+	timeout time.Duration
+	expired chan struct{}
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+func newFileTimer(timeout time.Duration) *fileTimer {
+	return &fileTimer{
+		timeout: timeout,
+		expired: make(chan struct{}),
+	}
+}
+
+// check starts the timer on the first call, and returns an error after the timer expires
+func (t *fileTimer) check() error {
+	t.mu.Lock()
+	if t.timer == nil && !t.stopped {
+		t.timer = time.AfterFunc(t.timeout, func() { close(t.expired) })
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.expired:
+		return errFileTimeout
+	default:
+		return nil
+	}
+}
+
+// stop stops the timer, and reports whether it stopped before expiring
+func (t *fileTimer) stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	if t.timer == nil {
+		return true
+	}
+	return t.timer.Stop()
+}
+
+func (t *fileTimer) opener(opener analyzer.Opener) analyzer.Opener {
+	return func() (dio.ReadSeekCloserAt, error) {
+		rc, err := opener()
+		if err != nil {
+			return nil, err
+		}
+		return timedReader{ReadSeekCloserAt: rc, timer: t}, nil
+	}
+}
+
+// timedReader fails the reads after the timer expires
+type timedReader struct {
+	dio.ReadSeekCloserAt
+	timer *fileTimer
+}
+
+func (r timedReader) Read(p []byte) (int, error) {
+	if err := r.timer.check(); err != nil {
+		return 0, err
+	}
+	return r.ReadSeekCloserAt.Read(p)
+}
+
+func (r timedReader) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.timer.check(); err != nil {
+		return 0, err
+	}
+	return r.ReadSeekCloserAt.ReadAt(p, off)
+}
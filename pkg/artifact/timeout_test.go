@@ -0,0 +1,214 @@
+package artifact
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/types"
+	dio "github.com/aquasecurity/go-dep-parser/pkg/io"
+	pkgtypes "github.com/aquasecurity/trivy/pkg/types"
+)
+
+type nopCloserReader struct {
+	*bytes.Reader
+}
+
+func (nopCloserReader) Close() error { return nil }
+
+func TestFileBudget_analyze(t *testing.T) {
+	opener := func() (dio.ReadSeekCloserAt, error) {
+		return nopCloserReader{Reader: bytes.NewReader([]byte("content"))}, nil
+	}
+	app := types.Application{Type: types.Npm, FilePath: "app/package-lock.json"}
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		analyze func(r dio.ReadSeekCloserAt, stuck <-chan struct{}) *analyzer.AnalysisResult
+		want    *analyzer.AnalysisResult
+	}{
+		{
+			name:    "within the timeout",
+			timeout: time.Minute,
+			analyze: func(r dio.ReadSeekCloserAt, _ <-chan struct{}) *analyzer.AnalysisResult {
+				_, _ = io.ReadAll(r)
+				return &analyzer.AnalysisResult{Applications: []types.Application{app}}
+			},
+			want: &analyzer.AnalysisResult{Applications: []types.Application{app}},
+		},
+		{
+			name:    "the reads fail after the timeout",
+			timeout: 10 * time.Millisecond,
+			analyze: func(r dio.ReadSeekCloserAt, _ <-chan struct{}) *analyzer.AnalysisResult {
+				// e.g. a corrupt archive read over and over
+				for {
+					if _, err := r.ReadAt(make([]byte, 1), 0); err != nil {
+						return &analyzer.AnalysisResult{Applications: []types.Application{app}}
+					}
+				}
+			},
+			want: &analyzer.AnalysisResult{
+				CustomResources: []types.CustomResource{
+					{
+						Type:     pkgtypes.SkippedFileType,
+						FilePath: "app/package-lock.json",
+						Data:     "analysis timed out after 10ms",
+					},
+				},
+			},
+		},
+		{
+			name:    "stuck without reading",
+			timeout: 10 * time.Millisecond,
+			analyze: func(r dio.ReadSeekCloserAt, stuck <-chan struct{}) *analyzer.AnalysisResult {
+				_, _ = r.Read(make([]byte, 1))
+				<-stuck
+				return &analyzer.AnalysisResult{Applications: []types.Application{app}}
+			},
+			want: &analyzer.AnalysisResult{
+				CustomResources: []types.CustomResource{
+					{
+						Type:     pkgtypes.SkippedFileType,
+						FilePath: "app/package-lock.json",
+						Data:     "analysis timed out after 10ms",
+					},
+				},
+			},
+		},
+		{
+			name: "no timeout",
+			analyze: func(r dio.ReadSeekCloserAt, _ <-chan struct{}) *analyzer.AnalysisResult {
+				_, _ = io.ReadAll(r)
+				return &analyzer.AnalysisResult{Applications: []types.Application{app}}
+			},
+			want: &analyzer.AnalysisResult{Applications: []types.Application{app}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stuck := make(chan struct{})
+			defer close(stuck)
+
+			var wg sync.WaitGroup
+			result := analyzer.NewAnalysisResult()
+			budget := fileBudget{timeout: tt.timeout}
+			err := budget.analyze(&wg, result, "app/package-lock.json", opener,
+				func(wg *sync.WaitGroup, result *analyzer.AnalysisResult, opener analyzer.Opener) error {
+					rc, err := opener()
+					if err != nil {
+						return err
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						result.Merge(tt.analyze(rc, stuck))
+					}()
+					return nil
+				})
+			require.NoError(t, err)
+
+			// The stuck analyzer is not waited for
+			wg.Wait()
+			assert.Equal(t, tt.want.Applications, result.Applications)
+			assert.Equal(t, tt.want.CustomResources, result.CustomResources)
+		})
+	}
+}
+
+func TestAnalyzerBudgets_analyze(t *testing.T) {
+	opener := func() (dio.ReadSeekCloserAt, error) {
+		return nopCloserReader{Reader: bytes.NewReader([]byte("content"))}, nil
+	}
+	app := types.Application{Type: types.Npm, FilePath: "app/package-lock.json"}
+	secret := types.Secret{FilePath: "app/package-lock.json"}
+
+	budgets := analyzerBudgets{
+		{
+			fileBudget: fileBudget{timeout: 10 * time.Millisecond, analyzer: analyzer.TypeSecret},
+			others:     []analyzer.Type{analyzer.TypeNpmPkgLock},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		disabled     []analyzer.Type
+		want         *analyzer.AnalysisResult
+		wantDisabled [][]analyzer.Type
+	}{
+		{
+			name: "only the results of the analyzer timed out are skipped",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{app},
+				CustomResources: []types.CustomResource{
+					{
+						Type:     pkgtypes.SkippedFileType,
+						FilePath: "app/package-lock.json",
+						Data:     "analysis by the secret analyzer timed out after 10ms",
+					},
+				},
+			},
+			wantDisabled: [][]analyzer.Type{
+				{analyzer.TypeNpmPkgLock},
+				{analyzer.TypeSecret},
+			},
+		},
+		{
+			name:     "the disabled analyzer is not given the budget",
+			disabled: []analyzer.Type{analyzer.TypeSecret},
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{app},
+			},
+			wantDisabled: [][]analyzer.Type{
+				{analyzer.TypeSecret},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			var gotDisabled [][]analyzer.Type
+			result := analyzer.NewAnalysisResult()
+			err := budgets.analyze(&wg, result, "app/package-lock.json", opener, tt.disabled,
+				func(wg *sync.WaitGroup, result *analyzer.AnalysisResult, opener analyzer.Opener,
+					disabled []analyzer.Type) error {
+					gotDisabled = append(gotDisabled, disabled)
+					rc, err := opener()
+					if err != nil {
+						return err
+					}
+					secretDisabled := slices.Contains(disabled, analyzer.TypeSecret)
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if secretDisabled {
+							result.Merge(&analyzer.AnalysisResult{Applications: []types.Application{app}})
+							return
+						}
+						// The secret analyzer reads the file over and over
+						for {
+							if _, err := rc.ReadAt(make([]byte, 1), 0); err != nil {
+								result.Merge(&analyzer.AnalysisResult{Secrets: []types.Secret{secret}})
+								return
+							}
+						}
+					}()
+					return nil
+				})
+			require.NoError(t, err)
+
+			wg.Wait()
+			assert.Equal(t, tt.wantDisabled, gotDisabled)
+			assert.Equal(t, tt.want.Applications, result.Applications)
+			assert.Empty(t, result.Secrets)
+			assert.Equal(t, tt.want.CustomResources, result.CustomResources)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package attestation
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"golang.org/x/xerrors"
+)
+
+// AttestKeyless signs the predicate with the certificate issued by Fulcio for the OIDC identity of the environment,
+// e.g. GitHub Actions, and attaches it to the image. The keyless flow is delegated to cosign
+// as it requires the OIDC login and the transparency log.
+func AttestKeyless(ctx context.Context, image, predicateType string, predicate []byte) error {
+	cosign, err := exec.LookPath("cosign")
+	if err != nil {
+		return xerrors.Errorf("keyless signing requires cosign in PATH, or specify the key: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "trivy-predicate-*.json")
+	if err != nil {
+		return xerrors.Errorf("unable to create a temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(predicate); err != nil {
+		f.Close()
+		return xerrors.Errorf("unable to write the predicate: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return xerrors.Errorf("unable to write the predicate: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, cosign, "attest", "--type", predicateType, "--predicate", f.Name(), image)
+	cmd.Env = append(os.Environ(), "COSIGN_EXPERIMENTAL=1")
+	// cosign prompts for the OIDC login when the identity token is not found in the environment
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return xerrors.Errorf("cosign attest error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// Envelope is the DSSE envelope of the signed payload, which cosign attaches to images
+// https://github.com/secure-systems-lab/dsse/blob/master/envelope.md
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is the signature of the envelope
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Sign signs the statement and returns the envelope
+func Sign(statement Statement, signer crypto.Signer) (Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return Envelope{}, xerrors.Errorf("failed to marshal the statement: %w", err)
+	}
+
+	sig, err := signMessage(signer, pae(PayloadType, payload))
+	if err != nil {
+		return Envelope{}, xerrors.Errorf("sign error: %w", err)
+	}
+	return Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// Verify returns the statement if one of the signatures is verified with the public key
+func (e Envelope) Verify(pub crypto.PublicKey) (Statement, error) {
+	if e.PayloadType != PayloadType {
+		return Statement{}, xerrors.Errorf("unexpected payload type: %s", e.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return Statement{}, xerrors.Errorf("payload decode error: %w", err)
+	}
+
+	message := pae(e.PayloadType, payload)
+	verified := false
+	for _, s := range e.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if verifyMessage(pub, message, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Statement{}, xerrors.New("no signature is verified with the public key")
+	}
+
+	var statement Statement
+	if err = json.Unmarshal(payload, &statement); err != nil {
+		return Statement{}, xerrors.Errorf("statement decode error: %w", err)
+	}
+	return statement, nil
+}
+
+// pae is the pre-authentication encoding of DSSE, which is signed instead of the payload
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// signMessage signs the SHA-256 digest of the message as cosign does, except with Ed25519 signing the message itself
+func signMessage(signer crypto.Signer, message []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(message)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func verifyMessage(pub crypto.PublicKey, message, sig []byte) bool {
+	digest := sha256.Sum256(message)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, message, sig)
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package attestation_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/attestation"
+)
+
+const testDigest = "ghcr.io/aquasecurity/trivy@sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6"
+
+func TestSign(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	image, err := name.NewDigest(testDigest)
+	require.NoError(t, err)
+	statement, err := attestation.NewImageStatement(image, "https://example.com/predicate/v1", map[string]interface{}{"foo": "bar"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		pub     crypto.PublicKey
+		wantErr string
+	}{
+		{
+			name:   "ECDSA",
+			signer: ecKey,
+			pub:    ecKey.Public(),
+		},
+		{
+			name:   "RSA",
+			signer: rsaKey,
+			pub:    rsaKey.Public(),
+		},
+		{
+			name:   "Ed25519",
+			signer: edKey,
+			pub:    edKey.Public(),
+		},
+		{
+			name:    "sad path: another key",
+			signer:  ecKey,
+			pub:     otherKey.Public(),
+			wantErr: "no signature is verified with the public key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := attestation.Sign(statement, tt.signer)
+			require.NoError(t, err)
+			assert.Equal(t, attestation.PayloadType, envelope.PayloadType)
+
+			got, err := envelope.Verify(tt.pub)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, attestation.StatementType, got.Type)
+			assert.Equal(t, "https://example.com/predicate/v1", got.PredicateType)
+			assert.Equal(t, []attestation.Subject{
+				{
+					Name:   "ghcr.io/aquasecurity/trivy",
+					Digest: map[string]string{"sha256": "1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6"},
+				},
+			}, got.Subject)
+			assert.Equal(t, map[string]interface{}{"foo": "bar"}, got.Predicate)
+		})
+	}
+}
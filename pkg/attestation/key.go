@@ -0,0 +1,129 @@
+package attestation
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+)
+
+// PEM types of the private keys generated by "cosign generate-key-pair"
+var cosignKeyTypes = []string{"ENCRYPTED COSIGN PRIVATE KEY", "ENCRYPTED SIGSTORE PRIVATE KEY"}
+
+// encryptedKey is the private key encrypted by cosign with scrypt and NaCl secretbox
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadPrivateKey loads the private key generated by cosign, which is decrypted with the password,
+// or the unencrypted PKCS #8, EC or PKCS #1 private key in PEM.
+func LoadPrivateKey(path string, password []byte) (crypto.Signer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to read the private key: %w", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, xerrors.Errorf("no PEM block in %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case cosignKeyTypes[0], cosignKeyTypes[1]:
+		var der []byte
+		if der, err = decrypt(block.Bytes, password); err != nil {
+			return nil, xerrors.Errorf("unable to decrypt the private key, check COSIGN_PASSWORD: %w", err)
+		}
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, xerrors.Errorf("unsupported PEM type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("private key parse error: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported private key: %T", key)
+	}
+	return signer, nil
+}
+
+// LoadPublicKey loads the public key in PEM, e.g. "cosign.pub"
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to read the public key: %w", err)
+	}
+	pub, err := ParsePublicKey(b)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: %w", path, err)
+	}
+	return pub, nil
+}
+
+// ParsePublicKey parses the PEM-encoded public key, e.g. "cosign.pub"
+func ParsePublicKey(b []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, xerrors.New("no public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, xerrors.Errorf("public key parse error: %w", err)
+	}
+	return pub, nil
+}
+
+func decrypt(b, password []byte) ([]byte, error) {
+	var k encryptedKey
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, xerrors.Errorf("JSON decode error: %w", err)
+	}
+	if k.KDF.Name != "scrypt" || k.Cipher.Name != "nacl/secretbox" {
+		return nil, xerrors.Errorf("unsupported encryption: %s, %s", k.KDF.Name, k.Cipher.Name)
+	}
+
+	var secret [32]byte
+	derived, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, len(secret))
+	if err != nil {
+		return nil, xerrors.Errorf("scrypt error: %w", err)
+	}
+	copy(secret[:], derived)
+
+	var nonce [24]byte
+	if len(k.Cipher.Nonce) != len(nonce) {
+		return nil, xerrors.Errorf("invalid nonce length: %d", len(k.Cipher.Nonce))
+	}
+	copy(nonce[:], k.Cipher.Nonce)
+
+	der, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &secret)
+	if !ok {
+		return nil, xerrors.New("wrong password")
+	}
+	return der, nil
+}
@@ -0,0 +1,135 @@
+package attestation_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/aquasecurity/trivy/pkg/attestation"
+)
+
+// writeCosignKey writes the key pair in the same format as "cosign generate-key-pair"
+func writeCosignKey(t *testing.T, dir string, password []byte) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	var salt [32]byte
+	var nonce [24]byte
+	_, err = rand.Read(salt[:])
+	require.NoError(t, err)
+	_, err = rand.Read(nonce[:])
+	require.NoError(t, err)
+
+	derived, err := scrypt.Key(password, salt[:], 32768, 8, 1, 32)
+	require.NoError(t, err)
+	var secret [32]byte
+	copy(secret[:], derived)
+
+	encrypted, err := json.Marshal(map[string]interface{}{
+		"kdf": map[string]interface{}{
+			"name":   "scrypt",
+			"params": map[string]int{"N": 32768, "r": 8, "p": 1},
+			"salt":   salt[:],
+		},
+		"cipher": map[string]interface{}{
+			"name":  "nacl/secretbox",
+			"nonce": nonce[:],
+		},
+		"ciphertext": secretbox.Seal(nil, der, &nonce, &secret),
+	})
+	require.NoError(t, err)
+
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(dir, "cosign.key")
+	pubPath := filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED COSIGN PRIVATE KEY", Bytes: encrypted}), 0600))
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644))
+	return keyPath, pubPath
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	dir := t.TempDir()
+	cosignKey, cosignPub := writeCosignKey(t, dir, []byte("password"))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	ecPath := filepath.Join(dir, "ec.pem")
+	require.NoError(t, os.WriteFile(ecPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+
+	tests := []struct {
+		name     string
+		path     string
+		password string
+		wantErr  string
+	}{
+		{
+			name:     "cosign key",
+			path:     cosignKey,
+			password: "password",
+		},
+		{
+			name: "unencrypted EC key",
+			path: ecPath,
+		},
+		{
+			name:     "sad path: wrong password",
+			path:     cosignKey,
+			password: "wrong",
+			wantErr:  "wrong password",
+		},
+		{
+			name:    "sad path: public key",
+			path:    cosignPub,
+			wantErr: "unsupported PEM type: PUBLIC KEY",
+		},
+		{
+			name:    "sad path: no file",
+			path:    filepath.Join(dir, "missing.key"),
+			wantErr: "unable to read the private key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := attestation.LoadPrivateKey(tt.path, []byte(tt.password))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, &ecdsa.PrivateKey{}, got)
+		})
+	}
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	cosignKey, cosignPub := writeCosignKey(t, dir, []byte("password"))
+
+	signer, err := attestation.LoadPrivateKey(cosignKey, []byte("password"))
+	require.NoError(t, err)
+	pub, err := attestation.LoadPublicKey(cosignPub)
+	require.NoError(t, err)
+	assert.True(t, signer.Public().(*ecdsa.PublicKey).Equal(pub))
+
+	_, err = attestation.LoadPublicKey(cosignKey)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no public key")
+}
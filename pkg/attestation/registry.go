@@ -0,0 +1,157 @@
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// EnvelopeMediaType is the media type of the layers with the DSSE envelopes
+	EnvelopeMediaType types.MediaType = "application/vnd.dsse.envelope.v1+json"
+
+	// The annotations of the layers read by "cosign verify-attestation"
+	signatureAnnotation     = "dev.cosignproject.cosign/signature"
+	predicateTypeAnnotation = "predicateType"
+)
+
+// AttestationTag returns the tag where cosign stores the attestations of the image, e.g. "ghcr.io/foo/bar:sha256-<hex>.att"
+func AttestationTag(image name.Digest) name.Tag {
+	return image.Context().Tag(strings.Replace(image.DigestStr(), ":", "-", 1) + ".att")
+}
+
+// Attach appends the envelope to the attestations of the image in the registry in the same way as "cosign attest"
+func Attach(ctx context.Context, image name.Digest, envelope Envelope, predicateType string, opts ...remote.Option) error {
+	b, err := json.Marshal(envelope)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the envelope: %w", err)
+	}
+
+	opts = append([]remote.Option{remote.WithContext(ctx)}, opts...)
+	tag := AttestationTag(image)
+	base, err := remote.Image(tag, opts...)
+	if isNotFound(err) {
+		// The image has no attestations yet
+		base = mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	} else if err != nil {
+		return xerrors.Errorf("unable to get the attestations (%s): %w", tag, err)
+	}
+
+	img, err := mutate.Append(base, mutate.Addendum{
+		Layer:     newBlobLayer(b, EnvelopeMediaType),
+		MediaType: EnvelopeMediaType,
+		Annotations: map[string]string{
+			signatureAnnotation:     "",
+			predicateTypeAnnotation: predicateType,
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("unable to append the attestation: %w", err)
+	}
+
+	if err = remote.Write(tag, img, opts...); err != nil {
+		return xerrors.Errorf("unable to push the attestations (%s): %w", tag, err)
+	}
+	return nil
+}
+
+// Fetch returns the envelopes of the attestations of the image with the predicate type, attached by "cosign attest".
+// It returns nothing if the image has no attestations.
+func Fetch(ctx context.Context, image name.Digest, predicateType string, opts ...remote.Option) ([]Envelope, error) {
+	opts = append([]remote.Option{remote.WithContext(ctx)}, opts...)
+	tag := AttestationTag(image)
+	img, err := remote.Image(tag, opts...)
+	if isNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, xerrors.Errorf("unable to get the attestations (%s): %w", tag, err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to get the manifest of the attestations: %w", err)
+	}
+
+	var envelopes []Envelope
+	for _, desc := range manifest.Layers {
+		if desc.MediaType != EnvelopeMediaType || desc.Annotations[predicateTypeAnnotation] != predicateType {
+			continue
+		}
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, xerrors.Errorf("unable to get the attestation (%s): %w", desc.Digest, err)
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return nil, xerrors.Errorf("unable to read the attestation (%s): %w", desc.Digest, err)
+		}
+		var envelope Envelope
+		err = json.NewDecoder(rc).Decode(&envelope)
+		rc.Close()
+		if err != nil {
+			return nil, xerrors.Errorf("invalid attestation (%s): %w", desc.Digest, err)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, nil
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+// blobLayer is the layer of the blob as it is, which is not a tarball
+type blobLayer struct {
+	b         []byte
+	hash      v1.Hash
+	mediaType types.MediaType
+}
+
+func newBlobLayer(b []byte, mediaType types.MediaType) blobLayer {
+	sum := sha256.Sum256(b)
+	return blobLayer{
+		b:         b,
+		hash:      v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(sum[:])},
+		mediaType: mediaType,
+	}
+}
+
+func (l blobLayer) Digest() (v1.Hash, error) {
+	return l.hash, nil
+}
+
+func (l blobLayer) DiffID() (v1.Hash, error) {
+	return l.hash, nil
+}
+
+func (l blobLayer) Compressed() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(l.b)), nil
+}
+
+func (l blobLayer) Uncompressed() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(l.b)), nil
+}
+
+func (l blobLayer) Size() (int64, error) {
+	return int64(len(l.b)), nil
+}
+
+func (l blobLayer) MediaType() (types.MediaType, error) {
+	return l.mediaType, nil
+}
@@ -0,0 +1,106 @@
+package attestation_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/attestation"
+)
+
+func TestAttach(t *testing.T) {
+	ts := httptest.NewServer(registry.New())
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	image, err := name.NewDigest(u.Host + "/foo/bar@sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6")
+	require.NoError(t, err)
+	assert.Equal(t, u.Host+"/foo/bar:sha256-1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6.att",
+		attestation.AttestationTag(image).Name())
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// The attestations are appended to the existing ones
+	for _, predicateType := range []string{"https://example.com/first/v1", "https://example.com/second/v1"} {
+		statement, err := attestation.NewImageStatement(image, predicateType, map[string]string{})
+		require.NoError(t, err)
+		envelope, err := attestation.Sign(statement, key)
+		require.NoError(t, err)
+		require.NoError(t, attestation.Attach(context.Background(), image, envelope, predicateType))
+	}
+
+	img, err := remote.Image(attestation.AttestationTag(image))
+	require.NoError(t, err)
+	manifest, err := img.Manifest()
+	require.NoError(t, err)
+	require.Len(t, manifest.Layers, 2)
+
+	for i, predicateType := range []string{"https://example.com/first/v1", "https://example.com/second/v1"} {
+		desc := manifest.Layers[i]
+		assert.Equal(t, attestation.EnvelopeMediaType, desc.MediaType)
+		assert.Equal(t, predicateType, desc.Annotations["predicateType"])
+
+		layer, err := img.LayerByDigest(desc.Digest)
+		require.NoError(t, err)
+		rc, err := layer.Compressed()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+
+		var envelope attestation.Envelope
+		require.NoError(t, json.Unmarshal(b, &envelope))
+		statement, err := envelope.Verify(key.Public())
+		require.NoError(t, err)
+		assert.Equal(t, predicateType, statement.PredicateType)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	ts := httptest.NewServer(registry.New())
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	image, err := name.NewDigest(u.Host + "/foo/bar@sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6")
+	require.NoError(t, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// The image has no attestations
+	got, err := attestation.Fetch(context.Background(), image, "https://example.com/first/v1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	for _, predicateType := range []string{"https://example.com/first/v1", "https://example.com/second/v1"} {
+		statement, err := attestation.NewImageStatement(image, predicateType, map[string]string{"type": predicateType})
+		require.NoError(t, err)
+		envelope, err := attestation.Sign(statement, key)
+		require.NoError(t, err)
+		require.NoError(t, attestation.Attach(context.Background(), image, envelope, predicateType))
+	}
+
+	got, err = attestation.Fetch(context.Background(), image, "https://example.com/second/v1")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	statement, err := got[0].Verify(key.Public())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "https://example.com/second/v1"}, statement.Predicate)
+
+	got, err = attestation.Fetch(context.Background(), image, "https://example.com/unknown/v1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,50 @@
+package attestation
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// StatementType is the type of the in-toto statements
+	StatementType = "https://in-toto.io/Statement/v0.1"
+
+	// PayloadType is the payload type of the DSSE envelopes with the in-toto statements
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Statement is the in-toto statement binding the predicate to the subjects
+// https://github.com/in-toto/attestation/blob/main/spec/v0.1.0/statement.md
+type Statement struct {
+	Type          string      `json:"_type"`
+	PredicateType string      `json:"predicateType"`
+	Subject       []Subject   `json:"subject"`
+	Predicate     interface{} `json:"predicate"`
+}
+
+// Subject is the artifact which the predicate is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// NewImageStatement returns the statement of the image pinned to the digest, e.g. "ghcr.io/foo/bar@sha256:..."
+func NewImageStatement(image name.Digest, predicateType string, predicate interface{}) (Statement, error) {
+	algorithm, hex, ok := strings.Cut(image.DigestStr(), ":")
+	if !ok {
+		return Statement{}, xerrors.Errorf("invalid digest: %s", image.DigestStr())
+	}
+	return Statement{
+		Type:          StatementType,
+		PredicateType: predicateType,
+		Subject: []Subject{
+			{
+				Name:   image.Context().Name(),
+				Digest: map[string]string{algorithm: hex},
+			},
+		},
+		Predicate: predicate,
+	}, nil
+}
@@ -0,0 +1,233 @@
+package buildkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/image"
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+// DefaultContentStore is the content store of buildkitd with the OCI worker.
+// The content store of containerd can be used as well with the containerd worker.
+const DefaultContentStore = "/var/lib/buildkit/runc-overlayfs/content"
+
+// Metadata is the metadata file written by "docker buildx build --metadata-file"
+type Metadata struct {
+	Descriptor *v1.Descriptor `json:"containerimage.descriptor"`
+	Digest     string         `json:"containerimage.digest"`
+	ImageName  string         `json:"image.name"`
+}
+
+// NewImage opens the image built by BuildKit from the content store without exporting it.
+// The target is either the metadata file of the build or the digest of the image.
+func NewImage(contentStore, target string) (ftypes.Image, error) {
+	var meta Metadata
+	if strings.HasPrefix(target, "sha256:") {
+		meta.Digest = target
+	} else {
+		b, err := os.ReadFile(target)
+		if err != nil {
+			return nil, xerrors.Errorf("unable to read the metadata file: %w", err)
+		}
+		if err = json.Unmarshal(b, &meta); err != nil {
+			return nil, xerrors.Errorf("invalid metadata file: %w", err)
+		}
+	}
+
+	digest := meta.Digest
+	if meta.Descriptor != nil {
+		digest = meta.Descriptor.Digest.String()
+	}
+	if digest == "" {
+		return nil, xerrors.Errorf("%s doesn't contain the image digest", target)
+	}
+	h, err := v1.NewHash(digest)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid image digest: %w", err)
+	}
+
+	store := layout.Path(contentStore)
+	img, err := openImage(store, h)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to open %s in the content store %s: %w", h, contentStore, err)
+	}
+
+	// The image may be tagged with multiple names, e.g. "myapp:1.0,myapp:latest"
+	imageName, _, _ := strings.Cut(meta.ImageName, ",")
+	return buildkitImage{
+		Image:  img,
+		name:   imageName,
+		digest: h,
+	}, nil
+}
+
+// openImage opens the image manifest, or the manifest for the running platform in the image index
+func openImage(store layout.Path, h v1.Hash) (v1.Image, error) {
+	b, err := store.Bytes(h)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to read the manifest: %w", err)
+	}
+
+	var m struct {
+		MediaType types.MediaType `json:"mediaType"`
+		Manifests []v1.Descriptor `json:"manifests"`
+	}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, xerrors.Errorf("invalid manifest: %w", err)
+	}
+
+	if !m.MediaType.IsIndex() && len(m.Manifests) == 0 {
+		mediaType := m.MediaType
+		if mediaType == "" {
+			mediaType = types.OCIManifestSchema1
+		}
+		return partial.CompressedToImage(&compressedImage{
+			store:       store,
+			mediaType:   mediaType,
+			rawManifest: b,
+		})
+	}
+
+	index, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid image index: %w", err)
+	}
+	desc, err := selectManifest(index.Manifests)
+	if err != nil {
+		return nil, err
+	}
+	return openImage(store, desc.Digest)
+}
+
+// selectManifest returns the manifest for the running platform, or the first one if no platform is specified.
+// Attestations are stored as manifests for the "unknown" platform, and they are skipped.
+func selectManifest(manifests []v1.Descriptor) (v1.Descriptor, error) {
+	platform := v1.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	var candidates []v1.Descriptor
+	for _, m := range manifests {
+		if m.Platform == nil {
+			candidates = append(candidates, m)
+			continue
+		} else if m.Platform.OS == "unknown" {
+			continue
+		}
+		if m.Platform.OS == platform.OS && m.Platform.Architecture == platform.Architecture {
+			return m, nil
+		}
+		candidates = append(candidates, m)
+	}
+	if len(candidates) == 0 {
+		return v1.Descriptor{}, xerrors.New("no image manifest in the image index")
+	}
+	return candidates[0], nil
+}
+
+// compressedImage reads the blobs of the image from the content store.
+// The content store has the same layout as the blobs in the OCI image layout.
+type compressedImage struct {
+	store       layout.Path
+	mediaType   types.MediaType
+	rawManifest []byte
+}
+
+func (i *compressedImage) MediaType() (types.MediaType, error) {
+	return i.mediaType, nil
+}
+
+func (i *compressedImage) RawManifest() ([]byte, error) {
+	return i.rawManifest, nil
+}
+
+func (i *compressedImage) RawConfigFile() ([]byte, error) {
+	m, err := partial.Manifest(i)
+	if err != nil {
+		return nil, err
+	}
+	return i.store.Bytes(m.Config.Digest)
+}
+
+func (i *compressedImage) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	m, err := partial.Manifest(i)
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range m.Layers {
+		if desc.Digest == h {
+			return &compressedBlob{store: i.store, desc: desc}, nil
+		}
+	}
+	return nil, xerrors.Errorf("could not find layer in image: %s", h)
+}
+
+type compressedBlob struct {
+	store layout.Path
+	desc  v1.Descriptor
+}
+
+func (b *compressedBlob) Digest() (v1.Hash, error) {
+	return b.desc.Digest, nil
+}
+
+func (b *compressedBlob) Compressed() (io.ReadCloser, error) {
+	return b.store.Blob(b.desc.Digest)
+}
+
+func (b *compressedBlob) Size() (int64, error) {
+	return b.desc.Size, nil
+}
+
+func (b *compressedBlob) MediaType() (types.MediaType, error) {
+	return b.desc.MediaType, nil
+}
+
+type buildkitImage struct {
+	v1.Image
+	name   string
+	digest v1.Hash
+}
+
+// Name returns the image name given to the build, or the digest if the image is not named
+func (img buildkitImage) Name() string {
+	if img.name == "" {
+		return img.digest.String()
+	}
+	return img.name
+}
+
+func (img buildkitImage) ID() (string, error) {
+	return image.ID(img)
+}
+
+// LayerIDs returns a list of uncompressed layer IDs
+func (img buildkitImage) LayerIDs() ([]string, error) {
+	return image.LayerIDs(img)
+}
+
+// RepoTags returns the image name as BuildKit doesn't store tags
+func (img buildkitImage) RepoTags() []string {
+	if img.name == "" {
+		return nil
+	}
+	return []string{img.name}
+}
+
+// RepoDigests returns the image name pinned to the digest so that it is recorded in the report
+func (img buildkitImage) RepoDigests() []string {
+	ref, err := name.ParseReference(img.name)
+	if img.name == "" || err != nil {
+		return nil
+	}
+	return []string{ref.Context().Digest(img.digest.String()).Name()}
+}
@@ -0,0 +1,126 @@
+package buildkit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/buildkit"
+)
+
+func TestNewImage(t *testing.T) {
+	store := t.TempDir()
+	p := layout.Path(store)
+
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	require.NoError(t, p.WriteImage(img))
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	configName, err := img.ConfigName()
+	require.NoError(t, err)
+
+	// A multi-platform image with an attestation
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"},
+			},
+		},
+		mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{OS: "linux", Architecture: "s390x"},
+			},
+		},
+	)
+	require.NoError(t, p.WriteIndex(index))
+	indexDigest, err := index.Digest()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	tests := []struct {
+		name            string
+		target          string
+		wantName        string
+		wantRepoDigests []string
+		wantErr         string
+	}{
+		{
+			name: "metadata file",
+			target: writeFile("metadata.json", `{
+  "containerimage.descriptor": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "digest": "`+digest.String()+`", "size": 100},
+  "containerimage.digest": "`+digest.String()+`",
+  "image.name": "ghcr.io/org/myapp:1.0,ghcr.io/org/myapp:latest"
+}`),
+			wantName:        "ghcr.io/org/myapp:1.0",
+			wantRepoDigests: []string{"ghcr.io/org/myapp@" + digest.String()},
+		},
+		{
+			name:     "digest",
+			target:   digest.String(),
+			wantName: digest.String(),
+		},
+		{
+			name:     "image index",
+			target:   writeFile("index.json", `{"containerimage.digest": "`+indexDigest.String()+`"}`),
+			wantName: indexDigest.String(),
+		},
+		{
+			name:    "no digest",
+			target:  writeFile("empty.json", `{}`),
+			wantErr: "doesn't contain the image digest",
+		},
+		{
+			name:    "not in the content store",
+			target:  "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			wantErr: "unable to open",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildkit.NewImage(store, tt.target)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantName, got.Name())
+			assert.Equal(t, tt.wantRepoDigests, got.RepoDigests())
+
+			id, err := got.ID()
+			require.NoError(t, err)
+			assert.Equal(t, configName.String(), id)
+
+			layerIDs, err := got.LayerIDs()
+			require.NoError(t, err)
+			assert.Len(t, layerIDs, 2)
+
+			// The layers are read from the content store
+			layers, err := got.Layers()
+			require.NoError(t, err)
+			for _, l := range layers {
+				rc, err := l.Uncompressed()
+				require.NoError(t, err)
+				require.NoError(t, rc.Close())
+			}
+		})
+	}
+}
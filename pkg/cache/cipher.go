@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// encryptedValueVersion is the first byte of the encrypted values, which never starts JSON or gzip
+	encryptedValueVersion = 0x01
+
+	keyIDSize = 4
+)
+
+// Cipher encrypts the values in the caches shared by the scans with AES-GCM, so that the file paths and the package
+// inventories of the artifacts are not readable from the cache infrastructure.
+// The encrypted value is the version, the ID of the key, the nonce and the sealed value.
+// The cache key is authenticated with the value, so that the values can't be swapped between the keys.
+type Cipher struct {
+	aead   cipher.AEAD
+	keyID  []byte
+	macKey []byte
+}
+
+// NewCipher returns the cipher with the AES key of 16, 24 or 32 bytes
+func NewCipher(key []byte) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, xerrors.Errorf("GCM error: %w", err)
+	}
+	id := sha256.Sum256(key)
+
+	// The MAC key is derived from the key, so that the key is not used for both the encryption and the MAC
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("trivy cache content"))
+	return &Cipher{
+		aead:   aead,
+		keyID:  id[:keyIDSize],
+		macKey: mac.Sum(nil),
+	}, nil
+}
+
+// KeyID returns the ID of the key, which is empty without encryption
+func (c *Cipher) KeyID() string {
+	if c == nil {
+		return ""
+	}
+	return hex.EncodeToString(c.keyID)
+}
+
+// seal encrypts the value of the cache key. The value is returned as it is if c is nil.
+func (c *Cipher) seal(key string, value []byte) ([]byte, error) {
+	if c == nil {
+		return value, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, xerrors.Errorf("nonce error: %w", err)
+	}
+
+	header := append([]byte{encryptedValueVersion}, c.keyID...)
+	sealed := append(header, nonce...)
+	return c.aead.Seal(sealed, nonce, value, []byte(key)), nil
+}
+
+// digest returns the HMAC-SHA256 of the value, which can't be told from the value without the key unlike the hash
+func (c *Cipher) digest(value []byte) string {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(value)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// canOpen reports whether the value starting with the header, i.e. the version and the ID of the key, is decrypted
+// with the key without decrypting it. The value must not be encrypted if c is nil.
+func (c *Cipher) canOpen(header []byte) bool {
+	encrypted := len(header) > 0 && header[0] == encryptedValueVersion
+	if c == nil {
+		return len(header) > 0 && !encrypted
+	}
+	return encrypted && len(header) >= 1+keyIDSize && bytes.Equal(header[1:1+keyIDSize], c.keyID)
+}
+
+// open decrypts the value of the cache key. The value is returned as it is if c is nil.
+func (c *Cipher) open(key string, value []byte) ([]byte, error) {
+	encrypted := len(value) > 0 && value[0] == encryptedValueVersion
+	switch {
+	case c == nil && encrypted:
+		return nil, xerrors.New("the value is encrypted, '--cache-encryption-key' is required")
+	case c == nil:
+		return value, nil
+	case !encrypted:
+		return nil, xerrors.New("the value is not encrypted")
+	}
+
+	headerSize := 1 + keyIDSize + c.aead.NonceSize()
+	if len(value) < headerSize+c.aead.Overhead() {
+		return nil, xerrors.New("the encrypted value is truncated")
+	} else if !bytes.Equal(value[1:1+keyIDSize], c.keyID) {
+		return nil, xerrors.Errorf("the value is encrypted with another key (%x)", value[1:1+keyIDSize])
+	}
+
+	plaintext, err := c.aead.Open(nil, value[1+keyIDSize:headerSize], value[headerSize:], []byte(key))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decrypt the value: %w", err)
+	}
+	return plaintext, nil
+}
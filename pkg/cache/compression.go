@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/xerrors"
+)
+
+// Compression is the algorithm to compress the values in the caches shared by the scans.
+// The values are decompressed by the magic number whatever the compression is, so that the caches written
+// with the other compressions are still read.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// Compressions are the compressions available
+var Compressions = []Compression{CompressionNone, CompressionGzip, CompressionZstd}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+	// The encoder and the decoder are shared, as they are safe for the concurrent use with EncodeAll and DecodeAll
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+func initZstd() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+// compress returns the compressed value, which is the value as it is with CompressionNone
+func (c Compression) compress(value []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone, "":
+		return value, nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(value); err != nil {
+			return nil, xerrors.Errorf("gzip error: %w", err)
+		} else if err = w.Close(); err != nil {
+			return nil, xerrors.Errorf("gzip error: %w", err)
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		if err := initZstd(); err != nil {
+			return nil, xerrors.Errorf("zstd error: %w", err)
+		}
+		return zstdEncoder.EncodeAll(value, nil), nil
+	}
+	return nil, xerrors.Errorf("unknown compression: %s", c)
+}
+
+// decompress returns the value decompressed by the magic number, and the value as it is if it is not compressed
+func decompress(value []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(value, gzipMagic):
+		r, err := gzip.NewReader(bytes.NewReader(value))
+		if err != nil {
+			return nil, xerrors.Errorf("gzip error: %w", err)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, xerrors.Errorf("gzip error: %w", err)
+		}
+		return b, nil
+	case bytes.HasPrefix(value, zstdMagic):
+		if err := initZstd(); err != nil {
+			return nil, xerrors.Errorf("zstd error: %w", err)
+		}
+		b, err := zstdDecoder.DecodeAll(value, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("zstd error: %w", err)
+		}
+		return b, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,354 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+)
+
+var _ cache.Cache = &DynamoDBCache{}
+
+const (
+	// The attributes of the items
+	dynamoDBKey           = "Key"
+	dynamoDBValue         = "Value"
+	dynamoDBSchemaVersion = "SchemaVersion"
+	dynamoDBExpiresAt     = "ExpiresAt"
+	dynamoDBKeyID         = "KeyID"
+
+	// The limits of BatchGetItem and BatchWriteItem
+	dynamoDBGetBatchSize   = 100
+	dynamoDBWriteBatchSize = 25
+
+	// dynamoDBMaxItemSize is the maximum size of an item
+	dynamoDBMaxItemSize = 400 * 1024
+)
+
+// DynamoDBCache implements the cache with a DynamoDB table, so that the scans without a persistent filesystem,
+// e.g. on AWS Lambda, share the analysis results.
+// The table has the string partition key "Key", and the value is stored compressed in "Value", with gzip by default.
+// With the TTL, the epoch time in "ExpiresAt" should be enabled as the TTL attribute of the table.
+// DynamoDB deletes the expired items only eventually, so they are handled as missing until deleted.
+// The values are encrypted after the compression if the cipher is given, and "KeyID" has the ID of the key.
+type DynamoDBCache struct {
+	client      dynamodbiface.DynamoDBAPI
+	table       string
+	expiration  time.Duration
+	cipher      *Cipher
+	compression Compression
+}
+
+// NewDynamoDBCache is the factory method for DynamoDBCache
+func NewDynamoDBCache(client dynamodbiface.DynamoDBAPI, table string, expiration time.Duration,
+	cipher *Cipher, compression Compression) DynamoDBCache {
+	if compression == "" {
+		compression = CompressionGzip
+	}
+	return DynamoDBCache{
+		client:      client,
+		table:       table,
+		expiration:  expiration,
+		cipher:      cipher,
+		compression: compression,
+	}
+}
+
+func (c DynamoDBCache) PutArtifact(artifactID string, artifactInfo types.ArtifactInfo) error {
+	if err := c.put(dynamoDBItemKey(artifactBucket, artifactID), artifactInfo.SchemaVersion, artifactInfo); err != nil {
+		return xerrors.Errorf("unable to store artifact information in DynamoDB cache (%s): %w", artifactID, err)
+	}
+	return nil
+}
+
+func (c DynamoDBCache) PutBlob(blobID string, blobInfo types.BlobInfo) error {
+	if err := c.put(dynamoDBItemKey(blobBucket, blobID), blobInfo.SchemaVersion, blobInfo); err != nil {
+		return xerrors.Errorf("unable to store blob information in DynamoDB cache (%s): %w", blobID, err)
+	}
+	return nil
+}
+
+func (c DynamoDBCache) put(key string, schemaVersion int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal JSON: %w", err)
+	}
+	if b, err = c.compression.compress(b); err != nil {
+		return xerrors.Errorf("failed to compress JSON: %w", err)
+	}
+	value, err := c.cipher.seal(key, b)
+	if err != nil {
+		return xerrors.Errorf("failed to encrypt the value: %w", err)
+	}
+	if len(value) > dynamoDBMaxItemSize {
+		return xerrors.Errorf("the compressed value (%d bytes) exceeds the item size limit of DynamoDB", len(value))
+	}
+
+	item := map[string]*dynamodb.AttributeValue{
+		dynamoDBKey:           {S: aws.String(key)},
+		dynamoDBValue:         {B: value},
+		dynamoDBSchemaVersion: {N: aws.String(strconv.Itoa(schemaVersion))},
+	}
+	if keyID := c.cipher.KeyID(); keyID != "" {
+		item[dynamoDBKeyID] = &dynamodb.AttributeValue{S: aws.String(keyID)}
+	}
+	if c.expiration > 0 {
+		expiresAt := time.Now().Add(c.expiration).Unix()
+		item[dynamoDBExpiresAt] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expiresAt, 10))}
+	}
+	_, err = c.client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(c.table),
+		Item:      item,
+	})
+	return err
+}
+
+func (c DynamoDBCache) GetArtifact(artifactID string) (types.ArtifactInfo, error) {
+	var info types.ArtifactInfo
+	found, err := c.get(dynamoDBItemKey(artifactBucket, artifactID), &info)
+	if err != nil {
+		return types.ArtifactInfo{}, xerrors.Errorf("failed to get artifact (%s) from the DynamoDB cache: %w", artifactID, err)
+	} else if !found {
+		return types.ArtifactInfo{}, xerrors.Errorf("artifact (%s) is missing in DynamoDB cache", artifactID)
+	}
+	return info, nil
+}
+
+func (c DynamoDBCache) GetBlob(blobID string) (types.BlobInfo, error) {
+	var info types.BlobInfo
+	found, err := c.get(dynamoDBItemKey(blobBucket, blobID), &info)
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("failed to get blob (%s) from the DynamoDB cache: %w", blobID, err)
+	} else if !found {
+		return types.BlobInfo{}, xerrors.Errorf("blob (%s) is missing in DynamoDB cache", blobID)
+	}
+	return info, nil
+}
+
+// get reads the item with a strongly consistent read, as the blobs are read right after they are written
+func (c DynamoDBCache) get(key string, v interface{}) (bool, error) {
+	out, err := c.client.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(c.table),
+		Key:            map[string]*dynamodb.AttributeValue{dynamoDBKey: {S: aws.String(key)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return false, err
+	} else if out.Item == nil || expired(out.Item) {
+		return false, nil
+	}
+
+	value, ok := out.Item[dynamoDBValue]
+	if !ok {
+		return false, xerrors.New("no value in the item")
+	}
+	b, err := c.cipher.open(key, value.B)
+	if err != nil {
+		return false, err
+	}
+	if b, err = decompress(b); err != nil {
+		return false, xerrors.Errorf("failed to decompress the value: %w", err)
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return false, xerrors.Errorf("failed to unmarshal the value: %w", err)
+	}
+	return true, nil
+}
+
+// MissingBlobs looks up the artifact and the blobs in batches, reading only the schema versions and the key IDs
+func (c DynamoDBCache) MissingBlobs(artifactID string, blobIDs []string) (bool, []string, error) {
+	keys := []string{dynamoDBItemKey(artifactBucket, artifactID)}
+	for _, blobID := range blobIDs {
+		keys = append(keys, dynamoDBItemKey(blobBucket, blobID))
+	}
+
+	versions, err := c.schemaVersions(keys)
+	if err != nil {
+		return false, nil, xerrors.Errorf("unable to get the artifact and blobs from the DynamoDB cache: %w", err)
+	}
+
+	// The cached values of the old schema are handled as missing, as well as those encrypted with another key or
+	// written before the encryption is enabled, so that they are overwritten
+	missingArtifact := versions[keys[0]] != types.ArtifactJSONSchemaVersion
+
+	var missingBlobIDs []string
+	for i, blobID := range blobIDs {
+		if versions[keys[i+1]] != types.BlobJSONSchemaVersion {
+			missingBlobIDs = append(missingBlobIDs, blobID)
+		}
+	}
+	return missingArtifact, missingBlobIDs, nil
+}
+
+// schemaVersions returns the schema versions of the items, without the missing and expired ones and those which
+// can't be decrypted with the cipher
+func (c DynamoDBCache) schemaVersions(keys []string) (map[string]int, error) {
+	// BatchGetItem rejects the duplicate keys
+	var uniqKeys []string
+	for _, key := range keys {
+		if !slices.Contains(uniqKeys, key) {
+			uniqKeys = append(uniqKeys, key)
+		}
+	}
+
+	versions := map[string]int{}
+	for start := 0; start < len(uniqKeys); start += dynamoDBGetBatchSize {
+		end := start + dynamoDBGetBatchSize
+		if end > len(uniqKeys) {
+			end = len(uniqKeys)
+		}
+
+		var itemKeys []map[string]*dynamodb.AttributeValue
+		for _, key := range uniqKeys[start:end] {
+			itemKeys = append(itemKeys, map[string]*dynamodb.AttributeValue{dynamoDBKey: {S: aws.String(key)}})
+		}
+		requests := map[string]*dynamodb.KeysAndAttributes{
+			c.table: {
+				Keys:                 itemKeys,
+				ConsistentRead:       aws.Bool(true),
+				ProjectionExpression: aws.String("#k, #v, #e, #i"),
+				ExpressionAttributeNames: aws.StringMap(map[string]string{"#k": dynamoDBKey, "#v": dynamoDBSchemaVersion,
+					"#e": dynamoDBExpiresAt, "#i": dynamoDBKeyID}),
+			},
+		}
+
+		// The keys unprocessed due to the throughput are retried
+		for attempt := 0; len(requests) > 0; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff(attempt))
+			}
+			out, err := c.client.BatchGetItem(&dynamodb.BatchGetItemInput{RequestItems: requests})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range out.Responses[c.table] {
+				if expired(item) || keyID(item) != c.cipher.KeyID() {
+					continue
+				}
+				version, _ := strconv.Atoi(aws.StringValue(item[dynamoDBSchemaVersion].N))
+				versions[aws.StringValue(item[dynamoDBKey].S)] = version
+			}
+			requests = out.UnprocessedKeys
+		}
+	}
+	return versions, nil
+}
+
+// DeleteBlobs removes the blobs in batches
+func (c DynamoDBCache) DeleteBlobs(blobIDs []string) error {
+	var keys []string
+	for _, blobID := range blobIDs {
+		keys = append(keys, dynamoDBItemKey(blobBucket, blobID))
+	}
+	if err := c.deleteItems(keys); err != nil {
+		return xerrors.Errorf("unable to delete blobs from the DynamoDB cache: %w", err)
+	}
+	return nil
+}
+
+func (c DynamoDBCache) deleteItems(keys []string) error {
+	for start := 0; start < len(keys); start += dynamoDBWriteBatchSize {
+		end := start + dynamoDBWriteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var writes []*dynamodb.WriteRequest
+		for _, key := range keys[start:end] {
+			writes = append(writes, &dynamodb.WriteRequest{
+				DeleteRequest: &dynamodb.DeleteRequest{
+					Key: map[string]*dynamodb.AttributeValue{dynamoDBKey: {S: aws.String(key)}},
+				},
+			})
+		}
+		requests := map[string][]*dynamodb.WriteRequest{c.table: writes}
+
+		// The items unprocessed due to the throughput are retried
+		for attempt := 0; len(requests) > 0; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff(attempt))
+			}
+			out, err := c.client.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: requests})
+			if err != nil {
+				return err
+			}
+			requests = out.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+// Ping checks if the table is reachable with DescribeTable, which doesn't consume the capacity of the table
+func (c DynamoDBCache) Ping(ctx context.Context) error {
+	_, err := c.client.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(c.table)})
+	if err != nil {
+		return xerrors.Errorf("unable to describe the DynamoDB table (%s): %w", c.table, err)
+	}
+	return nil
+}
+
+func (c DynamoDBCache) Close() error {
+	return nil
+}
+
+// Clear removes all the items in the table, which must be dedicated to the cache
+func (c DynamoDBCache) Clear() error {
+	var keys []string
+	err := c.client.ScanPages(&dynamodb.ScanInput{
+		TableName:                aws.String(c.table),
+		ProjectionExpression:     aws.String("#k"),
+		ExpressionAttributeNames: aws.StringMap(map[string]string{"#k": dynamoDBKey}),
+	}, func(out *dynamodb.ScanOutput, _ bool) bool {
+		for _, item := range out.Items {
+			keys = append(keys, aws.StringValue(item[dynamoDBKey].S))
+		}
+		return true
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to scan the DynamoDB table: %w", err)
+	}
+	if err = c.deleteItems(keys); err != nil {
+		return xerrors.Errorf("failed to delete the DynamoDB items: %w", err)
+	}
+	return nil
+}
+
+// expired returns true if the item outlives the TTL and waits for the deletion by DynamoDB
+func expired(item map[string]*dynamodb.AttributeValue) bool {
+	v, ok := item[dynamoDBExpiresAt]
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(aws.StringValue(v.N), 10, 64)
+	return err == nil && expiresAt < time.Now().Unix()
+}
+
+// keyID returns the ID of the key encrypting the item, which is empty if it is not encrypted
+func keyID(item map[string]*dynamodb.AttributeValue) string {
+	v, ok := item[dynamoDBKeyID]
+	if !ok {
+		return ""
+	}
+	return aws.StringValue(v.S)
+}
+
+func backoff(attempt int) time.Duration {
+	if attempt > 5 {
+		attempt = 5
+	}
+	return time.Duration(50<<attempt) * time.Millisecond
+}
+
+func dynamoDBItemKey(bucket, id string) string {
+	return fmt.Sprintf("%s::%s", bucket, id)
+}
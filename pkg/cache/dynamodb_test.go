@@ -0,0 +1,297 @@
+package cache_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/cache"
+)
+
+// fakeDynamoDB keeps the items in memory, and leaves the first key of each batch unprocessed once
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items       map[string]map[string]*dynamodb.AttributeValue
+	unprocessed bool
+}
+
+func (f *fakeDynamoDB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	f.items[aws.StringValue(input.Item["Key"].S)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: f.items[aws.StringValue(input.Key["Key"].S)]}, nil
+}
+
+func (f *fakeDynamoDB) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	out := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+	for table, req := range input.RequestItems {
+		if len(req.Keys) > 100 {
+			return nil, &dynamodb.ResourceNotFoundException{}
+		}
+		keys := req.Keys
+		if f.unprocessed {
+			out.UnprocessedKeys = map[string]*dynamodb.KeysAndAttributes{table: {Keys: keys[:1]}}
+			keys = keys[1:]
+		}
+		for _, key := range keys {
+			if item, ok := f.items[aws.StringValue(key["Key"].S)]; ok {
+				out.Responses[table] = append(out.Responses[table], item)
+			}
+		}
+	}
+	f.unprocessed = !f.unprocessed
+	return out, nil
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	for _, writes := range input.RequestItems {
+		if len(writes) > 25 {
+			return nil, &dynamodb.ResourceNotFoundException{}
+		}
+		for _, w := range writes {
+			delete(f.items, aws.StringValue(w.DeleteRequest.Key["Key"].S))
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DescribeTableWithContext(_ aws.Context, input *dynamodb.DescribeTableInput,
+	_ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	if aws.StringValue(input.TableName) != "trivy" {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: input.TableName}}, nil
+}
+
+func (f *fakeDynamoDB) ScanPages(_ *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool) error {
+	var items []map[string]*dynamodb.AttributeValue
+	for _, item := range f.items {
+		items = append(items, item)
+	}
+	fn(&dynamodb.ScanOutput{Items: items}, true)
+	return nil
+}
+
+func TestDynamoDBCache_PutGet(t *testing.T) {
+	client := &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	c := cache.NewDynamoDBCache(client, "trivy", time.Hour, nil, "")
+
+	artifactInfo := types.ArtifactInfo{
+		SchemaVersion: types.ArtifactJSONSchemaVersion,
+		Architecture:  "amd64",
+		OS:            "linux",
+	}
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+	require.NoError(t, c.PutArtifact("sha256:artifact", artifactInfo))
+	require.NoError(t, c.PutBlob("sha256:blob", blobInfo))
+
+	gotArtifactInfo, err := c.GetArtifact("sha256:artifact")
+	require.NoError(t, err)
+	assert.Equal(t, artifactInfo, gotArtifactInfo)
+
+	gotBlobInfo, err := c.GetBlob("sha256:blob")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, gotBlobInfo)
+
+	item := client.items["blob::sha256:blob"]
+	expiresAt, err := strconv.ParseInt(aws.StringValue(item["ExpiresAt"].N), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), expiresAt, 5)
+
+	_, err = c.GetBlob("sha256:missing")
+	assert.ErrorContains(t, err, "blob (sha256:missing) is missing in DynamoDB cache")
+}
+
+func TestDynamoDBCache_MissingBlobs(t *testing.T) {
+	type args struct {
+		artifactID string
+		blobIDs    []string
+	}
+	tests := []struct {
+		name                string
+		args                args
+		wantMissingArtifact bool
+		wantMissingBlobIDs  []string
+	}{
+		{
+			name: "all cached",
+			args: args{
+				artifactID: "sha256:artifact",
+				blobIDs:    []string{"sha256:blob1", "sha256:blob2"},
+			},
+		},
+		{
+			name: "missing, expired and old schema",
+			args: args{
+				artifactID: "sha256:missing",
+				blobIDs:    []string{"sha256:blob1", "sha256:missing1", "sha256:expired", "sha256:old", "sha256:blob1"},
+			},
+			wantMissingArtifact: true,
+			wantMissingBlobIDs:  []string{"sha256:missing1", "sha256:expired", "sha256:old"},
+		},
+		{
+			name: "expired artifact",
+			args: args{
+				artifactID: "sha256:expired",
+			},
+			wantMissingArtifact: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+			c := cache.NewDynamoDBCache(client, "trivy", 0, nil, "")
+
+			require.NoError(t, c.PutArtifact("sha256:artifact", types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}))
+			require.NoError(t, c.PutArtifact("sha256:expired", types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}))
+			for _, blobID := range []string{"sha256:blob1", "sha256:blob2", "sha256:expired"} {
+				require.NoError(t, c.PutBlob(blobID, types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}))
+			}
+			require.NoError(t, c.PutBlob("sha256:old", types.BlobInfo{SchemaVersion: 0}))
+
+			// DynamoDB hasn't deleted the expired items yet
+			for _, key := range []string{"artifact::sha256:expired", "blob::sha256:expired"} {
+				client.items[key]["ExpiresAt"] = &dynamodb.AttributeValue{N: aws.String("1")}
+			}
+
+			gotMissingArtifact, gotMissingBlobIDs, err := c.MissingBlobs(tt.args.artifactID, tt.args.blobIDs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMissingArtifact, gotMissingArtifact)
+			assert.Equal(t, tt.wantMissingBlobIDs, gotMissingBlobIDs)
+		})
+	}
+}
+
+func TestDynamoDBCache_compression(t *testing.T) {
+	client := &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+
+	// gzip by default
+	gzipCache := cache.NewDynamoDBCache(client, "trivy", 0, nil, "")
+	require.NoError(t, gzipCache.PutBlob("sha256:gzip", blobInfo))
+	assert.Equal(t, []byte{0x1f, 0x8b}, client.items["blob::sha256:gzip"]["Value"].B[:2])
+
+	zstdCache := cache.NewDynamoDBCache(client, "trivy", 0, nil, cache.CompressionZstd)
+	require.NoError(t, zstdCache.PutBlob("sha256:zstd", blobInfo))
+	assert.Equal(t, []byte{0x28, 0xb5, 0x2f, 0xfd}, client.items["blob::sha256:zstd"]["Value"].B[:4])
+
+	for _, c := range []cache.DynamoDBCache{gzipCache, zstdCache} {
+		for _, blobID := range []string{"sha256:gzip", "sha256:zstd"} {
+			got, err := c.GetBlob(blobID)
+			require.NoError(t, err)
+			assert.Equal(t, blobInfo, got)
+		}
+	}
+}
+
+func TestDynamoDBCache_encryption(t *testing.T) {
+	cipher, err := cache.NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	other, err := cache.NewCipher([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+
+	client := &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	c := cache.NewDynamoDBCache(client, "trivy", 0, cipher, "")
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+	require.NoError(t, c.PutBlob("sha256:blob", blobInfo))
+	assert.Equal(t, cipher.KeyID(), aws.StringValue(client.items["blob::sha256:blob"]["KeyID"].S))
+
+	got, err := c.GetBlob("sha256:blob")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, got)
+
+	_, missingBlobIDs, err := c.MissingBlobs("sha256:artifact", []string{"sha256:blob"})
+	require.NoError(t, err)
+	assert.Empty(t, missingBlobIDs)
+
+	// The items encrypted with another key and the plain items are handled as missing
+	plainCache := cache.NewDynamoDBCache(client, "trivy", 0, nil, "")
+	require.NoError(t, plainCache.PutBlob("sha256:plain", blobInfo))
+	for _, tt := range []struct {
+		name   string
+		cipher *cache.Cipher
+		blobID string
+	}{
+		{
+			name:   "another key",
+			cipher: other,
+			blobID: "sha256:blob",
+		},
+		{
+			name:   "plain",
+			cipher: cipher,
+			blobID: "sha256:plain",
+		},
+		{
+			name:   "no key",
+			blobID: "sha256:blob",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, gotMissingBlobIDs, err := cache.NewDynamoDBCache(client, "trivy", 0, tt.cipher, "").
+				MissingBlobs("sha256:artifact", []string{tt.blobID})
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.blobID}, gotMissingBlobIDs)
+		})
+	}
+
+	_, err = plainCache.GetBlob("sha256:blob")
+	assert.ErrorContains(t, err, "the value is encrypted, '--cache-encryption-key' is required")
+}
+
+func TestDynamoDBCache_DeleteBlobs(t *testing.T) {
+	client := &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	c := cache.NewDynamoDBCache(client, "trivy", 0, nil, "")
+
+	var blobIDs []string
+	for i := 0; i < 30; i++ {
+		blobID := "sha256:blob" + strconv.Itoa(i)
+		require.NoError(t, c.PutBlob(blobID, types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}))
+		blobIDs = append(blobIDs, blobID)
+	}
+	require.NoError(t, c.PutArtifact("sha256:artifact", types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}))
+
+	require.NoError(t, c.DeleteBlobs(blobIDs))
+	assert.Len(t, client.items, 1)
+
+	require.NoError(t, c.Clear())
+	assert.Empty(t, client.items)
+}
+
+func TestDynamoDBCache_Ping(t *testing.T) {
+	client := &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	require.NoError(t, cache.NewDynamoDBCache(client, "trivy", 0, nil, "").Ping(context.Background()))
+
+	err := cache.NewDynamoDBCache(client, "missing", 0, nil, "").Ping(context.Background())
+	assert.ErrorContains(t, err, "unable to describe the DynamoDB table (missing)")
+}
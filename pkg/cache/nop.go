@@ -1,16 +1,36 @@
 package cache
 
-import "github.com/aquasecurity/fanal/cache"
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+)
+
+// errNoLocalCache is returned by the reads of the no-op cache, whose blobs are stored in the remote cache
+var errNoLocalCache = xerrors.New("no local cache in client/server mode")
 
 func NopCache(ac cache.ArtifactCache) cache.Cache {
 	return nopCache{ArtifactCache: ac}
 }
 
+// nopCache stores the artifacts in the remote cache, and can't read them back
 type nopCache struct {
 	cache.ArtifactCache
-	cache.LocalArtifactCache
+}
+
+func (nopCache) GetArtifact(string) (types.ArtifactInfo, error) {
+	return types.ArtifactInfo{}, errNoLocalCache
+}
+
+func (nopCache) GetBlob(string) (types.BlobInfo, error) {
+	return types.BlobInfo{}, errNoLocalCache
 }
 
 func (nopCache) Close() error {
 	return nil
 }
+
+func (nopCache) Clear() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package cache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/image"
+	"github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/cache"
+	"github.com/aquasecurity/trivy/pkg/rpc/client"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+)
+
+func TestNopCache(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(rpcCache.CachePathPrefix, rpcCache.NewCacheServer(new(mockCacheServer), nil))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// The image is scanned in client mode, where the blobs are stored in the remote cache
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	ref, err := name.ParseReference("myapp:1.0")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "image.tar")
+	require.NoError(t, tarball.WriteToFile(path, ref, img))
+	archive, err := image.NewArchiveImage(path)
+	require.NoError(t, err)
+
+	c := cache.NopCache(cache.NewRemoteCache(client.ScannerOption{RemoteURL: ts.URL}))
+	art, err := artifact.NewImageArtifact(archive, c, artifact.Option{})
+	require.NoError(t, err)
+	_, err = art.Inspect(context.Background())
+	require.NoError(t, err)
+
+	// The blobs can't be read back from the no-op cache
+	_, err = c.GetBlob("sha256:24d44f3a4e1d4c3e1b8c2c3f9b2e6f0a1a1e2b7a3f0b6e9b1e0c2d4f6a8b0c2d")
+	assert.Error(t, err)
+}
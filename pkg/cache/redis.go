@@ -0,0 +1,478 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+)
+
+var _ cache.Cache = &RedisCache{}
+
+const (
+	// The keys are compatible with the Redis cache of fanal
+	redisPrefix    = "fanal"
+	artifactBucket = "artifact"
+	blobBucket     = "blob"
+	lockBucket     = "lock"
+
+	// layerBucket has the analysis results of the layers deduplicated by the content
+	layerBucket = "layer"
+
+	// DefaultRedisBatchSize is the default number of commands sent to Redis in a pipeline
+	DefaultRedisBatchSize = 100
+)
+
+// RedisCache implements the cache with Redis.
+// The commands for multiple blobs are pipelined in batches so that the round trips don't grow with the number of layers.
+// The next batch is sent after the replies to the previous batch are received, so that Redis is not flooded.
+// The values are compressed with the compression, and encrypted after the compression if the cipher is given.
+//
+// With dedup, the analysis result of a layer is stored once under the key of the diff ID and the digest of the result,
+// and the blob has only the reference to it, so that the same layer analyzed for the different blob IDs,
+// e.g. with the different analyzer versions or options, is not stored repeatedly.
+// The stored result is not deleted with the blob, as the other blobs may refer to it, and it expires with the TTL.
+type RedisCache struct {
+	client      *redis.Client
+	expiration  time.Duration
+	batchSize   int
+	cipher      *Cipher
+	compression Compression
+	dedup       bool
+}
+
+// NewRedisCache is the factory method for RedisCache
+func NewRedisCache(options *redis.Options, expiration time.Duration, batchSize int, cipher *Cipher,
+	compression Compression, dedup bool) RedisCache {
+	if batchSize <= 0 {
+		batchSize = DefaultRedisBatchSize
+	}
+	return RedisCache{
+		client:      redis.NewClient(options),
+		expiration:  expiration,
+		batchSize:   batchSize,
+		cipher:      cipher,
+		compression: compression,
+		dedup:       dedup,
+	}
+}
+
+// blobRef is the value of the blob whose analysis result is deduplicated.
+// ContentRef is the first field, so that the reference is told from the blob without decoding the JSON.
+type blobRef struct {
+	ContentRef    string
+	SchemaVersion int
+}
+
+var blobRefPrefix = []byte(`{"ContentRef":`)
+
+func (c RedisCache) PutArtifact(artifactID string, artifactInfo types.ArtifactInfo) error {
+	key := redisKey(artifactBucket, artifactID)
+	b, err := c.marshal(key, artifactInfo)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal artifact JSON: %w", err)
+	}
+	if err = c.client.Set(context.TODO(), key, b, c.expiration).Err(); err != nil {
+		return xerrors.Errorf("unable to store artifact information in Redis cache (%s): %w", artifactID, err)
+	}
+	return nil
+}
+
+func (c RedisCache) PutBlob(blobID string, blobInfo types.BlobInfo) error {
+	// Only the layers are deduplicated, as the other blobs, e.g. of the filesystems, are not shared
+	if c.dedup && blobInfo.DiffID != "" {
+		if err := c.putBlobRef(context.TODO(), blobID, blobInfo); err != nil {
+			return xerrors.Errorf("unable to store blob information in Redis cache (%s): %w", blobID, err)
+		}
+		return nil
+	}
+
+	key := redisKey(blobBucket, blobID)
+	b, err := c.marshal(key, blobInfo)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal blob JSON: %w", err)
+	}
+	if err = c.client.Set(context.TODO(), key, b, c.expiration).Err(); err != nil {
+		return xerrors.Errorf("unable to store blob information in Redis cache (%s): %w", blobID, err)
+	}
+	return nil
+}
+
+// putBlobRef stores the analysis result under the content key unless it is already stored, and the reference to it
+// as the blob. The expiration of the stored result is extended, so that it doesn't expire before the reference.
+func (c RedisCache) putBlobRef(ctx context.Context, blobID string, blobInfo types.BlobInfo) error {
+	b, err := json.Marshal(blobInfo)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal blob JSON: %w", err)
+	}
+	contentKey := redisKey(layerBucket, c.contentID(blobInfo.DiffID, b))
+
+	var stored bool
+	if c.expiration > 0 {
+		stored, err = c.client.Expire(ctx, contentKey, c.expiration).Result()
+	} else {
+		var n int64
+		n, err = c.client.Exists(ctx, contentKey).Result()
+		stored = n > 0
+	}
+	if err != nil {
+		return xerrors.Errorf("unable to look up the layer: %w", err)
+	}
+
+	if !stored {
+		value, err := c.encode(contentKey, b)
+		if err != nil {
+			return xerrors.Errorf("failed to encode the layer: %w", err)
+		}
+		if err = c.client.Set(ctx, contentKey, value, c.expiration).Err(); err != nil {
+			return xerrors.Errorf("unable to store the layer: %w", err)
+		}
+	}
+
+	key := redisKey(blobBucket, blobID)
+	ref, err := c.marshal(key, blobRef{
+		ContentRef:    contentKey,
+		SchemaVersion: blobInfo.SchemaVersion,
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the reference: %w", err)
+	}
+	return c.client.Set(ctx, key, ref, c.expiration).Err()
+}
+
+// contentID returns the ID of the analysis result of the layer. The ID of the encryption key is in it, so that the result
+// stored without the encryption or with another key is not taken for the one readable with the key.
+// The result is digested with the HMAC under the encryption key, so that the key in Redis doesn't tell a guessed result.
+func (c RedisCache) contentID(diffID string, b []byte) string {
+	if c.cipher != nil {
+		return fmt.Sprintf("%s::%s::hmac-sha256:%s", diffID, c.cipher.KeyID(), c.cipher.digest(b))
+	}
+	return fmt.Sprintf("%s::sha256:%x", diffID, sha256.Sum256(b))
+}
+
+func (c RedisCache) GetArtifact(artifactID string) (types.ArtifactInfo, error) {
+	key := redisKey(artifactBucket, artifactID)
+	b, err := c.client.Get(context.TODO(), key).Bytes()
+	if err == redis.Nil {
+		return types.ArtifactInfo{}, xerrors.Errorf("artifact (%s) is missing in Redis cache", artifactID)
+	} else if err != nil {
+		return types.ArtifactInfo{}, xerrors.Errorf("failed to get artifact from the Redis cache: %w", err)
+	}
+
+	var info types.ArtifactInfo
+	if err = c.unmarshal(key, b, &info); err != nil {
+		return types.ArtifactInfo{}, xerrors.Errorf("failed to unmarshal artifact (%s) from Redis value: %w", artifactID, err)
+	}
+	return info, nil
+}
+
+func (c RedisCache) GetBlob(blobID string) (types.BlobInfo, error) {
+	key := redisKey(blobBucket, blobID)
+	b, err := c.client.Get(context.TODO(), key).Bytes()
+	if err == redis.Nil {
+		return types.BlobInfo{}, xerrors.Errorf("blob (%s) is missing in Redis cache", blobID)
+	} else if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("failed to get blob from the Redis cache: %w", err)
+	}
+
+	b, err = c.decode(key, b)
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("failed to decode blob (%s) from Redis value: %w", blobID, err)
+	}
+
+	// The analysis result is read from the content key if the blob refers to it
+	if bytes.HasPrefix(b, blobRefPrefix) {
+		var ref blobRef
+		if err = json.Unmarshal(b, &ref); err != nil {
+			return types.BlobInfo{}, xerrors.Errorf("failed to unmarshal the reference of blob (%s): %w", blobID, err)
+		}
+		key = ref.ContentRef
+		b, err = c.client.Get(context.TODO(), key).Bytes()
+		if err == redis.Nil {
+			return types.BlobInfo{}, xerrors.Errorf("blob (%s) is missing in Redis cache", blobID)
+		} else if err != nil {
+			return types.BlobInfo{}, xerrors.Errorf("failed to get blob from the Redis cache: %w", err)
+		}
+		// The result which can't be decrypted is missing as well as the evicted one, so that it is analyzed again
+		if b, err = c.decode(key, b); err != nil {
+			return types.BlobInfo{}, xerrors.Errorf("blob (%s) is missing in Redis cache: %w", blobID, err)
+		}
+	}
+
+	var info types.BlobInfo
+	if err = json.Unmarshal(b, &info); err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("failed to unmarshal blob (%s) from Redis value: %w", blobID, err)
+	}
+	return info, nil
+}
+
+// MissingBlobs looks up the artifact and the blobs with pipelines
+func (c RedisCache) MissingBlobs(artifactID string, blobIDs []string) (bool, []string, error) {
+	keys := []string{redisKey(artifactBucket, artifactID)}
+	for _, blobID := range blobIDs {
+		keys = append(keys, redisKey(blobBucket, blobID))
+	}
+
+	values, err := c.getAll(context.TODO(), keys)
+	if err != nil {
+		return false, nil, xerrors.Errorf("unable to get the artifact and blobs from the Redis cache: %w", err)
+	}
+
+	// The cached values of the old schema are handled as missing, as well as those which can't be decrypted,
+	// e.g. written before the encryption is enabled, so that they are overwritten
+	missingArtifact := true
+	if values[0] != nil {
+		var info types.ArtifactInfo
+		if err = c.unmarshal(keys[0], values[0], &info); err == nil && info.SchemaVersion == types.ArtifactJSONSchemaVersion {
+			missingArtifact = false
+		}
+	}
+
+	// The blobs referring to the analysis results are missing if the results have been evicted,
+	// or can't be decrypted with the key
+	var missingBlobIDs []string
+	var refs, contentKeys []string
+	for i, blobID := range blobIDs {
+		var info blobRef
+		if b := values[i+1]; b == nil || c.unmarshal(keys[i+1], b, &info) != nil ||
+			info.SchemaVersion != types.BlobJSONSchemaVersion {
+			missingBlobIDs = append(missingBlobIDs, blobID)
+		} else if info.ContentRef != "" {
+			refs = append(refs, blobID)
+			contentKeys = append(contentKeys, info.ContentRef)
+		}
+	}
+
+	headers, err := c.headerAll(context.TODO(), contentKeys)
+	if err != nil {
+		return false, nil, xerrors.Errorf("unable to look up the layers in the Redis cache: %w", err)
+	}
+	for i, blobID := range refs {
+		if !c.cipher.canOpen(headers[i]) {
+			missingBlobIDs = append(missingBlobIDs, blobID)
+		}
+	}
+	return missingArtifact, missingBlobIDs, nil
+}
+
+// marshal returns the JSON of v encoded for the key
+func (c RedisCache) marshal(key string, v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.encode(key, b)
+}
+
+// unmarshal decodes the value of the key and parses the JSON into v
+func (c RedisCache) unmarshal(key string, b []byte, v interface{}) error {
+	b, err := c.decode(key, b)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// encode compresses the value and encrypts it for the key
+func (c RedisCache) encode(key string, b []byte) ([]byte, error) {
+	b, err := c.compression.compress(b)
+	if err != nil {
+		return nil, err
+	}
+	return c.cipher.seal(key, b)
+}
+
+// decode decrypts the value of the key and decompresses it
+func (c RedisCache) decode(key string, b []byte) ([]byte, error) {
+	b, err := c.cipher.open(key, b)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(b)
+}
+
+// headerAll returns the headers of the values of the keys with pipelines, i.e. the version and the ID of the encryption
+// key of the encrypted values, so that the values are checked without reading them. It is empty for missing keys.
+func (c RedisCache) headerAll(ctx context.Context, keys []string) ([][]byte, error) {
+	var headers [][]byte
+	err := c.batch(ctx, len(keys), func(pipe redis.Pipeliner, start, end int) error {
+		var cmds []*redis.StringCmd
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, pipe.GetRange(ctx, key, 0, keyIDSize))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			b, err := cmd.Bytes()
+			if err != nil {
+				return err
+			}
+			headers = append(headers, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// getAll returns the values of the keys, and nil for missing keys
+func (c RedisCache) getAll(ctx context.Context, keys []string) ([][]byte, error) {
+	var values [][]byte
+	err := c.batch(ctx, len(keys), func(pipe redis.Pipeliner, start, end int) error {
+		var cmds []*redis.StringCmd
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, pipe.Get(ctx, key))
+		}
+
+		// redis.Nil is returned on a missing key, and it is checked per command
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			b, err := cmd.Bytes()
+			if err == redis.Nil {
+				values = append(values, nil)
+				continue
+			} else if err != nil {
+				return err
+			}
+			values = append(values, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// DeleteBlobs removes the blobs with pipelines
+func (c RedisCache) DeleteBlobs(blobIDs []string) error {
+	ctx := context.TODO()
+	err := c.batch(ctx, len(blobIDs), func(pipe redis.Pipeliner, start, end int) error {
+		var keys []string
+		for _, blobID := range blobIDs[start:end] {
+			keys = append(keys, redisKey(blobBucket, blobID))
+		}
+		pipe.Del(ctx, keys...)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return xerrors.Errorf("unable to delete blobs from the Redis cache: %w", err)
+	}
+	return nil
+}
+
+// batch calls fn with a new pipeline for every batch of n commands
+func (c RedisCache) batch(ctx context.Context, n int, fn func(pipe redis.Pipeliner, start, end int) error) error {
+	for start := 0; start < n; start += c.batchSize {
+		end := start + c.batchSize
+		if end > n {
+			end = n
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(c.client.Pipeline(), start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping checks if Redis is reachable
+func (c RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func (c RedisCache) Close() error {
+	return c.client.Close()
+}
+
+func (c RedisCache) Clear() error {
+	ctx := context.Background()
+
+	var cursor uint64
+	for {
+		var keys []string
+		var err error
+		keys, cursor, err = c.client.Scan(ctx, cursor, redisPrefix+"::*", int64(c.batchSize)).Result()
+		if err != nil {
+			return xerrors.Errorf("failed to perform prefix scanning: %w", err)
+		}
+		if len(keys) > 0 {
+			if err = c.client.Unlink(ctx, keys...).Err(); err != nil {
+				return xerrors.Errorf("failed to unlink redis keys: %w", err)
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+var (
+	// The lock is released and extended only by the owner
+	unlockScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+	extendScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`)
+)
+
+// Lock acquires the lock of the key shared by the processes using the same Redis, e.g. replicas of the server.
+// It returns false without waiting if another process holds the lock.
+// The lock is extended until unlock is called, and it expires after ttl if the process crashes.
+func (c RedisCache) Lock(ctx context.Context, key string, ttl time.Duration) (unlock func() error, acquired bool, err error) {
+	k := redisKey(lockBucket, key)
+	token := uuid.NewString()
+	acquired, err = c.client.SetNX(ctx, k, token, ttl).Result()
+	if err != nil {
+		return nil, false, xerrors.Errorf("unable to acquire the lock (%s): %w", key, err)
+	} else if !acquired {
+		return nil, false, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// The lock expires if it fails to be extended, and then another process may acquire it
+				_ = extendScript.Run(context.Background(), c.client, []string{k}, token, ttl.Milliseconds()).Err()
+			}
+		}
+	}()
+
+	var once sync.Once
+	unlock = func() error {
+		once.Do(func() {
+			close(done)
+			if e := unlockScript.Run(context.Background(), c.client, []string{k}, token).Err(); e != nil {
+				err = xerrors.Errorf("unable to release the lock (%s): %w", key, e)
+			}
+		})
+		return err
+	}
+	return unlock, true, nil
+}
+
+func redisKey(bucket, id string) string {
+	return fmt.Sprintf("%s::%s::%s", redisPrefix, bucket, id)
+}
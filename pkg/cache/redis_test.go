@@ -0,0 +1,447 @@
+package cache_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/cache"
+)
+
+func TestRedisCache_MissingBlobs(t *testing.T) {
+	type args struct {
+		artifactID string
+		blobIDs    []string
+	}
+	tests := []struct {
+		name                string
+		batchSize           int
+		args                args
+		wantMissingArtifact bool
+		wantMissingBlobIDs  []string
+		wantErr             string
+	}{
+		{
+			name: "all cached",
+			args: args{
+				artifactID: "sha256:artifact",
+				blobIDs:    []string{"sha256:blob1", "sha256:blob2"},
+			},
+		},
+		{
+			name:      "missing artifact and blobs in multiple batches",
+			batchSize: 2,
+			args: args{
+				artifactID: "sha256:missing",
+				blobIDs:    []string{"sha256:blob1", "sha256:missing1", "sha256:blob2", "sha256:old", "sha256:missing2"},
+			},
+			wantMissingArtifact: true,
+			wantMissingBlobIDs:  []string{"sha256:missing1", "sha256:old", "sha256:missing2"},
+		},
+		{
+			name: "old schema",
+			args: args{
+				artifactID: "sha256:old",
+			},
+			wantMissingArtifact: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := miniredis.Run()
+			require.NoError(t, err)
+			defer s.Close()
+
+			c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, tt.batchSize, nil, "", false)
+			defer c.Close()
+
+			require.NoError(t, c.PutArtifact("sha256:artifact", types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}))
+			require.NoError(t, c.PutArtifact("sha256:old", types.ArtifactInfo{SchemaVersion: 0}))
+			for _, blobID := range []string{"sha256:blob1", "sha256:blob2"} {
+				require.NoError(t, c.PutBlob(blobID, types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}))
+			}
+			require.NoError(t, c.PutBlob("sha256:old", types.BlobInfo{SchemaVersion: 0}))
+
+			gotMissingArtifact, gotMissingBlobIDs, err := c.MissingBlobs(tt.args.artifactID, tt.args.blobIDs)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMissingArtifact, gotMissingArtifact)
+			assert.Equal(t, tt.wantMissingBlobIDs, gotMissingBlobIDs)
+		})
+	}
+}
+
+func TestRedisCache_MissingBlobs_unreachable(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	addr := s.Addr()
+	s.Close()
+
+	c := cache.NewRedisCache(&redis.Options{Addr: addr, MaxRetries: -1}, 0, 0, nil, "", false)
+	defer c.Close()
+
+	_, _, err = c.MissingBlobs("sha256:artifact", []string{"sha256:blob"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to get the artifact and blobs from the Redis cache")
+	assert.Error(t, c.Ping(context.Background()))
+}
+
+func TestRedisCache_PutBlob(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, time.Hour, 0, nil, "", false)
+	defer c.Close()
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		Digest:        "sha256:digest",
+		DiffID:        "sha256:diffid",
+	}
+	require.NoError(t, c.PutBlob("sha256:blob", blobInfo))
+
+	// The key is compatible with fanal
+	assert.True(t, s.Exists("fanal::blob::sha256:blob"))
+	assert.Equal(t, time.Hour, s.TTL("fanal::blob::sha256:blob"))
+
+	got, err := c.GetBlob("sha256:blob")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, got)
+
+	_, err = c.GetBlob("sha256:missing")
+	assert.ErrorContains(t, err, "blob (sha256:missing) is missing in Redis cache")
+}
+
+func TestRedisCache_encryption(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	cipher, err := cache.NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	other, err := cache.NewCipher([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+
+	c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, cipher, "", false)
+	defer c.Close()
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+	require.NoError(t, c.PutBlob("sha256:blob", blobInfo))
+	require.NoError(t, c.PutArtifact("sha256:artifact", types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}))
+
+	// The value in Redis doesn't leak the contents
+	value, err := s.Get("fanal::blob::sha256:blob")
+	require.NoError(t, err)
+	assert.NotContains(t, value, "alpine")
+
+	got, err := c.GetBlob("sha256:blob")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, got)
+
+	missingArtifact, missingBlobIDs, err := c.MissingBlobs("sha256:artifact", []string{"sha256:blob"})
+	require.NoError(t, err)
+	assert.False(t, missingArtifact)
+	assert.Empty(t, missingBlobIDs)
+
+	// The values can't be swapped between the keys
+	require.NoError(t, s.Set("fanal::blob::sha256:swapped", value))
+	_, err = c.GetBlob("sha256:swapped")
+	assert.ErrorContains(t, err, "failed to decrypt the value")
+
+	// The values encrypted with another key and the plain values are handled as missing
+	otherCache := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, other, "", false)
+	defer otherCache.Close()
+	_, err = otherCache.GetBlob("sha256:blob")
+	assert.ErrorContains(t, err, "the value is encrypted with another key")
+
+	missingArtifact, missingBlobIDs, err = otherCache.MissingBlobs("sha256:artifact", []string{"sha256:blob"})
+	require.NoError(t, err)
+	assert.True(t, missingArtifact)
+	assert.Equal(t, []string{"sha256:blob"}, missingBlobIDs)
+
+	plainCache := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", false)
+	defer plainCache.Close()
+	_, err = plainCache.GetBlob("sha256:blob")
+	assert.ErrorContains(t, err, "the value is encrypted, '--cache-encryption-key' is required")
+
+	require.NoError(t, plainCache.PutBlob("sha256:plain", blobInfo))
+	_, missingBlobIDs, err = c.MissingBlobs("sha256:artifact", []string{"sha256:plain"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:plain"}, missingBlobIDs)
+}
+
+func TestRedisCache_compression(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+	plainCache := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", false)
+	defer plainCache.Close()
+	require.NoError(t, plainCache.PutBlob("sha256:plain", blobInfo))
+
+	tests := []struct {
+		compression cache.Compression
+		magic       string
+	}{
+		{
+			compression: cache.CompressionGzip,
+			magic:       "\x1f\x8b",
+		},
+		{
+			compression: cache.CompressionZstd,
+			magic:       "\x28\xb5\x2f\xfd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.compression), func(t *testing.T) {
+			c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, tt.compression, false)
+			defer c.Close()
+
+			blobID := "sha256:" + string(tt.compression)
+			require.NoError(t, c.PutBlob(blobID, blobInfo))
+
+			value, err := s.Get("fanal::blob::" + blobID)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(value, tt.magic))
+
+			// The values are read whatever the compression is
+			for _, id := range []string{blobID, "sha256:plain"} {
+				got, err := c.GetBlob(id)
+				require.NoError(t, err)
+				assert.Equal(t, blobInfo, got)
+			}
+			got, err := plainCache.GetBlob(blobID)
+			require.NoError(t, err)
+			assert.Equal(t, blobInfo, got)
+		})
+	}
+}
+
+func TestRedisCache_dedup(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	cipher, err := cache.NewCipher([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, time.Hour, 0, cipher, cache.CompressionZstd, true)
+	defer c.Close()
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		DiffID:        "sha256:diffid",
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+	require.NoError(t, c.PutBlob("sha256:blob1", blobInfo))
+	require.NoError(t, c.PutBlob("sha256:blob2", blobInfo))
+	require.NoError(t, c.PutBlob("sha256:fs", types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}))
+
+	// The analysis result is stored once for both blobs, and the blob without the diff ID is stored as it is
+	var layerKeys []string
+	for _, key := range s.Keys() {
+		if strings.HasPrefix(key, "fanal::layer::sha256:diffid::") {
+			layerKeys = append(layerKeys, key)
+		}
+	}
+	require.Len(t, layerKeys, 1)
+	assert.Len(t, s.Keys(), 4)
+
+	// The key of the result is the HMAC under the encryption key, not the hash of the result
+	b, err := json.Marshal(blobInfo)
+	require.NoError(t, err)
+	assert.Contains(t, layerKeys[0], "::"+cipher.KeyID()+"::hmac-sha256:")
+	assert.NotContains(t, layerKeys[0], fmt.Sprintf("%x", sha256.Sum256(b)))
+	assert.Equal(t, time.Hour, s.TTL(layerKeys[0]))
+
+	for _, blobID := range []string{"sha256:blob1", "sha256:blob2"} {
+		got, err := c.GetBlob(blobID)
+		require.NoError(t, err)
+		assert.Equal(t, blobInfo, got)
+	}
+
+	// The blobs without dedup read the references as well
+	plainCache := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, cipher, "", false)
+	defer plainCache.Close()
+	got, err := plainCache.GetBlob("sha256:blob1")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, got)
+
+	_, missingBlobIDs, err := c.MissingBlobs("sha256:artifact", []string{"sha256:blob1", "sha256:blob2", "sha256:fs"})
+	require.NoError(t, err)
+	assert.Empty(t, missingBlobIDs)
+
+	// The stored result is kept for the other blobs
+	require.NoError(t, c.DeleteBlobs([]string{"sha256:blob1"}))
+	got, err = c.GetBlob("sha256:blob2")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, got)
+
+	// The blobs referring to the evicted result are missing
+	s.Del(layerKeys[0])
+	_, missingBlobIDs, err = c.MissingBlobs("sha256:artifact", []string{"sha256:blob2", "sha256:fs"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:blob2"}, missingBlobIDs)
+	_, err = c.GetBlob("sha256:blob2")
+	assert.ErrorContains(t, err, "blob (sha256:blob2) is missing in Redis cache")
+}
+
+func TestRedisCache_dedup_anotherKey(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	keyA, err := cache.NewCipher([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	keyB, err := cache.NewCipher([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion: types.BlobJSONSchemaVersion,
+		DiffID:        "sha256:diffid",
+		OS: &types.OS{
+			Family: "alpine",
+			Name:   "3.15.4",
+		},
+	}
+
+	// The result stored without the encryption and with key A is not taken for the one with key B
+	plainCache := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", true)
+	defer plainCache.Close()
+	cacheA := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, keyA, "", true)
+	defer cacheA.Close()
+	cacheB := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, keyB, "", true)
+	defer cacheB.Close()
+	require.NoError(t, plainCache.PutBlob("sha256:plain", blobInfo))
+	require.NoError(t, cacheA.PutBlob("sha256:blobA", blobInfo))
+	require.NoError(t, cacheB.PutBlob("sha256:blobB", blobInfo))
+
+	var layerKeys []string
+	for _, key := range s.Keys() {
+		if strings.HasPrefix(key, "fanal::layer::") {
+			layerKeys = append(layerKeys, key)
+		}
+	}
+	assert.Len(t, layerKeys, 3)
+
+	got, err := cacheB.GetBlob("sha256:blobB")
+	require.NoError(t, err)
+	assert.Equal(t, blobInfo, got)
+	_, missingBlobIDs, err := cacheB.MissingBlobs("sha256:artifact", []string{"sha256:blobA", "sha256:blobB"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:blobA"}, missingBlobIDs)
+
+	// The result which can't be decrypted with key B is missing, so that the layer is analyzed again
+	var contentA, contentKeyB string
+	for _, key := range layerKeys {
+		switch {
+		case strings.Contains(key, keyA.KeyID()):
+			contentA, err = s.Get(key)
+			require.NoError(t, err)
+		case strings.Contains(key, keyB.KeyID()):
+			contentKeyB = key
+		}
+	}
+	require.NoError(t, s.Set(contentKeyB, contentA))
+	_, missingBlobIDs, err = cacheB.MissingBlobs("sha256:artifact", []string{"sha256:blobB"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:blobB"}, missingBlobIDs)
+	_, err = cacheB.GetBlob("sha256:blobB")
+	assert.ErrorContains(t, err, "blob (sha256:blobB) is missing in Redis cache")
+}
+
+func TestRedisCache_DeleteBlobs(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 2, nil, "", false)
+	defer c.Close()
+
+	var blobIDs []string
+	for i := 0; i < 5; i++ {
+		blobID := fmt.Sprintf("sha256:blob%d", i)
+		require.NoError(t, c.PutBlob(blobID, types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}))
+		blobIDs = append(blobIDs, blobID)
+	}
+	require.NoError(t, c.PutArtifact("sha256:artifact", types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}))
+
+	require.NoError(t, c.DeleteBlobs(blobIDs))
+	assert.Equal(t, []string{"fanal::artifact::sha256:artifact"}, s.Keys())
+
+	require.NoError(t, c.Clear())
+	assert.Empty(t, s.Keys())
+}
+
+func TestRedisCache_Lock(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	c := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", false)
+	defer c.Close()
+
+	ctx := context.Background()
+	ttl := 300 * time.Millisecond
+	unlock, acquired, err := c.Lock(ctx, "sha256:blob", ttl)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Equal(t, ttl, s.TTL("fanal::lock::sha256:blob"))
+
+	// Another replica can't acquire the lock
+	_, acquired, err = c.Lock(ctx, "sha256:blob", ttl)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// The lock is extended while it is held
+	s.FastForward(200 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return s.TTL("fanal::lock::sha256:blob") == ttl
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, unlock())
+	assert.False(t, s.Exists("fanal::lock::sha256:blob"))
+
+	unlock, acquired, err = c.Lock(ctx, "sha256:blob", ttl)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// The lock acquired by another replica after the expiration is not released
+	s.FastForward(ttl)
+	_, acquired, err = c.Lock(ctx, "sha256:blob", ttl)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, unlock())
+	assert.True(t, s.Exists("fanal::lock::sha256:blob"))
+}
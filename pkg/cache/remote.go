@@ -2,8 +2,6 @@ package cache
 
 import (
 	"context"
-	"crypto/tls"
-	"net/http"
 
 	"golang.org/x/xerrors"
 
@@ -14,31 +12,27 @@ import (
 	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
 )
 
-// RemoteCache implements remote cache
+// RemoteCache implements remote cache.
+// Requests are retried when the server is updating the DB, restarting or overloaded.
 type RemoteCache struct {
 	ctx    context.Context // for custom header
+	retry  rpc.RetryOption
 	client rpcCache.Cache
 }
 
-// NewRemoteCache is the factory method for RemoteCache
-func NewRemoteCache(url string, customHeaders http.Header, insecure bool) cache.ArtifactCache {
-	ctx := client.WithCustomHeaders(context.Background(), customHeaders)
-
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: insecure,
-			},
-		},
-	}
-	c := rpcCache.NewCacheProtobufClient(url, httpClient)
-	return &RemoteCache{ctx: ctx, client: c}
+// NewRemoteCache is the factory method for RemoteCache.
+// The client certificates are presented when the server requires them.
+func NewRemoteCache(option client.ScannerOption) cache.ArtifactCache {
+	ctx := client.WithCustomHeaders(context.Background(), option.CustomHeaders)
+	return &RemoteCache{ctx: ctx, retry: option.Retry, client: client.NewCacheClient(option)}
 }
 
 // PutArtifact sends artifact to remote client
 func (c RemoteCache) PutArtifact(imageID string, artifactInfo types.ArtifactInfo) error {
-	_, err := c.client.PutArtifact(c.ctx, rpc.ConvertToRPCArtifactInfo(imageID, artifactInfo))
+	err := rpc.Retry(c.ctx, c.retry, func() error {
+		_, err := c.client.PutArtifact(c.ctx, rpc.ConvertToRPCArtifactInfo(imageID, artifactInfo))
+		return err
+	})
 	if err != nil {
 		return xerrors.Errorf("unable to store cache on the server: %w", err)
 	}
@@ -47,7 +41,10 @@ func (c RemoteCache) PutArtifact(imageID string, artifactInfo types.ArtifactInfo
 
 // PutBlob sends blobInfo to remote client
 func (c RemoteCache) PutBlob(diffID string, blobInfo types.BlobInfo) error {
-	_, err := c.client.PutBlob(c.ctx, rpc.ConvertToRPCBlobInfo(diffID, blobInfo))
+	err := rpc.Retry(c.ctx, c.retry, func() error {
+		_, err := c.client.PutBlob(c.ctx, rpc.ConvertToRPCBlobInfo(diffID, blobInfo))
+		return err
+	})
 	if err != nil {
 		return xerrors.Errorf("unable to store cache on the server: %w", err)
 	}
@@ -56,7 +53,12 @@ func (c RemoteCache) PutBlob(diffID string, blobInfo types.BlobInfo) error {
 
 // MissingBlobs fetches missing blobs from RemoteCache
 func (c RemoteCache) MissingBlobs(imageID string, layerIDs []string) (bool, []string, error) {
-	layers, err := c.client.MissingBlobs(c.ctx, rpc.ConvertToMissingBlobsRequest(imageID, layerIDs))
+	var layers *rpcCache.MissingBlobsResponse
+	err := rpc.Retry(c.ctx, c.retry, func() error {
+		var err error
+		layers, err = c.client.MissingBlobs(c.ctx, rpc.ConvertToMissingBlobsRequest(imageID, layerIDs))
+		return err
+	})
 	if err != nil {
 		return false, nil, xerrors.Errorf("unable to fetch missing layers: %w", err)
 	}
@@ -65,7 +67,10 @@ func (c RemoteCache) MissingBlobs(imageID string, layerIDs []string) (bool, []st
 
 // DeleteBlobs removes blobs by IDs from RemoteCache
 func (c RemoteCache) DeleteBlobs(blobIDs []string) error {
-	_, err := c.client.DeleteBlobs(c.ctx, rpc.ConvertToDeleteBlobsRequest(blobIDs))
+	err := rpc.Retry(c.ctx, c.retry, func() error {
+		_, err := c.client.DeleteBlobs(c.ctx, rpc.ConvertToDeleteBlobsRequest(blobIDs))
+		return err
+	})
 	if err != nil {
 		return xerrors.Errorf("unable to delete blobs on the server: %w", err)
 	}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+var _ cache.Cache = &TieredCache{}
+
+// TieredCache implements the cache with the local cache in front of the remote cache shared with others.
+// The lookups hit the local cache first, and the local cache is populated with the entries found in the remote cache,
+// so that only the misses pay the network latency. The entries are written to both tiers.
+type TieredCache struct {
+	local  cache.Cache
+	remote cache.Cache
+}
+
+// NewTieredCache is the factory method for TieredCache
+func NewTieredCache(local, remote cache.Cache) TieredCache {
+	return TieredCache{
+		local:  local,
+		remote: remote,
+	}
+}
+
+// Remote returns the remote tier
+func (c TieredCache) Remote() cache.Cache {
+	return c.remote
+}
+
+func (c TieredCache) PutArtifact(artifactID string, artifactInfo types.ArtifactInfo) error {
+	if err := c.local.PutArtifact(artifactID, artifactInfo); err != nil {
+		return xerrors.Errorf("local cache error: %w", err)
+	}
+	if err := c.remote.PutArtifact(artifactID, artifactInfo); err != nil {
+		return xerrors.Errorf("remote cache error: %w", err)
+	}
+	return nil
+}
+
+func (c TieredCache) PutBlob(blobID string, blobInfo types.BlobInfo) error {
+	if err := c.local.PutBlob(blobID, blobInfo); err != nil {
+		return xerrors.Errorf("local cache error: %w", err)
+	}
+	if err := c.remote.PutBlob(blobID, blobInfo); err != nil {
+		return xerrors.Errorf("remote cache error: %w", err)
+	}
+	return nil
+}
+
+func (c TieredCache) GetArtifact(artifactID string) (types.ArtifactInfo, error) {
+	if artifactInfo, err := c.local.GetArtifact(artifactID); err == nil {
+		return artifactInfo, nil
+	}
+	artifactInfo, err := c.remote.GetArtifact(artifactID)
+	if err != nil {
+		return types.ArtifactInfo{}, xerrors.Errorf("remote cache error: %w", err)
+	}
+	if err = c.local.PutArtifact(artifactID, artifactInfo); err != nil {
+		log.Logger.Debugf("Unable to store the artifact (%s) in the local cache: %s", artifactID, err)
+	}
+	return artifactInfo, nil
+}
+
+func (c TieredCache) GetBlob(blobID string) (types.BlobInfo, error) {
+	if blobInfo, err := c.local.GetBlob(blobID); err == nil {
+		return blobInfo, nil
+	}
+	blobInfo, err := c.remote.GetBlob(blobID)
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("remote cache error: %w", err)
+	}
+	if err = c.local.PutBlob(blobID, blobInfo); err != nil {
+		log.Logger.Debugf("Unable to store the blob (%s) in the local cache: %s", blobID, err)
+	}
+	return blobInfo, nil
+}
+
+// MissingBlobs looks up the remote cache only for the artifact and the blobs missing in the local cache,
+// and copies the ones found there into the local cache
+func (c TieredCache) MissingBlobs(artifactID string, blobIDs []string) (bool, []string, error) {
+	missingArtifact, missingBlobIDs, err := c.local.MissingBlobs(artifactID, blobIDs)
+	if err != nil {
+		return false, nil, xerrors.Errorf("local cache error: %w", err)
+	}
+	if !missingArtifact && len(missingBlobIDs) == 0 {
+		return false, nil, nil
+	}
+
+	remoteMissingArtifact, remoteMissingBlobIDs, err := c.remote.MissingBlobs(artifactID, missingBlobIDs)
+	if err != nil {
+		return false, nil, xerrors.Errorf("remote cache error: %w", err)
+	}
+
+	if missingArtifact && !remoteMissingArtifact {
+		// The artifact may expire in the remote cache in the meantime
+		if _, err = c.GetArtifact(artifactID); err != nil {
+			log.Logger.Debugf("Unable to copy the artifact (%s) from the remote cache: %s", artifactID, err)
+			remoteMissingArtifact = true
+		}
+	}
+	for _, blobID := range missingBlobIDs {
+		if slices.Contains(remoteMissingBlobIDs, blobID) {
+			continue
+		}
+		if _, err = c.GetBlob(blobID); err != nil {
+			log.Logger.Debugf("Unable to copy the blob (%s) from the remote cache: %s", blobID, err)
+			remoteMissingBlobIDs = append(remoteMissingBlobIDs, blobID)
+		}
+	}
+	return missingArtifact && remoteMissingArtifact, remoteMissingBlobIDs, nil
+}
+
+func (c TieredCache) DeleteBlobs(blobIDs []string) error {
+	if err := c.local.DeleteBlobs(blobIDs); err != nil {
+		return xerrors.Errorf("local cache error: %w", err)
+	}
+	if err := c.remote.DeleteBlobs(blobIDs); err != nil {
+		return xerrors.Errorf("remote cache error: %w", err)
+	}
+	return nil
+}
+
+func (c TieredCache) Close() error {
+	localErr := c.local.Close()
+	remoteErr := c.remote.Close()
+	if localErr != nil {
+		return xerrors.Errorf("local cache error: %w", localErr)
+	} else if remoteErr != nil {
+		return xerrors.Errorf("remote cache error: %w", remoteErr)
+	}
+	return nil
+}
+
+func (c TieredCache) Clear() error {
+	if err := c.local.Clear(); err != nil {
+		return xerrors.Errorf("local cache error: %w", err)
+	}
+	if err := c.remote.Clear(); err != nil {
+		return xerrors.Errorf("remote cache error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fcache "github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/cache"
+)
+
+func TestTieredCache_MissingBlobs(t *testing.T) {
+	type args struct {
+		artifactID string
+		blobIDs    []string
+	}
+	tests := []struct {
+		name                string
+		args                args
+		wantMissingArtifact bool
+		wantMissingBlobIDs  []string
+		wantLocalBlobIDs    []string
+	}{
+		{
+			name: "all cached locally",
+			args: args{
+				artifactID: "sha256:local",
+				blobIDs:    []string{"sha256:local1"},
+			},
+			wantLocalBlobIDs: []string{"sha256:local1"},
+		},
+		{
+			name: "cached in the remote tier",
+			args: args{
+				artifactID: "sha256:remote",
+				blobIDs:    []string{"sha256:local1", "sha256:remote1", "sha256:remote2"},
+			},
+			wantLocalBlobIDs: []string{"sha256:local1", "sha256:remote1", "sha256:remote2"},
+		},
+		{
+			name: "missing in both tiers",
+			args: args{
+				artifactID: "sha256:missing",
+				blobIDs:    []string{"sha256:local1", "sha256:remote1", "sha256:missing1"},
+			},
+			wantMissingArtifact: true,
+			wantMissingBlobIDs:  []string{"sha256:missing1"},
+			wantLocalBlobIDs:    []string{"sha256:local1", "sha256:remote1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := miniredis.Run()
+			require.NoError(t, err)
+			defer s.Close()
+
+			local, err := fcache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			remote := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", false)
+			c := cache.NewTieredCache(local, remote)
+			defer c.Close()
+
+			artifactInfo := types.ArtifactInfo{SchemaVersion: types.ArtifactJSONSchemaVersion}
+			blobInfo := types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}
+			require.NoError(t, local.PutArtifact("sha256:local", artifactInfo))
+			require.NoError(t, local.PutBlob("sha256:local1", blobInfo))
+			require.NoError(t, remote.PutArtifact("sha256:remote", artifactInfo))
+			for _, blobID := range []string{"sha256:remote1", "sha256:remote2"} {
+				require.NoError(t, remote.PutBlob(blobID, blobInfo))
+			}
+
+			gotMissingArtifact, gotMissingBlobIDs, err := c.MissingBlobs(tt.args.artifactID, tt.args.blobIDs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMissingArtifact, gotMissingArtifact)
+			assert.Equal(t, tt.wantMissingBlobIDs, gotMissingBlobIDs)
+
+			// The entries found in the remote tier are copied into the local tier
+			localMissingArtifact, localMissingBlobIDs, err := local.MissingBlobs(tt.args.artifactID, tt.wantLocalBlobIDs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMissingArtifact, localMissingArtifact)
+			assert.Empty(t, localMissingBlobIDs)
+		})
+	}
+}
+
+func TestTieredCache_GetBlob(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	local, err := fcache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	remote := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", false)
+	c := cache.NewTieredCache(local, remote)
+	defer c.Close()
+
+	want := types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion, DiffID: "sha256:diff"}
+	require.NoError(t, remote.PutBlob("sha256:blob", want))
+
+	got, err := c.GetBlob("sha256:blob")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// The next lookup hits the local tier
+	s.FlushAll()
+	got, err = c.GetBlob("sha256:blob")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = c.GetBlob("sha256:missing")
+	assert.Error(t, err)
+}
+
+func TestTieredCache_PutBlob(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	local, err := fcache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	remote := cache.NewRedisCache(&redis.Options{Addr: s.Addr()}, 0, 0, nil, "", false)
+	c := cache.NewTieredCache(local, remote)
+	defer c.Close()
+
+	want := types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion, DiffID: "sha256:diff"}
+	require.NoError(t, c.PutBlob("sha256:blob", want))
+
+	// The blob is written to both tiers
+	for _, tier := range []fcache.Cache{local, remote} {
+		got, err := tier.GetBlob("sha256:blob")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	require.NoError(t, c.DeleteBlobs([]string{"sha256:blob"}))
+	for _, tier := range []fcache.Cache{local, remote} {
+		_, missingBlobIDs, err := tier.MissingBlobs("sha256:artifact", []string{"sha256:blob"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sha256:blob"}, missingBlobIDs)
+	}
+}
@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"golang.org/x/xerrors"
+)
+
+const (
+	ServiceECR    = "ecr"
+	ServiceLambda = "lambda"
+	ServiceEC2    = "ec2"
+)
+
+// Services are the AWS services whose resources are scanned
+var Services = []string{ServiceECR, ServiceLambda, ServiceEC2}
+
+// Resource is an AWS resource to scan
+type Resource struct {
+	Account string
+	Region  string
+	Service string
+
+	// ID is the ARN of the ECR repository or the Lambda layer version, or the ID of the AMI
+	ID string
+
+	// Target is the image name of the ECR image, or the same as ID for the others
+	Target string
+}
+
+// client enumerates the resources of the account in the region
+type client struct {
+	account string
+	region  string
+	ecr     ecriface.ECRAPI
+	lambda  lambdaiface.LambdaAPI
+	ec2     ec2iface.EC2API
+}
+
+func newClient(sess *session.Session, account, region string) client {
+	cfg := aws.NewConfig().WithRegion(region)
+	return client{
+		account: account,
+		region:  region,
+		ecr:     ecr.New(sess, cfg),
+		lambda:  lambda.New(sess, cfg),
+		ec2:     ec2.New(sess, cfg),
+	}
+}
+
+// resources returns the resources of the services
+func (c client) resources(ctx context.Context, services []string) ([]Resource, error) {
+	var resources []Resource
+	for _, service := range services {
+		var rs []Resource
+		var err error
+		switch service {
+		case ServiceECR:
+			rs, err = c.ecrImages(ctx)
+		case ServiceLambda:
+			rs, err = c.lambdaLayers(ctx)
+		case ServiceEC2:
+			rs, err = c.amis(ctx)
+		default:
+			return nil, xerrors.Errorf("unknown service: %s", service)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("%s error (%s): %w", service, c.region, err)
+		}
+		resources = append(resources, rs...)
+	}
+	return resources, nil
+}
+
+// ecrImages returns the latest pushed image with a tag in each repository
+func (c client) ecrImages(ctx context.Context) ([]Resource, error) {
+	var repos []*ecr.Repository
+	err := c.ecr.DescribeRepositoriesPagesWithContext(ctx, &ecr.DescribeRepositoriesInput{},
+		func(out *ecr.DescribeRepositoriesOutput, _ bool) bool {
+			repos = append(repos, out.Repositories...)
+			return true
+		})
+	if err != nil {
+		return nil, xerrors.Errorf("unable to describe repositories: %w", err)
+	}
+
+	var resources []Resource
+	for _, repo := range repos {
+		var latest *ecr.ImageDetail
+		err = c.ecr.DescribeImagesPagesWithContext(ctx, &ecr.DescribeImagesInput{
+			RepositoryName: repo.RepositoryName,
+			RegistryId:     repo.RegistryId,
+			Filter:         &ecr.DescribeImagesFilter{TagStatus: aws.String(ecr.TagStatusTagged)},
+		}, func(out *ecr.DescribeImagesOutput, _ bool) bool {
+			for _, image := range out.ImageDetails {
+				if len(image.ImageTags) == 0 {
+					continue
+				}
+				if latest == nil || aws.TimeValue(image.ImagePushedAt).After(aws.TimeValue(latest.ImagePushedAt)) {
+					latest = image
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("unable to describe images of %s: %w", aws.StringValue(repo.RepositoryName), err)
+		}
+		if latest == nil {
+			continue
+		}
+
+		resources = append(resources, Resource{
+			Account: c.account,
+			Region:  c.region,
+			Service: ServiceECR,
+			ID:      aws.StringValue(repo.RepositoryArn),
+			Target:  aws.StringValue(repo.RepositoryUri) + ":" + aws.StringValue(latest.ImageTags[0]),
+		})
+	}
+	return resources, nil
+}
+
+// lambdaLayers returns the latest version of each layer
+func (c client) lambdaLayers(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	err := c.lambda.ListLayersPagesWithContext(ctx, &lambda.ListLayersInput{},
+		func(out *lambda.ListLayersOutput, _ bool) bool {
+			for _, layer := range out.Layers {
+				if layer.LatestMatchingVersion == nil {
+					continue
+				}
+				arn := aws.StringValue(layer.LatestMatchingVersion.LayerVersionArn)
+				resources = append(resources, Resource{
+					Account: c.account,
+					Region:  c.region,
+					Service: ServiceLambda,
+					ID:      arn,
+					Target:  arn,
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, xerrors.Errorf("unable to list layers: %w", err)
+	}
+	return resources, nil
+}
+
+// layerLocation returns the URL to download the layer archive, which is valid for 10 minutes
+func (c client) layerLocation(ctx context.Context, arn string) (string, error) {
+	out, err := c.lambda.GetLayerVersionByArnWithContext(ctx, &lambda.GetLayerVersionByArnInput{
+		Arn: aws.String(arn),
+	})
+	if err != nil {
+		return "", xerrors.Errorf("unable to get the layer version: %w", err)
+	}
+	if out.Content == nil || out.Content.Location == nil {
+		return "", xerrors.Errorf("no content location of %s", arn)
+	}
+	return aws.StringValue(out.Content.Location), nil
+}
+
+// amis returns the AMIs of the running instances
+func (c client) amis(ctx context.Context) ([]Resource, error) {
+	imageIDs := map[string]struct{}{}
+	err := c.ec2.DescribeInstancesPagesWithContext(ctx, &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{ec2.InstanceStateNameRunning}),
+			},
+		},
+	}, func(out *ec2.DescribeInstancesOutput, _ bool) bool {
+		for _, reservation := range out.Reservations {
+			for _, instance := range reservation.Instances {
+				imageIDs[aws.StringValue(instance.ImageId)] = struct{}{}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("unable to describe instances: %w", err)
+	}
+
+	var resources []Resource
+	for imageID := range imageIDs {
+		resources = append(resources, Resource{
+			Account: c.account,
+			Region:  c.region,
+			Service: ServiceEC2,
+			ID:      imageID,
+			Target:  imageID,
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID < resources[j].ID
+	})
+	return resources, nil
+}
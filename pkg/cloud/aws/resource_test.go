@@ -0,0 +1,209 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+type fakeECR struct {
+	ecriface.ECRAPI
+	repos  []*ecr.Repository
+	images map[string][]*ecr.ImageDetail
+	err    error
+}
+
+func (f fakeECR) DescribeRepositoriesPagesWithContext(_ aws.Context, _ *ecr.DescribeRepositoriesInput,
+	fn func(*ecr.DescribeRepositoriesOutput, bool) bool, _ ...request.Option) error {
+	if f.err != nil {
+		return f.err
+	}
+	fn(&ecr.DescribeRepositoriesOutput{Repositories: f.repos}, true)
+	return nil
+}
+
+func (f fakeECR) DescribeImagesPagesWithContext(_ aws.Context, in *ecr.DescribeImagesInput,
+	fn func(*ecr.DescribeImagesOutput, bool) bool, _ ...request.Option) error {
+	// Each image is returned in its own page
+	images := f.images[aws.StringValue(in.RepositoryName)]
+	for i, image := range images {
+		fn(&ecr.DescribeImagesOutput{ImageDetails: []*ecr.ImageDetail{image}}, i == len(images)-1)
+	}
+	return nil
+}
+
+type fakeLambda struct {
+	lambdaiface.LambdaAPI
+	layers []*lambda.LayersListItem
+}
+
+func (f fakeLambda) ListLayersPagesWithContext(_ aws.Context, _ *lambda.ListLayersInput,
+	fn func(*lambda.ListLayersOutput, bool) bool, _ ...request.Option) error {
+	fn(&lambda.ListLayersOutput{Layers: f.layers}, true)
+	return nil
+}
+
+type fakeEC2 struct {
+	ec2iface.EC2API
+	reservations []*ec2.Reservation
+}
+
+func (f fakeEC2) DescribeInstancesPagesWithContext(_ aws.Context, _ *ec2.DescribeInstancesInput,
+	fn func(*ec2.DescribeInstancesOutput, bool) bool, _ ...request.Option) error {
+	fn(&ec2.DescribeInstancesOutput{Reservations: f.reservations}, true)
+	return nil
+}
+
+func Test_client_resources(t *testing.T) {
+	pushedAt := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	fakes := client{
+		account: "123456789012",
+		region:  "us-east-1",
+		ecr: fakeECR{
+			repos: []*ecr.Repository{
+				{
+					RepositoryName: aws.String("app"),
+					RepositoryArn:  aws.String("arn:aws:ecr:us-east-1:123456789012:repository/app"),
+					RepositoryUri:  aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/app"),
+				},
+				{
+					RepositoryName: aws.String("empty"),
+					RepositoryArn:  aws.String("arn:aws:ecr:us-east-1:123456789012:repository/empty"),
+					RepositoryUri:  aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/empty"),
+				},
+			},
+			images: map[string][]*ecr.ImageDetail{
+				"app": {
+					{ImageTags: aws.StringSlice([]string{"v1"}), ImagePushedAt: aws.Time(pushedAt)},
+					{ImageTags: aws.StringSlice([]string{"v2", "latest"}), ImagePushedAt: aws.Time(pushedAt.AddDate(0, 0, 1))},
+					{ImageTags: aws.StringSlice([]string{"v0"}), ImagePushedAt: aws.Time(pushedAt.AddDate(0, 0, -1))},
+				},
+			},
+		},
+		lambda: fakeLambda{
+			layers: []*lambda.LayersListItem{
+				{
+					LayerName: aws.String("requests"),
+					LatestMatchingVersion: &lambda.LayerVersionsListItem{
+						LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:requests:3"),
+					},
+				},
+				{
+					LayerName: aws.String("deleted"),
+				},
+			},
+		},
+		ec2: fakeEC2{
+			reservations: []*ec2.Reservation{
+				{
+					Instances: []*ec2.Instance{
+						{ImageId: aws.String("ami-0b")},
+						{ImageId: aws.String("ami-0a")},
+					},
+				},
+				{
+					Instances: []*ec2.Instance{
+						{ImageId: aws.String("ami-0b")},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		client   client
+		services []string
+		want     []Resource
+		wantErr  string
+	}{
+		{
+			name:     "happy path",
+			client:   fakes,
+			services: Services,
+			want: []Resource{
+				{
+					Account: "123456789012",
+					Region:  "us-east-1",
+					Service: ServiceECR,
+					ID:      "arn:aws:ecr:us-east-1:123456789012:repository/app",
+					Target:  "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:v2",
+				},
+				{
+					Account: "123456789012",
+					Region:  "us-east-1",
+					Service: ServiceLambda,
+					ID:      "arn:aws:lambda:us-east-1:123456789012:layer:requests:3",
+					Target:  "arn:aws:lambda:us-east-1:123456789012:layer:requests:3",
+				},
+				{
+					Account: "123456789012",
+					Region:  "us-east-1",
+					Service: ServiceEC2,
+					ID:      "ami-0a",
+					Target:  "ami-0a",
+				},
+				{
+					Account: "123456789012",
+					Region:  "us-east-1",
+					Service: ServiceEC2,
+					ID:      "ami-0b",
+					Target:  "ami-0b",
+				},
+			},
+		},
+		{
+			name:     "lambda only",
+			client:   fakes,
+			services: []string{ServiceLambda},
+			want: []Resource{
+				{
+					Account: "123456789012",
+					Region:  "us-east-1",
+					Service: ServiceLambda,
+					ID:      "arn:aws:lambda:us-east-1:123456789012:layer:requests:3",
+					Target:  "arn:aws:lambda:us-east-1:123456789012:layer:requests:3",
+				},
+			},
+		},
+		{
+			name: "sad path: API error",
+			client: client{
+				region: "us-east-1",
+				ecr:    fakeECR{err: xerrors.New("AccessDeniedException")},
+			},
+			services: []string{ServiceECR},
+			wantErr:  "ecr error (us-east-1): unable to describe repositories: AccessDeniedException",
+		},
+		{
+			name:     "sad path: unknown service",
+			client:   fakes,
+			services: []string{"s3"},
+			wantErr:  "unknown service: s3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.client.resources(context.Background(), tt.services)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	cmd "github.com/aquasecurity/trivy/pkg/commands/artifact"
+	"github.com/aquasecurity/trivy/pkg/downloader"
+	"github.com/aquasecurity/trivy/pkg/log"
+	pkgReport "github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// notScannedAMI is the reason why the AMIs are listed but not scanned
+const notScannedAMI = "scanning the disks of AMIs is not supported"
+
+// Run enumerates the resources of the AWS account in the regions and scans them
+func Run(cliCtx *cli.Context) error {
+	opt, err := cmd.InitOption(cliCtx)
+	if err != nil {
+		return xerrors.Errorf("option error: %w", err)
+	}
+	for _, service := range opt.Services {
+		if !slices.Contains(Services, service) {
+			return xerrors.Errorf("unknown service %q, must be %q", service, Services)
+		}
+	}
+	ctx := cliCtx.Context
+
+	// The ID is added to the JSON logs so that the logs of the scan can be correlated
+	log.SetScanID(uuid.NewString())
+
+	runner, err := cmd.NewRunner(opt)
+	if err != nil {
+		if errors.Is(err, cmd.SkipScan) {
+			return nil
+		}
+		return xerrors.Errorf("init error: %w", err)
+	}
+	defer func() {
+		if err := runner.Close(); err != nil {
+			log.Logger.Errorf("failed to close runner: %s", err)
+		}
+	}()
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return xerrors.Errorf("aws session error: %w", err)
+	}
+	identity, err := sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return xerrors.Errorf("unable to get the caller identity: %w", err)
+	}
+	account := aws.StringValue(identity.Account)
+
+	regions := opt.Regions
+	if len(regions) == 0 {
+		if region := aws.StringValue(sess.Config.Region); region != "" {
+			regions = []string{region}
+		} else {
+			return xerrors.New("no region is specified, use '--region' or AWS_REGION")
+		}
+	}
+
+	var clients []client
+	for _, region := range regions {
+		clients = append(clients, newClient(sess, account, region))
+	}
+
+	s := scanner{
+		runner: runner,
+		opt:    opt,
+	}
+	report, err := scanResources(ctx, account, clients, opt.Services, s.scan)
+	if err != nil {
+		return xerrors.Errorf("aws scan error: %w", err)
+	}
+
+	if err = runner.Report(opt, report); err != nil {
+		return xerrors.Errorf("report error: %w", err)
+	}
+	cmd.Exit(opt, report.Results.FailedBy(opt.FailCondition()))
+
+	return nil
+}
+
+// scanFunc scans the resource
+type scanFunc func(ctx context.Context, c client, r Resource) (types.Report, error)
+
+// scanResources scans the resources of the services in the regions one by one, and aggregates the results into one report.
+// The targets of the results are prefixed with the account and the region, e.g. "123456789012/us-east-1: ".
+func scanResources(ctx context.Context, account string, clients []client, services []string,
+	scan scanFunc) (types.Report, error) {
+	report := types.Report{
+		SchemaVersion: pkgReport.SchemaVersion,
+		ArtifactName:  account,
+	}
+	for _, c := range clients {
+		log.Logger.Infof("Enumerating the resources in %s...", c.region)
+		resources, err := c.resources(ctx, services)
+		if err != nil {
+			return types.Report{}, err
+		}
+
+		prefix := fmt.Sprintf("%s/%s: ", c.account, c.region)
+		for i, r := range resources {
+			if r.Service == ServiceEC2 {
+				report.Results = append(report.Results, types.Result{
+					Target:     prefix + r.Target,
+					NotScanned: notScannedAMI,
+				})
+				continue
+			}
+
+			log.Logger.Infof("Scanning %s (%d/%d)...", r.Target, i+1, len(resources))
+			rr, err := scan(ctx, c, r)
+			if err != nil {
+				// A resource failing to be scanned doesn't stop scanning the others
+				log.Logger.Errorf("Unable to scan %s: %s", r.Target, err)
+				report.Results = append(report.Results, types.Result{
+					Target:     prefix + r.Target,
+					NotScanned: fmt.Sprintf("scan error: %s", err),
+				})
+				continue
+			}
+
+			for _, result := range rr.Results {
+				// The targets of the results are relative to the resource except for OS packages
+				if !strings.HasPrefix(result.Target, rr.ArtifactName) {
+					result.Target = fmt.Sprintf("%s: %s", r.Target, result.Target)
+				}
+				result.Target = prefix + result.Target
+				report.Results = append(report.Results, result)
+			}
+		}
+	}
+	return report, nil
+}
+
+// scanner scans the resources with the runner
+type scanner struct {
+	runner *cmd.Runner
+	opt    cmd.Option
+}
+
+// scan scans and filters the resource with its own timeout
+func (s scanner) scan(ctx context.Context, c client, r Resource) (types.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opt.Timeout)
+	defer cancel()
+
+	opt := s.opt
+	var report types.Report
+	var err error
+	switch r.Service {
+	case ServiceECR:
+		opt.Target = r.Target
+		if report, err = s.runner.ScanImage(ctx, opt); err != nil {
+			return types.Report{}, xerrors.Errorf("image scan error: %w", err)
+		}
+	case ServiceLambda:
+		var dir string
+		if dir, err = downloadLayer(ctx, c, r.ID); err != nil {
+			return types.Report{}, xerrors.Errorf("layer download error: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		// The packages are installed in the layer, e.g. python/lib/python3.9/site-packages
+		opt.Target = dir
+		if report, err = s.runner.ScanRootfs(ctx, opt); err != nil {
+			return types.Report{}, xerrors.Errorf("layer scan error: %w", err)
+		}
+		// The results are reported relative to the layer rather than the temp dir
+		report.ArtifactName = r.Target
+	default:
+		return types.Report{}, xerrors.Errorf("unsupported service: %s", r.Service)
+	}
+
+	if report, err = s.runner.Filter(ctx, opt, report); err != nil {
+		return types.Report{}, xerrors.Errorf("filter error: %w", err)
+	}
+	return report, nil
+}
+
+// downloadLayer downloads and unpacks the archive of the layer version into a temp dir
+func downloadLayer(ctx context.Context, c client, arn string) (string, error) {
+	location, err := c.layerLocation(ctx, arn)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "trivy-aws-layer")
+	if err != nil {
+		return "", xerrors.Errorf("failed to create a temp dir: %w", err)
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", xerrors.Errorf("unable to get the current dir: %w", err)
+	}
+
+	// The presigned URL has no extension to tell the archive type
+	sep := "?"
+	if strings.Contains(location, "?") {
+		sep = "&"
+	}
+	if err = downloader.Download(ctx, location+sep+"archive=zip", dir, pwd); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", xerrors.Errorf("download error: %w", err)
+	}
+	return dir, nil
+}
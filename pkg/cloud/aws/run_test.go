@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_scanResources(t *testing.T) {
+	c := client{
+		account: "123456789012",
+		region:  "us-east-1",
+		ecr: fakeECR{
+			repos: []*ecr.Repository{
+				{
+					RepositoryName: aws.String("app"),
+					RepositoryUri:  aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/app"),
+				},
+			},
+			images: map[string][]*ecr.ImageDetail{
+				"app": {
+					{ImageTags: aws.StringSlice([]string{"v1"})},
+				},
+			},
+		},
+		lambda: fakeLambda{
+			layers: []*lambda.LayersListItem{
+				{
+					LatestMatchingVersion: &lambda.LayerVersionsListItem{
+						LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:requests:3"),
+					},
+				},
+				{
+					LatestMatchingVersion: &lambda.LayerVersionsListItem{
+						LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:broken:1"),
+					},
+				},
+			},
+		},
+		ec2: fakeEC2{
+			reservations: []*ec2.Reservation{
+				{Instances: []*ec2.Instance{{ImageId: aws.String("ami-0a")}}},
+			},
+		},
+	}
+
+	scan := func(_ context.Context, _ client, r Resource) (types.Report, error) {
+		switch r.Target {
+		case "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:v1":
+			return types.Report{
+				ArtifactName: r.Target,
+				Results: types.Results{
+					{
+						Target: r.Target + " (alpine 3.15.4)",
+						Class:  types.ClassOSPkg,
+						Type:   "alpine",
+					},
+					{
+						Target: "app/package-lock.json",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.Npm,
+					},
+				},
+			}, nil
+		case "arn:aws:lambda:us-east-1:123456789012:layer:requests:3":
+			return types.Report{
+				ArtifactName: r.Target,
+				Results: types.Results{
+					{
+						Target: "python/lib/python3.9/site-packages",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.PythonPkg,
+					},
+				},
+			}, nil
+		}
+		return types.Report{}, xerrors.New("download error")
+	}
+
+	got, err := scanResources(context.Background(), "123456789012", []client{c}, Services, scan)
+	require.NoError(t, err)
+
+	want := types.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "123456789012",
+		Results: types.Results{
+			{
+				Target: "123456789012/us-east-1: 123456789012.dkr.ecr.us-east-1.amazonaws.com/app:v1 (alpine 3.15.4)",
+				Class:  types.ClassOSPkg,
+				Type:   "alpine",
+			},
+			{
+				Target: "123456789012/us-east-1: 123456789012.dkr.ecr.us-east-1.amazonaws.com/app:v1: app/package-lock.json",
+				Class:  types.ClassLangPkg,
+				Type:   ftypes.Npm,
+			},
+			{
+				Target: "123456789012/us-east-1: arn:aws:lambda:us-east-1:123456789012:layer:requests:3: python/lib/python3.9/site-packages",
+				Class:  types.ClassLangPkg,
+				Type:   ftypes.PythonPkg,
+			},
+			{
+				Target:     "123456789012/us-east-1: arn:aws:lambda:us-east-1:123456789012:layer:broken:1",
+				NotScanned: "scan error: download error",
+			},
+			{
+				Target:     "123456789012/us-east-1: ami-0a",
+				NotScanned: "scanning the disks of AMIs is not supported",
+			},
+		},
+	}
+	assert.Equal(t, want, got)
+}
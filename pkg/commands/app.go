@@ -9,24 +9,43 @@ import (
 	"time"
 
 	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/aquasecurity/trivy-db/pkg/metadata"
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/buildkit"
+	"github.com/aquasecurity/trivy/pkg/cache"
+	"github.com/aquasecurity/trivy/pkg/cloud/aws"
 	"github.com/aquasecurity/trivy/pkg/commands/artifact"
 	"github.com/aquasecurity/trivy/pkg/commands/option"
 	"github.com/aquasecurity/trivy/pkg/commands/plugin"
 	"github.com/aquasecurity/trivy/pkg/commands/server"
+	"github.com/aquasecurity/trivy/pkg/db"
 	"github.com/aquasecurity/trivy/pkg/k8s"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/module"
+	"github.com/aquasecurity/trivy/pkg/osv"
+	"github.com/aquasecurity/trivy/pkg/progress"
+	"github.com/aquasecurity/trivy/pkg/rekor"
 	"github.com/aquasecurity/trivy/pkg/result"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/rpc/client"
 	"github.com/aquasecurity/trivy/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/utils"
 )
 
 // VersionInfo holds the trivy DB version Info
 type VersionInfo struct {
-	Version         string             `json:",omitempty"`
-	VulnerabilityDB *metadata.Metadata `json:",omitempty"`
+	Version         string               `json:",omitempty"`
+	VulnerabilityDB *VulnerabilityDBInfo `json:",omitempty"`
+}
+
+// VulnerabilityDBInfo holds the metadata of the DB and whether the DB has not been updated after its next update
+type VulnerabilityDBInfo struct {
+	metadata.Metadata
+	Stale bool `json:",omitempty"`
 }
 
 var (
@@ -46,14 +65,60 @@ var (
 		EnvVars: []string{"TRIVY_FORMAT"},
 	}
 
+	schemaFlag = cli.IntFlag{
+		Name:    "schema",
+		Usage:   "schema version of the JSON report for the consumers of the older versions (1, 2), the latest by default",
+		EnvVars: []string{"TRIVY_SCHEMA"},
+	}
+
+	diffFormatFlag = cli.StringFlag{
+		Name:    "format",
+		Aliases: []string{"f"},
+		Value:   "table",
+		Usage:   "format (table, json)",
+		EnvVars: []string{"TRIVY_FORMAT"},
+	}
+
 	inputFlag = cli.StringFlag{
 		Name:    "input",
 		Aliases: []string{"i"},
 		Value:   "",
-		Usage:   "input file path instead of image name",
+		Usage:   "input file path instead of image name, or \"rootfs-dir:<path>\" for an unpacked image filesystem",
 		EnvVars: []string{"TRIVY_INPUT"},
 	}
 
+	inputListFlag = cli.StringFlag{
+		Name:    "input-list",
+		Usage:   "YAML file listing the targets, or file listing them one per line, to be scanned into one report, \"-\" for stdin",
+		EnvVars: []string{"TRIVY_INPUT_LIST"},
+	}
+
+	outputDirFlag = cli.StringFlag{
+		Name:    "output-dir",
+		Usage:   "directory to write a report per target of '--input-list' instead of the combined report",
+		EnvVars: []string{"TRIVY_OUTPUT_DIR"},
+	}
+
+	imagesFileFlag = cli.StringFlag{
+		Name:     "images-file",
+		Usage:    "file listing the images to be analyzed, one per line",
+		EnvVars:  []string{"TRIVY_IMAGES_FILE"},
+		Required: true,
+	}
+
+	scanOrderFlag = cli.StringFlag{
+		Name:    "scan-order",
+		Value:   "list",
+		Usage:   "order to scan the targets in the input list (list, newest)",
+		EnvVars: []string{"TRIVY_SCAN_ORDER"},
+	}
+
+	priorityLabelFlag = cli.StringSliceFlag{
+		Name:    "priority-label",
+		Usage:   "scan the targets in the input list with the label first (e.g. env=production)",
+		EnvVars: []string{"TRIVY_PRIORITY_LABEL"},
+	}
+
 	severityFlag = cli.StringFlag{
 		Name:    "severity",
 		Aliases: []string{"s"},
@@ -76,6 +141,18 @@ var (
 		EnvVars: []string{"TRIVY_EXIT_CODE"},
 	}
 
+	exitOnSeverityFlag = cli.StringFlag{
+		Name:    "exit-on-severity",
+		Usage:   "exit with the exit code only when vulnerabilities or misconfigurations with the severity or higher were found",
+		EnvVars: []string{"TRIVY_EXIT_ON_SEVERITY"},
+	}
+
+	exitCodeFixedOnlyFlag = cli.BoolFlag{
+		Name:    "exit-code-fixed-only",
+		Usage:   "exit with the exit code only when fixable vulnerabilities are found, while unfixed ones are still reported",
+		EnvVars: []string{"TRIVY_EXIT_CODE_FIXED_ONLY"},
+	}
+
 	skipDBUpdateFlag = cli.BoolFlag{
 		Name:    "skip-db-update",
 		Aliases: []string{"skip-update"},
@@ -115,12 +192,32 @@ var (
 		EnvVars: []string{"TRIVY_QUIET"},
 	}
 
+	logFormatFlag = cli.StringFlag{
+		Name:    "log-format",
+		Value:   log.FormatConsole,
+		Usage:   "log format (console, json)",
+		EnvVars: []string{"TRIVY_LOG_FORMAT"},
+	}
+
+	logFileFlag = cli.StringFlag{
+		Name:    "log-file",
+		Usage:   "write the logs to the file instead of stdout and stderr",
+		EnvVars: []string{"TRIVY_LOG_FILE"},
+	}
+
 	noProgressFlag = cli.BoolFlag{
 		Name:    "no-progress",
 		Usage:   "suppress progress bar",
 		EnvVars: []string{"TRIVY_NO_PROGRESS"},
 	}
 
+	progressFlag = cli.StringFlag{
+		Name:    "progress",
+		Value:   progress.FormatBar,
+		Usage:   "progress format (bar, json), json emits the progress events as JSON lines on stderr instead of the progress bar",
+		EnvVars: []string{"TRIVY_PROGRESS"},
+	}
+
 	ignoreUnfixedFlag = cli.BoolFlag{
 		Name:    "ignore-unfixed",
 		Usage:   "display only fixed vulnerabilities",
@@ -140,17 +237,79 @@ var (
 		EnvVars: []string{"TRIVY_REMOVED_PKGS"},
 	}
 
+	esmFlag = cli.BoolFlag{
+		Name:    "esm",
+		Usage:   "the scanned environment is entitled to Ubuntu Pro/ESM security updates",
+		EnvVars: []string{"TRIVY_ESM"},
+	}
+
+	rebuildOfFlag = cli.StringFlag{
+		Name:    "annotate-rebuild-of",
+		Usage:   "specify the original image which the scanned image was rebuilt from",
+		EnvVars: []string{"TRIVY_ANNOTATE_REBUILD_OF"},
+	}
+
+	requireDigestFlag = cli.BoolFlag{
+		Name:    "require-digest",
+		Usage:   "refuse images referenced only by tags, e.g. 'alpine:3.15' instead of 'alpine@sha256:...'",
+		EnvVars: []string{"TRIVY_REQUIRE_DIGEST"},
+	}
+
+	attestFlag = cli.BoolFlag{
+		Name:    "attest",
+		Usage:   "sign the scan result as an in-toto attestation of the image for cosign, keyless unless '--attest-key' is specified",
+		EnvVars: []string{"TRIVY_ATTEST"},
+	}
+
+	attestKeyFlag = cli.StringFlag{
+		Name:    "attest-key",
+		Usage:   "path to the private key signing the attestation, e.g. generated by 'cosign generate-key-pair' with the password in $COSIGN_PASSWORD",
+		EnvVars: []string{"TRIVY_ATTEST_KEY"},
+	}
+
+	attestOutputFlag = cli.StringFlag{
+		Name:    "attest-output",
+		Usage:   "path to write the signed attestation as a DSSE envelope",
+		EnvVars: []string{"TRIVY_ATTEST_OUTPUT"},
+	}
+
+	attestUploadFlag = cli.BoolFlag{
+		Name:    "attest-upload",
+		Usage:   "attach the attestation to the image in the registry as 'cosign attest' does",
+		EnvVars: []string{"TRIVY_ATTEST_UPLOAD"},
+	}
+
+	sbomSourcesFlag = cli.StringFlag{
+		Name:    "sbom-sources",
+		Usage:   "comma-separated list of where to look up the SBOM of the image to scan instead of analyzing the layers (oci,rekor)",
+		EnvVars: []string{"TRIVY_SBOM_SOURCES"},
+	}
+
+	sbomAttestationKeyFlag = cli.StringFlag{
+		Name:    "sbom-attestation-key",
+		Usage:   "path to the public key verifying the SBOM attestations, e.g. generated by 'cosign generate-key-pair'",
+		EnvVars: []string{"TRIVY_SBOM_ATTESTATION_KEY"},
+	}
+
+	rekorURLFlag = cli.StringFlag{
+		Name:    "rekor-url",
+		Value:   rekor.DefaultURL,
+		Usage:   "URL of the Rekor transparency log to look up the SBOM attestations with '--sbom-sources rekor'",
+		EnvVars: []string{"TRIVY_REKOR_URL"},
+	}
+
 	vulnTypeFlag = cli.StringFlag{
-		Name:    "vuln-type",
+		Name:    "pkg-types",
+		Aliases: []string{"vuln-type"},
 		Value:   strings.Join([]string{types.VulnTypeOS, types.VulnTypeLibrary}, ","),
-		Usage:   "comma-separated list of vulnerability types (os,library)",
-		EnvVars: []string{"TRIVY_VULN_TYPE"},
+		Usage:   "comma-separated list of package types to scan (os,library), or languages instead of library (e.g. lang:python,lang:node)",
+		EnvVars: []string{"TRIVY_PKG_TYPES", "TRIVY_VULN_TYPE"},
 	}
 
 	securityChecksFlag = cli.StringFlag{
 		Name:    "security-checks",
 		Value:   fmt.Sprintf("%s,%s", types.SecurityCheckVulnerability, types.SecurityCheckSecret),
-		Usage:   "comma-separated list of what security issues to detect (vuln,config,secret)",
+		Usage:   "comma-separated list of what security issues to detect (vuln,config,secret,license)",
 		EnvVars: []string{"TRIVY_SECURITY_CHECKS"},
 	}
 
@@ -161,19 +320,82 @@ var (
 		EnvVars: []string{"TRIVY_CACHE_DIR"},
 	}
 
+	moduleDirFlag = cli.StringFlag{
+		Name:    "module-dir",
+		Value:   module.DefaultDir(),
+		Usage:   "directory of WASM modules",
+		EnvVars: []string{"TRIVY_MODULE_DIR"},
+	}
+
+	caBundleFlag = cli.StringSliceFlag{
+		Name:    "ca-bundle",
+		Usage:   "CA certificate files or directories trusted by all outbound connections, e.g. of a corporate proxy",
+		EnvVars: []string{"TRIVY_CA_BUNDLE"},
+	}
+
+	includeMountsFlag = cli.BoolFlag{
+		Name:    "include-mounts",
+		Usage:   "scan the volumes and the bind mounts of the container as well",
+		EnvVars: []string{"TRIVY_INCLUDE_MOUNTS"},
+	}
+
+	dockerHostFlag = cli.StringFlag{
+		Name:    "docker-host",
+		Usage:   "Docker daemon to read the images from instead of DOCKER_HOST, e.g. tcp://build-host:2376",
+		EnvVars: []string{"TRIVY_DOCKER_HOST"},
+	}
+
+	dockerCertPathFlag = cli.StringFlag{
+		Name:    "docker-cert-path",
+		Usage:   "directory with ca.pem, cert.pem and key.pem to connect to the Docker daemon with TLS",
+		EnvVars: []string{"TRIVY_DOCKER_CERT_PATH"},
+	}
+
+	contentStoreFlag = cli.StringFlag{
+		Name:    "content-store",
+		Value:   buildkit.DefaultContentStore,
+		Usage:   "content store of BuildKit where the built images are stored",
+		EnvVars: []string{"TRIVY_CONTENT_STORE"},
+	}
+
 	cacheBackendFlag = cli.StringFlag{
 		Name:    "cache-backend",
 		Value:   "fs",
-		Usage:   "cache backend (e.g. redis://localhost:6379)",
+		Usage:   "cache backend (e.g. redis://localhost:6379 or dynamodb://table-name, prefixed with fs+ for the local cache in front of them)",
 		EnvVars: []string{"TRIVY_CACHE_BACKEND"},
 	}
 
 	cacheTTL = cli.DurationFlag{
 		Name:    "cache-ttl",
-		Usage:   "cache TTL when using redis as cache backend",
+		Usage:   "cache TTL when using redis or dynamodb as cache backend",
 		EnvVars: []string{"TRIVY_CACHE_TTL"},
 	}
 
+	cacheEncryptionKeyFlag = cli.StringFlag{
+		Name:    "cache-encryption-key",
+		Usage:   "base64-encoded AES key of 16, 24 or 32 bytes to encrypt the values in redis or dynamodb cache, or the reference to it, e.g. awskms://<ciphertext>",
+		EnvVars: []string{"TRIVY_CACHE_ENCRYPTION_KEY"},
+	}
+
+	cacheCompressionFlag = cli.StringFlag{
+		Name:    "cache-compression",
+		Usage:   "compression of the values in redis or dynamodb cache (none, gzip, zstd), none for redis and gzip for dynamodb by default",
+		EnvVars: []string{"TRIVY_CACHE_COMPRESSION"},
+	}
+
+	cacheDedupFlag = cli.BoolFlag{
+		Name:    "cache-dedup",
+		Usage:   "store the same analysis result of a layer once in redis cache, referred from the blobs of the different analyzer versions and options",
+		EnvVars: []string{"TRIVY_CACHE_DEDUP"},
+	}
+
+	redisBatchSize = cli.IntFlag{
+		Name:    "redis-batch-size",
+		Usage:   "number of commands pipelined to redis at a time when using redis as cache backend",
+		Value:   cache.DefaultRedisBatchSize,
+		EnvVars: []string{"TRIVY_REDIS_BATCH_SIZE"},
+	}
+
 	redisBackendCACert = cli.StringFlag{
 		Name:    "redis-ca",
 		Usage:   "redis ca file location, if using redis as cache backend",
@@ -198,7 +420,7 @@ var (
 	ignoreFileFlag = cli.StringFlag{
 		Name:    "ignorefile",
 		Value:   result.DefaultIgnoreFile,
-		Usage:   "specify .trivyignore file",
+		Usage:   "specify .trivyignore or .trivyignore.yaml file",
 		EnvVars: []string{"TRIVY_IGNOREFILE"},
 	}
 
@@ -209,6 +431,12 @@ var (
 		EnvVars: []string{"TRIVY_TIMEOUT"},
 	}
 
+	scanBudgetFlag = cli.DurationFlag{
+		Name:    "scan-budget",
+		Usage:   "total time for scanning, after which the remaining targets are reported as not scanned (0 means no limit)",
+		EnvVars: []string{"TRIVY_SCAN_BUDGET"},
+	}
+
 	namespaceFlag = cli.StringFlag{
 		Name:    "namespace",
 		Aliases: []string{"n"},
@@ -217,10 +445,74 @@ var (
 		EnvVars: []string{"TRIVY_K8S_NAMESPACE"},
 	}
 
+	nodeCollectorFlag = cli.BoolFlag{
+		Name:    "node-collector",
+		Usage:   "launch a job on each node to scan the OS packages of the nodes and the kubelet",
+		EnvVars: []string{"TRIVY_NODE_COLLECTOR"},
+	}
+
+	nodeCollectorImageFlag = cli.StringFlag{
+		Name:    "node-collector-image",
+		Value:   "alpine:3.16",
+		Usage:   "image of the node collector jobs, which needs sh, tar and base64",
+		EnvVars: []string{"TRIVY_NODE_COLLECTOR_IMAGE"},
+	}
+
+	nodeCollectorNamespaceFlag = cli.StringFlag{
+		Name:    "node-collector-namespace",
+		Value:   "default",
+		Usage:   "namespace to launch the node collector jobs in",
+		EnvVars: []string{"TRIVY_NODE_COLLECTOR_NAMESPACE"},
+	}
+
 	reportFlag = cli.StringFlag{
-		Name:  "report",
-		Value: "all",
-		Usage: "specify a report format for the output. (all,summary default: all)",
+		Name:    "report",
+		Value:   "all",
+		Usage:   "specify a report format for the output. (all,summary default: all)",
+		EnvVars: []string{"TRIVY_REPORT"},
+	}
+
+	vexOutputFlag = cli.StringFlag{
+		Name:    "vex-output",
+		Usage:   "write the OpenVEX document of the vulnerabilities ignored by the ignore file and policy to the file",
+		EnvVars: []string{"TRIVY_VEX_OUTPUT"},
+	}
+
+	vexAuthorFlag = cli.StringFlag{
+		Name:    "vex-author",
+		Usage:   "author of the OpenVEX document",
+		EnvVars: []string{"TRIVY_VEX_AUTHOR"},
+	}
+
+	remoteFlag = cli.StringFlag{
+		Name:    "remote",
+		Usage:   "scan the filesystem of the remote host over SSH instead of a local path, e.g. ssh://user@host:22/path",
+		EnvVars: []string{"TRIVY_REMOTE"},
+	}
+
+	sshKeyFlag = cli.StringFlag{
+		Name:    "ssh-key",
+		Usage:   "private key to authenticate to the remote host, ssh-agent and ~/.ssh/id_{ed25519,ecdsa,rsa} by default",
+		EnvVars: []string{"TRIVY_SSH_KEY"},
+	}
+
+	sshKnownHostsFlag = cli.StringFlag{
+		Name:    "ssh-known-hosts",
+		Usage:   "known_hosts file to verify the host key of the remote host, ~/.ssh/known_hosts by default",
+		EnvVars: []string{"TRIVY_SSH_KNOWN_HOSTS"},
+	}
+
+	awsRegionFlag = cli.StringSliceFlag{
+		Name:    "region",
+		Usage:   "AWS regions to scan, the region of the AWS config by default",
+		EnvVars: []string{"TRIVY_AWS_REGION"},
+	}
+
+	awsServiceFlag = cli.StringSliceFlag{
+		Name:    "service",
+		Value:   cli.NewStringSlice("ecr", "lambda", "ec2"),
+		Usage:   "AWS services whose resources are scanned (ecr,lambda,ec2)",
+		EnvVars: []string{"TRIVY_AWS_SERVICE"},
 	}
 
 	// TODO: remove this flag after a sufficient deprecation period.
@@ -245,34 +537,192 @@ var (
 
 	ignorePolicy = cli.StringFlag{
 		Name:    "ignore-policy",
-		Usage:   "specify the Rego file to evaluate each vulnerability",
+		Usage:   "specify the Rego file to evaluate each vulnerability and misconfiguration",
 		EnvVars: []string{"TRIVY_IGNORE_POLICY"},
 	}
 
+	gateFlag = cli.StringFlag{
+		Name:    "gate",
+		Aliases: []string{"output-policy"},
+		Usage:   "specify the Rego (.rego) or CEL (.cel) file to decide whether the report passes, overriding '--exit-on-severity' and '--exit-code-fixed-only'",
+		EnvVars: []string{"TRIVY_GATE", "TRIVY_OUTPUT_POLICY"},
+	}
+
+	complianceFlag = cli.StringFlag{
+		Name:    "compliance",
+		Usage:   "report the status of the controls of the compliance spec instead of the findings (docker-cis, k8s-cis, k8s-nsa or a path to the YAML file)",
+		EnvVars: []string{"TRIVY_COMPLIANCE"},
+	}
+
+	slaFlag = cli.StringFlag{
+		Name:    "sla",
+		Usage:   "specify the YAML file of the periods to fix vulnerabilities per severity, e.g. 'CRITICAL: 7d', to report the due dates",
+		EnvVars: []string{"TRIVY_SLA"},
+	}
+
+	onlyOverdueFlag = cli.BoolFlag{
+		Name:    "only-overdue",
+		Usage:   "display only the vulnerabilities past the due dates of '--sla'",
+		EnvVars: []string{"TRIVY_ONLY_OVERDUE"},
+	}
+
+	ignorePublishedBeforeFlag = cli.StringFlag{
+		Name:    "ignore-published-before",
+		Usage:   "ignore the vulnerabilities published before the date (e.g. 2015-01-01)",
+		EnvVars: []string{"TRIVY_IGNORE_PUBLISHED_BEFORE"},
+	}
+
+	ignoreUnfixedSinceFlag = cli.StringFlag{
+		Name:    "ignore-unfixed-since",
+		Usage:   "ignore the unfixed vulnerabilities published longer ago than the period (e.g. 90d)",
+		EnvVars: []string{"TRIVY_IGNORE_UNFIXED_SINCE"},
+	}
+
+	historyDirFlag = cli.StringFlag{
+		Name:    "history-dir",
+		Usage:   "directory to record when findings are first and last seen across scans, for 'trivy metrics'",
+		EnvVars: []string{"TRIVY_HISTORY_DIR"},
+	}
+
+	storeFlag = cli.StringFlag{
+		Name:    "store",
+		Usage:   "object storage to write the JSON report and the SBOM after the scan (e.g. s3://bucket/prefix, gs://bucket/prefix, azblob://container/prefix)",
+		EnvVars: []string{"TRIVY_STORE"},
+	}
+
+	webhookURLFlag = cli.StringFlag{
+		Name:    "webhook-url",
+		Usage:   "webhook to post the summary of findings to when the scan completes, e.g. Slack incoming webhook",
+		EnvVars: []string{"TRIVY_WEBHOOK_URL"},
+	}
+
+	webhookAttachReportFlag = cli.BoolFlag{
+		Name:    "webhook-attach-report",
+		Usage:   "include the full JSON report in the webhook payload",
+		EnvVars: []string{"TRIVY_WEBHOOK_ATTACH_REPORT"},
+	}
+
 	listAllPackages = cli.BoolFlag{
 		Name:    "list-all-pkgs",
 		Usage:   "enabling the option will output all packages regardless of vulnerability",
 		EnvVars: []string{"TRIVY_LIST_ALL_PKGS"},
 	}
 
+	sbomHashesFlag = cli.StringSliceFlag{
+		Name:    "sbom-hashes",
+		Usage:   "algorithms of the hashes of the package files included in the SBOMs, e.g. JAR files and wheels (md5,sha1,sha256,sha512)",
+		EnvVars: []string{"TRIVY_SBOM_HASHES"},
+	}
+
+	dependencyTreeFlag = cli.BoolFlag{
+		Name:    "dependency-tree",
+		Usage:   "show the chains of the direct dependencies pulling in the vulnerable packages, for lock files with the dependency graph",
+		EnvVars: []string{"TRIVY_DEPENDENCY_TREE"},
+	}
+
+	excludeDevDepsFlag = cli.BoolFlag{
+		Name:    "exclude-dev-deps",
+		Usage:   "exclude the development dependencies, for npm, yarn, pnpm, Composer and Bundler projects telling them",
+		EnvVars: []string{"TRIVY_EXCLUDE_DEV_DEPS"},
+	}
+
+	detectUnpinnedFlag = cli.BoolFlag{
+		Name:    "detect-unpinned",
+		Usage:   "detect vulnerabilities in the version ranges of requirements.txt and package.json without lock files, reported as unpinned",
+		EnvVars: []string{"TRIVY_DETECT_UNPINNED"},
+	}
+
+	deterministicFlag = cli.BoolFlag{
+		Name:    "deterministic",
+		Usage:   "write the same report for the same artifact and DB, sorting the findings stably and omitting the timestamps and the fields depending on the environment",
+		EnvVars: []string{"TRIVY_DETERMINISTIC"},
+	}
+
+	groupByFlag = cli.StringFlag{
+		Name:    "group-by",
+		Usage:   "collapse the identical findings in the table (vulnerability), listing the affected packages in a row per vulnerability and fixed version",
+		EnvVars: []string{"TRIVY_GROUP_BY"},
+	}
+
 	skipFiles = cli.StringSliceFlag{
 		Name:    "skip-files",
-		Usage:   "specify the file paths to skip traversal",
+		Usage:   "specify the file paths to skip traversal, which can be globs (e.g. **/*.min.js)",
 		EnvVars: []string{"TRIVY_SKIP_FILES"},
 	}
 
 	skipDirs = cli.StringSliceFlag{
 		Name:    "skip-dirs",
-		Usage:   "specify the directories where the traversal is skipped",
+		Usage:   "specify the directories where the traversal is skipped, which can be globs (e.g. **/node_modules)",
 		EnvVars: []string{"TRIVY_SKIP_DIRS"},
 	}
 
 	offlineScan = cli.BoolFlag{
 		Name:    "offline-scan",
-		Usage:   "do not issue API requests to identify dependencies",
+		Usage:   "scan without any network access, using the downloaded DB and skipping the API requests to identify dependencies",
 		EnvVars: []string{"TRIVY_OFFLINE_SCAN"},
 	}
 
+	parallelFlag = cli.IntFlag{
+		Name:    "parallel",
+		Usage:   "number of layers and files analyzed in parallel, 0 to use the number of CPUs",
+		Value:   tartifact.DefaultParallel,
+		EnvVars: []string{"TRIVY_PARALLEL"},
+	}
+
+	maxMemoryFlag = cli.StringFlag{
+		Name:    "max-memory",
+		Usage:   "total size of the files in layers kept in memory during the analysis, the rest are spilled to temp files (e.g. 512MiB)",
+		EnvVars: []string{"TRIVY_MAX_MEMORY"},
+	}
+
+	maxArchiveDepthFlag = cli.IntFlag{
+		Name:    "max-archive-depth",
+		Usage:   "how deep the nested zip and wheel archives are unpacked to find packages, 0 to disable",
+		EnvVars: []string{"TRIVY_MAX_ARCHIVE_DEPTH"},
+	}
+
+	fileTimeoutFlag = cli.DurationFlag{
+		Name:    "file-timeout",
+		Usage:   "timeout of analyzing each file, the files exceeding it are skipped and reported as not scanned, 0 to disable",
+		EnvVars: []string{"TRIVY_FILE_TIMEOUT"},
+	}
+
+	analyzerTimeoutFlag = cli.StringSliceFlag{
+		Name:    "analyzer-timeout",
+		Usage:   "timeouts of the analyzers analyzing each file, e.g. secret=10s, the results of the analyzer are skipped and the file is reported as not scanned",
+		EnvVars: []string{"TRIVY_ANALYZER_TIMEOUT"},
+	}
+
+	analysisTimeoutFlag = cli.DurationFlag{
+		Name:    "analysis-timeout",
+		Usage:   "timeout of the analysis phase of each scan within '--timeout', 0 to disable",
+		EnvVars: []string{"TRIVY_ANALYSIS_TIMEOUT"},
+	}
+
+	detectionTimeoutFlag = cli.DurationFlag{
+		Name:    "detection-timeout",
+		Usage:   "timeout of the detection phase of each scan within '--timeout', 0 to disable",
+		EnvVars: []string{"TRIVY_DETECTION_TIMEOUT"},
+	}
+
+	maxImageSizeFlag = cli.StringFlag{
+		Name:    "max-image-size",
+		Usage:   "refuse the images larger than the size before pulling the layers, e.g. 10GiB",
+		EnvVars: []string{"TRIVY_MAX_IMAGE_SIZE"},
+	}
+
+	maxFilesFlag = cli.IntFlag{
+		Name:    "max-files",
+		Usage:   "abort the scan of the artifacts with more files than the number, 0 to disable",
+		EnvVars: []string{"TRIVY_MAX_FILES"},
+	}
+
+	workdirFlag = cli.StringFlag{
+		Name:    "workdir",
+		Usage:   "directory where images are saved and unpacked during the scan (default: system temporary directory)",
+		EnvVars: []string{"TRIVY_WORKDIR"},
+	}
+
 	// For misconfigurations
 	configPolicy = cli.StringSliceFlag{
 		Name:    "config-policy",
@@ -302,7 +752,7 @@ var (
 
 	filePatterns = cli.StringSliceFlag{
 		Name:    "file-patterns",
-		Usage:   "specify file patterns",
+		Usage:   "specify file patterns as analyzer:regex to analyze files with nonstandard names (e.g. pip:requirements-.*\\.txt)",
 		EnvVars: []string{"TRIVY_FILE_PATTERNS"},
 	}
 
@@ -337,16 +787,29 @@ var (
 
 	remoteServer = cli.StringFlag{
 		Name:    "server",
-		Usage:   "server address",
+		Usage:   "server address, e.g. http://localhost:4954 or unix:///var/run/trivy.sock",
 		EnvVars: []string{"TRIVY_SERVER"},
 	}
 
 	customHeaders = cli.StringSliceFlag{
 		Name:    "custom-headers",
-		Usage:   "custom headers in client/server mode",
+		Usage:   "custom headers in client/server mode, e.g. x-api-key=XXX",
 		EnvVars: []string{"TRIVY_CUSTOM_HEADERS"},
 	}
 
+	clientIDFlag = cli.StringFlag{
+		Name:    "client-id",
+		Usage:   "identity of the client sent to the server, e.g. the name of the pipeline",
+		EnvVars: []string{"TRIVY_CLIENT_ID"},
+	}
+
+	localeFlag = cli.StringFlag{
+		Name:    "locale",
+		Value:   "en",
+		Usage:   "language of the titles and the descriptions of the vulnerabilities (en, ja), falling back to English when the advisory sources don't provide them",
+		EnvVars: []string{"TRIVY_LOCALE"},
+	}
+
 	dbRepositoryFlag = cli.StringFlag{
 		Name:    "db-repository",
 		Usage:   "OCI repository to retrieve trivy-db from",
@@ -354,6 +817,93 @@ var (
 		EnvVars: []string{"TRIVY_DB_REPOSITORY"},
 	}
 
+	osvFlag = cli.BoolFlag{
+		Name:    "osv",
+		Usage:   "query OSV.dev for the vulnerabilities of the ecosystems not covered by trivy-db, e.g. those found by modules",
+		EnvVars: []string{"TRIVY_OSV"},
+	}
+
+	osvURLFlag = cli.StringFlag{
+		Name:    "osv-url",
+		Usage:   "URL of the OSV API, e.g. a mirror",
+		Value:   osv.DefaultURL,
+		EnvVars: []string{"TRIVY_OSV_URL"},
+	}
+
+	registryCAFlag = cli.StringSliceFlag{
+		Name:    "registry-ca",
+		Usage:   "CA certificate files or directories to verify container registries",
+		EnvVars: []string{"TRIVY_REGISTRY_CA"},
+	}
+
+	serverCAFlag = cli.StringSliceFlag{
+		Name:    "server-ca",
+		Usage:   "CA certificate files or directories to verify the server in client/server mode",
+		EnvVars: []string{"TRIVY_SERVER_CA"},
+	}
+
+	clientCertFlag = cli.StringFlag{
+		Name:    "client-cert",
+		Usage:   "client certificate file to authenticate to the server in client/server mode",
+		EnvVars: []string{"TRIVY_CLIENT_CERT"},
+	}
+
+	clientKeyFlag = cli.StringFlag{
+		Name:    "client-key",
+		Usage:   "client key file to authenticate to the server in client/server mode",
+		EnvVars: []string{"TRIVY_CLIENT_KEY"},
+	}
+
+	serverTimeoutFlag = cli.DurationFlag{
+		Name:    "server-timeout",
+		Usage:   "timeout of each request to the server in client/server mode, and 0 means no timeout",
+		EnvVars: []string{"TRIVY_SERVER_TIMEOUT"},
+	}
+
+	serverRetriesFlag = cli.IntFlag{
+		Name:    "server-retries",
+		Usage:   "maximum number of retries with exponential backoff when the server is unavailable in client/server mode",
+		Value:   rpc.DefaultRetries,
+		EnvVars: []string{"TRIVY_SERVER_RETRIES"},
+	}
+
+	serverProtocolFlag = cli.StringFlag{
+		Name:    "server-protocol",
+		Usage:   "protocol to the server in client/server mode (twirp, grpc), where grpc requires the server with '--server-protocol grpc'",
+		Value:   rpc.ProtocolTwirp,
+		EnvVars: []string{"TRIVY_SERVER_PROTOCOL"},
+	}
+
+	fallbackToLocalFlag = cli.BoolFlag{
+		Name:    "fallback-to-local",
+		Usage:   "download the DB and scan locally when the server is unreachable in client/server mode",
+		EnvVars: []string{"TRIVY_FALLBACK_TO_LOCAL"},
+	}
+
+	pullViaServerFlag = cli.BoolFlag{
+		Name:    "pull-via-server",
+		Usage:   "pull the image through the server when the registry is not reachable in client/server mode",
+		EnvVars: []string{"TRIVY_PULL_VIA_SERVER"},
+	}
+
+	serverSideAnalysisFlag = cli.BoolFlag{
+		Name:    "server-side-analysis",
+		Usage:   "upload the layers to the server and analyze them on the server in client/server mode",
+		EnvVars: []string{"TRIVY_SERVER_SIDE_ANALYSIS"},
+	}
+
+	serverSidePullFlag = cli.BoolFlag{
+		Name:    "server-side-pull",
+		Usage:   "let the server pull and analyze the image when the registry is not reachable in client/server mode",
+		EnvVars: []string{"TRIVY_SERVER_SIDE_PULL"},
+	}
+
+	dbCAFlag = cli.StringSliceFlag{
+		Name:    "db-ca",
+		Usage:   "CA certificate files or directories to verify the DB repository",
+		EnvVars: []string{"TRIVY_DB_CA"},
+	}
+
 	secretConfig = cli.StringFlag{
 		Name:    "secret-config",
 		Usage:   "specify a path to config file for secret scanning",
@@ -361,11 +911,23 @@ var (
 		EnvVars: []string{"TRIVY_SECRET_CONFIG"},
 	}
 
+	licenseConfig = cli.StringFlag{
+		Name:    "license-config",
+		Usage:   "specify a path to the license policy mapping licenses to categories and severities",
+		Value:   "trivy-license.yaml",
+		EnvVars: []string{"TRIVY_LICENSE_CONFIG"},
+	}
+
 	// Global flags
 	globalFlags = []cli.Flag{
 		&quietFlag,
 		&debugFlag,
+		&logFormatFlag,
+		&logFileFlag,
 		&cacheDirFlag,
+		&configFileFlag,
+		&moduleDirFlag,
+		&caBundleFlag,
 	}
 )
 
@@ -385,7 +947,7 @@ func NewApp(version string) *cli.App {
 
 	if runAsPlugin := os.Getenv("TRIVY_RUN_AS_PLUGIN"); runAsPlugin != "" {
 		app.Action = func(ctx *cli.Context) error {
-			return plugin.RunWithArgs(ctx.Context, runAsPlugin, ctx.Args().Slice())
+			return plugin.RunWithArgs(ctx, runAsPlugin, ctx.Args().Slice())
 		}
 		app.HideVersion = true
 		app.HideHelp = true
@@ -407,44 +969,134 @@ func NewApp(version string) *cli.App {
 		NewConfigCommand(),
 		NewPluginCommand(),
 		NewK8sCommand(),
+		NewAWSCommand(),
 		NewSbomCommand(),
+		NewBuildkitCommand(),
+		NewContainerCommand(),
+		NewDiffCommand(),
+		NewConvertCommand(),
+		NewMetricsCommand(),
+		NewTemplateCommand(),
+		NewCacheCommand(),
+		NewCompletionCommand(),
 		NewVersionCommand(),
 	}
+	// The plugin commands are not affected as plugins parse their own arguments
+	app.Before = func(c *cli.Context) error {
+		if err := loadConfigFile(c); err != nil {
+			return err
+		}
+		// The log format and file can be given in the config file as well
+		if err := log.SetOutput(c.String(logFormatFlag.Name), c.String(logFileFlag.Name)); err != nil {
+			return xerrors.Errorf("log error: %w", err)
+		}
+		if err := log.InitLogger(c.Bool(debugFlag.Name), c.Bool(quietFlag.Name)); err != nil {
+			return xerrors.Errorf("failed to initialize a logger: %w", err)
+		}
+		// The CA bundle can be given in the config file as well
+		if err := utils.SetCABundle(c.StringSlice(caBundleFlag.Name)); err != nil {
+			return xerrors.Errorf("CA bundle error: %w", err)
+		}
+		return nil
+	}
+	bindEnvVars(app.Flags)
+	for _, cmd := range app.Commands {
+		withEnvVars(cmd)
+		withConfigFile(cmd)
+	}
 	app.Commands = append(app.Commands, plugin.LoadCommands()...)
 
 	return app
 }
 
+// withConfigFile applies the config file to the command and its subcommands
+func withConfigFile(cmd *cli.Command) {
+	before := cmd.Before
+	cmd.Before = func(c *cli.Context) error {
+		if err := applyConfigFile(c); err != nil {
+			return err
+		}
+		if before != nil {
+			return before(c)
+		}
+		return nil
+	}
+	for _, sub := range cmd.Subcommands {
+		withConfigFile(sub)
+	}
+}
+
 func showVersion(cacheDir, outputFormat, version string, outputWriter io.Writer) {
-	var dbMeta *metadata.Metadata
+	writeVersion(localVersionInfo(cacheDir, version), outputFormat, outputWriter)
+}
+
+// localVersionInfo returns the version of the CLI and the vulnerability DB in the cache dir
+func localVersionInfo(cacheDir, version string) VersionInfo {
+	info := VersionInfo{Version: version}
 
 	mc := metadata.NewClient(cacheDir)
 	meta, _ := mc.Get() // nolint: errcheck
 	if !meta.UpdatedAt.IsZero() && !meta.NextUpdate.IsZero() && meta.Version != 0 {
-		dbMeta = &metadata.Metadata{
-			Version:      meta.Version,
-			NextUpdate:   meta.NextUpdate.UTC(),
-			UpdatedAt:    meta.UpdatedAt.UTC(),
-			DownloadedAt: meta.DownloadedAt.UTC(),
+		info.VulnerabilityDB = &VulnerabilityDBInfo{
+			Metadata: metadata.Metadata{
+				Version:      meta.Version,
+				NextUpdate:   meta.NextUpdate.UTC(),
+				UpdatedAt:    meta.UpdatedAt.UTC(),
+				DownloadedAt: meta.DownloadedAt.UTC(),
+			},
+			Stale: db.IsStale(meta, time.Now()),
+		}
+	}
+	return info
+}
+
+// remoteVersionInfo returns the version of the server and the vulnerability DB served to clients
+func remoteVersionInfo(c *cli.Context) (VersionInfo, error) {
+	opt := option.NewRemoteOption(c)
+	if err := opt.Init(log.Logger); err != nil {
+		return VersionInfo{}, xerrors.Errorf("remote option error: %w", err)
+	}
+	scannerOption := client.ScannerOption{
+		RemoteURL:    opt.RemoteAddr,
+		Insecure:     c.Bool("insecure"),
+		RootCAs:      opt.ServerRootCAs,
+		Certificates: opt.ClientCertificates,
+		Timeout:      opt.ServerTimeout,
+		Protocol:     opt.ServerProtocol,
+	}
+
+	res, err := client.NewVersionClient(scannerOption).GetVersion(client.WithCustomHeaders(c.Context, opt.CustomHeaders), &emptypb.Empty{})
+	if err != nil {
+		return VersionInfo{}, xerrors.Errorf("failed to get the version via RPC: %w", err)
+	}
+
+	info := VersionInfo{Version: res.Version}
+	if meta := rpc.ConvertFromRPCVulnerabilityDB(res.VulnerabilityDb); meta != nil {
+		info.VulnerabilityDB = &VulnerabilityDBInfo{
+			Metadata: *meta,
+			Stale:    res.VulnerabilityDb.Stale,
 		}
 	}
+	return info, nil
+}
 
+func writeVersion(info VersionInfo, outputFormat string, outputWriter io.Writer) {
 	switch outputFormat {
 	case "json":
-		b, _ := json.Marshal(VersionInfo{ // nolint: errcheck
-			Version:         version,
-			VulnerabilityDB: dbMeta,
-		})
+		b, _ := json.Marshal(info) // nolint: errcheck
 		fmt.Fprintln(outputWriter, string(b))
 	default:
-		output := fmt.Sprintf("Version: %s\n", version)
-		if dbMeta != nil {
+		output := fmt.Sprintf("Version: %s\n", info.Version)
+		if dbMeta := info.VulnerabilityDB; dbMeta != nil {
 			output += fmt.Sprintf(`Vulnerability DB:
   Version: %d
   UpdatedAt: %s
   NextUpdate: %s
   DownloadedAt: %s
 `, dbMeta.Version, dbMeta.UpdatedAt.UTC(), dbMeta.NextUpdate.UTC(), dbMeta.DownloadedAt.UTC())
+			if dbMeta.Stale {
+				output += "  Stale: true\n"
+			}
 		}
 		fmt.Fprintf(outputWriter, output)
 	}
@@ -460,42 +1112,324 @@ func NewImageCommand() *cli.Command {
 		Action:    artifact.ImageRun,
 		Flags: []cli.Flag{
 			&templateFlag,
+			&schemaFlag,
 			&formatFlag,
 			&inputFlag,
 			&severityFlag,
 			&outputFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
 			&skipDBUpdateFlag,
 			&downloadDBOnlyFlag,
 			&resetFlag,
 			&clearCacheFlag,
 			&noProgressFlag,
+			&progressFlag,
 			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
 			&removedPkgsFlag,
+			&dockerHostFlag,
+			&dockerCertPathFlag,
+			&esmFlag,
+			&rebuildOfFlag,
+			&requireDigestFlag,
+			&attestFlag,
+			&attestKeyFlag,
+			&attestOutputFlag,
+			&attestUploadFlag,
+			&sbomSourcesFlag,
+			&sbomAttestationKeyFlag,
+			&rekorURLFlag,
 			&vulnTypeFlag,
 			&securityChecksFlag,
 			&ignoreFileFlag,
 			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&maxImageSizeFlag,
+			&maxMemoryFlag,
+			&scanBudgetFlag,
 			&lightFlag,
 			&ignorePolicy,
+			&gateFlag,
+			&complianceFlag,
 			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&dependencyTreeFlag,
+			&excludeDevDepsFlag,
 			&cacheBackendFlag,
 			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
 			&redisBackendCACert,
 			&redisBackendCert,
 			&redisBackendKey,
 			&offlineScan,
+			&workdirFlag,
+			&inputListFlag,
+			&outputDirFlag,
+			&scanOrderFlag,
+			stringSliceFlag(priorityLabelFlag),
 			&insecureFlag,
+			stringSliceFlag(registryCAFlag),
+			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
+			&secretConfig,
+			&licenseConfig,
+			stringSliceFlag(skipFiles),
+			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
+
+			// for client/server
+			&remoteServer,
+			&token,
+			&tokenHeader,
+			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&serverTimeoutFlag,
+			&serverRetriesFlag,
+			&serverProtocolFlag,
+			&fallbackToLocalFlag,
+			&pullViaServerFlag,
+			&serverSideAnalysisFlag,
+			&serverSidePullFlag,
+		},
+	}
+}
+
+// NewBuildkitCommand is the factory method to add buildkit command
+func NewBuildkitCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "buildkit",
+		ArgsUsage:   "METADATA_FILE|DIGEST",
+		Usage:       "scan an image in the BuildKit content store right after the build",
+		Description: `METADATA_FILE is the file written by "docker buildx build --metadata-file". The image is scanned before it is exported or pushed.`,
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - fail the build before pushing the image:
+      $ docker buildx build --metadata-file metadata.json -t myapp:1.0 .
+      $ trivy buildkit --exit-code 1 --severity CRITICAL metadata.json
+      $ docker buildx build --push -t myapp:1.0 .
+
+  - scan the image by the digest:
+      $ trivy buildkit --content-store /var/lib/containerd/io.containerd.content.v1.content sha256:4c0f...
+
+`,
+		Action: artifact.BuildkitRun,
+		Flags: []cli.Flag{
+			&templateFlag,
+			&schemaFlag,
+			&formatFlag,
+			&severityFlag,
+			&outputFlag,
+			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
+			&skipDBUpdateFlag,
+			&clearCacheFlag,
+			&noProgressFlag,
+			&progressFlag,
+			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
+			&removedPkgsFlag,
+			&esmFlag,
+			&rebuildOfFlag,
+			&vulnTypeFlag,
+			&securityChecksFlag,
+			&ignoreFileFlag,
+			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&maxMemoryFlag,
+			&lightFlag,
+			&ignorePolicy,
+			&gateFlag,
+			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&dependencyTreeFlag,
+			&excludeDevDepsFlag,
+			&cacheBackendFlag,
+			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
+			&redisBackendCACert,
+			&redisBackendCert,
+			&redisBackendKey,
+			&offlineScan,
+			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
+			&secretConfig,
+			&licenseConfig,
+			stringSliceFlag(skipFiles),
+			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
+			&contentStoreFlag,
+
+			// for client/server
+			&remoteServer,
+			&token,
+			&tokenHeader,
+			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&serverTimeoutFlag,
+			&serverRetriesFlag,
+			&serverProtocolFlag,
+			&fallbackToLocalFlag,
+		},
+	}
+}
+
+// NewContainerCommand is the factory method to add container command
+func NewContainerCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "container",
+		ArgsUsage:   "CONTAINER",
+		Usage:       "scan a running container in the Docker daemon",
+		Description: `CONTAINER is the ID or the name of the container. The writable layer of the container is scanned on top of its image, so that the packages installed at runtime are detected.`,
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - scan the container:
+      $ trivy container 4c3b2a1f
+
+  - scan the volumes and the bind mounts as well:
+      $ trivy container --include-mounts myapp
+
+`,
+		Action: artifact.ContainerRun,
+		Flags: []cli.Flag{
+			&templateFlag,
+			&schemaFlag,
+			&formatFlag,
+			&severityFlag,
+			&outputFlag,
+			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
+			&skipDBUpdateFlag,
+			&clearCacheFlag,
+			&noProgressFlag,
+			&progressFlag,
+			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
+			&removedPkgsFlag,
+			&dockerHostFlag,
+			&dockerCertPathFlag,
+			&esmFlag,
+			&vulnTypeFlag,
+			&securityChecksFlag,
+			&ignoreFileFlag,
+			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&maxMemoryFlag,
+			&lightFlag,
+			&ignorePolicy,
+			&gateFlag,
+			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&dependencyTreeFlag,
+			&excludeDevDepsFlag,
+			&cacheBackendFlag,
+			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
+			&redisBackendCACert,
+			&redisBackendCert,
+			&redisBackendKey,
+			&offlineScan,
 			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
 			&secretConfig,
+			&licenseConfig,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
+			&includeMountsFlag,
 
 			// for client/server
 			&remoteServer,
 			&token,
 			&tokenHeader,
 			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&serverTimeoutFlag,
+			&serverRetriesFlag,
+			&serverProtocolFlag,
+			&fallbackToLocalFlag,
 		},
 	}
 }
@@ -510,31 +1444,81 @@ func NewFilesystemCommand() *cli.Command {
 		Action:    artifact.FilesystemRun,
 		Flags: []cli.Flag{
 			&templateFlag,
+			&schemaFlag,
 			&formatFlag,
 			&severityFlag,
 			&outputFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
 			&skipDBUpdateFlag,
 			&skipPolicyUpdateFlag,
 			&clearCacheFlag,
 			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
+			&esmFlag,
 			&vulnTypeFlag,
 			&securityChecksFlag,
 			&ignoreFileFlag,
 			&cacheBackendFlag,
 			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
 			&redisBackendCACert,
 			&redisBackendCert,
 			&redisBackendKey,
 			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&scanBudgetFlag,
 			&noProgressFlag,
+			&progressFlag,
 			&ignorePolicy,
+			&gateFlag,
+			&complianceFlag,
 			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&dependencyTreeFlag,
+			&excludeDevDepsFlag,
+			&detectUnpinnedFlag,
 			&offlineScan,
+			&workdirFlag,
+			&remoteFlag,
+			&sshKeyFlag,
+			&sshKnownHostsFlag,
+			&inputListFlag,
+			&outputDirFlag,
+			&scanOrderFlag,
+			stringSliceFlag(priorityLabelFlag),
 			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
 			&secretConfig,
+			&licenseConfig,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
 
 			// for misconfiguration
 			stringSliceFlag(configPolicy),
@@ -546,6 +1530,14 @@ func NewFilesystemCommand() *cli.Command {
 			&token,
 			&tokenHeader,
 			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&serverTimeoutFlag,
+			&serverRetriesFlag,
+			&serverProtocolFlag,
+			&fallbackToLocalFlag,
 		},
 	}
 }
@@ -559,31 +1551,80 @@ func NewRootfsCommand() *cli.Command {
 		Action:    artifact.RootfsRun,
 		Flags: []cli.Flag{
 			&templateFlag,
+			&schemaFlag,
 			&formatFlag,
 			&severityFlag,
 			&outputFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
 			&skipDBUpdateFlag,
 			&skipPolicyUpdateFlag,
 			&clearCacheFlag,
 			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
+			&esmFlag,
 			&vulnTypeFlag,
 			&securityChecksFlag,
 			&ignoreFileFlag,
 			&cacheBackendFlag,
 			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
 			&redisBackendCACert,
 			&redisBackendCert,
 			&redisBackendKey,
 			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&scanBudgetFlag,
 			&noProgressFlag,
+			&progressFlag,
 			&ignorePolicy,
+			&gateFlag,
+			&complianceFlag,
 			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&dependencyTreeFlag,
+			&excludeDevDepsFlag,
 			&offlineScan,
+			&workdirFlag,
+			&remoteFlag,
+			&sshKeyFlag,
+			&sshKnownHostsFlag,
+			&inputListFlag,
+			&outputDirFlag,
+			&scanOrderFlag,
+			stringSliceFlag(priorityLabelFlag),
 			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
 			&secretConfig,
+			&licenseConfig,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
 			stringSliceFlag(configPolicy),
 			stringSliceFlag(configData),
 			stringSliceFlag(policyNamespaces),
@@ -601,35 +1642,81 @@ func NewRepositoryCommand() *cli.Command {
 		Action:    artifact.RepositoryRun,
 		Flags: []cli.Flag{
 			&templateFlag,
+			&schemaFlag,
 			&formatFlag,
 			&inputFlag,
 			&severityFlag,
 			&outputFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
 			&skipDBUpdateFlag,
 			&skipPolicyUpdateFlag,
 			&clearCacheFlag,
 			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
 			&removedPkgsFlag,
+			&esmFlag,
 			&vulnTypeFlag,
 			&securityChecksFlag,
 			&ignoreFileFlag,
 			&cacheBackendFlag,
 			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
 			&redisBackendCACert,
 			&redisBackendCert,
 			&redisBackendKey,
 			&timeoutFlag,
+			&scanBudgetFlag,
 			&noProgressFlag,
+			&progressFlag,
 			&quietFlag,
 			&ignorePolicy,
+			&gateFlag,
+			&complianceFlag,
 			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&dependencyTreeFlag,
+			&excludeDevDepsFlag,
+			&detectUnpinnedFlag,
 			&offlineScan,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&workdirFlag,
+			&inputListFlag,
+			&outputDirFlag,
+			&scanOrderFlag,
+			stringSliceFlag(priorityLabelFlag),
 			&insecureFlag,
 			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
 			&secretConfig,
+			&licenseConfig,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
 		},
 	}
 }
@@ -648,37 +1735,81 @@ func NewClientCommand() *cli.Command {
 		Hidden: true, // It is no longer displayed
 		Flags: []cli.Flag{
 			&templateFlag,
+			&schemaFlag,
 			&formatFlag,
 			&inputFlag,
 			&severityFlag,
 			&outputFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
 			&clearCacheFlag,
 			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
 			&removedPkgsFlag,
+			&dockerHostFlag,
+			&dockerCertPathFlag,
+			&esmFlag,
 			&vulnTypeFlag,
 			&securityChecksFlag,
 			&ignoreFileFlag,
 			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxFilesFlag,
+			&maxImageSizeFlag,
+			&maxMemoryFlag,
 			&noProgressFlag,
+			&progressFlag,
 			&ignorePolicy,
+			&gateFlag,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
 			stringSliceFlag(configPolicy),
 			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
 			&offlineScan,
+			&workdirFlag,
 			&insecureFlag,
+			stringSliceFlag(registryCAFlag),
 			&secretConfig,
+			&licenseConfig,
 
 			&token,
 			&tokenHeader,
 			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&serverTimeoutFlag,
+			&serverRetriesFlag,
+			&serverProtocolFlag,
+			&fallbackToLocalFlag,
+			&serverSidePullFlag,
 
 			// original flags
 			&cli.StringFlag{
 				Name:    "remote",
 				Value:   "http://localhost:4954",
-				Usage:   "server address",
+				Usage:   "server address, e.g. http://localhost:4954 or unix:///var/run/trivy.sock",
 				EnvVars: []string{"TRIVY_REMOTE"},
 			},
 		},
@@ -698,10 +1829,18 @@ func NewServerCommand() *cli.Command {
 			&resetFlag,
 			&cacheBackendFlag,
 			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
 			&redisBackendCACert,
 			&redisBackendCert,
 			&redisBackendKey,
 			&dbRepositoryFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
+			&insecureFlag,
+			stringSliceFlag(registryCAFlag),
 
 			// original flags
 			&token,
@@ -709,9 +1848,123 @@ func NewServerCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:    "listen",
 				Value:   "localhost:4954",
-				Usage:   "listen address",
+				Usage:   "listen address, or the path of the unix domain socket, e.g. unix:///var/run/trivy.sock",
 				EnvVars: []string{"TRIVY_LISTEN"},
 			},
+			&cli.StringFlag{
+				Name:    "tls-cert",
+				Usage:   "TLS certificate file to serve HTTPS",
+				EnvVars: []string{"TRIVY_TLS_CERT"},
+			},
+			&cli.StringFlag{
+				Name:    "tls-key",
+				Usage:   "TLS key file to serve HTTPS",
+				EnvVars: []string{"TRIVY_TLS_KEY"},
+			},
+			&cli.DurationFlag{
+				Name:    "tls-reload-interval",
+				Usage:   "interval to reload the TLS certificate and key when they are modified (e.g. 1m), 0 disables reloading",
+				EnvVars: []string{"TRIVY_TLS_RELOAD_INTERVAL"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "client-ca",
+				Usage:   "CA certificate files or directories to require and verify client certificates",
+				EnvVars: []string{"TRIVY_CLIENT_CA"},
+			},
+			&cli.StringFlag{
+				Name:    "jwt-issuer",
+				Usage:   "issuer of JWTs to authenticate clients, e.g. https://token.actions.githubusercontent.com",
+				EnvVars: []string{"TRIVY_JWT_ISSUER"},
+			},
+			&cli.StringFlag{
+				Name:    "jwt-audience",
+				Usage:   "audience which JWTs must be issued to",
+				EnvVars: []string{"TRIVY_JWT_AUDIENCE"},
+			},
+			&cli.StringFlag{
+				Name:    "jwt-jwks-url",
+				Usage:   "URL of the JWKS to verify JWTs, discovered from the issuer with OpenID Connect if not specified",
+				EnvVars: []string{"TRIVY_JWT_JWKS_URL"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "jwt-subject",
+				Usage:   "subjects of JWTs which are allowed, wildcards are allowed (e.g. repo:myorg/*)",
+				EnvVars: []string{"TRIVY_JWT_SUBJECT"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "auth-headers",
+				Usage:   "headers required to authenticate clients, e.g. set by the API gateway (x-gateway-key=XXX), and * accepts any value",
+				EnvVars: []string{"TRIVY_AUTH_HEADERS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "proxy-registries",
+				Usage:   "registries which clients can pull images from through the server, wildcards are allowed (e.g. ghcr.io,*.gcr.io)",
+				EnvVars: []string{"TRIVY_PROXY_REGISTRIES"},
+			},
+			&cli.IntFlag{
+				Name:    "max-concurrent-scans",
+				Usage:   "maximum number of scans processed at the same time, and 0 means unlimited",
+				EnvVars: []string{"TRIVY_MAX_CONCURRENT_SCANS"},
+			},
+			&cli.Float64Flag{
+				Name:    "rate-limit",
+				Usage:   "maximum number of requests per second per client IP, or per user over the unix domain socket, and 0 means unlimited",
+				EnvVars: []string{"TRIVY_RATE_LIMIT"},
+			},
+			&cli.IntFlag{
+				Name:    "rate-limit-burst",
+				Usage:   "number of requests allowed in a burst per client, and 0 means the rate limit rounded up",
+				EnvVars: []string{"TRIVY_RATE_LIMIT_BURST"},
+			},
+			&cli.StringFlag{
+				Name:    "max-layer-size",
+				Usage:   "maximum size of the layers uploaded by clients before and after the decompression, and 0 means unlimited",
+				Value:   "10GiB",
+				EnvVars: []string{"TRIVY_MAX_LAYER_SIZE"},
+			},
+			&cli.DurationFlag{
+				Name:    "result-cache-ttl",
+				Usage:   "how long the results of the same scans are reused, and 0 disables the result cache",
+				EnvVars: []string{"TRIVY_RESULT_CACHE_TTL"},
+			},
+			&cli.IntFlag{
+				Name:    "result-cache-size",
+				Usage:   "maximum number of scan results kept in memory",
+				Value:   1000,
+				EnvVars: []string{"TRIVY_RESULT_CACHE_SIZE"},
+			},
+			&cli.StringFlag{
+				Name:    "audit-log",
+				Usage:   "file to write the audit log of the requests as JSON lines, and '-' means stdout",
+				EnvVars: []string{"TRIVY_AUDIT_LOG"},
+			},
+			&cli.StringFlag{
+				Name:    "webhook-url",
+				Usage:   "webhook to post the summary of findings to when scans requested by clients complete",
+				EnvVars: []string{"TRIVY_WEBHOOK_URL"},
+			},
+			&webhookAttachReportFlag,
+			&cli.StringFlag{
+				Name:    "result-store",
+				Usage:   "database to persist the summaries of scans in and query the trends from (e.g. sqlite:///var/lib/trivy/results.db)",
+				EnvVars: []string{"TRIVY_RESULT_STORE"},
+			},
+			&cli.DurationFlag{
+				Name:    "rescan-interval",
+				Usage:   "interval to re-scan the images in --watch-file and post new findings to the webhook, and 0 disables re-scans",
+				EnvVars: []string{"TRIVY_RESCAN_INTERVAL"},
+			},
+			&cli.StringFlag{
+				Name:    "watch-file",
+				Usage:   "YAML file listing the images to re-scan at --rescan-interval",
+				EnvVars: []string{"TRIVY_WATCH_FILE"},
+			},
+			&cli.StringFlag{
+				Name:    "server-protocol",
+				Usage:   "protocol to serve (twirp, grpc), where grpc serves the services also over gRPC on the same address",
+				Value:   rpc.ProtocolTwirp,
+				EnvVars: []string{"TRIVY_SERVER_PROTOCOL"},
+			},
 		},
 	}
 }
@@ -726,15 +1979,25 @@ func NewConfigCommand() *cli.Command {
 		Action:    artifact.ConfigRun,
 		Flags: []cli.Flag{
 			&templateFlag,
+			&schemaFlag,
 			&formatFlag,
 			&severityFlag,
 			&outputFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
 			&skipPolicyUpdateFlag,
 			&resetFlag,
 			&clearCacheFlag,
 			&ignoreFileFlag,
+			&ignorePolicy,
+			&gateFlag,
+			&complianceFlag,
+			&historyDirFlag,
+			&storeFlag,
+			&webhookURLFlag,
+			&webhookAttachReportFlag,
 			&timeoutFlag,
+			&parallelFlag,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
 			stringSliceFlag(configPolicyAlias),
@@ -818,36 +2081,62 @@ func NewK8sCommand() *cli.Command {
 
   - resource scanning:
       $ trivy k8s deployment/orion
+
+  - cluster scanning including the nodes:
+      $ trivy k8s --node-collector --report summary
 `,
 		Action: k8s.Run,
 		Flags: []cli.Flag{
 			&namespaceFlag,
 			&reportFlag,
+			&nodeCollectorFlag,
+			&nodeCollectorImageFlag,
+			&nodeCollectorNamespaceFlag,
+			&complianceFlag,
 			&formatFlag,
 			&outputFlag,
 			&severityFlag,
 			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
 			&skipDBUpdateFlag,
 			&skipPolicyUpdateFlag,
 			&clearCacheFlag,
 			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&esmFlag,
 			&vulnTypeFlag,
 			&k8sSecurityChecksFlag,
 			&ignoreFileFlag,
 			&cacheBackendFlag,
 			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
 			&redisBackendCACert,
 			&redisBackendCert,
 			&redisBackendKey,
 			&timeoutFlag,
 			&noProgressFlag,
+			&progressFlag,
 			&ignorePolicy,
 			&listAllPackages,
 			&offlineScan,
+			&workdirFlag,
+			stringSliceFlag(registryCAFlag),
 			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
 			&secretConfig,
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
 
 			// for misconfiguration
 			stringSliceFlag(configPolicy),
@@ -857,13 +2146,81 @@ func NewK8sCommand() *cli.Command {
 	}
 }
 
+// NewAWSCommand is the factory method to add aws command
+func NewAWSCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "aws",
+		Usage: "scan the resources of an AWS account",
+		Description: `The ECR images, the Lambda layers and the AMIs of the running EC2 instances are enumerated in the regions, ` +
+			`with the credentials of the AWS config or the environment variables.`,
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - account scanning in the region of the AWS config:
+      $ trivy aws
+
+  - region scanning:
+      $ trivy aws --region us-east-1 --region eu-west-1
+
+  - service scanning:
+      $ trivy aws --service ecr
+`,
+		Action: aws.Run,
+		Flags: []cli.Flag{
+			&awsRegionFlag,
+			&awsServiceFlag,
+			&templateFlag,
+			&formatFlag,
+			&outputFlag,
+			&severityFlag,
+			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
+			&skipDBUpdateFlag,
+			&clearCacheFlag,
+			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&esmFlag,
+			&vulnTypeFlag,
+			&securityChecksFlag,
+			&ignoreFileFlag,
+			&cacheBackendFlag,
+			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
+			&redisBackendCACert,
+			&redisBackendCert,
+			&redisBackendKey,
+			&timeoutFlag,
+			&noProgressFlag,
+			&progressFlag,
+			&ignorePolicy,
+			&listAllPackages,
+			&offlineScan,
+			&workdirFlag,
+			stringSliceFlag(registryCAFlag),
+			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			stringSliceFlag(dbCAFlag),
+			&secretConfig,
+			&licenseConfig,
+			stringSliceFlag(skipFiles),
+			stringSliceFlag(skipDirs),
+		},
+	}
+}
+
 // NewSbomCommand is the factory method to add sbom command
 func NewSbomCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "sbom",
 		ArgsUsage:   "ARTIFACT",
 		Usage:       "generate SBOM for an artifact",
-		Description: `ARTIFACT can be a container image, file path/directory, git repository or container image archive. See examples.`,
+		Description: `ARTIFACT can be a container image, file path/directory, git repository, container image archive or CycloneDX SBOM. See examples.`,
 		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
   - image scanning:
       $ trivy sbom alpine:3.15
@@ -877,6 +2234,9 @@ func NewSbomCommand() *cli.Command {
   - image archive scanning:
       $ trivy sbom --artifact-type archive ./alpine.tar
 
+  - enrich CycloneDX SBOM with vulnerabilities:
+      $ trivy sbom --artifact-type cyclonedx --output enriched.json ./bom.json
+
 `,
 		Action: artifact.SbomRun,
 		Flags: []cli.Flag{
@@ -886,16 +2246,26 @@ func NewSbomCommand() *cli.Command {
 			&timeoutFlag,
 			&severityFlag,
 			&offlineScan,
+			&workdirFlag,
 			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			&localeFlag,
+			stringSliceFlag(dbCAFlag),
+			&rebuildOfFlag,
+			&requireDigestFlag,
+			stringSliceFlag(registryCAFlag),
 			stringSliceFlag(skipFiles),
 			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
+			stringSliceFlag(sbomHashesFlag),
 
 			// dedicated options
 			&cli.StringFlag{
 				Name:    "artifact-type",
 				Aliases: []string{"type"},
 				Value:   "image",
-				Usage:   "input artifact type (image, fs, repo, archive)",
+				Usage:   "input artifact type (image, fs, repo, archive, cyclonedx)",
 				EnvVars: []string{"TRIVY_ARTIFACT_TYPE"},
 			},
 			&cli.StringFlag{
@@ -909,17 +2279,312 @@ func NewSbomCommand() *cli.Command {
 	}
 }
 
+// NewDiffCommand is the factory method to add diff command
+func NewDiffCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "diff",
+		ArgsUsage:   "BASE TARGET",
+		Usage:       "compare two scan reports or images",
+		Description: `BASE and TARGET can be JSON reports generated with '--format json' or container images. See examples.`,
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - report comparison:
+      $ trivy diff base.json pr.json
+
+  - image comparison:
+      $ trivy diff myapp:1.0 myapp:1.1
+
+  - fail only when new vulnerabilities are introduced:
+      $ trivy diff --exit-code 1 base.json pr.json
+
+`,
+		Action: artifact.DiffRun,
+		Flags: []cli.Flag{
+			&diffFormatFlag,
+			&severityFlag,
+			&outputFlag,
+			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
+			&skipDBUpdateFlag,
+			&clearCacheFlag,
+			&noProgressFlag,
+			&progressFlag,
+			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&removedPkgsFlag,
+			&dockerHostFlag,
+			&dockerCertPathFlag,
+			&esmFlag,
+			&vulnTypeFlag,
+			&securityChecksFlag,
+			&ignoreFileFlag,
+			&timeoutFlag,
+			&parallelFlag,
+			&maxArchiveDepthFlag,
+			&fileTimeoutFlag,
+			stringSliceFlag(analyzerTimeoutFlag),
+			&analysisTimeoutFlag,
+			&detectionTimeoutFlag,
+			&maxMemoryFlag,
+			&ignorePolicy,
+			&cacheBackendFlag,
+			&cacheTTL,
+			&cacheEncryptionKeyFlag,
+			&cacheCompressionFlag,
+			&cacheDedupFlag,
+			&redisBatchSize,
+			&redisBackendCACert,
+			&redisBackendCert,
+			&redisBackendKey,
+			&offlineScan,
+			&workdirFlag,
+			&insecureFlag,
+			stringSliceFlag(registryCAFlag),
+			&dbRepositoryFlag,
+			&osvFlag,
+			&osvURLFlag,
+			stringSliceFlag(dbCAFlag),
+			&secretConfig,
+			&licenseConfig,
+			stringSliceFlag(skipFiles),
+			stringSliceFlag(skipDirs),
+			stringSliceFlag(filePatterns),
+
+			// for client/server
+			&remoteServer,
+			&token,
+			&tokenHeader,
+			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&serverTimeoutFlag,
+			&serverRetriesFlag,
+			&serverProtocolFlag,
+		},
+	}
+}
+
+// NewConvertCommand is the factory method to add convert command
+func NewConvertCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "convert",
+		ArgsUsage:   "REPORT",
+		Usage:       "convert a JSON report into another format",
+		Description: `REPORT is a JSON report generated with '--format json', which is converted without scanning again. See examples.`,
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - report in SARIF:
+      $ trivy image --format json --output result.json alpine:3.15
+      $ trivy convert --format sarif --output result.sarif result.json
+
+  - CycloneDX SBOM, which needs the packages recorded with '--list-all-pkgs':
+      $ trivy image --format json --list-all-pkgs --output result.json alpine:3.15
+      $ trivy convert --format cyclonedx result.json
+
+  - only critical vulnerabilities with fixes:
+      $ trivy convert --severity CRITICAL --ignore-unfixed result.json
+
+`,
+		Action: artifact.ConvertRun,
+		Flags: []cli.Flag{
+			&templateFlag,
+			&schemaFlag,
+			&formatFlag,
+			&severityFlag,
+			&outputFlag,
+			&exitCodeFlag,
+			&exitOnSeverityFlag,
+			&exitCodeFixedOnlyFlag,
+			&ignoreUnfixedFlag,
+			&slaFlag,
+			&onlyOverdueFlag,
+			&ignorePublishedBeforeFlag,
+			&ignoreUnfixedSinceFlag,
+			&ignoreFileFlag,
+			&ignorePolicy,
+			&gateFlag,
+			&complianceFlag,
+			&licenseConfig,
+			&listAllPackages,
+			stringSliceFlag(sbomHashesFlag),
+			&groupByFlag,
+			&reportFlag,
+			&deterministicFlag,
+			&vexOutputFlag,
+			&vexAuthorFlag,
+			&includeNonFailures,
+		},
+	}
+}
+
+// NewMetricsCommand is the factory method to add metrics command
+func NewMetricsCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "metrics",
+		ArgsUsage:   "[TARGET...]",
+		Usage:       "show the remediation statistics of the findings recorded with '--history-dir'",
+		Description: `The statistics of all the targets are shown unless TARGETs are given. See examples.`,
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - statistics of all the targets:
+      $ trivy metrics --history-dir ./history
+
+  - statistics of an image as JSON:
+      $ trivy metrics --history-dir ./history --format json myapp:1.0
+
+`,
+		Action: artifact.MetricsRun,
+		Flags: []cli.Flag{
+			&historyDirFlag,
+			&diffFormatFlag,
+			&severityFlag,
+			&outputFlag,
+		},
+	}
+}
+
+// NewTemplateCommand is the factory method to add template command
+func NewTemplateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "template",
+		Usage: "manage custom templates",
+		Subcommands: cli.Commands{
+			{
+				Name:        "validate",
+				Usage:       "render a custom template against a sample report and show the template errors",
+				ArgsUsage:   "TEMPLATE_FILE",
+				Description: `The bundled sample report is used unless '--sample' is given. See examples.`,
+				CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - validation against the bundled sample report:
+      $ trivy template validate my.tpl
+
+  - validation against your report and saving the rendered output:
+      $ trivy image --format json --output report.json alpine:3.15
+      $ trivy template validate --sample report.json --output out.html my.tpl
+
+`,
+				Action: artifact.TemplateValidateRun,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "sample",
+						Usage:   "JSON report generated with '--format json' to render the template against",
+						EnvVars: []string{"TRIVY_TEMPLATE_SAMPLE"},
+					},
+					&outputFlag,
+				},
+			},
+		},
+	}
+}
+
+// NewCacheCommand is the factory method to add cache command
+func NewCacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "manage the analysis cache",
+		Subcommands: cli.Commands{
+			{
+				Name:  "warm",
+				Usage: "analyze images in advance so that the scans of the images built on them analyze only the top layers",
+				Description: `The analysis results of the images in '--images-file' are stored in the cache without detecting vulnerabilities.
+Give the options changing the analysis, e.g. '--security-checks', the same as the later scans. See examples.`,
+				CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - base images in the local cache:
+      $ cat bases.txt
+      ubuntu:22.04
+      debian:11
+      $ trivy cache warm --images-file bases.txt
+
+  - base images in the cache of the server:
+      $ trivy cache warm --images-file bases.txt --server http://localhost:4954
+
+`,
+				Action: artifact.CacheWarmRun,
+				Flags: []cli.Flag{
+					&imagesFileFlag,
+					&noProgressFlag,
+					&removedPkgsFlag,
+					&dockerHostFlag,
+					&dockerCertPathFlag,
+					&vulnTypeFlag,
+					&securityChecksFlag,
+					&listAllPackages,
+					&timeoutFlag,
+					&parallelFlag,
+					&maxArchiveDepthFlag,
+					&fileTimeoutFlag,
+					stringSliceFlag(analyzerTimeoutFlag),
+					&maxMemoryFlag,
+					&cacheBackendFlag,
+					&cacheTTL,
+					&cacheEncryptionKeyFlag,
+					&cacheCompressionFlag,
+					&cacheDedupFlag,
+					&redisBatchSize,
+					&redisBackendCACert,
+					&redisBackendCert,
+					&redisBackendKey,
+					&offlineScan,
+					&insecureFlag,
+					stringSliceFlag(registryCAFlag),
+					&secretConfig,
+					stringSliceFlag(skipFiles),
+					stringSliceFlag(skipDirs),
+					stringSliceFlag(filePatterns),
+
+					// for client/server
+					&remoteServer,
+					&token,
+					&tokenHeader,
+					&customHeaders,
+					&clientIDFlag,
+					stringSliceFlag(serverCAFlag),
+					&clientCertFlag,
+					&clientKeyFlag,
+					&serverTimeoutFlag,
+					&serverRetriesFlag,
+					&serverProtocolFlag,
+				},
+			},
+		},
+	}
+}
+
 // NewVersionCommand adds version command
 func NewVersionCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "version",
 		Usage: "print the version",
 		Action: func(ctx *cli.Context) error {
-			showVersion(ctx.String("cache-dir"), ctx.String("format"), ctx.App.Version, ctx.App.Writer)
+			if ctx.String("server") == "" {
+				showVersion(ctx.String("cache-dir"), ctx.String("format"), ctx.App.Version, ctx.App.Writer)
+				return nil
+			}
+			info, err := remoteVersionInfo(ctx)
+			if err != nil {
+				return err
+			}
+			writeVersion(info, ctx.String("format"), ctx.App.Writer)
 			return nil
 		},
 		Flags: []cli.Flag{
 			&formatFlag,
+
+			// The version of the server and the DB served to clients
+			&remoteServer,
+			&token,
+			&tokenHeader,
+			&customHeaders,
+			&clientIDFlag,
+			stringSliceFlag(serverCAFlag),
+			&clientCertFlag,
+			&clientKeyFlag,
+			&insecureFlag,
+			&serverTimeoutFlag,
+			&serverProtocolFlag,
 		},
 	}
 }
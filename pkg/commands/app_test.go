@@ -2,10 +2,17 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
 )
 
 func Test_showVersion(t *testing.T) {
@@ -32,6 +39,7 @@ Vulnerability DB:
   UpdatedAt: 2022-03-02 06:07:07.99504083 +0000 UTC
   NextUpdate: 2022-03-02 12:07:07.99504023 +0000 UTC
   DownloadedAt: 2022-03-02 10:03:38.383312 +0000 UTC
+  Stale: true
 `,
 		},
 		{
@@ -62,8 +70,9 @@ Vulnerability DB:
   UpdatedAt: 2022-03-02 06:07:07.99504083 +0000 UTC
   NextUpdate: 2022-03-02 12:07:07.99504023 +0000 UTC
   DownloadedAt: 2022-03-02 10:03:38.383312 +0000 UTC
+  Stale: true
 `
-	jsonOutput := `{"Version":"test","VulnerabilityDB":{"Version":2,"NextUpdate":"2022-03-02T12:07:07.99504023Z","UpdatedAt":"2022-03-02T06:07:07.99504083Z","DownloadedAt":"2022-03-02T10:03:38.383312Z"}}
+	jsonOutput := `{"Version":"test","VulnerabilityDB":{"Version":2,"NextUpdate":"2022-03-02T12:07:07.99504023Z","UpdatedAt":"2022-03-02T06:07:07.99504083Z","DownloadedAt":"2022-03-02T10:03:38.383312Z","Stale":true}}
 `
 
 	tests := []struct {
@@ -121,6 +130,40 @@ Vulnerability DB:
 	}
 }
 
+type fakeVersionServer struct {
+	res *rpcVersion.VersionResponse
+}
+
+func (s fakeVersionServer) GetVersion(context.Context, *emptypb.Empty) (*rpcVersion.VersionResponse, error) {
+	return s.res, nil
+}
+
+func TestPrintVersion_server(t *testing.T) {
+	ts := httptest.NewServer(rpcVersion.NewVersionServer(fakeVersionServer{
+		res: &rpcVersion.VersionResponse{
+			Version: "0.30.0",
+			VulnerabilityDb: &rpcVersion.VulnerabilityDB{
+				Version:      2,
+				UpdatedAt:    timestamppb.New(time.Date(2022, 3, 2, 6, 0, 0, 0, time.UTC)),
+				NextUpdate:   timestamppb.New(time.Date(2022, 3, 2, 12, 0, 0, 0, time.UTC)),
+				DownloadedAt: timestamppb.New(time.Date(2022, 3, 2, 7, 0, 0, 0, time.UTC)),
+				Stale:        true,
+			},
+		},
+	}))
+	defer ts.Close()
+
+	got := new(bytes.Buffer)
+	app := NewApp("test")
+	app.Writer = got
+
+	err := app.Run([]string{"trivy", "--cache-dir", "testdata", "version", "--format", "json", "--server", ts.URL})
+	require.NoError(t, err)
+	want := `{"Version":"0.30.0","VulnerabilityDB":{"Version":2,"NextUpdate":"2022-03-02T12:00:00Z","UpdatedAt":"2022-03-02T06:00:00Z","DownloadedAt":"2022-03-02T07:00:00Z","Stale":true}}
+`
+	assert.Equal(t, want, got.String())
+}
+
 func TestNewCommands(t *testing.T) {
 	NewApp("test")
 	NewClientCommand()
@@ -0,0 +1,108 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/attestation"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/report/predicate"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// initAttest validates the combination of the attestation options before scanning
+func (c *Option) initAttest() error {
+	if !c.Attest {
+		if c.AttestKey != "" || c.AttestOutput != "" || c.AttestUpload {
+			c.Logger.Warn("'--attest-key', '--attest-output' and '--attest-upload' are ignored because '--attest' is not specified")
+		}
+		return nil
+	}
+
+	switch {
+	case c.InputList != "":
+		return xerrors.New("'--attest' can't be used with '--input-list'")
+	case c.Input != "":
+		return xerrors.New("'--attest' can't be used with '--input' as the attestation is bound to the digest of the image in the registry")
+	case c.AttestKey == "" && c.AttestOutput != "":
+		return xerrors.New("'--attest-output' can be used only with '--attest-key' as cosign attaches the keyless attestations to the image")
+	case c.AttestKey == "" && !c.AttestUpload:
+		return xerrors.New("keyless attestations are attached to the image by cosign, specify '--attest-upload' or '--attest-key'")
+	case c.AttestOutput == "" && !c.AttestUpload:
+		return xerrors.New("specify '--attest-output' or '--attest-upload' to save the attestation")
+	}
+	return nil
+}
+
+// attest signs the report as the in-toto attestation of the image, so that the admission can verify the scan of the image
+func attest(ctx context.Context, opt Option, report types.Report, startedOn time.Time) error {
+	if report.Metadata.ImageDigest == "" {
+		return xerrors.Errorf("%s has no digest in the registry, so the attestation can't be bound to it", report.ArtifactName)
+	}
+	image, err := name.NewDigest(report.Metadata.ImageDigest)
+	if err != nil {
+		return xerrors.Errorf("invalid image digest: %w", err)
+	}
+
+	p := predicate.NewVuln(report, opt.AppVersion, startedOn, time.Now())
+
+	// e.g. in CI where the OIDC identity is available
+	if opt.AttestSigner == nil {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return xerrors.Errorf("failed to marshal the predicate: %w", err)
+		}
+		log.Logger.Infof("Signing the attestation of %s with cosign...", image.Name())
+		return attestation.AttestKeyless(ctx, image.Name(), predicate.VulnPredicateType, b)
+	}
+
+	statement, err := attestation.NewImageStatement(image, predicate.VulnPredicateType, p)
+	if err != nil {
+		return xerrors.Errorf("statement error: %w", err)
+	}
+	envelope, err := attestation.Sign(statement, opt.AttestSigner)
+	if err != nil {
+		return xerrors.Errorf("unable to sign the attestation: %w", err)
+	}
+
+	if opt.AttestOutput != "" {
+		b, err := json.Marshal(envelope)
+		if err != nil {
+			return xerrors.Errorf("failed to marshal the envelope: %w", err)
+		}
+		if err = os.WriteFile(opt.AttestOutput, b, 0644); err != nil {
+			return xerrors.Errorf("unable to write the attestation: %w", err)
+		}
+	}
+
+	if opt.AttestUpload {
+		log.Logger.Infof("Attaching the attestation to %s...", image.Name())
+		if err = attestation.Attach(ctx, image, envelope, predicate.VulnPredicateType, registryAuth()); err != nil {
+			return xerrors.Errorf("unable to attach the attestation: %w", err)
+		}
+	}
+	return nil
+}
+
+// registryAuth returns the credentials of the registry in the same way as pulling images,
+// i.e. TRIVY_USERNAME, TRIVY_PASSWORD and TRIVY_REGISTRY_TOKEN, or the Docker config.
+func registryAuth() remote.Option {
+	dockerOpt, err := types.GetDockerOption()
+	if err != nil {
+		log.Logger.Debugf("Unable to get the registry credentials: %s", err)
+	}
+	switch {
+	case dockerOpt.UserName != "" && dockerOpt.Password != "":
+		return remote.WithAuth(&authn.Basic{Username: dockerOpt.UserName, Password: dockerOpt.Password})
+	case dockerOpt.RegistryToken != "":
+		return remote.WithAuth(&authn.Bearer{Token: dockerOpt.RegistryToken})
+	}
+	return remote.WithAuthFromKeychain(authn.DefaultKeychain)
+}
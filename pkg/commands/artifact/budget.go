@@ -0,0 +1,35 @@
+package artifact
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// notScannedBudget is the reason why the targets were not scanned after the scan budget was exhausted
+const notScannedBudget = "scan budget exhausted"
+
+// withScanBudget returns the context cancelled when the scan budget is exhausted.
+// A zero budget means no limit.
+func withScanBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, budget)
+}
+
+// budgetExhausted returns whether the scan budget of the context is exhausted
+func budgetExhausted(ctx context.Context) bool {
+	return xerrors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// notScannedResult returns the explicit entry for the target which was not scanned within the budget
+func notScannedResult(target string) types.Result {
+	return types.Result{
+		Target:     target,
+		NotScanned: notScannedBudget,
+	}
+}
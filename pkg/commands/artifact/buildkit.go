@@ -0,0 +1,40 @@
+package artifact
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/scanner"
+)
+
+// buildkitStandaloneScanner initializes a scanner of the image in the BuildKit content store in standalone mode
+// $ trivy buildkit metadata.json
+func buildkitStandaloneScanner(img ftypes.Image) InitializeScanner {
+	return func(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+		s, err := initializeImageScanner(ctx, img, conf.ArtifactCache, conf.LocalArtifactCache, conf.ArtifactOption)
+		if err != nil {
+			return scanner.Scanner{}, func() {}, xerrors.Errorf("unable to initialize the BuildKit scanner: %w", err)
+		}
+		return s, func() {}, nil
+	}
+}
+
+// buildkitRemoteScanner initializes a scanner of the image in the BuildKit content store in client/server mode
+// $ trivy buildkit --server localhost:4954 metadata.json
+func buildkitRemoteScanner(img ftypes.Image) InitializeScanner {
+	return func(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+		s, err := initializeRemoteImageScanner(ctx, img, conf.ArtifactCache, conf.RemoteOption, conf.ArtifactOption)
+		if err != nil {
+			return scanner.Scanner{}, nil, xerrors.Errorf("unable to initialize the BuildKit scanner: %w", err)
+		}
+		return s, func() {}, nil
+	}
+}
+
+// BuildkitRun scans an image in the BuildKit content store right after the build
+func BuildkitRun(ctx *cli.Context) error {
+	return Run(ctx, buildkitArtifact)
+}
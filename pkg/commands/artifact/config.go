@@ -2,6 +2,7 @@ package artifact
 
 import (
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/fanal/analyzer"
@@ -16,7 +17,7 @@ func ConfigRun(ctx *cli.Context) error {
 	}
 
 	// Disable OS and language analyzers
-	opt.DisabledAnalyzers = append(analyzer.TypeOSes, analyzer.TypeLanguages...)
+	opt.DisabledAnalyzers = append(slices.Clone(analyzer.TypeOSes), languageAnalyzers()...)
 
 	// Scan only config files
 	opt.VulnType = nil
@@ -0,0 +1,40 @@
+package artifact
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/scanner"
+)
+
+// containerStandaloneScanner initializes a scanner of the running container in standalone mode
+// $ trivy container 4c3b2a1f
+func containerStandaloneScanner(img ftypes.Image) InitializeScanner {
+	return func(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+		s, err := initializeImageScanner(ctx, img, conf.ArtifactCache, conf.LocalArtifactCache, conf.ArtifactOption)
+		if err != nil {
+			return scanner.Scanner{}, func() {}, xerrors.Errorf("unable to initialize the container scanner: %w", err)
+		}
+		return s, func() {}, nil
+	}
+}
+
+// containerRemoteScanner initializes a scanner of the running container in client/server mode
+// $ trivy container --server localhost:4954 4c3b2a1f
+func containerRemoteScanner(img ftypes.Image) InitializeScanner {
+	return func(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+		s, err := initializeRemoteImageScanner(ctx, img, conf.ArtifactCache, conf.RemoteOption, conf.ArtifactOption)
+		if err != nil {
+			return scanner.Scanner{}, nil, xerrors.Errorf("unable to initialize the container scanner: %w", err)
+		}
+		return s, func() {}, nil
+	}
+}
+
+// ContainerRun scans a running container including the packages installed after it started
+func ContainerRun(ctx *cli.Context) error {
+	return Run(ctx, containerArtifact)
+}
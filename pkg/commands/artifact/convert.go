@@ -0,0 +1,81 @@
+package artifact
+
+import (
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/gate"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// ConvertRun re-renders the JSON report in another format with the filtering options applied, without scanning again
+func ConvertRun(cliCtx *cli.Context) error {
+	if cliCtx.Args().Len() != 1 {
+		_ = cli.ShowSubcommandHelp(cliCtx) // nolint: errcheck
+		return xerrors.New("a JSON report must be specified")
+	}
+	path := cliCtx.Args().First()
+
+	opt, err := NewOption(cliCtx)
+	if err != nil {
+		return xerrors.Errorf("option error: %w", err)
+	}
+
+	// Only the report options are used since nothing is scanned
+	if err = opt.ReportOption.Init(cliCtx.App.Writer, opt.Logger); err != nil {
+		return xerrors.Errorf("option initialize error: %w", err)
+	}
+
+	var g gate.Gate
+	if opt.Gate != "" {
+		if g, err = gate.Load(cliCtx.Context, opt.Gate); err != nil {
+			return xerrors.Errorf("gate error: %w", err)
+		}
+	}
+
+	report, err := readReport(path)
+	if err != nil {
+		return xerrors.Errorf("report error: %w", err)
+	}
+
+	// The vulnerability info has been filled in the report, so the DB is not needed
+	report, err = filterReport(cliCtx.Context, opt, report, false)
+	if err != nil {
+		return xerrors.Errorf("filter error: %w", err)
+	}
+	convertPackages(opt, report)
+
+	// The report is written when the scan finishes, which is the closest to when it started
+	fi, err := os.Stat(path)
+	if err != nil {
+		return xerrors.Errorf("file stat error: %w", err)
+	}
+	if err = writeReport(opt, report, fi.ModTime()); err != nil {
+		return xerrors.Errorf("report error: %w", err)
+	}
+
+	failed := report.Results.FailedBy(opt.FailCondition())
+	if g != nil {
+		if failed, err = evaluateGate(cliCtx.Context, g, opt.Gate, report); err != nil {
+			return xerrors.Errorf("gate error: %w", err)
+		}
+	}
+	Exit(opt, failed)
+	return nil
+}
+
+// convertPackages removes the packages from the results unless '--list-all-pkgs' is given, as scans do
+func convertPackages(opt Option, report types.Report) {
+	var found bool
+	for i := range report.Results {
+		found = found || len(report.Results[i].Packages) > 0
+		if !opt.ListAllPkgs {
+			report.Results[i].Packages = nil
+		}
+	}
+	if opt.ListAllPkgs && !found {
+		opt.Logger.Warn("The report has no packages. Generate the report with '--list-all-pkgs' to convert the packages.")
+	}
+}
@@ -0,0 +1,131 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const testReport = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "alpine:3.15",
+  "ArtifactType": "container_image",
+  "Results": [
+    {
+      "Target": "alpine:3.15 (alpine 3.15.4)",
+      "Class": "os-pkgs",
+      "Type": "alpine",
+      "Packages": [
+        {"Name": "busybox", "Version": "1.34.1-r5"},
+        {"Name": "zlib", "Version": "1.2.12-r0"}
+      ],
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2022-37434", "PkgName": "zlib", "InstalledVersion": "1.2.12-r0", "FixedVersion": "1.2.12-r2", "Severity": "CRITICAL"},
+        {"VulnerabilityID": "CVE-2022-28391", "PkgName": "busybox", "InstalledVersion": "1.34.1-r5", "Severity": "HIGH"}
+      ]
+    }
+  ]
+}`
+
+func TestConvertRun(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(reportPath, []byte(testReport), 0600))
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantVulns  []string
+		wantPkgs   int
+		wantErr    string
+		wantFormat string
+	}{
+		{
+			name:      "all the findings",
+			args:      []string{reportPath},
+			wantVulns: []string{"CVE-2022-28391", "CVE-2022-37434"},
+		},
+		{
+			name:      "severity and unfixed",
+			args:      []string{"--severity", "HIGH", "--ignore-unfixed", reportPath},
+			wantVulns: nil,
+		},
+		{
+			name:      "all the packages",
+			args:      []string{"--severity", "CRITICAL", "--list-all-pkgs", reportPath},
+			wantVulns: []string{"CVE-2022-37434"},
+			wantPkgs:  2,
+		},
+		{
+			name:       "SARIF",
+			args:       []string{"--format", "sarif", reportPath},
+			wantFormat: "sarif",
+		},
+		{
+			name:    "no report",
+			args:    []string{filepath.Join(dir, "missing.json")},
+			wantErr: "file open error",
+		},
+		{
+			name:    "no argument",
+			wantErr: "a JSON report must be specified",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "output")
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Bool("quiet", true, "")
+			set.String("format", "json", "")
+			set.String("severity", "UNKNOWN,LOW,MEDIUM,HIGH,CRITICAL", "")
+			set.String("output", output, "")
+			set.String("ignorefile", filepath.Join(dir, ".trivyignore"), "")
+			set.Bool("ignore-unfixed", false, "")
+			set.Bool("list-all-pkgs", false, "")
+			require.NoError(t, set.Parse(tt.args))
+
+			ctx := cli.NewContext(app, set, nil)
+			ctx.Context = context.Background()
+
+			err := ConvertRun(ctx)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			b, err := os.ReadFile(output)
+			require.NoError(t, err)
+
+			if tt.wantFormat == "sarif" {
+				var sarif map[string]interface{}
+				require.NoError(t, json.Unmarshal(b, &sarif))
+				assert.Contains(t, sarif, "runs")
+				return
+			}
+
+			var got types.Report
+			require.NoError(t, json.Unmarshal(b, &got))
+			require.Len(t, got.Results, 1)
+
+			var gotVulns []string
+			for _, v := range got.Results[0].Vulnerabilities {
+				gotVulns = append(gotVulns, v.VulnerabilityID)
+			}
+			assert.Equal(t, tt.wantVulns, gotVulns)
+			assert.Len(t, got.Results[0].Packages, tt.wantPkgs)
+		})
+	}
+}
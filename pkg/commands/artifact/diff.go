@@ -0,0 +1,125 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/diff"
+	"github.com/aquasecurity/trivy/pkg/log"
+	pkgReport "github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// DiffRun compares two JSON reports or container images and shows the differences
+func DiffRun(cliCtx *cli.Context) error {
+	if cliCtx.Args().Len() != 2 {
+		_ = cli.ShowSubcommandHelp(cliCtx) // nolint: errcheck
+		return xerrors.New("two reports or images must be specified")
+	}
+
+	opt, err := NewOption(cliCtx)
+	if err != nil {
+		return xerrors.Errorf("option error: %w", err)
+	}
+
+	// The targets are passed as arguments, so the artifact options are not initialized
+	if err = opt.initPreScanOptions(); err != nil {
+		return xerrors.Errorf("option initialize error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cliCtx.Context, opt.Timeout)
+	defer cancel()
+
+	d := &differ{opt: opt}
+	defer d.close()
+
+	base, err := d.load(ctx, cliCtx.Args().Get(0))
+	if err != nil {
+		return xerrors.Errorf("base error: %w", err)
+	}
+	target, err := d.load(ctx, cliCtx.Args().Get(1))
+	if err != nil {
+		return xerrors.Errorf("target error: %w", err)
+	}
+
+	r := diff.Compare(base, target, opt.Severities)
+	if err = diff.Write(r, diff.Option{
+		Format:     opt.Format,
+		Output:     opt.Output,
+		Severities: opt.Severities,
+	}); err != nil {
+		return xerrors.Errorf("unable to write the differences: %w", err)
+	}
+
+	// Only newly introduced findings fail the build
+	d.close()
+	Exit(opt, r.Added.FailedBy(opt.FailCondition()))
+
+	return nil
+}
+
+type differ struct {
+	opt    Option
+	runner *Runner
+}
+
+// load reads the JSON report if the file exists, otherwise scans the container image
+func (d *differ) load(ctx context.Context, arg string) (types.Report, error) {
+	if _, err := os.Stat(arg); err == nil {
+		return readReport(arg)
+	}
+
+	// The scanner and DB are initialized only when an image needs to be scanned
+	if d.runner == nil {
+		runner, err := NewRunner(d.opt)
+		if errors.Is(err, SkipScan) {
+			return types.Report{}, xerrors.New("the image can not be scanned with the given options")
+		} else if err != nil {
+			return types.Report{}, xerrors.Errorf("init error: %w", err)
+		}
+		d.runner = runner
+	}
+
+	opt := d.opt
+	opt.Target = arg
+	log.Logger.Infof("Scanning %s...", arg)
+
+	report, err := d.runner.ScanImage(ctx, opt)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("image scan error: %w", err)
+	}
+	report, err = d.runner.Filter(ctx, opt, report)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("filter error: %w", err)
+	}
+	return report, nil
+}
+
+func (d *differ) close() {
+	if d.runner == nil {
+		return
+	}
+	if err := d.runner.Close(); err != nil {
+		log.Logger.Errorf("failed to close the runner: %s", err)
+	}
+	d.runner = nil
+}
+
+func readReport(path string) (types.Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("file open error: %w", err)
+	}
+	defer f.Close()
+
+	// The reports written with '--schema' can be compared as well
+	report, err := pkgReport.DecodeReport(f)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("report decode error (%s): %w", path, err)
+	}
+	return report, nil
+}
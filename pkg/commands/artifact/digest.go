@@ -0,0 +1,52 @@
+package artifact
+
+import (
+	"github.com/google/go-containerregistry/pkg/name"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// pinImage returns the canonical reference of the image name, e.g. "index.docker.io/library/alpine:3.15",
+// and the reference pinned to the digest of the scanned image.
+// The digest is taken from the scanned image rather than resolved again,
+// so that the image deployed by the digest is always the one the results come from.
+func pinImage(imageName string, repoDigests []string) (reference, digest string) {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		log.Logger.Debugf("Unable to parse the image name (%s): %s", imageName, err)
+		return "", ""
+	}
+
+	// The tag is dropped from "name:tag@digest"
+	if _, ok := ref.(name.Digest); ok {
+		return ref.Name(), ref.Name()
+	}
+
+	for _, rd := range repoDigests {
+		d, err := name.NewDigest(rd)
+		if err != nil {
+			continue
+		}
+		// The image might be pushed to several repositories
+		if d.Context().Name() == ref.Context().Name() {
+			return ref.Name(), d.Name()
+		}
+	}
+
+	// e.g. an image built locally and not pushed yet
+	log.Logger.Debugf("Unable to pin %s to a digest as it has no digest in %s", imageName, ref.Context().Name())
+	return ref.Name(), ""
+}
+
+// requireDigest returns an error if the image name is not pinned to a digest
+func requireDigest(imageName string) error {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return xerrors.Errorf("failed to parse the image name: %w", err)
+	}
+	if _, ok := ref.(name.Digest); !ok {
+		return xerrors.Errorf("%s is not pinned to a digest, specify the image as 'name@sha256:<digest>' with '--require-digest'", imageName)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package artifact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pinImage(t *testing.T) {
+	const digest = "sha256:4edbd2beb5f78b1014028f4fbb99f3237d9561100b6881aabbf5acce2c4f9454"
+	tests := []struct {
+		name          string
+		imageName     string
+		repoDigests   []string
+		wantReference string
+		wantDigest    string
+	}{
+		{
+			name:          "tag",
+			imageName:     "alpine:3.15",
+			repoDigests:   []string{"alpine@" + digest},
+			wantReference: "index.docker.io/library/alpine:3.15",
+			wantDigest:    "index.docker.io/library/alpine@" + digest,
+		},
+		{
+			name:      "multiple repositories",
+			imageName: "ghcr.io/aquasecurity/trivy:0.28.0",
+			repoDigests: []string{
+				"aquasec/trivy@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+				"ghcr.io/aquasecurity/trivy@" + digest,
+			},
+			wantReference: "ghcr.io/aquasecurity/trivy:0.28.0",
+			wantDigest:    "ghcr.io/aquasecurity/trivy@" + digest,
+		},
+		{
+			name:          "digest",
+			imageName:     "alpine@" + digest,
+			wantReference: "index.docker.io/library/alpine@" + digest,
+			wantDigest:    "index.docker.io/library/alpine@" + digest,
+		},
+		{
+			name:          "tag and digest",
+			imageName:     "alpine:3.15@" + digest,
+			wantReference: "index.docker.io/library/alpine@" + digest,
+			wantDigest:    "index.docker.io/library/alpine@" + digest,
+		},
+		{
+			name:          "no repo digest",
+			imageName:     "myapp:dev",
+			wantReference: "index.docker.io/library/myapp:dev",
+		},
+		{
+			name:      "invalid name",
+			imageName: "INVALID:tag",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotReference, gotDigest := pinImage(tt.imageName, tt.repoDigests)
+			assert.Equal(t, tt.wantReference, gotReference)
+			assert.Equal(t, tt.wantDigest, gotDigest)
+		})
+	}
+}
+
+func Test_requireDigest(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageName string
+		wantErr   string
+	}{
+		{
+			name:      "digest",
+			imageName: "alpine@sha256:4edbd2beb5f78b1014028f4fbb99f3237d9561100b6881aabbf5acce2c4f9454",
+		},
+		{
+			name:      "tag",
+			imageName: "alpine:3.15",
+			wantErr:   "alpine:3.15 is not pinned to a digest",
+		},
+		{
+			name:      "no tag",
+			imageName: "alpine",
+			wantErr:   "alpine is not pinned to a digest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireDigest(tt.imageName)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// localFallback scans the targets in standalone mode when the server is unreachable with '--fallback-to-local'.
+// The standalone runner is initialized on the first fallback only, as it downloads the DB.
+type localFallback struct {
+	runner *Runner
+}
+
+// wrap returns the scan function falling back to scanning locally
+func (f *localFallback) wrap(scan scanFunc) scanFunc {
+	return func(ctx context.Context, opt Option, artifactType ArtifactType) (types.Report, error) {
+		report, err := scan(ctx, opt, artifactType)
+		// The errors of the scan itself, e.g. the image not found, would happen locally as well
+		if err == nil || ctx.Err() != nil || !rpc.Unreachable(err) {
+			return report, err
+		}
+		log.Logger.Warnf("The server is unreachable, falling back to scanning %s locally: %s", opt.Target, err)
+
+		opt = standaloneOption(opt)
+		if f.runner == nil {
+			runner, err := NewRunner(opt)
+			if errors.Is(err, SkipScan) {
+				return types.Report{}, xerrors.New("the target can not be scanned locally with the given options")
+			} else if err != nil {
+				return types.Report{}, xerrors.Errorf("fallback init error: %w", err)
+			}
+			f.runner = runner
+		}
+		return scanTarget(ctx, f.runner, opt, artifactType)
+	}
+}
+
+func (f *localFallback) close() {
+	if f.runner == nil {
+		return
+	}
+	if err := f.runner.Close(); err != nil {
+		log.Logger.Errorf("failed to close the fallback runner: %s", err)
+	}
+	f.runner = nil
+}
+
+// standaloneOption returns the options to scan the target without the server
+func standaloneOption(opt Option) Option {
+	opt.RemoteAddr = ""
+	opt.PullViaServer = false
+	opt.ServerSideAnalysis = false
+	opt.ServerSidePull = false
+	opt.FallbackToLocal = false
+	return opt
+}
@@ -2,10 +2,16 @@ package artifact
 
 import (
 	"context"
+	"net/http"
+	"os"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
+	"github.com/aquasecurity/fanal/image"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	rpcClient "github.com/aquasecurity/trivy/pkg/rpc/client"
 	"github.com/aquasecurity/trivy/pkg/scanner"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
@@ -13,7 +19,7 @@ import (
 // imageStandaloneScanner initializes a container image scanner in standalone mode
 // $ trivy image alpine:3.15
 func imageStandaloneScanner(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
-	dockerOpt, err := types.GetDockerOption(conf.ArtifactOption.InsecureSkipTLS)
+	dockerOpt, err := types.GetDockerOption()
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
@@ -40,7 +46,7 @@ func archiveStandaloneScanner(ctx context.Context, conf ScannerConfig) (scanner.
 func imageRemoteScanner(ctx context.Context, conf ScannerConfig) (
 	scanner.Scanner, func(), error) {
 	// Scan an image in Docker Engine, Docker Registry, etc.
-	dockerOpt, err := types.GetDockerOption(conf.ArtifactOption.InsecureSkipTLS)
+	dockerOpt, err := types.GetDockerOption()
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
@@ -53,6 +59,66 @@ func imageRemoteScanner(ctx context.Context, conf ScannerConfig) (
 	return s, cleanup, nil
 }
 
+// imageProxyScanner initializes a scanner of the image pulled through the server in client/server mode
+// $ trivy image --server localhost:4954 --pull-via-server alpine:3.15
+func imageProxyScanner(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+	img, err := rpcClient.NewProxiedImage(ctx, conf.Target, conf.RemoteOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to pull the image through the server: %w", err)
+	}
+
+	s, err := initializeRemoteImageScanner(ctx, img, conf.ArtifactCache, conf.RemoteOption, conf.ArtifactOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to initialize the image scanner: %w", err)
+	}
+	return s, func() {}, nil
+}
+
+// imageServerSideScanner initializes a container image scanner uploading the layers to the server in client/server mode
+// $ trivy image --server localhost:4954 --server-side-analysis alpine:3.15
+func imageServerSideScanner(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+	dockerOpt, err := types.GetDockerOption()
+	if err != nil {
+		return scanner.Scanner{}, nil, err
+	}
+	img, cleanup, err := tartifact.NewDockerImage(ctx, conf.Target, dockerOpt, conf.ArtifactOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to open the image: %w", err)
+	}
+
+	s, err := initializeServerSideImageScanner(ctx, img, conf.ArtifactCache, conf.RemoteOption, conf.ArtifactOption)
+	if err != nil {
+		cleanup()
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to initialize the image scanner: %w", err)
+	}
+	return s, cleanup, nil
+}
+
+// imageServerSidePullScanner initializes a container image scanner pulling the image by the server in client/server mode
+// $ trivy image --server localhost:4954 --server-side-pull alpine:3.15
+func imageServerSidePullScanner(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+	s, err := initializeServerSidePullImageScanner(ctx, conf.Target, conf.RemoteOption, conf.ArtifactOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to initialize the image scanner: %w", err)
+	}
+	return s, func() {}, nil
+}
+
+// archiveServerSideScanner initializes an image archive scanner uploading the layers to the server in client/server mode
+// $ trivy image --server localhost:4954 --server-side-analysis --input alpine.tar
+func archiveServerSideScanner(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+	img, err := image.NewArchiveImage(conf.Target)
+	if err != nil {
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to open the image archive: %w", err)
+	}
+
+	s, err := initializeServerSideImageScanner(ctx, img, conf.ArtifactCache, conf.RemoteOption, conf.ArtifactOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, xerrors.Errorf("unable to initialize the image scanner: %w", err)
+	}
+	return s, func() {}, nil
+}
+
 // archiveRemoteScanner initializes an image archive scanner in client/server mode
 // $ trivy image --server localhost:4954 --input alpine.tar
 func archiveRemoteScanner(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
@@ -64,6 +130,56 @@ func archiveRemoteScanner(ctx context.Context, conf ScannerConfig) (scanner.Scan
 	return s, func() {}, nil
 }
 
+// estimateImageSize returns the disk space needed to scan the image, or 0 if it is unknown.
+// Images in Docker Engine are saved to the workspace as a tarball, while images in registries are streamed.
+func estimateImageSize(ctx context.Context, opt Option) int64 {
+	if opt.Input != "" {
+		fi, err := os.Stat(opt.Input)
+		if err != nil {
+			return 0
+		}
+		return fi.Size()
+	}
+
+	c, err := dockerDaemon(opt).Client()
+	if err != nil {
+		return 0
+	}
+	defer c.Close()
+
+	inspect, _, err := c.ImageInspectWithRaw(ctx, opt.Target)
+	if err != nil {
+		log.Logger.Debugf("Unable to estimate the image size: %s", err)
+		return 0
+	}
+	return inspect.Size
+}
+
+// dockerDaemon returns the Docker daemon given by '--docker-host' and '--docker-cert-path'
+func dockerDaemon(opt Option) tartifact.DockerDaemon {
+	return tartifact.DockerDaemon{
+		Host:     opt.DockerHost,
+		CertPath: opt.DockerCertPath,
+	}
+}
+
+// registryTransport returns the transport pulling images from registries, verified with the given CA certificates
+// or not verified with '--insecure', and failing without connecting with '--offline-scan'.
+// It is nil otherwise, and fanal uses the default transport of go-containerregistry, which honors HTTP(S)_PROXY and NO_PROXY.
+func registryTransport(opt Option) http.RoundTripper {
+	if !opt.OfflineScan && opt.RegistryRootCAs == nil && !opt.Insecure {
+		return nil
+	}
+
+	t := tartifact.NewRegistryTransport(opt.RegistryRootCAs, opt.Insecure)
+	if opt.OfflineScan {
+		t.Proxy = nil
+		t.DialContext = dialDisabled
+		t.DialTLSContext = nil
+	}
+	return t
+}
+
 // ImageRun runs scan on container image
 func ImageRun(ctx *cli.Context) error {
 	return Run(ctx, containerImageArtifact)
@@ -8,9 +8,9 @@ import (
 
 	"github.com/google/wire"
 
-	"github.com/aquasecurity/fanal/artifact"
 	"github.com/aquasecurity/fanal/cache"
 	"github.com/aquasecurity/fanal/types"
+	artifact2 "github.com/aquasecurity/trivy/pkg/artifact"
 	"github.com/aquasecurity/trivy/pkg/result"
 	"github.com/aquasecurity/trivy/pkg/rpc/client"
 	"github.com/aquasecurity/trivy/pkg/scanner"
@@ -23,7 +23,7 @@ import (
 // initializeDockerScanner is for container image scanning in standalone mode
 // e.g. dockerd, container registry, podman, etc.
 func initializeDockerScanner(ctx context.Context, imageName string, artifactCache cache.ArtifactCache,
-	localArtifactCache cache.LocalArtifactCache, dockerOpt types.DockerOption, artifactOption artifact.Option) (
+	localArtifactCache cache.LocalArtifactCache, dockerOpt types.DockerOption, artifactOption artifact2.Option) (
 	scanner.Scanner, func(), error) {
 	wire.Build(scanner.StandaloneDockerSet)
 	return scanner.Scanner{}, nil, nil
@@ -32,20 +32,43 @@ func initializeDockerScanner(ctx context.Context, imageName string, artifactCach
 // initializeArchiveScanner is for container image archive scanning in standalone mode
 // e.g. docker save -o alpine.tar alpine:3.15
 func initializeArchiveScanner(ctx context.Context, filePath string, artifactCache cache.ArtifactCache,
-	localArtifactCache cache.LocalArtifactCache, artifactOption artifact.Option) (scanner.Scanner, error) {
+	localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, error) {
 	wire.Build(scanner.StandaloneArchiveSet)
 	return scanner.Scanner{}, nil
 }
 
+// initializeImageScanner is for scanning images opened by Trivy in standalone mode
+// e.g. images in the BuildKit content store
+func initializeImageScanner(ctx context.Context, img types.Image, artifactCache cache.ArtifactCache,
+	localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	wire.Build(scanner.StandaloneImageSet)
+	return scanner.Scanner{}, nil
+}
+
+// initializeSBOMScanner is for scanning images with the packages listed by their SBOMs in standalone mode
+// e.g. SBOMs attached to the images by cosign
+func initializeSBOMScanner(ctx context.Context, imageName string, sbom artifact2.SBOM, artifactCache cache.ArtifactCache,
+	localArtifactCache cache.LocalArtifactCache) scanner.Scanner {
+	wire.Build(scanner.StandaloneSBOMSet)
+	return scanner.Scanner{}
+}
+
 // initializeFilesystemScanner is for filesystem scanning in standalone mode
 func initializeFilesystemScanner(ctx context.Context, path string, artifactCache cache.ArtifactCache,
-	localArtifactCache cache.LocalArtifactCache, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+	localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	wire.Build(scanner.StandaloneFilesystemSet)
 	return scanner.Scanner{}, nil, nil
 }
 
+// initializeSSHScanner is for scanning the filesystems of remote hosts over SSH in standalone mode
+func initializeSSHScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache,
+	localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
+	wire.Build(scanner.StandaloneSSHSet)
+	return scanner.Scanner{}, nil, nil
+}
+
 func initializeRepositoryScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache,
-	localArtifactCache cache.LocalArtifactCache, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+	localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	wire.Build(scanner.StandaloneRepositorySet)
 	return scanner.Scanner{}, nil, nil
 }
@@ -62,7 +85,7 @@ func initializeResultClient() result.Client {
 // initializeRemoteDockerScanner is for container image scanning in client/server mode
 // e.g. dockerd, container registry, podman, etc.
 func initializeRemoteDockerScanner(ctx context.Context, imageName string, artifactCache cache.ArtifactCache,
-	remoteScanOptions client.ScannerOption, dockerOpt types.DockerOption, artifactOption artifact.Option) (
+	remoteScanOptions client.ScannerOption, dockerOpt types.DockerOption, artifactOption artifact2.Option) (
 	scanner.Scanner, func(), error) {
 	wire.Build(scanner.RemoteDockerSet)
 	return scanner.Scanner{}, nil, nil
@@ -71,18 +94,54 @@ func initializeRemoteDockerScanner(ctx context.Context, imageName string, artifa
 // initializeRemoteArchiveScanner is for container image archive scanning in client/server mode
 // e.g. docker save -o alpine.tar alpine:3.15
 func initializeRemoteArchiveScanner(ctx context.Context, filePath string, artifactCache cache.ArtifactCache,
-	remoteScanOptions client.ScannerOption, artifactOption artifact.Option) (scanner.Scanner, error) {
+	remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
 	wire.Build(scanner.RemoteArchiveSet)
 	return scanner.Scanner{}, nil
 }
 
+// initializeRemoteImageScanner is for scanning images opened by Trivy in client/server mode
+// e.g. images in the BuildKit content store or pulled through the server
+func initializeRemoteImageScanner(ctx context.Context, img types.Image, artifactCache cache.ArtifactCache,
+	remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	wire.Build(scanner.RemoteImageSet)
+	return scanner.Scanner{}, nil
+}
+
+// initializeRemoteSBOMScanner is for scanning images with the packages listed by their SBOMs in client/server mode
+func initializeRemoteSBOMScanner(ctx context.Context, imageName string, sbom artifact2.SBOM, artifactCache cache.ArtifactCache,
+	remoteScanOptions client.ScannerOption) scanner.Scanner {
+	wire.Build(scanner.RemoteSBOMSet)
+	return scanner.Scanner{}
+}
+
+// initializeServerSideImageScanner is for scanning images whose layers are analyzed on the server in client/server mode
+func initializeServerSideImageScanner(ctx context.Context, img types.Image, artifactCache cache.ArtifactCache,
+	remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	wire.Build(scanner.RemoteServerSideImageSet)
+	return scanner.Scanner{}, nil
+}
+
+// initializeServerSidePullImageScanner is for scanning images pulled by the server in client/server mode
+func initializeServerSidePullImageScanner(ctx context.Context, imageName string, remoteScanOptions client.ScannerOption,
+	artifactOption artifact2.Option) (scanner.Scanner, error) {
+	wire.Build(scanner.RemoteServerSidePullSet)
+	return scanner.Scanner{}, nil
+}
+
 // initializeRemoteFilesystemScanner is for filesystem scanning in client/server mode
 func initializeRemoteFilesystemScanner(ctx context.Context, path string, artifactCache cache.ArtifactCache,
-	remoteScanOptions client.ScannerOption, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+	remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	wire.Build(scanner.RemoteFilesystemSet)
 	return scanner.Scanner{}, nil, nil
 }
 
+// initializeRemoteSSHScanner is for scanning the filesystems of remote hosts over SSH in client/server mode
+func initializeRemoteSSHScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache,
+	remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
+	wire.Build(scanner.RemoteSSHSet)
+	return scanner.Scanner{}, nil, nil
+}
+
 func initializeRemoteResultClient() result.Client {
 	wire.Build(result.SuperSet)
 	return result.Client{}
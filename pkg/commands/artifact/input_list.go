@@ -0,0 +1,264 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/history"
+	"github.com/aquasecurity/trivy/pkg/log"
+	pkgReport "github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/store"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/webhook"
+)
+
+var listArtifactTypes = []ArtifactType{
+	containerImageArtifact,
+	imageArchiveArtifact,
+	filesystemArtifact,
+	rootfsArtifact,
+	repositoryArtifact,
+}
+
+// InputList represents the targets passed via "--input-list"
+type InputList struct {
+	Targets []ListTarget `yaml:"targets"`
+}
+
+// ListTarget represents a target in the input list.
+// The options override those given by the command line for the target.
+type ListTarget struct {
+	Target string       `yaml:"target"`
+	Type   ArtifactType `yaml:"type"`
+
+	// Priority and Labels are the hints to order the targets, see prioritizeTargets
+	Priority int               `yaml:"priority"`
+	Labels   map[string]string `yaml:"labels"`
+
+	Severity       []string `yaml:"severity"`
+	SkipDirs       []string `yaml:"skip-dirs"`
+	SkipFiles      []string `yaml:"skip-files"`
+	IgnoreUnfixed  *bool    `yaml:"ignore-unfixed"`
+	IgnoreFile     string   `yaml:"ignorefile"`
+	SecurityChecks []string `yaml:"security-checks"`
+	VulnType       []string `yaml:"vuln-type"`
+}
+
+// stdinInputList reads the input list from stdin, e.g. the images listed by another command
+const stdinInputList = "-"
+
+// stdin is replaced in tests
+var stdin io.Reader = os.Stdin
+
+// readInputList reads the targets in YAML, or the targets of the default type listed one per line,
+// e.g. the images exported from the registry
+func readInputList(path string, defaultType ArtifactType) (InputList, error) {
+	var b []byte
+	var err error
+	if path == stdinInputList {
+		b, err = io.ReadAll(stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return InputList{}, xerrors.Errorf("file read error: %w", err)
+	}
+
+	var list InputList
+	if isYAMLMapping(b) {
+		if err = yaml.Unmarshal(b, &list); err != nil {
+			return InputList{}, xerrors.Errorf("yaml decode error (%s): %w", path, err)
+		}
+	} else {
+		lines, err := readLines(bytes.NewReader(b))
+		if err != nil {
+			return InputList{}, xerrors.Errorf("read error (%s): %w", path, err)
+		}
+		for _, line := range lines {
+			list.Targets = append(list.Targets, ListTarget{Target: line})
+		}
+	}
+	if len(list.Targets) == 0 {
+		return InputList{}, xerrors.Errorf("no target found in %s", path)
+	}
+
+	for i, t := range list.Targets {
+		if t.Target == "" {
+			return InputList{}, xerrors.Errorf("targets[%d]: target must be specified", i)
+		}
+		if t.Type == "" {
+			list.Targets[i].Type = defaultType
+		} else if !slices.Contains(listArtifactTypes, t.Type) {
+			return InputList{}, xerrors.Errorf("targets[%d]: type must be %q", i, listArtifactTypes)
+		}
+	}
+	return list, nil
+}
+
+// isYAMLMapping returns true if the list is a YAML document with "targets", and false if it lists the targets one
+// per line. A line with a colon, e.g. "alpine:3.15", is not a mapping as the value is not separated by a space.
+func isYAMLMapping(b []byte) bool {
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil || len(node.Content) == 0 {
+		return false
+	}
+	return node.Content[0].Kind == yaml.MappingNode
+}
+
+// apply returns the options to scan the target
+func (t ListTarget) apply(opt Option) (Option, error) {
+	opt.Target = t.Target
+	opt.Input = ""
+	if t.Type == imageArchiveArtifact {
+		opt.Input = t.Target
+	}
+
+	if len(t.Severity) > 0 {
+		opt.Severities = nil
+		for _, s := range t.Severity {
+			severity, err := dbTypes.NewSeverity(strings.ToUpper(s))
+			if err != nil {
+				return Option{}, xerrors.Errorf("unknown severity (%s)", s)
+			}
+			opt.Severities = append(opt.Severities, severity)
+		}
+	}
+	if len(t.SecurityChecks) > 0 {
+		for _, c := range t.SecurityChecks {
+			if types.NewSecurityCheck(c) == types.SecurityCheckUnknown {
+				return Option{}, xerrors.Errorf("unknown security check (%s)", c)
+			}
+		}
+		opt.SecurityChecks = t.SecurityChecks
+	}
+	if len(t.VulnType) > 0 {
+		for _, v := range t.VulnType {
+			if types.NewVulnType(v) == types.VulnTypeUnknown {
+				return Option{}, xerrors.Errorf("unknown vulnerability type (%s)", v)
+			}
+		}
+		opt.VulnType = t.VulnType
+	}
+	if len(t.SkipDirs) > 0 {
+		opt.SkipDirs = t.SkipDirs
+	}
+	if len(t.SkipFiles) > 0 {
+		opt.SkipFiles = t.SkipFiles
+	}
+	if t.IgnoreUnfixed != nil {
+		opt.IgnoreUnfixed = *t.IgnoreUnfixed
+	}
+	if t.IgnoreFile != "" {
+		opt.IgnoreFile = t.IgnoreFile
+	}
+	return opt, nil
+}
+
+// scanFunc scans the target
+type scanFunc func(ctx context.Context, opt Option, artifactType ArtifactType) (types.Report, error)
+
+// scanInputList scans the targets in the input list and combines the results into one report.
+// The targets which are not scanned within the scan budget are reported as not scanned.
+func scanInputList(ctx context.Context, opt Option, defaultType ArtifactType, scan scanFunc) (types.Report, error) {
+	list, err := readInputList(opt.InputList, defaultType)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("input list error: %w", err)
+	}
+	list.Targets = prioritizeTargets(ctx, opt, list.Targets, inspectImage)
+
+	combined := types.Report{
+		SchemaVersion: pkgReport.SchemaVersion,
+		ArtifactName:  opt.InputList,
+	}
+	var notScanned int
+	for i, t := range list.Targets {
+		if budgetExhausted(ctx) {
+			combined.Results = append(combined.Results, notScannedResult(t.Target))
+			notScanned++
+			continue
+		}
+
+		targetOpt, err := t.apply(opt)
+		if err != nil {
+			return types.Report{}, xerrors.Errorf("targets[%d] (%s): %w", i, t.Target, err)
+		}
+
+		log.Logger.Infof("Scanning %s (%d/%d)...", t.Target, i+1, len(list.Targets))
+		r, err := scan(ctx, targetOpt, t.Type)
+		if err != nil && budgetExhausted(ctx) {
+			combined.Results = append(combined.Results, notScannedResult(t.Target))
+			notScanned++
+			continue
+		} else if err != nil {
+			return types.Report{}, xerrors.Errorf("%s: %w", t.Target, err)
+		}
+
+		for _, result := range r.Results {
+			// The targets of the results are relative to the artifact except for OS packages
+			if !strings.HasPrefix(result.Target, r.ArtifactName) {
+				result.Target = fmt.Sprintf("%s: %s", r.ArtifactName, result.Target)
+			}
+			combined.Results = append(combined.Results, result)
+		}
+	}
+
+	if notScanned > 0 {
+		log.Logger.Warnf("The scan budget (%s) was exhausted, %d of %d targets were not scanned",
+			opt.ScanBudget, notScanned, len(list.Targets))
+	}
+	return combined, nil
+}
+
+// scanTarget scans and filters the target with its own timeout
+func scanTarget(ctx context.Context, runner *Runner, opt Option, artifactType ArtifactType) (types.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, opt.Timeout)
+	defer cancel()
+
+	r, err := scanArtifact(ctx, runner, opt, artifactType)
+	if err != nil {
+		return types.Report{}, err
+	}
+	r, err = runner.Filter(ctx, opt, r)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("filter error: %w", err)
+	}
+	r.Metadata.Scan = runner.scanMetadata(ctx, opt)
+
+	// Findings are tracked after filtering so that the history matches the reports
+	if opt.HistoryDir != "" {
+		if err = history.NewStore(opt.HistoryDir).Track(&r, time.Now()); err != nil {
+			return types.Report{}, xerrors.Errorf("history error: %w", err)
+		}
+	}
+
+	// The canonical report is archived regardless of the output format
+	var reportURL string
+	if opt.Store != "" {
+		if reportURL, err = store.Write(ctx, opt.Store, r, opt.AppVersion, time.Now()); err != nil {
+			return types.Report{}, xerrors.Errorf("store error: %w", err)
+		}
+	}
+
+	// The notification doesn't fail the scan
+	if opt.WebhookURL != "" {
+		payload := webhook.NewPayload(r, reportURL, opt.WebhookAttachReport)
+		webhookOption := webhook.Option{
+			URL:      opt.WebhookURL,
+			Insecure: opt.Insecure,
+		}
+		if err = webhook.Post(ctx, webhookOption, payload); err != nil {
+			log.Logger.Errorf("Webhook notification error: %s", err)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,310 @@
+package artifact
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_readInputList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    InputList
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			content: `
+targets:
+  - target: alpine:3.15
+  - target: ./app
+    type: fs
+    severity: [HIGH, CRITICAL]
+    skip-dirs: [node_modules]
+    ignore-unfixed: false
+`,
+			want: InputList{
+				Targets: []ListTarget{
+					{
+						Target: "alpine:3.15",
+						Type:   containerImageArtifact,
+					},
+					{
+						Target:        "./app",
+						Type:          filesystemArtifact,
+						Severity:      []string{"HIGH", "CRITICAL"},
+						SkipDirs:      []string{"node_modules"},
+						IgnoreUnfixed: boolPtr(false),
+					},
+				},
+			},
+		},
+		{
+			name:    "no target",
+			content: `targets: []`,
+			wantErr: "no target found",
+		},
+		{
+			name: "empty target",
+			content: `
+targets:
+  - type: fs
+`,
+			wantErr: "targets[0]: target must be specified",
+		},
+		{
+			name: "unsupported type",
+			content: `
+targets:
+  - target: bom.json
+    type: sbom
+`,
+			wantErr: "targets[0]: type must be",
+		},
+		{
+			name: "one image per line",
+			content: `# exported from the registry
+ghcr.io/org/app:1.0
+
+alpine:3.15
+`,
+			want: InputList{
+				Targets: []ListTarget{
+					{
+						Target: "ghcr.io/org/app:1.0",
+						Type:   containerImageArtifact,
+					},
+					{
+						Target: "alpine:3.15",
+						Type:   containerImageArtifact,
+					},
+				},
+			},
+		},
+		{
+			name:    "only comments",
+			content: "# no image\n",
+			wantErr: "no target found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "targets.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			got, err := readInputList(path, containerImageArtifact)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_readInputList_stdin(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = strings.NewReader("alpine:3.15\nalpine:3.16\n")
+
+	got, err := readInputList(stdinInputList, containerImageArtifact)
+	require.NoError(t, err)
+	assert.Equal(t, InputList{
+		Targets: []ListTarget{
+			{
+				Target: "alpine:3.15",
+				Type:   containerImageArtifact,
+			},
+			{
+				Target: "alpine:3.16",
+				Type:   containerImageArtifact,
+			},
+		},
+	}, got)
+}
+
+func TestListTarget_apply(t *testing.T) {
+	opt := Option{
+		ArtifactOption: option.ArtifactOption{
+			SkipDirs: []string{"test"},
+		},
+		ReportOption: option.ReportOption{
+			IgnoreUnfixed:  true,
+			Severities:     []dbTypes.Severity{dbTypes.SeverityCritical},
+			SecurityChecks: []string{types.SecurityCheckVulnerability},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		target  ListTarget
+		want    Option
+		wantErr string
+	}{
+		{
+			name: "command line options",
+			target: ListTarget{
+				Target: "alpine:3.15",
+				Type:   containerImageArtifact,
+			},
+			want: Option{
+				ArtifactOption: option.ArtifactOption{
+					Target:   "alpine:3.15",
+					SkipDirs: []string{"test"},
+				},
+				ReportOption: opt.ReportOption,
+			},
+		},
+		{
+			name: "target options",
+			target: ListTarget{
+				Target:         "alpine.tar",
+				Type:           imageArchiveArtifact,
+				Severity:       []string{"low", "MEDIUM"},
+				SkipDirs:       []string{"vendor"},
+				IgnoreUnfixed:  boolPtr(false),
+				SecurityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckSecret},
+			},
+			want: Option{
+				ArtifactOption: option.ArtifactOption{
+					Input:    "alpine.tar",
+					Target:   "alpine.tar",
+					SkipDirs: []string{"vendor"},
+				},
+				ReportOption: option.ReportOption{
+					Severities:     []dbTypes.Severity{dbTypes.SeverityLow, dbTypes.SeverityMedium},
+					SecurityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckSecret},
+				},
+			},
+		},
+		{
+			name: "unknown severity",
+			target: ListTarget{
+				Target:   "./app",
+				Severity: []string{"SEVERE"},
+			},
+			wantErr: "unknown severity (SEVERE)",
+		},
+		{
+			name: "unknown security check",
+			target: ListTarget{
+				Target:         "./app",
+				SecurityChecks: []string{"malware"},
+			},
+			wantErr: "unknown security check (malware)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.target.apply(opt)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func Test_scanInputList(t *testing.T) {
+	// "./slow" blocks until the context is done, e.g. the analysis of an enormous artifact
+	scan := func(ctx context.Context, opt Option, _ ArtifactType) (types.Report, error) {
+		switch opt.Target {
+		case "./slow":
+			<-ctx.Done()
+			return types.Report{}, xerrors.Errorf("analyze error: %w", ctx.Err())
+		case "./broken":
+			return types.Report{}, xerrors.New("broken")
+		}
+		return types.Report{
+			ArtifactName: opt.Target,
+			Results:      types.Results{{Target: "go.sum", Class: types.ClassLangPkg, Type: "gomod"}},
+		}, nil
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		budget  time.Duration
+		want    types.Results
+		wantErr string
+	}{
+		{
+			name: "budget exhausted",
+			content: `
+targets:
+  - target: ./app
+  - target: ./slow
+  - target: ./lib
+`,
+			budget: 100 * time.Millisecond,
+			want: types.Results{
+				{Target: "./app: go.sum", Class: types.ClassLangPkg, Type: "gomod"},
+				{Target: "./slow", NotScanned: notScannedBudget},
+				{Target: "./lib", NotScanned: notScannedBudget},
+			},
+		},
+		{
+			name: "within budget",
+			content: `
+targets:
+  - target: ./app
+  - target: ./lib
+`,
+			budget: time.Minute,
+			want: types.Results{
+				{Target: "./app: go.sum", Class: types.ClassLangPkg, Type: "gomod"},
+				{Target: "./lib: go.sum", Class: types.ClassLangPkg, Type: "gomod"},
+			},
+		},
+		{
+			name: "scan error",
+			content: `
+targets:
+  - target: ./app
+  - target: ./broken
+`,
+			budget:  time.Minute,
+			wantErr: "./broken: broken",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "targets.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			ctx, cancel := withScanBudget(context.Background(), tt.budget)
+			defer cancel()
+
+			opt := Option{ArtifactOption: option.ArtifactOption{InputList: path, ScanBudget: tt.budget}}
+			got, err := scanInputList(ctx, opt, filesystemArtifact, scan)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Results)
+		})
+	}
+}
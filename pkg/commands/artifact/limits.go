@@ -0,0 +1,181 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/image/token"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// largestLayers is the number of the layers reported when the image is too large
+const largestLayers = 3
+
+// layerSize is the size of the layer and the instruction which created it
+type layerSize struct {
+	createdBy string
+	size      int64
+}
+
+// imageSize is the size of the image, which is uncompressed in Docker Engine and compressed in the registries
+type imageSize struct {
+	total  int64
+	layers []layerSize
+}
+
+// checkImageSize returns an error with the largest layers if the image is larger than '--max-image-size'.
+// The image is looked up in the same order as the scan, and the check is skipped if the size is unknown.
+func checkImageSize(ctx context.Context, opt Option) error {
+	var size imageSize
+	var err error
+	switch {
+	case opt.Input != "":
+		var fi os.FileInfo
+		if fi, err = os.Stat(opt.Input); err == nil {
+			size.total = fi.Size()
+		}
+	default:
+		if size, err = daemonImageSize(ctx, dockerDaemon(opt), opt.Target); err != nil {
+			log.Logger.Debugf("Unable to get the size of the image in Docker Engine: %s", err)
+			size, err = registryImageSize(ctx, opt.Target, registryTransport(opt))
+		}
+	}
+	if err != nil {
+		log.Logger.Warnf("'--max-image-size' is not checked as the size of the image is unknown: %s", err)
+		return nil
+	}
+	return size.check(opt.MaxImageSize)
+}
+
+func (s imageSize) check(max int64) error {
+	if s.total <= max {
+		return nil
+	}
+	msg := fmt.Sprintf("the image is %s, larger than %s", units.BytesSize(float64(s.total)), units.BytesSize(float64(max)))
+
+	layers := append([]layerSize{}, s.layers...)
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layers[i].size > layers[j].size
+	})
+	if len(layers) > largestLayers {
+		layers = layers[:largestLayers]
+	}
+	var largest []string
+	for _, l := range layers {
+		largest = append(largest, fmt.Sprintf("%s: %s", units.BytesSize(float64(l.size)), instruction(l.createdBy)))
+	}
+	if len(largest) > 0 {
+		msg += fmt.Sprintf(", the largest layers: %s", strings.Join(largest, "; "))
+	}
+	return xerrors.New(msg + ". Slim the image or raise '--max-image-size'")
+}
+
+// instruction trims the shell prefix of the instruction in the history, e.g. "/bin/sh -c #(nop) "
+func instruction(createdBy string) string {
+	s := strings.TrimSpace(createdBy)
+	s = strings.TrimPrefix(s, "/bin/sh -c ")
+	s = strings.TrimSpace(strings.TrimPrefix(s, "#(nop)"))
+	if s == "" {
+		return "unknown"
+	}
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:77]) + "..."
+	}
+	return s
+}
+
+func daemonImageSize(ctx context.Context, docker tartifact.DockerDaemon, imageName string) (imageSize, error) {
+	c, err := docker.Client()
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("docker client error: %w", err)
+	}
+	defer c.Close()
+
+	inspect, _, err := c.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("unable to inspect the image: %w", err)
+	}
+	size := imageSize{total: inspect.Size}
+
+	history, err := c.ImageHistory(ctx, imageName)
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("unable to get the history: %w", err)
+	}
+	for _, h := range history {
+		if h.Size > 0 {
+			size.layers = append(size.layers, layerSize{createdBy: h.CreatedBy, size: h.Size})
+		}
+	}
+	return size, nil
+}
+
+// registryImageSize returns the compressed size of the image from the manifest, without pulling the layers
+func registryImageSize(ctx context.Context, imageName string, transport http.RoundTripper) (imageSize, error) {
+	dockerOpt, err := types.GetDockerOption()
+	if err != nil {
+		return imageSize{}, err
+	}
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("invalid image name: %w", err)
+	}
+
+	// The same credentials as fanal
+	remoteOpts := []remote.Option{remote.WithContext(ctx)}
+	if transport != nil {
+		remoteOpts = append(remoteOpts, remote.WithTransport(transport))
+	}
+	auth := token.GetToken(ctx, ref.Context().RegistryStr(), dockerOpt)
+	switch {
+	case auth.Username != "" && auth.Password != "":
+		remoteOpts = append(remoteOpts, remote.WithAuth(&auth))
+	case dockerOpt.RegistryToken != "":
+		remoteOpts = append(remoteOpts, remote.WithAuth(&authn.Bearer{Token: dockerOpt.RegistryToken}))
+	default:
+		remoteOpts = append(remoteOpts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	}
+
+	img, err := remote.Image(ref, remoteOpts...)
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("unable to get the image: %w", err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("unable to get the manifest: %w", err)
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return imageSize{}, xerrors.Errorf("unable to get the config: %w", err)
+	}
+
+	// The history has the empty layers too, which have no layers in the manifest
+	var createdBy []string
+	for _, h := range configFile.History {
+		if !h.EmptyLayer {
+			createdBy = append(createdBy, h.CreatedBy)
+		}
+	}
+
+	var size imageSize
+	for i, l := range manifest.Layers {
+		size.total += l.Size
+		layer := layerSize{size: l.Size}
+		if i < len(createdBy) {
+			layer.createdBy = createdBy[i]
+		}
+		size.layers = append(size.layers, layer)
+	}
+	return size, nil
+}
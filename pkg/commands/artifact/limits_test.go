@@ -0,0 +1,56 @@
+package artifact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_imageSize_check(t *testing.T) {
+	size := imageSize{
+		total: 12 << 30,
+		layers: []layerSize{
+			{createdBy: "/bin/sh -c #(nop) ADD file:1d9a1b2ef4c0a7c8e0 in / ", size: 80 << 20},
+			{createdBy: "/bin/sh -c pip install torch", size: 9 << 30},
+			{createdBy: "COPY models/ /models # buildkit", size: 3 << 30},
+			{createdBy: "/bin/sh -c apt-get update", size: 20 << 20},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		size    imageSize
+		max     int64
+		wantErr string
+	}{
+		{
+			name: "within the limit",
+			size: size,
+			max:  12 << 30,
+		},
+		{
+			name: "too large",
+			size: size,
+			max:  10 << 30,
+			wantErr: "the image is 12GiB, larger than 10GiB, the largest layers: 9GiB: pip install torch; " +
+				"3GiB: COPY models/ /models # buildkit; 80MiB: ADD file:1d9a1b2ef4c0a7c8e0 in /. " +
+				"Slim the image or raise '--max-image-size'",
+		},
+		{
+			name:    "no layers",
+			size:    imageSize{total: 2 << 30},
+			max:     1 << 30,
+			wantErr: "the image is 2GiB, larger than 1GiB. Slim the image or raise '--max-image-size'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.size.check(tt.max)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
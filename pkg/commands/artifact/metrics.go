@@ -0,0 +1,53 @@
+package artifact
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/history"
+)
+
+// MetricsRun shows the mean time to remediate and the age of open findings recorded with --history-dir
+func MetricsRun(cliCtx *cli.Context) error {
+	gc, err := option.NewGlobalOption(cliCtx)
+	if err != nil {
+		return xerrors.Errorf("option error: %w", err)
+	}
+
+	opt := option.NewReportOption(cliCtx)
+	if err = opt.Init(cliCtx.App.Writer, gc.Logger); err != nil {
+		return xerrors.Errorf("option initialize error: %w", err)
+	}
+	if opt.HistoryDir == "" {
+		return xerrors.New("'--history-dir' must be specified")
+	}
+
+	histories, err := history.NewStore(opt.HistoryDir).List()
+	if err != nil {
+		return xerrors.Errorf("history error: %w", err)
+	}
+
+	// Only the given targets are counted
+	if targets := cliCtx.Args().Slice(); len(targets) > 0 {
+		var filtered []history.TargetHistory
+		for _, h := range histories {
+			if slices.Contains(targets, h.Target) {
+				filtered = append(filtered, h)
+			}
+		}
+		if len(filtered) < len(targets) {
+			gc.Logger.Warn("Some targets have never been scanned with '--history-dir'")
+		}
+		histories = filtered
+	}
+
+	m := history.Compute(histories, opt.Severities, time.Now())
+	if err = history.WriteMetrics(opt.Output, m, opt.Format); err != nil {
+		return xerrors.Errorf("unable to write the metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package artifact
+
+import (
+	"context"
+	"net"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+)
+
+// errNetworkDisabled is returned by the outbound connections with '--offline-scan'
+var errNetworkDisabled = xerrors.New("network access is disabled by '--offline-scan'")
+
+// initOfflineScan fails fast for the options requiring the network with '--offline-scan',
+// and skips the DB update.
+func (c *Option) initOfflineScan() error {
+	if !c.OfflineScan {
+		return nil
+	}
+
+	switch {
+	case c.DownloadDBOnly:
+		return xerrors.New("'--offline-scan' can't be used with '--download-db-only'")
+	case c.Context.Command.Name == "repository":
+		return xerrors.New("'--offline-scan' can't be used for repositories as they are cloned")
+	case c.PullViaServer || c.ServerSidePull:
+		return xerrors.New("'--offline-scan' can't be used with '--pull-via-server' or '--server-side-pull' as the server pulls the image")
+	case c.Store != "":
+		return xerrors.New("'--offline-scan' can't be used with '--store'")
+	case c.WebhookURL != "":
+		return xerrors.New("'--offline-scan' can't be used with '--webhook-url'")
+	case c.AttestUpload || (c.Attest && c.AttestKey == ""):
+		return xerrors.New("'--offline-scan' can't be used with '--attest-upload' or keyless attestations")
+	case len(c.SbomSources) > 0:
+		return xerrors.New("'--offline-scan' can't be used with '--sbom-sources'")
+	}
+
+	c.SkipDBUpdate = true
+	c.SkipPolicyUpdate = true
+	return nil
+}
+
+// requireLocalDB returns an error unless the DB has been downloaded, as '--offline-scan' never downloads it
+func requireLocalDB(cacheDir string) error {
+	if _, err := metadata.NewClient(cacheDir).Get(); err != nil {
+		return xerrors.Errorf("the vulnerability DB is not found in %s, download it in advance with '--download-db-only' to scan with '--offline-scan'", cacheDir)
+	}
+	return nil
+}
+
+// dialDisabled fails the connections of the registry transport with '--offline-scan',
+// so that no image missing in the Docker Engine or Podman is pulled.
+// The server in client/server mode is still reachable as the client has its own transport.
+func dialDisabled(context.Context, string, string) (net.Conn, error) {
+	return nil, errNetworkDisabled
+}
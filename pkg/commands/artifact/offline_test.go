@@ -0,0 +1,48 @@
+package artifact
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-db/pkg/db"
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+)
+
+func Test_registryTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	assert.Nil(t, registryTransport(Option{}))
+
+	opt := Option{}
+	opt.OfflineScan = true
+	_, err := (&http.Client{Transport: registryTransport(opt)}).Get(ts.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errNetworkDisabled)
+
+	opt = Option{}
+	opt.Insecure = true
+	transport, ok := registryTransport(opt).(*http.Transport)
+	require.True(t, ok)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+
+	// The default transports are never changed, as the concurrent scans share them
+	_, err = (&http.Client{Transport: remote.DefaultTransport}).Get(ts.URL)
+	assert.NoError(t, err)
+	if remote.DefaultTransport.TLSClientConfig != nil {
+		assert.False(t, remote.DefaultTransport.TLSClientConfig.InsecureSkipVerify)
+	}
+}
+
+func Test_requireLocalDB(t *testing.T) {
+	cacheDir := t.TempDir()
+	assert.ErrorContains(t, requireLocalDB(cacheDir), "download it in advance with '--download-db-only'")
+
+	require.NoError(t, metadata.NewClient(cacheDir).Update(metadata.Metadata{Version: db.SchemaVersion}))
+	assert.NoError(t, requireLocalDB(cacheDir))
+}
@@ -2,10 +2,12 @@ package artifact
 
 import (
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/fanal/analyzer"
 	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 // Option holds the artifact options
@@ -20,7 +22,9 @@ type Option struct {
 	option.RemoteOption
 	option.SbomOption
 	option.SecretOption
+	option.LicenseOption
 	option.KubernetesOption
+	option.AWSOption
 
 	// We don't want to allow disabled analyzers to be passed by users,
 	// but it differs depending on scanning modes.
@@ -45,7 +49,9 @@ func NewOption(c *cli.Context) (Option, error) {
 		RemoteOption:     option.NewRemoteOption(c),
 		SbomOption:       option.NewSbomOption(c),
 		SecretOption:     option.NewSecretOption(c),
+		LicenseOption:    option.NewLicenseOption(c),
 		KubernetesOption: option.NewKubernetesOption(c),
+		AWSOption:        option.NewAWSOption(c),
 	}, nil
 }
 
@@ -79,7 +85,38 @@ func (c *Option) initPreScanOptions() error {
 	if err := c.SbomOption.Init(c.Context, c.Logger); err != nil {
 		return err
 	}
-	c.RemoteOption.Init(c.Logger)
+	if err := c.initAttest(); err != nil {
+		return err
+	}
+	if err := c.ImageOption.Init(); err != nil {
+		return err
+	}
+	if err := c.RemoteOption.Init(c.Logger); err != nil {
+		return err
+	}
+	// The dependency graph is not sent to the server
+	if c.DependencyTree && c.RemoteAddr != "" {
+		c.Logger.Warn("'--dependency-tree' is ignored in client/server mode")
+	}
+	// The scope of the dependencies is not sent to the server
+	if c.ExcludeDevDeps && c.RemoteAddr != "" {
+		c.Logger.Warn("'--exclude-dev-deps' is ignored in client/server mode")
+	}
+	// The server detects the vulnerabilities with its own DB
+	if c.OSV && c.RemoteAddr != "" {
+		c.Logger.Warn("'--osv' is ignored in client/server mode")
+	}
+	// The hashes are recorded in the packages listed with '--list-all-pkgs'
+	if len(c.SbomHashes) > 0 && !c.ListAllPkgs {
+		c.Logger.Warn("'--sbom-hashes' is ignored because '--list-all-pkgs' is not specified")
+	}
+	// The licenses are not sent back from the server
+	if slices.Contains(c.SecurityChecks, types.SecurityCheckLicense) && c.RemoteAddr != "" {
+		c.Logger.Warn("'--security-checks license' is ignored in client/server mode")
+	}
+	if err := c.initOfflineScan(); err != nil {
+		return err
+	}
 	return nil
 }
 
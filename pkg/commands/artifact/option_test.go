@@ -27,7 +27,7 @@ func TestOption_Init(t *testing.T) {
 	}{
 		{
 			name: "happy path",
-			args: []string{"--severity", "CRITICAL", "--vuln-type", "os", "--quiet", "alpine:3.10"},
+			args: []string{"--severity", "CRITICAL", "--pkg-types", "os", "--quiet", "alpine:3.10"},
 			want: Option{
 				GlobalOption: option.GlobalOption{
 					Quiet: true,
@@ -232,6 +232,68 @@ func TestOption_Init(t *testing.T) {
 			args:    []string{"--skip-db-update", "--download-db-only", "alpine:3.10"},
 			wantErr: "--skip-db-update and --download-db-only options can not be specified both",
 		},
+		{
+			name:    "sad: client cert without client key",
+			args:    []string{"--server", "http://localhost:8080", "--client-cert", "client.crt", "alpine:3.11"},
+			wantErr: "both '--client-cert' and '--client-key' must be specified",
+		},
+		{
+			name: "happy path: offline scan",
+			args: []string{"--offline-scan", "alpine:3.11"},
+			want: Option{
+				DBOption: option.DBOption{
+					SkipDBUpdate: true,
+				},
+				ConfigOption: option.ConfigOption{
+					SkipPolicyUpdate: true,
+				},
+				ReportOption: option.ReportOption{
+					Severities:     []dbTypes.Severity{dbTypes.SeverityCritical},
+					Output:         os.Stdout,
+					VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+					SecurityChecks: []string{types.SecurityCheckVulnerability},
+				},
+				ArtifactOption: option.ArtifactOption{
+					Target:      "alpine:3.11",
+					OfflineScan: true,
+				},
+			},
+		},
+		{
+			name:    "sad: offline scan with webhook",
+			args:    []string{"--offline-scan", "--webhook-url", "https://hooks.example.com/trivy", "alpine:3.11"},
+			wantErr: "'--offline-scan' can't be used with '--webhook-url'",
+		},
+		{
+			name:    "sad: negative server retries",
+			args:    []string{"--server", "http://localhost:8080", "--server-retries", "-1", "alpine:3.11"},
+			wantErr: "'--server-timeout' and '--server-retries' must not be negative",
+		},
+		{
+			name:    "sad: keyless attestation without upload",
+			args:    []string{"--attest", "alpine:3.11"},
+			wantErr: "keyless attestations are attached to the image by cosign, specify '--attest-upload' or '--attest-key'",
+		},
+		{
+			name:    "sad: attestation output without key",
+			args:    []string{"--attest", "--attest-upload", "--attest-output", "attestation.json", "alpine:3.11"},
+			wantErr: "'--attest-output' can be used only with '--attest-key'",
+		},
+		{
+			name:    "sad: offline scan with keyless attestation",
+			args:    []string{"--offline-scan", "--attest", "--attest-upload", "alpine:3.11"},
+			wantErr: "'--offline-scan' can't be used with '--attest-upload' or keyless attestations",
+		},
+		{
+			name:    "sad: unknown SBOM source",
+			args:    []string{"--sbom-sources", "oci,foo", "alpine:3.11"},
+			wantErr: `unknown SBOM source "foo"`,
+		},
+		{
+			name:    "sad: SBOM attestations without key",
+			args:    []string{"--sbom-sources", "oci,rekor", "alpine:3.11"},
+			wantErr: "'--sbom-sources' requires '--sbom-attestation-key' to verify the SBOM attestations",
+		},
 		{
 			name: "sad: multiple image names",
 			args: []string{"centos:7", "alpine:3.10"},
@@ -254,7 +316,7 @@ func TestOption_Init(t *testing.T) {
 			set.Bool("skip-db-update", false, "")
 			set.Bool("download-db-only", false, "")
 			set.String("severity", "CRITICAL", "")
-			set.String("vuln-type", "os,library", "")
+			set.String("pkg-types", "os,library", "")
 			set.String("security-checks", "vuln", "")
 			set.String("template", "", "")
 			set.String("format", "", "")
@@ -262,6 +324,19 @@ func TestOption_Init(t *testing.T) {
 			set.String("token", "", "")
 			set.String("token-header", option.DefaultTokenHeader, "")
 			set.Var(&cli.StringSlice{}, "custom-headers", "")
+			set.String("client-cert", "", "")
+			set.String("client-key", "", "")
+			set.Duration("server-timeout", 0, "")
+			set.Int("server-retries", 0, "")
+			set.Bool("offline-scan", false, "")
+			set.String("webhook-url", "", "")
+			set.Bool("attest", false, "")
+			set.String("attest-key", "", "")
+			set.String("attest-output", "", "")
+			set.Bool("attest-upload", false, "")
+			set.String("sbom-sources", "", "")
+			set.String("sbom-attestation-key", "", "")
+			set.String("rekor-url", "", "")
 
 			ctx := cli.NewContext(app, set, nil)
 			_ = set.Parse(tt.args)
@@ -0,0 +1,105 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// reportFileExtensions are the extensions of the report files per format, and the others are ".txt"
+var reportFileExtensions = map[string]string{
+	"json":         ".json",
+	"cyclonedx":    ".cdx.json",
+	"spdx":         ".spdx",
+	"spdx-json":    ".spdx.json",
+	"sarif":        ".sarif",
+	"bitbucket":    ".json",
+	"azure-devops": ".json",
+	"cosign-vuln":  ".json",
+
+	"gitlab-dependency-scanning": ".json",
+	"gitlab-codequality":         ".json",
+}
+
+// unsafeFileNameChars are replaced in the file names of the targets, e.g. "/" and ":" of the image names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// reportFiles names the report files of the targets in the output directory
+type reportFiles struct {
+	dir    string
+	format string
+
+	mu    sync.Mutex
+	names map[string]int
+}
+
+func newReportFiles(dir, format string) *reportFiles {
+	return &reportFiles{
+		dir:    dir,
+		format: format,
+		names:  map[string]int{},
+	}
+}
+
+// path returns the file of the target, e.g. "ghcr.io_org_app_1.0.json" of "ghcr.io/org/app:1.0".
+// The targets of the same name, e.g. "./app" and "app", are numbered so that the reports are not overwritten.
+func (f *reportFiles) path(target string) string {
+	name := unsafeFileNameChars.ReplaceAllString(target, "_")
+	if name == "" || name == "." || name == ".." {
+		name = "target"
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.names[name]++
+	if n := f.names[name]; n > 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+
+	ext, ok := reportFileExtensions[f.format]
+	if !ok {
+		ext = ".txt"
+	}
+	return filepath.Join(f.dir, name+ext)
+}
+
+// writeEachReport writes the report of each target to the output directory in the format of the options,
+// so that each target has its own report instead of the combined one
+func writeEachReport(scan scanFunc, dir, format string, startedOn time.Time) (scanFunc, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, xerrors.Errorf("unable to create the output directory: %w", err)
+	}
+	files := newReportFiles(dir, format)
+
+	return func(ctx context.Context, opt Option, artifactType ArtifactType) (types.Report, error) {
+		r, err := scan(ctx, opt, artifactType)
+		if err != nil {
+			return types.Report{}, err
+		}
+
+		path := files.path(opt.Target)
+		f, err := os.Create(path)
+		if err != nil {
+			return types.Report{}, xerrors.Errorf("unable to create the report file: %w", err)
+		}
+		defer f.Close()
+
+		// The ignore decisions are written once for all the targets
+		opt.Output = f
+		opt.VEXOutput = ""
+		if err = writeReport(opt, r, startedOn); err != nil {
+			return types.Report{}, xerrors.Errorf("report error (%s): %w", path, err)
+		}
+		log.Logger.Infof("The report of %s is written to %s", opt.Target, path)
+		return r, nil
+	}, nil
+}
@@ -0,0 +1,87 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_reportFiles_path(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		targets []string
+		want    []string
+	}{
+		{
+			name:    "images",
+			format:  "json",
+			targets: []string{"ghcr.io/org/app:1.0", "alpine@sha256:abc"},
+			want:    []string{"ghcr.io_org_app_1.0.json", "alpine_sha256_abc.json"},
+		},
+		{
+			name:    "same names",
+			format:  "table",
+			targets: []string{"./app", "_app", "."},
+			want:    []string{"._app.txt", "_app.txt", "target.txt"},
+		},
+		{
+			name:    "duplicates",
+			format:  "cyclonedx",
+			targets: []string{"alpine:3.15", "alpine:3.15"},
+			want:    []string{"alpine_3.15.cdx.json", "alpine_3.15-2.cdx.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := newReportFiles("reports", tt.format)
+			var got []string
+			for _, target := range tt.targets {
+				path := files.path(target)
+				assert.Equal(t, "reports", filepath.Dir(path))
+				got = append(got, filepath.Base(path))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_writeEachReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	scan := func(_ context.Context, opt Option, _ ArtifactType) (types.Report, error) {
+		return types.Report{ArtifactName: opt.Target}, nil
+	}
+
+	scan, err := writeEachReport(scan, dir, "json", time.Now())
+	require.NoError(t, err)
+
+	for _, target := range []string{"alpine:3.15", "alpine:3.16"} {
+		opt := Option{
+			ArtifactOption: option.ArtifactOption{Target: target},
+			ReportOption:   option.ReportOption{Format: "json"},
+		}
+		_, err = scan(context.Background(), opt, containerImageArtifact)
+		require.NoError(t, err)
+	}
+
+	for name, want := range map[string]string{
+		"alpine_3.15.json": "alpine:3.15",
+		"alpine_3.16.json": "alpine:3.16",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+
+		var report types.Report
+		require.NoError(t, json.Unmarshal(b, &report))
+		assert.Equal(t, want, report.ArtifactName)
+	}
+}
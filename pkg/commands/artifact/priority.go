@@ -0,0 +1,126 @@
+package artifact
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const scanOrderNewest = "newest"
+
+// imageMeta holds the metadata of an image used to prioritize it
+type imageMeta struct {
+	Created time.Time
+	Labels  map[string]string
+}
+
+// inspectImageFunc returns the metadata of the image
+type inspectImageFunc func(ctx context.Context, opt Option, imageName string) (imageMeta, error)
+
+// prioritizeTargets orders the targets so that the most important results arrive early.
+// The targets are ordered by "priority" in the list, then by whether they match "--priority-label",
+// then by the creation time if "--scan-order newest" is specified. Otherwise, the order in the list is kept.
+func prioritizeTargets(ctx context.Context, opt Option, targets []ListTarget, inspect inspectImageFunc) []ListTarget {
+	needMeta := len(opt.PriorityLabels) > 0 || opt.ScanOrder == scanOrderNewest
+
+	type entry struct {
+		ListTarget
+		matched bool
+		created time.Time
+	}
+
+	entries := make([]entry, 0, len(targets))
+	for _, t := range targets {
+		e := entry{ListTarget: t}
+		labels := t.Labels
+		if needMeta && t.Type == containerImageArtifact {
+			meta, err := inspect(ctx, opt, t.Target)
+			if err != nil {
+				// The image is still scanned, just without the hints
+				log.Logger.Debugf("Unable to inspect %s for prioritization: %s", t.Target, err)
+			}
+			e.created = meta.Created
+			labels = mergeLabels(meta.Labels, t.Labels)
+		}
+		e.matched = matchLabels(labels, opt.PriorityLabels)
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.matched != b.matched {
+			return a.matched
+		}
+		if opt.ScanOrder == scanOrderNewest {
+			return a.created.After(b.created)
+		}
+		return false
+	})
+
+	ordered := make([]ListTarget, 0, len(entries))
+	for _, e := range entries {
+		ordered = append(ordered, e.ListTarget)
+	}
+	return ordered
+}
+
+// inspectImage returns the metadata from Docker Engine, Podman or the registry in the same way as scanning
+func inspectImage(ctx context.Context, opt Option, imageName string) (imageMeta, error) {
+	dockerOpt, err := types.GetDockerOption()
+	if err != nil {
+		return imageMeta{}, err
+	}
+
+	img, cleanup, err := tartifact.NewDockerImage(ctx, imageName, dockerOpt, tartifact.Option{
+		RegistryTransport: registryTransport(opt),
+		Docker:            dockerDaemon(opt),
+	})
+	if err != nil {
+		return imageMeta{}, xerrors.Errorf("unable to find the image: %w", err)
+	}
+	defer cleanup()
+
+	config, err := img.ConfigFile()
+	if err != nil {
+		return imageMeta{}, xerrors.Errorf("unable to get the config file: %w", err)
+	}
+	return imageMeta{
+		Created: config.Created.Time,
+		Labels:  config.Config.Labels,
+	}, nil
+}
+
+// matchLabels returns whether the labels match any of the selectors.
+// A selector is "key=value" or "key", which matches any value.
+func matchLabels(labels map[string]string, selectors []string) bool {
+	for _, s := range selectors {
+		key, value, hasValue := strings.Cut(s, "=")
+		v, ok := labels[key]
+		if ok && (!hasValue || v == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeLabels returns the image labels overridden by the labels in the list
+func mergeLabels(imageLabels, listLabels map[string]string) map[string]string {
+	labels := map[string]string{}
+	for k, v := range imageLabels {
+		labels[k] = v
+	}
+	for k, v := range listLabels {
+		labels[k] = v
+	}
+	return labels
+}
@@ -0,0 +1,158 @@
+package artifact
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+)
+
+func Test_prioritizeTargets(t *testing.T) {
+	images := map[string]imageMeta{
+		"myapp:1.0": {
+			Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			Labels:  map[string]string{"env": "production"},
+		},
+		"myapp:2.0": {
+			Created: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC),
+			Labels:  map[string]string{"env": "staging"},
+		},
+		"batch:1.0": {
+			Created: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	inspect := func(_ context.Context, _ Option, imageName string) (imageMeta, error) {
+		meta, ok := images[imageName]
+		if !ok {
+			return imageMeta{}, xerrors.New("not found")
+		}
+		return meta, nil
+	}
+
+	targets := []ListTarget{
+		{Target: "unknown:1.0", Type: containerImageArtifact},
+		{Target: "myapp:1.0", Type: containerImageArtifact},
+		{Target: "./app", Type: filesystemArtifact},
+		{Target: "batch:1.0", Type: containerImageArtifact},
+		{Target: "myapp:2.0", Type: containerImageArtifact},
+	}
+
+	tests := []struct {
+		name      string
+		opt       option.ArtifactOption
+		targets   []ListTarget
+		want      []string
+		inspected bool
+	}{
+		{
+			name:    "list order",
+			opt:     option.ArtifactOption{ScanOrder: "list"},
+			targets: targets,
+			want:    []string{"unknown:1.0", "myapp:1.0", "./app", "batch:1.0", "myapp:2.0"},
+		},
+		{
+			name:      "newest",
+			opt:       option.ArtifactOption{ScanOrder: "newest"},
+			targets:   targets,
+			want:      []string{"myapp:2.0", "batch:1.0", "myapp:1.0", "unknown:1.0", "./app"},
+			inspected: true,
+		},
+		{
+			name:      "priority label",
+			opt:       option.ArtifactOption{PriorityLabels: []string{"env=production"}},
+			targets:   targets,
+			want:      []string{"myapp:1.0", "unknown:1.0", "./app", "batch:1.0", "myapp:2.0"},
+			inspected: true,
+		},
+		{
+			name: "priority label in the list and newest",
+			opt: option.ArtifactOption{
+				ScanOrder:      "newest",
+				PriorityLabels: []string{"critical"},
+			},
+			targets: []ListTarget{
+				{Target: "myapp:1.0", Type: containerImageArtifact},
+				{Target: "./app", Type: filesystemArtifact, Labels: map[string]string{"critical": "true"}},
+				{Target: "myapp:2.0", Type: containerImageArtifact},
+			},
+			want:      []string{"./app", "myapp:2.0", "myapp:1.0"},
+			inspected: true,
+		},
+		{
+			name: "priority",
+			opt: option.ArtifactOption{
+				ScanOrder:      "newest",
+				PriorityLabels: []string{"env=production"},
+			},
+			targets: []ListTarget{
+				{Target: "myapp:1.0", Type: containerImageArtifact},
+				{Target: "myapp:2.0", Type: containerImageArtifact},
+				{Target: "batch:1.0", Type: containerImageArtifact, Priority: 10},
+			},
+			want:      []string{"batch:1.0", "myapp:1.0", "myapp:2.0"},
+			inspected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inspected bool
+			f := func(ctx context.Context, opt Option, imageName string) (imageMeta, error) {
+				inspected = true
+				return inspect(ctx, opt, imageName)
+			}
+
+			got := prioritizeTargets(context.Background(), Option{ArtifactOption: tt.opt}, tt.targets, f)
+
+			var gotTargets []string
+			for _, g := range got {
+				gotTargets = append(gotTargets, g.Target)
+			}
+			assert.Equal(t, tt.want, gotTargets)
+			assert.Equal(t, tt.inspected, inspected)
+		})
+	}
+}
+
+func Test_matchLabels(t *testing.T) {
+	labels := map[string]string{
+		"env":  "production",
+		"team": "",
+	}
+	tests := []struct {
+		name      string
+		selectors []string
+		want      bool
+	}{
+		{
+			name:      "key and value",
+			selectors: []string{"env=production"},
+			want:      true,
+		},
+		{
+			name:      "different value",
+			selectors: []string{"env=staging"},
+		},
+		{
+			name:      "key only",
+			selectors: []string{"team"},
+			want:      true,
+		},
+		{
+			name:      "any of selectors",
+			selectors: []string{"tier=frontend", "env=production"},
+			want:      true,
+		},
+		{
+			name: "no selector",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchLabels(labels, tt.selectors))
+		})
+	}
+}
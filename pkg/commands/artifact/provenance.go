@@ -0,0 +1,69 @@
+package artifact
+
+import (
+	"context"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/rpc/client"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// scanMetadata returns the options of the target and the versions of Trivy and the DB recorded in the report.
+// The DB is looked up once for all the targets, as it is not updated during the scan.
+func (r *Runner) scanMetadata(ctx context.Context, opt Option) *types.ScanMetadata {
+	m := &types.ScanMetadata{
+		TrivyVersion:   opt.AppVersion,
+		SecurityChecks: opt.SecurityChecks,
+		VulnType:       opt.VulnType,
+		IgnoreUnfixed:  opt.IgnoreUnfixed,
+		SkipFiles:      opt.SkipFiles,
+		SkipDirs:       opt.SkipDirs,
+	}
+	for _, severity := range opt.Severities {
+		m.Severities = append(m.Severities, severity.String())
+	}
+
+	if slices.Contains(opt.SecurityChecks, types.SecurityCheckVulnerability) {
+		r.dbMetadataOnce.Do(func() {
+			r.dbMetadata = lookupDBMetadata(ctx, opt)
+		})
+		m.DB = r.dbMetadata
+	}
+	return m
+}
+
+// lookupDBMetadata returns the DB in the cache dir, or the DB of the server in client/server mode.
+// The report is written without the DB if it can't be looked up.
+func lookupDBMetadata(ctx context.Context, opt Option) *types.DBMetadata {
+	if opt.RemoteAddr == "" {
+		meta, err := metadata.NewClient(opt.CacheDir).Get()
+		if err != nil {
+			log.Logger.Debugf("DB metadata error: %s", err)
+			return nil
+		}
+		return &types.DBMetadata{
+			Repository: opt.DBRepository,
+			Version:    meta.Version,
+			UpdatedAt:  meta.UpdatedAt.UTC(),
+		}
+	}
+
+	res, err := client.NewVersionClient(remoteScannerOption(opt)).GetVersion(client.WithCustomHeaders(ctx, opt.CustomHeaders), &emptypb.Empty{})
+	if err != nil {
+		log.Logger.Debugf("Unable to get the DB version of the server: %s", err)
+		return nil
+	}
+	meta := rpc.ConvertFromRPCVulnerabilityDB(res.VulnerabilityDb)
+	if meta == nil {
+		return nil
+	}
+	return &types.DBMetadata{
+		Version:   meta.Version,
+		UpdatedAt: meta.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,112 @@
+package artifact
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestRunner_scanMetadata(t *testing.T) {
+	updatedAt := time.Date(2022, 6, 1, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		securityChecks []string
+		withDB         bool
+		want           *types.ScanMetadata
+	}{
+		{
+			name:           "vulnerabilities",
+			securityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckSecret},
+			withDB:         true,
+			want: &types.ScanMetadata{
+				TrivyVersion: "0.30.0",
+				DB: &types.DBMetadata{
+					Repository: "ghcr.io/aquasecurity/trivy-db",
+					Version:    2,
+					UpdatedAt:  updatedAt,
+				},
+				Severities:     []string{"HIGH", "CRITICAL"},
+				SecurityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckSecret},
+				VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+				IgnoreUnfixed:  true,
+				SkipFiles:      []string{"package-lock.json"},
+				SkipDirs:       []string{"node_modules"},
+			},
+		},
+		{
+			name:           "no DB",
+			securityChecks: []string{types.SecurityCheckVulnerability},
+			want: &types.ScanMetadata{
+				TrivyVersion:   "0.30.0",
+				Severities:     []string{"HIGH", "CRITICAL"},
+				SecurityChecks: []string{types.SecurityCheckVulnerability},
+				VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+				IgnoreUnfixed:  true,
+				SkipFiles:      []string{"package-lock.json"},
+				SkipDirs:       []string{"node_modules"},
+			},
+		},
+		{
+			name:           "config only",
+			securityChecks: []string{types.SecurityCheckConfig},
+			withDB:         true,
+			want: &types.ScanMetadata{
+				TrivyVersion:   "0.30.0",
+				Severities:     []string{"HIGH", "CRITICAL"},
+				SecurityChecks: []string{types.SecurityCheckConfig},
+				VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+				IgnoreUnfixed:  true,
+				SkipFiles:      []string{"package-lock.json"},
+				SkipDirs:       []string{"node_modules"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			if tt.withDB {
+				require.NoError(t, metadata.NewClient(cacheDir).Update(metadata.Metadata{
+					Version:   2,
+					UpdatedAt: updatedAt,
+				}))
+			}
+
+			opt := Option{
+				GlobalOption: option.GlobalOption{
+					AppVersion: "0.30.0",
+					CacheDir:   cacheDir,
+				},
+				ArtifactOption: option.ArtifactOption{
+					SkipFiles: []string{"package-lock.json"},
+					SkipDirs:  []string{"node_modules"},
+				},
+				DBOption: option.DBOption{
+					DBRepository: "ghcr.io/aquasecurity/trivy-db",
+				},
+				ReportOption: option.ReportOption{
+					VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+					SecurityChecks: tt.securityChecks,
+					Severities:     []dbTypes.Severity{dbTypes.SeverityHigh, dbTypes.SeverityCritical},
+					IgnoreUnfixed:  true,
+				},
+			}
+
+			r := &Runner{}
+			got := r.scanMetadata(context.Background(), opt)
+			assert.Equal(t, tt.want, got)
+
+			// The DB is looked up once
+			opt.CacheDir = t.TempDir()
+			assert.Equal(t, tt.want, r.scanMetadata(context.Background(), opt))
+		})
+	}
+}
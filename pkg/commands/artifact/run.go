@@ -3,8 +3,14 @@ package artifact
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/exp/slices"
@@ -16,14 +22,31 @@ import (
 	"github.com/aquasecurity/fanal/artifact"
 	"github.com/aquasecurity/fanal/cache"
 	"github.com/aquasecurity/trivy-db/pkg/db"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/buildkit"
 	tcache "github.com/aquasecurity/trivy/pkg/cache"
 	"github.com/aquasecurity/trivy/pkg/commands/operation"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/compliance"
+	"github.com/aquasecurity/trivy/pkg/container"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
+	"github.com/aquasecurity/trivy/pkg/gate"
+	"github.com/aquasecurity/trivy/pkg/licensing"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/module"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
+	"github.com/aquasecurity/trivy/pkg/php"
+	"github.com/aquasecurity/trivy/pkg/progress"
 	pkgReport "github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/result"
+	"github.com/aquasecurity/trivy/pkg/rpc"
 	"github.com/aquasecurity/trivy/pkg/rpc/client"
+	"github.com/aquasecurity/trivy/pkg/ruby"
 	"github.com/aquasecurity/trivy/pkg/scanner"
 	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/unpinned"
 	"github.com/aquasecurity/trivy/pkg/utils"
+	"github.com/aquasecurity/trivy/pkg/workspace"
 )
 
 type ArtifactType string
@@ -34,13 +57,16 @@ const (
 	rootfsArtifact         ArtifactType = "rootfs"
 	repositoryArtifact     ArtifactType = "repo"
 	imageArchiveArtifact   ArtifactType = "archive"
+	buildkitArtifact       ArtifactType = "buildkit"
+	containerArtifact      ArtifactType = "container"
+	cycloneDXArtifact      ArtifactType = "cyclonedx"
 )
 
 var (
 	defaultPolicyNamespaces = []string{"appshield", "defsec", "builtin"}
 
 	supportedArtifactTypes = []ArtifactType{containerImageArtifact, filesystemArtifact, rootfsArtifact,
-		repositoryArtifact, imageArchiveArtifact}
+		repositoryArtifact, imageArchiveArtifact, cycloneDXArtifact}
 
 	SkipScan = errors.New("skip subsequent processes")
 )
@@ -60,12 +86,24 @@ type ScannerConfig struct {
 	RemoteOption client.ScannerOption
 
 	// Artifact options
-	ArtifactOption artifact.Option
+	ArtifactOption tartifact.Option
 }
 
 type Runner struct {
-	cache  cache.Cache
-	dbOpen bool
+	cache     cache.Cache
+	dbOpen    bool
+	workspace *workspace.Workspace
+	module    *module.Manager
+
+	// skipDB is true when only the analysis results are cached, e.g. by "trivy cache warm"
+	skipDB bool
+
+	// startedOn is when the scan started, recorded in the attestations
+	startedOn time.Time
+
+	// dbMetadata is the DB recorded in the reports
+	dbMetadataOnce sync.Once
+	dbMetadata     *types.DBMetadata
 }
 
 type runnerOption func(*Runner)
@@ -77,27 +115,64 @@ func WithCacheClient(c cache.Cache) runnerOption {
 	}
 }
 
+// withoutDB skips the vulnerability database, as nothing is detected
+func withoutDB() runnerOption {
+	return func(r *Runner) {
+		r.skipDB = true
+	}
+}
+
 // NewRunner initializes Runner that provides scanning functionalities.
 // It is possible to return SkipScan and it must be handled by caller.
 func NewRunner(cliOption Option, opts ...runnerOption) (*Runner, error) {
-	r := &Runner{}
-	for _, opt := range opts {
-		opt(r)
-	}
-
 	err := log.InitLogger(cliOption.Debug, cliOption.Quiet)
 	if err != nil {
 		return nil, xerrors.Errorf("logger error: %w", err)
 	}
 
-	if err = r.initCache(cliOption); err != nil {
+	if cliOption.Progress == progress.FormatJSON {
+		progress.SetWriter(os.Stderr)
+	}
+
+	r, err := newRunner(cliOption, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.workspace.RemoveOnSignal()
+
+	if r.module, err = module.NewManager(context.Background(), cliOption.ModuleDir); err != nil {
+		return nil, xerrors.Errorf("WASM module error: %w", err)
+	}
+	r.module.Register()
+
+	return r, nil
+}
+
+// NewLibraryRunner initializes Runner for the programs embedding Trivy. Unlike NewRunner, the logger, the signals
+// and the environment of the process are left to the program, and the WASM modules are not loaded.
+func NewLibraryRunner(opt Option, opts ...runnerOption) (*Runner, error) {
+	return newRunner(opt, opts...)
+}
+
+// newRunner initializes the cache, the DB and the workspace
+func newRunner(opt Option, opts ...runnerOption) (*Runner, error) {
+	r := &Runner{startedOn: time.Now()}
+	for _, o := range opts {
+		o(r)
+	}
+
+	if err := r.initCache(opt); err != nil {
 		return nil, xerrors.Errorf("cache error: %w", err)
 	}
 
-	if err = r.initDB(cliOption); err != nil {
+	if err := r.initDB(opt); err != nil {
 		return nil, xerrors.Errorf("DB error: %w", err)
 	}
 
+	var err error
+	if r.workspace, err = workspace.New(opt.WorkDir); err != nil {
+		return nil, xerrors.Errorf("workspace error: %w", err)
+	}
 	return r, nil
 }
 
@@ -113,49 +188,180 @@ func (r *Runner) Close() error {
 			errs = multierror.Append(errs, err)
 		}
 	}
+
+	if r.workspace != nil {
+		r.workspace.Cleanup()
+	}
+
+	if r.module != nil {
+		if err := r.module.Close(context.Background()); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
 	return errs
 }
 
+// tempDir returns the workspace where the artifacts place their temporary files
+func (r *Runner) tempDir() string {
+	if r.workspace == nil {
+		return ""
+	}
+	return r.workspace.Dir()
+}
+
 func (r *Runner) ScanImage(ctx context.Context, opt Option) (types.Report, error) {
+	// The image filesystem has been unpacked by another tool
+	if strings.HasPrefix(opt.Input, option.RootfsDirInputPrefix) {
+		return r.scanUnpackedImage(ctx, opt)
+	}
+
 	// Disable the lock file scanning
-	opt.DisabledAnalyzers = analyzer.TypeLockfiles
+	opt.DisabledAnalyzers = lockfileAnalyzers()
+
+	// The server can't read the archive on the client
+	if opt.Input != "" && opt.ServerSidePull {
+		return types.Report{}, xerrors.New("'--server-side-pull' can't be used with '--input'")
+	}
 
 	var s InitializeScanner
 	switch {
 	case opt.Input != "" && opt.RemoteAddr == "":
 		// Scan image tarball in standalone mode
 		s = archiveStandaloneScanner
+	case opt.Input != "" && opt.RemoteAddr != "" && opt.ServerSideAnalysis:
+		// Scan image tarball analyzed on the server in client/server mode
+		s = archiveServerSideScanner
 	case opt.Input != "" && opt.RemoteAddr != "":
 		// Scan image tarball in client/server mode
 		s = archiveRemoteScanner
 	case opt.Input == "" && opt.RemoteAddr == "":
 		// Scan container image in standalone mode
 		s = imageStandaloneScanner
+	case opt.Input == "" && opt.RemoteAddr != "" && opt.ServerSidePull:
+		// Scan container image pulled and analyzed by the server in client/server mode
+		s = imageServerSidePullScanner
+	case opt.Input == "" && opt.RemoteAddr != "" && opt.ServerSideAnalysis:
+		// Scan container image analyzed on the server in client/server mode
+		s = imageServerSideScanner
+	case opt.Input == "" && opt.RemoteAddr != "" && opt.PullViaServer:
+		// Scan container image pulled through the server in client/server mode
+		s = imageProxyScanner
 	case opt.Input == "" && opt.RemoteAddr != "":
 		// Scan container image in client/server mode
 		s = imageRemoteScanner
 	}
 
+	// Refuse the references which can be moved to another image between the scan and the deployment
+	if opt.Input == "" && opt.RequireDigest {
+		if err := requireDigest(opt.Target); err != nil {
+			return types.Report{}, xerrors.Errorf("--require-digest error: %w", err)
+		}
+	}
+
+	// Scan the packages listed by the SBOM attested to the image instead of pulling the layers
+	if sbom, ok := findSBOM(ctx, opt); ok {
+		s = sbomStandaloneScanner(sbom)
+		if opt.RemoteAddr != "" {
+			s = sbomRemoteScanner(sbom)
+		}
+	} else {
+		// Refuse the images too large to scan in a reasonable time before pulling the layers
+		if opt.MaxImageSize > 0 {
+			if err := checkImageSize(ctx, opt); err != nil {
+				return types.Report{}, xerrors.Errorf("--max-image-size error: %w", err)
+			}
+		}
+
+		// Check the disk space before saving the image to the workspace
+		if r.workspace != nil {
+			if err := r.workspace.Preflight(estimateImageSize(ctx, opt)); err != nil {
+				return types.Report{}, xerrors.Errorf("preflight error: %w", err)
+			}
+		}
+	}
+
+	report, err := r.Scan(ctx, opt, s)
+	if err != nil {
+		return types.Report{}, err
+	}
+
+	// Link the rebuilt image to the original image so that fixes can be tracked across rebuilds
+	report.Metadata.RebuildOf = opt.RebuildOf
+
+	if opt.Input == "" {
+		report.Metadata.ImageReference, report.Metadata.ImageDigest = pinImage(opt.Target, report.Metadata.RepoDigests)
+	}
+
+	return report, nil
+}
+
+// ScanBuildkit scans the image in the BuildKit content store, so that the build can fail before the image is pushed
+func (r *Runner) ScanBuildkit(ctx context.Context, opt Option) (types.Report, error) {
+	// Disable the lock file scanning
+	opt.DisabledAnalyzers = lockfileAnalyzers()
+
+	img, err := buildkit.NewImage(opt.ContentStore, opt.Target)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("unable to open the image: %w", err)
+	}
+
+	s := buildkitStandaloneScanner(img)
+	if opt.RemoteAddr != "" {
+		s = buildkitRemoteScanner(img)
+	}
+
+	report, err := r.Scan(ctx, opt, s)
+	if err != nil {
+		return types.Report{}, err
+	}
+
+	report.Metadata.RebuildOf = opt.RebuildOf
+	report.Metadata.ImageReference, report.Metadata.ImageDigest = pinImage(img.Name(), report.Metadata.RepoDigests)
+
+	return report, nil
+}
+
+// ScanContainer scans the running container with the changes made after it started
+func (r *Runner) ScanContainer(ctx context.Context, opt Option) (types.Report, error) {
+	// Disable the lock file scanning
+	opt.DisabledAnalyzers = lockfileAnalyzers()
+
+	img, cleanup, err := container.NewImage(ctx, dockerDaemon(opt), r.tempDir(), opt.Target, opt.IncludeMounts)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("unable to open the container: %w", err)
+	}
+	defer cleanup()
+
+	s := containerStandaloneScanner(img)
+	if opt.RemoteAddr != "" {
+		s = containerRemoteScanner(img)
+	}
 	return r.Scan(ctx, opt, s)
 }
 
 func (r *Runner) ScanFilesystem(ctx context.Context, opt Option) (types.Report, error) {
 	// Disable the individual package scanning
-	opt.DisabledAnalyzers = append(opt.DisabledAnalyzers, analyzer.TypeIndividualPkgs...)
+	opt.DisabledAnalyzers = append(opt.DisabledAnalyzers, individualPkgAnalyzers()...)
 
 	return r.scanFS(ctx, opt)
 }
 
 func (r *Runner) ScanRootfs(ctx context.Context, opt Option) (types.Report, error) {
 	// Disable the lock file scanning
-	opt.DisabledAnalyzers = append(opt.DisabledAnalyzers, analyzer.TypeLockfiles...)
+	opt.DisabledAnalyzers = append(opt.DisabledAnalyzers, lockfileAnalyzers()...)
 
 	return r.scanFS(ctx, opt)
 }
 
 func (r *Runner) scanFS(ctx context.Context, opt Option) (types.Report, error) {
 	var s InitializeScanner
-	if opt.RemoteAddr == "" {
+	if opt.Remote != "" {
+		// Scan the filesystem of the remote host over SSH
+		s = sshStandaloneScanner
+		if opt.RemoteAddr != "" {
+			s = sshRemoteScanner
+		}
+	} else if opt.RemoteAddr == "" {
 		// Scan filesystem in standalone mode
 		s = filesystemStandaloneScanner
 	} else {
@@ -171,48 +377,123 @@ func (r *Runner) ScanRepository(ctx context.Context, opt Option) (types.Report,
 	opt.VulnType = []string{types.VulnTypeLibrary}
 
 	// Disable the OS analyzers and individual package analyzers
-	opt.DisabledAnalyzers = append(analyzer.TypeIndividualPkgs, analyzer.TypeOSes...)
+	opt.DisabledAnalyzers = append(individualPkgAnalyzers(), analyzer.TypeOSes...)
 
 	return r.Scan(ctx, opt, repositoryStandaloneScanner)
 }
 
 func (r *Runner) Scan(ctx context.Context, opt Option, initializeScanner InitializeScanner) (types.Report, error) {
-	report, err := scan(ctx, opt, initializeScanner, r.cache)
+	report, err := scan(ctx, opt, initializeScanner, r.cache, r.tempDir())
 	if err != nil {
 		return types.Report{}, xerrors.Errorf("scan error: %w", err)
 	}
 
+	if r.module == nil {
+		return report, nil
+	}
+	if report.Results, err = r.module.PostScan(ctx, report.Results); err != nil {
+		return types.Report{}, xerrors.Errorf("post scan error: %w", err)
+	}
+
 	return report, nil
 }
 
 func (r *Runner) Filter(ctx context.Context, opt Option, report types.Report) (types.Report, error) {
-	resultClient := initializeResultClient()
+	// Fill vulnerability info only in standalone mode
+	return filterReport(ctx, opt, report, opt.RemoteAddr == "")
+}
+
+// filterReport applies the filtering options to the results, filling the vulnerability info from the DB if fillInfo is true
+func filterReport(ctx context.Context, opt Option, report types.Report, fillInfo bool) (types.Report, error) {
+	ignoreConf, err := result.ParseIgnoreFile(opt.IgnoreFile)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("unable to parse the ignore file: %w", err)
+	}
+	slaConf, err := result.ParseSLAFile(opt.SLAFile)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("unable to parse the SLA file: %w", err)
+	}
+	secretSeverities, err := result.ParseSecretConfig(opt.SecretConfigPath)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("unable to parse the secret config: %w", err)
+	}
+	licenseConf, err := licensing.LoadConfig(opt.LicenseConfigPath)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("unable to load the license config: %w", err)
+	}
+	licenseClassifier, err := licensing.NewClassifier(licenseConf)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("invalid license config: %w", err)
+	}
+
+	resultClient := initializeResultClient().WithLocale(opt.Locale)
 	results := report.Results
 	for i := range results {
-		// Fill vulnerability info only in standalone mode
-		if opt.RemoteAddr == "" {
+		if fillInfo {
 			resultClient.FillVulnerabilityInfo(results[i].Vulnerabilities, results[i].Type)
 		}
-		vulns, misconfSummary, misconfs, secrets, err := resultClient.Filter(ctx, results[i].Vulnerabilities, results[i].Misconfigurations, results[i].Secrets,
-			opt.Severities, opt.IgnoreUnfixed, opt.IncludeNonFailures, opt.IgnoreFile, opt.IgnorePolicy)
+		results[i], err = resultClient.Filter(ctx, results[i], result.FilterOption{
+			Severities:         opt.Severities,
+			IgnoreUnfixed:      opt.IgnoreUnfixed,
+			IncludeNonFailures: opt.IncludeNonFailures,
+			IgnoreConfig:       ignoreConf,
+			PolicyFile:         opt.IgnorePolicy,
+			SLA:                slaConf,
+			OnlyOverdue:        opt.OnlyOverdue,
+			AgeFilter:          opt.AgeFilter,
+			SecretSeverities:   secretSeverities,
+			LicenseClassifier:  licenseClassifier,
+		})
 		if err != nil {
 			return types.Report{}, xerrors.Errorf("unable to filter vulnerabilities: %w", err)
 		}
-		results[i].Vulnerabilities = vulns
-		results[i].Misconfigurations = misconfs
-		results[i].MisconfSummary = misconfSummary
-		results[i].Secrets = secrets
 	}
 	return report, nil
 }
 
 func (r *Runner) Report(opt Option, report types.Report) error {
+	return writeReport(opt, report, r.startedOn)
+}
+
+func writeReport(opt Option, report types.Report, startedOn time.Time) error {
+	if opt.VEXOutput != "" {
+		if err := writeVEX(opt, report); err != nil {
+			return xerrors.Errorf("vex error: %w", err)
+		}
+	}
+
+	// The compliance report replaces the findings with the status of the controls
+	if opt.Compliance != "" {
+		complianceReport := compliance.BuildReport(opt.ComplianceSpec, report.Results, opt.SecurityChecks)
+		if err := compliance.Write(complianceReport, compliance.Option{
+			Format: opt.Format,
+			Output: opt.Output,
+		}); err != nil {
+			return xerrors.Errorf("unable to write the compliance report: %w", err)
+		}
+		return nil
+	}
+
+	// The CycloneDX SBOM scanned is written with the vulnerabilities instead of generating a new one
+	if ArtifactType(opt.SbomOption.ArtifactType) == cycloneDXArtifact && opt.Format == "cyclonedx" {
+		if err := writeEnrichedBOM(opt, report); err != nil {
+			return xerrors.Errorf("unable to write the enriched SBOM: %w", err)
+		}
+		return nil
+	}
+
 	if err := pkgReport.Write(report, pkgReport.Option{
 		AppVersion:         opt.GlobalOption.AppVersion,
 		Format:             opt.Format,
+		SchemaVersion:      opt.SchemaVersion,
+		ScanStartedOn:      startedOn,
 		Output:             opt.Output,
 		Severities:         opt.Severities,
 		OutputTemplate:     opt.Template,
+		GroupBy:            opt.GroupBy,
+		Report:             opt.Report,
+		Deterministic:      opt.Deterministic,
+		HashAlgorithms:     opt.SbomHashes,
 		IncludeNonFailures: opt.IncludeNonFailures,
 		Trace:              opt.Trace,
 	}); err != nil {
@@ -223,14 +504,25 @@ func (r *Runner) Report(opt Option, report types.Report) error {
 }
 
 func (r *Runner) initDB(c Option) error {
+	if r.skipDB {
+		return nil
+	}
+
 	// When scanning config files or running as client mode, it doesn't need to download the vulnerability database.
 	if c.RemoteAddr != "" || !slices.Contains(c.SecurityChecks, types.SecurityCheckVulnerability) {
 		return nil
 	}
 
+	// The clear error is returned rather than the one of '--skip-db-update'
+	if c.OfflineScan {
+		if err := requireLocalDB(c.CacheDir); err != nil {
+			return err
+		}
+	}
+
 	// download the database file
 	noProgress := c.Quiet || c.NoProgress
-	if err := operation.DownloadDB(c.AppVersion, c.CacheDir, c.DBRepository, noProgress, c.SkipDBUpdate); err != nil {
+	if err := operation.DownloadDB(c.AppVersion, c.CacheDir, c.DBRepository, noProgress, c.SkipDBUpdate, c.DBRootCAs); err != nil {
 		return err
 	}
 
@@ -254,7 +546,7 @@ func (r *Runner) initCache(c Option) error {
 
 	// client/server mode
 	if c.RemoteAddr != "" {
-		remoteCache := tcache.NewRemoteCache(c.RemoteAddr, c.CustomHeaders, c.Insecure)
+		remoteCache := tcache.NewRemoteCache(remoteScannerOption(c))
 		r.cache = tcache.NopCache(remoteCache)
 		return nil
 	}
@@ -297,15 +589,23 @@ func Run(cliCtx *cli.Context, artifactType ArtifactType) error {
 }
 
 func run(ctx context.Context, opt Option, artifactType ArtifactType) (err error) {
-	ctx, cancel := context.WithTimeout(ctx, opt.Timeout)
-	defer cancel()
-
 	defer func() {
 		if xerrors.Is(err, context.DeadlineExceeded) {
 			log.Logger.Warn("Increase --timeout value")
 		}
 	}()
 
+	// The ID is added to the JSON logs and sent to the server, so that the logs of the scan can be correlated
+	setScanID(&opt, uuid.NewString())
+
+	// The gate is loaded before scanning so that errors in the gate file are reported early
+	var g gate.Gate
+	if opt.Gate != "" {
+		if g, err = gate.Load(ctx, opt.Gate); err != nil {
+			return xerrors.Errorf("gate error: %w", err)
+		}
+	}
+
 	runner, err := NewRunner(opt)
 	if err != nil {
 		if errors.Is(err, SkipScan) {
@@ -315,38 +615,131 @@ func run(ctx context.Context, opt Option, artifactType ArtifactType) (err error)
 	}
 	defer runner.Close()
 
+	// The scan budget is shared by all the targets while the timeout is applied to each target
+	ctx, cancel := withScanBudget(ctx, opt.ScanBudget)
+	defer cancel()
+
+	var scan scanFunc = func(ctx context.Context, opt Option, artifactType ArtifactType) (types.Report, error) {
+		return scanTarget(ctx, runner, opt, artifactType)
+	}
+	if opt.FallbackToLocal {
+		fallback := &localFallback{}
+		defer fallback.close()
+		scan = fallback.wrap(scan)
+	}
+	if opt.OutputDir != "" {
+		if scan, err = writeEachReport(scan, opt.OutputDir, opt.Format, runner.startedOn); err != nil {
+			return xerrors.Errorf("output dir error: %w", err)
+		}
+	}
+
 	var report types.Report
+	if opt.InputList != "" {
+		if report, err = scanInputList(ctx, opt, artifactType, scan); err != nil {
+			return err
+		}
+	} else {
+		report, err = scan(ctx, opt, artifactType)
+		if err != nil && budgetExhausted(ctx) {
+			target := opt.Target
+			if target == "" {
+				target = opt.Input
+			}
+			log.Logger.Warnf("The scan budget (%s) was exhausted before %s was scanned", opt.ScanBudget, target)
+			report, err = types.Report{
+				SchemaVersion: pkgReport.SchemaVersion,
+				ArtifactName:  target,
+				Results:       types.Results{notScannedResult(target)},
+			}, nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The combined report of the input list and the report of the target not scanned have the options of the scan
+	if report.Metadata.Scan == nil {
+		report.Metadata.Scan = runner.scanMetadata(ctx, opt)
+	}
+
+	// The reports of the targets are already written to the output directory
+	if opt.OutputDir != "" && opt.VEXOutput != "" {
+		if err = writeVEX(opt, report); err != nil {
+			return xerrors.Errorf("vex error: %w", err)
+		}
+	} else if opt.OutputDir == "" {
+		if err = runner.Report(opt, report); err != nil {
+			return xerrors.Errorf("report error: %w", err)
+		}
+	}
+
+	// The attestation records the report whether the scan fails or not
+	if opt.Attest {
+		if err = attest(ctx, opt, report, runner.startedOn); err != nil {
+			return xerrors.Errorf("attestation error: %w", err)
+		}
+	}
+
+	failed := report.Results.FailedBy(opt.FailCondition())
+	if g != nil {
+		if failed, err = evaluateGate(ctx, g, opt.Gate, report); err != nil {
+			return xerrors.Errorf("gate error: %w", err)
+		}
+	}
+	Exit(opt, failed)
+
+	return nil
+}
+
+// evaluateGate returns whether the report failed the gate, showing the reasons
+func evaluateGate(ctx context.Context, g gate.Gate, gateFile string, report types.Report) (bool, error) {
+	res, err := g.Evaluate(ctx, report)
+	if err != nil {
+		return false, err
+	}
+	if res.Passed {
+		log.Logger.Infof("The report passed the gate (%s)", gateFile)
+		return false, nil
+	}
+	log.Logger.Errorf("The report failed the gate (%s)", gateFile)
+	for _, msg := range res.Messages {
+		log.Logger.Errorf("  - %s", msg)
+	}
+	return true, nil
+}
+
+func scanArtifact(ctx context.Context, runner *Runner, opt Option, artifactType ArtifactType) (report types.Report, err error) {
 	switch artifactType {
 	case containerImageArtifact, imageArchiveArtifact:
 		if report, err = runner.ScanImage(ctx, opt); err != nil {
-			return xerrors.Errorf("image scan error: %w", err)
+			return types.Report{}, xerrors.Errorf("image scan error: %w", err)
+		}
+	case buildkitArtifact:
+		if report, err = runner.ScanBuildkit(ctx, opt); err != nil {
+			return types.Report{}, xerrors.Errorf("BuildKit image scan error: %w", err)
+		}
+	case containerArtifact:
+		if report, err = runner.ScanContainer(ctx, opt); err != nil {
+			return types.Report{}, xerrors.Errorf("container scan error: %w", err)
 		}
 	case filesystemArtifact:
 		if report, err = runner.ScanFilesystem(ctx, opt); err != nil {
-			return xerrors.Errorf("filesystem scan error: %w", err)
+			return types.Report{}, xerrors.Errorf("filesystem scan error: %w", err)
 		}
 	case rootfsArtifact:
 		if report, err = runner.ScanRootfs(ctx, opt); err != nil {
-			return xerrors.Errorf("rootfs scan error: %w", err)
+			return types.Report{}, xerrors.Errorf("rootfs scan error: %w", err)
 		}
 	case repositoryArtifact:
 		if report, err = runner.ScanRepository(ctx, opt); err != nil {
-			return xerrors.Errorf("repository scan error: %w", err)
+			return types.Report{}, xerrors.Errorf("repository scan error: %w", err)
+		}
+	case cycloneDXArtifact:
+		if report, err = runner.ScanCycloneDX(ctx, opt); err != nil {
+			return types.Report{}, xerrors.Errorf("CycloneDX scan error: %w", err)
 		}
 	}
-
-	report, err = runner.Filter(ctx, opt, report)
-	if err != nil {
-		return xerrors.Errorf("filter error: %w", err)
-	}
-
-	if err = runner.Report(opt, report); err != nil {
-		return xerrors.Errorf("report error: %w", err)
-	}
-
-	Exit(opt, report.Results.Failed())
-
-	return nil
+	return report, nil
 }
 
 func InitOption(ctx *cli.Context) (Option, error) {
@@ -363,19 +756,72 @@ func InitOption(ctx *cli.Context) (Option, error) {
 	return opt, nil
 }
 
+// trivyLanguageAnalyzers are the language analyzers added by Trivy to those of fanal, all of which analyze
+// the lock files or the manifests of the projects
+var trivyLanguageAnalyzers = append([]analyzer.Type{nodejs.TypePnpm, nodejs.TypeManifest, php.TypeManifest, ruby.TypeManifest},
+	unpinned.Types...)
+
+// lockfileAnalyzers returns the lock file analyzers of fanal and Trivy
+func lockfileAnalyzers() []analyzer.Type {
+	return append(slices.Clone(analyzer.TypeLockfiles), trivyLanguageAnalyzers...)
+}
+
+// languageAnalyzers returns the language analyzers of fanal and Trivy, including those of the executables
+func languageAnalyzers() []analyzer.Type {
+	analyzers := append(slices.Clone(analyzer.TypeLanguages), trivyLanguageAnalyzers...)
+	return append(analyzers, fingerprint.TypeStaticLibrary)
+}
+
+// individualPkgAnalyzers returns the analyzers of the installed packages and the executables of fanal and Trivy
+func individualPkgAnalyzers() []analyzer.Type {
+	return append(slices.Clone(analyzer.TypeIndividualPkgs), fingerprint.TypeStaticLibrary)
+}
+
+// parallel returns the number of workers analyzing layers and files, and '--parallel 0' means the number of CPUs
+func parallel(opt Option) int {
+	if opt.Parallel == 0 {
+		return runtime.NumCPU()
+	}
+	return opt.Parallel
+}
+
+// hashAlgorithms returns the algorithms of the hashes of the package files,
+// which are hashed only when the hashes are requested with '--sbom-hashes'
+func hashAlgorithms(opt Option) []string {
+	if !opt.ListAllPkgs || !slices.Contains(opt.VulnType, types.VulnTypeLibrary) {
+		return nil
+	}
+	return opt.SbomHashes
+}
+
 func disabledAnalyzers(opt Option) []analyzer.Type {
 	// Specified analyzers to be disabled depending on scanning modes
 	// e.g. The 'image' subcommand should disable the lock file scanning.
 	analyzers := opt.DisabledAnalyzers
 
-	// It doesn't analyze apk commands by default.
-	if !opt.ScanRemovedPkgs {
+	// It doesn't analyze apk commands by default, and never with '--offline-scan' as the analyzer queries the API of Alpine.
+	if !opt.ScanRemovedPkgs || opt.OfflineScan {
 		analyzers = append(analyzers, analyzer.TypeApkCommand)
 	}
 
 	// Do not analyze programming language packages when not running in 'library' mode
 	if !slices.Contains(opt.VulnType, types.VulnTypeLibrary) {
-		analyzers = append(analyzers, analyzer.TypeLanguages...)
+		analyzers = append(analyzers, languageAnalyzers()...)
+	}
+
+	// The version ranges in the manifests are analyzed only when requested
+	if !opt.DetectUnpinned {
+		analyzers = append(analyzers, unpinned.Types...)
+	}
+
+	// Do not analyze the languages not selected with "lang:<language>"
+	analyzers = append(analyzers, opt.DisabledLanguageAnalyzers()...)
+
+	// The digests of the packages are recorded only to list all the packages
+	if !opt.ListAllPkgs {
+		analyzers = append(analyzers, tartifact.TypeDigests...)
+	} else if !slices.Contains(opt.VulnType, types.VulnTypeLibrary) {
+		analyzers = append(analyzers, tartifact.TypeJarDigest)
 	}
 
 	// Do not perform secret scanning when it is not specified.
@@ -402,56 +848,112 @@ func initScannerConfig(opt Option, cacheClient cache.Cache) (ScannerConfig, type
 		SecurityChecks:      opt.SecurityChecks,
 		ScanRemovedPackages: opt.ScanRemovedPkgs, // this is valid only for image subcommand
 		ListAllPackages:     opt.ListAllPkgs,
+		ESM:                 opt.ESM,
+		DependencyTree:      opt.DependencyTree,
+		ExcludeDevDeps:      opt.ExcludeDevDeps,
+		Locale:              opt.Locale,
+		AnalysisTimeout:     opt.AnalysisTimeout,
+		DetectionTimeout:    opt.DetectionTimeout,
+		OSV: types.OSVOption{
+			Enabled:  opt.OSV,
+			URL:      opt.OSVURL,
+			CacheDir: opt.CacheDir,
+			Offline:  opt.OfflineScan,
+		},
 	}
 	log.Logger.Debugf("Vulnerability type:  %s", scanOptions.VulnType)
 
-	// ScannerOption is filled only when config scanning is enabled.
+	// ScannerOption is filled only when config scanning is enabled, except the file patterns.
 	var configScannerOptions config.ScannerOption
 	if slices.Contains(opt.SecurityChecks, types.SecurityCheckConfig) {
 		configScannerOptions = config.ScannerOption{
-			Trace:        opt.Trace,
-			Namespaces:   append(opt.PolicyNamespaces, defaultPolicyNamespaces...),
-			PolicyPaths:  opt.PolicyPaths,
-			DataPaths:    opt.DataPaths,
-			FilePatterns: opt.FilePatterns,
+			Trace:       opt.Trace,
+			Namespaces:  append(opt.PolicyNamespaces, defaultPolicyNamespaces...),
+			PolicyPaths: opt.PolicyPaths,
+			DataPaths:   opt.DataPaths,
 		}
 	}
+	// The file patterns are used by not only the config analyzers but also the other analyzers
+	configScannerOptions.FilePatterns = opt.FilePatterns
 
 	return ScannerConfig{
 		Target:             target,
 		ArtifactCache:      cacheClient,
 		LocalArtifactCache: cacheClient,
-		RemoteOption: client.ScannerOption{
-			RemoteURL:     opt.RemoteAddr,
-			CustomHeaders: opt.CustomHeaders,
-			Insecure:      opt.Insecure,
-		},
-		ArtifactOption: artifact.Option{
-			DisabledAnalyzers: disabledAnalyzers(opt),
-			SkipFiles:         opt.SkipFiles,
-			SkipDirs:          opt.SkipDirs,
-			InsecureSkipTLS:   opt.Insecure,
-			Offline:           opt.OfflineScan,
-			NoProgress:        opt.NoProgress || opt.Quiet,
-
-			// For misconfiguration scanning
-			MisconfScannerOption: configScannerOptions,
-
-			// For secret scanning
-			SecretScannerOption: secret.ScannerOption{
-				ConfigPath: opt.SecretConfigPath,
+		RemoteOption:       remoteScannerOption(opt),
+		ArtifactOption: tartifact.Option{
+			Option: artifact.Option{
+				DisabledAnalyzers: disabledAnalyzers(opt),
+				SkipFiles:         opt.SkipFiles,
+				SkipDirs:          opt.SkipDirs,
+				InsecureSkipTLS:   opt.Insecure,
+				Offline:           opt.OfflineScan,
+				NoProgress:        opt.NoProgress || opt.Quiet,
+
+				// For misconfiguration scanning
+				MisconfScannerOption: configScannerOptions,
+
+				// For secret scanning
+				SecretScannerOption: secret.ScannerOption{
+					ConfigPath: opt.SecretConfigPath,
+				},
+			},
+			Parallel:         parallel(opt),
+			MaxMemory:        opt.MaxMemory,
+			MaxArchiveDepth:  opt.MaxArchiveDepth,
+			FileTimeout:      opt.FileTimeout,
+			AnalyzerTimeouts: opt.AnalyzerTimeouts,
+			MaxFiles:         opt.MaxFiles,
+			HashAlgorithms:   hashAlgorithms(opt),
+			SSH: tartifact.SSHOption{
+				KeyFile:        opt.SSHKey,
+				KnownHostsFile: opt.SSHKnownHosts,
 			},
+			RegistryTransport: registryTransport(opt),
+			Docker:            dockerDaemon(opt),
+
+			// The client doesn't read the cache of the server
+			NoLayerIndex: opt.RemoteAddr != "",
 		},
 	}, scanOptions, nil
 }
 
-func scan(ctx context.Context, opt Option, initializeScanner InitializeScanner, cacheClient cache.Cache) (
-	types.Report, error) {
+// remoteScannerOption returns the options to communicate with the server in client/server mode
+func remoteScannerOption(opt Option) client.ScannerOption {
+	return client.ScannerOption{
+		RemoteURL:     opt.RemoteAddr,
+		CustomHeaders: opt.CustomHeaders,
+		Insecure:      opt.Insecure,
+		RootCAs:       opt.ServerRootCAs,
+		Certificates:  opt.ClientCertificates,
+		Timeout:       opt.ServerTimeout,
+		Protocol:      opt.ServerProtocol,
+		Retry: rpc.RetryOption{
+			Retries: opt.ServerRetries,
+		},
+	}
+}
+
+// setScanID correlates the logs of the scan by the ID, and sends it to the server in client/server mode
+func setScanID(opt *Option, id string) {
+	log.SetScanID(id)
+	if opt.RemoteAddr == "" {
+		return
+	}
+	if opt.CustomHeaders == nil {
+		opt.CustomHeaders = http.Header{}
+	}
+	opt.CustomHeaders.Set(rpc.ScanIDHeader, id)
+}
+
+func scan(ctx context.Context, opt Option, initializeScanner InitializeScanner, cacheClient cache.Cache,
+	tempDir string) (types.Report, error) {
 
 	scannerConfig, scanOptions, err := initScannerConfig(opt, cacheClient)
 	if err != nil {
 		return types.Report{}, err
 	}
+	scannerConfig.ArtifactOption.TempDir = tempDir
 
 	s, cleanup, err := initializeScanner(ctx, scannerConfig)
 	if err != nil {
@@ -468,6 +970,7 @@ func scan(ctx context.Context, opt Option, initializeScanner InitializeScanner,
 
 func Exit(c Option, failedResults bool) {
 	if c.ExitCode != 0 && failedResults {
+		workspace.CleanupAll()
 		os.Exit(c.ExitCode)
 	}
 }
@@ -1,10 +1,16 @@
 package artifact
 
 import (
+	"context"
+	"os"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
+	"github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/report/cyclonedx"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
@@ -31,3 +37,47 @@ func SbomRun(ctx *cli.Context) error {
 
 	return run(ctx.Context, opt, artifactType)
 }
+
+// ScanCycloneDX scans the packages listed by the CycloneDX SBOM, which may be generated by other tools
+// $ trivy sbom --artifact-type cyclonedx --output enriched.json bom.json
+func (r *Runner) ScanCycloneDX(ctx context.Context, opt Option) (types.Report, error) {
+	bom, err := readCycloneDX(opt.Target)
+	if err != nil {
+		return types.Report{}, err
+	}
+
+	blob, metadata, err := cyclonedx.Decode(bom)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("CycloneDX decode error: %w", err)
+	}
+
+	sbom := artifact.SBOM{Blob: blob, Metadata: metadata}
+	s := sbomStandaloneScanner(sbom)
+	if opt.RemoteAddr != "" {
+		s = sbomRemoteScanner(sbom)
+	}
+	return r.Scan(ctx, opt, s)
+}
+
+// writeEnrichedBOM writes the CycloneDX SBOM scanned with the vulnerabilities appended
+func writeEnrichedBOM(opt Option, report types.Report) error {
+	bom, err := readCycloneDX(opt.Target)
+	if err != nil {
+		return err
+	}
+	return cyclonedx.NewWriter(opt.Output, opt.AppVersion).WriteEnriched(&bom, report)
+}
+
+func readCycloneDX(filePath string) (cdx.BOM, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return cdx.BOM{}, xerrors.Errorf("file open error: %w", err)
+	}
+	defer f.Close()
+
+	var bom cdx.BOM
+	if err = cdx.NewBOMDecoder(f, cdx.BOMFileFormatJSON).Decode(&bom); err != nil {
+		return cdx.BOM{}, xerrors.Errorf("invalid CycloneDX (%s): %w", filePath, err)
+	}
+	return bom, nil
+}
@@ -0,0 +1,221 @@
+package artifact
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"strings"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/attestation"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rekor"
+	"github.com/aquasecurity/trivy/pkg/report/cyclonedx"
+	"github.com/aquasecurity/trivy/pkg/scanner"
+)
+
+// cycloneDXPredicateType is the predicate type of "cosign attest --type cyclonedx"
+const cycloneDXPredicateType = "https://cyclonedx.org/bom"
+
+// sbomStandaloneScanner initializes a scanner of the packages listed by the SBOM of the image in standalone mode
+// $ trivy image --sbom-sources oci --sbom-attestation-key cosign.pub alpine:3.15
+func sbomStandaloneScanner(sbom artifact.SBOM) InitializeScanner {
+	return func(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+		s := initializeSBOMScanner(ctx, conf.Target, sbom, conf.ArtifactCache, conf.LocalArtifactCache)
+		return s, func() {}, nil
+	}
+}
+
+// sbomRemoteScanner initializes a scanner of the packages listed by the SBOM of the image in client/server mode
+// $ trivy image --server localhost:4954 --sbom-sources oci --sbom-attestation-key cosign.pub alpine:3.15
+func sbomRemoteScanner(sbom artifact.SBOM) InitializeScanner {
+	return func(ctx context.Context, conf ScannerConfig) (scanner.Scanner, func(), error) {
+		s := initializeRemoteSBOMScanner(ctx, conf.Target, sbom, conf.ArtifactCache, conf.RemoteOption)
+		return s, func() {}, nil
+	}
+}
+
+// findSBOM returns the packages listed by the SBOM attested to the image, looking up the sources in the order specified.
+// The attestations not signed by the key are ignored, and the layers are analyzed as usual unless the SBOM is found.
+func findSBOM(ctx context.Context, opt Option) (artifact.SBOM, bool) {
+	if opt.Input != "" || len(opt.SbomSources) == 0 {
+		return artifact.SBOM{}, false
+	}
+
+	image, err := resolveDigest(ctx, opt)
+	if err != nil {
+		log.Logger.Debugf("Unable to get the digest of %s: %s", opt.Target, err)
+		return artifact.SBOM{}, false
+	}
+
+	for _, source := range opt.SbomSources {
+		var statements []attestation.Statement
+		switch source {
+		case option.SbomSourceOCI:
+			statements, err = ociStatements(ctx, image, opt.SbomPublicKey)
+		case option.SbomSourceRekor:
+			statements, err = rekorStatements(ctx, image, opt.RekorURL, opt.Insecure, opt.SbomPublicKey)
+		}
+		if err != nil {
+			log.Logger.Debugf("Unable to get the SBOM attestations of %s from %s: %s", image.Name(), source, err)
+			continue
+		} else if len(statements) == 0 {
+			log.Logger.Debugf("No SBOM attestation of %s verified with the key in %s", image.Name(), source)
+			continue
+		}
+
+		for _, statement := range statements {
+			sbom, err := decodeSBOM(statement, image)
+			if err != nil {
+				log.Logger.Debugf("Unable to use the SBOM attestation of %s: %s", image.Name(), err)
+				continue
+			}
+			log.Logger.Infof("Scanning the packages listed by the SBOM attested to %s in %s instead of analyzing the layers",
+				image.Name(), source)
+			return sbom, true
+		}
+	}
+
+	log.Logger.Infof("No SBOM attestation of %s is verified with the key, so the layers are analyzed", image.Name())
+	return artifact.SBOM{}, false
+}
+
+// resolveDigest returns the digest of the image, which is resolved from the registry without pulling the image
+// unless the image is referenced by the digest.
+func resolveDigest(ctx context.Context, opt Option) (name.Digest, error) {
+	var nameOpts []name.Option
+	if opt.Insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	ref, err := name.ParseReference(opt.Target, nameOpts...)
+	if err != nil {
+		return name.Digest{}, xerrors.Errorf("image name parse error: %w", err)
+	}
+	if d, ok := ref.(name.Digest); ok {
+		return d, nil
+	}
+
+	desc, err := remote.Head(ref, remote.WithContext(ctx), registryAuth())
+	if err != nil {
+		return name.Digest{}, xerrors.Errorf("registry error: %w", err)
+	}
+	return ref.Context().Digest(desc.Digest.String()), nil
+}
+
+// ociStatements returns the statements of the SBOM attestations attached to the image and signed by the key
+func ociStatements(ctx context.Context, image name.Digest, key crypto.PublicKey) ([]attestation.Statement, error) {
+	envelopes, err := attestation.Fetch(ctx, image, cycloneDXPredicateType, registryAuth())
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []attestation.Statement
+	for _, envelope := range envelopes {
+		statement, err := envelope.Verify(key)
+		if err != nil {
+			log.Logger.Debugf("Skipping the attestation of %s: %s", image.Name(), err)
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// rekorStatements returns the statements of the SBOM attestations of the image in Rekor signed by the key.
+// Rekor has verified the signatures of the attestations with the keys in the entries.
+func rekorStatements(ctx context.Context, image name.Digest, rekorURL string, insecure bool,
+	key crypto.PublicKey) ([]attestation.Statement, error) {
+	c := rekor.NewClient(rekorURL, insecure)
+	uuids, err := c.Search(ctx, image.DigestStr())
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.GetEntries(ctx, uuids)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []attestation.Statement
+	for _, entry := range entries {
+		// The entries signed with the certificates of keyless signing are not trusted
+		pub, err := attestation.ParsePublicKey(entry.PublicKey)
+		if err != nil || !equalKey(pub, key) {
+			log.Logger.Debugf("Skipping the Rekor entry %s not signed by the key", entry.UUID)
+			continue
+		}
+
+		var statement attestation.Statement
+		if err = json.Unmarshal(entry.Statement, &statement); err != nil {
+			log.Logger.Debugf("Skipping the Rekor entry %s: invalid statement: %s", entry.UUID, err)
+			continue
+		}
+		if statement.PredicateType != cycloneDXPredicateType {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+func equalKey(pub, key crypto.PublicKey) bool {
+	k, ok := pub.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(key)
+}
+
+// decodeSBOM decodes the SBOM in the statement of the image
+func decodeSBOM(statement attestation.Statement, image name.Digest) (artifact.SBOM, error) {
+	// The attestation might be copied from another image
+	if !attested(statement, image) {
+		return artifact.SBOM{}, xerrors.Errorf("the attestation is not of %s", image.DigestStr())
+	}
+
+	// cosign of some versions wraps the predicate, i.e. {"Data": <BOM>, "Timestamp": "..."}
+	predicate := statement.Predicate
+	if m, ok := predicate.(map[string]interface{}); ok {
+		if data, ok := m["Data"]; ok {
+			predicate = data
+		}
+	}
+	var b []byte
+	if s, ok := predicate.(string); ok {
+		b = []byte(s)
+	} else {
+		var err error
+		if b, err = json.Marshal(predicate); err != nil {
+			return artifact.SBOM{}, xerrors.Errorf("failed to marshal the predicate: %w", err)
+		}
+	}
+
+	var bom cdx.BOM
+	if err := json.Unmarshal(b, &bom); err != nil {
+		return artifact.SBOM{}, xerrors.Errorf("invalid CycloneDX: %w", err)
+	}
+
+	blob, metadata, err := cyclonedx.Decode(bom)
+	if err != nil {
+		return artifact.SBOM{}, xerrors.Errorf("CycloneDX decode error: %w", err)
+	}
+
+	// The report is pinned to the digest attested
+	if !slices.Contains(metadata.RepoDigests, image.Name()) {
+		metadata.RepoDigests = append(metadata.RepoDigests, image.Name())
+	}
+	return artifact.SBOM{Blob: blob, Metadata: metadata}, nil
+}
+
+func attested(statement attestation.Statement, image name.Digest) bool {
+	algorithm, hex, _ := strings.Cut(image.DigestStr(), ":")
+	for _, subject := range statement.Subject {
+		if subject.Digest[algorithm] == hex {
+			return true
+		}
+	}
+	return false
+}
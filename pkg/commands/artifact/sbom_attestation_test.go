@@ -0,0 +1,202 @@
+package artifact
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/attestation"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+)
+
+const testBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "components": [
+    {"type": "library", "name": "bash", "version": "5.1-2+deb11u1", "purl": "pkg:deb/debian/bash@5.1-2+deb11u1?distro=debian-11"}
+  ]
+}`
+
+// pushImage pushes a random image with the SBOM attestations signed by the keys
+func pushImage(t *testing.T, ref string, signers ...crypto.Signer) name.Digest {
+	tag, err := name.NewTag(ref)
+	require.NoError(t, err)
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, img))
+
+	d, err := img.Digest()
+	require.NoError(t, err)
+	image := tag.Context().Digest(d.String())
+
+	var bom interface{}
+	require.NoError(t, json.Unmarshal([]byte(testBOM), &bom))
+	for _, signer := range signers {
+		statement, err := attestation.NewImageStatement(image, cycloneDXPredicateType, bom)
+		require.NoError(t, err)
+		envelope, err := attestation.Sign(statement, signer)
+		require.NoError(t, err)
+		require.NoError(t, attestation.Attach(context.Background(), image, envelope, cycloneDXPredicateType))
+	}
+	return image
+}
+
+// rekorServer returns the fake Rekor with the SBOM attestations of the image uploaded with the keys
+func rekorServer(t *testing.T, image name.Digest, keys ...crypto.PublicKey) *httptest.Server {
+	var bom interface{}
+	require.NoError(t, json.Unmarshal([]byte(testBOM), &bom))
+	statement, err := attestation.NewImageStatement(image, cycloneDXPredicateType, bom)
+	require.NoError(t, err)
+	payload, err := json.Marshal(statement)
+	require.NoError(t, err)
+	sum := sha256.Sum256(payload)
+
+	var entries []map[string]interface{}
+	for i, key := range keys {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+		body, err := json.Marshal(map[string]interface{}{
+			"apiVersion": "0.0.1",
+			"kind":       "intoto",
+			"spec": map[string]interface{}{
+				"content": map[string]interface{}{
+					"payloadHash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])},
+				},
+				"publicKey": base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			},
+		})
+		require.NoError(t, err)
+		entries = append(entries, map[string]interface{}{
+			fmt.Sprintf("uuid-%d", i): map[string]interface{}{
+				"body":        base64.StdEncoding.EncodeToString(body),
+				"attestation": map[string]string{"data": base64.StdEncoding.EncodeToString(payload)},
+			},
+		})
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/index/retrieve":
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			uuids := []string{}
+			if req["hash"] == image.DigestStr() {
+				for i := range keys {
+					uuids = append(uuids, fmt.Sprintf("uuid-%d", i))
+				}
+			}
+			_ = json.NewEncoder(w).Encode(uuids)
+		case "/api/v1/log/entries/retrieve":
+			_ = json.NewEncoder(w).Encode(entries)
+		}
+	}))
+}
+
+func Test_findSBOM(t *testing.T) {
+	ts := httptest.NewServer(registry.New())
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	attested := pushImage(t, u.Host+"/attested:1.0", otherKey, key)
+	pushImage(t, u.Host+"/other:1.0", otherKey)
+	pushImage(t, u.Host+"/none:1.0")
+
+	// The image is not in the registry, but its SBOM is in Rekor
+	private, err := name.NewDigest(u.Host + "/private@sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6")
+	require.NoError(t, err)
+	rekorTS := rekorServer(t, private, otherKey.Public(), key.Public())
+	defer rekorTS.Close()
+	otherRekorTS := rekorServer(t, private, otherKey.Public())
+	defer otherRekorTS.Close()
+
+	tests := []struct {
+		name           string
+		target         string
+		sources        []string
+		rekorURL       string
+		want           bool
+		wantRepoDigest string
+	}{
+		{
+			name:           "signed by the key",
+			target:         u.Host + "/attested:1.0",
+			sources:        []string{option.SbomSourceOCI},
+			want:           true,
+			wantRepoDigest: attested.Name(),
+		},
+		{
+			name:           "Rekor",
+			target:         private.Name(),
+			sources:        []string{option.SbomSourceOCI, option.SbomSourceRekor},
+			rekorURL:       rekorTS.URL,
+			want:           true,
+			wantRepoDigest: private.Name(),
+		},
+		{
+			name:     "Rekor signed by another key",
+			target:   private.Name(),
+			sources:  []string{option.SbomSourceRekor},
+			rekorURL: otherRekorTS.URL,
+		},
+		{
+			name:    "signed by another key",
+			target:  u.Host + "/other:1.0",
+			sources: []string{option.SbomSourceOCI},
+		},
+		{
+			name:    "no attestation",
+			target:  u.Host + "/none:1.0",
+			sources: []string{option.SbomSourceOCI},
+		},
+		{
+			name:   "no source",
+			target: u.Host + "/attested:1.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := Option{}
+			opt.Target = tt.target
+			opt.SbomSources = tt.sources
+			opt.SbomPublicKey = key.Public()
+			opt.RekorURL = tt.rekorURL
+
+			got, ok := findSBOM(context.Background(), opt)
+			require.Equal(t, tt.want, ok)
+			if !tt.want {
+				return
+			}
+			assert.Equal(t, &ftypes.OS{Family: "debian", Name: "11"}, got.Blob.OS)
+			require.Len(t, got.Blob.PackageInfos, 1)
+			assert.Equal(t, "bash", got.Blob.PackageInfos[0].Packages[0].Name)
+			assert.Equal(t, []string{tt.wantRepoDigest}, got.Metadata.RepoDigests)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package artifact
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// TemplateValidateRun renders the custom template against the sample report and shows the template errors
+func TemplateValidateRun(cliCtx *cli.Context) error {
+	if cliCtx.Args().Len() != 1 {
+		_ = cli.ShowSubcommandHelp(cliCtx) // nolint: errcheck
+		return xerrors.New("a template file must be specified")
+	}
+
+	gc, err := option.NewGlobalOption(cliCtx)
+	if err != nil {
+		return xerrors.Errorf("option error: %w", err)
+	}
+
+	// The same path as '--template' is accepted
+	path := strings.TrimPrefix(cliCtx.Args().First(), "@")
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return xerrors.Errorf("unable to read the template: %w", err)
+	}
+
+	var sample types.Report
+	if samplePath := cliCtx.String("sample"); samplePath != "" {
+		sample, err = readReport(samplePath)
+	} else {
+		sample, err = report.SampleReport()
+	}
+	if err != nil {
+		return xerrors.Errorf("sample error: %w", err)
+	}
+
+	// The rendered output is discarded unless '--output' is specified
+	var output io.Writer = io.Discard
+	if o := cliCtx.String("output"); o != "" {
+		f, err := os.Create(o)
+		if err != nil {
+			return xerrors.Errorf("failed to create an output file: %w", err)
+		}
+		defer f.Close()
+		output = f
+	}
+
+	if err = report.ValidateTemplate(output, path, string(text), sample); err != nil {
+		return xerrors.Errorf("invalid template: %w", err)
+	}
+	gc.Logger.Infof("%s is valid", path)
+	return nil
+}
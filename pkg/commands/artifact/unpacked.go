@@ -0,0 +1,70 @@
+package artifact
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// scanUnpackedImage scans the image filesystem unpacked by another tool, e.g. umoci or a CSI snapshot,
+// so that the unpacking and the analysis can run in separate stages.
+// $ trivy image --input rootfs-dir:/path/to/rootfs myapp@sha256:...
+func (r *Runner) scanUnpackedImage(ctx context.Context, opt Option) (types.Report, error) {
+	dir := strings.TrimPrefix(opt.Input, option.RootfsDirInputPrefix)
+	if fi, err := os.Stat(dir); err != nil {
+		return types.Report{}, xerrors.Errorf("unpacked image filesystem error: %w", err)
+	} else if !fi.IsDir() {
+		return types.Report{}, xerrors.Errorf("%s is not a directory", dir)
+	}
+
+	imageName := opt.Target
+	if opt.RequireDigest {
+		if imageName == "" {
+			return types.Report{}, xerrors.New("--require-digest error: specify the image of the unpacked filesystem as 'name@sha256:<digest>'")
+		} else if err := requireDigest(imageName); err != nil {
+			return types.Report{}, xerrors.Errorf("--require-digest error: %w", err)
+		}
+	}
+
+	// Disable the lock file scanning in the same way as images
+	opt.DisabledAnalyzers = append(opt.DisabledAnalyzers, lockfileAnalyzers()...)
+	opt.Input, opt.Target = "", dir
+
+	report, err := r.scanFS(ctx, opt)
+	if err != nil {
+		return types.Report{}, err
+	}
+
+	report.Metadata.RebuildOf = opt.RebuildOf
+	return mapUnpackedImage(report, dir, imageName), nil
+}
+
+// mapUnpackedImage maps the report of the unpacked filesystem back to the image
+func mapUnpackedImage(report types.Report, dir, imageName string) types.Report {
+	report.ArtifactType = ftypes.ArtifactContainerImage
+	if imageName == "" {
+		return report
+	}
+	report.ArtifactName = imageName
+
+	// e.g. "/path/to/rootfs (alpine 3.15.4)" => "myapp:1.0 (alpine 3.15.4)"
+	for i := range report.Results {
+		if strings.HasPrefix(report.Results[i].Target, dir+" (") {
+			report.Results[i].Target = imageName + strings.TrimPrefix(report.Results[i].Target, dir)
+		}
+	}
+
+	if ref, err := name.NewDigest(imageName); err == nil {
+		report.Metadata.RepoDigests = []string{ref.Name()}
+	}
+	report.Metadata.ImageReference, report.Metadata.ImageDigest = pinImage(imageName, report.Metadata.RepoDigests)
+
+	return report
+}
@@ -0,0 +1,81 @@
+package artifact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_mapUnpackedImage(t *testing.T) {
+	const digest = "sha256:4c0fa34d8d0b1c2ab1e77b0d4b2e0b6cde4f63cc9500b5bcaabe4e58e931fb4c"
+
+	report := func() types.Report {
+		return types.Report{
+			ArtifactName: "/tmp/rootfs",
+			ArtifactType: ftypes.ArtifactFilesystem,
+			Results: types.Results{
+				{Target: "/tmp/rootfs (alpine 3.15.4)", Class: types.ClassOSPkg},
+				{Target: "app/package-lock.json", Class: types.ClassLangPkg},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		imageName string
+		want      types.Report
+	}{
+		{
+			name:      "pinned to a digest",
+			imageName: "ghcr.io/org/app@" + digest,
+			want: types.Report{
+				ArtifactName: "ghcr.io/org/app@" + digest,
+				ArtifactType: ftypes.ArtifactContainerImage,
+				Metadata: types.Metadata{
+					RepoDigests:    []string{"ghcr.io/org/app@" + digest},
+					ImageReference: "ghcr.io/org/app@" + digest,
+					ImageDigest:    "ghcr.io/org/app@" + digest,
+				},
+				Results: types.Results{
+					{Target: "ghcr.io/org/app@" + digest + " (alpine 3.15.4)", Class: types.ClassOSPkg},
+					{Target: "app/package-lock.json", Class: types.ClassLangPkg},
+				},
+			},
+		},
+		{
+			name:      "tag",
+			imageName: "app:1.0",
+			want: types.Report{
+				ArtifactName: "app:1.0",
+				ArtifactType: ftypes.ArtifactContainerImage,
+				Metadata: types.Metadata{
+					ImageReference: "index.docker.io/library/app:1.0",
+				},
+				Results: types.Results{
+					{Target: "app:1.0 (alpine 3.15.4)", Class: types.ClassOSPkg},
+					{Target: "app/package-lock.json", Class: types.ClassLangPkg},
+				},
+			},
+		},
+		{
+			name: "no image name",
+			want: types.Report{
+				ArtifactName: "/tmp/rootfs",
+				ArtifactType: ftypes.ArtifactContainerImage,
+				Results: types.Results{
+					{Target: "/tmp/rootfs (alpine 3.15.4)", Class: types.ClassOSPkg},
+					{Target: "app/package-lock.json", Class: types.ClassLangPkg},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapUnpackedImage(report(), "/tmp/rootfs", tt.imageName)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
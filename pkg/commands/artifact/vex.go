@@ -0,0 +1,32 @@
+package artifact
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/vex"
+)
+
+// writeVEX records the ignore decisions of the vulnerabilities as the OpenVEX document,
+// so that they can be shared with the consumers of the artifact
+func writeVEX(opt Option, report types.Report) error {
+	now := time.Now()
+	if opt.Deterministic {
+		now = time.Time{}
+	}
+	doc := vex.NewOpenVEX(report, opt.VEXAuthor, opt.AppVersion, now)
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the OpenVEX document: %w", err)
+	}
+	if err = os.WriteFile(opt.VEXOutput, b, 0644); err != nil {
+		return xerrors.Errorf("unable to write the OpenVEX document: %w", err)
+	}
+	log.Logger.Infof("The OpenVEX document with %d statements has been written to %s", len(doc.Statements), opt.VEXOutput)
+	return nil
+}
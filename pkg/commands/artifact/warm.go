@@ -0,0 +1,140 @@
+package artifact
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/cache"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// warmImageFunc analyzes the image and stores the results in the cache
+type warmImageFunc func(ctx context.Context, opt Option, imageName string) error
+
+// CacheWarmRun analyzes the images listed in "--images-file" in advance, e.g. the common base images,
+// so that the scans of the images built on them analyze only the layers on top.
+func CacheWarmRun(cliCtx *cli.Context) error {
+	opt, err := InitOption(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	runner, err := NewRunner(opt, withoutDB())
+	if err != nil {
+		if errors.Is(err, SkipScan) {
+			return nil
+		}
+		return xerrors.Errorf("init error: %w", err)
+	}
+	defer runner.Close()
+
+	images, err := readImagesFile(opt.ImagesFile)
+	if err != nil {
+		return xerrors.Errorf("images file error: %w", err)
+	}
+
+	return warmImages(cliCtx.Context, opt, images, func(ctx context.Context, opt Option, imageName string) error {
+		return warmImage(ctx, opt, imageName, runner.cache, runner.tempDir())
+	})
+}
+
+// readImagesFile reads the image names, one per line
+func readImagesFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("file open error: %w", err)
+	}
+	defer f.Close()
+
+	images, err := readLines(f)
+	if err != nil {
+		return nil, xerrors.Errorf("file read error: %w", err)
+	}
+	if len(images) == 0 {
+		return nil, xerrors.Errorf("no image found in %s", path)
+	}
+	return images, nil
+}
+
+// readLines reads the lines. Empty lines and lines starting with "#" are ignored.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// warmImages analyzes the images one by one with its own timeout.
+// An image failing to be analyzed doesn't stop the others, and the failures are returned at the end.
+func warmImages(ctx context.Context, opt Option, images []string, warm warmImageFunc) error {
+	var failed int
+	for i, imageName := range images {
+		log.Logger.Infof("Analyzing %s (%d/%d)...", imageName, i+1, len(images))
+		if err := warmWithTimeout(ctx, opt, imageName, warm); err != nil {
+			log.Logger.Errorf("Unable to analyze %s: %s", imageName, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return xerrors.Errorf("%d of %d images failed to be analyzed", failed, len(images))
+	}
+	log.Logger.Infof("The analysis results of %d images are cached", len(images))
+	return nil
+}
+
+func warmWithTimeout(ctx context.Context, opt Option, imageName string, warm warmImageFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, opt.Timeout)
+	defer cancel()
+	return warm(ctx, opt, imageName)
+}
+
+// warmImage analyzes the layers of the image missing in the cache with the same analyzers as "trivy image",
+// so that the cache keys match those of the later scans given the same options.
+// In client/server mode, the results are stored in the cache of the server.
+func warmImage(ctx context.Context, opt Option, imageName string, c cache.ArtifactCache, tempDir string) error {
+	opt.Target = imageName
+	opt.DisabledAnalyzers = lockfileAnalyzers()
+
+	scannerConfig, _, err := initScannerConfig(opt, nil)
+	if err != nil {
+		return err
+	}
+	scannerConfig.ArtifactOption.TempDir = tempDir
+
+	dockerOpt, err := types.GetDockerOption()
+	if err != nil {
+		return err
+	}
+	img, cleanup, err := tartifact.NewDockerImage(ctx, imageName, dockerOpt, scannerConfig.ArtifactOption)
+	if err != nil {
+		return xerrors.Errorf("unable to open the image: %w", err)
+	}
+	defer cleanup()
+
+	art, err := tartifact.NewImageArtifact(img, c, scannerConfig.ArtifactOption)
+	if err != nil {
+		return xerrors.Errorf("unable to initialize the image artifact: %w", err)
+	}
+	if _, err = art.Inspect(ctx); err != nil {
+		return xerrors.Errorf("image analysis error: %w", err)
+	}
+	return nil
+}
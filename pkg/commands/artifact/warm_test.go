@@ -0,0 +1,95 @@
+package artifact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+)
+
+func Test_readImagesFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			content: `# base images
+ubuntu:22.04
+
+  debian:11
+`,
+			want: []string{"ubuntu:22.04", "debian:11"},
+		},
+		{
+			name:    "no image",
+			content: "# nothing yet\n",
+			wantErr: "no image found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bases.txt")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			got, err := readImagesFile(path)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_warmImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  []string
+		wantErr string
+	}{
+		{
+			name:   "happy path",
+			images: []string{"ubuntu:22.04", "debian:11"},
+		},
+		{
+			name:    "the others are analyzed after a failure",
+			images:  []string{"unknown:1.0", "ubuntu:22.04", "debian:11"},
+			wantErr: "1 of 3 images failed to be analyzed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warmed []string
+			warm := func(ctx context.Context, _ Option, imageName string) error {
+				if _, ok := ctx.Deadline(); !ok {
+					return xerrors.New("no timeout")
+				}
+				if imageName == "unknown:1.0" {
+					return xerrors.New("not found")
+				}
+				warmed = append(warmed, imageName)
+				return nil
+			}
+
+			opt := Option{ArtifactOption: option.ArtifactOption{Timeout: time.Minute}}
+			err := warmImages(context.Background(), opt, tt.images, warm)
+			assert.Equal(t, []string{"ubuntu:22.04", "debian:11"}, warmed)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
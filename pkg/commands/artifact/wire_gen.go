@@ -9,14 +9,11 @@ package artifact
 import (
 	"context"
 	"github.com/aquasecurity/fanal/applier"
-	"github.com/aquasecurity/fanal/artifact"
-	image2 "github.com/aquasecurity/fanal/artifact/image"
-	local2 "github.com/aquasecurity/fanal/artifact/local"
-	"github.com/aquasecurity/fanal/artifact/remote"
 	"github.com/aquasecurity/fanal/cache"
 	"github.com/aquasecurity/fanal/image"
 	"github.com/aquasecurity/fanal/types"
 	"github.com/aquasecurity/trivy-db/pkg/db"
+	artifact2 "github.com/aquasecurity/trivy/pkg/artifact"
 	"github.com/aquasecurity/trivy/pkg/detector/ospkg"
 	"github.com/aquasecurity/trivy/pkg/result"
 	"github.com/aquasecurity/trivy/pkg/rpc/client"
@@ -28,15 +25,15 @@ import (
 
 // initializeDockerScanner is for container image scanning in standalone mode
 // e.g. dockerd, container registry, podman, etc.
-func initializeDockerScanner(ctx context.Context, imageName string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, dockerOpt types.DockerOption, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+func initializeDockerScanner(ctx context.Context, imageName string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, dockerOpt types.DockerOption, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	applierApplier := applier.NewApplier(localArtifactCache)
 	detector := ospkg.Detector{}
 	localScanner := local.NewScanner(applierApplier, detector)
-	typesImage, cleanup, err := image.NewDockerImage(ctx, imageName, dockerOpt)
+	typesImage, cleanup, err := artifact2.NewDockerImage(ctx, imageName, dockerOpt, artifactOption)
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
-	artifactArtifact, err := image2.NewArtifact(typesImage, artifactCache, artifactOption)
+	artifactArtifact, err := artifact2.NewImageArtifact(typesImage, artifactCache, artifactOption)
 	if err != nil {
 		cleanup()
 		return scanner.Scanner{}, nil, err
@@ -49,7 +46,7 @@ func initializeDockerScanner(ctx context.Context, imageName string, artifactCach
 
 // initializeArchiveScanner is for container image archive scanning in standalone mode
 // e.g. docker save -o alpine.tar alpine:3.15
-func initializeArchiveScanner(ctx context.Context, filePath string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact.Option) (scanner.Scanner, error) {
+func initializeArchiveScanner(ctx context.Context, filePath string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, error) {
 	applierApplier := applier.NewApplier(localArtifactCache)
 	detector := ospkg.Detector{}
 	localScanner := local.NewScanner(applierApplier, detector)
@@ -57,7 +54,7 @@ func initializeArchiveScanner(ctx context.Context, filePath string, artifactCach
 	if err != nil {
 		return scanner.Scanner{}, err
 	}
-	artifactArtifact, err := image2.NewArtifact(typesImage, artifactCache, artifactOption)
+	artifactArtifact, err := artifact2.NewImageArtifact(typesImage, artifactCache, artifactOption)
 	if err != nil {
 		return scanner.Scanner{}, err
 	}
@@ -65,25 +62,65 @@ func initializeArchiveScanner(ctx context.Context, filePath string, artifactCach
 	return scannerScanner, nil
 }
 
+// initializeImageScanner is for scanning images opened by Trivy in standalone mode
+// e.g. images in the BuildKit content store
+func initializeImageScanner(ctx context.Context, img types.Image, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	applierApplier := applier.NewApplier(localArtifactCache)
+	detector := ospkg.Detector{}
+	localScanner := local.NewScanner(applierApplier, detector)
+	artifactArtifact, err := artifact2.NewImageArtifact(img, artifactCache, artifactOption)
+	if err != nil {
+		return scanner.Scanner{}, err
+	}
+	scannerScanner := scanner.NewScanner(localScanner, artifactArtifact)
+	return scannerScanner, nil
+}
+
+// initializeSBOMScanner is for scanning images with the packages listed by their SBOMs in standalone mode
+// e.g. SBOMs attached to the images by cosign
+func initializeSBOMScanner(ctx context.Context, imageName string, sbom artifact2.SBOM, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache) scanner.Scanner {
+	applierApplier := applier.NewApplier(localArtifactCache)
+	detector := ospkg.Detector{}
+	localScanner := local.NewScanner(applierApplier, detector)
+	artifactArtifact := artifact2.NewSBOMArtifact(imageName, sbom, artifactCache)
+	scannerScanner := scanner.NewScanner(localScanner, artifactArtifact)
+	return scannerScanner
+}
+
 // initializeFilesystemScanner is for filesystem scanning in standalone mode
-func initializeFilesystemScanner(ctx context.Context, path string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+func initializeFilesystemScanner(ctx context.Context, path string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
+	applierApplier := applier.NewApplier(localArtifactCache)
+	detector := ospkg.Detector{}
+	localScanner := local.NewScanner(applierApplier, detector)
+	artifactArtifact, err := artifact2.NewFilesystemArtifact(path, artifactCache, artifactOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, err
+	}
+	scannerScanner := scanner.NewScanner(localScanner, artifactArtifact)
+	return scannerScanner, func() {
+	}, nil
+}
+
+// initializeSSHScanner is for scanning the filesystems of remote hosts over SSH in standalone mode
+func initializeSSHScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	applierApplier := applier.NewApplier(localArtifactCache)
 	detector := ospkg.Detector{}
 	localScanner := local.NewScanner(applierApplier, detector)
-	artifactArtifact, err := local2.NewArtifact(path, artifactCache, artifactOption)
+	artifactArtifact, cleanup, err := artifact2.NewSSHArtifact(url, artifactCache, artifactOption)
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
 	scannerScanner := scanner.NewScanner(localScanner, artifactArtifact)
 	return scannerScanner, func() {
+		cleanup()
 	}, nil
 }
 
-func initializeRepositoryScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+func initializeRepositoryScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache, localArtifactCache cache.LocalArtifactCache, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	applierApplier := applier.NewApplier(localArtifactCache)
 	detector := ospkg.Detector{}
 	localScanner := local.NewScanner(applierApplier, detector)
-	artifactArtifact, cleanup, err := remote.NewArtifact(url, artifactCache, artifactOption)
+	artifactArtifact, cleanup, err := artifact2.NewRepositoryArtifact(url, artifactCache, artifactOption)
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
@@ -101,14 +138,14 @@ func initializeResultClient() result.Client {
 
 // initializeRemoteDockerScanner is for container image scanning in client/server mode
 // e.g. dockerd, container registry, podman, etc.
-func initializeRemoteDockerScanner(ctx context.Context, imageName string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, dockerOpt types.DockerOption, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+func initializeRemoteDockerScanner(ctx context.Context, imageName string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, dockerOpt types.DockerOption, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	v := _wireValue
 	clientScanner := client.NewScanner(remoteScanOptions, v...)
-	typesImage, cleanup, err := image.NewDockerImage(ctx, imageName, dockerOpt)
+	typesImage, cleanup, err := artifact2.NewDockerImage(ctx, imageName, dockerOpt, artifactOption)
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
-	artifactArtifact, err := image2.NewArtifact(typesImage, artifactCache, artifactOption)
+	artifactArtifact, err := artifact2.NewImageArtifact(typesImage, artifactCache, artifactOption)
 	if err != nil {
 		cleanup()
 		return scanner.Scanner{}, nil, err
@@ -125,14 +162,60 @@ var (
 
 // initializeRemoteArchiveScanner is for container image archive scanning in client/server mode
 // e.g. docker save -o alpine.tar alpine:3.15
-func initializeRemoteArchiveScanner(ctx context.Context, filePath string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact.Option) (scanner.Scanner, error) {
+func initializeRemoteArchiveScanner(ctx context.Context, filePath string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
 	v := _wireValue
 	clientScanner := client.NewScanner(remoteScanOptions, v...)
 	typesImage, err := image.NewArchiveImage(filePath)
 	if err != nil {
 		return scanner.Scanner{}, err
 	}
-	artifactArtifact, err := image2.NewArtifact(typesImage, artifactCache, artifactOption)
+	artifactArtifact, err := artifact2.NewImageArtifact(typesImage, artifactCache, artifactOption)
+	if err != nil {
+		return scanner.Scanner{}, err
+	}
+	scannerScanner := scanner.NewScanner(clientScanner, artifactArtifact)
+	return scannerScanner, nil
+}
+
+// initializeRemoteImageScanner is for scanning images opened by Trivy in client/server mode
+// e.g. images in the BuildKit content store or pulled through the server
+func initializeRemoteImageScanner(ctx context.Context, img types.Image, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	v := _wireValue
+	clientScanner := client.NewScanner(remoteScanOptions, v...)
+	artifactArtifact, err := artifact2.NewImageArtifact(img, artifactCache, artifactOption)
+	if err != nil {
+		return scanner.Scanner{}, err
+	}
+	scannerScanner := scanner.NewScanner(clientScanner, artifactArtifact)
+	return scannerScanner, nil
+}
+
+// initializeRemoteSBOMScanner is for scanning images with the packages listed by their SBOMs in client/server mode
+func initializeRemoteSBOMScanner(ctx context.Context, imageName string, sbom artifact2.SBOM, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption) scanner.Scanner {
+	v := _wireValue
+	clientScanner := client.NewScanner(remoteScanOptions, v...)
+	artifactArtifact := artifact2.NewSBOMArtifact(imageName, sbom, artifactCache)
+	scannerScanner := scanner.NewScanner(clientScanner, artifactArtifact)
+	return scannerScanner
+}
+
+// initializeServerSideImageScanner is for scanning images whose layers are analyzed on the server in client/server mode
+func initializeServerSideImageScanner(ctx context.Context, img types.Image, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	v := _wireValue
+	clientScanner := client.NewScanner(remoteScanOptions, v...)
+	artifactArtifact, err := client.NewServerSideArtifact(img, artifactCache, remoteScanOptions, artifactOption)
+	if err != nil {
+		return scanner.Scanner{}, err
+	}
+	scannerScanner := scanner.NewScanner(clientScanner, artifactArtifact)
+	return scannerScanner, nil
+}
+
+// initializeServerSidePullImageScanner is for scanning images pulled by the server in client/server mode
+func initializeServerSidePullImageScanner(ctx context.Context, imageName string, remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, error) {
+	v := _wireValue
+	clientScanner := client.NewScanner(remoteScanOptions, v...)
+	artifactArtifact, err := client.NewServerSidePullArtifact(imageName, remoteScanOptions, artifactOption, v...)
 	if err != nil {
 		return scanner.Scanner{}, err
 	}
@@ -141,10 +224,10 @@ func initializeRemoteArchiveScanner(ctx context.Context, filePath string, artifa
 }
 
 // initializeRemoteFilesystemScanner is for filesystem scanning in client/server mode
-func initializeRemoteFilesystemScanner(ctx context.Context, path string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact.Option) (scanner.Scanner, func(), error) {
+func initializeRemoteFilesystemScanner(ctx context.Context, path string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
 	v := _wireValue
 	clientScanner := client.NewScanner(remoteScanOptions, v...)
-	artifactArtifact, err := local2.NewArtifact(path, artifactCache, artifactOption)
+	artifactArtifact, err := artifact2.NewFilesystemArtifact(path, artifactCache, artifactOption)
 	if err != nil {
 		return scanner.Scanner{}, nil, err
 	}
@@ -153,6 +236,20 @@ func initializeRemoteFilesystemScanner(ctx context.Context, path string, artifac
 	}, nil
 }
 
+// initializeRemoteSSHScanner is for scanning the filesystems of remote hosts over SSH in client/server mode
+func initializeRemoteSSHScanner(ctx context.Context, url string, artifactCache cache.ArtifactCache, remoteScanOptions client.ScannerOption, artifactOption artifact2.Option) (scanner.Scanner, func(), error) {
+	v := _wireValue
+	clientScanner := client.NewScanner(remoteScanOptions, v...)
+	artifactArtifact, cleanup, err := artifact2.NewSSHArtifact(url, artifactCache, artifactOption)
+	if err != nil {
+		return scanner.Scanner{}, nil, err
+	}
+	scannerScanner := scanner.NewScanner(clientScanner, artifactArtifact)
+	return scannerScanner, func() {
+		cleanup()
+	}, nil
+}
+
 func initializeRemoteResultClient() result.Client {
 	config := db.Config{}
 	resultClient := result.NewClient(config)
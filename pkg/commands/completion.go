@@ -0,0 +1,528 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// completionValue holds the values suggested for a flag
+type completionValue struct {
+	values []string
+
+	// list is true if the flag accepts comma-separated values
+	list bool
+}
+
+// completionValues holds the values of flags keyed by "<command path> <flag name>" or "<flag name>"
+var completionValues = map[string]completionValue{
+	"format":           {values: []string{"table", "json", "sarif", "template", "cyclonedx", "spdx", "spdx-json", "cosign-vuln", "bitbucket", "azure-devops", "gitlab-dependency-scanning", "gitlab-codequality"}},
+	"diff format":      {values: []string{"table", "json"}},
+	"version format":   {values: []string{"table", "json"}},
+	"severity":         {values: dbTypes.SeverityNames, list: true},
+	"exit-on-severity": {values: dbTypes.SeverityNames},
+	"pkg-types":        {values: append([]string{types.VulnTypeOS, types.VulnTypeLibrary}, option.LanguagePkgTypes()...), list: true},
+	"security-checks": {
+		values: []string{types.SecurityCheckVulnerability, types.SecurityCheckConfig, types.SecurityCheckSecret,
+			types.SecurityCheckLicense},
+		list: true,
+	},
+	"cache-backend": {values: []string{"fs", "redis://", "fs+redis://", "dynamodb://", "fs+dynamodb://"}},
+	"sbom-format":   {values: []string{"cyclonedx", "spdx", "spdx-json"}},
+	"artifact-type": {values: []string{"image", "fs", "repo", "archive"}},
+	"scan-order":    {values: []string{"list", "newest"}},
+	"report":        {values: []string{"all", "summary"}},
+}
+
+var completionShells = map[string]func(w io.Writer, root completionNode){
+	"bash":       writeBashCompletion,
+	"zsh":        writeZshCompletion,
+	"fish":       writeFishCompletion,
+	"powershell": writePowerShellCompletion,
+}
+
+// NewCompletionCommand is the factory method to add completion subcommand
+func NewCompletionCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "completion",
+		Usage:     "generate the autocompletion script for the specified shell",
+		ArgsUsage: "bash|zsh|fish|powershell",
+		CustomHelpTemplate: cli.CommandHelpTemplate + `EXAMPLES:
+  - bash:
+      $ source <(trivy completion bash)
+
+  - zsh:
+      $ trivy completion zsh > "${fpath[1]}/_trivy"
+
+  - fish:
+      $ trivy completion fish > ~/.config/fish/completions/trivy.fish
+
+  - powershell:
+      PS> trivy completion powershell | Out-String | Invoke-Expression
+
+`,
+		Action: func(c *cli.Context) error {
+			write, ok := completionShells[c.Args().First()]
+			if c.Args().Len() != 1 || !ok {
+				_ = cli.ShowSubcommandHelp(c) // nolint: errcheck
+				return xerrors.Errorf("specify one of %s", strings.Join(completionShellNames(), ", "))
+			}
+			write(c.App.Writer, newCompletionNode(c.App))
+			return nil
+		},
+	}
+}
+
+// completionNode represents a command in the completion scripts
+type completionNode struct {
+	path     string // e.g. "plugin install", empty for the root
+	names    []string
+	usage    string
+	flags    []completionFlag
+	children []completionNode
+}
+
+type completionFlag struct {
+	names      []string // e.g. "--format", "-f"
+	usage      string
+	takesValue bool
+	value      completionValue
+}
+
+func newCompletionNode(app *cli.App) completionNode {
+	root := completionNode{
+		flags:    newCompletionFlags("", app.Flags, true),
+		children: newCompletionChildren("", app.Commands),
+	}
+	return root
+}
+
+func newCompletionChildren(parent string, commands []*cli.Command) []completionNode {
+	var nodes []completionNode
+	for _, cmd := range commands {
+		if cmd.Hidden {
+			continue
+		}
+		path := strings.TrimSpace(parent + " " + cmd.Name)
+		nodes = append(nodes, completionNode{
+			path:     path,
+			names:    cmd.Names(),
+			usage:    cmd.Usage,
+			flags:    newCompletionFlags(path, cmd.Flags, !cmd.HideHelp),
+			children: newCompletionChildren(path, cmd.Subcommands),
+		})
+	}
+	return nodes
+}
+
+func newCompletionFlags(path string, flags []cli.Flag, withHelp bool) []completionFlag {
+	var compFlags []completionFlag
+	hasHelp := false
+	for _, f := range flags {
+		df, ok := f.(cli.DocGenerationFlag)
+		if !ok {
+			continue
+		}
+		name := f.Names()[0]
+		hasHelp = hasHelp || name == "help"
+
+		value, ok := completionValues[strings.TrimSpace(path+" "+name)]
+		if !ok {
+			value = completionValues[name]
+		}
+		compFlags = append(compFlags, completionFlag{
+			names:      dashedNames(f.Names()),
+			usage:      df.GetUsage(),
+			takesValue: df.TakesValue(),
+			value:      value,
+		})
+	}
+
+	// urfave/cli adds the help flag when the command runs
+	if withHelp && !hasHelp {
+		compFlags = append(compFlags, completionFlag{
+			names: []string{"--help", "-h"},
+			usage: "show help",
+		})
+	}
+	return compFlags
+}
+
+func dashedNames(names []string) []string {
+	var dashed []string
+	for _, n := range names {
+		if len(n) == 1 {
+			dashed = append(dashed, "-"+n)
+		} else {
+			dashed = append(dashed, "--"+n)
+		}
+	}
+	return dashed
+}
+
+// walk calls fn for the node and its descendants
+func (n completionNode) walk(fn func(completionNode)) {
+	fn(n)
+	for _, c := range n.children {
+		c.walk(fn)
+	}
+}
+
+// parent returns the path of the parent command
+func (n completionNode) parent() string {
+	if i := strings.LastIndex(n.path, " "); i != -1 {
+		return n.path[:i]
+	}
+	return ""
+}
+
+func (n completionNode) commandNames() []string {
+	var names []string
+	for _, c := range n.children {
+		names = append(names, c.names[0])
+	}
+	return names
+}
+
+func (n completionNode) flagNames() []string {
+	var names []string
+	for _, f := range n.flags {
+		names = append(names, f.names...)
+	}
+	return names
+}
+
+// casePatterns returns the patterns matching "<path>:<name>" for all the names
+func casePatterns(path string, names []string) string {
+	var patterns []string
+	for _, name := range names {
+		patterns = append(patterns, fmt.Sprintf("%q", path+":"+name))
+	}
+	return strings.Join(patterns, "|")
+}
+
+func writeBashCompletion(w io.Writer, root completionNode) {
+	b := &strings.Builder{}
+	b.WriteString(`# bash completion for trivy
+
+__trivy_complete_list() {
+    local prefix=""
+    if [[ "${cur}" == *,* ]]; then
+        prefix="${cur%,*},"
+    fi
+    compopt -o nospace 2>/dev/null
+    COMPREPLY=($(compgen -P "${prefix}" -W "$1" -- "${cur##*,}"))
+}
+
+_trivy() {
+    local cur prev cmdpath i
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    cmdpath=""
+
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${cmdpath}:${COMP_WORDS[i]}" in
+`)
+	root.walk(func(n completionNode) {
+		if n.path == "" {
+			return
+		}
+		fmt.Fprintf(b, "        %s) cmdpath=%q ;;\n", casePatterns(n.parent(), n.names), n.path)
+	})
+	b.WriteString(`        esac
+    done
+
+    case "${cmdpath}:${prev}" in
+`)
+	root.walk(func(n completionNode) {
+		var files []string
+		for _, f := range n.flags {
+			switch {
+			case len(f.value.values) > 0 && f.value.list:
+				fmt.Fprintf(b, "    %s) __trivy_complete_list %q; return ;;\n",
+					casePatterns(n.path, f.names), strings.Join(f.value.values, " "))
+			case len(f.value.values) > 0:
+				fmt.Fprintf(b, "    %s) COMPREPLY=($(compgen -W %q -- \"${cur}\")); return ;;\n",
+					casePatterns(n.path, f.names), strings.Join(f.value.values, " "))
+			case f.takesValue:
+				files = append(files, f.names...)
+			}
+		}
+		// The default completion, i.e. file names, is used for the other values
+		if len(files) > 0 {
+			fmt.Fprintf(b, "    %s) return ;;\n", casePatterns(n.path, files))
+		}
+	})
+	b.WriteString(`    esac
+
+    local cmds flags
+    case "${cmdpath}" in
+`)
+	root.walk(func(n completionNode) {
+		fmt.Fprintf(b, "    %q)\n        cmds=%q\n        flags=%q\n        ;;\n",
+			n.path, strings.Join(n.commandNames(), " "), strings.Join(n.flagNames(), " "))
+	})
+	b.WriteString(`    esac
+
+    if [[ "${cur}" == -* ]]; then
+        COMPREPLY=($(compgen -W "${flags}" -- "${cur}"))
+    else
+        COMPREPLY=($(compgen -W "${cmds}" -- "${cur}"))
+    fi
+}
+
+complete -o default -F _trivy trivy
+`)
+	_, _ = io.WriteString(w, b.String())
+}
+
+func writeZshCompletion(w io.Writer, root completionNode) {
+	b := &strings.Builder{}
+	b.WriteString(`#compdef trivy
+
+# zsh completion for trivy
+_trivy() {
+    local cmdpath="" i
+    for ((i = 2; i < CURRENT; i++)); do
+        case "${cmdpath}:${words[i]}" in
+`)
+	root.walk(func(n completionNode) {
+		if n.path == "" {
+			return
+		}
+		fmt.Fprintf(b, "        %s) cmdpath=%q ;;\n", casePatterns(n.parent(), n.names), n.path)
+	})
+	b.WriteString(`        esac
+    done
+
+    case "${cmdpath}:${words[CURRENT-1]}" in
+`)
+	root.walk(func(n completionNode) {
+		var files []string
+		for _, f := range n.flags {
+			switch {
+			case len(f.value.values) > 0 && f.value.list:
+				fmt.Fprintf(b, "    %s) _values -s , 'value' %s; return ;;\n",
+					casePatterns(n.path, f.names), zshQuoteAll(f.value.values))
+			case len(f.value.values) > 0:
+				fmt.Fprintf(b, "    %s) compadd -- %s; return ;;\n",
+					casePatterns(n.path, f.names), zshQuoteAll(f.value.values))
+			case f.takesValue:
+				files = append(files, f.names...)
+			}
+		}
+		if len(files) > 0 {
+			fmt.Fprintf(b, "    %s) _files; return ;;\n", casePatterns(n.path, files))
+		}
+	})
+	b.WriteString(`    esac
+
+    local -a cmds flags
+    case "${cmdpath}" in
+`)
+	root.walk(func(n completionNode) {
+		var cmds, flags []string
+		for _, c := range n.children {
+			cmds = append(cmds, zshQuote(c.names[0]+":"+c.usage))
+		}
+		for _, f := range n.flags {
+			for _, name := range f.names {
+				flags = append(flags, zshQuote(name+":"+f.usage))
+			}
+		}
+		fmt.Fprintf(b, "    %q)\n        cmds=(%s)\n        flags=(%s)\n        ;;\n",
+			n.path, strings.Join(cmds, " "), strings.Join(flags, " "))
+	})
+	b.WriteString(`    esac
+
+    if [[ "${words[CURRENT]}" == -* ]]; then
+        _describe -t flags 'flag' flags
+    elif (( ${#cmds} )); then
+        _describe -t commands 'command' cmds
+    else
+        _files
+    fi
+}
+
+if [[ "${funcstack[1]}" == "_trivy" ]]; then
+    _trivy "$@"
+else
+    compdef _trivy trivy
+fi
+`)
+	_, _ = io.WriteString(w, b.String())
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func zshQuoteAll(ss []string) string {
+	var quoted []string
+	for _, s := range ss {
+		quoted = append(quoted, zshQuote(s))
+	}
+	return strings.Join(quoted, " ")
+}
+
+func writeFishCompletion(w io.Writer, root completionNode) {
+	b := &strings.Builder{}
+	b.WriteString(`# fish completion for trivy
+
+function __trivy_cmdpath
+    set -l cmdpath ""
+    for t in (commandline -opc)[2..-1]
+        switch "$cmdpath:$t"
+`)
+	root.walk(func(n completionNode) {
+		if n.path == "" {
+			return
+		}
+		var patterns []string
+		for _, name := range n.names {
+			patterns = append(patterns, fishQuote(n.parent()+":"+name))
+		}
+		fmt.Fprintf(b, "            case %s\n                set cmdpath %s\n", strings.Join(patterns, " "), fishQuote(n.path))
+	})
+	b.WriteString(`        end
+    end
+    echo $cmdpath
+end
+
+function __trivy_using
+    test (__trivy_cmdpath) = "$argv"
+end
+
+`)
+	root.walk(func(n completionNode) {
+		cond := fishQuote("__trivy_using " + n.path)
+		for _, c := range n.children {
+			fmt.Fprintf(b, "complete -c trivy -f -n %s -a %s -d %s\n", cond, fishQuote(c.names[0]), fishQuote(c.usage))
+		}
+		for _, f := range n.flags {
+			var opts []string
+			for _, name := range f.names {
+				if strings.HasPrefix(name, "--") {
+					opts = append(opts, "-l "+strings.TrimPrefix(name, "--"))
+				} else {
+					opts = append(opts, "-s "+strings.TrimPrefix(name, "-"))
+				}
+			}
+			switch {
+			case len(f.value.values) > 0:
+				opts = append(opts, "-x -a "+fishQuote(strings.Join(f.value.values, " ")))
+			case f.takesValue:
+				opts = append(opts, "-r")
+			}
+			fmt.Fprintf(b, "complete -c trivy -n %s %s -d %s\n", cond, strings.Join(opts, " "), fishQuote(f.usage))
+		}
+	})
+	_, _ = io.WriteString(w, b.String())
+}
+
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func writePowerShellCompletion(w io.Writer, root completionNode) {
+	b := &strings.Builder{}
+	b.WriteString(`# powershell completion for trivy
+Register-ArgumentCompleter -Native -CommandName trivy -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $aliases = @{
+`)
+	root.walk(func(n completionNode) {
+		if n.path == "" {
+			return
+		}
+		for _, name := range n.names {
+			fmt.Fprintf(b, "        %s = %s\n", psQuote(n.parent()+":"+name), psQuote(n.path))
+		}
+	})
+	b.WriteString("    }\n    $commands = @{\n")
+	root.walk(func(n completionNode) {
+		var items []string
+		for _, c := range n.children {
+			items = append(items, fmt.Sprintf("@(%s, %s)", psQuote(c.names[0]), psQuote(c.usage)))
+		}
+		fmt.Fprintf(b, "        %s = @(%s)\n", psQuote(n.path), strings.Join(items, ", "))
+	})
+	b.WriteString("    }\n    $flags = @{\n")
+	root.walk(func(n completionNode) {
+		var items []string
+		for _, f := range n.flags {
+			for _, name := range f.names {
+				items = append(items, fmt.Sprintf("@(%s, %s)", psQuote(name), psQuote(f.usage)))
+			}
+		}
+		fmt.Fprintf(b, "        %s = @(%s)\n", psQuote(n.path), strings.Join(items, ", "))
+	})
+	b.WriteString("    }\n    $values = @{\n")
+	root.walk(func(n completionNode) {
+		for _, f := range n.flags {
+			if len(f.value.values) == 0 {
+				continue
+			}
+			var values []string
+			for _, v := range f.value.values {
+				values = append(values, psQuote(v))
+			}
+			for _, name := range f.names {
+				fmt.Fprintf(b, "        %s = @(%s)\n", psQuote(n.path+":"+name), strings.Join(values, ", "))
+			}
+		}
+	})
+	b.WriteString(`    }
+
+    $tokens = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $tokens.Count -gt 0) {
+        $tokens = @($tokens | Select-Object -SkipLast 1)
+    }
+    $cmdpath = ''
+    foreach ($t in $tokens) {
+        if ($aliases.ContainsKey("${cmdpath}:$t")) {
+            $cmdpath = $aliases["${cmdpath}:$t"]
+        }
+    }
+    $prev = if ($tokens.Count -gt 0) { $tokens[-1] } else { '' }
+
+    if ($values.ContainsKey("${cmdpath}:$prev")) {
+        $candidates = $values["${cmdpath}:$prev"] | ForEach-Object { ,@($_, $_) }
+    } elseif ($wordToComplete -like '-*') {
+        $candidates = $flags[$cmdpath]
+    } else {
+        $candidates = $commands[$cmdpath]
+    }
+
+    $candidates | Where-Object { $_[0] -like "$wordToComplete*" } | ForEach-Object {
+        $tooltip = if ($_[1]) { $_[1] } else { $_[0] }
+        [System.Management.Automation.CompletionResult]::new($_[0], $_[0], 'ParameterValue', $tooltip)
+    }
+}
+`)
+	_, _ = io.WriteString(w, b.String())
+}
+
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// completionShellNames returns the supported shells
+func completionShellNames() []string {
+	var names []string
+	for name := range completionShells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
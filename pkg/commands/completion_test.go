@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestNewCompletionCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "bash",
+			shell: "bash",
+			want: []string{
+				`":image"|":i") cmdpath="image" ;;`,
+				`"plugin:install"|"plugin:i") cmdpath="plugin install" ;;`,
+				`"image:--severity"|"image:-s") __trivy_complete_list "UNKNOWN LOW MEDIUM HIGH CRITICAL"; return ;;`,
+				`"image:--format"|"image:-f") COMPREPLY=($(compgen -W "table json sarif template cyclonedx spdx spdx-json cosign-vuln bitbucket azure-devops gitlab-dependency-scanning gitlab-codequality" -- "${cur}")); return ;;`,
+				`"diff:--format") COMPREPLY=($(compgen -W "table json" -- "${cur}")); return ;;`,
+				`"image:--output") return ;;`,
+				`cmds="image plugin diff completion help"`,
+				`flags="--quiet -q --help -h"`,
+				`complete -o default -F _trivy trivy`,
+			},
+		},
+		{
+			name:  "zsh",
+			shell: "zsh",
+			want: []string{
+				`#compdef trivy`,
+				`":image"|":i") cmdpath="image" ;;`,
+				`"image:--severity"|"image:-s") _values -s , 'value' 'UNKNOWN' 'LOW' 'MEDIUM' 'HIGH' 'CRITICAL'; return ;;`,
+				`"image:--format"|"image:-f") compadd -- 'table' 'json'`,
+				`"image:--output") _files; return ;;`,
+				`cmds=('image:scan an image' 'plugin:manage plugins' 'diff:compare reports' 'completion:`,
+				`'--quiet:suppress progress bar'`,
+			},
+		},
+		{
+			name:  "fish",
+			shell: "fish",
+			want: []string{
+				"case ':image' ':i'\n                set cmdpath 'image'",
+				`complete -c trivy -f -n '__trivy_using ' -a 'image' -d 'scan an image'`,
+				`complete -c trivy -f -n '__trivy_using plugin' -a 'install' -d 'install a plugin'`,
+				`complete -c trivy -n '__trivy_using image' -l severity -s s -x -a 'UNKNOWN LOW MEDIUM HIGH CRITICAL' -d 'severities'`,
+				`complete -c trivy -n '__trivy_using image' -l output -r -d 'output file name'`,
+				`complete -c trivy -n '__trivy_using ' -l quiet -s q -d 'suppress progress bar'`,
+			},
+		},
+		{
+			name:  "powershell",
+			shell: "powershell",
+			want: []string{
+				`Register-ArgumentCompleter -Native -CommandName trivy`,
+				`':i' = 'image'`,
+				`'plugin:i' = 'plugin install'`,
+				`'' = @(@('image', 'scan an image'), @('plugin', 'manage plugins'), @('diff', 'compare reports'), @('completion',`,
+				`'image:--severity' = @('UNKNOWN', 'LOW', 'MEDIUM', 'HIGH', 'CRITICAL')`,
+				`'diff:--format' = @('table', 'json')`,
+			},
+		},
+		{
+			name:    "unknown shell",
+			shell:   "tcsh",
+			wantErr: "specify one of bash, fish, powershell, zsh",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			app := &cli.App{
+				Name:   "trivy",
+				Writer: out,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "quiet", Aliases: []string{"q"}, Usage: "suppress progress bar"},
+				},
+				Commands: []*cli.Command{
+					{
+						Name:    "image",
+						Aliases: []string{"i"},
+						Usage:   "scan an image",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "format", Aliases: []string{"f"}, Usage: "format"},
+							&cli.StringFlag{Name: "severity", Aliases: []string{"s"}, Usage: "severities"},
+							&cli.StringFlag{Name: "output", Usage: "output file name"},
+						},
+					},
+					{
+						Name:    "plugin",
+						Aliases: []string{"p"},
+						Usage:   "manage plugins",
+						Subcommands: []*cli.Command{
+							{Name: "install", Aliases: []string{"i"}, Usage: "install a plugin"},
+						},
+					},
+					{
+						Name:  "diff",
+						Usage: "compare reports",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "format", Usage: "format (table, json)"},
+						},
+					},
+					{
+						Name:   "internal",
+						Hidden: true,
+					},
+				},
+			}
+			app.Commands = append(app.Commands, NewCompletionCommand())
+
+			err := app.Run([]string{"trivy", "completion", tt.shell})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			got := out.String()
+			for _, w := range tt.want {
+				assert.Contains(t, got, w)
+			}
+			assert.NotContains(t, got, "internal")
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const (
+	defaultConfigFile = "trivy.yaml"
+
+	// configMetadataKey is the key of cli.App.Metadata to store the loaded config file
+	configMetadataKey = "config-file"
+)
+
+var configFileFlag = cli.StringFlag{
+	Name:    "config",
+	Value:   defaultConfigFile,
+	Usage:   "config file with the default values of the options",
+	EnvVars: []string{"TRIVY_CONFIG"},
+}
+
+// loadConfigFile reads the config file and applies it to the global options.
+// The options of subcommands are applied by applyConfigFile when the subcommand runs.
+func loadConfigFile(c *cli.Context) error {
+	path := c.String(configFileFlag.Name)
+	values, err := readConfigFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !c.IsSet(configFileFlag.Name) {
+		// The default config file is optional
+		return nil
+	} else if err != nil {
+		return xerrors.Errorf("config file error: %w", err)
+	}
+	c.App.Metadata[configMetadataKey] = values
+
+	if err = setFlags(c, c.App.Flags, values); err != nil {
+		return err
+	}
+
+	// "--debug" and "--quiet" may be set in the config file
+	if err = log.InitLogger(c.Bool("debug"), c.Bool("quiet")); err != nil {
+		return xerrors.Errorf("failed to initialize a logger: %w", err)
+	}
+	log.Logger.Debugf("Loaded the config file: %s", path)
+	warnUnknownOptions(c.App, values)
+
+	return nil
+}
+
+// applyConfigFile sets the options of the command which are not set by the command line or environment variables
+func applyConfigFile(c *cli.Context) error {
+	values, ok := c.App.Metadata[configMetadataKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return setFlags(c, c.Command.Flags, values)
+}
+
+func setFlags(c *cli.Context, flags []cli.Flag, values map[string]interface{}) error {
+	for _, f := range flags {
+		name := f.Names()[0]
+		value, ok := values[name]
+		if !ok || c.IsSet(name) {
+			continue
+		}
+
+		var s []string
+		switch v := value.(type) {
+		case []interface{}:
+			for _, vv := range v {
+				s = append(s, fmt.Sprint(vv))
+			}
+		default:
+			s = []string{fmt.Sprint(v)}
+		}
+
+		// Comma-separated options such as "--severity" accept a list as well
+		if _, ok = f.(*cli.StringSliceFlag); !ok {
+			s = []string{strings.Join(s, ",")}
+		}
+		for _, ss := range s {
+			if err := c.Set(name, ss); err != nil {
+				return xerrors.Errorf("config file error (%s): %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readConfigFile returns the options in the config file keyed by the flag names.
+// Nested keys are joined with "-", e.g. "cache.backend" is the same as "cache-backend".
+func readConfigFile(path string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("file read error: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, xerrors.Errorf("yaml decode error (%s): %w", path, err)
+	}
+
+	values := map[string]interface{}{}
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, m, values map[string]interface{}) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "-" + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			flatten(k, nested, values)
+			continue
+		}
+		values[k] = v
+	}
+}
+
+// warnUnknownOptions warns of the options which no command accepts so that typos are noticed
+func warnUnknownOptions(app *cli.App, values map[string]interface{}) {
+	known := map[string]struct{}{}
+	addFlags := func(flags []cli.Flag) {
+		for _, f := range flags {
+			known[f.Names()[0]] = struct{}{}
+		}
+	}
+	addFlags(app.Flags)
+	for _, cmd := range app.Commands {
+		addFlags(cmd.Flags)
+	}
+
+	var unknown []string
+	for k := range values {
+		if _, ok := known[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		log.Logger.Warnf("Unknown option in the config file: %s", k)
+	}
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestConfigFile(t *testing.T) {
+	type options struct {
+		Debug    bool
+		Severity string
+		SkipDirs []string
+		ExitCode int
+		Backend  string
+	}
+
+	config := `
+debug: true
+severity:
+  - HIGH
+  - CRITICAL
+skip-dirs:
+  - node_modules
+  - vendor
+exit-code: 1
+cache:
+  backend: redis://localhost:6379
+`
+
+	tests := []struct {
+		name    string
+		config  string
+		args    []string
+		env     map[string]string
+		want    options
+		wantErr string
+	}{
+		{
+			name:   "config file",
+			config: config,
+			args:   []string{"trivy", "image"},
+			want: options{
+				Debug:    true,
+				Severity: "HIGH,CRITICAL",
+				SkipDirs: []string{"node_modules", "vendor"},
+				ExitCode: 1,
+				Backend:  "redis://localhost:6379",
+			},
+		},
+		{
+			name:   "command line takes precedence",
+			config: config,
+			args:   []string{"trivy", "image", "--severity", "LOW", "--skip-dirs", "test", "--exit-code", "0"},
+			want: options{
+				Debug:    true,
+				Severity: "LOW",
+				SkipDirs: []string{"test"},
+				ExitCode: 0,
+				Backend:  "redis://localhost:6379",
+			},
+		},
+		{
+			name:   "environment variable takes precedence",
+			config: config,
+			args:   []string{"trivy", "image"},
+			env:    map[string]string{"TRIVY_SEVERITY": "MEDIUM", "TRIVY_CACHE_BACKEND": "fs"},
+			want: options{
+				Debug:    true,
+				Severity: "MEDIUM",
+				SkipDirs: []string{"node_modules", "vendor"},
+				ExitCode: 1,
+				Backend:  "fs",
+			},
+		},
+		{
+			name: "no config file",
+			args: []string{"trivy", "image"},
+			want: options{
+				Severity: "UNKNOWN,LOW,MEDIUM,HIGH,CRITICAL",
+				SkipDirs: []string{},
+				Backend:  "fs",
+			},
+		},
+		{
+			name:    "explicit config file not found",
+			args:    []string{"trivy", "--config", "unknown.yaml", "image"},
+			wantErr: "no such file or directory",
+		},
+		{
+			name:    "invalid value",
+			config:  "exit-code: one",
+			args:    []string{"trivy", "image"},
+			wantErr: "config file error (exit-code)",
+		},
+		{
+			name:    "invalid yaml",
+			config:  "severity: [HIGH",
+			args:    []string{"trivy", "image"},
+			wantErr: "yaml decode error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.config != "" {
+				err := os.WriteFile(filepath.Join(dir, defaultConfigFile), []byte(tt.config), 0600)
+				require.NoError(t, err)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// The default config file is looked up in the working directory
+			wd, err := os.Getwd()
+			require.NoError(t, err)
+			require.NoError(t, os.Chdir(dir))
+			defer os.Chdir(wd)
+
+			// Copy the flags as urfave/cli keeps the state in them
+			severity, exitCode, cacheBackend := severityFlag, exitCodeFlag, cacheBackendFlag
+			debug, configFile := debugFlag, configFileFlag
+
+			var got options
+			cmd := &cli.Command{
+				Name: "image",
+				Action: func(c *cli.Context) error {
+					got = options{
+						Debug:    c.Bool("debug"),
+						Severity: c.String("severity"),
+						SkipDirs: c.StringSlice("skip-dirs"),
+						ExitCode: c.Int("exit-code"),
+						Backend:  c.String("cache-backend"),
+					}
+					return nil
+				},
+				Flags: []cli.Flag{
+					&severity,
+					stringSliceFlag(skipDirs),
+					&exitCode,
+					&cacheBackend,
+				},
+			}
+			withConfigFile(cmd)
+
+			app := cli.NewApp()
+			app.Flags = []cli.Flag{&debug, &configFile}
+			app.Before = loadConfigFile
+			app.Commands = []*cli.Command{cmd}
+
+			err = app.Run(tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+const envPrefix = "TRIVY_"
+
+// envVarName returns the environment variable bound to the flag, e.g. "TRIVY_SKIP_DIRS" for "--skip-dirs"
+func envVarName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// bindEnvVars binds the flags without environment variables to "TRIVY_<FLAG_NAME>"
+// so that every option can be configured in containers without templating command lines.
+func bindEnvVars(flags []cli.Flag) {
+	for _, f := range flags {
+		envVars := []string{envVarName(f.Names()[0])}
+		switch ff := f.(type) {
+		case *cli.StringFlag:
+			if len(ff.EnvVars) == 0 {
+				ff.EnvVars = envVars
+			}
+		case *cli.BoolFlag:
+			if len(ff.EnvVars) == 0 {
+				ff.EnvVars = envVars
+			}
+		case *cli.IntFlag:
+			if len(ff.EnvVars) == 0 {
+				ff.EnvVars = envVars
+			}
+		case *cli.DurationFlag:
+			if len(ff.EnvVars) == 0 {
+				ff.EnvVars = envVars
+			}
+		case *cli.StringSliceFlag:
+			if len(ff.EnvVars) == 0 {
+				ff.EnvVars = envVars
+			}
+		}
+	}
+}
+
+// withEnvVars binds the flags of the command and its subcommands to environment variables
+func withEnvVars(cmd *cli.Command) {
+	bindEnvVars(cmd.Flags)
+	for _, sub := range cmd.Subcommands {
+		withEnvVars(sub)
+	}
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func Test_bindEnvVars(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: "report"},
+		&cli.BoolFlag{Name: "skip-db-update", Aliases: []string{"skip-update"}},
+		&cli.StringSliceFlag{Name: "skip-dirs"},
+		&cli.IntFlag{Name: "exit-code"},
+		&cli.DurationFlag{Name: "timeout"},
+		&cli.StringFlag{Name: "server", EnvVars: []string{"TRIVY_REMOTE_SERVER"}},
+	}
+	bindEnvVars(flags)
+
+	var got []string
+	for _, f := range flags {
+		got = append(got, f.(cli.DocGenerationFlag).GetEnvVars()...)
+	}
+	want := []string{
+		"TRIVY_REPORT",
+		"TRIVY_SKIP_DB_UPDATE",
+		"TRIVY_SKIP_DIRS",
+		"TRIVY_EXIT_CODE",
+		"TRIVY_TIMEOUT",
+		"TRIVY_REMOTE_SERVER",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestNewApp_EnvVars(t *testing.T) {
+	app := NewApp("dev")
+
+	assertEnvVars := func(t *testing.T, flags []cli.Flag) {
+		for _, f := range flags {
+			envVars := f.(cli.DocGenerationFlag).GetEnvVars()
+			if assert.NotEmpty(t, envVars, f.Names()[0]) {
+				assert.True(t, strings.HasPrefix(envVars[0], envPrefix), f.Names()[0])
+			}
+		}
+	}
+
+	assertEnvVars(t, app.Flags)
+	for _, cmd := range app.Commands {
+		t.Run(cmd.Name, func(t *testing.T) {
+			assertEnvVars(t, cmd.Flags)
+			for _, sub := range cmd.Subcommands {
+				assertEnvVars(t, sub.Flags)
+			}
+		})
+	}
+}
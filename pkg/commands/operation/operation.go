@@ -3,15 +3,22 @@ package operation
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/wire"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/fanal/cache"
 	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	tcache "github.com/aquasecurity/trivy/pkg/cache"
 	"github.com/aquasecurity/trivy/pkg/commands/option"
 	"github.com/aquasecurity/trivy/pkg/db"
 	"github.com/aquasecurity/trivy/pkg/log"
@@ -32,32 +39,42 @@ type Cache struct {
 
 // NewCache is the factory method for Cache
 func NewCache(c option.CacheOption) (Cache, error) {
-	if strings.HasPrefix(c.CacheBackend, "redis://") {
-		log.Logger.Infof("Redis cache: %s", c.CacheBackend)
-		options, err := redis.ParseURL(c.CacheBackend)
+	if strings.HasPrefix(c.CacheBackend, option.TieredCacheBackendPrefix) {
+		remoteURL := strings.TrimPrefix(c.CacheBackend, option.TieredCacheBackendPrefix)
+		log.Logger.Infof("Tiered cache: %s in front of %s", utils.CacheDir(), remoteURL)
+		remoteCache, err := newRemoteCache(remoteURL, c)
 		if err != nil {
 			return Cache{}, err
 		}
-
-		if (option.RedisOption{}) != c.RedisOption {
-			caCert, cert, err := utils.GetTLSConfig(c.RedisCACert, c.RedisCert, c.RedisKey)
-			if err != nil {
-				return Cache{}, err
-			}
-
-			options.TLSConfig = &tls.Config{
-				RootCAs:      caCert,
-				Certificates: []tls.Certificate{cert},
-				MinVersion:   tls.VersionTLS12,
-			}
+		fsCache, err := cache.NewFSCache(utils.CacheDir())
+		if err != nil {
+			return Cache{}, xerrors.Errorf("unable to initialize fs cache: %w", err)
 		}
+		return Cache{Cache: tcache.NewTieredCache(fsCache, remoteCache)}, nil
+	}
 
-		redisCache := cache.NewRedisCache(options, c.CacheTTL)
-		return Cache{Cache: redisCache}, nil
+	if strings.HasPrefix(c.CacheBackend, "redis://") {
+		log.Logger.Infof("Redis cache: %s", c.CacheBackend)
+	} else if strings.HasPrefix(c.CacheBackend, "dynamodb://") {
+		log.Logger.Infof("DynamoDB cache: %s", c.CacheBackend)
+	}
+	if remoteCache, err := newRemoteCache(c.CacheBackend, c); err != nil {
+		return Cache{}, err
+	} else if remoteCache != nil {
+		return Cache{Cache: remoteCache}, nil
 	}
 
 	if c.CacheTTL != 0 {
-		log.Logger.Warn("'--cache-ttl' is only available with Redis cache backend")
+		log.Logger.Warn("'--cache-ttl' is only available with Redis or DynamoDB cache backend")
+	}
+	if len(c.EncryptionKey) != 0 {
+		log.Logger.Warn("'--cache-encryption-key' is only available with Redis or DynamoDB cache backend")
+	}
+	if c.CacheCompression != "" {
+		log.Logger.Warn("'--cache-compression' is only available with Redis or DynamoDB cache backend")
+	}
+	if c.CacheDedup {
+		log.Logger.Warn("'--cache-dedup' is only available with Redis cache backend")
 	}
 
 	// standalone mode
@@ -68,6 +85,126 @@ func NewCache(c option.CacheOption) (Cache, error) {
 	return Cache{Cache: fsCache}, nil
 }
 
+// newRemoteCache returns the cache shared by the scans, or nil for the local cache.
+// Only the values in the remote cache are encrypted, as the local cache is private to the scan.
+func newRemoteCache(backend string, c option.CacheOption) (cache.Cache, error) {
+	if !strings.HasPrefix(backend, "redis://") && !strings.HasPrefix(backend, "dynamodb://") {
+		return nil, nil
+	}
+
+	var cipher *tcache.Cipher
+	if len(c.EncryptionKey) != 0 {
+		var err error
+		if cipher, err = tcache.NewCipher(c.EncryptionKey); err != nil {
+			return nil, xerrors.Errorf("cache encryption error: %w", err)
+		}
+		log.Logger.Debugf("Cache values are encrypted with the key %s", cipher.KeyID())
+	}
+
+	if strings.HasPrefix(backend, "redis://") {
+		return newRedisCache(backend, c, cipher)
+	}
+	return newDynamoDBCache(backend, c, cipher)
+}
+
+func newRedisCache(redisURL string, c option.CacheOption, cipher *tcache.Cipher) (tcache.RedisCache, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return tcache.RedisCache{}, err
+	}
+
+	if (option.RedisOption{}) != c.RedisOption {
+		caCert, cert, err := utils.GetTLSConfig(c.RedisCACert, c.RedisCert, c.RedisKey)
+		if err != nil {
+			return tcache.RedisCache{}, err
+		}
+
+		options.TLSConfig = &tls.Config{
+			RootCAs:      caCert,
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	return tcache.NewRedisCache(options, c.CacheTTL, c.RedisBatchSize, cipher, tcache.Compression(c.CacheCompression),
+		c.CacheDedup), nil
+}
+
+// newDynamoDBCache returns the cache in the DynamoDB table with the default credential chain,
+// e.g. dynamodb://trivy-cache?region=us-east-1.
+// "endpoint" in the query is used for DynamoDB compatible services, e.g. DynamoDB Local.
+func newDynamoDBCache(dynamoDBURL string, c option.CacheOption, cipher *tcache.Cipher) (tcache.DynamoDBCache, error) {
+	u, err := url.Parse(dynamoDBURL)
+	if err != nil {
+		return tcache.DynamoDBCache{}, xerrors.Errorf("invalid DynamoDB URL: %w", err)
+	} else if u.Host == "" {
+		return tcache.DynamoDBCache{}, xerrors.Errorf("no table name in the DynamoDB URL: %s", dynamoDBURL)
+	}
+
+	cfg := aws.NewConfig()
+	query := u.Query()
+	if region := query.Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return tcache.DynamoDBCache{}, xerrors.Errorf("aws session error: %w", err)
+	}
+	if c.CacheDedup {
+		log.Logger.Warn("'--cache-dedup' is only available with Redis cache backend")
+	}
+	return tcache.NewDynamoDBCache(dynamodb.New(sess), u.Host, c.CacheTTL, cipher, tcache.Compression(c.CacheCompression)), nil
+}
+
+// backend returns the cache backend, which is the remote tier of the tiered cache
+func (c Cache) backend() cache.Cache {
+	if tieredCache, ok := c.Cache.(tcache.TieredCache); ok {
+		return tieredCache.Remote()
+	}
+	return c.Cache
+}
+
+// redisCache returns the Redis cache of the backend
+func (c Cache) redisCache() (tcache.RedisCache, bool) {
+	redisCache, ok := c.backend().(tcache.RedisCache)
+	return redisCache, ok
+}
+
+// pinger is the cache backend checking if it is reachable, e.g. Redis and DynamoDB
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks if the cache backend is reachable
+func (c Cache) Ping(ctx context.Context) error {
+	p, ok := c.backend().(pinger)
+	if !ok {
+		// The local cache is always available
+		return nil
+	}
+	if err := p.Ping(ctx); err != nil {
+		return xerrors.Errorf("unable to connect to the cache backend: %w", err)
+	}
+	return nil
+}
+
+// Lock acquires the lock of the key shared by the server replicas using the same cache backend
+func (c Cache) Lock(ctx context.Context, key string, ttl time.Duration) (func() error, bool, error) {
+	redisCache, ok := c.redisCache()
+	if !ok {
+		// The local cache is not shared
+		return func() error { return nil }, true, nil
+	}
+	return redisCache.Lock(ctx, key, ttl)
+}
+
 // Reset resets the cache
 func (c Cache) Reset() (err error) {
 	if err := c.ClearDB(); err != nil {
@@ -98,8 +235,8 @@ func (c Cache) ClearArtifacts() error {
 }
 
 // DownloadDB downloads the DB
-func DownloadDB(appVersion, cacheDir, dbRepository string, quiet, skipUpdate bool) error {
-	client := db.NewClient(cacheDir, quiet, db.WithDBRepository(dbRepository))
+func DownloadDB(appVersion, cacheDir, dbRepository string, quiet, skipUpdate bool, rootCAs *x509.CertPool) error {
+	client := db.NewClient(cacheDir, quiet, db.WithDBRepository(dbRepository), db.WithRootCAs(rootCAs))
 	ctx := context.Background()
 	needsUpdate, err := client.NeedsUpdate(appVersion, skipUpdate)
 	if err != nil {
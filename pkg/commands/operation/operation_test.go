@@ -0,0 +1,64 @@
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/cache"
+	tcache "github.com/aquasecurity/trivy/pkg/cache"
+)
+
+// unreachableDynamoDB fails to describe the table
+type unreachableDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+}
+
+func (unreachableDynamoDB) DescribeTableWithContext(aws.Context, *dynamodb.DescribeTableInput,
+	...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return nil, &dynamodb.ResourceNotFoundException{}
+}
+
+func TestCache_Ping(t *testing.T) {
+	fsCache, err := cache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	defer fsCache.Close()
+	dynamoDBCache := tcache.NewDynamoDBCache(unreachableDynamoDB{}, "trivy", 0, nil, "")
+
+	tests := []struct {
+		name    string
+		cache   cache.Cache
+		wantErr string
+	}{
+		{
+			name:  "local cache",
+			cache: fsCache,
+		},
+		{
+			name:    "DynamoDB",
+			cache:   dynamoDBCache,
+			wantErr: "unable to connect to the cache backend",
+		},
+		{
+			name:    "DynamoDB behind the local cache",
+			cache:   tcache.NewTieredCache(fsCache, dynamoDBCache),
+			wantErr: "unable to describe the DynamoDB table (trivy)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Cache{Cache: tt.cache}.Ping(context.Background())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
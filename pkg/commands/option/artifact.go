@@ -2,23 +2,84 @@ package option
 
 import (
 	"os"
+	"strings"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
 )
 
+// RootfsDirInputPrefix is the prefix of "--input" for image filesystems unpacked by other tools.
+// e.g. --input rootfs-dir:/path/to/bundle/rootfs
+const RootfsDirInputPrefix = "rootfs-dir:"
+
+// scanOrders are the orders to scan the targets in the input list. The first one is the default.
+var scanOrders = []string{"list", "newest"}
+
 // ArtifactOption holds the options for an artifact scanning
 type ArtifactOption struct {
 	Input      string
+	InputList  string
+	OutputDir  string
 	Timeout    time.Duration
+	ScanBudget time.Duration
 	ClearCache bool
 	Insecure   bool
 
 	SkipDirs    []string
 	SkipFiles   []string
 	OfflineScan bool
+	WorkDir     string
+
+	// Parallel is the number of layers and files analyzed at the same time
+	Parallel int
+
+	// these variables are not exported
+	maxMemory    string
+	maxImageSize string
+
+	// MaxMemory is the total size of the files in layers kept in memory, populated in Init()
+	MaxMemory int64
+
+	// MaxImageSize refuses the larger images before pulling the layers, populated in Init(),
+	// and MaxFiles aborts the analysis of the artifacts with more files
+	MaxImageSize int64
+	MaxFiles     int
+
+	// MaxArchiveDepth is how deep the nested zip archives are unpacked
+	MaxArchiveDepth int
+
+	// FileTimeout is the time budget to analyze each file, and the files exceeding it are skipped
+	FileTimeout time.Duration
+
+	// AnalyzerTimeouts are the time budgets of the analyzers per file, populated in Init() from "<analyzer>=<duration>"
+	analyzerTimeouts []string
+	AnalyzerTimeouts map[analyzer.Type]time.Duration
+
+	// AnalysisTimeout and DetectionTimeout limit the phases of each scan within Timeout
+	AnalysisTimeout  time.Duration
+	DetectionTimeout time.Duration
+
+	// DetectUnpinned analyzes the version ranges in the manifests without lock files
+	DetectUnpinned bool
+
+	// ScanOrder and PriorityLabels order the targets in the input list
+	ScanOrder      string
+	PriorityLabels []string
+
+	// ImagesFile lists the images analyzed in advance by "trivy cache warm"
+	ImagesFile string
+
+	// Remote is the remote host whose filesystem is scanned over SSH, e.g. ssh://user@host/path,
+	// authenticated with SSHKey and verified with SSHKnownHosts
+	Remote        string
+	SSHKey        string
+	SSHKnownHosts string
 
 	// this field is populated in Init()
 	Target string
@@ -28,23 +89,129 @@ type ArtifactOption struct {
 func NewArtifactOption(c *cli.Context) ArtifactOption {
 	return ArtifactOption{
 		Input:       c.String("input"),
+		InputList:   c.String("input-list"),
+		OutputDir:   c.String("output-dir"),
 		Timeout:     c.Duration("timeout"),
+		ScanBudget:  c.Duration("scan-budget"),
 		ClearCache:  c.Bool("clear-cache"),
 		SkipFiles:   c.StringSlice("skip-files"),
 		SkipDirs:    c.StringSlice("skip-dirs"),
 		OfflineScan: c.Bool("offline-scan"),
 		Insecure:    c.Bool("insecure"),
+		WorkDir:     c.String("workdir"),
+		Parallel:    c.Int("parallel"),
+		maxMemory:   c.String("max-memory"),
+
+		maxImageSize: c.String("max-image-size"),
+		MaxFiles:     c.Int("max-files"),
+
+		MaxArchiveDepth: c.Int("max-archive-depth"),
+		FileTimeout:     c.Duration("file-timeout"),
+
+		analyzerTimeouts: c.StringSlice("analyzer-timeout"),
+		AnalysisTimeout:  c.Duration("analysis-timeout"),
+		DetectionTimeout: c.Duration("detection-timeout"),
+
+		DetectUnpinned: c.Bool("detect-unpinned"),
+
+		ScanOrder:      c.String("scan-order"),
+		PriorityLabels: c.StringSlice("priority-label"),
+
+		ImagesFile: c.String("images-file"),
+
+		Remote:        c.String("remote"),
+		SSHKey:        c.String("ssh-key"),
+		SSHKnownHosts: c.String("ssh-known-hosts"),
 	}
 }
 
 // Init initialize the CLI context for artifact scanning
 func (c *ArtifactOption) Init(ctx *cli.Context, logger *zap.SugaredLogger) (err error) {
 
-	// kubernetes subcommand doesn't require any argument
-	if ctx.Command.Name == "kubernetes" {
+	// kubernetes and aws subcommands don't require any argument
+	if ctx.Command.Name == "kubernetes" || ctx.Command.Name == "aws" {
 		return nil
 	}
 
+	if c.Parallel < 0 {
+		return xerrors.New("'--parallel' must not be negative")
+	}
+
+	if c.MaxArchiveDepth < 0 {
+		return xerrors.New("'--max-archive-depth' must not be negative")
+	}
+
+	if c.FileTimeout < 0 {
+		return xerrors.New("'--file-timeout' must not be negative")
+	}
+
+	if c.AnalyzerTimeouts, err = parseAnalyzerTimeouts(c.analyzerTimeouts); err != nil {
+		return xerrors.Errorf("invalid '--analyzer-timeout': %w", err)
+	}
+
+	if c.AnalysisTimeout < 0 || c.DetectionTimeout < 0 {
+		return xerrors.New("'--analysis-timeout' and '--detection-timeout' must not be negative")
+	}
+
+	if c.maxMemory != "" {
+		if c.MaxMemory, err = units.RAMInBytes(c.maxMemory); err != nil {
+			return xerrors.Errorf("invalid '--max-memory': %w", err)
+		}
+	}
+
+	if c.maxImageSize != "" {
+		if c.MaxImageSize, err = units.RAMInBytes(c.maxImageSize); err != nil {
+			return xerrors.Errorf("invalid '--max-image-size': %w", err)
+		}
+	}
+
+	if c.MaxFiles < 0 {
+		return xerrors.New("'--max-files' must not be negative")
+	}
+
+	// a report is written per target in the list
+	if c.OutputDir != "" && c.InputList == "" {
+		return xerrors.New(`"--output-dir" can be used only with "--input-list"`)
+	}
+
+	// the targets are described in the list
+	if c.InputList != "" {
+		if c.Input != "" || ctx.Args().Len() > 0 {
+			logger.Error(`"--input-list" cannot be used with a target or "--input"`)
+			return xerrors.New("arguments error")
+		}
+		if c.ScanOrder != "" && !slices.Contains(scanOrders, c.ScanOrder) {
+			return xerrors.Errorf("--scan-order must be %q", scanOrders)
+		}
+		return nil
+	}
+
+	// the images are listed in the file
+	if c.ImagesFile != "" {
+		if ctx.Args().Len() > 0 {
+			logger.Error(`"--images-file" cannot be used with a target`)
+			return xerrors.New("arguments error")
+		}
+		return nil
+	}
+
+	// the target is on the remote host
+	if c.Remote != "" {
+		if c.Input != "" || ctx.Args().Len() > 0 {
+			logger.Error(`"--remote" cannot be used with a target or "--input"`)
+			return xerrors.New("arguments error")
+		}
+		if !strings.HasPrefix(c.Remote, "ssh://") {
+			return xerrors.Errorf("invalid '--remote' %q, must be ssh://[user@]host[:port]/path", c.Remote)
+		}
+		c.Target = c.Remote
+		return nil
+	}
+
+	if (c.ScanOrder != "" && c.ScanOrder != scanOrders[0]) || len(c.PriorityLabels) > 0 {
+		logger.Warn(`"--scan-order" and "--priority-label" can be used only with "--input-list"`)
+	}
+
 	if c.Input == "" && ctx.Args().Len() == 0 {
 		logger.Debug(`trivy requires at least 1 argument or --input option`)
 		_ = cli.ShowSubcommandHelp(ctx) // nolint: errcheck
@@ -54,9 +221,32 @@ func (c *ArtifactOption) Init(ctx *cli.Context, logger *zap.SugaredLogger) (err
 		return xerrors.New("arguments error")
 	}
 
-	if c.Input == "" {
+	// The image name of the unpacked filesystem can be given as the argument
+	if c.Input == "" || strings.HasPrefix(c.Input, RootfsDirInputPrefix) {
 		c.Target = ctx.Args().First()
 	}
 
 	return nil
 }
+
+// parseAnalyzerTimeouts parses the time budgets of the analyzers, e.g. "secret=10s"
+func parseAnalyzerTimeouts(values []string) (map[analyzer.Type]time.Duration, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	timeouts := map[analyzer.Type]time.Duration{}
+	for _, v := range values {
+		t, d, ok := strings.Cut(v, "=")
+		if !ok || t == "" {
+			return nil, xerrors.Errorf("%q must be <analyzer>=<duration>, e.g. secret=10s", v)
+		}
+		timeout, err := time.ParseDuration(d)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid duration of %s: %w", t, err)
+		} else if timeout <= 0 {
+			return nil, xerrors.Errorf("the timeout of %s must be positive", t)
+		}
+		timeouts[analyzer.Type(t)] = timeout
+	}
+	return timeouts, nil
+}
@@ -3,7 +3,9 @@ package option_test
 import (
 	"flag"
 	"testing"
+	"time"
 
+	"github.com/aquasecurity/fanal/analyzer"
 	"github.com/aquasecurity/trivy/pkg/commands/option"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +29,71 @@ func TestArtifactOption_Init(t *testing.T) {
 				Target: "alpine:3.10",
 			},
 		},
+		{
+			name: "happy path with input list",
+			args: []string{"--input-list", "targets.yaml"},
+			want: option.ArtifactOption{
+				InputList: "targets.yaml",
+			},
+		},
+		{
+			name: "happy path with input list and output dir",
+			args: []string{"--input-list", "images.txt", "--output-dir", "reports"},
+			want: option.ArtifactOption{
+				InputList: "images.txt",
+				OutputDir: "reports",
+			},
+		},
+		{
+			name: "happy path with unpacked image filesystem",
+			args: []string{"--input", "rootfs-dir:/tmp/rootfs", "ghcr.io/org/app@sha256:4c0fa34d8d0b1c2ab1e77b0d4b2e0b6cde4f63cc9500b5bcaabe4e58e931fb4c"},
+			want: option.ArtifactOption{
+				Input:  "rootfs-dir:/tmp/rootfs",
+				Target: "ghcr.io/org/app@sha256:4c0fa34d8d0b1c2ab1e77b0d4b2e0b6cde4f63cc9500b5bcaabe4e58e931fb4c",
+			},
+		},
+		{
+			name: "happy path with parallel",
+			args: []string{"--parallel", "10", "alpine:3.10"},
+			want: option.ArtifactOption{
+				Parallel: 10,
+				Target:   "alpine:3.10",
+			},
+		},
+		{
+			name: "happy path with remote",
+			args: []string{"--remote", "ssh://root@10.0.0.1/"},
+			want: option.ArtifactOption{
+				Remote: "ssh://root@10.0.0.1/",
+				Target: "ssh://root@10.0.0.1/",
+			},
+		},
+		{
+			name: "sad: remote with a target",
+			args: []string{"--remote", "ssh://root@10.0.0.1/", "/"},
+			logs: []string{
+				`"--remote" cannot be used with a target or "--input"`,
+			},
+			wantErr: "arguments error",
+		},
+		{
+			name:    "sad: remote without ssh",
+			args:    []string{"--remote", "root@10.0.0.1:/"},
+			wantErr: `invalid '--remote' "root@10.0.0.1:/"`,
+		},
+		{
+			name: "sad: input list with a target",
+			args: []string{"--input-list", "targets.yaml", "alpine:3.10"},
+			logs: []string{
+				`"--input-list" cannot be used with a target or "--input"`,
+			},
+			wantErr: "arguments error",
+		},
+		{
+			name:    "sad: output dir without input list",
+			args:    []string{"--output-dir", "reports", "alpine:3.10"},
+			wantErr: `"--output-dir" can be used only with "--input-list"`,
+		},
 		{
 			name: "sad: multiple image names",
 			args: []string{"centos:7", "alpine:3.10"},
@@ -35,6 +102,41 @@ func TestArtifactOption_Init(t *testing.T) {
 			},
 			wantErr: "arguments error",
 		},
+		{
+			name:    "sad: negative parallel",
+			args:    []string{"--parallel", "-1", "alpine:3.10"},
+			wantErr: "'--parallel' must not be negative",
+		},
+		{
+			name:    "sad: negative max archive depth",
+			args:    []string{"--max-archive-depth", "-1", "alpine:3.10"},
+			wantErr: "'--max-archive-depth' must not be negative",
+		},
+		{
+			name:    "sad: negative file timeout",
+			args:    []string{"--file-timeout", "-1s", "alpine:3.10"},
+			wantErr: "'--file-timeout' must not be negative",
+		},
+		{
+			name:    "sad: invalid max memory",
+			args:    []string{"--max-memory", "lots", "alpine:3.10"},
+			wantErr: "invalid '--max-memory'",
+		},
+		{
+			name:    "sad: invalid max image size",
+			args:    []string{"--max-image-size", "huge", "alpine:3.10"},
+			wantErr: "invalid '--max-image-size'",
+		},
+		{
+			name:    "sad: negative max files",
+			args:    []string{"--max-files", "-1", "alpine:3.10"},
+			wantErr: "'--max-files' must not be negative",
+		},
+		{
+			name:    "sad: negative analysis timeout",
+			args:    []string{"--analysis-timeout", "-1m", "alpine:3.10"},
+			wantErr: "'--analysis-timeout' and '--detection-timeout' must not be negative",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -43,6 +145,18 @@ func TestArtifactOption_Init(t *testing.T) {
 
 			app := cli.NewApp()
 			set := flag.NewFlagSet("test", 0)
+			set.String("input-list", "", "")
+			set.String("output-dir", "", "")
+			set.String("input", "", "")
+			set.Int("parallel", 0, "")
+			set.String("max-memory", "", "")
+			set.String("max-image-size", "", "")
+			set.Int("max-files", 0, "")
+			set.Int("max-archive-depth", 0, "")
+			set.Duration("file-timeout", 0, "")
+			set.Duration("analysis-timeout", 0, "")
+			set.Duration("detection-timeout", 0, "")
+			set.String("remote", "", "")
 			ctx := cli.NewContext(app, set, nil)
 			_ = set.Parse(tt.args)
 
@@ -71,3 +185,59 @@ func TestArtifactOption_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestArtifactOption_Init_analyzerTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[analyzer.Type]time.Duration
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			args: []string{"--analyzer-timeout", "secret=10s", "--analyzer-timeout", "jar=1m", "alpine:3.10"},
+			want: map[analyzer.Type]time.Duration{
+				analyzer.TypeSecret: 10 * time.Second,
+				analyzer.TypeJar:    time.Minute,
+			},
+		},
+		{
+			name: "no timeout",
+			args: []string{"alpine:3.10"},
+		},
+		{
+			name:    "sad: invalid format",
+			args:    []string{"--analyzer-timeout", "secret", "alpine:3.10"},
+			wantErr: `invalid '--analyzer-timeout': "secret" must be <analyzer>=<duration>`,
+		},
+		{
+			name:    "sad: invalid duration",
+			args:    []string{"--analyzer-timeout", "secret=10", "alpine:3.10"},
+			wantErr: "invalid duration of secret",
+		},
+		{
+			name:    "sad: zero timeout",
+			args:    []string{"--analyzer-timeout", "secret=0s", "alpine:3.10"},
+			wantErr: "the timeout of secret must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Var(cli.NewStringSlice(), "analyzer-timeout", "")
+			ctx := cli.NewContext(app, set, nil)
+			require.NoError(t, set.Parse(tt.args))
+
+			c := option.NewArtifactOption(ctx)
+			err := c.Init(ctx, zap.NewNop().Sugar())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.AnalyzerTimeouts)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package option
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// AWSOption holds the options for AWS account scanning
+type AWSOption struct {
+	Regions  []string
+	Services []string
+}
+
+// NewAWSOption is the factory method to return AWS options
+func NewAWSOption(c *cli.Context) AWSOption {
+	return AWSOption{
+		Regions:  c.StringSlice("region"),
+		Services: c.StringSlice("service"),
+	}
+}
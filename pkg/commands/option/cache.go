@@ -1,18 +1,37 @@
 package option
 
 import (
+	"encoding/base64"
 	"strings"
 	"time"
 
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/cache"
+	"github.com/aquasecurity/trivy/pkg/credential"
 )
 
+// TieredCacheBackendPrefix is the prefix of the Redis or DynamoDB URL for the local cache in front of it,
+// e.g. fs+redis://localhost:6379
+const TieredCacheBackendPrefix = "fs+"
+
 // CacheOption holds the options for cache
 type CacheOption struct {
-	CacheBackend string
-	CacheTTL     time.Duration
+	CacheBackend   string
+	CacheTTL       time.Duration
+	RedisBatchSize int
 	RedisOption
+
+	// CacheEncryptionKey is the base64-encoded AES key or the reference to it, and EncryptionKey is the decoded key
+	CacheEncryptionKey string
+	EncryptionKey      []byte
+
+	// CacheCompression is the compression of the values in Redis or DynamoDB, the default of the backend if empty,
+	// and CacheDedup stores the analysis results of the same layers once in Redis
+	CacheCompression string
+	CacheDedup       bool
 }
 
 // RedisOption holds the options for redis cache
@@ -25,29 +44,72 @@ type RedisOption struct {
 // NewCacheOption returns an instance of CacheOption
 func NewCacheOption(c *cli.Context) CacheOption {
 	return CacheOption{
-		CacheBackend: c.String("cache-backend"),
-		CacheTTL:     c.Duration("cache-ttl"),
+		CacheBackend:   c.String("cache-backend"),
+		CacheTTL:       c.Duration("cache-ttl"),
+		RedisBatchSize: c.Int("redis-batch-size"),
 		RedisOption: RedisOption{
 			RedisCACert: c.String("redis-ca"),
 			RedisCert:   c.String("redis-cert"),
 			RedisKey:    c.String("redis-key"),
 		},
+		CacheEncryptionKey: c.String("cache-encryption-key"),
+		CacheCompression:   c.String("cache-compression"),
+		CacheDedup:         c.Bool("cache-dedup"),
 	}
 }
 
 // Init initialize the CacheOption
 func (c *CacheOption) Init() error {
-	// "redis://" or "fs" are allowed for now
+	// The redis URL can be stored in a secret manager as it may contain the password
+	backend, err := credential.Resolve(c.CacheBackend)
+	if err != nil {
+		return xerrors.Errorf("--cache-backend error: %w", err)
+	}
+	c.CacheBackend = backend
+
+	// "redis://", "dynamodb://", "fs+" in front of them or "fs" are allowed for now
 	// An empty value is also allowed for testability
-	if !strings.HasPrefix(c.CacheBackend, "redis://") &&
+	remote := strings.TrimPrefix(c.CacheBackend, TieredCacheBackendPrefix)
+	if !strings.HasPrefix(remote, "redis://") && !strings.HasPrefix(remote, "dynamodb://") &&
 		c.CacheBackend != "fs" && c.CacheBackend != "" {
 		return xerrors.Errorf("unsupported cache backend: %s", c.CacheBackend)
 	}
+	if c.RedisBatchSize < 0 {
+		return xerrors.Errorf("--redis-batch-size must not be negative: %d", c.RedisBatchSize)
+	}
 	// if one of redis option not nil, make sure CA, cert, and key provided
 	if (RedisOption{}) != c.RedisOption {
 		if c.RedisCACert == "" || c.RedisCert == "" || c.RedisKey == "" {
 			return xerrors.Errorf("you must provide CA, cert and key file path when using tls")
 		}
 	}
+	if c.CacheCompression != "" && !slices.Contains(cache.Compressions, cache.Compression(c.CacheCompression)) {
+		return xerrors.Errorf("unknown --cache-compression: %s, must be one of %q", c.CacheCompression, cache.Compressions)
+	}
+	if err = c.initEncryptionKey(); err != nil {
+		return xerrors.Errorf("--cache-encryption-key error: %w", err)
+	}
+	return nil
+}
+
+// initEncryptionKey fetches the key from the secret manager or decrypts it with AWS KMS if it is a reference
+func (c *CacheOption) initEncryptionKey() error {
+	if c.CacheEncryptionKey == "" {
+		return nil
+	}
+	encoded, err := credential.Resolve(c.CacheEncryptionKey)
+	if err != nil {
+		return err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return xerrors.Errorf("the key must be base64-encoded: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return xerrors.Errorf("the key must be 16, 24 or 32 bytes for AES, but %d bytes", len(key))
+	}
+	c.EncryptionKey = key
 	return nil
 }
@@ -48,12 +48,16 @@ func TestNewCacheOption(t *testing.T) {
 
 func TestCacheOption_Init(t *testing.T) {
 	type fields struct {
-		backend string
+		backend       string
+		batchSize     int
+		encryptionKey string
+		compression   string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		wantErr string
+		name              string
+		fields            fields
+		wantEncryptionKey []byte
+		wantErr           string
 	}{
 		{
 			name: "fs",
@@ -67,6 +71,18 @@ func TestCacheOption_Init(t *testing.T) {
 				backend: "redis://localhost:6379",
 			},
 		},
+		{
+			name: "tiered",
+			fields: fields{
+				backend: "fs+redis://localhost:6379",
+			},
+		},
+		{
+			name: "dynamodb",
+			fields: fields{
+				backend: "dynamodb://trivy-cache?region=us-east-1",
+			},
+		},
 		{
 			name: "sad path",
 			fields: fields{
@@ -74,11 +90,53 @@ func TestCacheOption_Init(t *testing.T) {
 			},
 			wantErr: "unsupported cache backend: unknown://",
 		},
+		{
+			name: "sad path: negative batch size",
+			fields: fields{
+				backend:   "redis://localhost:6379",
+				batchSize: -1,
+			},
+			wantErr: "--redis-batch-size must not be negative: -1",
+		},
+		{
+			name: "encryption key",
+			fields: fields{
+				backend:       "redis://localhost:6379",
+				encryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+			},
+			wantEncryptionKey: []byte("0123456789abcdef0123456789abcdef"),
+		},
+		{
+			name: "sad path: invalid encryption key",
+			fields: fields{
+				backend:       "redis://localhost:6379",
+				encryptionKey: "MDEyMzQ1Njc4OQ==",
+			},
+			wantErr: "--cache-encryption-key error: the key must be 16, 24 or 32 bytes for AES, but 10 bytes",
+		},
+		{
+			name: "zstd",
+			fields: fields{
+				backend:     "redis://localhost:6379",
+				compression: "zstd",
+			},
+		},
+		{
+			name: "sad path: unknown compression",
+			fields: fields{
+				backend:     "redis://localhost:6379",
+				compression: "lz4",
+			},
+			wantErr: `unknown --cache-compression: lz4, must be one of ["none" "gzip" "zstd"]`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &option.CacheOption{
-				CacheBackend: tt.fields.backend,
+				CacheBackend:       tt.fields.backend,
+				RedisBatchSize:     tt.fields.batchSize,
+				CacheEncryptionKey: tt.fields.encryptionKey,
+				CacheCompression:   tt.fields.compression,
 			}
 
 			err := c.Init()
@@ -86,6 +144,7 @@ func TestCacheOption_Init(t *testing.T) {
 				assert.EqualError(t, err, tt.wantErr, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.wantEncryptionKey, c.EncryptionKey)
 			}
 		})
 	}
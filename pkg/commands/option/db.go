@@ -1,10 +1,17 @@
 package option
 
 import (
+	"crypto/x509"
+	"strings"
+
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/progress"
+	"github.com/aquasecurity/trivy/pkg/result"
+	"github.com/aquasecurity/trivy/pkg/utils"
 )
 
 // DBOption holds the options for trivy DB
@@ -14,7 +21,21 @@ type DBOption struct {
 	SkipDBUpdate   bool
 	Light          bool
 	NoProgress     bool
+	Progress       string
 	DBRepository   string
+
+	// Locale is the language preferred for the titles and the descriptions of the vulnerabilities
+	Locale string
+
+	// OSV queries OSV.dev for the ecosystems which the DB doesn't cover
+	OSV    bool
+	OSVURL string
+
+	// this variable is not exported
+	dbCAs []string
+
+	// this field is populated in Init()
+	DBRootCAs *x509.CertPool
 }
 
 // NewDBOption is the factory method to return the DBOption
@@ -25,7 +46,12 @@ func NewDBOption(c *cli.Context) DBOption {
 		SkipDBUpdate:   c.Bool("skip-db-update"),
 		Light:          c.Bool("light"),
 		NoProgress:     c.Bool("no-progress"),
+		Progress:       c.String("progress"),
 		DBRepository:   c.String("db-repository"),
+		Locale:         c.String("locale"),
+		OSV:            c.Bool("osv"),
+		OSVURL:         c.String("osv-url"),
+		dbCAs:          c.StringSlice("db-ca"),
 	}
 }
 
@@ -34,8 +60,23 @@ func (c *DBOption) Init() (err error) {
 	if c.SkipDBUpdate && c.DownloadDBOnly {
 		return xerrors.New("--skip-db-update and --download-db-only options can not be specified both")
 	}
+	if c.Progress != "" && !slices.Contains(progress.Formats, c.Progress) {
+		return xerrors.Errorf("unknown progress format: %s, supported: %s", c.Progress, strings.Join(progress.Formats, ", "))
+	}
+	// The events replace the progress bars
+	if c.Progress == progress.FormatJSON {
+		c.NoProgress = true
+	}
 	if c.Light {
 		log.Logger.Warn("'--light' option is deprecated and will be removed. See also: https://github.com/aquasecurity/trivy/discussions/1649")
 	}
+	if c.Locale != "" {
+		if c.Locale, err = result.ParseLocale(c.Locale); err != nil {
+			return xerrors.Errorf("--locale error: %w", err)
+		}
+	}
+	if c.DBRootCAs, err = utils.LoadCertPool(c.dbCAs); err != nil {
+		return xerrors.Errorf("--db-ca error: %w", err)
+	}
 	return nil
 }
@@ -46,11 +46,14 @@ func TestDBOption_Init(t *testing.T) {
 		DownloadDBOnly bool
 		SkipUpdate     bool
 		Light          bool
+		Progress       string
+		Locale         string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		wantErr string
+		name           string
+		fields         fields
+		wantNoProgress bool
+		wantErr        string
 	}{
 		{
 			name: "happy path",
@@ -66,6 +69,39 @@ func TestDBOption_Init(t *testing.T) {
 			},
 			wantErr: "--skip-db-update and --download-db-only options can not be specified both",
 		},
+		{
+			name: "happy path: progress bar",
+			fields: fields{
+				Progress: "bar",
+			},
+		},
+		{
+			name: "happy path: progress events replace the progress bar",
+			fields: fields{
+				Progress: "json",
+			},
+			wantNoProgress: true,
+		},
+		{
+			name: "sad path: unknown progress format",
+			fields: fields{
+				Progress: "xml",
+			},
+			wantErr: "unknown progress format: xml, supported: bar, json",
+		},
+		{
+			name: "happy path: locale",
+			fields: fields{
+				Locale: "ja_JP.UTF-8",
+			},
+		},
+		{
+			name: "sad path: unsupported locale",
+			fields: fields{
+				Locale: "fr",
+			},
+			wantErr: `--locale error: unsupported locale "fr", supported locales: ["en" "ja"]`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -74,6 +110,8 @@ func TestDBOption_Init(t *testing.T) {
 				DownloadDBOnly: tt.fields.DownloadDBOnly,
 				SkipDBUpdate:   tt.fields.SkipUpdate,
 				Light:          tt.fields.Light,
+				Progress:       tt.fields.Progress,
+				Locale:         tt.fields.Locale,
 			}
 
 			err := c.Init()
@@ -81,6 +119,7 @@ func TestDBOption_Init(t *testing.T) {
 				assert.EqualError(t, err, tt.wantErr, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.wantNoProgress, c.NoProgress)
 			}
 		})
 	}
@@ -17,6 +17,7 @@ type GlobalOption struct {
 	Quiet      bool
 	Debug      bool
 	CacheDir   string
+	ModuleDir  string
 }
 
 // NewGlobalOption is the factory method to return GlobalOption
@@ -36,5 +37,6 @@ func NewGlobalOption(c *cli.Context) (GlobalOption, error) {
 		Quiet:      quiet,
 		Debug:      debug,
 		CacheDir:   c.String("cache-dir"),
+		ModuleDir:  c.String("module-dir"),
 	}, nil
 }
@@ -1,17 +1,151 @@
 package option
 
 import (
+	"crypto"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/client"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/attestation"
+	"github.com/aquasecurity/trivy/pkg/utils"
 )
 
+const (
+	// SbomSourceOCI looks up the SBOM attestations attached to the images in the registries by "cosign attest"
+	SbomSourceOCI = "oci"
+
+	// SbomSourceRekor looks up the SBOM attestations uploaded to the Rekor transparency log by "cosign attest"
+	SbomSourceRekor = "rekor"
+)
+
+var supportedSbomSources = []string{SbomSourceOCI, SbomSourceRekor}
+
+// dockerCertFiles are the files in '--docker-cert-path', the same as DOCKER_CERT_PATH of the Docker CLI
+var dockerCertFiles = []string{"ca.pem", "cert.pem", "key.pem"}
+
 // ImageOption holds the options for scanning images
 type ImageOption struct {
 	ScanRemovedPkgs bool
+	ESM             bool
+	RebuildOf       string
+	RequireDigest   bool
+	ContentStore    string
+	IncludeMounts   bool
+
+	// DockerHost is the Docker daemon to read the images and the containers from, e.g. tcp://build-host:2376,
+	// authenticated with the client certificate in DockerCertPath
+	DockerHost     string
+	DockerCertPath string
+
+	// Attest signs the report as an in-toto attestation with AttestKey, or keyless with cosign if it is empty.
+	// The attestation is written to AttestOutput and/or attached to the image with AttestUpload.
+	Attest       bool
+	AttestKey    string
+	AttestOutput string
+	AttestUpload bool
+
+	// SbomSources are looked up for the SBOM of the image before pulling the layers,
+	// and the SBOM attestations attached to the image are trusted only if they are signed by SbomAttestationKey.
+	SbomSources        []string
+	SbomAttestationKey string
+	RekorURL           string
+
+	// these variables are not exported
+	registryCAs []string
+	sbomSources string
+
+	// these fields are populated in Init()
+	RegistryRootCAs *x509.CertPool
+	AttestSigner    crypto.Signer
+	SbomPublicKey   crypto.PublicKey
 }
 
 // NewImageOption is the factory method to return ImageOption
 func NewImageOption(c *cli.Context) ImageOption {
 	return ImageOption{
-		ScanRemovedPkgs: c.Bool("removed-pkgs"),
+		ScanRemovedPkgs:    c.Bool("removed-pkgs"),
+		ESM:                c.Bool("esm"),
+		RebuildOf:          c.String("annotate-rebuild-of"),
+		RequireDigest:      c.Bool("require-digest"),
+		ContentStore:       c.String("content-store"),
+		IncludeMounts:      c.Bool("include-mounts"),
+		DockerHost:         c.String("docker-host"),
+		DockerCertPath:     c.String("docker-cert-path"),
+		registryCAs:        c.StringSlice("registry-ca"),
+		Attest:             c.Bool("attest"),
+		AttestKey:          c.String("attest-key"),
+		AttestOutput:       c.String("attest-output"),
+		AttestUpload:       c.Bool("attest-upload"),
+		sbomSources:        c.String("sbom-sources"),
+		SbomAttestationKey: c.String("sbom-attestation-key"),
+		RekorURL:           c.String("rekor-url"),
+	}
+}
+
+// Init initializes the ImageOption
+func (c *ImageOption) Init() (err error) {
+	if c.RegistryRootCAs, err = utils.LoadCertPool(c.registryCAs); err != nil {
+		return xerrors.Errorf("--registry-ca error: %w", err)
+	}
+
+	// The key is decrypted before scanning so that a wrong password is reported early
+	if c.Attest && c.AttestKey != "" {
+		if c.AttestSigner, err = attestation.LoadPrivateKey(c.AttestKey, []byte(os.Getenv("COSIGN_PASSWORD"))); err != nil {
+			return xerrors.Errorf("--attest-key error: %w", err)
+		}
+	}
+
+	if err = c.initDocker(); err != nil {
+		return xerrors.Errorf("docker error: %w", err)
+	}
+
+	if c.sbomSources != "" {
+		for _, source := range strings.Split(c.sbomSources, ",") {
+			if !slices.Contains(supportedSbomSources, source) {
+				return xerrors.Errorf("unknown SBOM source %q, supported sources: %q", source, supportedSbomSources)
+			}
+			c.SbomSources = append(c.SbomSources, source)
+		}
+	}
+	if len(c.SbomSources) > 0 {
+		if c.SbomAttestationKey == "" {
+			return xerrors.New("'--sbom-sources' requires '--sbom-attestation-key' to verify the SBOM attestations")
+		}
+		if c.SbomPublicKey, err = attestation.LoadPublicKey(c.SbomAttestationKey); err != nil {
+			return xerrors.Errorf("--sbom-attestation-key error: %w", err)
+		}
+	}
+	return nil
+}
+
+// initDocker validates the Docker daemon given by the options. The images are read from the daemon by the client
+// connecting to it with the options, and the certificate of the daemon is always verified when the client certificate is given.
+func (c *ImageOption) initDocker() error {
+	if c.DockerHost != "" {
+		u, err := client.ParseHostURL(c.DockerHost)
+		if err != nil {
+			return xerrors.Errorf("invalid '--docker-host': %w", err)
+		}
+		switch u.Scheme {
+		case "tcp", "unix", "npipe":
+		default:
+			return xerrors.Errorf("unsupported scheme of '--docker-host' %q, supported schemes: tcp, unix, npipe", u.Scheme)
+		}
+	}
+
+	if c.DockerCertPath == "" {
+		return nil
+	}
+	for _, f := range dockerCertFiles {
+		if _, err := os.Stat(filepath.Join(c.DockerCertPath, f)); err != nil {
+			return xerrors.Errorf("'--docker-cert-path' must have %q: %w", dockerCertFiles, err)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,67 @@
+package option
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageOption_initDocker(t *testing.T) {
+	certDir := t.TempDir()
+	for _, f := range dockerCertFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(certDir, f), nil, 0600))
+	}
+
+	tests := []struct {
+		name           string
+		dockerHost     string
+		dockerCertPath string
+		wantErr        string
+	}{
+		{
+			name:           "tcp with TLS",
+			dockerHost:     "tcp://build-host:2376",
+			dockerCertPath: certDir,
+		},
+		{
+			name:       "unix socket",
+			dockerHost: "unix:///run/user/1000/docker.sock",
+		},
+		{
+			name: "not given",
+		},
+		{
+			name:       "no scheme",
+			dockerHost: "build-host:2376",
+			wantErr:    "invalid '--docker-host'",
+		},
+		{
+			name:       "ssh",
+			dockerHost: "ssh://user@build-host",
+			wantErr:    `unsupported scheme of '--docker-host' "ssh"`,
+		},
+		{
+			name:           "no certificates",
+			dockerHost:     "tcp://build-host:2376",
+			dockerCertPath: t.TempDir(),
+			wantErr:        `'--docker-cert-path' must have ["ca.pem" "cert.pem" "key.pem"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ImageOption{
+				DockerHost:     tt.dockerHost,
+				DockerCertPath: tt.dockerCertPath,
+			}
+			err := c.initDocker()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -8,6 +8,12 @@ import (
 type KubernetesOption struct {
 	Namespace    string
 	ReportFormat string
+
+	// NodeCollector launches a job on each node to scan the OS packages of the nodes with NodeCollectorImage
+	// in NodeCollectorNamespace
+	NodeCollector          bool
+	NodeCollectorImage     string
+	NodeCollectorNamespace string
 }
 
 // NewKubernetesOption is the factory method to return Kubernetes options
@@ -15,5 +21,9 @@ func NewKubernetesOption(c *cli.Context) KubernetesOption {
 	return KubernetesOption{
 		Namespace:    c.String("namespace"),
 		ReportFormat: c.String("report"),
+
+		NodeCollector:          c.Bool("node-collector"),
+		NodeCollectorImage:     c.String("node-collector-image"),
+		NodeCollectorNamespace: c.String("node-collector-namespace"),
 	}
 }
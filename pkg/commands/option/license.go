@@ -0,0 +1,17 @@
+package option
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// LicenseOption holds the options for license scanning
+type LicenseOption struct {
+	LicenseConfigPath string
+}
+
+// NewLicenseOption is the factory method to return license options
+func NewLicenseOption(c *cli.Context) LicenseOption {
+	return LicenseOption{
+		LicenseConfigPath: c.String("license-config"),
+	}
+}
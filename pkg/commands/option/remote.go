@@ -1,11 +1,20 @@
 package option
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/credential"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/utils"
 )
 
 const DefaultTokenHeader = "Trivy-Token"
@@ -17,9 +26,34 @@ type RemoteOption struct {
 	token         string
 	tokenHeader   string
 	remote        string // deprecated
+	serverCAs     []string
+	clientCert    string
+	clientKey     string
+	PullViaServer bool
+
+	// ServerSideAnalysis makes the client upload the layers instead of analyzing them
+	ServerSideAnalysis bool
+
+	// ServerSidePull makes the server pull and analyze the image instead of the client
+	ServerSidePull bool
+
+	// ServerTimeout is the timeout of each request, and ServerRetries is the maximum number of retries
+	ServerTimeout time.Duration
+	ServerRetries int
+
+	// FallbackToLocal scans the targets in standalone mode when the server is unreachable
+	FallbackToLocal bool
+
+	// ClientID identifies the client to the server, e.g. in the audit log
+	ClientID string
+
+	// ServerProtocol is the protocol to the server, which must serve gRPC with the same flag to use "grpc"
+	ServerProtocol string
 
-	// this field is populated in Init()
-	CustomHeaders http.Header
+	// these fields are populated in Init()
+	CustomHeaders      http.Header
+	ServerRootCAs      *x509.CertPool
+	ClientCertificates []tls.Certificate
 }
 
 func NewRemoteOption(c *cli.Context) RemoteOption {
@@ -29,19 +63,34 @@ func NewRemoteOption(c *cli.Context) RemoteOption {
 		token:         c.String("token"),
 		tokenHeader:   c.String("token-header"),
 		remote:        c.String("remote"), // deprecated
+		serverCAs:     c.StringSlice("server-ca"),
+		clientCert:    c.String("client-cert"),
+		clientKey:     c.String("client-key"),
+		PullViaServer: c.Bool("pull-via-server"),
+
+		ServerSideAnalysis: c.Bool("server-side-analysis"),
+		ServerSidePull:     c.Bool("server-side-pull"),
+		ServerTimeout:      c.Duration("server-timeout"),
+		ServerRetries:      c.Int("server-retries"),
+		FallbackToLocal:    c.Bool("fallback-to-local"),
+		ClientID:           c.String("client-id"),
+		ServerProtocol:     c.String("server-protocol"),
 	}
 
 	return r
 }
 
 // Init initialize the options for client/server mode
-func (c *RemoteOption) Init(logger *zap.SugaredLogger) {
+func (c *RemoteOption) Init(logger *zap.SugaredLogger) (err error) {
 	// for testability
 	defer func() {
 		c.token = ""
 		c.tokenHeader = ""
 		c.remote = ""
 		c.customHeaders = nil
+		c.serverCAs = nil
+		c.clientCert = ""
+		c.clientKey = ""
 	}()
 
 	// for backward compatibility, should be removed in the future
@@ -57,25 +106,95 @@ func (c *RemoteOption) Init(logger *zap.SugaredLogger) {
 			logger.Warn(`"--token" can be used only with "--server"`)
 		case c.tokenHeader != "" && c.tokenHeader != DefaultTokenHeader:
 			logger.Warn(`'--token-header' can be used only with "--server"`)
+		case len(c.serverCAs) > 0:
+			logger.Warn(`'--server-ca' can be used only with "--server"`)
+		case c.clientCert != "" || c.clientKey != "":
+			logger.Warn(`'--client-cert' and '--client-key' can be used only with "--server"`)
+		case c.PullViaServer:
+			logger.Warn(`'--pull-via-server' can be used only with "--server"`)
+		case c.ServerSideAnalysis:
+			logger.Warn(`'--server-side-analysis' can be used only with "--server"`)
+		case c.ServerSidePull:
+			logger.Warn(`'--server-side-pull' can be used only with "--server"`)
+		case c.FallbackToLocal:
+			logger.Warn(`'--fallback-to-local' can be used only with "--server"`)
+		case c.ClientID != "":
+			logger.Warn(`'--client-id' can be used only with "--server"`)
+		case c.ServerProtocol != "" && c.ServerProtocol != rpc.ProtocolTwirp:
+			logger.Warn(`'--server-protocol' can be used only with "--server"`)
 		}
-		return
+		c.PullViaServer = false
+		c.ServerSideAnalysis = false
+		c.ServerSidePull = false
+		c.FallbackToLocal = false
+		c.ClientID = ""
+		c.ServerProtocol = ""
+		return nil
+	}
+
+	if c.ServerTimeout < 0 || c.ServerRetries < 0 {
+		return xerrors.New("'--server-timeout' and '--server-retries' must not be negative")
 	}
 
-	c.CustomHeaders = splitCustomHeaders(c.customHeaders)
+	if c.ServerProtocol != "" && !slices.Contains(rpc.Protocols, c.ServerProtocol) {
+		return xerrors.Errorf("unknown '--server-protocol' %q, must be one of %q", c.ServerProtocol, rpc.Protocols)
+	}
+
+	// The layers pulled through the server would be sent back to the server
+	if c.PullViaServer && c.ServerSideAnalysis {
+		return xerrors.New("'--pull-via-server' and '--server-side-analysis' can't be used together")
+	}
+
+	// The image is not pulled or analyzed on the client at all
+	if c.ServerSidePull && (c.PullViaServer || c.ServerSideAnalysis) {
+		return xerrors.New("'--server-side-pull' can't be used with '--pull-via-server' or '--server-side-analysis'")
+	}
+
+	c.CustomHeaders = SplitHeaders(c.customHeaders)
+	// e.g. --custom-headers x-api-token:awssm://trivy-api-token
+	for name := range c.CustomHeaders {
+		value, err := credential.Resolve(c.CustomHeaders.Get(name))
+		if err != nil {
+			return xerrors.Errorf("--custom-headers error (%s): %w", name, err)
+		}
+		c.CustomHeaders.Set(name, value)
+	}
+	if c.token, err = credential.Resolve(c.token); err != nil {
+		return xerrors.Errorf("--token error: %w", err)
+	}
 	if c.token != "" {
 		c.CustomHeaders.Set(c.tokenHeader, c.token)
 	}
+	if c.ClientID != "" {
+		c.CustomHeaders.Set(rpc.ClientIDHeader, c.ClientID)
+	}
+
+	if c.ServerRootCAs, err = utils.LoadCertPool(c.serverCAs); err != nil {
+		return xerrors.Errorf("--server-ca error: %w", err)
+	}
+
+	if (c.clientCert == "") != (c.clientKey == "") {
+		return xerrors.New("both '--client-cert' and '--client-key' must be specified")
+	} else if c.clientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.clientCert, c.clientKey)
+		if err != nil {
+			return xerrors.Errorf("client certificate error: %w", err)
+		}
+		c.ClientCertificates = []tls.Certificate{cert}
+	}
+	return nil
 }
 
-func splitCustomHeaders(headers []string) http.Header {
+// SplitHeaders parses the headers given as "key:value" or "key=value", e.g. x-api-token:XXX.
+// The key ends at the first separator because the header names can't contain either of them.
+func SplitHeaders(headers []string) http.Header {
 	result := make(http.Header)
 	for _, header := range headers {
-		// e.g. x-api-token:XXX
-		s := strings.SplitN(header, ":", 2)
-		if len(s) != 2 {
+		i := strings.IndexAny(header, ":=")
+		if i <= 0 {
 			continue
 		}
-		result.Set(s[0], s[1])
+		result.Set(header[:i], header[i+1:])
 	}
 	return result
 }
@@ -7,7 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_splitCustomHeaders(t *testing.T) {
+func TestSplitHeaders(t *testing.T) {
 	type args struct {
 		headers []string
 	}
@@ -26,10 +26,27 @@ func Test_splitCustomHeaders(t *testing.T) {
 				"Authorization": []string{"user:password"},
 			},
 		},
+		{
+			name: "equal sign",
+			args: args{
+				headers: []string{"X-Gateway-Key=abc:def", "x-api-token:a=b"},
+			},
+			want: http.Header{
+				"X-Gateway-Key": []string{"abc:def"},
+				"X-Api-Token":   []string{"a=b"},
+			},
+		},
+		{
+			name: "no separator",
+			args: args{
+				headers: []string{"x-api-token", "=foo"},
+			},
+			want: http.Header{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := splitCustomHeaders(tt.args.headers)
+			got := SplitHeaders(tt.args.headers)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -3,6 +3,7 @@ package option
 import (
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/urfave/cli/v2"
@@ -10,49 +11,165 @@ import (
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
+	"github.com/aquasecurity/fanal/analyzer"
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/compliance"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
+	"github.com/aquasecurity/trivy/pkg/php"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/result"
+	"github.com/aquasecurity/trivy/pkg/ruby"
+	"github.com/aquasecurity/trivy/pkg/store"
 	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/unpinned"
+	"github.com/aquasecurity/trivy/pkg/webhook"
 )
 
+// pkgTypeLangPrefix selects the packages of a language in '--pkg-types', e.g. "lang:python"
+const pkgTypeLangPrefix = "lang:"
+
+// languageAnalyzers are the analyzers of the languages which can be selected in '--pkg-types'
+var languageAnalyzers = map[string][]analyzer.Type{
+	"dotnet": {analyzer.TypeNuget},
+	"go":     {analyzer.TypeGoBinary, analyzer.TypeGoMod},
+	"java":   {analyzer.TypeJar, analyzer.TypePom},
+	"native": {fingerprint.TypeStaticLibrary},
+	"node": {analyzer.TypeNpmPkgLock, analyzer.TypeNodePkg, analyzer.TypeYarn, nodejs.TypePnpm, nodejs.TypeManifest,
+		unpinned.TypeNpm},
+	"php":    {analyzer.TypeComposer, php.TypeManifest},
+	"python": {analyzer.TypePythonPkg, analyzer.TypePip, analyzer.TypePipenv, analyzer.TypePoetry, unpinned.TypePip},
+	"ruby":   {analyzer.TypeBundler, analyzer.TypeGemSpec, ruby.TypeManifest},
+	"rust":   {analyzer.TypeCargo},
+}
+
+// LanguagePkgTypes returns the package types selecting the languages, e.g. "lang:python"
+func LanguagePkgTypes() []string {
+	var pkgTypes []string
+	for lang := range languageAnalyzers {
+		pkgTypes = append(pkgTypes, pkgTypeLangPrefix+lang)
+	}
+	sort.Strings(pkgTypes)
+	return pkgTypes
+}
+
 // ReportOption holds the options for reporting scan results
 type ReportOption struct {
 	Format   string
 	Template string
 
-	IgnoreFile    string
-	IgnoreUnfixed bool
-	ExitCode      int
-	IgnorePolicy  string
+	// SchemaVersion is the version of the JSON report, the latest if it is 0
+	SchemaVersion int
+
+	IgnoreFile        string
+	IgnoreUnfixed     bool
+	ExitCode          int
+	ExitCodeFixedOnly bool
+	IgnorePolicy      string
+
+	// Gate is the Rego or CEL file deciding whether the report passes
+	Gate string
+
+	// SLAFile holds the periods to fix vulnerabilities per severity
+	SLAFile     string
+	OnlyOverdue bool
+
+	// AgeFilter ignores the vulnerabilities by their published dates, populated by Init()
+	AgeFilter             result.AgeFilter
+	ignorePublishedBefore string
+	ignoreUnfixedSince    string
+
+	// HistoryDir records when findings are first and last seen across scans
+	HistoryDir string
+
+	// Store is the URL of the object storage to archive the reports
+	Store string
+
+	// WebhookURL is notified of the summary when scans complete
+	WebhookURL          string
+	WebhookAttachReport bool
 
 	// these variables are not exported
 	vulnType       string
 	securityChecks string
 	output         string
 	severities     string
+	exitOnSeverity string
 
 	// these variables are populated by Init()
 	VulnType       []string
 	SecurityChecks []string
 	Output         io.Writer
+
+	// Languages are selected with "lang:<language>" in '--pkg-types', and all the languages are scanned if empty
+	Languages []string
+
 	Severities     []dbTypes.Severity
+	ExitOnSeverity dbTypes.Severity
 	ListAllPkgs    bool
+
+	// DependencyTree fills the dependency paths of the vulnerable packages
+	DependencyTree bool
+
+	// ExcludeDevDeps drops the development dependencies where the projects tell them
+	ExcludeDevDeps bool
+
+	// GroupBy collapses the identical vulnerabilities across packages in the table
+	GroupBy string
+
+	// Report is "summary" to write only the number of the findings per severity of each target
+	Report string
+
+	// Deterministic writes the same report for the same artifact and DB, e.g. for golden files
+	Deterministic bool
+
+	// VEXOutput is the OpenVEX document recording the vulnerabilities ignored by the ignore file and the ignore policy
+	VEXOutput string
+	VEXAuthor string
+
+	// Compliance is the ID of the built-in compliance spec or the YAML file,
+	// loaded into ComplianceSpec by Init()
+	Compliance     string
+	ComplianceSpec compliance.Spec
 }
 
 // NewReportOption is the factory method to return ReportOption
 func NewReportOption(c *cli.Context) ReportOption {
 	return ReportOption{
-		output:       c.String("output"),
-		Format:       c.String("format"),
-		Template:     c.String("template"),
-		IgnorePolicy: c.String("ignore-policy"),
-
-		vulnType:       c.String("vuln-type"),
-		securityChecks: c.String("security-checks"),
-		severities:     c.String("severity"),
-		IgnoreFile:     c.String("ignorefile"),
-		IgnoreUnfixed:  c.Bool("ignore-unfixed"),
-		ExitCode:       c.Int("exit-code"),
-		ListAllPkgs:    c.Bool("list-all-pkgs"),
+		output:        c.String("output"),
+		Format:        c.String("format"),
+		Template:      c.String("template"),
+		SchemaVersion: c.Int("schema"),
+		IgnorePolicy:  c.String("ignore-policy"),
+		Gate:          c.String("gate"),
+		SLAFile:       c.String("sla"),
+		OnlyOverdue:   c.Bool("only-overdue"),
+		HistoryDir:    c.String("history-dir"),
+		Store:         c.String("store"),
+		WebhookURL:    c.String("webhook-url"),
+
+		vulnType:          c.String("pkg-types"),
+		securityChecks:    c.String("security-checks"),
+		severities:        c.String("severity"),
+		IgnoreFile:        c.String("ignorefile"),
+		IgnoreUnfixed:     c.Bool("ignore-unfixed"),
+		ExitCode:          c.Int("exit-code"),
+		ExitCodeFixedOnly: c.Bool("exit-code-fixed-only"),
+		exitOnSeverity:    c.String("exit-on-severity"),
+		ListAllPkgs:       c.Bool("list-all-pkgs"),
+		DependencyTree:    c.Bool("dependency-tree"),
+		ExcludeDevDeps:    c.Bool("exclude-dev-deps"),
+		GroupBy:           c.String("group-by"),
+		Report:            c.String("report"),
+		VEXOutput:         c.String("vex-output"),
+		VEXAuthor:         c.String("vex-author"),
+		Compliance:        c.String("compliance"),
+
+		WebhookAttachReport: c.Bool("webhook-attach-report"),
+		Deterministic:       c.Bool("deterministic"),
+
+		ignorePublishedBefore: c.String("ignore-published-before"),
+		ignoreUnfixedSince:    c.String("ignore-unfixed-since"),
 	}
 }
 
@@ -70,6 +187,15 @@ func (c *ReportOption) Init(output io.Writer, logger *zap.SugaredLogger) error {
 		}
 	}
 
+	if c.SchemaVersion != 0 {
+		if err := report.ValidateSchemaVersion(c.SchemaVersion); err != nil {
+			return xerrors.Errorf("schema: %w", err)
+		}
+		if c.Format != "json" {
+			logger.Warnf("'--schema' is ignored because '--format %s' is specified. Use '--schema' option with '--format json' option.", c.Format)
+		}
+	}
+
 	// "--list-all-pkgs" option is unavailable with "--format table".
 	// If user specifies "--list-all-pkgs" with "--format table", we should warn it.
 	if c.ListAllPkgs && c.Format == "table" {
@@ -80,20 +206,98 @@ func (c *ReportOption) Init(output io.Writer, logger *zap.SugaredLogger) error {
 		c.ListAllPkgs = true
 	}
 
+	// Fall back to the structured ignore file when the default one doesn't exist
+	if c.IgnoreFile == result.DefaultIgnoreFile && !fileExists(c.IgnoreFile) && fileExists(result.DefaultIgnoreYAMLFile) {
+		logger.Debugf("Using %s", result.DefaultIgnoreYAMLFile)
+		c.IgnoreFile = result.DefaultIgnoreYAMLFile
+	}
+
 	c.Severities = splitSeverity(logger, c.severities)
 
+	if err := c.populateExitOnSeverity(logger); err != nil {
+		return xerrors.Errorf("exit on severity: %w", err)
+	}
+
+	// "--exit-code-fixed-only" is meaningless without a non-zero exit code
+	if c.ExitCodeFixedOnly && c.ExitCode == 0 {
+		logger.Debugf("'--exit-code-fixed-only' is specified without '--exit-code', using exit code 1")
+		c.ExitCode = 1
+	}
+
+	// The gate decides the result instead of the findings
+	if c.Gate != "" && c.ExitCode == 0 {
+		logger.Debugf("'--gate' is specified without '--exit-code', using exit code 1")
+		c.ExitCode = 1
+	}
+
+	if c.GroupBy != "" {
+		if c.GroupBy != report.GroupByVulnerability {
+			return xerrors.Errorf("unknown '--group-by' %q, supported values: %q", c.GroupBy, report.GroupByVulnerability)
+		}
+		if c.Format != "table" {
+			logger.Warnf("'--group-by' is ignored because '--format %s' is specified. Use '--group-by' option with '--format table' option.", c.Format)
+		}
+	}
+
+	switch c.Report {
+	case "", report.AllReport:
+	case report.SummaryReport:
+		if c.Format != "table" && c.Format != "json" {
+			return xerrors.Errorf(`'--report summary' can be used only with '--format table' or '--format json', not %q`, c.Format)
+		}
+	default:
+		return xerrors.Errorf("unknown '--report' %q, supported values: %q, %q", c.Report, report.AllReport,
+			report.SummaryReport)
+	}
+
+	if c.VEXAuthor != "" && c.VEXOutput == "" {
+		logger.Warn("'--vex-author' is ignored because '--vex-output' is not specified")
+	}
+
+	// The due dates are given by the SLA
+	if c.OnlyOverdue && c.SLAFile == "" {
+		return xerrors.New("'--only-overdue' can be used only with '--sla'")
+	}
+
+	ageFilter, err := result.NewAgeFilter(c.ignorePublishedBefore, c.ignoreUnfixedSince)
+	if err != nil {
+		return xerrors.Errorf("age filter: %w", err)
+	}
+	c.AgeFilter = ageFilter
+
+	// The URL is validated before scanning
+	if c.Store != "" {
+		if err := store.Validate(c.Store); err != nil {
+			return xerrors.Errorf("store: %w", err)
+		}
+	}
+	if c.WebhookURL != "" {
+		if err := webhook.Validate(c.WebhookURL); err != nil {
+			return xerrors.Errorf("webhook: %w", err)
+		}
+	} else if c.WebhookAttachReport {
+		logger.Warn("'--webhook-attach-report' is ignored because '--webhook-url' is not specified")
+	}
+
 	if err := c.populateVulnTypes(); err != nil {
-		return xerrors.Errorf("vuln type: %w", err)
+		return xerrors.Errorf("pkg types: %w", err)
 	}
 
 	if err := c.populateSecurityChecks(); err != nil {
 		return xerrors.Errorf("security checks: %w", err)
 	}
 
+	if err := c.populateCompliance(logger); err != nil {
+		return xerrors.Errorf("compliance: %w", err)
+	}
+
 	// for testability
 	c.severities = ""
+	c.exitOnSeverity = ""
 	c.vulnType = ""
 	c.securityChecks = ""
+	c.ignorePublishedBefore = ""
+	c.ignoreUnfixedSince = ""
 
 	// The output is os.Stdout by default
 	if c.output != "" {
@@ -113,15 +317,49 @@ func (c *ReportOption) populateVulnTypes() error {
 		return nil
 	}
 
+	// "library" scans all the languages even if some of them are selected
+	var allLanguages bool
 	for _, v := range strings.Split(c.vulnType, ",") {
-		if types.NewVulnType(v) == types.VulnTypeUnknown {
-			return xerrors.Errorf("unknown vulnerability type (%s)", v)
+		if strings.HasPrefix(v, pkgTypeLangPrefix) {
+			lang := strings.TrimPrefix(v, pkgTypeLangPrefix)
+			if _, ok := languageAnalyzers[lang]; !ok {
+				return xerrors.Errorf("unknown language (%s), must be one of %s", lang, strings.Join(LanguagePkgTypes(), ","))
+			}
+			if !slices.Contains(c.Languages, lang) {
+				c.Languages = append(c.Languages, lang)
+			}
+			v = types.VulnTypeLibrary
+		} else if types.NewVulnType(v) == types.VulnTypeUnknown {
+			return xerrors.Errorf("unknown package type (%s)", v)
+		} else if v == types.VulnTypeLibrary {
+			allLanguages = true
+		}
+		if !slices.Contains(c.VulnType, v) {
+			c.VulnType = append(c.VulnType, v)
 		}
-		c.VulnType = append(c.VulnType, v)
+	}
+	if allLanguages {
+		c.Languages = nil
 	}
 	return nil
 }
 
+// DisabledLanguageAnalyzers returns the analyzers of the languages not selected in '--pkg-types',
+// and nothing unless the languages are selected
+func (c *ReportOption) DisabledLanguageAnalyzers() []analyzer.Type {
+	if len(c.Languages) == 0 {
+		return nil
+	}
+	var analyzers []analyzer.Type
+	for _, pkgType := range LanguagePkgTypes() {
+		lang := strings.TrimPrefix(pkgType, pkgTypeLangPrefix)
+		if !slices.Contains(c.Languages, lang) {
+			analyzers = append(analyzers, languageAnalyzers[lang]...)
+		}
+	}
+	return analyzers
+}
+
 func (c *ReportOption) populateSecurityChecks() error {
 	if c.securityChecks == "" {
 		return nil
@@ -136,6 +374,58 @@ func (c *ReportOption) populateSecurityChecks() error {
 	return nil
 }
 
+func (c *ReportOption) populateCompliance(logger *zap.SugaredLogger) error {
+	if c.Compliance == "" {
+		return nil
+	}
+
+	spec, err := compliance.GetSpec(c.Compliance)
+	if err != nil {
+		return err
+	}
+	c.ComplianceSpec = spec
+
+	if c.Format != "table" && c.Format != "json" {
+		return xerrors.Errorf(`'--compliance' can be used only with '--format table' or '--format json', not %q`, c.Format)
+	}
+
+	// The controls can't be evaluated without the findings of their checks
+	for _, check := range spec.SecurityChecks() {
+		if !slices.Contains(c.SecurityChecks, check) {
+			logger.Debugf("'--compliance %s' enables '--security-checks %s'", c.Compliance, check)
+			c.SecurityChecks = append(c.SecurityChecks, check)
+		}
+	}
+	return nil
+}
+
+func (c *ReportOption) populateExitOnSeverity(logger *zap.SugaredLogger) error {
+	if c.exitOnSeverity == "" {
+		return nil
+	}
+
+	severity, err := dbTypes.NewSeverity(strings.ToUpper(c.exitOnSeverity))
+	if err != nil {
+		return xerrors.Errorf("unknown severity (%s)", c.exitOnSeverity)
+	}
+	c.ExitOnSeverity = severity
+
+	// "--exit-on-severity" is meaningless without a non-zero exit code
+	if c.ExitCode == 0 {
+		logger.Debugf("'--exit-on-severity' is specified without '--exit-code', using exit code 1")
+		c.ExitCode = 1
+	}
+	return nil
+}
+
+// FailCondition returns the condition of findings to exit with the exit code
+func (c *ReportOption) FailCondition() types.FailCondition {
+	return types.FailCondition{
+		Severity:  c.ExitOnSeverity,
+		FixedOnly: c.ExitCodeFixedOnly,
+	}
+}
+
 func (c *ReportOption) forceListAllPkgs(logger *zap.SugaredLogger) bool {
 	if slices.Contains(supportedSbomFormats, c.Format) && !c.ListAllPkgs {
 		logger.Debugf("'cyclonedx', 'spdx', and 'spdx-json' automatically enables '--list-all-pkgs'.")
@@ -146,6 +436,10 @@ func (c *ReportOption) forceListAllPkgs(logger *zap.SugaredLogger) bool {
 
 func splitSeverity(logger *zap.SugaredLogger, severity string) []dbTypes.Severity {
 	logger.Debugf("Severities: %s", severity)
+	// The commands not reporting the findings, e.g. "trivy cache warm", have no severity
+	if severity == "" {
+		return nil
+	}
 	var severities []dbTypes.Severity
 	for _, s := range strings.Split(severity, ",") {
 		severity, err := dbTypes.NewSeverity(s)
@@ -156,3 +450,8 @@ func splitSeverity(logger *zap.SugaredLogger, severity string) []dbTypes.Severit
 	}
 	return severities
 }
+
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
@@ -10,26 +10,43 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 
+	"github.com/aquasecurity/fanal/analyzer"
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/compliance"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 func TestReportReportConfig_Init(t *testing.T) {
+	dockerCIS, err := compliance.GetSpec("docker-cis")
+	require.NoError(t, err)
+
 	type fields struct {
-		output         string
-		Format         string
-		Template       string
-		vulnType       string
-		securityChecks string
-		severities     string
-		IgnoreFile     string
-		IgnoreUnfixed  bool
-		listAllPksgs   bool
-		ExitCode       int
-		VulnType       []string
-		Output         *os.File
-		Severities     []dbTypes.Severity
-		debug          bool
+		output            string
+		Format            string
+		Template          string
+		vulnType          string
+		securityChecks    string
+		severities        string
+		IgnoreFile        string
+		IgnoreUnfixed     bool
+		listAllPksgs      bool
+		ExitCode          int
+		exitCodeFixedOnly bool
+		exitOnSeverity    string
+		Gate              string
+		OnlyOverdue       bool
+		publishedBefore   string
+		Store             string
+		WebhookURL        string
+		SchemaVersion     int
+		Compliance        string
+		GroupBy           string
+		Report            string
+		VulnType          []string
+		Output            *os.File
+		Severities        []dbTypes.Severity
+		debug             bool
 	}
 	tests := []struct {
 		name    string
@@ -72,6 +89,217 @@ func TestReportReportConfig_Init(t *testing.T) {
 				Output:         os.Stdout,
 			},
 		},
+		{
+			name: "happy path with languages",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os,lang:python,lang:node,lang:python",
+				securityChecks: "vuln",
+			},
+			args: []string{"alpine:3.10"},
+			want: ReportOption{
+				Severities:     []dbTypes.Severity{dbTypes.SeverityCritical},
+				VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+				Languages:      []string{"python", "node"},
+				SecurityChecks: []string{types.SecurityCheckVulnerability},
+				Output:         os.Stdout,
+			},
+		},
+		{
+			name: "happy path with languages and library",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "lang:python,library",
+				securityChecks: "vuln",
+			},
+			args: []string{"alpine:3.10"},
+			want: ReportOption{
+				Severities:     []dbTypes.Severity{dbTypes.SeverityCritical},
+				VulnType:       []string{types.VulnTypeLibrary},
+				SecurityChecks: []string{types.SecurityCheckVulnerability},
+				Output:         os.Stdout,
+			},
+		},
+		{
+			name: "sad path: unknown language",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "lang:cobol",
+				securityChecks: "vuln",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: "unknown language (cobol)",
+		},
+		{
+			name: "happy path with --exit-on-severity",
+			fields: fields{
+				severities:     "CRITICAL,HIGH,MEDIUM",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				exitOnSeverity: "HIGH",
+			},
+			args: []string{"alpine:3.10"},
+			want: ReportOption{
+				Severities:     []dbTypes.Severity{dbTypes.SeverityCritical, dbTypes.SeverityHigh, dbTypes.SeverityMedium},
+				ExitOnSeverity: dbTypes.SeverityHigh,
+				ExitCode:       1,
+				VulnType:       []string{types.VulnTypeOS},
+				SecurityChecks: []string{types.SecurityCheckVulnerability},
+				Output:         os.Stdout,
+			},
+		},
+		{
+			name: "happy path with --exit-code-fixed-only",
+			fields: fields{
+				severities:        "CRITICAL",
+				vulnType:          "os",
+				securityChecks:    "vuln",
+				exitCodeFixedOnly: true,
+			},
+			args: []string{"alpine:3.10"},
+			want: ReportOption{
+				Severities:        []dbTypes.Severity{dbTypes.SeverityCritical},
+				ExitCode:          1,
+				ExitCodeFixedOnly: true,
+				VulnType:          []string{types.VulnTypeOS},
+				SecurityChecks:    []string{types.SecurityCheckVulnerability},
+				Output:            os.Stdout,
+			},
+		},
+		{
+			name: "happy path with --gate",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				Gate:           "gate.rego",
+			},
+			args: []string{"alpine:3.10"},
+			want: ReportOption{
+				Severities:     []dbTypes.Severity{dbTypes.SeverityCritical},
+				Gate:           "gate.rego",
+				ExitCode:       1,
+				VulnType:       []string{types.VulnTypeOS},
+				SecurityChecks: []string{types.SecurityCheckVulnerability},
+				Output:         os.Stdout,
+			},
+		},
+		{
+			name: "sad path with an unknown --exit-on-severity",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				exitOnSeverity: "SEVERE",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: "unknown severity (SEVERE)",
+		},
+		{
+			name: "sad path with --only-overdue without --sla",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				OnlyOverdue:    true,
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: "'--only-overdue' can be used only with '--sla'",
+		},
+		{
+			name: "sad path with an invalid --ignore-published-before",
+			fields: fields{
+				severities:      "CRITICAL",
+				vulnType:        "os",
+				securityChecks:  "vuln",
+				publishedBefore: "2015",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: `age filter: invalid date "2015"`,
+		},
+		{
+			name: "sad path with an unknown --group-by",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				GroupBy:        "package",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: `unknown '--group-by' "package"`,
+		},
+		{
+			name: "sad path with --report summary and --format sarif",
+			fields: fields{
+				Format:         "sarif",
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				Report:         "summary",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: `'--report summary' can be used only with '--format table' or '--format json', not "sarif"`,
+		},
+		{
+			name: "sad path with an unknown --report",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				Report:         "counts",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: `unknown '--report' "counts"`,
+		},
+		{
+			name: "sad path with an unsupported store",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				Store:          "ftp://example.com/reports",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: "unsupported store URL",
+		},
+		{
+			name: "sad path with a webhook URL without the scheme",
+			fields: fields{
+				severities:     "CRITICAL",
+				vulnType:       "os",
+				securityChecks: "vuln",
+				WebhookURL:     "hooks.slack.com/services/T000/B000/XXXX",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: "the webhook URL must start with http:// or https://",
+		},
+		{
+			name: "sad path with an unknown schema version",
+			fields: fields{
+				Format:        "json",
+				SchemaVersion: 3,
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: "unknown schema version: 3, supported: 1, 2",
+		},
+		{
+			name: "happy path with a schema version and the table format",
+			fields: fields{
+				Format:        "table",
+				SchemaVersion: 1,
+				severities:    "CRITICAL",
+			},
+			args: []string{"alpine:3.10"},
+			logs: []string{
+				"'--schema' is ignored because '--format table' is specified. Use '--schema' option with '--format json' option.",
+			},
+			want: ReportOption{
+				Format:        "table",
+				SchemaVersion: 1,
+				Severities:    []dbTypes.Severity{dbTypes.SeverityCritical},
+				Output:        os.Stdout,
+			},
+		},
 		{
 			name: "happy path with an cyclonedx",
 			fields: fields{
@@ -199,6 +427,46 @@ func TestReportReportConfig_Init(t *testing.T) {
 				ListAllPkgs:    true,
 			},
 		},
+		{
+			name: "happy path with --compliance",
+			fields: fields{
+				Format:         "table",
+				severities:     "CRITICAL",
+				securityChecks: "vuln",
+				Compliance:     "docker-cis",
+			},
+			args: []string{"alpine:3.10"},
+			want: ReportOption{
+				Format:         "table",
+				Output:         os.Stdout,
+				Severities:     []dbTypes.Severity{dbTypes.SeverityCritical},
+				SecurityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckConfig},
+				Compliance:     "docker-cis",
+				ComplianceSpec: dockerCIS,
+			},
+		},
+		{
+			name: "invalid option combination: --compliance with --format sarif",
+			fields: fields{
+				Format:         "sarif",
+				severities:     "CRITICAL",
+				securityChecks: "vuln",
+				Compliance:     "docker-cis",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: `'--compliance' can be used only with '--format table' or '--format json', not "sarif"`,
+		},
+		{
+			name: "sad path: unknown compliance spec",
+			fields: fields{
+				Format:         "table",
+				severities:     "CRITICAL",
+				securityChecks: "vuln",
+				Compliance:     "pci-dss",
+			},
+			args:    []string{"alpine:3.10"},
+			wantErr: `unknown compliance spec "pci-dss"`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -214,17 +482,28 @@ func TestReportReportConfig_Init(t *testing.T) {
 			_ = set.Parse(tt.args)
 
 			c := &ReportOption{
-				output:         tt.fields.output,
-				Format:         tt.fields.Format,
-				Template:       tt.fields.Template,
-				vulnType:       tt.fields.vulnType,
-				securityChecks: tt.fields.securityChecks,
-				severities:     tt.fields.severities,
-				IgnoreFile:     tt.fields.IgnoreFile,
-				IgnoreUnfixed:  tt.fields.IgnoreUnfixed,
-				ExitCode:       tt.fields.ExitCode,
-				ListAllPkgs:    tt.fields.listAllPksgs,
-				Output:         tt.fields.Output,
+				output:                tt.fields.output,
+				Format:                tt.fields.Format,
+				Template:              tt.fields.Template,
+				vulnType:              tt.fields.vulnType,
+				securityChecks:        tt.fields.securityChecks,
+				severities:            tt.fields.severities,
+				IgnoreFile:            tt.fields.IgnoreFile,
+				IgnoreUnfixed:         tt.fields.IgnoreUnfixed,
+				ExitCode:              tt.fields.ExitCode,
+				ExitCodeFixedOnly:     tt.fields.exitCodeFixedOnly,
+				exitOnSeverity:        tt.fields.exitOnSeverity,
+				Gate:                  tt.fields.Gate,
+				OnlyOverdue:           tt.fields.OnlyOverdue,
+				ignorePublishedBefore: tt.fields.publishedBefore,
+				Store:                 tt.fields.Store,
+				WebhookURL:            tt.fields.WebhookURL,
+				SchemaVersion:         tt.fields.SchemaVersion,
+				Compliance:            tt.fields.Compliance,
+				GroupBy:               tt.fields.GroupBy,
+				Report:                tt.fields.Report,
+				ListAllPkgs:           tt.fields.listAllPksgs,
+				Output:                tt.fields.Output,
 			}
 			err := c.Init(os.Stdout, logger.Sugar())
 
@@ -248,3 +527,11 @@ func TestReportReportConfig_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestReportOption_DisabledLanguageAnalyzers(t *testing.T) {
+	c := ReportOption{Languages: []string{"go", "java", "node", "php", "python", "ruby", "rust"}}
+	assert.Equal(t, []analyzer.Type{analyzer.TypeNuget, fingerprint.TypeStaticLibrary}, c.DisabledLanguageAnalyzers())
+
+	c = ReportOption{}
+	assert.Nil(t, c.DisabledLanguageAnalyzers())
+}
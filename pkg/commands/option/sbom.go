@@ -1,11 +1,15 @@
 package option
 
 import (
+	"strings"
+
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
+
+	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 var supportedSbomFormats = []string{"cyclonedx", "spdx", "spdx-json"}
@@ -14,6 +18,9 @@ var supportedSbomFormats = []string{"cyclonedx", "spdx", "spdx-json"}
 type SbomOption struct {
 	ArtifactType string
 	SbomFormat   string
+
+	// SbomHashes are the algorithms of the hashes of the package files in the SBOMs, e.g. "sha256"
+	SbomHashes []string
 }
 
 // NewSbomOption is the factory method to return SBOM options
@@ -21,11 +28,21 @@ func NewSbomOption(c *cli.Context) SbomOption {
 	return SbomOption{
 		ArtifactType: c.String("artifact-type"),
 		SbomFormat:   c.String("sbom-format"),
+		SbomHashes:   c.StringSlice("sbom-hashes"),
 	}
 }
 
 // Init initialize the CLI context for SBOM generation
 func (c *SbomOption) Init(ctx *cli.Context, logger *zap.SugaredLogger) error {
+	for _, alg := range c.SbomHashes {
+		if !slices.Contains(types.HashAlgorithms, alg) {
+			return xerrors.Errorf("unknown '--sbom-hashes' %q, supported values: %q", alg, types.HashAlgorithms)
+		}
+	}
+	if format := ctx.String("format"); slices.Contains(c.SbomHashes, "sha512") && strings.HasPrefix(format, "spdx") {
+		logger.Warnf("'--sbom-hashes sha512' is ignored in '--format %s'. Use '--format cyclonedx' for SHA-512.", format)
+	}
+
 	if ctx.Command.Name != "sbom" {
 		return nil
 	}
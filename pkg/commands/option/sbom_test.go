@@ -0,0 +1,71 @@
+package option_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+)
+
+func TestSbomOption_Init(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		logs    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			args: []string{"--format", "cyclonedx", "--sbom-hashes", "sha256,sha1"},
+			want: []string{"sha256", "sha1"},
+		},
+		{
+			name: "sha512 in SPDX",
+			args: []string{"--format", "spdx-json", "--sbom-hashes", "sha512"},
+			logs: []string{"'--sbom-hashes sha512' is ignored in '--format spdx-json'. Use '--format cyclonedx' for SHA-512."},
+			want: []string{"sha512"},
+		},
+		{
+			name:    "sad path: unknown algorithm",
+			args:    []string{"--format", "cyclonedx", "--sbom-hashes", "sha3-256"},
+			wantErr: `unknown '--sbom-hashes' "sha3-256"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, obs := observer.New(zap.DebugLevel)
+			logger := zap.New(core)
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("format", "", "")
+			set.Var(cli.NewStringSlice(), "sbom-hashes", "")
+			ctx := cli.NewContext(app, set, nil)
+			require.NoError(t, set.Parse(tt.args))
+
+			c := option.NewSbomOption(ctx)
+			err := c.Init(ctx, logger.Sugar())
+
+			var gotMessages []string
+			for _, entry := range obs.AllUntimed() {
+				gotMessages = append(gotMessages, entry.Message)
+			}
+			assert.Equal(t, tt.logs, gotMessages)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.SbomHashes)
+		})
+	}
+}
@@ -1,9 +1,9 @@
 package plugin
 
 import (
-	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
@@ -120,17 +120,22 @@ func Run(c *cli.Context) error {
 
 	url := c.Args().First()
 	args := c.Args().Tail()
-	return RunWithArgs(c.Context, url, args)
+	return RunWithArgs(c, url, args)
 }
 
 // RunWithArgs runs the plugin with arguments
-func RunWithArgs(ctx context.Context, url string, args []string) error {
-	pl, err := plugin.Install(ctx, url, false)
+func RunWithArgs(c *cli.Context, url string, args []string) error {
+	env, err := scanContext(c)
+	if err != nil {
+		return xerrors.Errorf("scan context error: %w", err)
+	}
+
+	pl, err := plugin.Install(c.Context, url, false)
 	if err != nil {
 		return xerrors.Errorf("plugin install error: %w", err)
 	}
 
-	if err = pl.Run(ctx, args); err != nil {
+	if err = pl.Run(c.Context, plugin.RunOptions{Args: args, Env: env}); err != nil {
 		return xerrors.Errorf("unable to run %s plugin: %w", pl.Name, err)
 	}
 	return nil
@@ -154,7 +159,12 @@ func LoadCommands() cli.Commands {
 					return xerrors.Errorf("initialize error: %w", err)
 				}
 
-				if err := p.Run(c.Context, c.Args().Slice()); err != nil {
+				env, err := scanContext(c)
+				if err != nil {
+					return xerrors.Errorf("scan context error: %w", err)
+				}
+
+				if err = p.Run(c.Context, plugin.RunOptions{Args: c.Args().Slice(), Env: env}); err != nil {
 					return xerrors.Errorf("plugin error: %w", err)
 				}
 				return nil
@@ -177,3 +187,36 @@ func initLogger(ctx *cli.Context) error {
 	}
 	return nil
 }
+
+// scanContext returns the environment variables passed to plugins.
+// Plugins can run "$TRIVY_BIN" with the same global options and config file as the parent process,
+// since the options are read from "TRIVY_*" environment variables.
+func scanContext(c *cli.Context) ([]string, error) {
+	conf, err := option.NewGlobalOption(c)
+	if err != nil {
+		return nil, xerrors.Errorf("config error: %w", err)
+	}
+
+	bin, err := os.Executable()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to get the executable path: %w", err)
+	}
+
+	env := []string{
+		"TRIVY_BIN=" + bin,
+		"TRIVY_APP_VERSION=" + conf.AppVersion,
+		"TRIVY_CACHE_DIR=" + conf.CacheDir,
+		fmt.Sprintf("TRIVY_DEBUG=%t", conf.Debug),
+		fmt.Sprintf("TRIVY_QUIET=%t", conf.Quiet),
+	}
+
+	// The default config file is resolved in the working directory of the plugin
+	if c.IsSet("config") {
+		configFile, err := filepath.Abs(c.String("config"))
+		if err != nil {
+			return nil, xerrors.Errorf("config file path error: %w", err)
+		}
+		env = append(env, "TRIVY_CONFIG="+configFile)
+	}
+	return env, nil
+}
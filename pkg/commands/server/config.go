@@ -1,9 +1,25 @@
 package server
 
 import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
 
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
 	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/credential"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/resultstore"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	rpcServer "github.com/aquasecurity/trivy/pkg/rpc/server"
+	"github.com/aquasecurity/trivy/pkg/utils"
+	"github.com/aquasecurity/trivy/pkg/webhook"
 )
 
 // Config holds the Trivy config
@@ -15,6 +31,59 @@ type Config struct {
 	Listen      string
 	Token       string
 	TokenHeader string
+	TLSCert     string
+	TLSKey      string
+	ClientCAs   []string
+
+	// TLSReloadInterval is the interval to check if the TLS certificate is rotated, and 0 disables reloading
+	TLSReloadInterval time.Duration
+
+	JWTIssuer   string
+	JWTAudience string
+	JWTJWKSURL  string
+	JWTSubjects []string
+
+	// AuthHeaders are required in the requests, e.g. the headers set by the API gateway in front of the server
+	AuthHeaders []string
+
+	// ProxyRegistries are the registries which clients can pull images from through the server
+	ProxyRegistries []string
+
+	// Insecure doesn't verify the registries the server pulls images from, and registryCAs verify them
+	// in addition to '--ca-bundle'. RegistryTransport is populated in Init() from them.
+	Insecure          bool
+	registryCAs       []string
+	RegistryTransport http.RoundTripper
+
+	// Limits protects the server from bursts of requests, which are rejected with 429,
+	// and MaxLayerSize is populated in Init() from maxLayerSize, e.g. "10GiB"
+	Limits       rpcServer.Limits
+	maxLayerSize string
+
+	// ResultCache reuses the results of the same scans
+	ResultCache rpcServer.ResultCacheOption
+
+	// AuditLog is the file to write the audit log of the requests, and "-" means stdout
+	AuditLog string
+
+	// Webhook is notified of the summaries of scans
+	Webhook webhook.Option
+
+	// ResultStore is the database to persist the summaries of scans in, e.g. sqlite:///path/to/results.db
+	ResultStore string
+
+	// WatchFile lists the images to re-scan at Rescan.Interval, and Rescan.Targets is populated in Init()
+	WatchFile string
+	Rescan    rpcServer.RescanOption
+
+	// Protocol serves the services also over gRPC on the same address with "grpc", in addition to Twirp
+	Protocol string
+
+	// TLSConfig is populated in Init() when TLS is enabled
+	TLSConfig *tls.Config
+
+	// Authenticator is populated in Init() when the token or JWTs are required
+	Authenticator rpcServer.Authenticator
 }
 
 // NewConfig is the factory method to return config
@@ -29,6 +98,40 @@ func NewConfig(c *cli.Context) Config {
 		Listen:      c.String("listen"),
 		Token:       c.String("token"),
 		TokenHeader: c.String("token-header"),
+		TLSCert:     c.String("tls-cert"),
+		TLSKey:      c.String("tls-key"),
+		ClientCAs:   c.StringSlice("client-ca"),
+
+		TLSReloadInterval: c.Duration("tls-reload-interval"),
+		JWTIssuer:         c.String("jwt-issuer"),
+		JWTAudience:       c.String("jwt-audience"),
+		JWTJWKSURL:        c.String("jwt-jwks-url"),
+		JWTSubjects:       c.StringSlice("jwt-subject"),
+		AuthHeaders:       c.StringSlice("auth-headers"),
+		ProxyRegistries:   c.StringSlice("proxy-registries"),
+		Insecure:          c.Bool("insecure"),
+		registryCAs:       c.StringSlice("registry-ca"),
+		Limits: rpcServer.Limits{
+			MaxConcurrentScans: c.Int("max-concurrent-scans"),
+			RateLimit:          c.Float64("rate-limit"),
+			RateLimitBurst:     c.Int("rate-limit-burst"),
+		},
+		maxLayerSize: c.String("max-layer-size"),
+		ResultCache: rpcServer.ResultCacheOption{
+			TTL:  c.Duration("result-cache-ttl"),
+			Size: c.Int("result-cache-size"),
+		},
+		AuditLog: c.String("audit-log"),
+		Webhook: webhook.Option{
+			URL:          c.String("webhook-url"),
+			AttachReport: c.Bool("webhook-attach-report"),
+		},
+		ResultStore: c.String("result-store"),
+		WatchFile:   c.String("watch-file"),
+		Rescan: rpcServer.RescanOption{
+			Interval: c.Duration("rescan-interval"),
+		},
+		Protocol: c.String("server-protocol"),
 	}
 }
 
@@ -40,6 +143,186 @@ func (c *Config) Init() (err error) {
 	if err := c.CacheOption.Init(); err != nil {
 		return err
 	}
+	if c.Token, err = credential.Resolve(c.Token); err != nil {
+		return xerrors.Errorf("--token error: %w", err)
+	}
+	if err = c.initTLS(); err != nil {
+		return xerrors.Errorf("TLS error: %w", err)
+	}
+	if err = c.initAuth(); err != nil {
+		return xerrors.Errorf("authentication error: %w", err)
+	}
+	if err = c.initRegistryTransport(); err != nil {
+		return xerrors.Errorf("--registry-ca error: %w", err)
+	}
+	if c.Limits.MaxConcurrentScans < 0 || c.Limits.RateLimit < 0 || c.Limits.RateLimitBurst < 0 {
+		return xerrors.New("'--max-concurrent-scans', '--rate-limit' and '--rate-limit-burst' must not be negative")
+	}
+	if c.maxLayerSize != "" {
+		if c.Limits.MaxLayerSize, err = units.RAMInBytes(c.maxLayerSize); err != nil {
+			return xerrors.Errorf("'--max-layer-size' error: %w", err)
+		}
+	}
+	if c.ResultCache.TTL < 0 || c.ResultCache.Size < 0 {
+		return xerrors.New("'--result-cache-ttl' and '--result-cache-size' must not be negative")
+	}
+	if c.Webhook.URL != "" {
+		if err = webhook.Validate(c.Webhook.URL); err != nil {
+			return xerrors.Errorf("webhook error: %w", err)
+		}
+	}
+	if c.ResultStore != "" && !strings.HasPrefix(c.ResultStore, resultstore.SQLitePrefix) {
+		return xerrors.Errorf("unsupported '--result-store' %q, must be %s/path/to/file", c.ResultStore, resultstore.SQLitePrefix)
+	}
+	if err = c.initRescan(); err != nil {
+		return xerrors.Errorf("re-scan error: %w", err)
+	}
+	if c.Protocol == "" {
+		c.Protocol = rpc.ProtocolTwirp
+	} else if !slices.Contains(rpc.Protocols, c.Protocol) {
+		return xerrors.Errorf("unknown '--server-protocol' %q, must be one of %q", c.Protocol, rpc.Protocols)
+	}
+
+	return nil
+}
+
+// initRegistryTransport verifies the registries the same as the CLI, so that the images pulled by the CLI
+// are also pulled through the server
+func (c *Config) initRegistryTransport() error {
+	rootCAs, err := utils.LoadCertPool(c.registryCAs)
+	if err != nil {
+		return err
+	}
+	if rootCAs != nil || c.Insecure {
+		c.RegistryTransport = tartifact.NewRegistryTransport(rootCAs, c.Insecure)
+	}
+	return nil
+}
+
+func (c *Config) initRescan() (err error) {
+	if c.Rescan.Interval < 0 {
+		return xerrors.New("'--rescan-interval' must not be negative")
+	} else if (c.Rescan.Interval > 0) != (c.WatchFile != "") {
+		return xerrors.New("both '--rescan-interval' and '--watch-file' must be specified")
+	} else if c.WatchFile == "" {
+		return nil
+	}
+
+	if c.Rescan.Targets, err = rpcServer.ReadWatchFile(c.WatchFile); err != nil {
+		return xerrors.Errorf("--watch-file error: %w", err)
+	}
+	if c.Webhook.URL == "" {
+		log.Logger.Warn("'--webhook-url' is not specified, new findings of re-scans are only logged")
+	}
+	return nil
+}
+
+func (c *Config) initAuth() error {
+	var auths rpcServer.Authenticators
+	if c.Token != "" {
+		auths = append(auths, rpcServer.NewTokenAuthenticator(c.Token, c.TokenHeader))
+	}
+
+	if c.JWTIssuer == "" && c.JWTJWKSURL == "" {
+		if c.JWTAudience != "" || len(c.JWTSubjects) > 0 {
+			return xerrors.New("'--jwt-audience' and '--jwt-subject' can be used only with '--jwt-issuer' or '--jwt-jwks-url'")
+		}
+	} else {
+		if c.JWTAudience == "" {
+			log.Logger.Warn("'--jwt-audience' is not specified, JWTs issued to any audience are accepted")
+		}
+		jwtAuth, err := rpcServer.NewJWTAuthenticator(rpcServer.JWTOption{
+			Issuer:      c.JWTIssuer,
+			Audience:    c.JWTAudience,
+			JWKSURL:     c.JWTJWKSURL,
+			Subjects:    c.JWTSubjects,
+			TokenHeader: c.TokenHeader,
+		})
+		if err != nil {
+			return xerrors.Errorf("JWT error: %w", err)
+		}
+		auths = append(auths, jwtAuth)
+	}
 
+	var auth rpcServer.Authenticator
+	switch len(auths) {
+	case 0:
+	case 1:
+		auth = auths[0]
+	default:
+		auth = auths
+	}
+
+	if len(c.AuthHeaders) > 0 {
+		headers := option.SplitHeaders(c.AuthHeaders)
+		if len(headers) != len(c.AuthHeaders) {
+			return xerrors.New("'--auth-headers' must be key=value or key:value")
+		}
+		// e.g. --auth-headers x-gateway-key=vault://secret/data/trivy#gateway-key
+		for name := range headers {
+			value, err := credential.Resolve(headers.Get(name))
+			if err != nil {
+				return xerrors.Errorf("--auth-headers error (%s): %w", name, err)
+			}
+			headers.Set(name, value)
+		}
+		headerAuth := rpcServer.NewHeaderAuthenticator(headers)
+
+		// The headers are required in addition to the token or JWTs, so that clients can't skip them
+		switch {
+		case auth != nil:
+			auth = rpcServer.RequiredAuthenticators{headerAuth, auth}
+		case headerAuth.Wildcard():
+			return xerrors.New("'--auth-headers' with only '*' values accept any client, " +
+				"specify the value of a header, '--token' or '--jwt-issuer' as well")
+		default:
+			auth = headerAuth
+		}
+	}
+
+	c.Authenticator = auth
+	return nil
+}
+
+func (c *Config) initTLS() error {
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return xerrors.New("both '--tls-cert' and '--tls-key' must be specified")
+	} else if c.TLSCert == "" {
+		if len(c.ClientCAs) > 0 {
+			return xerrors.New("'--client-ca' can be used only with '--tls-cert' and '--tls-key'")
+		}
+		return nil
+	}
+	// The clients don't verify the co-located server, and the access to the socket is controlled by the file mode
+	if strings.HasPrefix(c.Listen, rpcServer.UnixSocketScheme) {
+		return xerrors.New("TLS can't be used with the unix domain socket")
+	}
+
+	c.TLSConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.TLSReloadInterval > 0 {
+		reloader, err := newCertReloader(c.TLSCert, c.TLSKey, c.TLSReloadInterval)
+		if err != nil {
+			return err
+		}
+		c.TLSConfig.GetCertificate = reloader.GetCertificate
+	} else {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return xerrors.Errorf("certificate error: %w", err)
+		}
+		c.TLSConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	clientCAs, err := utils.LoadClientCertPool(c.ClientCAs)
+	if err != nil {
+		return xerrors.Errorf("--client-ca error: %w", err)
+	} else if clientCAs != nil {
+		// Client certificates are verified in the handshake if given, and the RPC handlers require them.
+		// The health checks are available without client certificates for probes of Kubernetes.
+		c.TLSConfig.ClientCAs = clientCAs
+		c.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
 	return nil
 }
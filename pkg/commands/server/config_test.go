@@ -2,7 +2,10 @@ package server_test
 
 import (
 	"flag"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -10,6 +13,8 @@ import (
 
 	"github.com/aquasecurity/trivy/pkg/commands/option"
 	"github.com/aquasecurity/trivy/pkg/commands/server"
+	rpcServer "github.com/aquasecurity/trivy/pkg/rpc/server"
+	"github.com/aquasecurity/trivy/pkg/webhook"
 )
 
 func TestNew(t *testing.T) {
@@ -62,7 +67,26 @@ func TestConfig_Init(t *testing.T) {
 		name         string
 		globalConfig option.GlobalOption
 		dbConfig     option.DBOption
+		listen       string
+		tlsCert      string
+		tlsKey       string
+		clientCAs    []string
+		tlsReload    time.Duration
+		token        string
+		jwtIssuer    string
+		jwtAudience  string
+		authHeaders  []string
+		limits       rpcServer.Limits
+		resultCache  rpcServer.ResultCacheOption
+		webhookURL   string
+		resultStore  string
+		rescan       time.Duration
+		watchFile    string
+		protocol     string
 		args         []string
+		wantTLS      bool
+		wantAuth     rpcServer.Authenticator
+		wantTargets  []string
 		wantErr      string
 	}{
 		{
@@ -76,6 +100,137 @@ func TestConfig_Init(t *testing.T) {
 			},
 			args: []string{"alpine:3.10"},
 		},
+		{
+			name:      "happy path with TLS",
+			tlsCert:   "testdata/certs/cert.pem",
+			tlsKey:    "testdata/certs/key.pem",
+			clientCAs: []string{"testdata/certs/cert.pem"},
+			wantTLS:   true,
+		},
+		{
+			name:      "happy path with TLS reloading",
+			tlsCert:   "testdata/certs/cert.pem",
+			tlsKey:    "testdata/certs/key.pem",
+			clientCAs: []string{"testdata/certs/cert.pem"},
+			tlsReload: time.Minute,
+			wantTLS:   true,
+		},
+		{
+			name:     "happy path with token",
+			token:    "secret",
+			wantAuth: rpcServer.TokenAuthenticator{},
+		},
+		{
+			name:        "happy path with JWT",
+			jwtIssuer:   "https://token.actions.githubusercontent.com",
+			jwtAudience: "trivy",
+			wantAuth:    &rpcServer.JWTAuthenticator{},
+		},
+		{
+			name:        "happy path with auth headers",
+			authHeaders: []string{"X-Gateway-Key=secret", "X-Authenticated-User:*"},
+			wantAuth:    rpcServer.HeaderAuthenticator{},
+		},
+		{
+			name:        "happy path with token and auth headers",
+			token:       "secret",
+			authHeaders: []string{"X-Authenticated-User=*"},
+			wantAuth:    rpcServer.RequiredAuthenticators{},
+		},
+		{
+			name:        "sad: only wildcard auth headers",
+			authHeaders: []string{"X-Authenticated-User=*"},
+			wantErr:     "'--auth-headers' with only '*' values accept any client",
+		},
+		{
+			name:   "happy path with limits",
+			limits: rpcServer.Limits{MaxConcurrentScans: 4, RateLimit: 0.5},
+		},
+		{
+			name:      "happy path with token and JWT",
+			token:     "secret",
+			jwtIssuer: "https://token.actions.githubusercontent.com",
+			wantAuth:  rpcServer.Authenticators{},
+		},
+		{
+			name:        "sad: JWT audience without issuer",
+			jwtAudience: "trivy",
+			wantErr:     "'--jwt-audience' and '--jwt-subject' can be used only with '--jwt-issuer' or '--jwt-jwks-url'",
+		},
+		{
+			name:        "sad: auth header without value",
+			authHeaders: []string{"X-Gateway-Key"},
+			wantErr:     "'--auth-headers' must be key=value or key:value",
+		},
+		{
+			name:    "sad: negative rate limit",
+			limits:  rpcServer.Limits{RateLimit: -1},
+			wantErr: "'--max-concurrent-scans', '--rate-limit' and '--rate-limit-burst' must not be negative",
+		},
+		{
+			name:        "sad: negative result cache TTL",
+			resultCache: rpcServer.ResultCacheOption{TTL: -time.Minute},
+			wantErr:     "'--result-cache-ttl' and '--result-cache-size' must not be negative",
+		},
+		{
+			name:       "sad: webhook URL without the scheme",
+			webhookURL: "hooks.slack.com/services/T000/B000/XXXX",
+			wantErr:    "the webhook URL must start with http:// or https://",
+		},
+		{
+			name:        "sad: unsupported result store",
+			resultStore: "postgres://localhost/trivy",
+			wantErr:     `unsupported '--result-store' "postgres://localhost/trivy"`,
+		},
+		{
+			name:        "happy path with re-scans",
+			rescan:      24 * time.Hour,
+			watchFile:   "testdata/watch.yaml",
+			wantTargets: []string{"alpine:3.15", "ghcr.io/aquasecurity/trivy:0.28.0"},
+		},
+		{
+			name:    "sad: re-scan interval without watch file",
+			rescan:  24 * time.Hour,
+			wantErr: "both '--rescan-interval' and '--watch-file' must be specified",
+		},
+		{
+			name:      "sad: missing watch file",
+			rescan:    24 * time.Hour,
+			watchFile: "testdata/missing.yaml",
+			wantErr:   "--watch-file error",
+		},
+		{
+			name:     "happy path with gRPC",
+			protocol: "grpc",
+		},
+		{
+			name:     "sad: unknown protocol",
+			protocol: "http3",
+			wantErr:  `unknown '--server-protocol' "http3"`,
+		},
+		{
+			name:    "sad: TLS certificate without key",
+			tlsCert: "testdata/certs/cert.pem",
+			wantErr: "both '--tls-cert' and '--tls-key' must be specified",
+		},
+		{
+			name:    "sad: TLS on unix socket",
+			listen:  "unix:///var/run/trivy.sock",
+			tlsCert: "testdata/certs/cert.pem",
+			tlsKey:  "testdata/certs/key.pem",
+			wantErr: "TLS can't be used with the unix domain socket",
+		},
+		{
+			name:      "sad: client CA without TLS",
+			clientCAs: []string{"testdata/certs/cert.pem"},
+			wantErr:   "'--client-ca' can be used only with '--tls-cert' and '--tls-key'",
+		},
+		{
+			name:    "sad: invalid TLS certificate",
+			tlsCert: "testdata/certs/key.pem",
+			tlsKey:  "testdata/certs/key.pem",
+			wantErr: "certificate error",
+		},
 		{
 			name: "sad: skip and download db",
 			dbConfig: option.DBOption{
@@ -89,7 +244,25 @@ func TestConfig_Init(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &server.Config{
-				DBOption: tt.dbConfig,
+				DBOption:  tt.dbConfig,
+				Listen:    tt.listen,
+				TLSCert:   tt.tlsCert,
+				TLSKey:    tt.tlsKey,
+				ClientCAs: tt.clientCAs,
+
+				TLSReloadInterval: tt.tlsReload,
+				Token:             tt.token,
+				TokenHeader:       option.DefaultTokenHeader,
+				JWTIssuer:         tt.jwtIssuer,
+				JWTAudience:       tt.jwtAudience,
+				AuthHeaders:       tt.authHeaders,
+				Limits:            tt.limits,
+				ResultCache:       tt.resultCache,
+				Webhook:           webhook.Option{URL: tt.webhookURL},
+				ResultStore:       tt.resultStore,
+				WatchFile:         tt.watchFile,
+				Rescan:            rpcServer.RescanOption{Interval: tt.rescan},
+				Protocol:          tt.protocol,
 			}
 
 			err := c.Init()
@@ -103,6 +276,124 @@ func TestConfig_Init(t *testing.T) {
 			default:
 				assert.NoError(t, err, tt.name)
 			}
+			assert.Equal(t, tt.wantTargets, c.Rescan.Targets)
+
+			if tt.wantAuth == nil {
+				assert.Nil(t, c.Authenticator)
+			} else {
+				assert.IsType(t, tt.wantAuth, c.Authenticator)
+			}
+
+			if !tt.wantTLS {
+				assert.Nil(t, c.TLSConfig)
+				return
+			}
+			require.NotNil(t, c.TLSConfig)
+			assert.NotNil(t, c.TLSConfig.ClientCAs)
+			if tt.tlsReload > 0 {
+				cert, err := c.TLSConfig.GetCertificate(nil)
+				require.NoError(t, err)
+				assert.NotNil(t, cert)
+			} else {
+				assert.Len(t, c.TLSConfig.Certificates, 1)
+			}
+		})
+	}
+}
+
+func TestConfig_Init_authHeaders(t *testing.T) {
+	c := &server.Config{
+		Listen:      "localhost:4954",
+		Token:       "secret",
+		TokenHeader: option.DefaultTokenHeader,
+		AuthHeaders: []string{"X-Authenticated-User=*"},
+	}
+	require.NoError(t, c.Init())
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr bool
+	}{
+		{
+			name: "happy path",
+			header: http.Header{
+				"X-Authenticated-User":    []string{"alice"},
+				option.DefaultTokenHeader: []string{"secret"},
+			},
+		},
+		{
+			name:    "sad path: only the wildcard header",
+			header:  http.Header{"X-Authenticated-User": []string{"alice"}},
+			wantErr: true,
+		},
+		{
+			name:    "sad path: only the token",
+			header:  http.Header{option.DefaultTokenHeader: []string{"secret"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header = tt.header
+			err := c.Authenticator.Authenticate(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConfig_Init_maxLayerSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int64
+		wantErr string
+	}{
+		{
+			name: "default",
+			want: 10 << 30,
+		},
+		{
+			name: "happy path",
+			args: []string{"--max-layer-size", "512MB"},
+			want: 512 << 20,
+		},
+		{
+			name: "unlimited",
+			args: []string{"--max-layer-size", "0"},
+		},
+		{
+			name:    "sad: invalid size",
+			args:    []string{"--max-layer-size", "large"},
+			wantErr: "'--max-layer-size' error",
+		},
+		{
+			name:    "sad: negative size",
+			args:    []string{"--max-layer-size", "-1"},
+			wantErr: "'--max-layer-size' error: invalid size",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", 0)
+			set.String("max-layer-size", "10GiB", "")
+			require.NoError(t, set.Parse(tt.args))
+
+			c := server.NewConfig(cli.NewContext(&cli.App{}, set, nil))
+			c.TokenHeader = option.DefaultTokenHeader
+			err := c.Init()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Limits.MaxLayerSize)
 		})
 	}
 }
@@ -1,12 +1,16 @@
 package server
 
 import (
+	"io"
+	"os"
+
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/trivy-db/pkg/db"
 	"github.com/aquasecurity/trivy/pkg/commands/operation"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/resultstore"
 	rpcServer "github.com/aquasecurity/trivy/pkg/rpc/server"
 	"github.com/aquasecurity/trivy/pkg/utils"
 )
@@ -40,7 +44,7 @@ func run(c Config) (err error) {
 	}
 
 	// download the database file
-	if err = operation.DownloadDB(c.AppVersion, c.CacheDir, c.DBRepository, true, c.SkipDBUpdate); err != nil {
+	if err = operation.DownloadDB(c.AppVersion, c.CacheDir, c.DBRepository, true, c.SkipDBUpdate, c.DBRootCAs); err != nil {
 		return err
 	}
 
@@ -52,6 +56,59 @@ func run(c Config) (err error) {
 		return xerrors.Errorf("error in vulnerability DB initialize: %w", err)
 	}
 
-	server := rpcServer.NewServer(c.AppVersion, c.Listen, c.CacheDir, c.Token, c.TokenHeader)
+	var auditLogger *rpcServer.AuditLogger
+	if c.AuditLog != "" {
+		w, err := openAuditLog(c.AuditLog)
+		if err != nil {
+			return xerrors.Errorf("audit log error: %w", err)
+		}
+		defer w.Close()
+		auditLogger = rpcServer.NewAuditLogger(w, c.TokenHeader)
+	}
+
+	var resultStore *resultstore.Store
+	if c.ResultStore != "" {
+		if resultStore, err = resultstore.Open(c.ResultStore); err != nil {
+			return xerrors.Errorf("result store error: %w", err)
+		}
+		defer resultStore.Close()
+	}
+
+	server := rpcServer.NewServer(rpcServer.ServerOption{
+		AppVersion:        c.AppVersion,
+		Addr:              c.Listen,
+		CacheDir:          c.CacheDir,
+		Auth:              c.Authenticator,
+		DBRootCAs:         c.DBRootCAs,
+		TLSConfig:         c.TLSConfig,
+		ProxyRegistries:   c.ProxyRegistries,
+		RegistryTransport: c.RegistryTransport,
+		Limits:            c.Limits,
+		ResultCache:       c.ResultCache,
+		AuditLogger:       auditLogger,
+		Webhook:           c.Webhook,
+		ResultStore:       resultStore,
+		Rescan:            c.Rescan,
+		Locale:            c.Locale,
+		Protocol:          c.Protocol,
+	})
 	return server.ListenAndServe(cache)
 }
+
+// openAuditLog opens the audit log file in append mode so that the log is kept across restarts
+func openAuditLog(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to open %s: %w", path, err)
+	}
+	return f, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// certReloader loads the key pair again when the files are modified, e.g. rotated by cert-manager.
+// The files are checked on TLS handshakes at most once per interval.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate for tls.Config
+func (r *certReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) < r.interval {
+		return r.cert, nil
+	}
+	r.checkedAt = time.Now()
+
+	// The current certificate continues to be used if the new one is broken, e.g. being written
+	modTime, err := r.latestModTime()
+	if err != nil {
+		log.Logger.Warnf("Unable to check the TLS certificate: %s", err)
+	} else if !modTime.Equal(r.modTime) {
+		if err = r.load(modTime); err != nil {
+			log.Logger.Warnf("Unable to reload the TLS certificate: %s", err)
+		} else {
+			log.Logger.Info("TLS certificate reloaded")
+		}
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return xerrors.Errorf("certificate error: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// latestModTime returns the modification time of the certificate or the key, whichever is newer
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		// Symlinks are followed as secrets of Kubernetes are mounted with symlinks
+		fi, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, xerrors.Errorf("stat error: %w", err)
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertReloader_GetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeKeyPair(t, certFile, keyFile, "old")
+
+	r, err := newCertReloader(certFile, keyFile, time.Nanosecond)
+	require.NoError(t, err)
+	assert.Equal(t, "old", commonName(t, r))
+
+	// Rotate the certificate
+	writeKeyPair(t, certFile, keyFile, "new")
+	modTime := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.Equal(t, "new", commonName(t, r))
+
+	// The current certificate is used while the new one is broken
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0600))
+	modTime = modTime.Add(time.Second)
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	assert.Equal(t, "new", commonName(t, r))
+
+	// The certificate is not checked within the interval
+	r.interval = time.Hour
+	writeKeyPair(t, certFile, keyFile, "newer")
+	modTime = modTime.Add(time.Second)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.Equal(t, "new", commonName(t, r))
+}
+
+func commonName(t *testing.T, r *certReloader) string {
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func writeKeyPair(t *testing.T, certFile, keyFile, cn string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
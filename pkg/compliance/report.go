@@ -0,0 +1,174 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/table"
+	pkgReport "github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const (
+	tableFormat = "table"
+	jsonFormat  = "json"
+)
+
+// Status is the status of a control
+type Status string
+
+const (
+	// StatusPass means none of the checks of the control failed
+	StatusPass Status = "PASS"
+
+	// StatusFail means some of the checks of the control failed
+	StatusFail Status = "FAIL"
+
+	// StatusManual means the control has no checks and has to be verified manually
+	StatusManual Status = "MANUAL"
+)
+
+// Report is the compliance report with the status of each control
+type Report struct {
+	ID               string
+	Title            string
+	Description      string   `json:",omitempty"`
+	Version          string   `json:",omitempty"`
+	RelatedResources []string `json:",omitempty"`
+	Results          []ControlResult
+}
+
+// ControlResult is the status of a control with the findings failing it
+type ControlResult struct {
+	ID          string
+	Name        string
+	Description string `json:",omitempty"`
+	Severity    string
+	Status      Status
+	Findings    []Finding `json:",omitempty"`
+}
+
+// Finding is a vulnerability or a misconfiguration failing a control
+type Finding struct {
+	Target   string
+	CheckID  string
+	ID       string
+	PkgName  string `json:",omitempty"`
+	Title    string `json:",omitempty"`
+	Severity string
+}
+
+// BuildReport evaluates the controls of the spec against the results of the scan.
+// The checks needing the security checks not in securityChecks are not evaluated,
+// e.g. VULN-CRITICAL by "trivy config", and the controls without evaluated checks are reported as MANUAL.
+func BuildReport(spec Spec, results types.Results, securityChecks []string) Report {
+	findings := map[string][]Finding{}
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			checkID := vulnCheckPrefix + vuln.Severity
+			findings[checkID] = append(findings[checkID], Finding{
+				Target:   result.Target,
+				CheckID:  checkID,
+				ID:       vuln.VulnerabilityID,
+				PkgName:  vuln.PkgName,
+				Title:    vuln.Title,
+				Severity: vuln.Severity,
+			})
+		}
+		for _, misconf := range result.Misconfigurations {
+			if misconf.Status != types.StatusFailure {
+				continue
+			}
+			findings[misconf.ID] = append(findings[misconf.ID], Finding{
+				Target:   result.Target,
+				CheckID:  misconf.ID,
+				ID:       misconf.ID,
+				Title:    misconf.Title,
+				Severity: misconf.Severity,
+			})
+		}
+	}
+
+	report := Report{
+		ID:               spec.ID,
+		Title:            spec.Title,
+		Description:      spec.Description,
+		Version:          spec.Version,
+		RelatedResources: spec.RelatedResources,
+	}
+	for _, control := range spec.Controls {
+		result := ControlResult{
+			ID:          control.ID,
+			Name:        control.Name,
+			Description: control.Description,
+			Severity:    control.Severity,
+			Status:      StatusPass,
+		}
+		var evaluated bool
+		for _, check := range control.Checks {
+			if !slices.Contains(securityChecks, check.securityCheck()) {
+				continue
+			}
+			evaluated = true
+			result.Findings = append(result.Findings, findings[check.ID]...)
+		}
+		if !evaluated {
+			result.Status = StatusManual
+		} else if len(result.Findings) > 0 {
+			result.Status = StatusFail
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Option is the option to write the compliance report
+type Option struct {
+	Format string
+	Output io.Writer
+}
+
+// Write writes the compliance report in the format, the summary of the controls in the table format
+func Write(report Report, option Option) error {
+	switch option.Format {
+	case jsonFormat:
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return xerrors.Errorf("failed to marshal json: %w", err)
+		}
+		if _, err = fmt.Fprintln(option.Output, string(output)); err != nil {
+			return xerrors.Errorf("failed to write json: %w", err)
+		}
+	case tableFormat:
+		writeSummary(report, option.Output)
+	default:
+		return xerrors.Errorf(`unknown format %q for the compliance report. Use "json" or "table"`, option.Format)
+	}
+	return nil
+}
+
+func writeSummary(report Report, output io.Writer) {
+	_, _ = fmt.Fprintln(output)
+	_, _ = fmt.Fprintf(output, "Summary Report for compliance: %s\n", report.Title)
+
+	t := table.New(output)
+	t.SetRowLines(false)
+	t.SetHeaders("ID", "Severity", "Control Name", "Status", "Issues")
+	t.SetAlignment(table.AlignLeft, table.AlignCenter, table.AlignLeft, table.AlignCenter, table.AlignCenter)
+
+	for _, result := range report.Results {
+		issues := "-"
+		if result.Status != StatusManual {
+			issues = strconv.Itoa(len(result.Findings))
+		}
+		t.AddRow(result.ID, pkgReport.ColorizeSeverity(result.Severity, result.Severity), result.Name,
+			string(result.Status), issues)
+	}
+	t.Render()
+	_, _ = fmt.Fprintln(output)
+}
@@ -0,0 +1,229 @@
+package compliance_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/compliance"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+var testResults = types.Results{
+	{
+		Target: "alpine:3.15 (alpine 3.15.4)",
+		Class:  types.ClassOSPkg,
+		Vulnerabilities: []types.DetectedVulnerability{
+			{
+				VulnerabilityID: "CVE-2022-37434",
+				PkgName:         "zlib",
+				Vulnerability: dbTypes.Vulnerability{
+					Title:    "zlib: heap-based buffer over-read",
+					Severity: dbTypes.SeverityCritical.String(),
+				},
+			},
+			{
+				VulnerabilityID: "CVE-2022-28391",
+				PkgName:         "busybox",
+				Vulnerability: dbTypes.Vulnerability{
+					Severity: dbTypes.SeverityHigh.String(),
+				},
+			},
+		},
+	},
+	{
+		Target: "Dockerfile",
+		Class:  types.ClassConfig,
+		Misconfigurations: []types.DetectedMisconfiguration{
+			{
+				ID:       "DS002",
+				Title:    "Image user should not be 'root'",
+				Severity: dbTypes.SeverityHigh.String(),
+				Status:   types.StatusFailure,
+			},
+			{
+				ID:       "DS005",
+				Title:    "ADD instead of COPY",
+				Severity: dbTypes.SeverityLow.String(),
+				Status:   types.StatusPassed,
+			},
+		},
+	},
+}
+
+func TestBuildReport(t *testing.T) {
+	spec, err := compliance.GetSpec("testdata/custom.yaml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		results        types.Results
+		securityChecks []string
+		want           []compliance.ControlResult
+	}{
+		{
+			name:           "failed controls",
+			results:        testResults,
+			securityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckConfig},
+			want: []compliance.ControlResult{
+				{
+					ID:       "1",
+					Name:     "Run as non-root",
+					Severity: "HIGH",
+					Status:   compliance.StatusFail,
+					Findings: []compliance.Finding{
+						{
+							Target:   "Dockerfile",
+							CheckID:  "DS002",
+							ID:       "DS002",
+							Title:    "Image user should not be 'root'",
+							Severity: "HIGH",
+						},
+					},
+				},
+				{
+					ID:       "2",
+					Name:     "No critical vulnerabilities",
+					Severity: "CRITICAL",
+					Status:   compliance.StatusFail,
+					Findings: []compliance.Finding{
+						{
+							Target:   "alpine:3.15 (alpine 3.15.4)",
+							CheckID:  "VULN-CRITICAL",
+							ID:       "CVE-2022-37434",
+							PkgName:  "zlib",
+							Title:    "zlib: heap-based buffer over-read",
+							Severity: "CRITICAL",
+						},
+					},
+				},
+				{
+					ID:       "3",
+					Name:     "Signed images",
+					Severity: "MEDIUM",
+					Status:   compliance.StatusManual,
+				},
+			},
+		},
+		{
+			name:           "passed controls",
+			securityChecks: []string{types.SecurityCheckVulnerability, types.SecurityCheckConfig},
+			want: []compliance.ControlResult{
+				{
+					ID:       "1",
+					Name:     "Run as non-root",
+					Severity: "HIGH",
+					Status:   compliance.StatusPass,
+				},
+				{
+					ID:       "2",
+					Name:     "No critical vulnerabilities",
+					Severity: "CRITICAL",
+					Status:   compliance.StatusPass,
+				},
+				{
+					ID:       "3",
+					Name:     "Signed images",
+					Severity: "MEDIUM",
+					Status:   compliance.StatusManual,
+				},
+			},
+		},
+		{
+			name:           "vulnerabilities not scanned",
+			results:        testResults[1:],
+			securityChecks: []string{types.SecurityCheckConfig},
+			want: []compliance.ControlResult{
+				{
+					ID:       "1",
+					Name:     "Run as non-root",
+					Severity: "HIGH",
+					Status:   compliance.StatusFail,
+					Findings: []compliance.Finding{
+						{
+							Target:   "Dockerfile",
+							CheckID:  "DS002",
+							ID:       "DS002",
+							Title:    "Image user should not be 'root'",
+							Severity: "HIGH",
+						},
+					},
+				},
+				{
+					ID:       "2",
+					Name:     "No critical vulnerabilities",
+					Severity: "CRITICAL",
+					Status:   compliance.StatusManual,
+				},
+				{
+					ID:       "3",
+					Name:     "Signed images",
+					Severity: "MEDIUM",
+					Status:   compliance.StatusManual,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compliance.BuildReport(spec, tt.results, tt.securityChecks)
+			assert.Equal(t, "acme", got.ID)
+			assert.Equal(t, "Acme Container Policy", got.Title)
+			assert.Equal(t, tt.want, got.Results)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	spec, err := compliance.GetSpec("testdata/custom.yaml")
+	require.NoError(t, err)
+	report := compliance.BuildReport(spec, testResults,
+		[]string{types.SecurityCheckVulnerability, types.SecurityCheckConfig})
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "table",
+			format: "table",
+			want: `
+Summary Report for compliance: Acme Container Policy
+┌────┬──────────┬─────────────────────────────┬────────┬────────┐
+│ ID │ Severity │        Control Name         │ Status │ Issues │
+├────┼──────────┼─────────────────────────────┼────────┼────────┤
+│ 1  │   HIGH   │ Run as non-root             │  FAIL  │   1    │
+│ 2  │ CRITICAL │ No critical vulnerabilities │  FAIL  │   1    │
+│ 3  │  MEDIUM  │ Signed images               │ MANUAL │   -    │
+└────┴──────────┴─────────────────────────────┴────────┴────────┘
+
+`,
+		},
+		{
+			name:    "sarif",
+			format:  "sarif",
+			wantErr: `unknown format "sarif" for the compliance report`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := bytes.Buffer{}
+			err := compliance.Write(report, compliance.Option{
+				Format: tt.format,
+				Output: &output,
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, output.String())
+		})
+	}
+}
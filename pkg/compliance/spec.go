@@ -0,0 +1,146 @@
+package compliance
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// vulnCheckPrefix is the prefix of the checks matching the vulnerabilities by the severity, e.g. VULN-CRITICAL
+const vulnCheckPrefix = "VULN-"
+
+//go:embed specs/*.yaml
+var builtinSpecs embed.FS
+
+// Spec is the compliance specification mapping the checks of Trivy onto the controls of a benchmark
+type Spec struct {
+	ID               string    `yaml:"id"`
+	Title            string    `yaml:"title"`
+	Description      string    `yaml:"description"`
+	Version          string    `yaml:"version"`
+	RelatedResources []string  `yaml:"relatedResources"`
+	Controls         []Control `yaml:"controls"`
+}
+
+// Control is a control of the benchmark passing when none of the checks fail.
+// The controls without checks have to be verified manually.
+type Control struct {
+	ID          string  `yaml:"id"`
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Checks      []Check `yaml:"checks"`
+	Severity    string  `yaml:"severity"`
+}
+
+// Check is the ID of the misconfiguration check, e.g. DS002,
+// or VULN-<SEVERITY> matching the vulnerabilities of the severity
+type Check struct {
+	ID string `yaml:"id"`
+}
+
+// BuiltinSpecs returns the IDs of the built-in specifications
+func BuiltinSpecs() []string {
+	entries, err := builtinSpecs.ReadDir("specs")
+	if err != nil {
+		return nil
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// GetSpec returns the built-in specification by the ID, or loads the specification from the YAML file
+func GetSpec(name string) (Spec, error) {
+	b, err := builtinSpecs.ReadFile(path.Join("specs", name+".yaml"))
+	if err != nil {
+		b, err = os.ReadFile(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return Spec{}, xerrors.Errorf("unknown compliance spec %q, use one of %s or a path to the YAML file",
+				name, strings.Join(BuiltinSpecs(), ", "))
+		} else if err != nil {
+			return Spec{}, xerrors.Errorf("file read error: %w", err)
+		}
+	}
+
+	var raw struct {
+		Spec Spec `yaml:"spec"`
+	}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return Spec{}, xerrors.Errorf("YAML decode error (%s): %w", name, err)
+	}
+	if err = raw.Spec.validate(); err != nil {
+		return Spec{}, xerrors.Errorf("invalid compliance spec (%s): %w", name, err)
+	}
+	return raw.Spec, nil
+}
+
+func (s Spec) validate() error {
+	if s.ID == "" {
+		return xerrors.New("the spec has no ID")
+	}
+	if len(s.Controls) == 0 {
+		return xerrors.New("the spec has no controls")
+	}
+	for i, control := range s.Controls {
+		if control.ID == "" {
+			return xerrors.Errorf("the control %d has no ID", i+1)
+		}
+		if _, err := dbTypes.NewSeverity(control.Severity); err != nil {
+			return xerrors.Errorf("invalid severity %q of the control %s: %w", control.Severity, control.ID, err)
+		}
+		for _, check := range control.Checks {
+			if check.ID == "" {
+				return xerrors.Errorf("the control %s has a check without ID", control.ID)
+			}
+			if severity, ok := vulnSeverity(check.ID); ok {
+				if _, err := dbTypes.NewSeverity(severity); err != nil {
+					return xerrors.Errorf("invalid check %s of the control %s: %w", check.ID, control.ID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SecurityChecks returns the security checks needed to evaluate the controls
+func (s Spec) SecurityChecks() []string {
+	var checks []string
+	for _, control := range s.Controls {
+		for _, check := range control.Checks {
+			if securityCheck := check.securityCheck(); !slices.Contains(checks, securityCheck) {
+				checks = append(checks, securityCheck)
+			}
+		}
+	}
+	return checks
+}
+
+// securityCheck returns the security check finding the issues failing the check
+func (c Check) securityCheck() string {
+	if _, ok := vulnSeverity(c.ID); ok {
+		return types.SecurityCheckVulnerability
+	}
+	return types.SecurityCheckConfig
+}
+
+func vulnSeverity(checkID string) (string, bool) {
+	if !strings.HasPrefix(checkID, vulnCheckPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(checkID, vulnCheckPrefix), true
+}
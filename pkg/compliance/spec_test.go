@@ -0,0 +1,85 @@
+package compliance_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/compliance"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestGetSpec(t *testing.T) {
+	tests := []struct {
+		name               string
+		spec               string
+		wantID             string
+		wantControls       int
+		wantSecurityChecks []string
+		wantErr            string
+	}{
+		{
+			name:               "built-in docker-cis",
+			spec:               "docker-cis",
+			wantID:             "docker-cis",
+			wantControls:       11,
+			wantSecurityChecks: []string{types.SecurityCheckConfig, types.SecurityCheckVulnerability},
+		},
+		{
+			name:               "built-in k8s-nsa",
+			spec:               "k8s-nsa",
+			wantID:             "k8s-nsa",
+			wantControls:       19,
+			wantSecurityChecks: []string{types.SecurityCheckConfig, types.SecurityCheckVulnerability},
+		},
+		{
+			name:               "built-in k8s-cis",
+			spec:               "k8s-cis",
+			wantID:             "k8s-cis",
+			wantControls:       15,
+			wantSecurityChecks: []string{types.SecurityCheckConfig},
+		},
+		{
+			name:               "custom spec",
+			spec:               "testdata/custom.yaml",
+			wantID:             "acme",
+			wantControls:       3,
+			wantSecurityChecks: []string{types.SecurityCheckConfig, types.SecurityCheckVulnerability},
+		},
+		{
+			name:    "unknown spec",
+			spec:    "pci-dss",
+			wantErr: `unknown compliance spec "pci-dss", use one of docker-cis, k8s-cis, k8s-nsa or a path to the YAML file`,
+		},
+		{
+			name:    "invalid severity",
+			spec:    "testdata/invalid-severity.yaml",
+			wantErr: `invalid severity "SEVERE" of the control 1`,
+		},
+		{
+			name:    "invalid check",
+			spec:    "testdata/invalid-check.yaml",
+			wantErr: "invalid check VULN-ANY of the control 1",
+		},
+		{
+			name:    "no controls",
+			spec:    "testdata/no-controls.yaml",
+			wantErr: "the spec has no controls",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compliance.GetSpec(tt.spec)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, got.ID)
+			assert.Len(t, got.Controls, tt.wantControls)
+			assert.Equal(t, tt.wantSecurityChecks, got.SecurityChecks())
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/image"
+	"github.com/aquasecurity/fanal/image/daemon"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// The kinds of the changes in the writable layer, the same as github.com/docker/docker/pkg/archive
+const (
+	changeModify = iota
+	changeAdd
+	changeDelete
+)
+
+// Client is the part of the Docker API to read the filesystems of the containers
+type Client interface {
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerDiff(ctx context.Context, containerID string) ([]container.ContainerChangeResponseItem, error)
+	ContainerExport(ctx context.Context, containerID string) (io.ReadCloser, error)
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error)
+}
+
+// baseImage returns the image of the container from the daemon
+type baseImage func(imageID string) (daemon.Image, func(), error)
+
+// NewImage returns the image of the container in the Docker daemon, which has the writable layer
+// of the container on top of the layers of its image, so that the packages installed at runtime are detected.
+// The layers of the image are cached as usual, and only the writable layer is analyzed in the next scans.
+// The volumes and the bind mounts are added to the writable layer with includeMounts.
+// The caller must call cleanup() to remove the writable layer saved in a temporary file in tempDir.
+func NewImage(ctx context.Context, docker artifact.DockerDaemon, tempDir, containerID string, includeMounts bool) (
+	ftypes.Image, func(), error) {
+	c, err := docker.Client()
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	img, cleanup, err := newImage(ctx, c, tempDir, containerID, includeMounts, func(imageID string) (daemon.Image, func(), error) {
+		// The image is looked up by the ID, since the tag may have been moved to another image
+		ref, err := name.ParseReference(strings.TrimPrefix(imageID, "sha256:"))
+		if err != nil {
+			return nil, func() {}, xerrors.Errorf("invalid image ID (%s): %w", imageID, err)
+		}
+		return docker.Image(ctx, ref, tempDir)
+	})
+	if err != nil {
+		_ = c.Close()
+		return nil, func() {}, err
+	}
+	return img, func() {
+		cleanup()
+		_ = c.Close()
+	}, nil
+}
+
+func newImage(ctx context.Context, c Client, tempDir, containerID string, includeMounts bool, base baseImage) (
+	ftypes.Image, func(), error) {
+	inspect, err := c.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, func() {}, xerrors.Errorf("unable to inspect the container (%s): %w", containerID, err)
+	}
+
+	img, cleanup, err := base(inspect.Image)
+	if err != nil {
+		return nil, func() {}, xerrors.Errorf("unable to get the image of the container (%s): %w", inspect.Image, err)
+	}
+
+	f, err := os.CreateTemp(tempDir, "trivy-container-*")
+	if err != nil {
+		cleanup()
+		return nil, func() {}, xerrors.Errorf("failed to create a temporary file: %w", err)
+	}
+	removeLayer := func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	defer func() {
+		if err != nil {
+			removeLayer()
+			cleanup()
+		}
+	}()
+
+	h := sha256.New()
+	var written bool
+	if written, err = writeLayer(ctx, c, inspect, includeMounts, io.MultiWriter(f, h)); err != nil {
+		return nil, func() {}, xerrors.Errorf("unable to read the writable layer of the container (%s): %w", containerID, err)
+	}
+
+	ci := containerImage{
+		Image: img,
+		name:  strings.TrimPrefix(inspect.Name, "/"),
+	}
+	if ci.name == "" {
+		ci.name = inspect.ID
+	}
+
+	// Nothing has been changed in the container
+	if !written {
+		log.Logger.Debugf("No change in the container %s", ci.name)
+		return ci, func() {
+			removeLayer()
+			cleanup()
+		}, nil
+	}
+
+	ci.diffID = v1.Hash{Algorithm: "sha256", Hex: fmt.Sprintf("%x", h.Sum(nil))}
+	if ci.layer, err = partial.UncompressedToLayer(&writableLayer{path: f.Name(), diffID: ci.diffID}); err != nil {
+		return nil, func() {}, xerrors.Errorf("layer error: %w", err)
+	}
+	if ci.rawConfig, err = containerConfig(img, inspect, ci.diffID); err != nil {
+		return nil, func() {}, xerrors.Errorf("config error: %w", err)
+	}
+
+	return ci, func() {
+		removeLayer()
+		cleanup()
+	}, nil
+}
+
+// writeLayer writes the files changed in the container as a layer,
+// and the files in the mounts with includeMounts. It reports whether any file is written.
+func writeLayer(ctx context.Context, c Client, inspect types.ContainerJSON, includeMounts bool, w io.Writer) (bool, error) {
+	changes, err := c.ContainerDiff(ctx, inspect.ID)
+	if err != nil {
+		return false, xerrors.Errorf("diff error: %w", err)
+	}
+
+	changed := map[string]struct{}{}
+	var deleted []string
+	for _, change := range changes {
+		p := strings.TrimPrefix(path.Clean(change.Path), "/")
+		switch change.Kind {
+		case changeModify, changeAdd:
+			changed[p] = struct{}{}
+		case changeDelete:
+			deleted = append(deleted, p)
+		}
+	}
+
+	tw := tar.NewWriter(w)
+	var n int
+
+	// The changed files are picked from the whole filesystem exported
+	if len(changed) > 0 {
+		rc, err := c.ContainerExport(ctx, inspect.ID)
+		if err != nil {
+			return false, xerrors.Errorf("export error: %w", err)
+		}
+		defer rc.Close()
+
+		m, err := copyEntries(tw, tar.NewReader(rc), func(entryName string) (string, bool) {
+			_, ok := changed[entryName]
+			return entryName, ok
+		})
+		if err != nil {
+			return false, xerrors.Errorf("export error: %w", err)
+		}
+		n += m
+	}
+
+	// The deleted files are hidden by the whiteouts
+	for _, p := range deleted {
+		dir, file := path.Split(p)
+		if err = tw.WriteHeader(&tar.Header{Name: dir + ".wh." + file, Typeflag: tar.TypeReg, Mode: 0o644}); err != nil {
+			return false, xerrors.Errorf("whiteout error: %w", err)
+		}
+		n++
+	}
+
+	if includeMounts {
+		m, err := writeMounts(ctx, c, inspect, tw)
+		if err != nil {
+			return false, err
+		}
+		n += m
+	}
+
+	if err = tw.Close(); err != nil {
+		return false, xerrors.Errorf("tar error: %w", err)
+	}
+	return n > 0, nil
+}
+
+// writeMounts writes the files in the volumes and the bind mounts at their destinations in the container.
+// The files of the image under the destinations are hidden with the opaque whiteouts, as the mounts do.
+func writeMounts(ctx context.Context, c Client, inspect types.ContainerJSON, tw *tar.Writer) (int, error) {
+	var n int
+	for _, mount := range inspect.Mounts {
+		dest := strings.TrimPrefix(path.Clean(mount.Destination), "/")
+		if dest == "" {
+			continue
+		}
+
+		rc, stat, err := c.CopyFromContainer(ctx, inspect.ID, mount.Destination)
+		if err != nil {
+			log.Logger.Warnf("Unable to read the mount %s of the container: %s", mount.Destination, err)
+			continue
+		}
+
+		if stat.Mode.IsDir() {
+			if err = tw.WriteHeader(&tar.Header{Name: dest + "/.wh..wh..opq", Typeflag: tar.TypeReg, Mode: 0o644}); err != nil {
+				_ = rc.Close()
+				return 0, xerrors.Errorf("whiteout error: %w", err)
+			}
+			n++
+		}
+
+		// The entries are relative to the parent of the destination, e.g. "data/file" for "/var/lib/data"
+		m, err := copyEntries(tw, tar.NewReader(rc), func(entryName string) (string, bool) {
+			rel := strings.TrimPrefix(entryName, stat.Name)
+			if rel != "" && !strings.HasPrefix(rel, "/") {
+				return "", false
+			}
+			return dest + rel, true
+		})
+		_ = rc.Close()
+		if err != nil {
+			return 0, xerrors.Errorf("mount error (%s): %w", mount.Destination, err)
+		}
+		n += m
+	}
+	return n, nil
+}
+
+// copyEntries copies the entries selected by rename with the new names, and returns the number of entries copied
+func copyEntries(tw *tar.Writer, tr *tar.Reader, rename func(entryName string) (string, bool)) (int, error) {
+	var n int
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return 0, xerrors.Errorf("tar read error: %w", err)
+		}
+
+		entryName := strings.TrimPrefix(path.Clean(hdr.Name), "/")
+		newName, ok := rename(entryName)
+		if !ok {
+			continue
+		}
+		hdr.Name = newName
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Name += "/"
+		} else if hdr.Typeflag == tar.TypeLink {
+			if hdr.Linkname, ok = rename(strings.TrimPrefix(path.Clean(hdr.Linkname), "/")); !ok {
+				continue
+			}
+		}
+
+		if err = tw.WriteHeader(hdr); err != nil {
+			return 0, xerrors.Errorf("tar write error: %w", err)
+		}
+		if _, err = io.Copy(tw, tr); err != nil {
+			return 0, xerrors.Errorf("tar write error: %w", err)
+		}
+		n++
+	}
+}
+
+// containerConfig returns the config of the image with the writable layer added
+func containerConfig(img v1.Image, inspect types.ContainerJSON, diffID v1.Hash) ([]byte, error) {
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to get the config file: %w", err)
+	}
+	config = config.DeepCopy()
+	config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, diffID)
+
+	// The creation time of the container keeps the config the same until the writable layer changes
+	var created v1.Time
+	if t, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
+		created = v1.Time{Time: t}
+	}
+	config.History = append(config.History, v1.History{
+		Created:   created,
+		CreatedBy: "writable layer of the container " + inspect.ID,
+	})
+
+	return json.Marshal(config)
+}
+
+// containerImage is the image of the container with the writable layer
+type containerImage struct {
+	daemon.Image
+	name string
+
+	// the writable layer, which is nil if nothing has been changed in the container
+	layer     v1.Layer
+	diffID    v1.Hash
+	rawConfig []byte
+}
+
+// Name returns the name of the container
+func (img containerImage) Name() string {
+	return img.name
+}
+
+func (img containerImage) ID() (string, error) {
+	return image.ID(img)
+}
+
+func (img containerImage) LayerIDs() ([]string, error) {
+	return image.LayerIDs(img)
+}
+
+func (img containerImage) ConfigName() (v1.Hash, error) {
+	if img.layer == nil {
+		return img.Image.ConfigName()
+	}
+	h, _, err := v1.SHA256(bytes.NewReader(img.rawConfig))
+	return h, err
+}
+
+func (img containerImage) ConfigFile() (*v1.ConfigFile, error) {
+	if img.layer == nil {
+		return img.Image.ConfigFile()
+	}
+	return v1.ParseConfigFile(bytes.NewReader(img.rawConfig))
+}
+
+func (img containerImage) RawConfigFile() ([]byte, error) {
+	if img.layer == nil {
+		return img.Image.RawConfigFile()
+	}
+	return img.rawConfig, nil
+}
+
+func (img containerImage) LayerByDiffID(h v1.Hash) (v1.Layer, error) {
+	if img.layer != nil && h == img.diffID {
+		return img.layer, nil
+	}
+	return img.Image.LayerByDiffID(h)
+}
+
+// writableLayer is the writable layer saved in the temporary file
+type writableLayer struct {
+	path   string
+	diffID v1.Hash
+}
+
+func (l *writableLayer) DiffID() (v1.Hash, error) {
+	return l.diffID, nil
+}
+
+func (l *writableLayer) Uncompressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+func (l *writableLayer) MediaType() (ggcrtypes.MediaType, error) {
+	return ggcrtypes.DockerLayer, nil
+}
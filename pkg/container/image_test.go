@@ -0,0 +1,182 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/image/daemon"
+)
+
+type fakeClient struct {
+	changes []container.ContainerChangeResponseItem
+	export  map[string]string
+	mounts  map[string]map[string]string
+}
+
+func (c fakeClient) ContainerInspect(_ context.Context, containerID string) (types.ContainerJSON, error) {
+	inspect := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:      containerID,
+			Name:    "/myapp",
+			Image:   "sha256:0123456789abcdef",
+			Created: "2022-05-20T10:00:00.123456789Z",
+		},
+	}
+	for dest := range c.mounts {
+		inspect.Mounts = append(inspect.Mounts, types.MountPoint{Type: "bind", Destination: dest})
+	}
+	return inspect, nil
+}
+
+func (c fakeClient) ContainerDiff(context.Context, string) ([]container.ContainerChangeResponseItem, error) {
+	return c.changes, nil
+}
+
+func (c fakeClient) ContainerExport(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(tarball(c.export)), nil
+}
+
+func (c fakeClient) CopyFromContainer(_ context.Context, _, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+	return io.NopCloser(tarball(c.mounts[srcPath])), types.ContainerPathStat{Name: "data", Mode: os.ModeDir | 0o755}, nil
+}
+
+func tarball(files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	return &buf
+}
+
+type fakeImage struct {
+	v1.Image
+}
+
+func (fakeImage) RepoTags() []string    { return []string{"myapp:latest"} }
+func (fakeImage) RepoDigests() []string { return nil }
+
+func TestNewImage(t *testing.T) {
+	base, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	baseDiffIDs := diffIDs(t, base)
+
+	tests := []struct {
+		name          string
+		client        fakeClient
+		includeMounts bool
+		wantFiles     []string
+		wantLayers    int
+	}{
+		{
+			name: "happy path",
+			client: fakeClient{
+				changes: []container.ContainerChangeResponseItem{
+					{Kind: changeModify, Path: "/lib/apk/db/installed"},
+					{Kind: changeAdd, Path: "/usr/local/bin/app"},
+					{Kind: changeDelete, Path: "/etc/motd"},
+				},
+				export: map[string]string{
+					"lib/apk/db/installed": "P:musl",
+					"usr/local/bin/app":    "app",
+					"etc/passwd":           "root",
+				},
+				mounts: map[string]map[string]string{
+					"/var/lib/data": {"data/package.json": "{}"},
+				},
+			},
+			wantFiles:  []string{"lib/apk/db/installed", "usr/local/bin/app", "etc/.wh.motd"},
+			wantLayers: 3,
+		},
+		{
+			name: "include mounts",
+			client: fakeClient{
+				changes: []container.ContainerChangeResponseItem{
+					{Kind: changeAdd, Path: "/usr/local/bin/app"},
+				},
+				export: map[string]string{
+					"usr/local/bin/app": "app",
+				},
+				mounts: map[string]map[string]string{
+					"/var/lib/data": {"data/package.json": "{}"},
+				},
+			},
+			includeMounts: true,
+			wantFiles:     []string{"usr/local/bin/app", "var/lib/data/.wh..wh..opq", "var/lib/data/package.json"},
+			wantLayers:    3,
+		},
+		{
+			name:       "no change",
+			client:     fakeClient{},
+			wantLayers: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var baseCleaned bool
+			img, cleanup, err := newImage(context.Background(), tt.client, t.TempDir(), "4c3b2a1f", tt.includeMounts,
+				func(imageID string) (daemon.Image, func(), error) {
+					assert.Equal(t, "sha256:0123456789abcdef", imageID)
+					return fakeImage{Image: base}, func() { baseCleaned = true }, nil
+				})
+			require.NoError(t, err)
+
+			assert.Equal(t, "myapp", img.Name())
+			assert.Equal(t, []string{"myapp:latest"}, img.RepoTags())
+
+			got := diffIDs(t, img)
+			require.Len(t, got, tt.wantLayers)
+			assert.Equal(t, baseDiffIDs, got[:2])
+
+			layerIDs, err := img.LayerIDs()
+			require.NoError(t, err)
+			assert.Len(t, layerIDs, tt.wantLayers)
+
+			if len(tt.wantFiles) > 0 {
+				layer, err := img.LayerByDiffID(got[2])
+				require.NoError(t, err)
+				rc, err := layer.Uncompressed()
+				require.NoError(t, err)
+				defer rc.Close()
+
+				var files []string
+				tr := tar.NewReader(rc)
+				for {
+					hdr, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					require.NoError(t, err)
+					files = append(files, hdr.Name)
+				}
+				assert.ElementsMatch(t, tt.wantFiles, files)
+
+				config, err := img.ConfigFile()
+				require.NoError(t, err)
+				assert.Equal(t, "writable layer of the container 4c3b2a1f", config.History[len(config.History)-1].CreatedBy)
+			}
+
+			cleanup()
+			assert.True(t, baseCleaned)
+		})
+	}
+}
+
+func diffIDs(t *testing.T, img v1.Image) []v1.Hash {
+	config, err := img.ConfigFile()
+	require.NoError(t, err)
+	return config.RootFS.DiffIDs
+}
@@ -0,0 +1,36 @@
+package credential
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"golang.org/x/xerrors"
+)
+
+// awsKMS decrypts the data key encrypted with AWS KMS, e.g. by "aws kms generate-data-key", with the default
+// credential chain. The path is the base64-encoded ciphertext, and the plaintext key is returned base64-encoded.
+func awsKMS(ctx context.Context, path, key string) (string, error) {
+	if key != "" {
+		return "", xerrors.New("the data key of AWS KMS has no fields")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(path)
+	if err != nil {
+		return "", xerrors.Errorf("the ciphertext must be base64-encoded: %w", err)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", xerrors.Errorf("aws session error: %w", err)
+	}
+
+	// The ciphertext has the ID of the KMS key
+	out, err := kms.New(sess).DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil {
+		return "", xerrors.Errorf("aws kms error: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(out.Plaintext), nil
+}
@@ -0,0 +1,43 @@
+package credential
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"golang.org/x/xerrors"
+)
+
+// awsSecretsManager fetches the secret from AWS Secrets Manager with the default credential chain.
+// The path is the name or ARN of the secret. The region in the ARN takes precedence over AWS_REGION.
+func awsSecretsManager(ctx context.Context, path, key string) (string, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", xerrors.Errorf("aws session error: %w", err)
+	}
+
+	cfg := aws.NewConfig()
+	if strings.HasPrefix(path, "arn:") {
+		a, err := arn.Parse(path)
+		if err != nil {
+			return "", xerrors.Errorf("invalid ARN: %w", err)
+		}
+		cfg = cfg.WithRegion(a.Region)
+	}
+
+	out, err := secretsmanager.New(sess, cfg).GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(path),
+	})
+	if err != nil {
+		return "", xerrors.Errorf("aws secrets manager error: %w", err)
+	}
+	if out.SecretString == nil {
+		return "", xerrors.New("binary secrets are not supported")
+	}
+	return field(*out.SecretString, key)
+}
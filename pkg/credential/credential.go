@@ -0,0 +1,108 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// resolveTimeout is the timeout to fetch a credential from a secret manager
+const resolveTimeout = 30 * time.Second
+
+// provider fetches the secret stored at the path.
+// The key selects the field if the secret has several fields.
+type provider func(ctx context.Context, path, key string) (string, error)
+
+var providers = map[string]provider{
+	"vault":    vault,
+	"awssm":    awsSecretsManager,
+	"keychain": keychain,
+	"awskms":   awsKMS,
+}
+
+// Resolve returns the credential referenced by the given value.
+// The value is returned as it is if it is not a reference.
+//
+//	vault://secret/data/trivy#token                                    => HashiCorp Vault
+//	awssm://arn:aws:secretsmanager:us-east-1:123456789012:secret:trivy => AWS Secrets Manager
+//	keychain://service/account                                         => macOS Keychain, Secret Service on Linux
+//	awskms://AQIDAHh...                                                => AWS KMS, decrypting the data key
+//
+// A key after "#" selects the field of a secret stored as JSON.
+func Resolve(value string) (string, error) {
+	scheme, ref, ok := parse(value)
+	if !ok {
+		return value, nil
+	}
+
+	path, key := ref, ""
+	if i := strings.LastIndex(ref, "#"); i != -1 {
+		path, key = ref[:i], ref[i+1:]
+	}
+	if path == "" {
+		return "", xerrors.Errorf("empty secret path: %s", value)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	log.Logger.Debugf("Fetching the credential from %s://%s", scheme, path)
+	secret, err := providers[scheme](ctx, path, key)
+	if err != nil {
+		return "", xerrors.Errorf("unable to fetch %s://%s: %w", scheme, path, err)
+	}
+	return secret, nil
+}
+
+// ResolveAll resolves the given values in place
+func ResolveAll(values ...*string) error {
+	for _, v := range values {
+		resolved, err := Resolve(*v)
+		if err != nil {
+			return err
+		}
+		*v = resolved
+	}
+	return nil
+}
+
+func parse(value string) (string, string, bool) {
+	scheme, ref, ok := strings.Cut(value, "://")
+	if !ok {
+		return "", "", false
+	}
+	if _, ok = providers[scheme]; !ok {
+		return "", "", false
+	}
+	return scheme, ref, true
+}
+
+// field extracts the field from the secret stored as a JSON object.
+// The secret is returned as it is if no key is given.
+func field(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", xerrors.Errorf("the secret must be a JSON object to select %q: %w", key, err)
+	}
+	return lookup(fields, key)
+}
+
+func lookup(fields map[string]interface{}, key string) (string, error) {
+	v, ok := fields[key]
+	if !ok {
+		return "", xerrors.Errorf("no such key in the secret: %s", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", xerrors.Errorf("the value of %q must be a string", key)
+	}
+	return s, nil
+}
@@ -0,0 +1,201 @@
+package credential
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/trivy":
+			// KV version 2
+			_, _ = w.Write([]byte(`{"data": {"data": {"username": "trivy", "password": "p@ss"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/token":
+			// KV version 1
+			_, _ = w.Write([]byte(`{"data": {"token": "s3cr3t"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	t.Setenv("VAULT_ADDR", ts.URL)
+	t.Setenv("VAULT_TOKEN", "s.token")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "plain value",
+			value: "s3cr3t",
+			want:  "s3cr3t",
+		},
+		{
+			name:  "empty value",
+			value: "",
+			want:  "",
+		},
+		{
+			name:  "redis URL",
+			value: "redis://:p@ss@localhost:6379",
+			want:  "redis://:p@ss@localhost:6379",
+		},
+		{
+			name:  "vault kv v2",
+			value: "vault://secret/data/trivy#password",
+			want:  "p@ss",
+		},
+		{
+			name:  "vault kv v1 with a single field",
+			value: "vault://kv/token",
+			want:  "s3cr3t",
+		},
+		{
+			name:    "vault with several fields",
+			value:   "vault://secret/data/trivy",
+			wantErr: "the secret has 2 fields",
+		},
+		{
+			name:    "vault missing key",
+			value:   "vault://secret/data/trivy#token",
+			wantErr: "no such key in the secret: token",
+		},
+		{
+			name:    "vault not found",
+			value:   "vault://secret/data/unknown#token",
+			wantErr: "vault returned 404 Not Found",
+		},
+		{
+			name:    "empty path",
+			value:   "vault://#token",
+			wantErr: "empty secret path",
+		},
+		{
+			name:    "invalid keychain reference",
+			value:   "keychain://trivy",
+			wantErr: "the keychain reference must be",
+		},
+		{
+			name:    "invalid kms ciphertext",
+			value:   "awskms://not-base64!",
+			wantErr: "the ciphertext must be base64-encoded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolve_VaultTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"token": "s3cr3t"}}`))
+	}))
+	defer ts.Close()
+
+	cacert := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(cacert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0600))
+
+	t.Setenv("VAULT_ADDR", ts.URL)
+	t.Setenv("VAULT_TOKEN", "s.token")
+
+	_, err := Resolve("vault://kv/token")
+	assert.ErrorContains(t, err, "certificate signed by unknown authority")
+
+	t.Setenv("VAULT_CACERT", cacert)
+	got, err := Resolve("vault://kv/token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", got)
+
+	t.Setenv("VAULT_CACERT", "")
+	t.Setenv("VAULT_SKIP_VERIFY", "true")
+	got, err = Resolve("vault://kv/token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", got)
+}
+
+func TestResolve_Keychain(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("secret-tool is used only on Linux")
+	}
+
+	// Fake secret-tool printing the attributes
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho '{\"args\": \"'\"$*\"'\"}'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0700))
+	t.Setenv("PATH", dir)
+
+	got, err := Resolve("keychain://trivy/registry.example.com#args")
+	require.NoError(t, err)
+	assert.Equal(t, "lookup service trivy account registry.example.com", got)
+}
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		key     string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "no key",
+			secret: "s3cr3t",
+			want:   "s3cr3t",
+		},
+		{
+			name:   "JSON field",
+			secret: `{"username": "trivy", "password": "p@ss"}`,
+			key:    "password",
+			want:   "p@ss",
+		},
+		{
+			name:    "not JSON",
+			secret:  "s3cr3t",
+			key:     "password",
+			wantErr: "the secret must be a JSON object",
+		},
+		{
+			name:    "not string",
+			secret:  `{"port": 6379}`,
+			key:     "port",
+			wantErr: `the value of "port" must be a string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := field(tt.secret, tt.key)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// keychain fetches the password from the OS keychain.
+// The path is "<service>/<account>", which maps to the service and account of macOS Keychain items
+// and to the "service" and "account" attributes of Secret Service items on Linux.
+func keychain(ctx context.Context, path, key string) (string, error) {
+	service, account, ok := strings.Cut(path, "/")
+	if !ok || service == "" || account == "" {
+		return "", xerrors.Errorf("the keychain reference must be 'keychain://<service>/<account>': %s", path)
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", xerrors.Errorf("keychain is not supported on %s", runtime.GOOS)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", xerrors.Errorf("%s error: %w: %s", cmd.Args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return field(strings.TrimSuffix(string(out), "\n"), key)
+}
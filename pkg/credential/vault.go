@@ -0,0 +1,114 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+// vault fetches the secret from HashiCorp Vault with the same environment variables as the vault CLI.
+// Both KV version 1 and 2 are supported, e.g. "secret/data/trivy" for version 2.
+func vault(ctx context.Context, path, key string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(addr, "/"), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", xerrors.Errorf("vault request error: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	client, err := vaultClient()
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", xerrors.Errorf("vault request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("vault returned %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", xerrors.Errorf("vault response decode error: %w", err)
+	}
+
+	fields := secret.Data
+	// KV version 2 nests the fields with the metadata
+	if data, ok := fields["data"].(map[string]interface{}); ok {
+		if _, ok = fields["metadata"]; ok {
+			fields = data
+		}
+	}
+
+	if key == "" {
+		if len(fields) != 1 {
+			return "", xerrors.Errorf("the secret has %d fields, specify the key with '#'", len(fields))
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+	return lookup(fields, key)
+}
+
+// vaultClient returns the client trusting the CA bundle and VAULT_CACERT, or skipping the verification with
+// VAULT_SKIP_VERIFY as the vault CLI does
+func vaultClient() (*http.Client, error) {
+	insecure, _ := strconv.ParseBool(os.Getenv("VAULT_SKIP_VERIFY")) // nolint: errcheck
+	t := utils.HTTPTransport(insecure)
+	if cacert := os.Getenv("VAULT_CACERT"); cacert != "" {
+		pool, err := utils.LoadCertPool([]string{cacert})
+		if err != nil {
+			return nil, xerrors.Errorf("VAULT_CACERT error: %w", err)
+		}
+		t.TLSClientConfig = utils.TLSClientConfig(t.TLSClientConfig, pool, insecure)
+	}
+	return &http.Client{
+		Timeout:   resolveTimeout,
+		Transport: t,
+	}, nil
+}
+
+// vaultToken returns the token from VAULT_TOKEN or the file written by "vault login"
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", xerrors.Errorf("unable to get the home directory: %w", err)
+	}
+	b, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return "", xerrors.New("VAULT_TOKEN must be set or you must log in with 'vault login'")
+	}
+	return strings.TrimSpace(string(b)), nil
+}
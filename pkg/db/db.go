@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"time"
 
@@ -30,6 +31,7 @@ type options struct {
 	artifact     *oci.Artifact
 	clock        clock.Clock
 	dbRepository string
+	rootCAs      *x509.CertPool
 }
 
 // Option is a functional option
@@ -49,6 +51,13 @@ func WithDBRepository(dbRepository string) Option {
 	}
 }
 
+// WithRootCAs takes the CA certificates to verify the DB repository
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(opts *options) {
+		opts.rootCAs = pool
+	}
+}
+
 // WithClock takes a clock
 func WithClock(clock clock.Clock) Option {
 	return func(opts *options) {
@@ -118,6 +127,15 @@ func (c *Client) NeedsUpdate(cliVersion string, skip bool) (bool, error) {
 	return !c.isNewDB(meta), nil
 }
 
+// staleGracePeriod is the time for the DB to be updated after its next update, as the server checks the updates hourly
+const staleGracePeriod = time.Hour
+
+// IsStale returns true if the DB has not been updated for a while after its next update, e.g. due to the network errors
+// on the server
+func IsStale(meta metadata.Metadata, now time.Time) bool {
+	return !meta.NextUpdate.IsZero() && now.After(meta.NextUpdate.Add(staleGracePeriod))
+}
+
 func (c *Client) validate(meta metadata.Metadata) error {
 	if db.SchemaVersion != meta.Version {
 		log.Logger.Error("The local DB has an old schema version which is not supported by the current version of Trivy CLI. DB needs to be updated.")
@@ -183,7 +201,7 @@ func (c *Client) updateDownloadedAt(dst string) error {
 func (c *Client) populateOCIArtifact() error {
 	if c.artifact == nil {
 		repo := fmt.Sprintf("%s:%d", c.dbRepository, db.SchemaVersion)
-		art, err := oci.NewArtifact(repo, dbMediaType, c.quiet)
+		art, err := oci.NewArtifact(repo, dbMediaType, c.quiet, oci.WithRootCAs(c.rootCAs))
 		if err != nil {
 			return xerrors.Errorf("OCI artifact error: %w", err)
 		}
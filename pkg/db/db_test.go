@@ -223,3 +223,37 @@ func TestClient_Download(t *testing.T) {
 		})
 	}
 }
+
+func TestIsStale(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		nextUpdate time.Time
+		want       bool
+	}{
+		{
+			name:       "before the next update",
+			nextUpdate: now.Add(3 * time.Hour),
+			want:       false,
+		},
+		{
+			name:       "within the grace period",
+			nextUpdate: now.Add(-30 * time.Minute),
+			want:       false,
+		},
+		{
+			name:       "not updated after the grace period",
+			nextUpdate: now.Add(-2 * time.Hour),
+			want:       true,
+		},
+		{
+			name: "no next update",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, db.IsStale(metadata.Metadata{NextUpdate: tt.nextUpdate}, now))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package compare
 
 import (
+	"regexp"
 	"strings"
 
 	"golang.org/x/xerrors"
@@ -76,3 +77,36 @@ func (v GenericComparer) matchVersion(currentVersion, constraint string) (bool,
 
 	return c.Check(ver), nil
 }
+
+// versionPattern extracts the versions from the ranges, e.g. "1.2.3" from ">=1.2.3, <2"
+var versionPattern = regexp.MustCompile(`[0-9]+(\.[0-9]+)*`)
+
+// anyVersion is the range without any constraint
+const anyVersion = "*"
+
+// IsRangeVulnerable checks if any version satisfying the range is vulnerable to the advisory.
+// The candidates are the lower bounds of the range and of the vulnerable and patched versions in the advisory,
+// which includes the lowest version in the intersection of the range and the vulnerable versions
+// unless they are open at the lower end, e.g. ">1.2.3". It is best-effort as the released versions are unknown.
+func IsRangeVulnerable(constraint string, advisory dbTypes.Advisory, match matchVersion) bool {
+	ranges := append([]string{constraint, "0"}, advisory.VulnerableVersions...)
+	ranges = append(ranges, advisory.PatchedVersions...)
+	ranges = append(ranges, advisory.UnaffectedVersions...)
+
+	for _, candidate := range versionPattern.FindAllString(strings.Join(ranges, " "), -1) {
+		// Partial versions such as "^4" are completed, e.g. "4.0.0"
+		for strings.Count(candidate, ".") < 2 {
+			candidate += ".0"
+		}
+		if constraint != anyVersion {
+			// The candidates not parsed as versions are skipped without warnings
+			if ok, err := match(candidate, constraint); err != nil || !ok {
+				continue
+			}
+		}
+		if IsVulnerable(candidate, advisory, match) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package conan
+
+import (
+	"regexp"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/go-version/pkg/version"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/detector/library/compare"
+)
+
+// letterPattern matches the letter releases of OpenSSL, e.g. "1.1.1n"
+var letterPattern = regexp.MustCompile(`(\d+\.\d+\.\d+)([a-z]+)\b`)
+
+// Comparer represents a comparer for Conan
+type Comparer struct{}
+
+// IsVulnerable checks if the package version is vulnerable to the advisory.
+func (n Comparer) IsVulnerable(ver string, advisory dbTypes.Advisory) bool {
+	return compare.IsVulnerable(ver, advisory, n.matchVersion)
+}
+
+// matchVersion checks if the package version satisfies the given constraint.
+func (n Comparer) matchVersion(currentVersion, constraint string) (bool, error) {
+	v, err := version.Parse(normalize(currentVersion))
+	if err != nil {
+		return false, xerrors.Errorf("conan version error (%s): %s", currentVersion, err)
+	}
+
+	c, err := version.NewConstraints(normalize(constraint))
+	if err != nil {
+		return false, xerrors.Errorf("conan constraint error (%s): %s", constraint, err)
+	}
+
+	return c.Check(v), nil
+}
+
+// normalize replaces the letters of the releases with a number, e.g. "1.1.1n" with "1.1.1.14",
+// as they are newer than the release without a letter rather than pre-releases.
+func normalize(s string) string {
+	return letterPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := letterPattern.FindStringSubmatch(m)
+		n := 0
+		for _, r := range sub[2] {
+			n = n*26 + int(r-'a'+1)
+		}
+		return sub[1] + "." + strconv.Itoa(n)
+	})
+}
@@ -0,0 +1,91 @@
+package conan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/detector/library/compare/conan"
+)
+
+func TestConanComparer_IsVulnerable(t *testing.T) {
+	type args struct {
+		currentVersion string
+		advisory       dbTypes.Advisory
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "happy path",
+			args: args{
+				currentVersion: "1.2.11",
+				advisory: dbTypes.Advisory{
+					VulnerableVersions: []string{"<1.2.12"},
+					PatchedVersions:    []string{"1.2.12"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "patched",
+			args: args{
+				currentVersion: "1.2.12",
+				advisory: dbTypes.Advisory{
+					VulnerableVersions: []string{"<1.2.12"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "letter release",
+			args: args{
+				currentVersion: "1.1.1n",
+				advisory: dbTypes.Advisory{
+					VulnerableVersions: []string{">=1.1.1, <1.1.1o"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "letter release newer than the release without a letter",
+			args: args{
+				currentVersion: "1.1.1n",
+				advisory: dbTypes.Advisory{
+					VulnerableVersions: []string{"<1.1.1"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "letter release patched",
+			args: args{
+				currentVersion: "1.1.1o",
+				advisory: dbTypes.Advisory{
+					VulnerableVersions: []string{">=1.1.1, <1.1.1o", ">=3.0.0, <3.0.4"},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "invalid version",
+			args: args{
+				currentVersion: "1.2..4",
+				advisory: dbTypes.Advisory{
+					VulnerableVersions: []string{"<1.0.0"},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conan.Comparer{}
+			got := c.IsVulnerable(tt.args.currentVersion, tt.args.advisory)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -16,6 +16,16 @@ func (n Comparer) IsVulnerable(ver string, advisory dbTypes.Advisory) bool {
 	return compare.IsVulnerable(ver, advisory, n.matchVersion)
 }
 
+// RangeComparer represents a comparer for the ranges of the unpinned packages
+type RangeComparer struct {
+	Comparer
+}
+
+// IsVulnerable checks if any version satisfying the range is vulnerable to the advisory.
+func (n RangeComparer) IsVulnerable(constraint string, advisory dbTypes.Advisory) bool {
+	return compare.IsRangeVulnerable(constraint, advisory, n.matchVersion)
+}
+
 // matchVersion checks if the package version satisfies the given constraint.
 func (n Comparer) matchVersion(currentVersion, constraint string) (bool, error) {
 	v, err := npm.NewVersion(currentVersion)
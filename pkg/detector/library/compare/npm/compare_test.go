@@ -136,3 +136,53 @@ func TestNpmComparer_IsVulnerable(t *testing.T) {
 		})
 	}
 }
+
+func TestNpmRangeComparer_IsVulnerable(t *testing.T) {
+	advisory := dbTypes.Advisory{
+		VulnerableVersions: []string{">=4.0.0, <4.17.21"},
+		PatchedVersions:    []string{">=4.17.21"},
+	}
+	tests := []struct {
+		name       string
+		constraint string
+		want       bool
+	}{
+		{
+			name:       "the range includes vulnerable versions",
+			constraint: "^4.17.0",
+			want:       true,
+		},
+		{
+			name:       "the vulnerable versions start above the lower bound of the range",
+			constraint: ">=3.0.0 <5",
+			want:       true,
+		},
+		{
+			name:       "the range includes only patched versions",
+			constraint: "^4.17.21",
+			want:       false,
+		},
+		{
+			name:       "the range is below the vulnerable versions",
+			constraint: "~3.10.1",
+			want:       false,
+		},
+		{
+			name:       "any version",
+			constraint: "*",
+			want:       true,
+		},
+		{
+			name:       "invalid range",
+			constraint: "next",
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := npm.RangeComparer{}
+			got := c.IsVulnerable(tt.constraint, advisory)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -114,3 +114,43 @@ func TestPep440Comparer_IsVulnerable(t *testing.T) {
 		})
 	}
 }
+
+func TestPep440RangeComparer_IsVulnerable(t *testing.T) {
+	advisory := dbTypes.Advisory{
+		VulnerableVersions: []string{">=2.0, <2.31.0"},
+		PatchedVersions:    []string{">=2.31.0"},
+	}
+	tests := []struct {
+		name       string
+		constraint string
+		want       bool
+	}{
+		{
+			name:       "the range includes vulnerable versions",
+			constraint: ">=2.20,<3",
+			want:       true,
+		},
+		{
+			name:       "compatible release",
+			constraint: "~=2.28",
+			want:       true,
+		},
+		{
+			name:       "the range includes only patched versions",
+			constraint: ">=2.31.0",
+			want:       false,
+		},
+		{
+			name:       "any version",
+			constraint: "*",
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := pep440.RangeComparer{}
+			got := c.IsVulnerable(tt.constraint, advisory)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -13,10 +13,14 @@ import (
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/vulnerability"
 	"github.com/aquasecurity/trivy/pkg/detector/library/compare"
+	"github.com/aquasecurity/trivy/pkg/detector/library/compare/conan"
 	"github.com/aquasecurity/trivy/pkg/detector/library/compare/npm"
 	"github.com/aquasecurity/trivy/pkg/detector/library/compare/pep440"
 	"github.com/aquasecurity/trivy/pkg/detector/library/compare/rubygems"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
 	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/unpinned"
 )
 
 // NewDriver returns a driver according to the library type
@@ -40,7 +44,7 @@ func NewDriver(libType string) (Driver, error) {
 	case ftypes.Jar, ftypes.Pom:
 		ecosystem = vulnerability.Maven
 		comparer = maven.Comparer{}
-	case ftypes.Npm, ftypes.Yarn, ftypes.NodePkg, ftypes.JavaScript:
+	case ftypes.Npm, ftypes.Yarn, nodejs.Pnpm, ftypes.NodePkg, ftypes.JavaScript:
 		ecosystem = vulnerability.Npm
 		comparer = npm.Comparer{}
 	case ftypes.NuGet:
@@ -49,6 +53,15 @@ func NewDriver(libType string) (Driver, error) {
 	case ftypes.Pipenv, ftypes.Poetry, ftypes.Pip, ftypes.PythonPkg:
 		ecosystem = vulnerability.Pip
 		comparer = pep440.Comparer{}
+	case fingerprint.StaticLibrary:
+		ecosystem = vulnerability.Conan
+		comparer = conan.Comparer{}
+	case unpinned.Npm:
+		ecosystem = vulnerability.Npm
+		comparer = npm.RangeComparer{}
+	case unpinned.Pip:
+		ecosystem = vulnerability.Pip
+		comparer = pep440.RangeComparer{}
 	default:
 		return Driver{}, xerrors.Errorf("unsupported type %s", libType)
 	}
@@ -56,6 +69,7 @@ func NewDriver(libType string) (Driver, error) {
 		ecosystem: ecosystem,
 		comparer:  comparer,
 		dbc:       db.Config{},
+		unpinned:  unpinned.IsUnpinned(libType),
 	}, nil
 }
 
@@ -64,6 +78,9 @@ type Driver struct {
 	ecosystem dbTypes.Ecosystem
 	comparer  compare.Comparer
 	dbc       db.Config
+
+	// unpinned is true if the versions are the ranges in the manifests
+	unpinned bool
 }
 
 // Type returns the driver ecosystem
@@ -95,6 +112,7 @@ func (d *Driver) DetectVulnerabilities(pkgName, pkgVer string) ([]types.Detected
 			InstalledVersion: pkgVer,
 			FixedVersion:     createFixedVersions(adv),
 			DataSource:       adv.DataSource,
+			Unpinned:         d.unpinned,
 		}
 		vulns = append(vulns, vuln)
 	}
@@ -12,7 +12,9 @@ import (
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/vulnerability"
 	"github.com/aquasecurity/trivy/pkg/dbtest"
 	"github.com/aquasecurity/trivy/pkg/detector/library"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
 	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/unpinned"
 )
 
 func TestDriver_Detect(t *testing.T) {
@@ -113,6 +115,50 @@ func TestDriver_Detect(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "unpinned range",
+			fixtures: []string{"testdata/fixtures/npm.yaml"},
+			libType:  unpinned.Npm,
+			args: args{
+				pkgName: "lodash",
+				pkgVer:  "^4.17.0",
+			},
+			want: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2021-23337",
+					PkgName:          "lodash",
+					InstalledVersion: "^4.17.0",
+					FixedVersion:     "4.17.21",
+					Unpinned:         true,
+				},
+			},
+		},
+		{
+			name:     "unpinned range without vulnerable versions",
+			fixtures: []string{"testdata/fixtures/npm.yaml"},
+			libType:  unpinned.Npm,
+			args: args{
+				pkgName: "lodash",
+				pkgVer:  "^4.17.21",
+			},
+		},
+		{
+			name:     "statically linked library",
+			fixtures: []string{"testdata/fixtures/conan.yaml"},
+			libType:  fingerprint.StaticLibrary,
+			args: args{
+				pkgName: "openssl",
+				pkgVer:  "1.1.1n",
+			},
+			want: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2022-1292",
+					PkgName:          "openssl",
+					InstalledVersion: "1.1.1n",
+					FixedVersion:     "1.1.1o, 3.0.3",
+				},
+			},
+		},
 		{
 			name:     "no vulnerability",
 			fixtures: []string{"testdata/fixtures/php.yaml"},
@@ -44,6 +44,9 @@ var (
 		"3.15": time.Date(2023, 11, 1, 23, 59, 59, 0, time.UTC),
 		"edge": time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
+
+	// edge snapshots have a pre-release suffix in /etc/alpine-release, e.g. "3.16_alpha20220328"
+	edgeSuffixes = []string{"_alpha", "_beta", "_rc", "_pre"}
 )
 
 type options struct {
@@ -82,9 +85,7 @@ func NewScanner(opts ...option) *Scanner {
 // Detect vulnerabilities in package using Alpine scanner
 func (s *Scanner) Detect(osVer string, repo *ftypes.Repository, pkgs []ftypes.Package) ([]types.DetectedVulnerability, error) {
 	log.Logger.Info("Detecting Alpine vulnerabilities...")
-	if strings.Count(osVer, ".") > 1 {
-		osVer = osVer[:strings.LastIndex(osVer, ".")]
-	}
+	osVer = normalizeOSVersion(osVer)
 	repoRelease := s.repoRelease(repo)
 
 	log.Logger.Debugf("alpine: os version: %s", osVer)
@@ -167,9 +168,7 @@ func (s *Scanner) isVulnerable(installedVersion version.Version, adv dbTypes.Adv
 
 // IsSupportedVersion checks the OSFamily can be scanned using Alpine scanner
 func (s *Scanner) IsSupportedVersion(osFamily, osVer string) bool {
-	if strings.Count(osVer, ".") > 1 {
-		osVer = osVer[:strings.LastIndex(osVer, ".")]
-	}
+	osVer = normalizeOSVersion(osVer)
 
 	eol, ok := eolDates[osVer]
 	if !ok {
@@ -180,6 +179,19 @@ func (s *Scanner) IsSupportedVersion(osFamily, osVer string) bool {
 	return s.clock.Now().Before(eol)
 }
 
+// normalizeOSVersion trims the patch version and maps edge snapshots to "edge"
+func normalizeOSVersion(osVer string) string {
+	for _, suffix := range edgeSuffixes {
+		if strings.Contains(osVer, suffix) {
+			return "edge"
+		}
+	}
+	if strings.Count(osVer, ".") > 1 {
+		osVer = osVer[:strings.LastIndex(osVer, ".")]
+	}
+	return osVer
+}
+
 func (s *Scanner) repoRelease(repo *ftypes.Repository) string {
 	if repo == nil {
 		return ""
@@ -46,8 +46,14 @@ var (
 		fos.Oracle:       oracle.NewScanner(),
 		fos.OpenSUSELeap: suse.NewScanner(suse.OpenSUSE),
 		fos.SLES:         suse.NewScanner(suse.SUSEEnterpriseLinux),
+		suse.SLEMicro:    suse.NewScanner(suse.SUSEEnterpriseLinuxMicro),
 		fos.Photon:       photon.NewScanner(),
 	}
+
+	// esmDrivers are used instead of drivers when the scanned environment is entitled to extended security updates
+	esmDrivers = map[string]Driver{
+		fos.Ubuntu: ubuntu.NewScanner(ubuntu.WithESM(true)),
+	}
 )
 
 // RegisterDriver is defined for extensibility and not supposed to be used in Trivy.
@@ -57,7 +63,7 @@ func RegisterDriver(name string, driver Driver) {
 
 // Operation defines operation of OSpkg scan
 type Operation interface {
-	Detect(string, string, string, time.Time, []ftypes.Package) ([]types.DetectedVulnerability, bool, error)
+	Detect(string, string, string, *ftypes.Repository, time.Time, []ftypes.Package, bool) ([]types.DetectedVulnerability, bool, error)
 }
 
 // Driver defines operations for OS package scan
@@ -70,8 +76,8 @@ type Driver interface {
 type Detector struct{}
 
 // Detect detects the vulnerabilities
-func (d Detector) Detect(_, osFamily, osName string, repo *ftypes.Repository, _ time.Time, pkgs []ftypes.Package) ([]types.DetectedVulnerability, bool, error) {
-	driver, err := newDriver(osFamily)
+func (d Detector) Detect(_, osFamily, osName string, repo *ftypes.Repository, _ time.Time, pkgs []ftypes.Package, esm bool) ([]types.DetectedVulnerability, bool, error) {
+	driver, err := newDriver(osFamily, esm)
 	if err != nil {
 		return nil, false, ErrUnsupportedOS
 	}
@@ -86,7 +92,10 @@ func (d Detector) Detect(_, osFamily, osName string, repo *ftypes.Repository, _
 	return vulns, eosl, nil
 }
 
-func newDriver(osFamily string) (Driver, error) {
+func newDriver(osFamily string, esm bool) (Driver, error) {
+	if driver, ok := esmDrivers[osFamily]; ok && esm {
+		return driver, nil
+	}
 	if driver, ok := drivers[osFamily]; ok {
 		return driver, nil
 	}
@@ -22,6 +22,8 @@ import (
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
+const defaultArch = "x86_64"
+
 var (
 	defaultContentSets = map[string][]string{
 		"6": {
@@ -33,8 +35,12 @@ var (
 			"rhel-7-server-extras-rpms",
 		},
 		"8": {
-			"rhel-8-for-x86_64-baseos-rpms",
-			"rhel-8-for-x86_64-appstream-rpms",
+			"rhel-8-for-%s-baseos-rpms",
+			"rhel-8-for-%s-appstream-rpms",
+		},
+		"9": {
+			"rhel-9-for-%s-baseos-rpms",
+			"rhel-9-for-%s-appstream-rpms",
 		},
 	}
 	redhatEOLDates = map[string]time.Time{
@@ -44,6 +50,7 @@ var (
 		// N/A
 		"7": time.Date(3000, 1, 1, 23, 59, 59, 0, time.UTC),
 		"8": time.Date(3000, 1, 1, 23, 59, 59, 0, time.UTC),
+		"9": time.Date(3000, 1, 1, 23, 59, 59, 0, time.UTC),
 	}
 	centosEOLDates = map[string]time.Time{
 		"3": time.Date(2010, 10, 31, 23, 59, 59, 0, time.UTC),
@@ -56,6 +63,13 @@ var (
 	excludedVendorsSuffix = []string{
 		".remi",
 	}
+
+	// The advisories of Red Hat don't contain the data source, unlike the other OSes
+	dataSource = dbTypes.DataSource{
+		ID:   vulnerability.RedHatOVAL,
+		Name: "Red Hat OVAL v2",
+		URL:  "https://www.redhat.com/security/data/oval/v2/",
+	}
 )
 
 type options struct {
@@ -120,16 +134,21 @@ func (s *Scanner) detect(osVer string, pkg ftypes.Package) ([]types.DetectedVuln
 	// For Red Hat OVAL v2 containing only binary package names
 	pkgName := addModularNamespace(pkg.Name, pkg.Modularitylabel)
 
-	var contentSets []string
-	var nvr string
-	if pkg.BuildInfo == nil {
-		contentSets = defaultContentSets[osVer]
-	} else {
+	// Content sets and NVR are taken from the content manifests and Dockerfile labels in UBI-based images
+	var contentSets, nvrs []string
+	if pkg.BuildInfo != nil {
 		contentSets = pkg.BuildInfo.ContentSets
-		nvr = fmt.Sprintf("%s-%s", pkg.BuildInfo.Nvr, pkg.BuildInfo.Arch)
+		if pkg.BuildInfo.Nvr != "" {
+			nvrs = append(nvrs, fmt.Sprintf("%s-%s", pkg.BuildInfo.Nvr, pkg.BuildInfo.Arch))
+		}
+	}
+
+	// The default repositories are used only when the image has no information to identify CPEs
+	if len(contentSets) == 0 && len(nvrs) == 0 {
+		contentSets = lookupDefaultContentSets(osVer, pkg.Arch)
 	}
 
-	advisories, err := s.vs.Get(pkgName, contentSets, []string{nvr})
+	advisories, err := s.vs.Get(pkgName, contentSets, nvrs)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to get Red Hat advisories: %w", err)
 	}
@@ -140,12 +159,14 @@ func (s *Scanner) detect(osVer string, pkg ftypes.Package) ([]types.DetectedVuln
 	uniqVulns := map[string]types.DetectedVulnerability{}
 	for _, adv := range advisories {
 		vulnID := adv.VulnerabilityID
+		source := dataSource
 		vuln := types.DetectedVulnerability{
 			VulnerabilityID:  vulnID,
 			PkgName:          pkg.Name,
 			InstalledVersion: utils.FormatVersion(pkg),
 			Layer:            pkg.Layer,
 			SeveritySource:   vulnerability.RedHat,
+			DataSource:       &source,
 			Vulnerability: dbTypes.Vulnerability{
 				Severity: adv.Severity.String(),
 			},
@@ -209,6 +230,21 @@ func (s *Scanner) IsSupportedVersion(osFamily, osVer string) bool {
 	return s.clock.Now().Before(eolDate)
 }
 
+func lookupDefaultContentSets(osVer, arch string) []string {
+	if arch == "" || arch == "noarch" {
+		arch = defaultArch
+	}
+
+	var contentSets []string
+	for _, cs := range defaultContentSets[osVer] {
+		if strings.Contains(cs, "%s") {
+			cs = fmt.Sprintf(cs, arch)
+		}
+		contentSets = append(contentSets, cs)
+	}
+	return contentSets
+}
+
 func isFromSupportedVendor(pkg ftypes.Package) bool {
 	for _, suffix := range excludedVendorsSuffix {
 		if strings.HasSuffix(pkg.Release, suffix) {
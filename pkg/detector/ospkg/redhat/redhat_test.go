@@ -69,6 +69,11 @@ func TestScanner_Detect(t *testing.T) {
 					PkgName:          "vim-minimal",
 					InstalledVersion: "2:7.4.160-5.el7",
 					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
 					Vulnerability: dbTypes.Vulnerability{
 						Severity: dbTypes.SeverityLow.String(),
 					},
@@ -83,6 +88,11 @@ func TestScanner_Detect(t *testing.T) {
 					InstalledVersion: "2:7.4.160-5.el7",
 					FixedVersion:     "2:7.4.160-6.el7_6",
 					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
 					Vulnerability: dbTypes.Vulnerability{
 						Severity: dbTypes.SeverityHigh.String(),
 					},
@@ -128,6 +138,11 @@ func TestScanner_Detect(t *testing.T) {
 					InstalledVersion: "3.36.0-7.1.el7_6",
 					FixedVersion:     "3.36.0-9.el7_6",
 					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
 					Vulnerability: dbTypes.Vulnerability{
 						Severity: dbTypes.SeverityMedium.String(),
 					},
@@ -142,6 +157,11 @@ func TestScanner_Detect(t *testing.T) {
 					InstalledVersion: "3.36.0-7.1.el7_6",
 					FixedVersion:     "3.53.1-17.el7_3",
 					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
 					Vulnerability: dbTypes.Vulnerability{
 						Severity: dbTypes.SeverityHigh.String(),
 					},
@@ -177,12 +197,78 @@ func TestScanner_Detect(t *testing.T) {
 					InstalledVersion: "2:7.4.160-5.el8",
 					FixedVersion:     "2:7.4.160-7.el8_7",
 					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
+					Vulnerability: dbTypes.Vulnerability{
+						Severity: dbTypes.SeverityMedium.String(),
+					},
+				},
+			},
+		},
+		{
+			name: "build info without content sets and NVR",
+			fixtures: []string{
+				"testdata/fixtures/redhat.yaml",
+				"testdata/fixtures/cpe.yaml",
+			},
+			args: args{
+				osVer: "8.3",
+				pkgs: []ftypes.Package{
+					{
+						Name:      "vim-minimal",
+						Version:   "7.4.160",
+						Release:   "5.el8",
+						Epoch:     2,
+						Arch:      "x86_64",
+						BuildInfo: &ftypes.BuildInfo{},
+					},
+				},
+			},
+			want: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2019-12735",
+					VendorIDs:        []string{"RHSA-2019:1619"},
+					PkgName:          "vim-minimal",
+					InstalledVersion: "2:7.4.160-5.el8",
+					FixedVersion:     "2:7.4.160-7.el8_7",
+					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
 					Vulnerability: dbTypes.Vulnerability{
 						Severity: dbTypes.SeverityMedium.String(),
 					},
 				},
 			},
 		},
+		{
+			name: "content sets from content manifests",
+			fixtures: []string{
+				"testdata/fixtures/redhat.yaml",
+				"testdata/fixtures/cpe.yaml",
+			},
+			args: args{
+				osVer: "8.3",
+				pkgs: []ftypes.Package{
+					{
+						Name:    "vim-minimal",
+						Version: "7.4.160",
+						Release: "5.el8",
+						Epoch:   2,
+						Arch:    "x86_64",
+						BuildInfo: &ftypes.BuildInfo{
+							ContentSets: []string{"3scale-amp-2-rpms-for-rhel-8-x86_64-debug-rpms"},
+						},
+					},
+				},
+			},
+			want: []types.DetectedVulnerability(nil),
+		},
 		{
 			name: "modular packages",
 			fixtures: []string{
@@ -219,6 +305,11 @@ func TestScanner_Detect(t *testing.T) {
 					InstalledVersion: "7.2.10-1.module_el8.2.0+313+b04d0a66",
 					FixedVersion:     "7.2.11-1.1.module+el8.0.0+4664+17bd8d65",
 					SeveritySource:   vulnerability.RedHat,
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.RedHatOVAL,
+						Name: "Red Hat OVAL v2",
+						URL:  "https://www.redhat.com/security/data/oval/v2/",
+					},
 					Vulnerability: dbTypes.Vulnerability{
 						Severity: dbTypes.SeverityCritical.String(),
 					},
@@ -328,6 +419,15 @@ func TestScanner_IsSupportedVersion(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "rhel 9",
+			now:  time.Date(2022, 5, 31, 23, 59, 59, 0, time.UTC),
+			args: args{
+				osFamily: "redhat",
+				osVer:    "9.0",
+			},
+			want: true,
+		},
 		{
 			name: "unknown",
 			now:  time.Date(2019, 5, 31, 23, 59, 59, 0, time.UTC),
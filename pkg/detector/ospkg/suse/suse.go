@@ -1,6 +1,7 @@
 package suse
 
 import (
+	"strings"
 	"time"
 
 	"golang.org/x/xerrors"
@@ -10,8 +11,10 @@ import (
 
 	fos "github.com/aquasecurity/fanal/analyzer/os"
 	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	susecvrf "github.com/aquasecurity/trivy-db/pkg/vulnsrc/suse-cvrf"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/osrelease"
 	"github.com/aquasecurity/trivy/pkg/scanner/utils"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
@@ -54,8 +57,25 @@ var (
 		"15.2": time.Date(2021, 11, 30, 23, 59, 59, 0, time.UTC),
 		"15.3": time.Date(2022, 11, 30, 23, 59, 59, 0, time.UTC),
 	}
+
+	sleMicroEolDates = map[string]time.Time{
+		// Source: https://www.suse.com/lifecycle/
+		"5.0": time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC),
+		"5.1": time.Date(2022, 10, 31, 23, 59, 59, 0, time.UTC),
+		"5.2": time.Date(2023, 4, 30, 23, 59, 59, 0, time.UTC),
+	}
+
+	// SLE Micro shares the code base and advisories with SUSE Linux Enterprise
+	sleMicroBaseVersions = map[string]string{
+		"5.0": "15.2",
+		"5.1": "15.3",
+		"5.2": "15.3",
+	}
 )
 
+// SLEMicro is the OS family of SUSE Linux Enterprise Micro, detected from os-release by the analyzer of osrelease
+const SLEMicro = osrelease.SLEMicro
+
 type options struct {
 	clock clock.Clock
 }
@@ -76,11 +96,14 @@ const (
 	SUSEEnterpriseLinux Type = iota
 	// OpenSUSE for open versions
 	OpenSUSE
+	// SUSEEnterpriseLinuxMicro is SUSE Linux Enterprise Micro
+	SUSEEnterpriseLinuxMicro
 )
 
-// Scanner implements the Alpine scanner
+// Scanner implements the SUSE scanner
 type Scanner struct {
-	vs susecvrf.VulnSrc
+	vs   susecvrf.VulnSrc
+	dist Type
 	*options
 }
 
@@ -95,14 +118,16 @@ func NewScanner(t Type, opts ...option) *Scanner {
 	}
 
 	switch t {
-	case SUSEEnterpriseLinux:
+	case SUSEEnterpriseLinux, SUSEEnterpriseLinuxMicro:
 		return &Scanner{
 			vs:      susecvrf.NewVulnSrc(susecvrf.SUSEEnterpriseLinux),
+			dist:    t,
 			options: o,
 		}
 	case OpenSUSE:
 		return &Scanner{
 			vs:      susecvrf.NewVulnSrc(susecvrf.OpenSUSE),
+			dist:    t,
 			options: o,
 		}
 	}
@@ -115,11 +140,14 @@ func (s *Scanner) Detect(osVer string, _ *ftypes.Repository, pkgs []ftypes.Packa
 	log.Logger.Debugf("SUSE: os version: %s", osVer)
 	log.Logger.Debugf("SUSE: the number of packages: %d", len(pkgs))
 
+	platformVersions := s.platformVersions(osVer)
+	log.Logger.Debugf("SUSE: platform versions: %s", platformVersions)
+
 	var vulns []types.DetectedVulnerability
 	for _, pkg := range pkgs {
-		advisories, err := s.vs.Get(osVer, pkg.SrcName)
+		advisories, err := s.getAdvisories(platformVersions, pkg.SrcName)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to get SUSE advisory: %w", err)
+			return nil, err
 		}
 
 		installed := utils.FormatVersion(pkg)
@@ -143,15 +171,61 @@ func (s *Scanner) Detect(osVer string, _ *ftypes.Repository, pkgs []ftypes.Packa
 	return vulns, nil
 }
 
+// platformVersions returns the versions of SUSE Linux Enterprise platforms where advisories should be looked up.
+// The first version takes precedence over the rest.
+func (s *Scanner) platformVersions(osVer string) []string {
+	switch s.dist {
+	case SUSEEnterpriseLinuxMicro:
+		baseVer, ok := sleMicroBaseVersions[osVer]
+		if !ok {
+			log.Logger.Warnf("Unknown SLE Micro version: %s", osVer)
+			return []string{osVer}
+		}
+		osVer = baseVer
+	case OpenSUSE:
+		return []string{osVer}
+	}
+
+	// Modules and extensions such as "SUSE Linux Enterprise Module for Python2 15" are not bound to
+	// a specific service pack, and their advisories are stored under the major version.
+	if major, _, found := strings.Cut(osVer, "."); found {
+		return []string{osVer, major}
+	}
+	return []string{osVer}
+}
+
+func (s *Scanner) getAdvisories(platformVersions []string, pkgName string) ([]dbTypes.Advisory, error) {
+	var advisories []dbTypes.Advisory
+	uniqIDs := map[string]struct{}{}
+	for _, ver := range platformVersions {
+		advs, err := s.vs.Get(ver, pkgName)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to get SUSE advisory: %w", err)
+		}
+		for _, adv := range advs {
+			// The advisory for the service pack is preferred over the module one
+			if _, ok := uniqIDs[adv.VulnerabilityID]; ok {
+				continue
+			}
+			uniqIDs[adv.VulnerabilityID] = struct{}{}
+			advisories = append(advisories, adv)
+		}
+	}
+	return advisories, nil
+}
+
 // IsSupportedVersion checks if OSFamily can be scanned using SUSE scanner
 func (s *Scanner) IsSupportedVersion(osFamily, osVer string) bool {
 	var eolDate time.Time
 	var ok bool
 
-	if osFamily == fos.SLES {
+	switch osFamily {
+	case fos.SLES:
 		eolDate, ok = slesEolDates[osVer]
-	} else if osFamily == fos.OpenSUSELeap {
+	case fos.OpenSUSELeap:
 		eolDate, ok = opensuseEolDates[osVer]
+	case SLEMicro:
+		eolDate, ok = sleMicroEolDates[osVer]
 	}
 
 	if !ok {
@@ -67,6 +67,87 @@ func TestScanner_Detect(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:         "happy path: modules and extensions",
+			fixtures:     []string{"testdata/fixtures/suse.yaml", "testdata/fixtures/data-source.yaml"},
+			distribution: suse.SUSEEnterpriseLinux,
+			args: args{
+				osVer: "15.3",
+				pkgs: []ftypes.Package{
+					{
+						Name:       "libopenssl1_1",
+						Version:    "1.1.1d",
+						Release:    "11.38.1",
+						SrcName:    "libopenssl1_1",
+						SrcVersion: "1.1.1d",
+						SrcRelease: "11.38.1",
+					},
+					{
+						Name:       "python",
+						Version:    "2.7.18",
+						Release:    "33.1.1",
+						SrcName:    "python",
+						SrcVersion: "2.7.18",
+						SrcRelease: "33.1.1",
+					},
+				},
+			},
+			want: []types.DetectedVulnerability{
+				{
+					PkgName:          "libopenssl1_1",
+					VulnerabilityID:  "SUSE-SU-2022:1262-1",
+					InstalledVersion: "1.1.1d-11.38.1",
+					FixedVersion:     "1.1.1d-11.43.1",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.SuseCVRF,
+						Name: "SUSE CVRF",
+						URL:  "https://ftp.suse.com/pub/projects/security/cvrf/",
+					},
+				},
+				{
+					PkgName:          "python",
+					VulnerabilityID:  "SUSE-SU-2022:1040-1",
+					InstalledVersion: "2.7.18-33.1.1",
+					FixedVersion:     "2.7.18-33.5.1",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.SuseCVRF,
+						Name: "SUSE CVRF",
+						URL:  "https://ftp.suse.com/pub/projects/security/cvrf/",
+					},
+				},
+			},
+		},
+		{
+			name:         "happy path: SLE Micro",
+			fixtures:     []string{"testdata/fixtures/suse.yaml", "testdata/fixtures/data-source.yaml"},
+			distribution: suse.SUSEEnterpriseLinuxMicro,
+			args: args{
+				osVer: "5.1",
+				pkgs: []ftypes.Package{
+					{
+						Name:       "libopenssl1_1",
+						Version:    "1.1.1d",
+						Release:    "11.38.1",
+						SrcName:    "libopenssl1_1",
+						SrcVersion: "1.1.1d",
+						SrcRelease: "11.38.1",
+					},
+				},
+			},
+			want: []types.DetectedVulnerability{
+				{
+					PkgName:          "libopenssl1_1",
+					VulnerabilityID:  "SUSE-SU-2022:1262-1",
+					InstalledVersion: "1.1.1d-11.38.1",
+					FixedVersion:     "1.1.1d-11.43.1",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.SuseCVRF,
+						Name: "SUSE CVRF",
+						URL:  "https://ftp.suse.com/pub/projects/security/cvrf/",
+					},
+				},
+			},
+		},
 		{
 			name:         "broken bucket",
 			fixtures:     []string{"testdata/fixtures/invalid.yaml", "testdata/fixtures/data-source.yaml"},
@@ -135,6 +216,16 @@ func TestScanner_IsSupportedVersion(t *testing.T) {
 			distribution: suse.SUSEEnterpriseLinux,
 			want:         false,
 		},
+		{
+			name: "sle-micro5.1",
+			now:  time.Date(2022, 5, 31, 23, 59, 59, 0, time.UTC),
+			args: args{
+				osFamily: "sle-micro",
+				osVer:    "5.1",
+			},
+			distribution: suse.SUSEEnterpriseLinuxMicro,
+			want:         true,
+		},
 		{
 			name: "unknown",
 			now:  time.Date(2019, 5, 2, 23, 59, 59, 0, time.UTC),
@@ -1,13 +1,17 @@
 package ubuntu
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	version "github.com/knqyf263/go-deb-version"
+	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
 	"k8s.io/utils/clock"
 
 	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/ubuntu"
 	"github.com/aquasecurity/trivy/pkg/log"
 	"github.com/aquasecurity/trivy/pkg/scanner/utils"
@@ -53,10 +57,24 @@ var (
 		"21.10": time.Date(2022, 7, 22, 23, 59, 59, 0, time.UTC),
 		"22.04": time.Date(2032, 4, 23, 23, 59, 59, 0, time.UTC),
 	}
+	// Extended Security Maintenance available with Ubuntu Pro
+	esmEOLDates = map[string]time.Time{
+		"14.04": time.Date(2024, 4, 25, 23, 59, 59, 0, time.UTC),
+		"16.04": time.Date(2026, 4, 23, 23, 59, 59, 0, time.UTC),
+	}
+)
+
+const (
+	// esmPlatformSuffix is appended to the OS version for advisories only available with ESM, e.g. "16.04-ESM"
+	esmPlatformSuffix = "-ESM"
+
+	// esmVersionSuffix is contained in versions of packages published in the ESM archives, e.g. "1.2-3ubuntu0.1+esm1"
+	esmVersionSuffix = "+esm"
 )
 
 type options struct {
 	clock clock.Clock
+	esm   bool
 }
 
 type option func(*options)
@@ -67,6 +85,13 @@ func WithClock(clock clock.Clock) option {
 	}
 }
 
+// WithESM specifies whether the scanned environment is entitled to ESM (Ubuntu Pro) updates
+func WithESM(esm bool) option {
+	return func(opts *options) {
+		opts.esm = esm
+	}
+}
+
 // Scanner implements the Alpine scanner
 type Scanner struct {
 	vs ubuntu.VulnSrc
@@ -96,7 +121,7 @@ func (s *Scanner) Detect(osVer string, _ *ftypes.Repository, pkgs []ftypes.Packa
 
 	var vulns []types.DetectedVulnerability
 	for _, pkg := range pkgs {
-		advisories, err := s.vs.Get(osVer, pkg.SrcName)
+		advisories, err := s.getAdvisories(osVer, pkg.SrcName)
 		if err != nil {
 			return nil, xerrors.Errorf("failed to get Ubuntu advisories: %w", err)
 		}
@@ -131,6 +156,10 @@ func (s *Scanner) Detect(osVer string, _ *ftypes.Repository, pkgs []ftypes.Packa
 			}
 
 			if installedVersion.LessThan(fixedVersion) {
+				// The fix published in the ESM archives is not available without the entitlement
+				if !s.esm && strings.Contains(adv.FixedVersion, esmVersionSuffix) {
+					vuln.FixedVersion = ""
+				}
 				vulns = append(vulns, vuln)
 			}
 		}
@@ -138,9 +167,45 @@ func (s *Scanner) Detect(osVer string, _ *ftypes.Repository, pkgs []ftypes.Packa
 	return vulns, nil
 }
 
+func (s *Scanner) getAdvisories(osVer, pkgName string) ([]dbTypes.Advisory, error) {
+	advisories, err := s.vs.Get(osVer, pkgName)
+	if err != nil {
+		return nil, err
+	}
+	if !s.esm {
+		return advisories, nil
+	}
+
+	esmAdvisories, err := s.vs.Get(osVer+esmPlatformSuffix, pkgName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ESM advisories take precedence as they may contain fixes unavailable in the standard archives
+	uniqAdvisories := map[string]dbTypes.Advisory{}
+	for _, adv := range advisories {
+		uniqAdvisories[adv.VulnerabilityID] = adv
+	}
+	for _, adv := range esmAdvisories {
+		if old, ok := uniqAdvisories[adv.VulnerabilityID]; ok && adv.FixedVersion == "" && old.FixedVersion != "" {
+			continue
+		}
+		uniqAdvisories[adv.VulnerabilityID] = adv
+	}
+
+	advisories = maps.Values(uniqAdvisories)
+	sort.Slice(advisories, func(i, j int) bool {
+		return advisories[i].VulnerabilityID < advisories[j].VulnerabilityID
+	})
+	return advisories, nil
+}
+
 // IsSupportedVersion checks is OSFamily can be scanned using Ubuntu scanner
 func (s *Scanner) IsSupportedVersion(osFamily, osVer string) bool {
 	eol, ok := eolDates[osVer]
+	if esmEOL, found := esmEOLDates[osVer]; s.esm && found {
+		eol, ok = esmEOL, true
+	}
 	if !ok {
 		log.Logger.Warnf("This OS version is not on the EOL list: %s %s", osFamily, osVer)
 		return false
@@ -26,6 +26,7 @@ func TestScanner_Detect(t *testing.T) {
 	tests := []struct {
 		name     string
 		args     args
+		esm      bool
 		fixtures []string
 		want     []types.DetectedVulnerability
 		wantErr  string
@@ -78,6 +79,85 @@ func TestScanner_Detect(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "ESM fixes without entitlement",
+			fixtures: []string{"testdata/fixtures/ubuntu.yaml", "testdata/fixtures/data-source.yaml"},
+			args: args{
+				osVer: "16.04",
+				pkgs: []ftypes.Package{
+					{
+						Name:       "libssl1.0.0",
+						Version:    "1.0.2g-1ubuntu4.20",
+						SrcName:    "openssl",
+						SrcVersion: "1.0.2g-1ubuntu4.20",
+					},
+				},
+			},
+			want: []types.DetectedVulnerability{
+				{
+					PkgName:          "libssl1.0.0",
+					VulnerabilityID:  "CVE-2021-3712",
+					InstalledVersion: "1.0.2g-1ubuntu4.20",
+					FixedVersion:     "",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.Ubuntu,
+						Name: "Ubuntu CVE Tracker",
+						URL:  "https://git.launchpad.net/ubuntu-cve-tracker",
+					},
+				},
+				{
+					PkgName:          "libssl1.0.0",
+					VulnerabilityID:  "CVE-2022-0778",
+					InstalledVersion: "1.0.2g-1ubuntu4.20",
+					FixedVersion:     "",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.Ubuntu,
+						Name: "Ubuntu CVE Tracker",
+						URL:  "https://git.launchpad.net/ubuntu-cve-tracker",
+					},
+				},
+			},
+		},
+		{
+			name:     "ESM fixes with entitlement",
+			fixtures: []string{"testdata/fixtures/ubuntu.yaml", "testdata/fixtures/data-source.yaml"},
+			args: args{
+				osVer: "16.04",
+				pkgs: []ftypes.Package{
+					{
+						Name:       "libssl1.0.0",
+						Version:    "1.0.2g-1ubuntu4.20",
+						SrcName:    "openssl",
+						SrcVersion: "1.0.2g-1ubuntu4.20",
+					},
+				},
+			},
+			esm: true,
+			want: []types.DetectedVulnerability{
+				{
+					PkgName:          "libssl1.0.0",
+					VulnerabilityID:  "CVE-2021-3712",
+					InstalledVersion: "1.0.2g-1ubuntu4.20",
+					FixedVersion:     "1.0.2g-1ubuntu4.20+esm1",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.Ubuntu,
+						Name: "Ubuntu CVE Tracker",
+						URL:  "https://git.launchpad.net/ubuntu-cve-tracker",
+					},
+				},
+				{
+					PkgName:          "libssl1.0.0",
+					VulnerabilityID:  "CVE-2022-0778",
+					InstalledVersion: "1.0.2g-1ubuntu4.20",
+					FixedVersion:     "1.0.2g-1ubuntu4.20+esm2",
+					DataSource: &dbTypes.DataSource{
+						ID:   vulnerability.Ubuntu,
+						Name: "Ubuntu CVE Tracker",
+						URL:  "https://git.launchpad.net/ubuntu-cve-tracker",
+					},
+				},
+			},
+		},
 		{
 			name:     "broken bucket",
 			fixtures: []string{"testdata/fixtures/invalid.yaml", "testdata/fixtures/data-source.yaml"},
@@ -100,7 +180,7 @@ func TestScanner_Detect(t *testing.T) {
 			_ = dbtest.InitDB(t, tt.fixtures)
 			defer db.Close()
 
-			s := ubuntu.NewScanner()
+			s := ubuntu.NewScanner(ubuntu.WithESM(tt.esm))
 			got, err := s.Detect(tt.args.osVer, nil, tt.args.pkgs)
 			if tt.wantErr != "" {
 				require.Error(t, err)
@@ -124,6 +204,7 @@ func TestScanner_IsSupportedVersion(t *testing.T) {
 	tests := []struct {
 		name string
 		now  time.Time
+		esm  bool
 		args args
 		want bool
 	}{
@@ -145,6 +226,25 @@ func TestScanner_IsSupportedVersion(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "ubuntu 16.04 without ESM",
+			now:  time.Date(2025, 4, 30, 23, 59, 59, 0, time.UTC),
+			args: args{
+				osFamily: "ubuntu",
+				osVer:    "16.04",
+			},
+			want: false,
+		},
+		{
+			name: "ubuntu 16.04 with ESM",
+			now:  time.Date(2025, 4, 30, 23, 59, 59, 0, time.UTC),
+			esm:  true,
+			args: args{
+				osFamily: "ubuntu",
+				osVer:    "16.04",
+			},
+			want: true,
+		},
 		{
 			name: "unknown",
 			now:  time.Date(2019, 5, 2, 23, 59, 59, 0, time.UTC),
@@ -157,7 +257,7 @@ func TestScanner_IsSupportedVersion(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := ubuntu.NewScanner(ubuntu.WithClock(fake.NewFakeClock(tt.now)))
+			s := ubuntu.NewScanner(ubuntu.WithClock(fake.NewFakeClock(tt.now)), ubuntu.WithESM(tt.esm))
 			got := s.IsSupportedVersion(tt.args.osFamily, tt.args.osVer)
 			assert.Equal(t, tt.want, got)
 		})
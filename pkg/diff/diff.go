@@ -0,0 +1,229 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// Report represents the differences between two scan reports
+type Report struct {
+	Base   string `json:",omitempty"`
+	Target string `json:",omitempty"`
+
+	// Added holds the findings which exist only in the target report
+	Added types.Results `json:",omitempty"`
+
+	// Removed holds the findings which exist only in the base report
+	Removed types.Results `json:",omitempty"`
+
+	// Changed holds the vulnerabilities whose installed version, fixed version or severity changed
+	Changed []ChangedVulnerability `json:",omitempty"`
+}
+
+// ChangedVulnerability represents a vulnerability detected in both reports with different attributes
+type ChangedVulnerability struct {
+	Target string
+	Class  types.ResultClass `json:",omitempty"`
+	Type   string            `json:",omitempty"`
+	Base   types.DetectedVulnerability
+	Head   types.DetectedVulnerability
+}
+
+// Empty returns whether there is no difference
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Compare returns the findings added, removed and changed in the target report compared to the base report.
+// Only findings with the given severities are compared. All severities are compared if none is given.
+func Compare(base, target types.Report, severities []dbTypes.Severity) Report {
+	baseResults := index(base.Results)
+	targetResults := index(target.Results)
+
+	r := Report{
+		Base:   base.ArtifactName,
+		Target: target.ArtifactName,
+	}
+	for _, key := range sortedKeys(baseResults, targetResults) {
+		b, t := baseResults[key], targetResults[key]
+
+		added, removed, changed := compareResult(b, t, severities)
+		if !empty(added) {
+			r.Added = append(r.Added, added)
+		}
+		if !empty(removed) {
+			r.Removed = append(r.Removed, removed)
+		}
+		r.Changed = append(r.Changed, changed...)
+	}
+	return r
+}
+
+func compareResult(base, target types.Result, severities []dbTypes.Severity) (types.Result, types.Result, []ChangedVulnerability) {
+	added := types.Result{Target: target.Target, Class: target.Class, Type: target.Type}
+	removed := types.Result{Target: base.Target, Class: base.Class, Type: base.Type}
+	var changed []ChangedVulnerability
+
+	// Vulnerabilities
+	baseVulns := map[string]types.DetectedVulnerability{}
+	for _, v := range base.Vulnerabilities {
+		if matchSeverity(v.Severity, severities) {
+			baseVulns[vulnKey(v)] = v
+		}
+	}
+	for _, v := range target.Vulnerabilities {
+		if !matchSeverity(v.Severity, severities) {
+			continue
+		}
+		key := vulnKey(v)
+		old, ok := baseVulns[key]
+		if !ok {
+			added.Vulnerabilities = append(added.Vulnerabilities, v)
+			continue
+		}
+		delete(baseVulns, key)
+
+		if old.InstalledVersion != v.InstalledVersion || old.FixedVersion != v.FixedVersion || old.Severity != v.Severity {
+			changed = append(changed, ChangedVulnerability{
+				Target: target.Target,
+				Class:  target.Class,
+				Type:   target.Type,
+				Base:   old,
+				Head:   v,
+			})
+		}
+	}
+	for _, v := range base.Vulnerabilities {
+		if _, ok := baseVulns[vulnKey(v)]; ok {
+			removed.Vulnerabilities = append(removed.Vulnerabilities, v)
+		}
+	}
+
+	// Misconfigurations
+	added.Misconfigurations = subtractMisconfs(target.Misconfigurations, base.Misconfigurations, severities)
+	removed.Misconfigurations = subtractMisconfs(base.Misconfigurations, target.Misconfigurations, severities)
+	added.MisconfSummary = summarize(added.Misconfigurations)
+	removed.MisconfSummary = summarize(removed.Misconfigurations)
+
+	// Secrets
+	added.Secrets = subtractSecrets(target.Secrets, base.Secrets, severities)
+	removed.Secrets = subtractSecrets(base.Secrets, target.Secrets, severities)
+
+	return added, removed, changed
+}
+
+// subtractMisconfs returns the failed misconfigurations in x which are not failed in y
+func subtractMisconfs(x, y []types.DetectedMisconfiguration, severities []dbTypes.Severity) []types.DetectedMisconfiguration {
+	failed := map[string]struct{}{}
+	for _, m := range y {
+		if m.Status == types.StatusFailure {
+			failed[misconfKey(m)] = struct{}{}
+		}
+	}
+
+	var misconfs []types.DetectedMisconfiguration
+	for _, m := range x {
+		if m.Status != types.StatusFailure || !matchSeverity(m.Severity, severities) {
+			continue
+		}
+		if _, ok := failed[misconfKey(m)]; !ok {
+			misconfs = append(misconfs, m)
+		}
+	}
+	return misconfs
+}
+
+// subtractSecrets returns the secrets in x which are not in y
+func subtractSecrets(x, y []ftypes.SecretFinding, severities []dbTypes.Severity) []ftypes.SecretFinding {
+	found := map[string]struct{}{}
+	for _, s := range y {
+		found[secretKey(s)] = struct{}{}
+	}
+
+	var secrets []ftypes.SecretFinding
+	for _, s := range x {
+		if !matchSeverity(s.Severity, severities) {
+			continue
+		}
+		if _, ok := found[secretKey(s)]; !ok {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
+func summarize(misconfs []types.DetectedMisconfiguration) *types.MisconfSummary {
+	if len(misconfs) == 0 {
+		return nil
+	}
+	return &types.MisconfSummary{Failures: len(misconfs)}
+}
+
+func empty(r types.Result) bool {
+	return len(r.Vulnerabilities) == 0 && len(r.Misconfigurations) == 0 && len(r.Secrets) == 0
+}
+
+func matchSeverity(severity string, severities []dbTypes.Severity) bool {
+	if len(severities) == 0 {
+		return true
+	}
+	if severity == "" {
+		severity = dbTypes.SeverityUnknown.String()
+	}
+	for _, s := range severities {
+		if s.String() == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// index returns the results keyed by what identifies them across reports
+func index(results types.Results) map[string]types.Result {
+	m := map[string]types.Result{}
+	for _, r := range results {
+		m[resultKey(r)] = r
+	}
+	return m
+}
+
+func resultKey(r types.Result) string {
+	// The target of OS packages contains the artifact name and the OS version,
+	// e.g. "alpine:3.15 (alpine 3.15.0)", which differ between the reports.
+	if r.Class == types.ClassOSPkg {
+		return fmt.Sprintf("%s/%s", r.Class, r.Type)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Class, r.Type, r.Target)
+}
+
+func vulnKey(v types.DetectedVulnerability) string {
+	return fmt.Sprintf("%s/%s/%s", v.VulnerabilityID, v.PkgName, v.PkgPath)
+}
+
+func misconfKey(m types.DetectedMisconfiguration) string {
+	return fmt.Sprintf("%s/%s", m.ID, m.CauseMetadata.Resource)
+}
+
+func secretKey(s ftypes.SecretFinding) string {
+	// Line numbers are not compared as they shift easily
+	return fmt.Sprintf("%s/%s", s.RuleID, s.Match)
+}
+
+func sortedKeys(maps ...map[string]types.Result) []string {
+	uniq := map[string]struct{}{}
+	for _, m := range maps {
+		for k := range m {
+			uniq[k] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(uniq))
+	for k := range uniq {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
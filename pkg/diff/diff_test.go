@@ -0,0 +1,312 @@
+package diff_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/diff"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+var (
+	vuln1 = types.DetectedVulnerability{
+		VulnerabilityID:  "CVE-2021-0001",
+		PkgName:          "musl",
+		InstalledVersion: "1.2.2-r0",
+		FixedVersion:     "1.2.2-r1",
+		Vulnerability: dbTypes.Vulnerability{
+			Severity: dbTypes.SeverityHigh.String(),
+		},
+	}
+	vuln2 = types.DetectedVulnerability{
+		VulnerabilityID:  "CVE-2021-0002",
+		PkgName:          "zlib",
+		InstalledVersion: "1.2.11-r3",
+		Vulnerability: dbTypes.Vulnerability{
+			Severity: dbTypes.SeverityLow.String(),
+		},
+	}
+	vuln2Upgraded = types.DetectedVulnerability{
+		VulnerabilityID:  "CVE-2021-0002",
+		PkgName:          "zlib",
+		InstalledVersion: "1.2.12-r0",
+		FixedVersion:     "1.2.12-r1",
+		Vulnerability: dbTypes.Vulnerability{
+			Severity: dbTypes.SeverityLow.String(),
+		},
+	}
+	vuln3 = types.DetectedVulnerability{
+		VulnerabilityID:  "CVE-2022-0003",
+		PkgName:          "busybox",
+		InstalledVersion: "1.34.1-r3",
+		FixedVersion:     "1.34.1-r5",
+		Vulnerability: dbTypes.Vulnerability{
+			Severity: dbTypes.SeverityCritical.String(),
+		},
+	}
+	misconf1 = types.DetectedMisconfiguration{
+		ID:       "DS002",
+		Severity: dbTypes.SeverityHigh.String(),
+		Status:   types.StatusFailure,
+	}
+	misconf2 = types.DetectedMisconfiguration{
+		ID:       "DS005",
+		Severity: dbTypes.SeverityLow.String(),
+		Status:   types.StatusFailure,
+	}
+	secret1 = ftypes.SecretFinding{
+		RuleID:    "aws-access-key-id",
+		Severity:  dbTypes.SeverityCritical.String(),
+		StartLine: 10,
+		Match:     "AWS_ACCESS_KEY_ID=********************",
+	}
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name       string
+		base       types.Report
+		target     types.Report
+		severities []dbTypes.Severity
+		want       diff.Report
+	}{
+		{
+			name: "image tags",
+			base: types.Report{
+				ArtifactName: "myapp:1.0",
+				Results: types.Results{
+					{
+						Target:          "myapp:1.0 (alpine 3.15.0)",
+						Class:           types.ClassOSPkg,
+						Type:            "alpine",
+						Vulnerabilities: []types.DetectedVulnerability{vuln1, vuln2},
+					},
+				},
+			},
+			target: types.Report{
+				ArtifactName: "myapp:1.1",
+				Results: types.Results{
+					{
+						Target:          "myapp:1.1 (alpine 3.15.4)",
+						Class:           types.ClassOSPkg,
+						Type:            "alpine",
+						Vulnerabilities: []types.DetectedVulnerability{vuln2Upgraded, vuln3},
+					},
+				},
+			},
+			want: diff.Report{
+				Base:   "myapp:1.0",
+				Target: "myapp:1.1",
+				Added: types.Results{
+					{
+						Target:          "myapp:1.1 (alpine 3.15.4)",
+						Class:           types.ClassOSPkg,
+						Type:            "alpine",
+						Vulnerabilities: []types.DetectedVulnerability{vuln3},
+					},
+				},
+				Removed: types.Results{
+					{
+						Target:          "myapp:1.0 (alpine 3.15.0)",
+						Class:           types.ClassOSPkg,
+						Type:            "alpine",
+						Vulnerabilities: []types.DetectedVulnerability{vuln1},
+					},
+				},
+				Changed: []diff.ChangedVulnerability{
+					{
+						Target: "myapp:1.1 (alpine 3.15.4)",
+						Class:  types.ClassOSPkg,
+						Type:   "alpine",
+						Base:   vuln2,
+						Head:   vuln2Upgraded,
+					},
+				},
+			},
+		},
+		{
+			name: "misconfigurations and secrets",
+			base: types.Report{
+				ArtifactName: "main",
+				Results: types.Results{
+					{
+						Target:            "Dockerfile",
+						Class:             types.ClassConfig,
+						Type:              "dockerfile",
+						MisconfSummary:    &types.MisconfSummary{Failures: 1},
+						Misconfigurations: []types.DetectedMisconfiguration{misconf1},
+					},
+				},
+			},
+			target: types.Report{
+				ArtifactName: "feature",
+				Results: types.Results{
+					{
+						Target:            "Dockerfile",
+						Class:             types.ClassConfig,
+						Type:              "dockerfile",
+						MisconfSummary:    &types.MisconfSummary{Failures: 2},
+						Misconfigurations: []types.DetectedMisconfiguration{misconf1, misconf2},
+					},
+					{
+						Target:  ".env",
+						Class:   types.ClassSecret,
+						Secrets: []ftypes.SecretFinding{secret1},
+					},
+				},
+			},
+			want: diff.Report{
+				Base:   "main",
+				Target: "feature",
+				Added: types.Results{
+					{
+						Target:            "Dockerfile",
+						Class:             types.ClassConfig,
+						Type:              "dockerfile",
+						MisconfSummary:    &types.MisconfSummary{Failures: 1},
+						Misconfigurations: []types.DetectedMisconfiguration{misconf2},
+					},
+					{
+						Target:  ".env",
+						Class:   types.ClassSecret,
+						Secrets: []ftypes.SecretFinding{secret1},
+					},
+				},
+			},
+		},
+		{
+			name: "severities",
+			base: types.Report{
+				Results: types.Results{
+					{
+						Target:          "package-lock.json",
+						Class:           types.ClassLangPkg,
+						Type:            "npm",
+						Vulnerabilities: []types.DetectedVulnerability{vuln1},
+					},
+				},
+			},
+			target: types.Report{
+				Results: types.Results{
+					{
+						Target:          "package-lock.json",
+						Class:           types.ClassLangPkg,
+						Type:            "npm",
+						Vulnerabilities: []types.DetectedVulnerability{vuln2, vuln3},
+					},
+				},
+			},
+			severities: []dbTypes.Severity{dbTypes.SeverityLow},
+			want: diff.Report{
+				Added: types.Results{
+					{
+						Target:          "package-lock.json",
+						Class:           types.ClassLangPkg,
+						Type:            "npm",
+						Vulnerabilities: []types.DetectedVulnerability{vuln2},
+					},
+				},
+			},
+		},
+		{
+			name: "no differences",
+			base: types.Report{
+				Results: types.Results{
+					{
+						Target:          "go.sum",
+						Class:           types.ClassLangPkg,
+						Type:            "gomod",
+						Vulnerabilities: []types.DetectedVulnerability{vuln1},
+					},
+				},
+			},
+			target: types.Report{
+				Results: types.Results{
+					{
+						Target:          "go.sum",
+						Class:           types.ClassLangPkg,
+						Type:            "gomod",
+						Vulnerabilities: []types.DetectedVulnerability{vuln1},
+					},
+				},
+			},
+			want: diff.Report{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diff.Compare(tt.base, tt.target, tt.severities)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	r := diff.Report{
+		Base:   "myapp:1.0",
+		Target: "myapp:1.1",
+		Changed: []diff.ChangedVulnerability{
+			{
+				Target: "myapp:1.1 (alpine 3.15.4)",
+				Class:  types.ClassOSPkg,
+				Type:   "alpine",
+				Base:   vuln2,
+				Head:   vuln2Upgraded,
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "table",
+			format: "table",
+			want: []string{
+				"Base:   myapp:1.0\nTarget: myapp:1.1\n",
+				"Changed",
+				"1.2.11-r3 -> 1.2.12-r0",
+				"- -> 1.2.12-r1",
+			},
+		},
+		{
+			name:   "json",
+			format: "json",
+			want: []string{
+				`"Base": "myapp:1.0"`,
+				`"InstalledVersion": "1.2.12-r0"`,
+			},
+		},
+		{
+			name:    "unsupported format",
+			format:  "sarif",
+			wantErr: "unsupported format for diff: sarif",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			err := diff.Write(r, diff.Option{
+				Format: tt.format,
+				Output: output,
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, w := range tt.want {
+				assert.Contains(t, output.String(), w)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/table"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// Option holds the options for writing the differences
+type Option struct {
+	Format     string
+	Output     io.Writer
+	Severities []dbTypes.Severity
+}
+
+// Write writes the differences in the given format
+func Write(r Report, option Option) error {
+	switch option.Format {
+	case "table":
+		return writeTable(r, option)
+	case "json":
+		output, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return xerrors.Errorf("failed to marshal json: %w", err)
+		}
+		if _, err = fmt.Fprintln(option.Output, string(output)); err != nil {
+			return xerrors.Errorf("failed to write json: %w", err)
+		}
+		return nil
+	default:
+		return xerrors.Errorf("unsupported format for diff: %s", option.Format)
+	}
+}
+
+func writeTable(r Report, option Option) error {
+	severities := option.Severities
+	if len(severities) == 0 {
+		for _, s := range dbTypes.SeverityNames {
+			severity, _ := dbTypes.NewSeverity(s) // nolint: errcheck
+			severities = append(severities, severity)
+		}
+	}
+
+	_, _ = fmt.Fprintf(option.Output, "Base:   %s\nTarget: %s\n", r.Base, r.Target)
+	if r.Empty() {
+		_, _ = fmt.Fprintln(option.Output, "\nNo differences found")
+		return nil
+	}
+
+	tw := report.TableWriter{
+		Output:          option.Output,
+		Severities:      severities,
+		ShowMessageOnce: &sync.Once{},
+	}
+	for _, section := range []struct {
+		title   string
+		results types.Results
+	}{
+		{title: "Added", results: r.Added},
+		{title: "Removed", results: r.Removed},
+	} {
+		if len(section.results) == 0 {
+			continue
+		}
+		writeTitle(option.Output, section.title)
+		if err := tw.Write(types.Report{Results: section.results}); err != nil {
+			return xerrors.Errorf("failed to write %s findings: %w", strings.ToLower(section.title), err)
+		}
+	}
+
+	if len(r.Changed) > 0 {
+		writeTitle(option.Output, "Changed")
+		writeChanged(option.Output, r.Changed)
+	}
+	return nil
+}
+
+func writeTitle(w io.Writer, title string) {
+	_, _ = fmt.Fprintf(w, "\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+}
+
+func writeChanged(w io.Writer, changed []ChangedVulnerability) {
+	t := table.New(w)
+	t.SetBorders(true)
+	t.SetRowLines(true)
+	t.SetHeaders("Target", "Library", "Vulnerability", "Severity", "Installed Version", "Fixed Version")
+	for _, c := range changed {
+		lib := c.Head.PkgName
+		if c.Head.PkgPath != "" {
+			lib = fmt.Sprintf("%s (%s)", c.Head.PkgName, filepath.Base(c.Head.PkgPath))
+		}
+		t.AddRow(c.Target, lib, c.Head.VulnerabilityID,
+			change(c.Base.Severity, c.Head.Severity),
+			change(c.Base.InstalledVersion, c.Head.InstalledVersion),
+			change(c.Base.FixedVersion, c.Head.FixedVersion))
+	}
+	t.Render()
+}
+
+func change(before, after string) string {
+	if before == after {
+		return after
+	}
+	if before == "" {
+		before = "-"
+	}
+	if after == "" {
+		after = "-"
+	}
+	return fmt.Sprintf("%s -> %s", before, after)
+}
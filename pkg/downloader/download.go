@@ -2,10 +2,13 @@ package downloader
 
 import (
 	"context"
+	"net/http"
 	"os"
 
 	getter "github.com/hashicorp/go-getter"
 	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/utils"
 )
 
 // DownloadToTempDir downloads the configured source to a temp dir.
@@ -37,6 +40,11 @@ func Download(ctx context.Context, src, dst, pwd string) error {
 	// Overwrite the file getter so that a file will be copied
 	getter.Getters["file"] = &getter.FileGetter{Copy: true}
 
+	// go-getter has its own transport by default, which ignores '--ca-bundle'
+	httpGetter := &getter.HttpGetter{Netrc: true, Client: &http.Client{Transport: utils.HTTPTransport(false)}}
+	getter.Getters["http"] = httpGetter
+	getter.Getters["https"] = httpGetter
+
 	// Build the client
 	client := &getter.Client{
 		Ctx:     ctx,
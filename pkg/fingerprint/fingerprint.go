@@ -0,0 +1,155 @@
+// Package fingerprint identifies the native libraries statically linked into executables, such as OpenSSL and zlib
+// vendored in distroless images, by the version strings the libraries embed. They are not owned by any OS package,
+// so that their vulnerabilities are detected with the advisories of Conan, which packages them.
+package fingerprint
+
+import (
+	"context"
+	"debug/elf"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/language"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&staticLibraryAnalyzer{})
+}
+
+const (
+	// TypeStaticLibrary analyzes the executables for the statically linked libraries
+	TypeStaticLibrary = analyzer.Type("static-library")
+
+	// StaticLibrary is the type of the applications with the libraries found in the executables
+	StaticLibrary = "static-library"
+
+	version = 1
+)
+
+// fingerprint is the version string a library embeds, which is read from the read-only data of the executables
+type fingerprint struct {
+	// name is the package name in the advisories
+	name string
+
+	// pattern has the version in the first submatch
+	pattern *regexp.Regexp
+
+	// sharedLibraries are the prefixes of the shared libraries of the library. The fingerprints in the executables
+	// linking them dynamically are not the statically linked ones, e.g. the strings of libraries' headers.
+	sharedLibraries []string
+}
+
+var fingerprints = []fingerprint{
+	{
+		// e.g. "OpenSSL 1.1.1n  15 Mar 2022" of OpenSSL_version()
+		name:            "openssl",
+		pattern:         regexp.MustCompile(`OpenSSL (\d+\.\d+\.\d+[a-z]{0,2})(?:-[0-9A-Za-z]+)? {1,2}\d{1,2} [A-Z][a-z]{2} \d{4}`),
+		sharedLibraries: []string{"libssl.so", "libcrypto.so"},
+	},
+	{
+		// e.g. " deflate 1.2.11 Copyright 1995-2017 Jean-loup Gailly and Mark Adler "
+		name:            "zlib",
+		pattern:         regexp.MustCompile(`(?:de|in)flate (\d+\.\d+\.\d+(?:\.\d+)?) Copyright`),
+		sharedLibraries: []string{"libz.so"},
+	},
+	{
+		// e.g. "libcurl/7.83.1" of curl_version()
+		name:            "libcurl",
+		pattern:         regexp.MustCompile(`libcurl/(\d+\.\d+\.\d+)`),
+		sharedLibraries: []string{"libcurl.so", "libcurl-gnutls.so"},
+	},
+	{
+		// e.g. " libpng version 1.6.37 - April 14, 2019" of png_get_copyright()
+		name:            "libpng",
+		pattern:         regexp.MustCompile(`libpng version (\d+\.\d+\.\d+)`),
+		sharedLibraries: []string{"libpng"},
+	},
+}
+
+// staticLibraryAnalyzer reports the libraries found in the ELF executables
+type staticLibraryAnalyzer struct{}
+
+func (a staticLibraryAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	f, err := elf.NewFile(input.Content)
+	if err != nil {
+		// Not ELF, e.g. scripts
+		return nil, nil
+	}
+	defer f.Close()
+
+	// Shared libraries with SONAME are skipped, as they are owned by OS packages in general
+	if sonames, err := f.DynString(elf.DT_SONAME); err == nil && len(sonames) > 0 {
+		return nil, nil
+	}
+
+	needed, err := f.ImportedLibraries()
+	if err != nil {
+		return nil, xerrors.Errorf("ELF dynamic section error (%s): %w", input.FilePath, err)
+	}
+
+	data, err := readOnlyData(f)
+	if err != nil {
+		return nil, xerrors.Errorf("ELF section error (%s): %w", input.FilePath, err)
+	}
+
+	var libs []godeptypes.Library
+	for _, fp := range fingerprints {
+		if fp.linkedDynamically(needed) {
+			continue
+		}
+		if m := fp.pattern.FindSubmatch(data); m != nil {
+			libs = append(libs, godeptypes.Library{Name: fp.name, Version: string(m[1])})
+		}
+	}
+	sort.Slice(libs, func(i, j int) bool {
+		return libs[i].Name < libs[j].Name
+	})
+	return language.ToAnalysisResult(StaticLibrary, input.FilePath, "", libs, nil), nil
+}
+
+func (a staticLibraryAnalyzer) Required(_ string, fileInfo os.FileInfo) bool {
+	mode := fileInfo.Mode()
+	return mode.IsRegular() && mode.Perm()&0111 != 0
+}
+
+func (a staticLibraryAnalyzer) Type() analyzer.Type {
+	return TypeStaticLibrary
+}
+
+func (a staticLibraryAnalyzer) Version() int {
+	return version
+}
+
+func (fp fingerprint) linkedDynamically(needed []string) bool {
+	for _, lib := range needed {
+		for _, prefix := range fp.sharedLibraries {
+			if strings.HasPrefix(lib, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readOnlyData returns the sections where the compilers put the string constants
+func readOnlyData(f *elf.File) ([]byte, error) {
+	var data []byte
+	for _, name := range []string{".rodata", ".data.rel.ro"} {
+		s := f.Section(name)
+		if s == nil || s.Type == elf.SHT_NOBITS {
+			continue
+		}
+		b, err := s.Data()
+		if err != nil {
+			return nil, xerrors.Errorf("%s read error: %w", name, err)
+		}
+		data = append(data, b...)
+	}
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package fingerprint
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+func Test_staticLibraryAnalyzer_Analyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		want     *analyzer.AnalysisResult
+	}{
+		{
+			name:     "statically linked",
+			filePath: "testdata/static",
+			want: &analyzer.AnalysisResult{
+				Applications: []ftypes.Application{
+					{
+						Type:     StaticLibrary,
+						FilePath: "testdata/static",
+						Libraries: []ftypes.Package{
+							{Name: "openssl", Version: "1.1.1n"},
+							{Name: "zlib", Version: "1.2.11"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "dynamically linked zlib",
+			filePath: "testdata/dynamic",
+			want: &analyzer.AnalysisResult{
+				Applications: []ftypes.Application{
+					{
+						Type:     StaticLibrary,
+						FilePath: "testdata/dynamic",
+						Libraries: []ftypes.Package{
+							{Name: "openssl", Version: "3.0.2"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "shared library",
+			filePath: "testdata/libcrypto.so.1.1",
+		},
+		{
+			name:     "not ELF",
+			filePath: "testdata/static.c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.filePath)
+			require.NoError(t, err)
+			defer f.Close()
+
+			got, err := staticLibraryAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: tt.filePath,
+				Content:  f,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_staticLibraryAnalyzer_Required(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		want     bool
+	}{
+		{
+			name:     "executable",
+			filePath: "testdata/static",
+			want:     true,
+		},
+		{
+			name:     "not executable",
+			filePath: "testdata/static.c",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := os.Stat(tt.filePath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, staticLibraryAnalyzer{}.Required(strings.TrimPrefix(tt.filePath, "testdata/"), info))
+		})
+	}
+}
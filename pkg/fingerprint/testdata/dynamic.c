@@ -0,0 +1,5 @@
+// gcc -Os -s -o dynamic dynamic.c -Wl,--no-as-needed -lz
+// The zlib strings are in the shared library the binary links against
+const char openssl_version[] = "OpenSSL 3.0.2 15 Mar 2022";
+const char deflate_copyright[] = " deflate 1.2.11 Copyright 1995-2017 Jean-loup Gailly and Mark Adler ";
+int main(void) { return 0; }
@@ -0,0 +1,3 @@
+// gcc -Os -s -shared -fPIC -Wl,-soname,libcrypto.so.1.1 -o libcrypto.so.1.1 shared.c
+// Shared libraries are owned by the OS packages in general
+const char openssl_version[] = "OpenSSL 1.1.1n  15 Mar 2022";
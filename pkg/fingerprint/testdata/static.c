@@ -0,0 +1,7 @@
+// gcc -Os -s -static -nostdlib -o static static.c
+const char openssl_version[] = "OpenSSL 1.1.1n  15 Mar 2022";
+const char deflate_copyright[] = " deflate 1.2.11 Copyright 1995-2017 Jean-loup Gailly and Mark Adler ";
+const char inflate_copyright[] = " inflate 1.2.11 Copyright 1995-2017 Mark Adler ";
+void _start(void) {
+	__asm__ volatile("mov $60, %%eax\n xor %%edi, %%edi\n syscall" ::: "memory");
+}
@@ -0,0 +1,84 @@
+package gate
+
+import (
+	"context"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const celDefaultMessage = "denied by the gate"
+
+// celGate fails the report if the expression returns true, a non-empty message or a list of messages.
+// The report is given as "report", e.g. report.Results.exists(r, has(r.Vulnerabilities)).
+type celGate struct {
+	program cel.Program
+}
+
+func newCELGate(expr string) (celGate, error) {
+	env, err := cel.NewEnv(cel.Declarations(decls.NewVar("report", decls.Dyn)))
+	if err != nil {
+		return celGate{}, xerrors.Errorf("CEL environment error: %w", err)
+	}
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return celGate{}, xerrors.Errorf("CEL compile error: %w", issues.Err())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return celGate{}, xerrors.Errorf("CEL program error: %w", err)
+	}
+	return celGate{program: program}, nil
+}
+
+func (g celGate) Evaluate(_ context.Context, report types.Report) (Result, error) {
+	input, err := toInput(report)
+	if err != nil {
+		return Result{}, err
+	}
+
+	out, _, err := g.program.Eval(map[string]interface{}{"report": input})
+	if err != nil {
+		return Result{}, xerrors.Errorf("unable to evaluate the gate: %w", err)
+	}
+	messages, err := celMessages(out)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(messages), nil
+}
+
+func celMessages(out ref.Val) ([]string, error) {
+	switch v := out.Value().(type) {
+	case bool:
+		if v {
+			return []string{celDefaultMessage}, nil
+		}
+		return nil, nil
+	case string:
+		if v != "" {
+			return []string{v}, nil
+		}
+		return nil, nil
+	}
+
+	list, ok := out.(traits.Lister)
+	if !ok {
+		return nil, xerrors.Errorf("the gate must return bool, string or list of strings, but got %s", out.Type().TypeName())
+	}
+	var messages []string
+	for it := list.Iterator(); it.HasNext() == celtypes.True; {
+		msg, ok := it.Next().Value().(string)
+		if !ok {
+			return nil, xerrors.New("the gate must return a list of strings")
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
@@ -0,0 +1,69 @@
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// Result is the decision of the gate on the report
+type Result struct {
+	Passed bool
+
+	// Messages explain why the gate failed
+	Messages []string
+}
+
+// Gate decides whether the report passes, e.g. fail if any critical vulnerabilities are found in production images
+type Gate interface {
+	Evaluate(ctx context.Context, report types.Report) (Result, error)
+}
+
+// Load loads the gate file written in Rego (.rego) or CEL (.cel)
+func Load(ctx context.Context, path string) (Gate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to read the gate file: %w", err)
+	}
+
+	switch filepath.Ext(path) {
+	case ".rego":
+		return newRegoGate(ctx, string(b))
+	case ".cel":
+		return newCELGate(string(b))
+	}
+	return nil, xerrors.Errorf("unknown gate file extension (%s), .rego or .cel is supported", path)
+}
+
+// toInput converts the report to the same structure as the JSON report, so that gates can be written against it
+func toInput(report types.Report) (map[string]interface{}, error) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, xerrors.Errorf("JSON marshal error: %w", err)
+	}
+	var input map[string]interface{}
+	if err = json.Unmarshal(b, &input); err != nil {
+		return nil, xerrors.Errorf("JSON unmarshal error: %w", err)
+	}
+
+	// The empty fields are omitted in JSON, but gates can always refer to them
+	if _, ok := input["Results"]; !ok {
+		input["Results"] = []interface{}{}
+	}
+	if _, ok := input["Metadata"]; !ok {
+		input["Metadata"] = map[string]interface{}{}
+	}
+	return input, nil
+}
+
+func newResult(messages []string) Result {
+	return Result{
+		Passed:   len(messages) == 0,
+		Messages: messages,
+	}
+}
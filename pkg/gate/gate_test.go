@@ -0,0 +1,150 @@
+package gate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/gate"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func newReport(tags []string, severities ...string) types.Report {
+	var vulns []types.DetectedVulnerability
+	for _, s := range severities {
+		vulns = append(vulns, types.DetectedVulnerability{
+			VulnerabilityID: "CVE-2022-0001",
+			PkgName:         "openssl",
+			Vulnerability:   dbTypes.Vulnerability{Severity: s},
+		})
+	}
+	return types.Report{
+		ArtifactName: "alpine:3.10",
+		Metadata:     types.Metadata{RepoTags: tags},
+		Results: types.Results{
+			{
+				Target:          "alpine:3.10 (alpine 3.10.2)",
+				Vulnerabilities: vulns,
+			},
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		report  types.Report
+		want    gate.Result
+		wantErr string
+	}{
+		{
+			name:   "rego: passed",
+			path:   "testdata/gate.rego",
+			report: newReport([]string{"alpine:prod"}, "HIGH", "HIGH", "HIGH"),
+			want:   gate.Result{Passed: true},
+		},
+		{
+			name:   "rego: critical",
+			path:   "testdata/gate.rego",
+			report: newReport(nil, "CRITICAL", "LOW"),
+			want: gate.Result{
+				Messages: []string{"alpine:3.10 (alpine 3.10.2): critical vulnerability CVE-2022-0001 in openssl"},
+			},
+		},
+		{
+			name:   "rego: too many high in production",
+			path:   "testdata/gate.rego",
+			report: newReport([]string{"alpine:prod"}, "HIGH", "HIGH", "HIGH", "HIGH"),
+			want: gate.Result{
+				Messages: []string{"4 high vulnerabilities in the production image"},
+			},
+		},
+		{
+			name:   "rego: high out of production",
+			path:   "testdata/gate.rego",
+			report: newReport([]string{"alpine:dev"}, "HIGH", "HIGH", "HIGH", "HIGH"),
+			want:   gate.Result{Passed: true},
+		},
+		{
+			name:   "cel: passed",
+			path:   "testdata/gate.cel",
+			report: newReport(nil, "HIGH"),
+			want:   gate.Result{Passed: true},
+		},
+		{
+			name:   "cel: no vulnerabilities",
+			path:   "testdata/gate.cel",
+			report: newReport(nil),
+			want:   gate.Result{Passed: true},
+		},
+		{
+			name:   "cel: no results",
+			path:   "testdata/gate.cel",
+			report: types.Report{ArtifactName: "alpine:3.10"},
+			want:   gate.Result{Passed: true},
+		},
+		{
+			name:   "cel: critical",
+			path:   "testdata/gate.cel",
+			report: newReport(nil, "CRITICAL"),
+			want: gate.Result{
+				Messages: []string{"critical vulnerabilities found in alpine:3.10"},
+			},
+		},
+		{
+			name:   "cel: bool",
+			path:   "testdata/bool.cel",
+			report: newReport(nil),
+			want: gate.Result{
+				Messages: []string{"denied by the gate"},
+			},
+		},
+		{
+			name:    "sad path: cel returns int",
+			path:    "testdata/invalid-type.cel",
+			report:  newReport(nil),
+			wantErr: "the gate must return bool, string or list of strings",
+		},
+		{
+			name:    "sad path: invalid cel",
+			path:    "testdata/invalid.cel",
+			wantErr: "CEL compile error",
+		},
+		{
+			name:    "sad path: invalid rego",
+			path:    "testdata/invalid.rego",
+			wantErr: "unable to prepare for eval",
+		},
+		{
+			name:    "sad path: unknown extension",
+			path:    "testdata/gate.json",
+			wantErr: "unknown gate file extension",
+		},
+		{
+			name:    "sad path: no such file",
+			path:    "testdata/missing.rego",
+			wantErr: "unable to read the gate file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			g, err := gate.Load(ctx, tt.path)
+			if err == nil {
+				var got gate.Result
+				got, err = g.Evaluate(ctx, tt.report)
+				if tt.wantErr == "" {
+					require.NoError(t, err)
+					assert.Equal(t, tt.want, got)
+					return
+				}
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/opa/rego"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// regoGate fails the report if "deny" in the "trivy.gate" package returns any messages.
+//
+//	package trivy.gate
+//
+//	deny[msg] {
+//		...
+//	}
+type regoGate struct {
+	query rego.PreparedEvalQuery
+}
+
+func newRegoGate(ctx context.Context, policy string) (regoGate, error) {
+	query, err := rego.New(
+		rego.Query("data.trivy.gate.deny"),
+		rego.Module("gate.rego", policy),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return regoGate{}, xerrors.Errorf("unable to prepare for eval: %w", err)
+	}
+	return regoGate{query: query}, nil
+}
+
+func (g regoGate) Evaluate(ctx context.Context, report types.Report) (Result, error) {
+	input, err := toInput(report)
+	if err != nil {
+		return Result{}, err
+	}
+
+	results, err := g.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return Result{}, xerrors.Errorf("unable to evaluate the gate: %w", err)
+	} else if len(results) == 0 {
+		// "deny" is undefined
+		return newResult(nil), nil
+	}
+
+	denies, ok := results[0].Expressions[0].Value.([]interface{})
+	if !ok {
+		return Result{}, xerrors.New("'deny' must be a set of messages")
+	}
+	var messages []string
+	for _, d := range denies {
+		if msg, ok := d.(string); ok {
+			messages = append(messages, msg)
+		} else {
+			messages = append(messages, fmt.Sprint(d))
+		}
+	}
+	sort.Strings(messages)
+	return newResult(messages), nil
+}
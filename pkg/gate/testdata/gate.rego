@@ -0,0 +1,18 @@
+package trivy.gate
+
+import future.keywords.in
+
+deny[msg] {
+	some result in input.Results
+	some vuln in result.Vulnerabilities
+	vuln.Severity == "CRITICAL"
+	msg := sprintf("%s: critical vulnerability %s in %s", [result.Target, vuln.VulnerabilityID, vuln.PkgName])
+}
+
+deny[msg] {
+	some tag in input.Metadata.RepoTags
+	endswith(tag, ":prod")
+	highs := [vuln | some result in input.Results; some vuln in result.Vulnerabilities; vuln.Severity == "HIGH"]
+	count(highs) > 3
+	msg := sprintf("%d high vulnerabilities in the production image", [count(highs)])
+}
@@ -0,0 +1,3 @@
+package trivy.gate
+
+deny[msg {
@@ -0,0 +1,206 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// Store records when findings are first and last seen per target, so that how long findings stay open
+// and how long they take to be fixed can be calculated. The history of each target is stored as a JSON file.
+type Store struct {
+	dir string
+}
+
+// TargetHistory holds the findings ever seen in the target
+type TargetHistory struct {
+	Target string
+
+	// Findings are keyed by the fingerprints
+	Findings map[string]*Finding
+}
+
+// Finding holds when the finding was seen
+type Finding struct {
+	// ID is the vulnerability ID or the misconfiguration ID
+	ID        string
+	Severity  string
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// ResolvedAt is the time of the first scan where the finding disappeared
+	ResolvedAt *time.Time `json:",omitempty"`
+}
+
+// Open returns whether the finding has not been resolved yet
+func (f Finding) Open() bool {
+	return f.ResolvedAt == nil
+}
+
+// NewStore returns the store saving the histories in dir
+func NewStore(dir string) Store {
+	return Store{dir: dir}
+}
+
+// Track records the vulnerabilities and the failed misconfigurations in the report, and fills when they were
+// first and last seen. The findings which were open and are not in the report anymore are marked as resolved.
+func (s Store) Track(report *types.Report, now time.Time) error {
+	h, err := s.Load(report.ArtifactName)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]struct{}{}
+	for i, result := range report.Results {
+		for j, vuln := range result.Vulnerabilities {
+			fp := fingerprint(resultKey(result), vuln.VulnerabilityID, vuln.PkgName, vuln.PkgPath)
+			f := h.observe(fp, vuln.VulnerabilityID, vuln.Severity, now)
+			report.Results[i].Vulnerabilities[j].Tracking = f.tracking(fp)
+			seen[fp] = struct{}{}
+		}
+		for j, misconf := range result.Misconfigurations {
+			if misconf.Status != types.StatusFailure {
+				continue
+			}
+			fp := fingerprint(resultKey(result), misconf.ID, misconf.CauseMetadata.Resource)
+			f := h.observe(fp, misconf.ID, misconf.Severity, now)
+			report.Results[i].Misconfigurations[j].Tracking = f.tracking(fp)
+			seen[fp] = struct{}{}
+		}
+	}
+
+	for fp, f := range h.Findings {
+		if _, ok := seen[fp]; !ok && f.Open() {
+			resolvedAt := now
+			f.ResolvedAt = &resolvedAt
+		}
+	}
+
+	return s.save(h)
+}
+
+// Load returns the history of the target. It is empty if the target has never been scanned.
+func (s Store) Load(target string) (TargetHistory, error) {
+	h := TargetHistory{
+		Target:   target,
+		Findings: map[string]*Finding{},
+	}
+	b, err := os.ReadFile(s.path(target))
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	} else if err != nil {
+		return TargetHistory{}, xerrors.Errorf("unable to read the history of %s: %w", target, err)
+	}
+	if err = json.Unmarshal(b, &h); err != nil {
+		return TargetHistory{}, xerrors.Errorf("JSON decode error (%s): %w", s.path(target), err)
+	}
+	return h, nil
+}
+
+// List returns the histories of all the targets sorted by the target names
+func (s Store) List() ([]TargetHistory, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, xerrors.Errorf("glob error: %w", err)
+	}
+
+	var histories []TargetHistory
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, xerrors.Errorf("file read error: %w", err)
+		}
+		var h TargetHistory
+		if err = json.Unmarshal(b, &h); err != nil {
+			return nil, xerrors.Errorf("JSON decode error (%s): %w", file, err)
+		}
+		histories = append(histories, h)
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].Target < histories[j].Target
+	})
+	return histories, nil
+}
+
+func (s Store) save(h TargetHistory) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return xerrors.Errorf("failed to create the history directory: %w", err)
+	}
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("JSON encode error: %w", err)
+	}
+
+	// The history is replaced at once so that it is not broken by concurrent scans of the same target
+	tmp, err := os.CreateTemp(s.dir, ".history-*")
+	if err != nil {
+		return xerrors.Errorf("failed to create a temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return xerrors.Errorf("failed to write the history: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return xerrors.Errorf("failed to write the history: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), s.path(h.Target)); err != nil {
+		return xerrors.Errorf("failed to save the history: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of the target. The target is hashed as image names and paths can't be file names.
+func (s Store) path(target string) string {
+	h := sha256.Sum256([]byte(target))
+	return filepath.Join(s.dir, hex.EncodeToString(h[:])+".json")
+}
+
+// observe records the finding seen now. The finding which reappears after it is resolved is tracked as a new one.
+func (h TargetHistory) observe(fp, id, severity string, now time.Time) *Finding {
+	f, ok := h.Findings[fp]
+	if !ok || !f.Open() {
+		f = &Finding{
+			ID:        id,
+			FirstSeen: now,
+		}
+		h.Findings[fp] = f
+	}
+	f.Severity = severity
+	f.LastSeen = now
+	return f
+}
+
+func (f Finding) tracking(fp string) *types.Tracking {
+	return &types.Tracking{
+		Fingerprint: fp,
+		FirstSeen:   f.FirstSeen,
+		LastSeen:    f.LastSeen,
+	}
+}
+
+func fingerprint(parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:16])
+}
+
+func resultKey(r types.Result) string {
+	// The target of OS packages contains the OS version, e.g. "alpine:3.15 (alpine 3.15.0)",
+	// which changes when the base image is updated.
+	if r.Class == types.ClassOSPkg {
+		return fmt.Sprintf("%s/%s", r.Class, r.Type)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Class, r.Type, r.Target)
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func newReport(target, osVersion string, vulnIDs []string, misconfs ...types.DetectedMisconfiguration) types.Report {
+	var vulns []types.DetectedVulnerability
+	for _, id := range vulnIDs {
+		vulns = append(vulns, types.DetectedVulnerability{
+			VulnerabilityID:  id,
+			PkgName:          "musl",
+			InstalledVersion: "1.2.2-r7",
+			Vulnerability: dbTypes.Vulnerability{
+				Severity: dbTypes.SeverityHigh.String(),
+			},
+		})
+	}
+	return types.Report{
+		ArtifactName: target,
+		Results: types.Results{
+			{
+				Target:          target + " (alpine " + osVersion + ")",
+				Class:           types.ClassOSPkg,
+				Type:            "alpine",
+				Vulnerabilities: vulns,
+			},
+			{
+				Target:            "Dockerfile",
+				Class:             types.ClassConfig,
+				Type:              "dockerfile",
+				Misconfigurations: misconfs,
+			},
+		},
+	}
+}
+
+func TestStore_Track(t *testing.T) {
+	day1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	failed := types.DetectedMisconfiguration{ID: "DS002", Severity: "HIGH", Status: types.StatusFailure}
+	passed := types.DetectedMisconfiguration{ID: "DS001", Severity: "MEDIUM", Status: types.StatusPassed}
+
+	s := NewStore(filepath.Join(t.TempDir(), "history"))
+
+	// The first scan
+	r1 := newReport("alpine:3.15", "3.15.0", []string{"CVE-2022-0001", "CVE-2022-0002"}, failed, passed)
+	require.NoError(t, s.Track(&r1, day1))
+	assert.Equal(t, day1, r1.Results[0].Vulnerabilities[0].Tracking.FirstSeen)
+	assert.Equal(t, day1, r1.Results[1].Misconfigurations[0].Tracking.FirstSeen)
+	assert.Nil(t, r1.Results[1].Misconfigurations[1].Tracking, "passed checks are not tracked")
+
+	// CVE-2022-0002 is fixed, and the OS version in the target doesn't change the fingerprints
+	r2 := newReport("alpine:3.15", "3.15.4", []string{"CVE-2022-0001", "CVE-2022-0003"}, failed)
+	require.NoError(t, s.Track(&r2, day2))
+	assert.Equal(t, &types.Tracking{
+		Fingerprint: r1.Results[0].Vulnerabilities[0].Tracking.Fingerprint,
+		FirstSeen:   day1,
+		LastSeen:    day2,
+	}, r2.Results[0].Vulnerabilities[0].Tracking)
+	assert.Equal(t, day2, r2.Results[0].Vulnerabilities[1].Tracking.FirstSeen)
+
+	// CVE-2022-0002 comes back
+	r3 := newReport("alpine:3.15", "3.15.4", []string{"CVE-2022-0002"})
+	require.NoError(t, s.Track(&r3, day3))
+	assert.Equal(t, day3, r3.Results[0].Vulnerabilities[0].Tracking.FirstSeen, "reopened findings are new")
+
+	h, err := s.Load("alpine:3.15")
+	require.NoError(t, err)
+	assert.Equal(t, "alpine:3.15", h.Target)
+
+	got := map[string][]Finding{}
+	for _, f := range h.Findings {
+		got[f.ID] = append(got[f.ID], *f)
+	}
+	assert.Equal(t, map[string][]Finding{
+		"CVE-2022-0001": {{ID: "CVE-2022-0001", Severity: "HIGH", FirstSeen: day1, LastSeen: day2, ResolvedAt: &day3}},
+		"CVE-2022-0002": {{ID: "CVE-2022-0002", Severity: "HIGH", FirstSeen: day3, LastSeen: day3}},
+		"CVE-2022-0003": {{ID: "CVE-2022-0003", Severity: "HIGH", FirstSeen: day2, LastSeen: day2, ResolvedAt: &day3}},
+		"DS002":         {{ID: "DS002", Severity: "HIGH", FirstSeen: day1, LastSeen: day2, ResolvedAt: &day3}},
+	}, got)
+
+	// The other target has its own history
+	other := newReport("alpine:3.16", "3.16.0", []string{"CVE-2022-0001"})
+	require.NoError(t, s.Track(&other, day3))
+	assert.Equal(t, day3, other.Results[0].Vulnerabilities[0].Tracking.FirstSeen)
+
+	histories, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, histories, 2)
+	assert.Equal(t, "alpine:3.15", histories[0].Target)
+	assert.Equal(t, "alpine:3.16", histories[1].Target)
+}
+
+func TestStore_Load(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+
+	t.Run("never scanned", func(t *testing.T) {
+		h, err := s.Load("alpine:3.15")
+		require.NoError(t, err)
+		assert.Equal(t, TargetHistory{Target: "alpine:3.15", Findings: map[string]*Finding{}}, h)
+	})
+
+	t.Run("sad path: broken history", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(s.path("broken"), []byte("{"), 0600))
+		_, err := s.Load("broken")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "JSON decode error")
+	})
+}
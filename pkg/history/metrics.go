@@ -0,0 +1,113 @@
+package history
+
+import (
+	"math"
+	"time"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+// Metrics holds the remediation statistics of the targets
+type Metrics struct {
+	Targets    []string
+	Severities []SeverityMetrics
+	Total      SeverityMetrics
+}
+
+// SeverityMetrics holds the statistics of the findings with the severity. The periods are in days.
+type SeverityMetrics struct {
+	Severity string `json:",omitempty"`
+	Open     int
+	Resolved int
+
+	// MTTRDays is the mean time to remediate, from when the findings were first seen until they were resolved
+	MTTRDays float64
+
+	// MeanOpenAgeDays and MaxOpenAgeDays are how long the open findings have been seen
+	MeanOpenAgeDays float64
+	MaxOpenAgeDays  float64
+}
+
+type aggregator struct {
+	open, resolved       int
+	remediation, openAge time.Duration
+	maxOpenAge           time.Duration
+}
+
+func (a *aggregator) add(f Finding, now time.Time) {
+	if f.Open() {
+		age := now.Sub(f.FirstSeen)
+		a.open++
+		a.openAge += age
+		if age > a.maxOpenAge {
+			a.maxOpenAge = age
+		}
+		return
+	}
+	a.resolved++
+	a.remediation += f.ResolvedAt.Sub(f.FirstSeen)
+}
+
+func (a aggregator) metrics(severity string) SeverityMetrics {
+	m := SeverityMetrics{
+		Severity:       severity,
+		Open:           a.open,
+		Resolved:       a.resolved,
+		MaxOpenAgeDays: days(a.maxOpenAge),
+	}
+	if a.resolved > 0 {
+		m.MTTRDays = days(a.remediation / time.Duration(a.resolved))
+	}
+	if a.open > 0 {
+		m.MeanOpenAgeDays = days(a.openAge / time.Duration(a.open))
+	}
+	return m
+}
+
+// Compute calculates the statistics of the findings with the given severities, from the most severe one.
+// All severities are included if none is given.
+func Compute(histories []TargetHistory, severities []dbTypes.Severity, now time.Time) Metrics {
+	if len(severities) == 0 {
+		for _, s := range dbTypes.SeverityNames {
+			severity, _ := dbTypes.NewSeverity(s) // nolint: errcheck
+			severities = append(severities, severity)
+		}
+	}
+
+	bySeverity := map[dbTypes.Severity]*aggregator{}
+	for _, s := range severities {
+		bySeverity[s] = &aggregator{}
+	}
+
+	var m Metrics
+	var total aggregator
+	for _, h := range histories {
+		m.Targets = append(m.Targets, h.Target)
+		for _, f := range h.Findings {
+			severity, err := dbTypes.NewSeverity(f.Severity)
+			if err != nil {
+				severity = dbTypes.SeverityUnknown
+			}
+			a, ok := bySeverity[severity]
+			if !ok {
+				continue
+			}
+			a.add(*f, now)
+			total.add(*f, now)
+		}
+	}
+
+	for i := len(dbTypes.SeverityNames) - 1; i >= 0; i-- {
+		severity := dbTypes.Severity(i)
+		if a, ok := bySeverity[severity]; ok {
+			m.Severities = append(m.Severities, a.metrics(severity.String()))
+		}
+	}
+	m.Total = total.metrics("")
+	return m
+}
+
+// days returns the period in days rounded to one decimal place
+func days(d time.Duration) float64 {
+	return math.Round(d.Hours()/24*10) / 10
+}
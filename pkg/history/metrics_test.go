@@ -0,0 +1,130 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time {
+		return now.Add(-time.Duration(n) * 24 * time.Hour)
+	}
+	resolved := func(n int) *time.Time {
+		t := daysAgo(n)
+		return &t
+	}
+
+	histories := []TargetHistory{
+		{
+			Target: "alpine:3.15",
+			Findings: map[string]*Finding{
+				"a": {ID: "CVE-2022-0001", Severity: "CRITICAL", FirstSeen: daysAgo(20), ResolvedAt: resolved(16)},
+				"b": {ID: "CVE-2022-0002", Severity: "CRITICAL", FirstSeen: daysAgo(10), ResolvedAt: resolved(8)},
+				"c": {ID: "CVE-2022-0003", Severity: "CRITICAL", FirstSeen: daysAgo(5)},
+				"d": {ID: "CVE-2022-0004", Severity: "LOW", FirstSeen: daysAgo(30)},
+			},
+		},
+		{
+			Target: "myapp:1.0",
+			Findings: map[string]*Finding{
+				"e": {ID: "DS002", Severity: "HIGH", FirstSeen: daysAgo(3)},
+				"f": {ID: "CVE-2022-0005", Severity: "", FirstSeen: daysAgo(1)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		severities []dbTypes.Severity
+		want       Metrics
+	}{
+		{
+			name: "all severities",
+			want: Metrics{
+				Targets: []string{"alpine:3.15", "myapp:1.0"},
+				Severities: []SeverityMetrics{
+					{Severity: "CRITICAL", Open: 1, Resolved: 2, MTTRDays: 3, MeanOpenAgeDays: 5, MaxOpenAgeDays: 5},
+					{Severity: "HIGH", Open: 1, MeanOpenAgeDays: 3, MaxOpenAgeDays: 3},
+					{Severity: "MEDIUM"},
+					{Severity: "LOW", Open: 1, MeanOpenAgeDays: 30, MaxOpenAgeDays: 30},
+					{Severity: "UNKNOWN", Open: 1, MeanOpenAgeDays: 1, MaxOpenAgeDays: 1},
+				},
+				Total: SeverityMetrics{Open: 4, Resolved: 2, MTTRDays: 3, MeanOpenAgeDays: 9.8, MaxOpenAgeDays: 30},
+			},
+		},
+		{
+			name:       "only critical",
+			severities: []dbTypes.Severity{dbTypes.SeverityCritical},
+			want: Metrics{
+				Targets: []string{"alpine:3.15", "myapp:1.0"},
+				Severities: []SeverityMetrics{
+					{Severity: "CRITICAL", Open: 1, Resolved: 2, MTTRDays: 3, MeanOpenAgeDays: 5, MaxOpenAgeDays: 5},
+				},
+				Total: SeverityMetrics{Open: 1, Resolved: 2, MTTRDays: 3, MeanOpenAgeDays: 5, MaxOpenAgeDays: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(histories, tt.severities, now)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	m := Metrics{
+		Targets: []string{"alpine:3.15"},
+		Severities: []SeverityMetrics{
+			{Severity: "CRITICAL", Open: 1, Resolved: 2, MTTRDays: 3, MeanOpenAgeDays: 5, MaxOpenAgeDays: 5},
+		},
+		Total: SeverityMetrics{Open: 1, Resolved: 2, MTTRDays: 3, MeanOpenAgeDays: 5, MaxOpenAgeDays: 5},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "table",
+			format: "table",
+			want: `Targets: 1
+
+┌──────────┬──────┬──────────┬──────┬───────────────┬──────────────┐
+│ Severity │ Open │ Resolved │ MTTR │ Mean Open Age │ Max Open Age │
+├──────────┼──────┼──────────┼──────┼───────────────┼──────────────┤
+│ CRITICAL │ 1    │ 2        │ 3.0d │ 5.0d          │ 5.0d         │
+├──────────┼──────┼──────────┼──────┼───────────────┼──────────────┤
+│ TOTAL    │ 1    │ 2        │ 3.0d │ 5.0d          │ 5.0d         │
+└──────────┴──────┴──────────┴──────┴───────────────┴──────────────┘
+`,
+		},
+		{
+			name:    "sad path: unknown format",
+			format:  "sarif",
+			wantErr: "unsupported format for metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteMetrics(&buf, m, tt.format)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/table"
+)
+
+// WriteMetrics writes the metrics in the given format
+func WriteMetrics(w io.Writer, m Metrics, format string) error {
+	switch format {
+	case "table":
+		writeTable(w, m)
+		return nil
+	case "json":
+		output, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return xerrors.Errorf("failed to marshal json: %w", err)
+		}
+		if _, err = fmt.Fprintln(w, string(output)); err != nil {
+			return xerrors.Errorf("failed to write json: %w", err)
+		}
+		return nil
+	default:
+		return xerrors.Errorf("unsupported format for metrics: %s", format)
+	}
+}
+
+func writeTable(w io.Writer, m Metrics) {
+	_, _ = fmt.Fprintf(w, "Targets: %d\n\n", len(m.Targets))
+
+	t := table.New(w)
+	t.SetBorders(true)
+	t.SetRowLines(true)
+	t.SetHeaders("Severity", "Open", "Resolved", "MTTR", "Mean Open Age", "Max Open Age")
+	for _, s := range append(m.Severities, m.Total) {
+		severity := s.Severity
+		if severity == "" {
+			severity = "TOTAL"
+		}
+		t.AddRow(severity, strconv.Itoa(s.Open), strconv.Itoa(s.Resolved),
+			period(s.MTTRDays, s.Resolved), period(s.MeanOpenAgeDays, s.Open), period(s.MaxOpenAgeDays, s.Open))
+	}
+	t.Render()
+}
+
+// period shows the days, or "-" when no finding is counted
+func period(days float64, n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(days, 'f', 1, 64) + "d"
+}
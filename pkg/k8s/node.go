@@ -0,0 +1,291 @@
+package k8s
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const (
+	kindNode = "Node"
+
+	// The vulnerabilities of the kubelet are those of k8s.io/kubernetes
+	kubeletTarget = "kubelet"
+	kubernetesPkg = "k8s.io/kubernetes"
+
+	// nodeCollectorLabel is set to the jobs of the node collector, so that the leftovers can be found and deleted
+	nodeCollectorLabel = "app.kubernetes.io/managed-by"
+	nodeCollectorName  = "trivy-node-collector"
+
+	nodeCollectorHostPath = "/host"
+
+	// The jobs are deleted by Trivy, and by the TTL controller if Trivy is killed before deleting them
+	nodeCollectorDeadline = 5 * time.Minute
+	nodeCollectorTTL      = 10 * time.Minute
+	podPollInterval       = 2 * time.Second
+)
+
+// nodeCollectorFiles are the files of the OS and the package databases read by the OS analyzers
+var nodeCollectorFiles = []string{
+	"etc/os-release",
+	"usr/lib/os-release",
+	"etc/lsb-release",
+	"etc/alpine-release",
+	"etc/debian_version",
+	"etc/redhat-release",
+	"etc/system-release",
+	"etc/centos-release",
+	"etc/oracle-release",
+	"lib/apk/db/installed",
+	"var/lib/dpkg/status",
+	"var/lib/dpkg/status.d",
+	"var/lib/rpm",
+	"usr/lib/sysimage/rpm",
+}
+
+// nodeCollectorScript writes the files of the host to stdout as a base64-encoded tar.gz, following the symbolic links
+// such as /etc/os-release, so that the pod logs have the inventory of the node. The errors are discarded not to be
+// mixed into the logs.
+func nodeCollectorScript() string {
+	return "cd " + nodeCollectorHostPath + ` && tar -czhf - $(for f in ` + strings.Join(nodeCollectorFiles, " ") +
+		`; do [ -e "$f" ] && echo "$f"; done) 2>/dev/null | base64`
+}
+
+// NodeInfo is the versions of the node components reported by the kubelet
+type NodeInfo struct {
+	OSImage                 string `json:",omitempty"`
+	KernelVersion           string `json:",omitempty"`
+	KubeletVersion          string `json:",omitempty"`
+	ContainerRuntimeVersion string `json:",omitempty"`
+}
+
+func newNodeInfo(node corev1.Node) *NodeInfo {
+	info := node.Status.NodeInfo
+	return &NodeInfo{
+		OSImage:                 info.OSImage,
+		KernelVersion:           info.KernelVersion,
+		KubeletVersion:          info.KubeletVersion,
+		ContainerRuntimeVersion: info.ContainerRuntimeVersion,
+	}
+}
+
+// nodeCollector launches a short-lived job on each node to gather the package inventory of the node
+type nodeCollector struct {
+	client    kubernetes.Interface
+	namespace string
+	image     string
+}
+
+func newNodeCollector(namespace, image string) (*nodeCollector, error) {
+	config, err := genericclioptions.NewConfigFlags(true).ToRESTConfig()
+	if err != nil {
+		return nil, xerrors.Errorf("kubeconfig error: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, xerrors.Errorf("k8s client error: %w", err)
+	}
+	return &nodeCollector{
+		client:    client,
+		namespace: namespace,
+		image:     image,
+	}, nil
+}
+
+func (c *nodeCollector) nodes(ctx context.Context) ([]corev1.Node, error) {
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("unable to list nodes: %w", err)
+	}
+	return nodes.Items, nil
+}
+
+// collect runs the job on the node and extracts the inventory into dir
+func (c *nodeCollector) collect(ctx context.Context, nodeName, dir string) error {
+	job, err := c.client.BatchV1().Jobs(c.namespace).Create(ctx, nodeCollectorJob(nodeName, c.image), metav1.CreateOptions{})
+	if err != nil {
+		return xerrors.Errorf("unable to create the job: %w", err)
+	}
+	defer c.deleteJob(job.Name)
+
+	pod, err := c.waitForPod(ctx, job.Name)
+	if err != nil {
+		return xerrors.Errorf("node collector error (%s/%s): %w", c.namespace, job.Name, err)
+	}
+
+	// The logs are followed as the kubelet may rotate them while the pod is writing the inventory
+	logs, err := c.client.CoreV1().Pods(c.namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return xerrors.Errorf("unable to read the logs of %s/%s: %w", c.namespace, pod.Name, err)
+	}
+	defer logs.Close()
+
+	if err = extractInventory(base64.NewDecoder(base64.StdEncoding, logs), dir); err != nil {
+		return xerrors.Errorf("inventory error (%s/%s): %w", c.namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// waitForPod returns the pod of the job once it has started
+func (c *nodeCollector) waitForPod(ctx context.Context, jobName string) (*corev1.Pod, error) {
+	ticker := time.NewTicker(podPollInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := c.client.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+		if err != nil {
+			return nil, xerrors.Errorf("unable to list pods: %w", err)
+		}
+		for _, pod := range pods.Items {
+			switch pod.Status.Phase {
+			case corev1.PodRunning, corev1.PodSucceeded:
+				return &pod, nil
+			case corev1.PodFailed:
+				return nil, xerrors.Errorf("pod %s failed: %s", pod.Name, pod.Status.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *nodeCollector) deleteJob(name string) {
+	// The job is deleted even if the context is canceled
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	propagation := metav1.DeletePropagationBackground
+	err := c.client.BatchV1().Jobs(c.namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil {
+		log.Logger.Warnf("Unable to delete the job %s/%s: %s", c.namespace, name, err)
+	}
+}
+
+// nodeCollectorJob returns the job mounting the root filesystem of the node read-only, which is scheduled on the node
+// regardless of the taints
+func nodeCollectorJob(nodeName, image string) *batchv1.Job {
+	backoffLimit := int32(0)
+	deadline := int64(nodeCollectorDeadline.Seconds())
+	ttl := int32(nodeCollectorTTL.Seconds())
+	runAsUser := int64(0)
+	readOnly, noEscalation, noToken := true, false, false
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: nodeCollectorName + "-",
+			Labels:       map[string]string{nodeCollectorLabel: nodeCollectorName},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   &deadline,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{nodeCollectorLabel: nodeCollectorName},
+				},
+				Spec: corev1.PodSpec{
+					NodeName:                     nodeName,
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: &noToken,
+					Tolerations:                  []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{{
+						Name:    "collector",
+						Image:   image,
+						Command: []string{"sh", "-c", nodeCollectorScript()},
+						SecurityContext: &corev1.SecurityContext{
+							RunAsUser:                &runAsUser,
+							ReadOnlyRootFilesystem:   &readOnly,
+							AllowPrivilegeEscalation: &noEscalation,
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("100m"),
+								corev1.ResourceMemory: resource.MustParse("100Mi"),
+							},
+						},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "host",
+							MountPath: nodeCollectorHostPath,
+							ReadOnly:  true,
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "host",
+						VolumeSource: corev1.VolumeSource{
+							HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// extractInventory extracts the regular files of the tar.gz into dir. The paths are kept within dir.
+func extractInventory(r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return xerrors.Errorf("no package databases found: %w", err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("tar error: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.Clean("/"+hdr.Name))
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return xerrors.Errorf("mkdir error: %w", err)
+		}
+		if err = writeFile(path, tr); err != nil {
+			return xerrors.Errorf("unable to write %s: %w", hdr.Name, err)
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// kubeletVersion returns the upstream version of the kubelet, e.g. "v1.23.6" of "v1.23.6-eks-7d68063" and "v1.23.6+k3s1"
+func kubeletVersion(version string) string {
+	if i := strings.IndexAny(version, "-+"); i > 0 {
+		return version[:i]
+	}
+	return version
+}
@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_nodeCollectorJob(t *testing.T) {
+	job := nodeCollectorJob("worker-1", "alpine:3.16")
+
+	assert.Equal(t, nodeCollectorName, job.Labels[nodeCollectorLabel])
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, "worker-1", pod.NodeName)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.RestartPolicy)
+	assert.Equal(t, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}, pod.Tolerations)
+	assert.False(t, *pod.AutomountServiceAccountToken)
+
+	require.Len(t, pod.Containers, 1)
+	container := pod.Containers[0]
+	assert.Equal(t, "alpine:3.16", container.Image)
+	assert.Contains(t, container.Command[2], "var/lib/dpkg/status")
+	assert.True(t, *container.SecurityContext.ReadOnlyRootFilesystem)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "host", MountPath: "/host", ReadOnly: true}}, container.VolumeMounts)
+	assert.Equal(t, "/", pod.Volumes[0].HostPath.Path)
+}
+
+func Test_extractInventory(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range []struct {
+		name     string
+		typeflag byte
+		content  string
+	}{
+		{name: "etc/os-release", typeflag: tar.TypeReg, content: "ID=ubuntu\nVERSION_ID=\"22.04\"\n"},
+		{name: "var/lib/dpkg/", typeflag: tar.TypeDir},
+		{name: "var/lib/dpkg/status", typeflag: tar.TypeReg, content: "Package: bash\n"},
+		{name: "../../etc/passwd", typeflag: tar.TypeReg, content: "root:x:0:0::/root:/bin/sh\n"},
+		{name: "etc/shadow", typeflag: tar.TypeSymlink},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     f.name,
+			Typeflag: f.typeflag,
+			Mode:     0644,
+			Size:     int64(len(f.content)),
+		}))
+		_, err := tw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	// base64 of busybox wraps the lines at 76 characters
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	var logs strings.Builder
+	for len(encoded) > 76 {
+		logs.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	logs.WriteString(encoded + "\n")
+
+	dir := t.TempDir()
+	err := extractInventory(base64.NewDecoder(base64.StdEncoding, strings.NewReader(logs.String())), dir)
+	require.NoError(t, err)
+
+	var got []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			rel, _ := filepath.Rel(dir, path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"etc/os-release", "etc/passwd", "var/lib/dpkg/status"}, got)
+
+	b, err := os.ReadFile(filepath.Join(dir, "var/lib/dpkg/status"))
+	require.NoError(t, err)
+	assert.Equal(t, "Package: bash\n", string(b))
+}
+
+func Test_extractInventory_empty(t *testing.T) {
+	err := extractInventory(strings.NewReader(""), t.TempDir())
+	assert.ErrorContains(t, err, "no package databases found")
+}
+
+func Test_nodeCollector_waitForPod(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   corev1.PodPhase
+		message string
+		wantErr string
+	}{
+		{
+			name:  "succeeded",
+			phase: corev1.PodSucceeded,
+		},
+		{
+			name:  "running",
+			phase: corev1.PodRunning,
+		},
+		{
+			name:    "failed",
+			phase:   corev1.PodFailed,
+			message: "Pod was active on the node longer than the specified deadline",
+			wantErr: "pod trivy-node-collector-abcde-xyz failed: Pod was active on the node longer than the specified deadline",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "trivy-node-collector-abcde-xyz",
+					Namespace: "trivy",
+					Labels:    map[string]string{"job-name": "trivy-node-collector-abcde"},
+				},
+				Status: corev1.PodStatus{Phase: tt.phase, Message: tt.message},
+			})
+			c := &nodeCollector{client: client, namespace: "trivy"}
+
+			got, err := c.waitForPod(context.Background(), "trivy-node-collector-abcde")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "trivy-node-collector-abcde-xyz", got.Name)
+		})
+	}
+}
+
+func Test_kubeletVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "v1.23.6", want: "v1.23.6"},
+		{version: "v1.22.9-eks-810597c", want: "v1.22.9"},
+		{version: "v1.24.3+k3s1", want: "v1.24.3"},
+		{version: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, kubeletVersion(tt.version))
+		})
+	}
+}
@@ -55,6 +55,9 @@ type Resource struct {
 	Results types.Results `json:",omitempty"`
 	Error   string        `json:",omitempty"`
 
+	// NodeInfo is filled only for the nodes scanned with '--node-collector'
+	NodeInfo *NodeInfo `json:",omitempty"`
+
 	// original report
 	Report types.Report `json:"-"`
 }
@@ -65,14 +68,20 @@ func (r Resource) fullname() string {
 
 // Failed returns whether the k8s report includes any vulnerabilities or misconfigurations
 func (r Report) Failed() bool {
+	return r.FailedBy(types.FailCondition{})
+}
+
+// FailedBy returns whether the k8s report includes any vulnerabilities or misconfigurations
+// satisfying the given condition
+func (r Report) FailedBy(cond types.FailCondition) bool {
 	for _, r := range r.Vulnerabilities {
-		if r.Results.Failed() {
+		if r.Results.FailedBy(cond) {
 			return true
 		}
 	}
 
 	for _, r := range r.Misconfigurations {
-		if r.Results.Failed() {
+		if r.Results.FailedBy(cond) {
 			return true
 		}
 	}
@@ -102,6 +111,7 @@ func (r Report) consolidate() ConsolidatedReport {
 				Name:      r.Name,
 				Results:   append(r.Results, v.Results...),
 				Error:     r.Error,
+				NodeInfo:  v.NodeInfo,
 			}
 
 			continue
@@ -115,6 +125,21 @@ func (r Report) consolidate() ConsolidatedReport {
 	return consolidated
 }
 
+// results returns the results of all the resources with the resources as the targets, e.g. "default/Deployment/nginx"
+func (r Report) results() types.Results {
+	var results types.Results
+	for _, resources := range [][]Resource{r.Vulnerabilities, r.Misconfigurations} {
+		for _, resource := range resources {
+			target := strings.TrimPrefix(fmt.Sprintf("%s/%s/%s", resource.Namespace, resource.Kind, resource.Name), "/")
+			for _, result := range resource.Results {
+				result.Target = target
+				results = append(results, result)
+			}
+		}
+	}
+	return results
+}
+
 // Writer defines the result write operation
 type Writer interface {
 	Write(Report) error
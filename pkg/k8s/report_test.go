@@ -112,6 +112,32 @@ func TestReport_consolidate(t *testing.T) {
 	}
 }
 
+func TestReport_results(t *testing.T) {
+	report := Report{
+		Vulnerabilities:   []Resource{deployOrionWithVulns, cronjobHelloWithVulns},
+		Misconfigurations: []Resource{deployOrionWithMisconfigs},
+	}
+
+	want := types.Results{
+		{
+			Target:          "default/Deploy/orion",
+			Vulnerabilities: []types.DetectedVulnerability{{VulnerabilityID: "CVE-2020-8888"}},
+		},
+		{
+			Target:          "default/Cronjob/hello",
+			Vulnerabilities: []types.DetectedVulnerability{{VulnerabilityID: "CVE-2020-9999"}},
+		},
+		{
+			Target:            "default/Deploy/orion",
+			Misconfigurations: []types.DetectedMisconfiguration{{ID: "ID100", Status: types.StatusFailure}},
+		},
+	}
+	assert.Equal(t, want, report.results())
+
+	// The results of the resources are not modified
+	assert.Empty(t, deployOrionWithVulns.Results[0].Target)
+}
+
 func TestResource_fullname(t *testing.T) {
 	tests := []struct {
 		expected string
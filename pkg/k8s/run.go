@@ -5,11 +5,15 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	cmd "github.com/aquasecurity/trivy/pkg/commands/artifact"
+	"github.com/aquasecurity/trivy/pkg/compliance"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
 
 	"github.com/aquasecurity/trivy-kubernetes/pkg/artifacts"
 	"github.com/aquasecurity/trivy-kubernetes/pkg/k8s"
@@ -37,7 +41,10 @@ func Run(cliCtx *cli.Context) error {
 	// Single resource scanning is allowed with implicit "--report all".
 	//
 	// e.g. $ trivy k8s pod myapp
+	//
+	// The compliance report is a summary of the controls regardless of "--report".
 	if cliCtx.String("report") == allReport &&
+		cliCtx.String("compliance") == "" &&
 		!cliCtx.IsSet("report") &&
 		cliCtx.String("format") == tableFormat &&
 		!cliCtx.Args().Present() {
@@ -56,6 +63,9 @@ func Run(cliCtx *cli.Context) error {
 		}
 	}()
 
+	// The ID is added to the JSON logs so that the logs of the scan can be correlated
+	log.SetScanID(uuid.NewString())
+
 	runner, err := cmd.NewRunner(opt)
 	if err != nil {
 		if errors.Is(err, cmd.SkipScan) {
@@ -86,6 +96,16 @@ func Run(cliCtx *cli.Context) error {
 		opt:     opt,
 	}
 
+	// The nodes are scanned with the inventories gathered by the jobs
+	if opt.KubernetesOption.NodeCollector && slices.Contains(opt.SecurityChecks, types.SecurityCheckVulnerability) {
+		if s.collector, err = newNodeCollector(opt.KubernetesOption.NodeCollectorNamespace, opt.KubernetesOption.NodeCollectorImage); err != nil {
+			return xerrors.Errorf("node collector error: %w", err)
+		}
+		if s.nodes, err = s.collector.nodes(ctx); err != nil {
+			return xerrors.Errorf("node collector error: %w", err)
+		}
+	}
+
 	return run(ctx, s, opt, artifacts)
 }
 
@@ -95,7 +115,15 @@ func run(ctx context.Context, s *scanner, opt cmd.Option, artifacts []*artifacts
 		return xerrors.Errorf("k8s scan error: %w", err)
 	}
 
-	if err = write(report, Option{
+	if opt.Compliance != "" {
+		complianceReport := compliance.BuildReport(opt.ComplianceSpec, report.results(), opt.SecurityChecks)
+		if err = compliance.Write(complianceReport, compliance.Option{
+			Format: opt.Format,
+			Output: opt.Output,
+		}); err != nil {
+			return xerrors.Errorf("unable to write the compliance report: %w", err)
+		}
+	} else if err = write(report, Option{
 		Format:     opt.Format,
 		Report:     opt.KubernetesOption.ReportFormat,
 		Output:     opt.Output,
@@ -104,7 +132,7 @@ func run(ctx context.Context, s *scanner, opt cmd.Option, artifacts []*artifacts
 		return xerrors.Errorf("unable to write results: %w", err)
 	}
 
-	cmd.Exit(opt, report.Failed())
+	cmd.Exit(opt, report.FailedBy(opt.FailCondition()))
 
 	return nil
 }
@@ -3,13 +3,19 @@ package k8s
 import (
 	"context"
 	"io"
+	"os"
+	"strings"
 
 	"github.com/cheggaaa/pb/v3"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
 
+	ftypes "github.com/aquasecurity/fanal/types"
 	cmd "github.com/aquasecurity/trivy/pkg/commands/artifact"
+	"github.com/aquasecurity/trivy/pkg/detector/library"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/types"
 
 	"github.com/aquasecurity/trivy-kubernetes/pkg/artifacts"
@@ -19,15 +25,20 @@ type scanner struct {
 	cluster string
 	runner  *cmd.Runner
 	opt     cmd.Option
+
+	// collector gathers the inventories of the nodes if '--node-collector' is specified
+	collector *nodeCollector
+	nodes     []corev1.Node
 }
 
 func (s *scanner) run(ctx context.Context, artifacts []*artifacts.Artifact) (Report, error) {
 	// progress bar
-	bar := pb.StartNew(len(artifacts))
+	bar := pb.StartNew(len(artifacts) + len(s.nodes))
 	if s.opt.NoProgress {
 		bar.SetWriter(io.Discard)
 	}
 	defer bar.Finish()
+	tracker := progress.Start(progress.PhaseResourceScan, s.cluster, int64(len(artifacts)+len(s.nodes)))
 
 	var vulns, misconfigs []Resource
 
@@ -57,8 +68,20 @@ func (s *scanner) run(ctx context.Context, artifacts []*artifacts.Artifact) (Rep
 			}
 			misconfigs = append(misconfigs, resource)
 		}
+		tracker.Add(1, "")
 	}
 
+	for _, node := range s.nodes {
+		bar.Increment()
+		resource, err := s.scanNode(ctx, node)
+		if err != nil {
+			return Report{}, xerrors.Errorf("scanning node error: %w", err)
+		}
+		vulns = append(vulns, resource)
+		tracker.Add(1, "")
+	}
+	tracker.Finish()
+
 	// enable logs after scanning
 	err = log.InitLogger(s.opt.Debug, s.opt.Quiet)
 	if err != nil {
@@ -126,3 +149,77 @@ func (s *scanner) filter(ctx context.Context, report types.Report, artifact *art
 
 	return createResource(artifact, report, nil), nil
 }
+
+// scanNode scans the inventory of the node gathered by the node collector and the version of the kubelet.
+// The errors of the node collector are reported in the resource as well as those of the image scanning.
+func (s *scanner) scanNode(ctx context.Context, node corev1.Node) (Resource, error) {
+	artifact := &artifacts.Artifact{Kind: kindNode, Name: node.Name}
+
+	dir, err := os.MkdirTemp("", "trivy-node-*")
+	if err != nil {
+		return Resource{}, xerrors.Errorf("failed to create a temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = s.collector.collect(ctx, node.Name, dir); err != nil {
+		log.Logger.Debugf("failed to collect node %s: %s", node.Name, err)
+		return nodeResource(artifact, node, types.Report{}, err), nil
+	}
+
+	s.opt.Target = dir
+	report, err := s.runner.ScanRootfs(ctx, s.opt)
+	if err != nil {
+		log.Logger.Debugf("failed to scan node %s: %s", node.Name, err)
+		return nodeResource(artifact, node, report, err), nil
+	}
+
+	// The temp dir is replaced with the node, e.g. "worker-1 (ubuntu 22.04)"
+	report.ArtifactName = node.Name
+	for i := range report.Results {
+		report.Results[i].Target = strings.Replace(report.Results[i].Target, dir, node.Name, 1)
+	}
+
+	kubelet, err := s.scanKubelet(node)
+	if err != nil {
+		return Resource{}, xerrors.Errorf("kubelet error: %w", err)
+	} else if kubelet != nil {
+		report.Results = append(report.Results, *kubelet)
+	}
+
+	report, err = s.runner.Filter(ctx, s.opt, report)
+	if err != nil {
+		return Resource{}, xerrors.Errorf("filter error: %w", err)
+	}
+	return nodeResource(artifact, node, report, nil), nil
+}
+
+// scanKubelet detects the vulnerabilities of the kubelet with the advisories of k8s.io/kubernetes
+func (s *scanner) scanKubelet(node corev1.Node) (*types.Result, error) {
+	version := kubeletVersion(node.Status.NodeInfo.KubeletVersion)
+	if version == "" || !slices.Contains(s.opt.VulnType, types.VulnTypeLibrary) {
+		return nil, nil
+	}
+
+	pkgs := []ftypes.Package{{Name: kubernetesPkg, Version: version}}
+	vulns, err := library.Detect(ftypes.GoBinary, pkgs)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to detect kubelet vulnerabilities: %w", err)
+	}
+
+	result := &types.Result{
+		Target:          kubeletTarget,
+		Class:           types.ClassLangPkg,
+		Type:            ftypes.GoBinary,
+		Vulnerabilities: vulns,
+	}
+	if s.opt.ListAllPkgs {
+		result.Packages = types.NewPackages(pkgs)
+	}
+	return result, nil
+}
+
+func nodeResource(artifact *artifacts.Artifact, node corev1.Node, report types.Report, err error) Resource {
+	resource := createResource(artifact, report, err)
+	resource.NodeInfo = newNodeInfo(node)
+	return resource
+}
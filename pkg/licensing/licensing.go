@@ -0,0 +1,265 @@
+package licensing
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+// Category is the category of licenses by the restrictions on using them, following google/licenseclassifier
+type Category string
+
+const (
+	CategoryForbidden    Category = "forbidden"
+	CategoryRestricted   Category = "restricted"
+	CategoryReciprocal   Category = "reciprocal"
+	CategoryNotice       Category = "notice"
+	CategoryPermissive   Category = "permissive"
+	CategoryUnencumbered Category = "unencumbered"
+	CategoryUnknown      Category = "unknown"
+)
+
+var defaultSeverities = map[Category]dbTypes.Severity{
+	CategoryForbidden:    dbTypes.SeverityCritical,
+	CategoryRestricted:   dbTypes.SeverityHigh,
+	CategoryReciprocal:   dbTypes.SeverityMedium,
+	CategoryNotice:       dbTypes.SeverityLow,
+	CategoryPermissive:   dbTypes.SeverityLow,
+	CategoryUnencumbered: dbTypes.SeverityLow,
+	CategoryUnknown:      dbTypes.SeverityUnknown,
+}
+
+// defaultCategories has the SPDX identifiers of the licenses in each category.
+// The versions without "-only" and "-or-later" cover them as well.
+var defaultCategories = map[Category][]string{
+	CategoryForbidden: {
+		"AGPL-1.0", "AGPL-3.0", "CC-BY-NC-1.0", "CC-BY-NC-2.0", "CC-BY-NC-2.5", "CC-BY-NC-3.0", "CC-BY-NC-4.0",
+		"CC-BY-NC-ND-1.0", "CC-BY-NC-ND-2.0", "CC-BY-NC-ND-2.5", "CC-BY-NC-ND-3.0", "CC-BY-NC-ND-4.0",
+		"CC-BY-NC-SA-1.0", "CC-BY-NC-SA-2.0", "CC-BY-NC-SA-2.5", "CC-BY-NC-SA-3.0", "CC-BY-NC-SA-4.0",
+		"Commons-Clause", "Facebook-2-Clause", "Facebook-3-Clause", "Facebook-Examples", "SSPL-1.0", "WTFPL",
+	},
+	CategoryRestricted: {
+		"BCL", "CC-BY-ND-1.0", "CC-BY-ND-2.0", "CC-BY-ND-2.5", "CC-BY-ND-3.0", "CC-BY-ND-4.0",
+		"CC-BY-SA-1.0", "CC-BY-SA-2.0", "CC-BY-SA-2.5", "CC-BY-SA-3.0", "CC-BY-SA-4.0",
+		"GPL-1.0", "GPL-2.0", "GPL-2.0-with-autoconf-exception", "GPL-2.0-with-bison-exception",
+		"GPL-2.0-with-classpath-exception", "GPL-2.0-with-font-exception", "GPL-2.0-with-GCC-exception",
+		"GPL-3.0", "GPL-3.0-with-autoconf-exception", "GPL-3.0-with-GCC-exception",
+		"LGPL-2.0", "LGPL-2.1", "LGPL-3.0", "NPL-1.0", "NPL-1.1", "OSL-1.0", "OSL-1.1", "OSL-2.0", "OSL-2.1", "OSL-3.0",
+		"QPL-1.0", "Sleepycat",
+	},
+	CategoryReciprocal: {
+		"APSL-1.0", "APSL-1.1", "APSL-1.2", "APSL-2.0", "CDDL-1.0", "CDDL-1.1", "CPAL-1.0", "CPL-1.0",
+		"EPL-1.0", "EPL-2.0", "FreeImage", "IPL-1.0", "MPL-1.0", "MPL-1.1", "MPL-2.0", "Ruby",
+	},
+	CategoryNotice: {
+		"AFL-1.1", "AFL-1.2", "AFL-2.0", "AFL-2.1", "AFL-3.0", "Apache-1.0", "Apache-1.1", "Apache-2.0",
+		"Artistic-1.0", "Artistic-1.0-cl8", "Artistic-1.0-Perl", "Artistic-2.0", "BSL-1.0",
+		"BSD-1-Clause", "BSD-2-Clause", "BSD-2-Clause-FreeBSD", "BSD-2-Clause-NetBSD", "BSD-3-Clause",
+		"BSD-3-Clause-Attribution", "BSD-3-Clause-Clear", "BSD-3-Clause-LBNL", "BSD-4-Clause", "BSD-4-Clause-UC",
+		"BSD-Protection", "CC-BY-1.0", "CC-BY-2.0", "CC-BY-2.5", "CC-BY-3.0", "CC-BY-4.0", "FTL", "ISC",
+		"ImageMagick", "Libpng", "Lil-1.0", "Linux-OpenIB", "LPL-1.0", "LPL-1.02", "MIT", "MS-PL", "NCSA",
+		"OpenSSL", "PHP-3.0", "PHP-3.01", "PIL", "Python-2.0", "SGI-B-2.0", "Unicode-DFS-2015", "Unicode-DFS-2016",
+		"UPL-1.0", "W3C", "X11", "Xnet", "Zend-2.0", "Zlib", "ZPL-2.0", "ZPL-2.1",
+	},
+	CategoryUnencumbered: {
+		"0BSD", "CC0-1.0", "Unlicense",
+	},
+}
+
+// aliases are the names of the licenses written by package managers other than the SPDX identifiers
+var aliases = map[string]string{
+	"AGPLV3":             "AGPL-3.0",
+	"APACHE":             "Apache-2.0",
+	"APACHE 2":           "Apache-2.0",
+	"APACHE 2.0":         "Apache-2.0",
+	"APACHE LICENSE 2.0": "Apache-2.0",
+	"APACHE-2":           "Apache-2.0",
+	"APACHEV2":           "Apache-2.0",
+	"ASL 2.0":            "Apache-2.0",
+	"BOOST":              "BSL-1.0",
+	"BSD":                "BSD-3-Clause",
+	"BSD-2":              "BSD-2-Clause",
+	"BSD-3":              "BSD-3-Clause",
+	"GPL":                "GPL-2.0",
+	"GPL2":               "GPL-2.0",
+	"GPLV2":              "GPL-2.0",
+	"GPL3":               "GPL-3.0",
+	"GPLV3":              "GPL-3.0",
+	"LGPL":               "LGPL-2.0",
+	"LGPL2":              "LGPL-2.0",
+	"LGPLV2":             "LGPL-2.0",
+	"LGPLV2.1":           "LGPL-2.1",
+	"LGPL3":              "LGPL-3.0",
+	"LGPLV3":             "LGPL-3.0",
+	"MIT LICENSE":        "MIT",
+	"MPLV2.0":            "MPL-2.0",
+	"PUBLIC DOMAIN":      "Unlicense",
+	"ZLIB":               "Zlib",
+}
+
+// Config is the license policy, which is read from the YAML file, e.g.
+//
+//	forbidden:
+//	  - GPL-3.0
+//	ignored:
+//	  - LicenseRef-Acme-Proprietary
+//	severities:
+//	  restricted: CRITICAL
+type Config struct {
+	// The licenses listed in the categories are moved from the default categories
+	Forbidden    []string `yaml:"forbidden"`
+	Restricted   []string `yaml:"restricted"`
+	Reciprocal   []string `yaml:"reciprocal"`
+	Notice       []string `yaml:"notice"`
+	Permissive   []string `yaml:"permissive"`
+	Unencumbered []string `yaml:"unencumbered"`
+
+	// Ignored licenses are allowed and never reported, such as the licenses of your organization
+	Ignored []string `yaml:"ignored"`
+
+	// Severities overrides the severities of the categories
+	Severities map[Category]string `yaml:"severities"`
+}
+
+func (c Config) categories() map[Category][]string {
+	return map[Category][]string{
+		CategoryForbidden:    c.Forbidden,
+		CategoryRestricted:   c.Restricted,
+		CategoryReciprocal:   c.Reciprocal,
+		CategoryNotice:       c.Notice,
+		CategoryPermissive:   c.Permissive,
+		CategoryUnencumbered: c.Unencumbered,
+	}
+}
+
+// LoadConfig loads the license policy. The default policy is used if the file doesn't exist.
+func LoadConfig(configPath string) (Config, error) {
+	if configPath == "" {
+		return Config{}, nil
+	}
+
+	b, err := os.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	} else if err != nil {
+		return Config{}, xerrors.Errorf("file read error: %w", err)
+	}
+
+	var config Config
+	if err = yaml.Unmarshal(b, &config); err != nil {
+		return Config{}, xerrors.Errorf("YAML decode error: %w", err)
+	}
+	return config, nil
+}
+
+// Classifier determines the categories and the severities of the licenses
+type Classifier struct {
+	categories map[string]Category
+	ignored    map[string]bool
+	severities map[Category]dbTypes.Severity
+}
+
+// NewClassifier returns the classifier of the licenses listed in the default policy and the config
+func NewClassifier(config Config) (Classifier, error) {
+	c := Classifier{
+		categories: map[string]Category{},
+		ignored:    map[string]bool{},
+		severities: map[Category]dbTypes.Severity{},
+	}
+	for category, licenses := range defaultCategories {
+		for _, license := range licenses {
+			c.categories[normalize(license)] = category
+		}
+	}
+	for category, s := range defaultSeverities {
+		c.severities[category] = s
+	}
+
+	for category, licenses := range config.categories() {
+		for _, license := range licenses {
+			c.categories[normalize(license)] = category
+		}
+	}
+	for _, license := range config.Ignored {
+		c.ignored[normalize(license)] = true
+	}
+	for category, severity := range config.Severities {
+		if _, ok := defaultSeverities[category]; !ok {
+			return Classifier{}, xerrors.Errorf("unknown license category: %s", category)
+		}
+		s, err := dbTypes.NewSeverity(strings.ToUpper(severity))
+		if err != nil {
+			return Classifier{}, xerrors.Errorf("invalid severity %q of %s: %w", severity, category, err)
+		}
+		c.severities[category] = s
+	}
+	return c, nil
+}
+
+// defaultClassifier classifies the licenses with the default policy
+var defaultClassifier, _ = NewClassifier(Config{}) // nolint: errcheck
+
+// Classify returns the category and the severity of the license, and false if the license is ignored
+func (c Classifier) Classify(license string) (Category, dbTypes.Severity, bool) {
+	if c.categories == nil {
+		c = defaultClassifier
+	}
+
+	name := normalize(license)
+	if c.ignored[name] || c.ignored[baseName(name)] {
+		return "", dbTypes.SeverityUnknown, false
+	}
+
+	category, ok := c.categories[name]
+	if !ok {
+		// e.g. GPL-2.0-or-later => GPL-2.0
+		category, ok = c.categories[baseName(name)]
+	}
+	if !ok {
+		category = CategoryUnknown
+	}
+	return category, c.severities[category], true
+}
+
+// normalize returns the SPDX identifier of the license in upper case, so that the names are compared case-insensitively
+func normalize(license string) string {
+	name := strings.ToUpper(strings.Join(strings.Fields(license), " "))
+	if alias, ok := aliases[strings.TrimSuffix(name, "+")]; ok {
+		if strings.HasSuffix(name, "+") {
+			return strings.ToUpper(alias) + "+"
+		}
+		return strings.ToUpper(alias)
+	}
+	return name
+}
+
+var versionSuffix = regexp.MustCompile(`(-ONLY|-OR-LATER|\+)$`)
+
+func baseName(name string) string {
+	return versionSuffix.ReplaceAllString(name, "")
+}
+
+var (
+	expressionSeparator = regexp.MustCompile(`(?i)\s+(?:and|or)\s+|\s*[,;/|]\s*|[()]`)
+	exceptionSeparator  = regexp.MustCompile(`(?i)\s+with\s+`)
+)
+
+// Split splits the license expression of the package into the licenses, e.g. "MIT OR Apache-2.0".
+// The exceptions are dropped, e.g. "GPL-2.0-only WITH Classpath-exception-2.0" => "GPL-2.0-only"
+func Split(expression string) []string {
+	var licenses []string
+	for _, license := range expressionSeparator.Split(expression, -1) {
+		license = exceptionSeparator.Split(license, 2)[0]
+		if license = strings.TrimSpace(license); license != "" {
+			licenses = append(licenses, license)
+		}
+	}
+	return licenses
+}
@@ -0,0 +1,156 @@
+package licensing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/licensing"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	config, err := licensing.LoadConfig("testdata/trivy-license.yaml")
+	require.NoError(t, err)
+	custom, err := licensing.NewClassifier(config)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		classifier   licensing.Classifier
+		license      string
+		wantCategory licensing.Category
+		wantSeverity dbTypes.Severity
+		wantOK       bool
+	}{
+		{
+			name:         "SPDX identifier",
+			license:      "Apache-2.0",
+			wantCategory: licensing.CategoryNotice,
+			wantSeverity: dbTypes.SeverityLow,
+			wantOK:       true,
+		},
+		{
+			name:         "or later",
+			license:      "GPL-3.0-or-later",
+			wantCategory: licensing.CategoryRestricted,
+			wantSeverity: dbTypes.SeverityHigh,
+			wantOK:       true,
+		},
+		{
+			name:         "alias",
+			license:      "GPLv2+",
+			wantCategory: licensing.CategoryRestricted,
+			wantSeverity: dbTypes.SeverityHigh,
+			wantOK:       true,
+		},
+		{
+			name:         "case-insensitive",
+			license:      "wtfpl",
+			wantCategory: licensing.CategoryForbidden,
+			wantSeverity: dbTypes.SeverityCritical,
+			wantOK:       true,
+		},
+		{
+			name:         "unknown",
+			license:      "Acme Public License",
+			wantCategory: licensing.CategoryUnknown,
+			wantSeverity: dbTypes.SeverityUnknown,
+			wantOK:       true,
+		},
+		{
+			name:         "moved to forbidden",
+			classifier:   custom,
+			license:      "GPL-3.0-only",
+			wantCategory: licensing.CategoryForbidden,
+			wantSeverity: dbTypes.SeverityCritical,
+			wantOK:       true,
+		},
+		{
+			name:         "severity overridden",
+			classifier:   custom,
+			license:      "MPL-2.0",
+			wantCategory: licensing.CategoryReciprocal,
+			wantSeverity: dbTypes.SeverityHigh,
+			wantOK:       true,
+		},
+		{
+			name:       "ignored",
+			classifier: custom,
+			license:    "LicenseRef-Acme-Proprietary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, severity, ok := tt.classifier.Classify(tt.license)
+			require.Equal(t, tt.wantOK, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.wantCategory, category)
+			assert.Equal(t, tt.wantSeverity, severity)
+		})
+	}
+}
+
+func TestNewClassifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		wantErr    string
+	}{
+		{
+			name:       "happy path",
+			configPath: "testdata/trivy-license.yaml",
+		},
+		{
+			name:       "no file",
+			configPath: "testdata/unknown.yaml",
+		},
+		{
+			name:       "sad path: invalid severity",
+			configPath: "testdata/invalid-severity.yaml",
+			wantErr:    `invalid severity "SEVERE" of notice`,
+		},
+		{
+			name:       "sad path: unknown category",
+			configPath: "testdata/unknown-category.yaml",
+			wantErr:    "unknown license category: copyleft",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := licensing.LoadConfig(tt.configPath)
+			require.NoError(t, err)
+
+			_, err = licensing.NewClassifier(config)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []string
+	}{
+		{expression: "MIT", want: []string{"MIT"}},
+		{expression: "MIT OR Apache-2.0", want: []string{"MIT", "Apache-2.0"}},
+		{expression: "(MIT AND BSD-3-Clause) OR GPL-2.0-only", want: []string{"MIT", "BSD-3-Clause", "GPL-2.0-only"}},
+		{expression: "GPLv2+ and LGPLv2+", want: []string{"GPLv2+", "LGPLv2+"}},
+		{expression: "GPL-2.0-only WITH Classpath-exception-2.0", want: []string{"GPL-2.0-only"}},
+		{expression: "Apache 2.0, MIT", want: []string{"Apache 2.0", "MIT"}},
+		{expression: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			assert.Equal(t, tt.want, licensing.Split(tt.expression))
+		})
+	}
+}
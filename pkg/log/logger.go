@@ -2,19 +2,37 @@ package log
 
 import (
 	"os"
+	"strings"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	flog "github.com/aquasecurity/fanal/log"
 	dlog "github.com/aquasecurity/go-dep-parser/pkg/log"
 )
 
+const (
+	// FormatConsole writes the logs for humans
+	FormatConsole = "console"
+
+	// FormatJSON writes the logs as JSON lines for log collectors
+	FormatJSON = "json"
+)
+
+// Formats has the values of '--log-format'
+var Formats = []string{FormatConsole, FormatJSON}
+
 var (
 	// Logger is the global variable for logging
 	Logger      *zap.SugaredLogger
 	debugOption bool
+
+	// The output of the loggers created by NewLogger
+	logFormat = FormatConsole
+	logFile   zapcore.WriteSyncer
+	scanID    string
 )
 
 func init() {
@@ -40,6 +58,39 @@ func InitLogger(debug, disable bool) (err error) {
 
 }
 
+// SetOutput sets the format of the logs and the file written instead of stdout and stderr.
+// It is applied to the loggers created after it, and the file is appended to.
+func SetOutput(format, file string) error {
+	if format == "" {
+		format = FormatConsole
+	}
+	if !slices.Contains(Formats, format) {
+		return xerrors.Errorf("unknown log format: %s, supported: %s", format, strings.Join(Formats, ", "))
+	}
+	logFormat = format
+
+	logFile = nil
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return xerrors.Errorf("unable to open the log file: %w", err)
+		}
+		logFile = zapcore.Lock(f)
+	}
+	return nil
+}
+
+// SetScanID sets the ID added to the JSON logs of the loggers created after it,
+// so that the logs of a scan can be correlated with the ones of the server.
+func SetScanID(id string) {
+	scanID = id
+}
+
+// ScanID returns the ID set by SetScanID
+func ScanID() string {
+	return scanID
+}
+
 // NewLogger is the factory method to return the instance of logger
 func NewLogger(debug, disable bool) (*zap.SugaredLogger, error) {
 	// First, define our level-handling logic.
@@ -67,12 +118,23 @@ func NewLogger(debug, disable bool) (*zap.SugaredLogger, error) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
+	// The colors are only for terminals
+	if logFormat == FormatJSON || logFile != nil {
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
+
 	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
+	if logFormat == FormatJSON {
+		consoleEncoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
 
 	// High-priority output should also go to standard error, and low-priority
 	// output should also go to standard out.
 	consoleLogs := zapcore.Lock(os.Stdout)
 	consoleErrors := zapcore.Lock(os.Stderr)
+	if logFile != nil {
+		consoleLogs, consoleErrors = logFile, logFile
+	}
 	if disable {
 		devNull, err := os.Create(os.DevNull)
 		if err != nil {
@@ -93,6 +155,11 @@ func NewLogger(debug, disable bool) (*zap.SugaredLogger, error) {
 	}
 	logger := zap.New(core, opts...)
 
+	// The ID would be noisy on the console
+	if logFormat == FormatJSON && scanID != "" {
+		logger = logger.With(zap.String("ScanID", scanID))
+	}
+
 	return logger.Sugar(), nil
 }
 
@@ -0,0 +1,88 @@
+package log_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		debug   bool
+		scanID  string
+		want    []map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			scanID: "5a5776db-2653-4291-a9b2-1fab64979ab5",
+			want: []map[string]interface{}{
+				{"Level": "INFO", "Msg": "info", "ScanID": "5a5776db-2653-4291-a9b2-1fab64979ab5"},
+				{"Level": "ERROR", "Msg": "error", "ScanID": "5a5776db-2653-4291-a9b2-1fab64979ab5"},
+			},
+		},
+		{
+			name:   "json with debug",
+			format: "json",
+			debug:  true,
+			want: []map[string]interface{}{
+				{"Level": "DEBUG", "Msg": "debug"},
+				{"Level": "INFO", "Msg": "info"},
+				{"Level": "ERROR", "Msg": "error"},
+			},
+		},
+		{
+			name:    "sad path: unknown format",
+			format:  "xml",
+			wantErr: "unknown log format: xml, supported: console, json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logFile := filepath.Join(t.TempDir(), "trivy.log")
+			defer func() {
+				require.NoError(t, log.SetOutput("", ""))
+				log.SetScanID("")
+			}()
+
+			err := log.SetOutput(tt.format, logFile)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			log.SetScanID(tt.scanID)
+
+			logger, err := log.NewLogger(tt.debug, false)
+			require.NoError(t, err)
+			logger.Debug("debug")
+			logger.Info("info")
+			logger.Error("error")
+
+			f, err := os.Open(logFile)
+			require.NoError(t, err)
+			defer f.Close()
+
+			var got []map[string]interface{}
+			s := bufio.NewScanner(f)
+			for s.Scan() {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal(s.Bytes(), &line))
+				assert.NotEmpty(t, line["Time"])
+				delete(line, "Time")
+				got = append(got, line)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
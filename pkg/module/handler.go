@@ -0,0 +1,42 @@
+package module
+
+import (
+	"context"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/handler"
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+const (
+	customResourcePostHandler ftypes.HandlerType = "custom-resource"
+	handlerVersion                               = 1
+)
+
+// customResourceHandler passes custom resources detected by modules to the blob.
+// Only image artifacts store them in the blob by themselves.
+type customResourceHandler struct{}
+
+func newCustomResourceHandler(artifact.Option) (handler.PostHandler, error) {
+	return customResourceHandler{}, nil
+}
+
+func (customResourceHandler) Type() ftypes.HandlerType {
+	return customResourcePostHandler
+}
+
+func (customResourceHandler) Version() int {
+	return handlerVersion
+}
+
+func (customResourceHandler) Handle(_ context.Context, result *analyzer.AnalysisResult, blob *ftypes.BlobInfo) error {
+	if len(blob.CustomResources) == 0 {
+		blob.CustomResources = result.CustomResources
+	}
+	return nil
+}
+
+func (customResourceHandler) Priority() int {
+	return 1
+}
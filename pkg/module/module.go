@@ -0,0 +1,453 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/handler"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const (
+	// APIVersion is the version of the interface between Trivy and modules.
+	// Modules must return the same version from "api_version".
+	APIVersion = 1
+
+	xdgDataHome = "XDG_DATA_HOME"
+
+	// typePrefix namespaces the analyzer types of modules,
+	// so that a module cannot replace the built-in analyzer of the same name, e.g. "apk"
+	typePrefix = "module:"
+)
+
+var (
+	modulesRelativeDir = filepath.Join(".trivy", "modules")
+
+	// logLevels are the names of the host functions which modules can import from "env" for logging
+	logLevels = []string{"debug", "info", "warn", "error"}
+)
+
+// DefaultDir returns the directory where modules are loaded from.
+// It follows XDG_DATA_HOME in the same way as plugins.
+func DefaultDir() string {
+	dataHome := os.Getenv(xdgDataHome)
+	if dataHome != "" {
+		return filepath.Join(dataHome, modulesRelativeDir)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, modulesRelativeDir)
+}
+
+// Manager loads WASM modules and runs them as analyzers and post-scanners
+type Manager struct {
+	runtime wazero.Runtime
+	modules []*wasmModule
+}
+
+// NewManager loads all the "*.wasm" files in the directory.
+// It returns an empty manager if the directory doesn't exist.
+func NewManager(ctx context.Context, dir string) (*Manager, error) {
+	m := &Manager{}
+	if dir == "" {
+		return m, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.wasm"))
+	if err != nil {
+		return nil, xerrors.Errorf("glob error: %w", err)
+	} else if len(paths) == 0 {
+		return m, nil
+	}
+
+	m.runtime = wazero.NewRuntime(ctx)
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, m.runtime); err != nil {
+		return nil, xerrors.Errorf("WASI error: %w", err)
+	}
+
+	builder := m.runtime.NewHostModuleBuilder("env")
+	for _, level := range logLevels {
+		builder = builder.NewFunctionBuilder().WithFunc(logFunction(level)).Export(level)
+	}
+	if _, err = builder.Instantiate(ctx); err != nil {
+		return nil, xerrors.Errorf("host module error: %w", err)
+	}
+
+	names := map[string]string{}
+	for _, path := range paths {
+		log.Logger.Debugf("Loading the module %s...", path)
+		mod, err := m.load(ctx, path)
+		if err != nil {
+			return nil, xerrors.Errorf("module load error (%s): %w", path, err)
+		} else if other, ok := names[mod.name]; ok {
+			return nil, xerrors.Errorf("module load error (%s): the name %q is already used by %s", path, mod.name, other)
+		}
+		names[mod.name] = path
+		log.Logger.Infof("Module loaded: %s (version: %d)", mod.name, mod.version)
+		m.modules = append(m.modules, mod)
+	}
+	return m, nil
+}
+
+func (m *Manager) load(ctx context.Context, path string) (*wasmModule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("file read error: %w", err)
+	}
+
+	compiled, err := m.runtime.CompileModule(ctx, b)
+	if err != nil {
+		return nil, xerrors.Errorf("compile error: %w", err)
+	}
+
+	// Modules are initialized as reactors, i.e. "main" is not executed
+	config := wazero.NewModuleConfig().
+		WithName(strings.TrimSuffix(filepath.Base(path), ".wasm")).
+		WithStartFunctions("_initialize").
+		WithStdout(os.Stderr).
+		WithStderr(os.Stderr)
+	inst, err := m.runtime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		return nil, xerrors.Errorf("instantiate error: %w", err)
+	}
+
+	return newWASMModule(ctx, inst)
+}
+
+// Register registers the analyzers of the modules so that they run with the built-in analyzers
+func (m *Manager) Register() {
+	for _, mod := range m.modules {
+		if mod.isAnalyzer {
+			analyzer.RegisterAnalyzer(mod)
+			handler.RegisterPostHandlerInit(customResourcePostHandler, newCustomResourceHandler)
+		}
+	}
+}
+
+// PostScan passes the results to the post-scanners in order, which can add, modify and remove them
+func (m *Manager) PostScan(ctx context.Context, results types.Results) (types.Results, error) {
+	for _, mod := range m.modules {
+		if !mod.isPostScanner {
+			continue
+		}
+		var err error
+		if results, err = mod.PostScan(ctx, results); err != nil {
+			return nil, xerrors.Errorf("%s post scan error: %w", mod.name, err)
+		}
+	}
+	return results, nil
+}
+
+// Close closes the modules
+func (m *Manager) Close(ctx context.Context) error {
+	if m.runtime == nil {
+		return nil
+	}
+	return m.runtime.Close(ctx)
+}
+
+// logFunction returns the host function logging the message from the module
+func logFunction(level string) func(context.Context, api.Module, uint32, uint32) {
+	return func(_ context.Context, mod api.Module, ptr, size uint32) {
+		b, ok := mod.Memory().Read(ptr, size)
+		if !ok {
+			log.Logger.Errorf("[module %s] the log message is out of range", mod.Name())
+			return
+		}
+
+		// The logger is looked up on each call as it is replaced after the flags are parsed
+		switch level {
+		case "debug":
+			log.Logger.Debugf("[module %s] %s", mod.Name(), b)
+		case "info":
+			log.Logger.Infof("[module %s] %s", mod.Name(), b)
+		case "warn":
+			log.Logger.Warnf("[module %s] %s", mod.Name(), b)
+		case "error":
+			log.Logger.Errorf("[module %s] %s", mod.Name(), b)
+		}
+	}
+}
+
+// wasmModule represents a module and implements the analyzer interface of fanal
+type wasmModule struct {
+	// The instance cannot be called concurrently while fanal runs analyzers in parallel
+	mu  sync.Mutex
+	mod api.Module
+
+	name          string
+	version       int
+	isAnalyzer    bool
+	isPostScanner bool
+	required      []*regexp.Regexp
+
+	malloc   api.Function
+	free     api.Function
+	analyze  api.Function
+	postScan api.Function
+}
+
+func newWASMModule(ctx context.Context, mod api.Module) (*wasmModule, error) {
+	m := &wasmModule{
+		mod:    mod,
+		malloc: mod.ExportedFunction("malloc"),
+		free:   mod.ExportedFunction("free"),
+	}
+	if m.malloc == nil || m.free == nil {
+		return nil, xerrors.New(`"malloc" and "free" must be exported`)
+	}
+
+	apiVersion, err := m.callUint32(ctx, "api_version")
+	if err != nil {
+		return nil, err
+	} else if apiVersion != APIVersion {
+		return nil, xerrors.Errorf("API version %d is not supported, expected %d", apiVersion, APIVersion)
+	}
+
+	name, err := m.callBytes(ctx, "name")
+	if err != nil {
+		return nil, err
+	}
+	m.name = string(name)
+
+	version, err := m.callUint32(ctx, "version")
+	if err != nil {
+		return nil, err
+	}
+	m.version = int(version)
+
+	if m.isAnalyzer, err = m.callBool(ctx, "is_analyzer"); err != nil {
+		return nil, err
+	} else if m.isAnalyzer {
+		if m.analyze = mod.ExportedFunction("analyze"); m.analyze == nil {
+			return nil, xerrors.New(`analyzers must export "analyze"`)
+		}
+		if m.required, err = m.loadRequired(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.isPostScanner, err = m.callBool(ctx, "is_post_scanner"); err != nil {
+		return nil, err
+	} else if m.isPostScanner {
+		if m.postScan = mod.ExportedFunction("post_scan"); m.postScan == nil {
+			return nil, xerrors.New(`post-scanners must export "post_scan"`)
+		}
+	}
+	return m, nil
+}
+
+// loadRequired returns the regular expressions of the file paths which the analyzer requires
+func (m *wasmModule) loadRequired(ctx context.Context) ([]*regexp.Regexp, error) {
+	b, err := m.callBytes(ctx, "required")
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	if err = json.Unmarshal(b, &patterns); err != nil {
+		return nil, xerrors.Errorf("invalid required file patterns: %w", err)
+	}
+
+	var required []*regexp.Regexp
+	for _, p := range patterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid required file pattern (%s): %w", p, err)
+		}
+		required = append(required, r)
+	}
+	return required, nil
+}
+
+func (m *wasmModule) Type() analyzer.Type {
+	return analyzer.Type(typePrefix + m.name)
+}
+
+func (m *wasmModule) Version() int {
+	return m.version
+}
+
+func (m *wasmModule) Required(filePath string, _ os.FileInfo) bool {
+	for _, r := range m.required {
+		if r.MatchString(filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// analysisResult is the result which analyzers return in JSON
+type analysisResult struct {
+	Applications    []ftypes.Application
+	CustomResources []ftypes.CustomResource
+}
+
+func (m *wasmModule) Analyze(ctx context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	content, err := io.ReadAll(input.Content)
+	if err != nil {
+		return nil, xerrors.Errorf("read error: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pathPtr, pathSize, err := m.write(ctx, []byte(input.FilePath))
+	if err != nil {
+		return nil, err
+	}
+	defer m.release(ctx, pathPtr)
+
+	contentPtr, contentSize, err := m.write(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	defer m.release(ctx, contentPtr)
+
+	res, err := m.analyze.Call(ctx, pathPtr, pathSize, contentPtr, contentSize)
+	if err != nil {
+		return nil, xerrors.Errorf("analyze error: %w", err)
+	}
+	b, err := m.read(ctx, res[0])
+	if err != nil {
+		return nil, xerrors.Errorf("analyze error: %w", err)
+	}
+
+	var result analysisResult
+	if err = json.Unmarshal(b, &result); err != nil {
+		return nil, xerrors.Errorf("invalid analysis result: %w", err)
+	}
+	if len(result.Applications) == 0 && len(result.CustomResources) == 0 {
+		return nil, nil
+	}
+
+	for i := range result.Applications {
+		if result.Applications[i].FilePath == "" {
+			result.Applications[i].FilePath = input.FilePath
+		}
+	}
+	for i := range result.CustomResources {
+		if result.CustomResources[i].FilePath == "" {
+			result.CustomResources[i].FilePath = input.FilePath
+		}
+	}
+	return &analyzer.AnalysisResult{
+		Applications:    result.Applications,
+		CustomResources: result.CustomResources,
+	}, nil
+}
+
+// PostScan passes the results to the module in JSON and returns the results from the module
+func (m *wasmModule) PostScan(ctx context.Context, results types.Results) (types.Results, error) {
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, xerrors.Errorf("json encode error: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ptr, size, err := m.write(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	defer m.release(ctx, ptr)
+
+	res, err := m.postScan.Call(ctx, ptr, size)
+	if err != nil {
+		return nil, xerrors.Errorf("post_scan error: %w", err)
+	}
+	if b, err = m.read(ctx, res[0]); err != nil {
+		return nil, xerrors.Errorf("post_scan error: %w", err)
+	}
+
+	var got types.Results
+	if err = json.Unmarshal(b, &got); err != nil {
+		return nil, xerrors.Errorf("invalid post scan results: %w", err)
+	}
+	return got, nil
+}
+
+func (m *wasmModule) call(ctx context.Context, name string) (uint64, error) {
+	f := m.mod.ExportedFunction(name)
+	if f == nil {
+		return 0, xerrors.Errorf("%q must be exported", name)
+	}
+	res, err := f.Call(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("%s error: %w", name, err)
+	} else if len(res) != 1 {
+		return 0, xerrors.Errorf("%q must return one value", name)
+	}
+	return res[0], nil
+}
+
+func (m *wasmModule) callUint32(ctx context.Context, name string) (uint32, error) {
+	v, err := m.call(ctx, name)
+	return uint32(v), err
+}
+
+func (m *wasmModule) callBool(ctx context.Context, name string) (bool, error) {
+	v, err := m.callUint32(ctx, name)
+	return v != 0, err
+}
+
+func (m *wasmModule) callBytes(ctx context.Context, name string) ([]byte, error) {
+	v, err := m.call(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := m.read(ctx, v)
+	if err != nil {
+		return nil, xerrors.Errorf("%s error: %w", name, err)
+	}
+	return b, nil
+}
+
+// write allocates the memory in the module and copies the bytes into it
+func (m *wasmModule) write(ctx context.Context, b []byte) (ptr, size uint64, err error) {
+	res, err := m.malloc.Call(ctx, uint64(len(b)))
+	if err != nil {
+		return 0, 0, xerrors.Errorf("malloc error: %w", err)
+	}
+	if !m.mod.Memory().Write(uint32(res[0]), b) {
+		return 0, 0, xerrors.Errorf("memory write out of range (ptr: %d, size: %d)", res[0], len(b))
+	}
+	return res[0], uint64(len(b)), nil
+}
+
+// read copies the bytes which the pointer in the upper 32 bits and the size in the lower 32 bits point to,
+// and frees the memory in the module
+func (m *wasmModule) read(ctx context.Context, packed uint64) ([]byte, error) {
+	ptr, size := uint32(packed>>32), uint32(packed)
+	if ptr == 0 {
+		return nil, xerrors.New("no value returned")
+	}
+	defer m.release(ctx, uint64(ptr))
+
+	b, ok := m.mod.Memory().Read(ptr, size)
+	if !ok {
+		return nil, xerrors.Errorf("memory read out of range (ptr: %d, size: %d)", ptr, size)
+	}
+	// The view is invalidated when the memory grows
+	return append([]byte(nil), b...), nil
+}
+
+func (m *wasmModule) release(ctx context.Context, ptr uint64) {
+	if _, err := m.free.Call(ctx, ptr); err != nil {
+		log.Logger.Debugf("Unable to free the memory in %s: %s", m.name, err)
+	}
+}
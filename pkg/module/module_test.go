@@ -0,0 +1,151 @@
+package module
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// buildModule builds the test module into the directory.
+// The test is skipped if Go doesn't support WASI reactors, which requires Go 1.24 or later.
+func buildModule(t *testing.T, name, dir string) {
+	src, err := filepath.Abs(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", filepath.Join(dir, name+".wasm"), "main.go")
+	cmd.Dir = src
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("unable to build the test module: %s", out)
+	}
+}
+
+func TestManager(t *testing.T) {
+	dir := t.TempDir()
+	buildModule(t, "happy", dir)
+
+	ctx := context.Background()
+	m, err := NewManager(ctx, dir)
+	require.NoError(t, err)
+	defer m.Close(ctx)
+
+	require.Len(t, m.modules, 1)
+	mod := m.modules[0]
+	assert.Equal(t, analyzer.Type("module:happy"), mod.Type())
+	assert.Equal(t, 2, mod.Version())
+	assert.True(t, mod.Required("app/packages.lst", nil))
+	assert.False(t, mod.Required("app/package.json", nil))
+
+	t.Run("analyze", func(t *testing.T) {
+		got, err := mod.Analyze(ctx, analyzer.AnalysisInput{
+			FilePath: "app/packages.lst",
+			Content:  strings.NewReader("lodash 4.17.20\nexpress 4.17.1\n"),
+		})
+		require.NoError(t, err)
+		want := &analyzer.AnalysisResult{
+			Applications: []ftypes.Application{
+				{
+					Type:     "npm",
+					FilePath: "app/packages.lst",
+					Libraries: []ftypes.Package{
+						{Name: "lodash", Version: "4.17.20"},
+						{Name: "express", Version: "4.17.1"},
+					},
+				},
+			},
+			CustomResources: []ftypes.CustomResource{
+				{
+					Type:     "packages-list",
+					FilePath: "app/packages.lst",
+					Data:     float64(2),
+				},
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("post scan", func(t *testing.T) {
+		got, err := m.PostScan(ctx, types.Results{
+			{
+				Target: "app/packages.lst",
+				Class:  types.ClassLangPkg,
+				Type:   "npm",
+			},
+		})
+		require.NoError(t, err)
+		want := types.Results{
+			{
+				Target: "app/packages.lst (post-scanned)",
+				Class:  types.ClassLangPkg,
+				Type:   "npm",
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestNewManager_duplicateName(t *testing.T) {
+	dir := t.TempDir()
+	buildModule(t, "happy", dir)
+
+	b, err := os.ReadFile(filepath.Join(dir, "happy.wasm"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "renamed.wasm"), b, 0600))
+
+	_, err = NewManager(context.Background(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `the name "happy" is already used`)
+}
+
+func Test_wasmModule_Type(t *testing.T) {
+	// A module cannot replace the built-in analyzer of the same name
+	mod := &wasmModule{name: string(analyzer.TypeApk)}
+	assert.Equal(t, analyzer.Type("module:apk"), mod.Type())
+	assert.NotEqual(t, analyzer.TypeApk, mod.Type())
+}
+
+func TestNewManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "no module",
+		},
+		{
+			name:    "invalid module",
+			files:   map[string]string{"invalid.wasm": "invalid"},
+			wantErr: "compile error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			}
+
+			ctx := context.Background()
+			m, err := NewManager(ctx, dir)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, m.modules)
+			assert.NoError(t, m.Close(ctx))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+//go:build wasip1
+
+// This module is built by "GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o happy.wasm" with Go 1.24 or later.
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"unsafe"
+)
+
+// allocs keeps the memory passed to the host alive until it is freed
+var allocs = map[uint32][]byte{}
+
+//go:wasmimport env debug
+func debug(ptr, size uint32)
+
+func main() {}
+
+//go:wasmexport malloc
+func malloc(size uint32) uint32 {
+	if size == 0 {
+		size = 1
+	}
+	buf := make([]byte, size)
+	ptr := uint32(uintptr(unsafe.Pointer(&buf[0])))
+	allocs[ptr] = buf
+	return ptr
+}
+
+//go:wasmexport free
+func free(ptr uint32) {
+	delete(allocs, ptr)
+}
+
+//go:wasmexport name
+func name() uint64 {
+	return pack([]byte("happy"))
+}
+
+//go:wasmexport version
+func version() uint32 {
+	return 2
+}
+
+//go:wasmexport api_version
+func apiVersion() uint32 {
+	return 1
+}
+
+//go:wasmexport is_analyzer
+func isAnalyzer() uint32 {
+	return 1
+}
+
+//go:wasmexport required
+func required() uint64 {
+	b, _ := json.Marshal([]string{`(^|/)packages\.lst$`})
+	return pack(b)
+}
+
+//go:wasmexport analyze
+func analyze(pathPtr, pathSize, contentPtr, contentSize uint32) uint64 {
+	filePath := string(bytes(pathPtr, pathSize))
+	content := string(bytes(contentPtr, contentSize))
+	log("analyzing " + filePath)
+
+	// Each line is "name version"
+	type library struct {
+		Name    string
+		Version string
+	}
+	var libs []library
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		if n, v, ok := strings.Cut(line, " "); ok {
+			libs = append(libs, library{Name: n, Version: v})
+		}
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"Applications": []interface{}{
+			map[string]interface{}{"Type": "npm", "FilePath": filePath, "Libraries": libs},
+		},
+		"CustomResources": []interface{}{
+			map[string]interface{}{"Type": "packages-list", "FilePath": filePath, "Data": len(libs)},
+		},
+	})
+	return pack(b)
+}
+
+//go:wasmexport is_post_scanner
+func isPostScanner() uint32 {
+	return 1
+}
+
+//go:wasmexport post_scan
+func postScan(ptr, size uint32) uint64 {
+	var results []map[string]interface{}
+	if err := json.Unmarshal(bytes(ptr, size), &results); err != nil {
+		return 0
+	}
+	for _, r := range results {
+		r["Target"] = r["Target"].(string) + " (post-scanned)"
+	}
+	b, _ := json.Marshal(results)
+	return pack(b)
+}
+
+func log(msg string) {
+	b := []byte(msg)
+	debug(uint32(uintptr(unsafe.Pointer(&b[0]))), uint32(len(b)))
+}
+
+func bytes(ptr, size uint32) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(uintptr(ptr))), size)
+}
+
+// pack returns the pointer in the upper 32 bits and the size in the lower 32 bits
+func pack(b []byte) uint64 {
+	ptr := malloc(uint32(len(b)))
+	copy(bytes(ptr, uint32(len(b))), b)
+	return uint64(ptr)<<32 | uint64(len(b))
+}
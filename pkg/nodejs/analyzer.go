@@ -0,0 +1,236 @@
+package nodejs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/language"
+	// The analyzers of fanal are registered first, so that those of the same types here replace them
+	_ "github.com/aquasecurity/fanal/analyzer/language/nodejs/npm"
+	_ "github.com/aquasecurity/fanal/analyzer/language/nodejs/yarn"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/go-dep-parser/pkg/nodejs/yarn"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&npmAnalyzer{})
+	analyzer.RegisterAnalyzer(&yarnAnalyzer{})
+	analyzer.RegisterAnalyzer(&pnpmAnalyzer{})
+	analyzer.RegisterAnalyzer(&manifestAnalyzer{})
+}
+
+const (
+	// TypePnpm analyzes pnpm-lock.yaml
+	TypePnpm = analyzer.Type("pnpm")
+
+	// TypeManifest records the dependencies declared in package.json of the projects
+	TypeManifest = analyzer.Type("npm-manifest")
+
+	// Pnpm is the type of the applications in pnpm-lock.yaml
+	Pnpm = "pnpm"
+
+	// PnpmLock is the lock file of pnpm
+	PnpmLock = "pnpm-lock.yaml"
+
+	// PackageJSON is the manifest of the projects
+	PackageJSON = "package.json"
+
+	// The versions are greater than those of fanal, so that the layers analyzed by fanal are analyzed again
+	npmVersion  = 3
+	yarnVersion = 3
+	pnpmVersion = 2
+
+	manifestVersion = 1
+)
+
+// npmAnalyzer replaces the analyzer of fanal, which supports only "dependencies" of package-lock.json,
+// drops the development dependencies and reports the packages of all the workspace members in the project at the root
+type npmAnalyzer struct{}
+
+func (a npmAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	b, err := io.ReadAll(input.Content)
+	if err != nil {
+		return nil, xerrors.Errorf("read error (%s): %w", input.FilePath, err)
+	}
+
+	projects, g, ok, err := parseNpmLock(b)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to parse package-lock.json: %w", err)
+	} else if !ok {
+		// Lock files of v1 don't support workspaces, and don't list the direct dependencies
+		libs, deps, dev, err := parseNpmLockV1(b)
+		if err != nil {
+			return nil, xerrors.Errorf("unable to parse package-lock.json: %w", err)
+		}
+		res := language.ToAnalysisResult(ftypes.Npm, input.FilePath, "", libs, deps)
+		if res != nil {
+			res.CustomResources = []ftypes.CustomResource{
+				{
+					Type:     types.PackageScopeType,
+					FilePath: input.FilePath,
+					Data:     types.PackageScope{Dev: dev},
+				},
+			}
+		}
+		return res, nil
+	}
+	return analysisResult(ftypes.Npm, input.FilePath, projects, g), nil
+}
+
+func (a npmAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filepath.Base(filePath) == ftypes.NpmPkgLock
+}
+
+func (a npmAnalyzer) Type() analyzer.Type {
+	return analyzer.TypeNpmPkgLock
+}
+
+func (a npmAnalyzer) Version() int {
+	return npmVersion
+}
+
+// yarnAnalyzer replaces the analyzer of fanal, which doesn't support yarn.lock of Yarn 2 or later
+type yarnAnalyzer struct{}
+
+func (a yarnAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	b, err := io.ReadAll(input.Content)
+	if err != nil {
+		return nil, xerrors.Errorf("read error (%s): %w", input.FilePath, err)
+	}
+
+	if !isYarnBerry(b) {
+		res, err := language.Analyze(ftypes.Yarn, input.FilePath, bytes.NewReader(b), yarn.NewParser())
+		if err != nil {
+			return nil, xerrors.Errorf("unable to parse yarn.lock: %w", err)
+		}
+		return res, nil
+	}
+
+	projects, g, err := parseYarnBerry(b)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to parse yarn.lock: %w", err)
+	}
+	return analysisResult(ftypes.Yarn, input.FilePath, projects, g), nil
+}
+
+func (a yarnAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filepath.Base(filePath) == ftypes.YarnLock
+}
+
+func (a yarnAnalyzer) Type() analyzer.Type {
+	return analyzer.TypeYarn
+}
+
+func (a yarnAnalyzer) Version() int {
+	return yarnVersion
+}
+
+type pnpmAnalyzer struct{}
+
+func (a pnpmAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	b, err := io.ReadAll(input.Content)
+	if err != nil {
+		return nil, xerrors.Errorf("read error (%s): %w", input.FilePath, err)
+	}
+
+	projects, g, err := parsePnpmLock(b)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to parse pnpm-lock.yaml: %w", err)
+	}
+	return analysisResult(Pnpm, input.FilePath, projects, g), nil
+}
+
+func (a pnpmAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filepath.Base(filePath) == PnpmLock
+}
+
+func (a pnpmAnalyzer) Type() analyzer.Type {
+	return TypePnpm
+}
+
+func (a pnpmAnalyzer) Version() int {
+	return pnpmVersion
+}
+
+// packageJSON is package.json of the project
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+}
+
+// manifestAnalyzer records the dependencies in package.json of the projects, which tell the direct and
+// the development dependencies of the lock files in the same directory
+type manifestAnalyzer struct{}
+
+func (a manifestAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var pkg packageJSON
+	if err := json.NewDecoder(input.Content).Decode(&pkg); err != nil {
+		return nil, xerrors.Errorf("unable to decode package.json: %w", err)
+	}
+
+	var manifest types.PackageManifest
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.OptionalDependencies, pkg.PeerDependencies} {
+		manifest.Dependencies = append(manifest.Dependencies, maps.Keys(deps)...)
+	}
+	manifest.DevDependencies = maps.Keys(pkg.DevDependencies)
+	if len(manifest.Dependencies) == 0 && len(manifest.DevDependencies) == 0 {
+		return nil, nil
+	}
+	sort.Strings(manifest.Dependencies)
+	sort.Strings(manifest.DevDependencies)
+
+	return &analyzer.AnalysisResult{
+		CustomResources: []ftypes.CustomResource{
+			{
+				Type:     types.PackageManifestType,
+				FilePath: input.FilePath,
+				Data:     manifest,
+			},
+		},
+	}, nil
+}
+
+func (a manifestAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	if filepath.Base(filePath) != PackageJSON {
+		return false
+	}
+	// The installed packages are analyzed by the analyzer of fanal
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+		if dir == nodeModules {
+			return false
+		}
+	}
+	return true
+}
+
+func (a manifestAnalyzer) Type() analyzer.Type {
+	return TypeManifest
+}
+
+func (a manifestAnalyzer) Version() int {
+	return manifestVersion
+}
+
+func analysisResult(fileType, filePath string, projects []project, g graph) *analyzer.AnalysisResult {
+	apps, scopes := applications(fileType, filePath, projects, g)
+	if len(apps) == 0 {
+		return nil
+	}
+	return &analyzer.AnalysisResult{
+		Applications:    apps,
+		CustomResources: scopes,
+	}
+}
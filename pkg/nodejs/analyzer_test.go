@@ -0,0 +1,287 @@
+package nodejs
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// workspaceApps is the applications of the monorepo in testdata, where "packages/a" depends on "packages/b".
+// The root project has the development dependency if the lock file tells it.
+func workspaceApps(fileType, filePath string, withDev bool) []ftypes.Application {
+	root := []ftypes.Package{
+		{ID: "lodash@4.17.21", Name: "lodash", Version: "4.17.21"},
+	}
+	if withDev {
+		root = append(root, ftypes.Package{ID: "typescript@4.7.2", Name: "typescript", Version: "4.7.2"})
+	}
+	return []ftypes.Application{
+		{
+			Type:      fileType,
+			FilePath:  "app/" + filePath,
+			Libraries: root,
+		},
+		{
+			Type:     fileType,
+			FilePath: "app/packages/a/package.json",
+			Libraries: []ftypes.Package{
+				{ID: "debug@4.3.4", Name: "debug", Version: "4.3.4", Indirect: true},
+				{ID: "ms@2.1.2", Name: "ms", Version: "2.1.2", Indirect: true},
+				{ID: "ms@2.1.3", Name: "ms", Version: "2.1.3"},
+			},
+			Dependencies: []godeptypes.Dependency{
+				{ID: "debug@4.3.4", DependsOn: []string{"ms@2.1.2"}},
+			},
+		},
+		{
+			Type:     fileType,
+			FilePath: "app/packages/b/package.json",
+			Libraries: []ftypes.Package{
+				{ID: "debug@4.3.4", Name: "debug", Version: "4.3.4"},
+				{ID: "ms@2.1.2", Name: "ms", Version: "2.1.2", Indirect: true},
+			},
+			Dependencies: []godeptypes.Dependency{
+				{ID: "debug@4.3.4", DependsOn: []string{"ms@2.1.2"}},
+			},
+		},
+	}
+}
+
+// workspaceScopes is the scopes of the applications of workspaceApps
+func workspaceScopes(filePath string, withDev bool) []ftypes.CustomResource {
+	root := types.PackageScope{Direct: []string{"lodash@4.17.21"}}
+	if withDev {
+		root = types.PackageScope{
+			Direct: []string{"lodash@4.17.21", "typescript@4.7.2"},
+			Dev:    []string{"typescript@4.7.2"},
+		}
+	}
+	return []ftypes.CustomResource{
+		{Type: types.PackageScopeType, FilePath: "app/" + filePath, Data: root},
+		{
+			Type:     types.PackageScopeType,
+			FilePath: "app/packages/a/package.json",
+			Data:     types.PackageScope{Direct: []string{"ms@2.1.3"}},
+		},
+		{
+			Type:     types.PackageScopeType,
+			FilePath: "app/packages/b/package.json",
+			Data:     types.PackageScope{Direct: []string{"debug@4.3.4"}},
+		},
+	}
+}
+
+func TestAnalyzers(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer interface {
+			Analyze(context.Context, analyzer.AnalysisInput) (*analyzer.AnalysisResult, error)
+		}
+		inputFile  string
+		filePath   string
+		want       []ftypes.Application
+		wantScopes []ftypes.CustomResource
+	}{
+		{
+			name:       "package-lock.json v3 with workspaces",
+			analyzer:   npmAnalyzer{},
+			inputFile:  "testdata/npm-workspaces.json",
+			filePath:   "package-lock.json",
+			want:       workspaceApps(ftypes.Npm, "package-lock.json", true),
+			wantScopes: workspaceScopes("package-lock.json", true),
+		},
+		{
+			name:      "package-lock.json v1",
+			analyzer:  npmAnalyzer{},
+			inputFile: "testdata/npm-v1.json",
+			filePath:  "package-lock.json",
+			want: []ftypes.Application{
+				{
+					Type:     ftypes.Npm,
+					FilePath: "app/package-lock.json",
+					Libraries: []ftypes.Package{
+						{ID: "debug@4.3.4", Name: "debug", Version: "4.3.4"},
+						{ID: "lodash@4.17.21", Name: "lodash", Version: "4.17.21"},
+						{ID: "ms@2.1.2", Name: "ms", Version: "2.1.2"},
+						{ID: "typescript@4.7.2", Name: "typescript", Version: "4.7.2"},
+					},
+					Dependencies: []godeptypes.Dependency{
+						{ID: "debug@4.3.4", DependsOn: []string{"ms@2.1.2"}},
+					},
+				},
+			},
+			wantScopes: []ftypes.CustomResource{
+				{
+					Type:     types.PackageScopeType,
+					FilePath: "app/package-lock.json",
+					Data:     types.PackageScope{Dev: []string{"typescript@4.7.2"}},
+				},
+			},
+		},
+		{
+			name:       "yarn.lock of Yarn 2 with workspaces",
+			analyzer:   yarnAnalyzer{},
+			inputFile:  "testdata/yarn-berry.lock",
+			filePath:   "yarn.lock",
+			want:       workspaceApps(ftypes.Yarn, "yarn.lock", false),
+			wantScopes: workspaceScopes("yarn.lock", false),
+		},
+		{
+			name:       "pnpm-lock.yaml v5 with workspaces",
+			analyzer:   pnpmAnalyzer{},
+			inputFile:  "testdata/pnpm-v5.yaml",
+			filePath:   "pnpm-lock.yaml",
+			want:       workspaceApps(Pnpm, "pnpm-lock.yaml", true),
+			wantScopes: workspaceScopes("pnpm-lock.yaml", true),
+		},
+		{
+			name:       "pnpm-lock.yaml v6 with workspaces",
+			analyzer:   pnpmAnalyzer{},
+			inputFile:  "testdata/pnpm-v6.yaml",
+			filePath:   "pnpm-lock.yaml",
+			want:       workspaceApps(Pnpm, "pnpm-lock.yaml", true),
+			wantScopes: workspaceScopes("pnpm-lock.yaml", true),
+		},
+		{
+			name:       "pnpm-lock.yaml v9 with workspaces",
+			analyzer:   pnpmAnalyzer{},
+			inputFile:  "testdata/pnpm-v9.yaml",
+			filePath:   "pnpm-lock.yaml",
+			want:       workspaceApps(Pnpm, "pnpm-lock.yaml", true),
+			wantScopes: workspaceScopes("pnpm-lock.yaml", true),
+		},
+		{
+			name:      "pnpm-lock.yaml of a single project with peer dependencies",
+			analyzer:  pnpmAnalyzer{},
+			inputFile: "testdata/pnpm-single.yaml",
+			filePath:  "pnpm-lock.yaml",
+			want: []ftypes.Application{
+				{
+					Type:     Pnpm,
+					FilePath: "app/pnpm-lock.yaml",
+					Libraries: []ftypes.Package{
+						{ID: "js-tokens@4.0.0", Name: "js-tokens", Version: "4.0.0", Indirect: true},
+						{ID: "loose-envify@1.4.0", Name: "loose-envify", Version: "1.4.0", Indirect: true},
+						{ID: "object-assign@4.1.1", Name: "object-assign", Version: "4.1.1", Indirect: true},
+						{ID: "react-dom@17.0.2", Name: "react-dom", Version: "17.0.2"},
+						{ID: "react@17.0.2", Name: "react", Version: "17.0.2"},
+					},
+					Dependencies: []godeptypes.Dependency{
+						{ID: "loose-envify@1.4.0", DependsOn: []string{"js-tokens@4.0.0"}},
+						{ID: "react-dom@17.0.2", DependsOn: []string{"loose-envify@1.4.0", "object-assign@4.1.1", "react@17.0.2"}},
+						{ID: "react@17.0.2", DependsOn: []string{"loose-envify@1.4.0", "object-assign@4.1.1"}},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.inputFile)
+			require.NoError(t, err)
+			defer f.Close()
+
+			got, err := tt.analyzer.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: "app/" + tt.filePath,
+				Content:  f,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.Applications)
+			if tt.wantScopes != nil {
+				assert.Equal(t, tt.wantScopes, got.CustomResources)
+			}
+		})
+	}
+}
+
+func Test_parseYarnResolution(t *testing.T) {
+	tests := []struct {
+		resolution   string
+		wantName     string
+		wantProtocol string
+		wantRef      string
+	}{
+		{resolution: "lodash@npm:4.17.21", wantName: "lodash", wantProtocol: "npm", wantRef: "4.17.21"},
+		{resolution: "@babel/core@npm:7.18.2", wantName: "@babel/core", wantProtocol: "npm", wantRef: "7.18.2"},
+		{resolution: "b@workspace:packages/b", wantName: "b", wantProtocol: "workspace", wantRef: "packages/b"},
+		{resolution: "invalid", wantName: "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resolution, func(t *testing.T) {
+			name, protocol, ref := parseYarnResolution(tt.resolution)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantProtocol, protocol)
+			assert.Equal(t, tt.wantRef, ref)
+		})
+	}
+}
+
+func TestManifestAnalyzer_Analyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *analyzer.AnalysisResult
+	}{
+		{
+			name: "happy path",
+			content: `{
+  "name": "app",
+  "dependencies": {"lodash": "^4.17.21"},
+  "peerDependencies": {"react": "^17.0.0"},
+  "devDependencies": {"typescript": "^4.7.2"}
+}`,
+			want: &analyzer.AnalysisResult{
+				CustomResources: []ftypes.CustomResource{
+					{
+						Type:     types.PackageManifestType,
+						FilePath: "app/package.json",
+						Data: types.PackageManifest{
+							Dependencies:    []string{"lodash", "react"},
+							DevDependencies: []string{"typescript"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "no dependencies",
+			content: `{"name": "app"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manifestAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: "app/package.json",
+				Content:  strings.NewReader(tt.content),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestManifestAnalyzer_Required(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{filePath: "app/package.json", want: true},
+		{filePath: "app/node_modules/lodash/package.json", want: false},
+		{filePath: "app/package-lock.json", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			assert.Equal(t, tt.want, manifestAnalyzer{}.Required(tt.filePath, nil))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package nodejs parses the lock files of npm, Yarn and pnpm with workspaces, so that the packages are
+// reported per workspace member instead of being mixed into the project at the root of the repository.
+package nodejs
+
+import (
+	"path"
+	"sort"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// rootPath is the path of the project at the root of the lock file
+const rootPath = "."
+
+// project is the root project or a workspace member in the lock file
+type project struct {
+	// path is the directory of the project relative to the lock file
+	path string
+
+	// direct are the keys of the packages the project depends on, excluding the development dependencies
+	direct []string
+
+	// dev are the keys of the development dependencies of the project
+	dev []string
+}
+
+// node is a package installed by the lock file
+type node struct {
+	name    string
+	version string
+
+	// deps are the keys of the packages the package depends on
+	deps []string
+
+	// link is the path of the workspace member when the package is linked to it
+	link string
+}
+
+// graph holds the packages installed by the lock file keyed by the identifier unique in the lock file,
+// e.g. "node_modules/lodash" in package-lock.json
+type graph map[string]node
+
+func packageID(name, version string) string {
+	return name + "@" + version
+}
+
+// applications returns an application per project with the packages the project depends on, and the scopes of them.
+// The packages pulled in through the other workspace members the project depends on are indirect dependencies.
+// The application of the root project has the path of the lock file, and the others have that of package.json.
+func applications(fileType, filePath string, projects []project, g graph) ([]ftypes.Application, []ftypes.CustomResource) {
+	members := map[string]project{}
+	for _, p := range projects {
+		members[p.path] = p
+	}
+
+	// The root project comes first as "." precedes the paths of the members
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].path < projects[j].path
+	})
+
+	var apps []ftypes.Application
+	var scopes []ftypes.CustomResource
+	for _, p := range projects {
+		libs, deps, scope := g.walk(p, members)
+		if len(libs) == 0 {
+			continue
+		}
+
+		appPath := filePath
+		if p.path != rootPath {
+			appPath = path.Join(path.Dir(filePath), p.path, PackageJSON)
+		}
+		apps = append(apps, ftypes.Application{
+			Type:         fileType,
+			FilePath:     appPath,
+			Libraries:    libs,
+			Dependencies: deps,
+		})
+		scopes = append(scopes, ftypes.CustomResource{
+			Type:     types.PackageScopeType,
+			FilePath: appPath,
+			Data:     scope,
+		})
+	}
+	return apps, scopes
+}
+
+// walk returns the packages reachable from the project, the dependency graph among them and the scopes of them.
+// The packages reachable only from the development dependencies are the development ones.
+func (g graph) walk(p project, members map[string]project) ([]ftypes.Package, []godeptypes.Dependency,
+	types.PackageScope) {
+	direct := map[string]bool{}
+	for _, key := range append(append([]string{}, p.direct...), p.dev...) {
+		if n, ok := g[key]; ok && n.link == "" {
+			direct[packageID(n.name, n.version)] = true
+		}
+	}
+
+	visited := map[string]bool{}
+	linked := map[string]bool{p.path: true}
+	libs := map[string]ftypes.Package{}
+	dev := map[string]bool{}
+	dependsOn := map[string]map[string]struct{}{}
+	visit := func(queue []string, isDev bool) {
+		for len(queue) > 0 {
+			key := queue[0]
+			queue = queue[1:]
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			n, ok := g[key]
+			if !ok {
+				continue
+			}
+			if n.link != "" {
+				// The dependencies of the workspace member are installed for the project
+				if m, ok := members[n.link]; ok && !linked[n.link] {
+					linked[n.link] = true
+					queue = append(queue, m.direct...)
+				}
+				continue
+			}
+
+			id := packageID(n.name, n.version)
+			if _, ok := libs[id]; !ok && isDev {
+				dev[id] = true
+			}
+			libs[id] = ftypes.Package{
+				ID:       id,
+				Name:     n.name,
+				Version:  n.version,
+				Indirect: !direct[id],
+			}
+			for _, dep := range n.deps {
+				child, ok := g[dep]
+				if !ok || child.link != "" {
+					continue
+				}
+				if dependsOn[id] == nil {
+					dependsOn[id] = map[string]struct{}{}
+				}
+				dependsOn[id][packageID(child.name, child.version)] = struct{}{}
+				queue = append(queue, dep)
+			}
+		}
+	}
+	visit(append([]string{}, p.direct...), false)
+	visit(append([]string{}, p.dev...), true)
+
+	var scope types.PackageScope
+	for id := range direct {
+		scope.Direct = append(scope.Direct, id)
+	}
+	for id := range dev {
+		scope.Dev = append(scope.Dev, id)
+	}
+	sort.Strings(scope.Direct)
+	sort.Strings(scope.Dev)
+
+	var pkgs []ftypes.Package
+	for _, lib := range libs {
+		pkgs = append(pkgs, lib)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].ID < pkgs[j].ID
+	})
+
+	var deps []godeptypes.Dependency
+	for id, children := range dependsOn {
+		dep := godeptypes.Dependency{ID: id}
+		for child := range children {
+			dep.DependsOn = append(dep.DependsOn, child)
+		}
+		sort.Strings(dep.DependsOn)
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].ID < deps[j].ID
+	})
+	return pkgs, deps, scope
+}
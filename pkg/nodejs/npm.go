@@ -0,0 +1,212 @@
+package nodejs
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+)
+
+const nodeModules = "node_modules"
+
+// npmLockFile is package-lock.json. Lock files of v2 and v3 have the installed packages in "packages",
+// while those of v1 have only "dependencies" without the workspaces.
+type npmLockFile struct {
+	Packages map[string]npmPackage `json:"packages"`
+}
+
+type npmPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// parseNpmLock returns the projects and the packages in "packages" of package-lock.json,
+// and false if the lock file has no "packages", i.e. v1
+func parseNpmLock(b []byte) ([]project, graph, bool, error) {
+	var lockFile npmLockFile
+	if err := json.Unmarshal(b, &lockFile); err != nil {
+		return nil, nil, false, xerrors.Errorf("decode error: %w", err)
+	}
+	if len(lockFile.Packages) == 0 {
+		return nil, nil, false, nil
+	}
+
+	g := graph{}
+	var projects []project
+	for key, pkg := range lockFile.Packages {
+		if !isInstalled(key) {
+			// The root project is keyed by "", and the workspace members by their directories
+			p := project{path: rootPath}
+			if key != "" {
+				p.path = path.Clean(key)
+			}
+			for _, name := range pkg.dependencyNames() {
+				if dep, ok := lockFile.resolve(key, name); ok {
+					p.direct = append(p.direct, dep)
+				}
+			}
+			for name := range pkg.DevDependencies {
+				if dep, ok := lockFile.resolve(key, name); ok {
+					p.dev = append(p.dev, dep)
+				}
+			}
+			projects = append(projects, p)
+			continue
+		}
+
+		n := node{
+			name:    pkg.Name,
+			version: pkg.Version,
+		}
+		if n.name == "" {
+			n.name = key[strings.LastIndex(key, nodeModules+"/")+len(nodeModules)+1:]
+		}
+		if pkg.Link {
+			n.link = path.Clean(pkg.Resolved)
+		}
+		for _, name := range pkg.dependencyNames() {
+			if dep, ok := lockFile.resolve(key, name); ok {
+				n.deps = append(n.deps, dep)
+			}
+		}
+		g[key] = n
+	}
+	return projects, g, true, nil
+}
+
+// npmDependencyV1 is a package in "dependencies" of package-lock.json v1, which has the packages installed
+// under its node_modules in "dependencies"
+type npmDependencyV1 struct {
+	Version      string                     `json:"version"`
+	Dev          bool                       `json:"dev"`
+	Requires     map[string]string          `json:"requires"`
+	Dependencies map[string]npmDependencyV1 `json:"dependencies"`
+}
+
+// parseNpmLockV1 returns the packages of package-lock.json v1, the dependency graph among them and the IDs of
+// the packages marked as only the development needs them.
+// The lock file doesn't list the direct dependencies, so all the packages are reported as the fanal parser does.
+func parseNpmLockV1(b []byte) ([]godeptypes.Library, []godeptypes.Dependency, []string, error) {
+	var v1 struct {
+		Dependencies map[string]npmDependencyV1 `json:"dependencies"`
+	}
+	if err := json.Unmarshal(b, &v1); err != nil {
+		return nil, nil, nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	// The packages are keyed by the paths under node_modules as in v2, so that they are resolved in the same way
+	lockFile := npmLockFile{Packages: map[string]npmPackage{}}
+	var flatten func(dir string, deps map[string]npmDependencyV1)
+	flatten = func(dir string, deps map[string]npmDependencyV1) {
+		for name, dep := range deps {
+			key := path.Join(dir, nodeModules, name)
+			lockFile.Packages[key] = npmPackage{
+				Name:         name,
+				Version:      dep.Version,
+				Dev:          dep.Dev,
+				Dependencies: dep.Requires,
+			}
+			flatten(key, dep.Dependencies)
+		}
+	}
+	flatten("", v1.Dependencies)
+
+	libs := map[string]godeptypes.Library{}
+	dependsOn := map[string]map[string]struct{}{}
+	prod := map[string]bool{}
+	for key, pkg := range lockFile.Packages {
+		id := packageID(pkg.Name, pkg.Version)
+		libs[id] = godeptypes.Library{
+			ID:      id,
+			Name:    pkg.Name,
+			Version: pkg.Version,
+		}
+		if !pkg.Dev {
+			prod[id] = true
+		}
+		for name := range pkg.Dependencies {
+			dep, ok := lockFile.resolve(key, name)
+			if !ok {
+				continue
+			}
+			if dependsOn[id] == nil {
+				dependsOn[id] = map[string]struct{}{}
+			}
+			child := lockFile.Packages[dep]
+			dependsOn[id][packageID(child.Name, child.Version)] = struct{}{}
+		}
+	}
+
+	var pkgs []godeptypes.Library
+	var dev []string
+	for id, lib := range libs {
+		pkgs = append(pkgs, lib)
+		if !prod[id] {
+			dev = append(dev, id)
+		}
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].ID < pkgs[j].ID
+	})
+	sort.Strings(dev)
+
+	var deps []godeptypes.Dependency
+	for id, children := range dependsOn {
+		dep := godeptypes.Dependency{ID: id}
+		for child := range children {
+			dep.DependsOn = append(dep.DependsOn, child)
+		}
+		sort.Strings(dep.DependsOn)
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].ID < deps[j].ID
+	})
+	return pkgs, deps, dev, nil
+}
+
+// isInstalled returns true if the key is a package installed under node_modules
+func isInstalled(key string) bool {
+	return key == nodeModules || strings.HasPrefix(key, nodeModules+"/") || strings.Contains(key, "/"+nodeModules+"/")
+}
+
+// dependencyNames returns the names of the dependencies installed with the package.
+// Optional dependencies may be missing, e.g. those for other platforms.
+func (p npmPackage) dependencyNames() []string {
+	var names []string
+	for _, deps := range []map[string]string{p.Dependencies, p.OptionalDependencies, p.PeerDependencies} {
+		for name := range deps {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// resolve returns the key of the package required from the package or the project at "from",
+// looking up node_modules in the parent directories as Node.js does
+func (l npmLockFile) resolve(from, name string) (string, bool) {
+	dir := from
+	for {
+		key := path.Join(dir, nodeModules, name)
+		if _, ok := l.Packages[key]; ok {
+			return key, true
+		}
+		if dir == "" {
+			return "", false
+		}
+		if dir = path.Dir(dir); dir == "." {
+			dir = ""
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package nodejs
+
+import (
+	"path"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmLockFile is pnpm-lock.yaml. The lock files of a single project have the dependencies at the top level,
+// and those of workspaces have them per project in "importers", keyed by the directory of the project.
+type pnpmLockFile struct {
+	LockfileVersion string                   `yaml:"lockfileVersion"`
+	Importers       map[string]pnpmImporter  `yaml:"importers"`
+	Packages        map[string]pnpmSnapshot  `yaml:"packages"`
+	Snapshots       map[string]pnpmSnapshot  `yaml:"snapshots"`
+	Dependencies    map[string]pnpmReference `yaml:"dependencies"`
+	Optional        map[string]pnpmReference `yaml:"optionalDependencies"`
+	Dev             map[string]pnpmReference `yaml:"devDependencies"`
+}
+
+type pnpmImporter struct {
+	Dependencies map[string]pnpmReference `yaml:"dependencies"`
+	Optional     map[string]pnpmReference `yaml:"optionalDependencies"`
+	Dev          map[string]pnpmReference `yaml:"devDependencies"`
+}
+
+// pnpmSnapshot is a package installed by the lock file. Lock files of v9 have the dependencies in "snapshots"
+// instead of "packages".
+type pnpmSnapshot struct {
+	Name         string            `yaml:"name"`
+	Version      string            `yaml:"version"`
+	Dependencies map[string]string `yaml:"dependencies"`
+	Optional     map[string]string `yaml:"optionalDependencies"`
+}
+
+// pnpmReference is the version the dependency of a project is resolved to.
+// Lock files of v5 have the version as is, and those of v6 or later have it with the specifier in package.json.
+type pnpmReference struct {
+	Version string
+}
+
+func (r *pnpmReference) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		r.Version = n.Value
+		return nil
+	}
+	var v struct {
+		Version string `yaml:"version"`
+	}
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	r.Version = v.Version
+	return nil
+}
+
+// parsePnpmLock returns the projects and the packages in pnpm-lock.yaml.
+// The dependencies linked with the "link:" protocol are the workspace members.
+func parsePnpmLock(b []byte) ([]project, graph, error) {
+	var lockFile pnpmLockFile
+	if err := yaml.Unmarshal(b, &lockFile); err != nil {
+		return nil, nil, xerrors.Errorf("yaml decode error: %w", err)
+	}
+
+	// Lock files of v5 have the keys like "/@babel/core/7.18.2", v6 "/@babel/core@7.18.2" and v9 "@babel/core@7.18.2"
+	major, _, _ := strings.Cut(lockFile.LockfileVersion, ".")
+	keyFunc := func(name, version string) string { return "/" + name + "@" + version }
+	switch major {
+	case "5":
+		keyFunc = func(name, version string) string { return "/" + name + "/" + version }
+	case "9":
+		keyFunc = func(name, version string) string { return name + "@" + version }
+	}
+
+	resolve := func(name, version string) string {
+		// The dependency may be an alias to another package, e.g. "/string-width@4.2.3" or "string-width@4.2.3".
+		// The peer dependencies of v5 are suffixed with "@" of their versions, so only the former is an alias.
+		if strings.HasPrefix(version, "/") || major != "5" && strings.Contains(trimPeerSuffix(version), "@") {
+			return version
+		}
+		return keyFunc(name, version)
+	}
+
+	importers := lockFile.Importers
+	if len(importers) == 0 {
+		importers = map[string]pnpmImporter{
+			rootPath: {Dependencies: lockFile.Dependencies, Optional: lockFile.Optional, Dev: lockFile.Dev},
+		}
+	}
+
+	g := graph{}
+	var projects []project
+	for dir, importer := range importers {
+		p := project{path: path.Clean(dir)}
+		keys := func(deps map[string]pnpmReference) []string {
+			var keys []string
+			for name, ref := range deps {
+				if strings.HasPrefix(ref.Version, "link:") {
+					// Links are keyed by the paths of the members, which don't collide with the packages
+					member := path.Join(p.path, strings.TrimPrefix(ref.Version, "link:"))
+					key := "link:" + member
+					g[key] = node{name: name, link: member}
+					keys = append(keys, key)
+					continue
+				}
+				keys = append(keys, resolve(name, ref.Version))
+			}
+			return keys
+		}
+		p.direct = append(keys(importer.Dependencies), keys(importer.Optional)...)
+		p.dev = keys(importer.Dev)
+		projects = append(projects, p)
+	}
+
+	snapshots := lockFile.Snapshots
+	if len(snapshots) == 0 {
+		snapshots = lockFile.Packages
+	}
+	for key, snapshot := range snapshots {
+		name, version := parsePnpmKey(major, key)
+		if snapshot.Name != "" {
+			// The packages not from the registry, e.g. tarballs, have the name and the version
+			name, version = snapshot.Name, snapshot.Version
+		} else if pkg, ok := lockFile.Packages[key]; ok && pkg.Version != "" {
+			version = pkg.Version
+		}
+
+		n := node{name: name, version: version}
+		for _, deps := range []map[string]string{snapshot.Dependencies, snapshot.Optional} {
+			for depName, depVersion := range deps {
+				n.deps = append(n.deps, resolve(depName, depVersion))
+			}
+		}
+		g[key] = n
+	}
+	return projects, g, nil
+}
+
+// parsePnpmKey returns the name and the version in the key of "packages" or "snapshots"
+func parsePnpmKey(major, key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if major == "5" {
+		i := strings.LastIndex(key, "/")
+		if i < 0 {
+			return key, ""
+		}
+		// Peer dependencies are suffixed with "_", e.g. "/react-dom/17.0.2_react@17.0.2"
+		version, _, _ := strings.Cut(key[i+1:], "_")
+		return key[:i], version
+	}
+
+	// Peer dependencies are suffixed in parentheses, e.g. "react-dom@17.0.2(react@17.0.2)"
+	key = trimPeerSuffix(key)
+	i := strings.LastIndex(key, "@")
+	if i <= 0 {
+		return key, ""
+	}
+	return key[:i], key[i+1:]
+}
+
+func trimPeerSuffix(s string) string {
+	s, _, _ = strings.Cut(s, "(")
+	return s
+}
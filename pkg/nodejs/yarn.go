@@ -0,0 +1,101 @@
+package nodejs
+
+import (
+	"bytes"
+	"path"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// yarnEntry is an entry of yarn.lock of Yarn 2 or later, keyed by the descriptors resolved to the package,
+// e.g. "lodash@npm:^4.17.0, lodash@npm:^4.17.21"
+type yarnEntry struct {
+	Version      string            `yaml:"version"`
+	Resolution   string            `yaml:"resolution"`
+	Dependencies map[string]string `yaml:"dependencies"`
+}
+
+// isYarnBerry returns true if the lock file is generated by Yarn 2 or later, which is YAML unlike Yarn 1
+func isYarnBerry(b []byte) bool {
+	return bytes.HasPrefix(b, []byte("__metadata:")) || bytes.Contains(b, []byte("\n__metadata:"))
+}
+
+// parseYarnBerry returns the projects and the packages in yarn.lock of Yarn 2 or later.
+// The workspace members are the entries resolved with the "workspace:" protocol.
+// The packages with the protocols other than "npm:" are not reported as their versions are not those in the registry.
+func parseYarnBerry(b []byte) ([]project, graph, error) {
+	var lockFile map[string]yarnEntry
+	if err := yaml.Unmarshal(b, &lockFile); err != nil {
+		return nil, nil, xerrors.Errorf("yaml decode error: %w", err)
+	}
+	delete(lockFile, "__metadata")
+
+	descriptors := map[string]string{}
+	for key := range lockFile {
+		for _, descriptor := range strings.Split(key, ",") {
+			descriptors[strings.TrimSpace(descriptor)] = key
+		}
+	}
+
+	resolve := func(name, version string) (string, bool) {
+		if key, ok := descriptors[name+"@"+version]; ok {
+			return key, true
+		}
+		// The default protocol is omitted in package.json
+		key, ok := descriptors[name+"@npm:"+version]
+		return key, ok
+	}
+
+	g := graph{}
+	var projects []project
+	for key, entry := range lockFile {
+		name, protocol, ref := parseYarnResolution(entry.Resolution)
+
+		var deps []string
+		for depName, depVersion := range entry.Dependencies {
+			if dep, ok := resolve(depName, depVersion); ok {
+				deps = append(deps, dep)
+			}
+		}
+
+		switch protocol {
+		case "workspace":
+			projects = append(projects, project{
+				path:   path.Clean(ref),
+				direct: deps,
+			})
+			g[key] = node{
+				name: name,
+				link: path.Clean(ref),
+			}
+		case "npm", "patch":
+			if protocol == "patch" && !strings.Contains(ref, "@npm%3A") {
+				continue
+			}
+			g[key] = node{
+				name:    name,
+				version: entry.Version,
+				deps:    deps,
+			}
+		}
+	}
+	return projects, g, nil
+}
+
+// parseYarnResolution splits the resolution, e.g. "@babel/core@npm:7.18.2", into the name, the protocol and the rest
+func parseYarnResolution(resolution string) (string, string, string) {
+	// The "@" of the scope is not the separator
+	offset := 0
+	if strings.HasPrefix(resolution, "@") {
+		offset = 1
+	}
+	i := strings.Index(resolution[offset:], "@")
+	if i < 0 {
+		return resolution, "", ""
+	}
+	i += offset
+	protocol, ref, _ := strings.Cut(resolution[i+1:], ":")
+	return resolution[:i], protocol, ref
+}
@@ -2,6 +2,7 @@ package oci
 
 import (
 	"context"
+	"crypto/x509"
 	"io"
 	"os"
 
@@ -13,10 +14,13 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/trivy/pkg/downloader"
+	"github.com/aquasecurity/trivy/pkg/progress"
+	"github.com/aquasecurity/trivy/pkg/utils"
 )
 
 type options struct {
-	img v1.Image
+	img     v1.Image
+	rootCAs *x509.CertPool
 }
 
 // Option is a functional option
@@ -29,8 +33,16 @@ func WithImage(img v1.Image) Option {
 	}
 }
 
+// WithRootCAs takes the CA certificates to verify the registry
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(opts *options) {
+		opts.rootCAs = pool
+	}
+}
+
 // Artifact is used to download artifacts such as vulnerability database and policies from OCI registries.
 type Artifact struct {
+	repo  string
 	image v1.Image
 	layer v1.Layer // Take the first layer as OCI artifact
 	quiet bool
@@ -50,7 +62,10 @@ func NewArtifact(repo, mediaType string, quiet bool, opts ...Option) (*Artifact,
 			return nil, xerrors.Errorf("repository name error (%s): %w", repo, err)
 		}
 
-		o.img, err = remote.Image(ref)
+		t := remote.DefaultTransport.Clone()
+		t.TLSClientConfig = utils.TLSClientConfig(t.TLSClientConfig, o.rootCAs, false)
+
+		o.img, err = remote.Image(ref, remote.WithTransport(t))
 		if err != nil {
 			return nil, xerrors.Errorf("OCI repository error: %w", err)
 		}
@@ -77,6 +92,7 @@ func NewArtifact(repo, mediaType string, quiet bool, opts ...Option) (*Artifact,
 	}
 
 	return &Artifact{
+		repo:  repo,
 		image: o.img,
 		layer: layer,
 		quiet: quiet,
@@ -103,6 +119,8 @@ func (a Artifact) Download(ctx context.Context, dir string) error {
 	pr := bar.NewProxyReader(rc)
 	defer bar.Finish()
 
+	tracker := progress.Start(progress.PhaseDownload, a.repo, size)
+
 	// https://github.com/hashicorp/go-getter/issues/326
 	f, err := os.CreateTemp("", "artifact-*.tar.gz")
 	if err != nil {
@@ -114,9 +132,10 @@ func (a Artifact) Download(ctx context.Context, dir string) error {
 	}()
 
 	// Download the layer content into a temporal file
-	if _, err = io.Copy(f, pr); err != nil {
+	if _, err = io.Copy(f, tracker.Reader(pr)); err != nil {
 		return xerrors.Errorf("copy error: %w", err)
 	}
+	tracker.Finish()
 
 	// Decompress artifact-xxx.tar.gz and copy it into the cache dir
 	if err = downloader.Download(ctx, f.Name(), dir, dir); err != nil {
@@ -0,0 +1,98 @@
+// Package osrelease detects the OS from os-release, including the families fanal doesn't know.
+package osrelease
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	aos "github.com/aquasecurity/fanal/analyzer/os"
+	// The analyzer of fanal is registered first, so that the one of the same type here replaces it
+	_ "github.com/aquasecurity/fanal/analyzer/os/release"
+	"github.com/aquasecurity/fanal/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&osReleaseAnalyzer{})
+}
+
+const (
+	// SLEMicro is the OS family of SUSE Linux Enterprise Micro, whose ID is "sle-micro" in os-release
+	SLEMicro = "sle-micro"
+
+	// The version is greater than that of fanal, so that the layers analyzed by fanal are analyzed again
+	version = 2
+)
+
+var requiredFiles = []string{
+	"etc/os-release",
+	"usr/lib/os-release",
+}
+
+// osReleaseAnalyzer replaces the analyzer of fanal, which doesn't know SLE Micro
+type osReleaseAnalyzer struct{}
+
+func (a osReleaseAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var id, versionID string
+	scanner := bufio.NewScanner(input.Content)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "ID":
+			id = strings.Trim(strings.TrimSpace(value), `"'`)
+		case "VERSION_ID":
+			versionID = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("unable to read os-release: %w", err)
+	}
+
+	family := osFamily(id)
+	if family == "" || versionID == "" {
+		return nil, nil
+	}
+	return &analyzer.AnalysisResult{
+		OS: &types.OS{Family: family, Name: versionID},
+	}, nil
+}
+
+func (a osReleaseAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return slices.Contains(requiredFiles, filePath)
+}
+
+func (a osReleaseAnalyzer) Type() analyzer.Type {
+	return analyzer.TypeOSRelease
+}
+
+func (a osReleaseAnalyzer) Version() int {
+	return version
+}
+
+// osFamily returns the OS family of the ID in os-release, or empty for the OS detected by the other analyzers
+func osFamily(id string) string {
+	switch id {
+	case "alpine":
+		return aos.Alpine
+	case "opensuse-tumbleweed":
+		return aos.OpenSUSETumbleweed
+	case "opensuse-leap", "opensuse": // opensuse for leap:42, opensuse-leap for leap:15
+		return aos.OpenSUSELeap
+	case "sles":
+		return aos.SLES
+	case "sle-micro":
+		return SLEMicro
+	case "photon":
+		return aos.Photon
+	}
+	return ""
+}
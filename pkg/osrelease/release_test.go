@@ -0,0 +1,63 @@
+package osrelease
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	aos "github.com/aquasecurity/fanal/analyzer/os"
+	"github.com/aquasecurity/fanal/types"
+)
+
+func Test_osReleaseAnalyzer_Analyze(t *testing.T) {
+	tests := []struct {
+		name      string
+		inputFile string
+		want      *analyzer.AnalysisResult
+	}{
+		{
+			name:      "SUSE Linux Enterprise Micro",
+			inputFile: "testdata/sle-micro",
+			want: &analyzer.AnalysisResult{
+				OS: &types.OS{Family: SLEMicro, Name: "5.1"},
+			},
+		},
+		{
+			name:      "SUSE Linux Enterprise Server",
+			inputFile: "testdata/sles",
+			want: &analyzer.AnalysisResult{
+				OS: &types.OS{Family: aos.SLES, Name: "15.3"},
+			},
+		},
+		{
+			name:      "Unknown OS",
+			inputFile: "testdata/unknown",
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.inputFile)
+			require.NoError(t, err)
+			defer f.Close()
+
+			got, err := osReleaseAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: "etc/os-release",
+				Content:  f,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_osReleaseAnalyzer_registered(t *testing.T) {
+	// The analyzer here replaces that of fanal
+	a := analyzer.NewAnalyzerGroup(analyzer.GroupBuiltin, nil)
+	versions := a.AnalyzerVersions()
+	assert.Equal(t, version, versions[string(analyzer.TypeOSRelease)])
+}
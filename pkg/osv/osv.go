@@ -0,0 +1,366 @@
+package osv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+const (
+	// DefaultURL is the API of OSV.dev
+	DefaultURL = "https://api.osv.dev"
+
+	// SourceID is the data source of the vulnerabilities detected with OSV
+	SourceID dbTypes.SourceID = "osv"
+
+	// cacheTTL is the period to reuse the responses, which is ignored with '--offline-scan'
+	cacheTTL = 24 * time.Hour
+
+	// batchSize is the maximum number of the queries per request of the batch API
+	batchSize = 1000
+
+	timeout = 30 * time.Second
+)
+
+// Ecosystems maps the application types which the DB doesn't cover to the ecosystems of OSV,
+// e.g. the applications found by the modules.
+// ref. https://ossf.github.io/osv-schema/#affectedpackage-field
+var Ecosystems = map[string]string{
+	"cran":    "CRAN",
+	"hackage": "Hackage",
+	"hex":     "Hex",
+	"pub":     "Pub",
+	"swift":   "SwiftURL",
+}
+
+// Supported returns true if the vulnerabilities of the application type are detected with OSV
+func Supported(appType string) bool {
+	_, ok := Ecosystems[appType]
+	return ok
+}
+
+// Client queries OSV for the vulnerabilities of the packages, caching the responses in the cache directory
+type Client struct {
+	url      string
+	cacheDir string
+	offline  bool
+	client   *http.Client
+}
+
+// NewClient returns the client of the API, which reads only the cache if opt.Offline is true
+func NewClient(opt types.OSVOption) Client {
+	u := opt.URL
+	if u == "" {
+		u = DefaultURL
+	}
+	return Client{
+		url:      strings.TrimSuffix(u, "/"),
+		cacheDir: filepath.Join(opt.CacheDir, "osv"),
+		offline:  opt.Offline,
+		client:   &http.Client{Timeout: timeout, Transport: utils.HTTPTransport(false)},
+	}
+}
+
+type query struct {
+	Package queryPackage `json:"package"`
+	Version string       `json:"version"`
+}
+
+type queryPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type batchRequest struct {
+	Queries []query `json:"queries"`
+}
+
+type batchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+// vulnerability is the subset of the OSV schema
+type vulnerability struct {
+	ID         string     `json:"id"`
+	Summary    string     `json:"summary"`
+	Details    string     `json:"details"`
+	Aliases    []string   `json:"aliases"`
+	Published  *time.Time `json:"published"`
+	Modified   *time.Time `json:"modified"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+	Affected []struct {
+		Package queryPackage `json:"package"`
+		Ranges  []struct {
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string   `json:"severity"`
+		CweIDs   []string `json:"cwe_ids"`
+	} `json:"database_specific"`
+}
+
+// Detect returns the vulnerabilities of the packages. The packages not in the cache are skipped when offline.
+func (c Client) Detect(appType string, pkgs []ftypes.Package) ([]types.DetectedVulnerability, error) {
+	ecosystem, ok := Ecosystems[appType]
+	if !ok {
+		return nil, xerrors.Errorf("unsupported type %s", appType)
+	}
+
+	var queries []query
+	for _, pkg := range pkgs {
+		if pkg.Version == "" {
+			continue
+		}
+		queries = append(queries, query{
+			Package: queryPackage{Name: pkg.Name, Ecosystem: ecosystem},
+			Version: pkg.Version,
+		})
+	}
+	ids, err := c.queryIDs(queries)
+	if err != nil {
+		return nil, xerrors.Errorf("OSV query error: %w", err)
+	}
+
+	var vulns []types.DetectedVulnerability
+	for _, pkg := range pkgs {
+		for _, id := range ids[c.queryKey(query{
+			Package: queryPackage{Name: pkg.Name, Ecosystem: ecosystem},
+			Version: pkg.Version,
+		})] {
+			v, err := c.vulnerability(id)
+			if err != nil {
+				return nil, xerrors.Errorf("OSV vulnerability error (%s): %w", id, err)
+			} else if v == nil {
+				continue
+			}
+			vuln := v.detected(pkg.Name, pkg.Version, ecosystem)
+			vuln.Layer = pkg.Layer
+			vuln.PkgPath = pkg.FilePath
+			vulns = append(vulns, vuln)
+		}
+	}
+	return vulns, nil
+}
+
+// queryIDs returns the IDs of the vulnerabilities per query from the cache, and queries the others in batches
+func (c Client) queryIDs(queries []query) (map[string][]string, error) {
+	ids := map[string][]string{}
+	var uncached []query
+	for _, q := range queries {
+		key := c.queryKey(q)
+		if _, ok := ids[key]; ok {
+			continue
+		}
+		var cached []string
+		if c.readCache(c.queryPath(key), &cached) {
+			ids[key] = cached
+			continue
+		}
+		ids[key] = nil
+		uncached = append(uncached, q)
+	}
+
+	if len(uncached) > 0 && c.offline {
+		log.Logger.Warnf("%d packages are not in the OSV cache and are not scanned with '--offline-scan'", len(uncached))
+		return ids, nil
+	}
+
+	for len(uncached) > 0 {
+		n := len(uncached)
+		if n > batchSize {
+			n = batchSize
+		}
+		batch := uncached[:n]
+		uncached = uncached[n:]
+
+		var res batchResponse
+		if err := c.post("/v1/querybatch", batchRequest{Queries: batch}, &res); err != nil {
+			return nil, xerrors.Errorf("batch query error: %w", err)
+		} else if len(res.Results) != len(batch) {
+			return nil, xerrors.Errorf("unexpected number of results: %d, want %d", len(res.Results), len(batch))
+		}
+
+		for i, q := range batch {
+			key := c.queryKey(q)
+			found := []string{}
+			for _, v := range res.Results[i].Vulns {
+				found = append(found, v.ID)
+			}
+			ids[key] = found
+			c.writeCache(c.queryPath(key), found)
+		}
+	}
+	return ids, nil
+}
+
+// vulnerability returns the details of the vulnerability, or nil if it is not in the cache when offline
+func (c Client) vulnerability(id string) (*vulnerability, error) {
+	path := filepath.Join(c.cacheDir, "vulns", url.PathEscape(id)+".json")
+	var v vulnerability
+	if c.readCache(path, &v) {
+		return &v, nil
+	} else if c.offline {
+		log.Logger.Debugf("%s is not in the OSV cache", id)
+		return nil, nil
+	}
+
+	if err := c.get("/v1/vulns/"+url.PathEscape(id), &v); err != nil {
+		return nil, err
+	}
+	c.writeCache(path, v)
+	return &v, nil
+}
+
+func (c Client) post(path string, body, v interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return xerrors.Errorf("JSON encode error: %w", err)
+	}
+	res, err := c.client.Post(c.url+path, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return xerrors.Errorf("HTTP error: %w", err)
+	}
+	return decodeResponse(res, v)
+}
+
+func (c Client) get(path string, v interface{}) error {
+	res, err := c.client.Get(c.url + path)
+	if err != nil {
+		return xerrors.Errorf("HTTP error: %w", err)
+	}
+	return decodeResponse(res, v)
+}
+
+func decodeResponse(res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024)) // nolint: errcheck
+		return xerrors.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(b)))
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return xerrors.Errorf("JSON decode error: %w", err)
+	}
+	return nil
+}
+
+// queryKey identifies the package in the cache, as the names can't be the file names, e.g. the URLs of SwiftURL
+func (c Client) queryKey(q query) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(q.Package.Ecosystem+"/"+q.Package.Name+"@"+q.Version)))
+}
+
+func (c Client) queryPath(key string) string {
+	return filepath.Join(c.cacheDir, "queries", key+".json")
+}
+
+// readCache returns true if the cache is fresh, or exists when offline
+func (c Client) readCache(path string, v interface{}) bool {
+	fi, err := os.Stat(path)
+	if err != nil || (!c.offline && time.Since(fi.ModTime()) > cacheTTL) {
+		return false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, v) == nil
+}
+
+// writeCache doesn't fail the scan, as the response is queried again next time
+func (c Client) writeCache(path string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			err = os.WriteFile(path, b, 0600)
+		}
+	}
+	if err != nil {
+		log.Logger.Debugf("OSV cache error: %s", err)
+	}
+}
+
+// detected returns the vulnerability of the package.
+// The CVE ID is preferred to the OSV ID, so that the ignore files and the DB work with the vulnerability.
+func (v vulnerability) detected(pkgName, pkgVer, ecosystem string) types.DetectedVulnerability {
+	id := v.ID
+	for _, alias := range v.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			id = alias
+			break
+		}
+	}
+
+	var fixed, refs []string
+	for _, affected := range v.Affected {
+		if affected.Package.Ecosystem != ecosystem || affected.Package.Name != pkgName {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if f, ok := event["fixed"]; ok && !slices.Contains(fixed, f) {
+					fixed = append(fixed, f)
+				}
+			}
+		}
+	}
+	for _, ref := range v.References {
+		refs = append(refs, ref.URL)
+	}
+
+	return types.DetectedVulnerability{
+		VulnerabilityID:  id,
+		PkgName:          pkgName,
+		InstalledVersion: pkgVer,
+		FixedVersion:     strings.Join(fixed, ", "),
+		PrimaryURL:       "https://osv.dev/vulnerability/" + v.ID,
+		DataSource: &dbTypes.DataSource{
+			ID:   SourceID,
+			Name: "OSV",
+			URL:  "https://osv.dev",
+		},
+		Vulnerability: dbTypes.Vulnerability{
+			Title:            v.Summary,
+			Description:      v.Details,
+			Severity:         severity(v.DatabaseSpecific.Severity),
+			CweIDs:           v.DatabaseSpecific.CweIDs,
+			References:       refs,
+			PublishedDate:    v.Published,
+			LastModifiedDate: v.Modified,
+		},
+	}
+}
+
+// severity converts the severity of GitHub Security Advisories, e.g. "MODERATE", which is the most common in OSV
+func severity(s string) string {
+	s = strings.ToUpper(s)
+	if s == "MODERATE" {
+		return dbTypes.SeverityMedium.String()
+	}
+	if _, err := dbTypes.NewSeverity(s); err != nil {
+		return dbTypes.SeverityUnknown.String()
+	}
+	return s
+}
@@ -0,0 +1,155 @@
+package osv_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/osv"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const vulnJSON = `{
+  "id": "GHSA-4xqq-73wg-5mjp",
+  "summary": "shelf vulnerable to path traversal",
+  "aliases": ["CVE-2023-1234"],
+  "published": "2023-01-10T00:00:00Z",
+  "references": [{"type": "WEB", "url": "https://github.com/dart-lang/shelf/security/advisories/GHSA-4xqq-73wg-5mjp"}],
+  "affected": [
+    {
+      "package": {"ecosystem": "Pub", "name": "shelf"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.4.1"}]}]
+    },
+    {
+      "package": {"ecosystem": "Pub", "name": "shelf_static"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.1.2"}]}]
+    }
+  ],
+  "database_specific": {"severity": "MODERATE", "cwe_ids": ["CWE-22"]}
+}`
+
+func newServer(t *testing.T, requests *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/querybatch", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		var req struct {
+			Queries []struct {
+				Package struct {
+					Name      string `json:"name"`
+					Ecosystem string `json:"ecosystem"`
+				} `json:"package"`
+				Version string `json:"version"`
+			} `json:"queries"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		type result struct {
+			Vulns []map[string]string `json:"vulns"`
+		}
+		var results []result
+		for _, q := range req.Queries {
+			if q.Package.Ecosystem == "Pub" && q.Package.Name == "shelf" && q.Version == "1.4.0" {
+				results = append(results, result{Vulns: []map[string]string{{"id": "GHSA-4xqq-73wg-5mjp"}}})
+			} else {
+				results = append(results, result{})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	})
+	mux.HandleFunc("/v1/vulns/GHSA-4xqq-73wg-5mjp", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		_, _ = w.Write([]byte(vulnJSON))
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient_Detect(t *testing.T) {
+	pkgs := []ftypes.Package{
+		{Name: "shelf", Version: "1.4.0", FilePath: "pubspec.lock"},
+		{Name: "http", Version: "0.13.5", FilePath: "pubspec.lock"},
+	}
+	published := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	want := []types.DetectedVulnerability{
+		{
+			VulnerabilityID:  "CVE-2023-1234",
+			PkgName:          "shelf",
+			PkgPath:          "pubspec.lock",
+			InstalledVersion: "1.4.0",
+			FixedVersion:     "1.4.1",
+			PrimaryURL:       "https://osv.dev/vulnerability/GHSA-4xqq-73wg-5mjp",
+			DataSource: &dbTypes.DataSource{
+				ID:   osv.SourceID,
+				Name: "OSV",
+				URL:  "https://osv.dev",
+			},
+			Vulnerability: dbTypes.Vulnerability{
+				Title:         "shelf vulnerable to path traversal",
+				Severity:      "MEDIUM",
+				CweIDs:        []string{"CWE-22"},
+				References:    []string{"https://github.com/dart-lang/shelf/security/advisories/GHSA-4xqq-73wg-5mjp"},
+				PublishedDate: &published,
+			},
+		},
+	}
+
+	var requests int32
+	ts := newServer(t, &requests)
+	cacheDir := t.TempDir()
+
+	t.Run("query", func(t *testing.T) {
+		c := osv.NewClient(types.OSVOption{Enabled: true, URL: ts.URL, CacheDir: cacheDir})
+		got, err := c.Detect("pub", pkgs)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		c := osv.NewClient(types.OSVOption{Enabled: true, URL: ts.URL, CacheDir: cacheDir})
+		got, err := c.Detect("pub", pkgs)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("offline with the cache", func(t *testing.T) {
+		c := osv.NewClient(types.OSVOption{Enabled: true, URL: "http://localhost:0", CacheDir: cacheDir, Offline: true})
+		got, err := c.Detect("pub", pkgs)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("offline without the cache", func(t *testing.T) {
+		c := osv.NewClient(types.OSVOption{Enabled: true, URL: "http://localhost:0", CacheDir: t.TempDir(), Offline: true})
+		got, err := c.Detect("pub", pkgs)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		c := osv.NewClient(types.OSVOption{Enabled: true, URL: ts.URL, CacheDir: t.TempDir()})
+		_, err := c.Detect("npm", pkgs)
+		assert.ErrorContains(t, err, "unsupported type npm")
+	})
+}
+
+func TestClient_Detect_error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	c := osv.NewClient(types.OSVOption{Enabled: true, URL: ts.URL, CacheDir: t.TempDir()})
+	_, err := c.Detect("hex", []ftypes.Package{{Name: "plug", Version: "1.14.0"}})
+	assert.ErrorContains(t, err, "unexpected status 429 Too Many Requests: rate limited")
+}
@@ -0,0 +1,181 @@
+// Package php parses composer.lock with the development dependencies and the dependency graph,
+// and records the dependencies declared in composer.json of the projects.
+package php
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/language"
+	// The analyzer of fanal is registered first, so that the one of the same type here replaces it
+	_ "github.com/aquasecurity/fanal/analyzer/language/php/composer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&composerAnalyzer{})
+	analyzer.RegisterAnalyzer(&manifestAnalyzer{})
+}
+
+const (
+	// TypeManifest records the dependencies declared in composer.json of the projects
+	TypeManifest = analyzer.Type("composer-manifest")
+
+	// ComposerJSON is the manifest of Composer
+	ComposerJSON = "composer.json"
+
+	// The version is greater than that of fanal, so that the layers analyzed by fanal are analyzed again
+	composerVersion = 2
+
+	manifestVersion = 1
+)
+
+// composerLock is composer.lock, which has the development dependencies in "packages-dev"
+type composerLock struct {
+	Packages    []composerPackage `json:"packages"`
+	PackagesDev []composerPackage `json:"packages-dev"`
+}
+
+type composerPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Require map[string]string `json:"require"`
+}
+
+// composerAnalyzer replaces the analyzer of fanal, which drops "packages-dev" and the dependency graph
+type composerAnalyzer struct{}
+
+func (a composerAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var lockFile composerLock
+	if err := json.NewDecoder(input.Content).Decode(&lockFile); err != nil {
+		return nil, xerrors.Errorf("unable to decode composer.lock: %w", err)
+	}
+
+	// The requirements of the platform, e.g. "php" and "ext-json", are not in the lock file
+	versions := map[string]string{}
+	for _, pkg := range append(lockFile.Packages, lockFile.PackagesDev...) {
+		versions[pkg.Name] = pkg.Version
+	}
+
+	var libs []godeptypes.Library
+	var deps []godeptypes.Dependency
+	var dev []string
+	for i, pkg := range append(lockFile.Packages, lockFile.PackagesDev...) {
+		id := packageID(pkg.Name, pkg.Version)
+		libs = append(libs, godeptypes.Library{
+			ID:      id,
+			Name:    pkg.Name,
+			Version: pkg.Version,
+		})
+		if i >= len(lockFile.Packages) {
+			dev = append(dev, id)
+		}
+
+		var dependsOn []string
+		for name := range pkg.Require {
+			if version, ok := versions[name]; ok {
+				dependsOn = append(dependsOn, packageID(name, version))
+			}
+		}
+		if len(dependsOn) > 0 {
+			sort.Strings(dependsOn)
+			deps = append(deps, godeptypes.Dependency{ID: id, DependsOn: dependsOn})
+		}
+	}
+
+	res := language.ToAnalysisResult(ftypes.Composer, input.FilePath, "", libs, deps)
+	if res == nil {
+		return nil, nil
+	}
+	res.CustomResources = []ftypes.CustomResource{
+		{
+			Type:     types.PackageScopeType,
+			FilePath: input.FilePath,
+			Data:     types.PackageScope{Dev: dev},
+		},
+	}
+	return res, nil
+}
+
+func (a composerAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filepath.Base(filePath) == ftypes.ComposerLock
+}
+
+func (a composerAnalyzer) Type() analyzer.Type {
+	return analyzer.TypeComposer
+}
+
+func (a composerAnalyzer) Version() int {
+	return composerVersion
+}
+
+// composerManifest is composer.json of the project
+type composerManifest struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+// manifestAnalyzer records "require" and "require-dev" of composer.json, which tell the direct and
+// the development dependencies of composer.lock in the same directory
+type manifestAnalyzer struct{}
+
+func (a manifestAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var manifest composerManifest
+	if err := json.NewDecoder(input.Content).Decode(&manifest); err != nil {
+		return nil, xerrors.Errorf("unable to decode composer.json: %w", err)
+	}
+	if len(manifest.Require) == 0 && len(manifest.RequireDev) == 0 {
+		return nil, nil
+	}
+
+	data := types.PackageManifest{
+		Dependencies:    maps.Keys(manifest.Require),
+		DevDependencies: maps.Keys(manifest.RequireDev),
+	}
+	sort.Strings(data.Dependencies)
+	sort.Strings(data.DevDependencies)
+	return &analyzer.AnalysisResult{
+		CustomResources: []ftypes.CustomResource{
+			{
+				Type:     types.PackageManifestType,
+				FilePath: input.FilePath,
+				Data:     data,
+			},
+		},
+	}, nil
+}
+
+func (a manifestAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	if filepath.Base(filePath) != ComposerJSON {
+		return false
+	}
+	// The installed packages have their own composer.json under vendor
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+		if dir == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+func (a manifestAnalyzer) Type() analyzer.Type {
+	return TypeManifest
+}
+
+func (a manifestAnalyzer) Version() int {
+	return manifestVersion
+}
+
+func packageID(name, version string) string {
+	return name + "@" + version
+}
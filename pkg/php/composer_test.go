@@ -0,0 +1,109 @@
+package php
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestComposerAnalyzer_Analyze(t *testing.T) {
+	f, err := os.Open("testdata/composer.lock")
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, err := composerAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+		FilePath: "app/composer.lock",
+		Content:  f,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &analyzer.AnalysisResult{
+		Applications: []ftypes.Application{
+			{
+				Type:     ftypes.Composer,
+				FilePath: "app/composer.lock",
+				Libraries: []ftypes.Package{
+					{ID: "guzzlehttp/guzzle@7.4.2", Name: "guzzlehttp/guzzle", Version: "7.4.2"},
+					{ID: "guzzlehttp/psr7@2.2.1", Name: "guzzlehttp/psr7", Version: "2.2.1"},
+					{ID: "phpunit/phpunit@9.5.20", Name: "phpunit/phpunit", Version: "9.5.20"},
+				},
+				Dependencies: []godeptypes.Dependency{
+					{ID: "guzzlehttp/guzzle@7.4.2", DependsOn: []string{"guzzlehttp/psr7@2.2.1"}},
+				},
+			},
+		},
+		CustomResources: []ftypes.CustomResource{
+			{
+				Type:     types.PackageScopeType,
+				FilePath: "app/composer.lock",
+				Data:     types.PackageScope{Dev: []string{"phpunit/phpunit@9.5.20"}},
+			},
+		},
+	}, got)
+}
+
+func TestManifestAnalyzer_Analyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *analyzer.AnalysisResult
+	}{
+		{
+			name: "happy path",
+			content: `{
+  "require": {"php": "^8.0", "guzzlehttp/guzzle": "^7.4"},
+  "require-dev": {"phpunit/phpunit": "^9.5"}
+}`,
+			want: &analyzer.AnalysisResult{
+				CustomResources: []ftypes.CustomResource{
+					{
+						Type:     types.PackageManifestType,
+						FilePath: "app/composer.json",
+						Data: types.PackageManifest{
+							Dependencies:    []string{"guzzlehttp/guzzle", "php"},
+							DevDependencies: []string{"phpunit/phpunit"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "no dependencies",
+			content: `{"name": "app/app"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manifestAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: "app/composer.json",
+				Content:  strings.NewReader(tt.content),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestManifestAnalyzer_Required(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{filePath: "app/composer.json", want: true},
+		{filePath: "app/vendor/guzzlehttp/guzzle/composer.json", want: false},
+		{filePath: "app/composer.lock", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			assert.Equal(t, tt.want, manifestAnalyzer{}.Required(tt.filePath, nil))
+		})
+	}
+}
@@ -20,6 +20,9 @@ import (
 const (
 	configFile  = "plugin.yaml"
 	xdgDataHome = "XDG_DATA_HOME"
+
+	// pluginDirEnv tells the plugin where its files are installed
+	pluginDirEnv = "TRIVY_PLUGIN_DIR"
 )
 
 var (
@@ -58,20 +61,33 @@ type Selector struct {
 	Arch string
 }
 
+// RunOptions holds the options for running a plugin
+type RunOptions struct {
+	Args []string
+
+	// Env holds the scan context passed to the plugin in addition to the current environment,
+	// e.g. "TRIVY_CACHE_DIR=/path/to/cache"
+	Env []string
+}
+
 // Run runs the plugin
-func (p Plugin) Run(ctx context.Context, args []string) error {
+func (p Plugin) Run(ctx context.Context, opts RunOptions) error {
 	platform, err := p.selectPlatform()
 	if err != nil {
 		return xerrors.Errorf("platform selection error: %w", err)
 	}
 
-	execFile := filepath.Join(dir(), p.Name, platform.Bin)
+	pluginDir := filepath.Join(dir(), p.Name)
+	execFile := filepath.Join(pluginDir, platform.Bin)
 
-	cmd := exec.CommandContext(ctx, execFile, args...)
+	cmd := exec.CommandContext(ctx, execFile, opts.Args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Env = os.Environ()
+
+	// The later values take precedence over the current environment
+	cmd.Env = append(os.Environ(), pluginDirEnv+"="+pluginDir)
+	cmd.Env = append(cmd.Env, opts.Env...)
 
 	// If an error is found during the execution of the plugin, figure
 	// out if the error was from not being able to execute the plugin or
@@ -26,6 +26,7 @@ func TestPlugin_Run(t *testing.T) {
 	}
 	type args struct {
 		args []string
+		env  []string
 	}
 	tests := []struct {
 		name    string
@@ -94,6 +95,25 @@ func TestPlugin_Run(t *testing.T) {
 			},
 			wantErr: "platform not found",
 		},
+		{
+			name: "scan context",
+			fields: fields{
+				Name:        "env_plugin",
+				Repository:  "github.com/aquasecurity/trivy-plugin-env",
+				Version:     "0.1.0",
+				Usage:       "test",
+				Description: "test",
+				Platforms: []plugin.Platform{
+					{
+						URI: "github.com/aquasecurity/trivy-plugin-env",
+						Bin: "test.sh",
+					},
+				},
+			},
+			args: args{
+				env: []string{"TRIVY_CACHE_DIR=/tmp/cache"},
+			},
+		},
 		{
 			name: "no execution file",
 			fields: fields{
@@ -157,7 +177,7 @@ func TestPlugin_Run(t *testing.T) {
 				GOARCH:      tt.fields.GOARCH,
 			}
 
-			err := p.Run(context.Background(), tt.args.args)
+			err := p.Run(context.Background(), plugin.RunOptions{Args: tt.args.args, Env: tt.args.env})
 			if tt.wantErr != "" {
 				require.NotNil(t, err)
 				assert.Contains(t, err.Error(), tt.wantErr)
@@ -0,0 +1,3 @@
+#!/bin/sh
+
+[ "$TRIVY_PLUGIN_DIR" = "testdata/.trivy/plugins/env_plugin" ] && [ "$TRIVY_CACHE_DIR" = "/tmp/cache" ]
\ No newline at end of file
@@ -0,0 +1,200 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// FormatBar shows the progress bars for humans
+	FormatBar = "bar"
+
+	// FormatJSON emits the progress events as JSON lines instead of the progress bars
+	FormatJSON = "json"
+)
+
+// Formats has the values of '--progress'
+var Formats = []string{FormatBar, FormatJSON}
+
+// Phase is the step of a scan that progresses
+type Phase string
+
+const (
+	// PhaseDownload downloads the DB or the policy bundle
+	PhaseDownload Phase = "download"
+
+	// PhaseAnalysis analyzes the artifact, e.g. walks the filesystem
+	PhaseAnalysis Phase = "analysis"
+
+	// PhaseLayerAnalysis analyzes the layers of the image missing in the cache
+	PhaseLayerAnalysis Phase = "layer-analysis"
+
+	// PhaseDetection detects the vulnerabilities, misconfigurations and so on in the analysis result
+	PhaseDetection Phase = "detection"
+
+	// PhaseResourceScan scans the resources of the cluster
+	PhaseResourceScan Phase = "resource-scan"
+)
+
+// Event is emitted as a JSON line when a phase starts, progresses and finishes
+type Event struct {
+	Time   time.Time
+	Phase  Phase
+	Target string `json:",omitempty"`
+
+	// Layer is the diff ID of the layer analyzed in "layer-analysis"
+	Layer string `json:",omitempty"`
+
+	// Current and Total are the number of the items or the bytes, and Total is 0 when it is unknown
+	Current int64
+	Total   int64
+	Percent float64
+
+	// ETA is the estimated number of seconds to finish the phase
+	ETA int64 `json:",omitempty"`
+
+	Done bool `json:",omitempty"`
+}
+
+var (
+	mu sync.Mutex
+	w  io.Writer
+)
+
+// SetWriter sets the writer of the events, and nil disables the events
+func SetWriter(writer io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	w = writer
+}
+
+// Enabled returns true if the events are emitted
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return w != nil
+}
+
+func emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if w == nil {
+		return
+	}
+	// The events are best effort, and they must not fail scans
+	_ = json.NewEncoder(w).Encode(e) // nolint: errcheck
+}
+
+// Tracker emits the events of a phase
+type Tracker struct {
+	phase  Phase
+	target string
+	total  int64
+	start  time.Time
+
+	mu          sync.Mutex
+	current     int64
+	lastPercent float64
+}
+
+// Start emits the event that the phase starts, and returns the tracker of it.
+// total is the number of the items or the bytes processed in the phase, or 0 if it is unknown.
+func Start(phase Phase, target string, total int64) *Tracker {
+	t := &Tracker{
+		phase:       phase,
+		target:      target,
+		total:       total,
+		start:       time.Now(),
+		lastPercent: -1,
+	}
+	if Enabled() {
+		emit(t.event(""))
+	}
+	return t
+}
+
+// Add adds n items and emits the event, with the layer if it is not empty
+func (t *Tracker) Add(n int64, layer string) {
+	if !Enabled() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current += n
+	emit(t.event(layer))
+}
+
+// Reader returns the reader adding the bytes read.
+// The events are emitted only when the percent changes by 1 or more, as reads are frequent.
+func (t *Tracker) Reader(r io.Reader) io.Reader {
+	return &reader{Reader: r, tracker: t}
+}
+
+func (t *Tracker) addBytes(n int64) {
+	if !Enabled() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current += n
+	e := t.event("")
+	if math.Floor(e.Percent) <= math.Floor(t.lastPercent) {
+		return
+	}
+	t.lastPercent = e.Percent
+	emit(e)
+}
+
+// Finish emits the event that the phase finishes
+func (t *Tracker) Finish() {
+	if !Enabled() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.total > 0 {
+		t.current = t.total
+	}
+	e := t.event("")
+	e.Percent = 100
+	e.ETA = 0
+	e.Done = true
+	emit(e)
+}
+
+// event must be called with the lock held
+func (t *Tracker) event(layer string) Event {
+	e := Event{
+		Time:    time.Now(),
+		Phase:   t.phase,
+		Target:  t.target,
+		Layer:   layer,
+		Current: t.current,
+		Total:   t.total,
+	}
+	if t.total <= 0 {
+		return e
+	}
+
+	e.Percent = math.Round(float64(t.current)/float64(t.total)*1000) / 10
+	if t.current > 0 && t.current < t.total {
+		elapsed := time.Since(t.start)
+		remaining := time.Duration(float64(elapsed) / float64(t.current) * float64(t.total-t.current))
+		e.ETA = int64(math.Ceil(remaining.Seconds()))
+	}
+	return e
+}
+
+type reader struct {
+	io.Reader
+	tracker *Tracker
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.tracker.addBytes(int64(n))
+	return n, err
+}
@@ -0,0 +1,103 @@
+package progress_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/progress"
+)
+
+func TestTracker(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int64
+		run   func(tracker *progress.Tracker)
+		want  []progress.Event
+	}{
+		{
+			name:  "layers",
+			total: 2,
+			run: func(tracker *progress.Tracker) {
+				tracker.Add(1, "sha256:aaa")
+				tracker.Add(1, "sha256:bbb")
+				tracker.Finish()
+			},
+			want: []progress.Event{
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 0, Total: 2},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Layer: "sha256:aaa", Current: 1, Total: 2, Percent: 50},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Layer: "sha256:bbb", Current: 2, Total: 2, Percent: 100},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 2, Total: 2, Percent: 100, Done: true},
+			},
+		},
+		{
+			name:  "bytes",
+			total: 300,
+			run: func(tracker *progress.Tracker) {
+				// Read 100 bytes at a time
+				b := make([]byte, 100)
+				r := tracker.Reader(strings.NewReader(strings.Repeat("a", 300)))
+				for {
+					if _, err := r.Read(b); err == io.EOF {
+						break
+					}
+				}
+				tracker.Finish()
+			},
+			want: []progress.Event{
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 0, Total: 300},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 100, Total: 300, Percent: 33.3},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 200, Total: 300, Percent: 66.7},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 300, Total: 300, Percent: 100},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Current: 300, Total: 300, Percent: 100, Done: true},
+			},
+		},
+		{
+			name:  "unknown total",
+			total: 0,
+			run: func(tracker *progress.Tracker) {
+				tracker.Finish()
+			},
+			want: []progress.Event{
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16"},
+				{Phase: progress.PhaseLayerAnalysis, Target: "alpine:3.16", Percent: 100, Done: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			progress.SetWriter(&buf)
+			defer progress.SetWriter(nil)
+
+			tt.run(progress.Start(progress.PhaseLayerAnalysis, "alpine:3.16", tt.total))
+
+			var got []progress.Event
+			d := json.NewDecoder(&buf)
+			for d.More() {
+				var e progress.Event
+				require.NoError(t, d.Decode(&e))
+				assert.False(t, e.Time.IsZero())
+				e.Time, e.ETA = time.Time{}, 0 // They depend on the clock
+				got = append(got, e)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTracker_disabled(t *testing.T) {
+	tracker := progress.Start(progress.PhaseDownload, "ghcr.io/aquasecurity/trivy-db", 10)
+	b, err := io.ReadAll(tracker.Reader(strings.NewReader("0123456789")))
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(b))
+	tracker.Add(1, "")
+	tracker.Finish()
+	assert.False(t, progress.Enabled())
+}
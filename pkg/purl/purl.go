@@ -11,6 +11,8 @@ import (
 	"github.com/aquasecurity/fanal/analyzer"
 	"github.com/aquasecurity/fanal/analyzer/os"
 	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/fingerprint"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
 	"github.com/aquasecurity/trivy/pkg/scanner/utils"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
@@ -48,7 +50,7 @@ func NewPackageURL(t string, metadata types.Metadata, pkg ftypes.Package) (Packa
 		qualifiers = parseQualifier(pkg)
 	}
 
-	ptype := purlType(t)
+	ptype := Type(t)
 	name := pkg.Name
 	version := utils.FormatVersion(pkg)
 	namespace := ""
@@ -195,7 +197,8 @@ func parseNpm(pkgName string) (string, string) {
 	return parsePkgName(name)
 }
 
-func purlType(t string) string {
+// Type returns the PURL type of the packages in the result type, e.g. "npm" for "yarn" and "rpm" for "redhat"
+func Type(t string) string {
 	switch t {
 	case string(analyzer.TypeJar), string(analyzer.TypePom):
 		return packageurl.TypeMaven
@@ -205,8 +208,10 @@ func purlType(t string) string {
 		return packageurl.TypePyPi
 	case string(analyzer.TypeGoBinary), string(analyzer.TypeGoMod):
 		return packageurl.TypeGolang
-	case string(analyzer.TypeNpmPkgLock), string(analyzer.TypeNodePkg), string(analyzer.TypeYarn):
+	case string(analyzer.TypeNpmPkgLock), string(analyzer.TypeNodePkg), string(analyzer.TypeYarn), nodejs.Pnpm:
 		return packageurl.TypeNPM
+	case fingerprint.StaticLibrary:
+		return packageurl.TypeConan
 	case os.Alpine:
 		return string(analyzer.TypeApk)
 	case os.Debian, os.Ubuntu:
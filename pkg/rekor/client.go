@@ -0,0 +1,186 @@
+// Package rekor looks up the in-toto attestations in the Rekor transparency log, e.g. uploaded by "cosign attest"
+package rekor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+const (
+	// DefaultURL is the public instance of Rekor operated by Sigstore
+	DefaultURL = "https://rekor.sigstore.dev"
+
+	// retrieveLimit is the maximum number of the entries Rekor returns at once
+	retrieveLimit = 10
+
+	// requestTimeout is the timeout of each request to Rekor
+	requestTimeout = 30 * time.Second
+)
+
+// Entry is the in-toto attestation in the log
+type Entry struct {
+	UUID string
+
+	// PublicKey is the PEM of the key or the certificate verifying the attestation
+	PublicKey []byte
+
+	// Statement is the in-toto statement, which Rekor stores only if it is small enough
+	Statement []byte
+}
+
+// Client looks up the entries in Rekor
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+// NewClient returns the client of Rekor at the URL, which trusts the CA bundle.
+// The certificate of Rekor is not verified if insecure is true.
+func NewClient(rekorURL string, insecure bool) Client {
+	return Client{
+		url: strings.TrimSuffix(rekorURL, "/"),
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: utils.HTTPTransport(insecure),
+		},
+	}
+}
+
+// Search returns the UUIDs of the entries indexed by the digest, e.g. "sha256:<hex>" of the subject of the attestations
+func (c Client) Search(ctx context.Context, digest string) ([]string, error) {
+	var uuids []string
+	if err := c.post(ctx, "/api/v1/index/retrieve", map[string]string{"hash": digest}, &uuids); err != nil {
+		return nil, xerrors.Errorf("index search error: %w", err)
+	}
+	return uuids, nil
+}
+
+// GetEntries returns the in-toto attestations of the entries.
+// The other kinds of entries and the attestations not stored in the log are skipped.
+func (c Client) GetEntries(ctx context.Context, uuids []string) ([]Entry, error) {
+	var entries []Entry
+	for len(uuids) > 0 {
+		n := retrieveLimit
+		if len(uuids) < n {
+			n = len(uuids)
+		}
+
+		var resp []map[string]logEntry
+		if err := c.post(ctx, "/api/v1/log/entries/retrieve", map[string][]string{"entryUUIDs": uuids[:n]}, &resp); err != nil {
+			return nil, xerrors.Errorf("entry retrieve error: %w", err)
+		}
+		for _, m := range resp {
+			for uuid, e := range m {
+				entry, err := e.intoto()
+				if err != nil {
+					log.Logger.Debugf("Skipping the Rekor entry %s: %s", uuid, err)
+					continue
+				}
+				entry.UUID = uuid
+				entries = append(entries, entry)
+			}
+		}
+		uuids = uuids[n:]
+	}
+	return entries, nil
+}
+
+func (c Client) post(ctx context.Context, path string, body, v interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal json: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(b))
+	if err != nil {
+		return xerrors.Errorf("unable to create a request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return xerrors.Errorf("rekor returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return xerrors.Errorf("rekor response decode error: %w", err)
+	}
+	return nil
+}
+
+// logEntry is the entry of the log, whose body depends on the kind
+type logEntry struct {
+	Body        string `json:"body"`
+	Attestation struct {
+		Data string `json:"data"`
+	} `json:"attestation"`
+}
+
+// intotoBody is the body of the entries of the "intoto" kind, version 0.0.1
+type intotoBody struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Content struct {
+			PayloadHash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"payloadHash"`
+		} `json:"content"`
+		PublicKey string `json:"publicKey"`
+	} `json:"spec"`
+}
+
+func (e logEntry) intoto() (Entry, error) {
+	b, err := base64.StdEncoding.DecodeString(e.Body)
+	if err != nil {
+		return Entry{}, xerrors.Errorf("body decode error: %w", err)
+	}
+	var body intotoBody
+	if err = json.Unmarshal(b, &body); err != nil {
+		return Entry{}, xerrors.Errorf("body decode error: %w", err)
+	}
+	if body.Kind != "intoto" || body.APIVersion != "0.0.1" {
+		return Entry{}, xerrors.Errorf("unsupported entry: %s %s", body.Kind, body.APIVersion)
+	}
+	if e.Attestation.Data == "" {
+		return Entry{}, xerrors.New("the attestation is not stored in the log")
+	}
+
+	statement, err := base64.StdEncoding.DecodeString(e.Attestation.Data)
+	if err != nil {
+		return Entry{}, xerrors.Errorf("attestation decode error: %w", err)
+	}
+	// The attestation is stored apart from the entry signed by Rekor
+	sum := sha256.Sum256(statement)
+	if body.Spec.Content.PayloadHash.Algorithm != "sha256" || body.Spec.Content.PayloadHash.Value != hex.EncodeToString(sum[:]) {
+		return Entry{}, xerrors.New("the attestation doesn't match the payload hash")
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(body.Spec.PublicKey)
+	if err != nil {
+		return Entry{}, xerrors.Errorf("public key decode error: %w", err)
+	}
+	return Entry{
+		PublicKey: publicKey,
+		Statement: statement,
+	}, nil
+}
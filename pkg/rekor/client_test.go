@@ -0,0 +1,119 @@
+package rekor_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/rekor"
+)
+
+const testDigest = "sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6"
+
+func logEntry(kind, apiVersion string, statement []byte, payloadHash string) map[string]interface{} {
+	body, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"spec": map[string]interface{}{
+			"content": map[string]interface{}{
+				"payloadHash": map[string]string{"algorithm": "sha256", "value": payloadHash},
+			},
+			"publicKey": base64.StdEncoding.EncodeToString([]byte("-----BEGIN PUBLIC KEY-----")),
+		},
+	})
+	return map[string]interface{}{
+		"body":        base64.StdEncoding.EncodeToString(body),
+		"attestation": map[string]string{"data": base64.StdEncoding.EncodeToString(statement)},
+	}
+}
+
+func TestClient(t *testing.T) {
+	statement := []byte(`{"_type":"https://in-toto.io/Statement/v0.1"}`)
+	sum := sha256.Sum256(statement)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	// More entries than Rekor returns at once
+	entries := map[string]map[string]interface{}{}
+	var uuids []string
+	for i := 0; i < 12; i++ {
+		uuid := fmt.Sprintf("uuid-%02d", i)
+		uuids = append(uuids, uuid)
+		entries[uuid] = logEntry("intoto", "0.0.1", statement, payloadHash)
+	}
+	entries["uuid-00"] = logEntry("hashedrekord", "0.0.1", statement, payloadHash)
+	entries["uuid-01"] = logEntry("intoto", "0.0.1", statement, "0000")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/index/retrieve":
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req["hash"] != testDigest {
+				_ = json.NewEncoder(w).Encode([]string{})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(uuids)
+		case "/api/v1/log/entries/retrieve":
+			var req map[string][]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if len(req["entryUUIDs"]) > 10 {
+				http.Error(w, "too many entries", http.StatusUnprocessableEntity)
+				return
+			}
+			var resp []map[string]interface{}
+			for _, uuid := range req["entryUUIDs"] {
+				resp = append(resp, map[string]interface{}{uuid: entries[uuid]})
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := rekor.NewClient(ts.URL+"/", false)
+	got, err := c.Search(context.Background(), testDigest)
+	require.NoError(t, err)
+	assert.Equal(t, uuids, got)
+
+	got, err = c.Search(context.Background(), "sha256:0000")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	gotEntries, err := c.GetEntries(context.Background(), uuids)
+	require.NoError(t, err)
+	// The entry of another kind and the entry not matching the payload hash are skipped
+	require.Len(t, gotEntries, 10)
+	assert.Equal(t, rekor.Entry{
+		UUID:      "uuid-02",
+		PublicKey: []byte("-----BEGIN PUBLIC KEY-----"),
+		Statement: statement,
+	}, gotEntries[0])
+
+	_, err = rekor.NewClient(ts.URL+"/unknown", false).Search(context.Background(), testDigest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rekor returned 404 Not Found")
+}
+
+func TestClient_Insecure(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]string{"uuid-01"})
+	}))
+	defer ts.Close()
+
+	_, err := rekor.NewClient(ts.URL, false).Search(context.Background(), testDigest)
+	assert.ErrorContains(t, err, "certificate signed by unknown authority")
+
+	got, err := rekor.NewClient(ts.URL, true).Search(context.Background(), testDigest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"uuid-01"}, got)
+}
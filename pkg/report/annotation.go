@@ -0,0 +1,107 @@
+package report
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// The kinds of the findings annotated in the pull requests
+const (
+	annotationVulnerability    = "vulnerability"
+	annotationMisconfiguration = "misconfiguration"
+	annotationSecret           = "secret"
+)
+
+// annotation is a finding located in a file of the repository, written as an inline annotation of the pull requests
+// by the writers of the code review platforms
+type annotation struct {
+	kind      string
+	id        string
+	title     string
+	severity  string
+	path      string
+	startLine int
+	endLine   int
+	message   string
+	url       string
+}
+
+// fingerprint identifies the annotation across the scans, so that the platforms update the existing annotations
+func (a annotation) fingerprint() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%d/%s", a.kind, a.id, a.path, a.startLine,
+		a.message))))
+}
+
+// annotations returns the vulnerabilities, the failed misconfigurations and the secrets of the report
+func annotations(report types.Report) []annotation {
+	var annotations []annotation
+	for _, res := range report.Results {
+		for _, vuln := range res.Vulnerabilities {
+			path := vuln.PkgPath
+			if path == "" {
+				path = res.Target
+			}
+			title := vuln.Title
+			if title == "" {
+				title = vuln.VulnerabilityID
+			}
+			annotations = append(annotations, annotation{
+				kind:     annotationVulnerability,
+				id:       vuln.VulnerabilityID,
+				title:    title,
+				severity: vuln.Severity,
+				path:     toPathUri(path),
+				message: fmt.Sprintf("%s (%s): %s %s, fixed version: %s", vuln.VulnerabilityID, vuln.Severity,
+					vuln.PkgName, vuln.InstalledVersion, fixedVersion(vuln.FixedVersion)),
+				url: vuln.PrimaryURL,
+			})
+		}
+		for _, misconf := range res.Misconfigurations {
+			if misconf.Status != types.StatusFailure {
+				continue
+			}
+			annotations = append(annotations, annotation{
+				kind:      annotationMisconfiguration,
+				id:        misconf.ID,
+				title:     misconf.Title,
+				severity:  misconf.Severity,
+				path:      toPathUri(res.Target),
+				startLine: misconf.CauseMetadata.StartLine,
+				endLine:   misconf.CauseMetadata.EndLine,
+				message:   fmt.Sprintf("%s (%s): %s", misconf.ID, misconf.Severity, misconf.Message),
+				url:       misconf.PrimaryURL,
+			})
+		}
+		for _, secret := range res.Secrets {
+			annotations = append(annotations, annotation{
+				kind:      annotationSecret,
+				id:        secret.RuleID,
+				title:     secret.Title,
+				severity:  secret.Severity,
+				path:      toPathUri(res.Target),
+				startLine: secret.StartLine,
+				endLine:   secret.EndLine,
+				// The match is not written, as it has the secret
+				message: fmt.Sprintf("%s (%s): %s", secret.RuleID, secret.Severity, secret.Title),
+			})
+		}
+	}
+	return annotations
+}
+
+func fixedVersion(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
+
+// truncate cuts s to the limit of the platform
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// AzureDevOpsFingerprint is the property of the threads identifying the findings, so that the threads of the findings
+// already commented in the pull request can be skipped
+const AzureDevOpsFingerprint = "TrivyFingerprint"
+
+// AzureDevOpsReport has the threads to be created in the pull request, one per finding
+// ref. https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-threads/create
+type AzureDevOpsReport struct {
+	Threads []AzureDevOpsThread `json:"threads"`
+}
+
+type AzureDevOpsThread struct {
+	Comments      []AzureDevOpsComment             `json:"comments"`
+	Status        string                           `json:"status"`
+	ThreadContext AzureDevOpsThreadContext         `json:"threadContext"`
+	Properties    map[string]AzureDevOpsProperties `json:"properties"`
+}
+
+type AzureDevOpsComment struct {
+	ParentCommentID int    `json:"parentCommentId"`
+	Content         string `json:"content"`
+	CommentType     string `json:"commentType"`
+}
+
+type AzureDevOpsThreadContext struct {
+	FilePath       string              `json:"filePath"`
+	RightFileStart AzureDevOpsPosition `json:"rightFileStart"`
+	RightFileEnd   AzureDevOpsPosition `json:"rightFileEnd"`
+}
+
+type AzureDevOpsPosition struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+type AzureDevOpsProperties struct {
+	Type  string `json:"$type"`
+	Value string `json:"$value"`
+}
+
+// AzureDevOpsWriter writes the findings as the threads of the pull requests of Azure DevOps
+type AzureDevOpsWriter struct {
+	Output io.Writer
+}
+
+// Write writes the findings in the Azure DevOps format
+func (aw AzureDevOpsWriter) Write(report types.Report) error {
+	output := AzureDevOpsReport{Threads: []AzureDevOpsThread{}}
+	for _, a := range annotations(report) {
+		// The lines start at 1, and the findings without lines are put on the first line
+		start, end := a.startLine, a.endLine
+		if start < 1 {
+			start = 1
+		}
+		if end < start {
+			end = start
+		}
+
+		content := fmt.Sprintf("**%s** (%s): %s\n\n%s", a.id, a.severity, a.title, a.message)
+		if a.url != "" {
+			content += fmt.Sprintf("\n\n[%s](%s)", a.id, a.url)
+		}
+		output.Threads = append(output.Threads, AzureDevOpsThread{
+			Comments: []AzureDevOpsComment{{
+				ParentCommentID: 0,
+				Content:         content,
+				CommentType:     "text",
+			}},
+			Status: "active",
+			ThreadContext: AzureDevOpsThreadContext{
+				// The paths are absolute in the repository
+				FilePath:       "/" + strings.TrimPrefix(a.path, "/"),
+				RightFileStart: AzureDevOpsPosition{Line: start, Offset: 1},
+				RightFileEnd:   AzureDevOpsPosition{Line: end, Offset: 1},
+			},
+			Properties: map[string]AzureDevOpsProperties{
+				AzureDevOpsFingerprint: {Type: "System.String", Value: a.fingerprint()},
+			},
+		})
+	}
+
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the Azure DevOps report: %w", err)
+	}
+	if _, err = fmt.Fprintln(aw.Output, string(b)); err != nil {
+		return xerrors.Errorf("failed to write the Azure DevOps report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestAzureDevOpsWriter_Write(t *testing.T) {
+	tests := []struct {
+		name   string
+		report types.Report
+		want   []report.AzureDevOpsThread
+	}{
+		{
+			name:   "findings",
+			report: annotatedReport,
+			want: []report.AzureDevOpsThread{
+				{
+					Comments: []report.AzureDevOpsComment{{
+						Content: "**CVE-2021-23337** (HIGH): lodash: command injection via template\n\n" +
+							"CVE-2021-23337 (HIGH): lodash 4.17.20, fixed version: 4.17.21\n\n" +
+							"[CVE-2021-23337](https://avd.aquasec.com/nvd/cve-2021-23337)",
+						CommentType: "text",
+					}},
+					Status: "active",
+					ThreadContext: report.AzureDevOpsThreadContext{
+						FilePath:       "/app/package-lock.json",
+						RightFileStart: report.AzureDevOpsPosition{Line: 1, Offset: 1},
+						RightFileEnd:   report.AzureDevOpsPosition{Line: 1, Offset: 1},
+					},
+				},
+				{
+					Comments: []report.AzureDevOpsComment{{
+						Content: "**DS002** (HIGH): Image user should not be 'root'\n\n" +
+							"DS002 (HIGH): Specify at least 1 USER command in Dockerfile with non-root user as argument\n\n" +
+							"[DS002](https://avd.aquasec.com/misconfig/ds002)",
+						CommentType: "text",
+					}},
+					Status: "active",
+					ThreadContext: report.AzureDevOpsThreadContext{
+						FilePath:       "/Dockerfile",
+						RightFileStart: report.AzureDevOpsPosition{Line: 3, Offset: 1},
+						RightFileEnd:   report.AzureDevOpsPosition{Line: 4, Offset: 1},
+					},
+				},
+				{
+					Comments: []report.AzureDevOpsComment{{
+						Content:     "**aws-access-key-id** (UNKNOWN): AWS Access Key ID\n\naws-access-key-id (UNKNOWN): AWS Access Key ID",
+						CommentType: "text",
+					}},
+					Status: "active",
+					ThreadContext: report.AzureDevOpsThreadContext{
+						FilePath:       "/config/secret.env",
+						RightFileStart: report.AzureDevOpsPosition{Line: 2, Offset: 1},
+						RightFileEnd:   report.AzureDevOpsPosition{Line: 2, Offset: 1},
+					},
+				},
+			},
+		},
+		{
+			name:   "no findings",
+			report: types.Report{ArtifactName: "myapp"},
+			want:   []report.AzureDevOpsThread{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := report.Write(tt.report, report.Option{
+				Format: "azure-devops",
+				Output: &buf,
+			})
+			require.NoError(t, err)
+
+			var got report.AzureDevOpsReport
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+			// The fingerprints identify the findings
+			for i := range got.Threads {
+				fingerprint := got.Threads[i].Properties[report.AzureDevOpsFingerprint]
+				assert.Equal(t, "System.String", fingerprint.Type)
+				assert.Len(t, fingerprint.Value, 64)
+				got.Threads[i].Properties = nil
+			}
+			assert.Equal(t, tt.want, got.Threads)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// The limits of Bitbucket Code Insights
+// ref. https://developer.atlassian.com/cloud/bitbucket/rest/api-group-reports/
+const (
+	bitbucketMaxAnnotations = 1000
+	bitbucketMaxSummary     = 450
+	bitbucketMaxDetails     = 2000
+)
+
+// BitbucketReport is the payload of Bitbucket Code Insights, where Report is put to the reports of the commit
+// and Annotations are posted to its annotations
+type BitbucketReport struct {
+	Report      BitbucketInsightReport `json:"report"`
+	Annotations []BitbucketAnnotation  `json:"annotations"`
+}
+
+type BitbucketInsightReport struct {
+	ExternalID string          `json:"external_id"`
+	Title      string          `json:"title"`
+	Details    string          `json:"details"`
+	ReportType string          `json:"report_type"`
+	Reporter   string          `json:"reporter"`
+	Link       string          `json:"link,omitempty"`
+	Result     string          `json:"result"`
+	Data       []BitbucketData `json:"data"`
+}
+
+type BitbucketData struct {
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+type BitbucketAnnotation struct {
+	ExternalID     string `json:"external_id"`
+	AnnotationType string `json:"annotation_type"`
+	Summary        string `json:"summary"`
+	Details        string `json:"details,omitempty"`
+	Result         string `json:"result"`
+	Severity       string `json:"severity"`
+	Path           string `json:"path"`
+	Line           int    `json:"line,omitempty"`
+	Link           string `json:"link,omitempty"`
+}
+
+// BitbucketWriter writes the findings as the report and the annotations of Bitbucket Code Insights
+type BitbucketWriter struct {
+	Output  io.Writer
+	Version string
+}
+
+// Write writes the findings in the Bitbucket Code Insights format
+func (bw BitbucketWriter) Write(report types.Report) error {
+	annotations := annotations(report)
+
+	counts := map[string]int{}
+	output := BitbucketReport{Annotations: []BitbucketAnnotation{}}
+	for _, a := range annotations {
+		counts[a.kind]++
+		if len(output.Annotations) == bitbucketMaxAnnotations {
+			continue
+		}
+		annotationType := "VULNERABILITY"
+		if a.kind == annotationMisconfiguration {
+			annotationType = "CODE_SMELL"
+		}
+		output.Annotations = append(output.Annotations, BitbucketAnnotation{
+			ExternalID:     a.fingerprint(),
+			AnnotationType: annotationType,
+			Summary:        truncate(a.message, bitbucketMaxSummary),
+			Details:        truncate(a.title, bitbucketMaxDetails),
+			Result:         "FAILED",
+			Severity:       toBitbucketSeverity(a.severity),
+			Path:           a.path,
+			Line:           a.startLine,
+			Link:           a.url,
+		})
+	}
+
+	details := fmt.Sprintf("Trivy %s found %d findings in %s.", bw.Version, len(annotations), report.ArtifactName)
+	if len(annotations) > bitbucketMaxAnnotations {
+		details += fmt.Sprintf(" Only the first %d findings are annotated.", bitbucketMaxAnnotations)
+	}
+	result := "PASSED"
+	if len(annotations) > 0 {
+		result = "FAILED"
+	}
+	output.Report = BitbucketInsightReport{
+		ExternalID: "trivy",
+		Title:      "Trivy",
+		Details:    details,
+		ReportType: "SECURITY",
+		Reporter:   "Trivy",
+		Link:       "https://github.com/aquasecurity/trivy",
+		Result:     result,
+		Data: []BitbucketData{
+			{Title: "Vulnerabilities", Type: "NUMBER", Value: counts[annotationVulnerability]},
+			{Title: "Misconfigurations", Type: "NUMBER", Value: counts[annotationMisconfiguration]},
+			{Title: "Secrets", Type: "NUMBER", Value: counts[annotationSecret]},
+		},
+	}
+
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the Bitbucket report: %w", err)
+	}
+	if _, err = fmt.Fprintln(bw.Output, string(b)); err != nil {
+		return xerrors.Errorf("failed to write the Bitbucket report: %w", err)
+	}
+	return nil
+}
+
+// toBitbucketSeverity returns the severity of the annotation, which has no UNKNOWN
+func toBitbucketSeverity(severity string) string {
+	switch severity {
+	case "CRITICAL", "HIGH", "MEDIUM":
+		return severity
+	default:
+		return "LOW"
+	}
+}
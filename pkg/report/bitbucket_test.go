@@ -0,0 +1,176 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+var annotatedReport = types.Report{
+	ArtifactName: "myapp",
+	Results: types.Results{
+		{
+			Target: "app/package-lock.json",
+			Class:  types.ClassLangPkg,
+			Vulnerabilities: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2021-23337",
+					PkgName:          "lodash",
+					InstalledVersion: "4.17.20",
+					FixedVersion:     "4.17.21",
+					PrimaryURL:       "https://avd.aquasec.com/nvd/cve-2021-23337",
+					Vulnerability: dbTypes.Vulnerability{
+						Title:    "lodash: command injection via template",
+						Severity: "HIGH",
+					},
+				},
+			},
+		},
+		{
+			Target: "Dockerfile",
+			Class:  types.ClassConfig,
+			Misconfigurations: []types.DetectedMisconfiguration{
+				{
+					ID:         "DS002",
+					Title:      "Image user should not be 'root'",
+					Message:    "Specify at least 1 USER command in Dockerfile with non-root user as argument",
+					Severity:   "HIGH",
+					PrimaryURL: "https://avd.aquasec.com/misconfig/ds002",
+					Status:     types.StatusFailure,
+					CauseMetadata: ftypes.CauseMetadata{
+						StartLine: 3,
+						EndLine:   4,
+					},
+				},
+				{
+					ID:       "DS001",
+					Title:    "':latest' tag used",
+					Severity: "MEDIUM",
+					Status:   types.StatusPassed,
+				},
+			},
+		},
+		{
+			Target: "config/secret.env",
+			Class:  types.ClassSecret,
+			Secrets: []ftypes.SecretFinding{
+				{
+					RuleID:    "aws-access-key-id",
+					Title:     "AWS Access Key ID",
+					Severity:  "UNKNOWN",
+					StartLine: 2,
+					EndLine:   2,
+					Match:     "AWS_ACCESS_KEY_ID=********************",
+				},
+			},
+		},
+	},
+}
+
+func TestBitbucketWriter_Write(t *testing.T) {
+	tests := []struct {
+		name            string
+		report          types.Report
+		wantReport      report.BitbucketInsightReport
+		wantAnnotations []report.BitbucketAnnotation
+	}{
+		{
+			name:   "findings",
+			report: annotatedReport,
+			wantReport: report.BitbucketInsightReport{
+				ExternalID: "trivy",
+				Title:      "Trivy",
+				Details:    "Trivy 0.30.0 found 3 findings in myapp.",
+				ReportType: "SECURITY",
+				Reporter:   "Trivy",
+				Link:       "https://github.com/aquasecurity/trivy",
+				Result:     "FAILED",
+				Data: []report.BitbucketData{
+					{Title: "Vulnerabilities", Type: "NUMBER", Value: 1},
+					{Title: "Misconfigurations", Type: "NUMBER", Value: 1},
+					{Title: "Secrets", Type: "NUMBER", Value: 1},
+				},
+			},
+			wantAnnotations: []report.BitbucketAnnotation{
+				{
+					AnnotationType: "VULNERABILITY",
+					Summary:        "CVE-2021-23337 (HIGH): lodash 4.17.20, fixed version: 4.17.21",
+					Details:        "lodash: command injection via template",
+					Result:         "FAILED",
+					Severity:       "HIGH",
+					Path:           "app/package-lock.json",
+					Link:           "https://avd.aquasec.com/nvd/cve-2021-23337",
+				},
+				{
+					AnnotationType: "CODE_SMELL",
+					Summary:        "DS002 (HIGH): Specify at least 1 USER command in Dockerfile with non-root user as argument",
+					Details:        "Image user should not be 'root'",
+					Result:         "FAILED",
+					Severity:       "HIGH",
+					Path:           "Dockerfile",
+					Line:           3,
+					Link:           "https://avd.aquasec.com/misconfig/ds002",
+				},
+				{
+					AnnotationType: "VULNERABILITY",
+					Summary:        "aws-access-key-id (UNKNOWN): AWS Access Key ID",
+					Details:        "AWS Access Key ID",
+					Result:         "FAILED",
+					Severity:       "LOW",
+					Path:           "config/secret.env",
+					Line:           2,
+				},
+			},
+		},
+		{
+			name:   "no findings",
+			report: types.Report{ArtifactName: "myapp"},
+			wantReport: report.BitbucketInsightReport{
+				ExternalID: "trivy",
+				Title:      "Trivy",
+				Details:    "Trivy 0.30.0 found 0 findings in myapp.",
+				ReportType: "SECURITY",
+				Reporter:   "Trivy",
+				Link:       "https://github.com/aquasecurity/trivy",
+				Result:     "PASSED",
+				Data: []report.BitbucketData{
+					{Title: "Vulnerabilities", Type: "NUMBER"},
+					{Title: "Misconfigurations", Type: "NUMBER"},
+					{Title: "Secrets", Type: "NUMBER"},
+				},
+			},
+			wantAnnotations: []report.BitbucketAnnotation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := report.Write(tt.report, report.Option{
+				Format:     "bitbucket",
+				Output:     &buf,
+				AppVersion: "0.30.0",
+			})
+			require.NoError(t, err)
+
+			var got report.BitbucketReport
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, tt.wantReport, got.Report)
+
+			// The external IDs are the fingerprints of the findings
+			for i := range got.Annotations {
+				assert.Len(t, got.Annotations[i].ExternalID, 64)
+				got.Annotations[i].ExternalID = ""
+			}
+			assert.Equal(t, tt.wantAnnotations, got.Annotations)
+			assert.NotContains(t, buf.String(), "AWS_ACCESS_KEY_ID")
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	cdx "github.com/CycloneDX/cyclonedx-go"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
@@ -60,6 +61,9 @@ type options struct {
 	format  cdx.BOMFileFormat
 	clock   clock.Clock
 	newUUID newUUID
+
+	// hashAlgorithms selects the hashes of the components, and all the hashes are written if empty
+	hashAlgorithms []string
 }
 
 type option func(*options)
@@ -82,6 +86,13 @@ func WithNewUUID(newUUID newUUID) option {
 	}
 }
 
+// WithHashAlgorithms writes only the hashes of the components in the algorithms, e.g. "sha256"
+func WithHashAlgorithms(algorithms []string) option {
+	return func(opts *options) {
+		opts.hashAlgorithms = algorithms
+	}
+}
+
 func NewWriter(output io.Writer, version string, opts ...option) Writer {
 	o := &options{
 		format:  cdx.BOMFileFormatJSON,
@@ -156,8 +167,8 @@ func (cw *Writer) parseComponents(r types.Report, bomRef string) (*[]cdx.Compone
 			if err != nil {
 				return nil, nil, nil, xerrors.Errorf("failed to parse pkg: %w", err)
 			}
-			if _, ok := bomRefMap[pkg.Name+utils.FormatVersion(pkg)+pkg.FilePath]; !ok {
-				bomRefMap[pkg.Name+utils.FormatVersion(pkg)+pkg.FilePath] = pkgComponent.BOMRef
+			if _, ok := bomRefMap[pkg.Name+utils.FormatVersion(pkg.Package)+pkg.FilePath]; !ok {
+				bomRefMap[pkg.Name+utils.FormatVersion(pkg.Package)+pkg.FilePath] = pkgComponent.BOMRef
 			}
 
 			// When multiple lock files have the same dependency with the same name and version,
@@ -198,8 +209,9 @@ func (cw *Writer) parseComponents(r types.Report, bomRef string) (*[]cdx.Compone
 			}
 		}
 
-		if result.Type == ftypes.NodePkg || result.Type == ftypes.PythonPkg || result.Type == ftypes.GoBinary ||
-			result.Type == ftypes.GemSpec || result.Type == ftypes.Jar {
+		// A Go binary with the hashes is an Application component like a lock file, so that the hashes identify it
+		if (result.Type == ftypes.NodePkg || result.Type == ftypes.PythonPkg || result.Type == ftypes.GoBinary ||
+			result.Type == ftypes.GemSpec || result.Type == ftypes.Jar) && len(result.Hashes) == 0 {
 			// If a package is language-specific package that isn't associated with a lock file,
 			// it will be a dependency of a component under "metadata".
 			// e.g.
@@ -273,12 +285,12 @@ func (cw *Writer) vulnerability(vuln types.DetectedVulnerability, bomRef string)
 	return v
 }
 
-func (cw *Writer) pkgToComponent(t string, meta types.Metadata, pkg ftypes.Package) (cdx.Component, error) {
-	pu, err := purl.NewPackageURL(t, meta, pkg)
+func (cw *Writer) pkgToComponent(t string, meta types.Metadata, pkg types.Package) (cdx.Component, error) {
+	pu, err := purl.NewPackageURL(t, meta, pkg.Package)
 	if err != nil {
 		return cdx.Component{}, xerrors.Errorf("failed to new package purl: %w", err)
 	}
-	properties := parseProperties(pkg)
+	properties := parseProperties(pkg.Package)
 	component := cdx.Component{
 		Type:       cdx.ComponentTypeLibrary,
 		Name:       pkg.Name,
@@ -294,6 +306,8 @@ func (cw *Writer) pkgToComponent(t string, meta types.Metadata, pkg ftypes.Packa
 		}
 	}
 
+	component.Hashes = checksumsToHashes(pkg.Checksums(cw.hashAlgorithms))
+
 	return component, nil
 }
 
@@ -325,6 +339,10 @@ func (cw *Writer) reportToComponent(r types.Report) (*cdx.Component, error) {
 			component.BOMRef = p.ToString()
 			component.PackageURL = p.ToString()
 		}
+
+		if r.Metadata.RebuildOf != "" {
+			component.Pedigree = cw.pedigree(r.Metadata)
+		}
 	case ftypes.ArtifactFilesystem, ftypes.ArtifactRemoteRepository:
 		component.Type = cdx.ComponentTypeApplication
 		component.BOMRef = cw.newUUID().String()
@@ -345,6 +363,30 @@ func (cw *Writer) reportToComponent(r types.Report) (*cdx.Component, error) {
 	return component, nil
 }
 
+// pedigree returns the original image as the ancestor of the rebuilt image
+func (cw *Writer) pedigree(meta types.Metadata) *cdx.Pedigree {
+	ancestor := cdx.Component{
+		Type: cdx.ComponentTypeContainer,
+		Name: meta.RebuildOf,
+	}
+
+	// The package URL is available only when the original image is referenced by digest
+	p, err := purl.NewPackageURL(purl.TypeOCI, types.Metadata{
+		RepoDigests: []string{meta.RebuildOf},
+		ImageConfig: v1.ConfigFile{Architecture: meta.ImageConfig.Architecture},
+	}, ftypes.Package{})
+	if err != nil || p.Type == "" {
+		ancestor.BOMRef = cw.newUUID().String()
+	} else {
+		ancestor.BOMRef = p.ToString()
+		ancestor.PackageURL = p.ToString()
+	}
+
+	return &cdx.Pedigree{
+		Ancestors: &[]cdx.Component{ancestor},
+	}
+}
+
 func (cw Writer) resultToComponent(r types.Result, osFound *ftypes.OS) cdx.Component {
 	component := cdx.Component{
 		Name: r.Target,
@@ -369,6 +411,7 @@ func (cw Writer) resultToComponent(r types.Result, osFound *ftypes.OS) cdx.Compo
 		// https://cyclonedx.org/use-cases/#known-vulnerabilities
 		component.BOMRef = cw.newUUID().String()
 		component.Type = cdx.ComponentTypeApplication
+		component.Hashes = checksumsToHashes(r.Checksums(cw.hashAlgorithms))
 	case types.ClassConfig:
 		// TODO: Config support
 		component.BOMRef = cw.newUUID().String()
@@ -401,6 +444,42 @@ func parseProperties(pkg ftypes.Package) []cdx.Property {
 	return properties
 }
 
+// checksumsToHashes returns the hashes of the component, or nil if there is none
+func checksumsToHashes(checksums []string) *[]cdx.Hash {
+	var hashes []cdx.Hash
+	for _, checksum := range checksums {
+		if hash, ok := digestToHash(checksum); ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return &hashes
+}
+
+// digestToHash converts the digest of the package such as "sha1:<hex>" to the hash of the component
+func digestToHash(digest string) (cdx.Hash, bool) {
+	alg, value, ok := strings.Cut(digest, ":")
+	if !ok {
+		return cdx.Hash{}, false
+	}
+	var hashAlg cdx.HashAlgorithm
+	switch alg {
+	case "md5":
+		hashAlg = cdx.HashAlgoMD5
+	case "sha1":
+		hashAlg = cdx.HashAlgoSHA1
+	case "sha256":
+		hashAlg = cdx.HashAlgoSHA256
+	case "sha512":
+		hashAlg = cdx.HashAlgoSHA512
+	default:
+		return cdx.Hash{}, false
+	}
+	return cdx.Hash{Algorithm: hashAlg, Value: value}, true
+}
+
 func appendProperties(properties []cdx.Property, key, value string) []cdx.Property {
 	if value == "" || (key == PropertySrcEpoch && value == "0") {
 		return properties
@@ -26,9 +26,10 @@ import (
 
 func TestWriter_Write(t *testing.T) {
 	testCases := []struct {
-		name        string
-		inputReport types.Report
-		wantSBOM    *cdx.BOM
+		name           string
+		inputReport    types.Report
+		hashAlgorithms []string
+		wantSBOM       *cdx.BOM
 	}{
 		{
 			name: "happy path for container scan",
@@ -56,19 +57,22 @@ func TestWriter_Write(t *testing.T) {
 						Target: "rails:latest (centos 8.3.2011)",
 						Class:  types.ClassOSPkg,
 						Type:   fos.CentOS,
-						Packages: []ftypes.Package{
-							{
-								Name:            "binutils",
-								Version:         "2.30",
-								Release:         "93.el8",
-								Epoch:           0,
-								Arch:            "aarch64",
-								SrcName:         "binutils",
-								SrcVersion:      "2.30",
-								SrcRelease:      "93.el8",
-								SrcEpoch:        0,
-								Modularitylabel: "",
-								License:         "GPLv3+",
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:            "binutils",
+									Version:         "2.30",
+									Release:         "93.el8",
+									Epoch:           0,
+									Arch:            "aarch64",
+									SrcName:         "binutils",
+									SrcVersion:      "2.30",
+									SrcRelease:      "93.el8",
+									SrcEpoch:        0,
+									Modularitylabel: "",
+									License:         "GPLv3+",
+								},
+								Digest: "md5:7459cec61bb4d1b0ca8107e25e0dd005",
 							},
 						},
 						Vulnerabilities: []types.DetectedVulnerability{
@@ -121,14 +125,18 @@ func TestWriter_Write(t *testing.T) {
 						Target: "app/subproject/Gemfile.lock",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.Bundler,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actionpack",
-								Version: "7.0.0",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.0",
+								},
 							},
 							{
-								Name:    "actioncontroller",
-								Version: "7.0.0",
+								Package: ftypes.Package{
+									Name:    "actioncontroller",
+									Version: "7.0.0",
+								},
 							},
 						},
 					},
@@ -136,10 +144,12 @@ func TestWriter_Write(t *testing.T) {
 						Target: "app/Gemfile.lock",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.Bundler,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actionpack",
-								Version: "7.0.0",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.0",
+								},
 							},
 						},
 					},
@@ -201,6 +211,12 @@ func TestWriter_Write(t *testing.T) {
 						Licenses: &cdx.Licenses{
 							cdx.LicenseChoice{Expression: "GPLv3+"},
 						},
+						Hashes: &[]cdx.Hash{
+							{
+								Algorithm: cdx.HashAlgoMD5,
+								Value:     "7459cec61bb4d1b0ca8107e25e0dd005",
+							},
+						},
 						PackageURL: "pkg:rpm/centos/binutils@2.30-93.el8?arch=aarch64&distro=centos-8.3.2011",
 						Properties: &[]cdx.Property{
 							{
@@ -416,19 +432,21 @@ func TestWriter_Write(t *testing.T) {
 						Target: "centos:latest (centos 8.3.2011)",
 						Class:  types.ClassOSPkg,
 						Type:   fos.CentOS,
-						Packages: []ftypes.Package{
-							{
-								Name:            "acl",
-								Version:         "2.2.53",
-								Release:         "1.el8",
-								Epoch:           1,
-								Arch:            "aarch64",
-								SrcName:         "acl",
-								SrcVersion:      "2.2.53",
-								SrcRelease:      "1.el8",
-								SrcEpoch:        1,
-								Modularitylabel: "",
-								License:         "GPLv2+",
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:            "acl",
+									Version:         "2.2.53",
+									Release:         "1.el8",
+									Epoch:           1,
+									Arch:            "aarch64",
+									SrcName:         "acl",
+									SrcVersion:      "2.2.53",
+									SrcRelease:      "1.el8",
+									SrcEpoch:        1,
+									Modularitylabel: "",
+									License:         "GPLv2+",
+								},
 							},
 						},
 					},
@@ -436,22 +454,26 @@ func TestWriter_Write(t *testing.T) {
 						Target: "Ruby",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.GemSpec,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actionpack",
-								Version: "7.0.0",
-								Layer: ftypes.Layer{
-									DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.0",
+									Layer: ftypes.Layer{
+										DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+									},
+									FilePath: "tools/project-john/specifications/actionpack.gemspec",
 								},
-								FilePath: "tools/project-john/specifications/actionpack.gemspec",
 							},
 							{
-								Name:    "actionpack",
-								Version: "7.0.1",
-								Layer: ftypes.Layer{
-									DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.1",
+									Layer: ftypes.Layer{
+										DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+									},
+									FilePath: "tools/project-doe/specifications/actionpack.gemspec",
 								},
-								FilePath: "tools/project-doe/specifications/actionpack.gemspec",
 							},
 						},
 						Vulnerabilities: []types.DetectedVulnerability{
@@ -774,10 +796,12 @@ func TestWriter_Write(t *testing.T) {
 						Target: "Gemfile.lock",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.Bundler,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actioncable",
-								Version: "6.1.4.1",
+								Package: ftypes.Package{
+									Name:    "actioncable",
+									Version: "6.1.4.1",
+								},
 							},
 						},
 					},
@@ -854,6 +878,205 @@ func TestWriter_Write(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "happy path with hashes",
+			inputReport: types.Report{
+				SchemaVersion: report.SchemaVersion,
+				ArtifactName:  "app",
+				ArtifactType:  ftypes.ArtifactFilesystem,
+				Results: types.Results{
+					{
+						Target: "Java",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.Jar,
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:     "org.apache.logging.log4j:log4j-api",
+									Version:  "2.17.1",
+									FilePath: "app/log4j-api-2.17.1.jar",
+								},
+								Digest: "sha1:ea1b37f38c327596b216542bc636cfdc0b8036fa",
+								Hashes: []string{
+									"sha512:421785ff9c6212492d19d5f9e213197f130daaf721051b4f5b208dca8f86c94a4b56d3e232fc0b191d06f18e5a96be4691a295417e82d3a3f0d83c1f5846d1b8",
+									"sha256:1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+									"sha1:ea1b37f38c327596b216542bc636cfdc0b8036fa",
+								},
+							},
+						},
+					},
+				},
+			},
+			hashAlgorithms: []string{"sha256", "sha1"},
+			wantSBOM: &cdx.BOM{
+				BOMFormat:    "CycloneDX",
+				SpecVersion:  "1.4",
+				SerialNumber: "urn:uuid:3ff14136-e09f-4df9-80ea-000000000001",
+				Version:      1,
+				Metadata: &cdx.Metadata{
+					Timestamp: "2021-08-25T12:20:30.000000005Z",
+					Tools: &[]cdx.Tool{
+						{
+							Name:    "trivy",
+							Vendor:  "aquasecurity",
+							Version: "dev",
+						},
+					},
+					Component: &cdx.Component{
+						BOMRef: "3ff14136-e09f-4df9-80ea-000000000002",
+						Type:   cdx.ComponentTypeApplication,
+						Name:   "app",
+						Properties: &[]cdx.Property{
+							{
+								Name:  "aquasecurity:trivy:SchemaVersion",
+								Value: "2",
+							},
+						},
+					},
+				},
+				Components: &[]cdx.Component{
+					{
+						BOMRef:  "pkg:maven/org.apache.logging.log4j/log4j-api@2.17.1?file_path=app%2Flog4j-api-2.17.1.jar",
+						Type:    cdx.ComponentTypeLibrary,
+						Name:    "org.apache.logging.log4j:log4j-api",
+						Version: "2.17.1",
+						// The hashes in the other algorithms are not written, and the digest is preferred to the same hash
+						Hashes: &[]cdx.Hash{
+							{
+								Algorithm: cdx.HashAlgoSHA1,
+								Value:     "ea1b37f38c327596b216542bc636cfdc0b8036fa",
+							},
+							{
+								Algorithm: cdx.HashAlgoSHA256,
+								Value:     "1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+							},
+						},
+						PackageURL: "pkg:maven/org.apache.logging.log4j/log4j-api@2.17.1",
+						Properties: &[]cdx.Property{
+							{
+								Name:  "aquasecurity:trivy:FilePath",
+								Value: "app/log4j-api-2.17.1.jar",
+							},
+						},
+					},
+				},
+				Vulnerabilities: &[]cdx.Vulnerability{},
+				Dependencies: &[]cdx.Dependency{
+					{
+						Ref: "3ff14136-e09f-4df9-80ea-000000000002",
+						Dependencies: &[]cdx.Dependency{
+							{
+								Ref: "pkg:maven/org.apache.logging.log4j/log4j-api@2.17.1?file_path=app%2Flog4j-api-2.17.1.jar",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "happy path with the hashes of a binary",
+			inputReport: types.Report{
+				SchemaVersion: report.SchemaVersion,
+				ArtifactName:  "app",
+				ArtifactType:  ftypes.ArtifactFilesystem,
+				Results: types.Results{
+					{
+						Target: "usr/local/bin/app",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.GoBinary,
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:    "golang.org/x/text",
+									Version: "v0.3.7",
+								},
+							},
+						},
+						Hashes: []string{
+							"sha512:421785ff9c6212492d19d5f9e213197f130daaf721051b4f5b208dca8f86c94a4b56d3e232fc0b191d06f18e5a96be4691a295417e82d3a3f0d83c1f5846d1b8",
+							"sha256:1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+						},
+					},
+				},
+			},
+			hashAlgorithms: []string{"sha256"},
+			wantSBOM: &cdx.BOM{
+				BOMFormat:    "CycloneDX",
+				SpecVersion:  "1.4",
+				SerialNumber: "urn:uuid:3ff14136-e09f-4df9-80ea-000000000001",
+				Version:      1,
+				Metadata: &cdx.Metadata{
+					Timestamp: "2021-08-25T12:20:30.000000005Z",
+					Tools: &[]cdx.Tool{
+						{
+							Name:    "trivy",
+							Vendor:  "aquasecurity",
+							Version: "dev",
+						},
+					},
+					Component: &cdx.Component{
+						BOMRef: "3ff14136-e09f-4df9-80ea-000000000002",
+						Type:   cdx.ComponentTypeApplication,
+						Name:   "app",
+						Properties: &[]cdx.Property{
+							{
+								Name:  "aquasecurity:trivy:SchemaVersion",
+								Value: "2",
+							},
+						},
+					},
+				},
+				Components: &[]cdx.Component{
+					{
+						BOMRef:     "pkg:golang/golang.org/x/text@v0.3.7",
+						Type:       cdx.ComponentTypeLibrary,
+						Name:       "golang.org/x/text",
+						Version:    "v0.3.7",
+						PackageURL: "pkg:golang/golang.org/x/text@v0.3.7",
+					},
+					{
+						BOMRef: "3ff14136-e09f-4df9-80ea-000000000003",
+						Type:   cdx.ComponentTypeApplication,
+						Name:   "usr/local/bin/app",
+						Hashes: &[]cdx.Hash{
+							{
+								Algorithm: cdx.HashAlgoSHA256,
+								Value:     "1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+							},
+						},
+						Properties: &[]cdx.Property{
+							{
+								Name:  "aquasecurity:trivy:Type",
+								Value: "gobinary",
+							},
+							{
+								Name:  "aquasecurity:trivy:Class",
+								Value: "lang-pkgs",
+							},
+						},
+					},
+				},
+				Vulnerabilities: &[]cdx.Vulnerability{},
+				Dependencies: &[]cdx.Dependency{
+					{
+						Ref: "3ff14136-e09f-4df9-80ea-000000000003",
+						Dependencies: &[]cdx.Dependency{
+							{
+								Ref: "pkg:golang/golang.org/x/text@v0.3.7",
+							},
+						},
+					},
+					{
+						Ref: "3ff14136-e09f-4df9-80ea-000000000002",
+						Dependencies: &[]cdx.Dependency{
+							{
+								Ref: "3ff14136-e09f-4df9-80ea-000000000003",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "happy path aggregate results",
 			inputReport: types.Report{
@@ -865,15 +1088,17 @@ func TestWriter_Write(t *testing.T) {
 						Target: "Node.js",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.NodePkg,
-						Packages: []ftypes.Package{
-							{
-								Name:    "ruby-typeprof",
-								Version: "0.20.1",
-								License: "MIT",
-								Layer: ftypes.Layer{
-									DiffID: "sha256:661c3fd3cc16b34c070f3620ca6b03b6adac150f9a7e5d0e3c707a159990f88e",
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:    "ruby-typeprof",
+									Version: "0.20.1",
+									License: "MIT",
+									Layer: ftypes.Layer{
+										DiffID: "sha256:661c3fd3cc16b34c070f3620ca6b03b6adac150f9a7e5d0e3c707a159990f88e",
+									},
+									FilePath: "usr/local/lib/ruby/gems/3.1.0/gems/typeprof-0.21.1/vscode/package.json",
 								},
-								FilePath: "usr/local/lib/ruby/gems/3.1.0/gems/typeprof-0.21.1/vscode/package.json",
 							},
 						},
 					},
@@ -940,6 +1165,77 @@ func TestWriter_Write(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "happy path for rebuilt container scan",
+			inputReport: types.Report{
+				SchemaVersion: report.SchemaVersion,
+				ArtifactName:  "rails:hardened",
+				ArtifactType:  ftypes.ArtifactContainerImage,
+				Metadata: types.Metadata{
+					ImageID: "sha256:8d168f1cbc5a8c44f5d1b1b5b4c0b2e1f8a1d56d4a0c1b6a2c7b1f8e5d9a3c2b",
+					RepoDigests: []string{
+						"rails@sha256:b4f1321d8d2b2c6f4d1d8d5c4b1a6e5f6d4d0e3f2a1b4c7d8e9f0a1b2c3d4e5f",
+					},
+					ImageConfig: v1.ConfigFile{
+						Architecture: "arm64",
+					},
+					RebuildOf: "rails@sha256:a521e0fa7d2b5e7f1b1d8d6c4c3a1e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a",
+				},
+				Results: types.Results{},
+			},
+			wantSBOM: &cdx.BOM{
+				BOMFormat:    "CycloneDX",
+				SpecVersion:  "1.4",
+				SerialNumber: "urn:uuid:3ff14136-e09f-4df9-80ea-000000000001",
+				Version:      1,
+				Metadata: &cdx.Metadata{
+					Timestamp: "2021-08-25T12:20:30.000000005Z",
+					Tools: &[]cdx.Tool{
+						{
+							Name:    "trivy",
+							Vendor:  "aquasecurity",
+							Version: "dev",
+						},
+					},
+					Component: &cdx.Component{
+						Type:       cdx.ComponentTypeContainer,
+						BOMRef:     "pkg:oci/rails@sha256:b4f1321d8d2b2c6f4d1d8d5c4b1a6e5f6d4d0e3f2a1b4c7d8e9f0a1b2c3d4e5f?repository_url=index.docker.io%2Flibrary%2Frails&arch=arm64",
+						PackageURL: "pkg:oci/rails@sha256:b4f1321d8d2b2c6f4d1d8d5c4b1a6e5f6d4d0e3f2a1b4c7d8e9f0a1b2c3d4e5f?repository_url=index.docker.io%2Flibrary%2Frails&arch=arm64",
+						Name:       "rails:hardened",
+						Pedigree: &cdx.Pedigree{
+							Ancestors: &[]cdx.Component{
+								{
+									Type:       cdx.ComponentTypeContainer,
+									BOMRef:     "pkg:oci/rails@sha256:a521e0fa7d2b5e7f1b1d8d6c4c3a1e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a?repository_url=index.docker.io%2Flibrary%2Frails&arch=arm64",
+									PackageURL: "pkg:oci/rails@sha256:a521e0fa7d2b5e7f1b1d8d6c4c3a1e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a?repository_url=index.docker.io%2Flibrary%2Frails&arch=arm64",
+									Name:       "rails@sha256:a521e0fa7d2b5e7f1b1d8d6c4c3a1e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a",
+								},
+							},
+						},
+						Properties: &[]cdx.Property{
+							{
+								Name:  "aquasecurity:trivy:SchemaVersion",
+								Value: "2",
+							},
+							{
+								Name:  "aquasecurity:trivy:ImageID",
+								Value: "sha256:8d168f1cbc5a8c44f5d1b1b5b4c0b2e1f8a1d56d4a0c1b6a2c7b1f8e5d9a3c2b",
+							},
+							{
+								Name:  "aquasecurity:trivy:RepoDigest",
+								Value: "rails@sha256:b4f1321d8d2b2c6f4d1d8d5c4b1a6e5f6d4d0e3f2a1b4c7d8e9f0a1b2c3d4e5f",
+							},
+						},
+					},
+				},
+				Vulnerabilities: &[]cdx.Vulnerability{},
+				Dependencies: &[]cdx.Dependency{
+					{
+						Ref: "pkg:oci/rails@sha256:b4f1321d8d2b2c6f4d1d8d5c4b1a6e5f6d4d0e3f2a1b4c7d8e9f0a1b2c3d4e5f?repository_url=index.docker.io%2Flibrary%2Frails&arch=arm64",
+					},
+				},
+			},
+		},
 		{
 			name: "happy path empty",
 			inputReport: types.Report{
@@ -996,7 +1292,8 @@ func TestWriter_Write(t *testing.T) {
 			}
 
 			output := bytes.NewBuffer(nil)
-			writer := cyclonedx.NewWriter(output, "dev", cyclonedx.WithClock(clock), cyclonedx.WithNewUUID(newUUID))
+			writer := cyclonedx.NewWriter(output, "dev", cyclonedx.WithClock(clock), cyclonedx.WithNewUUID(newUUID),
+				cyclonedx.WithHashAlgorithms(tc.hashAlgorithms))
 
 			err := writer.Write(tc.inputReport)
 			require.NoError(t, err)
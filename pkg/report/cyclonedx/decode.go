@@ -0,0 +1,230 @@
+package cyclonedx
+
+import (
+	"strconv"
+	"strings"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// langTypes maps the package URL types to the types of the packages which are not associated with lock files
+var langTypes = map[string]string{
+	packageurl.TypeNPM:      ftypes.NodePkg,
+	packageurl.TypePyPi:     ftypes.PythonPkg,
+	packageurl.TypeGem:      ftypes.GemSpec,
+	packageurl.TypeMaven:    ftypes.Jar,
+	packageurl.TypeGolang:   ftypes.GoBinary,
+	packageurl.TypeCargo:    ftypes.Cargo,
+	packageurl.TypeComposer: ftypes.Composer,
+	packageurl.TypeNuget:    ftypes.NuGet,
+}
+
+// Decode returns the packages listed in the BOM as the blob of the artifact, so that the vulnerabilities
+// can be detected without analyzing the artifact again.
+// The BOMs generated by Trivy are decoded as the artifact was analyzed, while the packages in the BOMs
+// generated by other tools are classified by their package URLs.
+func Decode(bom cdx.BOM) (ftypes.BlobInfo, ftypes.ImageMetadata, error) {
+	var components []cdx.Component
+	if bom.Components != nil {
+		components = *bom.Components
+	}
+
+	// The component which each package depends on, i.e. the operating system or the application
+	parents := map[string]string{}
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			if dep.Dependencies == nil {
+				continue
+			}
+			for _, child := range *dep.Dependencies {
+				if _, ok := parents[child.Ref]; !ok {
+					parents[child.Ref] = dep.Ref
+				}
+			}
+		}
+	}
+
+	var osFound *ftypes.OS
+	apps := map[string]*ftypes.Application{}
+	var appRefs []string
+	for _, c := range components {
+		switch c.Type {
+		case cdx.ComponentTypeOS:
+			osFound = &ftypes.OS{Family: c.Name, Name: c.Version}
+		case cdx.ComponentTypeApplication:
+			apps[c.BOMRef] = &ftypes.Application{Type: lookupProperty(c, PropertyType), FilePath: c.Name}
+			appRefs = append(appRefs, c.BOMRef)
+		}
+	}
+
+	var pkgs []ftypes.Package
+	langApps := map[string]*ftypes.Application{}
+	var langAppTypes []string
+	for _, c := range components {
+		if c.Type != cdx.ComponentTypeLibrary || c.PackageURL == "" {
+			continue
+		}
+		p, err := packageurl.FromString(c.PackageURL)
+		if err != nil {
+			return ftypes.BlobInfo{}, ftypes.ImageMetadata{}, xerrors.Errorf("failed to parse the package URL (%s): %w", c.PackageURL, err)
+		}
+		pkg := toPackage(c, p)
+
+		// Packages in lock files
+		if app, ok := apps[parents[c.BOMRef]]; ok && app.Type != "" {
+			app.Libraries = append(app.Libraries, pkg)
+			continue
+		}
+
+		switch p.Type {
+		case string(analyzer.TypeApk), packageurl.TypeDebian, packageurl.TypeRPM:
+			pkgs = append(pkgs, pkg)
+			if osFound == nil {
+				osFound = guessOS(p)
+			}
+		default:
+			t, ok := langTypes[p.Type]
+			if !ok {
+				log.Logger.Debugf("Unsupported package type in the SBOM: %s", c.PackageURL)
+				continue
+			}
+			if _, ok = langApps[t]; !ok {
+				langApps[t] = &ftypes.Application{Type: t}
+				langAppTypes = append(langAppTypes, t)
+			}
+			langApps[t].Libraries = append(langApps[t].Libraries, pkg)
+		}
+	}
+
+	blob := ftypes.BlobInfo{
+		SchemaVersion: ftypes.BlobJSONSchemaVersion,
+		OS:            osFound,
+	}
+	if len(pkgs) > 0 {
+		blob.PackageInfos = []ftypes.PackageInfo{{Packages: pkgs}}
+	}
+	for _, ref := range appRefs {
+		if app := apps[ref]; len(app.Libraries) > 0 {
+			blob.Applications = append(blob.Applications, *app)
+		}
+	}
+	for _, t := range langAppTypes {
+		blob.Applications = append(blob.Applications, *langApps[t])
+	}
+
+	return blob, imageMetadata(bom), nil
+}
+
+func toPackage(c cdx.Component, p packageurl.PackageURL) ftypes.Package {
+	pkg := ftypes.Package{
+		Name:            c.Name,
+		Version:         p.Version,
+		Arch:            p.Qualifiers.Map()["arch"],
+		FilePath:        lookupProperty(c, PropertyFilePath),
+		SrcName:         lookupProperty(c, PropertySrcName),
+		SrcVersion:      lookupProperty(c, PropertySrcVersion),
+		SrcRelease:      lookupProperty(c, PropertySrcRelease),
+		Modularitylabel: lookupProperty(c, PropertyModularitylabel),
+		Layer: ftypes.Layer{
+			Digest: lookupProperty(c, PropertyLayerDigest),
+			DiffID: lookupProperty(c, PropertyLayerDiffID),
+		},
+	}
+	if pkg.Version == "" {
+		pkg.Version = c.Version
+	}
+	if epoch := lookupProperty(c, PropertySrcEpoch); epoch != "" {
+		pkg.SrcEpoch, _ = strconv.Atoi(epoch)
+	}
+	if c.Licenses != nil && len(*c.Licenses) > 0 {
+		l := (*c.Licenses)[0]
+		pkg.License = l.Expression
+		if l.License != nil {
+			pkg.License = l.License.ID
+			if pkg.License == "" {
+				pkg.License = l.License.Name
+			}
+		}
+	}
+
+	switch p.Type {
+	case packageurl.TypeRPM:
+		// Only RPM packages have the epoch and the release separately, i.e. "epoch:version-release"
+		pkg.Epoch, pkg.Version, pkg.Release = splitRPMVersion(pkg.Version)
+	case packageurl.TypeMaven:
+		if p.Namespace != "" {
+			pkg.Name = p.Namespace + ":" + p.Name
+		}
+	}
+
+	// The source packages are the same as the binary packages unless they are specified,
+	// as the vulnerabilities of OS packages are detected by the source packages.
+	if pkg.SrcName == "" {
+		pkg.SrcName, pkg.SrcVersion, pkg.SrcRelease, pkg.SrcEpoch = pkg.Name, pkg.Version, pkg.Release, pkg.Epoch
+	}
+	return pkg
+}
+
+func splitRPMVersion(v string) (int, string, string) {
+	var epoch int
+	if before, after, ok := strings.Cut(v, ":"); ok {
+		if e, err := strconv.Atoi(before); err == nil {
+			epoch, v = e, after
+		}
+	}
+	var release string
+	if i := strings.LastIndex(v, "-"); i != -1 {
+		v, release = v[:i], v[i+1:]
+	}
+	return epoch, v, release
+}
+
+// guessOS returns the OS from the qualifier of the package URL, e.g. "pkg:deb/debian/bash@5.1-2?distro=debian-11"
+func guessOS(p packageurl.PackageURL) *ftypes.OS {
+	distro := p.Qualifiers.Map()["distro"]
+	if p.Namespace == "" || distro == "" {
+		return nil
+	}
+	return &ftypes.OS{
+		Family: p.Namespace,
+		Name:   strings.TrimPrefix(distro, p.Namespace+"-"),
+	}
+}
+
+func imageMetadata(bom cdx.BOM) ftypes.ImageMetadata {
+	var metadata ftypes.ImageMetadata
+	if bom.Metadata == nil || bom.Metadata.Component == nil || bom.Metadata.Component.Properties == nil {
+		return metadata
+	}
+	for _, p := range *bom.Metadata.Component.Properties {
+		switch strings.TrimPrefix(p.Name, Namespace) {
+		case PropertyImageID:
+			metadata.ID = p.Value
+		case PropertyDiffID:
+			metadata.DiffIDs = append(metadata.DiffIDs, p.Value)
+		case PropertyRepoTag:
+			metadata.RepoTags = append(metadata.RepoTags, p.Value)
+		case PropertyRepoDigest:
+			metadata.RepoDigests = append(metadata.RepoDigests, p.Value)
+		}
+	}
+	return metadata
+}
+
+func lookupProperty(c cdx.Component, key string) string {
+	if c.Properties == nil {
+		return ""
+	}
+	for _, p := range *c.Properties {
+		if p.Name == Namespace+key {
+			return p.Value
+		}
+	}
+	return ""
+}
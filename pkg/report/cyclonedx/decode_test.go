@@ -0,0 +1,169 @@
+package cyclonedx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/report/cyclonedx"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestDecode(t *testing.T) {
+	// Generated by Trivy
+	var buf bytes.Buffer
+	err := cyclonedx.NewWriter(&buf, "dev").Write(types.Report{
+		SchemaVersion: report.SchemaVersion,
+		ArtifactName:  "myapp:1.0",
+		ArtifactType:  ftypes.ArtifactContainerImage,
+		Metadata: types.Metadata{
+			OS:          &ftypes.OS{Family: "centos", Name: "8.3.2011"},
+			ImageID:     "sha256:5d0da3dc976460b72c77d94c8a1ad043720b0416bfc16c52c45d4847e53fadb6",
+			RepoDigests: []string{"myapp@sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6"},
+		},
+		Results: types.Results{
+			{
+				Target: "myapp:1.0 (centos 8.3.2011)",
+				Class:  types.ClassOSPkg,
+				Type:   "centos",
+				Packages: []types.Package{
+					{
+						Package: ftypes.Package{
+							Name: "binutils", Version: "2.30", Release: "93.el8", Epoch: 1, Arch: "aarch64",
+							SrcName: "binutils", SrcVersion: "2.30", SrcRelease: "93.el8", SrcEpoch: 1, License: "GPLv3+",
+						},
+					},
+				},
+			},
+			{
+				Target: "app/package-lock.json",
+				Class:  types.ClassLangPkg,
+				Type:   "npm",
+				Packages: []types.Package{
+					{Package: ftypes.Package{Name: "@babel/core", Version: "7.18.2"}},
+				},
+			},
+			{
+				Target: "Java",
+				Class:  types.ClassLangPkg,
+				Type:   ftypes.Jar,
+				Packages: []types.Package{
+					{Package: ftypes.Package{Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", FilePath: "app/log4j-core-2.14.1.jar"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	trivyBOM := buf.String()
+
+	// Generated by another tool without the dependency graph
+	otherBOM := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "components": [
+    {"type": "library", "name": "bash", "version": "5.1-2+deb11u1", "purl": "pkg:deb/debian/bash@5.1-2+deb11u1?arch=amd64&distro=debian-11"},
+    {"type": "library", "name": "requests", "version": "2.27.1", "purl": "pkg:pypi/requests@2.27.1", "licenses": [{"license": {"id": "Apache-2.0"}}]},
+    {"type": "library", "name": "unknown", "version": "1.0", "purl": "pkg:hex/unknown@1.0"}
+  ]
+}`
+
+	tests := []struct {
+		name         string
+		bom          string
+		wantBlob     ftypes.BlobInfo
+		wantMetadata ftypes.ImageMetadata
+		wantErr      string
+	}{
+		{
+			name: "generated by Trivy",
+			bom:  trivyBOM,
+			wantBlob: ftypes.BlobInfo{
+				SchemaVersion: ftypes.BlobJSONSchemaVersion,
+				OS:            &ftypes.OS{Family: "centos", Name: "8.3.2011"},
+				PackageInfos: []ftypes.PackageInfo{
+					{
+						Packages: []ftypes.Package{
+							{
+								Name: "binutils", Version: "2.30", Release: "93.el8", Epoch: 1, Arch: "aarch64",
+								SrcName: "binutils", SrcVersion: "2.30", SrcRelease: "93.el8", SrcEpoch: 1, License: "GPLv3+",
+							},
+						},
+					},
+				},
+				Applications: []ftypes.Application{
+					{
+						Type:     "npm",
+						FilePath: "app/package-lock.json",
+						Libraries: []ftypes.Package{
+							{Name: "@babel/core", Version: "7.18.2", SrcName: "@babel/core", SrcVersion: "7.18.2"},
+						},
+					},
+					{
+						Type: ftypes.Jar,
+						Libraries: []ftypes.Package{
+							{
+								Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", FilePath: "app/log4j-core-2.14.1.jar",
+								SrcName: "org.apache.logging.log4j:log4j-core", SrcVersion: "2.14.1",
+							},
+						},
+					},
+				},
+			},
+			wantMetadata: ftypes.ImageMetadata{
+				ID:          "sha256:5d0da3dc976460b72c77d94c8a1ad043720b0416bfc16c52c45d4847e53fadb6",
+				RepoDigests: []string{"myapp@sha256:1ff023b5ac2e3d6a5550e2534eb6750e045e53df1bfa1f543b210a5749a132f6"},
+			},
+		},
+		{
+			name: "generated by another tool",
+			bom:  otherBOM,
+			wantBlob: ftypes.BlobInfo{
+				SchemaVersion: ftypes.BlobJSONSchemaVersion,
+				OS:            &ftypes.OS{Family: "debian", Name: "11"},
+				PackageInfos: []ftypes.PackageInfo{
+					{
+						Packages: []ftypes.Package{
+							{Name: "bash", Version: "5.1-2+deb11u1", Arch: "amd64", SrcName: "bash", SrcVersion: "5.1-2+deb11u1"},
+						},
+					},
+				},
+				Applications: []ftypes.Application{
+					{
+						Type: ftypes.PythonPkg,
+						Libraries: []ftypes.Package{
+							{Name: "requests", Version: "2.27.1", License: "Apache-2.0", SrcName: "requests", SrcVersion: "2.27.1"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "sad path: invalid package URL",
+			bom: `{"bomFormat": "CycloneDX", "specVersion": "1.4",
+  "components": [{"type": "library", "name": "bash", "version": "5.1", "purl": "deb/debian/bash@5.1"}]}`,
+			wantErr: "failed to parse the package URL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bom cdx.BOM
+			require.NoError(t, cdx.NewBOMDecoder(strings.NewReader(tt.bom), cdx.BOMFileFormatJSON).Decode(&bom))
+
+			gotBlob, gotMetadata, err := cyclonedx.Decode(bom)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBlob, gotBlob)
+			assert.Equal(t, tt.wantMetadata, gotMetadata)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package cyclonedx
+
+import (
+	"sort"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+	"golang.org/x/exp/maps"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/scanner/utils"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// WriteEnriched writes the BOM scanned by Trivy with the vulnerabilities detected in its components appended,
+// instead of generating a new BOM, so that the components, the metadata and the serial number given by
+// the upstream tools are preserved. The vulnerabilities already in the BOM are kept as they are.
+// As the BOM is modified, its version is incremented and Trivy is added to the tools.
+func (cw Writer) WriteEnriched(bom *cdx.BOM, report types.Report) error {
+	refs := componentRefs(bom)
+
+	var vulns []cdx.Vulnerability
+	existing := map[string]struct{}{}
+	if bom.Vulnerabilities != nil {
+		vulns = *bom.Vulnerabilities
+		for _, v := range vulns {
+			existing[v.ID] = struct{}{}
+		}
+	}
+
+	vulnMap := map[string]cdx.Vulnerability{}
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			if _, ok := existing[vuln.VulnerabilityID]; ok {
+				continue
+			}
+			ref, ok := refs[vuln.PkgName+vuln.InstalledVersion+vuln.PkgPath]
+			if !ok {
+				log.Logger.Debugf("No component of %s@%s in the BOM for %s", vuln.PkgName, vuln.InstalledVersion,
+					vuln.VulnerabilityID)
+				continue
+			}
+			if v, ok := vulnMap[vuln.VulnerabilityID]; ok {
+				*v.Affects = append(*v.Affects, affects(ref, vuln.InstalledVersion))
+			} else {
+				vulnMap[vuln.VulnerabilityID] = cw.vulnerability(vuln, ref)
+			}
+		}
+	}
+	added := maps.Values(vulnMap)
+	sort.Slice(added, func(i, j int) bool {
+		return added[i].ID > added[j].ID
+	})
+	if vulns = append(vulns, added...); len(vulns) > 0 {
+		bom.Vulnerabilities = &vulns
+	}
+
+	// The vulnerabilities are supported since CycloneDX 1.4
+	if bom.SpecVersion < cdx.SpecVersion {
+		bom.SpecVersion = cdx.SpecVersion
+	}
+	bom.Version++
+
+	if bom.Metadata == nil {
+		bom.Metadata = &cdx.Metadata{}
+	}
+	var tools []cdx.Tool
+	if bom.Metadata.Tools != nil {
+		tools = *bom.Metadata.Tools
+	}
+	tools = append(tools, cdx.Tool{
+		Vendor:  "aquasecurity",
+		Name:    "trivy",
+		Version: cw.version,
+	})
+	bom.Metadata.Tools = &tools
+
+	if err := cdx.NewBOMEncoder(cw.output, cw.format).Encode(bom); err != nil {
+		return xerrors.Errorf("failed to encode bom: %w", err)
+	}
+	return nil
+}
+
+// componentRefs returns the bom-refs of the library components by the packages decoded from them.
+// The package URLs are used as the bom-refs of the components without them, so that the vulnerabilities can refer to them.
+func componentRefs(bom *cdx.BOM) map[string]string {
+	refs := map[string]string{}
+	if bom.Components == nil {
+		return refs
+	}
+	for i := range *bom.Components {
+		c := &(*bom.Components)[i]
+		if c.Type != cdx.ComponentTypeLibrary || c.PackageURL == "" {
+			continue
+		}
+		p, err := packageurl.FromString(c.PackageURL)
+		if err != nil {
+			continue
+		}
+		if c.BOMRef == "" {
+			c.BOMRef = c.PackageURL
+		}
+
+		// The same key as the detected vulnerabilities
+		pkg := toPackage(*c, p)
+		key := pkg.Name + utils.FormatVersion(pkg) + pkg.FilePath
+		if _, ok := refs[key]; !ok {
+			refs[key] = c.BOMRef
+		}
+	}
+	return refs
+}
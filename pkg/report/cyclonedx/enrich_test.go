@@ -0,0 +1,127 @@
+package cyclonedx_test
+
+import (
+	"bytes"
+	"testing"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/report/cyclonedx"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestWriter_WriteEnriched(t *testing.T) {
+	// Generated by another tool
+	bom := &cdx.BOM{
+		BOMFormat:    cdx.BOMFormat,
+		SpecVersion:  "1.3",
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Version:      2,
+		Metadata: &cdx.Metadata{
+			Tools:     &[]cdx.Tool{{Vendor: "example", Name: "sbom-tool", Version: "1.0"}},
+			Component: &cdx.Component{Type: cdx.ComponentTypeApplication, Name: "myapp", Version: "1.0"},
+			Supplier:  &cdx.OrganizationalEntity{Name: "Example"},
+		},
+		Components: &[]cdx.Component{
+			{
+				BOMRef:     "lodash",
+				Type:       cdx.ComponentTypeLibrary,
+				Name:       "lodash",
+				Version:    "4.17.20",
+				PackageURL: "pkg:npm/lodash@4.17.20",
+				Supplier:   &cdx.OrganizationalEntity{Name: "OpenJS"},
+			},
+			{
+				Type:       cdx.ComponentTypeLibrary,
+				Name:       "minimist",
+				Version:    "1.2.5",
+				PackageURL: "pkg:npm/minimist@1.2.5",
+			},
+			{
+				BOMRef:     "express",
+				Type:       cdx.ComponentTypeLibrary,
+				Name:       "express",
+				Version:    "4.17.3",
+				PackageURL: "pkg:npm/express@4.17.3",
+			},
+		},
+		Vulnerabilities: &[]cdx.Vulnerability{
+			{
+				ID:      "CVE-2021-23337",
+				Affects: &[]cdx.Affects{{Ref: "lodash"}},
+			},
+		},
+	}
+
+	report := types.Report{
+		ArtifactName: "bom.json",
+		Results: types.Results{
+			{
+				Target: "Node.js",
+				Class:  types.ClassLangPkg,
+				Type:   "node-pkg",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{
+						VulnerabilityID:  "CVE-2021-23337",
+						PkgName:          "lodash",
+						InstalledVersion: "4.17.20",
+					},
+					{
+						VulnerabilityID:  "CVE-2020-28500",
+						PkgName:          "lodash",
+						InstalledVersion: "4.17.20",
+						Vulnerability: dtypes.Vulnerability{
+							Severity: "MEDIUM",
+						},
+					},
+					{
+						VulnerabilityID:  "CVE-2021-44906",
+						PkgName:          "minimist",
+						InstalledVersion: "1.2.5",
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := cyclonedx.NewWriter(&buf, "dev").WriteEnriched(bom, report)
+	require.NoError(t, err)
+
+	var got cdx.BOM
+	require.NoError(t, cdx.NewBOMDecoder(&buf, cdx.BOMFileFormatJSON).Decode(&got))
+
+	// The upstream metadata is preserved
+	assert.Equal(t, "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", got.SerialNumber)
+	assert.Equal(t, cdx.SpecVersion, got.SpecVersion)
+	assert.Equal(t, 3, got.Version)
+	assert.Equal(t, &[]cdx.Tool{
+		{Vendor: "example", Name: "sbom-tool", Version: "1.0"},
+		{Vendor: "aquasecurity", Name: "trivy", Version: "dev"},
+	}, got.Metadata.Tools)
+	assert.Equal(t, bom.Metadata.Component, got.Metadata.Component)
+	assert.Equal(t, bom.Metadata.Supplier, got.Metadata.Supplier)
+
+	// The package URL is used as the bom-ref of the component without it
+	require.Len(t, *got.Components, 3)
+	assert.Equal(t, &cdx.OrganizationalEntity{Name: "OpenJS"}, (*got.Components)[0].Supplier)
+	assert.Equal(t, "pkg:npm/minimist@1.2.5", (*got.Components)[1].BOMRef)
+
+	var ids []string
+	affected := map[string][]string{}
+	for _, v := range *got.Vulnerabilities {
+		ids = append(ids, v.ID)
+		for _, a := range *v.Affects {
+			affected[v.ID] = append(affected[v.ID], a.Ref)
+		}
+	}
+	assert.Equal(t, []string{"CVE-2021-23337", "CVE-2021-44906", "CVE-2020-28500"}, ids)
+	assert.Equal(t, map[string][]string{
+		"CVE-2021-23337": {"lodash"},
+		"CVE-2021-44906": {"pkg:npm/minimist@1.2.5"},
+		"CVE-2020-28500": {"lodash"},
+	}, affected)
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	fake "k8s.io/utils/clock/testing"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// resultClassOrder is the order of the results in the deterministic reports, in the same way as the scanners
+var resultClassOrder = []types.ResultClass{
+	types.ClassOSPkg,
+	types.ClassLangPkg,
+	types.ClassConfig,
+	types.ClassSecret,
+	types.ClassLicense,
+	types.ClassCustom,
+}
+
+// Deterministic sorts the results and the findings stably, and clears the fields depending on the time and the
+// environment of the scan, so that the same artifact scanned with the same DB results in the same report.
+//   - The creation time of the image and its history are zeroed.
+//   - The repository tags and digests, which depend on the registries the image was pulled from, are omitted.
+//   - The first and last seen times of '--history-dir' are omitted.
+//
+// The given report is not modified.
+func Deterministic(report types.Report) types.Report {
+	report.Metadata.RepoTags = nil
+	report.Metadata.RepoDigests = nil
+	report.Metadata.ImageConfig.Created = v1.Time{}
+	if history := report.Metadata.ImageConfig.History; len(history) > 0 {
+		report.Metadata.ImageConfig.History = make([]v1.History, len(history))
+		for i, h := range history {
+			h.Created = v1.Time{}
+			report.Metadata.ImageConfig.History[i] = h
+		}
+	}
+
+	results := make(types.Results, len(report.Results))
+	for i, result := range report.Results {
+		results[i] = deterministicResult(result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return less(
+			[]string{classRank(results[i].Class), results[i].Target, results[i].Type},
+			[]string{classRank(results[j].Class), results[j].Target, results[j].Type},
+		)
+	})
+	report.Results = results
+	return report
+}
+
+func deterministicResult(result types.Result) types.Result {
+	result.Packages = slices.Clone(result.Packages)
+	sort.SliceStable(result.Packages, func(i, j int) bool {
+		pi, pj := result.Packages[i], result.Packages[j]
+		return less([]string{pi.Name, pi.Version, pi.FilePath}, []string{pj.Name, pj.Version, pj.FilePath})
+	})
+
+	// The vulnerabilities are in the same order as usual, and those of the same package in the different files are
+	// ordered by the files
+	result.Vulnerabilities = slices.Clone(result.Vulnerabilities)
+	for i := range result.Vulnerabilities {
+		result.Vulnerabilities[i].Tracking = nil
+	}
+	vulns := types.BySeverity(result.Vulnerabilities)
+	sort.SliceStable(vulns, func(i, j int) bool {
+		if vulns.Less(i, j) || vulns.Less(j, i) {
+			return vulns.Less(i, j)
+		}
+		return less([]string{vulns[i].PkgPath, vulns[i].Layer.DiffID}, []string{vulns[j].PkgPath, vulns[j].Layer.DiffID})
+	})
+
+	result.Misconfigurations = slices.Clone(result.Misconfigurations)
+	for i := range result.Misconfigurations {
+		result.Misconfigurations[i].Tracking = nil
+	}
+	sort.SliceStable(result.Misconfigurations, func(i, j int) bool {
+		mi, mj := result.Misconfigurations[i], result.Misconfigurations[j]
+		return less(
+			[]string{mi.Type, mi.ID, fmt.Sprintf("%09d", mi.CauseMetadata.StartLine), mi.Message},
+			[]string{mj.Type, mj.ID, fmt.Sprintf("%09d", mj.CauseMetadata.StartLine), mj.Message},
+		)
+	})
+
+	result.Secrets = slices.Clone(result.Secrets)
+	sort.SliceStable(result.Secrets, func(i, j int) bool {
+		si, sj := result.Secrets[i], result.Secrets[j]
+		if si.StartLine != sj.StartLine {
+			return si.StartLine < sj.StartLine
+		}
+		return si.RuleID < sj.RuleID
+	})
+
+	result.Licenses = slices.Clone(result.Licenses)
+	sort.SliceStable(result.Licenses, func(i, j int) bool {
+		li, lj := result.Licenses[i], result.Licenses[j]
+		return less([]string{li.PkgName, li.FilePath, li.Name}, []string{lj.PkgName, lj.FilePath, lj.Name})
+	})
+	return result
+}
+
+// classRank returns the position of the class as a sortable string, and the unknown classes are the last
+func classRank(class types.ResultClass) string {
+	i := slices.Index(resultClassOrder, class)
+	if i < 0 {
+		i = len(resultClassOrder)
+	}
+	return fmt.Sprintf("%02d", i)
+}
+
+// less compares the keys in order
+func less(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// deterministicUUID returns the UUIDs in sequence instead of the random ones, e.g. for the serial numbers of the SBOMs.
+// The sequence is the same as long as the report is the same.
+func deterministicUUID() func() uuid.UUID {
+	var n int
+	return func() uuid.UUID {
+		n++
+		return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("trivy-%d", n)))
+	}
+}
+
+// deterministicClock returns the zero time for the timestamps of the SBOMs
+func deterministicClock() *fake.FakeClock {
+	return fake.NewFakeClock(time.Time{})
+}
@@ -0,0 +1,158 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestDeterministic(t *testing.T) {
+	created := v1.Time{Time: time.Date(2022, 6, 1, 12, 34, 56, 789, time.UTC)}
+	tracking := &types.Tracking{Fingerprint: "abc", FirstSeen: created.Time, LastSeen: created.Time}
+	input := types.Report{
+		ArtifactName: "alpine:3.15",
+		ArtifactType: ftypes.ArtifactContainerImage,
+		Metadata: types.Metadata{
+			ImageID:     "sha256:c059bfaa849c4d8e4aecaeb3a10c2d9b3d85f5165c66ad3a4d937758128c4d18",
+			RepoTags:    []string{"localhost:5000/alpine:3.15"},
+			RepoDigests: []string{"localhost:5000/alpine@sha256:4edbd2beb5f78b1014028f4fbb99f3237d9561100b6881aabbf5acce2c4f9454"},
+			ImageConfig: v1.ConfigFile{
+				Created: created,
+				History: []v1.History{{Created: created, CreatedBy: "ADD file:1234 in /"}},
+			},
+		},
+		Results: types.Results{
+			{
+				Target: "app/package-lock.json",
+				Class:  types.ClassLangPkg,
+				Type:   "npm",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2022-0002", PkgName: "lodash", PkgPath: "app/b", Tracking: tracking},
+					{VulnerabilityID: "CVE-2022-0002", PkgName: "lodash", PkgPath: "app/a"},
+					{VulnerabilityID: "CVE-2022-0001", PkgName: "express"},
+				},
+			},
+			{
+				Target: "alpine:3.15 (alpine 3.15.4)",
+				Class:  types.ClassOSPkg,
+				Type:   "alpine",
+			},
+			{
+				Target: "Dockerfile",
+				Class:  types.ClassConfig,
+				Type:   "dockerfile",
+				Misconfigurations: []types.DetectedMisconfiguration{
+					{ID: "DS002", Tracking: tracking},
+					{ID: "DS001"},
+				},
+			},
+		},
+	}
+	want := types.Report{
+		ArtifactName: "alpine:3.15",
+		ArtifactType: ftypes.ArtifactContainerImage,
+		Metadata: types.Metadata{
+			ImageID: "sha256:c059bfaa849c4d8e4aecaeb3a10c2d9b3d85f5165c66ad3a4d937758128c4d18",
+			ImageConfig: v1.ConfigFile{
+				History: []v1.History{{CreatedBy: "ADD file:1234 in /"}},
+			},
+		},
+		Results: types.Results{
+			{
+				Target: "alpine:3.15 (alpine 3.15.4)",
+				Class:  types.ClassOSPkg,
+				Type:   "alpine",
+			},
+			{
+				Target: "app/package-lock.json",
+				Class:  types.ClassLangPkg,
+				Type:   "npm",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2022-0001", PkgName: "express"},
+					{VulnerabilityID: "CVE-2022-0002", PkgName: "lodash", PkgPath: "app/a"},
+					{VulnerabilityID: "CVE-2022-0002", PkgName: "lodash", PkgPath: "app/b"},
+				},
+			},
+			{
+				Target: "Dockerfile",
+				Class:  types.ClassConfig,
+				Type:   "dockerfile",
+				Misconfigurations: []types.DetectedMisconfiguration{
+					{ID: "DS001"},
+					{ID: "DS002"},
+				},
+			},
+		},
+	}
+
+	got := report.Deterministic(input)
+	assert.Equal(t, want, got)
+
+	// The given report is kept as it is
+	assert.Equal(t, created, input.Metadata.ImageConfig.History[0].Created)
+	assert.Equal(t, tracking, input.Results[0].Vulnerabilities[0].Tracking)
+	assert.Equal(t, "CVE-2022-0002", input.Results[0].Vulnerabilities[0].VulnerabilityID)
+}
+
+func TestWrite_deterministic(t *testing.T) {
+	input := types.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "alpine:3.15",
+		ArtifactType:  ftypes.ArtifactContainerImage,
+		Metadata: types.Metadata{
+			OS: &ftypes.OS{Family: "alpine", Name: "3.15.4"},
+		},
+		Results: types.Results{
+			{
+				Target: "alpine:3.15 (alpine 3.15.4)",
+				Class:  types.ClassOSPkg,
+				Type:   "alpine",
+				Packages: []types.Package{
+					{Package: ftypes.Package{Name: "musl", Version: "1.2.2-r7"}},
+					{Package: ftypes.Package{Name: "busybox", Version: "1.34.1-r5"}},
+				},
+				Vulnerabilities: []types.DetectedVulnerability{
+					{
+						VulnerabilityID:  "CVE-2022-28391",
+						PkgName:          "busybox",
+						InstalledVersion: "1.34.1-r5",
+						Vulnerability:    dbTypes.Vulnerability{Severity: "HIGH"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, format := range []string{"cyclonedx", "spdx-json", "cosign-vuln"} {
+		t.Run(format, func(t *testing.T) {
+			var outputs []string
+			for i := 0; i < 2; i++ {
+				var buf bytes.Buffer
+				err := report.Write(input, report.Option{
+					Format:        format,
+					Output:        &buf,
+					AppVersion:    "dev",
+					ScanStartedOn: time.Now(),
+					Deterministic: true,
+				})
+				require.NoError(t, err)
+				outputs = append(outputs, buf.String())
+			}
+			assert.Equal(t, outputs[0], outputs[1])
+			assert.NotContains(t, outputs[0], time.Now().Format("2006-01-02"))
+
+			var v interface{}
+			require.NoError(t, json.Unmarshal([]byte(outputs[0]), &v))
+		})
+	}
+}
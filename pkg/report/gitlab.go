@@ -0,0 +1,366 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/scanner/utils"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// GitLabSchemaVersion is the version of the GitLab security report schemas the dependency scanning reports conform to
+// ref. https://gitlab.com/gitlab-org/security-products/security-report-schemas
+const GitLabSchemaVersion = "15.0.4"
+
+// gitlabTimeFormat is the format of the times of the scans, which have no time zone and are in UTC
+const gitlabTimeFormat = "2006-01-02T15:04:05"
+
+// gitlabTemplateDirs are the directories the GitLab templates were shipped in,
+// i.e. the working directory of the archives, the container images and the deb/rpm packages.
+var gitlabTemplateDirs = []string{"contrib", "/contrib", "/usr/local/share/trivy/templates"}
+
+// gitlabTemplateFormat returns the format replacing the removed GitLab template, e.g. "@contrib/gitlab.tpl",
+// or empty for the other templates.
+// Only the shipped templates are replaced, so the custom templates with the same file names are still rendered.
+func gitlabTemplateFormat(outputTemplate string) string {
+	if !strings.HasPrefix(outputTemplate, "@") {
+		return ""
+	}
+	dir, file := path.Split(path.Clean(strings.TrimPrefix(outputTemplate, "@")))
+	if !slices.Contains(gitlabTemplateDirs, path.Clean(dir)) {
+		return ""
+	}
+	switch file {
+	case "gitlab.tpl":
+		return "gitlab-dependency-scanning"
+	case "gitlab-codequality.tpl":
+		return "gitlab-codequality"
+	}
+	return ""
+}
+
+// gitlabNamespace derives the IDs of the vulnerabilities, so that GitLab tracks the same findings across the scans
+var gitlabNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/aquasecurity/trivy"))
+
+// GitLabDependencyScanningReport is the dependency scanning report of GitLab
+// ref. https://docs.gitlab.com/ee/development/integrations/secure.html#report
+type GitLabDependencyScanningReport struct {
+	Version         string                 `json:"version"`
+	Scan            GitLabScan             `json:"scan"`
+	Vulnerabilities []GitLabVulnerability  `json:"vulnerabilities"`
+	DependencyFiles []GitLabDependencyFile `json:"dependency_files"`
+	Remediations    []interface{}          `json:"remediations"`
+}
+
+type GitLabScan struct {
+	Analyzer  GitLabScanner `json:"analyzer"`
+	Scanner   GitLabScanner `json:"scanner"`
+	Type      string        `json:"type"`
+	StartTime string        `json:"start_time"`
+	EndTime   string        `json:"end_time"`
+	Status    string        `json:"status"`
+}
+
+type GitLabScanner struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	URL     string       `json:"url,omitempty"`
+	Version string       `json:"version"`
+	Vendor  GitLabVendor `json:"vendor"`
+}
+
+type GitLabVendor struct {
+	Name string `json:"name"`
+}
+
+type GitLabVulnerability struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Severity    string             `json:"severity"`
+	Solution    string             `json:"solution,omitempty"`
+	Identifiers []GitLabIdentifier `json:"identifiers"`
+	Links       []GitLabLink       `json:"links,omitempty"`
+	Location    GitLabLocation     `json:"location"`
+}
+
+type GitLabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type GitLabLink struct {
+	URL string `json:"url"`
+}
+
+type GitLabLocation struct {
+	File       string           `json:"file"`
+	Dependency GitLabDependency `json:"dependency"`
+}
+
+type GitLabDependency struct {
+	Package GitLabPackage `json:"package"`
+	Version string        `json:"version"`
+}
+
+type GitLabPackage struct {
+	Name string `json:"name"`
+}
+
+type GitLabDependencyFile struct {
+	Path           string             `json:"path"`
+	PackageManager string             `json:"package_manager"`
+	Dependencies   []GitLabDependency `json:"dependencies"`
+}
+
+// GitLabDependencyScanningWriter writes the vulnerabilities as the dependency scanning report of GitLab,
+// which is uploaded as the "dependency_scanning" report of the CI jobs
+type GitLabDependencyScanningWriter struct {
+	Output    io.Writer
+	Version   string
+	StartedOn time.Time
+	Now       func() time.Time
+}
+
+// Write writes the vulnerabilities in the GitLab dependency scanning format
+func (gw GitLabDependencyScanningWriter) Write(report types.Report) error {
+	finishedOn := gw.Now()
+	startedOn := gw.StartedOn
+	if startedOn.IsZero() {
+		startedOn = finishedOn
+	}
+
+	scanner := GitLabScanner{
+		ID:      "trivy",
+		Name:    "Trivy",
+		URL:     "https://github.com/aquasecurity/trivy",
+		Version: gw.Version,
+		Vendor:  GitLabVendor{Name: "Aqua Security"},
+	}
+	output := GitLabDependencyScanningReport{
+		Version: GitLabSchemaVersion,
+		Scan: GitLabScan{
+			Analyzer:  scanner,
+			Scanner:   scanner,
+			Type:      "dependency_scanning",
+			StartTime: startedOn.UTC().Format(gitlabTimeFormat),
+			EndTime:   finishedOn.UTC().Format(gitlabTimeFormat),
+			Status:    "success",
+		},
+		Vulnerabilities: []GitLabVulnerability{},
+		DependencyFiles: []GitLabDependencyFile{},
+		Remediations:    []interface{}{},
+	}
+
+	for _, res := range report.Results {
+		if res.Class != types.ClassOSPkg && res.Class != types.ClassLangPkg {
+			continue
+		}
+		file := GitLabDependencyFile{
+			Path:           toPathUri(res.Target),
+			PackageManager: string(res.Type),
+			Dependencies:   []GitLabDependency{},
+		}
+		for _, pkg := range res.Packages {
+			file.Dependencies = append(file.Dependencies, GitLabDependency{
+				Package: GitLabPackage{Name: pkg.Name},
+				Version: utils.FormatVersion(pkg.Package),
+			})
+		}
+		output.DependencyFiles = append(output.DependencyFiles, file)
+
+		for _, vuln := range res.Vulnerabilities {
+			output.Vulnerabilities = append(output.Vulnerabilities, toGitLabVulnerability(res.Target, vuln))
+		}
+	}
+
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the GitLab dependency scanning report: %w", err)
+	}
+	if _, err = fmt.Fprintln(gw.Output, string(b)); err != nil {
+		return xerrors.Errorf("failed to write the GitLab dependency scanning report: %w", err)
+	}
+	return nil
+}
+
+func toGitLabVulnerability(target string, vuln types.DetectedVulnerability) GitLabVulnerability {
+	file := vuln.PkgPath
+	if file == "" {
+		file = target
+	}
+	file = toPathUri(file)
+
+	var solution string
+	if vuln.FixedVersion != "" {
+		solution = fmt.Sprintf("Upgrade %s to %s", vuln.PkgName, vuln.FixedVersion)
+	}
+
+	identifiers := []GitLabIdentifier{{
+		Type:  gitlabIdentifierType(vuln.VulnerabilityID),
+		Name:  vuln.VulnerabilityID,
+		Value: vuln.VulnerabilityID,
+		URL:   vuln.PrimaryURL,
+	}}
+	for _, cweID := range vuln.CweIDs {
+		value := strings.TrimPrefix(cweID, "CWE-")
+		identifiers = append(identifiers, GitLabIdentifier{
+			Type:  "cwe",
+			Name:  cweID,
+			Value: value,
+			URL:   fmt.Sprintf("https://cwe.mitre.org/data/definitions/%s.html", value),
+		})
+	}
+
+	var links []GitLabLink
+	for _, ref := range vuln.References {
+		links = append(links, GitLabLink{URL: ref})
+	}
+
+	name := vuln.Title
+	if name == "" {
+		name = vuln.VulnerabilityID
+	}
+
+	return GitLabVulnerability{
+		ID: uuid.NewSHA1(gitlabNamespace, []byte(strings.Join([]string{file, vuln.PkgName, vuln.InstalledVersion,
+			vuln.VulnerabilityID}, "/"))).String(),
+		Name:        truncate(name, 255),
+		Description: vuln.Description,
+		Severity:    toGitLabSeverity(vuln.Severity),
+		Solution:    solution,
+		Identifiers: identifiers,
+		Links:       links,
+		Location: GitLabLocation{
+			File: file,
+			Dependency: GitLabDependency{
+				Package: GitLabPackage{Name: vuln.PkgName},
+				Version: vuln.InstalledVersion,
+			},
+		},
+	}
+}
+
+// gitlabIdentifierType returns the type of the identifier from the prefix of the vulnerability ID,
+// e.g. "cve" of "CVE-2021-23337" and "ghsa" of "GHSA-35jh-r3h4-6jhm"
+func gitlabIdentifierType(id string) string {
+	if prefix, _, ok := strings.Cut(id, "-"); ok && prefix != "" {
+		return strings.ToLower(prefix)
+	}
+	return "trivy"
+}
+
+// toGitLabSeverity returns the severity of the vulnerability in GitLab, which is capitalized
+func toGitLabSeverity(severity string) string {
+	switch severity {
+	case "CRITICAL":
+		return "Critical"
+	case "HIGH":
+		return "High"
+	case "MEDIUM":
+		return "Medium"
+	case "LOW":
+		return "Low"
+	default:
+		return "Unknown"
+	}
+}
+
+// GitLabCodeQualityIssue is an issue of the code quality report of GitLab, a subset of the Code Climate issues
+// ref. https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+type GitLabCodeQualityIssue struct {
+	Type        string                    `json:"type"`
+	CheckName   string                    `json:"check_name"`
+	Description string                    `json:"description"`
+	Content     *GitLabCodeQualityContent `json:"content,omitempty"`
+	Categories  []string                  `json:"categories"`
+	Fingerprint string                    `json:"fingerprint"`
+	Severity    string                    `json:"severity"`
+	Location    GitLabCodeQualityLocation `json:"location"`
+}
+
+type GitLabCodeQualityContent struct {
+	Body string `json:"body"`
+}
+
+type GitLabCodeQualityLocation struct {
+	Path  string                 `json:"path"`
+	Lines GitLabCodeQualityLines `json:"lines"`
+}
+
+type GitLabCodeQualityLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// GitLabCodeQualityWriter writes the findings as the code quality report of GitLab,
+// which is uploaded as the "codequality" report of the CI jobs
+type GitLabCodeQualityWriter struct {
+	Output io.Writer
+}
+
+// Write writes the findings in the GitLab code quality format
+func (gw GitLabCodeQualityWriter) Write(report types.Report) error {
+	issues := []GitLabCodeQualityIssue{}
+	for _, a := range annotations(report) {
+		// The lines start at 1, and the findings without lines are put on the first line
+		begin, end := a.startLine, a.endLine
+		if begin < 1 {
+			begin = 1
+		}
+		if end <= begin {
+			end = 0
+		}
+
+		var content *GitLabCodeQualityContent
+		if a.url != "" {
+			content = &GitLabCodeQualityContent{Body: fmt.Sprintf("%s\n\n[%s](%s)", a.title, a.id, a.url)}
+		}
+		issues = append(issues, GitLabCodeQualityIssue{
+			Type:        "issue",
+			CheckName:   a.id,
+			Description: a.message,
+			Content:     content,
+			Categories:  []string{"Security"},
+			Fingerprint: a.fingerprint(),
+			Severity:    toGitLabCodeQualitySeverity(a.severity),
+			Location: GitLabCodeQualityLocation{
+				Path:  a.path,
+				Lines: GitLabCodeQualityLines{Begin: begin, End: end},
+			},
+		})
+	}
+
+	b, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the GitLab code quality report: %w", err)
+	}
+	if _, err = fmt.Fprintln(gw.Output, string(b)); err != nil {
+		return xerrors.Errorf("failed to write the GitLab code quality report: %w", err)
+	}
+	return nil
+}
+
+// toGitLabCodeQualitySeverity returns the severity of the issue, which has no UNKNOWN
+func toGitLabCodeQualitySeverity(severity string) string {
+	switch severity {
+	case "CRITICAL":
+		return "critical"
+	case "HIGH":
+		return "major"
+	case "MEDIUM":
+		return "minor"
+	default:
+		return "info"
+	}
+}
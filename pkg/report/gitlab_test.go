@@ -0,0 +1,332 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestGitLabDependencyScanningWriter_Write(t *testing.T) {
+	startedOn := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	finishedOn := startedOn.Add(90 * time.Second)
+
+	scanner := report.GitLabScanner{
+		ID:      "trivy",
+		Name:    "Trivy",
+		URL:     "https://github.com/aquasecurity/trivy",
+		Version: "0.30.0",
+		Vendor:  report.GitLabVendor{Name: "Aqua Security"},
+	}
+	tests := []struct {
+		name                string
+		report              types.Report
+		template            string
+		wantVulnerabilities []report.GitLabVulnerability
+		wantDependencyFiles []report.GitLabDependencyFile
+	}{
+		{
+			name: "vulnerabilities",
+			report: types.Report{
+				ArtifactName: "alpine:3.15",
+				Results: types.Results{
+					{
+						Target: "alpine:3.15 (alpine 3.15.4)",
+						Class:  types.ClassOSPkg,
+						Type:   "alpine",
+						Packages: []types.Package{
+							{Package: ftypes.Package{Name: "busybox", Version: "1.34.1", Release: "r5"}},
+						},
+						Vulnerabilities: []types.DetectedVulnerability{
+							{
+								VulnerabilityID:  "CVE-2022-28391",
+								PkgName:          "busybox",
+								InstalledVersion: "1.34.1-r5",
+								FixedVersion:     "1.34.1-r6",
+								PrimaryURL:       "https://avd.aquasec.com/nvd/cve-2022-28391",
+								Vulnerability: dbTypes.Vulnerability{
+									Title:       "busybox: remote attackers may execute arbitrary code",
+									Description: "BusyBox through 1.35.0 allows remote attackers to execute arbitrary code.",
+									Severity:    "HIGH",
+									CweIDs:      []string{"CWE-78"},
+									References:  []string{"https://bugs.busybox.net/show_bug.cgi?id=15001"},
+								},
+							},
+						},
+					},
+					{
+						Target: "app/go.sum",
+						Class:  types.ClassLangPkg,
+						Type:   "gomod",
+						Vulnerabilities: []types.DetectedVulnerability{
+							{
+								VulnerabilityID:  "GHSA-qq97-vm5h-rrhg",
+								PkgName:          "github.com/docker/distribution",
+								InstalledVersion: "2.7.1",
+								Vulnerability: dbTypes.Vulnerability{
+									Severity: "UNKNOWN",
+								},
+							},
+						},
+					},
+					{
+						Target: "Dockerfile",
+						Class:  types.ClassConfig,
+					},
+				},
+			},
+			wantVulnerabilities: []report.GitLabVulnerability{
+				{
+					Name:        "busybox: remote attackers may execute arbitrary code",
+					Description: "BusyBox through 1.35.0 allows remote attackers to execute arbitrary code.",
+					Severity:    "High",
+					Solution:    "Upgrade busybox to 1.34.1-r6",
+					Identifiers: []report.GitLabIdentifier{
+						{
+							Type:  "cve",
+							Name:  "CVE-2022-28391",
+							Value: "CVE-2022-28391",
+							URL:   "https://avd.aquasec.com/nvd/cve-2022-28391",
+						},
+						{
+							Type:  "cwe",
+							Name:  "CWE-78",
+							Value: "78",
+							URL:   "https://cwe.mitre.org/data/definitions/78.html",
+						},
+					},
+					Links: []report.GitLabLink{{URL: "https://bugs.busybox.net/show_bug.cgi?id=15001"}},
+					Location: report.GitLabLocation{
+						File: "alpine:3.15",
+						Dependency: report.GitLabDependency{
+							Package: report.GitLabPackage{Name: "busybox"},
+							Version: "1.34.1-r5",
+						},
+					},
+				},
+				{
+					Name:     "GHSA-qq97-vm5h-rrhg",
+					Severity: "Unknown",
+					Identifiers: []report.GitLabIdentifier{
+						{
+							Type:  "ghsa",
+							Name:  "GHSA-qq97-vm5h-rrhg",
+							Value: "GHSA-qq97-vm5h-rrhg",
+						},
+					},
+					Location: report.GitLabLocation{
+						File: "app/go.sum",
+						Dependency: report.GitLabDependency{
+							Package: report.GitLabPackage{Name: "github.com/docker/distribution"},
+							Version: "2.7.1",
+						},
+					},
+				},
+			},
+			wantDependencyFiles: []report.GitLabDependencyFile{
+				{
+					Path:           "alpine:3.15",
+					PackageManager: "alpine",
+					Dependencies: []report.GitLabDependency{
+						{Package: report.GitLabPackage{Name: "busybox"}, Version: "1.34.1-r5"},
+					},
+				},
+				{
+					Path:           "app/go.sum",
+					PackageManager: "gomod",
+					Dependencies:   []report.GitLabDependency{},
+				},
+			},
+		},
+		{
+			name:                "gitlab.tpl is redirected",
+			report:              types.Report{ArtifactName: "alpine:3.15"},
+			template:            "@contrib/gitlab.tpl",
+			wantVulnerabilities: []report.GitLabVulnerability{},
+			wantDependencyFiles: []report.GitLabDependencyFile{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := "gitlab-dependency-scanning"
+			if tt.template != "" {
+				format = "template"
+			}
+
+			report.Now = func() time.Time { return finishedOn }
+			t.Cleanup(func() { report.Now = time.Now })
+
+			var buf bytes.Buffer
+			err := report.Write(tt.report, report.Option{
+				Format:         format,
+				OutputTemplate: tt.template,
+				Output:         &buf,
+				AppVersion:     "0.30.0",
+				ScanStartedOn:  startedOn,
+			})
+			require.NoError(t, err)
+
+			var got report.GitLabDependencyScanningReport
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, report.GitLabSchemaVersion, got.Version)
+			assert.Equal(t, report.GitLabScan{
+				Analyzer:  scanner,
+				Scanner:   scanner,
+				Type:      "dependency_scanning",
+				StartTime: "2022-06-01T10:00:00",
+				EndTime:   "2022-06-01T10:01:30",
+				Status:    "success",
+			}, got.Scan)
+
+			// The IDs are the UUIDs derived from the findings
+			for i := range got.Vulnerabilities {
+				assert.Len(t, got.Vulnerabilities[i].ID, 36)
+				got.Vulnerabilities[i].ID = ""
+			}
+			assert.Equal(t, tt.wantVulnerabilities, got.Vulnerabilities)
+			assert.Equal(t, tt.wantDependencyFiles, got.DependencyFiles)
+			assert.Empty(t, got.Remediations)
+		})
+	}
+}
+
+func TestGitLabCodeQualityWriter_Write(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   types.Report
+		template string
+		want     []report.GitLabCodeQualityIssue
+	}{
+		{
+			name:   "findings",
+			report: annotatedReport,
+			want: []report.GitLabCodeQualityIssue{
+				{
+					Type:        "issue",
+					CheckName:   "CVE-2021-23337",
+					Description: "CVE-2021-23337 (HIGH): lodash 4.17.20, fixed version: 4.17.21",
+					Content: &report.GitLabCodeQualityContent{
+						Body: "lodash: command injection via template\n\n[CVE-2021-23337](https://avd.aquasec.com/nvd/cve-2021-23337)",
+					},
+					Categories: []string{"Security"},
+					Severity:   "major",
+					Location: report.GitLabCodeQualityLocation{
+						Path:  "app/package-lock.json",
+						Lines: report.GitLabCodeQualityLines{Begin: 1},
+					},
+				},
+				{
+					Type:        "issue",
+					CheckName:   "DS002",
+					Description: "DS002 (HIGH): Specify at least 1 USER command in Dockerfile with non-root user as argument",
+					Content: &report.GitLabCodeQualityContent{
+						Body: "Image user should not be 'root'\n\n[DS002](https://avd.aquasec.com/misconfig/ds002)",
+					},
+					Categories: []string{"Security"},
+					Severity:   "major",
+					Location: report.GitLabCodeQualityLocation{
+						Path:  "Dockerfile",
+						Lines: report.GitLabCodeQualityLines{Begin: 3, End: 4},
+					},
+				},
+				{
+					Type:        "issue",
+					CheckName:   "aws-access-key-id",
+					Description: "aws-access-key-id (UNKNOWN): AWS Access Key ID",
+					Categories:  []string{"Security"},
+					Severity:    "info",
+					Location: report.GitLabCodeQualityLocation{
+						Path:  "config/secret.env",
+						Lines: report.GitLabCodeQualityLines{Begin: 2},
+					},
+				},
+			},
+		},
+		{
+			name:     "gitlab-codequality.tpl is redirected",
+			report:   types.Report{ArtifactName: "myapp"},
+			template: "@/contrib/gitlab-codequality.tpl",
+			want:     []report.GitLabCodeQualityIssue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := "gitlab-codequality"
+			if tt.template != "" {
+				format = "template"
+			}
+
+			var buf bytes.Buffer
+			err := report.Write(tt.report, report.Option{
+				Format:         format,
+				OutputTemplate: tt.template,
+				Output:         &buf,
+			})
+			require.NoError(t, err)
+
+			var got []report.GitLabCodeQualityIssue
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+			// The fingerprints are the same as the other platforms
+			for i := range got {
+				assert.Len(t, got[i].Fingerprint, 64)
+				got[i].Fingerprint = ""
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NotContains(t, buf.String(), "AWS_ACCESS_KEY_ID")
+		})
+	}
+}
+
+func TestWrite_GitLabTemplates(t *testing.T) {
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom-gitlab.tpl")
+	require.NoError(t, os.WriteFile(custom, []byte(`{{ range . }}{{ .Target }}{{ end }}`), 0600))
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "installed gitlab.tpl",
+			template: "@/usr/local/share/trivy/templates/gitlab.tpl",
+			want:     `"dependency_files": []`,
+		},
+		{
+			name:     "gitlab-codequality.tpl in the container image",
+			template: "@/contrib/gitlab-codequality.tpl",
+			want:     "[]",
+		},
+		{
+			name:     "custom template with the same suffix",
+			template: "@" + custom,
+			want:     "alpine:3.15",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := report.Write(types.Report{
+				ArtifactName: "alpine:3.15",
+				Results:      types.Results{{Target: "alpine:3.15"}},
+			}, report.Option{
+				Format:         "template",
+				OutputTemplate: tt.template,
+				Output:         &buf,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, buf.String(), tt.want)
+		})
+	}
+}
@@ -13,11 +13,19 @@ import (
 // JSONWriter implements result Writer
 type JSONWriter struct {
 	Output io.Writer
+
+	// SchemaVersion is the version of the report written, the latest if it is 0
+	SchemaVersion int
 }
 
 // Write writes the results in JSON format
 func (jw JSONWriter) Write(report types.Report) error {
-	output, err := json.MarshalIndent(report, "", "  ")
+	v, err := ConvertSchema(report, jw.SchemaVersion)
+	if err != nil {
+		return xerrors.Errorf("schema error: %w", err)
+	}
+
+	output, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return xerrors.Errorf("failed to marshal json: %w", err)
 	}
@@ -0,0 +1,93 @@
+package predicate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// VulnPredicateType is the in-toto predicate type of the cosign vulnerability attestations,
+// which can be verified with "cosign verify-attestation --type vuln"
+const VulnPredicateType = "https://cosign.sigstore.dev/attestation/vuln/v1"
+
+// CosignVulnPredicate is the predicate of the cosign vulnerability attestations
+// https://github.com/sigstore/cosign/blob/main/specs/COSIGN_VULN_ATTESTATION_SPEC.md
+type CosignVulnPredicate struct {
+	Invocation Invocation `json:"invocation"`
+	Scanner    Scanner    `json:"scanner"`
+	Metadata   Metadata   `json:"metadata"`
+}
+
+// Invocation is the environment where the scan was run, e.g. the CI job
+type Invocation struct {
+	Parameters interface{} `json:"parameters"`
+	URI        string      `json:"uri"`
+	EventID    string      `json:"event_id"`
+	BuilderID  string      `json:"builder.id"`
+}
+
+// DB is the vulnerability DB used by the scanner
+type DB struct {
+	URI     string `json:"uri"`
+	Version string `json:"version"`
+}
+
+// Scanner has the scanner and the result of the scan
+type Scanner struct {
+	URI     string       `json:"uri"`
+	Version string       `json:"version"`
+	DB      DB           `json:"db"`
+	Result  types.Report `json:"result"`
+}
+
+// Metadata has the time when the scan started and finished
+type Metadata struct {
+	ScanStartedOn  time.Time `json:"scanStartedOn"`
+	ScanFinishedOn time.Time `json:"scanFinishedOn"`
+}
+
+// NewVuln returns the predicate of the report
+func NewVuln(report types.Report, version string, startedOn, finishedOn time.Time) CosignVulnPredicate {
+	return CosignVulnPredicate{
+		Scanner: Scanner{
+			URI:     fmt.Sprintf("pkg:github/aquasecurity/trivy@%s", version),
+			Version: version,
+			Result:  report,
+		},
+		Metadata: Metadata{
+			ScanStartedOn:  startedOn.UTC(),
+			ScanFinishedOn: finishedOn.UTC(),
+		},
+	}
+}
+
+// VulnWriter writes the predicate, which is passed to "cosign attest --type vuln --predicate"
+type VulnWriter struct {
+	Output    io.Writer
+	Version   string
+	StartedOn time.Time
+	Now       func() time.Time
+}
+
+// Write writes the predicate of the report in JSON
+func (w VulnWriter) Write(report types.Report) error {
+	finishedOn := w.Now()
+	startedOn := w.StartedOn
+	if startedOn.IsZero() {
+		startedOn = finishedOn
+	}
+
+	output, err := json.MarshalIndent(NewVuln(report, w.Version, startedOn, finishedOn), "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal the predicate: %w", err)
+	}
+	if _, err = fmt.Fprintln(w.Output, string(output)); err != nil {
+		return xerrors.Errorf("failed to write the predicate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package predicate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/report/predicate"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestVulnWriter_Write(t *testing.T) {
+	startedOn := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	finishedOn := time.Date(2022, 5, 1, 12, 1, 0, 0, time.UTC)
+	report := types.Report{
+		SchemaVersion: 2,
+		ArtifactName:  "alpine:3.15",
+		Results: types.Results{
+			{
+				Target: "alpine:3.15 (alpine 3.15.0)",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2022-0778", PkgName: "libssl1.1", InstalledVersion: "1.1.1l-r7"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		startedOn time.Time
+		want      predicate.Metadata
+	}{
+		{
+			name:      "happy path",
+			startedOn: startedOn,
+			want:      predicate.Metadata{ScanStartedOn: startedOn, ScanFinishedOn: finishedOn},
+		},
+		{
+			name: "unknown start",
+			want: predicate.Metadata{ScanStartedOn: finishedOn, ScanFinishedOn: finishedOn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := predicate.VulnWriter{
+				Output:    &buf,
+				Version:   "0.28.0",
+				StartedOn: tt.startedOn,
+				Now:       func() time.Time { return finishedOn },
+			}
+			require.NoError(t, w.Write(report))
+
+			var got predicate.CosignVulnPredicate
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, predicate.CosignVulnPredicate{
+				Scanner: predicate.Scanner{
+					URI:     "pkg:github/aquasecurity/trivy@0.28.0",
+					Version: "0.28.0",
+					Result:  report,
+				},
+				Metadata: tt.want,
+			}, got)
+		})
+	}
+}
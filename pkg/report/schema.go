@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// The schema of the JSON reports is versioned by types.Report.SchemaVersion, following these rules:
+//
+//   - Fields can be added in the same version, and consumers must ignore unknown fields.
+//   - Removing, renaming or changing the type of a field bumps the version,
+//     and a converter from the new version to the previous one is added to schemaConverters.
+//   - Reports of all the previous versions can be decoded by DecodeReport.
+//
+// Version 1 is the array of the results written by Trivy before the reports had the metadata.
+// Version 2 is types.Report.
+
+// SchemaVersions has the versions which the JSON reports can be written in
+var SchemaVersions = []int{1, SchemaVersion}
+
+// schemaConverters convert the report of the key version into the previous version
+var schemaConverters = map[int]func(v interface{}) (interface{}, error){
+	2: func(v interface{}) (interface{}, error) {
+		report, ok := v.(types.Report)
+		if !ok {
+			return nil, xerrors.Errorf("unexpected report type: %T", v)
+		}
+		return report.Results, nil
+	},
+}
+
+// ConvertSchema returns the report in the schema version, which is the latest if the version is 0
+func ConvertSchema(report types.Report, version int) (interface{}, error) {
+	if version == 0 {
+		version = SchemaVersion
+	}
+	if err := ValidateSchemaVersion(version); err != nil {
+		return nil, err
+	}
+
+	var v interface{} = report
+	for current := SchemaVersion; current > version; current-- {
+		var err error
+		if v, err = schemaConverters[current](v); err != nil {
+			return nil, xerrors.Errorf("schema conversion error (%d to %d): %w", current, current-1, err)
+		}
+	}
+	return v, nil
+}
+
+// DecodeReport decodes the JSON report of any schema version into the latest version
+func DecodeReport(r io.Reader) (types.Report, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return types.Report{}, xerrors.Errorf("read error: %w", err)
+	}
+
+	// Version 1 is an array
+	if b = bytes.TrimSpace(b); bytes.HasPrefix(b, []byte("[")) {
+		var results types.Results
+		if err = json.Unmarshal(b, &results); err != nil {
+			return types.Report{}, xerrors.Errorf("JSON decode error: %w", err)
+		}
+		return types.Report{
+			SchemaVersion: SchemaVersion,
+			Results:       results,
+		}, nil
+	}
+
+	var report types.Report
+	if err = json.Unmarshal(b, &report); err != nil {
+		return types.Report{}, xerrors.Errorf("JSON decode error: %w", err)
+	}
+	if report.SchemaVersion > SchemaVersion {
+		return types.Report{}, xerrors.Errorf("schema version %d is newer than the supported version %d, update Trivy",
+			report.SchemaVersion, SchemaVersion)
+	}
+	report.SchemaVersion = SchemaVersion
+	return report, nil
+}
+
+// ValidateSchemaVersion returns an error if the JSON reports can't be written in the version
+func ValidateSchemaVersion(version int) error {
+	if slices.Contains(SchemaVersions, version) {
+		return nil
+	}
+	var names []string
+	for _, v := range SchemaVersions {
+		names = append(names, strconv.Itoa(v))
+	}
+	return xerrors.Errorf("unknown schema version: %d, supported: %s", version, strings.Join(names, ", "))
+}
@@ -0,0 +1,138 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestSchema(t *testing.T) {
+	input := types.Report{
+		SchemaVersion: report.SchemaVersion,
+		ArtifactName:  "alpine:3.14",
+		Results: types.Results{
+			{
+				Target: "alpine:3.14 (alpine 3.14.2)",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{
+						VulnerabilityID:  "CVE-2021-36159",
+						PkgName:          "apk-tools",
+						InstalledVersion: "2.12.6-r0",
+						FixedVersion:     "2.12.6-r1",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		schemaVersion int
+		wantPrefix    string
+		want          types.Report
+		wantErr       string
+	}{
+		{
+			name:       "latest",
+			wantPrefix: "{",
+			want:       input,
+		},
+		{
+			name:          "version 2",
+			schemaVersion: 2,
+			wantPrefix:    "{",
+			want:          input,
+		},
+		{
+			name:          "version 1",
+			schemaVersion: 1,
+			wantPrefix:    "[",
+			want: types.Report{
+				SchemaVersion: report.SchemaVersion,
+				Results:       input.Results,
+			},
+		},
+		{
+			name:          "sad path: unknown version",
+			schemaVersion: 3,
+			wantErr:       "unknown schema version: 3, supported: 1, 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := report.Write(input, report.Option{
+				Format:        "json",
+				Output:        &buf,
+				SchemaVersion: tt.schemaVersion,
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(buf.String(), tt.wantPrefix), buf.String())
+
+			// The reports of the old versions are decoded into the latest version
+			got, err := report.DecodeReport(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    types.Report
+		wantErr string
+	}{
+		{
+			name:  "version 2",
+			input: `{"SchemaVersion": 2, "ArtifactName": "alpine:3.14", "Results": [{"Target": "foo"}]}`,
+			want: types.Report{
+				SchemaVersion: 2,
+				ArtifactName:  "alpine:3.14",
+				Results:       types.Results{{Target: "foo"}},
+			},
+		},
+		{
+			name:  "version 1",
+			input: `  [{"Target": "foo"}]`,
+			want: types.Report{
+				SchemaVersion: 2,
+				Results:       types.Results{{Target: "foo"}},
+			},
+		},
+		{
+			name:    "sad path: newer version",
+			input:   `{"SchemaVersion": 3}`,
+			wantErr: "schema version 3 is newer than the supported version 2",
+		},
+		{
+			name:    "sad path: invalid JSON",
+			input:   `{`,
+			wantErr: "JSON decode error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := report.DecodeReport(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
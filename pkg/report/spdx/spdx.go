@@ -3,6 +3,7 @@ package spdx
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/utils/clock"
 
 	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/scanner/utils"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
@@ -26,6 +28,13 @@ const (
 	CreatorTool         = "trivy"
 )
 
+// checksumAlgorithms maps the algorithms of the package digests to the SPDX ones
+var checksumAlgorithms = map[string]spdx.ChecksumAlgorithm{
+	"md5":    spdx.MD5,
+	"sha1":   spdx.SHA1,
+	"sha256": spdx.SHA256,
+}
+
 type Writer struct {
 	output  io.Writer
 	version string
@@ -39,6 +48,9 @@ type options struct {
 	clock      clock.Clock
 	newUUID    newUUID
 	spdxFormat string
+
+	// hashAlgorithms selects the checksums of the packages, and all the checksums are written if empty
+	hashAlgorithms []string
 }
 
 type option func(*options)
@@ -57,6 +69,14 @@ func WithNewUUID(newUUID newUUID) option {
 	}
 }
 
+// WithHashAlgorithms writes only the checksums of the packages in the algorithms, e.g. "sha256".
+// SHA-512 is not written, since the SPDX 2.2 documents are saved and loaded without it.
+func WithHashAlgorithms(algorithms []string) option {
+	return func(opts *options) {
+		opts.hashAlgorithms = algorithms
+	}
+}
+
 func NewWriter(output io.Writer, version string, spdxFormat string, opts ...option) Writer {
 	o := &options{
 		format:     spdx.Document2_1{},
@@ -100,7 +120,7 @@ func (cw *Writer) convertToBom(r types.Report, version string) (*spdx.Document2_
 
 	for _, result := range r.Results {
 		for _, pkg := range result.Packages {
-			spdxPackage, err := pkgToSpdxPackage(result.Type, r.Metadata, pkg)
+			spdxPackage, err := pkgToSpdxPackage(result.Type, r.Metadata, pkg, cw.hashAlgorithms)
 			if err != nil {
 				return nil, xerrors.Errorf("failed to parse pkg: %w", err)
 			}
@@ -108,6 +128,30 @@ func (cw *Writer) convertToBom(r types.Report, version string) (*spdx.Document2_
 		}
 	}
 
+	// The hashed targets, e.g. Go binaries, are written as the files with the checksums
+	var files map[spdx.ElementID]*spdx.File2_2
+	for _, result := range r.Results {
+		checksums := spdxChecksums(result.Checksums(cw.hashAlgorithms))
+		if checksums == nil {
+			continue
+		}
+		fileID, err := getFileID(result.Target)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to get %s file ID: %w", result.Target, err)
+		}
+		if files == nil {
+			files = make(map[spdx.ElementID]*spdx.File2_2)
+		}
+		files[spdx.ElementID(fileID)] = &spdx.File2_2{
+			FileName:           result.Target,
+			FileSPDXIdentifier: spdx.ElementID(fileID),
+			FileChecksums:      checksums,
+			LicenseConcluded:   "NOASSERTION",
+			LicenseInfoInFile:  []string{"NOASSERTION"},
+			FileCopyrightText:  "NOASSERTION",
+		}
+	}
+
 	return &spdx.Document2_2{
 		CreationInfo: &spdx.CreationInfo2_2{
 			SPDXVersion:          SPDXVersion,
@@ -119,15 +163,16 @@ func (cw *Writer) convertToBom(r types.Report, version string) (*spdx.Document2_
 			CreatorTools:         []string{CreatorTool},
 			Created:              cw.clock.Now().UTC().Format(time.RFC3339Nano),
 		},
-		Packages: packages,
+		Packages:        packages,
+		UnpackagedFiles: files,
 	}, nil
 }
 
-func pkgToSpdxPackage(t string, meta types.Metadata, pkg ftypes.Package) (spdx.Package2_2, error) {
+func pkgToSpdxPackage(t string, meta types.Metadata, pkg types.Package, hashAlgorithms []string) (spdx.Package2_2, error) {
 	var spdxPackage spdx.Package2_2
-	license := getLicense(pkg)
+	license := getLicense(pkg.Package)
 
-	pkgID, err := getPackageID(pkg)
+	pkgID, err := getPackageID(pkg.Package)
 	if err != nil {
 		return spdx.Package2_2{}, xerrors.Errorf("failed to get %s package ID: %w", pkg.Name, err)
 	}
@@ -142,9 +187,30 @@ func pkgToSpdxPackage(t string, meta types.Metadata, pkg ftypes.Package) (spdx.P
 	// The Concluded License field is the license the SPDX file creator believes governs the package
 	spdxPackage.PackageLicenseDeclared = license
 
+	spdxPackage.PackageFileName = pkg.FilePath
+	if pkg.SrcName != "" {
+		spdxPackage.PackageSourceInfo = fmt.Sprintf("built package from: %s %s", pkg.SrcName, utils.FormatSrcVersion(pkg.Package))
+	}
+	spdxPackage.PackageChecksums = spdxChecksums(pkg.Checksums(hashAlgorithms))
+
 	return spdxPackage, nil
 }
 
+// spdxChecksums returns the checksums in the algorithms supported by SPDX, or nil if there is none
+func spdxChecksums(checksums []string) map[spdx.ChecksumAlgorithm]spdx.Checksum {
+	var spdxChecksums map[spdx.ChecksumAlgorithm]spdx.Checksum
+	for _, checksum := range checksums {
+		alg, value, _ := strings.Cut(checksum, ":")
+		if checksumAlg, ok := checksumAlgorithms[alg]; ok {
+			if spdxChecksums == nil {
+				spdxChecksums = map[spdx.ChecksumAlgorithm]spdx.Checksum{}
+			}
+			spdxChecksums[checksumAlg] = spdx.Checksum{Algorithm: checksumAlg, Value: value}
+		}
+	}
+	return spdxChecksums
+}
+
 func getLicense(p ftypes.Package) string {
 	if p.License == "" {
 		return "NONE"
@@ -168,3 +234,12 @@ func getPackageID(p ftypes.Package) (string, error) {
 
 	return fmt.Sprintf("%x", f), nil
 }
+
+func getFileID(filePath string) (string, error) {
+	f, err := hashstructure.Hash(filePath, hashstructure.FormatV2, nil)
+	if err != nil {
+		return "", xerrors.Errorf("could not build file ID for file=%s: %+v", filePath, err)
+	}
+
+	return fmt.Sprintf("%x", f), nil
+}
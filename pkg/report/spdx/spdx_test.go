@@ -22,9 +22,10 @@ import (
 
 func TestWriter_Write(t *testing.T) {
 	testCases := []struct {
-		name        string
-		inputReport types.Report
-		wantSBOM    *spdx.Document2_2
+		name           string
+		inputReport    types.Report
+		hashAlgorithms []string
+		wantSBOM       *spdx.Document2_2
 	}{
 		{
 			name: "happy path for container scan",
@@ -52,19 +53,21 @@ func TestWriter_Write(t *testing.T) {
 						Target: "rails:latest (centos 8.3.2011)",
 						Class:  types.ClassOSPkg,
 						Type:   fos.CentOS,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:            "binutils",
-								Version:         "2.30",
-								Release:         "93.el8",
-								Epoch:           0,
-								Arch:            "aarch64",
-								SrcName:         "binutils",
-								SrcVersion:      "2.30",
-								SrcRelease:      "93.el8",
-								SrcEpoch:        0,
-								Modularitylabel: "",
-								License:         "GPLv3+",
+								Package: ftypes.Package{
+									Name:            "binutils",
+									Version:         "2.30",
+									Release:         "93.el8",
+									Epoch:           0,
+									Arch:            "aarch64",
+									SrcName:         "binutils",
+									SrcVersion:      "2.30",
+									SrcRelease:      "93.el8",
+									SrcEpoch:        0,
+									Modularitylabel: "",
+									License:         "GPLv3+",
+								},
 							},
 						},
 					},
@@ -72,14 +75,18 @@ func TestWriter_Write(t *testing.T) {
 						Target: "app/subproject/Gemfile.lock",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.Bundler,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actionpack",
-								Version: "7.0.0",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.0",
+								},
 							},
 							{
-								Name:    "actioncontroller",
-								Version: "7.0.0",
+								Package: ftypes.Package{
+									Name:    "actioncontroller",
+									Version: "7.0.0",
+								},
 							},
 						},
 					},
@@ -87,10 +94,12 @@ func TestWriter_Write(t *testing.T) {
 						Target: "app/Gemfile.lock",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.Bundler,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actionpack",
-								Version: "7.0.0",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.0",
+								},
 							},
 						},
 					},
@@ -131,6 +140,7 @@ func TestWriter_Write(t *testing.T) {
 						PackageVersion:            "2.30",
 						PackageLicenseConcluded:   "GPLv3+",
 						PackageLicenseDeclared:    "GPLv3+",
+						PackageSourceInfo:         "built package from: binutils 2.30-93.el8",
 						IsFilesAnalyzedTagPresent: true,
 					},
 				},
@@ -166,19 +176,22 @@ func TestWriter_Write(t *testing.T) {
 						Target: "centos:latest (centos 8.3.2011)",
 						Class:  types.ClassOSPkg,
 						Type:   fos.CentOS,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:            "acl",
-								Version:         "2.2.53",
-								Release:         "1.el8",
-								Epoch:           1,
-								Arch:            "aarch64",
-								SrcName:         "acl",
-								SrcVersion:      "2.2.53",
-								SrcRelease:      "1.el8",
-								SrcEpoch:        1,
-								Modularitylabel: "",
-								License:         "GPLv2+",
+								Package: ftypes.Package{
+									Name:            "acl",
+									Version:         "2.2.53",
+									Release:         "1.el8",
+									Epoch:           1,
+									Arch:            "aarch64",
+									SrcName:         "acl",
+									SrcVersion:      "2.2.53",
+									SrcRelease:      "1.el8",
+									SrcEpoch:        1,
+									Modularitylabel: "",
+									License:         "GPLv2+",
+								},
+								Digest: "md5:483bd5d0d380e8ba6a8dd8f2e4fd8c4a",
 							},
 						},
 					},
@@ -186,22 +199,26 @@ func TestWriter_Write(t *testing.T) {
 						Target: "Ruby",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.GemSpec,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actionpack",
-								Version: "7.0.0",
-								Layer: ftypes.Layer{
-									DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.0",
+									Layer: ftypes.Layer{
+										DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+									},
+									FilePath: "tools/project-john/specifications/actionpack.gemspec",
 								},
-								FilePath: "tools/project-john/specifications/actionpack.gemspec",
 							},
 							{
-								Name:    "actionpack",
-								Version: "7.0.1",
-								Layer: ftypes.Layer{
-									DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+								Package: ftypes.Package{
+									Name:    "actionpack",
+									Version: "7.0.1",
+									Layer: ftypes.Layer{
+										DiffID: "sha256:ccb64cf0b7ba2e50741d0b64cae324eb5de3b1e2f580bbf177e721b67df38488",
+									},
+									FilePath: "tools/project-doe/specifications/actionpack.gemspec",
 								},
-								FilePath: "tools/project-doe/specifications/actionpack.gemspec",
 							},
 						},
 					},
@@ -221,17 +238,22 @@ func TestWriter_Write(t *testing.T) {
 				},
 				Packages: map[spdx.ElementID]*spdx.Package2_2{
 					spdx.ElementID("40d016db96700ecb"): {
-						PackageSPDXIdentifier:     spdx.ElementID("40d016db96700ecb"),
-						PackageName:               "acl",
-						PackageVersion:            "2.2.53",
-						PackageLicenseConcluded:   "GPLv2+",
-						PackageLicenseDeclared:    "GPLv2+",
+						PackageSPDXIdentifier:   spdx.ElementID("40d016db96700ecb"),
+						PackageName:             "acl",
+						PackageVersion:          "2.2.53",
+						PackageLicenseConcluded: "GPLv2+",
+						PackageLicenseDeclared:  "GPLv2+",
+						PackageSourceInfo:       "built package from: acl 1:2.2.53-1.el8",
+						PackageChecksums: map[spdx.ChecksumAlgorithm]spdx.Checksum{
+							spdx.MD5: {Algorithm: spdx.MD5, Value: "483bd5d0d380e8ba6a8dd8f2e4fd8c4a"},
+						},
 						IsFilesAnalyzedTagPresent: true,
 					},
 					spdx.ElementID("ff543ca421929db5"): {
 						PackageSPDXIdentifier:     spdx.ElementID("ff543ca421929db5"),
 						PackageName:               "actionpack",
 						PackageVersion:            "7.0.0",
+						PackageFileName:           "tools/project-john/specifications/actionpack.gemspec",
 						PackageLicenseConcluded:   "NONE",
 						PackageLicenseDeclared:    "NONE",
 						IsFilesAnalyzedTagPresent: true,
@@ -240,6 +262,7 @@ func TestWriter_Write(t *testing.T) {
 						PackageSPDXIdentifier:     spdx.ElementID("639cce3bbd87450f"),
 						PackageName:               "actionpack",
 						PackageVersion:            "7.0.1",
+						PackageFileName:           "tools/project-doe/specifications/actionpack.gemspec",
 						PackageLicenseConcluded:   "NONE",
 						PackageLicenseDeclared:    "NONE",
 						IsFilesAnalyzedTagPresent: true,
@@ -263,10 +286,12 @@ func TestWriter_Write(t *testing.T) {
 						Target: "Gemfile.lock",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.Bundler,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "actioncable",
-								Version: "6.1.4.1",
+								Package: ftypes.Package{
+									Name:    "actioncable",
+									Version: "6.1.4.1",
+								},
 							},
 						},
 					},
@@ -296,6 +321,64 @@ func TestWriter_Write(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "happy path with hashes",
+			inputReport: types.Report{
+				SchemaVersion: report.SchemaVersion,
+				ArtifactName:  "dist",
+				ArtifactType:  ftypes.ArtifactFilesystem,
+				Results: types.Results{
+					{
+						Target: "Python",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.PythonPkg,
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:     "requests",
+									Version:  "2.28.1",
+									FilePath: "dist/requests-2.28.1-py3-none-any.whl/requests-2.28.1.dist-info/METADATA",
+								},
+								Hashes: []string{
+									"md5:10893fd7cff66dca193f658ca9438d15",
+									"sha256:7c5599b102feddaa661c826c56ab4fee28bfd17f5abca1ebbe3e7f19d7c97983",
+									"sha512:421785ff9c6212492d19d5f9e213197f130daaf721051b4f5b208dca8f86c94a4b56d3e232fc0b191d06f18e5a96be4691a295417e82d3a3f0d83c1f5846d1b8",
+								},
+							},
+						},
+					},
+				},
+			},
+			hashAlgorithms: []string{"sha256", "sha512"},
+			wantSBOM: &spdx.Document2_2{
+				CreationInfo: &spdx.CreationInfo2_2{
+					SPDXVersion:                "SPDX-2.2",
+					DataLicense:                "CC0-1.0",
+					SPDXIdentifier:             "DOCUMENT",
+					DocumentName:               "dist",
+					DocumentNamespace:          "http://aquasecurity.github.io/trivy/filesystem/dist-3ff14136-e09f-4df9-80ea-000000000001",
+					CreatorOrganizations:       []string{"aquasecurity"},
+					CreatorTools:               []string{"trivy"},
+					Created:                    "2021-08-25T12:20:30.000000005Z",
+					ExternalDocumentReferences: map[string]spdx.ExternalDocumentRef2_2{},
+				},
+				Packages: map[spdx.ElementID]*spdx.Package2_2{
+					// SHA-512 is not supported in SPDX 2.2 documents
+					spdx.ElementID("f530c3fdb2b29f3e"): {
+						PackageSPDXIdentifier: spdx.ElementID("f530c3fdb2b29f3e"),
+						PackageName:           "requests",
+						PackageVersion:        "2.28.1",
+						PackageFileName:       "dist/requests-2.28.1-py3-none-any.whl/requests-2.28.1.dist-info/METADATA",
+						PackageChecksums: map[spdx.ChecksumAlgorithm]spdx.Checksum{
+							spdx.SHA256: {Algorithm: spdx.SHA256, Value: "7c5599b102feddaa661c826c56ab4fee28bfd17f5abca1ebbe3e7f19d7c97983"},
+						},
+						PackageLicenseConcluded:   "NONE",
+						PackageLicenseDeclared:    "NONE",
+						IsFilesAnalyzedTagPresent: true,
+					},
+				},
+			},
+		},
 		{
 			name: "happy path aggregate results",
 			inputReport: types.Report{
@@ -307,15 +390,17 @@ func TestWriter_Write(t *testing.T) {
 						Target: "Node.js",
 						Class:  types.ClassLangPkg,
 						Type:   ftypes.NodePkg,
-						Packages: []ftypes.Package{
+						Packages: []types.Package{
 							{
-								Name:    "ruby-typeprof",
-								Version: "0.20.1",
-								License: "MIT",
-								Layer: ftypes.Layer{
-									DiffID: "sha256:661c3fd3cc16b34c070f3620ca6b03b6adac150f9a7e5d0e3c707a159990f88e",
+								Package: ftypes.Package{
+									Name:    "ruby-typeprof",
+									Version: "0.20.1",
+									License: "MIT",
+									Layer: ftypes.Layer{
+										DiffID: "sha256:661c3fd3cc16b34c070f3620ca6b03b6adac150f9a7e5d0e3c707a159990f88e",
+									},
+									FilePath: "usr/local/lib/ruby/gems/3.1.0/gems/typeprof-0.21.1/vscode/package.json",
 								},
-								FilePath: "usr/local/lib/ruby/gems/3.1.0/gems/typeprof-0.21.1/vscode/package.json",
 							},
 						},
 					},
@@ -338,6 +423,7 @@ func TestWriter_Write(t *testing.T) {
 						PackageSPDXIdentifier:     spdx.ElementID("1275fe237f4887b3"),
 						PackageName:               "ruby-typeprof",
 						PackageVersion:            "0.20.1",
+						PackageFileName:           "usr/local/lib/ruby/gems/3.1.0/gems/typeprof-0.21.1/vscode/package.json",
 						PackageLicenseConcluded:   "MIT",
 						PackageLicenseDeclared:    "MIT",
 						IsFilesAnalyzedTagPresent: true,
@@ -345,6 +431,72 @@ func TestWriter_Write(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "happy path with the hashes of a binary",
+			inputReport: types.Report{
+				SchemaVersion: report.SchemaVersion,
+				ArtifactName:  "app",
+				ArtifactType:  ftypes.ArtifactFilesystem,
+				Results: types.Results{
+					{
+						Target: "usr/local/bin/app",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.GoBinary,
+						Packages: []types.Package{
+							{
+								Package: ftypes.Package{
+									Name:    "golang.org/x/text",
+									Version: "v0.3.7",
+								},
+							},
+						},
+						Hashes: []string{
+							"sha1:ea1b37f38c327596b216542bc636cfdc0b8036fa",
+							"sha256:1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+						},
+					},
+				},
+			},
+			hashAlgorithms: []string{"sha256"},
+			wantSBOM: &spdx.Document2_2{
+				CreationInfo: &spdx.CreationInfo2_2{
+					SPDXVersion:                "SPDX-2.2",
+					DataLicense:                "CC0-1.0",
+					SPDXIdentifier:             "DOCUMENT",
+					DocumentName:               "app",
+					DocumentNamespace:          "http://aquasecurity.github.io/trivy/filesystem/app-3ff14136-e09f-4df9-80ea-000000000001",
+					CreatorOrganizations:       []string{"aquasecurity"},
+					CreatorTools:               []string{"trivy"},
+					Created:                    "2021-08-25T12:20:30.000000005Z",
+					ExternalDocumentReferences: map[string]spdx.ExternalDocumentRef2_2{},
+				},
+				Packages: map[spdx.ElementID]*spdx.Package2_2{
+					spdx.ElementID("6b3ff23f918cd5a1"): {
+						PackageSPDXIdentifier:     spdx.ElementID("6b3ff23f918cd5a1"),
+						PackageName:               "golang.org/x/text",
+						PackageVersion:            "v0.3.7",
+						PackageLicenseConcluded:   "NONE",
+						PackageLicenseDeclared:    "NONE",
+						IsFilesAnalyzedTagPresent: true,
+					},
+				},
+				UnpackagedFiles: map[spdx.ElementID]*spdx.File2_2{
+					spdx.ElementID("ed250ed87181d6a7"): {
+						FileName:           "usr/local/bin/app",
+						FileSPDXIdentifier: spdx.ElementID("ed250ed87181d6a7"),
+						FileChecksums: map[spdx.ChecksumAlgorithm]spdx.Checksum{
+							spdx.SHA256: {
+								Algorithm: spdx.SHA256,
+								Value:     "1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+							},
+						},
+						LicenseConcluded:  "NOASSERTION",
+						LicenseInfoInFile: []string{"NOASSERTION"},
+						FileCopyrightText: "NOASSERTION",
+					},
+				},
+			},
+		},
 		{
 			name: "happy path empty",
 			inputReport: types.Report{
@@ -381,7 +533,8 @@ func TestWriter_Write(t *testing.T) {
 			}
 
 			output := bytes.NewBuffer(nil)
-			writer := reportSpdx.NewWriter(output, "dev", "spdx-json", reportSpdx.WithClock(clock), reportSpdx.WithNewUUID(newUUID))
+			writer := reportSpdx.NewWriter(output, "dev", "spdx-json", reportSpdx.WithClock(clock), reportSpdx.WithNewUUID(newUUID),
+				reportSpdx.WithHashAlgorithms(tc.hashAlgorithms))
 
 			err := writer.Write(tc.inputReport)
 			require.NoError(t, err)
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/table"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const (
+	// AllReport writes all the findings, and SummaryReport writes only the number of the findings per severity of
+	// each target, e.g. for dashboards and PR comments
+	AllReport     = "all"
+	SummaryReport = "summary"
+)
+
+// SummaryReportOutput is the JSON report with the number of the findings instead of the findings
+type SummaryReportOutput struct {
+	SchemaVersion int                 `json:",omitempty"`
+	ArtifactName  string              `json:",omitempty"`
+	ArtifactType  ftypes.ArtifactType `json:",omitempty"`
+	Metadata      types.Metadata      `json:",omitempty"`
+	Results       []ResultSummary     `json:",omitempty"`
+}
+
+// ResultSummary holds the number of the findings per severity of the target
+type ResultSummary struct {
+	Target            string
+	Class             types.ResultClass `json:",omitempty"`
+	Type              string            `json:",omitempty"`
+	Vulnerabilities   map[string]int    `json:",omitempty"`
+	Misconfigurations map[string]int    `json:",omitempty"` // only the failures
+	Secrets           map[string]int    `json:",omitempty"`
+	Licenses          map[string]int    `json:",omitempty"`
+	NotScanned        string            `json:",omitempty"`
+}
+
+// Summarize counts the findings of each result per severity
+func Summarize(report types.Report) SummaryReportOutput {
+	output := SummaryReportOutput{
+		SchemaVersion: report.SchemaVersion,
+		ArtifactName:  report.ArtifactName,
+		ArtifactType:  report.ArtifactType,
+		Metadata:      report.Metadata,
+	}
+	for _, result := range report.Results {
+		// Custom resources are not findings
+		if result.Class == types.ClassCustom {
+			continue
+		}
+		summary := ResultSummary{
+			Target:     result.Target,
+			Class:      result.Class,
+			Type:       result.Type,
+			NotScanned: result.NotScanned,
+		}
+		for _, vuln := range result.Vulnerabilities {
+			summary.Vulnerabilities = increment(summary.Vulnerabilities, vuln.Severity)
+		}
+		for _, misconf := range result.Misconfigurations {
+			if misconf.Status == types.StatusFailure {
+				summary.Misconfigurations = increment(summary.Misconfigurations, misconf.Severity)
+			}
+		}
+		for _, secret := range result.Secrets {
+			summary.Secrets = increment(summary.Secrets, secret.Severity)
+		}
+		for _, license := range result.Licenses {
+			summary.Licenses = increment(summary.Licenses, license.Severity)
+		}
+		output.Results = append(output.Results, summary)
+	}
+	return output
+}
+
+func increment(counts map[string]int, severity string) map[string]int {
+	if counts == nil {
+		counts = map[string]int{}
+	}
+	counts[severity]++
+	return counts
+}
+
+// summaryKinds is the number of the kinds of the findings in the columns of the summary table
+const summaryKinds = 4
+
+// SummaryWriter writes a row per target with the number of the findings per severity
+type SummaryWriter struct {
+	Output     io.Writer
+	Severities []dbTypes.Severity
+}
+
+// Write writes the summary of the results in the table format
+func (sw SummaryWriter) Write(report types.Report) error {
+	// The columns of each kind of the findings are ordered from CRITICAL to UNKNOWN
+	var severities, headings []string
+	for i := len(dbTypes.SeverityNames) - 1; i >= 0; i-- {
+		if slices.Contains(sw.Severities, dbTypes.Severity(i)) {
+			severity := dbTypes.SeverityNames[i]
+			severities = append(severities, severity)
+			headings = append(headings, severity[:1])
+		}
+	}
+
+	t := table.New(sw.Output)
+	t.SetRowLines(false)
+	t.SetHeaders("Target", "Type", "Vulnerabilities", "Misconfigurations", "Secrets", "Licenses")
+	t.AddHeaders(append([]string{"Target", "Type"}, repeat(headings, summaryKinds)...)...)
+	t.SetAutoMergeHeaders(true)
+	t.SetHeaderColSpans(0, 1, 1, len(severities), len(severities), len(severities), len(severities))
+	alignment := []table.Alignment{table.AlignLeft, table.AlignLeft}
+	for i := 0; i < len(severities)*summaryKinds; i++ {
+		alignment = append(alignment, table.AlignCenter)
+	}
+	t.SetAlignment(alignment...)
+
+	for _, result := range Summarize(report).Results {
+		target := result.Target
+		if result.NotScanned != "" {
+			target += " (not scanned)"
+		}
+		resultType := result.Type
+		if resultType == "" {
+			resultType = string(result.Class)
+		}
+
+		row := []string{target, resultType}
+		for _, counts := range []map[string]int{result.Vulnerabilities, result.Misconfigurations, result.Secrets,
+			result.Licenses} {
+			row = append(row, summaryCells(counts, severities)...)
+		}
+		t.AddRow(row...)
+	}
+	t.Render()
+	return nil
+}
+
+// summaryCells leaves the cells of zero blank, in the same way as the summary of 'trivy k8s'
+func summaryCells(counts map[string]int, severities []string) []string {
+	var cells []string
+	for _, severity := range severities {
+		if count, ok := counts[severity]; ok {
+			cells = append(cells, ColorizeSeverity(strconv.Itoa(count), severity))
+		} else {
+			cells = append(cells, " ")
+		}
+	}
+	return cells
+}
+
+func repeat(s []string, n int) []string {
+	var result []string
+	for i := 0; i < n; i++ {
+		result = append(result, s...)
+	}
+	return result
+}
+
+// SummaryJSONWriter writes the number of the findings per severity of each target in JSON
+type SummaryJSONWriter struct {
+	Output io.Writer
+}
+
+// Write writes the summary of the results in JSON format
+func (jw SummaryJSONWriter) Write(report types.Report) error {
+	output, err := json.MarshalIndent(Summarize(report), "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal json: %w", err)
+	}
+
+	if _, err = fmt.Fprintln(jw.Output, string(output)); err != nil {
+		return xerrors.Errorf("failed to write json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/report"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+var summaryTestReport = types.Report{
+	SchemaVersion: 2,
+	ArtifactName:  "alpine:3.15",
+	ArtifactType:  ftypes.ArtifactContainerImage,
+	Results: types.Results{
+		{
+			Target: "alpine:3.15 (alpine 3.15.4)",
+			Class:  types.ClassOSPkg,
+			Type:   "alpine",
+			Vulnerabilities: []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2022-0778", Vulnerability: dbTypes.Vulnerability{Severity: "HIGH"}},
+				{VulnerabilityID: "CVE-2022-1304", Vulnerability: dbTypes.Vulnerability{Severity: "HIGH"}},
+				{VulnerabilityID: "CVE-2022-2097", Vulnerability: dbTypes.Vulnerability{Severity: "MEDIUM"}},
+			},
+		},
+		{
+			Target: "Dockerfile",
+			Class:  types.ClassConfig,
+			Type:   "dockerfile",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				{ID: "DS002", Severity: "HIGH", Status: types.StatusFailure},
+				{ID: "DS026", Severity: "LOW", Status: types.StatusPassed},
+			},
+		},
+		{
+			Target: "/app/.env",
+			Class:  types.ClassSecret,
+			Secrets: []ftypes.SecretFinding{
+				{RuleID: "aws-access-key-id", Severity: "CRITICAL"},
+			},
+		},
+		{
+			Target:          "Skipped files",
+			Class:           types.ClassCustom,
+			CustomResources: []ftypes.CustomResource{{Type: types.SkippedFileType}},
+		},
+	},
+}
+
+func TestSummaryWriter_Write(t *testing.T) {
+	want := `┌─────────────────────────────┬────────────┬───────────────────┬───────────────────┬───────────────────┬───────────────────┐
+│           Target            │    Type    │  Vulnerabilities  │ Misconfigurations │      Secrets      │     Licenses      │
+│                             │            ├───┬───┬───┬───┬───┼───┬───┬───┬───┬───┼───┬───┬───┬───┬───┼───┬───┬───┬───┬───┤
+│                             │            │ C │ H │ M │ L │ U │ C │ H │ M │ L │ U │ C │ H │ M │ L │ U │ C │ H │ M │ L │ U │
+├─────────────────────────────┼────────────┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┼───┤
+│ alpine:3.15 (alpine 3.15.4) │ alpine     │   │ 2 │ 1 │   │   │   │   │   │   │   │   │   │   │   │   │   │   │   │   │   │
+│ Dockerfile                  │ dockerfile │   │   │   │   │   │   │ 1 │   │   │   │   │   │   │   │   │   │   │   │   │   │
+│ /app/.env                   │ secret     │   │   │   │   │   │   │   │   │   │   │ 1 │   │   │   │   │   │   │   │   │   │
+└─────────────────────────────┴────────────┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┴───┘
+`
+	var buf bytes.Buffer
+	err := report.Write(summaryTestReport, report.Option{
+		Format:     "table",
+		Report:     report.SummaryReport,
+		Output:     &buf,
+		Severities: []dbTypes.Severity{dbTypes.SeverityUnknown, dbTypes.SeverityLow, dbTypes.SeverityMedium, dbTypes.SeverityHigh, dbTypes.SeverityCritical},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, buf.String())
+}
+
+func TestSummaryJSONWriter_Write(t *testing.T) {
+	want := `{
+  "SchemaVersion": 2,
+  "ArtifactName": "alpine:3.15",
+  "ArtifactType": "container_image",
+  "Metadata": {
+    "ImageConfig": {
+      "architecture": "",
+      "created": "0001-01-01T00:00:00Z",
+      "os": "",
+      "rootfs": {
+        "type": "",
+        "diff_ids": null
+      },
+      "config": {}
+    }
+  },
+  "Results": [
+    {
+      "Target": "alpine:3.15 (alpine 3.15.4)",
+      "Class": "os-pkgs",
+      "Type": "alpine",
+      "Vulnerabilities": {
+        "HIGH": 2,
+        "MEDIUM": 1
+      }
+    },
+    {
+      "Target": "Dockerfile",
+      "Class": "config",
+      "Type": "dockerfile",
+      "Misconfigurations": {
+        "HIGH": 1
+      }
+    },
+    {
+      "Target": "/app/.env",
+      "Class": "secret",
+      "Secrets": {
+        "CRITICAL": 1
+      }
+    }
+  ]
+}
+`
+	var buf bytes.Buffer
+	err := report.Write(summaryTestReport, report.Option{
+		Format: "json",
+		Report: report.SummaryReport,
+		Output: &buf,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, buf.String())
+}
@@ -29,6 +29,10 @@ var (
 	}
 )
 
+// GroupByVulnerability shows a row per vulnerability and fixed version with the affected packages listed in it,
+// instead of a row per package, e.g. for a CVE affecting all the subpackages of binutils
+const GroupByVulnerability = "vulnerability"
+
 // TableWriter implements Writer and output in tabular form
 type TableWriter struct {
 	Severities []dbTypes.Severity
@@ -37,6 +41,9 @@ type TableWriter struct {
 	// We have to show a message once about using the '-format json' subcommand to get the full pkgPath
 	ShowMessageOnce *sync.Once
 
+	// GroupBy collapses the identical findings into a row, e.g. GroupByVulnerability
+	GroupBy string
+
 	// For misconfigurations
 	IncludeNonFailures bool
 	Trace              bool
@@ -62,6 +69,10 @@ func (tw TableWriter) isOutputToTerminal() bool {
 }
 
 func (tw TableWriter) write(result types.Result) {
+	// Custom resources are only available in JSON and templates
+	if result.Class == types.ClassCustom {
+		return
+	}
 
 	tableWriter := table.New(tw.Output)
 	if tw.isOutputToTerminal() { // use ansi output if we're not piping elsewhere
@@ -75,43 +86,59 @@ func (tw TableWriter) write(result types.Result) {
 	severityCount := tw.countSeverities(result)
 
 	switch {
+	case len(result.Vulnerabilities) > 0 && tw.GroupBy == GroupByVulnerability:
+		tw.writeGroupedVulnerabilities(tableWriter, result.Vulnerabilities)
 	case len(result.Vulnerabilities) > 0:
 		tw.writeVulnerabilities(tableWriter, result.Vulnerabilities)
 	case len(result.Secrets) > 0:
 		tw.writeSecrets(tableWriter, result.Secrets)
+	case len(result.Licenses) > 0:
+		tw.writeLicenses(tableWriter, result.Licenses)
 	}
 
 	total, summaries := tw.summary(severityCount)
 
 	target := result.Target
-	if result.Class == types.ClassSecret {
+	if result.NotScanned != "" {
+		target += " (not scanned)"
+	} else if result.Class == types.ClassSecret {
 		if len(result.Secrets) == 0 {
 			return
 		}
 		target += " (secrets)"
+	} else if result.Class == types.ClassLicense {
+		if len(result.Licenses) == 0 {
+			return
+		}
+		target += " (license)"
 	} else if result.Class != types.ClassOSPkg {
 		target += fmt.Sprintf(" (%s)", result.Type)
 	}
 
 	if tw.isOutputToTerminal() {
 		// nolint
-		_ = tml.Printf("\n<underline><bold>%s</bold></underline>\n\n", target)
+		_ = tml.Fprintf(tw.Output, "\n<underline><bold>%s</bold></underline>\n\n", target)
 	} else {
-		fmt.Printf("\n%s\n", target)
-		fmt.Println(strings.Repeat("=", len(target)))
+		tw.Printf("\n%s\n", target)
+		tw.Println(strings.Repeat("=", len(target)))
+	}
+	if result.NotScanned != "" {
+		tw.Printf("Reason: %s\n\n", result.NotScanned)
+		return
 	}
 	if result.Class == types.ClassConfig {
 		// for misconfigurations
 		summary := result.MisconfSummary
-		fmt.Printf("Tests: %d (SUCCESSES: %d, FAILURES: %d, EXCEPTIONS: %d)\n",
+		tw.Printf("Tests: %d (SUCCESSES: %d, FAILURES: %d, EXCEPTIONS: %d)\n",
 			summary.Successes+summary.Failures+summary.Exceptions, summary.Successes, summary.Failures, summary.Exceptions)
-		fmt.Printf("Failures: %d (%s)\n\n", total, strings.Join(summaries, ", "))
+		tw.Printf("Failures: %d (%s)\n\n", total, strings.Join(summaries, ", "))
 	} else {
-		// for vulnerabilities and secrets
-		fmt.Printf("Total: %d (%s)\n\n", total, strings.Join(summaries, ", "))
+		// for vulnerabilities, secrets and licenses
+		tw.Printf("Total: %d (%s)\n\n", total, strings.Join(summaries, ", "))
 	}
 
 	tableWriter.Render()
+	tw.writeDependencyPaths(result.Vulnerabilities)
 
 	if len(result.Misconfigurations) > 0 {
 		_, _ = fmt.Fprint(tw.Output, NewMisconfigRenderer(result.Target, result.Misconfigurations, tw.IncludeNonFailures, tw.isOutputToTerminal()).Render())
@@ -148,50 +175,220 @@ func (tw TableWriter) summary(severityCount map[string]int) (int, []string) {
 
 func (tw TableWriter) writeVulnerabilities(tableWriter *table.Table, vulns []types.DetectedVulnerability) {
 	header := []string{"Library", "Vulnerability", "Severity", "Installed Version", "Fixed Version", "Title"}
+
+	// The due dates are shown only when the SLA is configured
+	showDueDate := slices.IndexFunc(vulns, func(v types.DetectedVulnerability) bool { return v.SLA != nil }) >= 0
+	if showDueDate {
+		header = append(header, "Due Date")
+	}
+	// The layers are shown only for container images with the history
+	showLayer := slices.IndexFunc(vulns, func(v types.DetectedVulnerability) bool { return v.LayerCreatedBy != "" }) >= 0
+	if showLayer {
+		header = append(header, "Layer")
+	}
 	tableWriter.SetHeaders(header...)
-	tw.setVulnerabilityRows(tableWriter, vulns)
+	tw.setVulnerabilityRows(tableWriter, vulns, showDueDate, showLayer)
 }
 
-func (tw TableWriter) setVulnerabilityRows(tableWriter *table.Table, vulns []types.DetectedVulnerability) {
-	for _, v := range vulns {
-		lib := v.PkgName
-		if v.PkgPath != "" {
-			fileName := filepath.Base(v.PkgPath)
-			lib = fmt.Sprintf("%s (%s)", v.PkgName, fileName)
-			tw.ShowMessageOnce.Do(func() {
-				log.Logger.Infof("Table result includes only package filenames. Use '--format json' option to get the full path to the package file.")
-			})
+// writeGroupedVulnerabilities writes a row per vulnerability and fixed version, which lists the affected packages.
+// The layers are not shown since the packages may be installed in different layers.
+func (tw TableWriter) writeGroupedVulnerabilities(tableWriter *table.Table, vulns []types.DetectedVulnerability) {
+	header := []string{"Vulnerability", "Severity", "Fixed Version", "Libraries", "Title"}
+	showDueDate := slices.IndexFunc(vulns, func(v types.DetectedVulnerability) bool { return v.SLA != nil }) >= 0
+	if showDueDate {
+		header = append(header, "Due Date")
+	}
+	tableWriter.SetHeaders(header...)
+
+	for _, group := range groupVulnerabilities(vulns) {
+		v := group[0]
+		var libs []string
+		for _, vuln := range group {
+			libs = append(libs, tw.library(vuln)+" "+vuln.InstalledVersion)
 		}
 
-		title := v.Title
-		if title == "" {
-			title = v.Description
+		severity := v.Severity
+		if tw.isOutputToTerminal() {
+			severity = ColorizeSeverity(v.Severity, v.Severity)
 		}
-		splitTitle := strings.Split(title, " ")
-		if len(splitTitle) >= 12 {
-			title = strings.Join(splitTitle[:12], " ") + "..."
+		row := []string{v.VulnerabilityID, severity, v.FixedVersion, strings.Join(libs, "\n"), tw.title(v)}
+		if showDueDate {
+			row = append(row, tw.dueDate(earliestSLA(group)))
 		}
+		tableWriter.AddRow(row...)
+	}
+}
 
-		if len(v.PrimaryURL) > 0 {
-			if tw.isOutputToTerminal() {
-				title = tml.Sprintf("%s\n<blue>%s</blue>", title, v.PrimaryURL)
-			} else {
-				title = fmt.Sprintf("%s\n%s", title, v.PrimaryURL)
-			}
+// groupVulnerabilities groups the vulnerabilities by the ID and the fixed version in the order of the first appearance
+func groupVulnerabilities(vulns []types.DetectedVulnerability) [][]types.DetectedVulnerability {
+	var groups [][]types.DetectedVulnerability
+	index := map[[2]string]int{}
+	for _, v := range vulns {
+		key := [2]string{v.VulnerabilityID, v.FixedVersion}
+		if i, ok := index[key]; ok {
+			groups[i] = append(groups[i], v)
+			continue
 		}
+		index[key] = len(groups)
+		groups = append(groups, []types.DetectedVulnerability{v})
+	}
+	return groups
+}
+
+// earliestSLA returns the SLA status of the package to be fixed first in the group
+func earliestSLA(vulns []types.DetectedVulnerability) *types.SLAStatus {
+	var sla *types.SLAStatus
+	for _, v := range vulns {
+		if v.SLA != nil && (sla == nil || v.SLA.DueDate.Before(sla.DueDate)) {
+			sla = v.SLA
+		}
+	}
+	return sla
+}
+
+func (tw TableWriter) setVulnerabilityRows(tableWriter *table.Table, vulns []types.DetectedVulnerability, showDueDate, showLayer bool) {
+	for _, v := range vulns {
+		lib := tw.library(v)
+		title := tw.title(v)
 
 		var row []string
 		if tw.isOutputToTerminal() {
 			row = []string{lib, v.VulnerabilityID, ColorizeSeverity(v.Severity, v.Severity),
-				v.InstalledVersion, v.FixedVersion, strings.TrimSpace(title)}
+				v.InstalledVersion, v.FixedVersion, title}
 		} else {
-			row = []string{lib, v.VulnerabilityID, v.Severity, v.InstalledVersion, v.FixedVersion, strings.TrimSpace(title)}
+			row = []string{lib, v.VulnerabilityID, v.Severity, v.InstalledVersion, v.FixedVersion, title}
+		}
+		if showDueDate {
+			row = append(row, tw.dueDate(v.SLA))
+		}
+		if showLayer {
+			row = append(row, layerInstruction(v))
 		}
 
 		tableWriter.AddRow(row...)
 	}
 }
 
+// library returns the name of the package with the file name of the package file if any
+func (tw TableWriter) library(v types.DetectedVulnerability) string {
+	if v.PkgPath == "" {
+		return v.PkgName
+	}
+	tw.ShowMessageOnce.Do(func() {
+		log.Logger.Infof("Table result includes only package filenames. Use '--format json' option to get the full path to the package file.")
+	})
+	return fmt.Sprintf("%s (%s)", v.PkgName, filepath.Base(v.PkgPath))
+}
+
+// title returns the title of the vulnerability shortened to 12 words, with the primary URL
+func (tw TableWriter) title(v types.DetectedVulnerability) string {
+	title := v.Title
+	if title == "" {
+		title = v.Description
+	}
+	splitTitle := strings.Split(title, " ")
+	if len(splitTitle) >= 12 {
+		title = strings.Join(splitTitle[:12], " ") + "..."
+	}
+
+	if len(v.PrimaryURL) > 0 {
+		if tw.isOutputToTerminal() {
+			title = tml.Sprintf("%s\n<blue>%s</blue>", title, v.PrimaryURL)
+		} else {
+			title = fmt.Sprintf("%s\n%s", title, v.PrimaryURL)
+		}
+	}
+	return strings.TrimSpace(title)
+}
+
+// writeDependencyPaths shows the direct dependencies pulling in the vulnerable packages, filled with --dependency-tree
+func (tw TableWriter) writeDependencyPaths(vulns []types.DetectedVulnerability) {
+	var pkgs []string
+	pkgVulns := map[string][]string{}
+	pkgPaths := map[string][][]string{}
+	for _, v := range vulns {
+		if len(v.DependencyPaths) == 0 {
+			continue
+		}
+		pkg := v.PkgName + "@" + v.InstalledVersion
+		if _, ok := pkgPaths[pkg]; !ok {
+			pkgs = append(pkgs, pkg)
+			pkgPaths[pkg] = v.DependencyPaths
+		}
+		pkgVulns[pkg] = append(pkgVulns[pkg], v.VulnerabilityID)
+	}
+	if len(pkgs) == 0 {
+		return
+	}
+
+	title := "Dependency Paths"
+	if tw.isOutputToTerminal() {
+		_ = tml.Fprintf(tw.Output, "\n<bold>%s</bold>\n", title)
+	} else {
+		tw.Println()
+		tw.Println(title)
+		tw.Println(strings.Repeat("=", len(title)))
+	}
+	for _, pkg := range pkgs {
+		tw.Println(fmt.Sprintf("%s (%s)", pkg, strings.Join(pkgVulns[pkg], ", ")))
+		for _, path := range pkgPaths[pkg] {
+			tw.Println("  " + strings.Join(path, " > "))
+		}
+	}
+	tw.Println()
+}
+
+// maxInstructionLength is the maximum length of the instructions creating the layers in the table
+const maxInstructionLength = 40
+
+// layerInstruction returns the Dockerfile instruction which created the layer of the vulnerable package and the layer digest, e.g.
+//
+//	RUN apk add curl
+//	sha256:a3ed95caeb02
+func layerInstruction(v types.DetectedVulnerability) string {
+	instruction := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v.LayerCreatedBy), "# buildkit"))
+	switch {
+	case strings.HasPrefix(instruction, "/bin/sh -c #(nop) "):
+		// Instructions other than RUN by "docker build"
+		instruction = strings.TrimPrefix(instruction, "/bin/sh -c #(nop) ")
+	case strings.HasPrefix(instruction, "/bin/sh -c "):
+		// RUN by "docker build"
+		instruction = "RUN " + strings.TrimPrefix(instruction, "/bin/sh -c ")
+	case strings.HasPrefix(instruction, "RUN /bin/sh -c "):
+		// RUN by BuildKit
+		instruction = "RUN " + strings.TrimPrefix(instruction, "RUN /bin/sh -c ")
+	}
+	instruction = strings.Join(strings.Fields(instruction), " ")
+	if len(instruction) > maxInstructionLength {
+		instruction = instruction[:maxInstructionLength-3] + "..."
+	}
+
+	digest := v.Layer.Digest
+	if digest == "" {
+		digest = v.Layer.DiffID
+	}
+	if algorithm, hex, ok := strings.Cut(digest, ":"); ok && len(hex) > 12 {
+		digest = algorithm + ":" + hex[:12]
+	}
+	if digest == "" {
+		return instruction
+	}
+	return instruction + "\n" + digest
+}
+
+func (tw TableWriter) dueDate(sla *types.SLAStatus) string {
+	if sla == nil {
+		return ""
+	}
+	dueDate := sla.DueDate.Format("2006-01-02")
+	if !sla.Overdue {
+		return dueDate
+	} else if tw.isOutputToTerminal() {
+		return tml.Sprintf("%s\n<red>overdue</red>", dueDate)
+	}
+	return dueDate + "\noverdue"
+}
+
 func (tw TableWriter) outputTrace(result types.Result) {
 	blue := color.New(color.FgBlue).SprintFunc()
 	green := color.New(color.FgGreen).SprintfFunc()
@@ -244,10 +441,30 @@ func (tw TableWriter) setSecretRows(tableWriter *table.Table, secrets []ftypes.S
 	}
 }
 
+func (tw TableWriter) writeLicenses(tableWriter *table.Table, licenses []types.DetectedLicense) {
+	tableWriter.SetAlignment(table.AlignLeft, table.AlignLeft, table.AlignCenter, table.AlignCenter)
+	tableWriter.SetHeaders("Package", "License", "Category", "Severity")
+	for _, license := range licenses {
+		pkgName := license.PkgName
+		if license.FilePath != "" {
+			pkgName = fmt.Sprintf("%s (%s)", pkgName, license.FilePath)
+		}
+		severity := license.Severity
+		if tw.isOutputToTerminal() {
+			severity = ColorizeSeverity(severity, severity)
+		}
+		tableWriter.AddRow(pkgName, license.Name, license.Category, severity)
+	}
+}
+
 func (tw TableWriter) Println(a ...interface{}) {
 	_, _ = fmt.Fprintln(tw.Output, a...)
 }
 
+func (tw TableWriter) Printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(tw.Output, format, a...)
+}
+
 func (tw TableWriter) countSeverities(result types.Result) map[string]int {
 	severityCount := map[string]int{}
 	for _, misconf := range result.Misconfigurations {
@@ -262,6 +479,9 @@ func (tw TableWriter) countSeverities(result types.Result) map[string]int {
 	for _, v := range result.Vulnerabilities {
 		severityCount[v.Severity]++
 	}
+	for _, l := range result.Licenses {
+		severityCount[l.Severity]++
+	}
 	return severityCount
 }
 
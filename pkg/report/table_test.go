@@ -3,9 +3,11 @@ package report_test
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	ftypes "github.com/aquasecurity/fanal/types"
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/report"
 	"github.com/aquasecurity/trivy/pkg/types"
@@ -17,6 +19,7 @@ func TestReportWriter_Table(t *testing.T) {
 		results            types.Results
 		expectedOutput     string
 		includeNonFailures bool
+		groupBy            string
 	}{
 		{
 			name: "happy path full",
@@ -39,7 +42,12 @@ func TestReportWriter_Table(t *testing.T) {
 					},
 				},
 			},
-			expectedOutput: `┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬───────────────────────────────────────────┐
+			expectedOutput: `
+test ()
+=======
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 1, CRITICAL: 0)
+
+┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬───────────────────────────────────────────┐
 │ Library │ Vulnerability │ Severity │ Installed Version │ Fixed Version │                   Title                   │
 ├─────────┼───────────────┼──────────┼───────────────────┼───────────────┼───────────────────────────────────────────┤
 │ foo     │ CVE-2020-0001 │ HIGH     │ 1.2.3             │ 3.4.5         │ foobar                                    │
@@ -69,7 +77,12 @@ func TestReportWriter_Table(t *testing.T) {
 					},
 				},
 			},
-			expectedOutput: `┌───────────┬───────────────┬──────────┬───────────────────┬───────────────┬───────────────────────────────────────────┐
+			expectedOutput: `
+test ()
+=======
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 1, CRITICAL: 0)
+
+┌───────────┬───────────────┬──────────┬───────────────────┬───────────────┬───────────────────────────────────────────┐
 │  Library  │ Vulnerability │ Severity │ Installed Version │ Fixed Version │                   Title                   │
 ├───────────┼───────────────┼──────────┼───────────────────┼───────────────┼───────────────────────────────────────────┤
 │ foo (bar) │ CVE-2020-0001 │ HIGH     │ 1.2.3             │ 3.4.5         │ foobar                                    │
@@ -96,11 +109,63 @@ func TestReportWriter_Table(t *testing.T) {
 					},
 				},
 			},
-			expectedOutput: `┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬────────┐
+			expectedOutput: `
+test ()
+=======
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 1, CRITICAL: 0)
+
+┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬────────┐
 │ Library │ Vulnerability │ Severity │ Installed Version │ Fixed Version │ Title  │
 ├─────────┼───────────────┼──────────┼───────────────────┼───────────────┼────────┤
 │ foo     │ CVE-2020-0001 │ HIGH     │ 1.2.3             │ 3.4.5         │ foobar │
 └─────────┴───────────────┴──────────┴───────────────────┴───────────────┴────────┘
+`,
+		},
+		{
+			name: "happy path with due dates",
+			results: types.Results{
+				{
+					Target: "test",
+					Vulnerabilities: []types.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2020-0001",
+							PkgName:          "foo",
+							InstalledVersion: "1.2.3",
+							FixedVersion:     "3.4.5",
+							SLA: &types.SLAStatus{
+								DueDate: time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC),
+								Overdue: true,
+							},
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "foobar",
+								Severity: "CRITICAL",
+							},
+						},
+						{
+							VulnerabilityID:  "CVE-2020-0002",
+							PkgName:          "bar",
+							InstalledVersion: "1.2.3",
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "baz",
+								Severity: "LOW",
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: `
+test ()
+=======
+Total: 2 (UNKNOWN: 0, LOW: 1, MEDIUM: 0, HIGH: 0, CRITICAL: 1)
+
+┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬────────┬────────────┐
+│ Library │ Vulnerability │ Severity │ Installed Version │ Fixed Version │ Title  │  Due Date  │
+├─────────┼───────────────┼──────────┼───────────────────┼───────────────┼────────┼────────────┤
+│ foo     │ CVE-2020-0001 │ CRITICAL │ 1.2.3             │ 3.4.5         │ foobar │ 2020-01-08 │
+│         │               │          │                   │               │        │ overdue    │
+├─────────┼───────────────┼──────────┼───────────────────┼───────────────┼────────┼────────────┤
+│ bar     │ CVE-2020-0002 │ LOW      │ 1.2.3             │               │ baz    │            │
+└─────────┴───────────────┴──────────┴───────────────────┴───────────────┴────────┴────────────┘
 `,
 		},
 		{
@@ -124,12 +189,234 @@ func TestReportWriter_Table(t *testing.T) {
 					},
 				},
 			},
-			expectedOutput: `┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬───────────────────────────────────────────┐
+			expectedOutput: `
+test ()
+=======
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 1, CRITICAL: 0)
+
+┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬───────────────────────────────────────────┐
 │ Library │ Vulnerability │ Severity │ Installed Version │ Fixed Version │                   Title                   │
 ├─────────┼───────────────┼──────────┼───────────────────┼───────────────┼───────────────────────────────────────────┤
 │ foo     │ CVE-2020-1234 │ HIGH     │ 1.2.3             │ 3.4.5         │ a b c d e f g h i j k l...                │
 │         │               │          │                   │               │ https://avd.aquasec.com/nvd/cve-2020-1234 │
 └─────────┴───────────────┴──────────┴───────────────────┴───────────────┴───────────────────────────────────────────┘
+`,
+		},
+		{
+			name: "happy path with layers",
+			results: types.Results{
+				{
+					Target: "test",
+					Vulnerabilities: []types.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2020-0001",
+							PkgName:          "foo",
+							InstalledVersion: "1.2.3",
+							FixedVersion:     "3.4.5",
+							Layer: ftypes.Layer{
+								DiffID: "sha256:b2a1a2d80bf0c747a4f6b0ca6af5eef23f043fcdb1ed4f3a3e750aef2dc68079",
+							},
+							LayerCreatedBy: "/bin/sh -c apk add --no-cache foo",
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "foobar",
+								Severity: "HIGH",
+							},
+						},
+						{
+							VulnerabilityID:  "CVE-2020-0002",
+							PkgName:          "bar",
+							InstalledVersion: "1.2.3",
+							FixedVersion:     "3.4.5",
+							Layer: ftypes.Layer{
+								Digest: "sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10",
+								DiffID: "sha256:9a5d14f9f5503e55088666beef7e85a8d9625d4fa7418e2fe269e9c54bcb853c",
+							},
+							LayerCreatedBy: "/bin/sh -c #(nop) ADD file:5d673d25da3a14ce1f6cf66e4c7fd4f4b85a3759a9d93efb3fd9ff852b5b56e4 in / ",
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "foobar",
+								Severity: "HIGH",
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: `
+test ()
+=======
+Total: 2 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 2, CRITICAL: 0)
+
+┌─────────┬───────────────┬──────────┬───────────────────┬───────────────┬────────┬──────────────────────────────────────────┐
+│ Library │ Vulnerability │ Severity │ Installed Version │ Fixed Version │ Title  │                  Layer                   │
+├─────────┼───────────────┼──────────┼───────────────────┼───────────────┼────────┼──────────────────────────────────────────┤
+│ foo     │ CVE-2020-0001 │ HIGH     │ 1.2.3             │ 3.4.5         │ foobar │ RUN apk add --no-cache foo               │
+│         │               │          │                   │               │        │ sha256:b2a1a2d80bf0                      │
+├─────────┼───────────────┤          │                   │               │        ├──────────────────────────────────────────┤
+│ bar     │ CVE-2020-0002 │          │                   │               │        │ ADD file:5d673d25da3a14ce1f6cf66e4c7f... │
+│         │               │          │                   │               │        │ sha256:5216338b40a7                      │
+└─────────┴───────────────┴──────────┴───────────────────┴───────────────┴────────┴──────────────────────────────────────────┘
+`,
+		},
+		{
+			name: "happy path with dependency paths",
+			results: types.Results{
+				{
+					Target: "package-lock.json",
+					Vulnerabilities: []types.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2022-24999",
+							PkgName:          "qs",
+							InstalledVersion: "6.7.0",
+							FixedVersion:     "6.7.3",
+							DependencyPaths: [][]string{
+								{"express@4.17.1", "body-parser@1.19.0", "qs@6.7.0"},
+								{"request@2.88.0", "qs@6.7.0"},
+							},
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "qs: prototype poisoning",
+								Severity: "HIGH",
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: `
+package-lock.json ()
+====================
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 1, CRITICAL: 0)
+
+┌─────────┬────────────────┬──────────┬───────────────────┬───────────────┬─────────────────────────┐
+│ Library │ Vulnerability  │ Severity │ Installed Version │ Fixed Version │          Title          │
+├─────────┼────────────────┼──────────┼───────────────────┼───────────────┼─────────────────────────┤
+│ qs      │ CVE-2022-24999 │ HIGH     │ 6.7.0             │ 6.7.3         │ qs: prototype poisoning │
+└─────────┴────────────────┴──────────┴───────────────────┴───────────────┴─────────────────────────┘
+
+Dependency Paths
+================
+qs@6.7.0 (CVE-2022-24999)
+  express@4.17.1 > body-parser@1.19.0 > qs@6.7.0
+  request@2.88.0 > qs@6.7.0
+
+`,
+		},
+		{
+			name: "happy path with licenses",
+			results: types.Results{
+				{
+					Target: "OS Packages",
+					Class:  types.ClassLicense,
+					Licenses: []types.DetectedLicense{
+						{
+							Severity: "HIGH",
+							Category: "restricted",
+							PkgName:  "readline",
+							Name:     "GPL-3.0-or-later",
+						},
+					},
+				},
+				{
+					Target: "app/package-lock.json",
+					Class:  types.ClassLicense,
+					Licenses: []types.DetectedLicense{
+						{
+							Severity: "CRITICAL",
+							Category: "forbidden",
+							PkgName:  "left-pad",
+							FilePath: "node_modules/left-pad/package.json",
+							Name:     "WTFPL",
+						},
+					},
+				},
+			},
+			expectedOutput: `
+OS Packages (license)
+=====================
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 1, CRITICAL: 0)
+
+┌──────────┬──────────────────┬────────────┬──────────┐
+│ Package  │     License      │  Category  │ Severity │
+├──────────┼──────────────────┼────────────┼──────────┤
+│ readline │ GPL-3.0-or-later │ restricted │   HIGH   │
+└──────────┴──────────────────┴────────────┴──────────┘
+
+app/package-lock.json (license)
+===============================
+Total: 1 (UNKNOWN: 0, LOW: 0, MEDIUM: 0, HIGH: 0, CRITICAL: 1)
+
+┌───────────────────────────────────────────────┬─────────┬───────────┬──────────┐
+│                    Package                    │ License │ Category  │ Severity │
+├───────────────────────────────────────────────┼─────────┼───────────┼──────────┤
+│ left-pad (node_modules/left-pad/package.json) │ WTFPL   │ forbidden │ CRITICAL │
+└───────────────────────────────────────────────┴─────────┴───────────┴──────────┘
+`,
+		},
+		{
+			name:    "group by vulnerability",
+			groupBy: report.GroupByVulnerability,
+			results: types.Results{
+				{
+					Target: "test",
+					Vulnerabilities: []types.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2021-3487",
+							PkgName:          "binutils",
+							InstalledVersion: "2.35-r0",
+							FixedVersion:     "2.35.2-r1",
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "binutils: Excessive debug section size can cause excessive memory consumption",
+								Severity: "MEDIUM",
+							},
+						},
+						{
+							VulnerabilityID:  "CVE-2021-3549",
+							PkgName:          "binutils",
+							InstalledVersion: "2.35-r0",
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "binutils: out of bounds read",
+								Severity: "MEDIUM",
+							},
+						},
+						{
+							VulnerabilityID:  "CVE-2021-3487",
+							PkgName:          "binutils-dev",
+							InstalledVersion: "2.35-r0",
+							FixedVersion:     "2.35.2-r1",
+							Vulnerability: dbTypes.Vulnerability{
+								Title:    "binutils: Excessive debug section size can cause excessive memory consumption",
+								Severity: "MEDIUM",
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: `
+test ()
+=======
+Total: 3 (UNKNOWN: 0, LOW: 0, MEDIUM: 3, HIGH: 0, CRITICAL: 0)
+
+┌───────────────┬──────────┬───────────────┬──────────────────────┬────────────────────────────────────────────────────────────┐
+│ Vulnerability │ Severity │ Fixed Version │      Libraries       │                           Title                            │
+├───────────────┼──────────┼───────────────┼──────────────────────┼────────────────────────────────────────────────────────────┤
+│ CVE-2021-3487 │ MEDIUM   │ 2.35.2-r1     │ binutils 2.35-r0     │ binutils: Excessive debug section size can cause excessive │
+│               │          │               │ binutils-dev 2.35-r0 │ memory consumption                                         │
+├───────────────┼──────────┼───────────────┼──────────────────────┼────────────────────────────────────────────────────────────┤
+│ CVE-2021-3549 │ MEDIUM   │               │ binutils 2.35-r0     │ binutils: out of bounds read                               │
+└───────────────┴──────────┴───────────────┴──────────────────────┴────────────────────────────────────────────────────────────┘
+`,
+		},
+		{
+			name: "not scanned",
+			results: types.Results{
+				{
+					Target:     "alpine:3.16",
+					Class:      types.ClassOSPkg,
+					NotScanned: "the scan budget was exceeded",
+				},
+			},
+			expectedOutput: `
+alpine:3.16 (not scanned)
+=========================
+Reason: the scan budget was exceeded
+
 `,
 		},
 		{
@@ -144,7 +431,9 @@ func TestReportWriter_Table(t *testing.T) {
 			err := report.Write(types.Report{Results: tc.results}, report.Option{
 				Format:             "table",
 				Output:             &tableWritten,
+				Severities:         []dbTypes.Severity{dbTypes.SeverityUnknown, dbTypes.SeverityLow, dbTypes.SeverityMedium, dbTypes.SeverityHigh, dbTypes.SeverityCritical},
 				IncludeNonFailures: tc.includeNonFailures,
+				GroupBy:            tc.groupBy,
 			})
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expectedOutput, tableWritten.String(), tc.name)
@@ -35,6 +35,15 @@ func NewTemplateWriter(output io.Writer, outputTemplate string) (*TemplateWriter
 		}
 		outputTemplate = string(buf)
 	}
+	tmpl, err := newTemplate("output template", outputTemplate)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing template: %w", err)
+	}
+	return &TemplateWriter{Output: output, Template: tmpl}, nil
+}
+
+// newTemplate parses the template with the functions available in custom templates
+func newTemplate(name, text string) (*template.Template, error) {
 	var templateFuncMap template.FuncMap
 	templateFuncMap = sprig.GenericFuncMap()
 	templateFuncMap["escapeXML"] = func(input string) string {
@@ -63,11 +72,7 @@ func NewTemplateWriter(output io.Writer, outputTemplate string) (*TemplateWriter
 		templateFuncMap[k] = v
 	}
 
-	tmpl, err := template.New("output template").Funcs(templateFuncMap).Parse(outputTemplate)
-	if err != nil {
-		return nil, xerrors.Errorf("error parsing template: %w", err)
-	}
-	return &TemplateWriter{Output: output, Template: tmpl}, nil
+	return template.New(name).Funcs(templateFuncMap).Parse(text)
 }
 
 // Write writes result
@@ -0,0 +1,82 @@
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// sampleReport covers all the result classes so that most fields referenced by templates are evaluated
+//
+//go:embed template_sample.json
+var sampleReport []byte
+
+// e.g. "template: my.tpl:12:5: executing ..." or "template: my.tpl:3: unexpected ..."
+var templateErrPosition = regexp.MustCompile(`^template: (?:[^:]+):(\d+)(?::(\d+))?: `)
+
+// TemplateError is an error in a custom template with the position
+type TemplateError struct {
+	Line   int
+	Column int
+	Err    error
+
+	// Source is the line of the template where the error occurs
+	Source string
+}
+
+func (e *TemplateError) Error() string {
+	if e.Source == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s\n%5d | %s", e.Err, e.Line, e.Source)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// SampleReport returns the report bundled for validating templates
+func SampleReport() (types.Report, error) {
+	var report types.Report
+	if err := json.Unmarshal(sampleReport, &report); err != nil {
+		return types.Report{}, xerrors.Errorf("sample report decode error: %w", err)
+	}
+	return report, nil
+}
+
+// ValidateTemplate parses the template and renders it against the report into w.
+// The errors in the template are returned as *TemplateError with the line numbers.
+func ValidateTemplate(w io.Writer, name, text string, report types.Report) error {
+	tmpl, err := newTemplate(name, text)
+	if err != nil {
+		return newTemplateError(err, text)
+	}
+	if err = tmpl.Execute(w, report.Results); err != nil {
+		return newTemplateError(err, text)
+	}
+	return nil
+}
+
+func newTemplateError(err error, text string) error {
+	te := &TemplateError{Err: err}
+	m := templateErrPosition.FindStringSubmatch(err.Error())
+	if m == nil {
+		return te
+	}
+	te.Line, _ = strconv.Atoi(m[1])
+	te.Column, _ = strconv.Atoi(m[2])
+
+	lines := strings.Split(text, "\n")
+	if te.Line > 0 && te.Line <= len(lines) {
+		te.Source = strings.TrimRight(lines[te.Line-1], "\r")
+	}
+	return te
+}
@@ -0,0 +1,90 @@
+package report_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/report"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		want       string
+		wantLine   int
+		wantColumn int
+		wantErr    string
+	}{
+		{
+			name:     "happy path",
+			template: "{{ range . }}{{ range .Vulnerabilities }}{{ println .VulnerabilityID .Severity }}{{ end }}{{ end }}",
+			want:     "CVE-2022-1292 CRITICAL\nCVE-2022-28391 HIGH\nCVE-2021-23337 HIGH\n",
+		},
+		{
+			name:       "sad path: unknown field",
+			template:   "{{ range . }}\n{{ range .Misconfigurations }}\n{{ .IacMetadata.StartLine }}\n{{ end }}{{ end }}",
+			wantLine:   3,
+			wantColumn: 15,
+			wantErr: `template: my.tpl:3:15: executing "my.tpl" at <.IacMetadata.StartLine>: can't evaluate field IacMetadata in type types.DetectedMisconfiguration
+    3 | {{ .IacMetadata.StartLine }}`,
+		},
+		{
+			name:     "sad path: unknown function",
+			template: "{{ range . }}\n\n{{ toUpper .Target }}\n{{ end }}",
+			wantLine: 3,
+			wantErr: `template: my.tpl:3: function "toUpper" not defined
+    3 | {{ toUpper .Target }}`,
+		},
+		{
+			name:     "sad path: unclosed action",
+			template: "{{ range . }}\n{{ .Target }}\n",
+			wantLine: 3,
+			wantErr:  "template: my.tpl:3: unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, err := report.SampleReport()
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = report.ValidateTemplate(&buf, "my.tpl", tt.template, sample)
+			if tt.wantErr != "" {
+				var te *report.TemplateError
+				require.ErrorAs(t, err, &te)
+				assert.Equal(t, tt.wantLine, te.Line)
+				assert.Equal(t, tt.wantColumn, te.Column)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestValidateTemplate_contrib(t *testing.T) {
+	sample, err := report.SampleReport()
+	require.NoError(t, err)
+
+	templates, err := filepath.Glob("../../contrib/*.tpl")
+	require.NoError(t, err)
+	require.NotEmpty(t, templates)
+
+	for _, path := range templates {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			text, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, report.ValidateTemplate(&buf, path, string(text), sample))
+			assert.NotEmpty(t, buf.String())
+		})
+	}
+}
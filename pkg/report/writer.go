@@ -11,11 +11,13 @@ import (
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/log"
 	"github.com/aquasecurity/trivy/pkg/report/cyclonedx"
+	"github.com/aquasecurity/trivy/pkg/report/predicate"
 	"github.com/aquasecurity/trivy/pkg/report/spdx"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 const (
+	// SchemaVersion is the latest version of the JSON reports, see schema.go for the compatibility rules
 	SchemaVersion = 2
 )
 
@@ -29,6 +31,24 @@ type Option struct {
 	OutputTemplate string
 	AppVersion     string
 
+	// SchemaVersion is the version of the JSON reports, the latest by default
+	SchemaVersion int
+
+	// ScanStartedOn is recorded in the predicate of the attestations and the GitLab dependency scanning reports
+	ScanStartedOn time.Time
+
+	// GroupBy collapses the identical findings in the table, e.g. GroupByVulnerability
+	GroupBy string
+
+	// Report is SummaryReport to write only the number of the findings in the table or JSON, AllReport by default
+	Report string
+
+	// Deterministic writes the same report for the same artifact and DB, see Deterministic
+	Deterministic bool
+
+	// HashAlgorithms selects the hashes of the components in the SBOMs, and all the hashes are written if empty
+	HashAlgorithms []string
+
 	// For misconfigurations
 	IncludeNonFailures bool
 	Trace              bool
@@ -36,23 +56,49 @@ type Option struct {
 
 // Write writes the result to output, format as passed in argument
 func Write(report types.Report, option Option) error {
+	now := Now
+	if option.Deterministic {
+		report = Deterministic(report)
+		option.ScanStartedOn = time.Time{}
+		now = func() time.Time { return time.Time{} }
+	}
+
 	var writer Writer
 	switch option.Format {
 	case "table":
+		if option.Report == SummaryReport {
+			writer = SummaryWriter{Output: option.Output, Severities: option.Severities}
+			break
+		}
 		writer = &TableWriter{
 			Output:             option.Output,
 			Severities:         option.Severities,
 			ShowMessageOnce:    &sync.Once{},
+			GroupBy:            option.GroupBy,
 			IncludeNonFailures: option.IncludeNonFailures,
 			Trace:              option.Trace,
 		}
 	case "json":
-		writer = &JSONWriter{Output: option.Output}
+		if option.Report == SummaryReport {
+			writer = SummaryJSONWriter{Output: option.Output}
+			break
+		}
+		writer = &JSONWriter{Output: option.Output, SchemaVersion: option.SchemaVersion}
 	case "cyclonedx":
 		// TODO: support xml format option with cyclonedx writer
-		writer = cyclonedx.NewWriter(option.Output, option.AppVersion)
+		if option.Deterministic {
+			writer = cyclonedx.NewWriter(option.Output, option.AppVersion, cyclonedx.WithClock(deterministicClock()),
+				cyclonedx.WithNewUUID(deterministicUUID()), cyclonedx.WithHashAlgorithms(option.HashAlgorithms))
+			break
+		}
+		writer = cyclonedx.NewWriter(option.Output, option.AppVersion, cyclonedx.WithHashAlgorithms(option.HashAlgorithms))
 	case "spdx", "spdx-json":
-		writer = spdx.NewWriter(option.Output, option.AppVersion, option.Format)
+		if option.Deterministic {
+			writer = spdx.NewWriter(option.Output, option.AppVersion, option.Format, spdx.WithClock(deterministicClock()),
+				spdx.WithNewUUID(deterministicUUID()), spdx.WithHashAlgorithms(option.HashAlgorithms))
+			break
+		}
+		writer = spdx.NewWriter(option.Output, option.AppVersion, option.Format, spdx.WithHashAlgorithms(option.HashAlgorithms))
 	case "template":
 		// We keep `sarif.tpl` template working for backward compatibility for a while.
 		if strings.HasPrefix(option.OutputTemplate, "@") && strings.HasSuffix(option.OutputTemplate, "sarif.tpl") {
@@ -60,12 +106,50 @@ func Write(report types.Report, option Option) error {
 			writer = SarifWriter{Output: option.Output, Version: option.AppVersion}
 			break
 		}
+		// The GitLab templates were replaced with the formats, and the shipped templates are redirected to them
+		switch gitlabTemplateFormat(option.OutputTemplate) {
+		case "gitlab-codequality":
+			log.Logger.Warn("Using `--template gitlab-codequality.tpl` is deprecated. Please migrate to `--format gitlab-codequality`.")
+			writer = GitLabCodeQualityWriter{Output: option.Output}
+		case "gitlab-dependency-scanning":
+			log.Logger.Warn("Using `--template gitlab.tpl` is deprecated. Please migrate to `--format gitlab-dependency-scanning` " +
+				"and upload the report as `dependency_scanning`.")
+			writer = GitLabDependencyScanningWriter{
+				Output:    option.Output,
+				Version:   option.AppVersion,
+				StartedOn: option.ScanStartedOn,
+				Now:       now,
+			}
+		}
+		if writer != nil {
+			break
+		}
 		var err error
 		if writer, err = NewTemplateWriter(option.Output, option.OutputTemplate); err != nil {
 			return xerrors.Errorf("failed to initialize template writer: %w", err)
 		}
 	case "sarif":
 		writer = SarifWriter{Output: option.Output, Version: option.AppVersion}
+	case "bitbucket":
+		writer = BitbucketWriter{Output: option.Output, Version: option.AppVersion}
+	case "azure-devops":
+		writer = AzureDevOpsWriter{Output: option.Output}
+	case "gitlab-dependency-scanning":
+		writer = GitLabDependencyScanningWriter{
+			Output:    option.Output,
+			Version:   option.AppVersion,
+			StartedOn: option.ScanStartedOn,
+			Now:       now,
+		}
+	case "gitlab-codequality":
+		writer = GitLabCodeQualityWriter{Output: option.Output}
+	case "cosign-vuln":
+		writer = predicate.VulnWriter{
+			Output:    option.Output,
+			Version:   option.AppVersion,
+			StartedOn: option.ScanStartedOn,
+			Now:       now,
+		}
 	default:
 		return xerrors.Errorf("unknown format: %v", option.Format)
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
@@ -82,3 +83,74 @@ func TestResults_Failed(t *testing.T) {
 		})
 	}
 }
+
+func TestResults_FailedBy(t *testing.T) {
+	results := types.Results{
+		{
+			Target: "test",
+			Type:   "test",
+			Vulnerabilities: []types.DetectedVulnerability{
+				{
+					VulnerabilityID: "CVE-2021-0001",
+					PkgName:         "test",
+					FixedVersion:    "1.2.3",
+					Vulnerability: dbTypes.Vulnerability{
+						Severity: dbTypes.SeverityMedium.String(),
+					},
+				},
+				{
+					VulnerabilityID: "CVE-2021-0002",
+					PkgName:         "test",
+					Vulnerability: dbTypes.Vulnerability{
+						Severity: dbTypes.SeverityHigh.String(),
+					},
+				},
+			},
+			Misconfigurations: []types.DetectedMisconfiguration{
+				{
+					Type:     "Docker Security Check",
+					ID:       "ID-001",
+					Severity: dbTypes.SeverityCritical.String(),
+					Status:   types.StatusPassed,
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name string
+		cond types.FailCondition
+		want bool
+	}{
+		{
+			name: "vulnerabilities with the severity",
+			cond: types.FailCondition{Severity: dbTypes.SeverityMedium},
+			want: true,
+		},
+		{
+			name: "vulnerabilities with a lower severity",
+			cond: types.FailCondition{Severity: dbTypes.SeverityCritical},
+			want: false,
+		},
+		{
+			name: "fixed vulnerabilities",
+			cond: types.FailCondition{FixedOnly: true},
+			want: true,
+		},
+		{
+			name: "unfixed vulnerabilities with the severity",
+			cond: types.FailCondition{Severity: dbTypes.SeverityHigh, FixedOnly: true},
+			want: false,
+		},
+		{
+			name: "unfixed vulnerabilities with the severity without fixed only",
+			cond: types.FailCondition{Severity: dbTypes.SeverityHigh},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := results.FailedBy(tt.cond)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package result
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// publishedDateLayout is the layout of '--ignore-published-before', e.g. "2015-01-01"
+const publishedDateLayout = "2006-01-02"
+
+// AgeFilter ignores the vulnerabilities by their age instead of their IDs, e.g. to triage legacy images.
+// The vulnerabilities without the published date are never ignored, since their age is unknown.
+type AgeFilter struct {
+	// PublishedBefore ignores the vulnerabilities published before the date
+	PublishedBefore time.Time
+
+	// UnfixedSince ignores the vulnerabilities which have been published without a fix for the period
+	UnfixedSince time.Duration
+}
+
+// NewAgeFilter parses the date, e.g. "2015-01-01", and the period in days, e.g. "90d", or in the Go duration.
+// Either of them can be empty.
+func NewAgeFilter(publishedBefore, unfixedSince string) (AgeFilter, error) {
+	var f AgeFilter
+	if publishedBefore != "" {
+		t, err := time.Parse(publishedDateLayout, publishedBefore)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, publishedBefore); err != nil {
+				return AgeFilter{}, xerrors.Errorf("invalid date %q, must be YYYY-MM-DD or RFC 3339", publishedBefore)
+			}
+		}
+		f.PublishedBefore = t
+	}
+	if unfixedSince != "" {
+		d, err := parseSLAPeriod(unfixedSince)
+		if err != nil {
+			return AgeFilter{}, xerrors.Errorf("invalid period: %w", err)
+		}
+		f.UnfixedSince = d
+	}
+	return f, nil
+}
+
+// Empty returns true if no vulnerability is ignored
+func (f AgeFilter) Empty() bool {
+	return f.PublishedBefore.IsZero() && f.UnfixedSince == 0
+}
+
+// filter returns the vulnerabilities to be reported, and those ignored by their age.
+// The source of the ignored ones is the option, so that the decisions can be told apart from the ignore file.
+func (f AgeFilter) filter(vulns []types.DetectedVulnerability, now time.Time) ([]types.DetectedVulnerability,
+	[]types.IgnoredVulnerability) {
+	if f.Empty() {
+		return vulns, nil
+	}
+
+	var filtered []types.DetectedVulnerability
+	var ignored []types.IgnoredVulnerability
+	for _, vuln := range vulns {
+		if source := f.source(vuln, now); source != "" {
+			ignored = append(ignored, types.IgnoredVulnerability{
+				DetectedVulnerability: vuln,
+				Source:                source,
+			})
+			continue
+		}
+		filtered = append(filtered, vuln)
+	}
+	return filtered, ignored
+}
+
+// source returns the option ignoring the vulnerability, or empty if it is reported
+func (f AgeFilter) source(vuln types.DetectedVulnerability, now time.Time) string {
+	if vuln.PublishedDate == nil {
+		return ""
+	}
+	published := *vuln.PublishedDate
+	switch {
+	case !f.PublishedBefore.IsZero() && published.Before(f.PublishedBefore):
+		return fmt.Sprintf("--ignore-published-before %s", f.PublishedBefore.Format(publishedDateLayout))
+	case f.UnfixedSince > 0 && vuln.FixedVersion == "" && now.Sub(published) > f.UnfixedSince:
+		return fmt.Sprintf("--ignore-unfixed-since %s", formatPeriod(f.UnfixedSince))
+	}
+	return ""
+}
+
+// formatPeriod formats the period in days if possible, in the same way as it is given
+func formatPeriod(d time.Duration) string {
+	if day := 24 * time.Hour; d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
@@ -0,0 +1,134 @@
+package result
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy-db/pkg/utils"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestNewAgeFilter(t *testing.T) {
+	tests := []struct {
+		name            string
+		publishedBefore string
+		unfixedSince    string
+		want            AgeFilter
+		wantErr         string
+	}{
+		{
+			name:            "happy path",
+			publishedBefore: "2015-01-01",
+			unfixedSince:    "90d",
+			want: AgeFilter{
+				PublishedBefore: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+				UnfixedSince:    90 * 24 * time.Hour,
+			},
+		},
+		{
+			name:            "RFC 3339",
+			publishedBefore: "2015-01-01T09:00:00Z",
+			unfixedSince:    "720h",
+			want: AgeFilter{
+				PublishedBefore: time.Date(2015, 1, 1, 9, 0, 0, 0, time.UTC),
+				UnfixedSince:    720 * time.Hour,
+			},
+		},
+		{
+			name: "no filter",
+		},
+		{
+			name:            "sad path: invalid date",
+			publishedBefore: "01/01/2015",
+			wantErr:         `invalid date "01/01/2015"`,
+		},
+		{
+			name:         "sad path: invalid period",
+			unfixedSince: "3 months",
+			wantErr:      "invalid period",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAgeFilter(tt.publishedBefore, tt.unfixedSince)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAgeFilter_filter(t *testing.T) {
+	f := AgeFilter{
+		PublishedBefore: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+		UnfixedSince:    90 * 24 * time.Hour,
+	}
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		vuln       types.DetectedVulnerability
+		wantSource string
+	}{
+		{
+			name: "published before the date",
+			vuln: types.DetectedVulnerability{
+				FixedVersion: "1.2.3",
+				Vulnerability: dbTypes.Vulnerability{
+					PublishedDate: utils.MustTimeParse("2014-05-01T00:00:00Z"),
+				},
+			},
+			wantSource: "--ignore-published-before 2015-01-01",
+		},
+		{
+			name: "unfixed for long",
+			vuln: types.DetectedVulnerability{
+				Vulnerability: dbTypes.Vulnerability{
+					PublishedDate: utils.MustTimeParse("2022-01-01T00:00:00Z"),
+				},
+			},
+			wantSource: "--ignore-unfixed-since 90d",
+		},
+		{
+			name: "recently published without a fix",
+			vuln: types.DetectedVulnerability{
+				Vulnerability: dbTypes.Vulnerability{
+					PublishedDate: utils.MustTimeParse("2022-05-01T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "fixed",
+			vuln: types.DetectedVulnerability{
+				FixedVersion: "1.2.3",
+				Vulnerability: dbTypes.Vulnerability{
+					PublishedDate: utils.MustTimeParse("2022-01-01T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "no published date",
+			vuln: types.DetectedVulnerability{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, ignored := f.filter([]types.DetectedVulnerability{tt.vuln}, now)
+			if tt.wantSource == "" {
+				assert.Equal(t, []types.DetectedVulnerability{tt.vuln}, filtered)
+				assert.Empty(t, ignored)
+				return
+			}
+			assert.Empty(t, filtered)
+			assert.Equal(t, []types.IgnoredVulnerability{{DetectedVulnerability: tt.vuln, Source: tt.wantSource}}, ignored)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package result
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/package-url/packageurl-go"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	tpurl "github.com/aquasecurity/trivy/pkg/purl"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/vex"
+)
+
+// IgnoreFinding represents an item to be ignored
+type IgnoreFinding struct {
+	// ID is a vulnerability ID, misconfiguration ID or secret rule ID
+	ID string `yaml:"id"`
+
+	// Paths restricts the scope of the ignore rule to the matched targets or package paths.
+	// Glob patterns such as "vendor/*" and "**/testdata/**" can be used.
+	Paths []string `yaml:"paths"`
+
+	// PURLs restricts the scope of the ignore rule to the matched packages.
+	// The type must match the packages, e.g. "pkg:npm" for package-lock.json and yarn.lock.
+	// Version can be omitted, e.g. "pkg:npm/lodash".
+	PURLs []string `yaml:"purls"`
+
+	// Reason describes why the finding is ignored
+	Reason string `yaml:"reason"`
+
+	// Justification is the VEX justification of the vulnerability not affecting the package,
+	// e.g. "vulnerable_code_not_in_execute_path"
+	Justification string `yaml:"justification"`
+
+	// ExpiresAt is the date after which the finding reappears
+	ExpiresAt time.Time `yaml:"expires"`
+}
+
+// IgnoreFindings represents a list of ignore rules
+type IgnoreFindings []IgnoreFinding
+
+// IgnoreConfig represents the structure of the ignore file
+type IgnoreConfig struct {
+	Vulnerabilities   IgnoreFindings `yaml:"vulnerabilities"`
+	Misconfigurations IgnoreFindings `yaml:"misconfigurations"`
+	Secrets           IgnoreFindings `yaml:"secrets"`
+
+	// Path is the ignore file, recorded as the source of the ignore decisions
+	Path string `yaml:"-"`
+}
+
+// ParseIgnoreFile parses the ignore file. Both the flat .trivyignore and the structured .trivyignore.yaml are supported.
+func ParseIgnoreFile(ignoreFile string) (IgnoreConfig, error) {
+	if ignoreFile == "" {
+		return IgnoreConfig{}, nil
+	}
+
+	f, err := os.Open(ignoreFile)
+	if errors.Is(err, os.ErrNotExist) {
+		// trivy must work even if no .trivyignore exist
+		return IgnoreConfig{}, nil
+	} else if err != nil {
+		return IgnoreConfig{}, xerrors.Errorf("file open error: %w", err)
+	}
+	defer f.Close()
+	log.Logger.Debugf("Found an ignore file %s", ignoreFile)
+
+	var conf IgnoreConfig
+	switch filepath.Ext(ignoreFile) {
+	case ".yml", ".yaml":
+		if err = yaml.NewDecoder(f).Decode(&conf); err != nil {
+			return IgnoreConfig{}, xerrors.Errorf("YAML decode error: %w", err)
+		}
+	default:
+		var ignoredIDs IgnoreFindings
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "#") || line == "" {
+				continue
+			}
+			ignoredIDs = append(ignoredIDs, IgnoreFinding{ID: line})
+		}
+		if err = scanner.Err(); err != nil {
+			return IgnoreConfig{}, xerrors.Errorf("file scan error: %w", err)
+		}
+
+		// The flat format doesn't distinguish finding types
+		conf = IgnoreConfig{
+			Vulnerabilities:   ignoredIDs,
+			Misconfigurations: ignoredIDs,
+		}
+	}
+
+	for _, f := range conf.Vulnerabilities {
+		if f.Justification != "" && !slices.Contains(vex.Justifications, f.Justification) {
+			return IgnoreConfig{}, xerrors.Errorf("unknown justification %q of %s, supported values: %q",
+				f.Justification, f.ID, vex.Justifications)
+		}
+	}
+	conf.Path = ignoreFile
+
+	conf.reportExpired(time.Now())
+
+	return conf, nil
+}
+
+func (c IgnoreConfig) reportExpired(now time.Time) {
+	for _, findings := range []IgnoreFindings{c.Vulnerabilities, c.Misconfigurations, c.Secrets} {
+		for _, f := range findings {
+			if f.expired(now) {
+				log.Logger.Warnf("The ignore rule for %s expired on %s and it is no longer applied",
+					f.ID, f.ExpiresAt.Format("2006-01-02"))
+			}
+		}
+	}
+}
+
+// MatchVulnerability returns the rule ignoring the vulnerability of the result type, e.g. "npm" and "debian", if any
+func (f IgnoreFindings) MatchVulnerability(target, resultType string, vuln types.DetectedVulnerability) *IgnoreFinding {
+	for _, rule := range f {
+		if rule.ID != vuln.VulnerabilityID {
+			continue
+		}
+		if !rule.matchPath(target, vuln.PkgPath) || !rule.matchPURL(resultType, vuln.PkgName, vuln.InstalledVersion) {
+			continue
+		}
+		if rule.expired(time.Now()) {
+			continue
+		}
+		return &rule
+	}
+	return nil
+}
+
+// Match returns the rule ignoring the finding with the given ID in the target, if any
+func (f IgnoreFindings) Match(target, id string) *IgnoreFinding {
+	for _, rule := range f {
+		if rule.ID != id || !rule.matchPath(target) || len(rule.PURLs) > 0 {
+			continue
+		}
+		if rule.expired(time.Now()) {
+			continue
+		}
+		return &rule
+	}
+	return nil
+}
+
+func (r IgnoreFinding) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
+
+func (r IgnoreFinding) matchPath(paths ...string) bool {
+	if len(r.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range r.Paths {
+		for _, p := range paths {
+			if p == "" {
+				continue
+			}
+			if matched, _ := doublestar.Match(pattern, filepath.ToSlash(p)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r IgnoreFinding) matchPURL(resultType, pkgName, pkgVersion string) bool {
+	if len(r.PURLs) == 0 {
+		return true
+	}
+	for _, p := range r.PURLs {
+		purl, err := packageurl.FromString(p)
+		if err != nil {
+			log.Logger.Warnf("Invalid PURL in the ignore file: %s", p)
+			continue
+		}
+		// The same name can be different packages in other ecosystems, e.g. "requests" of npm and PyPI
+		if !strings.EqualFold(purl.Type, tpurl.Type(resultType)) {
+			continue
+		}
+		if purl.Version != "" && purl.Version != pkgVersion {
+			continue
+		}
+
+		// Package names are stored differently depending on ecosystems,
+		// e.g. "@babel/core" for npm and "org.apache:log4j" for Maven.
+		names := []string{purl.Name}
+		if purl.Namespace != "" {
+			names = append(names, purl.Namespace+"/"+purl.Name, purl.Namespace+":"+purl.Name)
+		}
+		for _, name := range names {
+			if name == pkgName {
+				return true
+			}
+		}
+	}
+	return false
+}
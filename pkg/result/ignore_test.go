@@ -0,0 +1,69 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestIgnoreFindings_MatchVulnerability(t *testing.T) {
+	rules := IgnoreFindings{
+		{
+			ID:    "CVE-2019-0001",
+			PURLs: []string{"pkg:npm/requests@2.0.0"},
+		},
+		{
+			ID:    "CVE-2019-0002",
+			PURLs: []string{"pkg:deb/debian/libtinfo6"},
+		},
+	}
+	tests := []struct {
+		name       string
+		resultType string
+		vuln       types.DetectedVulnerability
+		want       bool
+	}{
+		{
+			name:       "npm package in yarn.lock",
+			resultType: ftypes.Yarn,
+			vuln:       types.DetectedVulnerability{VulnerabilityID: "CVE-2019-0001", PkgName: "requests", InstalledVersion: "2.0.0"},
+			want:       true,
+		},
+		{
+			name:       "npm package installed in node_modules",
+			resultType: ftypes.NodePkg,
+			vuln:       types.DetectedVulnerability{VulnerabilityID: "CVE-2019-0001", PkgName: "requests", InstalledVersion: "2.0.0"},
+			want:       true,
+		},
+		{
+			name:       "PyPI package of the same name",
+			resultType: ftypes.PythonPkg,
+			vuln:       types.DetectedVulnerability{VulnerabilityID: "CVE-2019-0001", PkgName: "requests", InstalledVersion: "2.0.0"},
+		},
+		{
+			name:       "gem of the same name",
+			resultType: ftypes.Bundler,
+			vuln:       types.DetectedVulnerability{VulnerabilityID: "CVE-2019-0001", PkgName: "requests", InstalledVersion: "2.0.0"},
+		},
+		{
+			name:       "Debian package",
+			resultType: "debian",
+			vuln:       types.DetectedVulnerability{VulnerabilityID: "CVE-2019-0002", PkgName: "libtinfo6", InstalledVersion: "6.2"},
+			want:       true,
+		},
+		{
+			name:       "Alpine package of the same name",
+			resultType: "alpine",
+			vuln:       types.DetectedVulnerability{VulnerabilityID: "CVE-2019-0002", PkgName: "libtinfo6", InstalledVersion: "6.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.MatchVulnerability("target", tt.resultType, tt.vuln)
+			assert.Equal(t, tt.want, got != nil)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package result
+
+import (
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// LocaleEnglish is the language of the titles and the descriptions merged in the DB
+const LocaleEnglish = "en"
+
+// localeSources are the advisory sources providing the titles and the descriptions in the languages other than English,
+// kept in the vulnerability details of the DB, in the order of preference.
+var localeSources = map[string][]dbTypes.SourceID{
+	"ja": {"jvn"},
+}
+
+// ParseLocale returns the supported language of the locale, e.g. "ja" for "ja_JP.UTF-8" or "ja-JP"
+func ParseLocale(s string) (string, error) {
+	lang := strings.ToLower(s)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	// "C" and "POSIX" are the default locales of the systems
+	if lang == "" || lang == LocaleEnglish || lang == "c" || lang == "posix" {
+		return LocaleEnglish, nil
+	}
+	if _, ok := localeSources[lang]; !ok {
+		supported := append(maps.Keys(localeSources), LocaleEnglish)
+		slices.Sort(supported)
+		return "", xerrors.Errorf("unsupported locale %q, supported locales: %q", s, supported)
+	}
+	return lang, nil
+}
+
+// WithLocale returns the client preferring the titles and the descriptions of the vulnerabilities in the language,
+// e.g. the one requested by each client of the server. The English ones are used if the language is empty.
+func (c Client) WithLocale(lang string) Client {
+	c.locale = lang
+	return c
+}
+
+// localize replaces the title and the description with the ones in the language of the locale if the advisory
+// sources of the language provide them. The English ones are kept otherwise.
+func (c Client) localize(vulnID string, vuln *dbTypes.Vulnerability) {
+	sources := localeSources[c.locale]
+	if len(sources) == 0 {
+		return
+	}
+
+	details, err := c.dbc.GetVulnerabilityDetail(vulnID)
+	if err != nil {
+		log.Logger.Debugf("Unable to get the vulnerability details of %s: %s", vulnID, err)
+		return
+	}
+	for _, source := range sources {
+		detail, ok := details[source]
+		if !ok || (detail.Title == "" && detail.Description == "") {
+			continue
+		}
+		if detail.Title != "" {
+			vuln.Title = detail.Title
+		}
+		if detail.Description != "" {
+			vuln.Description = detail.Description
+		}
+		return
+	}
+}
@@ -0,0 +1,136 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-db/pkg/db"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/dbtest"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "language",
+			locale: "ja",
+			want:   "ja",
+		},
+		{
+			name:   "POSIX locale",
+			locale: "ja_JP.UTF-8",
+			want:   "ja",
+		},
+		{
+			name:   "language tag",
+			locale: "en-US",
+			want:   LocaleEnglish,
+		},
+		{
+			name:   "C locale",
+			locale: "C.UTF-8",
+			want:   LocaleEnglish,
+		},
+		{
+			name: "empty",
+			want: LocaleEnglish,
+		},
+		{
+			name:    "unsupported",
+			locale:  "fr_FR",
+			wantErr: `unsupported locale "fr_FR", supported locales: ["en" "ja"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocale(tt.locale)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_FillVulnerabilityInfo_Locale(t *testing.T) {
+	dbtest.InitDB(t, []string{"testdata/fixtures/vulnerability-detail.yaml"})
+	defer db.Close()
+
+	tests := []struct {
+		name   string
+		locale string
+		want   map[string]dbTypes.Vulnerability
+	}{
+		{
+			name:   "Japanese",
+			locale: "ja",
+			want: map[string]dbTypes.Vulnerability{
+				"CVE-2021-20231": {
+					Title:       "GnuTLS における解放済みメモリの使用に関する脆弱性",
+					Description: "GnuTLS には、解放済みメモリの使用に関する脆弱性が存在します。",
+					Severity:    "CRITICAL",
+				},
+				// The English title is kept without the Japanese one
+				"CVE-2021-3449": {
+					Title:       "openssl: NULL pointer dereference in signature_algorithms processing",
+					Description: "OpenSSL における NULL ポインタデリファレンスに関する脆弱性",
+					Severity:    "MEDIUM",
+				},
+				// Not in JVN
+				"CVE-2021-3450": {
+					Title:       "openssl: CA certificate check bypass with X509_V_FLAG_X509_STRICT",
+					Description: "The X509_V_FLAG_X509_STRICT flag enables additional security checks.",
+					Severity:    "HIGH",
+				},
+			},
+		},
+		{
+			name:   "English",
+			locale: LocaleEnglish,
+			want: map[string]dbTypes.Vulnerability{
+				"CVE-2021-20231": {
+					Title:       "gnutls: Use after free in client key_share extension",
+					Description: "A flaw was found in gnutls.",
+					Severity:    "CRITICAL",
+				},
+				"CVE-2021-3449": {
+					Title:       "openssl: NULL pointer dereference in signature_algorithms processing",
+					Description: "An OpenSSL TLS server may crash.",
+					Severity:    "MEDIUM",
+				},
+				"CVE-2021-3450": {
+					Title:       "openssl: CA certificate check bypass with X509_V_FLAG_X509_STRICT",
+					Description: "The X509_V_FLAG_X509_STRICT flag enables additional security checks.",
+					Severity:    "HIGH",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vulns := []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2021-20231"},
+				{VulnerabilityID: "CVE-2021-3449"},
+				{VulnerabilityID: "CVE-2021-3450"},
+			}
+			Client{dbc: db.Config{}}.WithLocale(tt.locale).FillVulnerabilityInfo(vulns, "")
+
+			got := map[string]dbTypes.Vulnerability{}
+			for _, v := range vulns {
+				got[v.VulnerabilityID] = v.Vulnerability
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
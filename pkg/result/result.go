@@ -1,12 +1,12 @@
 package result
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/wire"
 	"github.com/open-policy-agent/opa/rego"
@@ -18,13 +18,18 @@ import (
 	"github.com/aquasecurity/trivy-db/pkg/db"
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/vulnerability"
+	"github.com/aquasecurity/trivy/pkg/licensing"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/osv"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 const (
 	// DefaultIgnoreFile is the file name to be evaluated
 	DefaultIgnoreFile = ".trivyignore"
+
+	// DefaultIgnoreYAMLFile is the structured ignore file to be evaluated when DefaultIgnoreFile doesn't exist
+	DefaultIgnoreYAMLFile = ".trivyignore.yaml"
 )
 
 var (
@@ -32,6 +37,7 @@ var (
 		vulnerability.Debian:           {"http://www.debian.org", "https://www.debian.org"},
 		vulnerability.Ubuntu:           {"http://www.ubuntu.com", "https://usn.ubuntu.com"},
 		vulnerability.RedHat:           {"https://access.redhat.com"},
+		vulnerability.RedHatOVAL:       {"https://access.redhat.com"},
 		vulnerability.SuseCVRF:         {"http://lists.opensuse.org", "https://lists.opensuse.org"},
 		vulnerability.OracleOVAL:       {"http://linux.oracle.com/errata", "https://linux.oracle.com/errata"},
 		vulnerability.NodejsSecurityWg: {"https://www.npmjs.com", "https://hackerone.com"},
@@ -48,6 +54,9 @@ var SuperSet = wire.NewSet(
 // Client implements db operations
 type Client struct {
 	dbc db.Operation
+
+	// locale is the language preferred for the titles and the descriptions of the vulnerabilities, see WithLocale
+	locale string
 }
 
 // NewClient is the factory method for Client
@@ -60,7 +69,10 @@ func (c Client) FillVulnerabilityInfo(vulns []types.DetectedVulnerability, repor
 	for i := range vulns {
 		vulnID := vulns[i].VulnerabilityID
 		vuln, err := c.dbc.GetVulnerability(vulnID)
-		if err != nil {
+		if err != nil && vulns[i].DataSource != nil && vulns[i].DataSource.ID == osv.SourceID {
+			// The vulnerabilities from OSV have their own details
+			continue
+		} else if err != nil {
 			log.Logger.Warnf("Error while getting vulnerability details: %s\n", err)
 			continue
 		}
@@ -83,6 +95,7 @@ func (c Client) FillVulnerabilityInfo(vulns []types.DetectedVulnerability, repor
 		}
 
 		// Add the vulnerability detail
+		c.localize(vulnID, &vuln)
 		vulns[i].Vulnerability = vuln
 
 		vulns[i].Severity = severity
@@ -131,31 +144,76 @@ func (c Client) getPrimaryURL(vulnID string, refs []string, source dbTypes.Sourc
 	return ""
 }
 
-// Filter filter out the vulnerabilities
-func (c Client) Filter(ctx context.Context, vulns []types.DetectedVulnerability, misconfs []types.DetectedMisconfiguration, secrets []ftypes.SecretFinding,
-	severities []dbTypes.Severity, ignoreUnfixed, includeNonFailures bool, ignoreFile, policyFile string) (
-	[]types.DetectedVulnerability, *types.MisconfSummary, []types.DetectedMisconfiguration, []ftypes.SecretFinding, error) {
-	ignoredIDs := getIgnoredIDs(ignoreFile)
+// FilterOption holds the options for filtering the results
+type FilterOption struct {
+	Severities         []dbTypes.Severity
+	IgnoreUnfixed      bool
+	IncludeNonFailures bool
+	IgnoreConfig       IgnoreConfig
+	PolicyFile         string
+
+	// SLA fills the due dates of the vulnerabilities, and OnlyOverdue drops the vulnerabilities not past the due dates
+	SLA         SLAConfig
+	OnlyOverdue bool
+
+	// AgeFilter ignores the vulnerabilities published long ago or left unfixed for long
+	AgeFilter AgeFilter
 
-	filteredVulns := filterVulnerabilities(vulns, severities, ignoreUnfixed, ignoredIDs)
-	misconfSummary, filteredMisconfs := filterMisconfigurations(misconfs, severities, includeNonFailures, ignoredIDs)
-	filteredSecrets := filterSecrets(secrets, severities)
+	// SecretSeverities overrides the severities of the secrets before they are filtered
+	SecretSeverities SecretSeverities
 
-	if policyFile != "" {
+	// LicenseClassifier determines the severities of the licenses, the default policy is used if it is empty
+	LicenseClassifier licensing.Classifier
+}
+
+// Filter filter out the vulnerabilities, misconfigurations and secrets
+func (c Client) Filter(ctx context.Context, result types.Result, opt FilterOption) (types.Result, error) {
+	filteredVulns, ignoredVulns := filterVulnerabilities(result.Target, result.Type, result.Vulnerabilities, opt.Severities,
+		opt.IgnoreUnfixed, opt.IgnoreConfig)
+	filteredVulns, ignoredByAge := opt.AgeFilter.filter(filteredVulns, time.Now())
+	ignoredVulns = append(ignoredVulns, ignoredByAge...)
+	opt.SLA.annotate(filteredVulns, time.Now())
+	misconfSummary, filteredMisconfs := filterMisconfigurations(result.Target, result.Misconfigurations, opt.Severities,
+		opt.IncludeNonFailures, opt.IgnoreConfig.Misconfigurations)
+	opt.SecretSeverities.override(result.Secrets)
+	filteredSecrets := filterSecrets(result.Target, result.Secrets, opt.Severities, opt.IgnoreConfig.Secrets)
+	filteredLicenses := filterLicenses(result.Licenses, opt.Severities, opt.LicenseClassifier)
+
+	if opt.PolicyFile != "" {
 		var err error
-		filteredVulns, filteredMisconfs, err = applyPolicy(ctx, filteredVulns, filteredMisconfs, policyFile)
+		var ignoredByPolicy []types.IgnoredVulnerability
+		filteredVulns, ignoredByPolicy, filteredMisconfs, err = applyPolicy(ctx, filteredVulns, filteredMisconfs,
+			misconfSummary, opt.PolicyFile)
 		if err != nil {
-			return nil, nil, nil, nil, xerrors.Errorf("failed to apply the policy: %w", err)
+			return types.Result{}, xerrors.Errorf("failed to apply the policy: %w", err)
+		}
+		ignoredVulns = append(ignoredVulns, ignoredByPolicy...)
+		if misconfSummary != nil && misconfSummary.Empty() {
+			misconfSummary, filteredMisconfs = nil, nil
 		}
 	}
+	if opt.OnlyOverdue {
+		filteredVulns = filterOverdue(filteredVulns)
+	}
 	sort.Sort(types.BySeverity(filteredVulns))
 
-	return filteredVulns, misconfSummary, filteredMisconfs, filteredSecrets, nil
+	result.Vulnerabilities = filteredVulns
+	result.IgnoredVulnerabilities = ignoredVulns
+	result.MisconfSummary = misconfSummary
+	result.Misconfigurations = filteredMisconfs
+	result.Secrets = filteredSecrets
+	result.Licenses = filteredLicenses
+
+	return result, nil
 }
 
-func filterVulnerabilities(vulns []types.DetectedVulnerability, severities []dbTypes.Severity,
-	ignoreUnfixed bool, ignoredIDs []string) []types.DetectedVulnerability {
+// filterVulnerabilities returns the vulnerabilities to be reported, and those suppressed by the ignore file in the
+// order of the detection
+func filterVulnerabilities(target, resultType string, vulns []types.DetectedVulnerability, severities []dbTypes.Severity,
+	ignoreUnfixed bool, ignoreConfig IgnoreConfig) ([]types.DetectedVulnerability, []types.IgnoredVulnerability) {
 	uniqVulns := make(map[string]types.DetectedVulnerability)
+	var ignored []types.IgnoredVulnerability
+	ignoredKeys := map[string]struct{}{}
 	for _, vuln := range vulns {
 		if vuln.Severity == "" {
 			vuln.Severity = dbTypes.SeverityUnknown.String()
@@ -166,15 +224,25 @@ func filterVulnerabilities(vulns []types.DetectedVulnerability, severities []dbT
 				continue
 			}
 
-			// Ignore unfixed vulnerabilities
+			key := fmt.Sprintf("%s/%s/%s", vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion)
+
+			// Ignore unfixed vulnerabilities, and record the decisions of the ignore file
 			if ignoreUnfixed && vuln.FixedVersion == "" {
 				continue
-			} else if slices.Contains(ignoredIDs, vuln.VulnerabilityID) {
+			} else if rule := ignoreConfig.Vulnerabilities.MatchVulnerability(target, resultType, vuln); rule != nil {
+				if _, ok := ignoredKeys[key]; !ok {
+					ignoredKeys[key] = struct{}{}
+					ignored = append(ignored, types.IgnoredVulnerability{
+						DetectedVulnerability: vuln,
+						Source:                ignoreConfig.Path,
+						Reason:                rule.Reason,
+						Justification:         rule.Justification,
+					})
+				}
 				continue
 			}
 
 			// Check if there is a duplicate vulnerability
-			key := fmt.Sprintf("%s/%s/%s", vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion)
 			if old, ok := uniqVulns[key]; ok && !shouldOverwrite(old, vuln) {
 				continue
 			}
@@ -182,11 +250,11 @@ func filterVulnerabilities(vulns []types.DetectedVulnerability, severities []dbT
 			break
 		}
 	}
-	return maps.Values(uniqVulns)
+	return maps.Values(uniqVulns), ignored
 }
 
-func filterMisconfigurations(misconfs []types.DetectedMisconfiguration, severities []dbTypes.Severity,
-	includeNonFailures bool, ignoredIDs []string) (*types.MisconfSummary, []types.DetectedMisconfiguration) {
+func filterMisconfigurations(target string, misconfs []types.DetectedMisconfiguration, severities []dbTypes.Severity,
+	includeNonFailures bool, ignoreFindings IgnoreFindings) (*types.MisconfSummary, []types.DetectedMisconfiguration) {
 	var filtered []types.DetectedMisconfiguration
 	summary := new(types.MisconfSummary)
 
@@ -194,7 +262,7 @@ func filterMisconfigurations(misconfs []types.DetectedMisconfiguration, severiti
 		// Filter misconfigurations by severity
 		for _, s := range severities {
 			if s.String() == misconf.Severity {
-				if slices.Contains(ignoredIDs, misconf.ID) {
+				if ignoreFindings.Match(target, misconf.ID) != nil {
 					continue
 				}
 
@@ -217,9 +285,14 @@ func filterMisconfigurations(misconfs []types.DetectedMisconfiguration, severiti
 	return summary, filtered
 }
 
-func filterSecrets(secrets []ftypes.SecretFinding, severities []dbTypes.Severity) []ftypes.SecretFinding {
+func filterSecrets(target string, secrets []ftypes.SecretFinding, severities []dbTypes.Severity,
+	ignoreFindings IgnoreFindings) []ftypes.SecretFinding {
 	var filtered []ftypes.SecretFinding
 	for _, secret := range secrets {
+		if ignoreFindings.Match(target, secret.RuleID) != nil {
+			continue
+		}
+
 		// Filter secrets by severity
 		for _, s := range severities {
 			if s.String() == secret.Severity {
@@ -231,6 +304,25 @@ func filterSecrets(secrets []ftypes.SecretFinding, severities []dbTypes.Severity
 	return filtered
 }
 
+// filterLicenses classifies the licenses by the policy, and filters them by the severities
+func filterLicenses(licenses []types.DetectedLicense, severities []dbTypes.Severity,
+	classifier licensing.Classifier) []types.DetectedLicense {
+	var filtered []types.DetectedLicense
+	for _, license := range licenses {
+		category, severity, ok := classifier.Classify(license.Name)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(severities, severity) {
+			continue
+		}
+		license.Category = string(category)
+		license.Severity = severity.String()
+		filtered = append(filtered, license)
+	}
+	return filtered
+}
+
 func summarize(status types.MisconfStatus, summary *types.MisconfSummary) {
 	switch status {
 	case types.StatusFailure:
@@ -242,11 +334,25 @@ func summarize(status types.MisconfStatus, summary *types.MisconfSummary) {
 	}
 }
 
+func unsummarize(status types.MisconfStatus, summary *types.MisconfSummary) {
+	switch status {
+	case types.StatusFailure:
+		summary.Failures--
+	case types.StatusPassed:
+		summary.Successes--
+	case types.StatusException:
+		summary.Exceptions--
+	}
+}
+
+// applyPolicy returns the vulnerabilities and the misconfigurations not ignored by the policy,
+// and the vulnerabilities ignored by it
 func applyPolicy(ctx context.Context, vulns []types.DetectedVulnerability, misconfs []types.DetectedMisconfiguration,
-	policyFile string) ([]types.DetectedVulnerability, []types.DetectedMisconfiguration, error) {
+	misconfSummary *types.MisconfSummary, policyFile string) ([]types.DetectedVulnerability, []types.IgnoredVulnerability,
+	[]types.DetectedMisconfiguration, error) {
 	policy, err := os.ReadFile(policyFile)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("unable to read the policy file: %w", err)
+		return nil, nil, nil, xerrors.Errorf("unable to read the policy file: %w", err)
 	}
 
 	query, err := rego.New(
@@ -255,17 +361,19 @@ func applyPolicy(ctx context.Context, vulns []types.DetectedVulnerability, misco
 		rego.Module("trivy.rego", string(policy)),
 	).PrepareForEval(ctx)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("unable to prepare for eval: %w", err)
+		return nil, nil, nil, xerrors.Errorf("unable to prepare for eval: %w", err)
 	}
 
 	// Vulnerabilities
 	var filteredVulns []types.DetectedVulnerability
+	var ignoredVulns []types.IgnoredVulnerability
 	for _, vuln := range vulns {
 		ignored, err := evaluate(ctx, query, vuln)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if ignored {
+			ignoredVulns = append(ignoredVulns, types.IgnoredVulnerability{DetectedVulnerability: vuln, Source: policyFile})
 			continue
 		}
 		filteredVulns = append(filteredVulns, vuln)
@@ -276,14 +384,18 @@ func applyPolicy(ctx context.Context, vulns []types.DetectedVulnerability, misco
 	for _, misconf := range misconfs {
 		ignored, err := evaluate(ctx, query, misconf)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if ignored {
+			// Ignored misconfigurations must not be counted in the summary
+			if misconfSummary != nil {
+				unsummarize(misconf.Status, misconfSummary)
+			}
 			continue
 		}
 		filteredMisconfs = append(filteredMisconfs, misconf)
 	}
-	return filteredVulns, filteredMisconfs, nil
+	return filteredVulns, ignoredVulns, filteredMisconfs, nil
 }
 func evaluate(ctx context.Context, query rego.PreparedEvalQuery, input interface{}) (bool, error) {
 	results, err := query.Eval(ctx, rego.EvalInput(input))
@@ -301,30 +413,6 @@ func evaluate(ctx context.Context, query rego.PreparedEvalQuery, input interface
 	return ignore, nil
 }
 
-func getIgnoredIDs(ignoreFile string) []string {
-	f, err := os.Open(ignoreFile)
-	if err != nil {
-		// trivy must work even if no .trivyignore exist
-		return nil
-	}
-	log.Logger.Debugf("Found an ignore file %s", ignoreFile)
-
-	var ignoredIDs []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "#") || line == "" {
-			continue
-		}
-		ignoredIDs = append(ignoredIDs, line)
-	}
-
-	log.Logger.Debugf("These IDs will be ignored: %q", ignoredIDs)
-
-	return ignoredIDs
-}
-
 func shouldOverwrite(old, new types.DetectedVulnerability) bool {
 	// The same vulnerability must be picked always.
 	return old.FixedVersion < new.FixedVersion
@@ -3,6 +3,7 @@ package result
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,6 +15,7 @@ import (
 	"github.com/aquasecurity/trivy-db/pkg/utils"
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/vulnerability"
 	"github.com/aquasecurity/trivy/pkg/dbtest"
+	"github.com/aquasecurity/trivy/pkg/licensing"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
@@ -349,6 +351,18 @@ func TestClient_getPrimaryURL(t *testing.T) {
 			},
 			want: "http://lists.opensuse.org/opensuse-security-announce/2019-11/msg00076.html",
 		},
+		{
+			name: "Red Hat OVAL",
+			args: args{
+				vulnID: "RHSA-2019:1619",
+				refs: []string{
+					"https://access.redhat.com/errata/RHSA-2019:1619",
+					"https://www.redhat.com/security/data/oval/v2/",
+				},
+				source: vulnerability.RedHatOVAL,
+			},
+			want: "https://access.redhat.com/errata/RHSA-2019:1619",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -361,6 +375,8 @@ func TestClient_getPrimaryURL(t *testing.T) {
 
 func TestClient_Filter(t *testing.T) {
 	type args struct {
+		target        string
+		resultType    string
 		vulns         []types.DetectedVulnerability
 		misconfs      []types.DetectedMisconfiguration
 		secrets       []ftypes.SecretFinding
@@ -368,14 +384,23 @@ func TestClient_Filter(t *testing.T) {
 		ignoreUnfixed bool
 		ignoreFile    string
 		policyFile    string
+		sla           SLAConfig
+		onlyOverdue   bool
+
+		secretSeverities SecretSeverities
+
+		licenses      []types.DetectedLicense
+		licenseConfig licensing.Config
 	}
 	tests := []struct {
 		name               string
 		args               args
 		wantVulns          []types.DetectedVulnerability
+		wantIgnoredVulns   []types.IgnoredVulnerability
 		wantMisconfSummary *types.MisconfSummary
 		wantMisconfs       []types.DetectedMisconfiguration
 		wantSecrets        []ftypes.SecretFinding
+		wantLicenses       []types.DetectedLicense
 	}{
 		{
 			name: "happy path",
@@ -617,6 +642,229 @@ func TestClient_Filter(t *testing.T) {
 					},
 				},
 			},
+			wantIgnoredVulns: []types.IgnoredVulnerability{
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0001",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source: "testdata/.trivyignore",
+				},
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0002",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source: "testdata/.trivyignore",
+				},
+			},
+		},
+		{
+			name: "happy path with a structured ignore file",
+			args: args{
+				target:     "vendor/package-lock.json",
+				resultType: ftypes.Npm,
+				vulns: []types.DetectedVulnerability{
+					{
+						// this vulnerability is ignored
+						VulnerabilityID:  "CVE-2019-0001",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					{
+						// the ignore rule is expired
+						VulnerabilityID:  "CVE-2019-0002",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					{
+						// this vulnerability is ignored
+						VulnerabilityID:  "CVE-2019-0003",
+						PkgName:          "@babel/core",
+						InstalledVersion: "7.0.0",
+						FixedVersion:     "7.0.1",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					{
+						// the package version doesn't match
+						VulnerabilityID:  "CVE-2019-0003",
+						PkgName:          "@babel/core",
+						InstalledVersion: "6.0.0",
+						FixedVersion:     "7.0.1",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+				},
+				misconfs: []types.DetectedMisconfiguration{
+					{
+						Type:     ftypes.Kubernetes,
+						ID:       "ID100",
+						Title:    "Bad Deployment",
+						Message:  "something bad",
+						Severity: dbTypes.SeverityLow.String(),
+						Status:   types.StatusFailure,
+					},
+				},
+				secrets: []ftypes.SecretFinding{
+					{
+						RuleID:   "generic-wanted-rule",
+						Severity: dbTypes.SeverityLow.String(),
+						Title:    "Secret that should pass filter on rule id",
+					},
+					{
+						RuleID:   "generic-unwanted-rule",
+						Severity: dbTypes.SeverityLow.String(),
+						Title:    "Secret that should not pass filter on rule id",
+					},
+				},
+				severities: []dbTypes.Severity{dbTypes.SeverityLow},
+				ignoreFile: "testdata/.trivyignore.yaml",
+			},
+			wantVulns: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2019-0003",
+					PkgName:          "@babel/core",
+					InstalledVersion: "6.0.0",
+					FixedVersion:     "7.0.1",
+					Vulnerability: dbTypes.Vulnerability{
+						Severity: dbTypes.SeverityLow.String(),
+					},
+				},
+				{
+					VulnerabilityID:  "CVE-2019-0002",
+					PkgName:          "foo",
+					InstalledVersion: "1.2.3",
+					FixedVersion:     "1.2.4",
+					Vulnerability: dbTypes.Vulnerability{
+						Severity: dbTypes.SeverityLow.String(),
+					},
+				},
+			},
+			wantIgnoredVulns: []types.IgnoredVulnerability{
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0001",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source:        "testdata/.trivyignore.yaml",
+					Reason:        "test fixtures only",
+					Justification: "vulnerable_code_not_present",
+				},
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0003",
+						PkgName:          "@babel/core",
+						InstalledVersion: "7.0.0",
+						FixedVersion:     "7.0.1",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source: "testdata/.trivyignore.yaml",
+				},
+			},
+			wantMisconfSummary: &types.MisconfSummary{
+				Successes:  0,
+				Failures:   1,
+				Exceptions: 0,
+			},
+			wantMisconfs: []types.DetectedMisconfiguration{
+				{
+					Type:     ftypes.Kubernetes,
+					ID:       "ID100",
+					Title:    "Bad Deployment",
+					Message:  "something bad",
+					Severity: dbTypes.SeverityLow.String(),
+					Status:   types.StatusFailure,
+				},
+			},
+			wantSecrets: []ftypes.SecretFinding{
+				{
+					RuleID:   "generic-wanted-rule",
+					Severity: dbTypes.SeverityLow.String(),
+					Title:    "Secret that should pass filter on rule id",
+				},
+			},
+		},
+		{
+			name: "happy path with a structured ignore file and a recursive glob",
+			args: args{
+				target: "app/testdata/fixtures/package-lock.json",
+				vulns: []types.DetectedVulnerability{
+					{
+						VulnerabilityID:  "CVE-2019-0001",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					{
+						// this vulnerability is ignored
+						VulnerabilityID:  "CVE-2019-0004",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+				},
+				severities: []dbTypes.Severity{dbTypes.SeverityLow},
+				ignoreFile: "testdata/.trivyignore.yaml",
+			},
+			wantVulns: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2019-0001",
+					PkgName:          "foo",
+					InstalledVersion: "1.2.3",
+					FixedVersion:     "1.2.4",
+					Vulnerability: dbTypes.Vulnerability{
+						Severity: dbTypes.SeverityLow.String(),
+					},
+				},
+			},
+			wantIgnoredVulns: []types.IgnoredVulnerability{
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0004",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source: "testdata/.trivyignore.yaml",
+				},
+			},
 		},
 		{
 			name: "happy path with a policy file",
@@ -652,6 +900,25 @@ func TestClient_Filter(t *testing.T) {
 						},
 					},
 				},
+				misconfs: []types.DetectedMisconfiguration{
+					{
+						Type:     ftypes.Kubernetes,
+						ID:       "ID100",
+						Title:    "Bad Deployment",
+						Message:  "something bad",
+						Severity: dbTypes.SeverityLow.String(),
+						Status:   types.StatusFailure,
+					},
+					{
+						// this misconfiguration is ignored
+						Type:     ftypes.Kubernetes,
+						ID:       "ID200",
+						Title:    "Bad Pod",
+						Message:  "something bad",
+						Severity: dbTypes.SeverityLow.String(),
+						Status:   types.StatusFailure,
+					},
+				},
 				severities:    []dbTypes.Severity{dbTypes.SeverityLow},
 				ignoreUnfixed: false,
 				policyFile:    "./testdata/test.rego",
@@ -667,6 +934,47 @@ func TestClient_Filter(t *testing.T) {
 					},
 				},
 			},
+			wantIgnoredVulns: []types.IgnoredVulnerability{
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0002",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source: "./testdata/test.rego",
+				},
+				{
+					DetectedVulnerability: types.DetectedVulnerability{
+						VulnerabilityID:  "CVE-2019-0003",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						FixedVersion:     "1.2.4",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityLow.String(),
+						},
+					},
+					Source: "./testdata/test.rego",
+				},
+			},
+			wantMisconfSummary: &types.MisconfSummary{
+				Successes:  0,
+				Failures:   1,
+				Exceptions: 0,
+			},
+			wantMisconfs: []types.DetectedMisconfiguration{
+				{
+					Type:     ftypes.Kubernetes,
+					ID:       "ID100",
+					Title:    "Bad Deployment",
+					Message:  "something bad",
+					Severity: dbTypes.SeverityLow.String(),
+					Status:   types.StatusFailure,
+				},
+			},
 		},
 		{
 			name: "happy path with duplicates, one with empty fixed version",
@@ -796,17 +1104,156 @@ func TestClient_Filter(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "happy path with secret severity overrides",
+			args: args{
+				secrets: []ftypes.SecretFinding{
+					{
+						RuleID:   "aws-account-id",
+						Severity: dbTypes.SeverityMedium.String(),
+						Title:    "AWS Account ID",
+					},
+					{
+						RuleID:   "acme-token",
+						Severity: dbTypes.SeverityCritical.String(),
+						Title:    "Acme Token",
+					},
+				},
+				severities: []dbTypes.Severity{dbTypes.SeverityHigh, dbTypes.SeverityCritical},
+				secretSeverities: SecretSeverities{
+					"aws-account-id": dbTypes.SeverityHigh,
+					"acme-token":     dbTypes.SeverityLow,
+				},
+			},
+			wantVulns: []types.DetectedVulnerability{},
+			wantSecrets: []ftypes.SecretFinding{
+				{
+					RuleID:   "aws-account-id",
+					Severity: dbTypes.SeverityHigh.String(),
+					Title:    "AWS Account ID",
+				},
+			},
+		},
+		{
+			name: "happy path with a license policy",
+			args: args{
+				licenses: []types.DetectedLicense{
+					{PkgName: "musl", Name: "MIT"},
+					{PkgName: "readline", Name: "GPL-3.0-or-later"},
+					{PkgName: "left-pad", FilePath: "package.json", Name: "WTFPL"},
+					{PkgName: "acme", Name: "LicenseRef-Acme-Proprietary"},
+				},
+				severities: []dbTypes.Severity{dbTypes.SeverityHigh, dbTypes.SeverityCritical},
+				licenseConfig: licensing.Config{
+					Forbidden: []string{"GPL-3.0"},
+					Ignored:   []string{"LicenseRef-Acme-Proprietary"},
+				},
+			},
+			wantVulns: []types.DetectedVulnerability{},
+			wantLicenses: []types.DetectedLicense{
+				{
+					Severity: dbTypes.SeverityCritical.String(),
+					Category: "forbidden",
+					PkgName:  "readline",
+					Name:     "GPL-3.0-or-later",
+				},
+				{
+					Severity: dbTypes.SeverityCritical.String(),
+					Category: "forbidden",
+					PkgName:  "left-pad",
+					FilePath: "package.json",
+					Name:     "WTFPL",
+				},
+			},
+		},
+		{
+			name: "happy path with only overdue vulnerabilities",
+			args: args{
+				vulns: []types.DetectedVulnerability{
+					{
+						VulnerabilityID:  "CVE-2019-0001",
+						PkgName:          "foo",
+						InstalledVersion: "1.2.3",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity:      dbTypes.SeverityCritical.String(),
+							PublishedDate: utils.MustTimeParse("2019-01-01T00:00:00Z"),
+						},
+					},
+					{
+						VulnerabilityID:  "CVE-2019-0002",
+						PkgName:          "bar",
+						InstalledVersion: "1.2.3",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity: dbTypes.SeverityCritical.String(),
+						},
+					},
+					{
+						VulnerabilityID:  "CVE-2019-0003",
+						PkgName:          "baz",
+						InstalledVersion: "1.2.3",
+						Vulnerability: dbTypes.Vulnerability{
+							Severity:      dbTypes.SeverityLow.String(),
+							PublishedDate: utils.MustTimeParse("2019-01-01T00:00:00Z"),
+						},
+					},
+				},
+				severities: []dbTypes.Severity{dbTypes.SeverityCritical, dbTypes.SeverityLow},
+				sla: SLAConfig{
+					dbTypes.SeverityCritical: 7 * 24 * time.Hour,
+				},
+				onlyOverdue: true,
+			},
+			wantVulns: []types.DetectedVulnerability{
+				{
+					VulnerabilityID:  "CVE-2019-0001",
+					PkgName:          "foo",
+					InstalledVersion: "1.2.3",
+					SLA: &types.SLAStatus{
+						DueDate: time.Date(2019, 1, 8, 0, 0, 0, 0, time.UTC),
+						Overdue: true,
+					},
+					Vulnerability: dbTypes.Vulnerability{
+						Severity:      dbTypes.SeverityCritical.String(),
+						PublishedDate: utils.MustTimeParse("2019-01-01T00:00:00Z"),
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ignoreConf, err := ParseIgnoreFile(tt.args.ignoreFile)
+			require.NoError(t, err)
+
+			classifier, err := licensing.NewClassifier(tt.args.licenseConfig)
+			require.NoError(t, err)
+
 			c := Client{}
-			gotVulns, gotMisconfSummary, gotMisconfs, gotSecrets, err := c.Filter(context.Background(), tt.args.vulns, tt.args.misconfs, tt.args.secrets,
-				tt.args.severities, tt.args.ignoreUnfixed, false, tt.args.ignoreFile, tt.args.policyFile)
+			got, err := c.Filter(context.Background(), types.Result{
+				Target:            tt.args.target,
+				Type:              tt.args.resultType,
+				Vulnerabilities:   tt.args.vulns,
+				Misconfigurations: tt.args.misconfs,
+				Secrets:           tt.args.secrets,
+				Licenses:          tt.args.licenses,
+			}, FilterOption{
+				Severities:    tt.args.severities,
+				IgnoreUnfixed: tt.args.ignoreUnfixed,
+				IgnoreConfig:  ignoreConf,
+				PolicyFile:    tt.args.policyFile,
+				SLA:           tt.args.sla,
+				OnlyOverdue:   tt.args.onlyOverdue,
+
+				SecretSeverities:  tt.args.secretSeverities,
+				LicenseClassifier: classifier,
+			})
 			require.NoError(t, err)
-			assert.Equal(t, tt.wantVulns, gotVulns)
-			assert.Equal(t, tt.wantMisconfSummary, gotMisconfSummary)
-			assert.Equal(t, tt.wantMisconfs, gotMisconfs)
-			assert.Equal(t, tt.wantSecrets, gotSecrets)
+			assert.Equal(t, tt.wantVulns, got.Vulnerabilities)
+			assert.Equal(t, tt.wantIgnoredVulns, got.IgnoredVulnerabilities)
+			assert.Equal(t, tt.wantMisconfSummary, got.MisconfSummary)
+			assert.Equal(t, tt.wantMisconfs, got.Misconfigurations)
+			assert.Equal(t, tt.wantSecrets, got.Secrets)
+			assert.Equal(t, tt.wantLicenses, got.Licenses)
 		})
 	}
 }
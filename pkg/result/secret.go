@@ -0,0 +1,66 @@
+package result
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+// SecretSeverities overrides the severities of the secret rules by the rule IDs, e.g. HIGH for aws-account-id
+type SecretSeverities map[string]dbTypes.Severity
+
+// ParseSecretConfig parses the severity overrides in the config of the secret scanner.
+// The rules and the allow rules in the file are loaded by the secret scanner, e.g.
+//
+//	severity-overrides:
+//	  aws-account-id: HIGH
+//	  stripe-access-token: LOW
+func ParseSecretConfig(configPath string) (SecretSeverities, error) {
+	if configPath == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The secret scanner uses only the builtin rules without the config
+		return nil, nil
+	} else if err != nil {
+		return nil, xerrors.Errorf("file read error: %w", err)
+	}
+
+	var raw struct {
+		SeverityOverrides map[string]string `yaml:"severity-overrides"`
+	}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, xerrors.Errorf("YAML decode error: %w", err)
+	}
+	if len(raw.SeverityOverrides) == 0 {
+		return nil, nil
+	}
+
+	severities := SecretSeverities{}
+	for ruleID, severity := range raw.SeverityOverrides {
+		s, err := dbTypes.NewSeverity(strings.ToUpper(severity))
+		if err != nil {
+			return nil, xerrors.Errorf("invalid severity %q of %s: %w", severity, ruleID, err)
+		}
+		severities[ruleID] = s
+	}
+	return severities, nil
+}
+
+// override overwrites the severities of the secrets found by the rules, before they are filtered by the severities
+func (s SecretSeverities) override(secrets []ftypes.SecretFinding) {
+	for i, secret := range secrets {
+		if severity, ok := s[secret.RuleID]; ok {
+			secrets[i].Severity = severity.String()
+		}
+	}
+}
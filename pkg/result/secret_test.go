@@ -0,0 +1,56 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+func TestParseSecretConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		want       SecretSeverities
+		wantErr    string
+	}{
+		{
+			name:       "happy path",
+			configPath: "testdata/secret/trivy-secret.yaml",
+			want: SecretSeverities{
+				"aws-account-id": dbTypes.SeverityHigh,
+				"acme-token":     dbTypes.SeverityLow,
+			},
+		},
+		{
+			name:       "no overrides",
+			configPath: "testdata/secret/no-overrides.yaml",
+		},
+		{
+			name:       "no file",
+			configPath: "testdata/secret/unknown.yaml",
+		},
+		{
+			name: "no config",
+		},
+		{
+			name:       "sad path: unknown severity",
+			configPath: "testdata/secret/invalid-severity.yaml",
+			wantErr:    `invalid severity "SEVERE" of aws-account-id`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSecretConfig(tt.configPath)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
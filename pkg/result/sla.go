@@ -0,0 +1,112 @@
+package result
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// SLAConfig holds the time allowed to fix vulnerabilities per severity, e.g. 7 days for CRITICAL
+type SLAConfig map[dbTypes.Severity]time.Duration
+
+// ParseSLAFile parses the SLA file, e.g.
+//
+//	CRITICAL: 7d
+//	HIGH: 30d
+func ParseSLAFile(slaFile string) (SLAConfig, error) {
+	if slaFile == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(slaFile)
+	if err != nil {
+		return nil, xerrors.Errorf("file read error: %w", err)
+	}
+
+	var raw map[string]string
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, xerrors.Errorf("YAML decode error: %w", err)
+	}
+
+	conf := SLAConfig{}
+	for severity, period := range raw {
+		s, err := dbTypes.NewSeverity(strings.ToUpper(severity))
+		if err != nil {
+			return nil, xerrors.Errorf("invalid severity %q: %w", severity, err)
+		}
+		d, err := parseSLAPeriod(period)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid period for %s: %w", s, err)
+		}
+		conf[s] = d
+	}
+	return conf, nil
+}
+
+// parseSLAPeriod parses the period in days, e.g. "30d", or in the Go duration, e.g. "72h"
+func parseSLAPeriod(s string) (time.Duration, error) {
+	var d time.Duration
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, xerrors.Errorf("invalid days %q: %w", s, err)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, xerrors.Errorf("invalid duration %q: %w", s, err)
+		}
+	}
+	if d <= 0 {
+		return 0, xerrors.Errorf("the period must be positive: %s", s)
+	}
+	return d, nil
+}
+
+// annotate fills the due dates of the vulnerabilities.
+// The period starts when the vulnerability is published, and the vulnerabilities without the published date have no due date.
+func (c SLAConfig) annotate(vulns []types.DetectedVulnerability, now time.Time) {
+	for i, vuln := range vulns {
+		period, ok := c[severityOf(vuln)]
+		if !ok {
+			continue
+		} else if vuln.PublishedDate == nil {
+			log.Logger.Debugf("%s has no published date and no due date is given", vuln.VulnerabilityID)
+			continue
+		}
+
+		dueDate := vuln.PublishedDate.Add(period)
+		vulns[i].SLA = &types.SLAStatus{
+			DueDate: dueDate,
+			Overdue: now.After(dueDate),
+		}
+	}
+}
+
+func severityOf(vuln types.DetectedVulnerability) dbTypes.Severity {
+	s, err := dbTypes.NewSeverity(vuln.Severity)
+	if err != nil {
+		return dbTypes.SeverityUnknown
+	}
+	return s
+}
+
+// filterOverdue returns only the vulnerabilities past the due dates
+func filterOverdue(vulns []types.DetectedVulnerability) []types.DetectedVulnerability {
+	var overdue []types.DetectedVulnerability
+	for _, vuln := range vulns {
+		if vuln.SLA != nil && vuln.SLA.Overdue {
+			overdue = append(overdue, vuln)
+		}
+	}
+	return overdue
+}
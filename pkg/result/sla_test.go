@@ -0,0 +1,126 @@
+package result
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy-db/pkg/utils"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestParseSLAFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		slaFile string
+		want    SLAConfig
+		wantErr string
+	}{
+		{
+			name:    "happy path",
+			slaFile: "testdata/sla/sla.yaml",
+			want: SLAConfig{
+				dbTypes.SeverityCritical: 7 * 24 * time.Hour,
+				dbTypes.SeverityHigh:     30 * 24 * time.Hour,
+				dbTypes.SeverityMedium:   90 * 24 * time.Hour,
+			},
+		},
+		{
+			name: "no file",
+		},
+		{
+			name:    "sad path: unknown severity",
+			slaFile: "testdata/sla/invalid-severity.yaml",
+			wantErr: `invalid severity "SEVERE"`,
+		},
+		{
+			name:    "sad path: invalid period",
+			slaFile: "testdata/sla/invalid-period.yaml",
+			wantErr: "invalid period for CRITICAL",
+		},
+		{
+			name:    "sad path: not found",
+			slaFile: "testdata/sla/unknown.yaml",
+			wantErr: "file read error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSLAFile(tt.slaFile)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSLAConfig_annotate(t *testing.T) {
+	conf := SLAConfig{
+		dbTypes.SeverityCritical: 7 * 24 * time.Hour,
+		dbTypes.SeverityHigh:     30 * 24 * time.Hour,
+	}
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		vuln types.DetectedVulnerability
+		want *types.SLAStatus
+	}{
+		{
+			name: "overdue",
+			vuln: types.DetectedVulnerability{
+				Vulnerability: dbTypes.Vulnerability{
+					Severity:      dbTypes.SeverityCritical.String(),
+					PublishedDate: utils.MustTimeParse("2022-05-01T00:00:00Z"),
+				},
+			},
+			want: &types.SLAStatus{
+				DueDate: time.Date(2022, 5, 8, 0, 0, 0, 0, time.UTC),
+				Overdue: true,
+			},
+		},
+		{
+			name: "not overdue",
+			vuln: types.DetectedVulnerability{
+				Vulnerability: dbTypes.Vulnerability{
+					Severity:      dbTypes.SeverityHigh.String(),
+					PublishedDate: utils.MustTimeParse("2022-05-15T00:00:00Z"),
+				},
+			},
+			want: &types.SLAStatus{
+				DueDate: time.Date(2022, 6, 14, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "no SLA for the severity",
+			vuln: types.DetectedVulnerability{
+				Vulnerability: dbTypes.Vulnerability{
+					Severity:      dbTypes.SeverityLow.String(),
+					PublishedDate: utils.MustTimeParse("2022-05-01T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "no published date",
+			vuln: types.DetectedVulnerability{
+				Vulnerability: dbTypes.Vulnerability{
+					Severity: dbTypes.SeverityCritical.String(),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vulns := []types.DetectedVulnerability{tt.vuln}
+			conf.annotate(vulns, now)
+			assert.Equal(t, tt.want, vulns[0].SLA)
+		})
+	}
+}
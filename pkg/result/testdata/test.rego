@@ -3,3 +3,7 @@ package trivy
 ignore {
 	input.VulnerabilityID != "CVE-2019-0001"
 }
+
+ignore {
+	input.ID == "ID200"
+}
@@ -0,0 +1,246 @@
+package resultstore
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	_ "modernc.org/sqlite" // the SQLite driver
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// SQLitePrefix is the prefix of the result store in a SQLite database, e.g. sqlite:///var/lib/trivy/results.db
+const SQLitePrefix = "sqlite://"
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS scans (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		artifact_name TEXT NOT NULL,
+		scanned_at INTEGER NOT NULL,
+		os TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS scans_artifact_name ON scans (artifact_name, scanned_at)`,
+	`CREATE TABLE IF NOT EXISTS vulnerabilities (
+		scan_id INTEGER NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+		vulnerability_id TEXT NOT NULL,
+		pkg_name TEXT NOT NULL,
+		installed_version TEXT NOT NULL,
+		fixed_version TEXT NOT NULL,
+		severity TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS vulnerabilities_vulnerability_id ON vulnerabilities (vulnerability_id)`,
+	`CREATE INDEX IF NOT EXISTS vulnerabilities_scan_id ON vulnerabilities (scan_id)`,
+}
+
+// Store persists the summaries of the scans per artifact, so that the trends and
+// when the vulnerabilities first appeared in the artifacts can be queried later.
+// The placeholders in the queries are numbered so that they are portable to other databases.
+type Store struct {
+	db *sql.DB
+}
+
+// ScanSummary is the summary of a scan
+type ScanSummary struct {
+	ArtifactName string
+	ScannedAt    time.Time
+	OS           string
+
+	// Vulnerabilities is the number of the vulnerabilities per severity
+	Vulnerabilities map[string]int
+}
+
+// Appearance is when the vulnerability was seen in the artifact
+type Appearance struct {
+	ArtifactName    string
+	VulnerabilityID string
+	Severity        string
+	PkgNames        []string
+	FirstSeen       time.Time
+	LastSeen        time.Time
+
+	// Open is true if the vulnerability is found by the latest scan of the artifact
+	Open bool
+}
+
+// Open opens the result store, creating the tables if they don't exist
+func Open(dsn string) (*Store, error) {
+	path := strings.TrimPrefix(dsn, SQLitePrefix)
+	if path == dsn || path == "" {
+		return nil, xerrors.Errorf("unsupported result store %q, must be %s/path/to/file", dsn, SQLitePrefix)
+	}
+
+	// The pragmas are applied to every connection in the pool
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open %s: %w", path, err)
+	}
+	for _, stmt := range schema {
+		if _, err = db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, xerrors.Errorf("failed to create the tables: %w", err)
+		}
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record stores the summary of the report and the vulnerabilities found in it
+func (s *Store) Record(ctx context.Context, report types.Report, scannedAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return xerrors.Errorf("failed to begin a transaction: %w", err)
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	var os string
+	if report.Metadata.OS != nil {
+		os = strings.TrimSpace(report.Metadata.OS.Family + " " + report.Metadata.OS.Name)
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO scans (artifact_name, scanned_at, os) VALUES ($1, $2, $3)`,
+		report.ArtifactName, scannedAt.Unix(), os)
+	if err != nil {
+		return xerrors.Errorf("failed to insert the scan: %w", err)
+	}
+	scanID, err := res.LastInsertId()
+	if err != nil {
+		return xerrors.Errorf("failed to get the scan ID: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO vulnerabilities
+		(scan_id, vulnerability_id, pkg_name, installed_version, fixed_version, severity) VALUES ($1, $2, $3, $4, $5, $6)`)
+	if err != nil {
+		return xerrors.Errorf("failed to prepare the statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			_, err = stmt.ExecContext(ctx, scanID, vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion,
+				vuln.FixedVersion, vuln.Severity)
+			if err != nil {
+				return xerrors.Errorf("failed to insert %s: %w", vuln.VulnerabilityID, err)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return xerrors.Errorf("failed to commit the transaction: %w", err)
+	}
+	return nil
+}
+
+// ListScans returns the summaries of the scans of the artifact since the time, ordered by the scan time
+func (s *Store) ListScans(ctx context.Context, artifactName string, since time.Time) ([]ScanSummary, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT s.id, s.scanned_at, s.os, v.severity, COUNT(v.scan_id)
+		FROM scans s LEFT JOIN vulnerabilities v ON v.scan_id = s.id
+		WHERE s.artifact_name = $1 AND s.scanned_at >= $2
+		GROUP BY s.id, s.scanned_at, s.os, v.severity
+		ORDER BY s.scanned_at, s.id`, artifactName, since.Unix())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to query the scans: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []ScanSummary
+	var lastID int64
+	for rows.Next() {
+		var id, scannedAt int64
+		var os string
+		var severity sql.NullString
+		var count int
+		if err = rows.Scan(&id, &scannedAt, &os, &severity, &count); err != nil {
+			return nil, xerrors.Errorf("failed to read the scans: %w", err)
+		}
+		if len(summaries) == 0 || id != lastID {
+			summaries = append(summaries, ScanSummary{
+				ArtifactName:    artifactName,
+				ScannedAt:       time.Unix(scannedAt, 0).UTC(),
+				OS:              os,
+				Vulnerabilities: map[string]int{},
+			})
+			lastID = id
+		}
+		if severity.Valid {
+			summaries[len(summaries)-1].Vulnerabilities[severity.String] = count
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, xerrors.Errorf("failed to read the scans: %w", err)
+	}
+	return summaries, nil
+}
+
+// FindVulnerability returns when the vulnerability first and last appeared in each artifact.
+// All the artifacts are searched if artifactName is empty.
+func (s *Store) FindVulnerability(ctx context.Context, vulnID, artifactName string) ([]Appearance, error) {
+	query := `SELECT s.artifact_name, s.scanned_at, v.severity, v.pkg_name,
+			s.scanned_at = (SELECT MAX(l.scanned_at) FROM scans l WHERE l.artifact_name = s.artifact_name)
+		FROM vulnerabilities v JOIN scans s ON s.id = v.scan_id
+		WHERE v.vulnerability_id = $1`
+	args := []interface{}{vulnID}
+	if artifactName != "" {
+		query += ` AND s.artifact_name = $2`
+		args = append(args, artifactName)
+	}
+	query += ` ORDER BY s.artifact_name, s.scanned_at`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to query %s: %w", vulnID, err)
+	}
+	defer rows.Close()
+
+	var appearances []Appearance
+	for rows.Next() {
+		var name, severity, pkgName string
+		var scannedAt int64
+		var latest bool
+		if err = rows.Scan(&name, &scannedAt, &severity, &pkgName, &latest); err != nil {
+			return nil, xerrors.Errorf("failed to read %s: %w", vulnID, err)
+		}
+
+		seen := time.Unix(scannedAt, 0).UTC()
+		if len(appearances) == 0 || appearances[len(appearances)-1].ArtifactName != name {
+			appearances = append(appearances, Appearance{
+				ArtifactName:    name,
+				VulnerabilityID: vulnID,
+				FirstSeen:       seen,
+			})
+		}
+		a := &appearances[len(appearances)-1]
+		a.Severity = maxSeverity(a.Severity, severity)
+		a.LastSeen = seen
+		a.Open = a.Open || latest
+		if !slices.Contains(a.PkgNames, pkgName) {
+			a.PkgNames = append(a.PkgNames, pkgName)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, xerrors.Errorf("failed to read %s: %w", vulnID, err)
+	}
+
+	for i := range appearances {
+		sort.Strings(appearances[i].PkgNames)
+	}
+	return appearances, nil
+}
+
+// maxSeverity returns the higher severity, as the severity can be different per package
+func maxSeverity(a, b string) string {
+	sa, _ := dbTypes.NewSeverity(a) // nolint: errcheck
+	sb, _ := dbTypes.NewSeverity(b) // nolint: errcheck
+	if a == "" || sb > sa {
+		return b
+	}
+	return a
+}
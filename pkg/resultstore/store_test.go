@@ -0,0 +1,156 @@
+package resultstore_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/resultstore"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func vuln(id, pkgName, severity string) types.DetectedVulnerability {
+	return types.DetectedVulnerability{
+		VulnerabilityID:  id,
+		PkgName:          pkgName,
+		InstalledVersion: "1.0",
+		Vulnerability:    dbTypes.Vulnerability{Severity: severity},
+	}
+}
+
+func report(artifactName string, vulns ...types.DetectedVulnerability) types.Report {
+	return types.Report{
+		ArtifactName: artifactName,
+		Metadata: types.Metadata{
+			OS: &ftypes.OS{Family: "alpine", Name: "3.15.4"},
+		},
+		Results: types.Results{
+			{
+				Target:          artifactName + " (alpine 3.15.4)",
+				Vulnerabilities: vulns,
+			},
+		},
+	}
+}
+
+func newStore(t *testing.T) *resultstore.Store {
+	s, err := resultstore.Open("sqlite://" + filepath.Join(t.TempDir(), "results.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	day := func(d int) time.Time {
+		return time.Date(2022, 5, d, 0, 0, 0, 0, time.UTC)
+	}
+	ctx := context.Background()
+	require.NoError(t, s.Record(ctx, report("myapp:1.0", vuln("CVE-2022-0001", "openssl", "HIGH")), day(1)))
+	require.NoError(t, s.Record(ctx, report("myapp:1.0",
+		vuln("CVE-2022-0001", "openssl", "HIGH"),
+		vuln("CVE-2022-0001", "libssl", "CRITICAL"),
+		vuln("CVE-2022-0002", "musl", "LOW"),
+	), day(2)))
+	require.NoError(t, s.Record(ctx, report("myapp:1.0", vuln("CVE-2022-0002", "musl", "LOW")), day(3)))
+	require.NoError(t, s.Record(ctx, report("batch:1.0"), day(2)))
+	require.NoError(t, s.Record(ctx, report("batch:1.0", vuln("CVE-2022-0001", "openssl", "HIGH")), day(4)))
+	return s
+}
+
+func TestStore_ListScans(t *testing.T) {
+	s := newStore(t)
+
+	got, err := s.ListScans(context.Background(), "myapp:1.0", time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []resultstore.ScanSummary{
+		{
+			ArtifactName:    "myapp:1.0",
+			ScannedAt:       time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC),
+			OS:              "alpine 3.15.4",
+			Vulnerabilities: map[string]int{"CRITICAL": 1, "HIGH": 1, "LOW": 1},
+		},
+		{
+			ArtifactName:    "myapp:1.0",
+			ScannedAt:       time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC),
+			OS:              "alpine 3.15.4",
+			Vulnerabilities: map[string]int{"LOW": 1},
+		},
+	}, got)
+
+	got, err = s.ListScans(context.Background(), "batch:1.0", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Empty(t, got[0].Vulnerabilities)
+}
+
+func TestStore_FindVulnerability(t *testing.T) {
+	tests := []struct {
+		name         string
+		vulnID       string
+		artifactName string
+		want         []resultstore.Appearance
+	}{
+		{
+			name:   "all artifacts",
+			vulnID: "CVE-2022-0001",
+			want: []resultstore.Appearance{
+				{
+					ArtifactName:    "batch:1.0",
+					VulnerabilityID: "CVE-2022-0001",
+					Severity:        "HIGH",
+					PkgNames:        []string{"openssl"},
+					FirstSeen:       time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC),
+					LastSeen:        time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC),
+					Open:            true,
+				},
+				{
+					ArtifactName:    "myapp:1.0",
+					VulnerabilityID: "CVE-2022-0001",
+					Severity:        "CRITICAL",
+					PkgNames:        []string{"libssl", "openssl"},
+					FirstSeen:       time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC),
+					LastSeen:        time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC),
+					Open:            false,
+				},
+			},
+		},
+		{
+			name:         "an artifact",
+			vulnID:       "CVE-2022-0002",
+			artifactName: "myapp:1.0",
+			want: []resultstore.Appearance{
+				{
+					ArtifactName:    "myapp:1.0",
+					VulnerabilityID: "CVE-2022-0002",
+					Severity:        "LOW",
+					PkgNames:        []string{"musl"},
+					FirstSeen:       time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC),
+					LastSeen:        time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC),
+					Open:            true,
+				},
+			},
+		},
+		{
+			name:         "never seen",
+			vulnID:       "CVE-2022-0002",
+			artifactName: "batch:1.0",
+		},
+	}
+
+	s := newStore(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.FindVulnerability(context.Background(), tt.vulnID, tt.artifactName)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	_, err := resultstore.Open("postgres://localhost/trivy")
+	assert.ErrorContains(t, err, `unsupported result store "postgres://localhost/trivy"`)
+}
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/secret"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/handler"
+	ftypes "github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	r "github.com/aquasecurity/trivy/pkg/rpc"
+)
+
+// layerPathPrefix must be the same as LayerPathPrefix of the server
+const layerPathPrefix = "/layers/"
+
+// ServerSideArtifact inspects the image in the same way as the image artifact of fanal, but the layers are analyzed
+// on the server. Only the image config is analyzed on the client, so that tiny CI runners can scan large images.
+type ServerSideArtifact struct {
+	image          ftypes.Image
+	cache          cache.ArtifactCache
+	analyzer       analyzer.AnalyzerGroup
+	handlerManager handler.Manager
+	artifactOption artifact.Option
+
+	server     *url.URL
+	headers    http.Header
+	httpClient *http.Client
+	retry      r.RetryOption
+}
+
+// NewServerSideArtifact returns the artifact uploading the layers of the image to the server
+func NewServerSideArtifact(img ftypes.Image, c cache.ArtifactCache, option ScannerOption,
+	opt tartifact.Option) (artifact.Artifact, error) {
+	// The limits of the analysis are the ones of the server
+	artifactOpt := opt.Option
+
+	// The server doesn't have the policies of the client
+	if len(artifactOpt.MisconfScannerOption.Namespaces) > 0 {
+		return nil, xerrors.New("misconfiguration scanning is not supported with the server-side analysis")
+	}
+
+	// The server analyzes the layers only with the file names the analyzers expect,
+	// and the blob IDs would differ from the server with the patterns
+	if len(artifactOpt.MisconfScannerOption.FilePatterns) > 0 {
+		return nil, xerrors.New("'--file-patterns' is not supported with the server-side analysis")
+	}
+
+	if _, err := os.Stat(artifactOpt.SecretScannerOption.ConfigPath); err == nil {
+		log.Logger.Warnf("%s is not used since secrets are detected with the builtin rules of the server",
+			artifactOpt.SecretScannerOption.ConfigPath)
+	}
+
+	// The blob IDs are calculated with the same options as the server
+	artifactOpt = normalizeOption(artifactOpt)
+
+	// The secret analyzer must be registered so that the blob IDs are the same as the server
+	if err := secret.RegisterSecretAnalyzer(artifactOpt.SecretScannerOption); err != nil {
+		return nil, xerrors.Errorf("secret scanner error: %w", err)
+	}
+
+	handlerManager, err := handler.NewManager(artifactOpt)
+	if err != nil {
+		return nil, xerrors.Errorf("handler init error: %w", err)
+	}
+
+	u, err := url.Parse(option.ServerURL())
+	if err != nil {
+		return nil, xerrors.Errorf("invalid server URL: %w", err)
+	}
+
+	return ServerSideArtifact{
+		image:          img,
+		cache:          c,
+		analyzer:       analyzer.NewAnalyzerGroup(artifactOpt.AnalyzerGroup, artifactOpt.DisabledAnalyzers),
+		handlerManager: handlerManager,
+		artifactOption: artifactOpt,
+
+		server:     u,
+		headers:    option.CustomHeaders,
+		httpClient: option.HTTPClient(),
+		retry:      option.Retry,
+	}, nil
+}
+
+func (a ServerSideArtifact) Inspect(ctx context.Context) (ftypes.ArtifactReference, error) {
+	imageID, err := a.image.ID()
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to get the image ID: %w", err)
+	}
+
+	diffIDs, err := a.image.LayerIDs()
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to get layer IDs: %w", err)
+	}
+
+	configFile, err := a.image.ConfigFile()
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to get the image's config file: %w", err)
+	}
+
+	imageKey, err := cache.CalcKey(imageID, a.analyzer.ImageConfigAnalyzerVersions(), nil, artifact.Option{})
+	if err != nil {
+		return ftypes.ArtifactReference{}, err
+	}
+
+	layerKeyMap := map[string]string{}
+	var layerKeys []string
+	for _, diffID := range diffIDs {
+		key, err := cache.CalcKey(diffID, a.analyzer.AnalyzerVersions(), a.handlerManager.Versions(), a.artifactOption)
+		if err != nil {
+			return ftypes.ArtifactReference{}, err
+		}
+		layerKeys = append(layerKeys, key)
+		layerKeyMap[key] = diffID
+	}
+
+	missingImage, missingLayers, err := a.cache.MissingBlobs(imageKey, layerKeys)
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to get missing layers: %w", err)
+	}
+
+	// Layers are uploaded one by one so that the bandwidth of the client is not exhausted
+	baseDiffIDs := tartifact.GuessBaseLayers(diffIDs, configFile)
+	var osFound ftypes.OS
+	for _, key := range missingLayers {
+		diffID := layerKeyMap[key]
+		log.Logger.Infof("Uploading the layer %s to the server for analysis...", diffID)
+		found, err := a.uploadLayer(ctx, key, diffID, slices.Contains(baseDiffIDs, diffID))
+		if err != nil {
+			return ftypes.ArtifactReference{}, xerrors.Errorf("failed to analyze layer: %s : %w", diffID, err)
+		}
+		if found != nil {
+			osFound = *found
+		}
+	}
+
+	if missingImage {
+		if err = a.inspectConfig(imageKey, osFound); err != nil {
+			return ftypes.ArtifactReference{}, xerrors.Errorf("unable to analyze config: %w", err)
+		}
+	}
+
+	return ftypes.ArtifactReference{
+		Name:    a.image.Name(),
+		Type:    ftypes.ArtifactContainerImage,
+		ID:      imageKey,
+		BlobIDs: layerKeys,
+		ImageMetadata: ftypes.ImageMetadata{
+			ID:          imageID,
+			DiffIDs:     diffIDs,
+			RepoTags:    a.image.RepoTags(),
+			RepoDigests: a.image.RepoDigests(),
+			ConfigFile:  *configFile,
+		},
+	}, nil
+}
+
+func (ServerSideArtifact) Clean(_ ftypes.ArtifactReference) error {
+	return nil
+}
+
+// uploadLayer streams the uncompressed layer to the server, and returns the OS detected in the layer
+func (a ServerSideArtifact) uploadLayer(ctx context.Context, blobID, diffID string, baseLayer bool) (*ftypes.OS, error) {
+	h, err := v1.NewHash(diffID)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid layer ID (%s): %w", diffID, err)
+	}
+	layer, err := a.image.LayerByDiffID(h)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get the layer (%s): %w", diffID, err)
+	}
+
+	// digest is a hash of the compressed layer
+	var digest string
+	if tartifact.IsCompressed(layer) {
+		d, err := layer.Digest()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to get the digest (%s): %w", diffID, err)
+		}
+		digest = d.String()
+	}
+
+	query := url.Values{}
+	query.Set("diff_id", diffID)
+	query.Set("digest", digest)
+	query.Set("base_layer", strconv.FormatBool(baseLayer))
+	query.Set("offline", strconv.FormatBool(a.artifactOption.Offline))
+	query["skip_file"] = a.artifactOption.SkipFiles
+	query["skip_dir"] = a.artifactOption.SkipDirs
+	for _, t := range a.artifactOption.DisabledAnalyzers {
+		query.Add("disabled_analyzer", string(t))
+	}
+
+	u := *a.server
+	u.Path = strings.TrimSuffix(u.Path, "/") + layerPathPrefix + blobID
+	u.RawQuery = query.Encode()
+
+	var res layerResponse
+	// The server may be overloaded, and the layer is read again on retry
+	err = r.Retry(ctx, a.retry, func() error {
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return xerrors.Errorf("failed to get the layer content (%s): %w", diffID, err)
+		}
+		defer rc.Close()
+		return a.put(ctx, u.String(), rc, &res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.OS, nil
+}
+
+// normalizeOption returns the option in the same form as the server receives.
+// The empty lists are not sent, and the custom secret config is not used as the server detects secrets with the builtin rules.
+func normalizeOption(opt artifact.Option) artifact.Option {
+	if len(opt.SkipFiles) == 0 {
+		opt.SkipFiles = nil
+	}
+	if len(opt.SkipDirs) == 0 {
+		opt.SkipDirs = nil
+	}
+	opt.SecretScannerOption = secret.ScannerOption{}
+	return opt
+}
+
+// layerResponse must be the same as LayerResponse of the server
+type layerResponse struct {
+	OS *ftypes.OS `json:",omitempty"`
+}
+
+func (a ServerSideArtifact) put(ctx context.Context, endpoint string, body io.Reader, res *layerResponse) error {
+	// The layer is compressed while being sent, without being saved to the disk
+	pr, pw := io.Pipe()
+	go func() {
+		gw := gzip.NewWriter(pw)
+		_, err := io.Copy(gw, body)
+		if err == nil {
+			err = gw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	defer pr.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, pr)
+	if err != nil {
+		return xerrors.Errorf("HTTP request error: %w", err)
+	}
+	for k, v := range a.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("HTTP error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorFromResponse(resp)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return xerrors.Errorf("JSON decode error: %w", err)
+	}
+	return nil
+}
+
+// errorFromResponse returns the twirp error written by the server, so that the request is retried if necessary
+func errorFromResponse(resp *http.Response) error {
+	var e struct {
+		Code string            `json:"code"`
+		Msg  string            `json:"msg"`
+		Meta map[string]string `json:"meta"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || !twirp.IsValidErrorCode(twirp.ErrorCode(e.Code)) {
+		return twirp.NewError(twirp.Unknown, "unexpected HTTP status from the server: "+resp.Status)
+	}
+	twerr := twirp.NewError(twirp.ErrorCode(e.Code), e.Msg)
+	for k, v := range e.Meta {
+		twerr = twerr.WithMeta(k, v)
+	}
+	return twerr
+}
+
+func (a ServerSideArtifact) inspectConfig(imageID string, osFound ftypes.OS) error {
+	configBlob, err := a.image.RawConfigFile()
+	if err != nil {
+		return xerrors.Errorf("unable to get config blob: %w", err)
+	}
+
+	pkgs := a.analyzer.AnalyzeImageConfig(osFound, configBlob)
+
+	var s1 v1.ConfigFile
+	if err = json.Unmarshal(configBlob, &s1); err != nil {
+		return xerrors.Errorf("json marshal error: %w", err)
+	}
+
+	info := ftypes.ArtifactInfo{
+		SchemaVersion:   ftypes.ArtifactJSONSchemaVersion,
+		Architecture:    s1.Architecture,
+		Created:         s1.Created.Time,
+		DockerVersion:   s1.DockerVersion,
+		OS:              s1.OS,
+		HistoryPackages: pkgs,
+	}
+
+	if err = a.cache.PutArtifact(imageID, info); err != nil {
+		return xerrors.Errorf("failed to put image info into the cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	misconf "github.com/aquasecurity/fanal/analyzer/config"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/image"
+	ftypes "github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+)
+
+func TestServerSideArtifact_Inspect(t *testing.T) {
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	ref, err := name.ParseReference("app:1.0")
+	require.NoError(t, err)
+	imgPath := filepath.Join(t.TempDir(), "app.tar")
+	require.NoError(t, tarball.WriteToFile(imgPath, ref, img))
+
+	configFile, err := img.ConfigFile()
+	require.NoError(t, err)
+	var wantDiffIDs []string
+	for _, d := range configFile.RootFS.DiffIDs {
+		wantDiffIDs = append(wantDiffIDs, d.String())
+	}
+
+	tests := []struct {
+		name        string
+		artifactOpt artifact.Option
+		status      int
+		response    string
+		wantUploads int
+		wantErr     string
+	}{
+		{
+			name:        "happy path",
+			status:      http.StatusOK,
+			response:    `{"OS":{"Family":"alpine","Name":"3.10.4"}}`,
+			wantUploads: 2,
+		},
+		{
+			name: "sad path: misconfiguration",
+			artifactOpt: artifact.Option{
+				MisconfScannerOption: misconf.ScannerOption{
+					Namespaces: []string{"user"},
+				},
+			},
+			wantErr: "misconfiguration scanning is not supported",
+		},
+		{
+			name: "sad path: file patterns",
+			artifactOpt: artifact.Option{
+				MisconfScannerOption: misconf.ScannerOption{
+					FilePatterns: []string{`pip:requirements-.*\.txt`},
+				},
+			},
+			wantErr: "'--file-patterns' is not supported",
+		},
+		{
+			name:        "sad path: blob ID mismatch",
+			status:      http.StatusBadRequest,
+			response:    `{"code":"invalid_argument","msg":"blob_id the blob ID doesn't match"}`,
+			wantUploads: 1,
+			wantErr:     "the blob ID doesn't match",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var uploaded []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.True(t, strings.HasPrefix(r.URL.Path, "/layers/sha256:"), r.URL.Path)
+				assert.Equal(t, "test", r.Header.Get("Trivy-Token"))
+
+				// The uncompressed layer must match the diff ID
+				gr, err := gzip.NewReader(r.Body)
+				require.NoError(t, err)
+				h := sha256.New()
+				_, err = io.Copy(h, gr)
+				require.NoError(t, err)
+				diffID := r.URL.Query().Get("diff_id")
+				assert.Equal(t, diffID, fmt.Sprintf("sha256:%x", h.Sum(nil)))
+
+				mu.Lock()
+				uploaded = append(uploaded, diffID)
+				mu.Unlock()
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer ts.Close()
+
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			defer c.Close()
+
+			archive, err := image.NewArchiveImage(imgPath)
+			require.NoError(t, err)
+
+			a, err := NewServerSideArtifact(archive, c, ScannerOption{
+				RemoteURL:     ts.URL,
+				CustomHeaders: http.Header{"Trivy-Token": []string{"test"}},
+			}, tartifact.Option{Option: tt.artifactOpt})
+			if tt.wantErr != "" && tt.wantUploads == 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := a.Inspect(context.Background())
+			assert.Len(t, uploaded, tt.wantUploads)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ftypes.ArtifactContainerImage, got.Type)
+			assert.Equal(t, wantDiffIDs, got.ImageMetadata.DiffIDs)
+			assert.Len(t, got.BlobIDs, 2)
+
+			// The image config is analyzed on the client
+			_, err = c.GetArtifact(got.ID)
+			require.NoError(t, err)
+		})
+	}
+}
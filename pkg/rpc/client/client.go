@@ -3,14 +3,20 @@ package client
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"golang.org/x/xerrors"
 
 	ftypes "github.com/aquasecurity/fanal/types"
 	r "github.com/aquasecurity/trivy/pkg/rpc"
 	"github.com/aquasecurity/trivy/pkg/types"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
 	rpc "github.com/aquasecurity/trivy/rpc/scanner"
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
 )
 
 type options struct {
@@ -26,38 +32,119 @@ func WithRPCClient(c rpc.Scanner) Option {
 	}
 }
 
+const (
+	// UnixSocketScheme is the scheme of the server listening on the unix domain socket, e.g. unix:///var/run/trivy.sock
+	UnixSocketScheme = "unix://"
+
+	// unixSocketURL is the URL of the requests sent over the unix domain socket, where the host is not used
+	unixSocketURL = "http://unix"
+)
+
 // ScannerOption holds options for RPC client
 type ScannerOption struct {
+	// RemoteURL is the URL of the server, or the path of the unix domain socket prefixed with "unix://"
 	RemoteURL     string
 	Insecure      bool
+	RootCAs       *x509.CertPool
 	CustomHeaders http.Header
+
+	// Certificates are presented to the server requiring client certificates
+	Certificates []tls.Certificate
+
+	// Timeout is the timeout of each request to the server, and 0 means no timeout
+	Timeout time.Duration
+
+	// Retry configures the retries of the failed requests
+	Retry r.RetryOption
+
+	// Protocol is the protocol of the server, which is Twirp unless it is "grpc"
+	Protocol string
+}
+
+// ServerURL returns the base URL of the requests to the server
+func (o ScannerOption) ServerURL() string {
+	if _, ok := o.socketPath(); ok {
+		return unixSocketURL
+	}
+	return o.RemoteURL
+}
+
+// HTTPClient returns the HTTP client to send requests to the server
+func (o ScannerOption) HTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   o.Timeout,
+		Transport: o.transport(),
+	}
+}
+
+// NewScannerClient returns the client of the scanner over the protocol of the server
+func NewScannerClient(o ScannerOption) rpc.Scanner {
+	if o.Protocol == r.ProtocolGRPC {
+		return grpcScanner{newGRPCClient(o)}
+	}
+	return rpc.NewScannerProtobufClient(o.ServerURL(), o.HTTPClient())
+}
+
+// NewCacheClient returns the client of the cache over the protocol of the server
+func NewCacheClient(o ScannerOption) rpcCache.Cache {
+	if o.Protocol == r.ProtocolGRPC {
+		return grpcCache{newGRPCClient(o)}
+	}
+	return rpcCache.NewCacheProtobufClient(o.ServerURL(), o.HTTPClient())
+}
+
+// NewVersionClient returns the client of the version over the protocol of the server
+func NewVersionClient(o ScannerOption) rpcVersion.Version {
+	if o.Protocol == r.ProtocolGRPC {
+		return grpcVersion{newGRPCClient(o)}
+	}
+	return rpcVersion.NewVersionProtobufClient(o.ServerURL(), o.HTTPClient())
+}
+
+// transport returns the transport connecting to the server, which dials the unix domain socket instead of the host
+// of the URL if the server listens on it
+func (o ScannerOption) transport() *http.Transport {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: o.Insecure,
+			RootCAs:            o.RootCAs,
+			Certificates:       o.Certificates,
+		},
+	}
+	if path, ok := o.socketPath(); ok {
+		// The requests to the co-located server never go through the proxy
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+	}
+	return t
+}
+
+func (o ScannerOption) socketPath() (string, bool) {
+	if !strings.HasPrefix(o.RemoteURL, UnixSocketScheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(o.RemoteURL, UnixSocketScheme), true
 }
 
 // Scanner implements the RPC scanner
 type Scanner struct {
 	customHeaders http.Header
+	retry         r.RetryOption
 	client        rpc.Scanner
 }
 
 // NewScanner is the factory method to return RPC Scanner
 func NewScanner(scannerOptions ScannerOption, opts ...Option) Scanner {
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: scannerOptions.Insecure,
-			},
-		},
-	}
-
-	c := rpc.NewScannerProtobufClient(scannerOptions.RemoteURL, httpClient)
-
-	o := &options{rpcClient: c}
+	o := &options{rpcClient: NewScannerClient(scannerOptions)}
 	for _, opt := range opts {
 		opt(o)
 	}
 
-	return Scanner{customHeaders: scannerOptions.CustomHeaders, client: o.rpcClient}
+	return Scanner{customHeaders: scannerOptions.CustomHeaders, retry: scannerOptions.Retry, client: o.rpcClient}
 }
 
 // Scan scans the image
@@ -65,7 +152,7 @@ func (s Scanner) Scan(target, artifactKey string, blobKeys []string, options typ
 	ctx := WithCustomHeaders(context.Background(), s.customHeaders)
 
 	var res *rpc.ScanResponse
-	err := r.Retry(func() error {
+	err := r.Retry(ctx, s.retry, func() error {
 		var err error
 		res, err = s.client.Scan(ctx, &rpc.ScanRequest{
 			Target:     target,
@@ -75,6 +162,8 @@ func (s Scanner) Scan(target, artifactKey string, blobKeys []string, options typ
 				VulnType:        options.VulnType,
 				SecurityChecks:  options.SecurityChecks,
 				ListAllPackages: options.ListAllPackages,
+				Esm:             options.ESM,
+				Locale:          options.Locale,
 			},
 		})
 		return err
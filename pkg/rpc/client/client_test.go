@@ -4,8 +4,10 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/protobuf/ptypes/timestamp"
@@ -252,3 +254,26 @@ func TestScanner_ScanServerInsecure(t *testing.T) {
 		})
 	}
 }
+
+func TestScanner_ScanUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "trivy.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/twirp/trivy.scanner.v1.Scanner/Scan", r.URL.Path)
+		w.Header().Set("Content-Type", "application/protobuf")
+	}))
+	ts.Listener = l
+	ts.Start()
+	defer ts.Close()
+
+	// The proxy is not used for the socket
+	t.Setenv("HTTP_PROXY", "http://proxy.invalid:3128")
+
+	option := ScannerOption{RemoteURL: "unix://" + socket}
+	assert.Equal(t, "http://unix", option.ServerURL())
+
+	_, _, err = NewScanner(option).Scan("dummy", "", nil, types.ScanOptions{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	google_protobuf "github.com/golang/protobuf/ptypes/empty"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	r "github.com/aquasecurity/trivy/pkg/rpc"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	rpc "github.com/aquasecurity/trivy/rpc/scanner"
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
+)
+
+// grpcMaxMessageSize is the maximum size of the messages, which is the same as the server
+const grpcMaxMessageSize = 64 << 20
+
+// grpcTarget returns the address to dial, e.g. "localhost:4954" of "http://localhost:4954", and whether TLS is used
+func (o ScannerOption) grpcTarget() (string, bool, error) {
+	if path, ok := o.socketPath(); ok {
+		return "unix://" + path, false, nil
+	}
+
+	u, err := url.Parse(o.RemoteURL)
+	if err != nil {
+		return "", false, xerrors.Errorf("invalid server URL: %w", err)
+	} else if u.Host == "" {
+		return "", false, xerrors.Errorf("no host in the server URL: %s", o.RemoteURL)
+	} else if u.Path != "" && u.Path != "/" {
+		// The paths of gRPC are fixed by the services
+		return "", false, xerrors.Errorf("the server URL can't have the path with gRPC: %s", o.RemoteURL)
+	}
+
+	var useTLS bool
+	switch u.Scheme {
+	case "https":
+		useTLS = true
+	case "http":
+	default:
+		return "", false, xerrors.Errorf("unsupported scheme of the server URL: %s", o.RemoteURL)
+	}
+
+	if u.Port() != "" {
+		return u.Host, useTLS, nil
+	} else if useTLS {
+		return net.JoinHostPort(u.Hostname(), "443"), useTLS, nil
+	}
+	return net.JoinHostPort(u.Hostname(), "80"), useTLS, nil
+}
+
+// dialGRPC returns the connection to the server, which is established on the first request
+func (o ScannerOption) dialGRPC() (*grpc.ClientConn, error) {
+	target, useTLS, err := o.grpcTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{
+			InsecureSkipVerify: o.Insecure,
+			RootCAs:            o.RootCAs,
+			Certificates:       o.Certificates,
+		})
+	}
+	conn, err := grpc.Dial(target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(
+			grpc.UseCompressor(gzip.Name),
+			grpc.MaxCallRecvMsgSize(grpcMaxMessageSize),
+			grpc.MaxCallSendMsgSize(grpcMaxMessageSize),
+		),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("gRPC dial error: %w", err)
+	}
+	return conn, nil
+}
+
+// grpcClient sends the requests of the Twirp clients over gRPC.
+// The custom headers are sent as the metadata, and the timeout of each request is sent as the deadline.
+// The errors are returned as the twirp errors so that the requests are retried the same as Twirp.
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	err     error // returned by all the requests if the server URL is invalid
+	timeout time.Duration
+}
+
+func newGRPCClient(o ScannerOption) grpcClient {
+	conn, err := o.dialGRPC()
+	return grpcClient{conn: conn, err: err, timeout: o.Timeout}
+}
+
+// outgoing returns the context of the request, which must be canceled after the response is received
+func (c grpcClient) outgoing(ctx context.Context) (context.Context, context.CancelFunc) {
+	if headers, ok := twirp.HTTPRequestHeaders(ctx); ok {
+		md := metadata.MD{}
+		for name, values := range headers {
+			md.Append(name, values...)
+		}
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// grpcScanner receives the results of the scans in the stream
+type grpcScanner struct {
+	grpcClient
+}
+
+func (s grpcScanner) Scan(ctx context.Context, in *rpc.ScanRequest) (*rpc.ScanResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	ctx, cancel := s.outgoing(ctx)
+	defer cancel()
+
+	stream, err := rpc.NewScannerStreamClient(s.conn).ScanStream(ctx, in)
+	if err != nil {
+		return nil, r.ConvertFromGRPCError(err)
+	}
+	res := &rpc.ScanResponse{}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return nil, r.ConvertFromGRPCError(err)
+		}
+		if msg.Os != nil {
+			res.Os = msg.Os
+		}
+		res.Results = append(res.Results, msg.Results...)
+	}
+}
+
+func (s grpcScanner) InspectImage(ctx context.Context, in *rpc.InspectImageRequest) (*rpc.InspectImageResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	ctx, cancel := s.outgoing(ctx)
+	defer cancel()
+
+	res, err := rpc.NewScannerClient(s.conn).InspectImage(ctx, in)
+	return res, r.ConvertFromGRPCError(err)
+}
+
+type grpcCache struct {
+	grpcClient
+}
+
+func (c grpcCache) PutArtifact(ctx context.Context, in *rpcCache.PutArtifactRequest) (*google_protobuf.Empty, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	ctx, cancel := c.outgoing(ctx)
+	defer cancel()
+
+	res, err := rpcCache.NewCacheClient(c.conn).PutArtifact(ctx, in)
+	return res, r.ConvertFromGRPCError(err)
+}
+
+func (c grpcCache) PutBlob(ctx context.Context, in *rpcCache.PutBlobRequest) (*google_protobuf.Empty, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	ctx, cancel := c.outgoing(ctx)
+	defer cancel()
+
+	res, err := rpcCache.NewCacheClient(c.conn).PutBlob(ctx, in)
+	return res, r.ConvertFromGRPCError(err)
+}
+
+func (c grpcCache) MissingBlobs(ctx context.Context, in *rpcCache.MissingBlobsRequest) (*rpcCache.MissingBlobsResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	ctx, cancel := c.outgoing(ctx)
+	defer cancel()
+
+	res, err := rpcCache.NewCacheClient(c.conn).MissingBlobs(ctx, in)
+	return res, r.ConvertFromGRPCError(err)
+}
+
+func (c grpcCache) DeleteBlobs(ctx context.Context, in *rpcCache.DeleteBlobsRequest) (*google_protobuf.Empty, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	ctx, cancel := c.outgoing(ctx)
+	defer cancel()
+
+	res, err := rpcCache.NewCacheClient(c.conn).DeleteBlobs(ctx, in)
+	return res, r.ConvertFromGRPCError(err)
+}
+
+type grpcVersion struct {
+	grpcClient
+}
+
+func (v grpcVersion) GetVersion(ctx context.Context, in *emptypb.Empty) (*rpcVersion.VersionResponse, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	ctx, cancel := v.outgoing(ctx)
+	defer cancel()
+
+	res, err := rpcVersion.NewVersionClient(v.conn).GetVersion(ctx, in)
+	return res, r.ConvertFromGRPCError(err)
+}
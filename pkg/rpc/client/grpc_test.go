@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	r "github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpc "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+func TestScannerOption_grpcTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteURL string
+		want      string
+		wantTLS   bool
+		wantErr   string
+	}{
+		{
+			name:      "http",
+			remoteURL: "http://localhost:4954",
+			want:      "localhost:4954",
+		},
+		{
+			name:      "https without port",
+			remoteURL: "https://trivy.example.com/",
+			want:      "trivy.example.com:443",
+			wantTLS:   true,
+		},
+		{
+			name:      "http without port",
+			remoteURL: "http://trivy.example.com",
+			want:      "trivy.example.com:80",
+		},
+		{
+			name:      "unix domain socket",
+			remoteURL: "unix:///var/run/trivy.sock",
+			want:      "unix:///var/run/trivy.sock",
+		},
+		{
+			name:      "sad path: path",
+			remoteURL: "https://example.com/trivy",
+			wantErr:   "the server URL can't have the path with gRPC",
+		},
+		{
+			name:      "sad path: no host",
+			remoteURL: "localhost:4954",
+			wantErr:   "no host in the server URL",
+		},
+		{
+			name:      "sad path: unsupported scheme",
+			remoteURL: "ftp://localhost:4954",
+			wantErr:   "unsupported scheme of the server URL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotTLS, err := ScannerOption{RemoteURL: tt.remoteURL}.grpcTarget()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantTLS, gotTLS)
+		})
+	}
+}
+
+type fakeScannerStream struct {
+	rpc.UnimplementedScannerStreamServer
+	token     string
+	responses []*rpc.ScanResponse
+	err       error
+}
+
+func (s *fakeScannerStream) ScanStream(_ *rpc.ScanRequest, stream rpc.ScannerStream_ScanStreamServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if got := md.Get("trivy-token"); len(got) != 1 || got[0] != s.token {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	for _, res := range s.responses {
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+type fakeCache struct {
+	rpcCache.UnimplementedCacheServer
+}
+
+func (fakeCache) MissingBlobs(context.Context, *rpcCache.MissingBlobsRequest) (*rpcCache.MissingBlobsResponse, error) {
+	return nil, status.Error(codes.Unavailable, "the DB is being updated")
+}
+
+func listenGRPC(t *testing.T, scanner rpc.ScannerStreamServer) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	rpc.RegisterScannerStreamServer(s, scanner)
+	rpcCache.RegisterCacheServer(s, fakeCache{})
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	return "http://" + l.Addr().String()
+}
+
+func TestScanner_ScanGRPC(t *testing.T) {
+	retryInfo, err := status.New(codes.ResourceExhausted, "too many concurrent scans").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(5 * time.Second)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		stream  *fakeScannerStream
+		want    types.Results
+		wantOS  *ftypes.OS
+		wantErr string
+	}{
+		{
+			name:  "happy path",
+			token: "test",
+			stream: &fakeScannerStream{
+				token: "test",
+				responses: []*rpc.ScanResponse{
+					{Os: &common.OS{Family: "alpine", Name: "3.15.4"}},
+					{Results: []*rpc.Result{{Target: "alpine:3.15 (alpine 3.15.4)", Class: "os-pkgs"}}},
+					{Results: []*rpc.Result{{Target: "app/package-lock.json", Class: "lang-pkgs", Type: "npm"}}},
+				},
+			},
+			want: types.Results{
+				{Target: "alpine:3.15 (alpine 3.15.4)", Class: "os-pkgs"},
+				{Target: "app/package-lock.json", Class: "lang-pkgs", Type: "npm"},
+			},
+			wantOS: &ftypes.OS{Family: "alpine", Name: "3.15.4"},
+		},
+		{
+			name:  "sad path: invalid token",
+			token: "invalid",
+			stream: &fakeScannerStream{
+				token: "test",
+			},
+			wantErr: "invalid token",
+		},
+		{
+			name:  "sad path: error after results",
+			token: "test",
+			stream: &fakeScannerStream{
+				token: "test",
+				responses: []*rpc.ScanResponse{
+					{Os: &common.OS{Family: "alpine", Name: "3.15.4"}},
+				},
+				err: status.Error(codes.Internal, "failed to detect vulnerabilities"),
+			},
+			wantErr: "failed to detect vulnerabilities",
+		},
+		{
+			name:  "sad path: too many concurrent scans",
+			token: "test",
+			stream: &fakeScannerStream{
+				token: "test",
+				err:   retryInfo.Err(),
+			},
+			wantErr: "too many concurrent scans",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(ScannerOption{
+				RemoteURL:     listenGRPC(t, tt.stream),
+				CustomHeaders: http.Header{"Trivy-Token": []string{tt.token}},
+				Timeout:       10 * time.Second,
+				Protocol:      r.ProtocolGRPC,
+			})
+			got, gotOS, err := s.Scan("alpine:3.15", "", nil, types.ScanOptions{})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOS, gotOS)
+		})
+	}
+}
+
+func TestNewCacheClient_GRPC(t *testing.T) {
+	c := NewCacheClient(ScannerOption{
+		RemoteURL: listenGRPC(t, &fakeScannerStream{}),
+		Protocol:  r.ProtocolGRPC,
+	})
+	_, err := c.MissingBlobs(context.Background(), &rpcCache.MissingBlobsRequest{})
+	require.Error(t, err)
+
+	// The errors are returned as the twirp errors to be retried
+	twerr, ok := err.(twirp.Error)
+	require.True(t, ok)
+	assert.Equal(t, twirp.Unavailable, twerr.Code())
+	assert.Equal(t, "the DB is being updated", twerr.Msg())
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/artifact"
+	ftypes "github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	r "github.com/aquasecurity/trivy/pkg/rpc"
+	rpc "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// ServerSidePullArtifact lets the server pull and analyze the image, for clients which can't reach the registry at all.
+// Nothing is pulled on the client, and the analysis results are stored in the cache of the server.
+type ServerSidePullArtifact struct {
+	imageName      string
+	artifactOption artifact.Option
+	customHeaders  http.Header
+	retry          r.RetryOption
+	client         rpc.Scanner
+}
+
+// NewServerSidePullArtifact returns the artifact pulled by the server
+func NewServerSidePullArtifact(imageName string, option ScannerOption, opt tartifact.Option,
+	opts ...Option) (artifact.Artifact, error) {
+	// The image is pulled and analyzed with the transport and the limits of the server
+	artifactOpt := opt.Option
+
+	// The server doesn't have the policies of the client
+	if len(artifactOpt.MisconfScannerOption.Namespaces) > 0 {
+		return nil, xerrors.New("misconfiguration scanning is not supported with the server-side pull")
+	}
+	if len(artifactOpt.MisconfScannerOption.FilePatterns) > 0 {
+		return nil, xerrors.New("'--file-patterns' is not supported with the server-side pull")
+	}
+
+	if _, err := os.Stat(artifactOpt.SecretScannerOption.ConfigPath); err == nil {
+		log.Logger.Warnf("%s is not used since secrets are detected with the builtin rules of the server",
+			artifactOpt.SecretScannerOption.ConfigPath)
+	}
+
+	o := &options{rpcClient: NewScannerClient(option)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return ServerSidePullArtifact{
+		imageName:      imageName,
+		artifactOption: artifactOpt,
+		customHeaders:  option.CustomHeaders,
+		retry:          option.Retry,
+		client:         o.rpcClient,
+	}, nil
+}
+
+func (a ServerSidePullArtifact) Inspect(ctx context.Context) (ftypes.ArtifactReference, error) {
+	ctx = WithCustomHeaders(ctx, a.customHeaders)
+
+	var disabledAnalyzers []string
+	for _, t := range a.artifactOption.DisabledAnalyzers {
+		disabledAnalyzers = append(disabledAnalyzers, string(t))
+	}
+
+	var res *rpc.InspectImageResponse
+	err := r.Retry(ctx, a.retry, func() error {
+		var err error
+		res, err = a.client.InspectImage(ctx, &rpc.InspectImageRequest{
+			ImageName:         a.imageName,
+			DisabledAnalyzers: disabledAnalyzers,
+			SkipFiles:         a.artifactOption.SkipFiles,
+			SkipDirs:          a.artifactOption.SkipDirs,
+			Offline:           a.artifactOption.Offline,
+		})
+		return err
+	})
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("failed to pull the image by the server: %w", err)
+	}
+
+	var configFile v1.ConfigFile
+	if err = json.Unmarshal(res.ConfigFile, &configFile); err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to decode the image config: %w", err)
+	}
+
+	return ftypes.ArtifactReference{
+		Name:    a.imageName,
+		Type:    ftypes.ArtifactContainerImage,
+		ID:      res.ArtifactId,
+		BlobIDs: res.BlobIds,
+		ImageMetadata: ftypes.ImageMetadata{
+			ID:          res.ImageId,
+			DiffIDs:     res.DiffIds,
+			RepoTags:    res.RepoTags,
+			RepoDigests: res.RepoDigests,
+			ConfigFile:  configFile,
+		},
+	}, nil
+}
+
+func (ServerSidePullArtifact) Clean(_ ftypes.ArtifactReference) error {
+	return nil
+}
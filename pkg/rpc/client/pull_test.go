@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	misconf "github.com/aquasecurity/fanal/analyzer/config"
+	"github.com/aquasecurity/fanal/artifact"
+	ftypes "github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	rpc "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+func TestServerSidePullArtifact_Inspect(t *testing.T) {
+	configFile := v1.ConfigFile{
+		Architecture: "amd64",
+		OS:           "linux",
+		RootFS: v1.RootFS{
+			Type:    "layers",
+			DiffIDs: []v1.Hash{{Algorithm: "sha256", Hex: "5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"}},
+		},
+	}
+	b, err := json.Marshal(configFile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		artifactOpt artifact.Option
+		response    *rpc.InspectImageResponse
+		wantRequest *rpc.InspectImageRequest
+		want        ftypes.ArtifactReference
+		wantErr     string
+	}{
+		{
+			name: "happy path",
+			artifactOpt: artifact.Option{
+				DisabledAnalyzers: []analyzer.Type{analyzer.TypeYarn},
+				SkipDirs:          []string{"/usr/lib"},
+				Offline:           true,
+			},
+			response: &rpc.InspectImageResponse{
+				ArtifactId:  "sha256:e7d92cdc71feacf90708cb59182d0df1b911f8ae022d29e8e95d75ca6a99776a",
+				BlobIds:     []string{"sha256:beee9f30bc1f711043e78d4a2be0668955d4b761d587d6f60c2c8dc081efb203"},
+				ImageId:     "sha256:a187dde48cd289ac374ad8539930628314bc581a481cdb41409c9289419ddb72",
+				DiffIds:     []string{"sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"},
+				RepoTags:    []string{"alpine:3.11"},
+				RepoDigests: []string{"alpine@sha256:ab00606a42621fb68f2ed6ad3c88be54397f981a7b70a79db3d1172b11c4367d"},
+				ConfigFile:  b,
+			},
+			wantRequest: &rpc.InspectImageRequest{
+				ImageName:         "alpine:3.11",
+				DisabledAnalyzers: []string{"yarn"},
+				SkipDirs:          []string{"/usr/lib"},
+				Offline:           true,
+			},
+			want: ftypes.ArtifactReference{
+				Name:    "alpine:3.11",
+				Type:    ftypes.ArtifactContainerImage,
+				ID:      "sha256:e7d92cdc71feacf90708cb59182d0df1b911f8ae022d29e8e95d75ca6a99776a",
+				BlobIDs: []string{"sha256:beee9f30bc1f711043e78d4a2be0668955d4b761d587d6f60c2c8dc081efb203"},
+				ImageMetadata: ftypes.ImageMetadata{
+					ID:          "sha256:a187dde48cd289ac374ad8539930628314bc581a481cdb41409c9289419ddb72",
+					DiffIDs:     []string{"sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"},
+					RepoTags:    []string{"alpine:3.11"},
+					RepoDigests: []string{"alpine@sha256:ab00606a42621fb68f2ed6ad3c88be54397f981a7b70a79db3d1172b11c4367d"},
+					ConfigFile:  configFile,
+				},
+			},
+		},
+		{
+			name: "sad path: misconfiguration",
+			artifactOpt: artifact.Option{
+				MisconfScannerOption: misconf.ScannerOption{
+					Namespaces: []string{"user"},
+				},
+			},
+			wantErr: "misconfiguration scanning is not supported",
+		},
+		{
+			name: "sad path: file patterns",
+			artifactOpt: artifact.Option{
+				MisconfScannerOption: misconf.ScannerOption{
+					FilePatterns: []string{`pip:requirements-.*\.txt`},
+				},
+			},
+			wantErr: "'--file-patterns' is not supported",
+		},
+		{
+			name:    "sad path: not allowed",
+			wantErr: "failed to pull the image by the server",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "test", r.Header.Get("Trivy-Token"))
+				if tt.response == nil {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"code":"permission_denied","msg":"not allowed"}`))
+					return
+				}
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				var gotRequest rpc.InspectImageRequest
+				require.NoError(t, protojson.Unmarshal(body, &gotRequest))
+				assert.Equal(t, tt.wantRequest.ImageName, gotRequest.ImageName)
+				assert.Equal(t, tt.wantRequest.DisabledAnalyzers, gotRequest.DisabledAnalyzers)
+				assert.Equal(t, tt.wantRequest.SkipDirs, gotRequest.SkipDirs)
+				assert.Equal(t, tt.wantRequest.Offline, gotRequest.Offline)
+
+				b, err := protojson.Marshal(tt.response)
+				require.NoError(t, err)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(b)
+			}))
+			defer ts.Close()
+
+			a, err := NewServerSidePullArtifact("alpine:3.11", ScannerOption{
+				CustomHeaders: http.Header{"Trivy-Token": []string{"test"}},
+			}, tartifact.Option{Option: tt.artifactOpt}, WithRPCClient(rpc.NewScannerJSONClient(ts.URL, ts.Client())))
+			if err != nil {
+				require.NotEmpty(t, tt.wantErr)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			got, err := a.Inspect(context.Background())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
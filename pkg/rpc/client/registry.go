@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/image"
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+// registryProxyPathPrefix must be the same as RegistryProxyPathPrefix of the server
+const registryProxyPathPrefix = "/registry/"
+
+// NewProxiedImage opens the image in the registry through the server, for clients which can't reach the registry.
+// Only manifests and layers which are not cached in the server are pulled, as the image is scanned as usual.
+func NewProxiedImage(ctx context.Context, imageName string, option ScannerOption) (ftypes.Image, error) {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse the image name: %w", err)
+	}
+
+	tr, err := newRegistryTransport(option)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server authenticates to the registry with its own credentials
+	desc, err := remote.Get(ref, remote.WithContext(ctx), remote.WithTransport(tr), remote.WithAuth(authn.Anonymous))
+	if err != nil {
+		return nil, xerrors.Errorf("unable to get the image through the server: %w", err)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to get the image through the server: %w", err)
+	}
+
+	return proxiedImage{
+		Image:  img,
+		name:   imageName,
+		ref:    ref,
+		digest: desc.Digest,
+	}, nil
+}
+
+// registryTransport sends requests to registries to the registry proxy of the server instead,
+// e.g. https://ghcr.io/v2/... => http://localhost:4954/registry/ghcr.io/v2/...
+type registryTransport struct {
+	server  *url.URL
+	headers http.Header
+	base    http.RoundTripper
+}
+
+func newRegistryTransport(option ScannerOption) (registryTransport, error) {
+	u, err := url.Parse(option.ServerURL())
+	if err != nil {
+		return registryTransport{}, xerrors.Errorf("invalid server URL: %w", err)
+	}
+	return registryTransport{
+		server:  u,
+		headers: option.CustomHeaders,
+		base:    option.transport(),
+	}, nil
+}
+
+func (t registryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Host = ""
+	r.URL.Scheme = t.server.Scheme
+	r.URL.Host = t.server.Host
+	r.URL.Path = strings.TrimSuffix(t.server.Path, "/") + registryProxyPathPrefix + req.URL.Host + req.URL.Path
+	r.URL.RawPath = ""
+
+	// The token and the custom headers are required by the server
+	for k, v := range t.headers {
+		r.Header[k] = v
+	}
+	return t.base.RoundTrip(r)
+}
+
+type proxiedImage struct {
+	v1.Image
+	name   string
+	ref    name.Reference
+	digest v1.Hash
+}
+
+func (img proxiedImage) Name() string {
+	return img.name
+}
+
+func (img proxiedImage) ID() (string, error) {
+	return image.ID(img)
+}
+
+// LayerIDs returns a list of uncompressed layer IDs
+func (img proxiedImage) LayerIDs() ([]string, error) {
+	return image.LayerIDs(img)
+}
+
+func (img proxiedImage) RepoTags() []string {
+	if _, ok := img.ref.(name.Tag); !ok {
+		return nil
+	}
+	return []string{img.name}
+}
+
+// RepoDigests returns the image pinned to the digest of the manifest pulled through the server
+func (img proxiedImage) RepoDigests() []string {
+	return []string{img.ref.Context().Digest(img.digest.String()).Name()}
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProxiedImage(t *testing.T) {
+	reg := registry.New()
+
+	// Push the image to the registry directly
+	upstream := httptest.NewServer(reg)
+	defer upstream.Close()
+	u, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(u.Host + "/library/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	configDigest, err := img.ConfigName()
+	require.NoError(t, err)
+
+	// The server proxies the requests for "registry.example.com" to the registry
+	server := httptest.NewServer(http.StripPrefix("/registry/registry.example.com",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Trivy-Token") != "test" {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+			reg.ServeHTTP(w, r)
+		})))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		imageName       string
+		customHeaders   http.Header
+		wantRepoTags    []string
+		wantRepoDigests []string
+		wantErr         string
+	}{
+		{
+			name:      "happy path",
+			imageName: "registry.example.com/library/app:1.0",
+			customHeaders: http.Header{
+				"Trivy-Token": []string{"test"},
+			},
+			wantRepoTags:    []string{"registry.example.com/library/app:1.0"},
+			wantRepoDigests: []string{"registry.example.com/library/app@" + digest.String()},
+		},
+		{
+			name:      "digest",
+			imageName: "registry.example.com/library/app@" + digest.String(),
+			customHeaders: http.Header{
+				"Trivy-Token": []string{"test"},
+			},
+			wantRepoDigests: []string{"registry.example.com/library/app@" + digest.String()},
+		},
+		{
+			name:      "sad path: unknown image",
+			imageName: "registry.example.com/library/app:2.0",
+			customHeaders: http.Header{
+				"Trivy-Token": []string{"test"},
+			},
+			wantErr: "unable to get the image through the server",
+		},
+		{
+			name:      "sad path: invalid token",
+			imageName: "registry.example.com/library/app:1.0",
+			wantErr:   "unable to get the image through the server",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewProxiedImage(context.Background(), tt.imageName, ScannerOption{
+				RemoteURL:     server.URL,
+				CustomHeaders: tt.customHeaders,
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.imageName, got.Name())
+			assert.Equal(t, tt.wantRepoTags, got.RepoTags())
+			assert.Equal(t, tt.wantRepoDigests, got.RepoDigests())
+
+			id, err := got.ID()
+			require.NoError(t, err)
+			assert.Equal(t, configDigest.String(), id)
+
+			// The layers are pulled through the server as well
+			layerIDs, err := got.LayerIDs()
+			require.NoError(t, err)
+			assert.Len(t, layerIDs, 2)
+			layers, err := got.Layers()
+			require.NoError(t, err)
+			rc, err := layers[0].Compressed()
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+		})
+	}
+}
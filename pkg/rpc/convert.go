@@ -6,14 +6,17 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/log"
 	"github.com/aquasecurity/trivy/pkg/types"
 	"github.com/aquasecurity/trivy/rpc/cache"
 	"github.com/aquasecurity/trivy/rpc/common"
 	"github.com/aquasecurity/trivy/rpc/scanner"
+	"github.com/aquasecurity/trivy/rpc/version"
 )
 
 // ConvertToRPCPkgs returns the list of RPC package objects
@@ -38,6 +41,18 @@ func ConvertToRPCPkgs(pkgs []ftypes.Package) []*common.Package {
 	return rpcPkgs
 }
 
+// ConvertToRPCResultPkgs returns the list of RPC package objects with the digests and the hashes
+func ConvertToRPCResultPkgs(pkgs []types.Package) []*common.Package {
+	var rpcPkgs []*common.Package
+	for _, pkg := range pkgs {
+		rpcPkg := ConvertToRPCPkgs([]ftypes.Package{pkg.Package})[0]
+		rpcPkg.Digest = pkg.Digest
+		rpcPkg.Hashes = pkg.Hashes
+		rpcPkgs = append(rpcPkgs, rpcPkg)
+	}
+	return rpcPkgs
+}
+
 // ConvertFromRPCPkgs returns list of Fanal package objects
 func ConvertFromRPCPkgs(rpcPkgs []*common.Package) []ftypes.Package {
 	var pkgs []ftypes.Package
@@ -60,6 +75,19 @@ func ConvertFromRPCPkgs(rpcPkgs []*common.Package) []ftypes.Package {
 	return pkgs
 }
 
+// ConvertFromRPCResultPkgs returns the list of the packages in the results with the digests and the hashes
+func ConvertFromRPCResultPkgs(rpcPkgs []*common.Package) []types.Package {
+	var pkgs []types.Package
+	for i, pkg := range ConvertFromRPCPkgs(rpcPkgs) {
+		pkgs = append(pkgs, types.Package{
+			Package: pkg,
+			Digest:  rpcPkgs[i].Digest,
+			Hashes:  rpcPkgs[i].Hashes,
+		})
+	}
+	return pkgs
+}
+
 // ConvertToRPCVulns returns common.Vulnerability
 func ConvertToRPCVulns(vulns []types.DetectedVulnerability) []*common.Vulnerability {
 	var rpcVulns []*common.Vulnerability
@@ -183,8 +211,9 @@ func ConvertFromRPCResults(rpcResults []*scanner.Result) []types.Result {
 			Misconfigurations: ConvertFromRPCMisconfs(result.Misconfigurations),
 			Class:             types.ResultClass(result.Class),
 			Type:              result.Type,
-			Packages:          ConvertFromRPCPkgs(result.Packages),
+			Packages:          ConvertFromRPCResultPkgs(result.Packages),
 			CustomResources:   ConvertFromRPCCustomResources(result.CustomResources),
+			Hashes:            result.Hashes,
 		})
 	}
 	return results
@@ -201,7 +230,7 @@ func ConvertFromRPCCustomResources(rpcCustomResources []*common.CustomResource)
 				Digest: res.Layer.Digest,
 				DiffID: res.Layer.DiffId,
 			},
-			Data: res.Data,
+			Data: res.Data.AsInterface(),
 		})
 	}
 	return resources
@@ -568,7 +597,8 @@ func ConvertToRPCScanResponse(results types.Results, fos *ftypes.OS) *scanner.Sc
 			Type:              result.Type,
 			Vulnerabilities:   ConvertToRPCVulns(result.Vulnerabilities),
 			Misconfigurations: ConvertToRPCMisconfs(result.Misconfigurations),
-			Packages:          ConvertToRPCPkgs(result.Packages),
+			Packages:          ConvertToRPCResultPkgs(result.Packages),
+			Hashes:            result.Hashes,
 		})
 	}
 
@@ -588,3 +618,27 @@ func ConvertFromDeleteBlobsRequest(deleteBlobsRequest *cache.DeleteBlobsRequest)
 	}
 	return deleteBlobsRequest.GetBlobIds()
 }
+
+// ConvertToRPCVulnerabilityDB converts the metadata of the vulnerability DB to version.VulnerabilityDB
+func ConvertToRPCVulnerabilityDB(meta metadata.Metadata, stale bool) *version.VulnerabilityDB {
+	return &version.VulnerabilityDB{
+		Version:      int32(meta.Version),
+		UpdatedAt:    timestamppb.New(meta.UpdatedAt),
+		NextUpdate:   timestamppb.New(meta.NextUpdate),
+		DownloadedAt: timestamppb.New(meta.DownloadedAt),
+		Stale:        stale,
+	}
+}
+
+// ConvertFromRPCVulnerabilityDB converts version.VulnerabilityDB to the metadata of the vulnerability DB
+func ConvertFromRPCVulnerabilityDB(rpcDB *version.VulnerabilityDB) *metadata.Metadata {
+	if rpcDB == nil {
+		return nil
+	}
+	return &metadata.Metadata{
+		Version:      int(rpcDB.Version),
+		UpdatedAt:    rpcDB.UpdatedAt.AsTime(),
+		NextUpdate:   rpcDB.NextUpdate.AsTime(),
+		DownloadedAt: rpcDB.DownloadedAt.AsTime(),
+	}
+}
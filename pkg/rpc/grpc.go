@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"golang.org/x/xerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// ProtocolTwirp is the protocol of the RPCs over HTTP/1.1 served by default
+	ProtocolTwirp = "twirp"
+
+	// ProtocolGRPC is the native gRPC served alongside Twirp, so that service meshes and load balancers
+	// can apply the standard gRPC policies
+	ProtocolGRPC = "grpc"
+)
+
+// Protocols are the protocols between the client and the server
+var Protocols = []string{ProtocolTwirp, ProtocolGRPC}
+
+// The twirp error codes are the same as the gRPC codes except for the ones specific to HTTP
+var twirpToGRPCCodes = map[twirp.ErrorCode]codes.Code{
+	twirp.Canceled:           codes.Canceled,
+	twirp.Unknown:            codes.Unknown,
+	twirp.InvalidArgument:    codes.InvalidArgument,
+	twirp.Malformed:          codes.InvalidArgument,
+	twirp.DeadlineExceeded:   codes.DeadlineExceeded,
+	twirp.NotFound:           codes.NotFound,
+	twirp.BadRoute:           codes.Unimplemented,
+	twirp.AlreadyExists:      codes.AlreadyExists,
+	twirp.PermissionDenied:   codes.PermissionDenied,
+	twirp.Unauthenticated:    codes.Unauthenticated,
+	twirp.ResourceExhausted:  codes.ResourceExhausted,
+	twirp.FailedPrecondition: codes.FailedPrecondition,
+	twirp.Aborted:            codes.Aborted,
+	twirp.OutOfRange:         codes.OutOfRange,
+	twirp.Unimplemented:      codes.Unimplemented,
+	twirp.Internal:           codes.Internal,
+	twirp.Unavailable:        codes.Unavailable,
+	twirp.DataLoss:           codes.DataLoss,
+}
+
+var grpcToTwirpCodes = map[codes.Code]twirp.ErrorCode{
+	codes.Canceled:           twirp.Canceled,
+	codes.Unknown:            twirp.Unknown,
+	codes.InvalidArgument:    twirp.InvalidArgument,
+	codes.DeadlineExceeded:   twirp.DeadlineExceeded,
+	codes.NotFound:           twirp.NotFound,
+	codes.AlreadyExists:      twirp.AlreadyExists,
+	codes.PermissionDenied:   twirp.PermissionDenied,
+	codes.ResourceExhausted:  twirp.ResourceExhausted,
+	codes.FailedPrecondition: twirp.FailedPrecondition,
+	codes.Aborted:            twirp.Aborted,
+	codes.OutOfRange:         twirp.OutOfRange,
+	codes.Unimplemented:      twirp.Unimplemented,
+	codes.Internal:           twirp.Internal,
+	codes.Unavailable:        twirp.Unavailable,
+	codes.DataLoss:           twirp.DataLoss,
+	codes.Unauthenticated:    twirp.Unauthenticated,
+}
+
+// ConvertToGRPCError returns the gRPC status of the error returned by the services shared with Twirp.
+// The retry after of the twirp error is sent as the standard RetryInfo.
+func ConvertToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	} else if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var twerr twirp.Error
+	switch {
+	case errors.As(err, &twerr):
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		// Twirp also returns the internal error with the message
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code, ok := twirpToGRPCCodes[twerr.Code()]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, twerr.Msg())
+	if seconds, err := strconv.Atoi(twerr.Meta(RetryAfterMetaKey)); err == nil {
+		retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(seconds) * time.Second)}
+		if s, err := st.WithDetails(retryInfo); err == nil {
+			st = s
+		}
+	}
+	return st.Err()
+}
+
+// ConvertFromGRPCError returns the twirp error of the gRPC status, so that the errors are retried
+// the same as Twirp. The deadline exceeded is returned as the timeout of the request.
+func ConvertFromGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.OK:
+		return nil
+	case codes.DeadlineExceeded:
+		return xerrors.Errorf("%s: %w", st.Message(), context.DeadlineExceeded)
+	}
+
+	code, ok := grpcToTwirpCodes[st.Code()]
+	if !ok {
+		code = twirp.Unknown
+	}
+	twerr := twirp.NewError(code, st.Message())
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok && retryInfo.RetryDelay != nil {
+			seconds := int(retryInfo.RetryDelay.AsDuration().Round(time.Second).Seconds())
+			twerr = twerr.WithMeta(RetryAfterMetaKey, strconv.Itoa(seconds))
+		}
+	}
+	return twerr
+}
@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/xerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestConvertToGRPCError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantCode       codes.Code
+		wantMsg        string
+		wantRetryAfter time.Duration
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name:     "twirp error",
+			err:      twirp.NewError(twirp.Unauthenticated, "invalid token"),
+			wantCode: codes.Unauthenticated,
+			wantMsg:  "invalid token",
+		},
+		{
+			name:           "retry after",
+			err:            twirp.NewError(twirp.ResourceExhausted, "rate limit exceeded").WithMeta(RetryAfterMetaKey, "3"),
+			wantCode:       codes.ResourceExhausted,
+			wantMsg:        "rate limit exceeded",
+			wantRetryAfter: 3 * time.Second,
+		},
+		{
+			name:     "wrapped twirp error",
+			err:      xerrors.Errorf("scan error: %w", twirp.NewError(twirp.Unavailable, "db update")),
+			wantCode: codes.Unavailable,
+			wantMsg:  "db update",
+		},
+		{
+			name:     "other error",
+			err:      xerrors.New("failed scan"),
+			wantCode: codes.Internal,
+			wantMsg:  "failed scan",
+		},
+		{
+			name:     "deadline exceeded",
+			err:      xerrors.Errorf("failed scan: %w", context.DeadlineExceeded),
+			wantCode: codes.DeadlineExceeded,
+			wantMsg:  "failed scan: context deadline exceeded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConvertToGRPCError(tt.err)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantMsg, st.Message())
+
+			var retryAfter time.Duration
+			for _, detail := range st.Details() {
+				if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+					retryAfter = retryInfo.RetryDelay.AsDuration()
+				}
+			}
+			assert.Equal(t, tt.wantRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestConvertFromGRPCError(t *testing.T) {
+	retryAfter, err := status.New(codes.ResourceExhausted, "too many concurrent scans").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(2 * time.Second)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		err            error
+		want           twirp.ErrorCode
+		wantMsg        string
+		wantRetryAfter string
+		wantTemporary  bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name:    "unavailable",
+			err:     status.Error(codes.Unavailable, "connection refused"),
+			want:    twirp.Unavailable,
+			wantMsg: "connection refused",
+		},
+		{
+			name:           "retry after",
+			err:            retryAfter.Err(),
+			want:           twirp.ResourceExhausted,
+			wantMsg:        "too many concurrent scans",
+			wantRetryAfter: "2",
+		},
+		{
+			name:          "deadline exceeded",
+			err:           status.Error(codes.DeadlineExceeded, "context deadline exceeded"),
+			wantTemporary: true,
+		},
+		{
+			name:    "not status",
+			err:     xerrors.New("unexpected EOF"),
+			wantMsg: "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConvertFromGRPCError(tt.err)
+			switch {
+			case tt.err == nil:
+				assert.NoError(t, err)
+			case tt.wantTemporary:
+				// Retried the same as the timeout of Twirp
+				assert.True(t, isTemporary(err))
+			case tt.want == "":
+				assert.Equal(t, tt.err, err)
+			default:
+				twerr, ok := err.(twirp.Error)
+				require.True(t, ok)
+				assert.Equal(t, tt.want, twerr.Code())
+				assert.Equal(t, tt.wantMsg, twerr.Msg())
+				assert.Equal(t, tt.wantRetryAfter, twerr.Meta(RetryAfterMetaKey))
+			}
+		})
+	}
+}
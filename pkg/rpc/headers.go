@@ -0,0 +1,7 @@
+package rpc
+
+// ScanIDHeader is the header with the ID of the scan, so that the requests to the server can be correlated with the logs of the client
+const ScanIDHeader = "Trivy-Scan-Id"
+
+// ClientIDHeader is the header with the identity of the client given with '--client-id', e.g. the name of the pipeline
+const ClientIDHeader = "Trivy-Client-Id"
@@ -1,6 +1,12 @@
 package rpc
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -10,33 +16,134 @@ import (
 )
 
 const (
-	maxRetries = 10
+	// DefaultRetries is the maximum number of retries unless specified
+	DefaultRetries = 10
+
+	// RetryAfterMetaKey is the key of the twirp error metadata telling clients when to retry in seconds
+	RetryAfterMetaKey = "retry_after"
+
+	defaultInitialInterval = time.Second
+	maxInterval            = 30 * time.Second
 )
 
-// Retry executes the function again using backoff until maxRetries or success
-func Retry(f func() error) error {
+// RetryOption configures the retries of the requests to the server
+type RetryOption struct {
+	// Retries is the maximum number of retries, and 0 disables the retries
+	Retries int
+
+	// InitialInterval is the wait before the first retry, which doubles on every retry up to 30 seconds
+	InitialInterval time.Duration
+}
+
+// Retry executes the function again with exponential backoff until opt.Retries or success.
+// The function is retried when the server is restarting or overloaded, or the connection fails,
+// waiting at least as long as the server asks. It is not retried once ctx is done.
+func Retry(ctx context.Context, opt RetryOption, f func() error) error {
+	b := &retryAfterBackOff{BackOff: newBackOff(opt)}
 	operation := func() error {
 		err := f()
-		if err != nil {
-			twerr, ok := err.(twirp.Error)
-			if !ok {
-				return backoff.Permanent(err)
-			}
-			if twerr.Code() == twirp.Unavailable {
-				return err
-			}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
 			return backoff.Permanent(err)
 		}
-		return nil
+		if isTemporary(err) {
+			return err
+		}
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			return backoff.Permanent(err)
+		}
+		switch twerr.Code() {
+		case twirp.Unavailable:
+			return err
+		case twirp.ResourceExhausted:
+			if seconds, err := strconv.Atoi(twerr.Meta(RetryAfterMetaKey)); err == nil {
+				b.retryAfter = time.Duration(seconds) * time.Second
+			}
+			return err
+		}
+		return backoff.Permanent(err)
 	}
 
-	b := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries)
-	err := backoff.RetryNotify(operation, b, func(err error, _ time.Duration) {
+	err := backoff.RetryNotify(operation, b, func(err error, wait time.Duration) {
 		log.Logger.Warn(err)
-		log.Logger.Info("Retrying HTTP request...")
+		log.Logger.Infof("Retrying HTTP request in %s...", wait.Round(time.Millisecond))
 	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func newBackOff(opt RetryOption) backoff.BackOff {
+	// WithMaxRetries retries forever with 0
+	if opt.Retries <= 0 {
+		return &backoff.StopBackOff{}
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = opt.InitialInterval
+	if b.InitialInterval <= 0 {
+		b.InitialInterval = defaultInitialInterval
+	}
+	b.Multiplier = 2
+	b.MaxInterval = maxInterval
+	b.MaxElapsedTime = 0 // limited by the retries
+	b.Reset()
+	return backoff.WithMaxRetries(b, uint64(opt.Retries))
+}
+
+// isTemporary returns whether the connection to the server failed, e.g. the server is restarting,
+// or the request timed out. The errors of TLS are not temporary.
+func isTemporary(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		// e.g. "remote error" of TLS
+		return opErr.Op == "dial" || opErr.Op == "read" || opErr.Op == "write"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Unreachable returns whether the server can't be used because of the connection or the authentication,
+// e.g. the server is down, the TLS handshake fails or the identity provider has an outage.
+// The errors returned by the scan on the server are not included.
+func Unreachable(err error) bool {
+	if isTemporary(err) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		return false
+	}
+	switch twerr.Code() {
+	case twirp.Unavailable, twirp.ResourceExhausted, twirp.DeadlineExceeded, twirp.Unauthenticated,
+		twirp.PermissionDenied:
+		return true
+	}
+	return false
+}
+
+// retryAfterBackOff waits at least for the duration given by the server before the next retry
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next != backoff.Stop && b.retryAfter > next {
+		next = b.retryAfter
+	}
+	b.retryAfter = 0
+	return next
+}
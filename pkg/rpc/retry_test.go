@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/xerrors"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		retries   int
+		errs      []error
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name:      "happy path",
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "retry when unavailable",
+			errs:      []error{twirp.NewError(twirp.Unavailable, "db update"), nil},
+			wantCalls: 2,
+		},
+		{
+			name: "retry when rate limited",
+			errs: []error{
+				twirp.NewError(twirp.ResourceExhausted, "rate limit exceeded").WithMeta(RetryAfterMetaKey, "1"),
+				nil,
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "retry when the connection fails",
+			errs: []error{
+				twirp.InternalErrorWith(&url.Error{Op: "Post", URL: "http://localhost:4954",
+					Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}),
+				xerrors.Errorf("HTTP error: %w", io.ErrUnexpectedEOF),
+				nil,
+			},
+			wantCalls: 3,
+		},
+		{
+			name:      "sad path: retries exhausted",
+			retries:   1,
+			errs:      []error{twirp.NewError(twirp.Unavailable, "db update"), twirp.NewError(twirp.Unavailable, "db update")},
+			wantCalls: 2,
+			wantErr:   "db update",
+		},
+		{
+			name:      "sad path: retries disabled",
+			retries:   -1,
+			errs:      []error{twirp.NewError(twirp.Unavailable, "db update")},
+			wantCalls: 1,
+			wantErr:   "db update",
+		},
+		{
+			name: "sad path: TLS error",
+			errs: []error{
+				twirp.InternalErrorWith(&url.Error{Op: "Post", URL: "https://localhost:4954",
+					Err: &net.OpError{Op: "remote error", Err: xerrors.New("tls: bad certificate")}}),
+			},
+			wantCalls: 1,
+			wantErr:   "tls: bad certificate",
+		},
+		{
+			name:      "sad path: permanent error",
+			errs:      []error{twirp.NewError(twirp.Unauthenticated, "invalid token")},
+			wantCalls: 1,
+			wantErr:   "invalid token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retries := tt.retries
+			switch {
+			case retries == 0:
+				retries = DefaultRetries
+			case retries < 0:
+				retries = 0
+			}
+
+			var calls int
+			err := Retry(context.Background(), RetryOption{Retries: retries, InitialInterval: time.Millisecond}, func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRetry_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := Retry(ctx, RetryOption{Retries: DefaultRetries, InitialInterval: time.Millisecond}, func() error {
+		calls++
+		cancel()
+		return twirp.NewError(twirp.Unavailable, "db update")
+	})
+	assert.ErrorContains(t, err, "db update")
+	assert.Equal(t, 1, calls)
+}
+
+func Test_retryAfterBackOff(t *testing.T) {
+	b := &retryAfterBackOff{BackOff: backoff.NewConstantBackOff(time.Second)}
+	b.retryAfter = 3 * time.Second
+	assert.Equal(t, 3*time.Second, b.NextBackOff())
+
+	// The retry-after is used only once
+	assert.Equal(t, time.Second, b.NextBackOff())
+
+	// The shorter retry-after is ignored
+	b.retryAfter = time.Millisecond
+	assert.Equal(t, time.Second, b.NextBackOff())
+}
+
+func TestUnreachable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "connection refused",
+			err: xerrors.Errorf("unable to store cache on the server: %w",
+				twirp.InternalErrorWith(&url.Error{Op: "Post", URL: "http://localhost:4954",
+					Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}})),
+			want: true,
+		},
+		{
+			name: "TLS error",
+			err: twirp.InternalErrorWith(&url.Error{Op: "Post", URL: "https://localhost:4954",
+				Err: &net.OpError{Op: "remote error", Err: xerrors.New("tls: bad certificate")}}),
+			want: true,
+		},
+		{
+			name: "unavailable",
+			err:  xerrors.Errorf("failed to detect vulnerabilities: %w", twirp.NewError(twirp.Unavailable, "db update")),
+			want: true,
+		},
+		{
+			name: "unauthenticated",
+			err:  twirp.NewError(twirp.Unauthenticated, "invalid token"),
+			want: true,
+		},
+		{
+			name: "scan error",
+			err:  twirp.InternalError("failed scan, centos:7: unknown OS"),
+			want: false,
+		},
+		{
+			name: "client error",
+			err:  xerrors.New("unable to inspect the image"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Unreachable(tt.err))
+		})
+	}
+}
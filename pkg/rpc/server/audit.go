@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	google_protobuf "github.com/golang/protobuf/ptypes/empty"
+	"github.com/twitchtv/twirp"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+type auditEntryKey struct{}
+
+// AuditEntry is written as a JSON line for each request, so that security operations can trace who scanned what
+type AuditEntry struct {
+	Time       time.Time
+	RemoteAddr string
+
+	// The client is identified by the subject of the JWT, the hash of the token and the client certificate.
+	// The subject is not verified for the rejected requests.
+	Subject    string `json:",omitempty"`
+	TokenHash  string `json:",omitempty"`
+	ClientCert string `json:",omitempty"`
+
+	// ClientID is sent by the client with '--client-id', which is not verified
+	ClientID string `json:",omitempty"`
+
+	// ScanID is sent by the client to correlate the request with its logs
+	ScanID string `json:",omitempty"`
+
+	Service string `json:",omitempty"`
+	Method  string
+	Path    string
+
+	// The requested artifact
+	Artifact   string      `json:",omitempty"`
+	ArtifactID string      `json:",omitempty"`
+	BlobIDs    []string    `json:",omitempty"`
+	Options    interface{} `json:",omitempty"`
+
+	Status     int
+	DurationMS int64
+	Summary    *AuditSummary `json:",omitempty"`
+	Error      string        `json:",omitempty"`
+}
+
+// AuditSummary is the summary of the scan result
+type AuditSummary struct {
+	Vulnerabilities   map[string]int `json:",omitempty"`
+	Misconfigurations int            `json:",omitempty"`
+}
+
+// AuditLogger writes the audit log of the requests
+type AuditLogger struct {
+	mu          sync.Mutex
+	enc         *json.Encoder
+	tokenHeader string
+}
+
+// NewAuditLogger returns the audit logger writing to w.
+// The token is read from tokenHeader and only its hash is logged.
+func NewAuditLogger(w io.Writer, tokenHeader string) *AuditLogger {
+	return &AuditLogger{
+		enc:         json.NewEncoder(w),
+		tokenHeader: tokenHeader,
+	}
+}
+
+// handler logs the requests including the ones rejected by the authentication and the rate limiting
+func (l *AuditLogger) handler(base http.Handler) http.Handler {
+	if l == nil {
+		return base
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := l.newEntry(r, start)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		base.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), auditEntryKey{}, entry)))
+
+		entry.Status = rec.status
+		entry.DurationMS = time.Since(start).Milliseconds()
+		l.write(entry)
+	})
+}
+
+func (l *AuditLogger) newEntry(r *http.Request, now time.Time) *AuditEntry {
+	entry := &AuditEntry{
+		Time:       now.UTC(),
+		RemoteAddr: clientAddr(r),
+		ClientID:   r.Header.Get(rpc.ClientIDHeader),
+		ScanID:     r.Header.Get(rpc.ScanIDHeader),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+	}
+
+	// e.g. "Bearer eyJhbGciOi..."
+	if token := strings.TrimPrefix(r.Header.Get(l.tokenHeader), "Bearer "); token != "" {
+		entry.TokenHash = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(token)))
+
+		// The token is verified by the authenticator
+		var claims jwt.RegisteredClaims
+		if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err == nil {
+			entry.Subject = claims.Subject
+		}
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		entry.ClientCert = r.TLS.PeerCertificates[0].Subject.String()
+	}
+
+	// Layers are uploaded to "/layers/<blob ID>"
+	if blobID := strings.TrimPrefix(r.URL.Path, LayerPathPrefix); blobID != r.URL.Path {
+		entry.BlobIDs = []string{blobID}
+	}
+	return entry
+}
+
+func (l *AuditLogger) write(entry *AuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(entry); err != nil {
+		log.Logger.Errorf("Audit log error: %s", err)
+	}
+}
+
+// hooks records the RPC and the error
+func (l *AuditLogger) hooks() *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			if entry := auditEntryFrom(ctx); entry != nil {
+				// e.g. "trivy.scanner.v1.Scanner"
+				pkg, _ := twirp.PackageName(ctx)
+				service, _ := twirp.ServiceName(ctx)
+				entry.Service = pkg + "." + service
+				entry.Method, _ = twirp.MethodName(ctx)
+			}
+			return ctx, nil
+		},
+		Error: func(ctx context.Context, err twirp.Error) context.Context {
+			if entry := auditEntryFrom(ctx); entry != nil {
+				entry.Error = err.Msg()
+			}
+			return ctx
+		},
+	}
+}
+
+func auditEntryFrom(ctx context.Context) *AuditEntry {
+	entry, _ := ctx.Value(auditEntryKey{}).(*AuditEntry)
+	return entry
+}
+
+// statusRecorder records the status code of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Flush is called by the registry proxy streaming the blobs
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// auditScanner records the requested artifacts and the results of scans
+type auditScanner struct {
+	rpcScanner.Scanner
+}
+
+func (s auditScanner) Scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	res, err := s.Scanner.Scan(ctx, in)
+	entry := auditEntryFrom(ctx)
+	if entry == nil {
+		return res, err
+	}
+
+	entry.Artifact = in.Target
+	entry.ArtifactID = in.ArtifactId
+	entry.BlobIDs = in.BlobIds
+	entry.Options = in.Options
+	if err == nil {
+		summary := &AuditSummary{}
+		for _, r := range res.Results {
+			for _, v := range r.Vulnerabilities {
+				if summary.Vulnerabilities == nil {
+					summary.Vulnerabilities = map[string]int{}
+				}
+				summary.Vulnerabilities[v.Severity.String()]++
+			}
+			for _, m := range r.Misconfigurations {
+				if m.Status == string(types.StatusFailure) {
+					summary.Misconfigurations++
+				}
+			}
+		}
+		entry.Summary = summary
+	}
+	return res, err
+}
+
+func (s auditScanner) InspectImage(ctx context.Context, in *rpcScanner.InspectImageRequest) (*rpcScanner.InspectImageResponse, error) {
+	res, err := s.Scanner.InspectImage(ctx, in)
+	if entry := auditEntryFrom(ctx); entry != nil {
+		entry.Artifact = in.ImageName
+		entry.Options = struct {
+			DisabledAnalyzers []string `json:",omitempty"`
+			SkipFiles         []string `json:",omitempty"`
+			SkipDirs          []string `json:",omitempty"`
+			Offline           bool     `json:",omitempty"`
+		}{in.DisabledAnalyzers, in.SkipFiles, in.SkipDirs, in.Offline}
+		if err == nil {
+			entry.ArtifactID = res.ArtifactId
+		}
+	}
+	return res, err
+}
+
+// auditCache records the artifacts and the blobs stored by clients
+type auditCache struct {
+	rpcCache.Cache
+}
+
+func (c auditCache) PutArtifact(ctx context.Context, in *rpcCache.PutArtifactRequest) (*google_protobuf.Empty, error) {
+	if entry := auditEntryFrom(ctx); entry != nil {
+		entry.ArtifactID = in.ArtifactId
+	}
+	return c.Cache.PutArtifact(ctx, in)
+}
+
+func (c auditCache) PutBlob(ctx context.Context, in *rpcCache.PutBlobRequest) (*google_protobuf.Empty, error) {
+	if entry := auditEntryFrom(ctx); entry != nil {
+		entry.BlobIDs = []string{in.DiffId}
+	}
+	return c.Cache.PutBlob(ctx, in)
+}
+
+func (c auditCache) MissingBlobs(ctx context.Context, in *rpcCache.MissingBlobsRequest) (*rpcCache.MissingBlobsResponse, error) {
+	if entry := auditEntryFrom(ctx); entry != nil {
+		entry.ArtifactID = in.ArtifactId
+		entry.BlobIDs = in.BlobIds
+	}
+	return c.Cache.MissingBlobs(ctx, in)
+}
+
+func (c auditCache) DeleteBlobs(ctx context.Context, in *rpcCache.DeleteBlobsRequest) (*google_protobuf.Empty, error) {
+	if entry := auditEntryFrom(ctx); entry != nil {
+		entry.BlobIDs = in.BlobIds
+	}
+	return c.Cache.DeleteBlobs(ctx, in)
+}
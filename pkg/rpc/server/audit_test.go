@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/trivy-db/pkg/db"
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/utils"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+func TestAuditLogger(t *testing.T) {
+	// The static token can be a JWT as well
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject: "repo:org/app:ref:refs/heads/main",
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	fsCache, err := cache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	cacheDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(db.Dir(cacheDir), 0744))
+	_, err = utils.CopyFile("testdata/new.db", db.Path(cacheDir))
+	require.NoError(t, err)
+	_, err = utils.CopyFile("testdata/metadata.json", metadata.Path(cacheDir))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	auditLogger := NewAuditLogger(&buf, "Authorization")
+	ts := httptest.NewServer(newServeMux(pingCache{Cache: fsCache}, &sync.WaitGroup{}, &sync.WaitGroup{}, ServerOption{
+		AppVersion:  "dev",
+		CacheDir:    cacheDir,
+		Auth:        NewTokenAuthenticator(token, "Authorization"),
+		AuditLogger: auditLogger,
+		Protocol:    rpc.ProtocolTwirp,
+	}))
+	defer ts.Close()
+
+	client := rpcCache.NewCacheProtobufClient(ts.URL, http.DefaultClient)
+	for _, tok := range []string{token, "invalid"} {
+		header := http.Header{"Authorization": {tok}}
+		header.Set(rpc.ScanIDHeader, "5a5776db-2653-4291-a9b2-1fab64979ab5")
+		header.Set(rpc.ClientIDHeader, "release-pipeline")
+		ctx, err := twirp.WithHTTPRequestHeaders(context.Background(), header)
+		require.NoError(t, err)
+		_, _ = client.MissingBlobs(ctx, &rpcCache.MissingBlobsRequest{
+			ArtifactId: "sha256:artifact",
+			BlobIds:    []string{"sha256:blob"},
+		})
+	}
+
+	// Health checks are not audited
+	resp, err := http.Get(ts.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	var got []AuditEntry
+	s := bufio.NewScanner(&buf)
+	for s.Scan() {
+		var entry AuditEntry
+		require.NoError(t, json.Unmarshal(s.Bytes(), &entry))
+		assert.NotZero(t, entry.Time)
+		entry.Time, entry.DurationMS = time.Time{}, 0
+		got = append(got, entry)
+	}
+
+	assert.Equal(t, []AuditEntry{
+		{
+			RemoteAddr: "127.0.0.1",
+			Subject:    "repo:org/app:ref:refs/heads/main",
+			TokenHash:  fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(token))),
+			ClientID:   "release-pipeline",
+			ScanID:     "5a5776db-2653-4291-a9b2-1fab64979ab5",
+			Service:    "trivy.cache.v1.Cache",
+			Method:     "MissingBlobs",
+			Path:       "/twirp/trivy.cache.v1.Cache/MissingBlobs",
+			ArtifactID: "sha256:artifact",
+			BlobIDs:    []string{"sha256:blob"},
+			Status:     http.StatusOK,
+		},
+		{
+			RemoteAddr: "127.0.0.1",
+			TokenHash:  fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("invalid"))),
+			ClientID:   "release-pipeline",
+			ScanID:     "5a5776db-2653-4291-a9b2-1fab64979ab5",
+			Method:     http.MethodPost,
+			Path:       "/twirp/trivy.cache.v1.Cache/MissingBlobs",
+			Status:     http.StatusUnauthorized,
+		},
+	}, got)
+}
+
+type fakeScanner struct {
+	res *rpcScanner.ScanResponse
+}
+
+func (s fakeScanner) Scan(context.Context, *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	return s.res, nil
+}
+
+func (s fakeScanner) InspectImage(context.Context, *rpcScanner.InspectImageRequest) (*rpcScanner.InspectImageResponse, error) {
+	return &rpcScanner.InspectImageResponse{ArtifactId: "sha256:artifact"}, nil
+}
+
+func Test_auditScanner_Scan(t *testing.T) {
+	s := auditScanner{Scanner: fakeScanner{
+		res: &rpcScanner.ScanResponse{
+			Results: []*rpcScanner.Result{
+				{
+					Vulnerabilities: []*common.Vulnerability{
+						{VulnerabilityId: "CVE-2022-0001", Severity: common.Severity_CRITICAL},
+						{VulnerabilityId: "CVE-2022-0002", Severity: common.Severity_CRITICAL},
+						{VulnerabilityId: "CVE-2022-0003", Severity: common.Severity_LOW},
+					},
+				},
+				{
+					Misconfigurations: []*common.DetectedMisconfiguration{
+						{Id: "DS001", Status: "PASS"},
+						{Id: "DS002", Status: "FAIL"},
+					},
+				},
+			},
+		},
+	}}
+
+	entry := &AuditEntry{}
+	ctx := context.WithValue(context.Background(), auditEntryKey{}, entry)
+	options := &rpcScanner.ScanOptions{VulnType: []string{"os"}, SecurityChecks: []string{"vuln", "config"}}
+	_, err := s.Scan(ctx, &rpcScanner.ScanRequest{
+		Target:     "alpine:3.15",
+		ArtifactId: "sha256:artifact",
+		BlobIds:    []string{"sha256:blob"},
+		Options:    options,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &AuditEntry{
+		Artifact:   "alpine:3.15",
+		ArtifactID: "sha256:artifact",
+		BlobIDs:    []string{"sha256:blob"},
+		Options:    options,
+		Summary: &AuditSummary{
+			Vulnerabilities:   map[string]int{"CRITICAL": 2, "LOW": 1},
+			Misconfigurations: 1,
+		},
+	}, entry)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// Authenticator authenticates requests from clients, e.g. with the static token or identity tokens of CI pipelines
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// TokenAuthenticator authenticates requests with the static token shared with clients
+type TokenAuthenticator struct {
+	token       string
+	tokenHeader string
+}
+
+func NewTokenAuthenticator(token, tokenHeader string) TokenAuthenticator {
+	return TokenAuthenticator{
+		token:       token,
+		tokenHeader: tokenHeader,
+	}
+}
+
+func (a TokenAuthenticator) Authenticate(r *http.Request) error {
+	if a.token != r.Header.Get(a.tokenHeader) {
+		return xerrors.New("token mismatch")
+	}
+	return nil
+}
+
+// AnyHeaderValue accepts any non-empty value of the header in HeaderAuthenticator
+const AnyHeaderValue = "*"
+
+// HeaderAuthenticator authenticates requests with the headers set by the API gateway in front of the server,
+// e.g. the shared key of the gateway or the user it authenticated
+type HeaderAuthenticator struct {
+	headers http.Header
+}
+
+// NewHeaderAuthenticator returns the authenticator requiring all the headers, where the value "*" only requires the header
+func NewHeaderAuthenticator(headers http.Header) HeaderAuthenticator {
+	return HeaderAuthenticator{headers: headers}
+}
+
+// Wildcard reports whether all the headers accept any value, which clients can send without any credential
+func (a HeaderAuthenticator) Wildcard() bool {
+	for name := range a.headers {
+		if a.headers.Get(name) != AnyHeaderValue {
+			return false
+		}
+	}
+	return true
+}
+
+func (a HeaderAuthenticator) Authenticate(r *http.Request) error {
+	for name := range a.headers {
+		want, got := a.headers.Get(name), r.Header.Get(name)
+		switch {
+		case got == "":
+			return xerrors.Errorf("no %s header", name)
+		case want != AnyHeaderValue && want != got:
+			return xerrors.Errorf("%s header mismatch", name)
+		}
+	}
+	return nil
+}
+
+// Authenticators accepts requests authenticated by any of the authenticators,
+// e.g. the static token for existing clients while migrating to identity tokens
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(r *http.Request) error {
+	var errs error
+	for _, auth := range a {
+		err := auth.Authenticate(r)
+		if err == nil {
+			return nil
+		}
+		errs = multierror.Append(errs, err)
+	}
+	return errs
+}
+
+// RequiredAuthenticators accepts requests authenticated by all the authenticators,
+// e.g. the headers of the API gateway in addition to the token
+type RequiredAuthenticators []Authenticator
+
+func (a RequiredAuthenticators) Authenticate(r *http.Request) error {
+	for _, auth := range a {
+		if err := auth.Authenticate(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withAuth rejects requests which are not authenticated. All requests are accepted if auth is nil.
+func withAuth(base http.Handler, auth Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth != nil {
+			if err := auth.Authenticate(r); err != nil {
+				// The details are not returned to clients
+				log.Logger.Debugf("Authentication error: %s", err)
+				rpcScanner.WriteError(w, twirp.NewError(twirp.Unauthenticated, "invalid token"))
+				return
+			}
+		}
+		base.ServeHTTP(w, r)
+	})
+}
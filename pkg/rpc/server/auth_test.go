@@ -0,0 +1,139 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticators_Authenticate(t *testing.T) {
+	auths := Authenticators{
+		NewTokenAuthenticator("old", "Trivy-Token"),
+		NewTokenAuthenticator("new", "Authorization"),
+	}
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr string
+	}{
+		{
+			name:   "first authenticator",
+			header: http.Header{"Trivy-Token": []string{"old"}},
+		},
+		{
+			name:   "second authenticator",
+			header: http.Header{"Authorization": []string{"new"}},
+		},
+		{
+			name:    "sad path: no authenticator accepts",
+			header:  http.Header{"Trivy-Token": []string{"new"}},
+			wantErr: "token mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header = tt.header
+
+			err := auths.Authenticate(req)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHeaderAuthenticator_Authenticate(t *testing.T) {
+	auth := NewHeaderAuthenticator(http.Header{
+		"X-Gateway-Key":        []string{"secret"},
+		"X-Authenticated-User": []string{AnyHeaderValue},
+	})
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			header: http.Header{
+				"X-Gateway-Key":        []string{"secret"},
+				"X-Authenticated-User": []string{"alice"},
+			},
+		},
+		{
+			name: "sad path: value mismatch",
+			header: http.Header{
+				"X-Gateway-Key":        []string{"invalid"},
+				"X-Authenticated-User": []string{"alice"},
+			},
+			wantErr: "X-Gateway-Key header mismatch",
+		},
+		{
+			name: "sad path: no header",
+			header: http.Header{
+				"X-Gateway-Key": []string{"secret"},
+			},
+			wantErr: "no X-Authenticated-User header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header = tt.header
+
+			err := auth.Authenticate(req)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRequiredAuthenticators_Authenticate(t *testing.T) {
+	auths := RequiredAuthenticators{
+		NewHeaderAuthenticator(http.Header{"X-Authenticated-User": []string{AnyHeaderValue}}),
+		NewTokenAuthenticator("secret", "Trivy-Token"),
+	}
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			header: http.Header{
+				"X-Authenticated-User": []string{"alice"},
+				"Trivy-Token":          []string{"secret"},
+			},
+		},
+		{
+			name:    "sad path: only the wildcard header",
+			header:  http.Header{"X-Authenticated-User": []string{"alice"}},
+			wantErr: "token mismatch",
+		},
+		{
+			name:    "sad path: only the token",
+			header:  http.Header{"Trivy-Token": []string{"secret"}},
+			wantErr: "no X-Authenticated-User header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header = tt.header
+
+			err := auths.Authenticate(req)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
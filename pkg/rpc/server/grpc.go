@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	google_protobuf "github.com/golang/protobuf/ptypes/empty"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // the requests compressed by clients are decompressed
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	rpcResults "github.com/aquasecurity/trivy/rpc/results"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
+)
+
+// grpcMaxMessageSize is the maximum size of the messages, as the blobs and the results may exceed 4MB of the default
+const grpcMaxMessageSize = 64 << 20
+
+type httpRequestKey struct{}
+
+// grpcPolicy applies the same authentication and limits to the gRPC requests as the middlewares of Twirp,
+// and records them in the metrics and the audit log. The health checks are not authenticated nor limited.
+type grpcPolicy struct {
+	auth              Authenticator
+	requireClientCert bool
+	limiter           *rateLimiter
+	scanSlots         slots
+	dbUpdateWg        *sync.WaitGroup
+	requestWg         *sync.WaitGroup
+	metrics           *metrics
+	auditLogger       *AuditLogger
+}
+
+func (p grpcPolicy) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	var res interface{}
+	err := p.intercept(ctx, info.FullMethod, func(ctx context.Context) (err error) {
+		res, err = handler(ctx, req)
+		return err
+	})
+	return res, err
+}
+
+func (p grpcPolicy) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	return p.intercept(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+		return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+// intercept calls the handler of the method, e.g. "/trivy.scanner.v1.Scanner/Scan", and returns the gRPC status
+func (p grpcPolicy) intercept(ctx context.Context, fullMethod string, handler func(context.Context) error) error {
+	service, method := splitMethod(fullMethod)
+	if service == healthpb.Health_ServiceDesc.ServiceName {
+		return handler(ctx)
+	}
+
+	// The gRPC requests are served by the HTTP server of Twirp
+	r, ok := ctx.Value(httpRequestKey{}).(*http.Request)
+	if !ok {
+		return status.Error(codes.Internal, "no HTTP request")
+	}
+
+	start := time.Now()
+	var entry *AuditEntry
+	if p.auditLogger != nil {
+		entry = p.auditLogger.newEntry(r, start)
+		entry.Service = service
+		entry.Method = method
+		ctx = context.WithValue(ctx, auditEntryKey{}, entry)
+	}
+
+	err := rpc.ConvertToGRPCError(p.serve(ctx, r, service, handler))
+	code := grpcHTTPStatus(err)
+
+	// e.g. "Scanner" as the service name of Twirp
+	p.metrics.observe(service[strings.LastIndex(service, ".")+1:], method, strconv.Itoa(code), time.Since(start))
+	if entry != nil {
+		entry.Status = code
+		entry.DurationMS = time.Since(start).Milliseconds()
+		if err != nil {
+			entry.Error = status.Convert(err).Message()
+		}
+		p.auditLogger.write(entry)
+	}
+	return err
+}
+
+func (p grpcPolicy) serve(ctx context.Context, r *http.Request, service string, handler func(context.Context) error) error {
+	if p.limiter != nil {
+		if delay := p.limiter.reserve(clientAddr(r), time.Now()); delay > 0 {
+			p.metrics.rejectedRequests.WithLabelValues("rate_limit").Inc()
+			return resourceExhaustedError("rate limit exceeded", delay)
+		}
+	}
+	if p.requireClientCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		return twirp.NewError(twirp.Unauthenticated, "client certificate required")
+	}
+	if p.auth != nil {
+		if err := p.auth.Authenticate(r); err != nil {
+			// The details are not returned to clients
+			log.Logger.Debugf("Authentication error: %s", err)
+			return twirp.NewError(twirp.Unauthenticated, "invalid token")
+		}
+	}
+
+	switch service {
+	case rpcScanner.Scanner_ServiceDesc.ServiceName, rpcScanner.ScannerStream_ServiceDesc.ServiceName:
+		// Scans consume the memory
+		if !p.scanSlots.tryAcquire() {
+			p.metrics.rejectedRequests.WithLabelValues("concurrency").Inc()
+			return resourceExhaustedError("too many concurrent scans", time.Second)
+		}
+		defer p.scanSlots.release()
+		fallthrough
+	case rpcCache.Cache_ServiceDesc.ServiceName:
+		// Stop processing requests during DB update
+		p.dbUpdateWg.Wait()
+
+		// Wait for all requests to be processed before DB update
+		p.requestWg.Add(1)
+		defer p.requestWg.Done()
+	}
+	return handler(ctx)
+}
+
+// splitMethod returns the service and the method of "/trivy.scanner.v1.Scanner/Scan"
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return fullMethod, ""
+}
+
+// grpcHTTPStatus returns the HTTP status of Twirp for the same error
+func grpcHTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	code := twirp.DeadlineExceeded
+	if twerr, ok := rpc.ConvertFromGRPCError(err).(twirp.Error); ok {
+		code = twerr.Code()
+	}
+	return twirp.ServerHTTPStatusFromErrorCode(code)
+}
+
+// serverStream replaces the context of the stream with the one of the interceptor
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// newGRPCServer returns the server of the same services as Twirp, and the health checks of the standard gRPC
+func newGRPCServer(policy grpcPolicy, scanner rpcScanner.Scanner, cacheService rpcCache.Cache,
+	resultsService rpcResults.Results, versionService rpcVersion.Version, serverCache cache.Cache, cacheDir string) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(policy.unaryInterceptor),
+		grpc.StreamInterceptor(policy.streamInterceptor),
+		grpc.MaxRecvMsgSize(grpcMaxMessageSize),
+		grpc.MaxSendMsgSize(grpcMaxMessageSize),
+	)
+	rpcScanner.RegisterScannerServer(s, grpcScanner{scanner: scanner})
+	rpcScanner.RegisterScannerStreamServer(s, grpcScannerStream{scanner: scanner})
+	rpcCache.RegisterCacheServer(s, grpcCache{cache: cacheService})
+	if resultsService != nil {
+		rpcResults.RegisterResultsServer(s, grpcResults{results: resultsService})
+	}
+	rpcVersion.RegisterVersionServer(s, grpcVersion{version: versionService})
+
+	var services []string
+	for name := range s.GetServiceInfo() {
+		services = append(services, name)
+	}
+	healthpb.RegisterHealthServer(s, grpcHealth{services: services, cache: serverCache, cacheDir: cacheDir})
+	return s
+}
+
+// grpcHandler serves gRPC on the address of Twirp, passing the HTTP request to the interceptors
+func grpcHandler(s *grpc.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), httpRequestKey{}, r)))
+	})
+}
+
+// grpcScanner serves the scanner of Twirp over gRPC
+type grpcScanner struct {
+	rpcScanner.UnimplementedScannerServer
+	scanner rpcScanner.Scanner
+}
+
+func (s grpcScanner) Scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	return s.scanner.Scan(ctx, in)
+}
+
+func (s grpcScanner) InspectImage(ctx context.Context, in *rpcScanner.InspectImageRequest) (*rpcScanner.InspectImageResponse, error) {
+	return s.scanner.InspectImage(ctx, in)
+}
+
+// grpcScannerStream sends the result of the scanner in the messages of each result
+type grpcScannerStream struct {
+	rpcScanner.UnimplementedScannerStreamServer
+	scanner rpcScanner.Scanner
+}
+
+func (s grpcScannerStream) ScanStream(in *rpcScanner.ScanRequest, stream rpcScanner.ScannerStream_ScanStreamServer) error {
+	res, err := s.scanner.Scan(stream.Context(), in)
+	if err != nil {
+		return err
+	}
+	if err = stream.Send(&rpcScanner.ScanResponse{Os: res.Os}); err != nil {
+		return err
+	}
+	for _, result := range res.Results {
+		if err = stream.Send(&rpcScanner.ScanResponse{Results: []*rpcScanner.Result{result}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcCache serves the cache of Twirp over gRPC
+type grpcCache struct {
+	rpcCache.UnimplementedCacheServer
+	cache rpcCache.Cache
+}
+
+func (c grpcCache) PutArtifact(ctx context.Context, in *rpcCache.PutArtifactRequest) (*google_protobuf.Empty, error) {
+	return c.cache.PutArtifact(ctx, in)
+}
+
+func (c grpcCache) PutBlob(ctx context.Context, in *rpcCache.PutBlobRequest) (*google_protobuf.Empty, error) {
+	return c.cache.PutBlob(ctx, in)
+}
+
+func (c grpcCache) MissingBlobs(ctx context.Context, in *rpcCache.MissingBlobsRequest) (*rpcCache.MissingBlobsResponse, error) {
+	return c.cache.MissingBlobs(ctx, in)
+}
+
+func (c grpcCache) DeleteBlobs(ctx context.Context, in *rpcCache.DeleteBlobsRequest) (*google_protobuf.Empty, error) {
+	return c.cache.DeleteBlobs(ctx, in)
+}
+
+// grpcResults serves the queries of the result store over gRPC
+type grpcResults struct {
+	rpcResults.UnimplementedResultsServer
+	results rpcResults.Results
+}
+
+func (s grpcResults) ListScans(ctx context.Context, in *rpcResults.ListScansRequest) (*rpcResults.ListScansResponse, error) {
+	return s.results.ListScans(ctx, in)
+}
+
+func (s grpcResults) FindVulnerability(ctx context.Context, in *rpcResults.FindVulnerabilityRequest) (
+	*rpcResults.FindVulnerabilityResponse, error) {
+	return s.results.FindVulnerability(ctx, in)
+}
+
+// grpcVersion serves the version over gRPC
+type grpcVersion struct {
+	rpcVersion.UnimplementedVersionServer
+	version rpcVersion.Version
+}
+
+func (s grpcVersion) GetVersion(ctx context.Context, in *emptypb.Empty) (*rpcVersion.VersionResponse, error) {
+	return s.version.GetVersion(ctx, in)
+}
+
+// grpcHealth is ready under the same conditions as "/readyz", so that load balancers don't send requests
+// until the DB is downloaded
+type grpcHealth struct {
+	healthpb.UnimplementedHealthServer
+	services []string
+	cache    cache.Cache
+	cacheDir string
+}
+
+func (h grpcHealth) Check(ctx context.Context, in *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	// The empty service is the server as a whole
+	if in.Service != "" && !slices.Contains(h.services, in.Service) {
+		return nil, status.Errorf(codes.NotFound, "unknown service: %s", in.Service)
+	}
+	if err := ready(ctx, h.cache, h.cacheDir); err != nil {
+		log.Logger.Debugf("readiness check error: %s", err)
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	grpcMetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/trivy-db/pkg/db"
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/utils"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
+)
+
+func Test_newServeMux_grpc(t *testing.T) {
+	tests := []struct {
+		name     string
+		noDB     bool
+		token    string
+		call     func(ctx context.Context, conn *grpc.ClientConn) error
+		wantCode codes.Code
+	}{
+		{
+			name:  "cache",
+			token: "test",
+			call: func(ctx context.Context, conn *grpc.ClientConn) error {
+				res, err := rpcCache.NewCacheClient(conn).MissingBlobs(ctx, &rpcCache.MissingBlobsRequest{
+					ArtifactId: "sha256:e7d92cdc71feacf90708cb59182d0df1b911f8ae022d29e8e95d75ca6a99776a",
+				})
+				if err == nil && !res.MissingArtifact {
+					return status.Error(codes.Internal, "the artifact is not missing")
+				}
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:  "version",
+			token: "test",
+			call: func(ctx context.Context, conn *grpc.ClientConn) error {
+				res, err := rpcVersion.NewVersionClient(conn).GetVersion(ctx, &emptypb.Empty{})
+				if err == nil && res.Version != "dev" {
+					return status.Errorf(codes.Internal, "unexpected version: %s", res.Version)
+				}
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:  "sad path: invalid token",
+			token: "invalid",
+			call: func(ctx context.Context, conn *grpc.ClientConn) error {
+				_, err := rpcVersion.NewVersionClient(conn).GetVersion(ctx, &emptypb.Empty{})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "health check without token",
+			call: func(ctx context.Context, conn *grpc.ClientConn) error {
+				res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+					Service: rpcScanner.ScannerStream_ServiceDesc.ServiceName,
+				})
+				if err == nil && res.Status != healthpb.HealthCheckResponse_SERVING {
+					return status.Errorf(codes.Internal, "unexpected status: %s", res.Status)
+				}
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "sad path: DB is not downloaded",
+			noDB: true,
+			call: func(ctx context.Context, conn *grpc.ClientConn) error {
+				res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+				if err == nil && res.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+					return status.Errorf(codes.Internal, "unexpected status: %s", res.Status)
+				}
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "sad path: unknown service",
+			call: func(ctx context.Context, conn *grpc.ClientConn) error {
+				_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsCache, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+
+			cacheDir := t.TempDir()
+			if !tt.noDB {
+				require.NoError(t, os.MkdirAll(db.Dir(cacheDir), 0744))
+				_, err = utils.CopyFile("testdata/new.db", db.Path(cacheDir))
+				require.NoError(t, err)
+				_, err = utils.CopyFile("testdata/metadata.json", metadata.Path(cacheDir))
+				require.NoError(t, err)
+			}
+
+			ts := httptest.NewUnstartedServer(newServeMux(fsCache, &sync.WaitGroup{}, &sync.WaitGroup{}, ServerOption{
+				AppVersion: "dev",
+				CacheDir:   cacheDir,
+				Auth:       NewTokenAuthenticator("test", "Trivy-Token"),
+				Protocol:   rpc.ProtocolGRPC,
+			}))
+			ts.EnableHTTP2 = true
+			ts.StartTLS()
+			defer ts.Close()
+
+			conn, err := grpc.Dial(ts.Listener.Addr().String(),
+				grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = grpcMetadata.AppendToOutgoingContext(ctx, "trivy-token", tt.token)
+			}
+			err = tt.call(ctx, conn)
+			assert.Equal(t, tt.wantCode, status.Code(err), err)
+
+			// Twirp is served on the same address
+			resp, err := ts.Client().Get(ts.URL + "/healthz")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func Test_grpcPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		service        string
+		limits         Limits
+		calls          int
+		wantCode       codes.Code
+		wantRetryAfter bool
+	}{
+		{
+			name:     "happy path",
+			service:  rpcScanner.ScannerStream_ServiceDesc.ServiceName,
+			calls:    2,
+			wantCode: codes.OK,
+		},
+		{
+			name:    "sad path: rate limit exceeded",
+			service: rpcCache.Cache_ServiceDesc.ServiceName,
+			limits: Limits{
+				RateLimit:      0.1,
+				RateLimitBurst: 1,
+			},
+			calls:          2,
+			wantCode:       codes.ResourceExhausted,
+			wantRetryAfter: true,
+		},
+		{
+			name:    "sad path: too many concurrent scans",
+			service: rpcScanner.Scanner_ServiceDesc.ServiceName,
+			limits: Limits{
+				MaxConcurrentScans: 1,
+			},
+			calls:          2,
+			wantCode:       codes.ResourceExhausted,
+			wantRetryAfter: true,
+		},
+		{
+			name:    "health checks are not limited",
+			service: healthpb.Health_ServiceDesc.ServiceName,
+			limits: Limits{
+				RateLimit:      0.1,
+				RateLimitBurst: 1,
+			},
+			calls:    2,
+			wantCode: codes.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := grpcPolicy{
+				scanSlots:  newSlots(tt.limits.MaxConcurrentScans),
+				dbUpdateWg: &sync.WaitGroup{},
+				requestWg:  &sync.WaitGroup{},
+				metrics:    newMetrics(t.TempDir()),
+			}
+			if tt.limits.RateLimit > 0 {
+				p.limiter = newRateLimiter(tt.limits.RateLimit, tt.limits.RateLimitBurst)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/"+tt.service+"/Method", nil)
+			ctx := context.WithValue(context.Background(), httpRequestKey{}, r)
+
+			// The scans are still in progress when the next request is received
+			done := make(chan struct{})
+			defer close(done)
+			started := make(chan struct{}, tt.calls)
+
+			var err error
+			for i := 0; i < tt.calls; i++ {
+				errCh := make(chan error, 1)
+				go func() {
+					errCh <- p.intercept(ctx, "/"+tt.service+"/Method", func(context.Context) error {
+						started <- struct{}{}
+						<-done
+						return nil
+					})
+				}()
+				select {
+				case <-started:
+				case err = <-errCh:
+				case <-time.After(5 * time.Second):
+					require.Fail(t, "timeout")
+				}
+			}
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			twerr, ok := rpc.ConvertFromGRPCError(err).(twirp.Error)
+			if tt.wantRetryAfter {
+				require.True(t, ok)
+				assert.NotEmpty(t, twerr.Meta(rpc.RetryAfterMetaKey))
+			}
+		})
+	}
+}
+
+type fakeScanStream struct {
+	rpcScanner.ScannerStream_ScanStreamServer
+	sent []*rpcScanner.ScanResponse
+}
+
+func (s *fakeScanStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeScanStream) Send(res *rpcScanner.ScanResponse) error {
+	s.sent = append(s.sent, res)
+	return nil
+}
+
+type failedScanner struct {
+	fakeScanner
+	err error
+}
+
+func (s failedScanner) Scan(context.Context, *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	return nil, s.err
+}
+
+func Test_grpcScannerStream_ScanStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		res     *rpcScanner.ScanResponse
+		err     error
+		want    []*rpcScanner.ScanResponse
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			res: &rpcScanner.ScanResponse{
+				Os: &common.OS{Family: "alpine", Name: "3.15.4"},
+				Results: []*rpcScanner.Result{
+					{Target: "alpine:3.15 (alpine 3.15.4)"},
+					{Target: "app/package-lock.json"},
+				},
+			},
+			want: []*rpcScanner.ScanResponse{
+				{Os: &common.OS{Family: "alpine", Name: "3.15.4"}},
+				{Results: []*rpcScanner.Result{{Target: "alpine:3.15 (alpine 3.15.4)"}}},
+				{Results: []*rpcScanner.Result{{Target: "app/package-lock.json"}}},
+			},
+		},
+		{
+			name: "no results",
+			res:  &rpcScanner.ScanResponse{},
+			want: []*rpcScanner.ScanResponse{{}},
+		},
+		{
+			name:    "sad path",
+			err:     twirp.NewError(twirp.Unavailable, "db update"),
+			wantErr: "db update",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &fakeScanStream{}
+			var scanner rpcScanner.Scanner = fakeScanner{res: tt.res}
+			if tt.err != nil {
+				scanner = failedScanner{err: tt.err}
+			}
+			s := grpcScannerStream{scanner: scanner}
+			err := s.ScanStream(&rpcScanner.ScanRequest{Target: "alpine:3.15"}, stream)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stream.sent)
+		})
+	}
+}
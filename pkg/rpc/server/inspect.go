@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/image"
+	ftypes "github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// scannerService implements the scanner service with the scan server and the image inspector
+type scannerService struct {
+	scanHandler
+	imageInspector
+}
+
+// imageInspector pulls images from the allowed registries and analyzes them in the server,
+// for clients which can't reach the registries at all. The analysis results are stored in the cache,
+// and the clients scan them with Scan as usual.
+type imageInspector struct {
+	cache       cache.ArtifactCache
+	registries  registryAllowlist
+	options     []remote.Option
+	coordinator analysisCoordinator
+}
+
+func newImageInspector(c cache.ArtifactCache, allowedRegistries []string, transport http.RoundTripper) imageInspector {
+	return imageInspector{
+		cache:      c,
+		registries: newRegistryAllowlist(allowedRegistries),
+		options:    registryOptions(transport),
+
+		// The same image pulled by multiple replicas at the same time is analyzed by one of them
+		coordinator: newAnalysisCoordinator(c),
+	}
+}
+
+// InspectImage pulls the image and analyzes it in the server
+func (i imageInspector) InspectImage(ctx context.Context, in *rpcScanner.InspectImageRequest) (*rpcScanner.InspectImageResponse, error) {
+	ref, err := name.ParseReference(in.ImageName)
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("image_name", err.Error())
+	}
+
+	if len(i.registries) == 0 {
+		return nil, twirp.NewError(twirp.PermissionDenied, "pulling images is not enabled in the server")
+	} else if registry := ref.Context().RegistryStr(); !i.registries.allowed(registry) {
+		return nil, twirp.NewError(twirp.PermissionDenied, registry+" is not allowed to be pulled by the server")
+	}
+
+	var disabledAnalyzers []analyzer.Type
+	for _, a := range in.DisabledAnalyzers {
+		disabledAnalyzers = append(disabledAnalyzers, analyzer.Type(a))
+	}
+	artifactRef, err := i.inspect(ctx, in.ImageName, ref, artifact.Option{
+		DisabledAnalyzers: disabledAnalyzers,
+		SkipFiles:         in.SkipFiles,
+		SkipDirs:          in.SkipDirs,
+		Offline:           in.Offline,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := json.Marshal(artifactRef.ImageMetadata.ConfigFile)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to marshal the image config: %w", err)
+	}
+
+	return &rpcScanner.InspectImageResponse{
+		ArtifactId:  artifactRef.ID,
+		BlobIds:     artifactRef.BlobIDs,
+		ImageId:     artifactRef.ImageMetadata.ID,
+		DiffIds:     artifactRef.ImageMetadata.DiffIDs,
+		RepoTags:    artifactRef.ImageMetadata.RepoTags,
+		RepoDigests: artifactRef.ImageMetadata.RepoDigests,
+		ConfigFile:  configFile,
+	}, nil
+}
+
+// inspect pulls the image and analyzes it regardless of the allowed registries
+func (i imageInspector) inspect(ctx context.Context, imageName string, ref name.Reference,
+	opt artifact.Option) (ftypes.ArtifactReference, error) {
+	img, err := newRemoteImage(ctx, imageName, ref, i.options)
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to pull %s: %w", imageName, err)
+	}
+
+	art, err := tartifact.NewImageArtifact(img, i.cache, tartifact.Option{Option: opt})
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to initialize the image artifact: %w", err)
+	}
+
+	imageID, err := img.ID()
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to get the image ID: %w", err)
+	}
+
+	// The layers analyzed by another replica are found in the cache once the lock is released
+	var artifactRef ftypes.ArtifactReference
+	err = i.coordinator.do(ctx, imageID, nil, func() (err error) {
+		artifactRef, err = art.Inspect(ctx)
+		return err
+	})
+	if err != nil {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("unable to analyze %s: %w", imageName, err)
+	}
+	return artifactRef, nil
+}
+
+// remoteImage is the image pulled from the registry by the server
+type remoteImage struct {
+	v1.Image
+	name   string
+	ref    name.Reference
+	digest v1.Hash
+}
+
+func newRemoteImage(ctx context.Context, imageName string, ref name.Reference, options []remote.Option) (remoteImage, error) {
+	opts := append([]remote.Option{remote.WithContext(ctx)}, options...)
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return remoteImage{}, xerrors.Errorf("failed to get the manifest: %w", err)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return remoteImage{}, xerrors.Errorf("failed to get the image: %w", err)
+	}
+	return remoteImage{
+		Image:  img,
+		name:   imageName,
+		ref:    ref,
+		digest: desc.Digest,
+	}, nil
+}
+
+func (img remoteImage) Name() string {
+	return img.name
+}
+
+func (img remoteImage) ID() (string, error) {
+	return image.ID(img)
+}
+
+// LayerIDs returns a list of uncompressed layer IDs
+func (img remoteImage) LayerIDs() ([]string, error) {
+	return image.LayerIDs(img)
+}
+
+func (img remoteImage) RepoTags() []string {
+	if _, ok := img.ref.(name.Tag); !ok {
+		return nil
+	}
+	return []string{img.name}
+}
+
+func (img remoteImage) RepoDigests() []string {
+	return []string{img.ref.Context().Digest(img.digest.String()).Name()}
+}
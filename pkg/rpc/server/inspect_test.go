@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/aquasecurity/fanal/cache"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+func Test_imageInspector_InspectImage(t *testing.T) {
+	upstream := httptest.NewServer(registry.New())
+	defer upstream.Close()
+	u, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	host := u.Host
+
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/library/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	manifestDigest, err := img.Digest()
+	require.NoError(t, err)
+	configFile, err := img.ConfigFile()
+	require.NoError(t, err)
+	var wantDiffIDs []string
+	for _, d := range configFile.RootFS.DiffIDs {
+		wantDiffIDs = append(wantDiffIDs, d.String())
+	}
+
+	tests := []struct {
+		name              string
+		allowedRegistries []string
+		imageName         string
+		wantErrCode       twirp.ErrorCode
+	}{
+		{
+			name:              "happy path",
+			allowedRegistries: []string{host},
+			imageName:         host + "/library/app:1.0",
+		},
+		{
+			name:              "wildcard",
+			allowedRegistries: []string{"127.0.0.1:*"},
+			imageName:         host + "/library/app:1.0",
+		},
+		{
+			name:              "sad path: not allowed",
+			allowedRegistries: []string{"ghcr.io"},
+			imageName:         host + "/library/app:1.0",
+			wantErrCode:       twirp.PermissionDenied,
+		},
+		{
+			name:        "sad path: no registries",
+			imageName:   host + "/library/app:1.0",
+			wantErrCode: twirp.PermissionDenied,
+		},
+		{
+			name:              "sad path: invalid image name",
+			allowedRegistries: []string{host},
+			imageName:         "app:1.0:latest",
+			wantErrCode:       twirp.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			defer c.Close()
+
+			i := newImageInspector(c, tt.allowedRegistries, nil)
+			got, err := i.InspectImage(context.Background(), &rpcScanner.InspectImageRequest{
+				ImageName: tt.imageName,
+			})
+			if tt.wantErrCode != "" {
+				require.Error(t, err)
+				var twirpErr twirp.Error
+				require.ErrorAs(t, err, &twirpErr)
+				assert.Equal(t, tt.wantErrCode, twirpErr.Code())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, wantDiffIDs, got.DiffIds)
+			// The layers and the history of the image config
+			assert.Len(t, got.BlobIds, 3)
+			assert.Equal(t, []string{tt.imageName}, got.RepoTags)
+			assert.Equal(t, []string{host + "/library/app@" + manifestDigest.String()}, got.RepoDigests)
+
+			var gotConfig v1.ConfigFile
+			require.NoError(t, json.Unmarshal(got.ConfigFile, &gotConfig))
+			assert.Equal(t, configFile.RootFS, gotConfig.RootFS)
+
+			// The analysis results are stored in the server cache
+			_, err = c.GetArtifact(got.ArtifactId)
+			require.NoError(t, err)
+			missingArtifact, missingBlobs, err := c.MissingBlobs(got.ArtifactId, got.BlobIds)
+			require.NoError(t, err)
+			assert.False(t, missingArtifact)
+			assert.Empty(t, missingBlobs)
+		})
+	}
+}
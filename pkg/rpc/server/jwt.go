@@ -0,0 +1,336 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+const (
+	// The keys are fetched again on an unknown key ID at most once per the interval, as they are rotated by the issuer
+	jwksRefreshInterval = time.Minute
+	jwksFetchTimeout    = 10 * time.Second
+
+	// The failures to fetch the keys are returned without fetching again until the backoff doubling up to the maximum
+	jwksMinBackoff = time.Second
+	jwksMaxBackoff = time.Minute
+)
+
+// The symmetric algorithms are not allowed as the keys are public
+var jwtValidMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// JWTOption holds the options to validate JWTs, e.g. ID tokens issued to CI pipelines by GitHub Actions or GitLab CI
+type JWTOption struct {
+	Issuer   string
+	Audience string
+
+	// JWKSURL is discovered from the issuer with OpenID Connect Discovery if empty
+	JWKSURL string
+
+	// Subjects restrict the pipelines which can authenticate, e.g. "repo:myorg/*", and any subject is allowed if empty
+	Subjects []string
+
+	TokenHeader string
+}
+
+// JWTAuthenticator authenticates requests with short-lived JWTs signed by the keys of the issuer
+type JWTAuthenticator struct {
+	option   JWTOption
+	subjects []*regexp.Regexp
+	client   *http.Client
+
+	// The keys are fetched once for the concurrent requests, and outside the lock of the cached keys
+	group singleflight.Group
+
+	// jwksURL is discovered in the fetch, which runs one at a time
+	jwksURL string
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+	fetchErr  error
+	failedAt  time.Time
+	backoff   time.Duration
+}
+
+// NewJWTAuthenticator returns the authenticator, and an error if neither the issuer nor the JWKS URL is given
+func NewJWTAuthenticator(option JWTOption) (*JWTAuthenticator, error) {
+	if option.Issuer == "" && option.JWKSURL == "" {
+		return nil, xerrors.New("the issuer or the JWKS URL is required")
+	}
+	for _, u := range []string{option.Issuer, option.JWKSURL} {
+		if u == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, xerrors.Errorf("invalid URL: %s", u)
+		}
+	}
+
+	var subjects []*regexp.Regexp
+	for _, s := range option.Subjects {
+		// "*" matches any characters including "/"
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(s), `\*`, ".*")
+		subjects = append(subjects, regexp.MustCompile("^"+pattern+"$"))
+	}
+	return &JWTAuthenticator{
+		option:   option,
+		subjects: subjects,
+		client:   &http.Client{Timeout: jwksFetchTimeout, Transport: utils.HTTPTransport(false)},
+		jwksURL:  option.JWKSURL,
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) error {
+	// e.g. "Bearer eyJhbGciOi..."
+	token := strings.TrimPrefix(r.Header.Get(a.option.TokenHeader), "Bearer ")
+	if token == "" {
+		return xerrors.New("no JWT")
+	}
+
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.key(kid)
+	}, jwt.WithValidMethods(jwtValidMethods))
+	if err != nil {
+		return xerrors.Errorf("invalid JWT: %w", err)
+	}
+
+	switch {
+	case claims.ExpiresAt == nil:
+		return xerrors.New("JWT without expiration is not allowed")
+	case a.option.Issuer != "" && !claims.VerifyIssuer(a.option.Issuer, true):
+		return xerrors.Errorf("unexpected issuer: %s", claims.Issuer)
+	case a.option.Audience != "" && !claims.VerifyAudience(a.option.Audience, true):
+		return xerrors.Errorf("unexpected audience: %s", strings.Join(claims.Audience, ","))
+	case !a.allowedSubject(claims.Subject):
+		return xerrors.Errorf("subject not allowed: %s", claims.Subject)
+	}
+	return nil
+}
+
+func (a *JWTAuthenticator) allowedSubject(subject string) bool {
+	if len(a.subjects) == 0 {
+		return true
+	}
+	for _, s := range a.subjects {
+		if s.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the public key for the key ID, and fetches the keys again if the key ID is unknown
+func (a *JWTAuthenticator) key(kid string) (interface{}, error) {
+	a.mu.Lock()
+	key, ok := a.lookup(kid)
+	fresh := a.keys != nil && time.Since(a.fetchedAt) < jwksRefreshInterval
+	fetchErr := a.fetchErr
+	backingOff := fetchErr != nil && time.Since(a.failedAt) < a.backoff
+	a.mu.Unlock()
+
+	switch {
+	case ok:
+		return key, nil
+	case fresh:
+		return nil, xerrors.Errorf("unknown key ID: %s", kid)
+	case backingOff:
+		return nil, xerrors.Errorf("unable to fetch the JWKS: %w", fetchErr)
+	}
+
+	// The fetch is not canceled by the request which happens to start it
+	if _, err, _ := a.group.Do("jwks", func() (interface{}, error) {
+		return nil, a.refresh(context.Background())
+	}); err != nil {
+		return nil, xerrors.Errorf("unable to fetch the JWKS: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if key, ok = a.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, xerrors.Errorf("unknown key ID: %s", kid)
+}
+
+// refresh fetches the keys, and records the failure so that the issuer is not flooded with the requests
+func (a *JWTAuthenticator) refresh(ctx context.Context) error {
+	keys, err := a.fetchKeys(ctx)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	if err != nil {
+		a.backoff *= 2
+		if a.backoff < jwksMinBackoff {
+			a.backoff = jwksMinBackoff
+		} else if a.backoff > jwksMaxBackoff {
+			a.backoff = jwksMaxBackoff
+		}
+		a.fetchErr, a.failedAt = err, now
+		return err
+	}
+	a.keys, a.fetchedAt = keys, now
+	a.fetchErr, a.backoff = nil, 0
+	log.Logger.Debugf("%d keys fetched from the JWKS", len(keys))
+	return nil
+}
+
+func (a *JWTAuthenticator) lookup(kid string) (interface{}, bool) {
+	// The key ID can be omitted if the issuer has only one key
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[kid]
+	return key, ok
+}
+
+func (a *JWTAuthenticator) fetchKeys(ctx context.Context) (map[string]interface{}, error) {
+	if a.jwksURL == "" {
+		u, err := a.discoverJWKSURL(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("OpenID Connect discovery error: %w", err)
+		}
+		a.jwksURL = u
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := a.getJSON(ctx, a.jwksURL, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Logger.Debugf("Unable to parse the key %s in the JWKS: %s", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// discoverJWKSURL returns the JWKS URL in the OpenID Provider Configuration of the issuer
+func (a *JWTAuthenticator) discoverJWKSURL(ctx context.Context) (string, error) {
+	var conf struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	u := strings.TrimSuffix(a.option.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := a.getJSON(ctx, u, &conf); err != nil {
+		return "", err
+	} else if conf.JWKSURI == "" {
+		return "", xerrors.Errorf("%s doesn't contain jwks_uri", u)
+	}
+	return conf.JWKSURI, nil
+}
+
+func (a *JWTAuthenticator) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return xerrors.Errorf("HTTP request error: %w", err)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("HTTP error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("HTTP status %d from %s", resp.StatusCode, u)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return xerrors.Errorf("JSON decode error: %w", err)
+	}
+	return nil
+}
+
+// jsonWebKey is a public key in the JWKS (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, xerrors.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, xerrors.New("the point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, xerrors.Errorf("unsupported key type: %s", k.Kty)
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
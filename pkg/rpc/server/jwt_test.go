@@ -0,0 +1,315 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	jwks := map[string][]map[string]string{
+		"keys": {
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+		},
+	}
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	issuer = ts.URL
+
+	validClaims := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   "repo:myorg/myrepo:ref:refs/heads/main",
+			Audience:  jwt.ClaimStrings{"trivy"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.RegisteredClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	noExpiration := validClaims()
+	noExpiration.ExpiresAt = nil
+	anotherAudience := validClaims()
+	anotherAudience.Audience = jwt.ClaimStrings{"another"}
+	anotherIssuer := validClaims()
+	anotherIssuer.Issuer = "https://another.example.com"
+	anotherOrg := validClaims()
+	anotherOrg.Subject = "repo:another/myrepo:ref:refs/heads/main"
+
+	tests := []struct {
+		name    string
+		option  JWTOption
+		header  string
+		wantErr string
+	}{
+		{
+			name:   "happy path with RSA",
+			option: JWTOption{Issuer: issuer, Audience: "trivy"},
+			header: sign(jwt.SigningMethodRS256, "rsa", rsaKey, validClaims()),
+		},
+		{
+			name:   "happy path with ECDSA and JWKS URL",
+			option: JWTOption{JWKSURL: issuer + "/keys", Audience: "trivy"},
+			header: "Bearer " + sign(jwt.SigningMethodES256, "ec", ecKey, validClaims()),
+		},
+		{
+			name:   "happy path with subjects",
+			option: JWTOption{Issuer: issuer, Subjects: []string{"repo:another/*", "repo:myorg/*"}},
+			header: sign(jwt.SigningMethodRS256, "rsa", rsaKey, validClaims()),
+		},
+		{
+			name:    "sad path: no token",
+			option:  JWTOption{Issuer: issuer},
+			wantErr: "no JWT",
+		},
+		{
+			name:    "sad path: expired",
+			option:  JWTOption{Issuer: issuer},
+			header:  sign(jwt.SigningMethodRS256, "rsa", rsaKey, expired),
+			wantErr: "token is expired",
+		},
+		{
+			name:    "sad path: no expiration",
+			option:  JWTOption{Issuer: issuer},
+			header:  sign(jwt.SigningMethodRS256, "rsa", rsaKey, noExpiration),
+			wantErr: "JWT without expiration is not allowed",
+		},
+		{
+			name:    "sad path: another audience",
+			option:  JWTOption{Issuer: issuer, Audience: "trivy"},
+			header:  sign(jwt.SigningMethodRS256, "rsa", rsaKey, anotherAudience),
+			wantErr: "unexpected audience: another",
+		},
+		{
+			name:    "sad path: another issuer",
+			option:  JWTOption{JWKSURL: issuer + "/keys", Issuer: issuer},
+			header:  sign(jwt.SigningMethodRS256, "rsa", rsaKey, anotherIssuer),
+			wantErr: "unexpected issuer",
+		},
+		{
+			name:    "sad path: subject not allowed",
+			option:  JWTOption{Issuer: issuer, Subjects: []string{"repo:myorg/*"}},
+			header:  sign(jwt.SigningMethodRS256, "rsa", rsaKey, anotherOrg),
+			wantErr: "subject not allowed",
+		},
+		{
+			name:    "sad path: unknown key ID",
+			option:  JWTOption{Issuer: issuer},
+			header:  sign(jwt.SigningMethodRS256, "unknown", rsaKey, validClaims()),
+			wantErr: "unknown key ID: unknown",
+		},
+		{
+			name:    "sad path: encryption key",
+			option:  JWTOption{Issuer: issuer},
+			header:  sign(jwt.SigningMethodRS256, "enc", rsaKey, validClaims()),
+			wantErr: "unknown key ID: enc",
+		},
+		{
+			name:    "sad path: symmetric algorithm",
+			option:  JWTOption{Issuer: issuer},
+			header:  sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims()),
+			wantErr: "signing method HS256 is invalid",
+		},
+		{
+			name:    "sad path: signed by another key",
+			option:  JWTOption{Issuer: issuer},
+			header:  sign(jwt.SigningMethodES256, "ec", mustECKey(t), validClaims()),
+			wantErr: "invalid JWT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.option.TokenHeader = "Authorization"
+			a, err := NewJWTAuthenticator(tt.option)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/twirp/trivy.scanner.v1.Scanner/Scan", nil)
+			req.Header.Set("Authorization", tt.header)
+
+			err = a.Authenticate(req)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestJWTAuthenticator_refresh(t *testing.T) {
+	oldKey := mustECKey(t)
+	newKey := mustECKey(t)
+
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	currentKey := oldKey
+	var fetched int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		_ = json.NewEncoder(w).Encode(map[string][]map[string]string{
+			"keys": {{"kty": "EC", "crv": "P-256", "x": encode(currentKey.X), "y": encode(currentKey.Y)}},
+		})
+	}))
+	defer ts.Close()
+
+	a, err := NewJWTAuthenticator(JWTOption{JWKSURL: ts.URL, TokenHeader: "Trivy-Token"})
+	require.NoError(t, err)
+	authenticate := func(key *ecdsa.PrivateKey, kid string) error {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Trivy-Token", s)
+		return a.Authenticate(req)
+	}
+
+	// The key ID can be omitted in the JWKS with one key
+	require.NoError(t, authenticate(oldKey, ""))
+	require.NoError(t, authenticate(oldKey, ""))
+	assert.Equal(t, 1, fetched)
+
+	// The keys are not fetched again within the interval even if they are rotated
+	currentKey = newKey
+	assert.Error(t, authenticate(newKey, "new"))
+	assert.Equal(t, 1, fetched)
+
+	a.fetchedAt = time.Now().Add(-jwksRefreshInterval)
+	assert.Error(t, authenticate(newKey, "new"))
+	assert.Equal(t, 2, fetched)
+	assert.NoError(t, authenticate(newKey, ""))
+}
+
+func TestJWTAuthenticator_backoff(t *testing.T) {
+	var fetched int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		// The concurrent requests wait for the same fetch
+		time.Sleep(100 * time.Millisecond)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	a, err := NewJWTAuthenticator(JWTOption{JWKSURL: ts.URL, TokenHeader: "Trivy-Token"})
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString(mustECKey(t))
+	require.NoError(t, err)
+	authenticate := func() error {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Trivy-Token", token)
+		return a.Authenticate(req)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.ErrorContains(t, authenticate(), "HTTP status 503")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+
+	// The failure is returned without fetching again until the backoff
+	assert.ErrorContains(t, authenticate(), "HTTP status 503")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+	assert.Equal(t, jwksMinBackoff, a.backoff)
+
+	a.failedAt = time.Now().Add(-jwksMinBackoff)
+	assert.ErrorContains(t, authenticate(), "HTTP status 503")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetched))
+	assert.Equal(t, 2*jwksMinBackoff, a.backoff)
+}
+
+func TestNewJWTAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  JWTOption
+		wantErr string
+	}{
+		{
+			name:   "issuer",
+			option: JWTOption{Issuer: "https://token.actions.githubusercontent.com"},
+		},
+		{
+			name:   "JWKS URL",
+			option: JWTOption{JWKSURL: "https://gitlab.com/oauth/discovery/keys"},
+		},
+		{
+			name:    "sad path: neither issuer nor JWKS URL",
+			option:  JWTOption{Audience: "trivy"},
+			wantErr: "the issuer or the JWKS URL is required",
+		},
+		{
+			name:    "sad path: relative URL",
+			option:  JWTOption{Issuer: "token.actions.githubusercontent.com"},
+			wantErr: "invalid URL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTAuthenticator(tt.option)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func mustECKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/go-units"
+	"github.com/twitchtv/twirp"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/secret"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/handler"
+	"github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/log"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+const (
+	// LayerPathPrefix is the path prefix of the layer analysis.
+	// Clients upload uncompressed layers with "PUT /layers/<blob ID>", and the analysis results are stored in the cache.
+	LayerPathPrefix = "/layers/"
+
+	// The number of files analyzed in parallel per layer
+	layerAnalysisParallel = 5
+)
+
+// errLayerTooLarge is returned from the reads of the uploaded layer beyond the limit
+var errLayerTooLarge = xerrors.New("the layer is too large")
+
+// LayerResponse is returned when the layer is analyzed
+type LayerResponse struct {
+	// OS is detected in the layer, and it is used to analyze the image config on the client
+	OS *types.OS `json:",omitempty"`
+}
+
+// layerAnalyzer analyzes layers uploaded by thin clients, e.g. small CI runners which can't analyze large images.
+// Layers are analyzed in the same way as clients do, so that the blobs stored in the cache are the same.
+// The same layers uploaded to multiple replicas at the same time are analyzed by one of them.
+type layerAnalyzer struct {
+	cache       cache.Cache
+	coordinator analysisCoordinator
+
+	// maxLayerSize limits the uploaded layers before and after the decompression, and 0 means unlimited
+	maxLayerSize int64
+}
+
+func newLayerAnalyzer(c cache.Cache, maxLayerSize int64) layerAnalyzer {
+	// Secrets are detected with the builtin rules
+	if err := secret.RegisterSecretAnalyzer(secret.ScannerOption{}); err != nil {
+		log.Logger.Warnf("Secrets are not detected in uploaded layers: %s", err)
+	}
+	return layerAnalyzer{
+		cache:        c,
+		coordinator:  newAnalysisCoordinator(c),
+		maxLayerSize: maxLayerSize,
+	}
+}
+
+func (a layerAnalyzer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		rpcScanner.WriteError(w, twirp.NewError(twirp.BadRoute, "only PUT is supported"))
+		return
+	}
+
+	// e.g. /layers/sha256:3e2f...?diff_id=sha256:a1b2...
+	blobID := strings.TrimPrefix(r.URL.Path, LayerPathPrefix)
+	query := r.URL.Query()
+	diffID := query.Get("diff_id")
+	if blobID == "" || diffID == "" {
+		rpcScanner.WriteError(w, twirp.InvalidArgumentError("diff_id", "the blob ID and the diff ID are required"))
+		return
+	}
+	baseLayer, _ := strconv.ParseBool(query.Get("base_layer"))
+	offline, _ := strconv.ParseBool(query.Get("offline"))
+
+	opt := artifact.Option{
+		SkipFiles: query["skip_file"],
+		SkipDirs:  query["skip_dir"],
+		Offline:   offline,
+	}
+	for _, t := range query["disabled_analyzer"] {
+		opt.DisabledAnalyzers = append(opt.DisabledAnalyzers, analyzer.Type(t))
+	}
+
+	// The uploads are limited before and after the decompression
+	var body io.Reader = r.Body
+	if a.maxLayerSize > 0 {
+		if r.ContentLength > a.maxLayerSize {
+			rpcScanner.WriteError(w, twirp.InvalidArgumentError("body", a.tooLarge()))
+			return
+		}
+		body = a.limit(http.MaxBytesReader(w, r.Body, a.maxLayerSize+1))
+	}
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(body)
+		if err != nil {
+			rpcScanner.WriteError(w, twirp.InvalidArgumentError("body", err.Error()))
+			return
+		}
+		defer gr.Close()
+		// e.g. gzip bombs
+		body = a.limit(gr)
+	}
+
+	var blobInfo types.BlobInfo
+	cached := func() bool {
+		b, err := a.cache.GetBlob(blobID)
+		if err != nil || b.SchemaVersion != types.BlobJSONSchemaVersion || b.DiffID != diffID {
+			return false
+		}
+		blobInfo = b
+		return true
+	}
+	err := a.coordinator.do(r.Context(), blobID, cached, func() (err error) {
+		blobInfo, err = a.analyze(r.Context(), blobID, diffID, query.Get("digest"), baseLayer, opt, body)
+		return err
+	})
+	if err != nil {
+		log.Logger.Debugf("Layer analysis error (%s): %s", diffID, err)
+		var twerr twirp.Error
+		if xerrors.Is(err, errLayerTooLarge) {
+			rpcScanner.WriteError(w, twirp.InvalidArgumentError("body", a.tooLarge()))
+			return
+		} else if xerrors.As(err, &twerr) {
+			rpcScanner.WriteError(w, twerr)
+			return
+		}
+		rpcScanner.WriteError(w, twirp.InternalErrorWith(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(LayerResponse{OS: blobInfo.OS}); err != nil {
+		log.Logger.Debugf("Unable to send the layer analysis result (%s): %s", diffID, err)
+	}
+}
+
+// limit fails the reads beyond the limit of the layer size with errLayerTooLarge
+func (a layerAnalyzer) limit(r io.Reader) io.Reader {
+	if a.maxLayerSize <= 0 {
+		return r
+	}
+	return &layerSizeLimit{r: r, remaining: a.maxLayerSize}
+}
+
+func (a layerAnalyzer) tooLarge() string {
+	return fmt.Sprintf("the layer is larger than %s, see '--max-layer-size'", units.BytesSize(float64(a.maxLayerSize)))
+}
+
+type layerSizeLimit struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *layerSizeLimit) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, errLayerTooLarge
+	}
+	return n, err
+}
+
+func (a layerAnalyzer) analyze(ctx context.Context, blobID, diffID, digest string, baseLayer bool, opt artifact.Option,
+	body io.Reader) (types.BlobInfo, error) {
+	ag := analyzer.NewAnalyzerGroup(opt.AnalyzerGroup, opt.DisabledAnalyzers)
+	handlerManager, err := handler.NewManager(opt)
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("handler init error: %w", err)
+	}
+
+	// The blob ID must be calculated in the same way as the client, otherwise other clients can't find the blob
+	wantBlobID, err := cache.CalcKey(diffID, ag.AnalyzerVersions(), handlerManager.Versions(), opt)
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("cache key error: %w", err)
+	} else if blobID != wantBlobID {
+		return types.BlobInfo{}, twirp.InvalidArgumentError("blob_id", "the blob ID doesn't match, the versions of the client and the server may differ")
+	}
+
+	// Secrets are not detected in base layers, in the same way as the client-side analysis
+	var disabled []analyzer.Type
+	if baseLayer {
+		disabled = append(disabled, analyzer.TypeSecret)
+	}
+
+	// The diff ID is verified so that the cache isn't poisoned by the layer of another diff ID
+	h := sha256.New()
+	tr := io.TeeReader(body, h)
+
+	var wg sync.WaitGroup
+	result := analyzer.NewAnalysisResult()
+	limit := semaphore.NewWeighted(layerAnalysisParallel)
+	analysisOpts := analyzer.AnalysisOptions{Offline: opt.Offline}
+	opqDirs, whFiles, err := tartifact.NewLayerTar(opt.SkipFiles, opt.SkipDirs).Walk(tr,
+		func(filePath string, info os.FileInfo, opener analyzer.Opener) error {
+			if err := ag.AnalyzeFile(ctx, &wg, limit, result, "", filePath, info, opener, disabled, analysisOpts); err != nil {
+				return xerrors.Errorf("failed to analyze %s: %w", filePath, err)
+			}
+			return nil
+		})
+	wg.Wait()
+	if err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("walk error: %w", err)
+	}
+
+	// The padding after the end of the archive is included in the diff ID
+	if _, err = io.Copy(io.Discard, tr); err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("read error: %w", err)
+	}
+	if got := fmt.Sprintf("sha256:%x", h.Sum(nil)); got != diffID {
+		return types.BlobInfo{}, twirp.InvalidArgumentError("diff_id", fmt.Sprintf("the layer doesn't match the diff ID (got %s)", got))
+	}
+
+	// Sort the analysis result for consistent results
+	result.Sort()
+
+	blobInfo := types.BlobInfo{
+		SchemaVersion:   types.BlobJSONSchemaVersion,
+		Digest:          digest,
+		DiffID:          diffID,
+		OS:              result.OS,
+		Repository:      result.Repository,
+		PackageInfos:    result.PackageInfos,
+		Applications:    result.Applications,
+		Secrets:         result.Secrets,
+		OpaqueDirs:      opqDirs,
+		WhiteoutFiles:   whFiles,
+		CustomResources: result.CustomResources,
+
+		// For Red Hat
+		BuildInfo: result.BuildInfo,
+	}
+
+	if err = handlerManager.PostHandle(ctx, result, &blobInfo); err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("post handler error: %w", err)
+	}
+
+	if err = a.cache.PutBlob(blobID, blobInfo); err != nil {
+		return types.BlobInfo{}, xerrors.Errorf("unable to store the blob: %w", err)
+	}
+	return blobInfo, nil
+}
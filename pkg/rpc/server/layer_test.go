@@ -0,0 +1,209 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/artifact"
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/handler"
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+func newTestLayer(t *testing.T, files map[string]string) ([]byte, string) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes(), fmt.Sprintf("sha256:%x", sha256.Sum256(buf.Bytes()))
+}
+
+func Test_layerAnalyzer(t *testing.T) {
+	layer, diffID := newTestLayer(t, map[string]string{
+		"etc/alpine-release": "3.10.4\n",
+	})
+
+	// The secret analyzer is registered by the layer analyzer
+	_ = newLayerAnalyzer(nil, 0)
+	ag := analyzer.NewAnalyzerGroup("", nil)
+	handlerManager, err := handler.NewManager(artifact.Option{})
+	require.NoError(t, err)
+	blobID, err := cache.CalcKey(diffID, ag.AnalyzerVersions(), handlerManager.Versions(), artifact.Option{})
+	require.NoError(t, err)
+
+	// The blob ID of another layer, so that only the diff ID doesn't match the uploaded layer
+	otherDiffID := "sha256:" + fmt.Sprintf("%064d", 1)
+	otherBlobID, err := cache.CalcKey(otherDiffID, ag.AnalyzerVersions(), handlerManager.Versions(), artifact.Option{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		method        string
+		blobID        string
+		diffID        string
+		gzip          bool
+		maxLayerSize  int64
+		want          *ftypes.OS
+		wantStatus    int
+		wantErrorCode string
+		wantErrorMsg  string
+	}{
+		{
+			name:       "happy path",
+			blobID:     blobID,
+			diffID:     diffID,
+			want:       &ftypes.OS{Family: "alpine", Name: "3.10.4"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "happy path with gzip",
+			blobID:     blobID,
+			diffID:     diffID,
+			gzip:       true,
+			want:       &ftypes.OS{Family: "alpine", Name: "3.10.4"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "happy path within the size limit",
+			blobID:       blobID,
+			diffID:       diffID,
+			gzip:         true,
+			maxLayerSize: int64(len(layer)),
+			want:         &ftypes.OS{Family: "alpine", Name: "3.10.4"},
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:          "sad path: diff ID mismatch",
+			blobID:        otherBlobID,
+			diffID:        otherDiffID,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorCode: "invalid_argument",
+			wantErrorMsg:  "the layer doesn't match the diff ID",
+		},
+		{
+			name:          "sad path: layer too large",
+			blobID:        blobID,
+			diffID:        diffID,
+			maxLayerSize:  int64(len(layer)) - 1,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorCode: "invalid_argument",
+			wantErrorMsg:  "the layer is larger than 1.999KiB",
+		},
+		{
+			name:          "sad path: decompressed layer too large",
+			blobID:        blobID,
+			diffID:        diffID,
+			gzip:          true,
+			maxLayerSize:  int64(len(layer)) - 1,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorCode: "invalid_argument",
+			wantErrorMsg:  "the layer is larger than 1.999KiB",
+		},
+		{
+			name:          "sad path: blob ID mismatch",
+			blobID:        "sha256:" + fmt.Sprintf("%064d", 0),
+			diffID:        diffID,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorCode: "invalid_argument",
+		},
+		{
+			name:          "sad path: no diff ID",
+			blobID:        blobID,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorCode: "invalid_argument",
+		},
+		{
+			name:          "sad path: GET",
+			method:        http.MethodGet,
+			blobID:        blobID,
+			diffID:        diffID,
+			wantStatus:    http.StatusNotFound,
+			wantErrorCode: "bad_route",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+			defer c.Close()
+
+			ts := httptest.NewServer(newLayerAnalyzer(c, tt.maxLayerSize))
+			defer ts.Close()
+
+			body := layer
+			if tt.gzip {
+				var buf bytes.Buffer
+				gw := gzip.NewWriter(&buf)
+				_, err = gw.Write(layer)
+				require.NoError(t, err)
+				require.NoError(t, gw.Close())
+				body = buf.Bytes()
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPut
+			}
+			query := url.Values{}
+			if tt.diffID != "" {
+				query.Set("diff_id", tt.diffID)
+			}
+			req, err := http.NewRequest(method, ts.URL+LayerPathPrefix+tt.blobID+"?"+query.Encode(), bytes.NewReader(body))
+			require.NoError(t, err)
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantErrorCode != "" {
+				var got struct {
+					Code string `json:"code"`
+					Msg  string `json:"msg"`
+				}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+				assert.Equal(t, tt.wantErrorCode, got.Code)
+				assert.Contains(t, got.Msg, tt.wantErrorMsg)
+
+				// Nothing is stored on errors
+				_, missing, err := c.MissingBlobs("", []string{tt.blobID})
+				require.NoError(t, err)
+				assert.Equal(t, []string{tt.blobID}, missing)
+				return
+			}
+
+			var got LayerResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+			assert.Equal(t, tt.want, got.OS)
+
+			blobInfo, err := c.GetBlob(tt.blobID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.diffID, blobInfo.DiffID)
+			assert.Equal(t, tt.want, blobInfo.OS)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"golang.org/x/time/rate"
+
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// The limiters of clients which have not sent requests for the duration are removed
+const clientLimiterTTL = 10 * time.Minute
+
+// Limits protects the server from bursts of requests, e.g. CI jobs started at the same time
+type Limits struct {
+	// MaxConcurrentScans is the number of scans processed at the same time, and 0 means unlimited
+	MaxConcurrentScans int
+
+	// RateLimit is the number of requests per second per client, and 0 means unlimited
+	RateLimit      float64
+	RateLimitBurst int
+
+	// MaxLayerSize is the size of the layers uploaded by clients before and after the decompression, and 0 means unlimited
+	MaxLayerSize int64
+}
+
+// slots limits the scans processed at the same time, and nil means unlimited
+type slots chan struct{}
+
+func newSlots(limit int) slots {
+	if limit <= 0 {
+		return nil
+	}
+	return make(slots, limit)
+}
+
+// tryAcquire returns false if the limit is reached, and release must be called otherwise
+func (s slots) tryAcquire() bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s slots) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// concurrencyLimit returns the middleware rejecting requests exceeding the limit, so that the server doesn't run out of memory.
+// The limit is shared by all the handlers wrapped with the middleware, e.g. scans and layer analyses.
+func concurrencyLimit(sem slots, m *metrics) func(http.Handler) http.Handler {
+	if sem == nil {
+		return func(base http.Handler) http.Handler { return base }
+	}
+	return func(base http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !sem.tryAcquire() {
+				m.rejectedRequests.WithLabelValues("concurrency").Inc()
+				writeResourceExhausted(w, "too many concurrent scans", time.Second)
+				return
+			}
+			defer sem.release()
+			base.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimiter limits the requests per client with the token bucket
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu          sync.Mutex
+	clients     map[string]*clientLimiter
+	lastCleanup time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newRateLimiter(limit float64, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(limit)))
+	}
+	return &rateLimiter{
+		limit:   rate.Limit(limit),
+		burst:   burst,
+		clients: map[string]*clientLimiter{},
+	}
+}
+
+// reserve returns 0 if the request of the client is allowed, or the duration to wait otherwise
+func (l *rateLimiter) reserve(client string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastCleanup) > clientLimiterTTL {
+		for c, cl := range l.clients {
+			if now.Sub(cl.lastSeen) > clientLimiterTTL {
+				delete(l.clients, c)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	cl, ok := l.clients[client]
+	if !ok {
+		cl = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = cl
+	}
+	cl.lastSeen = now
+
+	// The token is not consumed by rejected requests
+	rv := cl.limiter.ReserveN(now, 1)
+	if delay := rv.DelayFrom(now); delay > 0 {
+		rv.CancelAt(now)
+		return delay
+	}
+	return 0
+}
+
+// withRateLimit rejects requests exceeding the rate per client.
+// Clients are identified by the remote address, or by the user over the unix domain socket.
+func withRateLimit(base http.Handler, limiter *rateLimiter, m *metrics) http.Handler {
+	if limiter == nil {
+		return base
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if delay := limiter.reserve(clientAddr(r), time.Now()); delay > 0 {
+			m.rejectedRequests.WithLabelValues("rate_limit").Inc()
+			writeResourceExhausted(w, "rate limit exceeded", delay)
+			return
+		}
+		base.ServeHTTP(w, r)
+	})
+}
+
+// clientAddr returns the host of the client without the port, which changes on every connection.
+// The clients over the unix domain socket are identified by their users, e.g. "uid:1000", where supported.
+func clientAddr(r *http.Request) string {
+	if user, ok := peerUser(r.Context()); ok {
+		return user
+	}
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return client
+}
+
+// writeResourceExhausted responds with 429 and tells clients when to retry
+func writeResourceExhausted(w http.ResponseWriter, msg string, retryAfter time.Duration) {
+	twerr := resourceExhaustedError(msg, retryAfter)
+	w.Header().Set("Retry-After", twerr.Meta(rpc.RetryAfterMetaKey))
+	rpcScanner.WriteError(w, twerr)
+}
+
+func resourceExhaustedError(msg string, retryAfter time.Duration) twirp.Error {
+	seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	return twirp.NewError(twirp.ResourceExhausted, msg).WithMeta(rpc.RetryAfterMetaKey, seconds)
+}
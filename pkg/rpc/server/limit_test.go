@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rateLimiter_reserve(t *testing.T) {
+	now := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, 2)
+
+	// The burst is allowed
+	assert.Zero(t, limiter.reserve("10.0.0.1", now))
+	assert.Zero(t, limiter.reserve("10.0.0.1", now))
+	assert.Equal(t, time.Second, limiter.reserve("10.0.0.1", now))
+
+	// The rejected request doesn't consume the token
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve("10.0.0.1", now.Add(500*time.Millisecond)))
+	assert.Zero(t, limiter.reserve("10.0.0.1", now.Add(time.Second)))
+
+	// Other clients are not limited
+	assert.Zero(t, limiter.reserve("10.0.0.2", now))
+	assert.Len(t, limiter.clients, 2)
+
+	// Idle clients are removed
+	limiter.reserve("10.0.0.2", now.Add(clientLimiterTTL))
+	limiter.reserve("10.0.0.2", now.Add(clientLimiterTTL+2*time.Second))
+	assert.Len(t, limiter.clients, 1)
+	assert.Contains(t, limiter.clients, "10.0.0.2")
+}
+
+func Test_newRateLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     float64
+		burst     int
+		wantBurst int
+	}{
+		{
+			name:      "burst",
+			limit:     10,
+			burst:     20,
+			wantBurst: 20,
+		},
+		{
+			name:      "default burst",
+			limit:     2.5,
+			wantBurst: 3,
+		},
+		{
+			name:      "default burst of less than 1 request per second",
+			limit:     0.1,
+			wantBurst: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newRateLimiter(tt.limit, tt.burst)
+			assert.Equal(t, tt.wantBurst, got.burst)
+		})
+	}
+}
+
+func Test_withRateLimit(t *testing.T) {
+	m := newMetrics(t.TempDir())
+	handler := withRateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), newRateLimiter(0.5, 1), m)
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/twirp/trivy.scanner.v1.Scanner/Scan", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve("10.0.0.1:1234").Code)
+
+	// Clients are identified by the host regardless of the port
+	rec := serve("10.0.0.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assertTwirpError(t, rec, "resource_exhausted", "rate limit exceeded", "2")
+
+	assert.Equal(t, http.StatusOK, serve("10.0.0.2:1234").Code)
+}
+
+func Test_concurrencyLimit(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	m := newMetrics(t.TempDir())
+	handler := concurrencyLimit(newSlots(1), m)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-done
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/twirp/trivy.scanner.v1.Scanner/Scan", nil))
+		return rec
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- serve() }()
+	<-started
+
+	rec := serve()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assertTwirpError(t, rec, "resource_exhausted", "too many concurrent scans", "1")
+
+	close(done)
+	assert.Equal(t, http.StatusOK, (<-first).Code)
+
+	// The slot is released after the scan
+	go func() { <-started }()
+	assert.Equal(t, http.StatusOK, serve().Code)
+}
+
+func assertTwirpError(t *testing.T, rec *httptest.ResponseRecorder, code, msg, retryAfter string) {
+	t.Helper()
+	var got struct {
+		Code string            `json:"code"`
+		Msg  string            `json:"msg"`
+		Meta map[string]string `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, code, got.Code)
+	assert.Equal(t, msg, got.Msg)
+	assert.Equal(t, retryAfter, got.Meta["retry_after"])
+}
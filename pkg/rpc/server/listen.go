@@ -2,13 +2,19 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/twitchtv/twirp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/fanal/cache"
@@ -17,31 +23,80 @@ import (
 	dbFile "github.com/aquasecurity/trivy/pkg/db"
 	dbc "github.com/aquasecurity/trivy/pkg/db"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/resultstore"
+	"github.com/aquasecurity/trivy/pkg/rpc"
 	"github.com/aquasecurity/trivy/pkg/utils"
+	"github.com/aquasecurity/trivy/pkg/webhook"
 	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+	rpcResults "github.com/aquasecurity/trivy/rpc/results"
 	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
 )
 
 const updateInterval = 1 * time.Hour
 
+// UnixSocketScheme is the scheme of the address to listen on the unix domain socket, e.g. unix:///var/run/trivy.sock,
+// so that co-located processes such as sidecars can reach the server without a TCP port
+const UnixSocketScheme = "unix://"
+
+// unixSocketMode allows the owner and the group of the server to connect to the socket
+const unixSocketMode = 0660
+
+// ServerOption holds the options of the server
+type ServerOption struct {
+	AppVersion string
+
+	// Addr is the TCP address or the unix domain socket to listen on, e.g. "unix:///var/run/trivy.sock"
+	Addr     string
+	CacheDir string
+
+	// Auth authenticates the requests unless it is nil
+	Auth      Authenticator
+	DBRootCAs *x509.CertPool
+
+	// TLSConfig serves HTTPS when it is given, and requires the client certificates if it has the client CAs
+	TLSConfig *tls.Config
+
+	// ProxyRegistries are the registries clients can pull images from through the server,
+	// or let the server pull and analyze the images from
+	ProxyRegistries []string
+
+	// RegistryTransport pulls the images from the registries, the default transport of go-containerregistry if nil
+	RegistryTransport http.RoundTripper
+
+	// Limits rejects the requests exceeding them with 429 so that clients retry later
+	Limits Limits
+
+	// ResultCache reuses the results of the same scans within the TTL
+	ResultCache ResultCacheOption
+
+	// AuditLogger writes the requests unless it is nil
+	AuditLogger *AuditLogger
+
+	// Webhook is posted the summaries of the scans if its URL is given
+	Webhook webhook.Option
+
+	// ResultStore persists the summaries of the scans so that they can be queried unless it is nil
+	ResultStore *resultstore.Store
+
+	// Rescan re-scans the images periodically, and the new findings are posted to the webhook
+	Rescan RescanOption
+
+	// Locale localizes the vulnerabilities unless the clients request their own locale
+	Locale string
+
+	// Protocol serves the services also over gRPC on the same address if it is gRPC
+	Protocol string
+}
+
 // Server represents Trivy server
 type Server struct {
-	appVersion  string
-	addr        string
-	cacheDir    string
-	token       string
-	tokenHeader string
+	option ServerOption
 }
 
 // NewServer returns an instance of Server
-func NewServer(appVersion, addr, cacheDir, token, tokenHeader string) Server {
-	return Server{
-		appVersion:  appVersion,
-		addr:        addr,
-		cacheDir:    cacheDir,
-		token:       token,
-		tokenHeader: tokenHeader,
-	}
+func NewServer(option ServerOption) Server {
+	return Server{option: option}
 }
 
 // ListenAndServe starts Trivy server
@@ -50,23 +105,87 @@ func (s Server) ListenAndServe(serverCache cache.Cache) error {
 	dbUpdateWg := &sync.WaitGroup{}
 
 	go func() {
-		worker := newDBWorker(dbc.NewClient(s.cacheDir, true))
+		worker := newDBWorker(dbc.NewClient(s.option.CacheDir, true, dbc.WithRootCAs(s.option.DBRootCAs)))
 		ctx := context.Background()
 		for {
 			time.Sleep(updateInterval)
-			if err := worker.update(ctx, s.appVersion, s.cacheDir, dbUpdateWg, requestWg); err != nil {
+			if err := worker.update(ctx, s.option.AppVersion, s.option.CacheDir, dbUpdateWg, requestWg); err != nil {
 				log.Logger.Errorf("%+v\n", err)
 			}
 		}
 	}()
 
-	mux := newServeMux(serverCache, dbUpdateWg, requestWg, s.token, s.tokenHeader)
-	log.Logger.Infof("Listening %s...", s.addr)
+	if s.option.Rescan.Interval > 0 {
+		// The images are pulled from any registries as they are configured by the operator
+		r := newRescanner(s.option.Rescan, newImageInspector(serverCache, nil, s.option.RegistryTransport), newScanServer(serverCache, s.option.Locale),
+			s.option.Webhook, s.option.ResultStore, dbUpdateWg, requestWg)
+		go r.run(context.Background())
+	}
 
-	return http.ListenAndServe(s.addr, mux)
+	mux := newServeMux(serverCache, dbUpdateWg, requestWg, s.option)
+
+	listener, err := listen(s.option.Addr)
+	if err != nil {
+		return xerrors.Errorf("listen error: %w", err)
+	}
+	defer listener.Close()
+
+	// The local clients over the unix domain socket are rate-limited by their users
+	server := &http.Server{
+		Addr:        s.option.Addr,
+		Handler:     mux,
+		ConnContext: withPeerUser,
+	}
+	if s.option.TLSConfig == nil {
+		log.Logger.Infof("Listening %s...", s.option.Addr)
+		if s.option.Protocol == rpc.ProtocolGRPC {
+			// gRPC requires HTTP/2, which is negotiated in the TLS handshake otherwise
+			server.Handler = h2c.NewHandler(mux, &http2.Server{})
+		}
+		return server.Serve(listener)
+	}
+
+	log.Logger.Infof("Listening %s with TLS...", s.option.Addr)
+	server.TLSConfig = s.option.TLSConfig
+	// The certificate is given in the TLS config
+	return server.ServeTLS(listener, "", "")
+}
+
+// listen listens on the unix domain socket if the address has "unix://", or on the TCP address
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, UnixSocketScheme) {
+		if addr == "" {
+			addr = ":http"
+		}
+		return net.Listen("tcp", addr)
+	}
+
+	path := strings.TrimPrefix(addr, UnixSocketScheme)
+	if path == "" {
+		return nil, xerrors.Errorf("no socket path: %s", addr)
+	}
+	// The socket left by the previous server is removed, but the other files are not
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, xerrors.Errorf("%s exists and is not a socket", path)
+		} else if err = os.Remove(path); err != nil {
+			return nil, xerrors.Errorf("unable to remove the stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, unixSocketMode); err != nil {
+		_ = listener.Close()
+		return nil, xerrors.Errorf("unable to change the mode of the socket: %w", err)
+	}
+	return listener, nil
 }
 
-func newServeMux(serverCache cache.Cache, dbUpdateWg, requestWg *sync.WaitGroup, token, tokenHeader string) *http.ServeMux {
+func newServeMux(serverCache cache.Cache, dbUpdateWg, requestWg *sync.WaitGroup, option ServerOption) *http.ServeMux {
+	requireClientCert := option.TLSConfig != nil && option.TLSConfig.ClientCAs != nil
 	withWaitGroup := func(base http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Stop processing requests during DB update
@@ -82,28 +201,138 @@ func newServeMux(serverCache cache.Cache, dbUpdateWg, requestWg *sync.WaitGroup,
 	}
 
 	mux := http.NewServeMux()
+	m := newMetrics(option.CacheDir)
+	serverHooks := m.hooks()
+	if option.AuditLogger != nil {
+		serverHooks = twirp.ChainHooks(serverHooks, option.AuditLogger.hooks())
+	}
+	hooks := twirp.WithServerHooks(serverHooks)
+
+	var limiter *rateLimiter
+	if option.Limits.RateLimit > 0 {
+		limiter = newRateLimiter(option.Limits.RateLimit, option.Limits.RateLimitBurst)
+	}
+	// The health checks and the metrics are not limited nor audited
+	withLimits := func(base http.Handler) http.Handler {
+		return option.AuditLogger.handler(withRateLimit(withClientCert(withAuth(base, option.Auth), requireClientCert), limiter, m))
+	}
+
+	// Scans and layer analyses consume the memory
+	scanSlots := newSlots(option.Limits.MaxConcurrentScans)
+	withConcurrencyLimit := concurrencyLimit(scanSlots, m)
 
-	scanServer := rpcScanner.NewScannerServer(initializeScanServer(serverCache), nil)
-	scanHandler := withToken(withWaitGroup(scanServer), token, tokenHeader)
+	// The server also pulls images from the proxy registries and analyzes them for clients
+	var scanner rpcScanner.Scanner = scannerService{
+		scanHandler:    newCachedScanServer(newScanServer(serverCache, option.Locale), option.ResultCache, option.CacheDir, m),
+		imageInspector: newImageInspector(serverCache, option.ProxyRegistries, option.RegistryTransport),
+	}
+	var cacheService rpcCache.Cache = NewCacheServer(metricsCache{Cache: serverCache, metrics: m})
+	if option.Webhook.URL != "" {
+		scanner = webhookScanner{Scanner: scanner, option: option.Webhook}
+	}
+	if option.ResultStore != nil {
+		scanner = resultStoreScanner{Scanner: scanner, store: option.ResultStore}
+	}
+	if option.AuditLogger != nil {
+		scanner = auditScanner{Scanner: scanner}
+		cacheService = auditCache{Cache: cacheService}
+	}
+	scanServer := rpcScanner.NewScannerServer(scanner, hooks)
+	scanHandler := withLimits(withConcurrencyLimit(withWaitGroup(scanServer)))
 	mux.Handle(rpcScanner.ScannerPathPrefix, gziphandler.GzipHandler(scanHandler))
 
-	layerServer := rpcCache.NewCacheServer(NewCacheServer(serverCache), nil)
-	layerHandler := withToken(withWaitGroup(layerServer), token, tokenHeader)
+	layerServer := rpcCache.NewCacheServer(cacheService, hooks)
+	layerHandler := withLimits(withWaitGroup(layerServer))
 	mux.Handle(rpcCache.CachePathPrefix, gziphandler.GzipHandler(layerHandler))
 
+	// Layers uploaded by clients are analyzed on the server
+	analysisHandler := withLimits(withConcurrencyLimit(withWaitGroup(newLayerAnalyzer(serverCache, option.Limits.MaxLayerSize))))
+	mux.Handle(LayerPathPrefix, analysisHandler)
+
+	// The scan summaries are queried by the same clients as the scans
+	var resultsService rpcResults.Results
+	if option.ResultStore != nil {
+		resultsService = resultsServer{store: option.ResultStore}
+		resultsServer := rpcResults.NewResultsServer(resultsService, hooks)
+		mux.Handle(rpcResults.ResultsPathPrefix, gziphandler.GzipHandler(withLimits(resultsServer)))
+	}
+
+	// The DB is not locked by withWaitGroup as only the metadata is read
+	var versionService rpcVersion.Version = versionServer{appVersion: option.AppVersion, cacheDir: option.CacheDir}
+	versionServer := rpcVersion.NewVersionServer(versionService, hooks)
+	mux.Handle(rpcVersion.VersionPathPrefix, gziphandler.GzipHandler(withLimits(versionServer)))
+
+	// The same services are served over gRPC, e.g. "/trivy.scanner.v1.Scanner/Scan"
+	if option.Protocol == rpc.ProtocolGRPC {
+		policy := grpcPolicy{
+			auth:              option.Auth,
+			requireClientCert: requireClientCert,
+			limiter:           limiter,
+			scanSlots:         scanSlots,
+			dbUpdateWg:        dbUpdateWg,
+			requestWg:         requestWg,
+			metrics:           m,
+			auditLogger:       option.AuditLogger,
+		}
+		grpcServer := newGRPCServer(policy, scanner, cacheService, resultsService, versionService, serverCache, option.CacheDir)
+		for name := range grpcServer.GetServiceInfo() {
+			mux.Handle("/"+name+"/", grpcHandler(grpcServer))
+		}
+	}
+
+	if len(option.ProxyRegistries) > 0 {
+		mux.Handle(RegistryProxyPathPrefix, withLimits(newRegistryProxy(option.ProxyRegistries, option.RegistryTransport)))
+	}
+
 	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) {
 		if _, err := rw.Write([]byte("ok")); err != nil {
 			log.Logger.Errorf("health check error: %s", err)
 		}
 	})
 
+	mux.HandleFunc("/readyz", func(rw http.ResponseWriter, r *http.Request) {
+		if err := ready(r.Context(), serverCache, option.CacheDir); err != nil {
+			log.Logger.Debugf("readiness check error: %s", err)
+			http.Error(rw, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := rw.Write([]byte("ok")); err != nil {
+			log.Logger.Errorf("readiness check error: %s", err)
+		}
+	})
+
+	mux.Handle("/metrics", m.handler())
+
 	return mux
 }
 
-func withToken(base http.Handler, token, tokenHeader string) http.Handler {
+// pinger is implemented by the cache backend which can be checked for reachability, e.g. Redis
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ready returns an error unless the vulnerability DB is downloaded and the cache backend is reachable
+func ready(ctx context.Context, serverCache cache.Cache, cacheDir string) error {
+	if _, err := os.Stat(db.Path(cacheDir)); err != nil {
+		return xerrors.Errorf("vulnerability DB is not available: %w", err)
+	}
+	if _, err := metadata.NewClient(cacheDir).Get(); err != nil {
+		return xerrors.Errorf("vulnerability DB metadata is not available: %w", err)
+	}
+
+	if p, ok := serverCache.(pinger); ok {
+		if err := p.Ping(ctx); err != nil {
+			return xerrors.Errorf("cache backend is not reachable: %w", err)
+		}
+	}
+	return nil
+}
+
+// withClientCert rejects requests without a verified client certificate if required
+func withClientCert(base http.Handler, required bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if token != "" && token != r.Header.Get(tokenHeader) {
-			rpcScanner.WriteError(w, twirp.NewError(twirp.Unauthenticated, "invalid token"))
+		if required && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			rpcScanner.WriteError(w, twirp.NewError(twirp.Unauthenticated, "client certificate required"))
 			return
 		}
 		base.ServeHTTP(w, r)
@@ -2,10 +2,13 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -19,6 +22,7 @@ import (
 	"github.com/aquasecurity/trivy-db/pkg/db"
 	"github.com/aquasecurity/trivy-db/pkg/metadata"
 	dbFile "github.com/aquasecurity/trivy/pkg/db"
+	"github.com/aquasecurity/trivy/pkg/rpc"
 	"github.com/aquasecurity/trivy/pkg/utils"
 	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
 )
@@ -161,6 +165,10 @@ func Test_newServeMux(t *testing.T) {
 	type args struct {
 		token       string
 		tokenHeader string
+		noDB        bool
+		pingErr     error
+
+		proxyRegistries []string
 	}
 	tests := []struct {
 		name   string
@@ -174,6 +182,55 @@ func Test_newServeMux(t *testing.T) {
 			path: "/healthz",
 			want: http.StatusOK,
 		},
+		{
+			name: "readiness check",
+			path: "/readyz",
+			want: http.StatusOK,
+		},
+		{
+			name: "sad path: DB is not downloaded",
+			args: args{
+				noDB: true,
+			},
+			path: "/readyz",
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "sad path: cache is not reachable",
+			args: args{
+				pingErr: xerrors.New("connection refused"),
+			},
+			path: "/readyz",
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "metrics",
+			path: "/metrics",
+			want: http.StatusOK,
+		},
+		{
+			name: "registry proxy",
+			args: args{
+				proxyRegistries: []string{"ghcr.io"},
+			},
+			path: "/registry/ghcr.io/v2/",
+			want: http.StatusOK,
+		},
+		{
+			name: "sad path: registry proxy is disabled",
+			path: "/registry/ghcr.io/v2/",
+			want: http.StatusNotFound,
+		},
+		{
+			name: "sad path: registry proxy with invalid token",
+			args: args{
+				token:           "test",
+				tokenHeader:     "Authorization",
+				proxyRegistries: []string{"ghcr.io"},
+			},
+			path: "/registry/ghcr.io/v2/",
+			want: http.StatusUnauthorized,
+		},
 		{
 			name: "cache endpoint",
 			path: path.Join(rpcCache.CachePathPrefix, "MissingBlobs"),
@@ -220,12 +277,31 @@ func Test_newServeMux(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			dbUpdateWg, requestWg := &sync.WaitGroup{}, &sync.WaitGroup{}
 
-			c, err := cache.NewFSCache(t.TempDir())
+			fsCache, err := cache.NewFSCache(t.TempDir())
 			require.NoError(t, err)
+			c := pingCache{Cache: fsCache, err: tt.args.pingErr}
 
-			ts := httptest.NewServer(newServeMux(
-				c, dbUpdateWg, requestWg, tt.args.token, tt.args.tokenHeader),
-			)
+			cacheDir := t.TempDir()
+			if !tt.args.noDB {
+				require.NoError(t, os.MkdirAll(db.Dir(cacheDir), 0744))
+				_, err = utils.CopyFile("testdata/new.db", db.Path(cacheDir))
+				require.NoError(t, err)
+				_, err = utils.CopyFile("testdata/metadata.json", metadata.Path(cacheDir))
+				require.NoError(t, err)
+			}
+
+			var auth Authenticator
+			if tt.args.token != "" {
+				auth = NewTokenAuthenticator(tt.args.token, tt.args.tokenHeader)
+			}
+
+			ts := httptest.NewServer(newServeMux(c, dbUpdateWg, requestWg, ServerOption{
+				AppVersion:      "dev",
+				CacheDir:        cacheDir,
+				Auth:            auth,
+				ProxyRegistries: tt.args.proxyRegistries,
+				Protocol:        rpc.ProtocolTwirp,
+			}))
 			defer ts.Close()
 
 			var resp *http.Response
@@ -247,3 +323,169 @@ func Test_newServeMux(t *testing.T) {
 		})
 	}
 }
+
+func Test_newServeMux_clientCert(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		header     http.Header
+		clientCert bool
+		want       int
+	}{
+		{
+			name: "happy path",
+			path: path.Join(rpcCache.CachePathPrefix, "MissingBlobs"),
+			header: http.Header{
+				"Content-Type": []string{"application/protobuf"},
+			},
+			clientCert: true,
+			want:       http.StatusOK,
+		},
+		{
+			name: "health check without client certificate",
+			path: "/healthz",
+			want: http.StatusOK,
+		},
+		{
+			name: "sad path: no client certificate",
+			path: path.Join(rpcCache.CachePathPrefix, "MissingBlobs"),
+			header: http.Header{
+				"Content-Type": []string{"application/protobuf"},
+			},
+			want: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cache.NewFSCache(t.TempDir())
+			require.NoError(t, err)
+
+			// The self-signed certificate is used for the server and the client
+			cert, err := tls.LoadX509KeyPair("testdata/certs/cert.pem", "testdata/certs/key.pem")
+			require.NoError(t, err)
+			pool, err := utils.LoadClientCertPool([]string{"testdata/certs/cert.pem"})
+			require.NoError(t, err)
+
+			serverTLSConfig := &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				ClientCAs:    pool,
+				ClientAuth:   tls.VerifyClientCertIfGiven,
+			}
+			ts := httptest.NewUnstartedServer(newServeMux(c, &sync.WaitGroup{}, &sync.WaitGroup{}, ServerOption{
+				AppVersion: "dev",
+				CacheDir:   t.TempDir(),
+				TLSConfig:  serverTLSConfig,
+				Protocol:   rpc.ProtocolTwirp,
+			}))
+			ts.TLS = serverTLSConfig
+			ts.StartTLS()
+			defer ts.Close()
+
+			tlsConfig := &tls.Config{
+				RootCAs:    pool,
+				ServerName: "localhost",
+			}
+			if tt.clientCert {
+				tlsConfig.Certificates = []tls.Certificate{cert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+			method := http.MethodGet
+			if tt.header != nil {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, ts.URL+tt.path, nil)
+			require.NoError(t, err)
+			req.Header = tt.header
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
+
+type pingCache struct {
+	cache.Cache
+	err error
+}
+
+func (c pingCache) Ping(_ context.Context) error {
+	return c.err
+}
+
+func Test_listen(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, path string)
+		addr    func(path string) string
+		wantErr string
+	}{
+		{
+			name: "unix socket",
+			addr: func(path string) string { return UnixSocketScheme + path },
+		},
+		{
+			name: "stale socket",
+			setup: func(t *testing.T, path string) {
+				l, err := net.Listen("unix", path)
+				require.NoError(t, err)
+				// The socket file is left as the server crashed
+				l.(*net.UnixListener).SetUnlinkOnClose(false)
+				require.NoError(t, l.Close())
+			},
+			addr: func(path string) string { return UnixSocketScheme + path },
+		},
+		{
+			name: "sad path: not a socket",
+			setup: func(t *testing.T, path string) {
+				require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+			},
+			addr:    func(path string) string { return UnixSocketScheme + path },
+			wantErr: "exists and is not a socket",
+		},
+		{
+			name:    "sad path: no path",
+			addr:    func(string) string { return UnixSocketScheme },
+			wantErr: "no socket path: unix://",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socket := filepath.Join(t.TempDir(), "trivy.sock")
+			if tt.setup != nil {
+				tt.setup(t, socket)
+			}
+
+			l, err := listen(tt.addr(socket))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			defer l.Close()
+
+			fi, err := os.Stat(socket)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0660), fi.Mode().Perm())
+
+			mux := newServeMux(nil, &sync.WaitGroup{}, &sync.WaitGroup{}, ServerOption{
+				AppVersion: "dev",
+				CacheDir:   t.TempDir(),
+				Protocol:   rpc.ProtocolTwirp,
+			})
+			go func() { _ = http.Serve(l, mux) }()
+
+			client := &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return net.Dial("unix", socket)
+				},
+			}}
+			resp, err := client.Get("http://unix/healthz")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
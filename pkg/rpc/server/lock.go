@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const (
+	// lockTTL is how long the lock is held by a replica which crashed during the analysis
+	lockTTL = 30 * time.Second
+
+	// lockPollInterval is how often the other replicas try to acquire the lock
+	lockPollInterval = time.Second
+)
+
+// locker is implemented by the cache backend shared by the server replicas, e.g. Redis
+type locker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func() error, acquired bool, err error)
+}
+
+// analysisCoordinator lets only one of the server replicas behind a load balancer analyze the same artifact at a time.
+// The other replicas wait for the lock to be released, and then find the result in the shared cache.
+type analysisCoordinator struct {
+	locker       locker
+	pollInterval time.Duration
+}
+
+func newAnalysisCoordinator(c cache.ArtifactCache) analysisCoordinator {
+	l, _ := c.(locker)
+	return analysisCoordinator{
+		locker:       l,
+		pollInterval: lockPollInterval,
+	}
+}
+
+// do calls analyze while holding the lock of key unless cached returns true after the lock is acquired.
+// cached can be nil if analyze looks up the cache by itself.
+func (c analysisCoordinator) do(ctx context.Context, key string, cached func() bool, analyze func() error) error {
+	if c.locker == nil {
+		return analyze()
+	}
+
+	for {
+		unlock, acquired, err := c.locker.Lock(ctx, key, lockTTL)
+		if err != nil {
+			// The analysis is not blocked by the cache backend
+			log.Logger.Debugf("Analyzing %s without the lock: %s", key, err)
+			return analyze()
+		} else if acquired {
+			defer func() {
+				if err := unlock(); err != nil {
+					log.Logger.Debugf("Unlock error: %s", err)
+				}
+			}()
+
+			// Another replica may have analyzed it in the meantime
+			if cached != nil && cached() {
+				return nil
+			}
+			return analyze()
+		}
+
+		log.Logger.Debugf("Waiting for another replica to analyze %s...", key)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
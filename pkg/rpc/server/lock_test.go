@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+// fakeLocker is held by another replica for the given number of attempts
+type fakeLocker struct {
+	heldFor  int
+	err      error
+	attempts int
+	unlocked bool
+}
+
+func (l *fakeLocker) Lock(_ context.Context, _ string, _ time.Duration) (func() error, bool, error) {
+	l.attempts++
+	if l.err != nil {
+		return nil, false, l.err
+	} else if l.attempts <= l.heldFor {
+		return nil, false, nil
+	}
+	return func() error {
+		l.unlocked = true
+		return nil
+	}, true, nil
+}
+
+func Test_analysisCoordinator_do(t *testing.T) {
+	tests := []struct {
+		name         string
+		locker       *fakeLocker
+		cached       bool
+		timeout      time.Duration
+		wantAnalyzed bool
+		wantAttempts int
+		wantUnlocked bool
+		wantErr      string
+	}{
+		{
+			name:         "not locked",
+			locker:       &fakeLocker{},
+			wantAnalyzed: true,
+			wantAttempts: 1,
+			wantUnlocked: true,
+		},
+		{
+			name:         "analyzed by another replica",
+			locker:       &fakeLocker{heldFor: 2},
+			cached:       true,
+			wantAttempts: 3,
+			wantUnlocked: true,
+		},
+		{
+			name:         "another replica failed",
+			locker:       &fakeLocker{heldFor: 2},
+			wantAnalyzed: true,
+			wantAttempts: 3,
+			wantUnlocked: true,
+		},
+		{
+			name:         "lock error",
+			locker:       &fakeLocker{err: xerrors.New("connection refused")},
+			wantAnalyzed: true,
+			wantAttempts: 1,
+		},
+		{
+			name:    "sad path: timeout",
+			locker:  &fakeLocker{heldFor: 1000},
+			timeout: 50 * time.Millisecond,
+			wantErr: "context deadline exceeded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			c := analysisCoordinator{locker: tt.locker, pollInterval: 10 * time.Millisecond}
+			var analyzed bool
+			err := c.do(ctx, "sha256:blob", func() bool { return tt.cached }, func() error {
+				analyzed = true
+				return nil
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAnalyzed, analyzed)
+			assert.Equal(t, tt.wantAttempts, tt.locker.attempts)
+			assert.Equal(t, tt.wantUnlocked, tt.locker.unlocked)
+		})
+	}
+}
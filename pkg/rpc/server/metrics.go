@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/twitchtv/twirp"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const metricsNamespace = "trivy_server"
+
+type requestStartKey struct{}
+
+// metrics holds the Prometheus metrics of the server
+type metrics struct {
+	registry *prometheus.Registry
+
+	rpcRequests  *prometheus.CounterVec
+	rpcDuration  *prometheus.HistogramVec
+	scans        *prometheus.CounterVec
+	scanDuration prometheus.Histogram
+	cacheLookups *prometheus.CounterVec
+
+	rejectedRequests *prometheus.CounterVec
+}
+
+func newMetrics(cacheDir string) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_requests_total",
+			Help:      "Number of RPC requests by service, method and HTTP status code.",
+		}, []string{"service", "method", "code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Latency of RPC requests by service and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method"}),
+		scans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scans_total",
+			Help:      "Number of scans by status (success, error).",
+		}, []string{"status"}),
+		scanDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scan_duration_seconds",
+			Help:      "Duration of scans.",
+			// Scans take longer than usual RPCs
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cache_lookups_total",
+			Help:      "Number of artifacts, blobs and scan results looked up in the cache by result (hit, miss).",
+		}, []string{"kind", "result"}),
+		rejectedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rejected_requests_total",
+			Help:      "Number of requests rejected with 429 by reason (rate_limit, concurrency).",
+		}, []string{"reason"}),
+	}
+
+	dbAge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "db_age_seconds",
+		Help:      "Time since the vulnerability DB was built.",
+	}, func() float64 {
+		meta, err := metadata.NewClient(cacheDir).Get()
+		if err != nil {
+			log.Logger.Debugf("Unable to get the DB metadata: %s", err)
+			return math.NaN()
+		}
+		return time.Since(meta.UpdatedAt).Seconds()
+	})
+
+	// Expose the scan counters before the first scan so that the rates can be calculated
+	m.scans.WithLabelValues("success")
+	m.scans.WithLabelValues("error")
+	m.rejectedRequests.WithLabelValues("rate_limit")
+	m.rejectedRequests.WithLabelValues("concurrency")
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcRequests, m.rpcDuration, m.scans, m.scanDuration, m.cacheLookups, m.rejectedRequests, dbAge,
+	)
+	return m
+}
+
+// handler returns the handler of the "/metrics" endpoint
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// hooks returns the server hooks observing RPC requests
+func (m *metrics) hooks() *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestReceived: func(ctx context.Context) (context.Context, error) {
+			return context.WithValue(ctx, requestStartKey{}, time.Now()), nil
+		},
+		ResponseSent: func(ctx context.Context) {
+			start, ok := ctx.Value(requestStartKey{}).(time.Time)
+			if !ok {
+				return
+			}
+
+			service, _ := twirp.ServiceName(ctx)
+			method, _ := twirp.MethodName(ctx)
+			code, _ := twirp.StatusCode(ctx)
+			m.observe(service, method, code, time.Since(start))
+		},
+	}
+}
+
+// observe records the RPC request, where the code is the HTTP status of the response.
+// The gRPC requests are recorded with the HTTP status of Twirp for the same error.
+func (m *metrics) observe(service, method, code string, elapsed time.Duration) {
+	m.rpcRequests.WithLabelValues(service, method, code).Inc()
+	m.rpcDuration.WithLabelValues(service, method).Observe(elapsed.Seconds())
+
+	if method == "Scan" || method == "ScanStream" {
+		status := "success"
+		if c, err := strconv.Atoi(code); err != nil || c >= http.StatusBadRequest {
+			status = "error"
+		}
+		m.scans.WithLabelValues(status).Inc()
+		m.scanDuration.Observe(elapsed.Seconds())
+	}
+}
+
+// metricsCache counts the cache hits and misses of the artifacts and blobs requested by clients
+type metricsCache struct {
+	cache.Cache
+	metrics *metrics
+}
+
+func (c metricsCache) MissingBlobs(artifactID string, blobIDs []string) (bool, []string, error) {
+	missingArtifact, missingBlobIDs, err := c.Cache.MissingBlobs(artifactID, blobIDs)
+	if err != nil {
+		return missingArtifact, missingBlobIDs, err
+	}
+
+	if missingArtifact {
+		c.metrics.cacheLookups.WithLabelValues("artifact", "miss").Inc()
+	} else {
+		c.metrics.cacheLookups.WithLabelValues("artifact", "hit").Inc()
+	}
+	c.metrics.cacheLookups.WithLabelValues("blob", "miss").Add(float64(len(missingBlobIDs)))
+	c.metrics.cacheLookups.WithLabelValues("blob", "hit").Add(float64(len(blobIDs) - len(missingBlobIDs)))
+
+	return missingArtifact, missingBlobIDs, nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/cache"
+	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	rpcCache "github.com/aquasecurity/trivy/rpc/cache"
+)
+
+func Test_metrics(t *testing.T) {
+	cacheDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(cacheDir, "db"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "db", "metadata.json"),
+		[]byte(`{"Version": 2, "UpdatedAt": "2022-05-01T00:00:00Z"}`), 0600))
+
+	c, err := cache.NewFSCache(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, c.PutBlob("sha256:cached", types.BlobInfo{SchemaVersion: types.BlobJSONSchemaVersion}))
+
+	ts := httptest.NewServer(newServeMux(c, &sync.WaitGroup{}, &sync.WaitGroup{}, ServerOption{
+		AppVersion: "dev",
+		CacheDir:   cacheDir,
+		Protocol:   rpc.ProtocolTwirp,
+	}))
+	defer ts.Close()
+
+	client := rpcCache.NewCacheProtobufClient(ts.URL, ts.Client())
+	_, err = client.MissingBlobs(context.Background(), &rpcCache.MissingBlobsRequest{
+		ArtifactId: "sha256:artifact",
+		BlobIds:    []string{"sha256:cached", "sha256:missing1", "sha256:missing2"},
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	got := string(b)
+
+	want := []string{
+		`trivy_server_cache_lookups_total{kind="artifact",result="miss"} 1`,
+		`trivy_server_cache_lookups_total{kind="blob",result="hit"} 1`,
+		`trivy_server_cache_lookups_total{kind="blob",result="miss"} 2`,
+		`trivy_server_rpc_requests_total{code="200",method="MissingBlobs",service="Cache"} 1`,
+		`trivy_server_rpc_duration_seconds_count{method="MissingBlobs",service="Cache"} 1`,
+		`trivy_server_scans_total{status="error"} 0`,
+		`trivy_server_rejected_requests_total{reason="rate_limit"} 0`,
+		`trivy_server_db_age_seconds `,
+		`go_goroutines`,
+	}
+	for _, w := range want {
+		assert.Contains(t, got, w)
+	}
+	assert.NotContains(t, got, `trivy_server_db_age_seconds NaN`)
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+)
+
+// peerUserKey is the key of the context holding the user of the process connecting over the unix domain socket
+type peerUserKey struct{}
+
+// withPeerUser records the user of the process connecting over the unix domain socket, which identifies the local
+// clients as the remote address of the socket is empty
+func withPeerUser(ctx context.Context, conn net.Conn) context.Context {
+	c, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	uid, ok := peerUID(c)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, peerUserKey{}, "uid:"+strconv.Itoa(uid))
+}
+
+// peerUser returns the user recorded by withPeerUser, if any
+func peerUser(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(peerUserKey{}).(string)
+	return user, ok
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_withPeerUser(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "trivy.sock")
+	l, err := listen(UnixSocketScheme + socket)
+	require.NoError(t, err)
+	defer l.Close()
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(clientAddr(r)))
+		}),
+		ConnContext: withPeerUser,
+	}
+	go func() { _ = server.Serve(l) }()
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://unix/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The local clients are rate-limited by their users instead of the empty address
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "uid:"+strconv.Itoa(os.Getuid()), string(got))
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package server
+
+import (
+	"net"
+)
+
+// peerUID is not supported, and the local clients share the rate limit
+func peerUID(_ *net.UnixConn) (int, bool) {
+	return 0, false
+}
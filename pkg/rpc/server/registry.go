@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// RegistryProxyPathPrefix is the path prefix of the registry proxy.
+// Clients pull images from registries through "/registry/<registry>/v2/...", which is the Docker Registry HTTP API V2.
+const RegistryProxyPathPrefix = "/registry/"
+
+// registryAllowlist holds the registries which the server pulls images from on behalf of clients
+type registryAllowlist []string
+
+func newRegistryAllowlist(registries []string) registryAllowlist {
+	var allowlist registryAllowlist
+	for _, r := range registries {
+		// e.g. docker.io => index.docker.io
+		if r == "docker.io" {
+			r = name.DefaultRegistry
+		}
+		allowlist = append(allowlist, r)
+	}
+	return allowlist
+}
+
+func (l registryAllowlist) allowed(registry string) bool {
+	for _, pattern := range l {
+		// e.g. *.dkr.ecr.us-east-1.amazonaws.com
+		if ok, err := path.Match(pattern, registry); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// registryOptions returns the options to pull images with the credentials of the server and the transport,
+// which verifies the registries with '--registry-ca' and '--ca-bundle' or not with '--insecure' as the CLI does.
+// The default transport of go-containerregistry is used if the transport is nil.
+func registryOptions(transport http.RoundTripper) []remote.Option {
+	opts := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+	if transport != nil {
+		opts = append(opts, remote.WithTransport(transport))
+	}
+	return opts
+}
+
+// registryProxy pulls manifests and blobs from the allowed registries on behalf of clients
+// which can't reach the registries directly. Only pulling is supported.
+type registryProxy struct {
+	registries registryAllowlist
+	options    []remote.Option
+}
+
+func newRegistryProxy(allowedRegistries []string, transport http.RoundTripper) registryProxy {
+	return registryProxy{
+		registries: newRegistryAllowlist(allowedRegistries),
+		options:    registryOptions(transport),
+	}
+}
+
+func (p registryProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeRegistryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "only pulling images is supported")
+		return
+	}
+
+	// e.g. /registry/ghcr.io/v2/aquasecurity/trivy/manifests/latest
+	registry, apiPath, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, RegistryProxyPathPrefix), "/")
+	if !ok || !strings.HasPrefix(apiPath+"/", "v2/") {
+		writeRegistryError(w, http.StatusNotFound, "NAME_UNKNOWN", "invalid registry path")
+		return
+	}
+	if !p.registries.allowed(registry) {
+		writeRegistryError(w, http.StatusForbidden, "DENIED", registry+" is not allowed to be pulled through the server")
+		return
+	}
+
+	apiPath = strings.TrimPrefix(strings.TrimPrefix(apiPath, "v2"), "/")
+	if apiPath == "" {
+		// The client doesn't need to authenticate since the server authenticates to the registry
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+		return
+	}
+
+	opts := append([]remote.Option{remote.WithContext(r.Context())}, p.options...)
+	if i := strings.LastIndex(apiPath, "/manifests/"); i > 0 {
+		p.serveManifest(w, r, registry+"/"+apiPath[:i], apiPath[i+len("/manifests/"):], opts)
+	} else if i = strings.LastIndex(apiPath, "/blobs/"); i > 0 {
+		p.serveBlob(w, r, registry+"/"+apiPath[:i], apiPath[i+len("/blobs/"):], opts)
+	} else {
+		writeRegistryError(w, http.StatusNotFound, "NAME_UNKNOWN", "unsupported API")
+	}
+}
+
+func (p registryProxy) serveManifest(w http.ResponseWriter, r *http.Request, repo, reference string, opts []remote.Option) {
+	sep := ":"
+	if strings.Contains(reference, ":") {
+		sep = "@"
+	}
+	ref, err := name.ParseReference(repo + sep + reference)
+	if err != nil {
+		writeRegistryError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		desc, err := remote.Head(ref, opts...)
+		if err != nil {
+			writeUpstreamError(w, ref.Name(), err)
+			return
+		}
+		setDescriptorHeaders(w, string(desc.MediaType), desc.Digest.String(), desc.Size)
+		return
+	}
+
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		writeUpstreamError(w, ref.Name(), err)
+		return
+	}
+	setDescriptorHeaders(w, string(desc.MediaType), desc.Digest.String(), int64(len(desc.Manifest)))
+	if _, err = w.Write(desc.Manifest); err != nil {
+		log.Logger.Debugf("Unable to send the manifest of %s: %s", ref.Name(), err)
+	}
+}
+
+func (p registryProxy) serveBlob(w http.ResponseWriter, r *http.Request, repo, digest string, opts []remote.Option) {
+	ref, err := name.NewDigest(repo + "@" + digest)
+	if err != nil {
+		writeRegistryError(w, http.StatusBadRequest, "DIGEST_INVALID", err.Error())
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		// The size is not known without the manifest, and it is not needed for pulling
+		writeRegistryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "HEAD is not supported for blobs")
+		return
+	}
+
+	layer, err := remote.Layer(ref, opts...)
+	if err != nil {
+		writeUpstreamError(w, ref.Name(), err)
+		return
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		writeUpstreamError(w, ref.Name(), err)
+		return
+	}
+	defer rc.Close()
+
+	// The blob is streamed to the client without being stored in the server
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", digest)
+	if _, err = io.Copy(w, rc); err != nil {
+		log.Logger.Debugf("Unable to send the blob %s: %s", ref.Name(), err)
+	}
+}
+
+func setDescriptorHeaders(w http.ResponseWriter, mediaType, digest string, size int64) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+}
+
+func writeUpstreamError(w http.ResponseWriter, ref string, err error) {
+	log.Logger.Debugf("Unable to pull %s: %s", ref, err)
+
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		writeRegistryError(w, terr.StatusCode, "UNKNOWN", xerrors.Errorf("upstream error: %w", err).Error())
+		return
+	}
+	writeRegistryError(w, http.StatusBadGateway, "UNKNOWN", xerrors.Errorf("upstream error: %w", err).Error())
+}
+
+// writeRegistryError writes the error in the format of the Docker Registry HTTP API V2
+func writeRegistryError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string][]map[string]string{
+		"errors": {{"code": code, "message": message}},
+	})
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+)
+
+func Test_registryProxy(t *testing.T) {
+	upstream := httptest.NewServer(registry.New())
+	defer upstream.Close()
+	u, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	host := u.Host
+
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/library/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	manifestDigest, err := img.Digest()
+	require.NoError(t, err)
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	layerDigest, err := layers[0].Digest()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		allowedRegistries []string
+		method            string
+		path              string
+		want              int
+		wantHeader        http.Header
+		wantErrorCode     string
+	}{
+		{
+			name:              "version check",
+			allowedRegistries: []string{host},
+			path:              "/registry/" + host + "/v2/",
+			want:              http.StatusOK,
+		},
+		{
+			name:              "manifest",
+			allowedRegistries: []string{host},
+			path:              "/registry/" + host + "/v2/library/app/manifests/1.0",
+			want:              http.StatusOK,
+			wantHeader: http.Header{
+				"Docker-Content-Digest": []string{manifestDigest.String()},
+			},
+		},
+		{
+			name:              "manifest with HEAD",
+			allowedRegistries: []string{host},
+			method:            http.MethodHead,
+			path:              "/registry/" + host + "/v2/library/app/manifests/" + manifestDigest.String(),
+			want:              http.StatusOK,
+			wantHeader: http.Header{
+				"Docker-Content-Digest": []string{manifestDigest.String()},
+			},
+		},
+		{
+			name:              "blob",
+			allowedRegistries: []string{"127.0.0.1:*"},
+			path:              "/registry/" + host + "/v2/library/app/blobs/" + layerDigest.String(),
+			want:              http.StatusOK,
+			wantHeader: http.Header{
+				"Docker-Content-Digest": []string{layerDigest.String()},
+			},
+		},
+		{
+			name:              "sad path: not allowed",
+			allowedRegistries: []string{"ghcr.io"},
+			path:              "/registry/" + host + "/v2/library/app/manifests/1.0",
+			want:              http.StatusForbidden,
+			wantErrorCode:     "DENIED",
+		},
+		{
+			name:              "sad path: push",
+			allowedRegistries: []string{host},
+			method:            http.MethodPut,
+			path:              "/registry/" + host + "/v2/library/app/manifests/1.0",
+			want:              http.StatusMethodNotAllowed,
+			wantErrorCode:     "UNSUPPORTED",
+		},
+		{
+			name:              "sad path: unknown manifest",
+			allowedRegistries: []string{host},
+			path:              "/registry/" + host + "/v2/library/app/manifests/2.0",
+			want:              http.StatusNotFound,
+			wantErrorCode:     "UNKNOWN",
+		},
+		{
+			name:              "sad path: invalid path",
+			allowedRegistries: []string{host},
+			path:              "/registry/" + host + "/v1/_ping",
+			want:              http.StatusNotFound,
+			wantErrorCode:     "NAME_UNKNOWN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(newRegistryProxy(tt.allowedRegistries, nil))
+			defer ts.Close()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequest(method, ts.URL+tt.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.want, resp.StatusCode)
+			for k := range tt.wantHeader {
+				assert.Equal(t, tt.wantHeader.Get(k), resp.Header.Get(k), k)
+			}
+			if tt.wantErrorCode != "" {
+				var got struct {
+					Errors []struct {
+						Code string `json:"code"`
+					} `json:"errors"`
+				}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+				require.Len(t, got.Errors, 1)
+				assert.Equal(t, tt.wantErrorCode, got.Errors[0].Code)
+			}
+		})
+	}
+}
+
+func Test_registryProxy_transport(t *testing.T) {
+	upstream := httptest.NewTLSServer(registry.New())
+	defer upstream.Close()
+	u, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	host := u.Host
+
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/library/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img, remote.WithTransport(upstream.Client().Transport)))
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(upstream.Certificate())
+
+	tests := []struct {
+		name      string
+		transport http.RoundTripper
+		want      int
+	}{
+		{
+			name:      "registry CA",
+			transport: tartifact.NewRegistryTransport(rootCAs, false),
+			want:      http.StatusOK,
+		},
+		{
+			name:      "insecure",
+			transport: tartifact.NewRegistryTransport(nil, true),
+			want:      http.StatusOK,
+		},
+		{
+			// go-containerregistry falls back to HTTP for the local registry, which the TLS server rejects
+			name: "sad path: unknown CA",
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(newRegistryProxy([]string{host}, tt.transport))
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + "/registry/" + host + "/v2/library/app/manifests/1.0")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aquasecurity/fanal/artifact"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/resultstore"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/webhook"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// rescanTimeout is the timeout to pull, analyze and scan an image in a re-scan
+const rescanTimeout = 30 * time.Minute
+
+// RescanOption holds the options of the scheduled re-scans
+type RescanOption struct {
+	// Interval is how often the images are re-scanned, and 0 disables re-scans
+	Interval time.Duration
+
+	// Targets are the images to re-scan, read from the watch file
+	Targets []string
+}
+
+// watchList represents the watch file given by '--watch-file'
+type watchList struct {
+	Targets []struct {
+		Target string `yaml:"target"`
+	} `yaml:"targets"`
+}
+
+// ReadWatchFile returns the images listed in the watch file
+func ReadWatchFile(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("file read error: %w", err)
+	}
+
+	var list watchList
+	if err = yaml.Unmarshal(b, &list); err != nil {
+		return nil, xerrors.Errorf("yaml decode error (%s): %w", path, err)
+	}
+	if len(list.Targets) == 0 {
+		return nil, xerrors.Errorf("no target found in %s", path)
+	}
+
+	var targets []string
+	for i, t := range list.Targets {
+		if t.Target == "" {
+			return nil, xerrors.Errorf("targets[%d]: target must be specified", i)
+		} else if _, err = name.ParseReference(t.Target); err != nil {
+			return nil, xerrors.Errorf("targets[%d]: invalid image name: %w", i, err)
+		}
+		targets = append(targets, t.Target)
+	}
+	return targets, nil
+}
+
+// inspectFunc pulls and analyzes the image, and returns the reference to the analysis results in the cache
+type inspectFunc func(ctx context.Context, imageName string) (ftypes.ArtifactReference, error)
+
+// rescanner periodically re-scans the watched images against the current DB,
+// so that vulnerabilities published after deploying the images are noticed.
+// The findings which were not found in the previous re-scan are posted to the webhook.
+type rescanner struct {
+	option  RescanOption
+	inspect inspectFunc
+	scanner scanHandler
+	webhook webhook.Option
+	store   *resultstore.Store
+
+	// Scans are suspended during DB update as well as requests
+	dbUpdateWg *sync.WaitGroup
+	requestWg  *sync.WaitGroup
+
+	// findings holds the findings of the previous re-scan by image, and is used only in the re-scan goroutine
+	findings map[string]map[string]struct{}
+}
+
+func newRescanner(option RescanOption, inspector imageInspector, scanner scanHandler, webhookOption webhook.Option,
+	store *resultstore.Store, dbUpdateWg, requestWg *sync.WaitGroup) rescanner {
+	return rescanner{
+		option: option,
+		inspect: func(ctx context.Context, imageName string) (ftypes.ArtifactReference, error) {
+			ref, err := name.ParseReference(imageName)
+			if err != nil {
+				return ftypes.ArtifactReference{}, xerrors.Errorf("invalid image name: %w", err)
+			}
+			return inspector.inspect(ctx, imageName, ref, artifact.Option{})
+		},
+		scanner: scanner,
+		webhook: webhookOption,
+		store:   store,
+
+		dbUpdateWg: dbUpdateWg,
+		requestWg:  requestWg,
+		findings:   map[string]map[string]struct{}{},
+	}
+}
+
+// run re-scans the images at the interval. The first re-scan records the current findings
+// without notifications, and the later ones notify the new findings.
+func (r rescanner) run(ctx context.Context) {
+	for {
+		log.Logger.Infof("Re-scanning %d images...", len(r.option.Targets))
+		for _, target := range r.option.Targets {
+			if err := r.rescan(ctx, target); err != nil {
+				log.Logger.Errorf("Re-scan error: %s", err)
+			}
+		}
+		time.Sleep(r.option.Interval)
+	}
+}
+
+func (r rescanner) rescan(ctx context.Context, target string) error {
+	ctx, cancel := context.WithTimeout(ctx, rescanTimeout)
+	defer cancel()
+
+	artifactRef, err := r.inspect(ctx, target)
+	if err != nil {
+		return xerrors.Errorf("unable to analyze %s: %w", target, err)
+	}
+
+	res, err := r.scan(ctx, &rpcScanner.ScanRequest{
+		Target:     target,
+		ArtifactId: artifactRef.ID,
+		BlobIds:    artifactRef.BlobIDs,
+		Options: &rpcScanner.ScanOptions{
+			VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+			SecurityChecks: []string{types.SecurityCheckVulnerability},
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("unable to scan %s: %w", target, err)
+	}
+
+	report := types.Report{
+		ArtifactName: target,
+		Metadata:     types.Metadata{OS: rpc.ConvertFromRPCOS(res.Os)},
+		Results:      rpc.ConvertFromRPCResults(res.Results),
+	}
+	if r.store != nil {
+		if err = r.store.Record(ctx, report, time.Now()); err != nil {
+			log.Logger.Errorf("Result store error: %s", err)
+		}
+	}
+
+	previous, rescanned := r.findings[target]
+	newReport, findings, count := newFindings(report, previous)
+	r.findings[target] = findings
+	if !rescanned || count == 0 {
+		return nil
+	}
+
+	log.Logger.Infof("%d new vulnerabilities found in %s", count, target)
+	if r.webhook.URL == "" {
+		return nil
+	}
+	if err = webhook.Post(ctx, r.webhook, webhook.NewRescanPayload(newReport, r.webhook.AttachReport)); err != nil {
+		return xerrors.Errorf("webhook notification error: %w", err)
+	}
+	return nil
+}
+
+// scan detects vulnerabilities in the analysis results. Only the detection waits for DB update,
+// as pulling and analyzing images don't use the DB and may take long.
+func (r rescanner) scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	r.dbUpdateWg.Wait()
+	r.requestWg.Add(1)
+	defer r.requestWg.Done()
+	return r.scanner.Scan(ctx, in)
+}
+
+// newFindings returns the report with only the vulnerabilities not found in the previous re-scan,
+// the keys of all the vulnerabilities in the report, and the number of the new ones
+func newFindings(report types.Report, previous map[string]struct{}) (types.Report, map[string]struct{}, int) {
+	findings := map[string]struct{}{}
+	newReport := report
+	newReport.Results = nil
+
+	var count int
+	for _, result := range report.Results {
+		var vulns []types.DetectedVulnerability
+		for _, v := range result.Vulnerabilities {
+			key := result.Target + "/" + v.PkgName + "@" + v.InstalledVersion + "/" + v.VulnerabilityID
+			findings[key] = struct{}{}
+			if _, ok := previous[key]; !ok {
+				vulns = append(vulns, v)
+			}
+		}
+		if len(vulns) == 0 {
+			continue
+		}
+		result.Vulnerabilities = vulns
+		newReport.Results = append(newReport.Results, result)
+		count += len(vulns)
+	}
+	return newReport, findings, count
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/webhook"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// sequenceScanHandler returns the vulnerabilities of the i-th scan in the i-th call
+type sequenceScanHandler struct {
+	vulns [][]*common.Vulnerability
+	calls *int
+}
+
+func (h sequenceScanHandler) Scan(_ context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	vulns := h.vulns[*h.calls]
+	*h.calls++
+	return &rpcScanner.ScanResponse{
+		Os: &common.OS{Family: "alpine", Name: "3.15.4"},
+		Results: []*rpcScanner.Result{
+			{Target: in.Target + " (alpine 3.15.4)", Vulnerabilities: vulns},
+		},
+	}, nil
+}
+
+func Test_rescanner_rescan(t *testing.T) {
+	openssl := &common.Vulnerability{VulnerabilityId: "CVE-2022-0001", PkgName: "openssl",
+		InstalledVersion: "1.1.1n-r0", Severity: common.Severity_HIGH}
+	musl := &common.Vulnerability{VulnerabilityId: "CVE-2022-0002", PkgName: "musl",
+		InstalledVersion: "1.2.2-r7", Severity: common.Severity_CRITICAL}
+
+	var payloads []webhook.Payload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhook.Payload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		payloads = append(payloads, p)
+	}))
+	defer ts.Close()
+
+	var inspected []string
+	r := rescanner{
+		option: RescanOption{Targets: []string{"alpine:3.15"}},
+		inspect: func(_ context.Context, imageName string) (ftypes.ArtifactReference, error) {
+			inspected = append(inspected, imageName)
+			return ftypes.ArtifactReference{ID: "sha256:artifact", BlobIDs: []string{"sha256:blob"}}, nil
+		},
+		scanner: sequenceScanHandler{
+			vulns: [][]*common.Vulnerability{
+				{openssl},       // the first re-scan records the findings
+				{openssl},       // no new findings
+				{openssl, musl}, // CVE-2022-0002 is published
+			},
+			calls: new(int),
+		},
+		webhook:    webhook.Option{URL: ts.URL},
+		dbUpdateWg: &sync.WaitGroup{},
+		requestWg:  &sync.WaitGroup{},
+		findings:   map[string]map[string]struct{}{},
+	}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, r.rescan(context.Background(), "alpine:3.15"))
+	}
+	assert.Equal(t, []string{"alpine:3.15", "alpine:3.15", "alpine:3.15"}, inspected)
+
+	require.Len(t, payloads, 1)
+	got := payloads[0]
+	assert.True(t, got.Rescan)
+	assert.Equal(t, "Trivy found new findings in alpine:3.15: vulnerabilities: 1 CRITICAL", got.Text)
+	assert.Equal(t, 0, got.Vulnerabilities["HIGH"])
+}
+
+func TestReadWatchFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "happy path",
+			content: `targets:
+  - target: alpine:3.15
+  - target: ghcr.io/aquasecurity/trivy:0.28.0
+`,
+			want: []string{"alpine:3.15", "ghcr.io/aquasecurity/trivy:0.28.0"},
+		},
+		{
+			name:    "sad path: no target",
+			content: "targets: []\n",
+			wantErr: "no target found",
+		},
+		{
+			name:    "sad path: invalid image name",
+			content: "targets:\n  - target: Alpine:3.15\n",
+			wantErr: "targets[0]: invalid image name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "watch.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			got, err := ReadWatchFile(path)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
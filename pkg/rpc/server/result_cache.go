@@ -0,0 +1,186 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// defaultResultCacheSize is the number of scan results kept in memory unless specified
+const defaultResultCacheSize = 1000
+
+// ResultCacheOption holds the options of the scan result cache
+type ResultCacheOption struct {
+	// TTL is how long the scan results are reused, and 0 disables the cache
+	TTL time.Duration
+
+	// Size is the maximum number of the scan results kept in memory
+	Size int
+}
+
+// scanHandler scans the artifact in the cache
+type scanHandler interface {
+	Scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error)
+}
+
+// cachedScanServer returns the results of the same scans without detecting vulnerabilities again,
+// e.g. when many pipelines scan the same base image. The results are keyed by the artifact, the blobs,
+// the DB version and the scan options, so that they are not reused once the DB is updated.
+type cachedScanServer struct {
+	scanHandler
+	cache    *resultCache
+	group    *singleflight.Group
+	cacheDir string
+	metrics  *metrics
+}
+
+func newCachedScanServer(s scanHandler, opt ResultCacheOption, cacheDir string, m *metrics) scanHandler {
+	if opt.TTL <= 0 {
+		return s
+	}
+	if opt.Size <= 0 {
+		opt.Size = defaultResultCacheSize
+	}
+	return cachedScanServer{
+		scanHandler: s,
+		cache:       newResultCache(opt.TTL, opt.Size),
+		group:       &singleflight.Group{},
+		cacheDir:    cacheDir,
+		metrics:     m,
+	}
+}
+
+func (s cachedScanServer) Scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	key, err := s.key(in)
+	if err != nil {
+		return nil, xerrors.Errorf("result cache key error: %w", err)
+	}
+
+	if res, ok := s.cache.get(key, time.Now()); ok {
+		s.lookedUp("hit")
+		return res, nil
+	}
+	s.lookedUp("miss")
+
+	// The same scans requested at the same time are processed once
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		res, err := s.scanHandler.Scan(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.set(key, res, time.Now())
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*rpcScanner.ScanResponse), nil
+}
+
+func (s cachedScanServer) key(in *rpcScanner.ScanRequest) (string, error) {
+	meta, err := metadata.NewClient(s.cacheDir).Get()
+	if err != nil {
+		return "", xerrors.Errorf("unable to get the DB metadata: %w", err)
+	}
+
+	b, err := json.Marshal(struct {
+		Target     string
+		ArtifactID string
+		BlobIDs    []string
+		DBVersion  int
+		DBUpdated  time.Time
+		Options    *rpcScanner.ScanOptions
+	}{
+		// The target is included as it is shown in the results
+		Target:     in.Target,
+		ArtifactID: in.ArtifactId,
+		BlobIDs:    in.BlobIds,
+		DBVersion:  meta.Version,
+		DBUpdated:  meta.UpdatedAt,
+		Options:    in.Options,
+	})
+	if err != nil {
+		return "", xerrors.Errorf("JSON encode error: %w", err)
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+func (s cachedScanServer) lookedUp(result string) {
+	if s.metrics != nil {
+		s.metrics.cacheLookups.WithLabelValues("result", result).Inc()
+	}
+}
+
+// resultCache keeps the scan results until they expire. The oldest one is evicted when it is full.
+type resultCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type resultEntry struct {
+	key       string
+	response  *rpcScanner.ScanResponse
+	expiresAt time.Time
+}
+
+func newResultCache(ttl time.Duration, size int) *resultCache {
+	return &resultCache{
+		ttl:     ttl,
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *resultCache) get(key string, now time.Time) (*rpcScanner.ScanResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*resultEntry)
+	if now.After(entry.expiresAt) {
+		c.remove(e)
+		return nil, false
+	}
+	return entry.response, true
+}
+
+func (c *resultCache) set(key string, res *rpcScanner.ScanResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	for c.order.Len() >= c.size {
+		c.remove(c.order.Front())
+	}
+	c.entries[key] = c.order.PushBack(&resultEntry{
+		key:       key,
+		response:  res,
+		expiresAt: now.Add(c.ttl),
+	})
+}
+
+func (c *resultCache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*resultEntry).key)
+}
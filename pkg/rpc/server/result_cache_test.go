@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+type fakeScanHandler struct {
+	calls   int32
+	err     error
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *fakeScanHandler) Scan(_ context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	atomic.AddInt32(&h.calls, 1)
+	if h.started != nil {
+		h.started <- struct{}{}
+		<-h.release
+	}
+	if h.err != nil {
+		return nil, h.err
+	}
+	return &rpcScanner.ScanResponse{Os: &common.OS{Family: "alpine", Name: in.Target}}, nil
+}
+
+func Test_cachedScanServer_Scan(t *testing.T) {
+	request := func(artifactID string, vulnType ...string) *rpcScanner.ScanRequest {
+		return &rpcScanner.ScanRequest{
+			Target:     "alpine:3.15",
+			ArtifactId: artifactID,
+			BlobIds:    []string{"sha256:blob"},
+			Options:    &rpcScanner.ScanOptions{VulnType: vulnType},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		requests  []*rpcScanner.ScanRequest
+		updateDB  bool
+		err       error
+		wantCalls int32
+		wantErr   string
+	}{
+		{
+			name:      "same scans",
+			requests:  []*rpcScanner.ScanRequest{request("sha256:a", "os"), request("sha256:a", "os")},
+			wantCalls: 1,
+		},
+		{
+			name:      "different artifacts",
+			requests:  []*rpcScanner.ScanRequest{request("sha256:a", "os"), request("sha256:b", "os")},
+			wantCalls: 2,
+		},
+		{
+			name:      "different options",
+			requests:  []*rpcScanner.ScanRequest{request("sha256:a", "os"), request("sha256:a", "library")},
+			wantCalls: 2,
+		},
+		{
+			name:      "DB updated",
+			requests:  []*rpcScanner.ScanRequest{request("sha256:a", "os"), request("sha256:a", "os")},
+			updateDB:  true,
+			wantCalls: 2,
+		},
+		{
+			name:      "errors are not cached",
+			requests:  []*rpcScanner.ScanRequest{request("sha256:a", "os"), request("sha256:a", "os")},
+			err:       xerrors.New("scan error"),
+			wantCalls: 2,
+			wantErr:   "scan error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			mc := metadata.NewClient(cacheDir)
+			require.NoError(t, mc.Update(metadata.Metadata{Version: 2, UpdatedAt: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)}))
+
+			h := &fakeScanHandler{err: tt.err}
+			s := newCachedScanServer(h, ResultCacheOption{TTL: time.Hour}, cacheDir, newMetrics(cacheDir))
+
+			for i, req := range tt.requests {
+				if tt.updateDB && i > 0 {
+					require.NoError(t, mc.Update(metadata.Metadata{Version: 2, UpdatedAt: time.Date(2022, 6, 2, 0, 0, 0, 0, time.UTC)}))
+				}
+				res, err := s.Scan(context.Background(), req)
+				if tt.wantErr != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, "alpine:3.15", res.Os.Name)
+			}
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(&h.calls))
+		})
+	}
+}
+
+func Test_cachedScanServer_concurrent(t *testing.T) {
+	cacheDir := t.TempDir()
+	require.NoError(t, metadata.NewClient(cacheDir).Update(metadata.Metadata{Version: 2}))
+
+	h := &fakeScanHandler{started: make(chan struct{}), release: make(chan struct{})}
+	s := newCachedScanServer(h, ResultCacheOption{TTL: time.Hour}, cacheDir, nil)
+
+	req := &rpcScanner.ScanRequest{Target: "alpine:3.15", ArtifactId: "sha256:a"}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Scan(context.Background(), req)
+			assert.NoError(t, err)
+		}()
+	}
+
+	// The other requests wait for the first scan
+	<-h.started
+	time.Sleep(100 * time.Millisecond)
+	close(h.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&h.calls))
+}
+
+func Test_resultCache(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	res := &rpcScanner.ScanResponse{}
+
+	t.Run("expired", func(t *testing.T) {
+		c := newResultCache(time.Minute, 10)
+		c.set("a", res, now)
+
+		_, ok := c.get("a", now.Add(30*time.Second))
+		assert.True(t, ok)
+		_, ok = c.get("a", now.Add(2*time.Minute))
+		assert.False(t, ok)
+		assert.Empty(t, c.entries)
+	})
+
+	t.Run("full", func(t *testing.T) {
+		c := newResultCache(time.Minute, 2)
+		c.set("a", res, now)
+		c.set("b", res, now)
+		c.set("c", res, now)
+
+		_, ok := c.get("a", now)
+		assert.False(t, ok, "the oldest one is evicted")
+		_, ok = c.get("b", now)
+		assert.True(t, ok)
+		_, ok = c.get("c", now)
+		assert.True(t, ok)
+	})
+}
+
+func Test_newCachedScanServer(t *testing.T) {
+	h := &fakeScanHandler{}
+	assert.Equal(t, h, newCachedScanServer(h, ResultCacheOption{}, t.TempDir(), nil), "disabled without TTL")
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/resultstore"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+	rpcResults "github.com/aquasecurity/trivy/rpc/results"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// resultStoreScanner records the summaries of the scans requested by clients in the result store
+type resultStoreScanner struct {
+	rpcScanner.Scanner
+	store *resultstore.Store
+}
+
+func (s resultStoreScanner) Scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	res, err := s.Scanner.Scan(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	report := types.Report{
+		ArtifactName: in.Target,
+		Metadata:     types.Metadata{OS: rpc.ConvertFromRPCOS(res.Os)},
+		Results:      rpc.ConvertFromRPCResults(res.Results),
+	}
+
+	// The scan doesn't fail even if the summary is not recorded
+	if err = s.store.Record(ctx, report, time.Now()); err != nil {
+		log.Logger.Errorf("Result store error: %s", err)
+	}
+	return res, nil
+}
+
+// resultsServer answers the queries of the scan summaries in the result store
+type resultsServer struct {
+	store *resultstore.Store
+}
+
+func (s resultsServer) ListScans(ctx context.Context, in *rpcResults.ListScansRequest) (*rpcResults.ListScansResponse, error) {
+	if in.ArtifactName == "" {
+		return nil, twirp.RequiredArgumentError("artifact_name")
+	}
+	var since time.Time
+	if in.Since != nil {
+		since = in.Since.AsTime()
+	}
+
+	summaries, err := s.store.ListScans(ctx, in.ArtifactName, since)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	var scans []*rpcResults.ScanSummary
+	for _, summary := range summaries {
+		vulns := map[string]int32{}
+		for severity, count := range summary.Vulnerabilities {
+			vulns[severity] = int32(count)
+		}
+		scans = append(scans, &rpcResults.ScanSummary{
+			ArtifactName:    summary.ArtifactName,
+			ScannedAt:       timestamppb.New(summary.ScannedAt),
+			Os:              summary.OS,
+			Vulnerabilities: vulns,
+		})
+	}
+	return &rpcResults.ListScansResponse{Scans: scans}, nil
+}
+
+func (s resultsServer) FindVulnerability(ctx context.Context, in *rpcResults.FindVulnerabilityRequest) (
+	*rpcResults.FindVulnerabilityResponse, error) {
+	if in.VulnerabilityId == "" {
+		return nil, twirp.RequiredArgumentError("vulnerability_id")
+	}
+
+	appearances, err := s.store.FindVulnerability(ctx, in.VulnerabilityId, in.ArtifactName)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	var res []*rpcResults.Appearance
+	for _, a := range appearances {
+		res = append(res, &rpcResults.Appearance{
+			ArtifactName:    a.ArtifactName,
+			VulnerabilityId: a.VulnerabilityID,
+			Severity:        a.Severity,
+			PkgNames:        a.PkgNames,
+			FirstSeen:       timestamppb.New(a.FirstSeen),
+			LastSeen:        timestamppb.New(a.LastSeen),
+			Open:            a.Open,
+		})
+	}
+	return &rpcResults.FindVulnerabilityResponse{Appearances: res}, nil
+}
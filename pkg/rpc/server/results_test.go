@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/aquasecurity/trivy/pkg/resultstore"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpcResults "github.com/aquasecurity/trivy/rpc/results"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+func Test_resultStoreScanner(t *testing.T) {
+	store, err := resultstore.Open("sqlite://" + filepath.Join(t.TempDir(), "results.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	s := resultStoreScanner{
+		Scanner: fakeScanner{
+			res: &rpcScanner.ScanResponse{
+				Os: &common.OS{Family: "alpine", Name: "3.15.4"},
+				Results: []*rpcScanner.Result{
+					{
+						Target: "alpine:3.15 (alpine 3.15.4)",
+						Vulnerabilities: []*common.Vulnerability{
+							{VulnerabilityId: "CVE-2022-0001", PkgName: "openssl", Severity: common.Severity_CRITICAL},
+						},
+					},
+				},
+			},
+		},
+		store: store,
+	}
+	_, err = s.Scan(context.Background(), &rpcScanner.ScanRequest{Target: "alpine:3.15"})
+	require.NoError(t, err)
+
+	// The summaries are queried over HTTP with JSON
+	ts := httptest.NewServer(rpcResults.NewResultsServer(resultsServer{store: store}))
+	defer ts.Close()
+	client := rpcResults.NewResultsJSONClient(ts.URL, ts.Client())
+
+	scans, err := client.ListScans(context.Background(), &rpcResults.ListScansRequest{ArtifactName: "alpine:3.15"})
+	require.NoError(t, err)
+	require.Len(t, scans.Scans, 1)
+	assert.Equal(t, "alpine 3.15.4", scans.Scans[0].Os)
+	assert.Equal(t, map[string]int32{"CRITICAL": 1}, scans.Scans[0].Vulnerabilities)
+
+	found, err := client.FindVulnerability(context.Background(), &rpcResults.FindVulnerabilityRequest{
+		VulnerabilityId: "CVE-2022-0001",
+	})
+	require.NoError(t, err)
+	require.Len(t, found.Appearances, 1)
+	got := found.Appearances[0]
+	assert.Equal(t, "alpine:3.15", got.ArtifactName)
+	assert.Equal(t, []string{"openssl"}, got.PkgNames)
+	assert.Equal(t, scans.Scans[0].ScannedAt.AsTime(), got.FirstSeen.AsTime())
+	assert.True(t, got.Open)
+
+	_, err = client.FindVulnerability(context.Background(), &rpcResults.FindVulnerabilityRequest{})
+	var twerr twirp.Error
+	require.ErrorAs(t, err, &twerr)
+	assert.Equal(t, twirp.InvalidArgument, twerr.Code())
+}
@@ -36,20 +36,34 @@ func NewScanServer(s scanner.Driver, vulnClient result.Client) *ScanServer {
 	return &ScanServer{localScanner: s, resultClient: vulnClient}
 }
 
+// newScanServer returns the scan server localizing the vulnerabilities in the default locale of the server,
+// which is used for the clients not requesting their own locale, e.g. the old ones.
+func newScanServer(c cache.LocalArtifactCache, locale string) *ScanServer {
+	s := initializeScanServer(c)
+	s.resultClient = s.resultClient.WithLocale(locale)
+	return s
+}
+
 // Scan scans and return response
 func (s *ScanServer) Scan(_ context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
 	options := types.ScanOptions{
 		VulnType:        in.Options.VulnType,
 		SecurityChecks:  in.Options.SecurityChecks,
 		ListAllPackages: in.Options.ListAllPackages,
+		ESM:             in.Options.Esm,
+		Locale:          in.Options.Locale,
 	}
 	results, os, err := s.localScanner.Scan(in.Target, in.ArtifactId, in.BlobIds, options)
 	if err != nil {
 		return nil, xerrors.Errorf("failed scan, %s: %w", in.Target, err)
 	}
 
+	resultClient := s.resultClient
+	if options.Locale != "" {
+		resultClient = resultClient.WithLocale(options.Locale)
+	}
 	for i := range results {
-		s.resultClient.FillVulnerabilityInfo(results[i].Vulnerabilities, results[i].Type)
+		resultClient.FillVulnerabilityInfo(results[i].Vulnerabilities, results[i].Type)
 	}
 	return rpc.ConvertToRPCScanResponse(results, os), nil
 }
@@ -181,6 +181,69 @@ func TestScanServer_Scan(t *testing.T) {
 	}
 }
 
+func TestScanServer_Scan_locale(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverLocale string
+		clientLocale string
+		wantTitle    string
+	}{
+		{
+			name:         "locale of the client",
+			serverLocale: result.LocaleEnglish,
+			clientLocale: "ja",
+			wantTitle:    "DoS の脆弱性",
+		},
+		{
+			name:         "locale of the server for the old clients",
+			serverLocale: "ja",
+			wantTitle:    "DoS の脆弱性",
+		},
+		{
+			name:         "English requested by the client",
+			serverLocale: "ja",
+			clientLocale: result.LocaleEnglish,
+			wantTitle:    "dos",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.InitDB(t, []string{"testdata/fixtures/vulnerability.yaml", "testdata/fixtures/vulnerability-detail.yaml"})
+			defer db.Close()
+
+			mockDriver := new(scanner.MockDriver)
+			mockDriver.ApplyScanExpectation(scanner.DriverScanExpectation{
+				Args: scanner.DriverScanArgs{
+					Target:   "alpine:3.11",
+					ImageID:  "sha256:e7d92cdc71feacf90708cb59182d0df1b911f8ae022d29e8e95d75ca6a99776a",
+					LayerIDs: []string{"sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"},
+					Options:  types.ScanOptions{Locale: tt.clientLocale},
+				},
+				Returns: scanner.DriverScanReturns{
+					Results: types.Results{
+						{
+							Target:          "alpine:3.11 (alpine 3.11)",
+							Vulnerabilities: []types.DetectedVulnerability{{VulnerabilityID: "CVE-2019-0001"}},
+						},
+					},
+				},
+			})
+
+			s := NewScanServer(mockDriver, result.NewClient(db.Config{}).WithLocale(tt.serverLocale))
+			got, err := s.Scan(context.Background(), &rpcScanner.ScanRequest{
+				Target:     "alpine:3.11",
+				ArtifactId: "sha256:e7d92cdc71feacf90708cb59182d0df1b911f8ae022d29e8e95d75ca6a99776a",
+				BlobIds:    []string{"sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"},
+				Options:    &rpcScanner.ScanOptions{Locale: tt.clientLocale},
+			})
+			require.NoError(t, err)
+			require.Len(t, got.Results, 1)
+			require.Len(t, got.Results[0].Vulnerabilities, 1)
+			assert.Equal(t, tt.wantTitle, got.Results[0].Vulnerabilities[0].Title)
+		})
+	}
+}
+
 func TestCacheServer_PutArtifact(t *testing.T) {
 	type args struct {
 		in *rpcCache.PutArtifactRequest
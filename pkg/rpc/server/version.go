@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/aquasecurity/trivy-db/pkg/metadata"
+	dbc "github.com/aquasecurity/trivy/pkg/db"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
+)
+
+// versionServer returns the version of the server and the vulnerability DB served to clients,
+// so that monitoring can alert when the DB is not updated
+type versionServer struct {
+	appVersion string
+	cacheDir   string
+}
+
+func (s versionServer) GetVersion(_ context.Context, _ *emptypb.Empty) (*rpcVersion.VersionResponse, error) {
+	res := &rpcVersion.VersionResponse{Version: s.appVersion}
+
+	// The DB may not be downloaded yet while the server is starting
+	meta, err := metadata.NewClient(s.cacheDir).Get()
+	if err != nil {
+		log.Logger.Debugf("DB metadata error: %s", err)
+		return res, nil
+	}
+	res.VulnerabilityDb = rpc.ConvertToRPCVulnerabilityDB(meta, dbc.IsStale(meta, time.Now()))
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	rpcVersion "github.com/aquasecurity/trivy/rpc/version"
+)
+
+func Test_versionServer_GetVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     *rpcVersion.VulnerabilityDB
+	}{
+		{
+			name:     "stale DB",
+			metadata: `{"Version": 2, "UpdatedAt": "2022-03-02T06:00:00Z", "NextUpdate": "2022-03-02T12:00:00Z", "DownloadedAt": "2022-03-02T07:00:00Z"}`,
+			want: &rpcVersion.VulnerabilityDB{
+				Version: 2,
+				Stale:   true,
+			},
+		},
+		{
+			name: "no DB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			if tt.metadata != "" {
+				require.NoError(t, os.MkdirAll(filepath.Join(cacheDir, "db"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "db", "metadata.json"), []byte(tt.metadata), 0600))
+			}
+
+			ts := httptest.NewServer(rpcVersion.NewVersionServer(versionServer{appVersion: "0.30.0", cacheDir: cacheDir}))
+			defer ts.Close()
+			client := rpcVersion.NewVersionProtobufClient(ts.URL, ts.Client())
+
+			got, err := client.GetVersion(context.Background(), &emptypb.Empty{})
+			require.NoError(t, err)
+			assert.Equal(t, "0.30.0", got.Version)
+			if tt.want == nil {
+				assert.Nil(t, got.VulnerabilityDb)
+				return
+			}
+			require.NotNil(t, got.VulnerabilityDb)
+			assert.Equal(t, tt.want.Version, got.VulnerabilityDb.Version)
+			assert.Equal(t, tt.want.Stale, got.VulnerabilityDb.Stale)
+			assert.Equal(t, time.Date(2022, 3, 2, 12, 0, 0, 0, time.UTC), got.VulnerabilityDb.NextUpdate.AsTime())
+		})
+	}
+}
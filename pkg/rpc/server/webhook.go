@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/webhook"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+// webhookScanner posts the summaries of the scans requested by clients to the webhook
+type webhookScanner struct {
+	rpcScanner.Scanner
+	option webhook.Option
+}
+
+func (s webhookScanner) Scan(ctx context.Context, in *rpcScanner.ScanRequest) (*rpcScanner.ScanResponse, error) {
+	res, err := s.Scanner.Scan(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	report := types.Report{
+		ArtifactName: in.Target,
+		Metadata:     types.Metadata{OS: rpc.ConvertFromRPCOS(res.Os)},
+		Results:      rpc.ConvertFromRPCResults(res.Results),
+	}
+	payload := webhook.NewPayload(report, "", s.option.AttachReport)
+
+	// The response is not delayed by the notification, and the request context is canceled after responding
+	go func() {
+		if err := webhook.Post(context.Background(), s.option, payload); err != nil {
+			log.Logger.Errorf("Webhook notification error: %s", err)
+		}
+	}()
+	return res, nil
+}
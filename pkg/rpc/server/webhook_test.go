@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/webhook"
+	"github.com/aquasecurity/trivy/rpc/common"
+	rpcScanner "github.com/aquasecurity/trivy/rpc/scanner"
+)
+
+func Test_webhookScanner_Scan(t *testing.T) {
+	payloads := make(chan webhook.Payload, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhook.Payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		payloads <- p
+	}))
+	defer ts.Close()
+
+	s := webhookScanner{
+		Scanner: fakeScanner{
+			res: &rpcScanner.ScanResponse{
+				Os: &common.OS{Family: "alpine", Name: "3.15.4"},
+				Results: []*rpcScanner.Result{
+					{
+						Target: "alpine:3.15 (alpine 3.15.4)",
+						Vulnerabilities: []*common.Vulnerability{
+							{VulnerabilityId: "CVE-2022-0001", Severity: common.Severity_CRITICAL},
+						},
+					},
+				},
+			},
+		},
+		option: webhook.Option{URL: ts.URL},
+	}
+	_, err := s.Scan(context.Background(), &rpcScanner.ScanRequest{Target: "alpine:3.15"})
+	require.NoError(t, err)
+
+	select {
+	case p := <-payloads:
+		assert.Equal(t, "Trivy scanned alpine:3.15: vulnerabilities: 1 CRITICAL", p.Text)
+		assert.Equal(t, 1, p.Vulnerabilities["CRITICAL"])
+		assert.Nil(t, p.Report)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook is not notified")
+	}
+}
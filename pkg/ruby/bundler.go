@@ -0,0 +1,259 @@
+// Package ruby parses Gemfile.lock with the direct dependencies and the dependency graph,
+// and records the groups of the gems declared in Gemfile of the projects.
+package ruby
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/language"
+	// The analyzer of fanal is registered first, so that the one of the same type here replaces it
+	_ "github.com/aquasecurity/fanal/analyzer/language/ruby/bundler"
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&bundlerAnalyzer{})
+	analyzer.RegisterAnalyzer(&manifestAnalyzer{})
+}
+
+const (
+	// TypeManifest records the gems declared in Gemfile of the projects
+	TypeManifest = analyzer.Type("bundler-manifest")
+
+	// Gemfile is the manifest of Bundler
+	Gemfile = "Gemfile"
+
+	// The version is greater than that of fanal, so that the layers analyzed by fanal are analyzed again
+	bundlerVersion = 2
+
+	manifestVersion = 1
+)
+
+// devGroups are the groups of the gems only the development needs
+var devGroups = []string{"development", "test"}
+
+// bundlerAnalyzer replaces the analyzer of fanal, which drops "DEPENDENCIES" and the dependency graph of Gemfile.lock
+type bundlerAnalyzer struct{}
+
+func (a bundlerAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	libs, deps, direct, err := parseGemfileLock(input.Content)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to parse Gemfile.lock: %w", err)
+	}
+
+	res := language.ToAnalysisResult(ftypes.Bundler, input.FilePath, "", libs, deps)
+	if res == nil {
+		return nil, nil
+	}
+	res.CustomResources = []ftypes.CustomResource{
+		{
+			Type:     types.PackageScopeType,
+			FilePath: input.FilePath,
+			Data:     types.PackageScope{Direct: direct},
+		},
+	}
+	return res, nil
+}
+
+func (a bundlerAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filepath.Base(filePath) == ftypes.GemfileLock
+}
+
+func (a bundlerAnalyzer) Type() analyzer.Type {
+	return analyzer.TypeBundler
+}
+
+func (a bundlerAnalyzer) Version() int {
+	return bundlerVersion
+}
+
+// parseGemfileLock returns the gems in Gemfile.lock, the dependency graph among them and the IDs of the gems
+// listed in "DEPENDENCIES", i.e. declared in Gemfile. The gems are indented with 4 spaces in "specs" of the sources,
+// and their dependencies with 6 spaces, as the fanal parser reads them.
+func parseGemfileLock(r io.Reader) ([]godeptypes.Library, []godeptypes.Dependency,
+	[]string, error) {
+	var libs []godeptypes.Library
+	var declared []string
+	dependsOn := map[string][]string{}
+	versions := map[string]string{}
+
+	var section, current string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && !strings.HasPrefix(line, " ") {
+			section, current = line, ""
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		name := fields[0]
+
+		switch indent := len(line) - len(strings.TrimLeft(line, " ")); {
+		case section == "DEPENDENCIES" && indent == 2:
+			// The gems from the git repositories and the paths are suffixed with "!"
+			declared = append(declared, strings.TrimSuffix(name, "!"))
+		case indent == 4 && len(fields) == 2:
+			current = name
+			version := strings.Trim(fields[1], "()")
+			versions[name] = version
+			libs = append(libs, godeptypes.Library{
+				ID:      packageID(name, version),
+				Name:    name,
+				Version: version,
+			})
+		case indent == 6 && current != "":
+			dependsOn[current] = append(dependsOn[current], name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, xerrors.Errorf("scan error: %w", err)
+	}
+
+	var deps []godeptypes.Dependency
+	for name, children := range dependsOn {
+		dep := godeptypes.Dependency{ID: packageID(name, versions[name])}
+		for _, child := range children {
+			// The dependencies for the other platforms are not installed
+			if version, ok := versions[child]; ok {
+				dep.DependsOn = append(dep.DependsOn, packageID(child, version))
+			}
+		}
+		if len(dep.DependsOn) == 0 {
+			continue
+		}
+		sort.Strings(dep.DependsOn)
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].ID < deps[j].ID
+	})
+
+	var direct []string
+	for _, name := range declared {
+		if version, ok := versions[name]; ok {
+			direct = append(direct, packageID(name, version))
+		}
+	}
+	sort.Strings(direct)
+	return libs, deps, direct, nil
+}
+
+var (
+	gemPattern    = regexp.MustCompile(`^gem\s*\(?\s*["']([^"']+)["']`)
+	groupPattern  = regexp.MustCompile(`^group\s*\(?\s*((?::\w+|["']\w+["'])(?:\s*,\s*(?::\w+|["']\w+["']))*)`)
+	optionPattern = regexp.MustCompile(`\bgroups?:\s*(\[[^\]]*\]|:\w+|["']\w+["'])`)
+	symbolPattern = regexp.MustCompile(`\w+`)
+	blockPattern  = regexp.MustCompile(`(^(if|unless|case|while|until|begin|def|class|module)\b)|\bdo\s*(\|[^|]*\|)?\s*$`)
+	endPattern    = regexp.MustCompile(`^end\b`)
+)
+
+// manifestAnalyzer records the gems in Gemfile by their groups, which tell the development dependencies of
+// Gemfile.lock in the same directory. The gems only in "development" and "test" groups are the development ones.
+// Gemfile is Ruby, so only the gems declared literally are read.
+type manifestAnalyzer struct{}
+
+func (a manifestAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var manifest types.PackageManifest
+
+	// The groups of the blocks the line is in, and nil for the blocks other than "group"
+	var blocks [][]string
+	scanner := bufio.NewScanner(input.Content)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), "#")
+		line = strings.TrimSpace(line)
+		switch {
+		case endPattern.MatchString(line):
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+		case gemPattern.MatchString(line):
+			name := gemPattern.FindStringSubmatch(line)[1]
+			var groups []string
+			if m := optionPattern.FindStringSubmatch(line); m != nil {
+				groups = symbolPattern.FindAllString(m[1], -1)
+			}
+			for _, block := range blocks {
+				groups = append(groups, block...)
+			}
+			if isDev(groups) {
+				manifest.DevDependencies = append(manifest.DevDependencies, name)
+			} else {
+				manifest.Dependencies = append(manifest.Dependencies, name)
+			}
+		case groupPattern.MatchString(line) && blockPattern.MatchString(line):
+			blocks = append(blocks, symbolPattern.FindAllString(groupPattern.FindStringSubmatch(line)[1], -1))
+		case blockPattern.MatchString(line):
+			blocks = append(blocks, nil)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("unable to read Gemfile: %w", err)
+	}
+	if len(manifest.Dependencies) == 0 && len(manifest.DevDependencies) == 0 {
+		return nil, nil
+	}
+
+	return &analyzer.AnalysisResult{
+		CustomResources: []ftypes.CustomResource{
+			{
+				Type:     types.PackageManifestType,
+				FilePath: input.FilePath,
+				Data:     manifest,
+			},
+		},
+	}, nil
+}
+
+func (a manifestAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	if filepath.Base(filePath) != Gemfile {
+		return false
+	}
+	// The installed gems have their own Gemfile under "gems" of GEM_HOME
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+		if dir == "gems" {
+			return false
+		}
+	}
+	return true
+}
+
+func (a manifestAnalyzer) Type() analyzer.Type {
+	return TypeManifest
+}
+
+func (a manifestAnalyzer) Version() int {
+	return manifestVersion
+}
+
+// isDev returns true if all the groups are only for the development, and false without groups, i.e. "default"
+func isDev(groups []string) bool {
+	if len(groups) == 0 {
+		return false
+	}
+	for _, group := range groups {
+		if !slices.Contains(devGroups, group) {
+			return false
+		}
+	}
+	return true
+}
+
+func packageID(name, version string) string {
+	return name + "@" + version
+}
@@ -0,0 +1,100 @@
+package ruby
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestBundlerAnalyzer_Analyze(t *testing.T) {
+	f, err := os.Open("testdata/Gemfile.lock")
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, err := bundlerAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+		FilePath: "app/Gemfile.lock",
+		Content:  f,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &analyzer.AnalysisResult{
+		Applications: []ftypes.Application{
+			{
+				Type:     ftypes.Bundler,
+				FilePath: "app/Gemfile.lock",
+				Libraries: []ftypes.Package{
+					{ID: "concurrent-ruby@1.1.10", Name: "concurrent-ruby", Version: "1.1.10"},
+					{ID: "diff-lcs@1.5.0", Name: "diff-lcs", Version: "1.5.0"},
+					{ID: "i18n@1.10.0", Name: "i18n", Version: "1.10.0"},
+					{ID: "nokogiri@1.13.6-x86_64-linux", Name: "nokogiri", Version: "1.13.6-x86_64-linux"},
+					{ID: "racc@1.6.0", Name: "racc", Version: "1.6.0"},
+					{ID: "rspec@3.11.0", Name: "rspec", Version: "3.11.0"},
+					{ID: "rspec-core@3.11.0", Name: "rspec-core", Version: "3.11.0"},
+					{ID: "rspec-support@3.11.0", Name: "rspec-support", Version: "3.11.0"},
+				},
+				Dependencies: []godeptypes.Dependency{
+					{ID: "i18n@1.10.0", DependsOn: []string{"concurrent-ruby@1.1.10"}},
+					{ID: "nokogiri@1.13.6-x86_64-linux", DependsOn: []string{"racc@1.6.0"}},
+					{ID: "rspec-support@3.11.0", DependsOn: []string{"diff-lcs@1.5.0"}},
+					{ID: "rspec@3.11.0", DependsOn: []string{"rspec-core@3.11.0"}},
+				},
+			},
+		},
+		CustomResources: []ftypes.CustomResource{
+			{
+				Type:     types.PackageScopeType,
+				FilePath: "app/Gemfile.lock",
+				Data: types.PackageScope{
+					Direct: []string{"i18n@1.10.0", "nokogiri@1.13.6-x86_64-linux", "rspec@3.11.0"},
+				},
+			},
+		},
+	}, got)
+}
+
+func TestManifestAnalyzer_Analyze(t *testing.T) {
+	f, err := os.Open("testdata/Gemfile")
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, err := manifestAnalyzer{}.Analyze(context.Background(), analyzer.AnalysisInput{
+		FilePath: "app/Gemfile",
+		Content:  f,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &analyzer.AnalysisResult{
+		CustomResources: []ftypes.CustomResource{
+			{
+				Type:     types.PackageManifestType,
+				FilePath: "app/Gemfile",
+				Data: types.PackageManifest{
+					Dependencies:    []string{"i18n", "nokogiri", "rack-test"},
+					DevDependencies: []string{"byebug", "rspec", "simplecov"},
+				},
+			},
+		},
+	}, got)
+}
+
+func TestManifestAnalyzer_Required(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{filePath: "app/Gemfile", want: true},
+		{filePath: "usr/local/bundle/gems/rspec-3.11.0/Gemfile", want: false},
+		{filePath: "app/Gemfile.lock", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			assert.Equal(t, tt.want, manifestAnalyzer{}.Required(tt.filePath, nil))
+		})
+	}
+}
@@ -29,6 +29,8 @@ type OspkgDetectorDetectArgs struct {
 	CreatedAnything   bool
 	Pkgs              []types.Package
 	PkgsAnything      bool
+	Esm               bool
+	EsmAnything       bool
 }
 
 type OspkgDetectorDetectReturns struct {
@@ -74,6 +76,11 @@ func (_m *MockOspkgDetector) ApplyDetectExpectation(e OspkgDetectorDetectExpecta
 	} else {
 		args = append(args, e.Args.Pkgs)
 	}
+	if e.Args.EsmAnything {
+		args = append(args, mock.Anything)
+	} else {
+		args = append(args, e.Args.Esm)
+	}
 	_m.On("Detect", args...).Return(e.Returns.DetectedVulns, e.Returns.Eosl, e.Returns.Err)
 }
 
@@ -83,13 +90,13 @@ func (_m *MockOspkgDetector) ApplyDetectExpectations(expectations []OspkgDetecto
 	}
 }
 
-// Detect provides a mock function with given fields: imageName, osFamily, osName, created, pkgs
-func (_m *MockOspkgDetector) Detect(imageName string, osFamily string, osName string, repo *types.Repository, created time.Time, pkgs []types.Package) ([]pkgtypes.DetectedVulnerability, bool, error) {
-	ret := _m.Called(imageName, osFamily, osName, repo, created, pkgs)
+// Detect provides a mock function with given fields: imageName, osFamily, osName, repo, created, pkgs, esm
+func (_m *MockOspkgDetector) Detect(imageName string, osFamily string, osName string, repo *types.Repository, created time.Time, pkgs []types.Package, esm bool) ([]pkgtypes.DetectedVulnerability, bool, error) {
+	ret := _m.Called(imageName, osFamily, osName, repo, created, pkgs, esm)
 
 	var r0 []pkgtypes.DetectedVulnerability
-	if rf, ok := ret.Get(0).(func(string, string, string, *types.Repository, time.Time, []types.Package) []pkgtypes.DetectedVulnerability); ok {
-		r0 = rf(imageName, osFamily, osName, repo, created, pkgs)
+	if rf, ok := ret.Get(0).(func(string, string, string, *types.Repository, time.Time, []types.Package, bool) []pkgtypes.DetectedVulnerability); ok {
+		r0 = rf(imageName, osFamily, osName, repo, created, pkgs, esm)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]pkgtypes.DetectedVulnerability)
@@ -97,15 +104,15 @@ func (_m *MockOspkgDetector) Detect(imageName string, osFamily string, osName st
 	}
 
 	var r1 bool
-	if rf, ok := ret.Get(1).(func(string, string, string, *types.Repository, time.Time, []types.Package) bool); ok {
-		r1 = rf(imageName, osFamily, osName, repo, created, pkgs)
+	if rf, ok := ret.Get(1).(func(string, string, string, *types.Repository, time.Time, []types.Package, bool) bool); ok {
+		r1 = rf(imageName, osFamily, osName, repo, created, pkgs, esm)
 	} else {
 		r1 = ret.Get(1).(bool)
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(string, string, string, *types.Repository, time.Time, []types.Package) error); ok {
-		r2 = rf(imageName, osFamily, osName, repo, created, pkgs)
+	if rf, ok := ret.Get(2).(func(string, string, string, *types.Repository, time.Time, []types.Package, bool) error); ok {
+		r2 = rf(imageName, osFamily, osName, repo, created, pkgs, esm)
 	} else {
 		r2 = ret.Error(2)
 	}
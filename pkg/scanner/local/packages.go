@@ -0,0 +1,142 @@
+package local
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// packageDigests holds the digests of the packages recorded by the digest analyzers
+type packageDigests struct {
+	// dbPkgs maps the names of the packages in the package databases to the digests
+	dbPkgs map[string]dbPackageDigest
+
+	// files maps the paths of the package files to the digests
+	files map[string]string
+
+	// hashes maps the paths of the package files to the hashes calculated with '--sbom-hashes'
+	hashes map[string][]string
+}
+
+type dbPackageDigest struct {
+	filePath string
+	digest   string
+}
+
+// splitPackageDigests splits the digests and the hashes of the packages from the custom resources
+func splitPackageDigests(resources []ftypes.CustomResource) (packageDigests, []ftypes.CustomResource) {
+	digests := packageDigests{
+		dbPkgs: map[string]dbPackageDigest{},
+		files:  map[string]string{},
+		hashes: map[string][]string{},
+	}
+	var customResources []ftypes.CustomResource
+	for _, res := range resources {
+		if res.Type == types.PackageHashType {
+			var hashes []string
+			if err := remarshal(res.Data, &hashes); err != nil {
+				log.Logger.Debugf("Invalid package hashes in %s: %s", res.FilePath, err)
+				continue
+			}
+			digests.hashes[res.FilePath] = hashes
+			continue
+		}
+		if res.Type != types.PackageDigestType {
+			customResources = append(customResources, res)
+			continue
+		}
+
+		// The data can be decoded from the cache
+		switch data := res.Data.(type) {
+		case string:
+			digests.files[res.FilePath] = data
+		default:
+			var pkgDigests map[string]string
+			if err := remarshal(data, &pkgDigests); err != nil {
+				log.Logger.Debugf("Invalid package digests in %s: %s", res.FilePath, err)
+				continue
+			}
+			for name, digest := range pkgDigests {
+				digests.dbPkgs[name] = dbPackageDigest{
+					filePath: res.FilePath,
+					digest:   digest,
+				}
+			}
+		}
+	}
+	return digests, customResources
+}
+
+func remarshal(in, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// osPackages lists the OS packages with the package database they are installed in and the digests.
+// The source package is the binary package itself when the package manager doesn't tell it.
+func (d packageDigests) osPackages(pkgs []ftypes.Package) []types.Package {
+	packages := types.NewPackages(pkgs)
+	for i := range packages {
+		pkg := &packages[i]
+		if dbPkg, ok := d.dbPkgs[pkg.Name]; ok {
+			pkg.Digest = dbPkg.digest
+			if pkg.FilePath == "" {
+				pkg.FilePath = dbPkg.filePath
+			}
+		}
+		if pkg.SrcName == "" {
+			pkg.SrcName, pkg.SrcVersion, pkg.SrcRelease, pkg.SrcEpoch = pkg.Name, pkg.Version, pkg.Release, pkg.Epoch
+		}
+	}
+	return packages
+}
+
+// libraries lists the packages of the application with the lock file they are found in and the digests.
+// The digest and the hashes of a package file are given only to the package when the file contains no other package,
+// as the digest of e.g. a fat JAR isn't the one of the packages bundled in it.
+func (d packageDigests) libraries(app ftypes.Application) []types.Package {
+	files := map[string]int{}
+	hashedFiles := map[string]int{}
+	for _, lib := range app.Libraries {
+		files[lib.FilePath]++
+		if f, ok := d.hashedFile(lib.FilePath); ok {
+			hashedFiles[f]++
+		}
+	}
+
+	packages := types.NewPackages(app.Libraries)
+	for i := range packages {
+		pkg := &packages[i]
+		if pkg.FilePath == "" {
+			pkg.FilePath = app.FilePath
+			continue
+		}
+		if digest, ok := d.files[pkg.FilePath]; ok && files[pkg.FilePath] == 1 {
+			pkg.Digest = digest
+		}
+		if f, ok := d.hashedFile(pkg.FilePath); ok && hashedFiles[f] == 1 {
+			pkg.Hashes = d.hashes[f]
+		}
+	}
+	return packages
+}
+
+// hashedFile returns the hashed package file the package is found in, which is the file itself,
+// or the archive containing it, e.g. the wheel of "app/requests-2.28.1-py3-none-any.whl/requests-2.28.1.dist-info/METADATA"
+func (d packageDigests) hashedFile(filePath string) (string, bool) {
+	if filePath == "" {
+		return "", false
+	}
+	for p := filePath; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if _, ok := d.hashes[p]; ok {
+			return p, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_packageDigests_libraries(t *testing.T) {
+	digests, _ := splitPackageDigests([]ftypes.CustomResource{
+		{
+			Type:     types.PackageHashType,
+			FilePath: "dist/requests-2.28.1-py3-none-any.whl",
+			Data:     []string{"sha256:7c5599b102feddaa661c826c56ab4fee28bfd17f5abca1ebbe3e7f19d7c97983"},
+		},
+		{
+			Type:     types.PackageHashType,
+			FilePath: "dist/bundle.whl",
+			Data:     []string{"sha256:0a8d8f2e1c3f7a3e8c9c6b5e9a7d1f4c2b3e5d6f7a8b9c0d1e2f3a4b5c6d7e8f"},
+		},
+	})
+
+	got := digests.libraries(ftypes.Application{
+		Type: ftypes.PythonPkg,
+		Libraries: []ftypes.Package{
+			{
+				Name:     "requests",
+				Version:  "2.28.1",
+				FilePath: "dist/requests-2.28.1-py3-none-any.whl/requests-2.28.1.dist-info/METADATA",
+			},
+			{
+				Name:     "certifi",
+				Version:  "2022.6.15",
+				FilePath: "dist/bundle.whl/certifi-2022.6.15.dist-info/METADATA",
+			},
+			{
+				Name:     "idna",
+				Version:  "3.3",
+				FilePath: "dist/bundle.whl/idna-3.3.dist-info/METADATA",
+			},
+			{
+				Name:     "urllib3",
+				Version:  "1.26.10",
+				FilePath: "usr/lib/python3.10/site-packages/urllib3-1.26.10.dist-info/METADATA",
+			},
+		},
+	})
+
+	// The wheel bundling several packages isn't the package file of any of them
+	want := []types.Package{
+		{
+			Package: ftypes.Package{
+				Name:     "requests",
+				Version:  "2.28.1",
+				FilePath: "dist/requests-2.28.1-py3-none-any.whl/requests-2.28.1.dist-info/METADATA",
+			},
+			Hashes: []string{"sha256:7c5599b102feddaa661c826c56ab4fee28bfd17f5abca1ebbe3e7f19d7c97983"},
+		},
+		{
+			Package: ftypes.Package{
+				Name:     "certifi",
+				Version:  "2022.6.15",
+				FilePath: "dist/bundle.whl/certifi-2022.6.15.dist-info/METADATA",
+			},
+		},
+		{
+			Package: ftypes.Package{
+				Name:     "idna",
+				Version:  "3.3",
+				FilePath: "dist/bundle.whl/idna-3.3.dist-info/METADATA",
+			},
+		},
+		{
+			Package: ftypes.Package{
+				Name:     "urllib3",
+				Version:  "1.26.10",
+				FilePath: "usr/lib/python3.10/site-packages/urllib3-1.26.10.dist-info/METADATA",
+			},
+		},
+	}
+	assert.Equal(t, want, got)
+}
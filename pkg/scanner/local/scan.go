@@ -17,11 +17,16 @@ import (
 	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/detector/library"
 	ospkgDetector "github.com/aquasecurity/trivy/pkg/detector/ospkg"
+	"github.com/aquasecurity/trivy/pkg/licensing"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/osv"
 	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/unpinned"
 
 	_ "github.com/aquasecurity/fanal/analyzer/all"
 	_ "github.com/aquasecurity/fanal/handler/all"
+	// The analyzers of trivy replacing those of fanal
+	_ "github.com/aquasecurity/trivy/pkg/apk"
 )
 
 var (
@@ -49,7 +54,7 @@ type Applier interface {
 
 // OspkgDetector defines operation to detect OS vulnerabilities
 type OspkgDetector interface {
-	Detect(imageName, osFamily, osName string, repo *ftypes.Repository, created time.Time, pkgs []ftypes.Package) (detectedVulns []types.DetectedVulnerability, eosl bool, err error)
+	Detect(imageName, osFamily, osName string, repo *ftypes.Repository, created time.Time, pkgs []ftypes.Package, esm bool) (detectedVulns []types.DetectedVulnerability, eosl bool, err error)
 }
 
 // Scanner implements the OspkgDetector and LibraryDetector
@@ -89,10 +94,20 @@ func (s Scanner) Scan(target string, artifactKey string, blobKeys []string, opti
 	var eosl bool
 	var results types.Results
 
+	// The digests of the packages listed with '--list-all-pkgs'
+	digests, customResources := splitPackageDigests(artifactDetail.CustomResources)
+
+	// How the applications depend on the packages, where the lock files and the manifests tell
+	scopes, customResources := splitPackageScopes(customResources)
+	artifactDetail.CustomResources = customResources
+	if options.ExcludeDevDeps {
+		artifactDetail.Applications = scopes.excludeDev(artifactDetail.Applications)
+	}
+
 	// Scan OS packages and language-specific dependencies
 	if slices.Contains(options.SecurityChecks, types.SecurityCheckVulnerability) {
 		var vulnResults types.Results
-		vulnResults, eosl, err = s.checkVulnerabilities(target, artifactDetail, options)
+		vulnResults, eosl, err = s.checkVulnerabilities(target, artifactDetail, digests, scopes, options)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("failed to detect vulnerabilities: %w", err)
 		}
@@ -114,16 +129,54 @@ func (s Scanner) Scan(target string, artifactKey string, blobKeys []string, opti
 		results = append(results, secretResults...)
 	}
 
+	// Scan licenses
+	if slices.Contains(options.SecurityChecks, types.SecurityCheckLicense) {
+		licenseResults := s.licensesToResults(artifactDetail)
+		results = append(results, licenseResults...)
+	}
+
+	// For WASM modules and other custom analyzers
+	customResources, skippedResults := splitSkippedFiles(artifactDetail.CustomResources)
+	if len(customResources) > 0 {
+		results = append(results, types.Result{
+			Target:          target,
+			Class:           types.ClassCustom,
+			CustomResources: customResources,
+		})
+	}
+
+	// The files skipped during the analysis, e.g. with '--file-timeout'
+	results = append(results, skippedResults...)
+
 	return results, artifactDetail.OS, nil
 }
 
-func (s Scanner) checkVulnerabilities(target string, detail ftypes.ArtifactDetail, options types.ScanOptions) (
-	types.Results, bool, error) {
+// splitSkippedFiles splits the files skipped during the analysis from the custom resources,
+// and returns them as the results not scanned
+func splitSkippedFiles(resources []ftypes.CustomResource) ([]ftypes.CustomResource, types.Results) {
+	var customResources []ftypes.CustomResource
+	var skipped types.Results
+	for _, res := range resources {
+		if res.Type != types.SkippedFileType {
+			customResources = append(customResources, res)
+			continue
+		}
+		reason, _ := res.Data.(string)
+		skipped = append(skipped, types.Result{
+			Target:     res.FilePath,
+			NotScanned: reason,
+		})
+	}
+	return customResources, skipped
+}
+
+func (s Scanner) checkVulnerabilities(target string, detail ftypes.ArtifactDetail, digests packageDigests,
+	scopes packageScopes, options types.ScanOptions) (types.Results, bool, error) {
 	var eosl bool
 	var results types.Results
 
 	if slices.Contains(options.VulnType, types.VulnTypeOS) {
-		result, detectedEosl, err := s.scanOSPkgs(target, detail, options)
+		result, detectedEosl, err := s.scanOSPkgs(target, detail, digests, options)
 		if err != nil {
 			return nil, false, xerrors.Errorf("unable to scan OS packages: %w", err)
 		} else if result != nil {
@@ -133,7 +186,7 @@ func (s Scanner) checkVulnerabilities(target string, detail ftypes.ArtifactDetai
 	}
 
 	if slices.Contains(options.VulnType, types.VulnTypeLibrary) {
-		libResults, err := s.scanLibrary(detail.Applications, options)
+		libResults, err := s.scanLibrary(detail.Applications, digests, scopes, options)
 		if err != nil {
 			return nil, false, xerrors.Errorf("failed to scan application libraries: %w", err)
 		}
@@ -143,8 +196,8 @@ func (s Scanner) checkVulnerabilities(target string, detail ftypes.ArtifactDetai
 	return results, eosl, nil
 }
 
-func (s Scanner) scanOSPkgs(target string, detail ftypes.ArtifactDetail, options types.ScanOptions) (
-	*types.Result, bool, error) {
+func (s Scanner) scanOSPkgs(target string, detail ftypes.ArtifactDetail, digests packageDigests,
+	options types.ScanOptions) (*types.Result, bool, error) {
 	if detail.OS == nil {
 		log.Logger.Debug("Detected OS: unknown")
 		return nil, false, nil
@@ -156,7 +209,7 @@ func (s Scanner) scanOSPkgs(target string, detail ftypes.ArtifactDetail, options
 		pkgs = mergePkgs(pkgs, detail.HistoryPackages)
 	}
 
-	result, eosl, err := s.detectVulnsInOSPkgs(target, detail.OS.Family, detail.OS.Name, detail.Repository, pkgs)
+	result, eosl, err := s.detectVulnsInOSPkgs(target, detail.OS.Family, detail.OS.Name, detail.Repository, pkgs, options.ESM)
 	if err != nil {
 		return nil, false, xerrors.Errorf("failed to scan OS packages: %w", err)
 	} else if result == nil {
@@ -167,17 +220,18 @@ func (s Scanner) scanOSPkgs(target string, detail ftypes.ArtifactDetail, options
 		sort.Slice(pkgs, func(i, j int) bool {
 			return strings.Compare(pkgs[i].Name, pkgs[j].Name) <= 0
 		})
-		result.Packages = pkgs
+		result.Packages = digests.osPackages(pkgs)
 	}
 
 	return result, eosl, nil
 }
 
-func (s Scanner) detectVulnsInOSPkgs(target, osFamily, osName string, repo *ftypes.Repository, pkgs []ftypes.Package) (*types.Result, bool, error) {
+func (s Scanner) detectVulnsInOSPkgs(target, osFamily, osName string, repo *ftypes.Repository, pkgs []ftypes.Package,
+	esm bool) (*types.Result, bool, error) {
 	if osFamily == "" {
 		return nil, false, nil
 	}
-	vulns, eosl, err := s.ospkgDetector.Detect("", osFamily, osName, repo, time.Time{}, pkgs)
+	vulns, eosl, err := s.ospkgDetector.Detect("", osFamily, osName, repo, time.Time{}, pkgs, esm)
 	if err == ospkgDetector.ErrUnsupportedOS {
 		return nil, false, nil
 	} else if err != nil {
@@ -194,7 +248,11 @@ func (s Scanner) detectVulnsInOSPkgs(target, osFamily, osName string, repo *ftyp
 	return result, eosl, nil
 }
 
-func (s Scanner) scanLibrary(apps []ftypes.Application, options types.ScanOptions) (types.Results, error) {
+func (s Scanner) scanLibrary(apps []ftypes.Application, digests packageDigests, scopes packageScopes,
+	options types.ScanOptions) (types.Results, error) {
+	// The version ranges are not scanned when the lock files pin them
+	apps = unpinned.SkipLocked(apps)
+
 	log.Logger.Infof("Number of language-specific files: %d", len(apps))
 	if len(apps) == 0 {
 		return nil, nil
@@ -214,10 +272,17 @@ func (s Scanner) scanLibrary(apps []ftypes.Application, options types.ScanOption
 		}
 
 		log.Logger.Debugf("Detecting library vulnerabilities, type: %s, path: %s", app.Type, app.FilePath)
-		vulns, err := library.Detect(app.Type, app.Libraries)
+		vulns, err := detectLibrary(app, options)
 		if err != nil {
 			return nil, xerrors.Errorf("failed vulnerability detection of libraries: %w", err)
 		}
+		scope := scopes.of(app)
+		fillRelationships(app, scope, vulns)
+		scope.fillScopes(vulns)
+
+		if options.DependencyTree {
+			fillDependencyPaths(app, vulns)
+		}
 
 		target := app.FilePath
 		if t, ok := pkgTargets[app.Type]; ok && target == "" {
@@ -232,7 +297,9 @@ func (s Scanner) scanLibrary(apps []ftypes.Application, options types.ScanOption
 			Type:            app.Type,
 		}
 		if options.ListAllPackages {
-			libReport.Packages = app.Libraries
+			libReport.Packages = digests.libraries(app)
+			scope.fillPackages(libReport.Packages)
+			libReport.Hashes = digests.hashes[app.FilePath]
 		}
 		results = append(results, libReport)
 	}
@@ -242,6 +309,14 @@ func (s Scanner) scanLibrary(apps []ftypes.Application, options types.ScanOption
 	return results, nil
 }
 
+// detectLibrary detects the vulnerabilities with the DB, or with OSV for the ecosystems which the DB doesn't cover
+func detectLibrary(app ftypes.Application, options types.ScanOptions) ([]types.DetectedVulnerability, error) {
+	if options.OSV.Enabled && osv.Supported(app.Type) {
+		return osv.NewClient(options.OSV).Detect(app.Type, app.Libraries)
+	}
+	return library.Detect(app.Type, app.Libraries)
+}
+
 func (s Scanner) misconfsToResults(misconfs []ftypes.Misconfiguration) types.Results {
 	log.Logger.Infof("Detected config files: %d", len(misconfs))
 	var results types.Results
@@ -292,6 +367,54 @@ func (s Scanner) secretsToResults(secrets []ftypes.Secret) types.Results {
 	return results
 }
 
+// licensesToResults lists the licenses of the OS packages and the language-specific packages.
+// They are classified by the license policy when the results are filtered.
+func (s Scanner) licensesToResults(detail ftypes.ArtifactDetail) types.Results {
+	log.Logger.Info("Detecting licenses...")
+
+	var results types.Results
+	if licenses := toDetectedLicenses(detail.Packages); len(licenses) > 0 {
+		results = append(results, types.Result{
+			Target:   "OS Packages",
+			Class:    types.ClassLicense,
+			Licenses: licenses,
+		})
+	}
+
+	for _, app := range detail.Applications {
+		licenses := toDetectedLicenses(app.Libraries)
+		if len(licenses) == 0 {
+			continue
+		}
+
+		target := app.FilePath
+		if t, ok := pkgTargets[app.Type]; ok && target == "" {
+			target = t
+		}
+		results = append(results, types.Result{
+			Target:   target,
+			Class:    types.ClassLicense,
+			Type:     app.Type,
+			Licenses: licenses,
+		})
+	}
+	return results
+}
+
+func toDetectedLicenses(pkgs []ftypes.Package) []types.DetectedLicense {
+	var licenses []types.DetectedLicense
+	for _, pkg := range pkgs {
+		for _, license := range licensing.Split(pkg.License) {
+			licenses = append(licenses, types.DetectedLicense{
+				PkgName:  pkg.Name,
+				FilePath: pkg.FilePath,
+				Name:     license,
+			})
+		}
+	}
+	return licenses
+}
+
 func toDetectedMisconfiguration(res ftypes.MisconfResult, defaultSeverity dbTypes.Severity,
 	status types.MisconfStatus, layer ftypes.Layer) types.DetectedMisconfiguration {
 
@@ -357,3 +480,148 @@ func mergePkgs(pkgs, pkgsFromCommands []ftypes.Package) []ftypes.Package {
 	}
 	return pkgs
 }
+
+// maxDependencyPaths is the maximum number of the paths shown for each package, since popular packages
+// can be pulled in through a huge number of paths
+const maxDependencyPaths = 5
+
+// fillDependencyPaths fills the chains of the dependencies pulling in the vulnerable packages, starting from
+// the direct dependencies, i.e. the packages no other package depends on.
+// The dependency graph is available only for some lock files such as package-lock.json.
+func fillDependencyPaths(app ftypes.Application, vulns []types.DetectedVulnerability) {
+	if len(app.Dependencies) == 0 {
+		return
+	}
+
+	ids := map[string]string{}
+	names := map[string]string{}
+	for _, lib := range app.Libraries {
+		if lib.ID == "" {
+			continue
+		}
+		ids[lib.Name+"@"+lib.Version] = lib.ID
+		names[lib.ID] = lib.Name + "@" + lib.Version
+	}
+
+	// The direct dependencies are the roots when the analyzer tells them, e.g. in workspaces of Node.js
+	direct := map[string]bool{}
+	if hasIndirect(app.Libraries) {
+		for _, lib := range app.Libraries {
+			direct[lib.ID] = lib.ID != "" && !lib.Indirect
+		}
+	}
+
+	parents := map[string][]string{}
+	for _, dep := range app.Dependencies {
+		for _, child := range dep.DependsOn {
+			parents[child] = append(parents[child], dep.ID)
+		}
+	}
+	for _, p := range parents {
+		sort.Strings(p)
+	}
+
+	cache := map[string][][]string{}
+	for i, vuln := range vulns {
+		id, ok := ids[vuln.PkgName+"@"+vuln.InstalledVersion]
+		if !ok {
+			continue
+		}
+		paths, ok := cache[id]
+		if !ok {
+			paths = dependencyPaths(id, parents, direct, names)
+			cache[id] = paths
+		}
+		vulns[i].DependencyPaths = paths
+	}
+}
+
+// dependencyPaths returns the chains from the direct dependencies to the package, which are empty for direct dependencies
+func dependencyPaths(id string, parents map[string][]string, direct map[string]bool, names map[string]string) [][]string {
+	var paths [][]string
+	onPath := map[string]bool{}
+
+	var walk func(id string, path []string)
+	walk = func(id string, path []string) {
+		if len(paths) >= maxDependencyPaths || onPath[id] {
+			return
+		}
+		name, ok := names[id]
+		if !ok {
+			name = id
+		}
+		path = append(path, name)
+
+		if len(parents[id]) == 0 || direct[id] {
+			// The direct dependency is reached
+			if len(path) > 1 {
+				p := make([]string, len(path))
+				for i := range path {
+					p[i] = path[len(path)-1-i]
+				}
+				paths = append(paths, p)
+			}
+			return
+		}
+
+		onPath[id] = true
+		for _, parent := range parents[id] {
+			walk(parent, path)
+		}
+		onPath[id] = false
+	}
+	walk(id, nil)
+
+	return paths
+}
+
+// fillRelationships marks whether the packages are direct or indirect dependencies where the project tells.
+// go.mod marks the indirect dependencies with "// indirect", and so do the analyzers resolving the workspaces of Node.js.
+// The others list the direct dependencies in the lock files or the manifests, e.g. "DEPENDENCIES" of Gemfile.lock.
+// Without them, the packages other packages depend on are regarded as indirect.
+func fillRelationships(app ftypes.Application, scope dependencyScope, vulns []types.DetectedVulnerability) {
+	switch {
+	case app.Type == ftypes.GoModule, hasIndirect(app.Libraries):
+	case scope.direct != nil:
+		for i, lib := range app.Libraries {
+			app.Libraries[i].Indirect = !scope.direct[packageKey(lib)]
+		}
+	case len(app.Dependencies) > 0:
+		children := map[string]bool{}
+		for _, dep := range app.Dependencies {
+			for _, child := range dep.DependsOn {
+				children[child] = true
+			}
+		}
+		for i, lib := range app.Libraries {
+			app.Libraries[i].Indirect = children[lib.ID]
+		}
+	default:
+		return
+	}
+
+	indirect := map[string]bool{}
+	for _, lib := range app.Libraries {
+		indirect[lib.Name+"@"+lib.Version] = lib.Indirect
+	}
+	for i, vuln := range vulns {
+		ind, ok := indirect[vuln.PkgName+"@"+vuln.InstalledVersion]
+		if !ok {
+			continue
+		}
+		vulns[i].PkgRelationship = types.RelationshipDirect
+		if ind {
+			vulns[i].PkgRelationship = types.RelationshipIndirect
+		}
+	}
+}
+
+// hasIndirect returns true if the analyzer has marked any indirect dependencies
+func hasIndirect(libs []ftypes.Package) bool {
+	for _, lib := range libs {
+		if lib.Indirect {
+			return true
+		}
+	}
+	return false
+}
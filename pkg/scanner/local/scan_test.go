@@ -10,6 +10,7 @@ import (
 	"github.com/aquasecurity/fanal/analyzer"
 	fos "github.com/aquasecurity/fanal/analyzer/os"
 	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
 	"github.com/aquasecurity/trivy-db/pkg/db"
 	"github.com/aquasecurity/trivy/pkg/dbtest"
 	ospkgDetector "github.com/aquasecurity/trivy/pkg/detector/ospkg"
@@ -203,6 +204,66 @@ func TestScanner_Scan(t *testing.T) {
 									},
 								},
 							},
+							{
+								Type: ftypes.Jar,
+								Libraries: []ftypes.Package{
+									{
+										Name:     "org.apache.logging.log4j:log4j-api",
+										Version:  "2.17.1",
+										FilePath: "app/log4j-api-2.17.1.jar",
+									},
+									{
+										Name:     "com.example:app",
+										Version:  "1.0",
+										FilePath: "app/app.jar",
+									},
+									{
+										Name:     "com.google.guava:guava",
+										Version:  "31.0",
+										FilePath: "app/app.jar",
+									},
+								},
+							},
+						},
+						CustomResources: []ftypes.CustomResource{
+							{
+								Type:     types.PackageDigestType,
+								FilePath: "lib/apk/db/installed",
+								Data: map[string]interface{}{
+									"musl": "sha1:1b6a55f8e8d9b0a4e0c3a4a1ad6fe24b6757b648",
+								},
+							},
+							{
+								Type:     types.PackageDigestType,
+								FilePath: "app/log4j-api-2.17.1.jar",
+								Data:     "sha1:ea1b37f38c327596b216542bc636cfdc0b8036fa",
+							},
+							{
+								Type:     types.PackageDigestType,
+								FilePath: "app/app.jar",
+								Data:     "sha1:e6d3e3d26e7e0bb8ea8da6e14e4ac1f2bd6ca2b0",
+							},
+							{
+								Type:     types.PackageHashType,
+								FilePath: "app/log4j-api-2.17.1.jar",
+								Data: []interface{}{
+									"sha256:1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d",
+								},
+							},
+							{
+								Type:     types.PackageHashType,
+								FilePath: "app/app.jar",
+								Data: []interface{}{
+									"sha256:b50d4931c7df0f8e8e3b02e7c7d9bb0561bd3a1bbd64b8b9ff6a5b2d4e4d4d43",
+								},
+							},
+							{
+								Type:     types.PackageHashType,
+								FilePath: "/app/Gemfile.lock",
+								Data: []interface{}{
+									"sha256:0ea33a93585cf1917ba522b2304634c3073654062d5282c1346322967790ef33",
+								},
+							},
 						},
 					},
 				},
@@ -248,20 +309,30 @@ func TestScanner_Scan(t *testing.T) {
 			wantResults: types.Results{
 				{
 					Target: "alpine:latest (alpine 3.11)",
-					Packages: []ftypes.Package{
+					Packages: []types.Package{
 						{
-							Name:    "ausl",
-							Version: "1.2.3",
-							Layer: ftypes.Layer{
-								DiffID: "sha256:bbf12965380b39889c99a9c02e82ba465f887b45975b6e389d42e9e6a3857888",
+							Package: ftypes.Package{
+								Name:       "ausl",
+								Version:    "1.2.3",
+								SrcName:    "ausl",
+								SrcVersion: "1.2.3",
+								Layer: ftypes.Layer{
+									DiffID: "sha256:bbf12965380b39889c99a9c02e82ba465f887b45975b6e389d42e9e6a3857888",
+								},
 							},
 						},
 						{
-							Name:    "musl",
-							Version: "1.2.3",
-							Layer: ftypes.Layer{
-								DiffID: "sha256:ebf12965380b39889c99a9c02e82ba465f887b45975b6e389d42e9e6a3857888",
+							Package: ftypes.Package{
+								Name:       "musl",
+								Version:    "1.2.3",
+								SrcName:    "musl",
+								SrcVersion: "1.2.3",
+								Layer: ftypes.Layer{
+									DiffID: "sha256:ebf12965380b39889c99a9c02e82ba465f887b45975b6e389d42e9e6a3857888",
+								},
+								FilePath: "lib/apk/db/installed",
 							},
+							Digest: "sha1:1b6a55f8e8d9b0a4e0c3a4a1ad6fe24b6757b648",
 						},
 					},
 					Vulnerabilities: []types.DetectedVulnerability{
@@ -280,12 +351,15 @@ func TestScanner_Scan(t *testing.T) {
 				},
 				{
 					Target: "/app/Gemfile.lock",
-					Packages: []ftypes.Package{
+					Packages: []types.Package{
 						{
-							Name:    "rails",
-							Version: "4.0.2",
-							Layer: ftypes.Layer{
-								DiffID: "sha256:0ea33a93585cf1917ba522b2304634c3073654062d5282c1346322967790ef33",
+							Package: ftypes.Package{
+								Name:    "rails",
+								Version: "4.0.2",
+								Layer: ftypes.Layer{
+									DiffID: "sha256:0ea33a93585cf1917ba522b2304634c3073654062d5282c1346322967790ef33",
+								},
+								FilePath: "/app/Gemfile.lock",
 							},
 						},
 					},
@@ -300,8 +374,40 @@ func TestScanner_Scan(t *testing.T) {
 							},
 						},
 					},
+					Class:  types.ClassLangPkg,
+					Type:   ftypes.Bundler,
+					Hashes: []string{"sha256:0ea33a93585cf1917ba522b2304634c3073654062d5282c1346322967790ef33"},
+				},
+				{
+					Target: "Java",
+					Packages: []types.Package{
+						{
+							Package: ftypes.Package{
+								Name:     "org.apache.logging.log4j:log4j-api",
+								Version:  "2.17.1",
+								FilePath: "app/log4j-api-2.17.1.jar",
+							},
+							Digest: "sha1:ea1b37f38c327596b216542bc636cfdc0b8036fa",
+							Hashes: []string{"sha256:1bb6e3aa5d6e3a1f8fd9ad2a3a6d4b28b8fd6a5c9c0bd243c3d0c0f84e9f5d3d"},
+						},
+						{
+							// The digest and the hashes of the file aren't the ones of the packages in the fat JAR
+							Package: ftypes.Package{
+								Name:     "com.example:app",
+								Version:  "1.0",
+								FilePath: "app/app.jar",
+							},
+						},
+						{
+							Package: ftypes.Package{
+								Name:     "com.google.guava:guava",
+								Version:  "31.0",
+								FilePath: "app/app.jar",
+							},
+						},
+					},
 					Class: types.ClassLangPkg,
-					Type:  ftypes.Bundler,
+					Type:  ftypes.Jar,
 				},
 			},
 			wantOS: &ftypes.OS{
@@ -542,6 +648,57 @@ func TestScanner_Scan(t *testing.T) {
 			wantResults: nil,
 			wantOS:      nil,
 		},
+		{
+			name: "happy path with skipped files",
+			args: args{
+				target:   "/app",
+				layerIDs: []string{"sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"},
+				options: types.ScanOptions{
+					VulnType:       []string{types.VulnTypeOS, types.VulnTypeLibrary},
+					SecurityChecks: []string{types.SecurityCheckVulnerability},
+				},
+			},
+			fixtures: []string{"testdata/fixtures/happy.yaml"},
+			applyLayersExpectation: ApplierApplyLayersExpectation{
+				Args: ApplierApplyLayersArgs{
+					BlobIDs: []string{"sha256:5216338b40a7b96416b8b9858974bbe4acc3096ee60acbc4dfb1ee02aecceb10"},
+				},
+				Returns: ApplierApplyLayersReturns{
+					Detail: ftypes.ArtifactDetail{
+						CustomResources: []ftypes.CustomResource{
+							{
+								Type:     "wasm-module",
+								FilePath: "app/config.yaml",
+								Data:     "custom",
+							},
+							{
+								Type:     types.SkippedFileType,
+								FilePath: "app/dist/bundle.min.js",
+								Data:     "analysis timed out after 30s",
+							},
+						},
+					},
+					Err: analyzer.ErrUnknownOS,
+				},
+			},
+			wantResults: types.Results{
+				{
+					Target: "/app",
+					Class:  types.ClassCustom,
+					CustomResources: []ftypes.CustomResource{
+						{
+							Type:     "wasm-module",
+							FilePath: "app/config.yaml",
+							Data:     "custom",
+						},
+					},
+				},
+				{
+					Target:     "app/dist/bundle.min.js",
+					NotScanned: "analysis timed out after 30s",
+				},
+			},
+		},
 		{
 			name: "happy path with only library detection",
 			args: args{
@@ -941,3 +1098,189 @@ func TestScanner_Scan(t *testing.T) {
 		})
 	}
 }
+
+func Test_fillDependencyPaths(t *testing.T) {
+	// app -> express -> body-parser -> qs, and qs is pulled in by request as well
+	app := ftypes.Application{
+		Type:     ftypes.Npm,
+		FilePath: "package-lock.json",
+		Libraries: []ftypes.Package{
+			{ID: "express@4.17.1", Name: "express", Version: "4.17.1"},
+			{ID: "body-parser@1.19.0", Name: "body-parser", Version: "1.19.0"},
+			{ID: "request@2.88.0", Name: "request", Version: "2.88.0"},
+			{ID: "qs@6.7.0", Name: "qs", Version: "6.7.0"},
+			{ID: "lodash@4.17.4", Name: "lodash", Version: "4.17.4"},
+			{ID: "a@1.0.0", Name: "a", Version: "1.0.0"},
+			{ID: "b@1.0.0", Name: "b", Version: "1.0.0"},
+		},
+		Dependencies: []godeptypes.Dependency{
+			{ID: "express@4.17.1", DependsOn: []string{"body-parser@1.19.0"}},
+			{ID: "body-parser@1.19.0", DependsOn: []string{"qs@6.7.0"}},
+			{ID: "request@2.88.0", DependsOn: []string{"qs@6.7.0"}},
+			// Cycles have no direct dependencies
+			{ID: "a@1.0.0", DependsOn: []string{"b@1.0.0"}},
+			{ID: "b@1.0.0", DependsOn: []string{"a@1.0.0"}},
+		},
+	}
+	vulns := []types.DetectedVulnerability{
+		{VulnerabilityID: "CVE-2022-24999", PkgName: "qs", InstalledVersion: "6.7.0"},
+		{VulnerabilityID: "CVE-2021-23337", PkgName: "lodash", InstalledVersion: "4.17.4"},
+		{VulnerabilityID: "CVE-2022-0001", PkgName: "b", InstalledVersion: "1.0.0"},
+		{VulnerabilityID: "CVE-2022-0002", PkgName: "unknown", InstalledVersion: "1.0.0"},
+	}
+	fillDependencyPaths(app, vulns)
+
+	assert.Equal(t, [][]string{
+		{"express@4.17.1", "body-parser@1.19.0", "qs@6.7.0"},
+		{"request@2.88.0", "qs@6.7.0"},
+	}, vulns[0].DependencyPaths)
+	// Direct dependencies have no paths
+	assert.Empty(t, vulns[1].DependencyPaths)
+	assert.Empty(t, vulns[2].DependencyPaths)
+	assert.Empty(t, vulns[3].DependencyPaths)
+
+	// The paths start from the direct dependencies marked by the analyzer even if other packages depend on them
+	app = ftypes.Application{
+		Type: ftypes.Npm,
+		Libraries: []ftypes.Package{
+			{ID: "react@17.0.2", Name: "react", Version: "17.0.2"},
+			{ID: "react-dom@17.0.2", Name: "react-dom", Version: "17.0.2"},
+			{ID: "loose-envify@1.4.0", Name: "loose-envify", Version: "1.4.0", Indirect: true},
+		},
+		Dependencies: []godeptypes.Dependency{
+			{ID: "react@17.0.2", DependsOn: []string{"loose-envify@1.4.0"}},
+			{ID: "react-dom@17.0.2", DependsOn: []string{"loose-envify@1.4.0", "react@17.0.2"}},
+		},
+	}
+	vulns = []types.DetectedVulnerability{
+		{VulnerabilityID: "CVE-2022-0001", PkgName: "loose-envify", InstalledVersion: "1.4.0"},
+		{VulnerabilityID: "CVE-2022-0002", PkgName: "react", InstalledVersion: "17.0.2"},
+	}
+	fillDependencyPaths(app, vulns)
+
+	assert.Equal(t, [][]string{
+		{"react-dom@17.0.2", "loose-envify@1.4.0"},
+		{"react@17.0.2", "loose-envify@1.4.0"},
+	}, vulns[0].DependencyPaths)
+	assert.Empty(t, vulns[1].DependencyPaths)
+}
+
+func Test_fillRelationships(t *testing.T) {
+	tests := []struct {
+		name         string
+		app          ftypes.Application
+		scope        dependencyScope
+		vulns        []types.DetectedVulnerability
+		want         []string
+		wantIndirect []bool
+	}{
+		{
+			name: "package-lock.json",
+			app: ftypes.Application{
+				Type: ftypes.Npm,
+				Libraries: []ftypes.Package{
+					{ID: "express@4.17.1", Name: "express", Version: "4.17.1"},
+					{ID: "qs@6.7.0", Name: "qs", Version: "6.7.0"},
+				},
+				Dependencies: []godeptypes.Dependency{
+					{ID: "express@4.17.1", DependsOn: []string{"qs@6.7.0"}},
+				},
+			},
+			vulns: []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2022-24999", PkgName: "qs", InstalledVersion: "6.7.0"},
+				{VulnerabilityID: "CVE-2022-24434", PkgName: "express", InstalledVersion: "4.17.1"},
+				{VulnerabilityID: "CVE-2022-0001", PkgName: "unknown", InstalledVersion: "1.0.0"},
+			},
+			want:         []string{types.RelationshipIndirect, types.RelationshipDirect, ""},
+			wantIndirect: []bool{false, true},
+		},
+		{
+			name: "go.mod",
+			app: ftypes.Application{
+				Type: ftypes.GoModule,
+				Libraries: []ftypes.Package{
+					{Name: "golang.org/x/text", Version: "0.3.6", Indirect: true},
+					{Name: "github.com/gin-gonic/gin", Version: "1.7.0"},
+				},
+			},
+			vulns: []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2021-38561", PkgName: "golang.org/x/text", InstalledVersion: "0.3.6"},
+				{VulnerabilityID: "CVE-2023-26125", PkgName: "github.com/gin-gonic/gin", InstalledVersion: "1.7.0"},
+			},
+			want:         []string{types.RelationshipIndirect, types.RelationshipDirect},
+			wantIndirect: []bool{true, false},
+		},
+		{
+			name: "workspace member marked by the analyzer",
+			app: ftypes.Application{
+				Type: ftypes.Npm,
+				Libraries: []ftypes.Package{
+					{ID: "react@17.0.2", Name: "react", Version: "17.0.2"},
+					{ID: "react-dom@17.0.2", Name: "react-dom", Version: "17.0.2"},
+					{ID: "loose-envify@1.4.0", Name: "loose-envify", Version: "1.4.0", Indirect: true},
+				},
+				Dependencies: []godeptypes.Dependency{
+					{ID: "react@17.0.2", DependsOn: []string{"loose-envify@1.4.0"}},
+					{ID: "react-dom@17.0.2", DependsOn: []string{"loose-envify@1.4.0", "react@17.0.2"}},
+				},
+			},
+			vulns: []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2022-0001", PkgName: "react", InstalledVersion: "17.0.2"},
+				{VulnerabilityID: "CVE-2022-0002", PkgName: "loose-envify", InstalledVersion: "1.4.0"},
+			},
+			want:         []string{types.RelationshipDirect, types.RelationshipIndirect},
+			wantIndirect: []bool{false, false, true},
+		},
+		{
+			name: "Gemfile.lock listing the direct dependencies",
+			app: ftypes.Application{
+				Type: ftypes.Bundler,
+				Libraries: []ftypes.Package{
+					{ID: "nokogiri@1.13.6", Name: "nokogiri", Version: "1.13.6"},
+					{ID: "racc@1.6.0", Name: "racc", Version: "1.6.0"},
+				},
+				Dependencies: []godeptypes.Dependency{
+					{ID: "nokogiri@1.13.6", DependsOn: []string{"racc@1.6.0"}},
+				},
+			},
+			scope: dependencyScope{direct: map[string]bool{"nokogiri@1.13.6": true, "racc@1.6.0": true}},
+			vulns: []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2022-29181", PkgName: "nokogiri", InstalledVersion: "1.13.6"},
+				{VulnerabilityID: "CVE-2022-0001", PkgName: "racc", InstalledVersion: "1.6.0"},
+			},
+			want:         []string{types.RelationshipDirect, types.RelationshipDirect},
+			wantIndirect: []bool{false, false},
+		},
+		{
+			name: "no relationship",
+			app: ftypes.Application{
+				Type: ftypes.Yarn,
+				Libraries: []ftypes.Package{
+					{Name: "lodash", Version: "4.17.4"},
+				},
+			},
+			vulns: []types.DetectedVulnerability{
+				{VulnerabilityID: "CVE-2021-23337", PkgName: "lodash", InstalledVersion: "4.17.4"},
+			},
+			want:         []string{""},
+			wantIndirect: []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fillRelationships(tt.app, tt.scope, tt.vulns)
+
+			var got []string
+			for _, v := range tt.vulns {
+				got = append(got, v.PkgRelationship)
+			}
+			assert.Equal(t, tt.want, got)
+
+			var gotIndirect []bool
+			for _, lib := range tt.app.Libraries {
+				gotIndirect = append(gotIndirect, lib.Indirect)
+			}
+			assert.Equal(t, tt.wantIndirect, gotIndirect)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package local
+
+import (
+	"path/filepath"
+
+	"golang.org/x/exp/slices"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
+	"github.com/aquasecurity/trivy/pkg/php"
+	"github.com/aquasecurity/trivy/pkg/ruby"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// manifestFiles are the manifests declaring the dependencies of the lock files in the same directory,
+// which tell what the lock files don't
+var manifestFiles = map[string]string{
+	ftypes.Npm:      nodejs.PackageJSON,
+	ftypes.Yarn:     nodejs.PackageJSON,
+	nodejs.Pnpm:     nodejs.PackageJSON,
+	ftypes.Composer: php.ComposerJSON,
+	ftypes.Bundler:  ruby.Gemfile,
+}
+
+// devLockFiles are the lock files telling the development dependencies, even when there are none
+var devLockFiles = []string{ftypes.Npm, nodejs.Pnpm, ftypes.Composer}
+
+// packageScopes holds how the applications depend on the packages, recorded by the lock file and the manifest analyzers
+type packageScopes struct {
+	// lockFiles maps the paths of the applications to the scopes told by the lock files
+	lockFiles map[string]types.PackageScope
+
+	// manifests maps the paths of the manifests to the dependencies declared in them
+	manifests map[string]types.PackageManifest
+}
+
+// dependencyScope is how an application depends on the packages, keyed by "name@version".
+// The maps are nil when neither the lock file nor the manifest tells them.
+type dependencyScope struct {
+	direct map[string]bool
+	dev    map[string]bool
+}
+
+// splitPackageScopes splits the scopes and the manifests of the applications from the custom resources
+func splitPackageScopes(resources []ftypes.CustomResource) (packageScopes, []ftypes.CustomResource) {
+	scopes := packageScopes{
+		lockFiles: map[string]types.PackageScope{},
+		manifests: map[string]types.PackageManifest{},
+	}
+	var customResources []ftypes.CustomResource
+	for _, res := range resources {
+		switch res.Type {
+		case types.PackageScopeType:
+			var scope types.PackageScope
+			if err := remarshal(res.Data, &scope); err != nil {
+				log.Logger.Debugf("Invalid package scope in %s: %s", res.FilePath, err)
+				continue
+			}
+			scopes.lockFiles[res.FilePath] = scope
+		case types.PackageManifestType:
+			var manifest types.PackageManifest
+			if err := remarshal(res.Data, &manifest); err != nil {
+				log.Logger.Debugf("Invalid package manifest in %s: %s", res.FilePath, err)
+				continue
+			}
+			scopes.manifests[res.FilePath] = manifest
+		default:
+			customResources = append(customResources, res)
+		}
+	}
+	return scopes, customResources
+}
+
+// of returns how the application depends on the packages. The lock file is preferred, and the manifest in the same
+// directory tells the rest. The development dependencies are the packages reachable only from those declared for the
+// development, e.g. "devDependencies" of package.json.
+func (s packageScopes) of(app ftypes.Application) dependencyScope {
+	var scope dependencyScope
+	lockFile, ok := s.lockFiles[app.FilePath]
+	if ok && len(lockFile.Direct) > 0 {
+		scope.direct = toSet(lockFile.Direct)
+	}
+	if ok && (len(lockFile.Dev) > 0 || slices.Contains(devLockFiles, app.Type)) {
+		scope.dev = toSet(lockFile.Dev)
+	}
+	if scope.direct != nil && scope.dev != nil {
+		return scope
+	}
+
+	manifestFile, ok := manifestFiles[app.Type]
+	if !ok {
+		return scope
+	}
+	manifest, ok := s.manifests[filepath.Join(filepath.Dir(app.FilePath), manifestFile)]
+	if !ok {
+		return scope
+	}
+
+	g := newPackageGraph(app)
+	if scope.direct == nil {
+		scope.direct = toSet(append(g.packages(manifest.Dependencies), g.packages(manifest.DevDependencies)...))
+	}
+	if scope.dev == nil {
+		prod := g.reachable(manifest.Dependencies)
+		scope.dev = map[string]bool{}
+		for key := range g.reachable(manifest.DevDependencies) {
+			if !prod[key] {
+				scope.dev[key] = true
+			}
+		}
+	}
+	return scope
+}
+
+// excludeDev drops the development dependencies from the applications
+func (s packageScopes) excludeDev(apps []ftypes.Application) []ftypes.Application {
+	for i, app := range apps {
+		scope := s.of(app)
+		if len(scope.dev) == 0 {
+			continue
+		}
+
+		var libs []ftypes.Package
+		dev := map[string]bool{}
+		for _, lib := range app.Libraries {
+			if scope.dev[packageKey(lib)] {
+				dev[lib.ID] = true
+				continue
+			}
+			libs = append(libs, lib)
+		}
+		var deps []godeptypes.Dependency
+		for _, dep := range app.Dependencies {
+			if !dev[dep.ID] {
+				deps = append(deps, dep)
+			}
+		}
+		log.Logger.Debugf("%d development dependencies are excluded from %s", len(app.Libraries)-len(libs), app.FilePath)
+		apps[i].Libraries = libs
+		apps[i].Dependencies = deps
+	}
+	return apps
+}
+
+// fillScopes marks whether the vulnerable packages are the development or the production dependencies
+func (s dependencyScope) fillScopes(vulns []types.DetectedVulnerability) {
+	if s.dev == nil {
+		return
+	}
+	for i, vuln := range vulns {
+		vulns[i].PkgScope = types.ScopeProd
+		if s.dev[vuln.PkgName+"@"+vuln.InstalledVersion] {
+			vulns[i].PkgScope = types.ScopeDev
+		}
+	}
+}
+
+// fillPackages marks the development dependencies in the packages listed with '--list-all-pkgs'
+func (s dependencyScope) fillPackages(pkgs []types.Package) {
+	for i, pkg := range pkgs {
+		pkgs[i].Dev = s.dev[packageKey(pkg.Package)]
+	}
+}
+
+// packageGraph is the dependency graph of the application, keyed by "name@version"
+type packageGraph struct {
+	keys      map[string][]string
+	dependsOn map[string][]string
+}
+
+func newPackageGraph(app ftypes.Application) packageGraph {
+	g := packageGraph{
+		keys:      map[string][]string{},
+		dependsOn: map[string][]string{},
+	}
+	ids := map[string]string{}
+	for _, lib := range app.Libraries {
+		key := packageKey(lib)
+		g.keys[lib.Name] = append(g.keys[lib.Name], key)
+		if lib.ID != "" {
+			ids[lib.ID] = key
+		}
+	}
+	for _, dep := range app.Dependencies {
+		for _, child := range dep.DependsOn {
+			if key, ok := ids[child]; ok {
+				g.dependsOn[ids[dep.ID]] = append(g.dependsOn[ids[dep.ID]], key)
+			}
+		}
+	}
+	return g
+}
+
+// packages returns the packages of the names
+func (g packageGraph) packages(names []string) []string {
+	var keys []string
+	for _, name := range names {
+		keys = append(keys, g.keys[name]...)
+	}
+	return keys
+}
+
+// reachable returns the packages of the names and those they depend on
+func (g packageGraph) reachable(names []string) map[string]bool {
+	visited := map[string]bool{}
+	queue := g.packages(names)
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		queue = append(queue, g.dependsOn[key]...)
+	}
+	return visited
+}
+
+func packageKey(pkg ftypes.Package) string {
+	return pkg.Name + "@" + pkg.Version
+}
+
+func toSet(keys []string) map[string]bool {
+	set := map[string]bool{}
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
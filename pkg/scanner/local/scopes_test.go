@@ -0,0 +1,172 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// yarnApp is yarn.lock where "typescript" and "ts-node" are the development dependencies,
+// and "tslib" is pulled in by both "ts-node" and "lodash-es"
+var yarnApp = ftypes.Application{
+	Type:     ftypes.Yarn,
+	FilePath: "app/yarn.lock",
+	Libraries: []ftypes.Package{
+		{ID: "lodash-es@4.17.21", Name: "lodash-es", Version: "4.17.21"},
+		{ID: "tslib@2.4.0", Name: "tslib", Version: "2.4.0"},
+		{ID: "ts-node@10.8.0", Name: "ts-node", Version: "10.8.0"},
+		{ID: "arg@4.1.3", Name: "arg", Version: "4.1.3"},
+		{ID: "typescript@4.7.2", Name: "typescript", Version: "4.7.2"},
+	},
+	Dependencies: []godeptypes.Dependency{
+		{ID: "lodash-es@4.17.21", DependsOn: []string{"tslib@2.4.0"}},
+		{ID: "ts-node@10.8.0", DependsOn: []string{"arg@4.1.3", "tslib@2.4.0"}},
+	},
+}
+
+func Test_packageScopes_of(t *testing.T) {
+	scopes, customResources := splitPackageScopes([]ftypes.CustomResource{
+		{
+			Type:     types.PackageScopeType,
+			FilePath: "app/composer.lock",
+			Data:     types.PackageScope{Dev: []string{"phpunit/phpunit@9.5.20"}},
+		},
+		{
+			Type:     types.PackageScopeType,
+			FilePath: "app/package-lock.json",
+			Data:     types.PackageScope{Direct: []string{"lodash@4.17.21"}},
+		},
+		{
+			Type:     types.PackageManifestType,
+			FilePath: "app/composer.json",
+			Data: types.PackageManifest{
+				Dependencies:    []string{"guzzlehttp/guzzle", "php"},
+				DevDependencies: []string{"phpunit/phpunit"},
+			},
+		},
+		{
+			Type:     types.PackageManifestType,
+			FilePath: "app/package.json",
+			Data: types.PackageManifest{
+				Dependencies:    []string{"lodash-es"},
+				DevDependencies: []string{"ts-node", "typescript"},
+			},
+		},
+		{
+			Type:     "wasm",
+			FilePath: "app/main.wasm",
+		},
+	})
+	assert.Equal(t, []ftypes.CustomResource{{Type: "wasm", FilePath: "app/main.wasm"}}, customResources)
+
+	tests := []struct {
+		name string
+		app  ftypes.Application
+		want dependencyScope
+	}{
+		{
+			name: "yarn.lock with package.json",
+			app:  yarnApp,
+			want: dependencyScope{
+				direct: map[string]bool{"lodash-es@4.17.21": true, "ts-node@10.8.0": true, "typescript@4.7.2": true},
+				dev:    map[string]bool{"arg@4.1.3": true, "ts-node@10.8.0": true, "typescript@4.7.2": true},
+			},
+		},
+		{
+			name: "composer.lock with composer.json",
+			app: ftypes.Application{
+				Type:     ftypes.Composer,
+				FilePath: "app/composer.lock",
+				Libraries: []ftypes.Package{
+					{ID: "guzzlehttp/guzzle@7.4.2", Name: "guzzlehttp/guzzle", Version: "7.4.2"},
+					{ID: "guzzlehttp/psr7@2.2.1", Name: "guzzlehttp/psr7", Version: "2.2.1"},
+					{ID: "phpunit/phpunit@9.5.20", Name: "phpunit/phpunit", Version: "9.5.20"},
+				},
+			},
+			want: dependencyScope{
+				direct: map[string]bool{"guzzlehttp/guzzle@7.4.2": true, "phpunit/phpunit@9.5.20": true},
+				dev:    map[string]bool{"phpunit/phpunit@9.5.20": true},
+			},
+		},
+		{
+			name: "package-lock.json without development dependencies",
+			app: ftypes.Application{
+				Type:     ftypes.Npm,
+				FilePath: "app/package-lock.json",
+				Libraries: []ftypes.Package{
+					{ID: "lodash@4.17.21", Name: "lodash", Version: "4.17.21"},
+				},
+			},
+			want: dependencyScope{
+				direct: map[string]bool{"lodash@4.17.21": true},
+				dev:    map[string]bool{},
+			},
+		},
+		{
+			name: "Gemfile.lock without Gemfile",
+			app: ftypes.Application{
+				Type:     ftypes.Bundler,
+				FilePath: "other/Gemfile.lock",
+				Libraries: []ftypes.Package{
+					{ID: "rack@2.2.3", Name: "rack", Version: "2.2.3"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, scopes.of(tt.app))
+		})
+	}
+}
+
+func Test_packageScopes_excludeDev(t *testing.T) {
+	scopes, _ := splitPackageScopes([]ftypes.CustomResource{
+		{
+			Type:     types.PackageManifestType,
+			FilePath: "app/package.json",
+			Data: types.PackageManifest{
+				Dependencies:    []string{"lodash-es"},
+				DevDependencies: []string{"ts-node", "typescript"},
+			},
+		},
+	})
+	app := yarnApp
+	app.Libraries = append([]ftypes.Package{}, yarnApp.Libraries...)
+
+	got := scopes.excludeDev([]ftypes.Application{app})
+	assert.Equal(t, []ftypes.Application{
+		{
+			Type:     ftypes.Yarn,
+			FilePath: "app/yarn.lock",
+			Libraries: []ftypes.Package{
+				{ID: "lodash-es@4.17.21", Name: "lodash-es", Version: "4.17.21"},
+				{ID: "tslib@2.4.0", Name: "tslib", Version: "2.4.0"},
+			},
+			Dependencies: []godeptypes.Dependency{
+				{ID: "lodash-es@4.17.21", DependsOn: []string{"tslib@2.4.0"}},
+			},
+		},
+	}, got)
+}
+
+func Test_dependencyScope_fillScopes(t *testing.T) {
+	vulns := []types.DetectedVulnerability{
+		{VulnerabilityID: "CVE-2022-0001", PkgName: "arg", InstalledVersion: "4.1.3"},
+		{VulnerabilityID: "CVE-2022-0002", PkgName: "tslib", InstalledVersion: "2.4.0"},
+	}
+	dependencyScope{dev: map[string]bool{"arg@4.1.3": true}}.fillScopes(vulns)
+	assert.Equal(t, types.ScopeDev, vulns[0].PkgScope)
+	assert.Equal(t, types.ScopeProd, vulns[1].PkgScope)
+
+	// The scope is unknown without the lock file or the manifest telling it
+	vulns = []types.DetectedVulnerability{
+		{VulnerabilityID: "CVE-2022-0001", PkgName: "arg", InstalledVersion: "4.1.3"},
+	}
+	dependencyScope{}.fillScopes(vulns)
+	assert.Empty(t, vulns[0].PkgScope)
+}
@@ -2,17 +2,18 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/wire"
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/fanal/artifact"
-	aimage "github.com/aquasecurity/fanal/artifact/image"
-	flocal "github.com/aquasecurity/fanal/artifact/local"
-	"github.com/aquasecurity/fanal/artifact/remote"
 	"github.com/aquasecurity/fanal/image"
 	ftypes "github.com/aquasecurity/fanal/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
 	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/report"
 	"github.com/aquasecurity/trivy/pkg/rpc/client"
 	"github.com/aquasecurity/trivy/pkg/scanner/local"
@@ -32,27 +33,45 @@ var StandaloneSuperSet = wire.NewSet(
 
 // StandaloneDockerSet binds docker dependencies
 var StandaloneDockerSet = wire.NewSet(
-	image.NewDockerImage,
-	aimage.NewArtifact,
+	tartifact.NewDockerImage,
+	tartifact.NewImageArtifact,
 	StandaloneSuperSet,
 )
 
 // StandaloneArchiveSet binds archive scan dependencies
 var StandaloneArchiveSet = wire.NewSet(
 	image.NewArchiveImage,
-	aimage.NewArtifact,
+	tartifact.NewImageArtifact,
+	StandaloneSuperSet,
+)
+
+// StandaloneImageSet binds the dependencies of images opened by Trivy, e.g. in the BuildKit content store
+var StandaloneImageSet = wire.NewSet(
+	tartifact.NewImageArtifact,
+	StandaloneSuperSet,
+)
+
+// StandaloneSBOMSet binds the dependencies of images scanned with the packages listed by their SBOMs
+var StandaloneSBOMSet = wire.NewSet(
+	tartifact.NewSBOMArtifact,
 	StandaloneSuperSet,
 )
 
 // StandaloneFilesystemSet binds filesystem dependencies
 var StandaloneFilesystemSet = wire.NewSet(
-	flocal.NewArtifact,
+	tartifact.NewFilesystemArtifact,
+	StandaloneSuperSet,
+)
+
+// StandaloneSSHSet binds the dependencies of remote filesystems over SSH
+var StandaloneSSHSet = wire.NewSet(
+	tartifact.NewSSHArtifact,
 	StandaloneSuperSet,
 )
 
 // StandaloneRepositorySet binds repository dependencies
 var StandaloneRepositorySet = wire.NewSet(
-	remote.NewArtifact,
+	tartifact.NewRepositoryArtifact,
 	StandaloneSuperSet,
 )
 
@@ -68,26 +87,57 @@ var RemoteSuperSet = wire.NewSet(
 	NewScanner,
 )
 
+// RemoteSBOMSet binds the dependencies of images scanned with the packages listed by their SBOMs for client/server mode
+var RemoteSBOMSet = wire.NewSet(
+	tartifact.NewSBOMArtifact,
+	RemoteSuperSet,
+)
+
 // RemoteFilesystemSet binds filesystem dependencies for client/server mode
 var RemoteFilesystemSet = wire.NewSet(
-	flocal.NewArtifact,
+	tartifact.NewFilesystemArtifact,
+	RemoteSuperSet,
+)
+
+// RemoteSSHSet binds the dependencies of remote filesystems over SSH for client/server mode
+var RemoteSSHSet = wire.NewSet(
+	tartifact.NewSSHArtifact,
 	RemoteSuperSet,
 )
 
 // RemoteDockerSet binds remote docker dependencies
 var RemoteDockerSet = wire.NewSet(
-	aimage.NewArtifact,
-	image.NewDockerImage,
+	tartifact.NewImageArtifact,
+	tartifact.NewDockerImage,
 	RemoteSuperSet,
 )
 
 // RemoteArchiveSet binds remote archive dependencies
 var RemoteArchiveSet = wire.NewSet(
-	aimage.NewArtifact,
+	tartifact.NewImageArtifact,
 	image.NewArchiveImage,
 	RemoteSuperSet,
 )
 
+// RemoteImageSet binds the dependencies of images opened by Trivy for client/server mode,
+// e.g. in the BuildKit content store or pulled through the server
+var RemoteImageSet = wire.NewSet(
+	tartifact.NewImageArtifact,
+	RemoteSuperSet,
+)
+
+// RemoteServerSideImageSet binds the dependencies of images whose layers are analyzed on the server
+var RemoteServerSideImageSet = wire.NewSet(
+	client.NewServerSideArtifact,
+	RemoteSuperSet,
+)
+
+// RemoteServerSidePullSet binds the dependencies of images pulled and analyzed by the server
+var RemoteServerSidePullSet = wire.NewSet(
+	client.NewServerSidePullArtifact,
+	RemoteSuperSet,
+)
+
 // Scanner implements the Artifact and Driver operations
 type Scanner struct {
 	driver   Driver
@@ -107,7 +157,7 @@ func NewScanner(driver Driver, ar artifact.Artifact) Scanner {
 
 // ScanArtifact scans the artifacts and returns results
 func (s Scanner) ScanArtifact(ctx context.Context, options types.ScanOptions) (types.Report, error) {
-	artifactInfo, err := s.artifact.Inspect(ctx)
+	artifactInfo, err := s.inspect(ctx, options.AnalysisTimeout)
 	if err != nil {
 		return types.Report{}, xerrors.Errorf("failed analysis: %w", err)
 	}
@@ -117,10 +167,12 @@ func (s Scanner) ScanArtifact(ctx context.Context, options types.ScanOptions) (t
 		}
 	}()
 
-	results, osFound, err := s.driver.Scan(artifactInfo.Name, artifactInfo.ID, artifactInfo.BlobIDs, options)
+	tracker := progress.Start(progress.PhaseDetection, artifactInfo.Name, 0)
+	results, osFound, err := s.detect(artifactInfo, options)
 	if err != nil {
 		return types.Report{}, xerrors.Errorf("scan failed: %w", err)
 	}
+	tracker.Finish()
 
 	if osFound != nil && osFound.Eosl {
 		log.Logger.Warnf("This OS version is no longer supported by the distribution: %s %s", osFound.Family, osFound.Name)
@@ -130,6 +182,8 @@ func (s Scanner) ScanArtifact(ctx context.Context, options types.ScanOptions) (t
 	// Layer makes sense only when scanning container images
 	if artifactInfo.Type != ftypes.ArtifactContainerImage {
 		removeLayer(results)
+	} else {
+		fillLayerCreatedBy(results, artifactInfo.ImageMetadata)
 	}
 
 	return types.Report{
@@ -148,6 +202,49 @@ func (s Scanner) ScanArtifact(ctx context.Context, options types.ScanOptions) (t
 	}, nil
 }
 
+// inspect analyzes the artifact within the timeout of the analysis phase
+func (s Scanner) inspect(ctx context.Context, timeout time.Duration) (ftypes.ArtifactReference, error) {
+	if timeout <= 0 {
+		return s.artifact.Inspect(ctx)
+	}
+	analysisCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	artifactInfo, err := s.artifact.Inspect(analysisCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(analysisCtx.Err(), context.DeadlineExceeded) {
+		return ftypes.ArtifactReference{}, xerrors.Errorf("the analysis didn't finish within %s: %w", timeout, err)
+	}
+	return artifactInfo, err
+}
+
+// detect detects the vulnerabilities within the timeout of the detection phase.
+// The drivers can't be canceled, so the detection is given up without waiting for it after the timeout.
+func (s Scanner) detect(artifactInfo ftypes.ArtifactReference, options types.ScanOptions) (types.Results, *ftypes.OS, error) {
+	if options.DetectionTimeout <= 0 {
+		return s.driver.Scan(artifactInfo.Name, artifactInfo.ID, artifactInfo.BlobIDs, options)
+	}
+
+	type detected struct {
+		results types.Results
+		osFound *ftypes.OS
+		err     error
+	}
+	done := make(chan detected, 1)
+	go func() {
+		results, osFound, err := s.driver.Scan(artifactInfo.Name, artifactInfo.ID, artifactInfo.BlobIDs, options)
+		done <- detected{results: results, osFound: osFound, err: err}
+	}()
+
+	timer := time.NewTimer(options.DetectionTimeout)
+	defer timer.Stop()
+	select {
+	case d := <-done:
+		return d.results, d.osFound, d.err
+	case <-timer.C:
+		return nil, nil, xerrors.Errorf("the detection didn't finish within %s", options.DetectionTimeout)
+	}
+}
+
 func removeLayer(results types.Results) {
 	for i := range results {
 		result := results[i]
@@ -163,3 +260,28 @@ func removeLayer(results types.Results) {
 		}
 	}
 }
+
+// fillLayerCreatedBy fills the instructions of the image history which created the layers introducing the vulnerabilities.
+// The history is not used unless its entries creating layers match the layers of the image, e.g. squashed images.
+func fillLayerCreatedBy(results types.Results, metadata ftypes.ImageMetadata) {
+	var createdBy []string
+	for _, h := range metadata.ConfigFile.History {
+		if !h.EmptyLayer {
+			createdBy = append(createdBy, h.CreatedBy)
+		}
+	}
+	if len(createdBy) == 0 || len(createdBy) != len(metadata.DiffIDs) {
+		return
+	}
+
+	layers := map[string]string{}
+	for i, diffID := range metadata.DiffIDs {
+		layers[diffID] = createdBy[i]
+	}
+	for i := range results {
+		for j := range results[i].Vulnerabilities {
+			vuln := &results[i].Vulnerabilities[j]
+			vuln.LayerCreatedBy = layers[vuln.Layer.DiffID]
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -207,3 +209,129 @@ func TestScanner_ScanArtifact(t *testing.T) {
 		})
 	}
 }
+
+// stuckArtifact and stuckDriver don't finish until the context is canceled or the test ends
+type stuckArtifact struct {
+	done <-chan struct{}
+}
+
+func (a stuckArtifact) Inspect(ctx context.Context) (ftypes.ArtifactReference, error) {
+	select {
+	case <-ctx.Done():
+		return ftypes.ArtifactReference{}, ctx.Err()
+	case <-a.done:
+		return ftypes.ArtifactReference{Name: "alpine:3.11"}, nil
+	}
+}
+
+func (stuckArtifact) Clean(ftypes.ArtifactReference) error { return nil }
+
+type stuckDriver struct {
+	done <-chan struct{}
+}
+
+func (d stuckDriver) Scan(string, string, []string, types.ScanOptions) (types.Results, *ftypes.OS, error) {
+	<-d.done
+	return nil, nil, nil
+}
+
+func TestScanner_ScanArtifact_timeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     types.ScanOptions
+		stuckDetect bool
+		wantErr     string
+	}{
+		{
+			name:    "analysis timeout",
+			options: types.ScanOptions{AnalysisTimeout: 10 * time.Millisecond},
+			wantErr: "the analysis didn't finish within 10ms",
+		},
+		{
+			name:        "detection timeout",
+			options:     types.ScanOptions{DetectionTimeout: 10 * time.Millisecond},
+			stuckDetect: true,
+			wantErr:     "the detection didn't finish within 10ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			defer close(done)
+
+			var art artifact.Artifact = stuckArtifact{done: done}
+			if tt.stuckDetect {
+				finished := make(chan struct{})
+				close(finished)
+				art = stuckArtifact{done: finished}
+			}
+
+			s := NewScanner(stuckDriver{done: done}, art)
+			_, err := s.ScanArtifact(context.Background(), tt.options)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func Test_fillLayerCreatedBy(t *testing.T) {
+	history := []v1.History{
+		{CreatedBy: "/bin/sh -c #(nop) ADD file:5d673d25da3a14ce1f6cf66e4c7fd4f4b85a3759a9d93efb3fd9ff852b5b56e4 in / "},
+		{CreatedBy: `/bin/sh -c #(nop)  CMD ["/bin/sh"]`, EmptyLayer: true},
+		{CreatedBy: "RUN /bin/sh -c apk add --no-cache curl # buildkit"},
+	}
+	tests := []struct {
+		name     string
+		metadata ftypes.ImageMetadata
+		want     []string
+	}{
+		{
+			name: "happy path",
+			metadata: ftypes.ImageMetadata{
+				DiffIDs:    []string{"sha256:base", "sha256:curl"},
+				ConfigFile: v1.ConfigFile{History: history},
+			},
+			want: []string{
+				"/bin/sh -c #(nop) ADD file:5d673d25da3a14ce1f6cf66e4c7fd4f4b85a3759a9d93efb3fd9ff852b5b56e4 in / ",
+				"RUN /bin/sh -c apk add --no-cache curl # buildkit",
+				"",
+			},
+		},
+		{
+			name: "squashed image",
+			metadata: ftypes.ImageMetadata{
+				DiffIDs:    []string{"sha256:curl"},
+				ConfigFile: v1.ConfigFile{History: history},
+			},
+			want: []string{"", "", ""},
+		},
+		{
+			name: "no history",
+			metadata: ftypes.ImageMetadata{
+				DiffIDs: []string{"sha256:base", "sha256:curl"},
+			},
+			want: []string{"", "", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := types.Results{
+				{
+					Target: "alpine:3.15",
+					Vulnerabilities: []types.DetectedVulnerability{
+						{VulnerabilityID: "CVE-2022-0001", Layer: ftypes.Layer{DiffID: "sha256:base"}},
+						{VulnerabilityID: "CVE-2022-0002", Layer: ftypes.Layer{DiffID: "sha256:curl"}},
+						{VulnerabilityID: "CVE-2022-0003"},
+					},
+				},
+			}
+			fillLayerCreatedBy(results, tt.metadata)
+
+			var got []string
+			for _, v := range results[0].Vulnerabilities {
+				got = append(got, v.LayerCreatedBy)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
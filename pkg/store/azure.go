@@ -0,0 +1,58 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+	"golang.org/x/xerrors"
+)
+
+// azureUpload writes the objects to Azure Blob Storage.
+// The account is given by AZURE_STORAGE_CONNECTION_STRING, or AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY,
+// in the same way as the Azure CLI.
+func azureUpload(ctx context.Context, container string, _ url.Values, objects []object) error {
+	client, err := azureClient()
+	if err != nil {
+		return err
+	}
+
+	blobService := client.GetBlobService()
+	c := blobService.GetContainerReference(container)
+	for _, obj := range objects {
+		// The client doesn't take the context
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		b := c.GetBlobReference(obj.Key)
+		b.Properties.ContentType = obj.ContentType
+		b.Metadata = obj.Metadata
+		if err = b.CreateBlockBlobFromReader(bytes.NewReader(obj.Body), nil); err != nil {
+			return xerrors.Errorf("unable to put %s: %w", obj.Key, err)
+		}
+	}
+	return nil
+}
+
+func azureClient() (storage.Client, error) {
+	if s := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); s != "" {
+		client, err := storage.NewClientFromConnectionString(s)
+		if err != nil {
+			return storage.Client{}, xerrors.Errorf("invalid AZURE_STORAGE_CONNECTION_STRING: %w", err)
+		}
+		return client, nil
+	}
+
+	account, key := os.Getenv("AZURE_STORAGE_ACCOUNT"), os.Getenv("AZURE_STORAGE_KEY")
+	if account == "" || key == "" {
+		return storage.Client{}, xerrors.New("AZURE_STORAGE_CONNECTION_STRING, or AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY must be set")
+	}
+	client, err := storage.NewBasicClient(account, key)
+	if err != nil {
+		return storage.Client{}, xerrors.Errorf("azure storage client error: %w", err)
+	}
+	return client, nil
+}
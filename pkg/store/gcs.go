@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"net/url"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/xerrors"
+)
+
+// gcsUpload writes the objects to Google Cloud Storage with the application default credentials.
+// STORAGE_EMULATOR_HOST is respected for testing.
+func gcsUpload(ctx context.Context, bucket string, _ url.Values, objects []object) error {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return xerrors.Errorf("GCS client error: %w", err)
+	}
+	defer client.Close()
+
+	for _, obj := range objects {
+		w := client.Bucket(bucket).Object(obj.Key).NewWriter(ctx)
+		w.ContentType = obj.ContentType
+		w.Metadata = obj.Metadata
+		if _, err = w.Write(obj.Body); err != nil {
+			_ = w.Close()
+			return xerrors.Errorf("unable to write %s: %w", obj.Key, err)
+		}
+		if err = w.Close(); err != nil {
+			return xerrors.Errorf("unable to write %s: %w", obj.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"golang.org/x/xerrors"
+)
+
+// s3Upload writes the objects to Amazon S3 with the default credential chain.
+// "region" and "endpoint" in the query are used for S3 compatible storage, e.g. MinIO.
+func s3Upload(ctx context.Context, bucket string, query url.Values, objects []object) error {
+	cfg := aws.NewConfig()
+	if region := query.Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return xerrors.Errorf("aws session error: %w", err)
+	}
+
+	client := s3.New(sess)
+	for _, obj := range objects {
+		_, err = client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(obj.Key),
+			Body:        bytes.NewReader(obj.Body),
+			ContentType: aws.String(obj.ContentType),
+			Metadata:    aws.StringMap(obj.Metadata),
+		})
+		if err != nil {
+			return xerrors.Errorf("unable to put %s: %w", obj.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_s3Upload(t *testing.T) {
+	type request struct {
+		Path        string
+		Body        string
+		ContentType string
+		Metadata    string
+	}
+	var got []request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		got = append(got, request{
+			Path:        r.URL.Path,
+			Body:        string(b),
+			ContentType: r.Header.Get("Content-Type"),
+			Metadata:    r.Header.Get("X-Amz-Meta-Artifact_name"),
+		})
+	}))
+	defer ts.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	query := url.Values{"endpoint": {ts.URL}, "region": {"us-east-1"}}
+	err := s3Upload(context.Background(), "reports", query, []object{
+		{
+			Key:         "trivy/sha256:digest/report.json",
+			Body:        []byte(`{}`),
+			ContentType: "application/json",
+			Metadata:    map[string]string{"artifact_name": "alpine:3.15"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []request{
+		{
+			Path:        "/reports/trivy/sha256:digest/report.json",
+			Body:        `{}`,
+			ContentType: "application/json",
+			Metadata:    "alpine:3.15",
+		},
+	}, got)
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/report/cyclonedx"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// uploadTimeout is the timeout to write the objects of a report
+const uploadTimeout = 5 * time.Minute
+
+// object is written to the object storage
+type object struct {
+	Key         string
+	Body        []byte
+	ContentType string
+	Metadata    map[string]string
+}
+
+// uploader writes the objects to the bucket.
+// The query of the URL configures the client, e.g. the endpoint of S3 compatible storage.
+type uploader func(ctx context.Context, bucket string, query url.Values, objects []object) error
+
+var uploaders = map[string]uploader{
+	"s3":     s3Upload,
+	"gs":     gcsUpload,
+	"azblob": azureUpload,
+}
+
+// Validate returns an error if the URL is not supported, so that it is reported before scanning
+func Validate(storeURL string) error {
+	_, err := parse(storeURL)
+	return err
+}
+
+// Write writes the JSON report, and the CycloneDX SBOM if packages are listed, to the object storage.
+//
+//	s3://bucket/prefix        => Amazon S3
+//	gs://bucket/prefix        => Google Cloud Storage
+//	azblob://container/prefix => Azure Blob Storage
+//
+// The objects are keyed by the digest of the artifact and the scan time,
+// e.g. "prefix/sha256:1234.../20220601T000000Z/report.json",
+// and the metadata holds the artifact and the number of findings.
+// The URL of the JSON report is returned, e.g. to be linked from notifications.
+func Write(ctx context.Context, storeURL string, report types.Report, appVersion string, now time.Time) (string, error) {
+	u, err := parse(storeURL)
+	if err != nil {
+		return "", err
+	}
+
+	objects, err := newObjects(report, appVersion, strings.Trim(u.Path, "/"), now)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+
+	log.Logger.Infof("Storing the report in %s://%s/%s", u.Scheme, u.Host, path.Dir(objects[0].Key))
+	if err = uploaders[u.Scheme](ctx, u.Host, u.Query(), objects); err != nil {
+		return "", xerrors.Errorf("unable to write the report to %s://%s: %w", u.Scheme, u.Host, err)
+	}
+	return fmt.Sprintf("%s://%s/%s", u.Scheme, u.Host, objects[0].Key), nil
+}
+
+func parse(storeURL string) (*url.URL, error) {
+	u, err := url.Parse(storeURL)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid store URL: %w", err)
+	}
+	if _, ok := uploaders[u.Scheme]; !ok {
+		return nil, xerrors.Errorf("unsupported store URL (%s), use s3://, gs:// or azblob://", storeURL)
+	} else if u.Host == "" {
+		return nil, xerrors.Errorf("the bucket must be specified in the store URL (%s)", storeURL)
+	}
+	return u, nil
+}
+
+func newObjects(report types.Report, appVersion, prefix string, now time.Time) ([]object, error) {
+	dir := path.Join(prefix, artifactDigest(report), now.UTC().Format("20060102T150405Z"))
+	metadata := newMetadata(report, appVersion, now)
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal json: %w", err)
+	}
+	objects := []object{
+		{
+			Key:         path.Join(dir, "report.json"),
+			Body:        b,
+			ContentType: "application/json",
+			Metadata:    metadata,
+		},
+	}
+
+	// The SBOM is empty without packages, e.g. '--list-all-pkgs' is not specified
+	if !hasPackages(report) {
+		return objects, nil
+	}
+	var buf bytes.Buffer
+	if err = cyclonedx.NewWriter(&buf, appVersion).Write(report); err != nil {
+		return nil, xerrors.Errorf("CycloneDX error: %w", err)
+	}
+	return append(objects, object{
+		Key:         path.Join(dir, "sbom.cdx.json"),
+		Body:        buf.Bytes(),
+		ContentType: "application/vnd.cyclonedx+json",
+		Metadata:    metadata,
+	}), nil
+}
+
+// artifactDigest returns the digest of the image, or the digest of the name for other artifacts
+func artifactDigest(report types.Report) string {
+	for _, d := range report.Metadata.RepoDigests {
+		if _, digest, ok := strings.Cut(d, "@"); ok {
+			return digest
+		}
+	}
+	if report.Metadata.ImageID != "" {
+		return report.Metadata.ImageID
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(report.ArtifactName)))
+}
+
+// newMetadata returns the metadata of the objects.
+// The keys consist of lowercase letters and underscores, which are allowed by all the object storage.
+func newMetadata(report types.Report, appVersion string, now time.Time) map[string]string {
+	metadata := map[string]string{
+		"artifact_name": report.ArtifactName,
+		"artifact_type": string(report.ArtifactType),
+		"created_at":    now.UTC().Format(time.RFC3339),
+		"trivy_version": appVersion,
+	}
+
+	var misconfs, secrets int
+	vulns := map[string]int{}
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			vulns[v.Severity]++
+		}
+		if r.MisconfSummary != nil {
+			misconfs += r.MisconfSummary.Failures
+		}
+		secrets += len(r.Secrets)
+	}
+	for _, s := range dbTypes.SeverityNames {
+		metadata["vulnerabilities_"+strings.ToLower(s)] = strconv.Itoa(vulns[s])
+	}
+	metadata["misconfigurations"] = strconv.Itoa(misconfs)
+	metadata["secrets"] = strconv.Itoa(secrets)
+	return metadata
+}
+
+func hasPackages(report types.Report) bool {
+	for _, r := range report.Results {
+		if len(r.Packages) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func TestWrite(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		storeURL     string
+		report       types.Report
+		wantBucket   string
+		wantKeys     []string
+		wantURL      string
+		wantMetadata map[string]string
+		wantErr      string
+	}{
+		{
+			name:     "image with packages",
+			storeURL: "s3://reports/trivy/",
+			report: types.Report{
+				ArtifactName: "alpine:3.15",
+				ArtifactType: ftypes.ArtifactContainerImage,
+				Metadata: types.Metadata{
+					OS:          &ftypes.OS{Family: "alpine", Name: "3.15.0"},
+					ImageID:     "sha256:6105d6cc76af400325e94d588ce511be5bfdbb73b437dc51eca43917d7a43e3d",
+					RepoDigests: []string{"alpine@sha256:071ca2227754705837aa3ef9748ed59e9f8a015fd765c42f391a4cbc271c6d5e"},
+				},
+				Results: types.Results{
+					{
+						Target:   "alpine:3.15 (alpine 3.15.0)",
+						Class:    types.ClassOSPkg,
+						Type:     "alpine",
+						Packages: []types.Package{{Package: ftypes.Package{Name: "musl", Version: "1.2.2-r7"}}},
+						Vulnerabilities: []types.DetectedVulnerability{
+							{VulnerabilityID: "CVE-2022-0001", Vulnerability: dbTypes.Vulnerability{Severity: "CRITICAL"}},
+							{VulnerabilityID: "CVE-2022-0002", Vulnerability: dbTypes.Vulnerability{Severity: "HIGH"}},
+						},
+					},
+					{
+						Target:         "Dockerfile",
+						Class:          types.ClassConfig,
+						MisconfSummary: &types.MisconfSummary{Successes: 3, Failures: 2},
+					},
+				},
+			},
+			wantBucket: "reports",
+			wantKeys: []string{
+				"trivy/sha256:071ca2227754705837aa3ef9748ed59e9f8a015fd765c42f391a4cbc271c6d5e/20220601T123000Z/report.json",
+				"trivy/sha256:071ca2227754705837aa3ef9748ed59e9f8a015fd765c42f391a4cbc271c6d5e/20220601T123000Z/sbom.cdx.json",
+			},
+			wantURL: "s3://reports/trivy/sha256:071ca2227754705837aa3ef9748ed59e9f8a015fd765c42f391a4cbc271c6d5e/20220601T123000Z/report.json",
+			wantMetadata: map[string]string{
+				"artifact_name":            "alpine:3.15",
+				"artifact_type":            "container_image",
+				"created_at":               "2022-06-01T12:30:00Z",
+				"trivy_version":            "dev",
+				"vulnerabilities_critical": "1",
+				"vulnerabilities_high":     "1",
+				"vulnerabilities_medium":   "0",
+				"vulnerabilities_low":      "0",
+				"vulnerabilities_unknown":  "0",
+				"misconfigurations":        "2",
+				"secrets":                  "0",
+			},
+		},
+		{
+			name:     "filesystem without packages",
+			storeURL: "s3://reports",
+			report: types.Report{
+				ArtifactName: "/app",
+				ArtifactType: ftypes.ArtifactFilesystem,
+			},
+			wantBucket: "reports",
+			wantKeys: []string{
+				// sha256 of "/app"
+				"sha256:f53b52ad6d21cceb72dfa78fb67614fe14f110c58e68412b01508d6a485501c3/20220601T123000Z/report.json",
+			},
+			wantURL: "s3://reports/sha256:f53b52ad6d21cceb72dfa78fb67614fe14f110c58e68412b01508d6a485501c3/20220601T123000Z/report.json",
+		},
+		{
+			name:     "sad path: unsupported scheme",
+			storeURL: "ftp://reports",
+			wantErr:  "unsupported store URL",
+		},
+		{
+			name:     "sad path: no bucket",
+			storeURL: "gs:///trivy",
+			wantErr:  "the bucket must be specified",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBucket string
+			var gotObjects []object
+			uploaders["s3"] = func(_ context.Context, bucket string, _ url.Values, objects []object) error {
+				gotBucket = bucket
+				gotObjects = objects
+				return nil
+			}
+			defer func() { uploaders["s3"] = s3Upload }()
+
+			gotURL, err := Write(context.Background(), tt.storeURL, tt.report, "dev", now)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBucket, gotBucket)
+
+			var gotKeys []string
+			for _, obj := range gotObjects {
+				gotKeys = append(gotKeys, obj.Key)
+			}
+			assert.Equal(t, tt.wantKeys, gotKeys)
+			assert.Equal(t, tt.wantURL, gotURL)
+			if tt.wantMetadata != nil {
+				assert.Equal(t, tt.wantMetadata, gotObjects[0].Metadata)
+			}
+
+			var got types.Report
+			require.NoError(t, json.Unmarshal(gotObjects[0].Body, &got))
+			assert.Equal(t, tt.report.ArtifactName, got.ArtifactName)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/credential"
 )
 
 // DockerConfig holds the config of Docker
@@ -15,18 +16,24 @@ type DockerConfig struct {
 	NonSSL        bool   `env:"TRIVY_NON_SSL" envDefault:"false"`
 }
 
-// GetDockerOption returns the Docker scanning options using DockerConfig
-func GetDockerOption(insecureTlsSkip bool) (types.DockerOption, error) {
+// GetDockerOption returns the Docker scanning options using DockerConfig.
+// '--insecure' is not passed to fanal as it ignores the proxy settings then,
+// and it is applied to the default transport of go-containerregistry instead.
+func GetDockerOption() (types.DockerOption, error) {
 	cfg := DockerConfig{}
 	if err := env.Parse(&cfg); err != nil {
 		return types.DockerOption{}, xerrors.Errorf("unable to parse environment variables: %w", err)
 	}
 
+	// The credentials can reference a secret manager, e.g. TRIVY_PASSWORD=vault://secret/data/registry#password
+	if err := credential.ResolveAll(&cfg.UserName, &cfg.Password, &cfg.RegistryToken); err != nil {
+		return types.DockerOption{}, xerrors.Errorf("registry credential error: %w", err)
+	}
+
 	return types.DockerOption{
-		UserName:              cfg.UserName,
-		Password:              cfg.Password,
-		RegistryToken:         cfg.RegistryToken,
-		InsecureSkipTLSVerify: insecureTlsSkip,
-		NonSSL:                cfg.NonSSL,
+		UserName:      cfg.UserName,
+		Password:      cfg.Password,
+		RegistryToken: cfg.RegistryToken,
+		NonSSL:        cfg.NonSSL,
 	}, nil
 }
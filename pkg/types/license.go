@@ -0,0 +1,12 @@
+package types
+
+// DetectedLicense holds the license of a package detected with '--security-checks license'
+type DetectedLicense struct {
+	// Severity and Category are determined by the license policy, e.g. CRITICAL for the forbidden licenses
+	Severity string
+	Category string `json:",omitempty"`
+
+	PkgName  string
+	FilePath string `json:",omitempty"` // It will be filled in the case of language-specific packages such as JAR files
+	Name     string
+}
@@ -19,6 +19,9 @@ type DetectedMisconfiguration struct {
 	Layer         ftypes.Layer         `json:",omitempty"`
 	CauseMetadata ftypes.CauseMetadata `json:",omitempty"`
 
+	// Tracking holds when the misconfiguration was first and last seen in the target with --history-dir
+	Tracking *Tracking `json:",omitempty"`
+
 	// For debugging
 	Traces []string `json:",omitempty"`
 }
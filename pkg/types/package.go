@@ -0,0 +1,93 @@
+package types
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+// PackageDigestType is the type of the custom resources recording the digests of the packages.
+// For the package databases, e.g. "lib/apk/db/installed", Data maps the package names to the digests.
+// For the package files, e.g. JAR files, Data is the digest of the file.
+const PackageDigestType = "trivy:package-digest"
+
+// PackageHashType is the type of the custom resources recording the hashes of the package files
+// calculated with '--sbom-hashes'. Data is the list of the hashes, e.g. ["sha256:<hex>", "sha1:<hex>"].
+const PackageHashType = "trivy:package-hash"
+
+// PackageScopeType is the type of the custom resources recording how the application depends on the packages
+// where the lock file tells. FilePath is the path of the application, and Data is PackageScope.
+const PackageScopeType = "trivy:package-scope"
+
+// PackageManifestType is the type of the custom resources recording the dependencies of the project declared in
+// the manifest, e.g. package.json. FilePath is the path of the manifest, and Data is PackageManifest.
+const PackageManifestType = "trivy:package-manifest"
+
+// PackageScope holds the IDs of the packages, e.g. "lodash@4.17.21", which the application depends on directly
+// and which only the development needs. Direct is empty when the lock file doesn't list the direct dependencies.
+type PackageScope struct {
+	Direct []string `json:",omitempty"`
+	Dev    []string `json:",omitempty"`
+}
+
+// PackageManifest holds the names of the production and the development dependencies declared in the manifest
+type PackageManifest struct {
+	Dependencies    []string `json:",omitempty"`
+	DevDependencies []string `json:",omitempty"`
+}
+
+// HashAlgorithms are the algorithms of the hashes which can be selected with '--sbom-hashes'
+var HashAlgorithms = []string{"md5", "sha1", "sha256", "sha512"}
+
+// Package is a package listed with '--list-all-pkgs'
+type Package struct {
+	ftypes.Package
+
+	// Digest is the checksum of the package, e.g. "sha1:1b6a55f8e8d9b0a4e0c3a4a1ad6fe24b6757b648".
+	// It is given by the package manager or calculated from the package file.
+	Digest string `json:",omitempty"`
+
+	// Hashes are the hashes of the package file in the algorithms selected with '--sbom-hashes'
+	Hashes []string `json:",omitempty"`
+
+	// Dev is true if only the development of the application needs the package
+	Dev bool `json:",omitempty"`
+}
+
+// Checksums returns the digest and the hashes of the package in the algorithms, or in any algorithm if none is given.
+// A checksum is returned once per algorithm, preferring the digest.
+func (p Package) Checksums(algorithms []string) []string {
+	return filterChecksums(append([]string{p.Digest}, p.Hashes...), algorithms)
+}
+
+// Checksums returns the hashes of the target file in the algorithms, or in any algorithm if none is given
+func (r Result) Checksums(algorithms []string) []string {
+	return filterChecksums(r.Hashes, algorithms)
+}
+
+func filterChecksums(candidates, algorithms []string) []string {
+	var checksums, seen []string
+	for _, checksum := range candidates {
+		alg, _, ok := strings.Cut(checksum, ":")
+		if !ok || slices.Contains(seen, alg) || (len(algorithms) > 0 && !slices.Contains(algorithms, alg)) {
+			continue
+		}
+		seen = append(seen, alg)
+		checksums = append(checksums, checksum)
+	}
+	return checksums
+}
+
+// NewPackages returns the packages without the digests
+func NewPackages(pkgs []ftypes.Package) []Package {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	packages := make([]Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		packages = append(packages, Package{Package: pkg})
+	}
+	return packages
+}
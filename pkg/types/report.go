@@ -2,14 +2,17 @@ package types
 
 import (
 	"encoding/json"
+	"time"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1" // nolint: goimports
 
 	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
 )
 
 // Report represents a scan result
 type Report struct {
+	// SchemaVersion is the version of the JSON schema of the report, see pkg/report for the compatibility rules
 	SchemaVersion int                 `json:",omitempty"`
 	ArtifactName  string              `json:",omitempty"`
 	ArtifactType  ftypes.ArtifactType `json:",omitempty"`
@@ -28,6 +31,38 @@ type Metadata struct {
 	RepoTags    []string      `json:",omitempty"`
 	RepoDigests []string      `json:",omitempty"`
 	ImageConfig v1.ConfigFile `json:",omitempty"`
+
+	// ImageReference is the canonical reference of the image given to Trivy,
+	// and ImageDigest is the reference pinned to the digest of the scanned image.
+	ImageReference string `json:",omitempty"`
+	ImageDigest    string `json:",omitempty"`
+
+	// RebuildOf is the original image which the container image was rebuilt from
+	RebuildOf string `json:",omitempty"`
+
+	// Scan is the configuration of the scan and the versions of Trivy and the DB,
+	// so that the result can be reproduced and audited
+	Scan *ScanMetadata `json:",omitempty"`
+}
+
+// ScanMetadata represents the options and the versions used for the scan
+type ScanMetadata struct {
+	TrivyVersion   string      `json:",omitempty"`
+	DB             *DBMetadata `json:",omitempty"`
+	Severities     []string    `json:",omitempty"`
+	SecurityChecks []string    `json:",omitempty"`
+	VulnType       []string    `json:",omitempty"`
+	IgnoreUnfixed  bool        `json:",omitempty"`
+	SkipFiles      []string    `json:",omitempty"`
+	SkipDirs       []string    `json:",omitempty"`
+}
+
+// DBMetadata represents the vulnerability DB used for the scan.
+// Repository is empty in client/server mode, as the DB is downloaded by the server.
+type DBMetadata struct {
+	Repository string    `json:",omitempty"`
+	Version    int       `json:",omitempty"`
+	UpdatedAt  time.Time `json:",omitempty"`
 }
 
 // Results to hold list of Result
@@ -40,6 +75,8 @@ const (
 	ClassLangPkg = "lang-pkgs"
 	ClassConfig  = "config"
 	ClassSecret  = "secret"
+	ClassLicense = "license"
+	ClassCustom  = "custom"
 )
 
 // Result holds a target and detected vulnerabilities
@@ -47,14 +84,29 @@ type Result struct {
 	Target            string                     `json:"Target"`
 	Class             ResultClass                `json:"Class,omitempty"`
 	Type              string                     `json:"Type,omitempty"`
-	Packages          []ftypes.Package           `json:"Packages,omitempty"`
+	Packages          []Package                  `json:"Packages,omitempty"`
 	Vulnerabilities   []DetectedVulnerability    `json:"Vulnerabilities,omitempty"`
 	MisconfSummary    *MisconfSummary            `json:"MisconfSummary,omitempty"`
 	Misconfigurations []DetectedMisconfiguration `json:"Misconfigurations,omitempty"`
 	Secrets           []ftypes.SecretFinding     `json:"Secrets,omitempty"`
+	Licenses          []DetectedLicense          `json:"Licenses,omitempty"`
 	CustomResources   []ftypes.CustomResource    `json:"CustomResources,omitempty"`
+
+	// Hashes are the hashes of the target file, e.g. a Go binary, in the algorithms selected with '--sbom-hashes'
+	Hashes []string `json:"Hashes,omitempty"`
+
+	// NotScanned holds the reason why the target was not scanned, e.g. the scan budget was exhausted
+	NotScanned string `json:"NotScanned,omitempty"`
+
+	// IgnoredVulnerabilities are the vulnerabilities suppressed by the filtering, which are not reported but written
+	// to the VEX document with --vex-output
+	IgnoredVulnerabilities []IgnoredVulnerability `json:"-"`
 }
 
+// SkippedFileType is the type of the custom resources recording the files skipped during the analysis,
+// e.g. with '--file-timeout'. The files are reported as not scanned, and Data has the reason.
+const SkippedFileType = "trivy:skipped-file"
+
 func (r *Result) MarshalJSON() ([]byte, error) {
 	// VendorSeverity includes all vendor severities.
 	// It would be noisy to users, so it should be removed from the JSON output.
@@ -88,17 +140,48 @@ func (s MisconfSummary) Empty() bool {
 	return s.Successes == 0 && s.Failures == 0 && s.Exceptions == 0
 }
 
+// FailCondition represents the findings which make the scan fail
+type FailCondition struct {
+	// Severity is the lowest severity to be counted
+	Severity dbTypes.Severity
+
+	// FixedOnly ignores vulnerabilities without a fixed version
+	FixedOnly bool
+}
+
 // Failed returns whether the result includes any vulnerabilities or misconfigurations
 func (results Results) Failed() bool {
+	return results.FailedBy(FailCondition{})
+}
+
+// FailedBy returns whether the result includes any vulnerabilities, misconfigurations or licenses
+// satisfying the given condition
+func (results Results) FailedBy(cond FailCondition) bool {
 	for _, r := range results {
-		if len(r.Vulnerabilities) > 0 {
-			return true
+		for _, v := range r.Vulnerabilities {
+			if cond.FixedOnly && v.FixedVersion == "" {
+				continue
+			}
+			if exceedsSeverity(v.Severity, cond.Severity) {
+				return true
+			}
 		}
 		for _, m := range r.Misconfigurations {
-			if m.Status == StatusFailure {
+			if m.Status == StatusFailure && exceedsSeverity(m.Severity, cond.Severity) {
+				return true
+			}
+		}
+		for _, l := range r.Licenses {
+			if exceedsSeverity(l.Severity, cond.Severity) {
 				return true
 			}
 		}
 	}
 	return false
 }
+
+func exceedsSeverity(severity string, threshold dbTypes.Severity) bool {
+	// An invalid or empty severity is handled as UNKNOWN
+	s, _ := dbTypes.NewSeverity(severity) // nolint: errcheck
+	return s >= threshold
+}
@@ -1,9 +1,38 @@
 package types
 
+import "time"
+
 // ScanOptions holds the attributes for scanning vulnerabilities
 type ScanOptions struct {
 	VulnType            []string
 	SecurityChecks      []string
 	ScanRemovedPackages bool
 	ListAllPackages     bool
+	ESM                 bool
+
+	// DependencyTree fills the chains of the dependencies pulling in the vulnerable packages
+	DependencyTree bool
+
+	// ExcludeDevDeps drops the packages only the development of the applications needs, where the projects tell them
+	ExcludeDevDeps bool
+
+	// OSV detects the vulnerabilities of the ecosystems which the DB doesn't cover
+	OSV OSVOption
+
+	// Locale is the language preferred for the titles and the descriptions of the vulnerabilities, English if empty
+	Locale string
+
+	// AnalysisTimeout and DetectionTimeout limit the phases of the scan, 0 means no limit but the context
+	AnalysisTimeout  time.Duration
+	DetectionTimeout time.Duration
+}
+
+// OSVOption holds the options to query OSV.dev
+type OSVOption struct {
+	Enabled bool
+	URL     string
+
+	// CacheDir holds the responses, which are the only source with Offline
+	CacheDir string
+	Offline  bool
 }
@@ -31,11 +31,14 @@ const (
 
 	// SecurityCheckSecret is a security check of secrets
 	SecurityCheckSecret = SecurityCheck("secret")
+
+	// SecurityCheckLicense is a security check of licenses
+	SecurityCheckLicense = SecurityCheck("license")
 )
 
 var (
 	vulnTypes      = []string{VulnTypeOS, VulnTypeLibrary}
-	securityChecks = []string{SecurityCheckVulnerability, SecurityCheckConfig, SecurityCheckSecret}
+	securityChecks = []string{SecurityCheckVulnerability, SecurityCheckConfig, SecurityCheckSecret, SecurityCheckLicense}
 )
 
 // NewVulnType returns an instance of VulnType
@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+// Tracking holds when the finding was first and last seen across the scans of the same target
+type Tracking struct {
+	// Fingerprint identifies the finding across the scans
+	Fingerprint string
+	FirstSeen   time.Time
+	LastSeen    time.Time
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	ftypes "github.com/aquasecurity/fanal/types"
 	"github.com/aquasecurity/trivy-db/pkg/types"
 )
@@ -11,15 +13,34 @@ type DetectedVulnerability struct {
 	VendorIDs        []string       `json:",omitempty"`
 	PkgName          string         `json:",omitempty"`
 	PkgPath          string         `json:",omitempty"` // It will be filled in the case of language-specific packages such as egg/wheel and gemspec
+	PkgRelationship  string         `json:",omitempty"` // "direct" or "indirect", filled only when the project tells
+	PkgScope         string         `json:",omitempty"` // "dev" or "prod", filled only when the project tells
 	InstalledVersion string         `json:",omitempty"`
 	FixedVersion     string         `json:",omitempty"`
 	Layer            ftypes.Layer   `json:",omitempty"`
 	SeveritySource   types.SourceID `json:",omitempty"`
 	PrimaryURL       string         `json:",omitempty"`
 
+	// Unpinned is true if InstalledVersion is the range in the manifest without a lock file with --detect-unpinned,
+	// and any version satisfying it is vulnerable
+	Unpinned bool `json:",omitempty"`
+
+	// LayerCreatedBy is the instruction in the image history which created the layer, e.g. "/bin/sh -c apk add curl"
+	LayerCreatedBy string `json:",omitempty"`
+
 	// DataSource holds where the advisory comes from
 	DataSource *types.DataSource `json:",omitempty"`
 
+	// SLA holds the due date of the fix when the SLA is configured
+	SLA *SLAStatus `json:",omitempty"`
+
+	// Tracking holds when the vulnerability was first and last seen in the target with --history-dir
+	Tracking *Tracking `json:",omitempty"`
+
+	// DependencyPaths holds the chains of the dependencies from the direct dependencies to the package
+	// with --dependency-tree, e.g. [["express@4.17.1", "body-parser@1.19.0", "qs@6.7.0"]]
+	DependencyPaths [][]string `json:",omitempty"`
+
 	// Custom is for extensibility and not supposed to be used in OSS
 	Custom interface{} `json:",omitempty"`
 
@@ -27,6 +48,36 @@ type DetectedVulnerability struct {
 	types.Vulnerability
 }
 
+// IgnoredVulnerability holds the vulnerability suppressed by the ignore file or the ignore policy, with the decision
+type IgnoredVulnerability struct {
+	DetectedVulnerability
+
+	// Source is the ignore file or the policy file which suppressed the vulnerability
+	Source string
+
+	// Reason and Justification are given in the structured ignore file, e.g. "vulnerable_code_not_in_execute_path"
+	Reason        string
+	Justification string
+}
+
+// Relationships of the packages to the application
+const (
+	RelationshipDirect   = "direct"
+	RelationshipIndirect = "indirect"
+)
+
+// Scopes of the packages, i.e. whether only the development of the application needs them
+const (
+	ScopeDev  = "dev"
+	ScopeProd = "prod"
+)
+
+// SLAStatus holds the due date to fix the vulnerability under the SLA of the organization
+type SLAStatus struct {
+	DueDate time.Time
+	Overdue bool
+}
+
 // BySeverity implements sort.Interface based on the Severity field.
 type BySeverity []DetectedVulnerability
 
@@ -0,0 +1,166 @@
+package unpinned
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	"github.com/aquasecurity/fanal/analyzer/language"
+	ftypes "github.com/aquasecurity/fanal/types"
+	godeptypes "github.com/aquasecurity/go-dep-parser/pkg/types"
+)
+
+func init() {
+	analyzer.RegisterAnalyzer(&pipAnalyzer{})
+	analyzer.RegisterAnalyzer(&npmAnalyzer{})
+}
+
+var pipNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// pipAnalyzer reports the requirements with ranges, e.g. "requests>=2.20,<3", or without versions.
+// The pinned ones with "==" are reported by the analyzer of fanal.
+type pipAnalyzer struct{}
+
+func (a pipAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var libs []godeptypes.Library
+	scanner := bufio.NewScanner(input.Content)
+	for scanner.Scan() {
+		if name, constraint, ok := parseRequirement(scanner.Text()); ok {
+			libs = append(libs, godeptypes.Library{Name: name, Version: constraint})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("unable to read requirements.txt: %w", err)
+	}
+	return language.ToAnalysisResult(Pip, input.FilePath, "", libs, nil), nil
+}
+
+func (a pipAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	return filepath.Base(filePath) == ftypes.PipRequirements
+}
+
+func (a pipAnalyzer) Type() analyzer.Type {
+	return TypePip
+}
+
+func (a pipAnalyzer) Version() int {
+	return version
+}
+
+// parseRequirement returns the name and the specifiers of the requirement, and false if it is pinned
+// or not a requirement from the index, e.g. options, URLs and local paths
+func parseRequirement(line string) (string, string, bool) {
+	line, _, _ = strings.Cut(line, "#")
+	line, _, _ = strings.Cut(line, ";") // environment markers
+	line, _, _ = strings.Cut(line, " --")
+	line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), `\`))
+	if line == "" || strings.HasPrefix(line, "-") || strings.ContainsAny(line, "@/:") {
+		return "", "", false
+	}
+
+	i := strings.IndexAny(line, "<>=!~[( ")
+	if i < 0 {
+		i = len(line)
+	}
+	name, constraint := line[:i], line[i:]
+	if !pipNamePattern.MatchString(name) {
+		return "", "", false
+	}
+
+	// Extras are not a part of the range, e.g. "requests[security]>=2.20"
+	if strings.HasPrefix(strings.TrimSpace(constraint), "[") {
+		if _, after, ok := strings.Cut(constraint, "]"); ok {
+			constraint = after
+		}
+	}
+	constraint = strings.NewReplacer(" ", "", "(", "", ")", "").Replace(constraint)
+
+	switch {
+	case constraint == "":
+		return name, AnyVersion, true
+	case strings.HasPrefix(constraint, "==") && !strings.ContainsAny(constraint, ",*"):
+		return "", "", false
+	}
+	return name, constraint, true
+}
+
+// packageJSON is package.json of the project. The development dependencies are not reported.
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// npmAnalyzer reports the dependencies in package.json of the projects, which the analyzer of fanal ignores
+// as it reads only the name and the version of the installed packages in node_modules
+type npmAnalyzer struct{}
+
+func (a npmAnalyzer) Analyze(_ context.Context, input analyzer.AnalysisInput) (*analyzer.AnalysisResult, error) {
+	var pkg packageJSON
+	if err := json.NewDecoder(input.Content).Decode(&pkg); err != nil {
+		return nil, xerrors.Errorf("unable to decode package.json: %w", err)
+	}
+
+	var libs []godeptypes.Library
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.OptionalDependencies} {
+		for name, spec := range deps {
+			if name, constraint, ok := parseNpmSpec(name, spec); ok {
+				libs = append(libs, godeptypes.Library{Name: name, Version: constraint})
+			}
+		}
+	}
+	sort.Slice(libs, func(i, j int) bool {
+		return libs[i].Name < libs[j].Name
+	})
+	return language.ToAnalysisResult(Npm, input.FilePath, "", libs, nil), nil
+}
+
+func (a npmAnalyzer) Required(filePath string, _ os.FileInfo) bool {
+	if filepath.Base(filePath) != "package.json" {
+		return false
+	}
+	// The installed packages are analyzed by the analyzer of fanal
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+		if dir == "node_modules" {
+			return false
+		}
+	}
+	return true
+}
+
+func (a npmAnalyzer) Type() analyzer.Type {
+	return TypeNpm
+}
+
+func (a npmAnalyzer) Version() int {
+	return version
+}
+
+// parseNpmSpec returns the name and the range of the dependency, and false if it is not from the registry,
+// e.g. "file:../lib", "github:user/repo" and "workspace:*"
+func parseNpmSpec(name, spec string) (string, string, bool) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "npm:") {
+		// Aliases have the name of the package, e.g. "npm:lodash@^4.17.21"
+		alias := strings.TrimPrefix(spec, "npm:")
+		i := strings.LastIndex(alias, "@")
+		if i <= 0 {
+			return alias, AnyVersion, true
+		}
+		name, spec = alias[:i], alias[i+1:]
+	}
+	if strings.ContainsAny(spec, ":/") {
+		return "", "", false
+	}
+	if spec == "" || spec == "latest" {
+		return name, AnyVersion, true
+	}
+	return name, spec, true
+}
@@ -0,0 +1,79 @@
+// Package unpinned analyzes the manifests with version ranges, such as requirements.txt and package.json,
+// when no lock file pins the versions. The packages have the ranges as their versions, and are detected
+// as vulnerable if any version satisfying the range is vulnerable, which is best-effort.
+package unpinned
+
+import (
+	"path"
+	"path/filepath"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/nodejs"
+)
+
+const (
+	// TypePip analyzes the ranges in requirements.txt, which the analyzer of fanal skips
+	TypePip = analyzer.Type("pip-unpinned")
+
+	// TypeNpm analyzes the ranges in package.json of the projects
+	TypeNpm = analyzer.Type("npm-unpinned")
+
+	// Pip is the type of the applications with the ranges in requirements.txt
+	Pip = "pip-unpinned"
+
+	// Npm is the type of the applications with the ranges in package.json
+	Npm = "npm-unpinned"
+
+	// AnyVersion is the range of the packages without any constraint
+	AnyVersion = "*"
+
+	version = 1
+)
+
+// Types are the analyzers enabled with '--detect-unpinned'
+var Types = []analyzer.Type{TypePip, TypeNpm}
+
+// lockFileTypes are the applications pinning the versions of the manifests in the same directory
+var lockFileTypes = map[string][]string{
+	Pip: {ftypes.Pipenv, ftypes.Poetry},
+	Npm: {ftypes.Npm, ftypes.Yarn, nodejs.Pnpm},
+}
+
+// IsUnpinned returns true if the application has the ranges instead of the versions
+func IsUnpinned(appType string) bool {
+	_, ok := lockFileTypes[appType]
+	return ok
+}
+
+// SkipLocked removes the manifests whose directories have lock files, which pin the versions.
+// The workspace members of Node.js are covered by the lock file at the root as their applications are
+// at the paths of their package.json.
+func SkipLocked(apps []ftypes.Application) []ftypes.Application {
+	locked := map[string]bool{}
+	for _, app := range apps {
+		locked[app.Type+":"+dir(app.FilePath)] = true
+	}
+
+	var filtered []ftypes.Application
+	for _, app := range apps {
+		if lockedBy(app, locked) {
+			continue
+		}
+		filtered = append(filtered, app)
+	}
+	return filtered
+}
+
+func lockedBy(app ftypes.Application, locked map[string]bool) bool {
+	for _, t := range lockFileTypes[app.Type] {
+		if locked[t+":"+dir(app.FilePath)] {
+			return true
+		}
+	}
+	return false
+}
+
+func dir(filePath string) string {
+	return path.Dir(filepath.ToSlash(filePath))
+}
@@ -0,0 +1,110 @@
+package unpinned
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/fanal/analyzer"
+	ftypes "github.com/aquasecurity/fanal/types"
+)
+
+func TestAnalyzers(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer interface {
+			Analyze(context.Context, analyzer.AnalysisInput) (*analyzer.AnalysisResult, error)
+		}
+		filePath string
+		content  string
+		want     []ftypes.Package
+	}{
+		{
+			name:     "requirements.txt",
+			analyzer: pipAnalyzer{},
+			filePath: "app/requirements.txt",
+			content: `# pinned ones are reported by fanal
+Django==4.0.4
+requests[security] >= 2.20, < 3  # comment
+urllib3~=1.26 ; python_version >= "3.6"
+flask
+-r base.txt
+git+https://github.com/pallets/click.git
+`,
+			want: []ftypes.Package{
+				{Name: "requests", Version: ">=2.20,<3"},
+				{Name: "urllib3", Version: "~=1.26"},
+				{Name: "flask", Version: "*"},
+			},
+		},
+		{
+			name:     "package.json",
+			analyzer: npmAnalyzer{},
+			filePath: "app/package.json",
+			content: `{
+  "name": "app",
+  "dependencies": {
+    "lodash": "^4.17.0",
+    "express": "latest",
+    "string-width": "npm:string-width@^4.2.3",
+    "lib": "file:../lib",
+    "repo": "github:user/repo"
+  },
+  "optionalDependencies": {
+    "fsevents": "~2.3.2"
+  },
+  "devDependencies": {
+    "typescript": "^4.7.2"
+  }
+}`,
+			want: []ftypes.Package{
+				{Name: "express", Version: "*"},
+				{Name: "fsevents", Version: "~2.3.2"},
+				{Name: "lodash", Version: "^4.17.0"},
+				{Name: "string-width", Version: "^4.2.3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.analyzer.Analyze(context.Background(), analyzer.AnalysisInput{
+				FilePath: tt.filePath,
+				Content:  strings.NewReader(tt.content),
+			})
+			require.NoError(t, err)
+			require.Len(t, got.Applications, 1)
+			assert.Equal(t, tt.filePath, got.Applications[0].FilePath)
+			assert.Equal(t, tt.want, got.Applications[0].Libraries)
+		})
+	}
+}
+
+func Test_npmAnalyzer_Required(t *testing.T) {
+	a := npmAnalyzer{}
+	assert.True(t, a.Required("app/package.json", nil))
+	assert.False(t, a.Required("app/node_modules/lodash/package.json", nil))
+	assert.False(t, a.Required("app/package-lock.json", nil))
+}
+
+func TestSkipLocked(t *testing.T) {
+	apps := []ftypes.Application{
+		{Type: ftypes.Npm, FilePath: "web/package-lock.json"},
+		{Type: Npm, FilePath: "web/package.json"},
+		{Type: Npm, FilePath: "tools/package.json"},
+		{Type: ftypes.Pip, FilePath: "api/requirements.txt"},
+		{Type: Pip, FilePath: "api/requirements.txt"},
+		{Type: ftypes.Poetry, FilePath: "worker/poetry.lock"},
+		{Type: Pip, FilePath: "worker/requirements.txt"},
+	}
+	got := SkipLocked(apps)
+	assert.Equal(t, []ftypes.Application{
+		{Type: ftypes.Npm, FilePath: "web/package-lock.json"},
+		{Type: Npm, FilePath: "tools/package.json"},
+		{Type: ftypes.Pip, FilePath: "api/requirements.txt"},
+		{Type: Pip, FilePath: "api/requirements.txt"},
+		{Type: ftypes.Poetry, FilePath: "worker/poetry.lock"},
+	}, got)
+}
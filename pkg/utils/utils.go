@@ -5,13 +5,22 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 
 	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
 )
 
-var cacheDir string
+var (
+	cacheDir string
+
+	// caBundle is trusted in addition to the system certificates, see SetCABundle
+	caBundle     []string
+	caBundlePool *x509.CertPool
+)
 
 // DefaultCacheDir returns/creates the cache-dir to be used for trivy operations
 func DefaultCacheDir() string {
@@ -75,3 +84,126 @@ func GetTLSConfig(caCertPath, certPath, keyPath string) (*x509.CertPool, tls.Cer
 
 	return caCertPool, cert, nil
 }
+
+// SetCABundle sets the PEM certificates in the given files and directories trusted by all outbound HTTPS requests
+// in addition to the system certificates, e.g. the CA of a corporate proxy intercepting TLS.
+// The default transports are left untouched, and the bundle applies to the pools returned by LoadCertPool
+// and the transports returned by HTTPTransport.
+func SetCABundle(paths []string) error {
+	if len(paths) == 0 {
+		caBundle, caBundlePool = nil, nil
+		return nil
+	}
+
+	caBundle = paths
+	pool, err := LoadCertPool(nil)
+	if err != nil {
+		caBundle, caBundlePool = nil, nil
+		return err
+	}
+	caBundlePool = pool
+	return nil
+}
+
+// HTTPTransport returns a clone of the default transport of net/http trusting the CA bundle,
+// which honors HTTP(S)_PROXY and NO_PROXY. The certificates are not verified if insecure is true.
+func HTTPTransport(insecure bool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = TLSClientConfig(t.TLSClientConfig, caBundlePool, insecure)
+	return t
+}
+
+// TLSClientConfig returns a clone of the TLS config of the transport verifying the certificates with the pool,
+// or not verifying them if insecure is true. The config is returned as is when neither is given.
+func TLSClientConfig(config *tls.Config, rootCAs *x509.CertPool, insecure bool) *tls.Config {
+	if rootCAs == nil && !insecure {
+		return config
+	}
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	if rootCAs != nil {
+		config.RootCAs = rootCAs
+	}
+	config.InsecureSkipVerify = insecure
+	return config
+}
+
+// LoadCertPool returns the system cert pool with the CA bundle and the PEM certificates in the given files and directories.
+// It returns nil when no path is given and no CA bundle is set so that the default pool is used.
+func LoadCertPool(paths []string) (*x509.CertPool, error) {
+	paths = append(caBundle[:len(caBundle):len(caBundle)], paths...)
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if err = appendCerts(pool, paths); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// LoadClientCertPool returns the cert pool only with the PEM certificates in the given files and directories.
+// The system certificates are not included so that only certificates issued by the given CAs are trusted.
+func LoadClientCertPool(paths []string) (*x509.CertPool, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if err := appendCerts(pool, paths); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func appendCerts(pool *x509.CertPool, paths []string) error {
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return xerrors.Errorf("CA stat error: %w", err)
+		}
+
+		if !fi.IsDir() {
+			if err = appendCertsFromFile(pool, path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return xerrors.Errorf("CA directory read error: %w", err)
+		}
+		for _, entry := range entries {
+			// Symlinks are followed since CA directories are often managed by c_rehash
+			name := filepath.Join(path, entry.Name())
+			if fi, err = os.Stat(name); err != nil || fi.IsDir() {
+				continue
+			}
+			// Files other than certificates are skipped
+			if err = appendCertsFromFile(pool, name); err != nil {
+				log.Logger.Debugf("CA file (%s) is skipped: %s", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func appendCertsFromFile(pool *x509.CertPool, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return xerrors.Errorf("CA file read error: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(b) {
+		return xerrors.Errorf("no PEM certificate found in %s", path)
+	}
+	return nil
+}
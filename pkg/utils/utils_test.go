@@ -1,9 +1,16 @@
 package utils
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -72,3 +79,127 @@ func TestCopyFile(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadCertPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		wantNil bool
+		wantErr string
+	}{
+		{
+			name:    "no path",
+			wantNil: true,
+		},
+		{
+			name:  "happy path with a file",
+			paths: []string{"testdata/ca/cert.pem"},
+		},
+		{
+			name:  "happy path with a directory",
+			paths: []string{"testdata/ca"},
+		},
+		{
+			name:    "sad path with a missing file",
+			paths:   []string{"testdata/missing.pem"},
+			wantErr: "CA stat error",
+		},
+		{
+			name:    "sad path with an invalid file",
+			paths:   []string{"testdata/invalid.pem"},
+			wantErr: "no PEM certificate found in testdata/invalid.pem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadCertPool(tt.paths)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+
+			// The test certificate must be trusted
+			b, err := os.ReadFile("testdata/ca/cert.pem")
+			require.NoError(t, err)
+			block, _ := pem.Decode(b)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			require.NoError(t, err)
+			_, err = cert.Verify(x509.VerifyOptions{
+				DNSName: "localhost",
+				Roots:   got,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSetCABundle(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.pem")
+	write(t, bundle, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})))
+
+	defer func() {
+		caBundle, caBundlePool = nil, nil
+	}()
+
+	// Not trusted without the bundle
+	client := &http.Client{Transport: HTTPTransport(false)}
+	_, err := client.Get(ts.URL)
+	require.Error(t, err)
+
+	require.NoError(t, SetCABundle([]string{bundle}))
+
+	client = &http.Client{Transport: HTTPTransport(false)}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The default transports are left untouched
+	for _, transport := range []*http.Transport{http.DefaultTransport.(*http.Transport), remote.DefaultTransport} {
+		if transport.TLSClientConfig != nil {
+			assert.Nil(t, transport.TLSClientConfig.RootCAs)
+		}
+	}
+
+	// The pools for the specific CAs include the bundle
+	pool, err := LoadCertPool([]string{"testdata/ca/cert.pem"})
+	require.NoError(t, err)
+	_, err = ts.Certificate().Verify(x509.VerifyOptions{Roots: pool})
+	assert.NoError(t, err)
+
+	assert.ErrorContains(t, SetCABundle([]string{"testdata/invalid.pem"}), "no PEM certificate found")
+}
+
+func TestLoadClientCertPool(t *testing.T) {
+	got, err := LoadClientCertPool(nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = LoadClientCertPool([]string{"testdata/ca"})
+	require.NoError(t, err)
+
+	// Only the given CA is included
+	assert.Len(t, got.Subjects(), 1) // nolint: staticcheck
+}
+
+func TestTLSClientConfig(t *testing.T) {
+	pool := x509.NewCertPool()
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// The config of the transport is kept without the pool and '--insecure'
+	assert.Same(t, base, TLSClientConfig(base, nil, false))
+
+	got := TLSClientConfig(base, pool, true)
+	assert.Equal(t, &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool, InsecureSkipVerify: true}, got)
+	assert.Nil(t, base.RootCAs, "the config of the transport must not be modified")
+
+	assert.Equal(t, &tls.Config{RootCAs: pool}, TLSClientConfig(nil, pool, false))
+}
@@ -0,0 +1,164 @@
+package vex
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/purl"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// ref. https://github.com/openvex/spec/blob/main/OPENVEX-SPEC.md
+const (
+	OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+	// DefaultAuthor is the author of the document when it is not given, in the same way as vexctl
+	DefaultAuthor = "Unknown Author"
+
+	StatusNotAffected = "not_affected"
+	StatusAffected    = "affected"
+)
+
+// Justifications are the justifications of the status "not_affected"
+var Justifications = []string{
+	"component_not_present",
+	"vulnerable_code_not_present",
+	"vulnerable_code_not_in_execute_path",
+	"vulnerable_code_cannot_be_controlled_by_adversary",
+	"inline_mitigations_already_exist",
+}
+
+// OpenVEX is the OpenVEX document recording the ignore decisions of the vulnerabilities
+type OpenVEX struct {
+	Context    string      `json:"@context"`
+	ID         string      `json:"@id"`
+	Author     string      `json:"author"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Version    int         `json:"version"`
+	Tooling    string      `json:"tooling,omitempty"`
+	Statements []Statement `json:"statements"`
+}
+
+// Statement is the status of the vulnerability in the products
+type Statement struct {
+	Vulnerability   Vulnerability `json:"vulnerability"`
+	Products        []Product     `json:"products"`
+	Status          string        `json:"status"`
+	Justification   string        `json:"justification,omitempty"`
+	ImpactStatement string        `json:"impact_statement,omitempty"`
+	ActionStatement string        `json:"action_statement,omitempty"`
+}
+
+type Vulnerability struct {
+	Name string `json:"name"`
+}
+
+// Product is the scanned artifact, and Subcomponents are the affected packages in it
+type Product struct {
+	ID            string      `json:"@id"`
+	Subcomponents []Component `json:"subcomponents,omitempty"`
+}
+
+type Component struct {
+	ID string `json:"@id"`
+}
+
+// NewOpenVEX returns the document with a statement per ignore decision of each vulnerability.
+// The vulnerabilities ignored with a reason or a justification are "not_affected", and those ignored without them,
+// e.g. by the flat .trivyignore or the ignore policy, are "affected" with the source of the decision, as there is no
+// claim that they don't affect the packages.
+func NewOpenVEX(report types.Report, author, appVersion string, now time.Time) OpenVEX {
+	if author == "" {
+		author = DefaultAuthor
+	}
+	product := productID(report)
+
+	// The packages with the same decision of the vulnerability are merged into a statement
+	var statements []Statement
+	index := map[string]int{}
+	for _, result := range report.Results {
+		for _, vuln := range result.IgnoredVulnerabilities {
+			statement := newStatement(vuln)
+			key := fmt.Sprintf("%s/%s/%s/%s/%s", vuln.VulnerabilityID, statement.Status, statement.Justification,
+				statement.ImpactStatement, statement.ActionStatement)
+			i, ok := index[key]
+			if !ok {
+				statement.Products = []Product{{ID: product}}
+				statements = append(statements, statement)
+				i = len(statements) - 1
+				index[key] = i
+			}
+
+			component := Component{ID: componentID(report.Metadata, result, vuln.DetectedVulnerability)}
+			subcomponents := statements[i].Products[0].Subcomponents
+			if !slices.Contains(subcomponents, component) {
+				statements[i].Products[0].Subcomponents = append(subcomponents, component)
+			}
+		}
+	}
+
+	doc := OpenVEX{
+		Context:    OpenVEXContext,
+		Author:     author,
+		Timestamp:  now.UTC(),
+		Version:    1,
+		Tooling:    "trivy " + appVersion,
+		Statements: statements,
+	}
+	if doc.Statements == nil {
+		doc.Statements = []Statement{}
+	}
+	doc.ID = documentID(doc.Statements)
+	return doc
+}
+
+func newStatement(vuln types.IgnoredVulnerability) Statement {
+	statement := Statement{
+		Vulnerability: Vulnerability{Name: vuln.VulnerabilityID},
+	}
+	if vuln.Justification == "" && vuln.Reason == "" {
+		statement.Status = StatusAffected
+		statement.ActionStatement = fmt.Sprintf("Ignored by %s", vuln.Source)
+		return statement
+	}
+	statement.Status = StatusNotAffected
+	statement.Justification = vuln.Justification
+	statement.ImpactStatement = vuln.Reason
+	return statement
+}
+
+// productID returns the PURL of the image if the image is in a registry, or the name of the artifact
+func productID(report types.Report) string {
+	if report.ArtifactType == ftypes.ArtifactContainerImage {
+		if p, err := purl.NewPackageURL(purl.TypeOCI, report.Metadata, ftypes.Package{}); err == nil && p.Type != "" {
+			return p.ToString()
+		}
+	}
+	return report.ArtifactName
+}
+
+// componentID returns the PURL of the package, or the name and the version if the PURL can't be built
+func componentID(metadata types.Metadata, result types.Result, vuln types.DetectedVulnerability) string {
+	// The PURLs of the OS packages need the OS
+	if result.Type != "" && (result.Class != types.ClassOSPkg || metadata.OS != nil) {
+		p, err := purl.NewPackageURL(result.Type, metadata, ftypes.Package{
+			Name:    vuln.PkgName,
+			Version: vuln.InstalledVersion,
+		})
+		if err == nil {
+			return p.ToString()
+		}
+	}
+	return vuln.PkgName + "@" + vuln.InstalledVersion
+}
+
+// documentID derives the ID from the statements, so that the same decisions have the same ID across the scans
+func documentID(statements []Statement) string {
+	b, _ := json.Marshal(statements) // nolint: errcheck
+	return fmt.Sprintf("https://openvex.dev/docs/public/vex-%x", sha256.Sum256(b))
+}
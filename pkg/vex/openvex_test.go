@@ -0,0 +1,160 @@
+package vex_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/vex"
+)
+
+func TestNewOpenVEX(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	ignored := func(id, pkgName, version, source, reason, justification string) types.IgnoredVulnerability {
+		return types.IgnoredVulnerability{
+			DetectedVulnerability: types.DetectedVulnerability{
+				VulnerabilityID:  id,
+				PkgName:          pkgName,
+				InstalledVersion: version,
+			},
+			Source:        source,
+			Reason:        reason,
+			Justification: justification,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		report types.Report
+		author string
+		want   []vex.Statement
+	}{
+		{
+			name: "image",
+			report: types.Report{
+				ArtifactName: "ghcr.io/aquasecurity/app:1.0",
+				ArtifactType: ftypes.ArtifactContainerImage,
+				Metadata: types.Metadata{
+					OS: &ftypes.OS{Family: "alpine", Name: "3.16.0"},
+					RepoDigests: []string{
+						"ghcr.io/aquasecurity/app@sha256:5c32b5dc1ffd62b7cf9f1a9ffb24ca0eb9a2f8fb4c4e2d9d6a5c4d2b8f1b0c2e",
+					},
+				},
+				Results: types.Results{
+					{
+						Target: "ghcr.io/aquasecurity/app:1.0 (alpine 3.16.0)",
+						Class:  types.ClassOSPkg,
+						Type:   "alpine",
+						IgnoredVulnerabilities: []types.IgnoredVulnerability{
+							ignored("CVE-2022-0001", "musl", "1.2.3-r0", ".trivyignore.yaml", "only in the build stage",
+								"vulnerable_code_not_in_execute_path"),
+							ignored("CVE-2022-0002", "busybox", "1.35.0-r13", "policy.rego", "", ""),
+						},
+					},
+					{
+						Target: "app/package-lock.json",
+						Class:  types.ClassLangPkg,
+						Type:   ftypes.Npm,
+						IgnoredVulnerabilities: []types.IgnoredVulnerability{
+							ignored("CVE-2022-0001", "lodash", "4.17.20", ".trivyignore.yaml", "only in the build stage",
+								"vulnerable_code_not_in_execute_path"),
+							ignored("CVE-2022-0001", "lodash", "4.17.20", ".trivyignore.yaml", "only in the build stage",
+								"vulnerable_code_not_in_execute_path"),
+						},
+					},
+				},
+			},
+			author: "security@example.com",
+			want: []vex.Statement{
+				{
+					Vulnerability: vex.Vulnerability{Name: "CVE-2022-0001"},
+					Products: []vex.Product{
+						{
+							ID: "pkg:oci/app@sha256:5c32b5dc1ffd62b7cf9f1a9ffb24ca0eb9a2f8fb4c4e2d9d6a5c4d2b8f1b0c2e?repository_url=ghcr.io%2Faquasecurity%2Fapp&arch=",
+							Subcomponents: []vex.Component{
+								{ID: "pkg:apk/alpine/musl@1.2.3-r0?distro=3.16.0"},
+								{ID: "pkg:npm/lodash@4.17.20"},
+							},
+						},
+					},
+					Status:          vex.StatusNotAffected,
+					Justification:   "vulnerable_code_not_in_execute_path",
+					ImpactStatement: "only in the build stage",
+				},
+				{
+					Vulnerability: vex.Vulnerability{Name: "CVE-2022-0002"},
+					Products: []vex.Product{
+						{
+							ID: "pkg:oci/app@sha256:5c32b5dc1ffd62b7cf9f1a9ffb24ca0eb9a2f8fb4c4e2d9d6a5c4d2b8f1b0c2e?repository_url=ghcr.io%2Faquasecurity%2Fapp&arch=",
+							Subcomponents: []vex.Component{
+								{ID: "pkg:apk/alpine/busybox@1.35.0-r13?distro=3.16.0"},
+							},
+						},
+					},
+					Status:          vex.StatusAffected,
+					ActionStatement: "Ignored by policy.rego",
+				},
+			},
+		},
+		{
+			name: "filesystem without purls",
+			report: types.Report{
+				ArtifactName: "./app",
+				ArtifactType: ftypes.ArtifactFilesystem,
+				Results: types.Results{
+					{
+						Target: "app/go.sum",
+						Class:  types.ClassLangPkg,
+						IgnoredVulnerabilities: []types.IgnoredVulnerability{
+							ignored("CVE-2022-0003", "golang.org/x/net", "v0.1.0", ".trivyignore", "", ""),
+						},
+					},
+				},
+			},
+			want: []vex.Statement{
+				{
+					Vulnerability: vex.Vulnerability{Name: "CVE-2022-0003"},
+					Products: []vex.Product{
+						{
+							ID:            "./app",
+							Subcomponents: []vex.Component{{ID: "golang.org/x/net@v0.1.0"}},
+						},
+					},
+					Status:          vex.StatusAffected,
+					ActionStatement: "Ignored by .trivyignore",
+				},
+			},
+		},
+		{
+			name: "nothing ignored",
+			report: types.Report{
+				ArtifactName: "./app",
+				ArtifactType: ftypes.ArtifactFilesystem,
+			},
+			want: []vex.Statement{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vex.NewOpenVEX(tt.report, tt.author, "0.30.0", now)
+			assert.Equal(t, tt.want, got.Statements)
+			assert.Equal(t, vex.OpenVEXContext, got.Context)
+			assert.Equal(t, now, got.Timestamp)
+			assert.Equal(t, "trivy 0.30.0", got.Tooling)
+			assert.True(t, strings.HasPrefix(got.ID, "https://openvex.dev/docs/public/vex-"))
+			if tt.author == "" {
+				assert.Equal(t, vex.DefaultAuthor, got.Author)
+			} else {
+				assert.Equal(t, tt.author, got.Author)
+			}
+
+			// The same decisions have the same ID
+			again := vex.NewOpenVEX(tt.report, tt.author, "0.30.0", now.Add(time.Hour))
+			assert.Equal(t, got.ID, again.ID)
+		})
+	}
+}
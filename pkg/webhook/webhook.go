@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+// postTimeout is the timeout to post a notification
+const postTimeout = 10 * time.Second
+
+// Option holds the options of the notifications
+type Option struct {
+	// URL is the webhook to post the summary to when scans complete
+	URL string
+
+	// AttachReport includes the full report in the payload
+	AttachReport bool
+
+	// Insecure skips the verification of the certificate of the webhook
+	Insecure bool
+}
+
+// Payload is posted to the webhook as JSON when a scan completes
+type Payload struct {
+	// Text is shown by chat services such as Slack and Microsoft Teams
+	Text string `json:"text"`
+
+	ArtifactName string
+	ArtifactType ftypes.ArtifactType `json:",omitempty"`
+
+	// The number of findings by severity
+	Vulnerabilities   map[string]int
+	Misconfigurations map[string]int
+	Secrets           map[string]int
+
+	// ReportURL is where the report is stored with --store
+	ReportURL string        `json:",omitempty"`
+	Report    *types.Report `json:",omitempty"`
+
+	// Rescan is true when the findings are the new ones found by the scheduled re-scan of the server
+	Rescan bool `json:",omitempty"`
+}
+
+// Validate returns an error if the URL is not an HTTP(S) URL, so that it is reported before scanning
+func Validate(webhookURL string) error {
+	// The URL is not included in the errors as it often includes the secret
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return xerrors.New("invalid webhook URL")
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return xerrors.New("the webhook URL must start with http:// or https://")
+	}
+	return nil
+}
+
+// NewPayload returns the summary of the report
+func NewPayload(report types.Report, reportURL string, attachReport bool) Payload {
+	p := Payload{
+		ArtifactName:      report.ArtifactName,
+		ArtifactType:      report.ArtifactType,
+		Vulnerabilities:   map[string]int{},
+		Misconfigurations: map[string]int{},
+		Secrets:           map[string]int{},
+		ReportURL:         reportURL,
+	}
+	for _, s := range dbTypes.SeverityNames {
+		p.Vulnerabilities[s] = 0
+		p.Misconfigurations[s] = 0
+		p.Secrets[s] = 0
+	}
+
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			p.Vulnerabilities[severity(v.Severity)]++
+		}
+		for _, m := range r.Misconfigurations {
+			if m.Status == types.StatusFailure {
+				p.Misconfigurations[severity(m.Severity)]++
+			}
+		}
+		for _, s := range r.Secrets {
+			p.Secrets[severity(s.Severity)]++
+		}
+	}
+
+	if attachReport {
+		p.Report = &report
+	}
+	p.Text = p.text()
+	return p
+}
+
+// NewRescanPayload returns the summary of the new findings in the report re-scanned by the server
+func NewRescanPayload(report types.Report, attachReport bool) Payload {
+	p := NewPayload(report, "", attachReport)
+	p.Rescan = true
+	p.Text = p.text()
+	return p
+}
+
+// text returns the summary in a line,
+// e.g. "Trivy scanned alpine:3.15: vulnerabilities: 1 CRITICAL, 2 HIGH; secrets: 1 HIGH"
+func (p Payload) text() string {
+	var findings []string
+	for _, f := range []struct {
+		name   string
+		counts map[string]int
+	}{
+		{"vulnerabilities", p.Vulnerabilities},
+		{"misconfigurations", p.Misconfigurations},
+		{"secrets", p.Secrets},
+	} {
+		// The most severe first
+		var counts []string
+		for i := len(dbTypes.SeverityNames) - 1; i >= 0; i-- {
+			s := dbTypes.SeverityNames[i]
+			if n := f.counts[s]; n > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", n, s))
+			}
+		}
+		if len(counts) > 0 {
+			findings = append(findings, fmt.Sprintf("%s: %s", f.name, strings.Join(counts, ", ")))
+		}
+	}
+
+	text := fmt.Sprintf("Trivy scanned %s: ", p.ArtifactName)
+	if p.Rescan {
+		text = fmt.Sprintf("Trivy found new findings in %s: ", p.ArtifactName)
+	}
+	if len(findings) == 0 {
+		text += "no findings"
+	} else {
+		text += strings.Join(findings, "; ")
+	}
+	if p.ReportURL != "" {
+		text += fmt.Sprintf(" (%s)", p.ReportURL)
+	}
+	return text
+}
+
+// Post posts the payload to the webhook of the option
+func Post(ctx context.Context, opt Option, p Payload) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal json: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opt.URL, bytes.NewReader(b))
+	if err != nil {
+		return xerrors.Errorf("unable to create a request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// The URL is not logged as it often includes the secret, e.g. Slack incoming webhooks
+	log.Logger.Debugf("Posting the notification of %s to the webhook", p.ArtifactName)
+	// The default client has no timeout and ignores '--ca-bundle' and '--insecure'
+	client := &http.Client{
+		Timeout:   postTimeout,
+		Transport: utils.HTTPTransport(opt.Insecure),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return xerrors.Errorf("webhook request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return xerrors.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// An invalid or empty severity is counted as UNKNOWN
+func severity(s string) string {
+	sev, _ := dbTypes.NewSeverity(s) // nolint: errcheck
+	return sev.String()
+}
@@ -0,0 +1,186 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/fanal/types"
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/webhook"
+)
+
+func TestNewPayload(t *testing.T) {
+	report := types.Report{
+		ArtifactName: "alpine:3.15",
+		ArtifactType: ftypes.ArtifactContainerImage,
+		Results: types.Results{
+			{
+				Target: "alpine:3.15 (alpine 3.15.4)",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2022-0001", Vulnerability: dbTypes.Vulnerability{Severity: "CRITICAL"}},
+					{VulnerabilityID: "CVE-2022-0002", Vulnerability: dbTypes.Vulnerability{Severity: "HIGH"}},
+					{VulnerabilityID: "CVE-2022-0003", Vulnerability: dbTypes.Vulnerability{Severity: "HIGH"}},
+					{VulnerabilityID: "CVE-2022-0004"},
+				},
+			},
+			{
+				Target: "Dockerfile",
+				Misconfigurations: []types.DetectedMisconfiguration{
+					{ID: "DS001", Severity: "MEDIUM", Status: types.StatusPassed},
+					{ID: "DS002", Severity: "HIGH", Status: types.StatusFailure},
+				},
+			},
+			{
+				Target: "deploy.sh",
+				Secrets: []ftypes.SecretFinding{
+					{RuleID: "aws-access-key-id", Severity: "CRITICAL"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		report       types.Report
+		reportURL    string
+		attachReport bool
+		wantText     string
+		wantVulns    map[string]int
+	}{
+		{
+			name:      "findings",
+			report:    report,
+			reportURL: "s3://reports/sha256:1234/20220601T000000Z/report.json",
+			wantText: "Trivy scanned alpine:3.15: vulnerabilities: 1 CRITICAL, 2 HIGH, 1 UNKNOWN; " +
+				"misconfigurations: 1 HIGH; secrets: 1 CRITICAL (s3://reports/sha256:1234/20220601T000000Z/report.json)",
+			wantVulns: map[string]int{"CRITICAL": 1, "HIGH": 2, "MEDIUM": 0, "LOW": 0, "UNKNOWN": 1},
+		},
+		{
+			name:         "no findings",
+			report:       types.Report{ArtifactName: "/app", ArtifactType: ftypes.ArtifactFilesystem},
+			attachReport: true,
+			wantText:     "Trivy scanned /app: no findings",
+			wantVulns:    map[string]int{"CRITICAL": 0, "HIGH": 0, "MEDIUM": 0, "LOW": 0, "UNKNOWN": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := webhook.NewPayload(tt.report, tt.reportURL, tt.attachReport)
+			assert.Equal(t, tt.wantText, got.Text)
+			assert.Equal(t, tt.wantVulns, got.Vulnerabilities)
+			assert.Equal(t, tt.reportURL, got.ReportURL)
+			if tt.attachReport {
+				require.NotNil(t, got.Report)
+				assert.Equal(t, tt.report.ArtifactName, got.Report.ArtifactName)
+			} else {
+				assert.Nil(t, got.Report)
+			}
+		})
+	}
+}
+
+func TestPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		tls      bool
+		insecure bool
+		wantErr  string
+	}{
+		{
+			name:   "happy path",
+			status: http.StatusOK,
+		},
+		{
+			name:     "happy path: insecure",
+			status:   http.StatusOK,
+			tls:      true,
+			insecure: true,
+		},
+		{
+			name:    "sad path: not found",
+			status:  http.StatusNotFound,
+			wantErr: "webhook returned 404 Not Found: no_service",
+		},
+		{
+			name:    "sad path: unknown authority",
+			status:  http.StatusOK,
+			tls:     true,
+			wantErr: "certificate signed by unknown authority",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("no_service"))
+			}))
+			if tt.tls {
+				ts.StartTLS()
+			} else {
+				ts.Start()
+			}
+			defer ts.Close()
+
+			p := webhook.NewPayload(types.Report{ArtifactName: "alpine:3.15"}, "", false)
+			opt := webhook.Option{
+				URL:      ts.URL + "/services/T000/B000/XXXX",
+				Insecure: tt.insecure,
+			}
+			err := webhook.Post(context.Background(), opt, p)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Trivy scanned alpine:3.15: no findings", got["text"])
+			assert.Equal(t, "alpine:3.15", got["ArtifactName"])
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		webhookURL string
+		wantErr    string
+	}{
+		{
+			name:       "happy path",
+			webhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+		},
+		{
+			name:       "sad path: unsupported scheme",
+			webhookURL: "ftp://example.com/hook",
+			wantErr:    "the webhook URL must start with http:// or https://",
+		},
+		{
+			name:       "sad path: invalid URL",
+			webhookURL: "https://example.com/%zz",
+			wantErr:    "invalid webhook URL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Validate(tt.webhookURL)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
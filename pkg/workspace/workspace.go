@@ -0,0 +1,210 @@
+package workspace
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/go-units"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const (
+	dirPrefix = "trivy-workspace-"
+
+	// lockFile is held by the process using the workspace, and released by the OS when the process exits
+	lockFile = ".lock"
+
+	// lockGrace is the age before which workspaces are never removed, as the owner may not have locked it yet
+	lockGrace = time.Minute
+
+	// staleAge is the age after which workspaces without the lock file, e.g. left by older versions, are removed
+	staleAge = 24 * time.Hour
+)
+
+var (
+	// live holds the workspaces to be removed before os.Exit() in creation order
+	live   []*Workspace
+	liveMu sync.Mutex
+)
+
+// Workspace represents a temporary directory where images are saved and unpacked during a scan.
+// The directory is passed to the artifacts, which place their temporary files under it
+// so that they can be removed at once, even when the scan is interrupted.
+type Workspace struct {
+	dir  string
+	lock *os.File
+
+	signals     chan os.Signal
+	cleanupOnce sync.Once
+}
+
+// New creates a workspace under the given root directory. The system temporary directory is used if root is empty.
+// Stale workspaces left by the processes which have exited are garbage-collected.
+func New(root string) (*Workspace, error) {
+	if root == "" {
+		root = os.TempDir()
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, xerrors.Errorf("failed to create the workdir (%s): %w", root, err)
+	}
+
+	removeStale(root, time.Now())
+
+	dir, err := os.MkdirTemp(root, dirPrefix+"*")
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create a workspace in %s: %w", root, err)
+	}
+	log.Logger.Debugf("Workspace: %s", dir)
+
+	// The lock tells the other processes that the workspace is in use
+	lock, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, xerrors.Errorf("failed to create the lock file in %s: %w", dir, err)
+	}
+	if err = lockExclusive(lock); err != nil {
+		_ = lock.Close()
+		_ = os.RemoveAll(dir)
+		return nil, xerrors.Errorf("failed to lock the workspace (%s): %w", dir, err)
+	}
+
+	w := &Workspace{
+		dir:     dir,
+		lock:    lock,
+		signals: make(chan os.Signal, 1),
+	}
+
+	liveMu.Lock()
+	live = append(live, w)
+	liveMu.Unlock()
+
+	return w, nil
+}
+
+// RemoveOnSignal removes all the workspaces and exits on SIGINT and SIGTERM until the workspace is cleaned up,
+// as deferred functions are not called on Ctrl-C. It is for the CLI, and the programs embedding Trivy
+// handle the signals by themselves.
+func (w *Workspace) RemoveOnSignal() {
+	signal.Notify(w.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-w.signals
+		if !ok {
+			return
+		}
+		log.Logger.Infof("Received %s, removing the workspace...", sig)
+		CleanupAll()
+		if s, ok := sig.(syscall.Signal); ok {
+			os.Exit(128 + int(s))
+		}
+		os.Exit(1)
+	}()
+}
+
+// Dir returns the path of the workspace
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// Preflight checks if the workspace has enough free space for the estimated need.
+// The check is skipped when the need is unknown.
+func (w *Workspace) Preflight(need int64) error {
+	if need <= 0 {
+		return nil
+	}
+
+	avail, err := availableSpace(w.dir)
+	if err != nil {
+		log.Logger.Debugf("Unable to get the available disk space of %s: %s", w.dir, err)
+		return nil
+	}
+	log.Logger.Debugf("Disk space: %s available, %s required",
+		units.BytesSize(float64(avail)), units.BytesSize(float64(need)))
+
+	if uint64(need) > avail {
+		return xerrors.Errorf("insufficient disk space in %s: %s available, %s required (use --workdir to change the directory)",
+			filepath.Dir(w.dir), units.BytesSize(float64(avail)), units.BytesSize(float64(need)))
+	}
+	return nil
+}
+
+// Cleanup removes the workspace. It is safe to call it multiple times.
+func (w *Workspace) Cleanup() {
+	w.cleanupOnce.Do(func() {
+		signal.Stop(w.signals)
+		close(w.signals)
+
+		// The locked file can't be removed on Windows
+		_ = w.lock.Close()
+		if err := os.RemoveAll(w.dir); err != nil {
+			log.Logger.Warnf("Failed to remove the workspace (%s): %s", w.dir, err)
+		}
+
+		liveMu.Lock()
+		if i := slices.Index(live, w); i >= 0 {
+			live = slices.Delete(live, i, i+1)
+		}
+		liveMu.Unlock()
+	})
+}
+
+// CleanupAll removes all the workspaces. It must be called before os.Exit() as deferred functions are not run.
+func CleanupAll() {
+	liveMu.Lock()
+	workspaces := slices.Clone(live)
+	liveMu.Unlock()
+
+	for _, w := range workspaces {
+		w.Cleanup()
+	}
+}
+
+// removeStale removes workspaces which are left by processes killed without cleanup, e.g. OOM killed.
+// The workspace is stale when its lock file is not held by any process, as the OS releases the lock when the process
+// exits. The workspaces without the lock file are stale after staleAge.
+func removeStale(root string, now time.Time) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), dirPrefix) {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if !isStale(dir, entry, now) {
+			continue
+		}
+
+		log.Logger.Debugf("Removing the stale workspace: %s", dir)
+		if err = os.RemoveAll(dir); err != nil {
+			log.Logger.Debugf("Failed to remove the stale workspace (%s): %s", dir, err)
+		}
+	}
+}
+
+// isStale returns true if the owner of the workspace has exited
+func isStale(dir string, entry os.DirEntry, now time.Time) bool {
+	info, err := entry.Info()
+	if err != nil || now.Sub(info.ModTime()) < lockGrace {
+		return false
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return now.Sub(info.ModTime()) >= staleAge
+	} else if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	// The lock is released by closing the file
+	return tryLockExclusive(f) == nil
+}
@@ -0,0 +1,115 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	// Workspace left by an older version
+	legacy := filepath.Join(root, dirPrefix+"legacy")
+	require.NoError(t, os.Mkdir(legacy, 0700))
+	require.NoError(t, os.Chtimes(legacy, old, old))
+
+	// Workspace left by a killed process, whose lock is not held
+	stale := filepath.Join(root, dirPrefix+"stale")
+	require.NoError(t, os.Mkdir(stale, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(stale, lockFile), nil, 0600))
+	require.NoError(t, os.Chtimes(stale, recent, recent))
+
+	// Workspace used by another process for a long time
+	running, err := New(root)
+	require.NoError(t, err)
+	defer running.Cleanup()
+	require.NoError(t, os.Chtimes(running.Dir(), old, old))
+
+	// Workspace just created by another process, which is not locked yet
+	creating := filepath.Join(root, dirPrefix+"creating")
+	require.NoError(t, os.Mkdir(creating, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(creating, lockFile), nil, 0600))
+
+	// Unrelated directory
+	other := filepath.Join(root, "other")
+	require.NoError(t, os.Mkdir(other, 0700))
+	require.NoError(t, os.Chtimes(other, old, old))
+
+	origTmpDir := os.TempDir()
+
+	w, err := New(root)
+	require.NoError(t, err)
+	assert.DirExists(t, w.Dir())
+	assert.Equal(t, root, filepath.Dir(w.Dir()))
+
+	assert.NoDirExists(t, legacy)
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, running.Dir())
+	assert.DirExists(t, creating)
+	assert.DirExists(t, other)
+
+	// The temporary directory of the process is not changed
+	assert.Equal(t, origTmpDir, os.TempDir())
+
+	w.Cleanup()
+	assert.NoDirExists(t, w.Dir())
+
+	// Cleanup must be idempotent
+	w.Cleanup()
+}
+
+func TestCleanupAll(t *testing.T) {
+	w1, err := New(t.TempDir())
+	require.NoError(t, err)
+	w2, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	CleanupAll()
+	assert.NoDirExists(t, w1.Dir())
+	assert.NoDirExists(t, w2.Dir())
+	assert.Empty(t, live)
+}
+
+func TestWorkspace_Preflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		need    int64
+		wantErr string
+	}{
+		{
+			name: "unknown need",
+			need: 0,
+		},
+		{
+			name: "enough space",
+			need: 1,
+		},
+		{
+			name:    "insufficient space",
+			need:    1 << 62,
+			wantErr: "insufficient disk space",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := New(t.TempDir())
+			require.NoError(t, err)
+			defer w.Cleanup()
+
+			err = w.Preflight(tt.need)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
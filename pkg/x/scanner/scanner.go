@@ -0,0 +1,319 @@
+// Package scanner embeds Trivy in Go programs instead of running the CLI.
+//
+// The package is experimental as the "x" in the path tells, and the API can change in any version.
+// The reports are the types of the CLI, which change with the JSON output.
+//
+//	s, err := scanner.New(scanner.Options{Severities: []string{"HIGH", "CRITICAL"}})
+//	if err != nil {
+//		return err
+//	}
+//	defer s.Close()
+//
+//	report, err := s.ScanImage(ctx, "alpine:3.15")
+//
+// The vulnerability DB is shared by the process, so only one Scanner can be open at a time.
+// The Scanner doesn't handle the signals, change the environment variables or replace the logger of the program.
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	tartifact "github.com/aquasecurity/trivy/pkg/artifact"
+	"github.com/aquasecurity/trivy/pkg/commands/artifact"
+	"github.com/aquasecurity/trivy/pkg/commands/option"
+	"github.com/aquasecurity/trivy/pkg/result"
+	"github.com/aquasecurity/trivy/pkg/rpc"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/utils"
+)
+
+const (
+	// DefaultDBRepository is the OCI repository of the vulnerability DB
+	DefaultDBRepository = "ghcr.io/aquasecurity/trivy-db"
+
+	// DefaultTimeout is the timeout of a scan
+	DefaultTimeout = 5 * time.Minute
+
+	// DefaultParallel is the number of layers and files analyzed at the same time
+	DefaultParallel = tartifact.DefaultParallel
+)
+
+// Report is the result of a scan, which is the same as the JSON output of the CLI
+type Report = types.Report
+
+// Options configures the Scanner. The zero value of each field is the default of the CLI.
+type Options struct {
+	// CacheDir has the vulnerability DB and the analysis cache, the user cache directory by default
+	CacheDir string
+
+	// DBRepository is where the vulnerability DB is downloaded from, DefaultDBRepository by default
+	DBRepository string
+
+	// SkipDBUpdate uses the DB in CacheDir without updating it
+	SkipDBUpdate bool
+
+	// SkipPolicyUpdate uses the policies in CacheDir without updating them
+	SkipPolicyUpdate bool
+
+	// SecurityChecks are "vuln", "config" and "secret", "vuln" and "secret" by default
+	SecurityChecks []string
+
+	// VulnTypes are "os" and "library", both by default
+	VulnTypes []string
+
+	// Severities are the severities of the findings included in the reports, all by default
+	Severities []string
+
+	// IgnoreUnfixed excludes the vulnerabilities without fixes
+	IgnoreUnfixed bool
+
+	// IgnoreFile is the path to the .trivyignore file, nothing is ignored by default
+	IgnoreFile string
+
+	// ListAllPackages includes all the packages in the reports, not only the vulnerable ones
+	ListAllPackages bool
+
+	// SkipFiles and SkipDirs are the paths or the globs not analyzed
+	SkipFiles []string
+	SkipDirs  []string
+
+	// FilePatterns are the analyzers and the regexps of the files with nonstandard names (e.g. pip:requirements-.*\.txt)
+	FilePatterns []string
+
+	// SecretConfigPath is the path to the config of the secret rules, the builtin rules by default
+	SecretConfigPath string
+
+	// Timeout is applied to each scan, DefaultTimeout by default
+	Timeout time.Duration
+
+	// Parallel is the number of layers and files analyzed at the same time, DefaultParallel by default
+	Parallel int
+
+	// MaxFiles aborts the analysis of the artifacts with more files, no limit by default
+	MaxFiles int
+
+	// FileTimeout skips the files whose analysis takes longer, no limit by default
+	FileTimeout time.Duration
+
+	// MaxArchiveDepth is how deep the nested zip archives, e.g. JAR files, are unpacked, not unpacked by default
+	MaxArchiveDepth int
+
+	// Locale is the language of the titles and the descriptions of the vulnerabilities, e.g. "ja", English by default
+	Locale string
+
+	// SBOMHashes are the algorithms of the hashes of the package files, e.g. "sha256", written with ListAllPackages
+	SBOMHashes []string
+
+	// Insecure allows the registries and the servers without valid certificates
+	Insecure bool
+
+	// RemoteAddr is the address of the server to scan in client/server mode, e.g. "http://localhost:4954"
+	RemoteAddr string
+
+	// CustomHeaders are sent to the server, e.g. the token
+	CustomHeaders http.Header
+
+	// TempDir is where the images and the large files are saved during the scans, the system temporary directory by default.
+	// Each Scanner has its own directory under it, which is removed by Close.
+	TempDir string
+}
+
+// Scanner scans images, filesystems and repositories
+type Scanner struct {
+	opt    artifact.Option
+	runner *artifact.Runner
+}
+
+// New initializes the cache and downloads the vulnerability DB unless it is up to date.
+// The Scanner must be closed after use.
+func New(opts Options) (*Scanner, error) {
+	opt, err := opts.option()
+	if err != nil {
+		return nil, err
+	}
+
+	runner, err := artifact.NewLibraryRunner(opt)
+	if err != nil {
+		return nil, xerrors.Errorf("init error: %w", err)
+	}
+	return &Scanner{
+		opt:    opt,
+		runner: runner,
+	}, nil
+}
+
+// option converts the options into the ones of the CLI
+func (o Options) option() (artifact.Option, error) {
+	severities, err := parseSeverities(o.Severities)
+	if err != nil {
+		return artifact.Option{}, err
+	}
+	if o.Parallel < 0 || o.MaxFiles < 0 || o.FileTimeout < 0 || o.MaxArchiveDepth < 0 {
+		return artifact.Option{}, xerrors.New("Parallel, MaxFiles, FileTimeout and MaxArchiveDepth must not be negative")
+	}
+	for _, alg := range o.SBOMHashes {
+		if !slices.Contains(types.HashAlgorithms, alg) {
+			return artifact.Option{}, xerrors.Errorf("unknown SBOM hash algorithm: %s", alg)
+		}
+	}
+	locale := o.Locale
+	if locale != "" {
+		if locale, err = result.ParseLocale(locale); err != nil {
+			return artifact.Option{}, xerrors.Errorf("locale error: %w", err)
+		}
+	}
+
+	opt := artifact.Option{
+		GlobalOption: option.GlobalOption{
+			CacheDir: defaultString(o.CacheDir, utils.DefaultCacheDir()),
+		},
+		ArtifactOption: option.ArtifactOption{
+			Timeout:   o.Timeout,
+			Insecure:  o.Insecure,
+			SkipFiles: o.SkipFiles,
+			SkipDirs:  o.SkipDirs,
+			Parallel:  o.Parallel,
+			WorkDir:   o.TempDir,
+
+			MaxFiles:        o.MaxFiles,
+			FileTimeout:     o.FileTimeout,
+			MaxArchiveDepth: o.MaxArchiveDepth,
+		},
+		DBOption: option.DBOption{
+			SkipDBUpdate: o.SkipDBUpdate,
+			// The progress bars are not drawn in the programs embedding the scanner
+			NoProgress:   true,
+			DBRepository: defaultString(o.DBRepository, DefaultDBRepository),
+			Locale:       locale,
+		},
+		ReportOption: option.ReportOption{
+			IgnoreFile:     o.IgnoreFile,
+			IgnoreUnfixed:  o.IgnoreUnfixed,
+			VulnType:       o.VulnTypes,
+			SecurityChecks: o.SecurityChecks,
+			Severities:     severities,
+			ListAllPkgs:    o.ListAllPackages,
+		},
+		CacheOption: option.CacheOption{
+			CacheBackend: "fs",
+		},
+		ConfigOption: option.ConfigOption{
+			FilePatterns:     o.FilePatterns,
+			SkipPolicyUpdate: o.SkipPolicyUpdate,
+		},
+		RemoteOption: option.RemoteOption{
+			RemoteAddr:    o.RemoteAddr,
+			ServerRetries: rpc.DefaultRetries,
+			CustomHeaders: o.CustomHeaders,
+		},
+		SecretOption: option.SecretOption{
+			SecretConfigPath: o.SecretConfigPath,
+		},
+		SbomOption: option.SbomOption{
+			SbomHashes: o.SBOMHashes,
+		},
+	}
+
+	if opt.Timeout == 0 {
+		opt.Timeout = DefaultTimeout
+	}
+	// 0 is the number of CPUs in the CLI
+	if opt.Parallel == 0 {
+		opt.Parallel = DefaultParallel
+	}
+	if len(opt.VulnType) == 0 {
+		opt.VulnType = []string{types.VulnTypeOS, types.VulnTypeLibrary}
+	}
+	if len(opt.SecurityChecks) == 0 {
+		opt.SecurityChecks = []string{types.SecurityCheckVulnerability, types.SecurityCheckSecret}
+	}
+	if opt.CustomHeaders == nil {
+		opt.CustomHeaders = http.Header{}
+	}
+	return opt, nil
+}
+
+func parseSeverities(names []string) ([]dbTypes.Severity, error) {
+	if len(names) == 0 {
+		names = dbTypes.SeverityNames
+	}
+	var severities []dbTypes.Severity
+	for _, name := range names {
+		s, err := dbTypes.NewSeverity(name)
+		if err != nil {
+			return nil, xerrors.Errorf("unknown severity: %s", name)
+		}
+		severities = append(severities, s)
+	}
+	return severities, nil
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// ScanImage scans the image in the Docker Engine, containerd, Podman or the registry, e.g. "alpine:3.15"
+func (s *Scanner) ScanImage(ctx context.Context, ref string) (Report, error) {
+	opt := s.opt
+	opt.Target = ref
+	return s.scan(ctx, opt, s.runner.ScanImage)
+}
+
+// ScanImageArchive scans the image saved as a tar archive, e.g. by "docker save"
+func (s *Scanner) ScanImageArchive(ctx context.Context, path string) (Report, error) {
+	opt := s.opt
+	opt.Input = path
+	return s.scan(ctx, opt, s.runner.ScanImage)
+}
+
+// ScanFilesystem scans the lock files, the config files and the secrets in the directory
+func (s *Scanner) ScanFilesystem(ctx context.Context, path string) (Report, error) {
+	opt := s.opt
+	opt.Target = path
+	return s.scan(ctx, opt, s.runner.ScanFilesystem)
+}
+
+// ScanRootfs scans the root filesystem, e.g. of a VM or an unpacked image
+func (s *Scanner) ScanRootfs(ctx context.Context, path string) (Report, error) {
+	opt := s.opt
+	opt.Target = path
+	return s.scan(ctx, opt, s.runner.ScanRootfs)
+}
+
+// ScanRepository clones the remote Git repository and scans it like a filesystem
+func (s *Scanner) ScanRepository(ctx context.Context, url string) (Report, error) {
+	opt := s.opt
+	opt.Target = url
+	return s.scan(ctx, opt, s.runner.ScanRepository)
+}
+
+type scanFunc func(ctx context.Context, opt artifact.Option) (types.Report, error)
+
+func (s *Scanner) scan(ctx context.Context, opt artifact.Option, scan scanFunc) (Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, opt.Timeout)
+	defer cancel()
+
+	report, err := scan(ctx, opt)
+	if err != nil {
+		return Report{}, err
+	}
+	report, err = s.runner.Filter(ctx, opt, report)
+	if err != nil {
+		return Report{}, xerrors.Errorf("filter error: %w", err)
+	}
+	return report, nil
+}
+
+// Close closes the cache and the vulnerability DB, and removes the temporary directory
+func (s *Scanner) Close() error {
+	return s.runner.Close()
+}
@@ -34,6 +34,9 @@ const _ = twirp.TwirpPackageMinVersion_8_1_0
 
 type Scanner interface {
 	Scan(context.Context, *ScanRequest) (*ScanResponse, error)
+
+	// InspectImage pulls the image and analyzes it in the server, for clients without registry access
+	InspectImage(context.Context, *InspectImageRequest) (*InspectImageResponse, error)
 }
 
 // =======================
@@ -42,7 +45,7 @@ type Scanner interface {
 
 type scannerProtobufClient struct {
 	client      HTTPClient
-	urls        [1]string
+	urls        [2]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -70,8 +73,9 @@ func NewScannerProtobufClient(baseURL string, client HTTPClient, opts ...twirp.C
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "trivy.scanner.v1", "Scanner")
-	urls := [1]string{
+	urls := [2]string{
 		serviceURL + "Scan",
+		serviceURL + "InspectImage",
 	}
 
 	return &scannerProtobufClient{
@@ -128,13 +132,59 @@ func (c *scannerProtobufClient) callScan(ctx context.Context, in *ScanRequest) (
 	return out, nil
 }
 
+func (c *scannerProtobufClient) InspectImage(ctx context.Context, in *InspectImageRequest) (*InspectImageResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "trivy.scanner.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "Scanner")
+	ctx = ctxsetters.WithMethodName(ctx, "InspectImage")
+	caller := c.callInspectImage
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *InspectImageRequest) (*InspectImageResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*InspectImageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*InspectImageRequest) when calling interceptor")
+					}
+					return c.callInspectImage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*InspectImageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*InspectImageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *scannerProtobufClient) callInspectImage(ctx context.Context, in *InspectImageRequest) (*InspectImageResponse, error) {
+	out := new(InspectImageResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 // ===================
 // Scanner JSON Client
 // ===================
 
 type scannerJSONClient struct {
 	client      HTTPClient
-	urls        [1]string
+	urls        [2]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -162,8 +212,9 @@ func NewScannerJSONClient(baseURL string, client HTTPClient, opts ...twirp.Clien
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "trivy.scanner.v1", "Scanner")
-	urls := [1]string{
+	urls := [2]string{
 		serviceURL + "Scan",
+		serviceURL + "InspectImage",
 	}
 
 	return &scannerJSONClient{
@@ -220,6 +271,52 @@ func (c *scannerJSONClient) callScan(ctx context.Context, in *ScanRequest) (*Sca
 	return out, nil
 }
 
+func (c *scannerJSONClient) InspectImage(ctx context.Context, in *InspectImageRequest) (*InspectImageResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "trivy.scanner.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "Scanner")
+	ctx = ctxsetters.WithMethodName(ctx, "InspectImage")
+	caller := c.callInspectImage
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *InspectImageRequest) (*InspectImageResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*InspectImageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*InspectImageRequest) when calling interceptor")
+					}
+					return c.callInspectImage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*InspectImageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*InspectImageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *scannerJSONClient) callInspectImage(ctx context.Context, in *InspectImageRequest) (*InspectImageResponse, error) {
+	out := new(InspectImageResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 // ======================
 // Scanner Server Handler
 // ======================
@@ -320,6 +417,9 @@ func (s *scannerServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	case "Scan":
 		s.serveScan(ctx, resp, req)
 		return
+	case "InspectImage":
+		s.serveInspectImage(ctx, resp, req)
+		return
 	default:
 		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
 		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
@@ -507,6 +607,186 @@ func (s *scannerServer) serveScanProtobuf(ctx context.Context, resp http.Respons
 	callResponseSent(ctx, s.hooks)
 }
 
+func (s *scannerServer) serveInspectImage(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveInspectImageJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveInspectImageProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *scannerServer) serveInspectImageJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "InspectImage")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(InspectImageRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.Scanner.InspectImage
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *InspectImageRequest) (*InspectImageResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*InspectImageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*InspectImageRequest) when calling interceptor")
+					}
+					return s.Scanner.InspectImage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*InspectImageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*InspectImageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *InspectImageResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *InspectImageResponse and nil error while calling InspectImage. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *scannerServer) serveInspectImageProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "InspectImage")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(InspectImageRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.Scanner.InspectImage
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *InspectImageRequest) (*InspectImageResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*InspectImageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*InspectImageRequest) when calling interceptor")
+					}
+					return s.Scanner.InspectImage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*InspectImageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*InspectImageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *InspectImageResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *InspectImageResponse and nil error while calling InspectImage. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
 func (s *scannerServer) ServiceDescriptor() ([]byte, int) {
 	return twirpFileDescriptor0, 0
 }
@@ -1091,38 +1371,53 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 514 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x53, 0x3d, 0x6f, 0xdb, 0x30,
-	0x10, 0x85, 0x6c, 0xc7, 0x1f, 0xa7, 0xa2, 0x76, 0x88, 0xb6, 0x50, 0x92, 0x7e, 0x18, 0x1e, 0x5a,
-	0xa3, 0x83, 0x0c, 0x2b, 0x43, 0x87, 0x4e, 0x6d, 0x1a, 0x14, 0x19, 0x8a, 0x04, 0x74, 0xd0, 0xa1,
-	0x8b, 0x40, 0x53, 0x8c, 0x43, 0x44, 0x16, 0x15, 0x1e, 0x65, 0xc0, 0x53, 0xff, 0x47, 0xff, 0x5e,
-	0xff, 0x48, 0x20, 0x52, 0x0a, 0x22, 0x07, 0x9e, 0xa4, 0x7b, 0xf7, 0x8e, 0x7c, 0xf7, 0x78, 0x07,
-	0x47, 0x3a, 0xe7, 0x33, 0xe4, 0x2c, 0xcb, 0x84, 0x9e, 0xa1, 0xd0, 0x1b, 0xc9, 0x45, 0x98, 0x6b,
-	0x65, 0x14, 0x19, 0x19, 0x2d, 0x37, 0xdb, 0xb0, 0x4a, 0x86, 0x9b, 0xf9, 0x71, 0x50, 0x92, 0xb9,
-	0x5a, 0xaf, 0x55, 0xd6, 0xe4, 0x4e, 0xfe, 0x79, 0xe0, 0x2f, 0x38, 0xcb, 0xa8, 0xb8, 0x2f, 0x04,
-	0x1a, 0xf2, 0x06, 0xba, 0x86, 0xe9, 0x95, 0x30, 0x81, 0x37, 0xf6, 0xa6, 0x03, 0x5a, 0x45, 0xe4,
-	0x03, 0xf8, 0x4c, 0x1b, 0x79, 0xc3, 0xb8, 0x89, 0x65, 0x12, 0xb4, 0x6c, 0x12, 0x6a, 0xe8, 0x22,
-	0x21, 0x47, 0xd0, 0x5f, 0xa6, 0x6a, 0x19, 0xcb, 0x04, 0x83, 0xf6, 0xb8, 0x3d, 0x1d, 0xd0, 0x5e,
-	0x19, 0x5f, 0x24, 0x48, 0xbe, 0x40, 0x4f, 0xe5, 0x46, 0xaa, 0x0c, 0x83, 0xce, 0xd8, 0x9b, 0xfa,
-	0xd1, 0xbb, 0x70, 0x57, 0x61, 0x58, 0x6a, 0xb8, 0x74, 0x24, 0x5a, 0xb3, 0x27, 0x7f, 0x9d, 0xb6,
-	0x0a, 0x27, 0x27, 0x30, 0xd8, 0x14, 0x69, 0x16, 0x9b, 0x6d, 0x2e, 0x02, 0xcf, 0xde, 0xd1, 0x2f,
-	0x81, 0xeb, 0x6d, 0x2e, 0xc8, 0x27, 0x18, 0xa2, 0xe0, 0x85, 0x96, 0x66, 0x1b, 0xf3, 0x5b, 0xc1,
-	0xef, 0x30, 0x68, 0x59, 0xca, 0xcb, 0x1a, 0x3e, 0xb3, 0x28, 0xf9, 0x0c, 0x87, 0xa9, 0x44, 0x13,
-	0xb3, 0x34, 0x8d, 0x73, 0xc6, 0xef, 0xd8, 0x4a, 0x94, 0x8a, 0xbd, 0x69, 0x9f, 0x0e, 0xcb, 0xc4,
-	0xb7, 0x34, 0xbd, 0xaa, 0xe0, 0x49, 0x02, 0x2f, 0x9c, 0x39, 0x98, 0xab, 0x0c, 0x05, 0x19, 0x43,
-	0x4b, 0xa1, 0x75, 0xc6, 0x8f, 0x46, 0x55, 0x13, 0xce, 0xd6, 0xf0, 0x72, 0x41, 0x5b, 0x0a, 0x49,
-	0x04, 0x3d, 0x2d, 0xb0, 0x48, 0x8d, 0x73, 0xc1, 0x8f, 0x82, 0xe7, 0xbd, 0x52, 0x4b, 0xa0, 0x35,
-	0x71, 0xf2, 0xbf, 0x05, 0x5d, 0x87, 0xed, 0xb5, 0xff, 0x1c, 0x86, 0x65, 0xa7, 0x42, 0xb3, 0xa5,
-	0x4c, 0xa5, 0x91, 0xc2, 0x75, 0xe7, 0x47, 0x27, 0x4d, 0x15, 0xbf, 0x9f, 0x90, 0xb6, 0x74, 0xb7,
-	0x86, 0x5c, 0xc3, 0xe1, 0x5a, 0x22, 0x57, 0xd9, 0x8d, 0x5c, 0x15, 0x9a, 0xd5, 0x6f, 0x52, 0x1e,
-	0xf4, 0xb1, 0x79, 0xd0, 0x0f, 0x61, 0x04, 0x37, 0x22, 0xf9, 0xb5, 0x43, 0xa7, 0xcf, 0x0f, 0x20,
-	0xaf, 0xe0, 0x80, 0xa7, 0x0c, 0x31, 0xe8, 0x5a, 0xcd, 0x2e, 0x20, 0x04, 0x3a, 0xf6, 0xa1, 0xda,
-	0x16, 0xb4, 0xff, 0x64, 0x0e, 0xfd, 0x47, 0xcb, 0x0f, 0xec, 0xb5, 0xaf, 0x9b, 0xd7, 0x56, 0xce,
-	0xd3, 0x47, 0x1a, 0xf9, 0x09, 0x23, 0x5e, 0xa0, 0x51, 0xeb, 0x58, 0x0b, 0x54, 0x85, 0xe6, 0x02,
-	0x83, 0x9e, 0x2d, 0x7d, 0xdb, 0x2c, 0x3d, 0xb3, 0x2c, 0x5a, 0x91, 0xe8, 0x90, 0x37, 0x62, 0x8c,
-	0xae, 0xa0, 0xb7, 0x70, 0x6f, 0x40, 0xce, 0xa1, 0x53, 0xfe, 0x92, 0x3d, 0x73, 0x58, 0xed, 0xc2,
-	0xf1, 0xfb, 0x7d, 0x69, 0x37, 0x0d, 0xdf, 0x4f, 0xff, 0xcc, 0x57, 0xd2, 0xdc, 0x16, 0xcb, 0x52,
-	0xc2, 0x8c, 0xdd, 0x17, 0xac, 0x1e, 0xb5, 0x99, 0x2d, 0x9c, 0x3d, 0x59, 0xd1, 0xaf, 0xd5, 0x77,
-	0xd9, 0xb5, 0x7b, 0x77, 0xfa, 0x10, 0x00, 0x00, 0xff, 0xff, 0xb0, 0x5b, 0x27, 0x32, 0xc0, 0x03,
-	0x00, 0x00,
+	// 760 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0x4f, 0x6f, 0xe4, 0x34,
+	0x14, 0x57, 0xa6, 0x7f, 0x92, 0x79, 0x53, 0xd1, 0xd6, 0x2c, 0x28, 0x6d, 0x59, 0x18, 0x46, 0x62,
+	0x19, 0x21, 0x31, 0xa3, 0xce, 0x1e, 0x38, 0x70, 0x5a, 0xb6, 0x0b, 0xea, 0x01, 0x16, 0xb9, 0x15,
+	0x07, 0x24, 0x14, 0x79, 0x9c, 0x37, 0x19, 0xab, 0x4e, 0x9c, 0xb5, 0x9d, 0x91, 0x86, 0x8f, 0xc2,
+	0x37, 0xe0, 0xc4, 0x99, 0x8f, 0xc4, 0x67, 0xe0, 0x82, 0x6c, 0x27, 0x55, 0x33, 0xa5, 0x70, 0x4a,
+	0xde, 0xef, 0xfd, 0x6c, 0xff, 0xde, 0xcf, 0xef, 0x19, 0xce, 0x74, 0xcd, 0xe7, 0x86, 0xb3, 0xaa,
+	0x42, 0x3d, 0x37, 0xa8, 0x37, 0x82, 0xe3, 0xac, 0xd6, 0xca, 0x2a, 0x72, 0x62, 0xb5, 0xd8, 0x6c,
+	0x67, 0x6d, 0x72, 0xb6, 0xb9, 0x3c, 0x4f, 0x1d, 0x99, 0xab, 0xb2, 0x54, 0x55, 0x9f, 0x3b, 0xf9,
+	0x2d, 0x82, 0xd1, 0x0d, 0x67, 0x15, 0xc5, 0x77, 0x0d, 0x1a, 0x4b, 0x3e, 0x84, 0x43, 0xcb, 0x74,
+	0x81, 0x36, 0x8d, 0xc6, 0xd1, 0x74, 0x48, 0xdb, 0x88, 0x7c, 0x02, 0x23, 0xa6, 0xad, 0x58, 0x31,
+	0x6e, 0x33, 0x91, 0xa7, 0x03, 0x9f, 0x84, 0x0e, 0xba, 0xce, 0xc9, 0x19, 0x24, 0x4b, 0xa9, 0x96,
+	0x99, 0xc8, 0x4d, 0xba, 0x37, 0xde, 0x9b, 0x0e, 0x69, 0xec, 0xe2, 0xeb, 0xdc, 0x90, 0xaf, 0x20,
+	0x56, 0xb5, 0x15, 0xaa, 0x32, 0xe9, 0xfe, 0x38, 0x9a, 0x8e, 0x16, 0xcf, 0x67, 0xbb, 0x0a, 0x67,
+	0x4e, 0xc3, 0xdb, 0x40, 0xa2, 0x1d, 0x7b, 0xf2, 0x7b, 0x2b, 0xae, 0x4d, 0x90, 0x0b, 0x18, 0x6e,
+	0x1a, 0x59, 0x65, 0x76, 0x5b, 0x63, 0x1a, 0xf9, 0x43, 0x12, 0x07, 0xdc, 0x6e, 0x6b, 0x24, 0x9f,
+	0xc3, 0xb1, 0x41, 0xde, 0x68, 0x61, 0xb7, 0x19, 0x5f, 0x23, 0xbf, 0x33, 0xe9, 0xc0, 0x53, 0xde,
+	0xeb, 0xe0, 0xd7, 0x1e, 0x25, 0x5f, 0xc0, 0xa9, 0x14, 0xc6, 0x66, 0x4c, 0xca, 0xac, 0x66, 0xfc,
+	0x8e, 0x15, 0xe8, 0x24, 0x47, 0xd3, 0x84, 0x1e, 0xbb, 0xc4, 0x2b, 0x29, 0x7f, 0x6c, 0x61, 0x72,
+	0x02, 0x7b, 0x68, 0x4a, 0x2f, 0x3b, 0xa1, 0xee, 0xd7, 0x19, 0x24, 0x15, 0x67, 0x12, 0xd3, 0x83,
+	0x60, 0x50, 0x88, 0x26, 0x7f, 0x46, 0xf0, 0xfe, 0x75, 0x65, 0x6a, 0xe4, 0xf6, 0xba, 0x64, 0x05,
+	0x76, 0x86, 0x3e, 0x07, 0x10, 0x2e, 0xce, 0x2a, 0x56, 0x62, 0x6b, 0xea, 0xd0, 0x23, 0x3f, 0xb0,
+	0x12, 0xc9, 0x97, 0x40, 0x72, 0x61, 0xd8, 0x52, 0x62, 0x9e, 0xb1, 0x8a, 0xc9, 0xed, 0xaf, 0xa8,
+	0x3b, 0xe1, 0xa7, 0x5d, 0xe6, 0x55, 0x97, 0x70, 0xbb, 0x99, 0x3b, 0x51, 0x67, 0x2b, 0x21, 0xb1,
+	0xf3, 0x79, 0xe8, 0x90, 0x6f, 0x1d, 0xe0, 0x0c, 0xf2, 0xe9, 0x5c, 0x68, 0xe7, 0xb5, 0x37, 0xc8,
+	0x01, 0x57, 0x42, 0x1b, 0x92, 0x42, 0xac, 0x56, 0x2b, 0x29, 0xaa, 0x20, 0x3d, 0xa1, 0x5d, 0x38,
+	0xf9, 0x2b, 0x82, 0x67, 0x7d, 0xed, 0xa6, 0x56, 0x95, 0xc1, 0xdd, 0x5b, 0x8f, 0xfe, 0xf3, 0xd6,
+	0x07, 0xfd, 0x5b, 0x3f, 0x83, 0x24, 0x14, 0x2e, 0x72, 0xef, 0xee, 0x90, 0xc6, 0x3e, 0x0e, 0xab,
+	0x72, 0xb1, 0x5a, 0xf9, 0x55, 0x41, 0x65, 0xec, 0x62, 0xb7, 0xea, 0x02, 0x86, 0x1a, 0x6b, 0x95,
+	0x59, 0x56, 0x98, 0xf4, 0x20, 0x54, 0xe0, 0x80, 0x5b, 0x56, 0x18, 0xf2, 0x29, 0x1c, 0xf9, 0x64,
+	0x2e, 0x0a, 0x34, 0xd6, 0xa4, 0x87, 0x3e, 0x3f, 0x72, 0xd8, 0x55, 0x80, 0x9c, 0x62, 0xae, 0xaa,
+	0x95, 0x28, 0xbc, 0x45, 0x69, 0x3c, 0x8e, 0xa6, 0x47, 0x14, 0x02, 0xe4, 0x3c, 0x9a, 0xe4, 0x70,
+	0x14, 0xfa, 0xbd, 0x2d, 0x71, 0x0c, 0x03, 0x65, 0x7c, 0x65, 0xa3, 0xc5, 0x49, 0xdb, 0x97, 0x61,
+	0x52, 0x66, 0x6f, 0x6f, 0xe8, 0x40, 0x19, 0xb2, 0x80, 0x58, 0xa3, 0x69, 0xa4, 0x0d, 0x86, 0x8f,
+	0x16, 0xe9, 0xe3, 0xf6, 0xa5, 0x9e, 0x40, 0x3b, 0xe2, 0xe4, 0xef, 0x01, 0x1c, 0x06, 0xec, 0xc9,
+	0x89, 0x7a, 0x03, 0xc7, 0xae, 0x77, 0x51, 0xb3, 0xa5, 0x90, 0xc2, 0x0a, 0x0c, 0x0e, 0x8e, 0x16,
+	0x17, 0x7d, 0x15, 0x3f, 0x3d, 0x20, 0x6d, 0xe9, 0xee, 0x1a, 0x72, 0x0b, 0xa7, 0xa5, 0x30, 0xa1,
+	0xc0, 0x46, 0xb3, 0x6e, 0xcc, 0xdc, 0x46, 0x2f, 0xfa, 0x1b, 0x5d, 0xa1, 0x45, 0x6e, 0x31, 0xff,
+	0x7e, 0x87, 0x4e, 0x1f, 0x6f, 0x40, 0x9e, 0xc1, 0x01, 0x97, 0xcc, 0x38, 0x8b, 0x9d, 0xe6, 0x10,
+	0x10, 0x02, 0xfb, 0x7e, 0xf4, 0xc2, 0x75, 0xfa, 0x7f, 0x72, 0x09, 0xc9, 0xfd, 0x10, 0x1d, 0xf8,
+	0x63, 0x3f, 0xe8, 0x1f, 0xdb, 0xce, 0x12, 0xbd, 0xa7, 0x91, 0xef, 0xe0, 0x84, 0x37, 0xc6, 0xaa,
+	0x32, 0xd3, 0x68, 0x54, 0xa3, 0x39, 0x9a, 0x34, 0xf6, 0x4b, 0x3f, 0xea, 0x2f, 0x7d, 0xed, 0x59,
+	0xb4, 0x25, 0xd1, 0x63, 0xde, 0x8b, 0x8d, 0xb3, 0x76, 0xcd, 0xcc, 0x1a, 0x4d, 0x9a, 0xf8, 0x4e,
+	0x68, 0xa3, 0xc5, 0x1f, 0x11, 0xc4, 0x37, 0xe1, 0x72, 0xc8, 0x1b, 0xd8, 0x77, 0xbf, 0xe4, 0x89,
+	0x37, 0xa7, 0x1d, 0xd3, 0xf3, 0x8f, 0x9f, 0x4a, 0xb7, 0x6d, 0xf2, 0x0b, 0x1c, 0x3d, 0x9c, 0x10,
+	0xf2, 0xd9, 0x63, 0xfe, 0xbf, 0x4c, 0xff, 0xf9, 0x8b, 0xff, 0xa3, 0x85, 0xed, 0xbf, 0x79, 0xf9,
+	0xf3, 0x65, 0x21, 0xec, 0xba, 0x59, 0xba, 0xd2, 0xe7, 0xec, 0x5d, 0xc3, 0xba, 0x47, 0x6b, 0xee,
+	0x37, 0x98, 0x3f, 0x78, 0xed, 0xbf, 0x6e, 0xbf, 0xcb, 0x43, 0xff, 0x84, 0xbf, 0xfc, 0x67, 0x00,
+	0xeb, 0xd5, 0x87, 0x2a, 0x0b, 0x06, 0x00, 0x00,
 }